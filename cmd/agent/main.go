@@ -7,16 +7,33 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/kubeflow/kfserving/pkg/agent"
+	"github.com/kubeflow/kfserving/pkg/agent/metadata"
 	"github.com/kubeflow/kfserving/pkg/agent/storage"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
 	s3credential "github.com/kubeflow/kfserving/pkg/credentials/s3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
 	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+	"time"
 )
 
 var (
-	configDir = flag.String("config-dir", "/mnt/configs", "directory for model config files")
-	modelDir  = flag.String("model-dir", "/mnt/models", "directory for model files")
+	configDir      = flag.String("config-dir", "/mnt/configs", "directory for model config files")
+	configFile     = flag.String("config-file", constants.ModelConfigFileName, "name of the model config file in the config directory")
+	modelDir       = flag.String("model-dir", "/mnt/models", "directory for model files")
+	modelServerUrl = flag.String("model-server-url", "", "model server url for the model repository API, the models are only loaded and unloaded explicitly when set, e.g. http://localhost:8080")
+	metadataPort   = flag.String("metadata-port", "9081", "port of the v2 model metadata endpoint serving the signatures of the models, empty to disable")
+	drainDelay     = flag.Int("drain-delay", 0, "seconds to wait on shutdown before unloading the models")
+	drainTimeout   = flag.Int("drain-timeout", 10, "seconds to wait on shutdown for the models to be unloaded")
 )
 
+const podNamespaceEnvVarKey = "POD_NAMESPACE"
+
 func main() {
 	flag.Parse()
 	downloader := agent.Downloader{
@@ -41,6 +58,52 @@ func main() {
 	}
 
 	watcher := agent.NewWatcher(*configDir, *modelDir)
-	agent.StartPuller(downloader, watcher.ModelEvents)
+	watcher.ConfigFile = *configFile
+	var loader *agent.Loader
+	if *modelServerUrl != "" {
+		loader = agent.NewLoader(*modelServerUrl)
+	}
+	var metadataStore *metadata.Store
+	if *metadataPort != "" {
+		metadataStore = metadata.NewStore()
+		metadataStore.StatusUpdater = newStatusUpdater()
+		go func() {
+			if err := http.ListenAndServe(":"+*metadataPort, metadataStore); err != nil {
+				panic(err)
+			}
+		}()
+	}
+	agent.StartPuller(downloader, loader, metadataStore, watcher.ModelEvents)
 	watcher.Start()
+
+	<-signals.SetupSignalHandler()
+	// Unload the models after the batcher and the logger have drained
+	time.Sleep(time.Duration(*drainDelay) * time.Second)
+	if loader != nil && !loader.UnloadAll(time.Duration(*drainTimeout)*time.Second) {
+		logf.Log.WithName("agent").Info("Timed out unloading the models on shutdown")
+	}
+}
+
+// newStatusUpdater returns the updater of the TrainedModel status when the namespace of the pod is set, nil otherwise
+func newStatusUpdater() *metadata.StatusUpdater {
+	log := logf.Log.WithName("agent")
+	namespace, ok := os.LookupEnv(podNamespaceEnvVarKey)
+	if !ok {
+		return nil
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "Failed to get the cluster config, the trained model status is not updated")
+		return nil
+	}
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		log.Error(err, "Failed to create the client, the trained model status is not updated")
+		return nil
+	}
+	return &metadata.StatusUpdater{Client: c, Namespace: namespace}
 }
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apikeyauthz"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned"
+	"github.com/kubeflow/kfserving/pkg/client/informers/externalversions"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	port         = flag.String("port", "8000", "Port of the ext-authz provider")
+	drainTimeout = flag.Int("drain-timeout", 10, "Seconds to wait for the check requests on shutdown")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("apikeyauthz")
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "Failed to get the kubeconfig")
+		os.Exit(1)
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "Failed to create the clientset")
+		os.Exit(1)
+	}
+
+	servingClientset, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "Failed to create the serving clientset")
+		os.Exit(1)
+	}
+
+	// Only the API key secrets generated for the InferenceServices are watched, the secret of a request is read by
+	// the name of the InferenceService resolved from the InferenceServices
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = constants.APIKeySecretLabelKey + "=true," + constants.InferenceServicePodLabelKey
+		}))
+	servingFactory := externalversions.NewSharedInformerFactory(servingClientset, 0)
+	secrets := factory.Core().V1().Secrets()
+	isvcs := servingFactory.Serving().V1beta1().InferenceServices()
+	authorizer := &apikeyauthz.Authorizer{InferenceServices: isvcs.Lister(), Secrets: secrets.Lister(), Log: log}
+	stopCh := signals.SetupSignalHandler()
+	factory.Start(stopCh)
+	servingFactory.Start(stopCh)
+	for informer, synced := range factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			log.Info("Failed to sync the informer", "informer", informer)
+			os.Exit(1)
+		}
+	}
+	for informer, synced := range servingFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			log.Info("Failed to sync the informer", "informer", informer)
+			os.Exit(1)
+		}
+	}
+
+	server := &http.Server{Addr: ":" + *port, Handler: authorizer}
+	go func() {
+		log.Info("Starting", "Port", *port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "Failed to serve the ext-authz provider")
+			os.Exit(1)
+		}
+	}()
+
+	<-stopCh
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*drainTimeout)*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Info("Check requests still in flight at the drain timeout")
+	}
+}
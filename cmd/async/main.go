@@ -0,0 +1,98 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/async"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	port          = flag.String("port", "9084", "Port of the async frontend")
+	config        = flag.String("config", "", "JSON configuration of the async inference")
+	componentHost = flag.String("component-host", "127.0.0.1", "Component host")
+	componentPort = flag.String("component-port", "8080", "Component port")
+	retries       = flag.Int("retries", 3, "Retries of a request failing with a connection error or a 502, 503 or 504 response")
+	drainDelay    = flag.Int("drain-delay", 0, "Seconds to keep accepting requests on shutdown before draining the requests sent to the predictor")
+	drainTimeout  = flag.Int("drain-timeout", 10, "Seconds to wait for the requests sent to the predictor on shutdown")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("async")
+
+	frontend := &async.Frontend{
+		ComponentURL:  "http://" + *componentHost + ":" + *componentPort,
+		Retries:       *retries,
+		RetryInterval: time.Second,
+		Log:           log,
+	}
+	if err := json.Unmarshal([]byte(*config), &frontend.Config); err != nil {
+		log.Error(err, "Invalid async configuration", "config", *config)
+		os.Exit(1)
+	}
+	frontend.Client = &http.Client{Timeout: time.Duration(frontend.TimeoutSeconds) * time.Second}
+	frontend.CallbackClient = async.NewCallbackClient(time.Duration(frontend.TimeoutSeconds) * time.Second)
+	resultTTL := time.Duration(frontend.ResultTTLSeconds) * time.Second
+	switch frontend.Backend {
+	case "redis":
+		frontend.Queue = async.NewRedisQueue(frontend.RedisAddress, frontend.QueueName, frontend.MaxQueueDepth, resultTTL)
+	default:
+		frontend.Queue = async.NewMemoryQueue(frontend.MaxQueueDepth, resultTTL)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(async.MetricsPath, promhttp.Handler())
+	mux.Handle("/", frontend)
+	server := &http.Server{Addr: ":" + *port, Handler: mux}
+	go func() {
+		log.Info("Starting", "Port", *port, "backend", frontend.Backend, "workers", frontend.Workers)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "Failed to serve the async frontend")
+			os.Exit(1)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		frontend.Run(ctx)
+		close(done)
+	}()
+
+	<-signals.SetupSignalHandler()
+	log.Info("Draining the requests sent to the predictor", "delay", *drainDelay, "timeout", *drainTimeout)
+	time.Sleep(time.Duration(*drainDelay) * time.Second)
+	server.Shutdown(context.Background())
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Duration(*drainTimeout) * time.Second):
+		log.Info("Requests still sent to the predictor at the drain timeout")
+	}
+}
@@ -23,7 +23,9 @@ import (
 	"github.com/kubeflow/kfserving/pkg/batcher/controllers"
 	"os"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 	"strconv"
+	"time"
 )
 
 var (
@@ -33,6 +35,11 @@ var (
 	maxBatchSize  = flag.String("max-batchsize", "32", "Max Batch Size")
 	maxLatency    = flag.String("max-latency", "5000", "Max Latency in milliseconds")
 	timeout       = flag.String("timeout", "60", "Timeout of calling predictor service in seconds")
+	maxQueueDepth = flag.String("max-queue-depth", "0", "Max number of pending requests, 0 for no limit")
+	retryAfter    = flag.String("retry-after", "1", "Retry-After in seconds advised to the clients when the predictor is saturated")
+	drainDelay    = flag.Int("drain-delay", 0, "Seconds to keep serving on shutdown before draining the pending requests")
+	drainTimeout  = flag.Int("drain-timeout", 10, "Seconds to wait for the pending requests on shutdown")
+	streaming     = flag.Bool("streaming", false, "Pass the requests asking for a stream or without instances through to the predictor")
 )
 
 func main() {
@@ -59,7 +66,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	controllers.Config(*port, *componentHost, *componentPort, maxBatchSizeInt, maxLatencyInt, timeoutInt)
+	maxQueueDepthInt, err := strconv.Atoi(*maxQueueDepth)
+	if err != nil || maxQueueDepthInt < 0 {
+		log.Error(errors.New("Invalid max queue depth"), *maxQueueDepth)
+		os.Exit(1)
+	}
+
+	retryAfterInt, err := strconv.Atoi(*retryAfter)
+	if err != nil || retryAfterInt <= 0 {
+		log.Error(errors.New("Invalid retry after"), *retryAfter)
+		os.Exit(1)
+	}
+
+	controllers.Config(*port, *componentHost, *componentPort, maxBatchSizeInt, maxLatencyInt, timeoutInt,
+		maxQueueDepthInt, retryAfterInt, *streaming)
+
+	stopCh := signals.SetupSignalHandler()
+	go func() {
+		<-stopCh
+		log.Info("Draining the pending requests", "delay", *drainDelay, "timeout", *drainTimeout)
+		if !controllers.Drain(time.Duration(*drainDelay)*time.Second, time.Duration(*drainTimeout)*time.Second) {
+			log.Info("Requests still pending at the drain timeout")
+		}
+		os.Exit(0)
+	}()
 
 	log.Info("Starting", "Port", *port)
 	batcher.StartHttpServer()
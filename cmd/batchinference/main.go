@@ -0,0 +1,130 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/batchinference"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	input          = flag.String("input", "", "Storage prefix of the input files")
+	output         = flag.String("output", "", "Storage prefix of the output files")
+	predictURL     = flag.String("predict-url", "", "URL of the predict endpoint of the inference service")
+	shardIndex     = flag.Int("shard-index", 0, "Index of the shard of the input files processed by the worker")
+	shardCount     = flag.Int("shard-count", 1, "Number of shards of the input files")
+	retries        = flag.Int("retries", 3, "Retries of a request failing with a connection error or a 5xx response")
+	timeout        = flag.Int("timeout", 300, "Timeout in seconds of a predict request")
+	terminationLog = flag.String("termination-log", "/dev/termination-log", "File the progress of the worker is written to")
+	progressName   = flag.String("progress-config-map", "", "Config map the progress of the running worker is reported to")
+	reportInterval = flag.Int("report-interval", 10, "Interval in seconds the progress of the running worker is reported at")
+)
+
+// podNamespaceEnvVarKey is the env var of the namespace of the worker pod, set with the downward API
+const podNamespaceEnvVarKey = "POD_NAMESPACE"
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("batchinference")
+
+	worker := &batchinference.Worker{
+		PredictURL:     *predictURL,
+		ShardIndex:     *shardIndex,
+		ShardCount:     *shardCount,
+		Retries:        *retries,
+		RetryInterval:  time.Second,
+		Client:         &http.Client{Timeout: time.Duration(*timeout) * time.Second},
+		ReportInterval: time.Duration(*reportInterval) * time.Second,
+		Log:            log,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCh := signals.SetupSignalHandler()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	var err error
+	if worker.Input, err = batchinference.ParseLocation(*input); err != nil {
+		log.Error(err, "Invalid input")
+		os.Exit(1)
+	}
+	if worker.Output, err = batchinference.ParseLocation(*output); err != nil {
+		log.Error(err, "Invalid output")
+		os.Exit(1)
+	}
+	if worker.InputStorage, err = batchinference.NewStorage(ctx, worker.Input); err != nil {
+		log.Error(err, "Failed to create the storage of the input")
+		os.Exit(1)
+	}
+	if worker.OutputStorage, err = batchinference.NewStorage(ctx, worker.Output); err != nil {
+		log.Error(err, "Failed to create the storage of the output")
+		os.Exit(1)
+	}
+	worker.Reporter = newReporter()
+
+	progress, err := worker.Run(ctx)
+	if err != nil {
+		log.Error(err, "Shard not processed")
+		os.Exit(1)
+	}
+	log.Info("Shard processed", "files", progress.Files, "records", progress.ProcessedRecords,
+		"failedRecords", progress.FailedRecords)
+	message, _ := json.Marshal(progress)
+	if err := ioutil.WriteFile(*terminationLog, message, 0644); err != nil {
+		log.Error(err, "Failed to write the progress", "file", *terminationLog)
+	}
+}
+
+// newReporter returns the reporter of the progress config map when it and the namespace of the pod are set, nil
+// otherwise
+func newReporter() batchinference.ProgressReporter {
+	log := logf.Log.WithName("batchinference")
+	namespace, ok := os.LookupEnv(podNamespaceEnvVarKey)
+	if *progressName == "" || !ok {
+		return nil
+	}
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "Failed to get the cluster config, the progress is not reported")
+		return nil
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "Failed to create the client, the progress is not reported")
+		return nil
+	}
+	return &batchinference.ConfigMapReporter{
+		Client:    clientset,
+		Namespace: namespace,
+		Name:      *progressName,
+		Shard:     *shardIndex,
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-inferenceservice is a kubectl plugin running the common operations on InferenceServices, installed on the
+// PATH it is run as `kubectl inferenceservice`
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/cli"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	kubeconfig string
+	namespace  string
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:          "kubectl-inferenceservice",
+		Short:        "Deploy, inspect and roll out InferenceServices",
+		SilenceUsage: true,
+	}
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig, the default kubeconfig when empty")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "Namespace of the InferenceService, the namespace of the current context when empty")
+	rootCmd.AddCommand(deployCmd(), getCmd(), logsCmd(), predictCmd(false), predictCmd(true), promoteCmd(), rollbackCmd())
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newClient creates the client of the namespace with the kubeconfig, as kubectl does
+func newClient() (*cli.Client, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	ns, _, err := config.Namespace()
+	if err != nil {
+		return nil, err
+	}
+	if namespace != "" {
+		ns = namespace
+	}
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	serving, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	kube, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &cli.Client{
+		Serving:   serving.ServingV1beta1(),
+		Kube:      kube,
+		HTTP:      &http.Client{Timeout: time.Minute},
+		Namespace: ns,
+	}, nil
+}
+
+func deployCmd() *cobra.Command {
+	opts := cli.DeployOptions{}
+	var minReplicas int
+	var canaryTrafficPercent int64
+	cmd := &cobra.Command{
+		Use:   "deploy NAME --framework FRAMEWORK --model-uri URI",
+		Short: "Deploy a model, or roll it out to the predictor of an existing InferenceService",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			opts.Name = args[0]
+			if cmd.Flags().Changed("min-replicas") {
+				opts.MinReplicas = &minReplicas
+			}
+			if cmd.Flags().Changed("canary-traffic-percent") {
+				opts.CanaryTrafficPercent = &canaryTrafficPercent
+			}
+			isvc, err := c.Deploy(opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "inferenceservice/%s deployed\n", isvc.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Framework, "framework", "", "Framework of the model, one of: ["+strings.Join(cli.Frameworks, ", ")+"]")
+	cmd.Flags().StringVar(&opts.ModelURI, "model-uri", "", "URI of the model")
+	cmd.Flags().StringVar(&opts.RuntimeVersion, "runtime-version", "", "Version of the model server, the default version of the framework when empty")
+	cmd.Flags().IntVar(&minReplicas, "min-replicas", 1, "Minimum number of replicas of the predictor, 0 scales it to zero")
+	cmd.Flags().Int64Var(&canaryTrafficPercent, "canary-traffic-percent", 0, "Percentage of the traffic of the new revision of an existing InferenceService, all the traffic when unset")
+	cmd.MarkFlagRequired("framework")
+	cmd.MarkFlagRequired("model-uri")
+	return cmd
+}
+
+func getCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get NAME",
+		Short: "Show the status of an InferenceService with the revisions and the traffic of each component",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			isvc, err := c.Serving.InferenceServices(c.Namespace).Get(args[0], metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			return cli.PrintStatus(cmd.OutOrStdout(), isvc)
+		},
+	}
+}
+
+func logsCmd() *cobra.Command {
+	opts := cli.LogsOptions{}
+	var component string
+	var tail int64
+	cmd := &cobra.Command{
+		Use:   "logs NAME",
+		Short: "Print the logs of the pods of a component",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			opts.Name = args[0]
+			opts.Component = v1beta1.ComponentType(component)
+			if tail >= 0 {
+				opts.TailLines = &tail
+			}
+			return c.Logs(cmd.OutOrStdout(), opts)
+		},
+	}
+	cmd.Flags().StringVarP(&component, "component", "c", string(v1beta1.PredictorComponent), "Component of the pods: predictor, transformer or explainer")
+	cmd.Flags().StringVar(&opts.Container, "container", "", "Container of the pods, the model server container when empty")
+	cmd.Flags().BoolVarP(&opts.Follow, "follow", "f", false, "Stream the logs")
+	cmd.Flags().Int64Var(&tail, "tail", -1, "Number of recent lines of each pod, all the lines when negative")
+	return cmd
+}
+
+// predictCmd returns the predict command, or the explain command sending the request to the explain endpoint
+func predictCmd(explain bool) *cobra.Command {
+	opts := cli.PredictOptions{Explain: explain}
+	var file, component string
+	cmd := &cobra.Command{
+		Use:   "predict NAME --data FILE",
+		Short: "Send a test prediction to an InferenceService",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			opts.Name = args[0]
+			opts.Component = v1beta1.ComponentType(component)
+			if file == "-" {
+				opts.Request, err = ioutil.ReadAll(cmd.InOrStdin())
+			} else {
+				opts.Request, err = ioutil.ReadFile(file)
+			}
+			if err != nil {
+				return err
+			}
+			response, err := c.Predict(opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(response))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "data", "d", "-", "File of the request body, the standard input when -")
+	cmd.Flags().StringVar(&opts.Ingress, "ingress", "", "Address of the ingress gateway, e.g. http://localhost:8080, the URL of the InferenceService when empty")
+	cmd.Flags().StringVarP(&component, "component", "c", "", "Component to send the request to, e.g. the predictor behind a transformer, the InferenceService when empty")
+	cmd.Flags().StringVar(&opts.Token, "token", "", "Bearer token of the request, e.g. the JWT of an InferenceService with auth")
+	cmd.Flags().StringVar(&opts.APIKey, "api-key", "", "API key of the request of an InferenceService with API keys")
+	if explain {
+		cmd.Use = "explain NAME --data FILE"
+		cmd.Short = "Send a test explanation request to an InferenceService"
+	}
+	return cmd
+}
+
+func promoteCmd() *cobra.Command {
+	var component string
+	cmd := &cobra.Command{
+		Use:   "promote NAME",
+		Short: "Route all the traffic to the latest revision, ending a canary rollout or a rollback",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			if _, err := c.Promote(args[0], v1beta1.ComponentType(component)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "inferenceservice/%s promoted\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&component, "component", "c", "", "Component to promote, all the components when empty")
+	return cmd
+}
+
+func rollbackCmd() *cobra.Command {
+	var component string
+	cmd := &cobra.Command{
+		Use:   "rollback NAME",
+		Short: "Pin all the traffic to the previous ready revision, ending a canary rollout",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient()
+			if err != nil {
+				return err
+			}
+			if _, err := c.Rollback(args[0], v1beta1.ComponentType(component)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "inferenceservice/%s rolled back\n", args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&component, "component", "c", "", "Component to roll back, all the components when empty")
+	return cmd
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
@@ -29,6 +31,12 @@ var (
 	inferenceService = flag.String("inference-service", "", "The InferenceService name to add as header to log events")
 	namespace        = flag.String("namespace", "", "The namespace to add as header to log events")
 	endpoint         = flag.String("endpoint", "", "The endpoint name to add as header to log events")
+	allowedDomains   = flag.String("allowed-sink-domains", "", "Comma separated list of domains the log-url is restricted to")
+	explainerUrl     = flag.String("explainer-url", "", "The explainer URL to send sampled predict requests to")
+	explainSampling  = flag.Int("explain-sampling-percent", 0, "Percentage of predict requests to send to the explainer")
+	drainDelay       = flag.Int("drain-delay", 0, "Seconds to keep serving on shutdown before draining, e.g. while the batcher drains")
+	drainTimeout     = flag.Int("drain-timeout", 10, "Seconds to wait for the in-flight requests and the queued log events on shutdown")
+	streaming        = flag.Bool("streaming", false, "Pass the chunked responses and the server-sent events through as they are read")
 )
 
 func main() {
@@ -47,6 +55,12 @@ func main() {
 		log.Info("Malformed log-url", "URL", *logUrl)
 		os.Exit(-1)
 	}
+	if *allowedDomains != "" {
+		if err := logger.ValidateSinkURL(logUrlParsed, strings.Split(*allowedDomains, ",")); err != nil {
+			log.Error(err, "Log sink violates the data residency policy")
+			os.Exit(-1)
+		}
+	}
 	loggingMode := v1alpha2.LoggerMode(*logMode)
 	switch loggingMode {
 	case v1alpha2.LogAll, v1alpha2.LogRequest, v1alpha2.LogResponse:
@@ -64,9 +78,22 @@ func main() {
 		os.Exit(-1)
 	}
 
+	var explainerUrlParsed *url.URL
+	if *explainerUrl != "" {
+		explainerUrlParsed, err = url.Parse(*explainerUrl)
+		if err != nil {
+			log.Info("Malformed explainer-url", "URL", *explainerUrl)
+			os.Exit(-1)
+		}
+	}
+	if *explainSampling < 0 || *explainSampling > 100 {
+		log.Info("explain-sampling-percent must be between 0 and 100", "percent", *explainSampling)
+		os.Exit(-1)
+	}
+
 	stopCh := signals.SetupSignalHandler()
 
-	var eh http.Handler = logger.New(log, *componentHost, *componentPort, logUrlParsed, sourceUriParsed, loggingMode, *inferenceService, *namespace, *endpoint)
+	var eh http.Handler = logger.New(log, *componentHost, *componentPort, logUrlParsed, sourceUriParsed, loggingMode, *inferenceService, *namespace, *endpoint, explainerUrlParsed, *explainSampling, *streaming)
 
 	h1s := &http.Server{
 		Addr:    ":" + *port,
@@ -93,9 +120,19 @@ func main() {
 		log.Error(err, "Failed to run HTTP server")
 	}
 
-	err = h1s.Shutdown(context.Background())
+	// The requests are served until the sidecars before the logger are drained, the in-flight requests and then the
+	// log events they queued are flushed within the drain timeout
+	time.Sleep(time.Duration(*drainDelay) * time.Second)
+	log.Info("Draining the in-flight requests and the queued log events", "timeout", *drainTimeout)
+	deadline := time.Now().Add(time.Duration(*drainTimeout) * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	err = h1s.Shutdown(ctx)
 	if err != nil {
 		log.Error(err, "Failed to shutdown HTTP server")
 	}
+	if !logger.Flush(time.Until(deadline)) {
+		log.Info("Log events still queued at the drain timeout")
+	}
 
 }
@@ -18,16 +18,40 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
+	"github.com/kubeflow/kfserving/pkg/catalog"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	batchinferencejobcontroller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/batchinferencejob"
 	v1beta1controller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/federation"
+	servingquotacontroller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/servingquota"
 	trainedmodelcontroller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/trainedmodel"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/trainedmodel/reconcilers/modelconfig"
+	warmpoolcontroller "github.com/kubeflow/kfserving/pkg/controller/v1beta1/warmpool"
+	"github.com/kubeflow/kfserving/pkg/health"
+	"github.com/kubeflow/kfserving/pkg/idlereaper"
+	"github.com/kubeflow/kfserving/pkg/modelmetadata"
+	"github.com/kubeflow/kfserving/pkg/scalingschedule"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/kubeflow/kfserving/pkg/wakequeue"
+	"github.com/kubeflow/kfserving/pkg/webhook/admission/configmap"
 	"github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	"github.com/kubeflow/kfserving/pkg/webhook/admission/servingquota"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -36,6 +60,7 @@ import (
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
@@ -47,9 +72,61 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+const webhookPort = 9443
+
 func main() {
 	var metricsAddr string
+	var catalogAddr string
+	var catalogCertDir string
+	var catalogPlaintext bool
+	var prometheusURL string
+	var servingMetricsInterval time.Duration
+	var servingMetricsWindow time.Duration
+	var idleReaperInterval time.Duration
+	var modelMetadataInterval time.Duration
+	var auditSink string
+	var scalingScheduleInterval time.Duration
+	var wakeQueueInterval time.Duration
+	var shards int
+	var shardNamespaceSelector string
+	var shardLease string
+	var enableLeaderElection bool
+	var enableFederation bool
+	var leaderElectionID string
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var probeAddr string
+	var mutationDiffVerbosity int
+	var reconcileLoopThreshold int
+	var reconcileLoopWindow time.Duration
+	var reconcileLoopDamping time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&catalogAddr, "catalog-addr", ":8082", "The address the serving catalog endpoint binds to, empty to disable the catalog.")
+	flag.StringVar(&catalogCertDir, "catalog-cert-dir", "/tmp/k8s-webhook-server/serving-certs", "The directory of the tls.crt and tls.key the serving catalog is served with over TLS.")
+	flag.BoolVar(&catalogPlaintext, "catalog-plaintext", false, "Serve the catalog over plain HTTP, only behind an Istio sidecar terminating the mutual TLS of the mesh. The catalog then binds to the loopback address the sidecar forwards the requests from, the requests not received over mutual TLS are rejected.")
+	flag.StringVar(&prometheusURL, "prometheus-url", "", "The URL of the Prometheus server the serving metrics of the inference services are aggregated from and the canaries are analyzed with, empty to disable the aggregation and the canary analysis.")
+	flag.DurationVar(&servingMetricsInterval, "serving-metrics-interval", time.Minute, "The interval between the aggregations of the serving metrics.")
+	flag.DurationVar(&servingMetricsWindow, "serving-metrics-window", 5*time.Minute, "The time range of the aggregated request and error rates.")
+	flag.DurationVar(&idleReaperInterval, "idle-reaper-interval", time.Minute, "The interval between the checks of the inference services idle for longer than their TTL after the last request.")
+	flag.DurationVar(&modelMetadataInterval, "model-metadata-interval", time.Minute, "The interval between the reads of the model metadata of the ready v2 inference services into their status, 0 to disable the reads.")
+	flag.StringVar(&auditSink, "audit-sink", "", "The URL of the sink receiving the audit events of the inference services as cloud events, empty to only record them as Kubernetes events.")
+	flag.DurationVar(&scalingScheduleInterval, "scaling-schedule-interval", 30*time.Second, "The interval between the checks of the scaling windows of the inference services.")
+	flag.DurationVar(&wakeQueueInterval, "wake-queue-interval", 2*time.Second, "The interval between the wakes of the components scaled from zero held by the scale from zero queue.")
+	flag.IntVar(&shards, "shards", 0, "The number of shards the namespaces are hashed into across the replicas of the controller, 0 to not hash the namespaces.")
+	flag.StringVar(&shardNamespaceSelector, "shard-namespace-selector", "", "The label selector of the namespaces reconciled by the replicas, empty to reconcile all the namespaces.")
+	flag.StringVar(&shardLease, "shard-lease", "kfserving-controller-shard", "The name prefix of the leases of the shards.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election so that a single replica of the controller reconciles, the other replicas are standbys.")
+	flag.BoolVar(&enableFederation, "enable-federation", false, "Propagate the inference services with a federation to the member clusters, the federation of the inference services is ignored when disabled.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "kfserving-controller-manager", "The name of the ConfigMap holding the leader election lock.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second, "The duration the standbys wait before taking over the leadership of a leader which stopped renewing it.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second, "The duration the leader retries to renew the leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second, "The interval between the attempts to acquire or renew the leadership.")
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the /healthz and /readyz endpoints bind to.")
+	flag.IntVar(&mutationDiffVerbosity, "mutation-diff-verbosity", 0, "Log the changes the controller makes to the Knative services, the virtual services and the status of the inference services at 1, and record them as events of the mutated resources at 2, 0 to disable.")
+	flag.IntVar(&reconcileLoopThreshold, "reconcile-loop-threshold", 10, "The number of reconciles making the same changes to an inference service within the window detected as a reconcile loop, 0 to disable the detection.")
+	flag.DurationVar(&reconcileLoopWindow, "reconcile-loop-window", time.Minute, "The time range of the reconciles detected as a reconcile loop.")
+	flag.DurationVar(&reconcileLoopDamping, "reconcile-loop-damping", time.Minute, "The duration the reconciles of an inference service in a reconcile loop are damped.")
 	flag.Parse()
 	logf.SetLogger(logf.ZapLogger(false))
 	log := logf.Log.WithName("entrypoint")
@@ -62,9 +139,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The shards hold a lease each, a replica is the leader of its shard
+	if enableLeaderElection && (shards > 0 || shardNamespaceSelector != "") {
+		log.Error(fmt.Errorf("leader election and sharding are exclusive"), "invalid flags")
+		os.Exit(1)
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	log.Info("Setting up manager")
-	mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: metricsAddr, Port: 9443})
+	mgr, err := manager.New(cfg, manager.Options{
+		MetricsBindAddress:      metricsAddr,
+		Port:                    webhookPort,
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: constants.KFServingNamespace,
+		LeaseDuration:           &leaseDuration,
+		RenewDeadline:           &renewDeadline,
+		RetryPeriod:             &retryPeriod,
+		NewClient:               v1beta1controller.NewClient,
+	})
 	if err != nil {
 		log.Error(err, "unable to set up overall controller manager")
 		os.Exit(1)
@@ -111,12 +205,86 @@ func main() {
 		os.Exit(1)
 	}
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientSet.CoreV1().Events("")})
+	var sink *audit.Sink
+	if auditSink != "" {
+		setupLog.Info("Setting up the audit events sink", "sink", auditSink)
+		if sink, err = audit.NewSink(auditSink, ctrl.Log.WithName("Audit")); err != nil {
+			setupLog.Error(err, "unable to set up the audit events sink")
+			os.Exit(1)
+		}
+	}
+	// The namespaces are sharded when they are hashed or selected, the replica acquires the lease of its shard once the
+	// manager is started and its reconcilers wait for it, the webhooks serve meanwhile
+	stop := signals.SetupSignalHandler()
+	var controllerShard *shard.Shard
+	if shards > 0 || shardNamespaceSelector != "" {
+		selector, err := labels.Parse(shardNamespaceSelector)
+		if err != nil {
+			setupLog.Error(err, "unable to parse the shard namespace selector")
+			os.Exit(1)
+		}
+		identity, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to get the identity of the replica")
+			os.Exit(1)
+		}
+		controllerShard = &shard.Shard{
+			Client:         mgr.GetClient(),
+			Selector:       selector,
+			Count:          shards,
+			Leases:         clientSet.CoordinationV1(),
+			LeaseNamespace: constants.KFServingNamespace,
+			LeaseName:      shardLease,
+			Identity:       identity,
+		}
+		controllerShard.Lost = func() {
+			setupLog.Info("Lost the lease of the shard", "shard", controllerShard.Index)
+			os.Exit(1)
+		}
+		setupLog.Info("Setting up the shard", "shards", shards, "selector", shardNamespaceSelector)
+		if err = mgr.Add(controllerShard); err != nil {
+			setupLog.Error(err, "unable to set up the shard")
+			os.Exit(1)
+		}
+	}
+	// The mutations are logged to debug the reconcile loops fighting with other controllers, and observed to detect
+	// them
+	var mutations *audit.Mutations
+	var loopDetector *v1beta1controller.LoopDetector
+	if reconcileLoopThreshold > 0 {
+		loopDetector = v1beta1controller.NewLoopDetector(reconcileLoopThreshold, reconcileLoopWindow, reconcileLoopDamping)
+		mutations = &audit.Mutations{Observe: loopDetector.Observe}
+	}
+	if mutationDiffVerbosity > 0 {
+		if mutations == nil {
+			mutations = &audit.Mutations{}
+		}
+		mutations.Log = ctrl.Log.WithName("Mutations")
+		if mutationDiffVerbosity > 1 {
+			mutations.Recorder = eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"})
+		}
+	}
+	// The canary analysis and the serving metrics aggregation query the metrics of the Knative queue-proxy sidecars
+	var querier servingmetrics.Querier
+	if prometheusURL != "" {
+		querier = &servingmetrics.PrometheusQuerier{URL: prometheusURL, Client: &http.Client{Timeout: 10 * time.Second}}
+	}
+	var memberClusters *federation.MemberClusters
+	if enableFederation {
+		memberClusters = federation.NewMemberClusters(federation.NewMemberClient)
+	}
 	if err = (&v1beta1controller.InferenceServiceReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("v1beta1Controllers").WithName("InferenceService"),
 		Scheme: mgr.GetScheme(),
 		Recorder: eventBroadcaster.NewRecorder(
 			mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"}),
+		AuditSink:      sink,
+		Querier:        querier,
+		Shard:          controllerShard,
+		Mutations:      mutations,
+		LoopDetector:   loopDetector,
+		MemberClusters: memberClusters,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "v1beta1Controller", "InferenceService")
 		os.Exit(1)
@@ -132,16 +300,152 @@ func main() {
 		Scheme:                mgr.GetScheme(),
 		Recorder:              eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"}),
 		ModelConfigReconciler: modelconfig.NewModelConfigReconciler(mgr.GetClient(), mgr.GetScheme()),
+		Shard:                 controllerShard,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "v1beta1Controllers", "TrainedModel")
 		os.Exit(1)
 	}
 
+	//Setup WarmPool controller
+	setupLog.Info("Setting up v1beta1 WarmPool controller")
+	if err = (&warmpoolcontroller.WarmPoolReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("v1beta1Controllers").WithName("WarmPool"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"}),
+		Shard:    controllerShard,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1beta1Controllers", "WarmPool")
+		os.Exit(1)
+	}
+
+	//Setup BatchInferenceJob controller
+	setupLog.Info("Setting up v1beta1 BatchInferenceJob controller")
+	if err = (&batchinferencejobcontroller.BatchInferenceJobReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("v1beta1Controllers").WithName("BatchInferenceJob"),
+		Scheme:   mgr.GetScheme(),
+		Recorder: eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "v1beta1Controllers"}),
+		Shard:    controllerShard,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1beta1Controllers", "BatchInferenceJob")
+		os.Exit(1)
+	}
+
+	//Setup ServingQuota controller
+	setupLog.Info("Setting up v1beta1 ServingQuota controller")
+	if err = (&servingquotacontroller.ServingQuotaReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("v1beta1Controllers").WithName("ServingQuota"),
+		Scheme: mgr.GetScheme(),
+		Shard:  controllerShard,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "v1beta1Controllers", "ServingQuota")
+		os.Exit(1)
+	}
+
+	if catalogAddr != "" {
+		setupLog.Info("Setting up the serving catalog", "address", catalogAddr, "plaintext", catalogPlaintext)
+		mux := http.NewServeMux()
+		mux.Handle(catalog.Path, &catalog.Handler{
+			Client:     mgr.GetClient(),
+			Authorizer: &catalog.ReviewAuthorizer{Client: clientSet},
+		})
+		server := &catalogServer{addr: catalogAddr, handler: mux}
+		if catalogPlaintext {
+			_, port, err := net.SplitHostPort(catalogAddr)
+			if err != nil {
+				setupLog.Error(err, "unable to parse the address of the serving catalog", "address", catalogAddr)
+				os.Exit(1)
+			}
+			server.addr = net.JoinHostPort(catalog.PlaintextHost, port)
+		} else {
+			server.certDir = catalogCertDir
+		}
+		if err = mgr.Add(server); err != nil {
+			setupLog.Error(err, "unable to set up the serving catalog")
+			os.Exit(1)
+		}
+	}
+
+	if prometheusURL != "" {
+		setupLog.Info("Setting up the serving metrics aggregation", "prometheus", prometheusURL)
+		if err = mgr.Add(&servingmetrics.Aggregator{
+			Client:   mgr.GetClient(),
+			Querier:  querier,
+			Interval: servingMetricsInterval,
+			Window:   servingMetricsWindow,
+			Shard:    controllerShard,
+			Log:      ctrl.Log.WithName("ServingMetrics"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the serving metrics aggregation")
+			os.Exit(1)
+		}
+	}
+
+	if prometheusURL != "" {
+		setupLog.Info("Setting up the idle reaper", "interval", idleReaperInterval)
+		if err = mgr.Add(&idlereaper.Reaper{
+			Client:   mgr.GetClient(),
+			Querier:  querier,
+			Recorder: eventBroadcaster.NewRecorder(mgr.GetScheme(), v1.EventSource{Component: "IdleReaper"}),
+			Interval: idleReaperInterval,
+			Shard:    controllerShard,
+			Log:      ctrl.Log.WithName("IdleReaper"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the idle reaper")
+			os.Exit(1)
+		}
+	}
+
+	if modelMetadataInterval > 0 {
+		setupLog.Info("Setting up the model metadata collection", "interval", modelMetadataInterval)
+		if err = mgr.Add(&modelmetadata.Collector{
+			Client:   mgr.GetClient(),
+			HTTP:     &http.Client{Timeout: 10 * time.Second},
+			Interval: modelMetadataInterval,
+			Shard:    controllerShard,
+			Log:      ctrl.Log.WithName("ModelMetadata"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up the model metadata collection")
+			os.Exit(1)
+		}
+	}
+
+	setupLog.Info("Setting up the scaling windows", "interval", scalingScheduleInterval)
+	if err = mgr.Add(&scalingschedule.Scheduler{
+		Client:   mgr.GetClient(),
+		Interval: scalingScheduleInterval,
+		Shard:    controllerShard,
+		Log:      ctrl.Log.WithName("ScalingSchedule"),
+	}); err != nil {
+		setupLog.Error(err, "unable to set up the scaling windows")
+		os.Exit(1)
+	}
+
+	setupLog.Info("Setting up the scale from zero queue", "interval", wakeQueueInterval)
+	if err = wakequeue.IndexSchedulerName(mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to index the pods by scheduler name")
+		os.Exit(1)
+	}
+	if err = mgr.Add(&wakequeue.Queue{
+		Client:   mgr.GetClient(),
+		Reader:   mgr.GetAPIReader(),
+		Interval: wakeQueueInterval,
+		Shard:    controllerShard,
+		Log:      ctrl.Log.WithName("WakeQueue"),
+	}); err != nil {
+		setupLog.Error(err, "unable to set up the scale from zero queue")
+		os.Exit(1)
+	}
+
 	log.Info("setting up webhook server")
 	hookServer := mgr.GetWebhookServer()
 
 	log.Info("registering webhooks to the webhook server")
-	hookServer.Register("/mutate-pods", &webhook.Admission{Handler: &pod.Mutator{}})
+	hookServer.Register("/mutate-pods", &webhook.Admission{Handler: &pod.Mutator{Reader: mgr.GetAPIReader()}})
+	hookServer.Register("/validate-configmaps", &webhook.Admission{Handler: &configmap.Validator{}})
+	hookServer.Register("/validate-servingquotas", &webhook.Admission{Handler: &servingquota.Validator{Reader: mgr.GetAPIReader()}})
 
 	if err = ctrl.NewWebhookManagedBy(mgr).
 		For(&v1alpha2.InferenceService{}).
@@ -149,17 +453,76 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "v1alpha2")
 		os.Exit(1)
 	}
-	if err = ctrl.NewWebhookManagedBy(mgr).
-		For(&v1beta1.InferenceService{}).
-		Complete(); err != nil {
+	if err = (&v1beta1.InferenceService{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "v1beta1")
 		os.Exit(1)
 	}
 
+	// The replicas are ready once the informers of the reconcilers have synced and the webhook server is serving, the
+	// standbys included so that they take over with warm caches
+	log.Info("Setting up the health probes", "address", probeAddr)
+	if err = mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up the health check")
+		os.Exit(1)
+	}
+	for name, objs := range map[string][]runtime.Object{
+		"inferenceservice":  {&v1beta1.InferenceService{}, &knservingv1.Service{}},
+		"trainedmodel":      {&v1beta1.TrainedModel{}},
+		"warmpool":          {&v1beta1.WarmPool{}, &appsv1.Deployment{}, &v1.Pod{}},
+		"batchinferencejob": {&v1beta1.BatchInferenceJob{}, &batchv1.Job{}},
+		"servingquota":      {&v1beta1.ServingQuota{}, &v1beta1.InferenceService{}},
+	} {
+		check, err := health.Informers(mgr.GetCache(), objs...)
+		if err != nil {
+			setupLog.Error(err, "unable to set up the readiness check", "reconciler", name)
+			os.Exit(1)
+		}
+		if err = mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up the readiness check", "reconciler", name)
+			os.Exit(1)
+		}
+	}
+	if err = mgr.AddReadyzCheck("webhook", health.Webhook(fmt.Sprintf("localhost:%d", webhookPort), time.Second)); err != nil {
+		setupLog.Error(err, "unable to set up the readiness check", "check", "webhook")
+		os.Exit(1)
+	}
+
 	// Start the Cmd
 	log.Info("Starting the Cmd.")
-	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(stop); err != nil {
 		log.Error(err, "unable to run the manager")
 		os.Exit(1)
 	}
 }
+
+// catalogServer serves the catalog until the manager stops, on all the replicas as the catalog only reads
+type catalogServer struct {
+	addr    string
+	handler http.Handler
+	// certDir is the directory of the certificate of the catalog, the catalog is served over plain HTTP when empty
+	certDir string
+}
+
+// Start serves the catalog until the manager stops
+func (c *catalogServer) Start(stop <-chan struct{}) error {
+	server := &http.Server{Addr: c.addr, Handler: c.handler}
+	errs := make(chan error, 1)
+	go func() {
+		if c.certDir == "" {
+			errs <- server.ListenAndServe()
+			return
+		}
+		errs <- server.ListenAndServeTLS(filepath.Join(c.certDir, "tls.crt"), filepath.Join(c.certDir, "tls.key"))
+	}()
+	select {
+	case <-stop:
+		return server.Close()
+	case err := <-errs:
+		return err
+	}
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface, the catalog is served by the standbys too
+func (c *catalogServer) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/migrate"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	namespace           = flag.String("namespace", "", "Namespace of the InferenceServices to rewrite, all the namespaces when empty")
+	dryRun              = flag.Bool("dry-run", false, "List the InferenceServices to rewrite without rewriting them")
+	pruneStoredVersions = flag.Bool("prune-stored-versions", true, "Prune v1alpha2 from the stored versions of the CRD once all the InferenceServices are rewritten")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("migrate")
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "Failed to get the kubeconfig")
+		os.Exit(1)
+	}
+	if err := v1beta1.AddToScheme(scheme.Scheme); err != nil {
+		log.Error(err, "Failed to add the v1beta1 InferenceService to the scheme")
+		os.Exit(1)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		log.Error(err, "Failed to create the client")
+		os.Exit(1)
+	}
+
+	m := &migrate.Migrator{
+		Client:    c,
+		Log:       log,
+		Namespace: *namespace,
+		DryRun:    *dryRun,
+	}
+	migrated, err := m.Migrate(context.Background())
+	if err != nil {
+		log.Error(err, "Failed to rewrite the InferenceServices", "rewritten", migrated)
+		os.Exit(1)
+	}
+	log.Info("Rewrote the InferenceServices", "rewritten", migrated)
+
+	// The stored versions can only be pruned once the InferenceServices of all the namespaces are rewritten
+	if !*pruneStoredVersions || *namespace != "" {
+		return
+	}
+	if err := m.PruneStoredVersions(context.Background()); err != nil {
+		log.Error(err, "Failed to prune the stored versions of the CRD")
+		os.Exit(1)
+	}
+}
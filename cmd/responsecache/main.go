@@ -0,0 +1,85 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/responsecache"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	port          = flag.String("port", "9086", "Port of the response cache")
+	config        = flag.String("config", "", "JSON configuration of the response cache")
+	keyPrefix     = flag.String("key-prefix", "kfserving:cache:", "Prefix of the Redis keys of the cached responses")
+	componentHost = flag.String("component-host", "127.0.0.1", "Component host")
+	componentPort = flag.String("component-port", "8080", "Component port")
+	drainDelay    = flag.Int("drain-delay", 0, "Seconds to keep accepting requests on shutdown before draining the requests sent to the component")
+	drainTimeout  = flag.Int("drain-timeout", 10, "Seconds to wait for the requests sent to the component on shutdown")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("responsecache")
+
+	spec := &v1beta1.ResponseCacheSpec{}
+	if err := json.Unmarshal([]byte(*config), spec); err != nil {
+		log.Error(err, "Invalid response cache configuration", "config", *config)
+		os.Exit(1)
+	}
+	ttl := time.Duration(spec.GetTTLSeconds()) * time.Second
+	var store responsecache.Store
+	switch spec.GetBackend() {
+	case v1beta1.ResponseCacheRedisBackend:
+		store = responsecache.NewRedisStore(spec.RedisAddress, *keyPrefix, ttl)
+	default:
+		store = responsecache.NewMemoryStore(spec.GetMaxEntries(), ttl)
+	}
+	cache := &responsecache.Cache{
+		ComponentURL: "http://" + *componentHost + ":" + *componentPort,
+		Store:        store,
+		Log:          log,
+	}
+
+	server := &http.Server{Addr: ":" + *port, Handler: cache}
+	go func() {
+		log.Info("Starting", "Port", *port, "backend", spec.GetBackend(), "ttl", ttl)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "Failed to serve the response cache")
+			os.Exit(1)
+		}
+	}()
+
+	<-signals.SetupSignalHandler()
+	log.Info("Draining the requests sent to the component", "delay", *drainDelay, "timeout", *drainTimeout)
+	time.Sleep(time.Duration(*drainDelay) * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*drainTimeout)*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Info("Requests still sent to the component at the drain timeout")
+	}
+}
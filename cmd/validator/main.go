@@ -0,0 +1,80 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/requestvalidation"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	port          = flag.String("port", "9085", "Port of the validator")
+	config        = flag.String("config", "", "JSON configuration of the request validation")
+	componentHost = flag.String("component-host", "127.0.0.1", "Component host")
+	componentPort = flag.String("component-port", "8080", "Component port")
+	drainDelay    = flag.Int("drain-delay", 0, "Seconds to keep accepting requests on shutdown before draining the requests sent to the predictor")
+	drainTimeout  = flag.Int("drain-timeout", 10, "Seconds to wait for the requests sent to the predictor on shutdown")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("validator")
+
+	spec := &v1beta1.RequestValidationSpec{}
+	if err := json.Unmarshal([]byte(*config), spec); err != nil {
+		log.Error(err, "Invalid request validation configuration", "config", *config)
+		os.Exit(1)
+	}
+	// The rejected requests are answered with the reason of the error, not with the schema and the request
+	openapi3.SchemaErrorDetailsDisabled = true
+	validator, err := requestvalidation.NewValidator(spec, "http://"+*componentHost+":"+*componentPort,
+		&http.Client{Timeout: 10 * time.Second}, log)
+	if err != nil {
+		log.Error(err, "Invalid request validation schema")
+		os.Exit(1)
+	}
+
+	server := &http.Server{Addr: ":" + *port, Handler: validator}
+	go func() {
+		log.Info("Starting", "Port", *port, "schema", validator.Schema != nil, "modelMetadata", validator.ModelMetadata)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "Failed to serve the validator")
+			os.Exit(1)
+		}
+	}()
+
+	<-signals.SetupSignalHandler()
+	log.Info("Draining the requests sent to the predictor", "delay", *drainDelay, "timeout", *drainTimeout)
+	time.Sleep(time.Duration(*drainDelay) * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*drainTimeout)*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Info("Requests still sent to the predictor at the drain timeout")
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/warmup"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+)
+
+var (
+	port        = flag.String("port", "9083", "Port of the readiness endpoint of the warmup")
+	config      = flag.String("config", "", "JSON configuration of the warmup")
+	serverURL   = flag.String("server-url", "http://localhost:8080", "URL of the model server")
+	requestsDir = flag.String("requests-dir", "", "Directory of the request files, empty without request files")
+)
+
+func main() {
+	flag.Parse()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("warmup")
+
+	warmer := &warmup.Warmer{
+		ServerURL:    *serverURL,
+		RequestsDir:  *requestsDir,
+		PollInterval: time.Second,
+		Client:       &http.Client{},
+		Log:          log,
+	}
+	if err := json.Unmarshal([]byte(*config), &warmer.Config); err != nil {
+		log.Error(err, "Invalid warmup configuration", "config", *config)
+		os.Exit(1)
+	}
+
+	http.Handle("/ready", warmer)
+	go func() {
+		log.Info("Starting", "Port", *port)
+		if err := http.ListenAndServe(":"+*port, nil); err != nil {
+			log.Error(err, "Failed to serve the readiness of the warmup")
+			os.Exit(1)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCh := signals.SetupSignalHandler()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	if err := warmer.Run(ctx); err != nil {
+		log.Error(err, "Warmup failed, the pod is not ready")
+	}
+	<-stopCh
+}
@@ -84,6 +84,8 @@ DNS.2 = ${service}.${namespace}
 DNS.3 = ${service}.${namespace}.svc
 DNS.4 = ${service}.${namespace}.svc.cluster
 DNS.5 = ${service}.${namespace}.svc.cluster.local
+DNS.6 = kfserving-catalog.${namespace}.svc
+DNS.7 = kfserving-catalog.${namespace}.svc.cluster.local
 
 EOF
 # Create CA and Server key/certificate
@@ -117,7 +119,7 @@ echo "Encoded CA:"
 echo -e "${caBundle} \n"
 
 # Patch CA Certificate to webhooks
-mutatingPatchString='[{"op": "replace", "path": "/webhooks/0/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}, {"op": "replace", "path": "/webhooks/1/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}]'
+mutatingPatchString='[{"op": "replace", "path": "/webhooks/0/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}, {"op": "replace", "path": "/webhooks/1/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}, {"op": "replace", "path": "/webhooks/2/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}, {"op": "replace", "path": "/webhooks/3/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}]'
 mutatingPatchString=$(echo ${mutatingPatchString} | sed "s|{{CA_BUNDLE}}|${caBundle}|g")
 validatingPatchString='[{"op": "replace", "path": "/webhooks/0/clientConfig/caBundle", "value":"{{CA_BUNDLE}}"}]'
 validatingPatchString=$(echo ${validatingPatchString} | sed "s|{{CA_BUNDLE}}|${caBundle}|g")
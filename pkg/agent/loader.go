@@ -0,0 +1,117 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sync"
+	"time"
+)
+
+// Loader loads and unloads models on the model server through the v2 model repository API,
+// e.g. Triton started with --model-control-mode=explicit.
+type Loader struct {
+	ModelServerUrl string
+	Client         *http.Client
+	// loaded tracks the models loaded onto the model server, unloaded on shutdown
+	mu     sync.Mutex
+	loaded map[string]bool
+}
+
+func NewLoader(modelServerUrl string) *Loader {
+	return &Loader{
+		ModelServerUrl: modelServerUrl,
+		Client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		loaded: map[string]bool{},
+	}
+}
+
+func (l *Loader) LoadModel(modelName string) error {
+	if err := l.post(fmt.Sprintf("%s/v2/repository/models/%s/load", l.ModelServerUrl, modelName)); err != nil {
+		return err
+	}
+	l.setLoaded(modelName, true)
+	return nil
+}
+
+func (l *Loader) UnloadModel(modelName string) error {
+	if err := l.post(fmt.Sprintf("%s/v2/repository/models/%s/unload", l.ModelServerUrl, modelName)); err != nil {
+		return err
+	}
+	l.setLoaded(modelName, false)
+	return nil
+}
+
+// UnloadAll unloads the loaded models from the model server on shutdown, the models which are not unloaded
+// within the timeout are left to the termination of the model server. Returns false on timeout.
+func (l *Loader) UnloadAll(timeout time.Duration) bool {
+	log := logf.Log.WithName("Loader")
+	deadline := time.Now().Add(timeout)
+	for _, modelName := range l.loadedModels() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		if err := l.UnloadModel(modelName); err != nil {
+			log.Error(err, "Failed to Unload model on shutdown", "modelName", modelName)
+		} else {
+			log.Info("Unloaded model on shutdown", "modelName", modelName)
+		}
+	}
+	return true
+}
+
+func (l *Loader) setLoaded(modelName string, loaded bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loaded == nil {
+		l.loaded = map[string]bool{}
+	}
+	if loaded {
+		l.loaded[modelName] = true
+	} else {
+		delete(l.loaded, modelName)
+	}
+}
+
+func (l *Loader) loadedModels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	models := make([]string, 0, len(l.loaded))
+	for modelName := range l.loaded {
+		models = append(models, modelName)
+	}
+	return models
+}
+
+func (l *Loader) post(url string) error {
+	resp, err := l.Client.Post(url, "application/json", bytes.NewBufferString("{}"))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("model server returned %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const tritonConfig = `
+name: "mnist"
+platform: "onnxruntime_onnx"
+max_batch_size: 8 # batching adds the -1 dim
+input [
+  {
+    name: "Input3"
+    data_type: TYPE_FP32
+    dims: [ 1, 28, 28 ]
+  }
+]
+output {
+  name: "Plus214_Output_0"
+  data_type: TYPE_FP32
+  dims: [ 10 ]
+}
+output {
+  name: "label"
+  data_type: TYPE_STRING
+  dims: 1
+}
+`
+
+const mlflowColumnModel = `
+artifact_path: model
+flavors:
+  sklearn:
+    pickled_model: model.pkl
+    sklearn_version: 0.23.1
+signature:
+  inputs: '[{"name": "sepal length (cm)", "type": "double"}, {"name": "species", "type": "string"}]'
+  outputs: '[{"type": "long"}]'
+`
+
+const mlflowTensorModel = `
+flavors:
+  keras: {}
+signature:
+  inputs: '[{"name": "images", "type": "tensor", "tensor-spec": {"dtype": "float32", "shape": [-1, 28, 28]}}]'
+  outputs: '[{"type": "tensor", "tensor-spec": {"dtype": "<U8", "shape": [-1]}}]'
+`
+
+type testTensor struct {
+	name  string
+	dtype uint64
+	dims  []int64
+}
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// savedModel encodes a saved model with a meta graph per tag and a signature per name
+func savedModel(tags []string, signatures map[string][2][]testTensor) []byte {
+	var model []byte
+	for _, tag := range tags {
+		var metaGraph []byte
+		metaGraph = appendMessage(metaGraph, metaGraphDefMetaInfoDef, appendString(nil, metaInfoDefTags, tag))
+		for name, tensors := range signatures {
+			var signature []byte
+			for i, num := range []protowire.Number{signatureDefInputs, signatureDefOutputs} {
+				for _, tensor := range tensors[i] {
+					var shape []byte
+					for _, dim := range tensor.dims {
+						shape = appendMessage(shape, tensorShapeDim, appendVarint(nil, tensorShapeDimSize, uint64(dim)))
+					}
+					if tensor.dims == nil {
+						shape = appendVarint(shape, tensorShapeUnknownRank, 1)
+					}
+					info := appendString(nil, 1, tensor.name+":0")
+					info = appendVarint(info, tensorInfoDtype, tensor.dtype)
+					info = appendMessage(info, tensorInfoTensorShape, shape)
+					entry := appendString(nil, mapEntryKey, tensor.name)
+					entry = appendMessage(entry, mapEntryValue, info)
+					signature = appendMessage(signature, num, entry)
+				}
+			}
+			signature = appendString(signature, 3, "tensorflow/serving/predict")
+			entry := appendString(nil, mapEntryKey, name)
+			entry = appendMessage(entry, mapEntryValue, signature)
+			metaGraph = appendMessage(metaGraph, metaGraphDefSignatureDef, entry)
+		}
+		model = appendMessage(model, savedModelMetaGraphs, metaGraph)
+	}
+	return appendVarint(model, 1, 1)
+}
+
+func writeFiles(t *testing.T, files map[string][]byte) string {
+	dir, err := ioutil.TempDir("", "metadata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRead(t *testing.T) {
+	scenarios := map[string]struct {
+		files    map[string][]byte
+		expected *v1beta1.ModelMetadata
+	}{
+		"TritonConfig": {
+			files: map[string][]byte{
+				"config.pbtxt":      []byte(tritonConfig),
+				"1/model.onnx":      []byte("onnx"),
+				"2/saved_model.pb":  savedModel([]string{"serve"}, nil),
+				"ignored/README.md": []byte("readme"),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: "onnxruntime_onnx",
+				Inputs:   []v1beta1.TensorMetadata{{Name: "Input3", Datatype: "FP32", Shape: []int64{-1, 1, 28, 28}}},
+				Outputs: []v1beta1.TensorMetadata{
+					{Name: "Plus214_Output_0", Datatype: "FP32", Shape: []int64{-1, 10}},
+					{Name: "label", Datatype: "BYTES", Shape: []int64{-1, 1}},
+				},
+			},
+		},
+		"MLflowColumnSignature": {
+			files: map[string][]byte{
+				"MLmodel":   []byte(mlflowColumnModel),
+				"model.pkl": []byte("pickle"),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: MLflowPlatform,
+				Inputs: []v1beta1.TensorMetadata{
+					{Name: "sepal length (cm)", Datatype: "FP64", Shape: []int64{-1}},
+					{Name: "species", Datatype: "BYTES", Shape: []int64{-1}},
+				},
+				Outputs: []v1beta1.TensorMetadata{{Name: "output-0", Datatype: "INT64", Shape: []int64{-1}}},
+			},
+		},
+		"MLflowTensorSignature": {
+			files: map[string][]byte{
+				"MLmodel": []byte(mlflowTensorModel),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: MLflowPlatform,
+				Inputs:   []v1beta1.TensorMetadata{{Name: "images", Datatype: "FP32", Shape: []int64{-1, 28, 28}}},
+				Outputs:  []v1beta1.TensorMetadata{{Name: "output-0", Datatype: "BYTES", Shape: []int64{-1}}},
+			},
+		},
+		"SavedModelLatestVersion": {
+			files: map[string][]byte{
+				"9/saved_model.pb": savedModel([]string{"serve"}, map[string][2][]testTensor{
+					"serving_default": {{{name: "old", dtype: 1, dims: []int64{-1}}}, nil},
+				}),
+				"10/saved_model.pb": savedModel([]string{"train", "serve"}, map[string][2][]testTensor{
+					"__saved_model_init_op": {nil, {{name: "init", dtype: 1}}},
+					"predict":               {{{name: "ignored", dtype: 1, dims: []int64{1}}}, nil},
+					"serving_default": {
+						{{name: "x", dtype: 1, dims: []int64{-1, 3}}, {name: "key", dtype: 7, dims: []int64{-1}}},
+						{{name: "scores", dtype: 2, dims: []int64{-1, 2}}, {name: "ids", dtype: 9}},
+					},
+				}),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: TensorflowPlatform,
+				Inputs: []v1beta1.TensorMetadata{
+					{Name: "key", Datatype: "BYTES", Shape: []int64{-1}},
+					{Name: "x", Datatype: "FP32", Shape: []int64{-1, 3}},
+				},
+				Outputs: []v1beta1.TensorMetadata{
+					{Name: "ids", Datatype: "INT64", Shape: []int64{}},
+					{Name: "scores", Datatype: "FP64", Shape: []int64{-1, 2}},
+				},
+			},
+		},
+		"SavedModelFirstSignature": {
+			files: map[string][]byte{
+				"saved_model.pb": savedModel([]string{"serve"}, map[string][2][]testTensor{
+					"predict_b": {{{name: "b", dtype: 3, dims: []int64{2}}}, nil},
+					"predict_a": {{{name: "a", dtype: 19, dims: []int64{1}}}, nil},
+				}),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: TensorflowPlatform,
+				Inputs:   []v1beta1.TensorMetadata{{Name: "a", Datatype: "FP16", Shape: []int64{1}}},
+			},
+		},
+		"TritonConfigWithoutTensors": {
+			files: map[string][]byte{
+				"config.pbtxt": []byte(`backend: "python"`),
+				"MLmodel":      []byte(mlflowTensorModel),
+			},
+			expected: &v1beta1.ModelMetadata{
+				Platform: MLflowPlatform,
+				Inputs:   []v1beta1.TensorMetadata{{Name: "images", Datatype: "FP32", Shape: []int64{-1, 28, 28}}},
+				Outputs:  []v1beta1.TensorMetadata{{Name: "output-0", Datatype: "BYTES", Shape: []int64{-1}}},
+			},
+		},
+		"NoSignature": {
+			files: map[string][]byte{
+				"model.joblib": []byte("joblib"),
+				"a/b/c/saved_model.pb": savedModel([]string{"serve"}, map[string][2][]testTensor{
+					"serving_default": {{{name: "x", dtype: 1, dims: []int64{1}}}, nil},
+				}),
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			dir := writeFiles(t, scenario.files)
+			defer os.RemoveAll(dir)
+			metadata, err := Read(dir)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(metadata).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	scenarios := map[string]struct {
+		files         map[string][]byte
+		expectedError string
+	}{
+		"TritonConfigSyntax": {
+			files:         map[string][]byte{"config.pbtxt": []byte(`input [ { name: "x" ]`)},
+			expectedError: `unexpected "]", expecting a field name`,
+		},
+		"TritonConfigDims": {
+			files:         map[string][]byte{"config.pbtxt": []byte(`input { name: "x" dims: [ a ] }`)},
+			expectedError: "invalid dims of tensor x",
+		},
+		"MLflowColumnType": {
+			files:         map[string][]byte{"MLmodel": []byte(`signature: {inputs: '[{"name": "x", "type": "decimal"}]'}`)},
+			expectedError: "unsupported type decimal of column x",
+		},
+		"SavedModelDtype": {
+			files: map[string][]byte{"saved_model.pb": savedModel([]string{"serve"}, map[string][2][]testTensor{
+				"serving_default": {{{name: "x", dtype: 8, dims: []int64{1}}}, nil},
+			})},
+			expectedError: "unsupported dtype 8",
+		},
+		"SavedModelWireFormat": {
+			files:         map[string][]byte{"saved_model.pb": {0x12, 0x05, 0x01}},
+			expectedError: "invalid saved model",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			dir := writeFiles(t, scenario.files)
+			defer os.RemoveAll(dir)
+			_, err := Read(dir)
+			g.Expect(err).To(gomega.HaveOccurred())
+			g.Expect(err.Error()).To(gomega.ContainSubstring(scenario.expectedError))
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	trainedModel := &v1beta1.TrainedModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "mnist", Namespace: "default"},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, trainedModel)
+	store := NewStore()
+	store.StatusUpdater = &StatusUpdater{Client: cl, Namespace: "default"}
+	metadata := &v1beta1.ModelMetadata{
+		Platform: TensorflowPlatform,
+		Inputs:   []v1beta1.TensorMetadata{{Name: "x", Datatype: "FP32", Shape: []int64{-1, 784}}},
+	}
+	store.Set("mnist", metadata)
+	// The models without trained model are served on the endpoint only
+	store.Set("iris", metadata)
+
+	actual := &v1beta1.TrainedModel{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "mnist", Namespace: "default"}, actual)).To(gomega.Succeed())
+	g.Expect(actual.Status.Metadata).To(gomega.Equal(metadata))
+
+	scenarios := map[string]struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		"Metadata": {
+			method:       http.MethodGet,
+			path:         "/v2/models/mnist",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"mnist","platform":"tensorflow_savedmodel","inputs":[{"name":"x","datatype":"FP32","shape":[-1,784]}]}`,
+		},
+		"UnknownModel": {
+			method:       http.MethodGet,
+			path:         "/v2/models/unknown",
+			expectedCode: http.StatusNotFound,
+		},
+		"OtherPath": {
+			method:       http.MethodGet,
+			path:         "/v2/models/mnist/ready",
+			expectedCode: http.StatusNotFound,
+		},
+		"MethodNotAllowed": {
+			method:       http.MethodPost,
+			path:         "/v2/models/mnist",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			recorder := httptest.NewRecorder()
+			store.ServeHTTP(recorder, httptest.NewRequest(scenario.method, scenario.path, nil))
+			g.Expect(recorder.Code).To(gomega.Equal(scenario.expectedCode))
+			if scenario.expectedBody != "" {
+				g.Expect(recorder.Body.String()).To(gomega.MatchJSON(scenario.expectedBody))
+			}
+		})
+	}
+
+	store.Delete("mnist")
+	_, ok := store.Get("mnist")
+	g.Expect(ok).To(gomega.BeFalse())
+}
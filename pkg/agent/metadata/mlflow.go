@@ -0,0 +1,153 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const mlflowTensorType = "tensor"
+
+// mlflowModel is the MLmodel file, the signature inputs and outputs are json strings
+type mlflowModel struct {
+	Signature *struct {
+		Inputs  string `json:"inputs"`
+		Outputs string `json:"outputs"`
+	} `json:"signature,omitempty"`
+}
+
+// mlflowSpec is a column of a column based signature or a tensor of a tensor based signature
+type mlflowSpec struct {
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type"`
+	TensorSpec *struct {
+		Dtype string  `json:"dtype"`
+		Shape []int64 `json:"shape"`
+	} `json:"tensor-spec,omitempty"`
+}
+
+// mlflowColumnTypes maps the column types of the MLflow schemas to the v2 datatypes
+var mlflowColumnTypes = map[string]string{
+	"boolean":  "BOOL",
+	"integer":  "INT32",
+	"long":     "INT64",
+	"float":    "FP32",
+	"double":   "FP64",
+	"string":   "BYTES",
+	"binary":   "BYTES",
+	"datetime": "BYTES",
+}
+
+// numpyDatatypes maps the numpy dtypes of the MLflow tensor specs to the v2 datatypes
+var numpyDatatypes = map[string]string{
+	"bool":    "BOOL",
+	"int8":    "INT8",
+	"int16":   "INT16",
+	"int32":   "INT32",
+	"int64":   "INT64",
+	"uint8":   "UINT8",
+	"uint16":  "UINT16",
+	"uint32":  "UINT32",
+	"uint64":  "UINT64",
+	"float16": "FP16",
+	"float32": "FP32",
+	"float64": "FP64",
+	"object":  "BYTES",
+	"str":     "BYTES",
+	"bytes":   "BYTES",
+}
+
+// readMLflowModel reads the signature of the MLmodel file, the columns of a column based signature are mapped to
+// tensors of variable size.
+func readMLflowModel(path string) (*v1beta1.ModelMetadata, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MLmodel %s: %v", path, err)
+	}
+	model := &mlflowModel{}
+	if err := json.Unmarshal(jsonData, model); err != nil {
+		return nil, fmt.Errorf("invalid MLmodel %s: %v", path, err)
+	}
+	metadata := &v1beta1.ModelMetadata{Platform: MLflowPlatform}
+	if model.Signature == nil {
+		return metadata, nil
+	}
+	if metadata.Inputs, err = mlflowTensors(model.Signature.Inputs, "input"); err != nil {
+		return nil, fmt.Errorf("invalid signature inputs in MLmodel %s: %v", path, err)
+	}
+	if metadata.Outputs, err = mlflowTensors(model.Signature.Outputs, "output"); err != nil {
+		return nil, fmt.Errorf("invalid signature outputs in MLmodel %s: %v", path, err)
+	}
+	return metadata, nil
+}
+
+func mlflowTensors(schema string, defaultName string) ([]v1beta1.TensorMetadata, error) {
+	if schema == "" {
+		return nil, nil
+	}
+	var specs []mlflowSpec
+	if err := json.Unmarshal([]byte(schema), &specs); err != nil {
+		return nil, err
+	}
+	var tensors []v1beta1.TensorMetadata
+	for i, spec := range specs {
+		tensor := v1beta1.TensorMetadata{Name: spec.Name}
+		if tensor.Name == "" {
+			tensor.Name = fmt.Sprintf("%s-%d", defaultName, i)
+		}
+		if spec.Type == mlflowTensorType {
+			if spec.TensorSpec == nil {
+				return nil, fmt.Errorf("tensor %s has no tensor-spec", tensor.Name)
+			}
+			datatype, ok := numpyDatatype(spec.TensorSpec.Dtype)
+			if !ok {
+				return nil, fmt.Errorf("unsupported dtype %s of tensor %s", spec.TensorSpec.Dtype, tensor.Name)
+			}
+			tensor.Datatype = datatype
+			tensor.Shape = append([]int64{}, spec.TensorSpec.Shape...)
+		} else {
+			datatype, ok := mlflowColumnTypes[spec.Type]
+			if !ok {
+				return nil, fmt.Errorf("unsupported type %s of column %s", spec.Type, tensor.Name)
+			}
+			tensor.Datatype = datatype
+			tensor.Shape = []int64{-1}
+		}
+		tensors = append(tensors, tensor)
+	}
+	return tensors, nil
+}
+
+// numpyDatatype maps a numpy dtype to the v2 datatype, the unicode and bytes arrays (e.g. <U8, |S4) are BYTES
+func numpyDatatype(dtype string) (string, bool) {
+	dtype = strings.TrimLeft(dtype, "<>|=")
+	if strings.HasPrefix(dtype, "U") || strings.HasPrefix(dtype, "S") {
+		return "BYTES", true
+	}
+	datatype, ok := numpyDatatypes[dtype]
+	return datatype, ok
+}
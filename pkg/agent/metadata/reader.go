@@ -0,0 +1,119 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+const (
+	TritonConfigFileName         = "config.pbtxt"
+	MLflowModelFileName          = "MLmodel"
+	TensorflowSavedModelFileName = "saved_model.pb"
+	TensorflowPlatform           = "tensorflow_savedmodel"
+	MLflowPlatform               = "mlflow"
+	TritonPlatform               = "triton"
+	maxModelDirSearchDepth       = 3
+)
+
+// signatureReaders read the metadata from the signature files, in order of precedence. The Triton config comes first
+// as it describes the tensors of the model server whatever the framework of the model is.
+var signatureReaders = []struct {
+	fileName string
+	read     func(path string) (*v1beta1.ModelMetadata, error)
+}{
+	{TritonConfigFileName, readTritonConfig},
+	{MLflowModelFileName, readMLflowModel},
+	{TensorflowSavedModelFileName, readSavedModel},
+}
+
+// Read returns the metadata of the signature found in the model dir, nil when the model dir has no signature file.
+// The signature files are searched in the sub directories as well, e.g. the version dirs of TensorFlow Serving.
+func Read(modelDir string) (*v1beta1.ModelMetadata, error) {
+	files := map[string]string{}
+	err := filepath.Walk(modelDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(modelDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel != "." && strings.Count(rel, string(filepath.Separator)) >= maxModelDirSearchDepth-1 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Keep the shallowest file, the latest version of the model when at the same depth
+		if current, ok := files[info.Name()]; !ok || isPreferred(rel, current) {
+			files[info.Name()] = rel
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, reader := range signatureReaders {
+		rel, ok := files[reader.fileName]
+		if !ok {
+			continue
+		}
+		metadata, err := reader.read(filepath.Join(modelDir, rel))
+		if err != nil {
+			return nil, err
+		}
+		if len(metadata.Inputs) != 0 || len(metadata.Outputs) != 0 {
+			return metadata, nil
+		}
+	}
+	return nil, nil
+}
+
+func isPreferred(path string, current string) bool {
+	depth := strings.Count(path, string(filepath.Separator))
+	currentDepth := strings.Count(current, string(filepath.Separator))
+	if depth != currentDepth {
+		return depth < currentDepth
+	}
+	return compareNumeric(path, current) > 0
+}
+
+// compareNumeric compares the paths by their numeric prefix when both have one, lexically otherwise
+func compareNumeric(a string, b string) int {
+	na, nb := numericPrefix(a), numericPrefix(b)
+	if na != "" && nb != "" && len(na) != len(nb) {
+		if len(na) > len(nb) {
+			return 1
+		}
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+func numericPrefix(s string) string {
+	for i, c := range s {
+		if c < '0' || c > '9' {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const modelMetadataPathPrefix = "/v2/models/"
+
+var log = logf.Log.WithName("modelMetadata")
+
+// modelMetadataResponse is the response of the v2 model metadata endpoint
+type modelMetadataResponse struct {
+	Name string `json:"name"`
+	v1beta1.ModelMetadata
+}
+
+// Store keeps the metadata of the models pulled by the agent and serves it on the v2 model metadata endpoint
+// GET /v2/models/{model}.
+type Store struct {
+	mu     sync.RWMutex
+	models map[string]*v1beta1.ModelMetadata
+	// StatusUpdater reports the metadata to the status of the TrainedModels, nil to disable
+	StatusUpdater *StatusUpdater
+}
+
+func NewStore() *Store {
+	return &Store{
+		models: map[string]*v1beta1.ModelMetadata{},
+	}
+}
+
+func (s *Store) Set(modelName string, metadata *v1beta1.ModelMetadata) {
+	s.mu.Lock()
+	s.models[modelName] = metadata
+	s.mu.Unlock()
+	if s.StatusUpdater != nil {
+		if err := s.StatusUpdater.Update(modelName, metadata); err != nil {
+			log.Error(err, "Failed to update the metadata of the trained model status", "modelName", modelName)
+		}
+	}
+}
+
+func (s *Store) Get(modelName string) (*v1beta1.ModelMetadata, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	metadata, ok := s.models[modelName]
+	return metadata, ok
+}
+
+func (s *Store) Delete(modelName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.models, modelName)
+}
+
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	modelName := strings.TrimPrefix(r.URL.Path, modelMetadataPathPrefix)
+	if modelName == r.URL.Path || modelName == "" || strings.Contains(modelName, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	metadata, ok := s.Get(modelName)
+	if !ok {
+		http.Error(w, "metadata of model "+modelName+" not found", http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(&modelMetadataResponse{Name: modelName, ModelMetadata: *metadata})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// StatusUpdater patches the metadata into the status of the TrainedModel named after the model, the service account
+// of the agent needs the permission to patch trainedmodels/status in the namespace.
+type StatusUpdater struct {
+	Client    client.Client
+	Namespace string
+}
+
+// Update patches the metadata of the TrainedModel status, the models without TrainedModel are skipped
+func (u *StatusUpdater) Update(modelName string, metadata *v1beta1.ModelMetadata) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"metadata": metadata,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	trainedModel := &v1beta1.TrainedModel{}
+	if err := u.Client.Get(context.TODO(), types.NamespacedName{Name: modelName, Namespace: u.Namespace}, trainedModel); err != nil {
+		if apierr.IsNotFound(err) {
+			log.Info("No trained model for the model, skipping the status update", "modelName", modelName)
+			return nil
+		}
+		return err
+	}
+	return u.Client.Status().Patch(context.TODO(), trainedModel, client.ConstantPatch(types.MergePatchType, patch))
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	tensorflowServingTag       = "serve"
+	tensorflowServingSignature = "serving_default"
+	tensorflowInitOpSignature  = "__saved_model_init_op"
+)
+
+// Field numbers of the SavedModel protos of tensorflow/core/protobuf/{saved_model,meta_graph}.proto and
+// tensorflow/core/framework/tensor_shape.proto, the protos are decoded from the wire format to avoid depending on
+// the TensorFlow Go bindings.
+const (
+	savedModelMetaGraphs      protowire.Number = 2
+	metaGraphDefMetaInfoDef   protowire.Number = 1
+	metaGraphDefSignatureDef  protowire.Number = 5
+	metaInfoDefTags           protowire.Number = 4
+	mapEntryKey               protowire.Number = 1
+	mapEntryValue             protowire.Number = 2
+	signatureDefInputs        protowire.Number = 1
+	signatureDefOutputs       protowire.Number = 2
+	tensorInfoDtype           protowire.Number = 2
+	tensorInfoTensorShape     protowire.Number = 3
+	tensorShapeDim            protowire.Number = 2
+	tensorShapeUnknownRank    protowire.Number = 3
+	tensorShapeDimSize        protowire.Number = 1
+	tensorInfoCompositeTensor protowire.Number = 5
+	tensorInfoCooSparse       protowire.Number = 4
+)
+
+// tensorflowDatatypes maps the TensorFlow DataType enum to the v2 datatypes
+var tensorflowDatatypes = map[uint64]string{
+	1:  "FP32",
+	2:  "FP64",
+	3:  "INT32",
+	4:  "UINT8",
+	5:  "INT16",
+	6:  "INT8",
+	7:  "BYTES",
+	9:  "INT64",
+	10: "BOOL",
+	14: "BF16",
+	17: "UINT16",
+	19: "FP16",
+	22: "UINT32",
+	23: "UINT64",
+}
+
+type protoField struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// readSavedModel reads the tensors of the serving_default signature of the meta graph tagged serve, the first
+// signature in name order when the model has no serving_default signature.
+func readSavedModel(path string) (*v1beta1.ModelMetadata, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseProto(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved model %s: %v", path, err)
+	}
+	var metaGraph []protoField
+	for _, field := range fields {
+		if field.num != savedModelMetaGraphs {
+			continue
+		}
+		graph, err := parseProto(field.bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid meta graph in saved model %s: %v", path, err)
+		}
+		if metaGraph == nil {
+			metaGraph = graph
+		}
+		if hasServingTag(graph) {
+			metaGraph = graph
+			break
+		}
+	}
+
+	signatures := map[string][]byte{}
+	for _, field := range metaGraph {
+		if field.num != metaGraphDefSignatureDef {
+			continue
+		}
+		name, signature, err := parseMapEntry(field.bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature in saved model %s: %v", path, err)
+		}
+		if name != tensorflowInitOpSignature {
+			signatures[name] = signature
+		}
+	}
+	metadata := &v1beta1.ModelMetadata{Platform: TensorflowPlatform}
+	if len(signatures) == 0 {
+		return metadata, nil
+	}
+	signature, ok := signatures[tensorflowServingSignature]
+	if !ok {
+		names := make([]string, 0, len(signatures))
+		for name := range signatures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		signature = signatures[names[0]]
+	}
+
+	signatureFields, err := parseProto(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature in saved model %s: %v", path, err)
+	}
+	for _, field := range signatureFields {
+		if field.num != signatureDefInputs && field.num != signatureDefOutputs {
+			continue
+		}
+		name, tensorInfo, err := parseMapEntry(field.bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature in saved model %s: %v", path, err)
+		}
+		tensor, err := parseTensorInfo(name, tensorInfo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tensor %s in saved model %s: %v", name, path, err)
+		}
+		if field.num == signatureDefInputs {
+			metadata.Inputs = append(metadata.Inputs, *tensor)
+		} else {
+			metadata.Outputs = append(metadata.Outputs, *tensor)
+		}
+	}
+	// The signature maps are serialized in any order
+	sortTensors(metadata.Inputs)
+	sortTensors(metadata.Outputs)
+	return metadata, nil
+}
+
+func hasServingTag(metaGraph []protoField) bool {
+	for _, field := range metaGraph {
+		if field.num != metaGraphDefMetaInfoDef {
+			continue
+		}
+		metaInfo, err := parseProto(field.bytes)
+		if err != nil {
+			return false
+		}
+		for _, info := range metaInfo {
+			if info.num == metaInfoDefTags && string(info.bytes) == tensorflowServingTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseTensorInfo reads the dtype and shape of a dense tensor, the sparse and composite tensors are not supported
+func parseTensorInfo(name string, data []byte) (*v1beta1.TensorMetadata, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return nil, err
+	}
+	tensor := &v1beta1.TensorMetadata{Name: name, Shape: []int64{}}
+	for _, field := range fields {
+		switch field.num {
+		case tensorInfoCooSparse, tensorInfoCompositeTensor:
+			return nil, fmt.Errorf("sparse and composite tensors are not supported")
+		case tensorInfoDtype:
+			datatype, ok := tensorflowDatatypes[field.varint]
+			if !ok {
+				return nil, fmt.Errorf("unsupported dtype %d", field.varint)
+			}
+			tensor.Datatype = datatype
+		case tensorInfoTensorShape:
+			shape, err := parseProto(field.bytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, dim := range shape {
+				switch dim.num {
+				case tensorShapeUnknownRank:
+					// The shape has no dims when the rank is unknown
+					if dim.varint != 0 {
+						tensor.Shape = []int64{}
+					}
+				case tensorShapeDim:
+					dimFields, err := parseProto(dim.bytes)
+					if err != nil {
+						return nil, err
+					}
+					var size int64
+					for _, dimField := range dimFields {
+						if dimField.num == tensorShapeDimSize {
+							size = int64(dimField.varint)
+						}
+					}
+					tensor.Shape = append(tensor.Shape, size)
+				}
+			}
+		}
+	}
+	return tensor, nil
+}
+
+func parseMapEntry(data []byte) (string, []byte, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return "", nil, err
+	}
+	var key string
+	var value []byte
+	for _, field := range fields {
+		switch field.num {
+		case mapEntryKey:
+			key = string(field.bytes)
+		case mapEntryValue:
+			value = field.bytes
+		}
+	}
+	return key, value, nil
+}
+
+// parseProto decodes the fields of a message from the protobuf wire format, the values of the fixed size fields
+// and groups are skipped.
+func parseProto(data []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		field := protoField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			field.varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func sortTensors(tensors []v1beta1.TensorMetadata) {
+	sort.Slice(tensors, func(i, j int) bool {
+		return tensors[i].Name < tensors[j].Name
+	})
+}
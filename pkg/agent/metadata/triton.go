@@ -0,0 +1,250 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadata
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+const tritonDatatypePrefix = "TYPE_"
+
+// textMessage is a message decoded from the protobuf text format, the values are strings or nested messages
+type textMessage map[string][]interface{}
+
+// readTritonConfig reads the input and output tensors of the Triton model configuration, the batch dimension is
+// added to the dims of the tensors when the model supports batching.
+func readTritonConfig(path string) (*v1beta1.ModelMetadata, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseTextProto(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid triton config %s: %v", path, err)
+	}
+	metadata := &v1beta1.ModelMetadata{Platform: TritonPlatform}
+	if platform := config.scalar("platform"); platform != "" {
+		metadata.Platform = platform
+	} else if backend := config.scalar("backend"); backend != "" {
+		metadata.Platform = backend
+	}
+	batching := false
+	if maxBatchSize := config.scalar("max_batch_size"); maxBatchSize != "" {
+		size, err := strconv.Atoi(maxBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_batch_size in triton config %s: %v", path, err)
+		}
+		batching = size > 0
+	}
+	if metadata.Inputs, err = tritonTensors(config.messages("input"), batching); err != nil {
+		return nil, fmt.Errorf("invalid input in triton config %s: %v", path, err)
+	}
+	if metadata.Outputs, err = tritonTensors(config.messages("output"), batching); err != nil {
+		return nil, fmt.Errorf("invalid output in triton config %s: %v", path, err)
+	}
+	return metadata, nil
+}
+
+func tritonTensors(tensors []textMessage, batching bool) ([]v1beta1.TensorMetadata, error) {
+	var result []v1beta1.TensorMetadata
+	for _, tensor := range tensors {
+		metadata := v1beta1.TensorMetadata{
+			Name:     tensor.scalar("name"),
+			Datatype: strings.TrimPrefix(tensor.scalar("data_type"), tritonDatatypePrefix),
+			Shape:    []int64{},
+		}
+		if metadata.Datatype == "STRING" {
+			metadata.Datatype = "BYTES"
+		}
+		if batching {
+			metadata.Shape = append(metadata.Shape, -1)
+		}
+		for _, dim := range tensor["dims"] {
+			value, ok := dim.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid dims of tensor %s", metadata.Name)
+			}
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid dims of tensor %s: %v", metadata.Name, err)
+			}
+			metadata.Shape = append(metadata.Shape, size)
+		}
+		result = append(result, metadata)
+	}
+	return result, nil
+}
+
+func (m textMessage) scalar(name string) string {
+	for _, value := range m[name] {
+		if s, ok := value.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func (m textMessage) messages(name string) []textMessage {
+	var messages []textMessage
+	for _, value := range m[name] {
+		if message, ok := value.(textMessage); ok {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// parseTextProto decodes a message from the protobuf text format without its descriptor, the scalar values are
+// kept as strings with the quotes of the string values removed.
+func parseTextProto(data string) (textMessage, error) {
+	parser := &textParser{tokens: tokenizeTextProto(data)}
+	message, err := parser.message("")
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+type textToken struct {
+	value  string
+	quoted bool
+}
+
+type textParser struct {
+	tokens []textToken
+	pos    int
+}
+
+func (p *textParser) next() (textToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return textToken{}, false
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, true
+}
+
+func (p *textParser) peek(value string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].value == value
+}
+
+// message parses the fields until the end delimiter, the end of the input for the top level message
+func (p *textParser) message(end string) (textMessage, error) {
+	message := textMessage{}
+	for {
+		token, ok := p.next()
+		if !ok {
+			if end != "" {
+				return nil, fmt.Errorf("unexpected end of input, expecting %q", end)
+			}
+			return message, nil
+		}
+		if !token.quoted && token.value == end {
+			return message, nil
+		}
+		if token.quoted || strings.ContainsAny(token.value, "{}[]<>:,;") {
+			return nil, fmt.Errorf("unexpected %q, expecting a field name", token.value)
+		}
+		name := token.value
+		if p.peek(":") {
+			p.pos++
+		}
+		if p.peek("[") {
+			p.pos++
+			for !p.peek("]") {
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				message[name] = append(message[name], value)
+				if p.peek(",") {
+					p.pos++
+				}
+			}
+			p.pos++
+		} else {
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			message[name] = append(message[name], value)
+		}
+		if p.peek(",") || p.peek(";") {
+			p.pos++
+		}
+	}
+}
+
+func (p *textParser) value() (interface{}, error) {
+	token, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of input, expecting a value")
+	}
+	if !token.quoted {
+		switch token.value {
+		case "{":
+			return p.message("}")
+		case "<":
+			return p.message(">")
+		case "}", ">", "[", "]", ":", ",", ";":
+			return nil, fmt.Errorf("unexpected %q, expecting a value", token.value)
+		}
+	}
+	return token.value, nil
+}
+
+func tokenizeTextProto(data string) []textToken {
+	var tokens []textToken
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("{}[]<>:,;", c) >= 0:
+			tokens = append(tokens, textToken{value: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			var value strings.Builder
+			i++
+			for i < len(data) && data[i] != c {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+				}
+				value.WriteByte(data[i])
+				i++
+			}
+			i++
+			tokens = append(tokens, textToken{value: value.String(), quoted: true})
+		default:
+			start := i
+			for i < len(data) && strings.IndexByte(" \t\n\r#{}[]<>:,;\"'", data[i]) < 0 {
+				i++
+			}
+			tokens = append(tokens, textToken{value: data[start:i]})
+		}
+	}
+	return tokens
+}
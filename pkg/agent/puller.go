@@ -17,12 +17,9 @@ limitations under the License.
 package agent
 
 import (
-	"bytes"
-	"fmt"
+	"github.com/kubeflow/kfserving/pkg/agent/metadata"
 	"github.com/kubeflow/kfserving/pkg/agent/storage"
 	v1 "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
-	"io/ioutil"
-	"net/http"
 	"path/filepath"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 )
@@ -39,6 +36,10 @@ type Puller struct {
 	completions chan *ModelOp
 	opStats     map[string]map[OpType]int
 	Downloader  Downloader
+	// Loader is nil when the model server picks up the models from the model dir by itself
+	Loader *Loader
+	// Metadata keeps the signature metadata read from the model files, nil to disable
+	Metadata *metadata.Store
 }
 
 type ModelOp struct {
@@ -47,12 +48,14 @@ type ModelOp struct {
 	Spec      *v1.ModelSpec
 }
 
-func StartPuller(downloader Downloader, commands <-chan ModelOp) {
+func StartPuller(downloader Downloader, loader *Loader, metadataStore *metadata.Store, commands <-chan ModelOp) {
 	puller := Puller{
 		channelMap:  make(map[string]*ModelChannel),
 		completions: make(chan *ModelOp, 4),
 		opStats:     make(map[string]map[OpType]int),
 		Downloader:  downloader,
+		Loader:      loader,
+		Metadata:    metadataStore,
 	}
 	go puller.processCommands(commands)
 }
@@ -133,43 +136,56 @@ func (p *Puller) modelProcessor(modelName string, ops <-chan *ModelOp) {
 				// need to call the error endpoint of the puller
 				log.Error(err, "Fails to download model", "modelName", modelName)
 			} else {
-				// Load the model onto the model server
-				resp, err := http.Post(fmt.Sprintf("http://localhost:8080/v2/repository/models/%s/load", modelName),
-					"application/json",
-					bytes.NewBufferString("{}"))
-				if err != nil {
-					// handle error
-					log.Error(err, "Failed to Load model", "modelName", modelName)
-				} else {
-					defer resp.Body.Close()
-					body, err := ioutil.ReadAll(resp.Body)
-					if err != nil {
-						log.Info("Loaded model", "modelName", modelName, "resp", body)
+				p.readMetadata(modelName)
+				if modelOp.Spec.LoadPolicy != nil && *modelOp.Spec.LoadPolicy == v1.LoadPolicyLazy {
+					// The model server loads the model from the model dir on the first request
+					log.Info("Skipping the load of the lazily loaded model", "modelName", modelName)
+				} else if p.Loader != nil {
+					// Load the model onto the model server
+					if err := p.Loader.LoadModel(modelName); err != nil {
+						log.Error(err, "Failed to Load model", "modelName", modelName)
+					} else {
+						log.Info("Loaded model", "modelName", modelName)
 					}
 				}
 			}
 		case Remove:
 			log.Info("unloading model", "modelName", modelName)
+			if p.Metadata != nil {
+				p.Metadata.Delete(modelName)
+			}
 			// If there is an error, we will NOT do a delete... that could be problematic
 			if err := storage.RemoveDir(filepath.Join(p.Downloader.ModelDir, modelName)); err != nil {
 				log.Error(err, "failing to delete model directory")
-			} else {
+			} else if p.Loader != nil {
 				// unload model from model server
-				resp, err := http.Post(fmt.Sprintf("http://localhost:8080/v2/repository/models/%s/unload", modelName),
-					"application/json",
-					bytes.NewBufferString("{}"))
-				if err != nil {
-					// handle error
+				if err := p.Loader.UnloadModel(modelName); err != nil {
 					log.Error(err, "Failed to Unload model", "modelName", modelName)
 				} else {
-					defer resp.Body.Close()
-					body, err := ioutil.ReadAll(resp.Body)
-					if err != nil {
-						log.Info("Unloaded model", "modelName", modelName, "resp", body)
-					}
+					log.Info("Unloaded model", "modelName", modelName)
 				}
 			}
 		}
 		p.completions <- modelOp
 	}
 }
+
+// readMetadata reads the signature metadata from the downloaded model files, the models without signature files
+// have no metadata.
+func (p *Puller) readMetadata(modelName string) {
+	if p.Metadata == nil {
+		return
+	}
+	log := logf.Log.WithName("modelMetadata")
+	modelMetadata, err := metadata.Read(filepath.Join(p.Downloader.ModelDir, modelName))
+	if err != nil {
+		log.Error(err, "Failed to read model metadata", "modelName", modelName)
+		return
+	}
+	if modelMetadata == nil {
+		log.Info("No signature found in the model files", "modelName", modelName)
+		p.Metadata.Delete(modelName)
+		return
+	}
+	p.Metadata.Set(modelName, modelMetadata)
+}
@@ -33,7 +33,9 @@ var (
 )
 
 type Watcher struct {
-	configDir    string
+	configDir string
+	// ConfigFile is the name of the model config file in the config dir
+	ConfigFile   string
 	modelTracker map[string]modelWrapper
 	ModelEvents  chan ModelOp
 }
@@ -45,6 +47,7 @@ func NewWatcher(configDir string, modelDir string) Watcher {
 	}
 	return Watcher{
 		configDir:    configDir,
+		ConfigFile:   constants.ModelConfigFileName,
 		modelTracker: modelTracker,
 		ModelEvents:  make(chan ModelOp),
 	}
@@ -79,7 +82,7 @@ func (w *Watcher) Start() {
 				// TODO: Should we use atomic integer or timestamp??
 				if isDataDir && isCreate {
 					symlink, _ := filepath.EvalSymlinks(eventPath)
-					file, err := ioutil.ReadFile(filepath.Join(symlink, constants.ModelConfigFileName))
+					file, err := ioutil.ReadFile(filepath.Join(symlink, w.ConfigFile))
 					if err != nil {
 						log.Error(err, "Error in reading model config file")
 					} else {
@@ -14,7 +14,10 @@ import (
 	. "github.com/onsi/gomega"
 	"io/ioutil"
 	logger "log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 )
 
 type mockS3Client struct {
@@ -269,5 +272,111 @@ var _ = Describe("Watcher", func() {
 				Eventually(func() int { return puller.opStats["model1"][Add] }).Should(Equal(1))
 			})
 		})
+
+		Context("Explicit model loading", func() {
+			It("should load and unload the models on the model server", func() {
+				defer GinkgoRecover()
+				logger.Printf("Sync explicit model loading using temp dir %v\n", modelDir)
+				var mu sync.Mutex
+				var calls []string
+				modelServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					mu.Lock()
+					defer mu.Unlock()
+					calls = append(calls, req.URL.Path)
+				}))
+				defer modelServer.Close()
+				watcher := NewWatcher("/tmp/configs", modelDir)
+				puller := Puller{
+					channelMap:  make(map[string]*ModelChannel),
+					completions: make(chan *ModelOp, 4),
+					opStats:     make(map[string]map[OpType]int),
+					Downloader: Downloader{
+						ModelDir: modelDir + "/test5",
+						Providers: map[storage.Protocol]storage.Provider{
+							storage.S3: &storage.S3Provider{
+								Client:     &mockS3Client{},
+								Downloader: &mockS3Downloder{},
+							},
+						},
+					},
+					Loader: NewLoader(modelServer.URL),
+				}
+				go puller.processCommands(watcher.ModelEvents)
+				modelConfigs := modelconfig.ModelConfigs{
+					{
+						Name: "model1",
+						Spec: v1beta1.ModelSpec{
+							StorageURI: "s3://models/model1",
+							Framework:  "tensorrt",
+						},
+					},
+				}
+				watcher.parseConfig(modelConfigs)
+				watcher.parseConfig(modelconfig.ModelConfigs{})
+				Eventually(func() int { return puller.opStats["model1"][Remove] }).Should(Equal(1))
+				Eventually(func() []string {
+					mu.Lock()
+					defer mu.Unlock()
+					return append([]string{}, calls...)
+				}).Should(Equal([]string{
+					"/v2/repository/models/model1/load",
+					"/v2/repository/models/model1/unload",
+				}))
+			})
+		})
+
+		Context("Lazy model loading", func() {
+			It("should download the model without loading it on the model server", func() {
+				defer GinkgoRecover()
+				logger.Printf("Sync lazy model loading using temp dir %v\n", modelDir)
+				var mu sync.Mutex
+				var calls []string
+				modelServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					mu.Lock()
+					defer mu.Unlock()
+					calls = append(calls, req.URL.Path)
+				}))
+				defer modelServer.Close()
+				watcher := NewWatcher("/tmp/configs", modelDir)
+				puller := Puller{
+					channelMap:  make(map[string]*ModelChannel),
+					completions: make(chan *ModelOp, 4),
+					opStats:     make(map[string]map[OpType]int),
+					Downloader: Downloader{
+						ModelDir: modelDir + "/test6",
+						Providers: map[storage.Protocol]storage.Provider{
+							storage.S3: &storage.S3Provider{
+								Client:     &mockS3Client{},
+								Downloader: &mockS3Downloder{},
+							},
+						},
+					},
+					Loader: NewLoader(modelServer.URL),
+				}
+				go puller.processCommands(watcher.ModelEvents)
+				lazy := v1beta1.LoadPolicyLazy
+				modelConfigs := modelconfig.ModelConfigs{
+					{
+						Name: "model1",
+						Spec: v1beta1.ModelSpec{
+							StorageURI: "s3://models/model1",
+							Framework:  "sklearn",
+							LoadPolicy: &lazy,
+						},
+					},
+				}
+				watcher.parseConfig(modelConfigs)
+				Eventually(func() int { return puller.opStats["model1"][Add] }).Should(Equal(1))
+				watcher.parseConfig(modelconfig.ModelConfigs{})
+				Eventually(func() int { return puller.opStats["model1"][Remove] }).Should(Equal(1))
+				Eventually(func() []string {
+					mu.Lock()
+					defer mu.Unlock()
+					return append([]string{}, calls...)
+				}).Should(Equal([]string{
+					"/v2/repository/models/model1/unload",
+				}))
+			})
+		})
 	})
 })
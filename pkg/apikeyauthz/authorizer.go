@@ -0,0 +1,144 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyauthz
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1beta1listers "github.com/kubeflow/kfserving/pkg/client/listers/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// Authorizer is the Istio envoyExtAuthzHttp provider checking the API keys of the InferenceServices. The check
+// requests carry the host and the path of the original request, the request is allowed with 200 when its X-API-Key
+// header matches one of the values of the API key secret of the InferenceService and denied with 403 otherwise.
+type Authorizer struct {
+	// InferenceServices lists the InferenceServices, the InferenceService of a request is resolved from the external
+	// host and the url of their status, which are only set by the controller
+	InferenceServices v1beta1listers.InferenceServiceLister
+	// Secrets lists the API key secrets, labeled with constants.APIKeySecretLabelKey
+	Secrets corev1listers.SecretLister
+	Log     logr.Logger
+}
+
+func (a *Authorizer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	secret, err := a.lookup(host, req.URL.Path)
+	if err != nil {
+		a.Log.Error(err, "Failed to look up the API key secret")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if secret == nil || !matches(secret, req.Header.Get(constants.APIKeyHeader)) {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// resolve returns the InferenceService called by the request. The external host of an InferenceService takes
+// precedence over the longest path prefix on the ingress domain, nil when no InferenceService or more than one
+// InferenceService matches.
+func (a *Authorizer) resolve(host string, path string) (*v1beta1.InferenceService, error) {
+	isvcs, err := a.InferenceServices.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var hostMatches, pathMatches []*v1beta1.InferenceService
+	longest := ""
+	for _, isvc := range isvcs {
+		if isvc.Status.ExternalHost == host {
+			hostMatches = append(hostMatches, isvc)
+			continue
+		}
+		url := isvc.Status.URL
+		if url == nil || url.Host != host || url.Path == "" {
+			continue
+		}
+		prefix := strings.TrimRight(url.Path, "/")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") || len(prefix) < len(longest) {
+			continue
+		}
+		if len(prefix) > len(longest) {
+			pathMatches = nil
+			longest = prefix
+		}
+		pathMatches = append(pathMatches, isvc)
+	}
+	candidates := pathMatches
+	if len(hostMatches) > 0 {
+		candidates = hostMatches
+	}
+	if len(candidates) > 1 {
+		a.Log.Info("Denying the request matching several InferenceServices", "host", host, "path", path)
+		return nil, nil
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return candidates[0], nil
+}
+
+// lookup returns the API key secret of the InferenceService called by the request, nil without one. Only the secret
+// named after the InferenceService in its namespace and controlled by it is accepted.
+func (a *Authorizer) lookup(host string, path string) (*corev1.Secret, error) {
+	isvc, err := a.resolve(host, path)
+	if err != nil || isvc == nil {
+		return nil, err
+	}
+	secret, err := a.Secrets.Secrets(isvc.Namespace).Get(constants.APIKeySecretName(isvc.Name))
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(secret)
+	if owner == nil || owner.Kind != "InferenceService" || owner.Name != isvc.Name || owner.UID != isvc.UID {
+		a.Log.Info("Ignoring the API key secret not controlled by the InferenceService", "namespace",
+			secret.Namespace, "name", secret.Name)
+		return nil, nil
+	}
+	return secret, nil
+}
+
+// matches tells whether the key is one of the values of the secret
+func matches(secret *corev1.Secret, key string) bool {
+	if key == "" {
+		return false
+	}
+	matched := false
+	for _, value := range secret.Data {
+		if subtle.ConstantTimeCompare(value, []byte(key)) == 1 {
+			matched = true
+		}
+	}
+	return matched
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyauthz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1beta1listers "github.com/kubeflow/kfserving/pkg/client/listers/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestAuthorizer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	isvc := func(namespace string, name string, host string, url string) *v1beta1.InferenceService {
+		parsed, err := apis.ParseURL(url)
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		return &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				UID:       types.UID(namespace + "-" + name),
+			},
+			Status: v1beta1.InferenceServiceStatus{ExternalHost: host, URL: parsed},
+		}
+	}
+	secret := func(owner *v1beta1.InferenceService, keys ...string) *corev1.Secret {
+		data := map[string][]byte{}
+		for i, key := range keys {
+			data[[]string{constants.APIKeySecretKey, constants.PreviousAPIKeySecretKey, "client-a"}[i]] = []byte(key)
+		}
+		controller := true
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.APIKeySecretName(owner.Name),
+				Namespace: owner.Namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1beta1.SchemeGroupVersion.String(),
+					Kind:       "InferenceService",
+					Name:       owner.Name,
+					UID:        owner.UID,
+					Controller: &controller,
+				}},
+			},
+			Data: data,
+		}
+	}
+	iris := isvc("default", "iris", "iris.default.example.com", "http://models.example.com/serving/default/iris")
+	irisV2 := isvc("default", "iris-v2", "iris-v2.default.example.com",
+		"http://models.example.com/serving/default/iris-v2")
+	flowers := isvc("default", "flowers", "flowers.default.example.com", "http://flowers.default.example.com")
+	// The tenant of another namespace claims the host and the path of iris in the secret of its own service
+	intruder := isvc("tenant", "intruder", "intruder.tenant.example.com", "http://intruder.tenant.example.com")
+	forged := secret(intruder, "intruder-key")
+	forged.Annotations = map[string]string{
+		constants.KFServingAPIGroupName + "/api-key-host": "iris.default.example.com",
+		constants.KFServingAPIGroupName + "/api-key-path": "/serving/default/iris",
+	}
+	// The secret of unowned is not controlled by it
+	unowned := isvc("default", "unowned", "unowned.default.example.com", "http://unowned.default.example.com")
+	uncontrolled := secret(unowned, "unowned-key")
+	uncontrolled.OwnerReferences = nil
+	// The secret of mislabeled is controlled by another InferenceService
+	mislabeled := isvc("default", "mislabeled", "mislabeled.default.example.com",
+		"http://mislabeled.default.example.com")
+	foreign := secret(flowers, "mislabeled-key")
+	foreign.Name = constants.APIKeySecretName(mislabeled.Name)
+	// Both duplicate services claim the same host
+	duplicate := isvc("default", "duplicate", "duplicate.default.example.com", "http://duplicate.default.example.com")
+	duplicateCopy := isvc("tenant", "duplicate", "duplicate.default.example.com",
+		"http://duplicate.default.example.com")
+
+	isvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, i := range []*v1beta1.InferenceService{iris, irisV2, flowers, intruder, unowned, mislabeled, duplicate,
+		duplicateCopy} {
+		g.Expect(isvcIndexer.Add(i)).To(gomega.Succeed())
+	}
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, s := range []*corev1.Secret{
+		secret(iris, "iris-key", "iris-previous"),
+		secret(irisV2, "v2-key"),
+		secret(flowers, "flowers-key", "", "flowers-client"),
+		forged,
+		uncontrolled,
+		foreign,
+		secret(duplicate, "duplicate-key"),
+		secret(duplicateCopy, "duplicate-key"),
+	} {
+		g.Expect(secretIndexer.Add(s)).To(gomega.Succeed())
+	}
+	authorizer := &Authorizer{
+		InferenceServices: v1beta1listers.NewInferenceServiceLister(isvcIndexer),
+		Secrets:           corev1listers.NewSecretLister(secretIndexer),
+		Log:               logf.Log,
+	}
+
+	scenarios := []struct {
+		name   string
+		host   string
+		path   string
+		key    string
+		status int
+	}{
+		{"Host", "iris.default.example.com", "/v1/models/iris:predict", "iris-key", http.StatusOK},
+		{"HostWithPort", "iris.default.example.com:80", "/v1/models/iris:predict", "iris-key", http.StatusOK},
+		{"PreviousKey", "iris.default.example.com", "/v1/models/iris:predict", "iris-previous", http.StatusOK},
+		{"ClientKey", "flowers.default.example.com", "/v1/models/flowers:predict", "flowers-client", http.StatusOK},
+		{"KeyOfAnotherService", "iris.default.example.com", "/v1/models/iris:predict", "v2-key", http.StatusForbidden},
+		{"NoKey", "iris.default.example.com", "/v1/models/iris:predict", "", http.StatusForbidden},
+		{"EmptyValue", "flowers.default.example.com", "/v1/models/flowers:predict", "", http.StatusForbidden},
+		{"Path", "models.example.com", "/serving/default/iris/v1/models/iris:predict", "iris-key", http.StatusOK},
+		{"LongestPath", "models.example.com", "/serving/default/iris-v2/v1/models/iris:predict", "v2-key",
+			http.StatusOK},
+		{"PathPrefixOfAnotherService", "models.example.com", "/serving/default/iris-v2", "iris-key",
+			http.StatusForbidden},
+		{"UnknownService", "unknown.default.example.com", "/v1/models/unknown:predict", "iris-key",
+			http.StatusForbidden},
+		{"KeyOfAnotherTenant", "iris.default.example.com", "/v1/models/iris:predict", "intruder-key",
+			http.StatusForbidden},
+		{"KeyOfAnotherTenantOnPath", "models.example.com", "/serving/default/iris/v1/models/iris:predict",
+			"intruder-key", http.StatusForbidden},
+		{"UncontrolledSecret", "unowned.default.example.com", "/v1/models/unowned:predict", "unowned-key",
+			http.StatusForbidden},
+		{"SecretOfAnotherService", "mislabeled.default.example.com", "/v1/models/mislabeled:predict",
+			"mislabeled-key", http.StatusForbidden},
+		{"AmbiguousHost", "duplicate.default.example.com", "/v1/models/duplicate:predict", "duplicate-key",
+			http.StatusForbidden},
+	}
+	for _, scenario := range scenarios {
+		t.Run(scenario.name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			req := httptest.NewRequest(http.MethodPost, "http://"+scenario.host+scenario.path, nil)
+			if scenario.key != "" {
+				req.Header.Set(constants.APIKeyHeader, scenario.key)
+			}
+			rw := httptest.NewRecorder()
+			authorizer.ServeHTTP(rw, req)
+			g.Expect(rw.Code).To(gomega.Equal(scenario.status))
+		})
+	}
+}
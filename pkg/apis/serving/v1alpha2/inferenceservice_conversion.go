@@ -17,300 +17,499 @@ limitations under the License.
 package v1alpha2
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/gogo/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // Convert to hub version from v1alpha2 to v1beta1
 func (src *InferenceService) ConvertTo(dstRaw conversion.Hub) error {
 	dst := dstRaw.(*v1beta1.InferenceService)
-	dst.ObjectMeta = src.ObjectMeta
-	if src.Spec.Default.Predictor.Tensorflow != nil {
-		dst.Spec.Predictor.Tensorflow = &v1beta1.TFServingSpec{
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	convertSpecTo(&src.Spec, &dst.Spec)
+	convertStatusTo(&src.Status, &dst.Status)
+
+	// The v1beta1 spec kept by the conversion to v1alpha2 is restored, unless the v1alpha2 spec was changed since
+	if value, ok := dst.Annotations[constants.V1Beta1SpecAnnotationKey]; ok {
+		removeAnnotation(&dst.ObjectMeta, constants.V1Beta1SpecAnnotationKey)
+		restored := v1beta1.InferenceServiceSpec{}
+		if err := json.Unmarshal([]byte(value), &restored); err == nil {
+			converted := InferenceServiceSpec{}
+			convertSpecFrom(&restored, &converted)
+			if equality.Semantic.DeepEqual(converted, src.Spec) {
+				dst.Spec = restored
+			}
+		}
+	}
+	converted := InferenceServiceSpec{}
+	convertSpecFrom(&dst.Spec, &converted)
+	return keepSpec(&dst.ObjectMeta, constants.V1Alpha2SpecAnnotationKey, src.Spec, converted)
+}
+
+// convertSpecTo converts the v1alpha2 spec to the v1beta1 spec
+func convertSpecTo(src *InferenceServiceSpec, dst *v1beta1.InferenceServiceSpec) {
+	// The canary endpoint is rolled out as the latest revision of the components, the default endpoint being their
+	// previous revision which keeps the rest of the traffic
+	endpoint := &src.Default
+	if src.Canary != nil {
+		endpoint = src.Canary
+	}
+	if endpoint.Predictor.Tensorflow != nil {
+		dst.Predictor.Tensorflow = &v1beta1.TFServingSpec{
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.Tensorflow.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.Tensorflow.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.Tensorflow.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.Tensorflow.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.Tensorflow.Resources,
+					Resources: endpoint.Predictor.Tensorflow.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.SKLearn != nil {
-		dst.Spec.Predictor.SKLearn = &v1beta1.SKLearnSpec{
+	} else if endpoint.Predictor.SKLearn != nil {
+		dst.Predictor.SKLearn = &v1beta1.SKLearnSpec{
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.SKLearn.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.SKLearn.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.SKLearn.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.SKLearn.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.SKLearn.Resources,
+					Resources: endpoint.Predictor.SKLearn.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.XGBoost != nil {
-		dst.Spec.Predictor.XGBoost = &v1beta1.XGBoostSpec{
+	} else if endpoint.Predictor.XGBoost != nil {
+		dst.Predictor.XGBoost = &v1beta1.XGBoostSpec{
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.XGBoost.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.XGBoost.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.XGBoost.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.XGBoost.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.XGBoost.Resources,
+					Resources: endpoint.Predictor.XGBoost.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.Triton != nil {
-		dst.Spec.Predictor.Triton = &v1beta1.TritonSpec{
+	} else if endpoint.Predictor.Triton != nil {
+		dst.Predictor.Triton = &v1beta1.TritonSpec{
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.Triton.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.Triton.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.Triton.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.Triton.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.Triton.Resources,
+					Resources: endpoint.Predictor.Triton.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.ONNX != nil {
-		dst.Spec.Predictor.ONNX = &v1beta1.ONNXRuntimeSpec{
+	} else if endpoint.Predictor.ONNX != nil {
+		dst.Predictor.ONNX = &v1beta1.ONNXRuntimeSpec{
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.ONNX.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.ONNX.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.ONNX.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.ONNX.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.ONNX.Resources,
+					Resources: endpoint.Predictor.ONNX.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.PyTorch != nil {
-		dst.Spec.Predictor.PyTorch = &v1beta1.TorchServeSpec{
-			ModelClassName: src.Spec.Default.Predictor.PyTorch.ModelClassName,
+	} else if endpoint.Predictor.PyTorch != nil {
+		dst.Predictor.PyTorch = &v1beta1.TorchServeSpec{
+			ModelClassName: endpoint.Predictor.PyTorch.ModelClassName,
 			PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
-				RuntimeVersion: &src.Spec.Default.Predictor.PyTorch.RuntimeVersion,
-				StorageURI:     &src.Spec.Default.Predictor.PyTorch.StorageURI,
+				RuntimeVersion: &endpoint.Predictor.PyTorch.RuntimeVersion,
+				StorageURI:     &endpoint.Predictor.PyTorch.StorageURI,
 				Container: v1.Container{
-					Resources: src.Spec.Default.Predictor.PyTorch.Resources,
+					Resources: endpoint.Predictor.PyTorch.Resources,
 				},
 			},
 		}
-	} else if src.Spec.Default.Predictor.Custom != nil {
-		dst.Spec.Predictor.PodSpec = v1beta1.PodSpec{
+	} else if endpoint.Predictor.Custom != nil {
+		dst.Predictor.PodSpec = v1beta1.PodSpec{
 			Containers: []v1.Container{
-				src.Spec.Default.Predictor.Custom.Container,
+				endpoint.Predictor.Custom.Container,
 			},
 		}
 	}
-	dst.Spec.Predictor.MinReplicas = src.Spec.Default.Predictor.MinReplicas
-	dst.Spec.Predictor.MaxReplicas = src.Spec.Default.Predictor.MaxReplicas
-	dst.Spec.Predictor.ContainerConcurrency = proto.Int64(int64(src.Spec.Default.Predictor.Parallelism))
-	if src.Spec.CanaryTrafficPercent != nil {
-		dst.Spec.Predictor.CanaryTrafficPercent = proto.Int64(int64(*src.Spec.CanaryTrafficPercent))
+	dst.Predictor.MinReplicas = endpoint.Predictor.MinReplicas
+	dst.Predictor.MaxReplicas = endpoint.Predictor.MaxReplicas
+	dst.Predictor.ContainerConcurrency = proto.Int64(int64(endpoint.Predictor.Parallelism))
+	if src.CanaryTrafficPercent != nil {
+		dst.Predictor.CanaryTrafficPercent = proto.Int64(int64(*src.CanaryTrafficPercent))
 	}
-	if src.Spec.Default.Predictor.Batcher != nil {
-		dst.Spec.Predictor.Batcher = &v1beta1.Batcher{
-			MaxBatchSize: src.Spec.Default.Predictor.Batcher.MaxBatchSize,
-			MaxLatency:   src.Spec.Default.Predictor.Batcher.MaxLatency,
-			Timeout:      src.Spec.Default.Predictor.Batcher.Timeout,
+	if endpoint.Predictor.Batcher != nil {
+		dst.Predictor.Batcher = &v1beta1.Batcher{
+			MaxBatchSize: endpoint.Predictor.Batcher.MaxBatchSize,
+			MaxLatency:   endpoint.Predictor.Batcher.MaxLatency,
+			Timeout:      endpoint.Predictor.Batcher.Timeout,
 		}
 	}
-	if src.Spec.Default.Predictor.Logger != nil {
-		dst.Spec.Predictor.Logger = &v1beta1.LoggerSpec{
-			URL:  src.Spec.Default.Predictor.Logger.Url,
-			Mode: v1beta1.LoggerType(src.Spec.Default.Predictor.Logger.Mode),
+	if endpoint.Predictor.Logger != nil {
+		dst.Predictor.Logger = &v1beta1.LoggerSpec{
+			URL:  endpoint.Predictor.Logger.Url,
+			Mode: v1beta1.LoggerType(endpoint.Predictor.Logger.Mode),
 		}
 	}
-	if src.Spec.Default.Predictor.ServiceAccountName != "" {
-		dst.Spec.Predictor.PodSpec.ServiceAccountName = src.Spec.Default.Predictor.ServiceAccountName
+	if endpoint.Predictor.ServiceAccountName != "" {
+		dst.Predictor.PodSpec.ServiceAccountName = endpoint.Predictor.ServiceAccountName
 	}
 
-	if src.Spec.Default.Transformer != nil {
-		if src.Spec.Default.Transformer.Custom != nil {
-			dst.Spec.Transformer = &v1beta1.TransformerSpec{
+	if endpoint.Transformer != nil {
+		if endpoint.Transformer.Custom != nil {
+			dst.Transformer = &v1beta1.TransformerSpec{
 				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
-					MinReplicas:          src.Spec.Default.Transformer.MinReplicas,
-					MaxReplicas:          src.Spec.Default.Transformer.MaxReplicas,
-					ContainerConcurrency: proto.Int64(int64(src.Spec.Default.Transformer.Parallelism)),
+					MinReplicas:          endpoint.Transformer.MinReplicas,
+					MaxReplicas:          endpoint.Transformer.MaxReplicas,
+					ContainerConcurrency: proto.Int64(int64(endpoint.Transformer.Parallelism)),
 				},
 				PodSpec: v1beta1.PodSpec{
 					Containers: []v1.Container{
-						src.Spec.Default.Transformer.Custom.Container,
+						endpoint.Transformer.Custom.Container,
 					},
 				},
 			}
 		}
 	}
-	if src.Spec.Default.Explainer != nil {
-		if src.Spec.Default.Explainer.Alibi != nil {
-			dst.Spec.Explainer = &v1beta1.ExplainerSpec{
+	if endpoint.Explainer != nil {
+		if endpoint.Explainer.Alibi != nil {
+			dst.Explainer = &v1beta1.ExplainerSpec{
 				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
-					MinReplicas:          src.Spec.Default.Explainer.MinReplicas,
-					MaxReplicas:          src.Spec.Default.Explainer.MaxReplicas,
-					ContainerConcurrency: proto.Int64(int64(src.Spec.Default.Explainer.Parallelism)),
+					MinReplicas:          endpoint.Explainer.MinReplicas,
+					MaxReplicas:          endpoint.Explainer.MaxReplicas,
+					ContainerConcurrency: proto.Int64(int64(endpoint.Explainer.Parallelism)),
 				},
 				Alibi: &v1beta1.AlibiExplainerSpec{
-					Type:           v1beta1.AlibiExplainerType(src.Spec.Default.Explainer.Alibi.Type),
-					StorageURI:     src.Spec.Default.Explainer.Alibi.StorageURI,
-					RuntimeVersion: proto.String(src.Spec.Default.Explainer.Alibi.RuntimeVersion),
+					Type:           v1beta1.AlibiExplainerType(endpoint.Explainer.Alibi.Type),
+					StorageURI:     endpoint.Explainer.Alibi.StorageURI,
+					RuntimeVersion: proto.String(endpoint.Explainer.Alibi.RuntimeVersion),
 					Container: v1.Container{
-						Resources: src.Spec.Default.Explainer.Alibi.Resources,
+						Resources: endpoint.Explainer.Alibi.Resources,
 					},
 				},
 			}
 		}
-		if src.Spec.Default.Explainer.AIX != nil {
-			dst.Spec.Explainer = &v1beta1.ExplainerSpec{
+		if endpoint.Explainer.AIX != nil {
+			dst.Explainer = &v1beta1.ExplainerSpec{
 				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
-					MinReplicas:          src.Spec.Default.Explainer.MinReplicas,
-					MaxReplicas:          src.Spec.Default.Explainer.MaxReplicas,
-					ContainerConcurrency: proto.Int64(int64(src.Spec.Default.Explainer.Parallelism)),
+					MinReplicas:          endpoint.Explainer.MinReplicas,
+					MaxReplicas:          endpoint.Explainer.MaxReplicas,
+					ContainerConcurrency: proto.Int64(int64(endpoint.Explainer.Parallelism)),
 				},
 				AIX: &v1beta1.AIXExplainerSpec{
-					Type:           v1beta1.AIXExplainerType(src.Spec.Default.Explainer.AIX.Type),
-					StorageURI:     src.Spec.Default.Explainer.AIX.StorageURI,
-					RuntimeVersion: proto.String(src.Spec.Default.Explainer.AIX.RuntimeVersion),
+					Type:           v1beta1.AIXExplainerType(endpoint.Explainer.AIX.Type),
+					StorageURI:     endpoint.Explainer.AIX.StorageURI,
+					RuntimeVersion: proto.String(endpoint.Explainer.AIX.RuntimeVersion),
 					Container: v1.Container{
-						Resources: src.Spec.Default.Explainer.AIX.Resources,
+						Resources: endpoint.Explainer.AIX.Resources,
 					},
 				},
 			}
 		}
-		if src.Spec.Default.Explainer.Custom != nil {
-			dst.Spec.Explainer = &v1beta1.ExplainerSpec{
+		if endpoint.Explainer.Custom != nil {
+			dst.Explainer = &v1beta1.ExplainerSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					MinReplicas:          endpoint.Explainer.MinReplicas,
+					MaxReplicas:          endpoint.Explainer.MaxReplicas,
+					ContainerConcurrency: proto.Int64(int64(endpoint.Explainer.Parallelism)),
+				},
 				PodSpec: v1beta1.PodSpec{
+					ServiceAccountName: endpoint.Explainer.ServiceAccountName,
 					Containers: []v1.Container{
-						src.Spec.Default.Explainer.Custom.Container,
+						endpoint.Explainer.Custom.Container,
 					},
 				},
 			}
 		}
 	}
-	return nil
+	if src.Canary != nil && src.CanaryTrafficPercent != nil {
+		if dst.Transformer != nil {
+			dst.Transformer.CanaryTrafficPercent = proto.Int64(int64(*src.CanaryTrafficPercent))
+		}
+		if dst.Explainer != nil {
+			dst.Explainer.CanaryTrafficPercent = proto.Int64(int64(*src.CanaryTrafficPercent))
+		}
+	}
 }
 
 // Convert from hub version v1beta1 to v1alpha2
 func (dst *InferenceService) ConvertFrom(srcRaw conversion.Hub) error {
 	src := srcRaw.(*v1beta1.InferenceService)
-	dst.ObjectMeta = src.ObjectMeta
-	if src.Spec.Predictor.Tensorflow != nil {
-		dst.Spec.Default.Predictor.Tensorflow = &TensorflowSpec{
-			RuntimeVersion: *src.Spec.Predictor.Tensorflow.RuntimeVersion,
-			Resources:      src.Spec.Predictor.Tensorflow.Resources,
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	convertSpecFrom(&src.Spec, &dst.Spec)
+	convertStatusFrom(&src.Status, &dst.Status)
+
+	// The v1alpha2 spec kept by the conversion to v1beta1 is restored, unless the v1beta1 spec was changed since
+	if value, ok := dst.Annotations[constants.V1Alpha2SpecAnnotationKey]; ok {
+		removeAnnotation(&dst.ObjectMeta, constants.V1Alpha2SpecAnnotationKey)
+		restored := InferenceServiceSpec{}
+		if err := json.Unmarshal([]byte(value), &restored); err == nil {
+			converted := v1beta1.InferenceServiceSpec{}
+			convertSpecTo(&restored, &converted)
+			if equality.Semantic.DeepEqual(converted, src.Spec) {
+				dst.Spec = restored
+			}
 		}
-		if src.Spec.Predictor.Tensorflow.StorageURI != nil {
-			dst.Spec.Default.Predictor.Tensorflow.StorageURI = *src.Spec.Predictor.Tensorflow.StorageURI
+	}
+	converted := v1beta1.InferenceServiceSpec{}
+	convertSpecTo(&dst.Spec, &converted)
+	return keepSpec(&dst.ObjectMeta, constants.V1Beta1SpecAnnotationKey, src.Spec, converted)
+}
+
+// convertSpecFrom converts the v1beta1 spec to the v1alpha2 spec
+func convertSpecFrom(src *v1beta1.InferenceServiceSpec, dst *InferenceServiceSpec) {
+	if src.Predictor.Tensorflow != nil {
+		dst.Default.Predictor.Tensorflow = &TensorflowSpec{
+			RuntimeVersion: stringValue(src.Predictor.Tensorflow.RuntimeVersion),
+			Resources:      src.Predictor.Tensorflow.Resources,
 		}
-	} else if src.Spec.Predictor.SKLearn != nil {
-		dst.Spec.Default.Predictor.SKLearn = &SKLearnSpec{
-			RuntimeVersion: *src.Spec.Predictor.SKLearn.RuntimeVersion,
-			Resources:      src.Spec.Predictor.SKLearn.Resources,
+		if src.Predictor.Tensorflow.StorageURI != nil {
+			dst.Default.Predictor.Tensorflow.StorageURI = *src.Predictor.Tensorflow.StorageURI
 		}
-		if src.Spec.Predictor.SKLearn.StorageURI != nil {
-			dst.Spec.Default.Predictor.SKLearn.StorageURI = *src.Spec.Predictor.SKLearn.StorageURI
+	} else if src.Predictor.SKLearn != nil {
+		dst.Default.Predictor.SKLearn = &SKLearnSpec{
+			RuntimeVersion: stringValue(src.Predictor.SKLearn.RuntimeVersion),
+			Resources:      src.Predictor.SKLearn.Resources,
 		}
-	} else if src.Spec.Predictor.XGBoost != nil {
-		dst.Spec.Default.Predictor.XGBoost = &XGBoostSpec{
-			RuntimeVersion: *src.Spec.Predictor.XGBoost.RuntimeVersion,
-			Resources:      src.Spec.Predictor.XGBoost.Resources,
+		if src.Predictor.SKLearn.StorageURI != nil {
+			dst.Default.Predictor.SKLearn.StorageURI = *src.Predictor.SKLearn.StorageURI
 		}
-		if src.Spec.Predictor.XGBoost.StorageURI != nil {
-			dst.Spec.Default.Predictor.XGBoost.StorageURI = *src.Spec.Predictor.XGBoost.StorageURI
+	} else if src.Predictor.XGBoost != nil {
+		dst.Default.Predictor.XGBoost = &XGBoostSpec{
+			RuntimeVersion: stringValue(src.Predictor.XGBoost.RuntimeVersion),
+			Resources:      src.Predictor.XGBoost.Resources,
 		}
-	} else if src.Spec.Predictor.Triton != nil {
-		dst.Spec.Default.Predictor.Triton = &TritonSpec{
-			RuntimeVersion: *src.Spec.Predictor.Triton.RuntimeVersion,
-			Resources:      src.Spec.Predictor.Triton.Resources,
+		if src.Predictor.XGBoost.StorageURI != nil {
+			dst.Default.Predictor.XGBoost.StorageURI = *src.Predictor.XGBoost.StorageURI
 		}
-		if src.Spec.Predictor.Triton.StorageURI != nil {
-			dst.Spec.Default.Predictor.Triton.StorageURI = *src.Spec.Predictor.Triton.StorageURI
+	} else if src.Predictor.Triton != nil {
+		dst.Default.Predictor.Triton = &TritonSpec{
+			RuntimeVersion: stringValue(src.Predictor.Triton.RuntimeVersion),
+			Resources:      src.Predictor.Triton.Resources,
 		}
-	} else if src.Spec.Predictor.ONNX != nil {
-		dst.Spec.Default.Predictor.ONNX = &ONNXSpec{
-			RuntimeVersion: *src.Spec.Predictor.ONNX.RuntimeVersion,
-			Resources:      src.Spec.Predictor.ONNX.Resources,
+		if src.Predictor.Triton.StorageURI != nil {
+			dst.Default.Predictor.Triton.StorageURI = *src.Predictor.Triton.StorageURI
 		}
-		if src.Spec.Predictor.ONNX.StorageURI != nil {
-			dst.Spec.Default.Predictor.ONNX.StorageURI = *src.Spec.Predictor.ONNX.StorageURI
+	} else if src.Predictor.ONNX != nil {
+		dst.Default.Predictor.ONNX = &ONNXSpec{
+			RuntimeVersion: stringValue(src.Predictor.ONNX.RuntimeVersion),
+			Resources:      src.Predictor.ONNX.Resources,
 		}
-	} else if src.Spec.Predictor.PyTorch != nil {
-		dst.Spec.Default.Predictor.PyTorch = &PyTorchSpec{
-			RuntimeVersion: *src.Spec.Predictor.PyTorch.RuntimeVersion,
-			Resources:      src.Spec.Predictor.PyTorch.Resources,
+		if src.Predictor.ONNX.StorageURI != nil {
+			dst.Default.Predictor.ONNX.StorageURI = *src.Predictor.ONNX.StorageURI
 		}
-		if src.Spec.Predictor.PyTorch.StorageURI != nil {
-			dst.Spec.Default.Predictor.PyTorch.StorageURI = *src.Spec.Predictor.PyTorch.StorageURI
+	} else if src.Predictor.PyTorch != nil {
+		dst.Default.Predictor.PyTorch = &PyTorchSpec{
+			RuntimeVersion: stringValue(src.Predictor.PyTorch.RuntimeVersion),
+			Resources:      src.Predictor.PyTorch.Resources,
 		}
-	} else if len(src.Spec.Predictor.PodSpec.Containers) != 0 {
-		dst.Spec.Default.Predictor.ServiceAccountName = src.Spec.Predictor.PodSpec.ServiceAccountName
-		dst.Spec.Default.Predictor.Custom = &CustomSpec{
-			src.Spec.Predictor.PodSpec.Containers[0],
+		if src.Predictor.PyTorch.StorageURI != nil {
+			dst.Default.Predictor.PyTorch.StorageURI = *src.Predictor.PyTorch.StorageURI
+		}
+	} else if len(src.Predictor.PodSpec.Containers) != 0 {
+		dst.Default.Predictor.ServiceAccountName = src.Predictor.PodSpec.ServiceAccountName
+		dst.Default.Predictor.Custom = &CustomSpec{
+			src.Predictor.PodSpec.Containers[0],
 		}
 	}
 
-	dst.Spec.Default.Predictor.MinReplicas = src.Spec.Predictor.MinReplicas
-	dst.Spec.Default.Predictor.MaxReplicas = src.Spec.Predictor.MaxReplicas
-	if src.Spec.Predictor.ContainerConcurrency != nil {
-		dst.Spec.Default.Predictor.Parallelism = int(*src.Spec.Predictor.ContainerConcurrency)
+	dst.Default.Predictor.MinReplicas = src.Predictor.MinReplicas
+	dst.Default.Predictor.MaxReplicas = src.Predictor.MaxReplicas
+	if src.Predictor.ContainerConcurrency != nil {
+		dst.Default.Predictor.Parallelism = int(*src.Predictor.ContainerConcurrency)
 	}
-	if src.Spec.Predictor.CanaryTrafficPercent != nil {
-		dst.Spec.CanaryTrafficPercent = GetIntReference(int(*src.Spec.Predictor.CanaryTrafficPercent))
+	if src.Predictor.CanaryTrafficPercent != nil {
+		dst.CanaryTrafficPercent = GetIntReference(int(*src.Predictor.CanaryTrafficPercent))
 	}
-	if src.Spec.Predictor.Batcher != nil {
-		dst.Spec.Default.Predictor.Batcher = &Batcher{
-			MaxBatchSize: src.Spec.Predictor.Batcher.MaxBatchSize,
-			MaxLatency:   src.Spec.Predictor.Batcher.MaxLatency,
-			Timeout:      src.Spec.Predictor.Batcher.Timeout,
+	if src.Predictor.Batcher != nil {
+		dst.Default.Predictor.Batcher = &Batcher{
+			MaxBatchSize: src.Predictor.Batcher.MaxBatchSize,
+			MaxLatency:   src.Predictor.Batcher.MaxLatency,
+			Timeout:      src.Predictor.Batcher.Timeout,
 		}
 	}
-	if src.Spec.Predictor.Logger != nil {
-		dst.Spec.Default.Predictor.Logger = &Logger{
-			Url:  src.Spec.Predictor.Logger.URL,
-			Mode: LoggerMode(src.Spec.Predictor.Logger.Mode),
+	if src.Predictor.Logger != nil {
+		dst.Default.Predictor.Logger = &Logger{
+			Url:  src.Predictor.Logger.URL,
+			Mode: LoggerMode(src.Predictor.Logger.Mode),
 		}
 	}
 	//Transformer
-	if src.Spec.Transformer != nil {
-		if len(src.Spec.Transformer.PodSpec.Containers) != 0 {
-			dst.Spec.Default.Transformer = &TransformerSpec{
-				Custom: &CustomSpec{
-					Container: src.Spec.Transformer.PodSpec.Containers[0],
-				},
+	if src.Transformer != nil {
+		dst.Default.Transformer = &TransformerSpec{}
+		if len(src.Transformer.PodSpec.Containers) != 0 {
+			dst.Default.Transformer.Custom = &CustomSpec{
+				Container: src.Transformer.PodSpec.Containers[0],
 			}
 		}
-		dst.Spec.Default.Transformer.MinReplicas = src.Spec.Transformer.MinReplicas
-		dst.Spec.Default.Transformer.MaxReplicas = src.Spec.Transformer.MaxReplicas
-		dst.Spec.Default.Transformer.ServiceAccountName = src.Spec.Transformer.PodSpec.ServiceAccountName
-		if src.Spec.Transformer.ContainerConcurrency != nil {
-			dst.Spec.Default.Transformer.Parallelism = int(*src.Spec.Transformer.ContainerConcurrency)
+		dst.Default.Transformer.MinReplicas = src.Transformer.MinReplicas
+		dst.Default.Transformer.MaxReplicas = src.Transformer.MaxReplicas
+		dst.Default.Transformer.ServiceAccountName = src.Transformer.PodSpec.ServiceAccountName
+		if src.Transformer.ContainerConcurrency != nil {
+			dst.Default.Transformer.Parallelism = int(*src.Transformer.ContainerConcurrency)
 		}
 	}
 	//Explainer
-	if src.Spec.Explainer != nil {
-		if src.Spec.Explainer.Alibi != nil {
-			dst.Spec.Default.Explainer = &ExplainerSpec{
+	if src.Explainer != nil {
+		if src.Explainer.Alibi != nil {
+			dst.Default.Explainer = &ExplainerSpec{
 				Alibi: &AlibiExplainerSpec{
-					Type:           AlibiExplainerType(src.Spec.Explainer.Alibi.Type),
-					StorageURI:     src.Spec.Explainer.Alibi.StorageURI,
-					RuntimeVersion: *src.Spec.Explainer.Alibi.RuntimeVersion,
-					Resources:      src.Spec.Explainer.Alibi.Resources,
+					Type:           AlibiExplainerType(src.Explainer.Alibi.Type),
+					StorageURI:     src.Explainer.Alibi.StorageURI,
+					RuntimeVersion: stringValue(src.Explainer.Alibi.RuntimeVersion),
+					Resources:      src.Explainer.Alibi.Resources,
 				},
 			}
-		} else if src.Spec.Explainer.AIX != nil {
-			dst.Spec.Default.Explainer = &ExplainerSpec{
+		} else if src.Explainer.AIX != nil {
+			dst.Default.Explainer = &ExplainerSpec{
 				AIX: &AIXExplainerSpec{
-					Type:           AIXExplainerType(src.Spec.Explainer.AIX.Type),
-					StorageURI:     src.Spec.Explainer.AIX.StorageURI,
-					RuntimeVersion: *src.Spec.Explainer.AIX.RuntimeVersion,
-					Resources:      src.Spec.Explainer.AIX.Resources,
+					Type:           AIXExplainerType(src.Explainer.AIX.Type),
+					StorageURI:     src.Explainer.AIX.StorageURI,
+					RuntimeVersion: stringValue(src.Explainer.AIX.RuntimeVersion),
+					Resources:      src.Explainer.AIX.Resources,
 				},
 			}
-		} else if len(src.Spec.Explainer.PodSpec.Containers) != 0 {
-			dst.Spec.Default.Explainer = &ExplainerSpec{
+		} else if len(src.Explainer.PodSpec.Containers) != 0 {
+			dst.Default.Explainer = &ExplainerSpec{
 				Custom: &CustomSpec{
-					Container: src.Spec.Explainer.PodSpec.Containers[0],
+					Container: src.Explainer.PodSpec.Containers[0],
 				},
 			}
+		} else {
+			dst.Default.Explainer = &ExplainerSpec{}
 		}
-		dst.Spec.Default.Explainer.ServiceAccountName = src.Spec.Explainer.PodSpec.ServiceAccountName
-		dst.Spec.Default.Explainer.MinReplicas = src.Spec.Explainer.MinReplicas
-		dst.Spec.Default.Explainer.MaxReplicas = src.Spec.Explainer.MaxReplicas
-		if src.Spec.Explainer.ContainerConcurrency != nil {
-			dst.Spec.Default.Explainer.Parallelism = int(*src.Spec.Explainer.ContainerConcurrency)
+		dst.Default.Explainer.ServiceAccountName = src.Explainer.PodSpec.ServiceAccountName
+		dst.Default.Explainer.MinReplicas = src.Explainer.MinReplicas
+		dst.Default.Explainer.MaxReplicas = src.Explainer.MaxReplicas
+		if src.Explainer.ContainerConcurrency != nil {
+			dst.Default.Explainer.Parallelism = int(*src.Explainer.ContainerConcurrency)
 		}
 	}
+	// The latest revision of the components receiving the canary traffic, the v1alpha2 canary endpoint is the same
+	// as the default one as the spec of the previous revision is not kept in v1beta1
+	if dst.CanaryTrafficPercent != nil {
+		dst.Canary = dst.Default.DeepCopy()
+	}
+}
+
+// keepSpec keeps the spec in the annotation when the spec converted back from the other version differs, i.e. when the
+// conversion loses some of the fields of the spec, so they are restored when the InferenceService is converted back
+func keepSpec(meta *metav1.ObjectMeta, key string, spec interface{}, converted interface{}) error {
+	if equality.Semantic.DeepEqual(spec, converted) {
+		return nil
+	}
+	value, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to keep the spec in the %s annotation: %v", key, err)
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[key] = string(value)
 	return nil
 }
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func removeAnnotation(meta *metav1.ObjectMeta, key string) {
+	delete(meta.Annotations, key)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// convertStatusTo converts the status of the default and canary endpoints to the previous and latest ready revisions
+// of the components
+func convertStatusTo(src *InferenceServiceStatus, dst *v1beta1.InferenceServiceStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.Conditions = duckv1.Conditions(src.Conditions)
+	if src.URL != "" {
+		if url, err := apis.ParseURL(src.URL); err == nil {
+			dst.URL = url
+		}
+	}
+	if src.Address != nil {
+		dst.Address = &duckv1.Addressable{URL: src.Address.URL}
+	}
+	if src.Default == nil {
+		return
+	}
+	dst.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{}
+	for component, status := range *src.Default {
+		componentStatus := v1beta1.ComponentStatusSpec{
+			LatestReadyRevision: status.Name,
+			TrafficPercent:      proto.Int64(int64(src.Traffic)),
+		}
+		if src.Canary != nil {
+			if canary, ok := (*src.Canary)[component]; ok {
+				componentStatus.PreviousReadyRevision = status.Name
+				componentStatus.LatestReadyRevision = canary.Name
+				componentStatus.TrafficPercent = proto.Int64(int64(src.CanaryTraffic))
+			}
+		}
+		if status.Hostname != "" {
+			componentStatus.URL = &apis.URL{Scheme: "http", Host: status.Hostname}
+		}
+		dst.Components[v1beta1.ComponentType(component)] = componentStatus
+	}
+}
+
+// convertStatusFrom converts the status of the components to the default and canary endpoints, the latest ready
+// revision of a component being its canary while it does not receive all the traffic
+func convertStatusFrom(src *v1beta1.InferenceServiceStatus, dst *InferenceServiceStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.Conditions = duckv1beta1.Conditions(src.Conditions)
+	if src.URL != nil {
+		dst.URL = src.URL.String()
+	}
+	if src.Address != nil {
+		dst.Address = &duckv1beta1.Addressable{URL: src.Address.URL}
+	}
+	if len(src.Components) == 0 {
+		return
+	}
+	defaults := map[constants.InferenceServiceComponent]StatusConfigurationSpec{}
+	canaries := map[constants.InferenceServiceComponent]StatusConfigurationSpec{}
+	for component, status := range src.Components {
+		var hostname string
+		if status.URL != nil {
+			hostname = status.URL.Host
+		}
+		traffic := 100
+		if status.TrafficPercent != nil {
+			traffic = int(*status.TrafficPercent)
+		}
+		canary := status.PreviousReadyRevision != "" && traffic < 100
+		if canary {
+			defaults[constants.InferenceServiceComponent(component)] = StatusConfigurationSpec{
+				Name:     status.PreviousReadyRevision,
+				Hostname: hostname,
+			}
+			canaries[constants.InferenceServiceComponent(component)] = StatusConfigurationSpec{
+				Name:     status.LatestReadyRevision,
+				Hostname: hostname,
+			}
+		} else {
+			defaults[constants.InferenceServiceComponent(component)] = StatusConfigurationSpec{
+				Name:     status.LatestReadyRevision,
+				Hostname: hostname,
+			}
+		}
+		if component == v1beta1.PredictorComponent {
+			dst.Traffic = traffic
+			if canary {
+				dst.Traffic = 100 - traffic
+				dst.CanaryTraffic = traffic
+			}
+		}
+	}
+	dst.Default = &defaults
+	if len(canaries) != 0 {
+		dst.Canary = &canaries
+	}
+}
@@ -1,13 +1,18 @@
 package v1alpha2
 
 import (
+	"encoding/json"
+	"testing"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/google/go-cmp/cmp"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
 )
 
 func TestInferenceServiceConversion(t *testing.T) {
@@ -265,6 +270,92 @@ func TestInferenceServiceConversion(t *testing.T) {
 				},
 			},
 		},
+		"customExplainer": {
+			v1alpha2spec: &InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "custom-explainer",
+					Namespace: "default",
+				},
+				Spec: InferenceServiceSpec{
+					Default: EndpointSpec{
+						Predictor: PredictorSpec{
+							DeploymentSpec: DeploymentSpec{
+								MinReplicas: GetIntReference(1),
+								MaxReplicas: 3,
+								Parallelism: 1,
+							},
+							Tensorflow: &TensorflowSpec{
+								StorageURI:     "s3://test/mnist/export",
+								RuntimeVersion: "1.13.0",
+							},
+						},
+						Explainer: &ExplainerSpec{
+							DeploymentSpec: DeploymentSpec{
+								ServiceAccountName: "explainer-sa",
+								MinReplicas:        GetIntReference(1),
+								MaxReplicas:        2,
+								Parallelism:        4,
+							},
+							Custom: &CustomSpec{
+								Container: v1.Container{
+									Name:  "kfserving-container",
+									Image: "custom-explainer:v1",
+									Env: []v1.EnvVar{
+										{
+											Name:  "STORAGE_URI",
+											Value: "s3://test/mnist/explainer",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			v1beta1Spec: &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "custom-explainer",
+					Namespace: "default",
+				},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor: v1beta1.PredictorSpec{
+						ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+							MinReplicas:          GetIntReference(1),
+							MaxReplicas:          3,
+							ContainerConcurrency: proto.Int64(1),
+						},
+						Tensorflow: &v1beta1.TFServingSpec{
+							PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+								StorageURI:     proto.String("s3://test/mnist/export"),
+								RuntimeVersion: proto.String("1.13.0"),
+							},
+						},
+					},
+					Explainer: &v1beta1.ExplainerSpec{
+						ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+							MinReplicas:          GetIntReference(1),
+							MaxReplicas:          2,
+							ContainerConcurrency: proto.Int64(4),
+						},
+						PodSpec: v1beta1.PodSpec{
+							ServiceAccountName: "explainer-sa",
+							Containers: []v1.Container{
+								{
+									Name:  "kfserving-container",
+									Image: "custom-explainer:v1",
+									Env: []v1.EnvVar{
+										{
+											Name:  "STORAGE_URI",
+											Value: "s3://test/mnist/explainer",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for name, scenario := range scenarios {
 		t.Run(name, func(t *testing.T) {
@@ -281,3 +372,357 @@ func TestInferenceServiceConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestInferenceServiceCanaryConversion(t *testing.T) {
+	v1alpha2spec := &InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "canary",
+			Namespace: "default",
+		},
+		Spec: InferenceServiceSpec{
+			Default: EndpointSpec{
+				Predictor: PredictorSpec{
+					SKLearn: &SKLearnSpec{
+						StorageURI:     "gs://kfserving-samples/models/sklearn/iris",
+						RuntimeVersion: "0.1.0",
+					},
+				},
+			},
+			Canary: &EndpointSpec{
+				Predictor: PredictorSpec{
+					SKLearn: &SKLearnSpec{
+						StorageURI:     "gs://kfserving-samples/models/sklearn/iris-v2",
+						RuntimeVersion: "0.1.0",
+					},
+				},
+				Transformer: &TransformerSpec{
+					Custom: &CustomSpec{
+						Container: v1.Container{
+							Image: "transformer:v2",
+						},
+					},
+				},
+			},
+			CanaryTrafficPercent: GetIntReference(20),
+		},
+		Status: InferenceServiceStatus{
+			URL:           "http://canary.default.example.com",
+			Traffic:       80,
+			CanaryTraffic: 20,
+			Default: &map[constants.InferenceServiceComponent]StatusConfigurationSpec{
+				constants.Predictor: {
+					Name:     "canary-predictor-default-00001",
+					Hostname: "canary-predictor-default.default.example.com",
+				},
+			},
+			Canary: &map[constants.InferenceServiceComponent]StatusConfigurationSpec{
+				constants.Predictor: {
+					Name:     "canary-predictor-default-00002",
+					Hostname: "canary-predictor-default.default.example.com",
+				},
+			},
+		},
+	}
+	keptSpec, err := json.Marshal(v1alpha2spec.Spec)
+	if err != nil {
+		t.Fatalf("failed to marshal the spec: %v", err)
+	}
+	expectedV1beta1 := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "canary",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.V1Alpha2SpecAnnotationKey: string(keptSpec),
+			},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					ContainerConcurrency: proto.Int64(0),
+					CanaryTrafficPercent: proto.Int64(20),
+				},
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI:     proto.String("gs://kfserving-samples/models/sklearn/iris-v2"),
+						RuntimeVersion: proto.String("0.1.0"),
+					},
+				},
+			},
+			Transformer: &v1beta1.TransformerSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					ContainerConcurrency: proto.Int64(0),
+					CanaryTrafficPercent: proto.Int64(20),
+				},
+				PodSpec: v1beta1.PodSpec{
+					Containers: []v1.Container{{Image: "transformer:v2"}},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			URL: &apis.URL{Scheme: "http", Host: "canary.default.example.com"},
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision:   "canary-predictor-default-00002",
+					PreviousReadyRevision: "canary-predictor-default-00001",
+					TrafficPercent:        proto.Int64(20),
+					URL:                   &apis.URL{Scheme: "http", Host: "canary-predictor-default.default.example.com"},
+				},
+			},
+		},
+	}
+
+	dst := &v1beta1.InferenceService{}
+	if err := v1alpha2spec.ConvertTo(dst); err != nil {
+		t.Fatalf("failed to convert to v1beta1: %v", err)
+	}
+	if diff := cmp.Diff(expectedV1beta1, dst); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// The default endpoint lost by the conversion is restored from the annotation
+	src := &InferenceService{}
+	if err := src.ConvertFrom(dst); err != nil {
+		t.Fatalf("failed to convert from v1beta1: %v", err)
+	}
+	if diff := cmp.Diff(v1alpha2spec, src); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	// The spec changed in v1beta1 since is not restored, the canary endpoint being then the same as the default one
+	dst.Spec.Predictor.SKLearn.StorageURI = proto.String("gs://kfserving-samples/models/sklearn/iris-v3")
+	src = &InferenceService{}
+	if err := src.ConvertFrom(dst); err != nil {
+		t.Fatalf("failed to convert from v1beta1: %v", err)
+	}
+	if _, ok := src.Annotations[constants.V1Alpha2SpecAnnotationKey]; ok {
+		t.Errorf("expected the %s annotation to be removed", constants.V1Alpha2SpecAnnotationKey)
+	}
+	if e, a := "gs://kfserving-samples/models/sklearn/iris-v3", src.Spec.Default.Predictor.SKLearn.StorageURI; e != a {
+		t.Errorf("expected default storage uri %s, got %s", e, a)
+	}
+	if diff := cmp.Diff(&src.Spec.Default, src.Spec.Canary); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
+
+func TestInferenceServiceRoundTrip(t *testing.T) {
+	extension := v1beta1.PredictorExtensionSpec{
+		StorageURI:     proto.String("gs://kfserving-samples/models/model"),
+		RuntimeVersion: proto.String("0.5.0"),
+		Container: v1.Container{
+			Env: []v1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+			Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+			},
+		},
+	}
+	componentExtension := v1beta1.ComponentExtensionSpec{
+		MinReplicas:          GetIntReference(0),
+		MaxReplicas:          5,
+		TimeoutSeconds:       proto.Int64(30),
+		CanaryTrafficPercent: proto.Int64(10),
+		Logger: &v1beta1.LoggerSpec{
+			URL:  proto.String("http://logger.default"),
+			Mode: v1beta1.LogAll,
+		},
+	}
+	container := v1.Container{
+		Name:  "kfserving-container",
+		Image: "custom:v1",
+		Args:  []string{"--port", "8080"},
+	}
+	sklearnFlavor := v1beta1.MLflowSKLearnFlavor
+	v1beta1Specs := map[string]v1beta1.InferenceServiceSpec{
+		"sklearn":    {Predictor: v1beta1.PredictorSpec{SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension}}},
+		"xgboost":    {Predictor: v1beta1.PredictorSpec{XGBoost: &v1beta1.XGBoostSpec{PredictorExtensionSpec: extension}}},
+		"lightgbm":   {Predictor: v1beta1.PredictorSpec{LightGBM: &v1beta1.LightGBMSpec{PredictorExtensionSpec: extension}}},
+		"tensorflow": {Predictor: v1beta1.PredictorSpec{Tensorflow: &v1beta1.TFServingSpec{PredictorExtensionSpec: extension}}},
+		"pytorch": {Predictor: v1beta1.PredictorSpec{PyTorch: &v1beta1.TorchServeSpec{
+			ModelClassName:         "Net",
+			PredictorExtensionSpec: extension,
+		}}},
+		"triton": {Predictor: v1beta1.PredictorSpec{Triton: &v1beta1.TritonSpec{PredictorExtensionSpec: extension}}},
+		"onnx":   {Predictor: v1beta1.PredictorSpec{ONNX: &v1beta1.ONNXRuntimeSpec{PredictorExtensionSpec: extension}}},
+		"pmml":   {Predictor: v1beta1.PredictorSpec{PMML: &v1beta1.PMMLSpec{PredictorExtensionSpec: extension}}},
+		"paddle": {Predictor: v1beta1.PredictorSpec{Paddle: &v1beta1.PaddleSpec{PredictorExtensionSpec: extension}}},
+		"mlflow": {Predictor: v1beta1.PredictorSpec{MLflow: &v1beta1.MLflowSpec{
+			Flavor:                 &sklearnFlavor,
+			PredictorExtensionSpec: extension,
+		}}},
+		"model": {Predictor: v1beta1.PredictorSpec{Model: &v1beta1.ModelPredictorSpec{
+			ModelFormat:            v1beta1.ModelFormat{Name: "sklearn", Version: proto.String("0.23")},
+			Runtime:                proto.String("sklearn-runtime"),
+			PredictorExtensionSpec: extension,
+		}}},
+		"predictorExtensions": {Predictor: v1beta1.PredictorSpec{
+			SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension},
+			GPU:     &v1beta1.GPUSpec{Type: "nvidia.com/mig-1g.5gb", Count: proto.Int64(1)},
+			Warmup: &v1beta1.WarmupSpec{
+				Requests:   []v1beta1.WarmupRequest{{Body: runtime.RawExtension{Raw: []byte(`{"instances":[[1,2]]}`)}}},
+				Iterations: GetIntReference(3),
+			},
+			Rollout: &v1beta1.RolloutSpec{ProgressDeadlineSeconds: GetIntReference(300)},
+			ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+				RevisionAnnotations: map[string]string{"autoscaling.knative.dev/window": "120s"},
+			},
+		}},
+		"customPredictor": {Predictor: v1beta1.PredictorSpec{
+			PodSpec: v1beta1.PodSpec{
+				ServiceAccountName: "predictor-sa",
+				Containers:         []v1.Container{container, {Name: "sidecar", Image: "sidecar:v1"}},
+			},
+			ComponentExtensionSpec: componentExtension,
+		}},
+		"transformer": {
+			Predictor: v1beta1.PredictorSpec{SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension}},
+			Transformer: &v1beta1.TransformerSpec{
+				PodSpec: v1beta1.PodSpec{
+					ServiceAccountName: "transformer-sa",
+					Containers:         []v1.Container{container},
+				},
+				ComponentExtensionSpec: componentExtension,
+			},
+		},
+		"alibiExplainer": {
+			Predictor: v1beta1.PredictorSpec{SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension}},
+			Explainer: &v1beta1.ExplainerSpec{
+				Alibi: &v1beta1.AlibiExplainerSpec{
+					Type:       v1beta1.AlibiAnchorsTabularExplainer,
+					StorageURI: "gs://kfserving-samples/models/explainer",
+					Config:     map[string]string{"threshold": "0.9"},
+				},
+				ComponentExtensionSpec: componentExtension,
+			},
+		},
+		"aixExplainer": {
+			Predictor: v1beta1.PredictorSpec{SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension}},
+			Explainer: &v1beta1.ExplainerSpec{
+				AIX: &v1beta1.AIXExplainerSpec{
+					Type:           v1beta1.AIXLimeImageExplainer,
+					RuntimeVersion: proto.String("0.2.2"),
+				},
+			},
+		},
+		"customExplainer": {
+			Predictor: v1beta1.PredictorSpec{SKLearn: &v1beta1.SKLearnSpec{PredictorExtensionSpec: extension}},
+			Explainer: &v1beta1.ExplainerSpec{
+				PodSpec: v1beta1.PodSpec{Containers: []v1.Container{container}},
+			},
+		},
+	}
+	for name, spec := range v1beta1Specs {
+		t.Run("v1beta1/"+name, func(t *testing.T) {
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       spec,
+			}
+			converted := &InferenceService{}
+			if err := converted.ConvertFrom(isvc); err != nil {
+				t.Fatalf("failed to convert from v1beta1: %v", err)
+			}
+			restored := &v1beta1.InferenceService{}
+			if err := converted.ConvertTo(restored); err != nil {
+				t.Fatalf("failed to convert to v1beta1: %v", err)
+			}
+			if diff := cmp.Diff(isvc, restored); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+
+	deployment := DeploymentSpec{
+		ServiceAccountName: "sa",
+		MinReplicas:        GetIntReference(1),
+		MaxReplicas:        3,
+		Parallelism:        2,
+	}
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	v1alpha2Specs := map[string]InferenceServiceSpec{
+		"tensorflow": {Default: EndpointSpec{Predictor: PredictorSpec{
+			Tensorflow:     &TensorflowSpec{StorageURI: "gs://model", RuntimeVersion: "1.14.0", Resources: resources},
+			DeploymentSpec: deployment,
+		}}},
+		"triton": {Default: EndpointSpec{Predictor: PredictorSpec{
+			Triton:         &TritonSpec{StorageURI: "gs://model", RuntimeVersion: "19.05-py3", Resources: resources},
+			DeploymentSpec: deployment,
+		}}},
+		"xgboost": {Default: EndpointSpec{Predictor: PredictorSpec{
+			XGBoost:        &XGBoostSpec{StorageURI: "gs://model", NThread: 4, Resources: resources},
+			DeploymentSpec: deployment,
+		}}},
+		"sklearn": {Default: EndpointSpec{Predictor: PredictorSpec{
+			SKLearn:        &SKLearnSpec{StorageURI: "gs://model", Resources: resources},
+			DeploymentSpec: deployment,
+		}}},
+		"onnx": {Default: EndpointSpec{Predictor: PredictorSpec{
+			ONNX:           &ONNXSpec{StorageURI: "gs://model", RuntimeVersion: "v0.5.0"},
+			DeploymentSpec: deployment,
+		}}},
+		"pytorch": {Default: EndpointSpec{Predictor: PredictorSpec{
+			PyTorch:        &PyTorchSpec{StorageURI: "gs://model", ModelClassName: "Net"},
+			DeploymentSpec: deployment,
+		}}},
+		"customPredictor": {Default: EndpointSpec{Predictor: PredictorSpec{
+			Custom:         &CustomSpec{Container: container},
+			DeploymentSpec: deployment,
+		}}},
+		"transformer": {Default: EndpointSpec{
+			Predictor:   PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model"}},
+			Transformer: &TransformerSpec{Custom: &CustomSpec{Container: container}, DeploymentSpec: deployment},
+		}},
+		"alibiExplainer": {Default: EndpointSpec{
+			Predictor: PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model"}},
+			Explainer: &ExplainerSpec{
+				Alibi: &AlibiExplainerSpec{
+					Type:      AlibiAnchorsTabularExplainer,
+					Resources: resources,
+					Config:    map[string]string{"threshold": "0.9"},
+				},
+				DeploymentSpec: deployment,
+			},
+		}},
+		"aixExplainer": {Default: EndpointSpec{
+			Predictor: PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model"}},
+			Explainer: &ExplainerSpec{
+				AIX:            &AIXExplainerSpec{Type: AIXLimeImageExplainer, Config: map[string]string{"top_labels": "10"}},
+				DeploymentSpec: deployment,
+			},
+		}},
+		"customExplainer": {Default: EndpointSpec{
+			Predictor: PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model"}},
+			Explainer: &ExplainerSpec{Custom: &CustomSpec{Container: container}, DeploymentSpec: deployment},
+		}},
+		"canary": {
+			Default: EndpointSpec{
+				Predictor: PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model"}},
+				Explainer: &ExplainerSpec{Alibi: &AlibiExplainerSpec{Type: AlibiAnchorsTabularExplainer}},
+			},
+			Canary: &EndpointSpec{
+				Predictor:   PredictorSpec{SKLearn: &SKLearnSpec{StorageURI: "gs://model-v2"}},
+				Transformer: &TransformerSpec{Custom: &CustomSpec{Container: container}},
+			},
+			CanaryTrafficPercent: GetIntReference(10),
+		},
+	}
+	for name, spec := range v1alpha2Specs {
+		t.Run("v1alpha2/"+name, func(t *testing.T) {
+			isvc := &InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       spec,
+			}
+			converted := &v1beta1.InferenceService{}
+			if err := isvc.ConvertTo(converted); err != nil {
+				t.Fatalf("failed to convert to v1beta1: %v", err)
+			}
+			restored := &InferenceService{}
+			if err := restored.ConvertFrom(converted); err != nil {
+				t.Fatalf("failed to convert from v1beta1: %v", err)
+			}
+			if diff := cmp.Diff(isvc, restored); diff != "" {
+				t.Errorf("diff: %s", diff)
+			}
+		})
+	}
+}
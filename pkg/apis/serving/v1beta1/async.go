@@ -0,0 +1,205 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Known error messages
+const (
+	AsyncRedisAddressRequiredError  = "Async redisAddress is required by the redis queue."
+	AsyncWorkersLowerBoundError     = "Async workers cannot be less than 1."
+	AsyncTimeoutLowerBoundError     = "Async timeoutSeconds cannot be less than 1."
+	AsyncResultTTLLowerBoundError   = "Async resultTTLSeconds cannot be less than 1."
+	AsyncQueueDepthTargetQueueError = "Async queueDepthTarget requires the redis queue, the memory queue of a replica is not visible to the autoscaler."
+	AsyncQueueDepthTargetKedaError  = "Async queueDepthTarget cannot be set with keda, add a trigger on the queue to the keda triggers."
+	AsyncBatcherLoggerConflictError = "Async cannot be set with the batcher or the logger of the predictor."
+	AsyncQueueDepthLowerBoundError  = "Async %s cannot be less than %d."
+	AsyncMemoryQueueReplicasError   = "Async memory queue requires a single replica, the requests and the results of a replica are lost when it is scaled down. Use the redis queue to scale the predictor."
+	AsyncInvalidCallbackHostError   = "Async callbackHosts %q is invalid, a host name or a *. wildcard of the subdomains of a host name is required."
+)
+
+// Defaults of the async inference
+const (
+	DefaultAsyncWorkers          = 1
+	DefaultAsyncTimeoutSeconds   = 300
+	DefaultAsyncResultTTLSeconds = 3600
+)
+
+// AsyncQueueType is the backend of the queue of the async requests
+// +kubebuilder:validation:Enum=memory;redis
+type AsyncQueueType string
+
+// AsyncQueueType Enum
+const (
+	// AsyncMemoryQueue keeps the requests and the results in the memory of each replica, the predictor runs a single
+	// replica so the results are reachable and the queued requests are not lost on scale down
+	AsyncMemoryQueue AsyncQueueType = "memory"
+	// AsyncRedisQueue keeps the requests in a Redis list shared by the replicas and the results in Redis keys
+	AsyncRedisQueue AsyncQueueType = "redis"
+)
+
+// AsyncSpec defines the asynchronous inference of the predictor. The requests are accepted by a frontend injected in
+// the predictor pods, which answers with the ID of the request and queues it. The queued requests are sent to the
+// predictor and their results are posted to the callback URL of the request or kept until fetched by their ID.
+type AsyncSpec struct {
+	// Queue backend, defaults to memory. The predictor runs a single replica with the memory queue.
+	// +optional
+	Queue AsyncQueueType `json:"queue,omitempty"`
+	// RedisAddress is the host:port of the Redis server of the redis queue
+	// +optional
+	RedisAddress string `json:"redisAddress,omitempty"`
+	// Workers is the number of queued requests each replica sends to the predictor concurrently, defaults to 1
+	// +optional
+	Workers *int `json:"workers,omitempty"`
+	// MaxQueueDepth is the number of queued requests beyond which the requests are rejected with a 503, defaults to
+	// no limit
+	// +optional
+	MaxQueueDepth *int `json:"maxQueueDepth,omitempty"`
+	// TimeoutSeconds of a request sent to the predictor, defaults to 300
+	// +optional
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty"`
+	// ResultTTLSeconds is the time the results are kept for, defaults to 3600
+	// +optional
+	ResultTTLSeconds *int `json:"resultTTLSeconds,omitempty"`
+	// QueueDepthTarget scales the predictor with KEDA on the depth of the redis queue, targeting the number of queued
+	// requests per replica. The accepted requests return immediately and are not seen by the Knative autoscaler.
+	// +optional
+	QueueDepthTarget *int `json:"queueDepthTarget,omitempty"`
+	// CallbackHosts are the hosts the results can be posted to with the X-Callback-Url header, e.g. hooks.example.com
+	// or *.example.com for its subdomains. The requests with a callback URL are rejected when empty.
+	// +optional
+	CallbackHosts []string `json:"callbackHosts,omitempty"`
+}
+
+// GetQueue returns the queue backend
+func (a *AsyncSpec) GetQueue() AsyncQueueType {
+	if a.Queue == "" {
+		return AsyncMemoryQueue
+	}
+	return a.Queue
+}
+
+// GetWorkers returns the number of requests each replica sends to the predictor concurrently
+func (a *AsyncSpec) GetWorkers() int {
+	if a.Workers == nil {
+		return DefaultAsyncWorkers
+	}
+	return *a.Workers
+}
+
+// GetTimeoutSeconds returns the timeout of a request sent to the predictor
+func (a *AsyncSpec) GetTimeoutSeconds() int {
+	if a.TimeoutSeconds == nil {
+		return DefaultAsyncTimeoutSeconds
+	}
+	return *a.TimeoutSeconds
+}
+
+// GetResultTTLSeconds returns the time the results are kept for
+func (a *AsyncSpec) GetResultTTLSeconds() int {
+	if a.ResultTTLSeconds == nil {
+		return DefaultAsyncResultTTLSeconds
+	}
+	return *a.ResultTTLSeconds
+}
+
+// Validate returns an error if invalid
+func (a *AsyncSpec) Validate() error {
+	if a.GetQueue() == AsyncRedisQueue && a.RedisAddress == "" {
+		return fmt.Errorf(AsyncRedisAddressRequiredError)
+	}
+	if a.GetWorkers() < 1 {
+		return fmt.Errorf(AsyncWorkersLowerBoundError)
+	}
+	if a.GetTimeoutSeconds() < 1 {
+		return fmt.Errorf(AsyncTimeoutLowerBoundError)
+	}
+	if a.GetResultTTLSeconds() < 1 {
+		return fmt.Errorf(AsyncResultTTLLowerBoundError)
+	}
+	if a.MaxQueueDepth != nil && *a.MaxQueueDepth < 0 {
+		return fmt.Errorf(AsyncQueueDepthLowerBoundError, "maxQueueDepth", 0)
+	}
+	for _, host := range a.CallbackHosts {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(host, "*.")); len(errs) != 0 {
+			return fmt.Errorf(AsyncInvalidCallbackHostError, host)
+		}
+	}
+	if a.QueueDepthTarget != nil {
+		if a.GetQueue() != AsyncRedisQueue {
+			return fmt.Errorf(AsyncQueueDepthTargetQueueError)
+		}
+		if *a.QueueDepthTarget < 1 {
+			return fmt.Errorf(AsyncQueueDepthLowerBoundError, "queueDepthTarget", 1)
+		}
+	}
+	return nil
+}
+
+// validateAsync validates the async inference of the predictor
+func validateAsync(predictor *PredictorSpec) error {
+	if predictor.Async == nil {
+		return nil
+	}
+	// The batcher and the logger take the port of the predictor the frontend receives the requests on
+	if predictor.Batcher != nil || predictor.Logger != nil {
+		return fmt.Errorf(AsyncBatcherLoggerConflictError)
+	}
+	if predictor.Async.QueueDepthTarget != nil && predictor.Keda != nil {
+		return fmt.Errorf(AsyncQueueDepthTargetKedaError)
+	}
+	if predictor.Async.GetQueue() == AsyncMemoryQueue && (predictor.MinReplicas != nil && *predictor.MinReplicas != 1 ||
+		predictor.MaxReplicas > 1 || predictor.Keda != nil || predictor.Scaling != nil) {
+		return fmt.Errorf(AsyncMemoryQueueReplicasError)
+	}
+	return predictor.Async.Validate()
+}
+
+// WithAsyncScaling returns the component extensions scaled with KEDA on the length of the redis list of the queue
+// when the async inference sets a queue depth target, and pinned to a single replica with the memory queue. The
+// extensions are returned as is otherwise.
+func (s *ComponentExtensionSpec) WithAsyncScaling(async *AsyncSpec, queueName string) *ComponentExtensionSpec {
+	if async != nil && async.GetQueue() == AsyncMemoryQueue {
+		extensions := s.DeepCopy()
+		extensions.MinReplicas = GetIntReference(1)
+		extensions.MaxReplicas = 1
+		return extensions
+	}
+	if async == nil || async.QueueDepthTarget == nil || async.GetQueue() != AsyncRedisQueue || s.Keda != nil {
+		return s
+	}
+	extensions := s.DeepCopy()
+	extensions.Keda = &KedaSpec{
+		Triggers: []KedaTrigger{
+			{
+				Type: "redis",
+				Metadata: map[string]string{
+					"address":    async.RedisAddress,
+					"listName":   queueName,
+					"listLength": strconv.Itoa(*async.QueueDepthTarget),
+				},
+			},
+		},
+	}
+	return extensions
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestAsyncValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		predictor *PredictorSpec
+		matcher   types.GomegaMatcher
+	}{
+		"NoAsync": {
+			predictor: &PredictorSpec{},
+			matcher:   gomega.BeNil(),
+		},
+		"MemoryQueue": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{Workers: GetIntReference(4), MaxQueueDepth: GetIntReference(100)}},
+			matcher:   gomega.BeNil(),
+		},
+		"RedisQueue": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{Queue: AsyncRedisQueue, RedisAddress: "redis:6379",
+				QueueDepthTarget: GetIntReference(10)}},
+			matcher: gomega.BeNil(),
+		},
+		"MissingRedisAddress": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{Queue: AsyncRedisQueue}},
+			matcher:   gomega.MatchError(AsyncRedisAddressRequiredError),
+		},
+		"ZeroWorkers": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{Workers: GetIntReference(0)}},
+			matcher:   gomega.MatchError(AsyncWorkersLowerBoundError),
+		},
+		"ZeroTimeout": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{TimeoutSeconds: GetIntReference(0)}},
+			matcher:   gomega.MatchError(AsyncTimeoutLowerBoundError),
+		},
+		"ZeroResultTTL": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{ResultTTLSeconds: GetIntReference(0)}},
+			matcher:   gomega.MatchError(AsyncResultTTLLowerBoundError),
+		},
+		"NegativeMaxQueueDepth": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{MaxQueueDepth: GetIntReference(-1)}},
+			matcher:   gomega.MatchError(fmt.Sprintf(AsyncQueueDepthLowerBoundError, "maxQueueDepth", 0)),
+		},
+		"QueueDepthTargetMemoryQueue": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{QueueDepthTarget: GetIntReference(10)}},
+			matcher:   gomega.MatchError(AsyncQueueDepthTargetQueueError),
+		},
+		"ZeroQueueDepthTarget": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{Queue: AsyncRedisQueue, RedisAddress: "redis:6379",
+				QueueDepthTarget: GetIntReference(0)}},
+			matcher: gomega.MatchError(fmt.Sprintf(AsyncQueueDepthLowerBoundError, "queueDepthTarget", 1)),
+		},
+		"QueueDepthTargetWithKeda": {
+			predictor: &PredictorSpec{
+				Async: &AsyncSpec{Queue: AsyncRedisQueue, RedisAddress: "redis:6379", QueueDepthTarget: GetIntReference(10)},
+				ComponentExtensionSpec: ComponentExtensionSpec{
+					Keda: &KedaSpec{Triggers: []KedaTrigger{{Type: "cron"}}},
+				},
+			},
+			matcher: gomega.MatchError(AsyncQueueDepthTargetKedaError),
+		},
+		"MemoryQueueScaled": {
+			predictor: &PredictorSpec{
+				Async:                  &AsyncSpec{},
+				ComponentExtensionSpec: ComponentExtensionSpec{MaxReplicas: 3},
+			},
+			matcher: gomega.MatchError(AsyncMemoryQueueReplicasError),
+		},
+		"MemoryQueueScaledToZero": {
+			predictor: &PredictorSpec{
+				Async:                  &AsyncSpec{},
+				ComponentExtensionSpec: ComponentExtensionSpec{MinReplicas: GetIntReference(0)},
+			},
+			matcher: gomega.MatchError(AsyncMemoryQueueReplicasError),
+		},
+		"MemoryQueueSingleReplica": {
+			predictor: &PredictorSpec{
+				Async:                  &AsyncSpec{},
+				ComponentExtensionSpec: ComponentExtensionSpec{MinReplicas: GetIntReference(1), MaxReplicas: 1},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"CallbackHosts": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{CallbackHosts: []string{"hooks.example.com", "*.example.org"}}},
+			matcher:   gomega.BeNil(),
+		},
+		"InvalidCallbackHost": {
+			predictor: &PredictorSpec{Async: &AsyncSpec{CallbackHosts: []string{"https://hooks.example.com"}}},
+			matcher:   gomega.MatchError(fmt.Sprintf(AsyncInvalidCallbackHostError, "https://hooks.example.com")),
+		},
+		"WithBatcher": {
+			predictor: &PredictorSpec{
+				Async:                  &AsyncSpec{},
+				ComponentExtensionSpec: ComponentExtensionSpec{Batcher: &Batcher{}},
+			},
+			matcher: gomega.MatchError(AsyncBatcherLoggerConflictError),
+		},
+		"WithLogger": {
+			predictor: &PredictorSpec{
+				Async:                  &AsyncSpec{},
+				ComponentExtensionSpec: ComponentExtensionSpec{Logger: &LoggerSpec{}},
+			},
+			matcher: gomega.MatchError(AsyncBatcherLoggerConflictError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateAsync(scenario.predictor)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestWithAsyncScaling(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	redis := &AsyncSpec{Queue: AsyncRedisQueue, RedisAddress: "redis:6379", QueueDepthTarget: GetIntReference(10)}
+	extensions := &ComponentExtensionSpec{MinReplicas: GetIntReference(1), MaxReplicas: 5}
+
+	scaled := extensions.WithAsyncScaling(redis, "kfserving:async:default:sklearn")
+	g.Expect(scaled.Keda).To(gomega.Equal(&KedaSpec{
+		Triggers: []KedaTrigger{{
+			Type: "redis",
+			Metadata: map[string]string{
+				"address":    "redis:6379",
+				"listName":   "kfserving:async:default:sklearn",
+				"listLength": "10",
+			},
+		}},
+	}))
+	g.Expect(scaled.MaxReplicas).To(gomega.Equal(5))
+	g.Expect(extensions.Keda).To(gomega.BeNil())
+
+	// The extensions are unchanged without async inference or a queue depth target
+	g.Expect(extensions.WithAsyncScaling(nil, "queue")).To(gomega.BeIdenticalTo(extensions))
+	redis.QueueDepthTarget = nil
+	g.Expect(extensions.WithAsyncScaling(redis, "queue")).To(gomega.BeIdenticalTo(extensions))
+
+	// The memory queue runs a single replica
+	single := (&ComponentExtensionSpec{}).WithAsyncScaling(&AsyncSpec{}, "queue")
+	g.Expect(*single.MinReplicas).To(gomega.Equal(1))
+	g.Expect(single.MaxReplicas).To(gomega.Equal(1))
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// Known error messages
+const (
+	UnsupportedBatchLocationError    = "The %s location %q is not supported, it must start with s3:// or gs:// followed by a bucket."
+	BatchParallelismLowerBoundError  = "Parallelism cannot be less than 1."
+	BatchBackoffLimitLowerBoundError = "BackoffLimit cannot be less than 0."
+)
+
+const (
+	// DefaultBatchParallelism is the number of workers of a batch inference job
+	DefaultBatchParallelism int32 = 1
+	// DefaultBatchBackoffLimit is the number of retries of a worker before the batch inference job fails
+	DefaultBatchBackoffLimit int32 = 3
+)
+
+// BatchInferenceJobSpec defines the input and the output locations of a batch inference job
+type BatchInferenceJobSpec struct {
+	// Name of the InferenceService serving the predictions, in the namespace of the job
+	InferenceService string `json:"inferenceService"`
+	// Input is the storage prefix of the input files, e.g. s3://bucket/inputs/. Each line of an input file is the JSON
+	// body of a predict request.
+	Input string `json:"input"`
+	// Output is the storage prefix the output files are written to. The output file of an input file has the path of
+	// the input file relative to the input prefix, each line is the response to the request on the same line of the
+	// input file.
+	Output string `json:"output"`
+	// Parallelism is the number of workers, the input files are sharded between the workers. Defaults to 1.
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+	// BackoffLimit is the number of retries of a worker before the job fails. Defaults to 3.
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ServiceAccountName is the service account whose secrets grant access to the input and the output locations
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// BatchInferenceJobStatus defines the observed state of BatchInferenceJob
+type BatchInferenceJobStatus struct {
+	// Conditions for the batch inference job
+	duckv1.Status `json:",inline"`
+	// Time the workers were created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time the last worker completed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Number of running workers
+	// +optional
+	ActiveWorkers int32 `json:"activeWorkers,omitempty"`
+	// Number of workers which processed their shard
+	// +optional
+	SucceededWorkers int32 `json:"succeededWorkers,omitempty"`
+	// Number of workers which failed past their backoff limit
+	// +optional
+	FailedWorkers int32 `json:"failedWorkers,omitempty"`
+	// Number of records processed by the succeeded workers
+	// +optional
+	ProcessedRecords int64 `json:"processedRecords,omitempty"`
+	// Number of records the succeeded workers failed to predict, their output line is an error
+	// +optional
+	FailedRecords int64 `json:"failedRecords,omitempty"`
+}
+
+// BatchInferenceJob runs the records of the input files through the predict endpoint of an InferenceService and writes
+// the responses to the output files. The input files are sharded between worker pods.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="InferenceService",type="string",JSONPath=".spec.inferenceService"
+// +kubebuilder:printcolumn:name="Succeeded",type="integer",JSONPath=".status.succeededWorkers"
+// +kubebuilder:printcolumn:name="Parallelism",type="integer",JSONPath=".spec.parallelism"
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type=='Succeeded')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=batchinferencejobs,singular=batchinferencejob
+type BatchInferenceJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BatchInferenceJobSpec   `json:"spec,omitempty"`
+	Status            BatchInferenceJobStatus `json:"status,omitempty"`
+}
+
+// BatchInferenceJobList contains a list of BatchInferenceJob
+// +kubebuilder:object:root=true
+type BatchInferenceJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BatchInferenceJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BatchInferenceJob{}, &BatchInferenceJobList{})
+}
+
+// GetParallelism returns the number of workers of the job
+func (s *BatchInferenceJobSpec) GetParallelism() int32 {
+	if s.Parallelism == nil {
+		return DefaultBatchParallelism
+	}
+	return *s.Parallelism
+}
+
+// GetBackoffLimit returns the number of retries of a worker
+func (s *BatchInferenceJobSpec) GetBackoffLimit() int32 {
+	if s.BackoffLimit == nil {
+		return DefaultBatchBackoffLimit
+	}
+	return *s.BackoffLimit
+}
+
+// Validate checks the locations, the parallelism and the backoff limit of the job
+func (s *BatchInferenceJobSpec) Validate() error {
+	if !isBatchLocation(s.Input) {
+		return fmt.Errorf(UnsupportedBatchLocationError, "input", s.Input)
+	}
+	if !isBatchLocation(s.Output) {
+		return fmt.Errorf(UnsupportedBatchLocationError, "output", s.Output)
+	}
+	if s.GetParallelism() < 1 {
+		return fmt.Errorf(BatchParallelismLowerBoundError)
+	}
+	if s.GetBackoffLimit() < 0 {
+		return fmt.Errorf(BatchBackoffLimitLowerBoundError)
+	}
+	return nil
+}
+
+// isBatchLocation is true for the storage prefixes of the S3 API, on S3 or on GCS with its interoperability API
+func isBatchLocation(location string) bool {
+	for _, scheme := range []string{"s3://", "gs://"} {
+		if strings.HasPrefix(location, scheme) && len(location) > len(scheme) && location[len(scheme)] != '/' {
+			return true
+		}
+	}
+	return false
+}
+
+var batchInferenceJobCondSet = apis.NewBatchConditionSet()
+
+// InitializeConditions sets the initial values to the conditions
+func (s *BatchInferenceJobStatus) InitializeConditions() {
+	batchInferenceJobCondSet.Manage(s).InitializeConditions()
+}
+
+// MarkRunning marks the job running with the reason and message
+func (s *BatchInferenceJobStatus) MarkRunning(reason, message string) {
+	batchInferenceJobCondSet.Manage(s).MarkUnknown(apis.ConditionSucceeded, reason, message)
+}
+
+// MarkSucceeded marks the job succeeded once all the workers processed their shard
+func (s *BatchInferenceJobStatus) MarkSucceeded() {
+	batchInferenceJobCondSet.Manage(s).MarkTrue(apis.ConditionSucceeded)
+}
+
+// MarkFailed marks the job failed with the reason and message
+func (s *BatchInferenceJobStatus) MarkFailed(reason, message string) {
+	batchInferenceJobCondSet.Manage(s).MarkFalse(apis.ConditionSucceeded, reason, message)
+}
+
+// IsDone is true once the job succeeded or failed
+func (s *BatchInferenceJobStatus) IsDone() bool {
+	condition := batchInferenceJobCondSet.Manage(s).GetCondition(apis.ConditionSucceeded)
+	return condition != nil && !condition.IsUnknown()
+}
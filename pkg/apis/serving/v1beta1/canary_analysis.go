@@ -0,0 +1,89 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	MissingCanaryAnalysisThresholdError   = "Canary analysis requires maxErrorPercent or maxP99LatencyMilliseconds."
+	CanaryAnalysisErrorPercentError       = "Canary analysis maxErrorPercent must be between 0 and 100."
+	CanaryAnalysisLatencyLowerBoundError  = "Canary analysis maxP99LatencyMilliseconds cannot be less than 1."
+	CanaryAnalysisIntervalLowerBoundError = "Canary analysis interval cannot be less than 1m."
+)
+
+// DefaultCanaryAnalysisInterval is the default time range the metrics of the canary are evaluated over
+const DefaultCanaryAnalysisInterval = 5 * time.Minute
+
+// CanaryAnalysisSpec rolls back the canary of the component when its metrics exceed the thresholds. The metrics of the
+// Knative queue-proxy of the canary revision are queried from the Prometheus server of the controller.
+type CanaryAnalysisSpec struct {
+	// MaxErrorPercent is the maximum percentage of the requests of the canary answered with a 5xx status code
+	// +optional
+	MaxErrorPercent *int64 `json:"maxErrorPercent,omitempty"`
+	// MaxP99LatencyMilliseconds is the maximum 99th percentile of the request latency of the canary
+	// +optional
+	MaxP99LatencyMilliseconds *int64 `json:"maxP99LatencyMilliseconds,omitempty"`
+	// Interval is the time range the metrics of the canary are evaluated over, e.g. 10m. Defaults to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// GetInterval returns the time range the metrics of the canary are evaluated over
+func (c *CanaryAnalysisSpec) GetInterval() time.Duration {
+	if c.Interval == nil {
+		return DefaultCanaryAnalysisInterval
+	}
+	return c.Interval.Duration
+}
+
+// Violation returns the threshold exceeded by the metrics of the canary, empty if none. The latency is nil when the
+// canary received no request.
+func (c *CanaryAnalysisSpec) Violation(errorPercent float64, p99LatencyMilliseconds *float64) string {
+	if c.MaxErrorPercent != nil && errorPercent > float64(*c.MaxErrorPercent) {
+		return fmt.Sprintf("error rate %.2f%% exceeds %d%%", errorPercent, *c.MaxErrorPercent)
+	}
+	if c.MaxP99LatencyMilliseconds != nil && p99LatencyMilliseconds != nil &&
+		*p99LatencyMilliseconds > float64(*c.MaxP99LatencyMilliseconds) {
+		return fmt.Sprintf("p99 latency %.2fms exceeds %dms", *p99LatencyMilliseconds, *c.MaxP99LatencyMilliseconds)
+	}
+	return ""
+}
+
+func validateCanaryAnalysis(analysis *CanaryAnalysisSpec) error {
+	if analysis == nil {
+		return nil
+	}
+	if analysis.MaxErrorPercent == nil && analysis.MaxP99LatencyMilliseconds == nil {
+		return fmt.Errorf(MissingCanaryAnalysisThresholdError)
+	}
+	if analysis.MaxErrorPercent != nil && (*analysis.MaxErrorPercent < 0 || *analysis.MaxErrorPercent > 100) {
+		return fmt.Errorf(CanaryAnalysisErrorPercentError)
+	}
+	if analysis.MaxP99LatencyMilliseconds != nil && *analysis.MaxP99LatencyMilliseconds < 1 {
+		return fmt.Errorf(CanaryAnalysisLatencyLowerBoundError)
+	}
+	if analysis.GetInterval() < time.Minute {
+		return fmt.Errorf(CanaryAnalysisIntervalLowerBoundError)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanaryAnalysisValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		analysis *CanaryAnalysisSpec
+		matcher  types.GomegaMatcher
+	}{
+		"NoAnalysis": {
+			matcher: gomega.BeNil(),
+		},
+		"Thresholds": {
+			analysis: &CanaryAnalysisSpec{
+				MaxErrorPercent:           proto.Int64(5),
+				MaxP99LatencyMilliseconds: proto.Int64(200),
+				Interval:                  &metav1.Duration{Duration: 10 * time.Minute},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"NoThreshold": {
+			analysis: &CanaryAnalysisSpec{},
+			matcher:  gomega.MatchError(MissingCanaryAnalysisThresholdError),
+		},
+		"ErrorPercentAbove100": {
+			analysis: &CanaryAnalysisSpec{MaxErrorPercent: proto.Int64(101)},
+			matcher:  gomega.MatchError(CanaryAnalysisErrorPercentError),
+		},
+		"ZeroLatency": {
+			analysis: &CanaryAnalysisSpec{MaxP99LatencyMilliseconds: proto.Int64(0)},
+			matcher:  gomega.MatchError(CanaryAnalysisLatencyLowerBoundError),
+		},
+		"ShortInterval": {
+			analysis: &CanaryAnalysisSpec{
+				MaxErrorPercent: proto.Int64(5),
+				Interval:        &metav1.Duration{Duration: 30 * time.Second},
+			},
+			matcher: gomega.MatchError(CanaryAnalysisIntervalLowerBoundError),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(validateCanaryAnalysis(scenario.analysis)).To(scenario.matcher)
+		})
+	}
+}
+
+func TestCanaryAnalysisViolation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	latency := func(value float64) *float64 { return &value }
+	analysis := &CanaryAnalysisSpec{MaxErrorPercent: proto.Int64(5), MaxP99LatencyMilliseconds: proto.Int64(200)}
+	g.Expect(analysis.GetInterval()).To(gomega.Equal(DefaultCanaryAnalysisInterval))
+	g.Expect(analysis.Violation(1, latency(120))).To(gomega.BeEmpty())
+	g.Expect(analysis.Violation(0, nil)).To(gomega.BeEmpty())
+	g.Expect(analysis.Violation(12.5, latency(120))).To(gomega.Equal("error rate 12.50% exceeds 5%"))
+	g.Expect(analysis.Violation(1, latency(350))).To(gomega.Equal("p99 latency 350.00ms exceeds 200ms"))
+	g.Expect((&CanaryAnalysisSpec{MaxP99LatencyMilliseconds: proto.Int64(200)}).Violation(50, latency(120))).
+		To(gomega.BeEmpty())
+}
@@ -20,12 +20,15 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/serving/pkg/apis/autoscaling"
 )
 
 // Known error messages
@@ -34,11 +37,65 @@ const (
 	MinReplicasLowerBoundExceededError  = "MinReplicas cannot be less than 0."
 	MaxReplicasLowerBoundExceededError  = "MaxReplicas cannot be less than 0."
 	ParallelismLowerBoundExceededError  = "Parallelism cannot be less than 0."
+	SamplingPercentOutOfRangeError      = "SamplingPercent must be between 0 and 100."
+	SamplingRequiresLoggerError         = "Explainer sampling requires a logger on the predictor to publish the explanations."
+	AutoscalingServiceAnnotationError   = "Autoscaling annotation %s has no effect on the Knative Service, set it in revisionAnnotations."
+	InvalidRevisionAnnotationsError     = "Invalid revisionAnnotations: %s."
+	InvalidModelSizeAnnotationError     = "Annotation %s must be a resource quantity (e.g. 10Gi), got %q."
+	InvalidIngressHostAnnotationError   = "Annotation %s must be a DNS subdomain, got %q: %s."
+	IngressHostNotAllowedError          = "Annotation %s host %q is not under the hostOverrideDomains of the ingress config of the %s ConfigMap."
+	InvalidBooleanAnnotationError       = "Annotation %s must be true or false, got %q."
+	InvalidAuthIssuerAnnotationError    = "Annotation %s must be the issuer of the JWTs, got %q."
+	InvalidModelDigestError             = "ModelDigest must be a sha256 or sha512 digest, e.g. sha256:9c2a..., got %q."
 	UnsupportedStorageURIFormatError    = "storageUri, must be one of: [%s] or match https://{}.blob.core.windows.net/{}/{} or be an absolute or relative local path. StorageUri [%s] is not supported."
 	InvalidLoggerType                   = "Invalid logger type"
+	InvalidLoggerURLError               = "Logger url %q of the %s is not a valid url: %v."
+	LoggerSinkNotAllowedError           = "Logger url %q of the %s is not in the allowed sink domains [%s] of the logger config of the %s ConfigMap."
+	ScaleTargetLowerBoundExceededError  = "ScaleTarget cannot be less than 1."
+	UtilizationScaleTargetError         = "ScaleTarget of the %s scaleMetric is a utilization percentage, it must be between 1 and 100."
+	ScaleToZeroNotSupportedError        = "MinReplicas cannot be 0 with the %s scaleMetric, scale to zero is only supported with the concurrency and rps metrics."
+	UnsupportedScaleMetricError         = "ScaleMetric %s is not supported, it must be one of concurrency, rps and cpu."
+	KedaTriggersRequiredError           = "Keda requires at least one trigger."
+	KedaTriggerTypeRequiredError        = "Keda trigger type is required."
+	KedaScaleMetricConflictError        = "ScaleMetric, scaleTarget and targetUtilizationPercentage cannot be set with keda, the component is scaled on the keda triggers."
+	KedaDisabledError                   = "The %s cannot be scaled by KEDA, kedaEnabled is not set in the autoscaling config of the %s config map."
+	TargetUtilizationOutOfRangeError    = "TargetUtilizationPercentage must be between 1 and 100."
+	TargetUtilizationMetricError        = "TargetUtilizationPercentage cannot be set with the %s scaleMetric, it only applies to the concurrency and rps metrics."
+	ScaleTargetConcurrencyError         = "ScaleTarget %d cannot exceed containerConcurrency %d, the replicas could never reach the target concurrency."
+	InvalidMinAvailableError            = "DisruptionBudget minAvailable must be a number of replicas or a percentage, got %q."
+	MinAvailableMinReplicasError        = "DisruptionBudget minAvailable %d must be less than minReplicas %d, the replicas could not be evicted at the minimum scale."
+	RateLimitLowerBoundExceededError    = "RequestsPerSecond cannot be less than 1."
+	BurstLowerBoundExceededError        = "Burst cannot be less than requestsPerSecond."
+	BurstRequiresRateLimitError         = "Burst requires requestsPerSecond."
+	TimeoutLowerBoundExceededError      = "Timeout cannot be less than 1."
+	MaxBytesLowerBoundExceededError     = "%s cannot be less than 1."
+	InvalidRetryOnError                 = "RetryOn %q is not an Envoy retry policy or an HTTP status code."
+	NegativeValueError                  = "%s cannot be negative."
+	NonPositiveDurationError            = "%s must be a positive duration."
+	MaxEjectionPercentError             = "CircuitBreaker maxEjectionPercent must be between 0 and 100."
+	InvalidPredictorCallHostError       = "PredictorCall host must be a DNS subdomain with an optional port, got %q: %s."
+	InvalidPredictorCallProtocolError   = "PredictorCall protocol %q is not supported, must be one of: [HTTP/1.1, HTTP/2]."
+	GatewayMaximumExceededError         = "%s of the %s cannot exceed %d, the maximum set in the ingress config of the %s ConfigMap."
+	HedgingNotSupportedError            = "Hedging is only supported by the istio ingress backend, the ingress config of the %s ConfigMap sets the %s backend."
+	RollbackCanaryConflictError         = "RollbackTo cannot be set with canaryTrafficPercent, the traffic is pinned to the rollback revision."
+	InvalidRollbackRevisionError        = "RollbackTo revision %q of the %s is not in its revision history: [%s]."
+	ShadowRequiresCanaryError           = "Shadow requires canaryTrafficPercent, the percentage of the traffic mirrored to the latest revision."
+	ShadowBehindTransformerError        = "Shadow is not supported on the predictor of an InferenceService with a transformer, set it on the transformer."
+	EmptyCanaryMatchError               = "CanaryMatch requires at least one header, cookie or query parameter."
+	MultipleCanaryCookiesError          = "CanaryMatch supports one cookie per match, add a match per cookie."
+	CanaryMatchBehindTransformerError   = "CanaryMatch is not supported on the predictor of an InferenceService with a transformer, set it on the transformer."
+	LazyLoadPolicyNotSupportedError     = "loadPolicy Lazy is not supported by the %s predictor, it is supported by the predictors: [%s]."
+	ServerParallelismLowerBoundError    = "Parallelism %s cannot be less than 1."
+	ParallelismNotSupportedError        = "Parallelism %s is not supported by the %s predictor."
+	TritonParallelWorkersError          = "Parallelism workers is not supported by the triton predictor, the instances of a model are set by the instance_group of its config.pbtxt."
+	InvalidRuntimeVersionError          = "runtimeVersion %q of the %s %s is not allowed, must be one of: [%s]. The allowed versions are set in the %s ConfigMap."
+	InvalidResourceProfileError         = "Resource profile %q of annotation %s is not defined for the %s %s, must be one of: [%s]. The resource profiles are set in the %s ConfigMap."
 	InvalidISVCNameFormatError          = "The InferenceService \"%s\" is invalid: a InferenceService name must consist of lower case alphanumeric characters or '-', and must start with alphabetical character. (e.g. \"my-name\" or \"abc-123\", regex used for validation is '%s')"
 )
 
+// customContainersFieldName is the field of the PodSpec setting a custom implementation of the component
+const customContainersFieldName = "containers"
+
 // Constants
 var (
 	SupportedStorageURIPrefixList = []string{"gs://", "s3://", "pvc://", "file://", "https://", "http://"}
@@ -78,15 +135,206 @@ type ComponentExtensionSpec struct {
 	// TimeoutSeconds specifies the number of seconds to wait before timing out a request to the component.
 	// +optional
 	TimeoutSeconds *int64 `json:"timeout,omitempty"`
+	// MaxRequestBytes limits the size of the request bodies the component accepts, the larger requests are rejected
+	// with 413 by the Envoy sidecar of the replica. Requires the Istio sidecar injection.
+	// +optional
+	MaxRequestBytes *int64 `json:"maxRequestBytes,omitempty"`
+	// MaxResponseBytes limits the size of the response bodies of the component, the larger responses are replaced
+	// with a 502 by the Envoy sidecar of the replica. Only the responses with a Content-Length are checked. Requires
+	// the Istio sidecar injection.
+	// +optional
+	MaxResponseBytes *int64 `json:"maxResponseBytes,omitempty"`
+	// Retry is the retry policy of the routes of the component, Istio retries the requests twice on connection
+	// failures by default.
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// Hedging sends a hedged copy of the slow requests of the component to cut its tail latency, e.g. the p99 latency
+	// of GPU servers. Requires the istio ingress backend.
+	// +optional
+	Hedging *HedgingSpec `json:"hedging,omitempty"`
+	// CircuitBreaker ejects the failing replicas of the component from the load balancing and bounds the connections
+	// and the pending requests of the replicas.
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
 	// CanaryTrafficPercent defines the traffic split percentage between the candidate revision and the last ready revision
 	// +optional
 	CanaryTrafficPercent *int64 `json:"canaryTrafficPercent,omitempty"`
+	// RollbackTo pins all the traffic of the component to a prior revision of the revision history of the component
+	// status, rolling back a bad model version without redeploying the old spec. The latest revision stays reachable
+	// on the latest tag, unset rollbackTo to route the traffic to the latest revision again.
+	// +optional
+	RollbackTo *string `json:"rollbackTo,omitempty"`
+	// Shadow mirrors canaryTrafficPercent of the traffic to the latest revision instead of splitting it, the responses
+	// of the latest revision are discarded and the previous revision keeps serving all the traffic. Used to validate
+	// the latency and the accuracy of a new model version on live traffic.
+	// +optional
+	Shadow bool `json:"shadow,omitempty"`
+	// CanaryMatch routes the requests matching any of the rules to the latest revision whatever the traffic split,
+	// so experimenters can deterministically target the canary, e.g. with the header x-model-version: canary.
+	// +optional
+	CanaryMatch []CanaryMatch `json:"canaryMatch,omitempty"`
+	// CanaryAnalysis rolls the canary back to the previous revision when its error rate or its latency exceed the
+	// thresholds, the previous revision then serves all the traffic until the next update of the component.
+	// +optional
+	CanaryAnalysis *CanaryAnalysisSpec `json:"canaryAnalysis,omitempty"`
+	// ModelDigest is the digest of the model of the storage uri of the component, e.g. sha256:9c2a..., sent to the
+	// policy engines of the policy config with the storage uri. The policy engines deny the components with a storage
+	// uri and no model digest, unless the storage uri is pinned to a digest. The storage initializer fails the pod
+	// when the downloaded model does not match the model digest.
+	// +optional
+	ModelDigest *string `json:"modelDigest,omitempty"`
 	// Activate request/response logging and logger configurations
 	// +optional
 	Logger *LoggerSpec `json:"logger,omitempty"`
 	// Activate request batching and batching configurations
 	// +optional
 	Batcher *Batcher `json:"batcher,omitempty"`
+	// Caches the responses of the identical requests, e.g. the repeated lookups of the same embeddings
+	// +optional
+	ResponseCache *ResponseCacheSpec `json:"responseCache,omitempty"`
+	// Annotations added to the generated Knative Service, e.g. routing annotations.
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+	// Annotations added to the revision template of the generated Knative Service, e.g. autoscaling annotations.
+	// minReplicas, maxReplicas, scaleMetric and scaleTarget take precedence over the autoscaling annotations.
+	// +optional
+	RevisionAnnotations map[string]string `json:"revisionAnnotations,omitempty"`
+	// SidecarInjection injects the Istio sidecar into the pods of the component when true and keeps it out when false,
+	// the sidecarInjection of the mesh config applies when unset.
+	// +optional
+	SidecarInjection *bool `json:"sidecarInjection,omitempty"`
+	// ScaleMetric is the metric the component is scaled on. The cpu metric is scaled by the HPA autoscaling class,
+	// the concurrency and rps metrics by the KPA autoscaling class. Defaults to concurrency.
+	// +optional
+	ScaleMetric *ScaleMetric `json:"scaleMetric,omitempty"`
+	// ScaleTarget is the target value of the scale metric per replica: the utilization percentage of the requests for
+	// cpu, the in-flight requests for concurrency and the requests per second for rps.
+	// +optional
+	ScaleTarget *int `json:"scaleTarget,omitempty"`
+	// TargetUtilizationPercentage is the percentage of the scale target the KPA scales the replicas of the component
+	// up at, keeping some headroom for the bursts of requests while the new replicas start. Only applies to the
+	// concurrency and rps metrics, defaults to 70 on Knative.
+	// +optional
+	TargetUtilizationPercentage *int `json:"targetUtilizationPercentage,omitempty"`
+	// Keda scales the component with a KEDA ScaledObject on event sources, e.g. the lag of a Kafka topic, instead of
+	// the Knative autoscaler. minReplicas and maxReplicas bound the replicas of the ScaledObject.
+	// +optional
+	Keda *KedaSpec `json:"keda,omitempty"`
+	// RequestsPerSecond limits the requests each replica of the component accepts, the requests over the limit are
+	// rejected with 429 by the Envoy sidecar of the replica. Requires the Istio sidecar injection.
+	// +optional
+	RequestsPerSecond *int64 `json:"requestsPerSecond,omitempty"`
+	// Burst is the number of requests each replica accepts above requestsPerSecond before rejecting them, defaults
+	// to requestsPerSecond.
+	// +optional
+	Burst *int64 `json:"burst,omitempty"`
+	// DisruptionBudget keeps a minimum of replicas of the component available during the voluntary disruptions, e.g.
+	// the drains of the nodes, with a PodDisruptionBudget.
+	// +optional
+	DisruptionBudget *DisruptionBudgetSpec `json:"disruptionBudget,omitempty"`
+	// Scaling sets minReplicas and maxReplicas on schedules, e.g. to keep replicas warm during the business hours
+	// +optional
+	Scaling *ScalingSpec `json:"scaling,omitempty"`
+}
+
+// DisruptionBudgetSpec configures the PodDisruptionBudget of the replicas of the component
+type DisruptionBudgetSpec struct {
+	// MinAvailable is the number or the percentage of the replicas kept available, e.g. 2 or 50%. When not set, the
+	// replicas are evicted one at a time.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// RetryPolicy is the retry policy of the routes of a component
+type RetryPolicy struct {
+	// Attempts is the number of retries of a request, 0 disables the retries
+	Attempts int32 `json:"attempts"`
+	// PerTryTimeout is the timeout of each attempt, e.g. 10s, defaults to the timeout of the component
+	// +optional
+	PerTryTimeout *metav1.Duration `json:"perTryTimeout,omitempty"`
+	// RetryOn are the conditions the requests are retried on: Envoy retry policies, e.g. 5xx, gateway-error or
+	// connect-failure, or HTTP status codes, e.g. 503. Defaults to the conditions of Istio.
+	// +optional
+	RetryOn []string `json:"retryOn,omitempty"`
+}
+
+// CircuitBreaker is the outlier detection and the connection pool of the replicas of a component, the fields left
+// empty take the defaults of Istio
+type CircuitBreaker struct {
+	// ConsecutiveErrors is the number of consecutive 5xx responses of a replica before it is ejected, defaults to 5
+	// +optional
+	ConsecutiveErrors int32 `json:"consecutiveErrors,omitempty"`
+	// Interval between the ejection sweeps, defaults to 10s
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// BaseEjectionTime is the ejection duration of a replica, multiplied by its number of ejections, defaults to 30s
+	// +optional
+	BaseEjectionTime *metav1.Duration `json:"baseEjectionTime,omitempty"`
+	// MaxEjectionPercent is the maximum percentage of the replicas ejected at once, defaults to 10
+	// +optional
+	MaxEjectionPercent int32 `json:"maxEjectionPercent,omitempty"`
+	// MaxConnections is the maximum number of connections to a replica
+	// +optional
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+	// MaxPendingRequests is the maximum number of requests waiting for a connection, the requests over the limit
+	// are rejected with 503
+	// +optional
+	MaxPendingRequests int32 `json:"maxPendingRequests,omitempty"`
+}
+
+// CanaryMatch matches the requests having all the headers, cookies and query parameters
+type CanaryMatch struct {
+	// Headers are the names and the exact values of the request headers
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Cookies is the name and the exact value of a request cookie
+	// +optional
+	Cookies map[string]string `json:"cookies,omitempty"`
+	// QueryParams are the names and the exact values of the query parameters
+	// +optional
+	QueryParams map[string]string `json:"queryParams,omitempty"`
+}
+
+// KedaSpec configures the KEDA ScaledObject scaling the component
+type KedaSpec struct {
+	// Triggers are the event sources the component is scaled on, see https://keda.sh/docs/scalers
+	Triggers []KedaTrigger `json:"triggers"`
+	// PollingInterval is the interval in seconds KEDA checks the triggers on, defaults to 30.
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// CooldownPeriod is the period in seconds after the last active trigger before scaling to minReplicas, defaults to 300.
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+}
+
+// KedaTrigger is an event source of the KEDA ScaledObject
+type KedaTrigger struct {
+	// Type of the KEDA scaler, e.g. kafka, prometheus or aws-sqs-queue
+	Type string `json:"type"`
+	// Metadata configures the scaler, e.g. the topic and the lagThreshold of the kafka scaler
+	Metadata map[string]string `json:"metadata"`
+	// AuthenticationRef is the name of the KEDA TriggerAuthentication holding the credentials of the event source
+	// +optional
+	AuthenticationRef *string `json:"authenticationRef,omitempty"`
+}
+
+// ScaleMetric is the metric the autoscaler scales the component on
+// +kubebuilder:validation:Enum=cpu;concurrency;rps
+type ScaleMetric string
+
+// ScaleMetric Enum
+const (
+	MetricCPU         ScaleMetric = "cpu"
+	MetricConcurrency ScaleMetric = "concurrency"
+	MetricRPS         ScaleMetric = "rps"
+)
+
+// AutoscalerClass returns the Knative autoscaling class scaling on the metric
+func (m ScaleMetric) AutoscalerClass() string {
+	if m == MetricCPU {
+		return autoscaling.HPA
+	}
+	return autoscaling.KPA
 }
 
 // Default the ComponentExtensionSpec
@@ -97,10 +345,37 @@ func (s *ComponentExtensionSpec) Validate() error {
 	return utils.FirstNonNilError([]error{
 		validateContainerConcurrency(s.ContainerConcurrency),
 		validateReplicas(s.MinReplicas, s.MaxReplicas),
+		validateScaling(s.ScaleMetric, s.ScaleTarget, s.MinReplicas),
+		validateConcurrencyTarget(s.ContainerConcurrency, s.ScaleMetric, s.ScaleTarget, s.TargetUtilizationPercentage),
+		validateKeda(s.Keda, s.ScaleMetric, s.ScaleTarget, s.TargetUtilizationPercentage),
+		validateDisruptionBudget(s.DisruptionBudget, s.MinReplicas),
+		validateScalingSchedules(s.Scaling, s.MinReplicas, s.MaxReplicas, s.ScaleMetric),
+		validateRateLimit(s.RequestsPerSecond, s.Burst),
+		validateRequestLimits(s.TimeoutSeconds, s.MaxRequestBytes, s.MaxResponseBytes),
+		validateRetryPolicy(s.Retry),
+		validateHedging(s.Hedging, s.Retry, s.TimeoutSeconds),
+		validateCircuitBreaker(s.CircuitBreaker),
+		validateModelDigest(s.ModelDigest),
+		validateRollbackCanary(s.RollbackTo, s.CanaryTrafficPercent),
+		validateShadow(s.Shadow, s.CanaryTrafficPercent),
+		validateCanaryMatch(s.CanaryMatch),
+		validateCanaryAnalysis(s.CanaryAnalysis),
 		validateLogger(s.Logger),
+		validateResponseCache(s.ResponseCache),
+		validateAnnotations(s.ServiceAnnotations, s.RevisionAnnotations),
 	})
 }
 
+// modelDigestRegexp matches the digests of the models, the hex encoded sha256 or sha512 of the model
+var modelDigestRegexp = regexp.MustCompile(`^(sha256:[0-9a-f]{64}|sha512:[0-9a-f]{128})$`)
+
+func validateModelDigest(modelDigest *string) error {
+	if modelDigest != nil && !modelDigestRegexp.MatchString(*modelDigest) {
+		return fmt.Errorf(InvalidModelDigestError, *modelDigest)
+	}
+	return nil
+}
+
 func validateStorageURI(storageURI *string) error {
 	if storageURI == nil {
 		return nil
@@ -144,6 +419,225 @@ func validateReplicas(minReplicas *int, maxReplicas int) error {
 	return nil
 }
 
+// validateScaling checks the scale target against the metric, the utilization metrics are scaled by the HPA which does
+// not scale to zero
+func validateScaling(scaleMetric *ScaleMetric, scaleTarget *int, minReplicas *int) error {
+	metric := MetricConcurrency
+	if scaleMetric != nil {
+		metric = *scaleMetric
+	}
+	// The HPA autoscaling class of Knative only scales on cpu
+	switch metric {
+	case MetricConcurrency, MetricRPS, MetricCPU:
+	default:
+		return fmt.Errorf(UnsupportedScaleMetricError, metric)
+	}
+	if scaleTarget != nil {
+		if *scaleTarget < autoscaling.TargetMin {
+			return fmt.Errorf(ScaleTargetLowerBoundExceededError)
+		}
+		if metric.AutoscalerClass() == autoscaling.HPA && *scaleTarget > 100 {
+			return fmt.Errorf(UtilizationScaleTargetError, metric)
+		}
+	}
+	if metric.AutoscalerClass() == autoscaling.HPA && minReplicas != nil && *minReplicas == 0 {
+		return fmt.Errorf(ScaleToZeroNotSupportedError, metric)
+	}
+	return nil
+}
+
+// validateConcurrencyTarget checks the target utilization applies to the metric of the KPA, and that the target
+// concurrency can be reached under the hard limit of the container concurrency, 0 being unlimited
+func validateConcurrencyTarget(containerConcurrency *int64, scaleMetric *ScaleMetric, scaleTarget *int,
+	targetUtilization *int) error {
+	metric := MetricConcurrency
+	if scaleMetric != nil {
+		metric = *scaleMetric
+	}
+	if targetUtilization != nil {
+		if *targetUtilization < 1 || *targetUtilization > 100 {
+			return fmt.Errorf(TargetUtilizationOutOfRangeError)
+		}
+		if metric.AutoscalerClass() == autoscaling.HPA {
+			return fmt.Errorf(TargetUtilizationMetricError, metric)
+		}
+	}
+	if metric == MetricConcurrency && scaleTarget != nil && containerConcurrency != nil &&
+		*containerConcurrency > 0 && int64(*scaleTarget) > *containerConcurrency {
+		return fmt.Errorf(ScaleTargetConcurrencyError, *scaleTarget, *containerConcurrency)
+	}
+	return nil
+}
+
+// validateKeda checks the triggers of the ScaledObject, the Knative scale metric does not apply to the components
+// scaled by KEDA
+func validateKeda(keda *KedaSpec, scaleMetric *ScaleMetric, scaleTarget *int, targetUtilization *int) error {
+	if keda == nil {
+		return nil
+	}
+	if scaleMetric != nil || scaleTarget != nil || targetUtilization != nil {
+		return fmt.Errorf(KedaScaleMetricConflictError)
+	}
+	if len(keda.Triggers) == 0 {
+		return fmt.Errorf(KedaTriggersRequiredError)
+	}
+	for _, trigger := range keda.Triggers {
+		if trigger.Type == "" {
+			return fmt.Errorf(KedaTriggerTypeRequiredError)
+		}
+	}
+	return nil
+}
+
+// WithKeda returns the extensions of the component scaled by the Knative autoscaler when KEDA is disabled in the
+// cluster, the ScaledObject of the component is then deleted
+func (s *ComponentExtensionSpec) WithKeda(enabled bool) *ComponentExtensionSpec {
+	if enabled || s.Keda == nil {
+		return s
+	}
+	extensions := s.DeepCopy()
+	extensions.Keda = nil
+	return extensions
+}
+
+// validateDisruptionBudget checks minAvailable is a non negative number of replicas or a percentage, a number of
+// replicas is bounded by minReplicas so the drains of the nodes are not blocked at the minimum scale. The default
+// budget evicting one replica at a time is valid at any scale.
+func validateDisruptionBudget(disruptionBudget *DisruptionBudgetSpec, minReplicas *int) error {
+	if disruptionBudget == nil || disruptionBudget.MinAvailable == nil {
+		return nil
+	}
+	minAvailable := *disruptionBudget.MinAvailable
+	if minAvailable.Type == intstr.String {
+		percent, err := strconv.Atoi(strings.TrimSuffix(minAvailable.StrVal, "%"))
+		if !strings.HasSuffix(minAvailable.StrVal, "%") || err != nil || percent < 0 || percent > 100 {
+			return fmt.Errorf(InvalidMinAvailableError, minAvailable.StrVal)
+		}
+		return nil
+	}
+	if minAvailable.IntVal < 0 {
+		return fmt.Errorf(NegativeValueError, "DisruptionBudget minAvailable")
+	}
+	if minReplicas == nil {
+		minReplicas = &constants.DefaultMinReplicas
+	}
+	if minAvailable.IntVal > 0 && int(minAvailable.IntVal) >= *minReplicas {
+		return fmt.Errorf(MinAvailableMinReplicasError, minAvailable.IntVal, *minReplicas)
+	}
+	return nil
+}
+
+func validateRateLimit(requestsPerSecond *int64, burst *int64) error {
+	if requestsPerSecond == nil {
+		if burst != nil {
+			return fmt.Errorf(BurstRequiresRateLimitError)
+		}
+		return nil
+	}
+	if *requestsPerSecond < 1 {
+		return fmt.Errorf(RateLimitLowerBoundExceededError)
+	}
+	if burst != nil && *burst < *requestsPerSecond {
+		return fmt.Errorf(BurstLowerBoundExceededError)
+	}
+	return nil
+}
+
+func validateRequestLimits(timeoutSeconds *int64, maxRequestBytes *int64, maxResponseBytes *int64) error {
+	if timeoutSeconds != nil && *timeoutSeconds < 1 {
+		return fmt.Errorf(TimeoutLowerBoundExceededError)
+	}
+	if maxRequestBytes != nil && *maxRequestBytes < 1 {
+		return fmt.Errorf(MaxBytesLowerBoundExceededError, "MaxRequestBytes")
+	}
+	if maxResponseBytes != nil && *maxResponseBytes < 1 {
+		return fmt.Errorf(MaxBytesLowerBoundExceededError, "MaxResponseBytes")
+	}
+	return nil
+}
+
+// retryPolicies are the Envoy retry policies of the Istio retryOn field
+var retryPolicies = map[string]bool{
+	"5xx": true, "gateway-error": true, "reset": true, "connect-failure": true, "envoy-ratelimited": true,
+	"retriable-4xx": true, "refused-stream": true, "retriable-status-codes": true, "retriable-headers": true,
+	"cancelled": true, "deadline-exceeded": true, "internal": true, "resource-exhausted": true, "unavailable": true,
+}
+
+func validateRetryPolicy(retry *RetryPolicy) error {
+	if retry == nil {
+		return nil
+	}
+	if retry.Attempts < 0 {
+		return fmt.Errorf(NegativeValueError, "Retry attempts")
+	}
+	if retry.PerTryTimeout != nil && retry.PerTryTimeout.Duration <= 0 {
+		return fmt.Errorf(NonPositiveDurationError, "Retry perTryTimeout")
+	}
+	for _, retryOn := range retry.RetryOn {
+		if retryPolicies[retryOn] {
+			continue
+		}
+		if code, err := strconv.Atoi(retryOn); err != nil || code < 100 || code > 599 {
+			return fmt.Errorf(InvalidRetryOnError, retryOn)
+		}
+	}
+	return nil
+}
+
+func validateCircuitBreaker(circuitBreaker *CircuitBreaker) error {
+	if circuitBreaker == nil {
+		return nil
+	}
+	for _, limit := range []struct {
+		field string
+		value int32
+	}{
+		{field: "CircuitBreaker consecutiveErrors", value: circuitBreaker.ConsecutiveErrors},
+		{field: "CircuitBreaker maxConnections", value: circuitBreaker.MaxConnections},
+		{field: "CircuitBreaker maxPendingRequests", value: circuitBreaker.MaxPendingRequests},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf(NegativeValueError, limit.field)
+		}
+	}
+	if circuitBreaker.MaxEjectionPercent < 0 || circuitBreaker.MaxEjectionPercent > 100 {
+		return fmt.Errorf(MaxEjectionPercentError)
+	}
+	if circuitBreaker.Interval != nil && circuitBreaker.Interval.Duration <= 0 {
+		return fmt.Errorf(NonPositiveDurationError, "CircuitBreaker interval")
+	}
+	if circuitBreaker.BaseEjectionTime != nil && circuitBreaker.BaseEjectionTime.Duration <= 0 {
+		return fmt.Errorf(NonPositiveDurationError, "CircuitBreaker baseEjectionTime")
+	}
+	return nil
+}
+
+func validateRollbackCanary(rollbackTo *string, canaryTrafficPercent *int64) error {
+	if rollbackTo != nil && canaryTrafficPercent != nil {
+		return fmt.Errorf(RollbackCanaryConflictError)
+	}
+	return nil
+}
+
+func validateShadow(shadow bool, canaryTrafficPercent *int64) error {
+	if shadow && canaryTrafficPercent == nil {
+		return fmt.Errorf(ShadowRequiresCanaryError)
+	}
+	return nil
+}
+
+func validateCanaryMatch(canaryMatch []CanaryMatch) error {
+	for _, match := range canaryMatch {
+		if len(match.Headers) == 0 && len(match.Cookies) == 0 && len(match.QueryParams) == 0 {
+			return fmt.Errorf(EmptyCanaryMatchError)
+		}
+		if len(match.Cookies) > 1 {
+			return fmt.Errorf(MultipleCanaryCookiesError)
+		}
+	}
+	return nil
+}
+
 func validateContainerConcurrency(containerConcurrency *int64) error {
 	if containerConcurrency == nil {
 		return nil
@@ -163,6 +657,20 @@ func validateLogger(logger *LoggerSpec) error {
 	return nil
 }
 
+// validateAnnotations checks the annotations of the Knative Service and of its revisions, the autoscaling annotations
+// are only honored on the revisions which are checked with the Knative autoscaling validation
+func validateAnnotations(serviceAnnotations map[string]string, revisionAnnotations map[string]string) error {
+	for key := range serviceAnnotations {
+		if strings.HasPrefix(key, autoscaling.GroupName) {
+			return fmt.Errorf(AutoscalingServiceAnnotationError, key)
+		}
+	}
+	if err := autoscaling.ValidateAnnotations(revisionAnnotations); err != nil {
+		return fmt.Errorf(InvalidRevisionAnnotationsError, err.Error())
+	}
+	return nil
+}
+
 func validateExactlyOneImplementation(component Component) error {
 	if len(component.GetImplementations()) != 1 {
 		return ExactlyOneErrorFor(component)
@@ -188,16 +696,63 @@ func NonNilComponents(objects []ComponentImplementation) (results []ComponentImp
 	return results
 }
 
-// ExactlyOneErrorFor creates an error for the component's one-of semantic.
+// ExactlyOneErrorFor creates an error for the component's one-of semantic, listing the implementations set in the component.
 func ExactlyOneErrorFor(component Component) error {
-	componentType := reflect.ValueOf(component).Type().Elem()
+	componentValue := reflect.ValueOf(component).Elem()
+	componentType := componentValue.Type()
+	implementationType := reflect.TypeOf((*ComponentImplementation)(nil)).Elem()
 	implementationTypes := []string{}
-	for i := 0; i < componentType.NumField()-1; i++ {
-		implementationTypes = append(implementationTypes, componentType.Field(i).Name)
+	specified := []string{}
+	for i := 0; i < componentType.NumField(); i++ {
+		field := componentType.Field(i)
+		value := componentValue.Field(i)
+		// Only list the "1-of" fields, i.e. the framework implementations and the custom PodSpec
+		if field.Type.Implements(implementationType) {
+			implementationTypes = append(implementationTypes, jsonFieldName(field))
+			if !value.IsNil() {
+				specified = append(specified, jsonFieldName(field))
+			}
+		} else if field.Type == reflect.TypeOf(PodSpec{}) {
+			implementationTypes = append(implementationTypes, customContainersFieldName)
+			if len(value.Interface().(PodSpec).Containers) != 0 {
+				specified = append(specified, customContainersFieldName)
+			}
+		}
+	}
+	found := "none is specified"
+	if len(specified) != 0 {
+		found = fmt.Sprintf("found [%s]", strings.Join(specified, ", "))
 	}
 	return fmt.Errorf(
-		"Exactly one of [%s] must be specified in %s",
+		"Exactly one of [%s] must be specified in %s, %s",
 		strings.Join(implementationTypes, ", "),
 		componentType.Name(),
+		found,
 	)
 }
+
+// ImplementationName returns the field of the implementation specified in the component, e.g. sklearn, or containers
+// for a custom implementation, empty when no implementation is specified.
+func ImplementationName(component Component) string {
+	componentValue := reflect.ValueOf(component).Elem()
+	componentType := componentValue.Type()
+	implementationType := reflect.TypeOf((*ComponentImplementation)(nil)).Elem()
+	for i := 0; i < componentType.NumField(); i++ {
+		field := componentType.Field(i)
+		value := componentValue.Field(i)
+		if field.Type.Implements(implementationType) && !value.IsNil() {
+			return jsonFieldName(field)
+		} else if field.Type == reflect.TypeOf(PodSpec{}) && len(value.Interface().(PodSpec).Containers) != 0 {
+			return customContainersFieldName
+		}
+	}
+	return ""
+}
+
+// jsonFieldName returns the name of the field in the json serialization of the spec
+func jsonFieldName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
@@ -20,10 +20,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"text/template"
 
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -32,10 +39,26 @@ const (
 	PredictorConfigKeyName   = "predictors"
 	TransformerConfigKeyName = "transformers"
 	ExplainerConfigKeyName   = "explainers"
+	MeshConfigKeyName        = "mesh"
 )
 
 const (
-	IngressConfigKeyName = "ingress"
+	IngressConfigKeyName     = "ingress"
+	FederationConfigKeyName  = "federation"
+	CostConfigKeyName        = "cost"
+	RegistryConfigKeyName    = "registry"
+	PolicyConfigKeyName      = "policy"
+	LoggerConfigKeyName      = "logger"
+	AutoscalingConfigKeyName = "autoscaling"
+)
+
+// Ingress backends programming the routing of the inference services
+const (
+	IstioIngressBackend      = "istio"
+	KubernetesIngressBackend = "kubernetes"
+	GatewayAPIIngressBackend = "gateway-api"
+	AmbassadorIngressBackend = "ambassador"
+	ContourIngressBackend    = "contour"
 )
 
 // +kubebuilder:object:generate=false
@@ -44,6 +67,9 @@ type ExplainerConfig struct {
 	ContainerImage string `json:"image"`
 	// default explainer docker image version
 	DefaultImageVersion string `json:"defaultImageVersion"`
+	// runtime versions allowed in addition to the default version, any version is allowed when empty
+	AllowedImageVersions []string `json:"allowedImageVersions,omitempty"`
+	ResourceProfilesConfig
 }
 
 // +kubebuilder:object:generate=false
@@ -60,6 +86,23 @@ type PredictorConfig struct {
 	DefaultImageVersion string `json:"defaultImageVersion"`
 	// default predictor docker image version on gpu
 	DefaultGpuImageVersion string `json:"defaultGpuImageVersion"`
+	// frameworks of the models served by the predictor
+	SupportedFrameworks []string `json:"supportedFrameworks,omitempty"`
+	// whether the predictor serves multiple models, the TrainedModels of the inference service
+	MultiModelServer bool `json:"multiModelServer,string,omitempty"`
+	// runtime versions allowed in addition to the default versions, any version is allowed when empty
+	AllowedImageVersions []string `json:"allowedImageVersions,omitempty"`
+	ResourceProfilesConfig
+}
+
+// ResourceProfilesConfig are the resource presets of a framework, the mutating webhook sets the resources of the
+// profile selected with the serving.kubeflow.org/resource-profile annotation when the resources are omitted.
+// +kubebuilder:object:generate=false
+type ResourceProfilesConfig struct {
+	// resource requirements by profile name, e.g. small, medium and large
+	ResourceProfiles map[string]v1.ResourceRequirements `json:"resourceProfiles,omitempty"`
+	// profile used when the annotation is not set, the fixed defaults are used when empty
+	DefaultResourceProfile string `json:"defaultResourceProfile,omitempty"`
 }
 
 // +kubebuilder:object:generate=false
@@ -67,9 +110,14 @@ type PredictorsConfig struct {
 	Tensorflow PredictorConfig `json:"tensorflow,omitempty"`
 	Triton     PredictorConfig `json:"triton,omitempty"`
 	XGBoost    PredictorConfig `json:"xgboost,omitempty"`
+	LightGBM   PredictorConfig `json:"lightgbm,omitempty"`
 	SKlearn    PredictorConfig `json:"sklearn,omitempty"`
 	PyTorch    PredictorConfig `json:"pytorch,omitempty"`
+	TorchServe PredictorConfig `json:"torchserve,omitempty"`
 	ONNX       PredictorConfig `json:"onnx,omitempty"`
+	PMML       PredictorConfig `json:"pmml,omitempty"`
+	Paddle     PredictorConfig `json:"paddle,omitempty"`
+	MLflow     PredictorConfig `json:"mlflow,omitempty"`
 }
 
 // +kubebuilder:object:generate=false
@@ -85,6 +133,73 @@ type TransformersConfig struct {
 	Feast TransformerConfig `json:"feast,omitempty"`
 }
 
+// Failure policies of the policy verifiers, whether a component is rolled out when its verifier can not be reached
+const (
+	PolicyFailurePolicyFail   = "Fail"
+	PolicyFailurePolicyIgnore = "Ignore"
+)
+
+// Mutual TLS modes of the traffic to the pods of the components
+const (
+	MTLSModePermissive = "PERMISSIVE"
+	MTLSModeStrict     = "STRICT"
+)
+
+// MeshConfig is the Istio service mesh configuration of the components of the inference services
+// +kubebuilder:object:generate=false
+type MeshConfig struct {
+	// injects the Istio sidecar into the pods of the components when true and keeps it out when false, the
+	// sidecarInjection of the components overrides it. The pods follow the injection of their namespace when unset.
+	SidecarInjection *bool `json:"sidecarInjection,omitempty"`
+	// mutual TLS mode of the traffic to the pods of the components, PERMISSIVE or STRICT, set by a PeerAuthentication
+	// of each inference service. The traffic between the components is sent over mutual TLS when set, the mode of the
+	// namespace applies when empty.
+	MTLSMode string `json:"mtlsMode,omitempty"`
+}
+
+// RegistryConfig is the private registry configuration of the images of the inference services
+// +kubebuilder:object:generate=false
+type RegistryConfig struct {
+	// image pull secrets merged into the pods of the components, the warm pools and the pods the sidecars from the
+	// inferenceservice-config ConfigMap are injected into. The secrets must exist in the namespaces of the pods.
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// resolves the tags of the images of the components to digests with the registry API when true, the components
+	// are deployed by digest so that a retagged image does not change the model server. An image is resolved again
+	// when it changes in the spec.
+	ResolveDigests bool `json:"resolveDigests,omitempty"`
+}
+
+// AutoscalingConfig is the configuration of the autoscaling backends installed in the cluster
+// +kubebuilder:object:generate=false
+type AutoscalingConfig struct {
+	// scales the components setting keda with KEDA ScaledObjects when true, requires KEDA and its Knative autoscaler
+	// extension. The components are scaled by the Knative autoscaler when false.
+	KedaEnabled bool `json:"kedaEnabled,omitempty"`
+}
+
+// PolicyConfig is the supply-chain policy configuration of the inference services, the models and the images of the
+// components are verified by the policy engines before they are rolled out
+// +kubebuilder:object:generate=false
+type PolicyConfig struct {
+	// policy engines verifying the components in order, e.g. cosign signature verification or OPA, a component is
+	// rolled out once all of them allow it
+	Verifiers []PolicyVerifierConfig `json:"verifiers,omitempty"`
+}
+
+// PolicyVerifierConfig is a policy engine the storage uri and the image digests of the components are posted to
+// +kubebuilder:object:generate=false
+type PolicyVerifierConfig struct {
+	// name of the verifier reported in the PolicyViolation condition
+	Name string `json:"name"`
+	// URL the verification requests are posted to
+	URL string `json:"url"`
+	// timeout of a verification request, 10 seconds when unset
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+	// Fail blocks the rollout of the components when the verifier can not be reached, Ignore rolls them out.
+	// Defaults to Fail.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
 // +kubebuilder:object:generate=false
 type InferenceServicesConfig struct {
 	// Transformer configurations
@@ -93,51 +208,444 @@ type InferenceServicesConfig struct {
 	Predictors PredictorsConfig `json:"predictors"`
 	// Explainer configurations
 	Explainers ExplainersConfig `json:"explainers"`
+	// Service mesh configuration
+	Mesh MeshConfig `json:"mesh"`
+	// Private registry configuration of the cluster, not overlaid by the namespaces
+	Registry RegistryConfig `json:"registry"`
+	// Supply-chain policy configuration of the cluster, not overlaid by the namespaces
+	Policy PolicyConfig `json:"policy"`
+	// Autoscaling backends of the cluster, not overlaid by the namespaces
+	Autoscaling AutoscalingConfig `json:"autoscaling"`
+	// Resource versions of the ConfigMaps the configuration is read from, the one of the cluster followed by the one
+	// of the namespace if any
+	Version string `json:"-"`
 }
 
 // +kubebuilder:object:generate=false
 type IngressConfig struct {
+	// backend programming the routing, one of istio, kubernetes, gateway-api, ambassador or contour, istio when empty
+	IngressBackend     string `json:"backend,omitempty"`
 	IngressGateway     string `json:"ingressGateway,omitempty"`
 	IngressServiceName string `json:"ingressService,omitempty"`
+	// ingress class of the Kubernetes Ingress and Contour HTTPProxy resources, the default ingress class of the cluster
+	// is used when empty
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// <namespace>/<name> of the Gateway the HTTPRoutes of the gateway-api backend are attached to
+	KubernetesGateway string `json:"kubernetesGateway,omitempty"`
+	// template of the external host of the inference services, e.g. {{.Name}}-{{.Namespace}}.models.example.com, with
+	// the Name, Namespace, Annotations and Labels of the inference service. The host of the Knative service of the
+	// component receiving the traffic is used when empty.
+	DomainTemplate string `json:"domainTemplate,omitempty"`
+	// domains the serving.kubeflow.org/ingress-host annotation can set the external host under, e.g.
+	// models.example.com allows flowers.models.example.com. The annotation is rejected when empty.
+	HostOverrideDomains []string `json:"hostOverrideDomains,omitempty"`
+	// template of the path prefix of the inference services on the ingress domain, e.g.
+	// /serving/{{.Namespace}}/{{.Name}}, with the same fields as the domain template. The inference services are
+	// also exposed under their path prefix on the ingress domain when set, only supported by the istio backend.
+	PathTemplate string `json:"pathTemplate,omitempty"`
+	// single domain of the inference services exposed under their path prefix, required with the path template
+	IngressDomain string `json:"ingressDomain,omitempty"`
+	// cert-manager issuer of the certificates of the external hosts, the inference services are served over HTTPS
+	// when set, only supported by the istio backend
+	CertificateIssuer string `json:"certificateIssuer,omitempty"`
+	// kind of the issuer, Issuer or ClusterIssuer, ClusterIssuer when empty
+	CertificateIssuerKind string `json:"certificateIssuerKind,omitempty"`
+	// namespace of the ingress gateway pods the certificates are created in, istio-system when empty
+	CertificateNamespace string `json:"certificateNamespace,omitempty"`
+	// JWT authentication of the requests at the ingress gateway, only supported by the istio backend
+	Auth *AuthConfig `json:"auth,omitempty"`
+	// name of the Istio envoyExtAuthz extension provider checking the X-API-Key header of the requests against the
+	// API key secrets, required by the inference services requiring an API key. The keys are only read by the
+	// provider, they are not copied to the policies of the ingress gateway.
+	APIKeyAuthProvider string `json:"apiKeyAuthProvider,omitempty"`
+	// maximum timeout in seconds of the components, e.g. the max-revision-timeout-seconds of Knative, the inference
+	// services with a larger timeout are rejected. Not limited when 0.
+	MaxTimeoutSeconds int64 `json:"maxTimeoutSeconds,omitempty"`
+	// maximum maxRequestBytes of the components, e.g. the request size limit of the ingress gateway. Not limited when 0.
+	MaxRequestBytes int64 `json:"maxRequestBytes,omitempty"`
+	// maximum maxResponseBytes of the components. Not limited when 0.
+	MaxResponseBytes int64 `json:"maxResponseBytes,omitempty"`
+}
+
+// AuthConfig is the JWT authentication of the requests of the inference services at the ingress gateway, the
+// issuer, the audiences and the subjects can be overridden per inference service with annotations
+// +kubebuilder:object:generate=false
+type AuthConfig struct {
+	// issuer of the JWTs, e.g. https://accounts.google.com
+	Issuer string `json:"issuer,omitempty"`
+	// URL of the JSON web key set of the issuer, discovered with OpenID Connect discovery when empty
+	JwksURI string `json:"jwksUri,omitempty"`
+	// accepted audiences of the JWTs, any audience is accepted when empty
+	Audiences []string `json:"audiences,omitempty"`
+	// subjects of the JWTs allowed to call the inference services, any subject of the issuer when empty
+	Subjects []string `json:"subjects,omitempty"`
+	// require a JWT for all the inference services exposed outside the cluster, for the annotated ones only when false
+	Required bool `json:"required,omitempty"`
+	// namespace of the ingress gateway pods the policies are created in, istio-system when empty
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// FederationConfig is the configuration of the inference services propagated to member clusters
+// +kubebuilder:object:generate=false
+type FederationConfig struct {
+	// template of the global host of the federated inference services, e.g. {{.Name}}-{{.Namespace}}.global.example.com,
+	// with the same fields as the domain template of the ingress config. The copies in the member clusters are served
+	// on the global host so a global load balancer or DNS can spread the requests across the member clusters. The
+	// member clusters serve their own hosts when empty.
+	DomainTemplate string `json:"domainTemplate,omitempty"`
+	// namespaces whose inference services can be federated. The copies are created with the credentials of the
+	// member clusters, so only the namespaces whose users may manage the inference services of the same namespace in
+	// all the member clusters are listed. No inference service is federated when empty.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// LoggerConfig is the part of the logger configuration the log sinks of the inference services are validated against,
+// the logger sidecar is configured by the pod mutator
+// +kubebuilder:object:generate=false
+type LoggerConfig struct {
+	// sink of the loggers not setting a url
+	DefaultUrl string `json:"defaultUrl,omitempty"`
+	// domains the log sinks are restricted to, any sink is allowed when empty
+	AllowedSinkDomains []string `json:"allowedSinkDomains,omitempty"`
 }
 
-func NewInferenceServicesConfig(cli client.Client) (*InferenceServicesConfig, error) {
+// CostConfig is the price table the hourly cost of the inference services is estimated with
+// +kubebuilder:object:generate=false
+type CostConfig struct {
+	// currency of the prices, e.g. USD
+	Currency string `json:"currency,omitempty"`
+	// hourly prices of the resources requested by the containers of the pods: cpu per core, memory and
+	// ephemeral-storage per GiB, and the other resources per unit, e.g. nvidia.com/gpu. The cost is not estimated when
+	// empty.
+	Prices map[v1.ResourceName]float64 `json:"prices,omitempty"`
+}
+
+// NewInferenceServicesConfig reads the inference services configuration of the cluster overlaid with the
+// inferenceservice-config ConfigMap of the namespace, the keys set in the namespace override the cluster ones.
+func NewInferenceServicesConfig(cli client.Reader, namespace string) (*InferenceServicesConfig, error) {
 	configMap := &v1.ConfigMap{}
 	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
 	if err != nil {
 		return nil, err
 	}
-	icfg := &InferenceServicesConfig{}
-	for _, err := range []error{
-		getComponentConfig(PredictorConfigKeyName, configMap, &icfg.Predictors),
-		getComponentConfig(ExplainerConfigKeyName, configMap, &icfg.Explainers),
-		getComponentConfig(TransformerConfigKeyName, configMap, &icfg.Transformers),
-	} {
-		if err != nil {
-			return nil, err
+	namespaceConfigMap, err := getNamespaceConfigMap(cli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	icfg := &InferenceServicesConfig{Version: configMap.ResourceVersion}
+	if namespaceConfigMap.ResourceVersion != "" {
+		icfg.Version += "/" + namespaceConfigMap.ResourceVersion
+	}
+	for _, cm := range []*v1.ConfigMap{configMap, namespaceConfigMap} {
+		for _, err := range []error{
+			getComponentConfig(PredictorConfigKeyName, cm, &icfg.Predictors),
+			getComponentConfig(ExplainerConfigKeyName, cm, &icfg.Explainers),
+			getComponentConfig(TransformerConfigKeyName, cm, &icfg.Transformers),
+			getComponentConfig(MeshConfigKeyName, cm, &icfg.Mesh),
+		} {
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
+	if err := getComponentConfig(RegistryConfigKeyName, configMap, &icfg.Registry); err != nil {
+		return nil, err
+	}
+	if err := getComponentConfig(PolicyConfigKeyName, configMap, &icfg.Policy); err != nil {
+		return nil, err
+	}
+	if err := getComponentConfig(AutoscalingConfigKeyName, configMap, &icfg.Autoscaling); err != nil {
+		return nil, err
+	}
+	if err := ValidateMeshConfig(&icfg.Mesh); err != nil {
+		return nil, err
+	}
+	if err := ValidatePolicyConfig(&icfg.Policy); err != nil {
+		return nil, err
+	}
 	return icfg, nil
 }
 
-func NewIngressConfig(cli client.Client) (*IngressConfig, error) {
+// NewIngressConfig reads the ingress configuration of the cluster. The inferenceservice-config ConfigMap of the namespace
+// can only tighten the maxTimeoutSeconds, maxRequestBytes and maxResponseBytes limits, the ingress domain, the domain
+// template and the other hosts stay the ones of the cluster.
+func NewIngressConfig(cli client.Reader, namespace string) (*IngressConfig, error) {
 	configMap := &v1.ConfigMap{}
 	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
 	if err != nil {
 		return nil, err
 	}
+	namespaceConfigMap, err := getNamespaceConfigMap(cli, namespace)
+	if err != nil {
+		return nil, err
+	}
 	ingressConfig := &IngressConfig{}
 	if ingress, ok := configMap.Data[IngressConfigKeyName]; ok {
 		err := json.Unmarshal([]byte(ingress), &ingressConfig)
 		if err != nil {
 			return nil, fmt.Errorf("Unable to parse ingress config json: %v", err)
 		}
+		if err := ValidateIngressConfig(ingressConfig); err != nil {
+			return nil, err
+		}
+	}
+	if ingress, ok := namespaceConfigMap.Data[IngressConfigKeyName]; ok {
+		// A namespace only tightens the limits of its inference services, the hosts stay the ones of the cluster so a
+		// namespace cannot claim the hosts of another one
+		namespaceIngressConfig := &IngressConfig{}
+		if err := json.Unmarshal([]byte(ingress), namespaceIngressConfig); err != nil {
+			return nil, fmt.Errorf("Unable to parse ingress config json of namespace %s: %v", namespace, err)
+		}
+		if err := ValidateNamespaceIngressConfig(namespaceIngressConfig); err != nil {
+			return nil, fmt.Errorf("Invalid ingress config of namespace %s: %v", namespace, err)
+		}
+		ingressConfig.MaxTimeoutSeconds = tighterLimit(ingressConfig.MaxTimeoutSeconds,
+			namespaceIngressConfig.MaxTimeoutSeconds)
+		ingressConfig.MaxRequestBytes = tighterLimit(ingressConfig.MaxRequestBytes, namespaceIngressConfig.MaxRequestBytes)
+		ingressConfig.MaxResponseBytes = tighterLimit(ingressConfig.MaxResponseBytes,
+			namespaceIngressConfig.MaxResponseBytes)
+	}
+	return ingressConfig, nil
+}
+
+// NewFederationConfig reads the federation configuration of the cluster
+func NewFederationConfig(cli client.Reader) (*FederationConfig, error) {
+	configMap := &v1.ConfigMap{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
+	if err != nil {
+		return nil, err
+	}
+	federationConfig := &FederationConfig{}
+	if err := getComponentConfig(FederationConfigKeyName, configMap, federationConfig); err != nil {
+		return nil, err
+	}
+	if _, err := template.New("domain").Parse(federationConfig.DomainTemplate); err != nil {
+		return nil, fmt.Errorf("Invalid federation config, unable to parse domainTemplate: %v", err)
+	}
+	if err := ValidateFederationConfig(federationConfig); err != nil {
+		return nil, err
+	}
+	return federationConfig, nil
+}
 
+// NewCostConfig reads the cost configuration of the cluster
+func NewCostConfig(cli client.Reader) (*CostConfig, error) {
+	configMap := &v1.ConfigMap{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
+	if err != nil {
+		return nil, err
+	}
+	costConfig := &CostConfig{}
+	if err := getComponentConfig(CostConfigKeyName, configMap, costConfig); err != nil {
+		return nil, err
+	}
+	if err := ValidateCostConfig(costConfig); err != nil {
+		return nil, err
+	}
+	return costConfig, nil
+}
+
+// NewLoggerConfig reads the logger configuration of the cluster overlaid with the inferenceservice-config ConfigMap of
+// the namespace. The namespace sets the default sink of its loggers and narrows the allowed sink domains, the sinks
+// stay within the domains allowed by the cluster.
+func NewLoggerConfig(cli client.Reader, namespace string) (*LoggerConfig, error) {
+	configMap := &v1.ConfigMap{}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}, configMap)
+	if err != nil {
+		return nil, err
+	}
+	namespaceConfigMap, err := getNamespaceConfigMap(cli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	loggerConfig := &LoggerConfig{}
+	if err := getComponentConfig(LoggerConfigKeyName, configMap, loggerConfig); err != nil {
+		return nil, err
+	}
+	namespaceLoggerConfig := &LoggerConfig{}
+	if err := getComponentConfig(LoggerConfigKeyName, namespaceConfigMap, namespaceLoggerConfig); err != nil {
+		return nil, err
+	}
+	if namespaceLoggerConfig.DefaultUrl != "" {
+		loggerConfig.DefaultUrl = namespaceLoggerConfig.DefaultUrl
+	}
+	if len(namespaceLoggerConfig.AllowedSinkDomains) != 0 {
+		for _, domain := range namespaceLoggerConfig.AllowedSinkDomains {
+			domain = strings.TrimPrefix(strings.TrimSpace(domain), ".")
+			if len(loggerConfig.AllowedSinkDomains) != 0 && !utils.InDomains(domain, loggerConfig.AllowedSinkDomains) {
+				return nil, fmt.Errorf("Invalid logger config of namespace %s, sink domain %s is not allowed by the cluster",
+					namespace, domain)
+			}
+		}
+		loggerConfig.AllowedSinkDomains = namespaceLoggerConfig.AllowedSinkDomains
+	}
+	return loggerConfig, nil
+}
+
+// GetRegistryConfig reads the private registry configuration of the inferenceservice-config ConfigMap of the cluster
+func GetRegistryConfig(configMap *v1.ConfigMap) (*RegistryConfig, error) {
+	registryConfig := &RegistryConfig{}
+	if err := getComponentConfig(RegistryConfigKeyName, configMap, registryConfig); err != nil {
+		return nil, err
+	}
+	return registryConfig, nil
+}
+
+// ValidateCostConfig validates the prices of the cost configuration
+func ValidateCostConfig(costConfig *CostConfig) error {
+	for name, price := range costConfig.Prices {
+		if price < 0 {
+			return fmt.Errorf("Invalid cost config, the price of %s cannot be negative.", name)
+		}
+	}
+	return nil
+}
+
+// ValidateMeshConfig validates the mutual TLS mode of the mesh configuration
+func ValidateMeshConfig(meshConfig *MeshConfig) error {
+	switch meshConfig.MTLSMode {
+	case "", MTLSModePermissive, MTLSModeStrict:
+		return nil
+	default:
+		return fmt.Errorf("Invalid mesh config, unknown mtlsMode %s, must be PERMISSIVE or STRICT.", meshConfig.MTLSMode)
+	}
+}
+
+// ValidatePolicyConfig validates the URLs, the timeouts and the failure policies of the policy verifiers
+func ValidatePolicyConfig(policyConfig *PolicyConfig) error {
+	for _, verifier := range policyConfig.Verifiers {
+		if verifier.Name == "" || verifier.URL == "" {
+			return fmt.Errorf("Invalid policy config, the name and the url of a verifier are required.")
+		}
+		if _, err := url.ParseRequestURI(verifier.URL); err != nil {
+			return fmt.Errorf("Invalid policy config, invalid url %s of verifier %s.", verifier.URL, verifier.Name)
+		}
+		if verifier.TimeoutSeconds < 0 {
+			return fmt.Errorf("Invalid policy config, the timeout of verifier %s cannot be negative.", verifier.Name)
+		}
+		switch verifier.FailurePolicy {
+		case "", PolicyFailurePolicyFail, PolicyFailurePolicyIgnore:
+		default:
+			return fmt.Errorf("Invalid policy config, unknown failurePolicy %s of verifier %s, must be Fail or Ignore.",
+				verifier.FailurePolicy, verifier.Name)
+		}
+	}
+	return nil
+}
+
+// tighterLimit returns the limit of the namespace when it is lower than the one of the cluster, 0 is no limit
+func tighterLimit(cluster int64, namespace int64) int64 {
+	if namespace > 0 && (cluster == 0 || namespace < cluster) {
+		return namespace
+	}
+	return cluster
+}
+
+// ValidateNamespaceIngressConfig validates the ingress configuration of a namespace, it only sets the maximums of the
+// inference services of the namespace
+func ValidateNamespaceIngressConfig(ingressConfig *IngressConfig) error {
+	limits := IngressConfig{
+		MaxTimeoutSeconds: ingressConfig.MaxTimeoutSeconds,
+		MaxRequestBytes:   ingressConfig.MaxRequestBytes,
+		MaxResponseBytes:  ingressConfig.MaxResponseBytes,
+	}
+	if !reflect.DeepEqual(*ingressConfig, limits) {
+		return fmt.Errorf("Invalid ingress config of a namespace, only maxTimeoutSeconds, maxRequestBytes and " +
+			"maxResponseBytes can be set, the other settings are only read from the cluster config map.")
+	}
+	return nil
+}
+
+// ValidateIngressConfig validates the ingress configuration: the settings required by the backend and the settings
+// only supported by the istio backend
+func ValidateIngressConfig(ingressConfig *IngressConfig) error {
+	switch ingressConfig.IngressBackend {
+	case "", IstioIngressBackend:
 		if ingressConfig.IngressGateway == "" || ingressConfig.IngressServiceName == "" {
-			return nil, fmt.Errorf("Invalid ingress config, ingressGateway and ingressService are required.")
+			return fmt.Errorf("Invalid ingress config, ingressGateway and ingressService are required.")
+		}
+	case KubernetesIngressBackend, AmbassadorIngressBackend, ContourIngressBackend:
+	case GatewayAPIIngressBackend:
+		if parts := strings.Split(ingressConfig.KubernetesGateway, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("Invalid ingress config, kubernetesGateway <namespace>/<name> is required.")
 		}
+	default:
+		return fmt.Errorf("Invalid ingress config, unknown backend %s.", ingressConfig.IngressBackend)
 	}
-	return ingressConfig, nil
+	if _, err := template.New("domain").Parse(ingressConfig.DomainTemplate); err != nil {
+		return fmt.Errorf("Invalid ingress config, unable to parse domainTemplate: %v", err)
+	}
+	for _, domain := range ingressConfig.HostOverrideDomains {
+		if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+			return fmt.Errorf("Invalid ingress config, hostOverrideDomains %q is not a DNS subdomain: %s.", domain,
+				strings.Join(errs, ", "))
+		}
+	}
+	if ingressConfig.PathTemplate != "" {
+		if ingressConfig.IngressBackend != "" && ingressConfig.IngressBackend != IstioIngressBackend {
+			return fmt.Errorf("Invalid ingress config, pathTemplate is only supported by the istio backend.")
+		}
+		if ingressConfig.IngressDomain == "" {
+			return fmt.Errorf("Invalid ingress config, ingressDomain is required with pathTemplate.")
+		}
+		if _, err := template.New("path").Parse(ingressConfig.PathTemplate); err != nil {
+			return fmt.Errorf("Invalid ingress config, unable to parse pathTemplate: %v", err)
+		}
+	}
+	if ingressConfig.CertificateIssuer != "" && ingressConfig.IngressBackend != "" &&
+		ingressConfig.IngressBackend != IstioIngressBackend {
+		return fmt.Errorf("Invalid ingress config, certificateIssuer is only supported by the istio backend.")
+	}
+	if ingressConfig.Auth != nil && ingressConfig.IngressBackend != "" &&
+		ingressConfig.IngressBackend != IstioIngressBackend {
+		return fmt.Errorf("Invalid ingress config, auth is only supported by the istio backend.")
+	}
+	return nil
+}
+
+// IsHostOverrideAllowed tells whether the ingress host annotation can set the host, the host must be a subdomain of one
+// of the host override domains
+func IsHostOverrideAllowed(ingressConfig *IngressConfig, host string) bool {
+	for _, domain := range ingressConfig.HostOverrideDomains {
+		if strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFederationConfig validates the allowed namespaces of the federation config
+func ValidateFederationConfig(federationConfig *FederationConfig) error {
+	for _, namespace := range federationConfig.AllowedNamespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("Invalid federation config, allowedNamespaces has an invalid namespace %q: %s.",
+				namespace, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// IsFederationAllowed tells whether the inference services of the namespace can be federated
+func IsFederationAllowed(federationConfig *FederationConfig, namespace string) bool {
+	return utils.Includes(federationConfig.AllowedNamespaces, namespace)
+}
+
+// getNamespaceConfigMap returns the inferenceservice-config ConfigMap of the namespace overlaying the one of the
+// cluster, an empty ConfigMap when the namespace does not have one. The ConfigMap is ignored when it is not labelled,
+// only the labelled ConfigMaps are validated by the webhook.
+func getNamespaceConfigMap(cli client.Reader, namespace string) (*v1.ConfigMap, error) {
+	configMap := &v1.ConfigMap{}
+	if namespace == "" || namespace == constants.KFServingNamespace {
+		return configMap, nil
+	}
+	err := cli.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: namespace}, configMap)
+	if apierr.IsNotFound(err) {
+		return &v1.ConfigMap{}, nil
+	}
+	if err == nil && configMap.Labels[constants.InferenceServiceConfigLabelKey] != "enabled" {
+		return &v1.ConfigMap{}, nil
+	}
+	return configMap, err
 }
 
 func getComponentConfig(key string, configMap *v1.ConfigMap, componentConfig interface{}) error {
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNamespaceConfigOverlay(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace,
+				ResourceVersion: "100"},
+			Data: map[string]string{
+				PredictorConfigKeyName: `{"sklearn": {"image": "kfserving/sklearnserver", "defaultImageVersion": "v0.5.0"},
+					"xgboost": {"image": "kfserving/xgbserver", "defaultImageVersion": "v0.5.0"}}`,
+				IngressConfigKeyName: `{"ingressGateway": "knative-serving/knative-ingress-gateway",
+					"ingressService": "istio-ingressgateway.istio-system.svc.cluster.local", "ingressDomain": "example.com",
+					"maxTimeoutSeconds": 600, "maxRequestBytes": 1048576}`,
+				MeshConfigKeyName:     `{"sidecarInjection": true}`,
+				RegistryConfigKeyName: `{"imagePullSecrets": [{"name": "kfserving-registry"}]}`,
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: "team-a",
+				ResourceVersion: "200", Labels: map[string]string{constants.InferenceServiceConfigLabelKey: "enabled"}},
+			Data: map[string]string{
+				PredictorConfigKeyName: `{"sklearn": {"image": "team-a/sklearnserver",
+					"resourceProfiles": {"small": {"limits": {"cpu": "1"}}}}}`,
+				IngressConfigKeyName:  `{"maxTimeoutSeconds": 60, "maxRequestBytes": 4194304, "maxResponseBytes": 1048576}`,
+				MeshConfigKeyName:     `{"mtlsMode": "STRICT"}`,
+				RegistryConfigKeyName: `{"imagePullSecrets": [{"name": "team-a-registry"}]}`,
+			},
+		},
+	)
+
+	config, err := NewInferenceServicesConfig(cl, "team-a")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(config.Predictors.SKlearn.ContainerImage).To(gomega.Equal("team-a/sklearnserver"))
+	g.Expect(config.Predictors.SKlearn.DefaultImageVersion).To(gomega.Equal("v0.5.0"))
+	g.Expect(config.Predictors.SKlearn.ResourceProfiles).To(gomega.HaveKey("small"))
+	g.Expect(config.Predictors.XGBoost.ContainerImage).To(gomega.Equal("kfserving/xgbserver"))
+	g.Expect(*config.Mesh.SidecarInjection).To(gomega.BeTrue())
+	g.Expect(config.Mesh.MTLSMode).To(gomega.Equal(MTLSModeStrict))
+	// The registry config is only read from the cluster ConfigMap
+	g.Expect(config.Registry.ImagePullSecrets).To(gomega.Equal([]v1.LocalObjectReference{{Name: "kfserving-registry"}}))
+
+	g.Expect(config.Version).To(gomega.Equal("100/200"))
+
+	config, err = NewInferenceServicesConfig(cl, "team-b")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(config.Predictors.SKlearn.ContainerImage).To(gomega.Equal("kfserving/sklearnserver"))
+	g.Expect(config.Mesh.MTLSMode).To(gomega.BeEmpty())
+	g.Expect(config.Version).To(gomega.Equal("100"))
+
+	// The ConfigMap of a namespace is ignored without the label, it is not validated by the webhook
+	g.Expect(cl.Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: "team-c"},
+		Data:       map[string]string{PredictorConfigKeyName: `{"sklearn": {"image": "team-c/sklearnserver"}}`},
+	})).To(gomega.Succeed())
+	config, err = NewInferenceServicesConfig(cl, "team-c")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(config.Predictors.SKlearn.ContainerImage).To(gomega.Equal("kfserving/sklearnserver"))
+
+	// The namespace only tightens the limits of the cluster
+	ingressConfig, err := NewIngressConfig(cl, "team-a")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ingressConfig.IngressDomain).To(gomega.Equal("example.com"))
+	g.Expect(ingressConfig.IngressGateway).To(gomega.Equal("knative-serving/knative-ingress-gateway"))
+	g.Expect(ingressConfig.MaxTimeoutSeconds).To(gomega.Equal(int64(60)))
+	g.Expect(ingressConfig.MaxRequestBytes).To(gomega.Equal(int64(1048576)))
+	g.Expect(ingressConfig.MaxResponseBytes).To(gomega.Equal(int64(1048576)))
+
+	ingressConfig, err = NewIngressConfig(cl, "team-b")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ingressConfig.IngressDomain).To(gomega.Equal("example.com"))
+	g.Expect(ingressConfig.MaxTimeoutSeconds).To(gomega.Equal(int64(600)))
+	g.Expect(ingressConfig.MaxResponseBytes).To(gomega.BeZero())
+}
+
+func TestNamespaceConfigOverlayInvalid(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: "team-a",
+				Labels: map[string]string{constants.InferenceServiceConfigLabelKey: "enabled"}},
+			Data: map[string]string{
+				PredictorConfigKeyName: `{"sklearn": `,
+				IngressConfigKeyName:   `{"domainTemplate": "{{ .Name }}.team-b.example.com"}`,
+			},
+		},
+	)
+
+	_, err := NewInferenceServicesConfig(cl, "team-a")
+	g.Expect(err).To(gomega.HaveOccurred())
+	_, err = NewIngressConfig(cl, "team-a")
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	cl = fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+			Data:       map[string]string{MeshConfigKeyName: `{"mtlsMode": "DISABLE"}`},
+		},
+	)
+	_, err = NewInferenceServicesConfig(cl, "team-a")
+	g.Expect(err).To(gomega.MatchError("Invalid mesh config, unknown mtlsMode DISABLE, must be PERMISSIVE or STRICT."))
+
+	cl = fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+			Data:       map[string]string{PolicyConfigKeyName: `{"verifiers": [{"name": "cosign", "url": "cosign-verifier"}]}`},
+		},
+	)
+	_, err = NewInferenceServicesConfig(cl, "team-a")
+	g.Expect(err).To(gomega.MatchError("Invalid policy config, invalid url cosign-verifier of verifier cosign."))
+}
+
+func TestNamespaceLoggerConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	namespaceConfigMap := func(namespace string, logger string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: namespace,
+				Labels: map[string]string{constants.InferenceServiceConfigLabelKey: "enabled"}},
+			Data: map[string]string{LoggerConfigKeyName: logger},
+		}
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+			Data: map[string]string{LoggerConfigKeyName: `{"image": "kfserving/logger:v0.5.0",
+				"defaultUrl": "http://default-broker.eu.example.com", "allowedSinkDomains": ["eu.example.com"]}`},
+		},
+		namespaceConfigMap("team-a", `{"defaultUrl": "http://broker.team-a.eu.example.com",
+			"allowedSinkDomains": ["team-a.eu.example.com"]}`),
+		namespaceConfigMap("team-b", `{"allowedSinkDomains": ["us.example.com"]}`),
+	)
+
+	// The namespace sets its default sink and narrows the sink domains of the cluster
+	loggerConfig, err := NewLoggerConfig(cl, "team-a")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(loggerConfig.DefaultUrl).To(gomega.Equal("http://broker.team-a.eu.example.com"))
+	g.Expect(loggerConfig.AllowedSinkDomains).To(gomega.Equal([]string{"team-a.eu.example.com"}))
+
+	// The sinks of a namespace stay within the domains of the cluster
+	_, err = NewLoggerConfig(cl, "team-b")
+	g.Expect(err).To(gomega.MatchError("Invalid logger config of namespace team-b, sink domain us.example.com is not " +
+		"allowed by the cluster"))
+
+	loggerConfig, err = NewLoggerConfig(cl, "team-c")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(loggerConfig.DefaultUrl).To(gomega.Equal("http://default-broker.eu.example.com"))
+	g.Expect(loggerConfig.AllowedSinkDomains).To(gomega.Equal([]string{"eu.example.com"}))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// bytesPerGiB converts the memory and the ephemeral storage to GiB, their prices are per GiB
+const bytesPerGiB = 1 << 30
+
+// CostStatus is the estimated hourly cost of the running replicas of the inference service, from the resources
+// requested by their pods and the price table of the cost config. The costs are formatted decimals since the status
+// does not hold floats.
+type CostStatus struct {
+	// Currency of the prices, e.g. USD
+	// +optional
+	Currency string `json:"currency,omitempty"`
+	// Estimated hourly cost of the inference service, the sum of the hourly costs of its components
+	HourlyCost string `json:"hourlyCost"`
+	// Estimated hourly costs of the components
+	// +optional
+	Components map[ComponentType]ComponentCostStatus `json:"components,omitempty"`
+}
+
+// ComponentCostStatus is the estimated hourly cost of the running replicas of a component
+type ComponentCostStatus struct {
+	// Number of replicas scheduled on a node, the replicas of all the revisions of the component
+	Replicas int32 `json:"replicas"`
+	// Estimated hourly cost of the replicas
+	HourlyCost string `json:"hourlyCost"`
+}
+
+// PropagateCost estimates the hourly cost of the components of the inference service from their pods, the cost is
+// removed from the status when the cost config has no prices. The pods waiting to be scheduled cost nothing.
+func (ss *InferenceServiceStatus) PropagateCost(components []ComponentType, pods []v1.Pod, config *CostConfig) {
+	if len(config.Prices) == 0 {
+		ss.Cost = nil
+		return
+	}
+	costs := make(map[ComponentType]float64, len(components))
+	replicas := make(map[ComponentType]int32, len(components))
+	for _, component := range components {
+		costs[component] = 0
+	}
+	for i := range pods {
+		pod := &pods[i]
+		component := ComponentType(pod.Labels[constants.KServiceComponentLabel])
+		if _, ok := costs[component]; !ok || !isRunning(pod) {
+			continue
+		}
+		replicas[component]++
+		costs[component] += podHourlyCost(pod, config.Prices)
+	}
+	total := 0.0
+	ss.Cost = &CostStatus{Currency: config.Currency, Components: make(map[ComponentType]ComponentCostStatus, len(costs))}
+	for component, cost := range costs {
+		total += cost
+		ss.Cost.Components[component] = ComponentCostStatus{Replicas: replicas[component], HourlyCost: formatCost(cost)}
+	}
+	ss.Cost.HourlyCost = formatCost(total)
+}
+
+// isRunning returns whether the pod is scheduled on a node and not terminated
+func isRunning(pod *v1.Pod) bool {
+	return pod.DeletionTimestamp == nil && pod.Spec.NodeName != "" &&
+		pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+}
+
+// podHourlyCost returns the hourly cost of the resources requested by the containers of the pod, the limits of the
+// resources without request
+func podHourlyCost(pod *v1.Pod, prices map[v1.ResourceName]float64) float64 {
+	cost := 0.0
+	for _, container := range pod.Spec.Containers {
+		for name, price := range prices {
+			quantity, ok := container.Resources.Requests[name]
+			if !ok {
+				if quantity, ok = container.Resources.Limits[name]; !ok {
+					continue
+				}
+			}
+			cost += price * units(name, quantity)
+		}
+	}
+	return cost
+}
+
+// units returns the quantity in the unit of the price of the resource
+func units(name v1.ResourceName, quantity resource.Quantity) float64 {
+	if name == v1.ResourceMemory || name == v1.ResourceEphemeralStorage {
+		return float64(quantity.Value()) / bytesPerGiB
+	}
+	return float64(quantity.MilliValue()) / 1000
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', 4, 64)
+}
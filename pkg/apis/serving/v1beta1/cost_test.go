@@ -0,0 +1,127 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeCostPod(component ComponentType, phase v1.PodPhase, nodeName string, requests v1.ResourceList,
+	limits v1.ResourceList) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{constants.KServiceComponentLabel: string(component)}},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{Name: constants.InferenceServiceContainerName, Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}},
+				{Name: "queue-proxy", Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("25m"),
+				}}},
+			},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestPropagateCost(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := &CostConfig{
+		Currency: "USD",
+		Prices: map[v1.ResourceName]float64{
+			v1.ResourceCPU:    0.04,
+			v1.ResourceMemory: 0.005,
+			"nvidia.com/gpu":  2.5,
+		},
+	}
+	gpuRequests := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("975m"),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	gpuLimits := v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}
+	transformerRequests := v1.ResourceList{v1.ResourceCPU: resource.MustParse("475m")}
+	scenarios := map[string]struct {
+		components []ComponentType
+		pods       []v1.Pod
+		config     *CostConfig
+		expected   *CostStatus
+	}{
+		"NoPrices": {
+			components: []ComponentType{PredictorComponent},
+			pods:       []v1.Pod{makeCostPod(PredictorComponent, v1.PodRunning, "node-1", gpuRequests, gpuLimits)},
+			config:     &CostConfig{Currency: "USD"},
+			expected:   nil,
+		},
+		"RunningReplicas": {
+			components: []ComponentType{PredictorComponent, TransformerComponent},
+			pods: []v1.Pod{
+				makeCostPod(PredictorComponent, v1.PodRunning, "node-1", gpuRequests, gpuLimits),
+				makeCostPod(PredictorComponent, v1.PodRunning, "node-2", gpuRequests, gpuLimits),
+				makeCostPod(TransformerComponent, v1.PodRunning, "node-1", transformerRequests, nil),
+			},
+			config: config,
+			expected: &CostStatus{
+				Currency:   "USD",
+				HourlyCost: "5.1400",
+				Components: map[ComponentType]ComponentCostStatus{
+					PredictorComponent:   {Replicas: 2, HourlyCost: "5.1200"},
+					TransformerComponent: {Replicas: 1, HourlyCost: "0.0200"},
+				},
+			},
+		},
+		"UnscheduledAndTerminatedReplicas": {
+			components: []ComponentType{PredictorComponent},
+			pods: []v1.Pod{
+				makeCostPod(PredictorComponent, v1.PodRunning, "node-1", gpuRequests, gpuLimits),
+				makeCostPod(PredictorComponent, v1.PodPending, "", gpuRequests, gpuLimits),
+				makeCostPod(PredictorComponent, v1.PodSucceeded, "node-2", gpuRequests, gpuLimits),
+				makeCostPod(ExplainerComponent, v1.PodRunning, "node-2", gpuRequests, gpuLimits),
+			},
+			config: config,
+			expected: &CostStatus{
+				Currency:   "USD",
+				HourlyCost: "2.5600",
+				Components: map[ComponentType]ComponentCostStatus{
+					PredictorComponent: {Replicas: 1, HourlyCost: "2.5600"},
+				},
+			},
+		},
+		"ScaledToZero": {
+			components: []ComponentType{PredictorComponent},
+			config:     config,
+			expected: &CostStatus{
+				Currency:   "USD",
+				HourlyCost: "0.0000",
+				Components: map[ComponentType]ComponentCostStatus{
+					PredictorComponent: {Replicas: 0, HourlyCost: "0.0000"},
+				},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			status := &InferenceServiceStatus{Cost: &CostStatus{HourlyCost: "1.0000"}}
+			status.PropagateCost(scenario.components, scenario.pods, scenario.config)
+			g.Expect(status.Cost).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
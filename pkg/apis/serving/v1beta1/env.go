@@ -0,0 +1,73 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Known error messages
+const (
+	InvalidEnvVarSourceError  = "Env var %q of the %s must be set from a configMapKeyRef or a secretKeyRef, the field and resource references are not supported by Knative."
+	InvalidEnvFromSourceError = "The envFrom of the %s must set exactly one of configMapRef and secretRef."
+)
+
+// explainerContainer returns the explainer container set in the spec
+func explainerContainer(explainer *ExplainerSpec) *v1.Container {
+	switch {
+	case len(explainer.PodSpec.Containers) != 0:
+		return &explainer.PodSpec.Containers[0]
+	case explainer.Alibi != nil:
+		return &explainer.Alibi.Container
+	case explainer.AIX != nil:
+		return &explainer.AIX.Container
+	}
+	return nil
+}
+
+// validateEnv validates the env vars and the envFrom sources of the component containers, whether they are set on a
+// framework or on a custom container, before Knative rejects the knative services
+func validateEnv(isvc *InferenceService) error {
+	containers := map[ComponentType]*v1.Container{
+		PredictorComponent: predictorContainer(&isvc.Spec.Predictor),
+	}
+	if isvc.Spec.Transformer != nil && len(isvc.Spec.Transformer.PodSpec.Containers) != 0 {
+		containers[TransformerComponent] = &isvc.Spec.Transformer.PodSpec.Containers[0]
+	}
+	if isvc.Spec.Explainer != nil {
+		containers[ExplainerComponent] = explainerContainer(isvc.Spec.Explainer)
+	}
+	for _, component := range []ComponentType{PredictorComponent, TransformerComponent, ExplainerComponent} {
+		container := containers[component]
+		if container == nil {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef == nil && env.ValueFrom.SecretKeyRef == nil {
+				return fmt.Errorf(InvalidEnvVarSourceError, env.Name, component)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if (envFrom.ConfigMapRef == nil) == (envFrom.SecretRef == nil) {
+				return fmt.Errorf(InvalidEnvFromSourceError, component)
+			}
+		}
+	}
+	return nil
+}
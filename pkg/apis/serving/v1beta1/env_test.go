@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEnvValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	secretKeyRef := v1.EnvVar{Name: "API_TOKEN", ValueFrom: &v1.EnvVarSource{
+		SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}, Key: "token"},
+	}}
+	fieldRef := v1.EnvVar{Name: "POD_NAME", ValueFrom: &v1.EnvVarSource{
+		FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"},
+	}}
+	secretRef := v1.EnvFromSource{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}}}
+	configMapRef := v1.EnvFromSource{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "tuning"}}}
+	sklearn := func(container v1.Container) PredictorSpec {
+		return PredictorSpec{SKLearn: &SKLearnSpec{PredictorExtensionSpec: PredictorExtensionSpec{Container: container}}}
+	}
+	scenarios := map[string]struct {
+		spec    InferenceServiceSpec
+		matcher types.GomegaMatcher
+	}{
+		"FrameworkEnvFromAndSecretKeyRef": {
+			spec: InferenceServiceSpec{
+				Predictor: sklearn(v1.Container{
+					Env:     []v1.EnvVar{{Name: "WORKERS", Value: "4"}, secretKeyRef},
+					EnvFrom: []v1.EnvFromSource{secretRef, configMapRef},
+				}),
+			},
+			matcher: gomega.BeNil(),
+		},
+		"FrameworkFieldRef": {
+			spec:    InferenceServiceSpec{Predictor: sklearn(v1.Container{Env: []v1.EnvVar{fieldRef}})},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidEnvVarSourceError, "POD_NAME", PredictorComponent)),
+		},
+		"FrameworkEnvFromWithoutSource": {
+			spec:    InferenceServiceSpec{Predictor: sklearn(v1.Container{EnvFrom: []v1.EnvFromSource{{Prefix: "APP_"}}})},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidEnvFromSourceError, PredictorComponent)),
+		},
+		"TransformerEnvFromWithBothSources": {
+			spec: InferenceServiceSpec{
+				Predictor: sklearn(v1.Container{}),
+				Transformer: &TransformerSpec{PodSpec: PodSpec{Containers: []v1.Container{{
+					EnvFrom: []v1.EnvFromSource{{SecretRef: secretRef.SecretRef, ConfigMapRef: configMapRef.ConfigMapRef}},
+				}}}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidEnvFromSourceError, TransformerComponent)),
+		},
+		"ExplainerFieldRef": {
+			spec: InferenceServiceSpec{
+				Predictor: sklearn(v1.Container{}),
+				Explainer: &ExplainerSpec{Alibi: &AlibiExplainerSpec{Container: v1.Container{Env: []v1.EnvVar{fieldRef}}}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidEnvVarSourceError, "POD_NAME", ExplainerComponent)),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateEnv(&InferenceService{Spec: scenario.spec})
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
@@ -31,6 +31,10 @@ type ExplainerSpec struct {
 	PodSpec `json:",inline"`
 	// Extensions available in all components
 	ComponentExtensionSpec `json:",inline"`
+	// Percentage of the predict requests which are explained asynchronously in addition to the explicit
+	// explain calls. Explanations are published to the logger sink of the predictor, which must enable the logger.
+	// +optional
+	SamplingPercent *int64 `json:"samplingPercent,omitempty"`
 }
 
 var _ Component = &ExplainerSpec{}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/constants"
@@ -12,17 +13,42 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// AIXExplainerType is the explanation method
 type AIXExplainerType string
 
+// AIXExplainerType Enum
 const (
-	AIXLimeImageExplainer AIXExplainerType = "LimeImages"
+	AIXLimeImageExplainer   AIXExplainerType = "LimeImages"
+	AIXLimeTabularExplainer AIXExplainerType = "LimeTabular"
+	AIXLimeTextExplainer    AIXExplainerType = "LimeText"
+	AIXProtodashExplainer   AIXExplainerType = "Protodash"
 )
 
+// Known error messages
+const (
+	InvalidAIXExplainerTypeError = "AIX explainer type must be one of: [%s]. Type [%s] is not supported."
+	MissingAIXTrainingDataError  = "AIX explainer type [%s] requires the storageUri of its training data."
+)
+
+// SupportedAIXExplainerTypes lists the AIX360 explanation methods served by the AIX explainer image
+var SupportedAIXExplainerTypes = []AIXExplainerType{
+	AIXLimeImageExplainer,
+	AIXLimeTabularExplainer,
+	AIXLimeTextExplainer,
+	AIXProtodashExplainer,
+}
+
 // AIXExplainerSpec defines the arguments for configuring an AIX Explanation Server
 type AIXExplainerSpec struct {
 	// The type of AIX explainer
+	// Valid values are:
+	// - "LimeImages";
+	// - "LimeTabular";
+	// - "LimeText";
+	// - "Protodash";
 	Type AIXExplainerType `json:"type"`
-	// The location of a trained explanation model
+	// The location of a trained explanation model, the LimeTabular explainer loads its training data from
+	// training_data.npy
 	StorageURI string `json:"storageUri,omitempty"`
 	// Defaults to latest AIX Version
 	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
@@ -74,16 +100,22 @@ func (s *AIXExplainerSpec) GetContainer(metadata metav1.ObjectMeta, extensions *
 		args = append(args, s.Config[k])
 	}
 
-	return &v1.Container{
-		Image:     config.Explainers.AIXExplainer.ContainerImage + ":" + *s.RuntimeVersion,
-		Name:      constants.InferenceServiceContainerName,
-		Resources: s.Resources,
-		Args:      args,
+	// The container is built on a copy of the container overrides of the spec, e.g. the env vars and the envFrom
+	// sources, so the spec is left unchanged
+	container := s.Container.DeepCopy()
+	if container.Image == "" {
+		container.Image = config.Explainers.AIXExplainer.ContainerImage + ":" + *s.RuntimeVersion
 	}
+	container.Name = constants.InferenceServiceContainerName
+	container.Args = args
+	return container
 }
 
 func (s *AIXExplainerSpec) Default(config *InferenceServicesConfig) {
 	s.Name = constants.InferenceServiceContainerName
+	if s.Type == "" {
+		s.Type = AIXLimeImageExplainer
+	}
 	if s.RuntimeVersion == nil {
 		s.RuntimeVersion = proto.String(config.Explainers.AIXExplainer.DefaultImageVersion)
 	}
@@ -94,5 +126,26 @@ func (s *AIXExplainerSpec) Default(config *InferenceServicesConfig) {
 func (s *AIXExplainerSpec) Validate() error {
 	return utils.FirstNonNilError([]error{
 		validateStorageURI(s.GetStorageUri()),
+		validateAIXExplainerType(s.Type),
+		validateAIXTrainingData(s.Type, s.StorageURI),
 	})
 }
+
+// validateAIXTrainingData checks the LimeTabular explainer has the storage uri of its training data
+func validateAIXTrainingData(explainerType AIXExplainerType, storageURI string) error {
+	if explainerType == AIXLimeTabularExplainer && storageURI == "" {
+		return fmt.Errorf(MissingAIXTrainingDataError, explainerType)
+	}
+	return nil
+}
+
+func validateAIXExplainerType(explainerType AIXExplainerType) error {
+	supported := []string{}
+	for _, supportedType := range SupportedAIXExplainerTypes {
+		if explainerType == supportedType {
+			return nil
+		}
+		supported = append(supported, string(supportedType))
+	}
+	return fmt.Errorf(InvalidAIXExplainerTypeError, strings.Join(supported, ", "), explainerType)
+}
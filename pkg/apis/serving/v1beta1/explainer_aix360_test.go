@@ -1,6 +1,7 @@
 package v1beta1
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
@@ -33,6 +34,35 @@ func TestAIXExplainer(t *testing.T) {
 			},
 			matcher: gomega.Succeed(),
 		},
+		"AcceptProtodashExplainer": {
+			spec: AIXExplainerSpec{
+				Type:           AIXProtodashExplainer,
+				RuntimeVersion: proto.String("latest"),
+			},
+			matcher: gomega.Succeed(),
+		},
+		"AcceptLimeTabularWithTrainingData": {
+			spec: AIXExplainerSpec{
+				Type:           AIXLimeTabularExplainer,
+				StorageURI:     "gs://someUri",
+				RuntimeVersion: proto.String("latest"),
+			},
+			matcher: gomega.Succeed(),
+		},
+		"RejectLimeTabularWithoutTrainingData": {
+			spec: AIXExplainerSpec{
+				Type:           AIXLimeTabularExplainer,
+				RuntimeVersion: proto.String("latest"),
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(MissingAIXTrainingDataError, "LimeTabular")),
+		},
+		"RejectUnknownExplainerType": {
+			spec: AIXExplainerSpec{
+				Type:           "Shap",
+				RuntimeVersion: proto.String("latest"),
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidAIXExplainerTypeError, "LimeImages, LimeTabular, LimeText, Protodash", "Shap")),
+		},
 	}
 	for name, scenario := range scenarios {
 		t.Run(name, func(t *testing.T) {
@@ -70,11 +100,16 @@ func TestCreateAIXExplainerContainer(t *testing.T) {
 	ComponentExtensionSpec := ComponentExtensionSpec{
 		MaxReplicas: 2,
 	}
+	envFrom := []v1.EnvFromSource{
+		{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}}},
+	}
 	var spec = AIXExplainerSpec{
 		Type:       "LimeImages",
 		StorageURI: "gs://someUri",
 		Container: v1.Container{
 			Resources: requestedResource,
+			Env:       []v1.EnvVar{{Name: "WORKERS", Value: "2"}},
+			EnvFrom:   envFrom,
 		},
 		RuntimeVersion: proto.String("0.2.2"),
 	}
@@ -84,6 +119,8 @@ func TestCreateAIXExplainerContainer(t *testing.T) {
 		Image:     "aipipeline/aixexplainer:0.2.2",
 		Name:      constants.InferenceServiceContainerName,
 		Resources: requestedResource,
+		Env:       []v1.EnvVar{{Name: "WORKERS", Value: "2"}},
+		EnvFrom:   envFrom,
 		Args: []string{
 			constants.ArgumentModelName,
 			"someName",
@@ -99,8 +136,11 @@ func TestCreateAIXExplainerContainer(t *testing.T) {
 	}
 
 	// Test Create with config
+	expectedSpec := spec.DeepCopy()
 	container := spec.GetContainer(metav1.ObjectMeta{Name: "someName", Namespace: "default"}, &ComponentExtensionSpec, config)
 	g.Expect(container).To(gomega.Equal(expectedContainer))
+	// The spec is left unchanged
+	g.Expect(&spec).To(gomega.Equal(expectedSpec))
 }
 
 func TestCreateAIXExplainerContainerWithConfig(t *testing.T) {
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
@@ -65,26 +66,28 @@ func (c *CustomExplainer) GetStorageUri() *string {
 // GetContainer transforms the resource into a container spec
 func (c *CustomExplainer) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
 	container := &c.Containers[0]
-	modelNameExists := false
-	for _, arg := range container.Args {
-		if arg == constants.ArgumentModelName {
-			modelNameExists = true
-		}
-	}
-	if !modelNameExists {
-		container.Args = append(container.Args, []string{
-			constants.ArgumentModelName,
-			metadata.Name,
-		}...)
+	// Arguments already provided by the user take precedence over the defaults
+	defaultArgs := [][]string{
+		{constants.ArgumentModelName, metadata.Name},
+		{constants.ArgumentPredictorHost, constants.PredictorURL(metadata, false)},
+		{constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort},
 	}
-	container.Args = append(container.Args, []string{
-		constants.ArgumentPredictorHost,
-		constants.PredictorURL(metadata, false),
-		constants.ArgumentHttpPort,
-		constants.InferenceServiceDefaultHttpPort,
-	}...)
 	if extensions.ContainerConcurrency != nil {
-		container.Args = append(container.Args, constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10))
+		defaultArgs = append(defaultArgs, []string{constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10)})
+	}
+	for _, arg := range defaultArgs {
+		if !hasArgument(container.Args, arg[0]) {
+			container.Args = append(container.Args, arg...)
+		}
 	}
 	return &c.Containers[0]
 }
+
+func hasArgument(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -330,6 +330,49 @@ func TestCreateCustomExplainerContainer(t *testing.T) {
 				},
 			},
 		},
+		"ContainerSpecWithUserProvidedArgs": {
+			isvc: InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "sklearn",
+				},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						SKLearn: &SKLearnSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI: proto.String("gs://someUri"),
+							},
+						},
+					},
+					Explainer: &ExplainerSpec{
+						PodSpec: PodSpec{
+							Containers: []v1.Container{
+								{
+									Image: "explainer:0.1.0",
+									Args: []string{
+										"--predictor_host",
+										"custom-predictor.default",
+										"--http_port=9000",
+									},
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "explainer:0.1.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args: []string{
+					"--predictor_host",
+					"custom-predictor.default",
+					"--http_port=9000",
+					"--model_name",
+					"someName",
+				},
+			},
+		},
 	}
 	for name, scenario := range scenarios {
 		t.Run(name, func(t *testing.T) {
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// Known error messages
+const (
+	FederationPlacementError   = "Federation requires clusters or a clusterSelector."
+	FederationClusterNameError = "Invalid federation cluster %q: %s."
+	FederationSelectorError    = "Invalid federation clusterSelector: %v."
+)
+
+// FederationSpec propagates the inference service to member clusters, each member cluster serves a copy of the
+// inference service in the same namespace. The member clusters are registered with the secrets of their kubeconfig
+// in the namespace of the controller, labelled serving.kubeflow.org/member-cluster.
+type FederationSpec struct {
+	// Clusters the inference service is propagated to, the names of the secrets of the member clusters
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+	// ClusterSelector selects the member clusters the inference service is propagated to by the labels of their
+	// secrets, e.g. topology.kubernetes.io/region
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+}
+
+// FederationStatus is the status of the copies of the inference service in the member clusters
+type FederationStatus struct {
+	// Global URL of the inference service served by all the member clusters, set when the federation config has a
+	// domain template. It has the form http://{global host}
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+	// Statuses of the copies of the inference service in the member clusters, sorted by cluster name
+	// +optional
+	Clusters []FederatedClusterStatus `json:"clusters,omitempty"`
+}
+
+// FederatedClusterStatus is the status of the copy of the inference service in a member cluster
+type FederatedClusterStatus struct {
+	// Name of the member cluster
+	Name string `json:"name"`
+	// Region of the member cluster, the topology.kubernetes.io/region label of its secret
+	// +optional
+	Region string `json:"region,omitempty"`
+	// URL of the inference service in the member cluster
+	// +optional
+	URL *apis.URL `json:"url,omitempty"`
+	// Whether the inference service is ready in the member cluster
+	Ready bool `json:"ready"`
+	// Reason the inference service is not ready in the member cluster
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+func validateFederation(federation *FederationSpec) error {
+	if federation == nil {
+		return nil
+	}
+	if len(federation.Clusters) == 0 && federation.ClusterSelector == nil {
+		return fmt.Errorf(FederationPlacementError)
+	}
+	for _, cluster := range federation.Clusters {
+		if errs := validation.IsDNS1123Subdomain(cluster); len(errs) > 0 {
+			return fmt.Errorf(FederationClusterNameError, cluster, strings.Join(errs, ", "))
+		}
+	}
+	if federation.ClusterSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(federation.ClusterSelector); err != nil {
+			return fmt.Errorf(FederationSelectorError, err)
+		}
+	}
+	return nil
+}
+
+// MarkFederationReady sets the FederationReady condition, false with the member clusters the inference service is
+// not ready in
+func (ss *InferenceServiceStatus) MarkFederationReady() {
+	notReady := []string{}
+	for _, cluster := range ss.Federation.Clusters {
+		if !cluster.Ready {
+			notReady = append(notReady, cluster.Name)
+		}
+	}
+	if len(ss.Federation.Clusters) == 0 {
+		conditionSet.Manage(ss).MarkFalse(FederationReady, NoMemberCluster,
+			"No member cluster matches the federation placement")
+	} else if len(notReady) > 0 {
+		conditionSet.Manage(ss).MarkFalse(FederationReady, MemberClusterNotReady,
+			"The inference service is not ready in the member clusters %s", strings.Join(notReady, ", "))
+	} else {
+		conditionSet.Manage(ss).MarkTrue(FederationReady)
+	}
+}
+
+// MarkFederationNotAllowed sets the FederationReady condition to false since the namespace of the inference service is
+// not allowed by the federation config
+func (ss *InferenceServiceStatus) MarkFederationNotAllowed(namespace string) {
+	conditionSet.Manage(ss).MarkFalse(FederationReady, NamespaceNotFederated,
+		"The namespace %s is not in the allowedNamespaces of the federation config", namespace)
+}
+
+// MarkFederationHostNotAllowed sets the FederationReady condition to false since the global host is not under the
+// hostOverrideDomains of the ingress config of the member clusters, the inference service is not propagated to them
+func (ss *InferenceServiceStatus) MarkFederationHostNotAllowed(host string, clusters []string) {
+	conditionSet.Manage(ss).MarkFalse(FederationReady, GlobalHostNotAllowed,
+		"The global host %s is not under the hostOverrideDomains of the member clusters %s", host,
+		strings.Join(clusters, ", "))
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFederationValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		federation *FederationSpec
+		matcher    types.GomegaMatcher
+	}{
+		"NoFederation": {
+			federation: nil,
+			matcher:    gomega.BeNil(),
+		},
+		"Clusters": {
+			federation: &FederationSpec{Clusters: []string{"us-east", "europe"}},
+			matcher:    gomega.BeNil(),
+		},
+		"ClusterSelector": {
+			federation: &FederationSpec{ClusterSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"topology.kubernetes.io/region": "us-east1"},
+			}},
+			matcher: gomega.BeNil(),
+		},
+		"NoPlacement": {
+			federation: &FederationSpec{},
+			matcher:    gomega.MatchError(FederationPlacementError),
+		},
+		"InvalidClusterName": {
+			federation: &FederationSpec{Clusters: []string{"US_East"}},
+			matcher:    gomega.MatchError(gomega.ContainSubstring(`Invalid federation cluster "US_East"`)),
+		},
+		"InvalidClusterSelector": {
+			federation: &FederationSpec{ClusterSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "topology.kubernetes.io/region",
+					Operator: "Near",
+				}},
+			}},
+			matcher: gomega.MatchError(gomega.ContainSubstring("Invalid federation clusterSelector")),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(validateFederation(scenario.federation)).Should(scenario.matcher)
+		})
+	}
+}
+
+func TestMarkFederationReady(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		clusters []FederatedClusterStatus
+		status   v1.ConditionStatus
+		reason   string
+		message  string
+	}{
+		"NoMemberCluster": {
+			clusters: nil,
+			status:   v1.ConditionFalse,
+			reason:   NoMemberCluster,
+			message:  "No member cluster matches the federation placement",
+		},
+		"MemberClusterNotReady": {
+			clusters: []FederatedClusterStatus{{Name: "europe"}, {Name: "us-east", Ready: true}, {Name: "us-west"}},
+			status:   v1.ConditionFalse,
+			reason:   MemberClusterNotReady,
+			message:  fmt.Sprintf("The inference service is not ready in the member clusters %s", "europe, us-west"),
+		},
+		"Ready": {
+			clusters: []FederatedClusterStatus{{Name: "europe", Ready: true}, {Name: "us-east", Ready: true}},
+			status:   v1.ConditionTrue,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			status := &InferenceServiceStatus{Federation: &FederationStatus{Clusters: scenario.clusters}}
+			status.MarkFederationReady()
+			condition := status.GetCondition(FederationReady)
+			g.Expect(condition.Status).To(gomega.Equal(scenario.status))
+			g.Expect(condition.Reason).To(gomega.Equal(scenario.reason))
+			g.Expect(condition.Message).To(gomega.Equal(scenario.message))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Known error messages
+const (
+	InvalidGPUTypeError           = "GPU type %q is not supported, must be nvidia.com/gpu or a MIG profile, e.g. nvidia.com/mig-1g.5gb."
+	GPUCountLowerBoundError       = "GPU count cannot be less than 1."
+	GPUTimeSlicingCountError      = "GPU count of a time-sliced GPU cannot be more than 1, the replicas of a time-sliced GPU share the same GPU."
+	GPUResourcesConflictError     = "GPU conflicts with the %s resources of the predictor, remove the GPU resources or the gpu field."
+	GPUCustomContainersCountError = "GPU requires a single container in the predictor, set the GPU resources of the containers instead."
+)
+
+// migProfileRegex matches the resources of the MIG profiles, e.g. nvidia.com/mig-1g.5gb or nvidia.com/mig-3g.20gb
+var migProfileRegex = regexp.MustCompile(`^nvidia\.com/mig-[1-7]g\.[0-9]+gb$`)
+
+// GPUSpec selects the GPUs of the predictor, it sets the GPU resources of the predictor container which select the
+// GPU enabled runtime version of the predictor
+type GPUSpec struct {
+	// Type is the resource of the GPU advertised by the NVIDIA device plugin: nvidia.com/gpu for whole GPUs or a MIG
+	// profile with the mixed strategy, e.g. nvidia.com/mig-1g.5gb. Defaults to nvidia.com/gpu.
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Count of GPUs or MIG instances, defaults to 1
+	// +optional
+	Count *int64 `json:"count,omitempty"`
+	// TimeSlicing shares the GPU with the other time-sliced workloads of the node, the predictor requests the shared
+	// resource of the type, e.g. nvidia.com/gpu.shared. Requires the time-slicing of the NVIDIA device plugin with
+	// renameByDefault.
+	// +optional
+	TimeSlicing bool `json:"timeSlicing,omitempty"`
+}
+
+// ResourceName returns the resource requested by the predictor container
+func (g *GPUSpec) ResourceName() v1.ResourceName {
+	name := g.Type
+	if name == "" {
+		name = constants.NvidiaGPUResourceType
+	}
+	if g.TimeSlicing {
+		name += constants.GPUSharedResourceSuffix
+	}
+	return v1.ResourceName(name)
+}
+
+// GetCount returns the number of GPUs or MIG instances
+func (g *GPUSpec) GetCount() int64 {
+	if g.Count == nil {
+		return 1
+	}
+	return *g.Count
+}
+
+// Validate returns an error if invalid
+func (g *GPUSpec) Validate() error {
+	if g.Type != "" && g.Type != constants.NvidiaGPUResourceType && !migProfileRegex.MatchString(g.Type) {
+		return fmt.Errorf(InvalidGPUTypeError, g.Type)
+	}
+	if g.GetCount() < 1 {
+		return fmt.Errorf(GPUCountLowerBoundError)
+	}
+	if g.TimeSlicing && g.GetCount() > 1 {
+		return fmt.Errorf(GPUTimeSlicingCountError)
+	}
+	return nil
+}
+
+// setGPUResources sets the GPU resources of the predictor container, the GPU resources set in the predictor
+// container are kept and checked by the validation
+func setGPUResources(predictor *PredictorSpec) {
+	if predictor.GPU == nil {
+		return
+	}
+	resources := predictorResources(predictor)
+	if resources == nil || utils.IsGPUEnabled(*resources) {
+		return
+	}
+	quantity := *resource.NewQuantity(predictor.GPU.GetCount(), resource.DecimalSI)
+	if resources.Limits == nil {
+		resources.Limits = v1.ResourceList{}
+	}
+	if resources.Requests == nil {
+		resources.Requests = v1.ResourceList{}
+	}
+	resources.Limits[predictor.GPU.ResourceName()] = quantity
+	resources.Requests[predictor.GPU.ResourceName()] = quantity
+}
+
+// validateGPU validates the GPU of the predictor and checks that the GPU resources of the predictor container match
+func validateGPU(predictor *PredictorSpec) error {
+	if predictor.GPU == nil {
+		return nil
+	}
+	if err := predictor.GPU.Validate(); err != nil {
+		return err
+	}
+	if len(predictor.PodSpec.Containers) > 1 {
+		return fmt.Errorf(GPUCustomContainersCountError)
+	}
+	resources := predictorResources(predictor)
+	if resources == nil {
+		return nil
+	}
+	expected := resource.NewQuantity(predictor.GPU.GetCount(), resource.DecimalSI)
+	for name, quantity := range resources.Limits {
+		if !utils.IsGPUResource(name) {
+			continue
+		}
+		if name != predictor.GPU.ResourceName() || quantity.Cmp(*expected) != 0 {
+			return fmt.Errorf(GPUResourcesConflictError, name)
+		}
+	}
+	return nil
+}
+
+// predictorResources returns the resources of the predictor container
+func predictorResources(predictor *PredictorSpec) *v1.ResourceRequirements {
+	if len(predictor.PodSpec.Containers) != 0 {
+		return &predictor.PodSpec.Containers[0].Resources
+	}
+	implementations := predictor.GetImplementations()
+	if len(implementations) == 0 {
+		return nil
+	}
+	if model, ok := implementations[0].(*ModelPredictorSpec); ok {
+		return &model.Resources
+	}
+	_, resources, _ := resourceProfiles(implementations[0], &InferenceServicesConfig{})
+	return resources
+}
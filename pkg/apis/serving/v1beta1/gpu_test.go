@@ -0,0 +1,205 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGPUValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	tensorflow := func(limits v1.ResourceList) *TFServingSpec {
+		return &TFServingSpec{
+			PredictorExtensionSpec: PredictorExtensionSpec{
+				StorageURI: proto.String("gs://models/tensorflow"),
+				Container: v1.Container{
+					Resources: v1.ResourceRequirements{Limits: limits},
+				},
+			},
+		}
+	}
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"NoGPU": {
+			spec:    PredictorSpec{Tensorflow: tensorflow(nil)},
+			matcher: gomega.BeNil(),
+		},
+		"WholeGPUs": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(v1.ResourceList{"nvidia.com/gpu": resource.MustParse("2")}),
+				GPU:        &GPUSpec{Count: proto.Int64(2)},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"MIGProfile": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")}),
+				GPU:        &GPUSpec{Type: "nvidia.com/mig-1g.5gb"},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"TimeSlicing": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")}),
+				GPU:        &GPUSpec{TimeSlicing: true},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"InvalidType": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(nil),
+				GPU:        &GPUSpec{Type: "amd.com/gpu"},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidGPUTypeError, "amd.com/gpu")),
+		},
+		"InvalidMIGProfile": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(nil),
+				GPU:        &GPUSpec{Type: "nvidia.com/mig-9g.5gb"},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidGPUTypeError, "nvidia.com/mig-9g.5gb")),
+		},
+		"ZeroCount": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(nil),
+				GPU:        &GPUSpec{Count: proto.Int64(0)},
+			},
+			matcher: gomega.MatchError(GPUCountLowerBoundError),
+		},
+		"TimeSlicingCount": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(nil),
+				GPU:        &GPUSpec{Count: proto.Int64(2), TimeSlicing: true},
+			},
+			matcher: gomega.MatchError(GPUTimeSlicingCountError),
+		},
+		"ConflictingResource": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}),
+				GPU:        &GPUSpec{Type: "nvidia.com/mig-1g.5gb"},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(GPUResourcesConflictError, "nvidia.com/gpu")),
+		},
+		"ConflictingCount": {
+			spec: PredictorSpec{
+				Tensorflow: tensorflow(v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}),
+				GPU:        &GPUSpec{Count: proto.Int64(2)},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(GPUResourcesConflictError, "nvidia.com/gpu")),
+		},
+		"MultipleCustomContainers": {
+			spec: PredictorSpec{
+				PodSpec: PodSpec{Containers: []v1.Container{{Name: "server"}, {Name: "sidecar"}}},
+				GPU:     &GPUSpec{},
+			},
+			matcher: gomega.MatchError(GPUCustomContainersCountError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateGPU(&scenario.spec)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestGPUDefaults(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := &InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			Tensorflow: PredictorConfig{
+				ContainerImage:         "tfserving",
+				DefaultImageVersion:    "1.14.0",
+				DefaultGpuImageVersion: "1.14.0-gpu",
+			},
+		},
+	}
+	scenarios := map[string]struct {
+		gpu             *GPUSpec
+		limits          v1.ResourceList
+		expectedGPU     map[v1.ResourceName]string
+		expectedVersion string
+	}{
+		"WholeGPU": {
+			gpu:             &GPUSpec{},
+			expectedGPU:     map[v1.ResourceName]string{"nvidia.com/gpu": "1"},
+			expectedVersion: "1.14.0-gpu",
+		},
+		"MIGProfile": {
+			gpu:             &GPUSpec{Type: "nvidia.com/mig-3g.20gb", Count: proto.Int64(2)},
+			expectedGPU:     map[v1.ResourceName]string{"nvidia.com/mig-3g.20gb": "2"},
+			expectedVersion: "1.14.0-gpu",
+		},
+		"TimeSlicing": {
+			gpu:             &GPUSpec{TimeSlicing: true},
+			expectedGPU:     map[v1.ResourceName]string{"nvidia.com/gpu.shared": "1"},
+			expectedVersion: "1.14.0-gpu",
+		},
+		"KeepGPUResources": {
+			gpu:             &GPUSpec{Count: proto.Int64(2)},
+			limits:          v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+			expectedGPU:     map[v1.ResourceName]string{"nvidia.com/gpu": "1"},
+			expectedVersion: "1.14.0-gpu",
+		},
+		"NoGPU": {
+			expectedGPU:     map[v1.ResourceName]string{},
+			expectedVersion: "1.14.0",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						Tensorflow: &TFServingSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI: proto.String("gs://testbucket/testmodel"),
+								Container: v1.Container{
+									Resources: v1.ResourceRequirements{Limits: scenario.limits},
+								},
+							},
+						},
+						GPU: scenario.gpu,
+					},
+				},
+			}
+			isvc.DefaultInferenceService(config)
+			limits := map[v1.ResourceName]string{}
+			for name, quantity := range isvc.Spec.Predictor.Tensorflow.Resources.Limits {
+				if name != v1.ResourceCPU && name != v1.ResourceMemory {
+					limits[name] = quantity.String()
+				}
+			}
+			g.Expect(limits).To(gomega.Equal(scenario.expectedGPU))
+			g.Expect(*isvc.Spec.Predictor.Tensorflow.RuntimeVersion).To(gomega.Equal(scenario.expectedVersion))
+		})
+	}
+}
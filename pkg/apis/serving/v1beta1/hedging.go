@@ -0,0 +1,119 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	HedgingPercentileError        = "Hedging percentile must be between 50 and 99."
+	HedgingDelayLowerBoundError   = "Hedging delay cannot be less than 1ms."
+	HedgingMaxHedgesError         = "Hedging maxHedges must be between 1 and 3."
+	HedgingRetryConflictError     = "Hedging cannot be set with retry, the hedged requests are the retries of the routes of the component."
+	HedgingTimeoutError           = "Hedging delay must be less than the timeout of the component."
+	HedgingStreamingConflictError = "Hedging cannot be set with the %s protocol of the predictor."
+	HedgingTransformerError       = "Hedging of the predictor cannot be set with a transformer, the requests of the transformer are not routed by the ingress gateway, set the hedging of the transformer instead."
+)
+
+// Default hedging values
+const (
+	DefaultHedgingDelay     = time.Second
+	DefaultHedgingMaxHedges = 1
+)
+
+// HedgingSpec sends a hedged copy of a request of the component when the request is not answered within the hedging
+// delay, the first response is returned and the other requests are cancelled. The requests are hedged by the ingress
+// gateway on the routes of the component, the hedged copies are load balanced to the replicas again.
+type HedgingSpec struct {
+	// Percentile of the request latency of the component the hedged requests are sent after, e.g. 95 hedges the 5%
+	// slowest requests. The latency is queried from the Prometheus server of the controller over the last 5 minutes,
+	// the delay is used until the latency is known.
+	// +optional
+	Percentile *int64 `json:"percentile,omitempty"`
+	// Delay the hedged requests are sent after without percentile, or until the percentile of the latency is known,
+	// e.g. 200ms. Defaults to 1s.
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+	// MaxHedges is the number of hedged copies sent for a request, defaults to 1
+	// +optional
+	MaxHedges *int32 `json:"maxHedges,omitempty"`
+}
+
+// GetDelay returns the hedging delay used until the percentile of the latency is known
+func (h *HedgingSpec) GetDelay() time.Duration {
+	if h.Delay == nil {
+		return DefaultHedgingDelay
+	}
+	return h.Delay.Duration
+}
+
+// GetMaxHedges returns the number of hedged copies of a request
+func (h *HedgingSpec) GetMaxHedges() int32 {
+	if h.MaxHedges == nil {
+		return DefaultHedgingMaxHedges
+	}
+	return *h.MaxHedges
+}
+
+func validateHedging(hedging *HedgingSpec, retry *RetryPolicy, timeoutSeconds *int64) error {
+	if hedging == nil {
+		return nil
+	}
+	if hedging.Percentile != nil && (*hedging.Percentile < 50 || *hedging.Percentile > 99) {
+		return fmt.Errorf(HedgingPercentileError)
+	}
+	if hedging.GetDelay() < time.Millisecond {
+		return fmt.Errorf(HedgingDelayLowerBoundError)
+	}
+	if hedging.GetMaxHedges() < 1 || hedging.GetMaxHedges() > 3 {
+		return fmt.Errorf(HedgingMaxHedgesError)
+	}
+	if retry != nil {
+		return fmt.Errorf(HedgingRetryConflictError)
+	}
+	if timeoutSeconds != nil && hedging.GetDelay() >= time.Duration(*timeoutSeconds)*time.Second {
+		return fmt.Errorf(HedgingTimeoutError)
+	}
+	return nil
+}
+
+// validateInferenceServiceHedging rejects the hedging of the predictor behind a transformer, and the hedging of the
+// streamed responses and the websocket connections of the predictor which the transformer relays
+func validateInferenceServiceHedging(isvc *InferenceService) error {
+	if isvc.Spec.Predictor.Hedging != nil && isvc.Spec.Transformer != nil {
+		return fmt.Errorf(HedgingTransformerError)
+	}
+	if !isvc.Spec.Predictor.IsStreaming() && !isvc.Spec.Predictor.IsWebsocket() {
+		return nil
+	}
+	if isvc.Spec.Predictor.Hedging != nil || (isvc.Spec.Transformer != nil && isvc.Spec.Transformer.Hedging != nil) {
+		return fmt.Errorf(HedgingStreamingConflictError, isvc.Spec.Predictor.Protocol)
+	}
+	return nil
+}
+
+// HasHedging returns true when a component of the inference service hedges its requests
+func (isvc *InferenceService) HasHedging() bool {
+	return isvc.Spec.Predictor.Hedging != nil ||
+		(isvc.Spec.Transformer != nil && isvc.Spec.Transformer.Hedging != nil) ||
+		(isvc.Spec.Explainer != nil && isvc.Spec.Explainer.Hedging != nil)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHedgingValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	percentile := int64(95)
+	lowPercentile := int64(10)
+	timeout := int64(1)
+	hedges := int32(2)
+	zeroHedges := int32(0)
+	scenarios := map[string]struct {
+		hedging        *HedgingSpec
+		retry          *RetryPolicy
+		timeoutSeconds *int64
+		matcher        types.GomegaMatcher
+	}{
+		"NoHedging": {
+			hedging: nil,
+			matcher: gomega.BeNil(),
+		},
+		"Percentile": {
+			hedging: &HedgingSpec{Percentile: &percentile, MaxHedges: &hedges},
+			matcher: gomega.BeNil(),
+		},
+		"Delay": {
+			hedging: &HedgingSpec{Delay: &metav1.Duration{Duration: 200 * time.Millisecond}},
+			matcher: gomega.BeNil(),
+		},
+		"LowPercentile": {
+			hedging: &HedgingSpec{Percentile: &lowPercentile},
+			matcher: gomega.MatchError(HedgingPercentileError),
+		},
+		"ZeroDelay": {
+			hedging: &HedgingSpec{Delay: &metav1.Duration{}},
+			matcher: gomega.MatchError(HedgingDelayLowerBoundError),
+		},
+		"ZeroMaxHedges": {
+			hedging: &HedgingSpec{MaxHedges: &zeroHedges},
+			matcher: gomega.MatchError(HedgingMaxHedgesError),
+		},
+		"WithRetry": {
+			hedging: &HedgingSpec{},
+			retry:   &RetryPolicy{},
+			matcher: gomega.MatchError(HedgingRetryConflictError),
+		},
+		"DelayAboveTimeout": {
+			hedging:        &HedgingSpec{},
+			timeoutSeconds: &timeout,
+			matcher:        gomega.MatchError(HedgingTimeoutError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateHedging(scenario.hedging, scenario.retry, scenario.timeoutSeconds)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestInferenceServiceHedgingValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		isvc    *InferenceService
+		matcher types.GomegaMatcher
+	}{
+		"Hedging": {
+			isvc: &InferenceService{Spec: InferenceServiceSpec{Predictor: PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{Hedging: &HedgingSpec{}},
+			}}},
+			matcher: gomega.BeNil(),
+		},
+		"PredictorWithTransformer": {
+			isvc: &InferenceService{Spec: InferenceServiceSpec{
+				Predictor: PredictorSpec{
+					ComponentExtensionSpec: ComponentExtensionSpec{Hedging: &HedgingSpec{}},
+				},
+				Transformer: &TransformerSpec{},
+			}},
+			matcher: gomega.MatchError(HedgingTransformerError),
+		},
+		"PredictorWithStreaming": {
+			isvc: &InferenceService{Spec: InferenceServiceSpec{Predictor: PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{Hedging: &HedgingSpec{}},
+				Protocol:               StreamingProtocol,
+			}}},
+			matcher: gomega.MatchError(fmt.Sprintf(HedgingStreamingConflictError, StreamingProtocol)),
+		},
+		"TransformerWithWebsocket": {
+			isvc: &InferenceService{Spec: InferenceServiceSpec{
+				Predictor: PredictorSpec{Protocol: WebsocketProtocol},
+				Transformer: &TransformerSpec{
+					ComponentExtensionSpec: ComponentExtensionSpec{Hedging: &HedgingSpec{}},
+				},
+			}},
+			matcher: gomega.MatchError(fmt.Sprintf(HedgingStreamingConflictError, WebsocketProtocol)),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateInferenceServiceHedging(scenario.isvc)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
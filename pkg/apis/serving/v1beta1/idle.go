@@ -0,0 +1,144 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+// Known error messages
+const (
+	InvalidIdleTTLAnnotationError    = "Annotation %s must be a positive duration (e.g. 24h), got %q."
+	InvalidIdleActionAnnotationError = "Annotation %s must be ScaleToZero, Suspend or Delete, got %q."
+	IdleScaleToZeroNotSupportedError = "Annotation %s cannot be ScaleToZero, the %s is scaled with the %s scaleMetric."
+)
+
+// IdleAction is the action taken on an inference service receiving no request for the TTL after the last request
+type IdleAction string
+
+const (
+	// IdleActionScaleToZero sets the minReplicas of the components to 0
+	IdleActionScaleToZero IdleAction = "ScaleToZero"
+	// IdleActionSuspend sets spec.suspend
+	IdleActionSuspend IdleAction = "Suspend"
+	// IdleActionDelete deletes the inference service
+	IdleActionDelete IdleAction = "Delete"
+)
+
+// GetIdleTTL returns the TTL after the last request of the annotation, nil without the annotation
+func (isvc *InferenceService) GetIdleTTL() (*time.Duration, error) {
+	value, ok := isvc.Annotations[constants.IdleTTLAnnotationKey]
+	if !ok {
+		return nil, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf(InvalidIdleTTLAnnotationError, constants.IdleTTLAnnotationKey, value)
+	}
+	return &ttl, nil
+}
+
+// GetIdleAction returns the action of the annotation, Suspend by default
+func (isvc *InferenceService) GetIdleAction() IdleAction {
+	if action, ok := isvc.Annotations[constants.IdleActionAnnotationKey]; ok {
+		return IdleAction(action)
+	}
+	return IdleActionSuspend
+}
+
+// IsIdleActionTaken returns whether the inference service is suspended, or scaled to zero by the ScaleToZero action,
+// the time of the last request is not tracked meanwhile
+func (isvc *InferenceService) IsIdleActionTaken() bool {
+	if isvc.Spec.Suspend {
+		return true
+	}
+	if isvc.GetIdleAction() != IdleActionScaleToZero {
+		return false
+	}
+	for _, extension := range isvc.idleComponents() {
+		if extension.MinReplicas == nil || *extension.MinReplicas != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetIdleTTLEnforced sets the IdleTTLNotEnforced condition while the inference service has a TTL after the last
+// request the idle reaper does not enforce, it is cleared otherwise
+func (ss *InferenceServiceStatus) SetIdleTTLEnforced(hasTTL bool, reaperEnabled bool) {
+	if !hasTTL || reaperEnabled {
+		ss.ClearCondition(IdleTTLNotEnforced)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     IdleTTLNotEnforced,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   IdleReaperDisabled,
+		Message: fmt.Sprintf("Annotation %s is not enforced, the idle reaper requires the controller to run "+
+			"with a Prometheus server", constants.IdleTTLAnnotationKey),
+	})
+}
+
+// ScaleToZero sets the minReplicas of the components to 0
+func (isvc *InferenceService) ScaleToZero() {
+	for _, extension := range isvc.idleComponents() {
+		zero := 0
+		extension.MinReplicas = &zero
+	}
+}
+
+// idleComponents returns the extensions of the components of the inference service
+func (isvc *InferenceService) idleComponents() map[ComponentType]*ComponentExtensionSpec {
+	extensions := map[ComponentType]*ComponentExtensionSpec{
+		PredictorComponent: &isvc.Spec.Predictor.ComponentExtensionSpec,
+	}
+	if isvc.Spec.Transformer != nil {
+		extensions[TransformerComponent] = &isvc.Spec.Transformer.ComponentExtensionSpec
+	}
+	if isvc.Spec.Explainer != nil {
+		extensions[ExplainerComponent] = &isvc.Spec.Explainer.ComponentExtensionSpec
+	}
+	return extensions
+}
+
+// Validation of the idle annotations, the components scaled by the HPA can not be scaled to zero
+func validateIdleAnnotations(isvc *InferenceService) error {
+	if _, err := isvc.GetIdleTTL(); err != nil {
+		return err
+	}
+	switch action := isvc.GetIdleAction(); action {
+	case IdleActionSuspend, IdleActionDelete:
+		return nil
+	case IdleActionScaleToZero:
+		for component, extension := range isvc.idleComponents() {
+			if extension.ScaleMetric != nil && extension.ScaleMetric.AutoscalerClass() == autoscaling.HPA {
+				return fmt.Errorf(IdleScaleToZeroNotSupportedError, constants.IdleActionAnnotationKey, component,
+					*extension.ScaleMetric)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf(InvalidIdleActionAnnotationError, constants.IdleActionAnnotationKey, action)
+	}
+}
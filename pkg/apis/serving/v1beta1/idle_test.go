@@ -0,0 +1,106 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsIdleActionTaken(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	makeInferenceService := func(action IdleAction) *InferenceService {
+		return &InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				constants.IdleTTLAnnotationKey:    "1h",
+				constants.IdleActionAnnotationKey: string(action),
+			}},
+			Spec: InferenceServiceSpec{Transformer: &TransformerSpec{}},
+		}
+	}
+	scenarios := map[string]struct {
+		isvc     func() *InferenceService
+		expected bool
+	}{
+		"Serving": {
+			isvc:     func() *InferenceService { return makeInferenceService(IdleActionSuspend) },
+			expected: false,
+		},
+		"Suspended": {
+			isvc: func() *InferenceService {
+				isvc := makeInferenceService(IdleActionDelete)
+				isvc.Spec.Suspend = true
+				return isvc
+			},
+			expected: true,
+		},
+		"ScaledToZero": {
+			isvc: func() *InferenceService {
+				isvc := makeInferenceService(IdleActionScaleToZero)
+				isvc.ScaleToZero()
+				return isvc
+			},
+			expected: true,
+		},
+		"PredictorScaledToZero": {
+			isvc: func() *InferenceService {
+				isvc := makeInferenceService(IdleActionScaleToZero)
+				isvc.Spec.Predictor.MinReplicas = GetIntReference(0)
+				return isvc
+			},
+			expected: false,
+		},
+		"ScaledToZeroWithoutScaleToZeroAction": {
+			isvc: func() *InferenceService {
+				isvc := makeInferenceService(IdleActionSuspend)
+				isvc.ScaleToZero()
+				return isvc
+			},
+			expected: false,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(scenario.isvc().IsIdleActionTaken()).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestSetIdleTTLEnforced(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	status := &InferenceServiceStatus{}
+	status.InitializeConditions()
+
+	status.SetIdleTTLEnforced(true, false)
+	condition := status.GetCondition(IdleTTLNotEnforced)
+	g.Expect(condition).NotTo(gomega.BeNil())
+	g.Expect(condition.Status).To(gomega.Equal(v1.ConditionTrue))
+	g.Expect(condition.Reason).To(gomega.Equal(IdleReaperDisabled))
+	g.Expect(condition.Message).To(gomega.ContainSubstring(constants.IdleTTLAnnotationKey))
+	// The warning does not change the readiness of the inference service
+	g.Expect(status.GetCondition("Ready").Status).To(gomega.Equal(v1.ConditionUnknown))
+
+	status.SetIdleTTLEnforced(true, true)
+	g.Expect(status.GetCondition(IdleTTLNotEnforced)).To(gomega.BeNil())
+	status.SetIdleTTLEnforced(true, false)
+	status.SetIdleTTLEnforced(false, false)
+	g.Expect(status.GetCondition(IdleTTLNotEnforced)).To(gomega.BeNil())
+}
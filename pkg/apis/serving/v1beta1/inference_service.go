@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"github.com/kubeflow/kfserving/pkg/constants"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,8 +34,32 @@ type InferenceServiceSpec struct {
 	// transformer service calls to predictor service.
 	// +optional
 	Transformer *TransformerSpec `json:"transformer,omitempty"`
+	// Visibility keeps the inference service off the external gateway when ClusterLocal, it takes precedence over the
+	// serving.knative.dev/visibility and networking.knative.dev/visibility labels.
+	// +optional
+	Visibility Visibility `json:"visibility,omitempty"`
+	// Suspend scales the components of the inference service to zero and deletes its external routes while true, the
+	// routes are created again once false. The inference service, its status and the revisions of the components are
+	// kept.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Federation propagates the inference service to member clusters, e.g. to serve it active-active across regions
+	// +optional
+	Federation *FederationSpec `json:"federation,omitempty"`
 }
 
+// Visibility controls whether the inference service is exposed outside the cluster
+// +kubebuilder:validation:Enum=ClusterLocal;External
+type Visibility string
+
+// Visibility Enum
+const (
+	// Only exposed on the cluster local gateway
+	ClusterLocalVisibility Visibility = "ClusterLocal"
+	// Exposed on the external and the cluster local gateways
+	ExternalVisibility Visibility = "External"
+)
+
 // LoggerType controls the scope of log publishing
 // +kubebuilder:validation:Enum=all;request;response
 type LoggerType string
@@ -74,13 +99,21 @@ type Batcher struct {
 	// Specifies the timeout of a batch
 	// +optional
 	Timeout *int `json:"timeout,omitempty"`
+	// Specifies the max number of pending requests, the batcher rejects the requests beyond it with a 503 and a
+	// Retry-After header. Defaults to no limit.
+	// +optional
+	MaxQueueDepth *int `json:"maxQueueDepth,omitempty"`
 }
 
 // InferenceService is the Schema for the InferenceServices API
+// +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="RPS",type="string",JSONPath=".status.servingMetrics.requestsPerSecond",priority=1
+// +kubebuilder:printcolumn:name="P99 (ms)",type="string",JSONPath=".status.servingMetrics.p99LatencyMilliseconds",priority=1
+// +kubebuilder:printcolumn:name="Errors (%)",type="string",JSONPath=".status.servingMetrics.errorPercent",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:path=inferenceservices,shortName=isvc
 // +kubebuilder:storageversion
@@ -101,6 +134,22 @@ type InferenceServiceList struct {
 	Items []InferenceService `json:"items"`
 }
 
+// IsClusterLocal returns true when the inference service is only exposed inside the cluster, either with
+// spec.visibility or with a Knative visibility label
+func (isvc *InferenceService) IsClusterLocal() bool {
+	switch isvc.Spec.Visibility {
+	case ClusterLocalVisibility:
+		return true
+	case ExternalVisibility:
+		return false
+	}
+	switch isvc.Labels[constants.VisibilityLabel] {
+	case string(ClusterLocalVisibility), constants.VisibilityClusterLocal:
+		return true
+	}
+	return isvc.Labels[constants.NetworkingVisibilityLabel] == constants.VisibilityClusterLocal
+}
+
 func init() {
 	SchemeBuilder.Register(&InferenceService{}, &InferenceServiceList{})
 }
@@ -17,11 +17,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"reflect"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -60,11 +59,7 @@ func setResourceRequirementDefaults(requirements *v1.ResourceRequirements) {
 
 func (isvc *InferenceService) Default() {
 	mutatorLogger.Info("Defaulting InferenceService", "namespace", isvc.Namespace, "isvc", isvc.Spec.Predictor)
-	cli, err := client.New(config.GetConfigOrDie(), client.Options{})
-	if err != nil {
-		panic("Failed to create client in defaulter")
-	}
-	configMap, err := NewInferenceServicesConfig(cli)
+	configMap, err := getInferenceServicesConfig(isvc.Namespace)
 	if err != nil {
 		panic(err)
 	}
@@ -81,9 +76,68 @@ func (isvc *InferenceService) DefaultInferenceService(config *InferenceServicesC
 			if err := validateExactlyOneImplementation(component); err != nil {
 				mutatorLogger.Error(ExactlyOneErrorFor(component), "Missing component implementation")
 			} else {
+				setResourceProfile(component.GetImplementation(), isvc.Annotations, config)
+				if predictor, ok := component.(*PredictorSpec); ok {
+					setGPUResources(predictor)
+				}
 				component.GetImplementation().Default(config)
 				component.GetExtensions().Default(config)
 			}
 		}
 	}
 }
+
+// setResourceProfile sets the resources of the profile selected with the resource profile annotation, or of the default
+// profile of the framework, when the resources of the implementation are omitted. The profile is set before the
+// defaults of the implementation so the GPU runtime version is selected for the GPU profiles.
+func setResourceProfile(implementation ComponentImplementation, annotations map[string]string, config *InferenceServicesConfig) {
+	_, resources, profiles := resourceProfiles(implementation, config)
+	if resources == nil || len(resources.Requests) != 0 || len(resources.Limits) != 0 {
+		return
+	}
+	profileName, ok := annotations[constants.ResourceProfileAnnotationKey]
+	if !ok {
+		profileName = profiles.DefaultResourceProfile
+	}
+	if profileName == "" {
+		return
+	}
+	profile, ok := profiles.ResourceProfiles[profileName]
+	if !ok {
+		mutatorLogger.Info("Resource profile not found, using the default resources", "profile", profileName)
+		return
+	}
+	profile.DeepCopyInto(resources)
+}
+
+// resourceProfiles returns the framework, the resources and the resource profiles of the implementation, no resources
+// when the implementation runs a custom container or a ServingRuntime.
+func resourceProfiles(implementation ComponentImplementation, config *InferenceServicesConfig) (string, *v1.ResourceRequirements, ResourceProfilesConfig) {
+	switch spec := implementation.(type) {
+	case *SKLearnSpec:
+		return "sklearn", &spec.Resources, config.Predictors.SKlearn.ResourceProfilesConfig
+	case *XGBoostSpec:
+		return "xgboost", &spec.Resources, config.Predictors.XGBoost.ResourceProfilesConfig
+	case *LightGBMSpec:
+		return "lightgbm", &spec.Resources, config.Predictors.LightGBM.ResourceProfilesConfig
+	case *TFServingSpec:
+		return "tensorflow", &spec.Resources, config.Predictors.Tensorflow.ResourceProfilesConfig
+	case *TorchServeSpec:
+		return "pytorch", &spec.Resources, config.Predictors.TorchServe.ResourceProfilesConfig
+	case *TritonSpec:
+		return "triton", &spec.Resources, config.Predictors.Triton.ResourceProfilesConfig
+	case *ONNXRuntimeSpec:
+		return "onnx", &spec.Resources, config.Predictors.ONNX.ResourceProfilesConfig
+	case *PMMLSpec:
+		return "pmml", &spec.Resources, config.Predictors.PMML.ResourceProfilesConfig
+	case *PaddleSpec:
+		return "paddle", &spec.Resources, config.Predictors.Paddle.ResourceProfilesConfig
+	case *MLflowSpec:
+		return "mlflow", &spec.Resources, spec.runtimeConfig(config).ResourceProfilesConfig
+	case *AlibiExplainerSpec:
+		return "alibi", &spec.Resources, config.Explainers.AlibiExplainer.ResourceProfilesConfig
+	case *AIXExplainerSpec:
+		return "aix", &spec.Resources, config.Explainers.AIXExplainer.ResourceProfilesConfig
+	}
+	return "", nil, ResourceProfilesConfig{}
+}
@@ -17,11 +17,14 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
 	"github.com/golang/protobuf/proto"
 	"testing"
 
+	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -132,3 +135,83 @@ func TestCustomPredictorDefaults(t *testing.T) {
 	isvc.DefaultInferenceService(config)
 	g.Expect(isvc.Spec.Predictor.PodSpec.Containers[0].Resources).To(gomega.Equal(resources))
 }
+
+func TestResourceProfileDefaults(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	small := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	large := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("16Gi")},
+		Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("16Gi"),
+			constants.NvidiaGPUResourceType: resource.MustParse("1")},
+	}
+	config := &InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			Tensorflow: PredictorConfig{
+				ContainerImage:         "tfserving",
+				DefaultImageVersion:    "1.14.0",
+				DefaultGpuImageVersion: "1.14.0-gpu",
+				ResourceProfilesConfig: ResourceProfilesConfig{
+					ResourceProfiles:       map[string]v1.ResourceRequirements{"small": small, "large": large},
+					DefaultResourceProfile: "small",
+				},
+			},
+		},
+	}
+	userResources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+		Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+	}
+
+	scenarios := map[string]struct {
+		annotations     map[string]string
+		resources       v1.ResourceRequirements
+		expected        v1.ResourceRequirements
+		expectedVersion string
+	}{
+		"DefaultProfile": {
+			expected:        small,
+			expectedVersion: "1.14.0",
+		},
+		"AnnotatedGPUProfile": {
+			annotations:     map[string]string{constants.ResourceProfileAnnotationKey: "large"},
+			expected:        large,
+			expectedVersion: "1.14.0-gpu",
+		},
+		"UnknownProfile": {
+			annotations:     map[string]string{constants.ResourceProfileAnnotationKey: "huge"},
+			expected:        v1.ResourceRequirements{Requests: defaultResource, Limits: defaultResource},
+			expectedVersion: "1.14.0",
+		},
+		"ResourcesSetByUser": {
+			annotations:     map[string]string{constants.ResourceProfileAnnotationKey: "large"},
+			resources:       userResources,
+			expected:        userResources,
+			expectedVersion: "1.14.0",
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := InferenceService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "default",
+				Annotations: scenario.annotations,
+			},
+			Spec: InferenceServiceSpec{
+				Predictor: PredictorSpec{
+					Tensorflow: &TFServingSpec{
+						PredictorExtensionSpec: PredictorExtensionSpec{
+							StorageURI: proto.String("gs://testbucket/testmodel"),
+							Container:  v1.Container{Resources: *scenario.resources.DeepCopy()},
+						},
+					},
+				},
+			},
+		}
+		isvc.DefaultInferenceService(config)
+		g.Expect(isvc.Spec.Predictor.Tensorflow.Resources).To(gomega.Equal(scenario.expected), fmt.Sprintf("Testing %s", name))
+		g.Expect(*isvc.Spec.Predictor.Tensorflow.RuntimeVersion).To(gomega.Equal(scenario.expectedVersion), fmt.Sprintf("Testing %s", name))
+	}
+}
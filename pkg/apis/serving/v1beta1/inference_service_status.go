@@ -17,7 +17,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -32,15 +37,75 @@ type InferenceServiceStatus struct {
 	// - RoutesReady: aggregated routing condition;
 	// - Ready: aggregated condition;
 	duckv1.Status `json:",inline"`
-	// Addressable endpoint for the InferenceService
+	// Cluster local address of the InferenceService, reachable from the pods of the cluster. It has the form
+	// http://{name}.{namespace}.svc.{cluster domain}
 	// +optional
 	Address *duckv1.Addressable `json:"address,omitempty"`
 	// URL holds the url that will distribute traffic over the provided traffic targets.
 	// It generally has the form http[s]://{route-name}.{route-namespace}.{cluster-level-suffix}
 	// +optional
 	URL *apis.URL `json:"url,omitempty"`
-	// Statuses for the components of the InferenceService
+	// External host the InferenceService is served on outside the cluster, the host of the url unless the url is the
+	// path prefix of the InferenceService on the ingress domain. It is empty for a cluster local InferenceService.
+	// +optional
+	ExternalHost string `json:"externalHost,omitempty"`
+	// Statuses for the components of the InferenceService, with the endpoints of each component
 	Components map[ComponentType]ComponentStatusSpec `json:"components,omitempty"`
+	// Traffic served by the InferenceService, set when the controller aggregates the serving metrics
+	// +optional
+	ServingMetrics *ServingMetricsStatus `json:"servingMetrics,omitempty"`
+	// Versions of the model deployed by the predictor, most recent first, which the predictor can redeploy
+	// +optional
+	ModelVersions []ModelVersion `json:"modelVersions,omitempty"`
+	// Signature of the model read from the v2 model metadata endpoint of the predictor once it is ready
+	// +optional
+	ModelMetadata *ModelMetadataStatus `json:"modelMetadata,omitempty"`
+	// Resource versions of the inferenceservice-config ConfigMaps of the cluster and of the namespace the
+	// InferenceService was last reconciled with
+	// +optional
+	ConfigVersion string `json:"configVersion,omitempty"`
+	// Status of the copies of the inference service in the member clusters it is propagated to
+	// +optional
+	Federation *FederationStatus `json:"federation,omitempty"`
+	// Estimated hourly cost of the running replicas of the inference service, set when the cost config has prices
+	// +optional
+	Cost *CostStatus `json:"cost,omitempty"`
+	// Time the last request was observed at, set while the inference service has a TTL after the last request and
+	// is not suspended or scaled to zero by it
+	// +optional
+	LastRequestTime *metav1.Time `json:"lastRequestTime,omitempty"`
+	// Time the idle action was announced at with an IdleActionPending event, the action is taken on a later check
+	// once the TTL after the last request is reached. Cleared when a request is observed.
+	// +optional
+	IdleActionPendingTime *metav1.Time `json:"idleActionPendingTime,omitempty"`
+}
+
+// ServingMetricsStatus is the traffic served by the InferenceService, rolled up from the metrics of the component
+// receiving its requests: the transformer if any, the predictor otherwise. The values are formatted decimals since
+// the status does not hold floats.
+type ServingMetricsStatus struct {
+	// Requests per second
+	// +optional
+	RequestsPerSecond string `json:"requestsPerSecond,omitempty"`
+	// 99th percentile of the latency of the requests in milliseconds
+	// +optional
+	P99LatencyMilliseconds string `json:"p99LatencyMilliseconds,omitempty"`
+	// Percentage of the requests answered with a 5xx status code
+	// +optional
+	ErrorPercent string `json:"errorPercent,omitempty"`
+	// Time range of the rates, e.g. 5m
+	// +optional
+	Window string `json:"window,omitempty"`
+	// Last time the metrics were aggregated
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// ModelMetadataStatus is the metadata of the model served by a revision of the predictor
+type ModelMetadataStatus struct {
+	ModelMetadata `json:",inline"`
+	// Revision of the predictor the metadata was read from, the metadata is read again once another revision is ready
+	Revision string `json:"revision"`
 }
 
 // ComponentStatusSpec describes the state of the component
@@ -57,15 +122,88 @@ type ComponentStatusSpec struct {
 	// Traffic percent on the latest ready revision
 	// +optional
 	TrafficPercent *int64 `json:"trafficPercent,omitempty"`
-	// URL holds the url that will distribute traffic over the provided traffic targets.
-	// It generally has the form http[s]://{route-name}.{route-namespace}.{cluster-level-suffix}
+	// URL of the component, to call it directly rather than through the components in front of it, e.g. the
+	// predictor behind a transformer. It has the form http[s]://{component service}.{namespace}.{domain}
 	// +optional
 	URL *apis.URL `json:"url,omitempty"`
-	// Addressable endpoint for the InferenceService
+	// Cluster local address of the component, e.g. the host the transformer sends its requests to. It has the form
+	// http://{component service}.{namespace}.svc.{cluster domain}
 	// +optional
 	Address *duckv1.Addressable `json:"address,omitempty"`
+	// Human readable summary of the last change rolled out to the component, listing the runtime image,
+	// model and traffic plan before and after the change
+	// +optional
+	RolloutNotes string `json:"rolloutNotes,omitempty"`
+	// Whether the latest ready revision is scaled to zero for lack of traffic, a component scaled to zero stays ready
+	// and is activated by the next request
+	// +optional
+	ScaledToZero bool `json:"scaledToZero,omitempty"`
+	// Last time the latest ready revision became active, i.e. scaled up from zero or first deployed
+	// +optional
+	LastActivationTime *metav1.Time `json:"lastActivationTime,omitempty"`
+	// Ready revisions of the component, most recent first, which the component can roll back to
+	// +optional
+	RevisionHistory []string `json:"revisionHistory,omitempty"`
+	// Revision the traffic is pinned to by rollbackTo
+	// +optional
+	PinnedRevision string `json:"pinnedRevision,omitempty"`
+	// Cron schedule of the active scaling window, the replica bounds of the window apply to the component
+	// +optional
+	ScalingSchedule string `json:"scalingSchedule,omitempty"`
+	// Revision being rolled out, the traffic stays on the serving revision until it is ready
+	// +optional
+	RolloutRevision string `json:"rolloutRevision,omitempty"`
+	// Revision whose rollout was aborted since it was not ready within the progress deadline, the traffic stays on
+	// the serving revision until the next update of the component
+	// +optional
+	AbortedRevision string `json:"abortedRevision,omitempty"`
+	// Revision holding the traffic during the rollout of the rollout revision or after the abort of the aborted
+	// revision
+	// +optional
+	ServingRevision string `json:"servingRevision,omitempty"`
+	// Canary revision rolled back by the canary analysis, the previous ready revision serves all the traffic until
+	// the next update of the component
+	// +optional
+	RolledBackRevision string `json:"rolledBackRevision,omitempty"`
+	// Delay the hedged requests of the component are sent after, the percentile of the request latency of the
+	// component set by the hedging
+	// +optional
+	HedgingDelay *metav1.Duration `json:"hedgingDelay,omitempty"`
+	// Digests the images of the containers of the component were resolved to, keyed by container name, when the
+	// registry config resolves the image digests. The component is deployed by digest.
+	// +optional
+	ResolvedImages map[string]ResolvedImage `json:"resolvedImages,omitempty"`
+	// Reason the policy engines denied the rollout of the component for, the latest revision of the component keeps
+	// serving until its model and images are allowed
+	// +optional
+	PolicyViolation string `json:"policyViolation,omitempty"`
+}
+
+// ResolvedImage is the digest an image of a component was resolved to, the image is resolved again when it changes
+type ResolvedImage struct {
+	// Image of the container as set in the spec, e.g. kfserving/sklearnserver:v0.5.0
+	Image string `json:"image"`
+	// Digest of the manifest the image referred to when resolved, e.g. sha256:4b7d...
+	Digest string `json:"digest"`
 }
 
+// IsCanaryRolledBack returns true when the latest ready revision of the component was rolled back by the canary
+// analysis
+func (c ComponentStatusSpec) IsCanaryRolledBack() bool {
+	return c.RolledBackRevision != "" && c.RolledBackRevision == c.LatestReadyRevision
+}
+
+// StableRevision returns the latest ready revision which was not rolled back by the canary analysis
+func (c ComponentStatusSpec) StableRevision() string {
+	if c.IsCanaryRolledBack() {
+		return c.PreviousReadyRevision
+	}
+	return c.LatestReadyRevision
+}
+
+// MaxRevisionHistory is the number of ready revisions kept in the revision history of a component
+const MaxRevisionHistory = 10
+
 // ComponentType contains the different types of components of the service
 type ComponentType string
 
@@ -96,8 +234,104 @@ const (
 	TransformerReady apis.ConditionType = "TransformerReady"
 	// ExplainerReady is set when explainer has reported readiness.
 	ExplainerReady apis.ConditionType = "ExplainerReady"
+	// PredictorSidecarsReady is set when the sidecar containers injected into predictor pods are ready.
+	PredictorSidecarsReady apis.ConditionType = "PredictorSidecarsReady"
+	// TransformerSidecarsReady is set when the sidecar containers injected into transformer pods are ready.
+	TransformerSidecarsReady apis.ConditionType = "TransformerSidecarsReady"
+	// ExplainerSidecarsReady is set when the sidecar containers injected into explainer pods are ready.
+	ExplainerSidecarsReady apis.ConditionType = "ExplainerSidecarsReady"
+	// PredictorScheduled is set when the scheduler can place the predictor pods.
+	PredictorScheduled apis.ConditionType = "PredictorScheduled"
+	// TransformerScheduled is set when the scheduler can place the transformer pods.
+	TransformerScheduled apis.ConditionType = "TransformerScheduled"
+	// ExplainerScheduled is set when the scheduler can place the explainer pods.
+	ExplainerScheduled apis.ConditionType = "ExplainerScheduled"
 	// Ingress is created
 	IngressReady apis.ConditionType = "IngressReady"
+	// CertificateReady is set when the TLS certificate of the external host is issued.
+	CertificateReady apis.ConditionType = "CertificateReady"
+	// RollbackPerformed is set when the canary analysis rolled back the canary of a component.
+	RollbackPerformed apis.ConditionType = "RollbackPerformed"
+	// Suspended is set while the inference service is suspended.
+	Suspended apis.ConditionType = "Suspended"
+	// FederationReady is set when the inference service is ready in all the member clusters it is propagated to.
+	FederationReady apis.ConditionType = "FederationReady"
+	// Queued is set while the new inference service is queued by a ServingQuota of its namespace.
+	Queued apis.ConditionType = "Queued"
+	// PolicyViolation is set while the rollout of a component is denied by the supply-chain policy engines.
+	PolicyViolation apis.ConditionType = "PolicyViolation"
+	// IdleTTLNotEnforced is set while the TTL after the last request of the inference service is not enforced.
+	IdleTTLNotEnforced apis.ConditionType = "IdleTTLNotEnforced"
+)
+
+// Reasons reported on the sidecar readiness conditions
+const (
+	// SidecarNotReady is set when at least one sidecar container is not ready.
+	SidecarNotReady = "SidecarNotReady"
+	// NoPods is set when the component has no pod, e.g. it is scaled to zero.
+	NoPods = "NoPods"
+)
+
+// Reasons reported on the scheduling conditions
+const (
+	// Unschedulable is set when at least one pod of the component can not be scheduled.
+	Unschedulable = "Unschedulable"
+)
+
+// Knative revision condition and reason reported when the revision is scaled to zero
+const (
+	RevisionConditionActive apis.ConditionType = "Active"
+	RevisionNoTraffic                          = "NoTraffic"
+)
+
+// Reasons reported on the predictor readiness condition
+const (
+	// NoSupportingRuntime is set when no serving runtime can serve the model of the predictor.
+	NoSupportingRuntime = "NoSupportingRuntime"
+	// ModelVersionNotFound is set when the version redeployed by the predictor is not in its model versions.
+	ModelVersionNotFound = "ModelVersionNotFound"
+)
+
+// Reasons reported on the component readiness conditions
+const (
+	// PriorityClassNotFound is set when the priority class of the component does not exist.
+	PriorityClassNotFound = "PriorityClassNotFound"
+	// ProgressDeadlineExceeded is set when the rollout of the latest revision is aborted.
+	ProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+	// InferenceServiceSuspended is set while the components of the inference service are deleted by spec.suspend.
+	InferenceServiceSuspended = "InferenceServiceSuspended"
+	// QuotaExceeded is set while the components of the inference service are not deployed since it exceeds a quota.
+	QuotaExceeded = "QuotaExceeded"
+)
+
+// Reasons reported on the federation condition
+const (
+	// NoMemberCluster is set when no registered member cluster matches the federation placement.
+	NoMemberCluster = "NoMemberCluster"
+	// MemberClusterNotReady is set when the inference service is not ready in at least one member cluster.
+	MemberClusterNotReady = "MemberClusterNotReady"
+	// NamespaceNotFederated is set when the namespace is not allowed by the federation config.
+	NamespaceNotFederated = "NamespaceNotFederated"
+	// GlobalHostNotAllowed is set when the global host is not allowed by the ingress config of member clusters.
+	GlobalHostNotAllowed = "GlobalHostNotAllowed"
+)
+
+// Reasons reported on the policy violation condition
+const (
+	// PolicyVerificationFailed is set when the model or the images of a component fail the policy verification.
+	PolicyVerificationFailed = "PolicyVerificationFailed"
+)
+
+// Reasons reported on the idle TTL condition
+const (
+	// IdleReaperDisabled is set when the manager runs without the idle reaper, i.e. without a Prometheus server.
+	IdleReaperDisabled = "IdleReaperDisabled"
+)
+
+// Reasons reported on the rollback condition
+const (
+	// CanaryAnalysisFailed is set when the metrics of a canary exceed the thresholds of its canary analysis.
+	CanaryAnalysisFailed = "CanaryAnalysisFailed"
 )
 
 var conditionsMap = map[ComponentType]apis.ConditionType{
@@ -118,6 +352,18 @@ var configurationConditionsMap = map[ComponentType]apis.ConditionType{
 	TransformerComponent: TransformerConfigurationeReady,
 }
 
+var sidecarConditionsMap = map[ComponentType]apis.ConditionType{
+	PredictorComponent:   PredictorSidecarsReady,
+	ExplainerComponent:   ExplainerSidecarsReady,
+	TransformerComponent: TransformerSidecarsReady,
+}
+
+var schedulingConditionsMap = map[ComponentType]apis.ConditionType{
+	PredictorComponent:   PredictorScheduled,
+	ExplainerComponent:   ExplainerScheduled,
+	TransformerComponent: TransformerScheduled,
+}
+
 // InferenceService Ready condition is depending on predictor and route readiness condition
 var conditionSet = apis.NewLivingConditionSet(
 	PredictorReady,
@@ -155,8 +401,11 @@ func (ss *InferenceServiceStatus) PropagateStatus(component ComponentType, servi
 	}
 	statusSpec.LatestCreatedRevision = serviceStatus.LatestCreatedRevisionName
 	if serviceStatus.LatestReadyRevisionName != statusSpec.LatestReadyRevision {
-		statusSpec.PreviousReadyRevision = statusSpec.LatestReadyRevision
+		// The revision rolled back by the canary analysis is not a previous revision to split the traffic with
+		statusSpec.PreviousReadyRevision = statusSpec.StableRevision()
 		statusSpec.LatestReadyRevision = serviceStatus.LatestReadyRevisionName
+		statusSpec.RevisionHistory = addRevisionHistory(statusSpec.RevisionHistory, serviceStatus.LatestReadyRevisionName)
+		statusSpec.RolledBackRevision = ""
 	}
 	// propagate overall service condition
 	serviceCondition := serviceStatus.GetCondition(knservingv1.ServiceConditionReady)
@@ -179,14 +428,316 @@ func (ss *InferenceServiceStatus) PropagateStatus(component ComponentType, servi
 	configurationCondition := serviceStatus.GetCondition("RoutesReady")
 	configurationConditionType := configurationConditionsMap[component]
 	// propagate traffic status for each component
+	statusSpec.PinnedRevision = ""
 	for _, traffic := range serviceStatus.Traffic {
 		if traffic.LatestRevision != nil && *traffic.LatestRevision {
 			statusSpec.TrafficPercent = traffic.Percent
 		}
+		if traffic.Tag == constants.RollbackTrafficTag {
+			statusSpec.PinnedRevision = traffic.RevisionName
+		}
 	}
 	ss.SetCondition(configurationConditionType, configurationCondition)
 
 	ss.Components[component] = statusSpec
+	// The rollback condition is cleared once the rolled back canaries are replaced by new revisions
+	if ss.GetCondition(RollbackPerformed) != nil && !ss.hasCanaryRolledBack() {
+		ss.ClearCondition(RollbackPerformed)
+	}
+}
+
+// hasCanaryRolledBack returns true when the canary of a component is rolled back
+func (ss *InferenceServiceStatus) hasCanaryRolledBack() bool {
+	for _, statusSpec := range ss.Components {
+		if statusSpec.RolledBackRevision != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// addRevisionHistory records the ready revision at the head of the revision history, keeping the MaxRevisionHistory
+// most recent revisions
+func addRevisionHistory(history []string, revision string) []string {
+	if revision == "" {
+		return history
+	}
+	revisions := []string{revision}
+	for _, name := range history {
+		if name != revision && len(revisions) < MaxRevisionHistory {
+			revisions = append(revisions, name)
+		}
+	}
+	return revisions
+}
+
+// PropagateRolloutNotes records the summary of the change rolled out to the component, the notes of the previous
+// rollout are kept when no change was detected.
+func (ss *InferenceServiceStatus) PropagateRolloutNotes(component ComponentType, notes string) {
+	if notes == "" {
+		return
+	}
+	if len(ss.Components) == 0 {
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.RolloutNotes = notes
+	ss.Components[component] = statusSpec
+}
+
+// PropagateActivationStatus reports whether the component is scaled to zero from the Active condition of its latest
+// ready revision, so a component scaled to zero can be told apart from a failing one. The status is left untouched
+// when the revision does not report the condition yet.
+func (ss *InferenceServiceStatus) PropagateActivationStatus(component ComponentType, activeCondition *apis.Condition) {
+	if activeCondition == nil {
+		return
+	}
+	if len(ss.Components) == 0 {
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	switch activeCondition.Status {
+	case v1.ConditionTrue:
+		statusSpec.ScaledToZero = false
+		activationTime := activeCondition.LastTransitionTime.Inner
+		statusSpec.LastActivationTime = &activationTime
+	case v1.ConditionFalse:
+		statusSpec.ScaledToZero = activeCondition.Reason == RevisionNoTraffic
+	}
+	ss.Components[component] = statusSpec
+}
+
+// PropagateSidecarStatus aggregates the readiness of the sidecar containers (e.g. logger, batcher, agent) running
+// next to the model server in the component pods, so the model server readiness and the sidecar readiness can be
+// told apart. The condition is removed when the component pods do not run any sidecar, and it is Unknown while the
+// component has no pod, e.g. scaled to zero.
+func (ss *InferenceServiceStatus) PropagateSidecarStatus(component ComponentType, pods []v1.Pod) {
+	conditionType := sidecarConditionsMap[component]
+	if len(pods) == 0 {
+		if ss.GetCondition(conditionType) != nil {
+			conditionSet.Manage(ss).MarkUnknown(conditionType, NoPods, "The component has no pod")
+		}
+		return
+	}
+	hasSidecars := false
+	modelServerReady := false
+	notReady := []string{}
+	for _, pod := range pods {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name == constants.InferenceServiceContainerName {
+				modelServerReady = modelServerReady || containerStatus.Ready
+				continue
+			}
+			if containerStatus.Name == constants.KnativeQueueProxyContainerName {
+				continue
+			}
+			hasSidecars = true
+			if !containerStatus.Ready {
+				notReady = append(notReady, fmt.Sprintf("%s/%s (%s)", pod.Name, containerStatus.Name,
+					containerStateReason(containerStatus.State)))
+			}
+		}
+	}
+	if !hasSidecars {
+		ss.ClearCondition(conditionType)
+		return
+	}
+	if len(notReady) == 0 {
+		conditionSet.Manage(ss).MarkTrue(conditionType)
+		return
+	}
+	modelServerState := "not ready"
+	if modelServerReady {
+		modelServerState = "ready"
+	}
+	conditionSet.Manage(ss).MarkFalse(conditionType, SidecarNotReady,
+		"Model server is %s, sidecar containers not ready: %s", modelServerState, strings.Join(notReady, ", "))
+}
+
+// PropagateSchedulingStatus surfaces the pods of the component which the scheduler can not place, e.g. for lack of
+// resources when no lower priority pod can be preempted. The condition is left unchanged when the component has no pod.
+func (ss *InferenceServiceStatus) PropagateSchedulingStatus(component ComponentType, pods []v1.Pod) {
+	if len(pods) == 0 {
+		return
+	}
+	conditionType := schedulingConditionsMap[component]
+	unschedulable := []string{}
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse &&
+				condition.Reason == v1.PodReasonUnschedulable {
+				unschedulable = append(unschedulable, fmt.Sprintf("%s (%s)", pod.Name, condition.Message))
+			}
+		}
+	}
+	if len(unschedulable) == 0 {
+		conditionSet.Manage(ss).MarkTrue(conditionType)
+		return
+	}
+	conditionSet.Manage(ss).MarkFalse(conditionType, Unschedulable, "Pods can not be scheduled: %s",
+		strings.Join(unschedulable, ", "))
+}
+
+// MarkPriorityClassNotFound marks the component not ready since its pods can not be created without their priority class
+func (ss *InferenceServiceStatus) MarkPriorityClassNotFound(component ComponentType, priorityClassName string) {
+	conditionSet.Manage(ss).MarkFalse(conditionsMap[component], PriorityClassNotFound,
+		"Priority class %q does not exist", priorityClassName)
+}
+
+// MarkModelVersionNotFound marks the predictor not ready since the version it redeploys left its model versions
+func (ss *InferenceServiceStatus) MarkModelVersionNotFound(version int64) {
+	conditionSet.Manage(ss).MarkFalse(PredictorReady, ModelVersionNotFound,
+		"Model version %d is not in the model versions of the predictor", version)
+}
+
+// MarkSuspended sets the Suspended condition and marks the components and the ingress of the suspended inference
+// service not ready. The URL, the address and the cost are dropped from the status, the revisions of the components
+// scaled to zero are kept.
+func (ss *InferenceServiceStatus) MarkSuspended() {
+	conditionTypes := []apis.ConditionType{PredictorReady, IngressReady}
+	for component := range ss.Components {
+		if component != PredictorComponent {
+			conditionTypes = append(conditionTypes, conditionsMap[component])
+		}
+	}
+	for _, conditionType := range conditionTypes {
+		conditionSet.Manage(ss).MarkFalse(conditionType, InferenceServiceSuspended, "Inference service is suspended")
+	}
+	ss.URL = nil
+	ss.ExternalHost = ""
+	ss.Address = nil
+	ss.Cost = nil
+	conditionSet.Manage(ss).MarkTrue(Suspended)
+}
+
+// MarkQueued sets the Queued condition with the quota the new inference service exceeds and marks the predictor and
+// the ingress not ready, the components of the queued inference service are not deployed
+func (ss *InferenceServiceStatus) MarkQueued(message string) {
+	conditionSet.Manage(ss).MarkFalse(PredictorReady, QuotaExceeded, "%s", message)
+	conditionSet.Manage(ss).MarkFalse(IngressReady, QuotaExceeded, "%s", message)
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:    Queued,
+		Status:  v1.ConditionTrue,
+		Reason:  QuotaExceeded,
+		Message: message,
+	})
+}
+
+// IsQuotaAdmitted returns whether the inference service holds its usage of the quotas, the inference services which
+// are neither queued nor reconciled yet do
+func (ss *InferenceServiceStatus) IsQuotaAdmitted() bool {
+	return ss.GetCondition(Queued) == nil
+}
+
+// SetHedgingDelay sets the delay the hedged requests of the component are sent after, nil when the component is not
+// hedged
+func (ss *InferenceServiceStatus) SetHedgingDelay(component ComponentType, delay *metav1.Duration) {
+	if len(ss.Components) == 0 {
+		if delay == nil {
+			return
+		}
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.HedgingDelay = delay
+	ss.Components[component] = statusSpec
+}
+
+// SetResolvedImages sets the digests the images of the component were resolved to, nil when the digests are not
+// resolved
+func (ss *InferenceServiceStatus) SetResolvedImages(component ComponentType, images map[string]ResolvedImage) {
+	if len(ss.Components) == 0 {
+		if images == nil {
+			return
+		}
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.ResolvedImages = images
+	ss.Components[component] = statusSpec
+}
+
+// SetPolicyViolation sets the reason the rollout of the component is denied for, empty when it is allowed. The
+// PolicyViolation condition reports the denied components and is cleared once they are all allowed.
+func (ss *InferenceServiceStatus) SetPolicyViolation(component ComponentType, violation string) {
+	if len(ss.Components) == 0 {
+		if violation == "" {
+			return
+		}
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.PolicyViolation = violation
+	ss.Components[component] = statusSpec
+	violations := []string{}
+	for _, component := range []ComponentType{PredictorComponent, TransformerComponent, ExplainerComponent} {
+		if statusSpec, ok := ss.Components[component]; ok && statusSpec.PolicyViolation != "" {
+			violations = append(violations, fmt.Sprintf("%s: %s", component, statusSpec.PolicyViolation))
+		}
+	}
+	if len(violations) == 0 {
+		ss.ClearCondition(PolicyViolation)
+		return
+	}
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:    PolicyViolation,
+		Status:  v1.ConditionTrue,
+		Reason:  PolicyVerificationFailed,
+		Message: "Rollout denied by policy, " + strings.Join(violations, "; "),
+	})
+}
+
+// MarkCanaryRolledBack records the rollback of the canary revision of the component whose metrics exceed the
+// thresholds of the canary analysis
+func (ss *InferenceServiceStatus) MarkCanaryRolledBack(component ComponentType, violation string) {
+	if len(ss.Components) == 0 {
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.RolledBackRevision = statusSpec.LatestReadyRevision
+	ss.Components[component] = statusSpec
+	conditionSet.Manage(ss).SetCondition(apis.Condition{
+		Type:     RollbackPerformed,
+		Status:   v1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   CanaryAnalysisFailed,
+		Message: fmt.Sprintf("Canary revision %q of the %s rolled back to revision %q: %s",
+			statusSpec.RolledBackRevision, component, statusSpec.PreviousReadyRevision, violation),
+	})
+}
+
+// PropagateRolloutStatus records the rollout of the latest created revision of the component while the serving
+// revision holds the traffic. An aborted rollout marks the component not ready until the next update.
+func (ss *InferenceServiceStatus) PropagateRolloutStatus(component ComponentType, servingRevision string,
+	rolloutRevision string, aborted bool) {
+	if len(ss.Components) == 0 {
+		ss.Components = make(map[ComponentType]ComponentStatusSpec)
+	}
+	statusSpec := ss.Components[component]
+	statusSpec.ServingRevision = servingRevision
+	statusSpec.RolloutRevision = ""
+	statusSpec.AbortedRevision = ""
+	if aborted {
+		statusSpec.AbortedRevision = rolloutRevision
+		conditionSet.Manage(ss).MarkFalse(conditionsMap[component], ProgressDeadlineExceeded,
+			"Revision %q was not ready within the progress deadline, the traffic stays on revision %q",
+			rolloutRevision, servingRevision)
+	} else {
+		statusSpec.RolloutRevision = rolloutRevision
+	}
+	ss.Components[component] = statusSpec
+}
+
+func containerStateReason(state v1.ContainerState) string {
+	switch {
+	case state.Waiting != nil && state.Waiting.Reason != "":
+		return state.Waiting.Reason
+	case state.Terminated != nil && state.Terminated.Reason != "":
+		return state.Terminated.Reason
+	case state.Running != nil:
+		return "ReadinessProbeFailed"
+	}
+	return "Unknown"
 }
 
 func (ss *InferenceServiceStatus) SetCondition(conditionType apis.ConditionType, condition *apis.Condition) {
@@ -200,3 +751,11 @@ func (ss *InferenceServiceStatus) SetCondition(conditionType apis.ConditionType,
 		conditionSet.Manage(ss).MarkFalse(conditionType, condition.Reason, condition.Message)
 	}
 }
+
+// ClearCondition removes a condition no longer reported, e.g. the CertificateReady condition of an inference service
+// no longer served over HTTPS
+func (ss *InferenceServiceStatus) ClearCondition(conditionType apis.ConditionType) {
+	if ss.GetCondition(conditionType) != nil {
+		conditionSet.Manage(ss).ClearCondition(conditionType)
+	}
+}
@@ -17,7 +17,13 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -172,3 +178,463 @@ func TestInferenceServiceIsReady(t *testing.T) {
 		})
 	}
 }
+
+func TestPropagateSidecarStatus(t *testing.T) {
+	cases := []struct {
+		name            string
+		pods            []v1.Pod
+		expectCondition bool
+		isReady         bool
+	}{{
+		name: "pods without sidecars do not set the condition",
+		pods: []v1.Pod{{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "kfserving-container", Ready: true},
+					{Name: "queue-proxy", Ready: true},
+				},
+			},
+		}},
+		expectCondition: false,
+	}, {
+		name: "ready sidecars should be ready",
+		pods: []v1.Pod{{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "kfserving-container", Ready: true},
+					{Name: "queue-proxy", Ready: true},
+					{Name: "inferenceservice-logger", Ready: true},
+				},
+			},
+		}},
+		expectCondition: true,
+		isReady:         true,
+	}, {
+		name: "sidecar not ready while model server is ready should not be ready",
+		pods: []v1.Pod{{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{Name: "kfserving-container", Ready: true},
+					{Name: "queue-proxy", Ready: false},
+					{
+						Name:  "inferenceservice-logger",
+						Ready: false,
+						State: v1.ContainerState{
+							Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+					},
+				},
+			},
+		}},
+		expectCondition: true,
+		isReady:         false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := InferenceServiceStatus{}
+			status.InitializeConditions()
+			status.PropagateSidecarStatus(PredictorComponent, tc.pods)
+			condition := status.GetCondition(PredictorSidecarsReady)
+			if e, a := tc.expectCondition, condition != nil; e != a {
+				t.Errorf("%q expected condition set: %v got: %v conditions: %v", tc.name, e, a, status.Conditions)
+			}
+			if e, a := tc.isReady, status.IsConditionReady(PredictorSidecarsReady); e != a {
+				t.Errorf("%q expected: %v got: %v conditions: %v", tc.name, e, a, status.Conditions)
+			}
+			if condition != nil && !tc.isReady && condition.Reason != SidecarNotReady {
+				t.Errorf("%q expected reason %q got: %q", tc.name, SidecarNotReady, condition.Reason)
+			}
+		})
+	}
+}
+
+func TestPropagateSidecarStatusScaledToZero(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.InitializeConditions()
+	// The component without pod never reported its sidecars
+	status.PropagateSidecarStatus(PredictorComponent, nil)
+	if status.GetCondition(PredictorSidecarsReady) != nil {
+		t.Errorf("expected no sidecar condition got: %v", status.GetCondition(PredictorSidecarsReady))
+	}
+
+	status.PropagateSidecarStatus(PredictorComponent, []v1.Pod{{
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "kfserving-container", Ready: true},
+				{Name: "inferenceservice-logger", Ready: true},
+			},
+		},
+	}})
+	if !status.IsConditionReady(PredictorSidecarsReady) {
+		t.Errorf("expected the sidecars ready got: %v", status.GetCondition(PredictorSidecarsReady))
+	}
+	// The readiness of the sidecars is no longer known once the component is scaled to zero
+	status.PropagateSidecarStatus(PredictorComponent, nil)
+	condition := status.GetCondition(PredictorSidecarsReady)
+	if condition == nil || condition.Status != v1.ConditionUnknown || condition.Reason != NoPods {
+		t.Errorf("expected the sidecars unknown with reason %q got: %v", NoPods, condition)
+	}
+	// The condition is removed when the pods no longer run sidecars
+	status.PropagateSidecarStatus(PredictorComponent, []v1.Pod{{
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{Name: "kfserving-container", Ready: true}},
+		},
+	}})
+	if status.GetCondition(PredictorSidecarsReady) != nil {
+		t.Errorf("expected no sidecar condition got: %v", status.GetCondition(PredictorSidecarsReady))
+	}
+}
+
+func TestPropagateSchedulingStatus(t *testing.T) {
+	scheduled := v1.Pod{
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue}},
+		},
+	}
+	unschedulable := v1.Pod{
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{
+				Type:    v1.PodScheduled,
+				Status:  v1.ConditionFalse,
+				Reason:  v1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+			}},
+		},
+	}
+	unschedulable.Name = "sklearn-predictor-default-00001-deployment-1"
+	cases := []struct {
+		name            string
+		pods            []v1.Pod
+		expectCondition bool
+		isReady         bool
+	}{{
+		name:            "no pods do not set the condition",
+		pods:            []v1.Pod{},
+		expectCondition: false,
+	}, {
+		name:            "scheduled pods should be scheduled",
+		pods:            []v1.Pod{scheduled},
+		expectCondition: true,
+		isReady:         true,
+	}, {
+		name:            "unschedulable pod should not be scheduled",
+		pods:            []v1.Pod{scheduled, unschedulable},
+		expectCondition: true,
+		isReady:         false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := InferenceServiceStatus{}
+			status.InitializeConditions()
+			status.PropagateSchedulingStatus(PredictorComponent, tc.pods)
+			condition := status.GetCondition(PredictorScheduled)
+			if e, a := tc.expectCondition, condition != nil; e != a {
+				t.Errorf("%q expected condition set: %v got: %v conditions: %v", tc.name, e, a, status.Conditions)
+			}
+			if e, a := tc.isReady, status.IsConditionReady(PredictorScheduled); e != a {
+				t.Errorf("%q expected: %v got: %v conditions: %v", tc.name, e, a, status.Conditions)
+			}
+			if condition != nil && !tc.isReady {
+				if condition.Reason != Unschedulable {
+					t.Errorf("%q expected reason %q got: %q", tc.name, Unschedulable, condition.Reason)
+				}
+				if !strings.Contains(condition.Message, "3 Insufficient nvidia.com/gpu") {
+					t.Errorf("%q expected the scheduler message got: %q", tc.name, condition.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkPriorityClassNotFound(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.MarkPriorityClassNotFound(TransformerComponent, "production")
+	condition := status.GetCondition(TransformerReady)
+	if condition == nil || condition.Status != v1.ConditionFalse || condition.Reason != PriorityClassNotFound {
+		t.Errorf("expected the transformer not ready with reason %q got: %v", PriorityClassNotFound, condition)
+	}
+}
+
+func TestMarkSuspended(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.URL = &apis.URL{Scheme: "http", Host: "iris.default.example.com"}
+	status.Components = map[ComponentType]ComponentStatusSpec{
+		PredictorComponent:   {LatestReadyRevision: "iris-predictor-default-00001"},
+		TransformerComponent: {LatestReadyRevision: "iris-transformer-default-00001"},
+	}
+	status.MarkSuspended()
+	for _, conditionType := range []apis.ConditionType{PredictorReady, TransformerReady, IngressReady, apis.ConditionReady} {
+		condition := status.GetCondition(conditionType)
+		if condition == nil || condition.Status != v1.ConditionFalse || condition.Reason != InferenceServiceSuspended {
+			t.Errorf("expected %s false with reason %q got: %v", conditionType, InferenceServiceSuspended, condition)
+		}
+	}
+	if status.GetCondition(ExplainerReady) != nil {
+		t.Errorf("expected no explainer condition got: %v", status.GetCondition(ExplainerReady))
+	}
+	if !status.IsConditionReady(Suspended) {
+		t.Errorf("expected the suspended condition got: %v", status.GetCondition(Suspended))
+	}
+	if status.URL != nil {
+		t.Errorf("expected no url got: %v", status.URL)
+	}
+	if status.Components[PredictorComponent].LatestReadyRevision != "iris-predictor-default-00001" {
+		t.Errorf("expected the predictor revision kept got: %v", status.Components)
+	}
+	status.ClearCondition(Suspended)
+	if status.GetCondition(Suspended) != nil {
+		t.Errorf("expected the suspended condition cleared got: %v", status.GetCondition(Suspended))
+	}
+}
+
+func TestMarkQueued(t *testing.T) {
+	status := InferenceServiceStatus{}
+	if !status.IsQuotaAdmitted() {
+		t.Errorf("expected a new inference service to hold its quota")
+	}
+	message := "ServingQuota gpus exceeded: requested 2 GPUs, used 8 of 8."
+	status.MarkQueued(message)
+	for _, conditionType := range []apis.ConditionType{PredictorReady, IngressReady, apis.ConditionReady} {
+		condition := status.GetCondition(conditionType)
+		if condition == nil || condition.Status != v1.ConditionFalse || condition.Reason != QuotaExceeded ||
+			condition.Message != message {
+			t.Errorf("expected %s false with reason %q got: %v", conditionType, QuotaExceeded, condition)
+		}
+	}
+	if !status.IsConditionReady(Queued) || status.IsQuotaAdmitted() {
+		t.Errorf("expected the queued condition got: %v", status.GetCondition(Queued))
+	}
+	status.ClearCondition(Queued)
+	if !status.IsQuotaAdmitted() {
+		t.Errorf("expected the queued condition cleared got: %v", status.GetCondition(Queued))
+	}
+}
+
+func TestPropagateRolloutNotes(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.PropagateRolloutNotes(PredictorComponent, "image: sklearnserver:v0.4.0")
+	if e, a := "image: sklearnserver:v0.4.0", status.Components[PredictorComponent].RolloutNotes; e != a {
+		t.Errorf("expected rollout notes %q got: %q", e, a)
+	}
+	// notes of the previous rollout are kept when nothing changed
+	status.PropagateRolloutNotes(PredictorComponent, "")
+	if e, a := "image: sklearnserver:v0.4.0", status.Components[PredictorComponent].RolloutNotes; e != a {
+		t.Errorf("expected rollout notes %q got: %q", e, a)
+	}
+}
+
+func TestSetResolvedImages(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.SetResolvedImages(PredictorComponent, nil)
+	if status.Components != nil {
+		t.Errorf("expected no component status got: %v", status.Components)
+	}
+	images := map[string]ResolvedImage{
+		"kfserving-container": {Image: "kfserving/sklearnserver:v0.5.0", Digest: "sha256:4b7d"},
+	}
+	status.SetResolvedImages(PredictorComponent, images)
+	if e, a := "sha256:4b7d", status.Components[PredictorComponent].ResolvedImages["kfserving-container"].Digest; e != a {
+		t.Errorf("expected digest %q got: %q", e, a)
+	}
+	status.SetResolvedImages(PredictorComponent, nil)
+	if a := status.Components[PredictorComponent].ResolvedImages; a != nil {
+		t.Errorf("expected no resolved images got: %v", a)
+	}
+}
+
+func TestSetPolicyViolation(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.SetPolicyViolation(PredictorComponent, "")
+	if status.Components != nil || status.GetCondition(PolicyViolation) != nil {
+		t.Errorf("expected no policy violation got: %v", status.Components)
+	}
+	status.SetPolicyViolation(TransformerComponent, "cosign: no matching signatures")
+	status.SetPolicyViolation(PredictorComponent, "opa: model is not pinned")
+	condition := status.GetCondition(PolicyViolation)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != PolicyVerificationFailed {
+		t.Fatalf("expected the policy violation condition got: %v", condition)
+	}
+	if e, a := "Rollout denied by policy, predictor: opa: model is not pinned; transformer: cosign: no matching "+
+		"signatures", condition.Message; e != a {
+		t.Errorf("expected message %q got: %q", e, a)
+	}
+	status.SetPolicyViolation(PredictorComponent, "")
+	status.SetPolicyViolation(TransformerComponent, "")
+	if condition := status.GetCondition(PolicyViolation); condition != nil {
+		t.Errorf("expected the policy violation condition cleared got: %v", condition)
+	}
+	if a := status.Components[TransformerComponent].PolicyViolation; a != "" {
+		t.Errorf("expected no policy violation got: %q", a)
+	}
+}
+
+func TestPropagateRolloutStatus(t *testing.T) {
+	status := InferenceServiceStatus{}
+	status.InitializeConditions()
+	status.PropagateRolloutStatus(PredictorComponent, "sklearn-predictor-default-00001", "sklearn-predictor-default-00002", false)
+	if e, a := "sklearn-predictor-default-00002", status.Components[PredictorComponent].RolloutRevision; e != a {
+		t.Errorf("expected rollout revision %q got: %q", e, a)
+	}
+	if condition := status.GetCondition(PredictorReady); condition.Reason == ProgressDeadlineExceeded {
+		t.Errorf("expected the predictor readiness untouched got: %v", condition)
+	}
+
+	status.PropagateRolloutStatus(PredictorComponent, "sklearn-predictor-default-00001", "sklearn-predictor-default-00002", true)
+	statusSpec := status.Components[PredictorComponent]
+	if statusSpec.RolloutRevision != "" || statusSpec.AbortedRevision != "sklearn-predictor-default-00002" ||
+		statusSpec.ServingRevision != "sklearn-predictor-default-00001" {
+		t.Errorf("expected the rollout of sklearn-predictor-default-00002 aborted got: %+v", statusSpec)
+	}
+	condition := status.GetCondition(PredictorReady)
+	if condition == nil || condition.Status != v1.ConditionFalse || condition.Reason != ProgressDeadlineExceeded {
+		t.Errorf("expected the predictor not ready with reason %q got: %v", ProgressDeadlineExceeded, condition)
+	}
+
+	// the rollout status is cleared once the traffic is released
+	status.PropagateRolloutStatus(PredictorComponent, "", "", false)
+	if statusSpec := status.Components[PredictorComponent]; statusSpec.RolloutRevision != "" ||
+		statusSpec.AbortedRevision != "" || statusSpec.ServingRevision != "" {
+		t.Errorf("expected no rollout got: %+v", statusSpec)
+	}
+}
+
+func TestMarkCanaryRolledBack(t *testing.T) {
+	status := InferenceServiceStatus{
+		Components: map[ComponentType]ComponentStatusSpec{
+			PredictorComponent: {
+				LatestReadyRevision:   "sklearn-predictor-default-00002",
+				PreviousReadyRevision: "sklearn-predictor-default-00001",
+			},
+		},
+	}
+	status.InitializeConditions()
+	status.MarkCanaryRolledBack(PredictorComponent, "error rate 12.00% exceeds 5%")
+	statusSpec := status.Components[PredictorComponent]
+	if !statusSpec.IsCanaryRolledBack() || statusSpec.StableRevision() != "sklearn-predictor-default-00001" {
+		t.Errorf("expected the canary rolled back to sklearn-predictor-default-00001 got: %+v", statusSpec)
+	}
+	condition := status.GetCondition(RollbackPerformed)
+	if condition == nil || condition.Status != v1.ConditionTrue || condition.Reason != CanaryAnalysisFailed {
+		t.Errorf("expected the rollback condition with reason %q got: %v", CanaryAnalysisFailed, condition)
+	}
+
+	// the rolled back revision is skipped once a new revision is ready
+	status.PropagateStatus(PredictorComponent, &knservingv1.ServiceStatus{
+		ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+			LatestReadyRevisionName:   "sklearn-predictor-default-00003",
+			LatestCreatedRevisionName: "sklearn-predictor-default-00003",
+		},
+	})
+	statusSpec = status.Components[PredictorComponent]
+	if statusSpec.PreviousReadyRevision != "sklearn-predictor-default-00001" || statusSpec.RolledBackRevision != "" {
+		t.Errorf("expected the previous revision sklearn-predictor-default-00001 got: %+v", statusSpec)
+	}
+	if condition := status.GetCondition(RollbackPerformed); condition != nil {
+		t.Errorf("expected the rollback condition cleared got: %v", condition)
+	}
+}
+
+func TestPropagateActivationStatus(t *testing.T) {
+	activated := metav1.Now()
+	cases := []struct {
+		name               string
+		condition          *apis.Condition
+		scaledToZero       bool
+		lastActivationTime *metav1.Time
+	}{{
+		name:      "missing condition leaves the status untouched",
+		condition: nil,
+	}, {
+		name: "active revision is not scaled to zero",
+		condition: &apis.Condition{
+			Type:               RevisionConditionActive,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: apis.VolatileTime{Inner: activated},
+		},
+		scaledToZero:       false,
+		lastActivationTime: &activated,
+	}, {
+		name: "inactive revision without traffic is scaled to zero",
+		condition: &apis.Condition{
+			Type:   RevisionConditionActive,
+			Status: v1.ConditionFalse,
+			Reason: RevisionNoTraffic,
+		},
+		scaledToZero: true,
+	}, {
+		name: "inactive revision failing to scale is not scaled to zero",
+		condition: &apis.Condition{
+			Type:   RevisionConditionActive,
+			Status: v1.ConditionFalse,
+			Reason: "FailedCreate",
+		},
+		scaledToZero: false,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := InferenceServiceStatus{}
+			status.PropagateActivationStatus(PredictorComponent, tc.condition)
+			componentStatus := status.Components[PredictorComponent]
+			if e, a := tc.scaledToZero, componentStatus.ScaledToZero; e != a {
+				t.Errorf("%q expected scaled to zero: %v got: %v", tc.name, e, a)
+			}
+			if e, a := tc.lastActivationTime, componentStatus.LastActivationTime; !e.Equal(a) {
+				t.Errorf("%q expected last activation time: %v got: %v", tc.name, e, a)
+			}
+		})
+	}
+}
+
+func TestPropagateRevisionHistory(t *testing.T) {
+	status := InferenceServiceStatus{}
+	for i := 0; i < MaxRevisionHistory+2; i++ {
+		status.PropagateStatus(PredictorComponent, &knservingv1.ServiceStatus{
+			ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+				LatestReadyRevisionName: fmt.Sprintf("sklearn-predictor-default-%05d", i),
+			},
+		})
+	}
+	history := status.Components[PredictorComponent].RevisionHistory
+	if e, a := MaxRevisionHistory, len(history); e != a {
+		t.Errorf("expected %d revisions in the history got: %d", e, a)
+	}
+	if e, a := "sklearn-predictor-default-00011", history[0]; e != a {
+		t.Errorf("expected latest revision %q at the head of the history got: %q", e, a)
+	}
+	if e, a := "sklearn-predictor-default-00002", history[MaxRevisionHistory-1]; e != a {
+		t.Errorf("expected oldest revision %q at the tail of the history got: %q", e, a)
+	}
+
+	// the traffic pinned by the rollback is reported until the rollback is unset
+	status.PropagateStatus(PredictorComponent, &knservingv1.ServiceStatus{
+		ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+			LatestReadyRevisionName: "sklearn-predictor-default-00011",
+		},
+		RouteStatusFields: knservingv1.RouteStatusFields{
+			Traffic: []knservingv1.TrafficTarget{{
+				Tag:          constants.RollbackTrafficTag,
+				RevisionName: "sklearn-predictor-default-00005",
+			}},
+		},
+	})
+	if e, a := "sklearn-predictor-default-00005", status.Components[PredictorComponent].PinnedRevision; e != a {
+		t.Errorf("expected pinned revision %q got: %q", e, a)
+	}
+	if e, a := MaxRevisionHistory, len(status.Components[PredictorComponent].RevisionHistory); e != a {
+		t.Errorf("expected %d revisions in the history got: %d", e, a)
+	}
+	status.PropagateStatus(PredictorComponent, &knservingv1.ServiceStatus{
+		ConfigurationStatusFields: knservingv1.ConfigurationStatusFields{
+			LatestReadyRevisionName: "sklearn-predictor-default-00011",
+		},
+	})
+	if e, a := "", status.Components[PredictorComponent].PinnedRevision; e != a {
+		t.Errorf("expected pinned revision %q got: %q", e, a)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsClusterLocal(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		visibility Visibility
+		labels     map[string]string
+		expected   bool
+	}{
+		"Default": {
+			expected: false,
+		},
+		"ClusterLocalVisibility": {
+			visibility: ClusterLocalVisibility,
+			expected:   true,
+		},
+		"ServingLabel": {
+			labels:   map[string]string{constants.VisibilityLabel: constants.VisibilityClusterLocal},
+			expected: true,
+		},
+		"ServingLabelClusterLocal": {
+			labels:   map[string]string{constants.VisibilityLabel: "ClusterLocal"},
+			expected: true,
+		},
+		"NetworkingLabel": {
+			labels:   map[string]string{constants.NetworkingVisibilityLabel: constants.VisibilityClusterLocal},
+			expected: true,
+		},
+		"ExternalVisibilityOverridesLabel": {
+			visibility: ExternalVisibility,
+			labels:     map[string]string{constants.NetworkingVisibilityLabel: constants.VisibilityClusterLocal},
+			expected:   false,
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := &InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", Labels: scenario.labels},
+			Spec:       InferenceServiceSpec{Visibility: scenario.visibility},
+		}
+		g.Expect(isvc.IsClusterLocal()).To(gomega.Equal(scenario.expected), name)
+	}
+}
@@ -17,14 +17,25 @@ limitations under the License.
 package v1beta1
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"reflect"
 
+	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"regexp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // regular expressions for validation of isvc name
@@ -37,8 +48,42 @@ var (
 	validatorLogger = logf.Log.WithName("inferenceservice-v1beta1-validation-webhook")
 	// regular expressions for validation of isvc name
 	IsvcRegexp = regexp.MustCompile("^" + IsvcNameFmt + "$")
+	// configReader reads the config maps the inference services are defaulted and validated against, the API reader
+	// of the manager shared by the admissions, set when the webhook is registered
+	configReader client.Reader
+	// getInferenceServicesConfig reads the configuration of the namespace listing the allowed runtime versions
+	getInferenceServicesConfig = func(namespace string) (*InferenceServicesConfig, error) {
+		if configReader == nil {
+			return nil, errWebhookNotRegistered
+		}
+		return NewInferenceServicesConfig(configReader, namespace)
+	}
+	// getIngressConfig reads the ingress configuration setting the gateway maximums and the host override domains
+	getIngressConfig = func(namespace string) (*IngressConfig, error) {
+		if configReader == nil {
+			return nil, errWebhookNotRegistered
+		}
+		return NewIngressConfig(configReader, namespace)
+	}
+	// getLoggerConfig reads the logger configuration of the namespace restricting the log sinks
+	getLoggerConfig = func(namespace string) (*LoggerConfig, error) {
+		if configReader == nil {
+			return nil, errWebhookNotRegistered
+		}
+		return NewLoggerConfig(configReader, namespace)
+	}
+	errWebhookNotRegistered = errors.New("the inference service webhook is not registered with a manager")
 )
 
+// SetupWebhookWithManager registers the defaulting and validation webhooks of the inference services, the config maps
+// are read through the API reader of the manager
+func (isvc *InferenceService) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	configReader = mgr.GetAPIReader()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(isvc).
+		Complete()
+}
+
 // +kubebuilder:webhook:verbs=create;update,path=/validate-inferenceservices,mutating=false,failurePolicy=fail,groups=serving.kubeflow.org,resources=inferenceservices,versions=v1beta1,name=inferenceservice.kfserving-webhook-server.validator
 var _ webhook.Validator = &InferenceService{}
 
@@ -46,6 +91,17 @@ var _ webhook.Validator = &InferenceService{}
 func (isvc *InferenceService) ValidateCreate() error {
 	validatorLogger.Info("validate create", "name", isvc.Name)
 
+	if err := validateRollbackTo(isvc, &InferenceService{}); err != nil {
+		return err
+	}
+	if err := validateDeployVersion(isvc, &InferenceService{}); err != nil {
+		return err
+	}
+	return isvc.validate()
+}
+
+// validate checks the fields validated on create and on update
+func (isvc *InferenceService) validate() error {
 	if err := validateInferenceServiceName(isvc); err != nil {
 		return err
 	}
@@ -67,14 +123,121 @@ func (isvc *InferenceService) ValidateCreate() error {
 			}
 		}
 	}
-	return nil
+	if err := validateExplainerSampling(isvc); err != nil {
+		return err
+	}
+	if err := validateIngressRouting(isvc); err != nil {
+		return err
+	}
+	if err := validateModelSizeAnnotation(isvc.Annotations); err != nil {
+		return err
+	}
+	if err := validateIngressHostAnnotation(isvc.Annotations); err != nil {
+		return err
+	}
+	if err := validateAuthAnnotations(isvc.Annotations); err != nil {
+		return err
+	}
+	if err := validateIdleAnnotations(isvc); err != nil {
+		return err
+	}
+	if err := validateModelConversion(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateLoadPolicy(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateParallelism(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateGPU(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateModelReadiness(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateWarmup(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateRollout(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateAsync(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateRequestValidation(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validatePredictorResponseCache(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateInferenceServiceHedging(isvc); err != nil {
+		return err
+	}
+	if err := validateProtocol(&isvc.Spec.Predictor, isvc.Spec.Transformer); err != nil {
+		return err
+	}
+	if err := validateSidecars(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validateEnv(isvc); err != nil {
+		return err
+	}
+	if err := validateVolumes(&isvc.Spec.Predictor); err != nil {
+		return err
+	}
+	if err := validatePredictorCall(isvc.Spec.Transformer); err != nil {
+		return err
+	}
+	if err := validateFederation(isvc.Spec.Federation); err != nil {
+		return err
+	}
+	if isvc.Spec.Predictor.PyTorch != nil {
+		if err := validateTorchServeAnnotations(isvc.Annotations); err != nil {
+			return err
+		}
+	}
+	// The runtime versions, the gateway maximums, the ingress host and the hedging backend are not validated when the config map can not be
+	// read, the admission must not depend on the availability of the config map for the other validations.
+	if ingressConfig, err := getIngressConfig(isvc.Namespace); err != nil {
+		validatorLogger.Error(err, "Failed to read the ingress config, skipping the gateway maximums and ingress host validation", "name", isvc.Name)
+	} else if err := validateGatewayMaximums(isvc, ingressConfig); err != nil {
+		return err
+	} else if err := validateIngressHostDomain(isvc.Annotations, ingressConfig); err != nil {
+		return err
+	} else if err := validateHedgingBackend(isvc, ingressConfig); err != nil {
+		return err
+	}
+	if loggerConfig, err := getLoggerConfig(isvc.Namespace); err != nil {
+		validatorLogger.Error(err, "Failed to read the logger config, skipping the log sink validation", "name", isvc.Name)
+	} else if err := validateLoggerSinks(isvc, loggerConfig); err != nil {
+		return err
+	}
+	servicesConfig, err := getInferenceServicesConfig(isvc.Namespace)
+	if err != nil {
+		validatorLogger.Error(err, "Failed to read the inference services config, skipping the runtime version and resource profile validation", "name", isvc.Name)
+		return nil
+	}
+	return utils.FirstNonNilError([]error{
+		validateRuntimeVersions(isvc, servicesConfig),
+		validateResourceProfile(isvc, servicesConfig),
+		validateKedaEnabled(isvc, servicesConfig),
+	})
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (isvc *InferenceService) ValidateUpdate(old runtime.Object) error {
 	validatorLogger.Info("validate update", "name", isvc.Name)
 
-	return isvc.ValidateCreate()
+	if oldIsvc, ok := old.(*InferenceService); ok {
+		if err := validateRollbackTo(isvc, oldIsvc); err != nil {
+			return err
+		}
+		if err := validateDeployVersion(isvc, oldIsvc); err != nil {
+			return err
+		}
+	}
+	return isvc.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -83,6 +246,40 @@ func (isvc *InferenceService) ValidateDelete() error {
 	return nil
 }
 
+// validateRollbackTo checks that the components roll back to a revision of their revision history. The rollback is
+// only checked when it changes, the other updates are not rejected once the revision left the history.
+func validateRollbackTo(isvc *InferenceService, old *InferenceService) error {
+	for _, c := range []struct {
+		componentType ComponentType
+		component     Component
+		oldComponent  Component
+	}{
+		{PredictorComponent, &isvc.Spec.Predictor, &old.Spec.Predictor},
+		{TransformerComponent, isvc.Spec.Transformer, old.Spec.Transformer},
+		{ExplainerComponent, isvc.Spec.Explainer, old.Spec.Explainer},
+	} {
+		rollbackTo := getRollbackTo(c.component)
+		if rollbackTo == nil {
+			continue
+		}
+		if oldRollbackTo := getRollbackTo(c.oldComponent); oldRollbackTo != nil && *oldRollbackTo == *rollbackTo {
+			continue
+		}
+		history := old.Status.Components[c.componentType].RevisionHistory
+		if !utils.Includes(history, *rollbackTo) {
+			return fmt.Errorf(InvalidRollbackRevisionError, *rollbackTo, c.componentType, strings.Join(history, ", "))
+		}
+	}
+	return nil
+}
+
+func getRollbackTo(component Component) *string {
+	if reflect.ValueOf(component).IsNil() {
+		return nil
+	}
+	return component.GetExtensions().RollbackTo
+}
+
 // GetIntReference returns the pointer for the integer input
 func GetIntReference(number int) *int {
 	num := number
@@ -96,3 +293,412 @@ func validateInferenceServiceName(isvc *InferenceService) error {
 	}
 	return nil
 }
+
+// Validation of the explainer sampling, the sampled explanations are published by the predictor logger
+func validateExplainerSampling(isvc *InferenceService) error {
+	if isvc.Spec.Explainer == nil || isvc.Spec.Explainer.SamplingPercent == nil {
+		return nil
+	}
+	if *isvc.Spec.Explainer.SamplingPercent < 0 || *isvc.Spec.Explainer.SamplingPercent > 100 {
+		return fmt.Errorf(SamplingPercentOutOfRangeError)
+	}
+	if *isvc.Spec.Explainer.SamplingPercent > 0 && isvc.Spec.Predictor.Logger == nil {
+		return fmt.Errorf(SamplingRequiresLoggerError)
+	}
+	return nil
+}
+
+// validateIngressRouting checks that the shadow and the canary match components receive the traffic from the ingress,
+// the traffic sent to the predictor by the transformer is not routed by the ingress
+func validateIngressRouting(isvc *InferenceService) error {
+	if isvc.Spec.Predictor.Shadow && isvc.Spec.Transformer != nil {
+		return fmt.Errorf(ShadowBehindTransformerError)
+	}
+	if len(isvc.Spec.Predictor.CanaryMatch) != 0 && isvc.Spec.Transformer != nil {
+		return fmt.Errorf(CanaryMatchBehindTransformerError)
+	}
+	return nil
+}
+
+// Validation of the declared model size used to size the ephemeral storage of the predictor
+func validateModelSizeAnnotation(annotations map[string]string) error {
+	modelSize, ok := annotations[constants.ModelSizeAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := resource.ParseQuantity(modelSize); err != nil {
+		return fmt.Errorf(InvalidModelSizeAnnotationError, constants.ModelSizeAnnotationKey, modelSize)
+	}
+	return nil
+}
+
+// Validation of the ingress host annotation
+func validateIngressHostAnnotation(annotations map[string]string) error {
+	host, ok := annotations[constants.IngressHostAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return fmt.Errorf(InvalidIngressHostAnnotationError, constants.IngressHostAnnotationKey, host, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Validation of the ingress host annotation against the host override domains of the ingress config
+func validateIngressHostDomain(annotations map[string]string, config *IngressConfig) error {
+	host, ok := annotations[constants.IngressHostAnnotationKey]
+	if !ok || IsHostOverrideAllowed(config, host) {
+		return nil
+	}
+	return fmt.Errorf(IngressHostNotAllowedError, constants.IngressHostAnnotationKey, host,
+		constants.InferenceServiceConfigMapName)
+}
+
+// Validation of the auth annotations
+func validateAuthAnnotations(annotations map[string]string) error {
+	for _, key := range []string{constants.AuthRequiredAnnotationKey, constants.APIKeyAnnotationKey} {
+		if value, ok := annotations[key]; ok {
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf(InvalidBooleanAnnotationError, key, value)
+			}
+		}
+	}
+	if issuer, ok := annotations[constants.AuthIssuerAnnotationKey]; ok {
+		if strings.TrimSpace(issuer) == "" {
+			return fmt.Errorf(InvalidAuthIssuerAnnotationError, constants.AuthIssuerAnnotationKey, issuer)
+		}
+	}
+	return nil
+}
+
+// Validation of the load policy, only the kfserving python model servers load the model on the first request
+func validateLoadPolicy(predictor *PredictorSpec) error {
+	var extension *PredictorExtensionSpec
+	framework := ""
+	switch spec := predictor.GetImplementation().(type) {
+	case *SKLearnSpec, *XGBoostSpec, *LightGBMSpec, *PMMLSpec, *PaddleSpec:
+		return nil
+	case *TFServingSpec:
+		framework, extension = "tensorflow", &spec.PredictorExtensionSpec
+	case *TorchServeSpec:
+		framework, extension = "pytorch", &spec.PredictorExtensionSpec
+	case *TritonSpec:
+		framework, extension = "triton", &spec.PredictorExtensionSpec
+	case *ONNXRuntimeSpec:
+		framework, extension = "onnx", &spec.PredictorExtensionSpec
+	case *MLflowSpec:
+		framework, extension = "mlflow", &spec.PredictorExtensionSpec
+	case *ModelPredictorSpec:
+		framework, extension = "model", &spec.PredictorExtensionSpec
+	default:
+		return nil
+	}
+	if extension.LoadPolicy != nil && *extension.LoadPolicy == LoadPolicyLazy {
+		return fmt.Errorf(LazyLoadPolicyNotSupportedError, framework, "sklearn, xgboost, lightgbm, pmml, paddle")
+	}
+	return nil
+}
+
+// Validation of the parallelism, the model servers only support the fields they have a flag or an environment
+// variable for
+func validateParallelism(predictor *PredictorSpec) error {
+	var extension *PredictorExtensionSpec
+	framework, workers, threads := "", false, false
+	switch spec := predictor.GetImplementation().(type) {
+	case *SKLearnSpec:
+		framework, extension, workers = "sklearn", &spec.PredictorExtensionSpec, true
+	case *XGBoostSpec:
+		framework, extension, workers, threads = "xgboost", &spec.PredictorExtensionSpec, true, true
+	case *LightGBMSpec:
+		framework, extension, workers, threads = "lightgbm", &spec.PredictorExtensionSpec, true, true
+	case *PMMLSpec:
+		framework, extension = "pmml", &spec.PredictorExtensionSpec
+	case *PaddleSpec:
+		framework, extension, workers = "paddle", &spec.PredictorExtensionSpec, true
+	case *TFServingSpec:
+		framework, extension, threads = "tensorflow", &spec.PredictorExtensionSpec, true
+	case *TorchServeSpec:
+		framework, extension, workers = "pytorch", &spec.PredictorExtensionSpec, true
+	case *TritonSpec:
+		framework, extension, threads = "triton", &spec.PredictorExtensionSpec, true
+	case *ONNXRuntimeSpec:
+		framework, extension, threads = "onnx", &spec.PredictorExtensionSpec, true
+	case *MLflowSpec:
+		// the onnx flavor is served by the ONNX Runtime server, the other flavors by python model servers
+		framework, extension = "mlflow", &spec.PredictorExtensionSpec
+		workers, threads = spec.getFlavor() != MLflowONNXFlavor, spec.getFlavor() == MLflowONNXFlavor
+	case *ModelPredictorSpec:
+		framework, extension = "model", &spec.PredictorExtensionSpec
+	default:
+		return nil
+	}
+	if extension.Parallelism == nil {
+		return nil
+	}
+	if extension.Parallelism.Workers != nil {
+		if *extension.Parallelism.Workers < 1 {
+			return fmt.Errorf(ServerParallelismLowerBoundError, "workers")
+		}
+		if framework == "triton" {
+			return fmt.Errorf(TritonParallelWorkersError)
+		}
+		if !workers {
+			return fmt.Errorf(ParallelismNotSupportedError, "workers", framework)
+		}
+	}
+	if extension.Parallelism.Threads != nil {
+		if *extension.Parallelism.Threads < 1 {
+			return fmt.Errorf(ServerParallelismLowerBoundError, "threads")
+		}
+		if !threads {
+			return fmt.Errorf(ParallelismNotSupportedError, "threads", framework)
+		}
+	}
+	return nil
+}
+
+// Validation of the configuration of the calls of the transformer to the predictor
+func validatePredictorCall(transformer *TransformerSpec) error {
+	if transformer == nil || transformer.PredictorCall == nil {
+		return nil
+	}
+	predictorCall := transformer.PredictorCall
+	if predictorCall.Host != "" {
+		host := predictorCall.Host
+		if h, port, err := net.SplitHostPort(host); err == nil {
+			if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+				return fmt.Errorf(InvalidPredictorCallHostError, predictorCall.Host, "invalid port")
+			}
+			host = h
+		}
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			return fmt.Errorf(InvalidPredictorCallHostError, predictorCall.Host, strings.Join(errs, ", "))
+		}
+	}
+	switch predictorCall.Protocol {
+	case "", PredictorCallHTTP1, PredictorCallHTTP2:
+	default:
+		return fmt.Errorf(InvalidPredictorCallProtocolError, predictorCall.Protocol)
+	}
+	if predictorCall.MaxConnections < 0 {
+		return fmt.Errorf(NegativeValueError, "PredictorCall maxConnections")
+	}
+	if predictorCall.IdleTimeout != nil && predictorCall.IdleTimeout.Duration <= 0 {
+		return fmt.Errorf(NonPositiveDurationError, "PredictorCall idleTimeout")
+	}
+	return nil
+}
+
+// Validation of the runtime versions of the framework implementations against the versions allowed in the config map
+func validateRuntimeVersions(isvc *InferenceService, config *InferenceServicesConfig) error {
+	components := map[string]Component{"predictor": &isvc.Spec.Predictor}
+	if isvc.Spec.Explainer != nil {
+		components["explainer"] = isvc.Spec.Explainer
+	}
+	for _, componentName := range []string{"predictor", "explainer"} {
+		component, ok := components[componentName]
+		if !ok {
+			continue
+		}
+		framework, runtimeVersion, allowed := allowedRuntimeVersions(component.GetImplementation(), config)
+		if runtimeVersion == nil || len(allowed) == 0 {
+			continue
+		}
+		isAllowed := false
+		for _, version := range allowed {
+			if version == *runtimeVersion {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return fmt.Errorf(InvalidRuntimeVersionError, *runtimeVersion, framework, componentName,
+				strings.Join(allowed, ", "), constants.InferenceServiceConfigMapName)
+		}
+	}
+	return nil
+}
+
+// Validation of the log sinks of the components against the allowed sink domains of the logger config, the pod
+// mutator rejects the pods of the sinks not allowed as well
+func validateLoggerSinks(isvc *InferenceService, config *LoggerConfig) error {
+	if len(config.AllowedSinkDomains) == 0 {
+		return nil
+	}
+	for _, c := range []struct {
+		name      string
+		component Component
+	}{
+		{"predictor", &isvc.Spec.Predictor},
+		{"transformer", isvc.Spec.Transformer},
+		{"explainer", isvc.Spec.Explainer},
+	} {
+		if reflect.ValueOf(c.component).IsNil() || c.component.GetExtensions().Logger == nil {
+			continue
+		}
+		sink := config.DefaultUrl
+		if loggerURL := c.component.GetExtensions().Logger.URL; loggerURL != nil {
+			sink = *loggerURL
+		}
+		sinkURL, err := url.Parse(sink)
+		if err != nil {
+			return fmt.Errorf(InvalidLoggerURLError, sink, c.name, err)
+		}
+		if !utils.InDomains(sinkURL.Hostname(), config.AllowedSinkDomains) {
+			return fmt.Errorf(LoggerSinkNotAllowedError, sink, c.name, strings.Join(config.AllowedSinkDomains, ", "),
+				constants.InferenceServiceConfigMapName)
+		}
+	}
+	return nil
+}
+
+// Validation of the components scaled by KEDA, including the predictor scaled on the depth of its async queue, against
+// the autoscaling backends enabled in the config map
+func validateKedaEnabled(isvc *InferenceService, config *InferenceServicesConfig) error {
+	if config.Autoscaling.KedaEnabled {
+		return nil
+	}
+	predictorAsync := isvc.Spec.Predictor.Async
+	if predictorAsync != nil && predictorAsync.QueueDepthTarget != nil {
+		return fmt.Errorf(KedaDisabledError, "predictor", constants.InferenceServiceConfigMapName)
+	}
+	for _, c := range []struct {
+		name      string
+		component Component
+	}{
+		{"predictor", &isvc.Spec.Predictor},
+		{"transformer", isvc.Spec.Transformer},
+		{"explainer", isvc.Spec.Explainer},
+	} {
+		if !reflect.ValueOf(c.component).IsNil() && c.component.GetExtensions().Keda != nil {
+			return fmt.Errorf(KedaDisabledError, c.name, constants.InferenceServiceConfigMapName)
+		}
+	}
+	return nil
+}
+
+// Validation of the timeout and of the size limits of the components against the maximums of the ingress config
+func validateGatewayMaximums(isvc *InferenceService, config *IngressConfig) error {
+	components := map[string]Component{"predictor": &isvc.Spec.Predictor}
+	if isvc.Spec.Transformer != nil {
+		components["transformer"] = isvc.Spec.Transformer
+	}
+	if isvc.Spec.Explainer != nil {
+		components["explainer"] = isvc.Spec.Explainer
+	}
+	for _, componentName := range []string{"predictor", "transformer", "explainer"} {
+		component, ok := components[componentName]
+		if !ok {
+			continue
+		}
+		extensions := component.GetExtensions()
+		for _, limit := range []struct {
+			field   string
+			value   *int64
+			maximum int64
+		}{
+			{field: "Timeout", value: extensions.TimeoutSeconds, maximum: config.MaxTimeoutSeconds},
+			{field: "MaxRequestBytes", value: extensions.MaxRequestBytes, maximum: config.MaxRequestBytes},
+			{field: "MaxResponseBytes", value: extensions.MaxResponseBytes, maximum: config.MaxResponseBytes},
+		} {
+			if limit.value != nil && limit.maximum > 0 && *limit.value > limit.maximum {
+				return fmt.Errorf(GatewayMaximumExceededError, limit.field, componentName, limit.maximum,
+					constants.InferenceServiceConfigMapName)
+			}
+		}
+	}
+	return nil
+}
+
+// Validation of the hedging of the components against the ingress backend, only the istio backend sends the hedged
+// requests
+func validateHedgingBackend(isvc *InferenceService, config *IngressConfig) error {
+	if isvc.HasHedging() && config.IngressBackend != "" && config.IngressBackend != IstioIngressBackend {
+		return fmt.Errorf(HedgingNotSupportedError, constants.InferenceServiceConfigMapName, config.IngressBackend)
+	}
+	return nil
+}
+
+// Validation of the resource profile annotation against the resource profiles of the frameworks in the config map
+func validateResourceProfile(isvc *InferenceService, config *InferenceServicesConfig) error {
+	profileName, ok := isvc.Annotations[constants.ResourceProfileAnnotationKey]
+	if !ok {
+		return nil
+	}
+	components := map[string]Component{"predictor": &isvc.Spec.Predictor}
+	if isvc.Spec.Explainer != nil {
+		components["explainer"] = isvc.Spec.Explainer
+	}
+	for _, componentName := range []string{"predictor", "explainer"} {
+		component, ok := components[componentName]
+		if !ok {
+			continue
+		}
+		// The frameworks without resource profiles use the default resources whatever the annotation
+		framework, resources, profiles := resourceProfiles(component.GetImplementation(), config)
+		if resources == nil || len(profiles.ResourceProfiles) == 0 {
+			continue
+		}
+		if _, ok := profiles.ResourceProfiles[profileName]; !ok {
+			var profileNames []string
+			for name := range profiles.ResourceProfiles {
+				profileNames = append(profileNames, name)
+			}
+			sort.Strings(profileNames)
+			return fmt.Errorf(InvalidResourceProfileError, profileName, constants.ResourceProfileAnnotationKey, framework,
+				componentName, strings.Join(profileNames, ", "), constants.InferenceServiceConfigMapName)
+		}
+	}
+	return nil
+}
+
+// allowedRuntimeVersions returns the framework, the runtime version and the allowed runtime versions of the
+// implementation, no versions when the implementation runs a custom container or a ServingRuntime. The default versions
+// are always allowed.
+func allowedRuntimeVersions(implementation ComponentImplementation, config *InferenceServicesConfig) (string, *string, []string) {
+	predictorVersions := func(predictorConfig PredictorConfig) []string {
+		return withDefaultVersions(predictorConfig.AllowedImageVersions, predictorConfig.DefaultImageVersion, predictorConfig.DefaultGpuImageVersion)
+	}
+	switch spec := implementation.(type) {
+	case *SKLearnSpec:
+		return "sklearn", spec.RuntimeVersion, predictorVersions(config.Predictors.SKlearn)
+	case *XGBoostSpec:
+		return "xgboost", spec.RuntimeVersion, predictorVersions(config.Predictors.XGBoost)
+	case *LightGBMSpec:
+		return "lightgbm", spec.RuntimeVersion, predictorVersions(config.Predictors.LightGBM)
+	case *TFServingSpec:
+		return "tensorflow", spec.RuntimeVersion, predictorVersions(config.Predictors.Tensorflow)
+	case *TorchServeSpec:
+		return "pytorch", spec.RuntimeVersion, predictorVersions(config.Predictors.TorchServe)
+	case *TritonSpec:
+		return "triton", spec.RuntimeVersion, predictorVersions(config.Predictors.Triton)
+	case *ONNXRuntimeSpec:
+		return "onnx", spec.RuntimeVersion, predictorVersions(config.Predictors.ONNX)
+	case *PMMLSpec:
+		return "pmml", spec.RuntimeVersion, predictorVersions(config.Predictors.PMML)
+	case *PaddleSpec:
+		return "paddle", spec.RuntimeVersion, predictorVersions(config.Predictors.Paddle)
+	case *MLflowSpec:
+		return "mlflow", spec.RuntimeVersion, predictorVersions(spec.runtimeConfig(config))
+	case *AlibiExplainerSpec:
+		explainerConfig := config.Explainers.AlibiExplainer
+		return "alibi", spec.RuntimeVersion, withDefaultVersions(explainerConfig.AllowedImageVersions, explainerConfig.DefaultImageVersion)
+	case *AIXExplainerSpec:
+		explainerConfig := config.Explainers.AIXExplainer
+		return "aix", spec.RuntimeVersion, withDefaultVersions(explainerConfig.AllowedImageVersions, explainerConfig.DefaultImageVersion)
+	}
+	return "", nil, nil
+}
+
+func withDefaultVersions(allowed []string, defaultVersions ...string) []string {
+	if len(allowed) == 0 {
+		return nil
+	}
+	versions := append([]string{}, allowed...)
+	for _, version := range defaultVersions {
+		if version != "" && !utils.Includes(versions, version) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
@@ -18,13 +18,22 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
 
 	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func makeTestInferenceService() InferenceService {
@@ -196,6 +205,374 @@ func TestBadReplicaValues(t *testing.T) {
 	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(MinReplicasShouldBeLessThanMaxError))
 }
 
+func TestBadScalingValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	cpu := MetricCPU
+	rps := MetricRPS
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ScaleTargetLowerBoundExceededError))
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(200)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ScaleMetric = &cpu
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(UtilizationScaleTargetError, cpu)))
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(80)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.MinReplicas = GetIntReference(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ScaleToZeroNotSupportedError, cpu)))
+	isvc.Spec.Predictor.ScaleMetric = &rps
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	memory := ScaleMetric("memory")
+	isvc.Spec.Predictor.ScaleMetric = &memory
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(UnsupportedScaleMetricError, memory)))
+}
+
+func TestBadConcurrencyTargetValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	cpu := MetricCPU
+	rps := MetricRPS
+	isvc.Spec.Predictor.ContainerConcurrency = proto.Int64(10)
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(10)
+	isvc.Spec.Predictor.TargetUtilizationPercentage = GetIntReference(80)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(20)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ScaleTargetConcurrencyError, 20, 10)))
+	// the container concurrency does not bound the requests per second, nor the unlimited container concurrency
+	isvc.Spec.Predictor.ScaleMetric = &rps
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ScaleMetric = nil
+	isvc.Spec.Predictor.ContainerConcurrency = proto.Int64(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.TargetUtilizationPercentage = GetIntReference(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TargetUtilizationOutOfRangeError))
+	isvc.Spec.Predictor.TargetUtilizationPercentage = GetIntReference(101)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TargetUtilizationOutOfRangeError))
+	isvc.Spec.Predictor.TargetUtilizationPercentage = GetIntReference(70)
+	isvc.Spec.Predictor.ScaleMetric = &cpu
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(80)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(TargetUtilizationMetricError, cpu)))
+}
+
+func TestBadKedaValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Keda = &KedaSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(KedaTriggersRequiredError))
+	isvc.Spec.Predictor.Keda.Triggers = []KedaTrigger{{Metadata: map[string]string{"topic": "requests"}}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(KedaTriggerTypeRequiredError))
+	isvc.Spec.Predictor.Keda.Triggers[0].Type = "kafka"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ScaleTarget = GetIntReference(10)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(KedaScaleMetricConflictError))
+	isvc.Spec.Predictor.ScaleTarget = nil
+	isvc.Spec.Predictor.TargetUtilizationPercentage = GetIntReference(70)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(KedaScaleMetricConflictError))
+}
+
+func TestKedaDisabled(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*InferenceServicesConfig, error)) {
+		getInferenceServicesConfig = get
+	}(getInferenceServicesConfig)
+	servicesConfig := &InferenceServicesConfig{}
+	getInferenceServicesConfig = func(string) (*InferenceServicesConfig, error) {
+		return servicesConfig, nil
+	}
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Keda = &KedaSpec{Triggers: []KedaTrigger{{Type: "kafka"}}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(KedaDisabledError, "predictor", constants.InferenceServiceConfigMapName)))
+	servicesConfig.Autoscaling.KedaEnabled = true
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestBadDisruptionBudgetValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.DisruptionBudget = &DisruptionBudgetSpec{}
+	// The default budget evicting one replica at a time does not block the drains at the default minReplicas
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	minAvailable := intstr.FromInt(1)
+	isvc.Spec.Predictor.DisruptionBudget.MinAvailable = &minAvailable
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MinAvailableMinReplicasError, 1, 1)))
+	minReplicas := 2
+	isvc.Spec.Predictor.MinReplicas = &minReplicas
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	minAvailable = intstr.FromInt(-1)
+	isvc.Spec.Predictor.DisruptionBudget.MinAvailable = &minAvailable
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NegativeValueError, "DisruptionBudget minAvailable")))
+	minAvailable = intstr.FromInt(3)
+	isvc.Spec.Predictor.MinReplicas = &minReplicas
+	isvc.Spec.Predictor.MaxReplicas = 5
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MinAvailableMinReplicasError, 3, 2)))
+	minReplicas = 4
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	// No replica is kept available at scale to zero
+	minReplicas = 0
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MinAvailableMinReplicasError, 3, 0)))
+	minAvailable = intstr.FromInt(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	for _, invalid := range []string{"50", "150%", "-10%", "half%"} {
+		minAvailable = intstr.FromString(invalid)
+		g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidMinAvailableError, invalid)))
+	}
+	minAvailable = intstr.FromString("50%")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestModelDigest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.ModelDigest = proto.String("sha256:" + strings.Repeat("9c", 32))
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.ModelDigest = proto.String("sha512:" + strings.Repeat("9c", 64))
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	for _, invalid := range []string{"9c2a", "sha256:9c2a", "md5:" + strings.Repeat("9c", 16), "sha256:" + strings.Repeat("9C", 32)} {
+		isvc.Spec.Predictor.ModelDigest = proto.String(invalid)
+		g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidModelDigestError, invalid)))
+	}
+}
+
+func TestBadRateLimitValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Burst = proto.Int64(10)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(BurstRequiresRateLimitError))
+	isvc.Spec.Predictor.RequestsPerSecond = proto.Int64(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(RateLimitLowerBoundExceededError))
+	isvc.Spec.Predictor.RequestsPerSecond = proto.Int64(20)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(BurstLowerBoundExceededError))
+	isvc.Spec.Predictor.Burst = proto.Int64(40)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestBadRequestLimitValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TimeoutLowerBoundExceededError))
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(60)
+	isvc.Spec.Predictor.MaxRequestBytes = proto.Int64(0)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MaxBytesLowerBoundExceededError, "MaxRequestBytes")))
+	isvc.Spec.Predictor.MaxRequestBytes = proto.Int64(1048576)
+	isvc.Spec.Predictor.MaxResponseBytes = proto.Int64(-1)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(MaxBytesLowerBoundExceededError, "MaxResponseBytes")))
+	isvc.Spec.Predictor.MaxResponseBytes = proto.Int64(1048576)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestBadRetryValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Retry = &RetryPolicy{Attempts: -1}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NegativeValueError, "Retry attempts")))
+	isvc.Spec.Predictor.Retry.Attempts = 3
+	isvc.Spec.Predictor.Retry.PerTryTimeout = &metav1.Duration{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NonPositiveDurationError, "Retry perTryTimeout")))
+	isvc.Spec.Predictor.Retry.PerTryTimeout = &metav1.Duration{Duration: 10 * time.Second}
+	isvc.Spec.Predictor.Retry.RetryOn = []string{"gateway-error", "5xx", "always"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidRetryOnError, "always")))
+	isvc.Spec.Predictor.Retry.RetryOn = []string{"gateway-error", "600"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidRetryOnError, "600")))
+	isvc.Spec.Predictor.Retry.RetryOn = []string{"gateway-error", "503"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestBadCircuitBreakerValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.CircuitBreaker = &CircuitBreaker{ConsecutiveErrors: -1}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NegativeValueError,
+		"CircuitBreaker consecutiveErrors")))
+	isvc.Spec.Predictor.CircuitBreaker.ConsecutiveErrors = 5
+	isvc.Spec.Predictor.CircuitBreaker.MaxEjectionPercent = 101
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(MaxEjectionPercentError))
+	isvc.Spec.Predictor.CircuitBreaker.MaxEjectionPercent = 50
+	isvc.Spec.Predictor.CircuitBreaker.BaseEjectionTime = &metav1.Duration{Duration: -time.Second}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NonPositiveDurationError,
+		"CircuitBreaker baseEjectionTime")))
+	isvc.Spec.Predictor.CircuitBreaker.BaseEjectionTime = &metav1.Duration{Duration: time.Minute}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestBadPredictorCallValues(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Transformer = &TransformerSpec{}
+	isvc.Spec.Transformer.PodSpec = PodSpec{Containers: []v1.Container{{Image: "some-image"}}}
+	isvc.Spec.Transformer.PredictorCall = &PredictorCallSpec{Host: "http://other-model"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(gomega.ContainSubstring("PredictorCall host must be a DNS subdomain")))
+	isvc.Spec.Transformer.PredictorCall.Host = "other-model.other-namespace:0"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidPredictorCallHostError,
+		"other-model.other-namespace:0", "invalid port")))
+	isvc.Spec.Transformer.PredictorCall.Host = "other-model.other-namespace:8080"
+	isvc.Spec.Transformer.PredictorCall.Protocol = "gRPC"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(InvalidPredictorCallProtocolError, "gRPC")))
+	isvc.Spec.Transformer.PredictorCall.Protocol = PredictorCallHTTP2
+	isvc.Spec.Transformer.PredictorCall.MaxConnections = -1
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NegativeValueError, "PredictorCall maxConnections")))
+	isvc.Spec.Transformer.PredictorCall.MaxConnections = 50
+	isvc.Spec.Transformer.PredictorCall.IdleTimeout = &metav1.Duration{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(NonPositiveDurationError, "PredictorCall idleTimeout")))
+	isvc.Spec.Transformer.PredictorCall.IdleTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestGatewayMaximums(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*IngressConfig, error)) {
+		getIngressConfig = get
+	}(getIngressConfig)
+	getIngressConfig = func(string) (*IngressConfig, error) {
+		return &IngressConfig{MaxTimeoutSeconds: 600, MaxRequestBytes: 10485760}, nil
+	}
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(600)
+	isvc.Spec.Predictor.MaxRequestBytes = proto.Int64(10485760)
+	// The response size is not limited by the config
+	isvc.Spec.Predictor.MaxResponseBytes = proto.Int64(104857600)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(900)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(GatewayMaximumExceededError, "Timeout",
+		"predictor", 600, constants.InferenceServiceConfigMapName)))
+	isvc.Spec.Predictor.TimeoutSeconds = nil
+	isvc.Spec.Transformer = &TransformerSpec{}
+	isvc.Spec.Transformer.PodSpec = PodSpec{Containers: []v1.Container{{Image: "some-image"}}}
+	isvc.Spec.Transformer.MaxRequestBytes = proto.Int64(20971520)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(GatewayMaximumExceededError, "MaxRequestBytes",
+		"transformer", 10485760, constants.InferenceServiceConfigMapName)))
+}
+
+func TestHedgingBackend(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*IngressConfig, error)) {
+		getIngressConfig = get
+	}(getIngressConfig)
+	backend := KubernetesIngressBackend
+	getIngressConfig = func(string) (*IngressConfig, error) {
+		return &IngressConfig{IngressBackend: backend}, nil
+	}
+
+	isvc := makeTestInferenceService()
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Hedging = &HedgingSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(HedgingNotSupportedError,
+		constants.InferenceServiceConfigMapName, KubernetesIngressBackend)))
+	backend = IstioIngressBackend
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestConfigReader(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(reader client.Reader) {
+		configReader = reader
+	}(configReader)
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(900)
+	// The validations depending on the config maps are skipped until the webhook is registered with a manager
+	configReader = nil
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	// The config maps are read through the reader shared by the admissions
+	configReader = fake.NewFakeClientWithScheme(scheme.Scheme, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		Data: map[string]string{IngressConfigKeyName: `{"ingressGateway": "knative-serving/knative-ingress-gateway",
+			"ingressService": "istio-ingressgateway.istio-system.svc.cluster.local", "maxTimeoutSeconds": 600}`},
+	})
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(GatewayMaximumExceededError, "Timeout",
+		"predictor", 600, constants.InferenceServiceConfigMapName)))
+}
+
+func TestLoggerSinks(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*LoggerConfig, error)) {
+		getLoggerConfig = get
+	}(getLoggerConfig)
+	getLoggerConfig = func(string) (*LoggerConfig, error) {
+		return &LoggerConfig{DefaultUrl: "http://default-broker.eu.example.com", AllowedSinkDomains: []string{"eu.example.com"}}, nil
+	}
+
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Logger = &LoggerSpec{Mode: LogAll}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Logger.URL = proto.String("http://sink.logs.eu.example.com")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Predictor.Logger.URL = proto.String("http://sink.us.example.com")
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(LoggerSinkNotAllowedError,
+		"http://sink.us.example.com", "predictor", "eu.example.com", constants.InferenceServiceConfigMapName)))
+	isvc.Spec.Predictor.Logger = nil
+	isvc.Spec.Transformer = &TransformerSpec{}
+	isvc.Spec.Transformer.PodSpec = PodSpec{Containers: []v1.Container{{Image: "some-image"}}}
+	isvc.Spec.Transformer.Logger = &LoggerSpec{Mode: LogAll, URL: proto.String("http://eu.example.com.attacker.io")}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(LoggerSinkNotAllowedError,
+		"http://eu.example.com.attacker.io", "transformer", "eu.example.com", constants.InferenceServiceConfigMapName)))
+}
+
+func TestRollbackTo(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	old := makeTestInferenceService()
+	old.Status.Components = map[ComponentType]ComponentStatusSpec{
+		PredictorComponent: {
+			RevisionHistory: []string{"foo-predictor-default-00002", "foo-predictor-default-00001"},
+		},
+	}
+	isvc := old.DeepCopy()
+	isvc.Spec.Predictor.RollbackTo = proto.String("foo-predictor-default-00001")
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.Succeed())
+	// no revision history on create
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidRollbackRevisionError, "foo-predictor-default-00001", PredictorComponent, "")))
+	isvc.Spec.Predictor.RollbackTo = proto.String("foo-predictor-default-00003")
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidRollbackRevisionError, "foo-predictor-default-00003", PredictorComponent,
+			"foo-predictor-default-00002, foo-predictor-default-00001")))
+	// an unchanged rollback is not checked against the history
+	old.Spec.Predictor.RollbackTo = proto.String("foo-predictor-default-00003")
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.Succeed())
+	isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(10)
+	g.Expect(isvc.ValidateUpdate(&old)).Should(gomega.MatchError(RollbackCanaryConflictError))
+}
+
+func TestShadow(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Shadow = true
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ShadowRequiresCanaryError))
+	isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(20)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers: []v1.Container{{Image: "transformer:latest"}},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(ShadowBehindTransformerError))
+}
+
+func TestCanaryMatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.CanaryMatch = []CanaryMatch{{}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(EmptyCanaryMatchError))
+	isvc.Spec.Predictor.CanaryMatch = []CanaryMatch{{
+		Cookies: map[string]string{"model-version": "canary", "user-group": "beta"},
+	}}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(MultipleCanaryCookiesError))
+	isvc.Spec.Predictor.CanaryMatch = []CanaryMatch{
+		{Headers: map[string]string{"x-model-version": "canary"}},
+		{Cookies: map[string]string{"model-version": "canary"}},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Spec.Transformer = &TransformerSpec{
+		PodSpec: PodSpec{
+			Containers: []v1.Container{{Image: "transformer:latest"}},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(CanaryMatchBehindTransformerError))
+}
+
 func TestCustomOK(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
 	isvc := makeTestInferenceService()
@@ -257,3 +634,391 @@ func TestRejectBadNameIncludeDot(t *testing.T) {
 	isvc.Name = "abc.de"
 	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
 }
+
+func TestExplainerSampling(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		samplingPercent int64
+		logger          *LoggerSpec
+		matcher         types.GomegaMatcher
+	}{
+		"ValidSampling": {
+			samplingPercent: 10,
+			logger:          &LoggerSpec{Mode: LogAll},
+			matcher:         gomega.Succeed(),
+		},
+		"NoSampling": {
+			samplingPercent: 0,
+			matcher:         gomega.Succeed(),
+		},
+		"SamplingOutOfRange": {
+			samplingPercent: 101,
+			logger:          &LoggerSpec{Mode: LogAll},
+			matcher:         gomega.MatchError(SamplingPercentOutOfRangeError),
+		},
+		"SamplingWithoutLogger": {
+			samplingPercent: 10,
+			matcher:         gomega.MatchError(SamplingRequiresLoggerError),
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := makeTestInferenceService()
+		isvc.Spec.Predictor.Logger = scenario.logger
+		isvc.Spec.Explainer = &ExplainerSpec{
+			Alibi: &AlibiExplainerSpec{
+				StorageURI: "gs://testbucket/testmodel",
+			},
+			SamplingPercent: proto.Int64(scenario.samplingPercent),
+		}
+		g.Expect(isvc.ValidateCreate()).Should(scenario.matcher, fmt.Sprintf("Testing %s", name))
+	}
+}
+
+func TestModelSizeAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{constants.ModelSizeAnnotationKey: "10Gi"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Annotations[constants.ModelSizeAnnotationKey] = "ten gigabytes"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidModelSizeAnnotationError, constants.ModelSizeAnnotationKey, "ten gigabytes")))
+}
+
+func TestIdleAnnotations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{constants.IdleTTLAnnotationKey: "24h"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Annotations[constants.IdleTTLAnnotationKey] = "-1h"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidIdleTTLAnnotationError, constants.IdleTTLAnnotationKey, "-1h")))
+
+	isvc.Annotations[constants.IdleTTLAnnotationKey] = "24h"
+	isvc.Annotations[constants.IdleActionAnnotationKey] = "Archive"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidIdleActionAnnotationError, constants.IdleActionAnnotationKey, "Archive")))
+
+	isvc.Annotations[constants.IdleActionAnnotationKey] = string(IdleActionScaleToZero)
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	cpu := MetricCPU
+	isvc.Spec.Predictor.ScaleMetric = &cpu
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(IdleScaleToZeroNotSupportedError, constants.IdleActionAnnotationKey, PredictorComponent, cpu)))
+}
+
+func TestIngressHostAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{constants.IngressHostAnnotationKey: "flowers.models.example.com"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Annotations[constants.IngressHostAnnotationKey] = "https://flowers.models.example.com"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(gomega.HavePrefix(
+		fmt.Sprintf("Annotation %s must be a DNS subdomain", constants.IngressHostAnnotationKey))))
+
+	// The host must be under the host override domains of the ingress config
+	defer func(get func(string) (*IngressConfig, error)) {
+		getIngressConfig = get
+	}(getIngressConfig)
+	getIngressConfig = func(string) (*IngressConfig, error) {
+		return &IngressConfig{HostOverrideDomains: []string{"models.example.com"}}, nil
+	}
+	isvc.Annotations[constants.IngressHostAnnotationKey] = "flowers.models.example.com"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+	isvc.Annotations[constants.IngressHostAnnotationKey] = "flowers.example.com"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(IngressHostNotAllowedError,
+		constants.IngressHostAnnotationKey, "flowers.example.com", constants.InferenceServiceConfigMapName)))
+}
+
+func TestAuthAnnotations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Annotations = map[string]string{
+		constants.AuthRequiredAnnotationKey:  "true",
+		constants.AuthIssuerAnnotationKey:    "https://accounts.example.com",
+		constants.AuthAudiencesAnnotationKey: "flowers,models",
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Annotations[constants.AuthRequiredAnnotationKey] = "yes please"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidBooleanAnnotationError, constants.AuthRequiredAnnotationKey, "yes please")))
+
+	isvc.Annotations[constants.AuthRequiredAnnotationKey] = "false"
+	isvc.Annotations[constants.APIKeyAnnotationKey] = "on"
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidBooleanAnnotationError, constants.APIKeyAnnotationKey, "on")))
+
+	isvc.Annotations[constants.APIKeyAnnotationKey] = "true"
+	isvc.Annotations[constants.AuthIssuerAnnotationKey] = " "
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(InvalidAuthIssuerAnnotationError, constants.AuthIssuerAnnotationKey, " ")))
+}
+
+func TestRejectAutoscalingServiceAnnotations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.ServiceAnnotations = map[string]string{"autoscaling.knative.dev/target": "10"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(AutoscalingServiceAnnotationError, "autoscaling.knative.dev/target")))
+
+	isvc.Spec.Predictor.ServiceAnnotations = nil
+	isvc.Spec.Predictor.RevisionAnnotations = map[string]string{"autoscaling.knative.dev/target": "10"}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Spec.Predictor.RevisionAnnotations = map[string]string{
+		"autoscaling.knative.dev/minScale": "3",
+		"autoscaling.knative.dev/maxScale": "2",
+	}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+	isvc.Spec.Predictor.RevisionAnnotations = map[string]string{"autoscaling.knative.dev/minScale": "one"}
+	g.Expect(isvc.ValidateCreate()).ShouldNot(gomega.Succeed())
+}
+
+func TestExactlyOneErrorListsSpecifiedImplementations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.XGBoost = &XGBoostSpec{}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		"Exactly one of [sklearn, xgboost, lightgbm, tensorflow, pytorch, triton, onnx, pmml, paddle, mlflow, model, containers] " +
+			"must be specified in PredictorSpec, found [xgboost, tensorflow]"))
+
+	isvc.Spec.Explainer = &ExplainerSpec{}
+	isvc.Spec.Predictor.XGBoost = nil
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		"Exactly one of [alibi, aix, containers] must be specified in ExplainerSpec, none is specified"))
+}
+
+func TestRuntimeVersions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*InferenceServicesConfig, error)) {
+		getInferenceServicesConfig = get
+	}(getInferenceServicesConfig)
+	servicesConfig := &InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			Tensorflow: PredictorConfig{
+				ContainerImage:         "tensorflow/serving",
+				DefaultImageVersion:    "1.14.0",
+				DefaultGpuImageVersion: "1.14.0-gpu",
+				AllowedImageVersions:   []string{"2.3.0", "2.3.0-gpu"},
+			},
+			SKlearn: PredictorConfig{
+				ContainerImage:      "kfserving/sklearnserver",
+				DefaultImageVersion: "v0.4.0",
+			},
+		},
+		Explainers: ExplainersConfig{
+			AlibiExplainer: ExplainerConfig{
+				ContainerImage:       "kfserving/alibi-explainer",
+				DefaultImageVersion:  "v0.4.0",
+				AllowedImageVersions: []string{"v0.4.0", "v0.5.0"},
+			},
+		},
+	}
+	getInferenceServicesConfig = func(string) (*InferenceServicesConfig, error) {
+		return servicesConfig, nil
+	}
+
+	scenarios := map[string]struct {
+		update  func(isvc *InferenceService)
+		matcher types.GomegaMatcher
+	}{
+		"AllowedVersion": {
+			update: func(isvc *InferenceService) {
+				isvc.Spec.Predictor.Tensorflow.RuntimeVersion = proto.String("2.3.0")
+			},
+			matcher: gomega.Succeed(),
+		},
+		"DefaultGpuVersion": {
+			update: func(isvc *InferenceService) {
+				isvc.Spec.Predictor.Tensorflow.RuntimeVersion = proto.String("1.14.0-gpu")
+				isvc.Spec.Predictor.Tensorflow.Resources.Limits = v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")}
+			},
+			matcher: gomega.Succeed(),
+		},
+		"VersionNotAllowed": {
+			update: func(isvc *InferenceService) {},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidRuntimeVersionError, "0.14.0", "tensorflow", "predictor",
+				"2.3.0, 2.3.0-gpu, 1.14.0, 1.14.0-gpu", constants.InferenceServiceConfigMapName)),
+		},
+		"AnyVersionWithoutAllowedVersions": {
+			update: func(isvc *InferenceService) {
+				isvc.Spec.Predictor.Tensorflow = nil
+				isvc.Spec.Predictor.SKLearn = &SKLearnSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI:     proto.String("gs://testbucket/testmodel"),
+						RuntimeVersion: proto.String("v0.6.0"),
+					},
+				}
+			},
+			matcher: gomega.Succeed(),
+		},
+		"ExplainerVersionNotAllowed": {
+			update: func(isvc *InferenceService) {
+				isvc.Spec.Predictor.Tensorflow.RuntimeVersion = proto.String("2.3.0")
+				isvc.Spec.Explainer = &ExplainerSpec{
+					Alibi: &AlibiExplainerSpec{
+						StorageURI:     "gs://testbucket/testmodel",
+						RuntimeVersion: proto.String("v0.3.0"),
+					},
+				}
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidRuntimeVersionError, "v0.3.0", "alibi", "explainer",
+				"v0.4.0, v0.5.0", constants.InferenceServiceConfigMapName)),
+		},
+		"CustomPredictor": {
+			update: func(isvc *InferenceService) {
+				isvc.Spec.Predictor.Tensorflow = nil
+				isvc.Spec.Predictor.Containers = []v1.Container{{Image: "custom:0.14.0"}}
+			},
+			matcher: gomega.Succeed(),
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := makeTestInferenceService()
+		scenario.update(&isvc)
+		g.Expect(isvc.ValidateCreate()).Should(scenario.matcher, fmt.Sprintf("Testing %s", name))
+	}
+
+	getInferenceServicesConfig = func(string) (*InferenceServicesConfig, error) {
+		return nil, fmt.Errorf("configmaps %q not found", constants.InferenceServiceConfigMapName)
+	}
+	isvc := makeTestInferenceService()
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestLoadPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	lazy := LoadPolicyLazy
+	eager := LoadPolicyEager
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow.LoadPolicy = &eager
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Spec.Predictor.Tensorflow.LoadPolicy = &lazy
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(LazyLoadPolicyNotSupportedError, "tensorflow", "sklearn, xgboost, lightgbm, pmml, paddle")))
+
+	isvc.Spec.Predictor.Tensorflow = nil
+	isvc.Spec.Predictor.SKLearn = &SKLearnSpec{
+		PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI: proto.String("gs://testbucket/testmodel"),
+			LoadPolicy: &lazy,
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+}
+
+func TestParallelism(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	isvc.Spec.Predictor.Tensorflow.Parallelism = &ParallelismSpec{Threads: proto.Int32(4)}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.Succeed())
+
+	isvc.Spec.Predictor.Tensorflow.Parallelism = &ParallelismSpec{Workers: proto.Int32(2)}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(ParallelismNotSupportedError, "workers", "tensorflow")))
+
+	isvc.Spec.Predictor.Tensorflow.Parallelism = &ParallelismSpec{Threads: proto.Int32(0)}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(fmt.Sprintf(ServerParallelismLowerBoundError, "threads")))
+
+	isvc.Spec.Predictor.Tensorflow = nil
+	isvc.Spec.Predictor.Triton = &TritonSpec{
+		PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI:  proto.String("gs://testbucket/testmodel"),
+			Parallelism: &ParallelismSpec{Workers: proto.Int32(2)},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(TritonParallelWorkersError))
+
+	isvc.Spec.Predictor.Triton = nil
+	isvc.Spec.Predictor.SKLearn = &SKLearnSpec{
+		PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI:  proto.String("gs://testbucket/testmodel"),
+			Parallelism: &ParallelismSpec{Workers: proto.Int32(2), Threads: proto.Int32(2)},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(ParallelismNotSupportedError, "threads", "sklearn")))
+
+	isvc.Spec.Predictor.SKLearn = nil
+	isvc.Spec.Predictor.PMML = &PMMLSpec{
+		PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI:  proto.String("gs://testbucket/testmodel"),
+			Parallelism: &ParallelismSpec{Workers: proto.Int32(2)},
+		},
+	}
+	g.Expect(isvc.ValidateCreate()).Should(gomega.MatchError(
+		fmt.Sprintf(ParallelismNotSupportedError, "workers", "pmml")))
+}
+
+func TestResourceProfile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func(get func(string) (*InferenceServicesConfig, error)) {
+		getInferenceServicesConfig = get
+	}(getInferenceServicesConfig)
+	small := v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	getInferenceServicesConfig = func(string) (*InferenceServicesConfig, error) {
+		return &InferenceServicesConfig{
+			Predictors: PredictorsConfig{
+				Tensorflow: PredictorConfig{
+					ResourceProfilesConfig: ResourceProfilesConfig{
+						ResourceProfiles: map[string]v1.ResourceRequirements{"small": small, "medium": small},
+					},
+				},
+			},
+		}, nil
+	}
+
+	scenarios := map[string]struct {
+		update  func(isvc *InferenceService)
+		matcher types.GomegaMatcher
+	}{
+		"NoAnnotation": {
+			update:  func(isvc *InferenceService) {},
+			matcher: gomega.Succeed(),
+		},
+		"DefinedProfile": {
+			update: func(isvc *InferenceService) {
+				isvc.Annotations = map[string]string{constants.ResourceProfileAnnotationKey: "small"}
+			},
+			matcher: gomega.Succeed(),
+		},
+		"UndefinedProfile": {
+			update: func(isvc *InferenceService) {
+				isvc.Annotations = map[string]string{constants.ResourceProfileAnnotationKey: "large"}
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidResourceProfileError, "large", constants.ResourceProfileAnnotationKey,
+				"tensorflow", "predictor", "medium, small", constants.InferenceServiceConfigMapName)),
+		},
+		"ExplainerWithoutProfiles": {
+			update: func(isvc *InferenceService) {
+				isvc.Annotations = map[string]string{constants.ResourceProfileAnnotationKey: "small"}
+				isvc.Spec.Explainer = &ExplainerSpec{
+					Alibi: &AlibiExplainerSpec{
+						StorageURI: "gs://testbucket/testmodel",
+					},
+				}
+			},
+			matcher: gomega.Succeed(),
+		},
+		"CustomPredictor": {
+			update: func(isvc *InferenceService) {
+				isvc.Annotations = map[string]string{constants.ResourceProfileAnnotationKey: "large"}
+				isvc.Spec.Predictor.Tensorflow = nil
+				isvc.Spec.Predictor.Containers = []v1.Container{{Image: "custom:0.14.0"}}
+			},
+			matcher: gomega.Succeed(),
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := makeTestInferenceService()
+		scenario.update(&isvc)
+		g.Expect(isvc.ValidateCreate()).Should(scenario.matcher, fmt.Sprintf("Testing %s", name))
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Known error messages
+const (
+	MissingModelConversionImageError         = "Model conversion requires the image of the converter."
+	ModelConversionCacheRequiresCommandError = "Model conversion caching requires the command of the converter."
+	ModelConversionRequiresStorageURIError   = "Model conversion requires the storageUri of the predictor."
+)
+
+// ModelConversionSpec defines a conversion step run once the model is downloaded and before the model server starts,
+// e.g. exporting a model to ONNX or building TensorRT engines for the GPU of the node.
+// The converter reads and writes the model in the directory set in the MODEL_DIR environment variable.
+type ModelConversionSpec struct {
+	// Image of the converter
+	Image string `json:"image"`
+	// Entrypoint of the converter, required when the converted models are cached
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Arguments of the converter
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Environment variables of the converter
+	// +optional
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Compute resources of the converter, defaults to the resources of the predictor so that engines are built for
+	// the GPU serving the model
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Name of the PersistentVolumeClaim caching the converted models keyed on the model checksum and the GPU type.
+	// The models are converted by every replica when not set.
+	// +optional
+	CacheVolumeClaim *string `json:"cacheVolumeClaim,omitempty"`
+}
+
+// Validate returns an error if invalid
+func (m *ModelConversionSpec) Validate() error {
+	if m.Image == "" {
+		return fmt.Errorf(MissingModelConversionImageError)
+	}
+	if m.CacheVolumeClaim != nil && len(m.Command) == 0 {
+		return fmt.Errorf(ModelConversionCacheRequiresCommandError)
+	}
+	return nil
+}
+
+// validateModelConversion validates the model conversion of the predictor, the converter needs a model to convert
+func validateModelConversion(predictor *PredictorSpec) error {
+	if predictor.ModelConversion == nil {
+		return nil
+	}
+	if err := predictor.ModelConversion.Validate(); err != nil {
+		return err
+	}
+	if implementations := predictor.GetImplementations(); len(implementations) == 0 || implementations[0].GetStorageUri() == nil {
+		return fmt.Errorf(ModelConversionRequiresStorageURIError)
+	}
+	return nil
+}
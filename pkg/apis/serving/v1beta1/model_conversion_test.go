@@ -0,0 +1,89 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestModelConversionValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	onnx := &ONNXRuntimeSpec{
+		PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI: proto.String("gs://models/onnx"),
+		},
+	}
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"NoConversion": {
+			spec:    PredictorSpec{ONNX: onnx},
+			matcher: gomega.BeNil(),
+		},
+		"ValidConversion": {
+			spec: PredictorSpec{
+				ONNX: onnx,
+				ModelConversion: &ModelConversionSpec{
+					Image:            "trtexec:21.03",
+					Command:          []string{"build-engine"},
+					CacheVolumeClaim: proto.String("engines"),
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"MissingImage": {
+			spec: PredictorSpec{
+				ONNX:            onnx,
+				ModelConversion: &ModelConversionSpec{},
+			},
+			matcher: gomega.MatchError(MissingModelConversionImageError),
+		},
+		"CacheWithoutCommand": {
+			spec: PredictorSpec{
+				ONNX: onnx,
+				ModelConversion: &ModelConversionSpec{
+					Image:            "trtexec:21.03",
+					CacheVolumeClaim: proto.String("engines"),
+				},
+			},
+			matcher: gomega.MatchError(ModelConversionCacheRequiresCommandError),
+		},
+		"MissingStorageUri": {
+			spec: PredictorSpec{
+				ONNX: &ONNXRuntimeSpec{},
+				ModelConversion: &ModelConversionSpec{
+					Image: "trtexec:21.03",
+				},
+			},
+			matcher: gomega.MatchError(ModelConversionRequiresStorageURIError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateModelConversion(&scenario.spec)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
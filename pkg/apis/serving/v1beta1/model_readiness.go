@@ -0,0 +1,187 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Known error messages
+const (
+	InvalidModelReadinessPathError    = "Model readiness path %q must start with /."
+	ModelReadinessLoadTimeoutError    = "Model readiness loadTimeoutSeconds cannot be less than 1."
+	ModelReadinessPeriodError         = "Model readiness periodSeconds must be between 1 and loadTimeoutSeconds."
+	ModelReadinessProbeConflictError  = "Model readiness conflicts with the readinessProbe of the predictor, remove the readinessProbe or the modelReadiness field."
+	ModelReadinessLazyLoadPolicyError = "Model readiness requires the Eager load policy, the model of a Lazy predictor is only loaded by the first request."
+)
+
+// Defaults of the model readiness
+const (
+	DefaultModelLoadTimeoutSeconds     int32 = 600
+	DefaultModelReadinessPeriodSeconds int32 = 10
+)
+
+// ModelReadinessSpec gates the traffic of the predictor on the loading of the model. The predictor pods only become
+// ready once the model ready endpoint of the model server passes, and the liveness probe of the predictor only starts
+// then, so that large models loading for minutes are neither served 503s nor restarted.
+type ModelReadinessSpec struct {
+	// Path of the HTTP endpoint of the model server answering 200 once the model is loaded, distinct from the
+	// liveness of the server. Defaults to /v1/models/<name> with the v1 protocol and to /v2/models/<name>/ready with
+	// the v2 protocol.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Seconds given to the model server to load the model before the container is restarted, defaults to 600
+	// +optional
+	LoadTimeoutSeconds *int32 `json:"loadTimeoutSeconds,omitempty"`
+	// Seconds between the probes of the model ready endpoint while the model loads, defaults to 10
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+}
+
+// GetPath returns the path of the model ready endpoint of the model of an InferenceService
+func (m *ModelReadinessSpec) GetPath(name string, protocol constants.InferenceServiceProtocol) string {
+	if m.Path != "" {
+		return m.Path
+	}
+	if protocol == constants.ProtocolV2 {
+		return constants.ModelReadyPathV2(name)
+	}
+	return constants.InferenceServicePrefix(name)
+}
+
+// GetLoadTimeoutSeconds returns the time given to the model server to load the model
+func (m *ModelReadinessSpec) GetLoadTimeoutSeconds() int32 {
+	if m.LoadTimeoutSeconds == nil {
+		return DefaultModelLoadTimeoutSeconds
+	}
+	return *m.LoadTimeoutSeconds
+}
+
+// GetPeriodSeconds returns the time between the probes while the model loads
+func (m *ModelReadinessSpec) GetPeriodSeconds() int32 {
+	if m.PeriodSeconds == nil {
+		return DefaultModelReadinessPeriodSeconds
+	}
+	return *m.PeriodSeconds
+}
+
+// ReadinessProbe returns the readiness probe of the predictor container, run by the Knative queue-proxy with
+// aggressive retries so that the revision receives traffic as soon as the model is loaded
+func (m *ModelReadinessSpec) ReadinessProbe(path string) *v1.Probe {
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{Path: path},
+		},
+		SuccessThreshold: 1,
+	}
+}
+
+// StartupProbe returns the startup probe of the predictor container, the container is restarted when the model is
+// not loaded within the load timeout. The port is left to the pod mutator, Knative does not allow startup probes.
+func (m *ModelReadinessSpec) StartupProbe(path string) *v1.Probe {
+	period := m.GetPeriodSeconds()
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{Path: path},
+		},
+		PeriodSeconds:    period,
+		TimeoutSeconds:   1,
+		SuccessThreshold: 1,
+		FailureThreshold: (m.GetLoadTimeoutSeconds() + period - 1) / period,
+	}
+}
+
+// Validate returns an error if invalid
+func (m *ModelReadinessSpec) Validate() error {
+	if m.Path != "" && !strings.HasPrefix(m.Path, "/") {
+		return fmt.Errorf(InvalidModelReadinessPathError, m.Path)
+	}
+	if m.GetLoadTimeoutSeconds() < 1 {
+		return fmt.Errorf(ModelReadinessLoadTimeoutError)
+	}
+	if m.GetPeriodSeconds() < 1 || m.GetPeriodSeconds() > m.GetLoadTimeoutSeconds() {
+		return fmt.Errorf(ModelReadinessPeriodError)
+	}
+	return nil
+}
+
+// validateModelReadiness validates the model readiness of the predictor, the readiness probe of the predictor
+// container is set from the model readiness
+func validateModelReadiness(predictor *PredictorSpec) error {
+	if predictor.ModelReadiness == nil {
+		return nil
+	}
+	if err := predictor.ModelReadiness.Validate(); err != nil {
+		return err
+	}
+	if container := predictorContainer(predictor); container != nil && container.ReadinessProbe != nil {
+		return fmt.Errorf(ModelReadinessProbeConflictError)
+	}
+	if extension := predictorExtension(predictor); extension != nil && extension.LoadPolicy != nil &&
+		*extension.LoadPolicy == LoadPolicyLazy {
+		return fmt.Errorf(ModelReadinessLazyLoadPolicyError)
+	}
+	return nil
+}
+
+// predictorContainer returns the predictor container set in the spec
+func predictorContainer(predictor *PredictorSpec) *v1.Container {
+	if len(predictor.PodSpec.Containers) != 0 {
+		return &predictor.PodSpec.Containers[0]
+	}
+	if extension := predictorExtension(predictor); extension != nil {
+		return &extension.Container
+	}
+	return nil
+}
+
+// predictorExtension returns the extension of the predictor framework, nil for custom predictors
+func predictorExtension(predictor *PredictorSpec) *PredictorExtensionSpec {
+	implementations := predictor.GetImplementations()
+	if len(implementations) == 0 {
+		return nil
+	}
+	switch spec := implementations[0].(type) {
+	case *SKLearnSpec:
+		return &spec.PredictorExtensionSpec
+	case *XGBoostSpec:
+		return &spec.PredictorExtensionSpec
+	case *LightGBMSpec:
+		return &spec.PredictorExtensionSpec
+	case *TFServingSpec:
+		return &spec.PredictorExtensionSpec
+	case *TorchServeSpec:
+		return &spec.PredictorExtensionSpec
+	case *TritonSpec:
+		return &spec.PredictorExtensionSpec
+	case *ONNXRuntimeSpec:
+		return &spec.PredictorExtensionSpec
+	case *PMMLSpec:
+		return &spec.PredictorExtensionSpec
+	case *PaddleSpec:
+		return &spec.PredictorExtensionSpec
+	case *MLflowSpec:
+		return &spec.PredictorExtensionSpec
+	case *ModelPredictorSpec:
+		return &spec.PredictorExtensionSpec
+	}
+	return nil
+}
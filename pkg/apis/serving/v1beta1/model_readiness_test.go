@@ -0,0 +1,184 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestModelReadinessValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	lazy := LoadPolicyLazy
+	sklearn := func(loadPolicy *LoadPolicy) *SKLearnSpec {
+		return &SKLearnSpec{
+			PredictorExtensionSpec: PredictorExtensionSpec{
+				StorageURI: proto.String("gs://models/sklearn"),
+				LoadPolicy: loadPolicy,
+			},
+		}
+	}
+	readinessProbe := &v1.Probe{Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/ready"}}}
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"NoModelReadiness": {
+			spec:    PredictorSpec{SKLearn: sklearn(nil)},
+			matcher: gomega.BeNil(),
+		},
+		"Defaults": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(nil),
+				ModelReadiness: &ModelReadinessSpec{},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"CustomPath": {
+			spec: PredictorSpec{
+				PodSpec:        PodSpec{Containers: []v1.Container{{Name: "server"}}},
+				ModelReadiness: &ModelReadinessSpec{Path: "/models/ready", LoadTimeoutSeconds: proto.Int32(1800)},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"RelativePath": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(nil),
+				ModelReadiness: &ModelReadinessSpec{Path: "ready"},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidModelReadinessPathError, "ready")),
+		},
+		"ZeroLoadTimeout": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(nil),
+				ModelReadiness: &ModelReadinessSpec{LoadTimeoutSeconds: proto.Int32(0)},
+			},
+			matcher: gomega.MatchError(ModelReadinessLoadTimeoutError),
+		},
+		"ZeroPeriod": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(nil),
+				ModelReadiness: &ModelReadinessSpec{PeriodSeconds: proto.Int32(0)},
+			},
+			matcher: gomega.MatchError(ModelReadinessPeriodError),
+		},
+		"PeriodLongerThanLoadTimeout": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(nil),
+				ModelReadiness: &ModelReadinessSpec{LoadTimeoutSeconds: proto.Int32(30), PeriodSeconds: proto.Int32(60)},
+			},
+			matcher: gomega.MatchError(ModelReadinessPeriodError),
+		},
+		"ConflictingReadinessProbe": {
+			spec: PredictorSpec{
+				Tensorflow: &TFServingSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/tensorflow"),
+						Container:  v1.Container{ReadinessProbe: readinessProbe},
+					},
+				},
+				ModelReadiness: &ModelReadinessSpec{},
+			},
+			matcher: gomega.MatchError(ModelReadinessProbeConflictError),
+		},
+		"ConflictingCustomReadinessProbe": {
+			spec: PredictorSpec{
+				PodSpec:        PodSpec{Containers: []v1.Container{{Name: "server", ReadinessProbe: readinessProbe}}},
+				ModelReadiness: &ModelReadinessSpec{},
+			},
+			matcher: gomega.MatchError(ModelReadinessProbeConflictError),
+		},
+		"LazyLoadPolicy": {
+			spec: PredictorSpec{
+				SKLearn:        sklearn(&lazy),
+				ModelReadiness: &ModelReadinessSpec{},
+			},
+			matcher: gomega.MatchError(ModelReadinessLazyLoadPolicyError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateModelReadiness(&scenario.spec)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestModelReadinessProbes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		spec             ModelReadinessSpec
+		protocol         constants.InferenceServiceProtocol
+		expectedPath     string
+		expectedPeriod   int32
+		expectedFailures int32
+	}{
+		"V1Defaults": {
+			spec:             ModelReadinessSpec{},
+			protocol:         constants.ProtocolV1,
+			expectedPath:     "/v1/models/foo",
+			expectedPeriod:   10,
+			expectedFailures: 60,
+		},
+		"V2Defaults": {
+			spec:             ModelReadinessSpec{},
+			protocol:         constants.ProtocolV2,
+			expectedPath:     "/v2/models/foo/ready",
+			expectedPeriod:   10,
+			expectedFailures: 60,
+		},
+		"CustomPathAndTimeout": {
+			spec: ModelReadinessSpec{
+				Path:               "/health/model",
+				LoadTimeoutSeconds: proto.Int32(125),
+				PeriodSeconds:      proto.Int32(30),
+			},
+			protocol:         constants.ProtocolV1,
+			expectedPath:     "/health/model",
+			expectedPeriod:   30,
+			expectedFailures: 5,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			path := scenario.spec.GetPath("foo", scenario.protocol)
+			g.Expect(path).To(gomega.Equal(scenario.expectedPath))
+
+			readinessProbe := scenario.spec.ReadinessProbe(path)
+			g.Expect(readinessProbe.HTTPGet.Path).To(gomega.Equal(scenario.expectedPath))
+			// Knative rejects the failure threshold and the timeout of the aggressive readiness probes
+			g.Expect(readinessProbe.PeriodSeconds).To(gomega.BeZero())
+			g.Expect(readinessProbe.FailureThreshold).To(gomega.BeZero())
+			g.Expect(readinessProbe.TimeoutSeconds).To(gomega.BeZero())
+
+			startupProbe := scenario.spec.StartupProbe(path)
+			g.Expect(startupProbe.HTTPGet.Path).To(gomega.Equal(scenario.expectedPath))
+			g.Expect(startupProbe.PeriodSeconds).To(gomega.Equal(scenario.expectedPeriod))
+			g.Expect(startupProbe.FailureThreshold).To(gomega.Equal(scenario.expectedFailures))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	InvalidDeployVersionError = "DeployVersion %d is not in the model versions of the predictor: [%s]."
+)
+
+// MaxModelVersions is the number of model versions kept in the status of an InferenceService
+const MaxModelVersions = 10
+
+// ModelVersion is a version of the model deployed by the predictor, recorded once its revision is ready
+type ModelVersion struct {
+	// Version is the number of the version, incremented on each deployment of the predictor
+	Version int64 `json:"version"`
+	// Revision of the predictor which served the version
+	Revision string `json:"revision"`
+	// StorageUri of the model
+	// +optional
+	StorageUri string `json:"storageUri,omitempty"`
+	// ModelDigest of the model declared by the predictor
+	// +optional
+	ModelDigest string `json:"modelDigest,omitempty"`
+	// Image of the model server
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Resources of the model server
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// DeployedAt is the time the revision of the version became ready
+	// +optional
+	DeployedAt *metav1.Time `json:"deployedAt,omitempty"`
+}
+
+// AddModelVersion records the model deployed by a new ready revision of the predictor at the head of the model
+// versions, keeping the MaxModelVersions most recent versions. A revision is only recorded once.
+func (ss *InferenceServiceStatus) AddModelVersion(version ModelVersion) {
+	if version.Revision == "" {
+		return
+	}
+	for _, modelVersion := range ss.ModelVersions {
+		if modelVersion.Revision == version.Revision {
+			return
+		}
+	}
+	version.Version = 1
+	if len(ss.ModelVersions) != 0 {
+		version.Version = ss.ModelVersions[0].Version + 1
+	}
+	versions := []ModelVersion{version}
+	for _, modelVersion := range ss.ModelVersions {
+		if len(versions) == MaxModelVersions {
+			break
+		}
+		versions = append(versions, modelVersion)
+	}
+	ss.ModelVersions = versions
+}
+
+// GetModelVersion returns a version of the model versions, nil if not found
+func (ss *InferenceServiceStatus) GetModelVersion(version int64) *ModelVersion {
+	for i := range ss.ModelVersions {
+		if ss.ModelVersions[i].Version == version {
+			return &ss.ModelVersions[i]
+		}
+	}
+	return nil
+}
+
+// validateDeployVersion checks that the predictor redeploys a version of its model versions. The version is only
+// checked when it changes, the other updates are not rejected once the version left the model versions.
+func validateDeployVersion(isvc *InferenceService, old *InferenceService) error {
+	deployVersion := isvc.Spec.Predictor.DeployVersion
+	if deployVersion == nil {
+		return nil
+	}
+	if oldVersion := old.Spec.Predictor.DeployVersion; oldVersion != nil && *oldVersion == *deployVersion {
+		return nil
+	}
+	if old.Status.GetModelVersion(*deployVersion) == nil {
+		versions := []string{}
+		for _, modelVersion := range old.Status.ModelVersions {
+			versions = append(versions, fmt.Sprint(modelVersion.Version))
+		}
+		return fmt.Errorf(InvalidDeployVersionError, *deployVersion, strings.Join(versions, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+)
+
+func TestAddModelVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	status := &InferenceServiceStatus{}
+	status.AddModelVersion(ModelVersion{})
+	g.Expect(status.ModelVersions).To(gomega.BeEmpty())
+
+	for i := 1; i <= MaxModelVersions+2; i++ {
+		status.AddModelVersion(ModelVersion{Revision: fmt.Sprintf("iris-predictor-default-%05d", i)})
+	}
+	g.Expect(status.ModelVersions).To(gomega.HaveLen(MaxModelVersions))
+	g.Expect(status.ModelVersions[0].Version).To(gomega.Equal(int64(MaxModelVersions + 2)))
+	g.Expect(status.ModelVersions[0].Revision).To(gomega.Equal("iris-predictor-default-00012"))
+	g.Expect(status.ModelVersions[MaxModelVersions-1].Version).To(gomega.Equal(int64(3)))
+
+	status.AddModelVersion(ModelVersion{Revision: "iris-predictor-default-00005"})
+	g.Expect(status.ModelVersions[0].Version).To(gomega.Equal(int64(MaxModelVersions + 2)))
+	g.Expect(status.GetModelVersion(5).Revision).To(gomega.Equal("iris-predictor-default-00005"))
+	g.Expect(status.GetModelVersion(1)).To(gomega.BeNil())
+}
+
+func TestDeployVersionValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	old := makeTestInferenceService()
+	old.Status.AddModelVersion(ModelVersion{Revision: "foo-predictor-default-00001"})
+	old.Status.AddModelVersion(ModelVersion{Revision: "foo-predictor-default-00002"})
+	isvc := old.DeepCopy()
+	g.Expect(validateDeployVersion(isvc, &old)).To(gomega.Succeed())
+
+	isvc.Spec.Predictor.DeployVersion = proto.Int64(1)
+	g.Expect(validateDeployVersion(isvc, &old)).To(gomega.Succeed())
+	isvc.Spec.Predictor.DeployVersion = proto.Int64(3)
+	g.Expect(validateDeployVersion(isvc, &old)).
+		To(gomega.MatchError(fmt.Sprintf(InvalidDeployVersionError, 3, "2, 1")))
+	g.Expect(validateDeployVersion(isvc, &InferenceService{})).
+		To(gomega.MatchError(fmt.Sprintf(InvalidDeployVersionError, 3, "")))
+
+	// The version is not checked again once set
+	old.Spec.Predictor.DeployVersion = proto.Int64(3)
+	g.Expect(validateDeployVersion(isvc, &old)).To(gomega.Succeed())
+}
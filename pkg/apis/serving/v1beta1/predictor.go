@@ -17,6 +17,9 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -27,6 +30,8 @@ type PredictorSpec struct {
 	SKLearn *SKLearnSpec `json:"sklearn,omitempty"`
 	// Spec for XGBoost model server
 	XGBoost *XGBoostSpec `json:"xgboost,omitempty"`
+	// Spec for LightGBM model server
+	LightGBM *LightGBMSpec `json:"lightgbm,omitempty"`
 	// Spec for TFServing (https://github.com/tensorflow/serving)
 	Tensorflow *TFServingSpec `json:"tensorflow,omitempty"`
 	// Spec for TorchServe (https://pytorch.org/serve)
@@ -35,18 +40,92 @@ type PredictorSpec struct {
 	Triton *TritonSpec `json:"triton,omitempty"`
 	// Spec for ONNX runtime (https://github.com/microsoft/onnxruntime)
 	ONNX *ONNXRuntimeSpec `json:"onnx,omitempty"`
+	// Spec for PMML (http://dmg.org/pmml/v4-1/GeneralStructure.html)
+	PMML *PMMLSpec `json:"pmml,omitempty"`
+	// Spec for PaddlePaddle model server (https://github.com/PaddlePaddle/Paddle)
+	Paddle *PaddleSpec `json:"paddle,omitempty"`
+	// Spec for models saved in the MLflow model format (https://www.mlflow.org/docs/latest/models.html)
+	MLflow *MLflowSpec `json:"mlflow,omitempty"`
+	// Spec for a model served by the ServingRuntime or ClusterServingRuntime supporting its model format
+	Model *ModelPredictorSpec `json:"model,omitempty"`
 	// This spec is dual purpose.
 	// 1) Users may choose to provide a full PodSpec for their predictor.
 	// The field PodSpec.Containers is mutually exclusive with other Predictors (i.e. TFServing).
 	// 2) Users may choose to provide a Predictor (i.e. TFServing) and specify PodSpec
 	// overrides in the CustomPredictor PodSpec. They must not provide PodSpec.Containers in this case.
 	PodSpec `json:",inline"`
+	// Conversion of the model run before the model server starts, e.g. building TensorRT engines
+	// +optional
+	ModelConversion *ModelConversionSpec `json:"modelConversion,omitempty"`
+	// GPU of the predictor: whole GPUs, MIG instances or a time-sliced GPU. Sets the GPU resources of the predictor
+	// container and selects the GPU enabled runtime version of the predictor.
+	// +optional
+	GPU *GPUSpec `json:"gpu,omitempty"`
+	// Gates the traffic of the predictor on the model ready endpoint of the model server, so that the pods only
+	// receive requests once the model is loaded
+	// +optional
+	ModelReadiness *ModelReadinessSpec `json:"modelReadiness,omitempty"`
+	// Requests replayed against each new pod of the predictor before it receives traffic
+	// +optional
+	Warmup *WarmupSpec `json:"warmup,omitempty"`
+	// Holds the traffic on the serving revision until the new revision of an update is ready
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+	// DeployVersion redeploys the storageUri, the image and the resources of a version of the model versions of the
+	// status, overriding the ones of the predictor. Unset it to deploy the predictor spec again.
+	// +optional
+	DeployVersion *int64 `json:"deployVersion,omitempty"`
+	// Accepts the requests asynchronously and queues them, the results are posted to a callback URL or fetched by
+	// the ID of the request
+	// +optional
+	Async *AsyncSpec `json:"async,omitempty"`
+	// Validates the requests against a schema or the model metadata and rejects the malformed requests with a 400
+	// before they reach the model server
+	// +optional
+	RequestValidation *RequestValidationSpec `json:"requestValidation,omitempty"`
+	// Protocol of the responses of the predictor, "unary", "streaming" or "websocket". Defaults to "unary". The
+	// streamed responses, chunked responses and server-sent events, are passed through the data plane as they are
+	// written. The websocket connections are passed through the data plane to the model server.
+	// +optional
+	Protocol ResponseProtocol `json:"protocol,omitempty"`
+	// Containers run alongside the model server in the pods of the predictor, e.g. fetching features or tokenizing
+	// the requests. They share the network of the model server and reach it on localhost. The init containers of the
+	// PodSpec run after the model is downloaded.
+	// +optional
+	Sidecars []v1.Container `json:"sidecars,omitempty"`
 	// Extensions available in all components
 	ComponentExtensionSpec `json:",inline"`
 }
 
 var _ Component = &PredictorSpec{}
 
+// LoadPolicy defines when the model server loads the model
+// +kubebuilder:validation:Enum=Eager;Lazy
+type LoadPolicy string
+
+const (
+	// LoadPolicyEager loads the model at startup, the model server is ready once the model is loaded
+	LoadPolicyEager LoadPolicy = "Eager"
+	// LoadPolicyLazy loads the model on the first request, the model server is ready before the model is loaded
+	LoadPolicyLazy LoadPolicy = "Lazy"
+)
+
+// ParallelismSpec sets the worker processes and the threads of a model server without its flags. The model servers
+// which do not support a field reject it.
+type ParallelismSpec struct {
+	// Number of worker processes serving the model: the workers of the kfserving python model servers, the workers
+	// per model of TorchServe and the parallel workers of MLServer. Overrides the workers set by containerConcurrency.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Workers *int32 `json:"workers,omitempty"`
+	// Number of threads of the model server: the threads of xgboost and lightgbm, the intra-op threads of
+	// TensorFlow Serving and the HTTP threads of Triton and of the ONNX Runtime server. Overrides the threads set by
+	// the CPU limit or by containerConcurrency.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Threads *int32 `json:"threads,omitempty"`
+}
+
 // PredictorExtensionSpec defines configuration shared across all predictor frameworks
 type PredictorExtensionSpec struct {
 	// This field points to the location of the trained model which is mounted onto the pod.
@@ -54,6 +133,17 @@ type PredictorExtensionSpec struct {
 	// Runtime version of the predictor docker image
 	// +optional
 	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Protocol version to use by the predictor (i.e. v1 or v2)
+	// +optional
+	ProtocolVersion *constants.InferenceServiceProtocol `json:"protocolVersion,omitempty"`
+	// When the model server loads the model, Eager by default. Lazy is supported by the model servers built on the
+	// kfserving python server: sklearn, xgboost, lightgbm, pmml and paddle.
+	// +optional
+	LoadPolicy *LoadPolicy `json:"loadPolicy,omitempty"`
+	// Worker processes and threads of the model server, translated into the flags or the environment variables of
+	// each model server.
+	// +optional
+	Parallelism *ParallelismSpec `json:"parallelism,omitempty"`
 	// Container enables overrides for the predictor.
 	// Each framework will have different defaults that are populated in the underlying container spec.
 	// +optional
@@ -64,11 +154,16 @@ type PredictorExtensionSpec struct {
 func (s *PredictorSpec) GetImplementations() []ComponentImplementation {
 	implementations := NonNilComponents([]ComponentImplementation{
 		s.XGBoost,
+		s.LightGBM,
 		s.PyTorch,
 		s.Triton,
 		s.SKLearn,
 		s.Tensorflow,
 		s.ONNX,
+		s.PMML,
+		s.Paddle,
+		s.MLflow,
+		s.Model,
 	})
 	// This struct is not a pointer, so it will never be nil; include if containers are specified
 	if len(s.PodSpec.Containers) != 0 {
@@ -86,3 +181,62 @@ func (s *PredictorSpec) GetImplementation() ComponentImplementation {
 func (s *PredictorSpec) GetExtensions() *ComponentExtensionSpec {
 	return &s.ComponentExtensionSpec
 }
+
+// GetProtocol returns the inference protocol served by the predictor, custom predictors are assumed to serve v1
+func (s *PredictorSpec) GetProtocol() constants.InferenceServiceProtocol {
+	implementations := s.GetImplementations()
+	if len(implementations) == 0 {
+		return constants.ProtocolV1
+	}
+	if predictor, ok := implementations[0].(interface {
+		GetProtocol() constants.InferenceServiceProtocol
+	}); ok {
+		return predictor.GetProtocol()
+	}
+	return constants.ProtocolV1
+}
+
+// GetProtocol returns the protocol version of the predictor, defaults to v1
+func (p *PredictorExtensionSpec) GetProtocol() constants.InferenceServiceProtocol {
+	if p.ProtocolVersion != nil {
+		return *p.ProtocolVersion
+	}
+	return constants.ProtocolV1
+}
+
+// loadPolicyArguments returns the arguments setting the load policy of the kfserving python model servers, the model
+// servers load the model at startup by default.
+func (p *PredictorExtensionSpec) loadPolicyArguments() []string {
+	if p.LoadPolicy == nil || *p.LoadPolicy == LoadPolicyEager {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s=%s", constants.ArgumentLoadPolicy, *p.LoadPolicy)}
+}
+
+// workersArguments returns the arguments setting the workers of the kfserving python model servers, the workers of
+// the parallelism or else the container concurrency
+func (p *PredictorExtensionSpec) workersArguments(extensions *ComponentExtensionSpec) []string {
+	if workers := p.getWorkers(); workers != nil {
+		return []string{fmt.Sprintf("%s=%d", constants.ArgumentWorkers, *workers)}
+	}
+	if extensions.ContainerConcurrency != nil {
+		return []string{fmt.Sprintf("%s=%d", constants.ArgumentWorkers, *extensions.ContainerConcurrency)}
+	}
+	return nil
+}
+
+// getWorkers returns the worker processes of the parallelism, nil when unset
+func (p *PredictorExtensionSpec) getWorkers() *int32 {
+	if p.Parallelism == nil {
+		return nil
+	}
+	return p.Parallelism.Workers
+}
+
+// getThreads returns the threads of the parallelism, nil when unset
+func (p *PredictorExtensionSpec) getThreads() *int32 {
+	if p.Parallelism == nil {
+		return nil
+	}
+	return p.Parallelism.Threads
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	InvalidLightGBMModelFileError = "LightGBM storageUri must point to a model directory or a .txt or .bst model file, got %s."
+)
+
+var (
+	// LightGBMModelFileExtensions are the model file formats loaded by the LightGBM server
+	LightGBMModelFileExtensions = []string{".txt", ".bst"}
+)
+
+// LightGBMSpec defines arguments for configuring LightGBM model serving.
+type LightGBMSpec struct {
+	// Contains fields shared across all predictors
+	PredictorExtensionSpec `json:",inline"`
+}
+
+var _ ComponentImplementation = &LightGBMSpec{}
+
+// Validate returns an error if invalid
+func (x *LightGBMSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(x.GetStorageUri()),
+		validateLightGBMModelFile(x.GetStorageUri()),
+	})
+}
+
+// Default sets defaults on the resource
+func (x *LightGBMSpec) Default(config *InferenceServicesConfig) {
+	x.Container.Name = constants.InferenceServiceContainerName
+	if x.RuntimeVersion == nil {
+		x.RuntimeVersion = proto.String(config.Predictors.LightGBM.DefaultImageVersion)
+	}
+	setResourceRequirementDefaults(&x.Resources)
+}
+
+// GetContainer transforms the resource into a container spec
+func (x *LightGBMSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	// The threads default to the CPU limit
+	cpuLimit := x.Resources.Limits.Cpu()
+	cpuLimit.RoundUp(0)
+	threads := strconv.Itoa(int(cpuLimit.Value()))
+	if x.getThreads() != nil {
+		threads = strconv.Itoa(int(*x.getThreads()))
+	}
+	arguments := []string{
+		fmt.Sprintf("%s=%s", constants.ArgumentModelName, metadata.Name),
+		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
+		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
+		fmt.Sprintf("%s=%s", "--nthread", threads),
+	}
+	arguments = append(arguments, x.workersArguments(extensions)...)
+	arguments = append(arguments, x.loadPolicyArguments()...)
+	// The container is built on a copy so the spec is left unchanged
+	container := x.Container.DeepCopy()
+	if container.Image == "" {
+		container.Image = config.Predictors.LightGBM.ContainerImage + ":" + *x.RuntimeVersion
+	}
+	container.Name = constants.InferenceServiceContainerName
+	container.Args = arguments
+	return container
+}
+
+func (x *LightGBMSpec) GetStorageUri() *string {
+	return x.StorageURI
+}
+
+// validateLightGBMModelFile accepts model directories and the model file formats saved by LightGBM
+func validateLightGBMModelFile(storageURI *string) error {
+	if storageURI == nil {
+		return nil
+	}
+	uri, err := url.Parse(*storageURI)
+	if err != nil {
+		return fmt.Errorf(InvalidLightGBMModelFileError, *storageURI)
+	}
+	// the bucket is part of the host so only the path is checked
+	ext := path.Ext(strings.TrimSuffix(uri.Path, "/"))
+	if ext == "" || utils.Includes(LightGBMModelFileExtensions, ext) {
+		return nil
+	}
+	return fmt.Errorf(InvalidLightGBMModelFileError, *storageURI)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MLflowFlavor is the MLflow model flavor used to load the model
+type MLflowFlavor string
+
+// MLflowFlavor Enum
+const (
+	// The python_function flavor is served by the MLServer mlflow runtime which reads the MLmodel file and loads the
+	// model with the flavor it was logged with, e.g. sklearn, pytorch or onnx.
+	MLflowPyFuncFlavor MLflowFlavor = "python_function"
+	// The sklearn flavor is served by the sklearn predictor, the model is saved as model.pkl
+	MLflowSKLearnFlavor MLflowFlavor = "sklearn"
+	// The onnx flavor is served by the ONNX runtime predictor, the model is saved as model.onnx
+	MLflowONNXFlavor MLflowFlavor = "onnx"
+)
+
+const (
+	InvalidMLflowFlavorError   = "MLflow flavor must be one of %s, got %s."
+	InvalidMLflowProtocolError = "MLflow flavor %s only supports protocol version %s."
+)
+
+// Environment variables configuring the MLServer mlflow runtime
+const (
+	MLServerHTTPPortEnvKey            = "MLSERVER_HTTP_PORT"
+	MLServerGRPCPortEnvKey            = "MLSERVER_GRPC_PORT"
+	MLServerModelNameEnvKey           = "MLSERVER_MODEL_NAME"
+	MLServerModelURIEnvKey            = "MLSERVER_MODEL_URI"
+	MLServerModelImplementationEnvKey = "MLSERVER_MODEL_IMPLEMENTATION"
+	MLServerParallelWorkersEnvKey     = "MLSERVER_PARALLEL_WORKERS"
+	MLServerMLflowRuntime             = "mlserver_mlflow.MLflowRuntime"
+	MLServerGRPCPort                  = "9000"
+)
+
+var (
+	mlflowFlavors = []MLflowFlavor{MLflowPyFuncFlavor, MLflowSKLearnFlavor, MLflowONNXFlavor}
+)
+
+// MLflowSpec defines arguments for serving models saved in the MLflow model format, the storageUri points to the
+// directory containing the MLmodel file.
+type MLflowSpec struct {
+	// Flavor used to serve the model, defaults to python_function.
+	// The sklearn and onnx flavors are served by the native KFServing runtimes of the framework.
+	// +optional
+	Flavor *MLflowFlavor `json:"flavor,omitempty"`
+	// Contains fields shared across all predictors
+	PredictorExtensionSpec `json:",inline"`
+}
+
+var _ ComponentImplementation = &MLflowSpec{}
+
+// Validate returns an error if invalid
+func (m *MLflowSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(m.GetStorageUri()),
+		validateMLflowFlavor(m.Flavor),
+		m.validateProtocol(),
+	})
+}
+
+// Default sets defaults on the resource
+func (m *MLflowSpec) Default(config *InferenceServicesConfig) {
+	if m.Flavor == nil {
+		flavor := MLflowPyFuncFlavor
+		m.Flavor = &flavor
+	}
+	if m.RuntimeVersion == nil {
+		m.RuntimeVersion = proto.String(m.runtimeConfig(config).DefaultImageVersion)
+	}
+	if m.ProtocolVersion == nil {
+		protocol := m.GetProtocol()
+		m.ProtocolVersion = &protocol
+	}
+	m.Container.Name = constants.InferenceServiceContainerName
+	setResourceRequirementDefaults(&m.Resources)
+}
+
+// GetContainer transforms the resource into a container spec
+func (m *MLflowSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	switch m.getFlavor() {
+	case MLflowSKLearnFlavor:
+		sklearn := &SKLearnSpec{PredictorExtensionSpec: m.PredictorExtensionSpec}
+		m.Container = *sklearn.GetContainer(metadata, extensions, config)
+	case MLflowONNXFlavor:
+		onnx := &ONNXRuntimeSpec{PredictorExtensionSpec: m.PredictorExtensionSpec}
+		m.Container = *onnx.GetContainer(metadata, extensions, config)
+	default:
+		envs := []v1.EnvVar{
+			{Name: MLServerHTTPPortEnvKey, Value: constants.InferenceServiceDefaultHttpPort},
+			{Name: MLServerGRPCPortEnvKey, Value: MLServerGRPCPort},
+			{Name: MLServerModelNameEnvKey, Value: metadata.Name},
+			{Name: MLServerModelURIEnvKey, Value: constants.DefaultModelLocalMountPath},
+			{Name: MLServerModelImplementationEnvKey, Value: MLServerMLflowRuntime},
+		}
+		if workers := m.getWorkers(); workers != nil {
+			envs = append(envs, v1.EnvVar{Name: MLServerParallelWorkersEnvKey, Value: strconv.Itoa(int(*workers))})
+		}
+		// environment variables set by the user take precedence
+		for _, env := range envs {
+			if !hasEnvVar(m.Env, env.Name) {
+				m.Env = append(m.Env, env)
+			}
+		}
+		if m.Container.Image == "" {
+			m.Container.Image = config.Predictors.MLflow.ContainerImage + ":" + *m.RuntimeVersion
+		}
+		m.Container.Name = constants.InferenceServiceContainerName
+		m.Container.Args = []string{"mlserver", "start", constants.DefaultModelLocalMountPath}
+	}
+	return &m.Container
+}
+
+func (m *MLflowSpec) GetStorageUri() *string {
+	return m.StorageURI
+}
+
+// GetProtocol returns the protocol served by the runtime of the flavor, MLServer only serves the v2 protocol
+func (m *MLflowSpec) GetProtocol() constants.InferenceServiceProtocol {
+	if m.getFlavor() == MLflowPyFuncFlavor {
+		return constants.ProtocolV2
+	}
+	return constants.ProtocolV1
+}
+
+func (m *MLflowSpec) getFlavor() MLflowFlavor {
+	if m.Flavor == nil {
+		return MLflowPyFuncFlavor
+	}
+	return *m.Flavor
+}
+
+func (m *MLflowSpec) runtimeConfig(config *InferenceServicesConfig) PredictorConfig {
+	switch m.getFlavor() {
+	case MLflowSKLearnFlavor:
+		return config.Predictors.SKlearn
+	case MLflowONNXFlavor:
+		return config.Predictors.ONNX
+	default:
+		return config.Predictors.MLflow
+	}
+}
+
+func (m *MLflowSpec) validateProtocol() error {
+	if m.ProtocolVersion == nil || *m.ProtocolVersion == m.GetProtocol() {
+		return nil
+	}
+	return fmt.Errorf(InvalidMLflowProtocolError, m.getFlavor(), m.GetProtocol())
+}
+
+func validateMLflowFlavor(flavor *MLflowFlavor) error {
+	if flavor == nil {
+		return nil
+	}
+	flavors := []string{}
+	for _, f := range mlflowFlavors {
+		if f == *flavor {
+			return nil
+		}
+		flavors = append(flavors, string(f))
+	}
+	return fmt.Errorf(InvalidMLflowFlavorError, strings.Join(flavors, ", "), *flavor)
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMLflowValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	sklearnFlavor := MLflowSKLearnFlavor
+	unknownFlavor := MLflowFlavor("h2o")
+	protocolV1 := constants.ProtocolV1
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"ValidStorageUri": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://kfserving-examples/models/mlflow/wine"),
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"InvalidStorageUri": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("hdfs://modelzoo"),
+					},
+				},
+			},
+			matcher: gomega.Not(gomega.BeNil()),
+		},
+		"SKLearnFlavor": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					Flavor: &sklearnFlavor,
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI:      proto.String("gs://kfserving-examples/models/mlflow/wine"),
+						ProtocolVersion: &protocolV1,
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"UnknownFlavor": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					Flavor: &unknownFlavor,
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://kfserving-examples/models/mlflow/wine"),
+					},
+				},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidMLflowFlavorError, "python_function, sklearn, onnx", "h2o")),
+		},
+		"PyFuncRejectsProtocolV1": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI:      proto.String("gs://kfserving-examples/models/mlflow/wine"),
+						ProtocolVersion: &protocolV1,
+					},
+				},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidMLflowProtocolError, MLflowPyFuncFlavor, constants.ProtocolV2)),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := scenario.spec.MLflow.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestMLflowDefaulter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			MLflow: PredictorConfig{
+				ContainerImage:      "seldonio/mlserver",
+				DefaultImageVersion: "0.2.1",
+			},
+			SKlearn: PredictorConfig{
+				ContainerImage:      "sklearnserver",
+				DefaultImageVersion: "v0.4.0",
+			},
+		},
+	}
+	defaultResource = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	pyfuncFlavor := MLflowPyFuncFlavor
+	sklearnFlavor := MLflowSKLearnFlavor
+	protocolV1 := constants.ProtocolV1
+	protocolV2 := constants.ProtocolV2
+	scenarios := map[string]struct {
+		spec     PredictorSpec
+		expected PredictorSpec
+	}{
+		"DefaultPyFuncFlavor": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{},
+			},
+			expected: PredictorSpec{
+				MLflow: &MLflowSpec{
+					Flavor: &pyfuncFlavor,
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion:  proto.String("0.2.1"),
+						ProtocolVersion: &protocolV2,
+						Container: v1.Container{
+							Name: constants.InferenceServiceContainerName,
+							Resources: v1.ResourceRequirements{
+								Requests: defaultResource,
+								Limits:   defaultResource,
+							},
+						},
+					},
+				},
+			},
+		},
+		"SKLearnFlavor": {
+			spec: PredictorSpec{
+				MLflow: &MLflowSpec{
+					Flavor: &sklearnFlavor,
+				},
+			},
+			expected: PredictorSpec{
+				MLflow: &MLflowSpec{
+					Flavor: &sklearnFlavor,
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion:  proto.String("v0.4.0"),
+						ProtocolVersion: &protocolV1,
+						Container: v1.Container{
+							Name: constants.InferenceServiceContainerName,
+							Resources: v1.ResourceRequirements{
+								Requests: defaultResource,
+								Limits:   defaultResource,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			scenario.spec.MLflow.Default(&config)
+			if !g.Expect(scenario.spec).To(gomega.Equal(scenario.expected)) {
+				t.Errorf("got %v, want %v", scenario.spec, scenario.expected)
+			}
+		})
+	}
+}
+
+func TestCreateMLflowModelServingContainer(t *testing.T) {
+	var requestedResource = v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			"cpu": resource.MustParse("100m"),
+		},
+		Requests: v1.ResourceList{
+			"cpu": resource.MustParse("90m"),
+		},
+	}
+	var config = InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			MLflow: PredictorConfig{
+				ContainerImage:      "seldonio/mlserver",
+				DefaultImageVersion: "0.2.1",
+			},
+			SKlearn: PredictorConfig{
+				ContainerImage:      "sklearnserver",
+				DefaultImageVersion: "v0.4.0",
+			},
+		},
+	}
+	sklearnFlavor := MLflowSKLearnFlavor
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		isvc                  InferenceService
+		expectedContainerSpec *v1.Container
+	}{
+		"PyFuncFlavor": {
+			isvc: InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mlflow",
+				},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						MLflow: &MLflowSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI: proto.String("gs://someUri"),
+								Container: v1.Container{
+									Resources: requestedResource,
+									Env: []v1.EnvVar{
+										{Name: MLServerGRPCPortEnvKey, Value: "8081"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "seldonio/mlserver:0.2.1",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args:      []string{"mlserver", "start", "/mnt/models"},
+				Env: []v1.EnvVar{
+					{Name: MLServerGRPCPortEnvKey, Value: "8081"},
+					{Name: MLServerHTTPPortEnvKey, Value: "8080"},
+					{Name: MLServerModelNameEnvKey, Value: "someName"},
+					{Name: MLServerModelURIEnvKey, Value: "/mnt/models"},
+					{Name: MLServerModelImplementationEnvKey, Value: MLServerMLflowRuntime},
+				},
+			},
+		},
+		"SKLearnFlavor": {
+			isvc: InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mlflow",
+				},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						MLflow: &MLflowSpec{
+							Flavor: &sklearnFlavor,
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI: proto.String("gs://someUri"),
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "sklearnserver:v0.4.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args: []string{
+					"--model_name=someName",
+					"--model_dir=/mnt/models",
+					"--http_port=8080",
+				},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			predictor := scenario.isvc.Spec.Predictor.GetImplementation()
+			predictor.Default(&config)
+			res := predictor.GetContainer(metav1.ObjectMeta{Name: "someName"}, &scenario.isvc.Spec.Predictor.ComponentExtensionSpec, &config)
+			if !g.Expect(res).To(gomega.Equal(scenario.expectedContainerSpec)) {
+				t.Errorf("got %q, want %q", res, scenario.expectedContainerSpec)
+			}
+			g.Expect(scenario.isvc.Spec.Predictor.GetProtocol()).To(gomega.Equal(*scenario.isvc.Spec.Predictor.MLflow.ProtocolVersion))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	MissingModelFormatError       = "The model format name must be specified."
+	RuntimeWithoutContainersError = "The serving runtime does not define any container."
+)
+
+// ModelFormat identifies the format of a model
+type ModelFormat struct {
+	// Name of the model format, e.g. "sklearn", "tensorflow" or "onnx"
+	Name string `json:"name"`
+	// Version of the model format
+	// +optional
+	Version *string `json:"version,omitempty"`
+}
+
+// ModelPredictorSpec defines a predictor served by a ServingRuntime or ClusterServingRuntime supporting the model format.
+type ModelPredictorSpec struct {
+	// Format of the model
+	ModelFormat ModelFormat `json:"modelFormat"`
+	// Name of the ServingRuntime or ClusterServingRuntime serving the model, a namespace runtime takes precedence over
+	// a cluster runtime with the same name. When not set the runtime is selected from the model format.
+	// +optional
+	Runtime *string `json:"runtime,omitempty"`
+	// Contains fields shared across all predictors, the container fields override the runtime container
+	PredictorExtensionSpec `json:",inline"`
+}
+
+var _ ComponentImplementation = &ModelPredictorSpec{}
+
+// runtimePlaceholders are the values of the placeholders of the runtime containers
+type runtimePlaceholders struct {
+	Name     string
+	ModelDir string
+	HTTPPort string
+}
+
+// Validate returns an error if invalid
+func (m *ModelPredictorSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateModelFormat(m.ModelFormat),
+		validateStorageURI(m.GetStorageUri()),
+	})
+}
+
+func validateModelFormat(format ModelFormat) error {
+	if format.Name == "" {
+		return fmt.Errorf(MissingModelFormatError)
+	}
+	return nil
+}
+
+// Default sets defaults on the resource, the resource defaults are set once merged with the runtime container
+func (m *ModelPredictorSpec) Default(config *InferenceServicesConfig) {
+	m.Container.Name = constants.InferenceServiceContainerName
+}
+
+// GetContainer returns the container overrides of the predictor, the predictor reconciler merges them into the
+// container of the selected runtime with GetRuntimeContainer.
+func (m *ModelPredictorSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	m.Container.Name = constants.InferenceServiceContainerName
+	return &m.Container
+}
+
+// GetRuntimeContainer merges the container overrides of the predictor into the model server container of the runtime
+func (m *ModelPredictorSpec) GetRuntimeContainer(metadata metav1.ObjectMeta, runtime *ServingRuntimeSpec) (*v1.Container, error) {
+	if len(runtime.Containers) == 0 {
+		return nil, fmt.Errorf(RuntimeWithoutContainersError)
+	}
+	container := runtime.Containers[0].DeepCopy()
+	placeholders := runtimePlaceholders{
+		Name:     metadata.Name,
+		ModelDir: constants.DefaultModelLocalMountPath,
+		HTTPPort: constants.InferenceServiceDefaultHttpPort,
+	}
+	if err := replacePlaceholders(container, placeholders); err != nil {
+		return nil, err
+	}
+
+	container.Name = constants.InferenceServiceContainerName
+	if m.Image != "" {
+		container.Image = m.Image
+	} else if m.RuntimeVersion != nil {
+		container.Image = withImageTag(container.Image, *m.RuntimeVersion)
+	}
+	if len(m.Command) != 0 {
+		container.Command = m.Command
+	}
+	container.Args = append(container.Args, m.Args...)
+	container.Env = mergeEnvVars(container.Env, m.Env)
+	container.EnvFrom = append(container.EnvFrom, m.EnvFrom...)
+	container.VolumeMounts = append(container.VolumeMounts, m.VolumeMounts...)
+	if len(m.Ports) != 0 {
+		container.Ports = m.Ports
+	}
+	if m.LivenessProbe != nil {
+		container.LivenessProbe = m.LivenessProbe
+	}
+	if m.ReadinessProbe != nil {
+		container.ReadinessProbe = m.ReadinessProbe
+	}
+	container.Resources = mergeResourceRequirements(container.Resources, m.Resources)
+	setResourceRequirementDefaults(&container.Resources)
+	return container, nil
+}
+
+func (m *ModelPredictorSpec) GetStorageUri() *string {
+	return m.StorageURI
+}
+
+// replacePlaceholders renders the placeholders of the command, args and env values of the container
+func replacePlaceholders(container *v1.Container, placeholders runtimePlaceholders) error {
+	render := func(value string) (string, error) {
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		tmpl, err := template.New("runtime").Option("missingkey=error").Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid placeholder in %q: %v", value, err)
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, placeholders); err != nil {
+			return "", fmt.Errorf("invalid placeholder in %q: %v", value, err)
+		}
+		return rendered.String(), nil
+	}
+	var err error
+	for i := range container.Command {
+		if container.Command[i], err = render(container.Command[i]); err != nil {
+			return err
+		}
+	}
+	for i := range container.Args {
+		if container.Args[i], err = render(container.Args[i]); err != nil {
+			return err
+		}
+	}
+	for i := range container.Env {
+		if container.Env[i].Value, err = render(container.Env[i].Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withImageTag replaces the tag of the image
+func withImageTag(image string, tag string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
+
+// mergeEnvVars overrides the runtime env vars with the predictor env vars of the same name
+func mergeEnvVars(runtimeEnv []v1.EnvVar, predictorEnv []v1.EnvVar) []v1.EnvVar {
+	merged := append([]v1.EnvVar{}, runtimeEnv...)
+	for _, env := range predictorEnv {
+		found := false
+		for i := range merged {
+			if merged[i].Name == env.Name {
+				merged[i] = env
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, env)
+		}
+	}
+	return merged
+}
+
+// mergeResourceRequirements overrides the runtime requests and limits with the predictor ones
+func mergeResourceRequirements(runtime v1.ResourceRequirements, predictor v1.ResourceRequirements) v1.ResourceRequirements {
+	merged := *runtime.DeepCopy()
+	if len(predictor.Requests) != 0 && merged.Requests == nil {
+		merged.Requests = v1.ResourceList{}
+	}
+	for name, quantity := range predictor.Requests {
+		merged.Requests[name] = quantity
+	}
+	if len(predictor.Limits) != 0 && merged.Limits == nil {
+		merged.Limits = v1.ResourceList{}
+	}
+	for name, quantity := range predictor.Limits {
+		merged.Limits[name] = quantity
+	}
+	return merged
+}
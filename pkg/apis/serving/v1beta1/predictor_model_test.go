@@ -0,0 +1,210 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestModelPredictorValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"ValidModelFormat": {
+			spec: PredictorSpec{
+				Model: &ModelPredictorSpec{
+					ModelFormat: ModelFormat{Name: "sklearn"},
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/sklearn"),
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"MissingModelFormat": {
+			spec: PredictorSpec{
+				Model: &ModelPredictorSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/sklearn"),
+					},
+				},
+			},
+			matcher: gomega.MatchError(MissingModelFormatError),
+		},
+		"InvalidStorageUri": {
+			spec: PredictorSpec{
+				Model: &ModelPredictorSpec{
+					ModelFormat: ModelFormat{Name: "sklearn"},
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("hdfs://modelzoo"),
+					},
+				},
+			},
+			matcher: gomega.Not(gomega.BeNil()),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := scenario.spec.Model.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestModelPredictorGetRuntimeContainer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defaultResource = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	runtime := ServingRuntimeSpec{
+		SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+		Containers: []v1.Container{
+			{
+				Name:  "sklearn",
+				Image: "kfserving/sklearnserver:v0.4.0",
+				Args: []string{
+					"--model_name={{.Name}}",
+					"--model_dir={{.ModelDir}}",
+					"--http_port={{.HTTPPort}}",
+				},
+				Env: []v1.EnvVar{
+					{Name: "WORKERS", Value: "1"},
+				},
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+		},
+	}
+	scenarios := map[string]struct {
+		spec     ModelPredictorSpec
+		runtime  ServingRuntimeSpec
+		expected *v1.Container
+		matcher  types.GomegaMatcher
+	}{
+		"RuntimeContainer": {
+			spec: ModelPredictorSpec{
+				ModelFormat: ModelFormat{Name: "sklearn"},
+			},
+			runtime: runtime,
+			expected: &v1.Container{
+				Name:  constants.InferenceServiceContainerName,
+				Image: "kfserving/sklearnserver:v0.4.0",
+				Args: []string{
+					"--model_name=someName",
+					"--model_dir=/mnt/models",
+					"--http_port=8080",
+				},
+				Env: []v1.EnvVar{
+					{Name: "WORKERS", Value: "1"},
+				},
+				Resources: v1.ResourceRequirements{
+					Requests: defaultResource,
+					Limits: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("2"),
+						v1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"PredictorOverrides": {
+			spec: ModelPredictorSpec{
+				ModelFormat: ModelFormat{Name: "sklearn"},
+				PredictorExtensionSpec: PredictorExtensionSpec{
+					RuntimeVersion: proto.String("v0.5.0"),
+					Container: v1.Container{
+						Args: []string{"--enable_docs_url=true"},
+						Env: []v1.EnvVar{
+							{Name: "WORKERS", Value: "4"},
+							{Name: "LOG_LEVEL", Value: "debug"},
+						},
+						EnvFrom: []v1.EnvFromSource{
+							{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}}},
+						},
+						Resources: v1.ResourceRequirements{
+							Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+						},
+					},
+				},
+			},
+			runtime: runtime,
+			expected: &v1.Container{
+				Name:  constants.InferenceServiceContainerName,
+				Image: "kfserving/sklearnserver:v0.5.0",
+				Args: []string{
+					"--model_name=someName",
+					"--model_dir=/mnt/models",
+					"--http_port=8080",
+					"--enable_docs_url=true",
+				},
+				Env: []v1.EnvVar{
+					{Name: "WORKERS", Value: "4"},
+					{Name: "LOG_LEVEL", Value: "debug"},
+				},
+				EnvFrom: []v1.EnvFromSource{
+					{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}}},
+				},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("4"),
+						v1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+					Limits: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("4"),
+						v1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"RuntimeWithoutContainers": {
+			spec: ModelPredictorSpec{
+				ModelFormat: ModelFormat{Name: "sklearn"},
+			},
+			runtime: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn"}},
+			},
+			matcher: gomega.MatchError(RuntimeWithoutContainersError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res, err := scenario.spec.GetRuntimeContainer(metav1.ObjectMeta{Name: "someName"}, &scenario.runtime)
+			g.Expect(err).To(scenario.matcher)
+			if scenario.expected != nil && !g.Expect(res).To(gomega.Equal(scenario.expected)) {
+				t.Errorf("got %v, want %v", res, scenario.expected)
+			}
+		})
+	}
+}
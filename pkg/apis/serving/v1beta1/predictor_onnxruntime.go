@@ -63,6 +63,9 @@ func (o *ONNXRuntimeSpec) GetContainer(metadata metav1.ObjectMeta, extensions *C
 		fmt.Sprintf("%s=%s", "--http_port", ONNXServingRestPort),
 		fmt.Sprintf("%s=%s", "--grpc_port", ONNXServingGRPCPort),
 	}
+	if threads := o.getThreads(); threads != nil {
+		arguments = append(arguments, fmt.Sprintf("%s=%d", "--num_http_threads", *threads))
+	}
 
 	if o.Container.Image == "" {
 		o.Container.Image = config.Predictors.ONNX.ContainerImage + ":" + *o.RuntimeVersion
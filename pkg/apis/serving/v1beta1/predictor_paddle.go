@@ -0,0 +1,94 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	PaddleServingGPUSuffix          = "-gpu"
+	InvalidPaddleRuntimeIncludesGPU = "Paddle RuntimeVersion is not GPU enabled but GPU resources are requested. "
+	InvalidPaddleRuntimeExcludesGPU = "Paddle RuntimeVersion is GPU enabled but GPU resources are not requested. "
+)
+
+// PaddleSpec defines arguments for configuring PaddlePaddle model serving.
+type PaddleSpec struct {
+	// Contains fields shared across all predictors
+	PredictorExtensionSpec `json:",inline"`
+}
+
+var _ ComponentImplementation = &PaddleSpec{}
+
+// Validate returns an error if invalid
+func (p *PaddleSpec) Validate() error {
+	runtimeVersion := ""
+	if p.RuntimeVersion != nil {
+		runtimeVersion = *p.RuntimeVersion
+	}
+	if utils.IsGPUEnabled(p.Resources) && !strings.Contains(runtimeVersion, PaddleServingGPUSuffix) {
+		return fmt.Errorf(InvalidPaddleRuntimeIncludesGPU)
+	}
+
+	if !utils.IsGPUEnabled(p.Resources) && strings.Contains(runtimeVersion, PaddleServingGPUSuffix) {
+		return fmt.Errorf(InvalidPaddleRuntimeExcludesGPU)
+	}
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(p.GetStorageUri()),
+	})
+}
+
+// Default sets defaults on the resource
+func (p *PaddleSpec) Default(config *InferenceServicesConfig) {
+	p.Container.Name = constants.InferenceServiceContainerName
+	if p.RuntimeVersion == nil {
+		if utils.IsGPUEnabled(p.Resources) {
+			p.RuntimeVersion = proto.String(config.Predictors.Paddle.DefaultGpuImageVersion)
+		} else {
+			p.RuntimeVersion = proto.String(config.Predictors.Paddle.DefaultImageVersion)
+		}
+	}
+	setResourceRequirementDefaults(&p.Resources)
+}
+
+// GetContainer transforms the resource into a container spec
+func (p *PaddleSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	arguments := []string{
+		fmt.Sprintf("%s=%s", constants.ArgumentModelName, metadata.Name),
+		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
+		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
+	}
+	arguments = append(arguments, p.workersArguments(extensions)...)
+	arguments = append(arguments, p.loadPolicyArguments()...)
+	if p.Container.Image == "" {
+		p.Container.Image = config.Predictors.Paddle.ContainerImage + ":" + *p.RuntimeVersion
+	}
+	p.Container.Name = constants.InferenceServiceContainerName
+	p.Container.Args = arguments
+	return &p.Container
+}
+
+func (p *PaddleSpec) GetStorageUri() *string {
+	return p.StorageURI
+}
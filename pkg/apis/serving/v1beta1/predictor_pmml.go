@@ -0,0 +1,113 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	InvalidPMMLModelFileError = "PMML storageUri must point to a model directory or a .pmml model file, got %s."
+	InvalidPMMLProtocolError  = "PMML server only supports protocol version v1."
+	// PMMLModelFileExtension is the extension of the model file loaded by the PMML server
+	PMMLModelFileExtension = ".pmml"
+)
+
+// PMMLSpec defines arguments for configuring PMML model serving.
+type PMMLSpec struct {
+	// Contains fields shared across all predictors
+	PredictorExtensionSpec `json:",inline"`
+}
+
+var _ ComponentImplementation = &PMMLSpec{}
+
+// Validate returns an error if invalid
+func (p *PMMLSpec) Validate() error {
+	return utils.FirstNonNilError([]error{
+		validateStorageURI(p.GetStorageUri()),
+		validatePMMLModelFile(p.GetStorageUri()),
+		validatePMMLProtocol(p.ProtocolVersion),
+	})
+}
+
+// Default sets defaults on the resource
+func (p *PMMLSpec) Default(config *InferenceServicesConfig) {
+	p.Container.Name = constants.InferenceServiceContainerName
+	if p.RuntimeVersion == nil {
+		p.RuntimeVersion = proto.String(config.Predictors.PMML.DefaultImageVersion)
+	}
+	if p.ProtocolVersion == nil {
+		protocol := constants.ProtocolV1
+		p.ProtocolVersion = &protocol
+	}
+	setResourceRequirementDefaults(&p.Resources)
+}
+
+// GetContainer transforms the resource into a container spec
+func (p *PMMLSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	arguments := []string{
+		fmt.Sprintf("%s=%s", constants.ArgumentModelName, metadata.Name),
+		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
+		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
+	}
+	// The PMML model is evaluated by a JVM which is not safe to fork, the server runs a single worker
+	arguments = append(arguments, p.loadPolicyArguments()...)
+	if p.Container.Image == "" {
+		p.Container.Image = config.Predictors.PMML.ContainerImage + ":" + *p.RuntimeVersion
+	}
+	p.Container.Name = constants.InferenceServiceContainerName
+	p.Container.Args = arguments
+	return &p.Container
+}
+
+func (p *PMMLSpec) GetStorageUri() *string {
+	return p.StorageURI
+}
+
+// validatePMMLModelFile accepts model directories and .pmml model files, the server loads the single .pmml file
+// found in the model directory
+func validatePMMLModelFile(storageURI *string) error {
+	if storageURI == nil {
+		return nil
+	}
+	uri, err := url.Parse(*storageURI)
+	if err != nil {
+		return fmt.Errorf(InvalidPMMLModelFileError, *storageURI)
+	}
+	// the bucket is part of the host so only the path is checked
+	ext := path.Ext(strings.TrimSuffix(uri.Path, "/"))
+	if ext == "" || ext == PMMLModelFileExtension {
+		return nil
+	}
+	return fmt.Errorf(InvalidPMMLModelFileError, *storageURI)
+}
+
+func validatePMMLProtocol(protocol *constants.InferenceServiceProtocol) error {
+	if protocol == nil || *protocol == constants.ProtocolV1 {
+		return nil
+	}
+	return fmt.Errorf(InvalidPMMLProtocolError)
+}
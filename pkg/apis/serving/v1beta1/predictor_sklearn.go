@@ -18,7 +18,6 @@ package v1beta1
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/constants"
@@ -58,9 +57,8 @@ func (k *SKLearnSpec) GetContainer(metadata metav1.ObjectMeta, extensions *Compo
 		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
 		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
 	}
-	if extensions.ContainerConcurrency != nil {
-		arguments = append(arguments, fmt.Sprintf("%s=%s", constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10)))
-	}
+	arguments = append(arguments, k.workersArguments(extensions)...)
+	arguments = append(arguments, k.loadPolicyArguments()...)
 	if k.Container.Image == "" {
 		k.Container.Image = config.Predictors.SKlearn.ContainerImage + ":" + *k.RuntimeVersion
 	}
@@ -33,8 +33,8 @@ var (
 	TensorflowServingRestPort            = "8080"
 	TensorflowServingGPUSuffix           = "-gpu"
 	InvalidTensorflowRuntimeVersionError = "Tensorflow RuntimeVersion must be one of %s"
-	InvalidTensorflowRuntimeIncludesGPU  = "Tensorflow RuntimeVersion is not GPU enabled but GPU resources are requested. " + InvalidTensorflowRuntimeVersionError
-	InvalidTensorflowRuntimeExcludesGPU  = "Tensorflow RuntimeVersion is GPU enabled but GPU resources are not requested. " + InvalidTensorflowRuntimeVersionError
+	InvalidTensorflowRuntimeIncludesGPU  = "Tensorflow RuntimeVersion is not GPU enabled but GPU resources are requested. Use a RuntimeVersion with the -gpu suffix."
+	InvalidTensorflowRuntimeExcludesGPU  = "Tensorflow RuntimeVersion is GPU enabled but GPU resources are not requested. Request nvidia.com/gpu resources or use a RuntimeVersion without the -gpu suffix."
 )
 
 // TFServingSpec defines arguments for configuring Tensorflow model serving.
@@ -89,6 +89,9 @@ func (t *TFServingSpec) GetContainer(metadata metav1.ObjectMeta, extensions *Com
 		fmt.Sprintf("%s=%s", "--model_name", metadata.Name),
 		fmt.Sprintf("%s=%s", "--model_base_path", constants.DefaultModelLocalMountPath),
 	}
+	if threads := t.getThreads(); threads != nil {
+		arguments = append(arguments, fmt.Sprintf("%s=%d", "--tensorflow_intra_op_parallelism", *threads))
+	}
 	if t.Container.Image == "" {
 		t.Container.Image = config.Predictors.Tensorflow.ContainerImage + ":" + *t.RuntimeVersion
 	}
@@ -21,38 +21,70 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	DefaultPyTorchModelClassName     = "PyTorchModel"
-	PyTorchServingGPUSuffix          = "-gpu"
-	InvalidPyTorchRuntimeIncludesGPU = "PyTorch RuntimeVersion is not GPU enabled but GPU resources are requested. "
-	InvalidPyTorchRuntimeExcludesGPU = "PyTorch RuntimeVersion is GPU enabled but GPU resources are not requested. "
+	PyTorchServingGPUSuffix           = "-gpu"
+	InvalidPyTorchRuntimeIncludesGPU  = "PyTorch RuntimeVersion is not GPU enabled but GPU resources are requested. "
+	InvalidPyTorchRuntimeExcludesGPU  = "PyTorch RuntimeVersion is GPU enabled but GPU resources are not requested. "
+	InvalidTorchServeProtocolError    = "TorchServe only supports protocol versions v1 and v2."
+	InvalidTorchServeAnnotationFormat = "Annotation %s must be a positive integer, got %q."
 )
 
+// TorchServe model store layout, the storage initializer moves the model archives into place
+var (
+	TorchServeModelStorePath = constants.DefaultModelLocalMountPath + "/model-store"
+	TorchServeConfigPath     = constants.DefaultModelLocalMountPath + "/config/config.properties"
+)
+
+// Environment variables read by TorchServe, the generated config.properties enables envvars config
+const (
+	ProtocolVersionEnvKey           = "PROTOCOL_VERSION"
+	TorchServeServiceEnvelopeEnvKey = "TS_SERVICE_ENVELOPE"
+	TorchServeWorkersEnvKey         = "TS_DEFAULT_WORKERS_PER_MODEL"
+	TorchServeJobQueueSizeEnvKey    = "TS_JOB_QUEUE_SIZE"
+	TorchServeNumberOfGPUEnvKey     = "TS_NUMBER_OF_GPU"
+)
+
+// TorchServe service envelopes translating the KFServing protocols to the TorchServe API
+var torchServeServiceEnvelopes = map[constants.InferenceServiceProtocol]string{
+	constants.ProtocolV1: "kfserving",
+	constants.ProtocolV2: "kfservingv2",
+}
+
 // TorchServeSpec defines arguments for configuring PyTorch model serving.
 type TorchServeSpec struct {
-	// Defaults PyTorch model class name to 'PyTorchModel'
+	// The TorchServe handler used to archive a serialized model (.pt, .pth) without model archive (.mar),
+	// TorchServe loads the handler of the model archives.
 	ModelClassName string `json:"modelClassName,omitempty"`
 	// Contains fields shared across all predictors
 	PredictorExtensionSpec `json:",inline"`
 }
 
+var _ ComponentImplementation = &TorchServeSpec{}
+
 // Validate returns an error if invalid
 func (t *TorchServeSpec) Validate() error {
-	if utils.IsGPUEnabled(t.Resources) && !strings.Contains(*t.RuntimeVersion, PyTorchServingGPUSuffix) {
+	runtimeVersion := ""
+	if t.RuntimeVersion != nil {
+		runtimeVersion = *t.RuntimeVersion
+	}
+	if utils.IsGPUEnabled(t.Resources) && !strings.Contains(runtimeVersion, PyTorchServingGPUSuffix) {
 		return fmt.Errorf(InvalidPyTorchRuntimeIncludesGPU)
 	}
 
-	if !utils.IsGPUEnabled(t.Resources) && strings.Contains(*t.RuntimeVersion, PyTorchServingGPUSuffix) {
+	if !utils.IsGPUEnabled(t.Resources) && strings.Contains(runtimeVersion, PyTorchServingGPUSuffix) {
 		return fmt.Errorf(InvalidPyTorchRuntimeExcludesGPU)
 	}
 	return utils.FirstNonNilError([]error{
 		validateStorageURI(t.GetStorageUri()),
+		validateTorchServeProtocol(t.ProtocolVersion),
 	})
 }
 
@@ -61,13 +93,14 @@ func (t *TorchServeSpec) Default(config *InferenceServicesConfig) {
 	t.Container.Name = constants.InferenceServiceContainerName
 	if t.RuntimeVersion == nil {
 		if utils.IsGPUEnabled(t.Resources) {
-			*t.RuntimeVersion = config.Predictors.PyTorch.DefaultGpuImageVersion
+			t.RuntimeVersion = proto.String(config.Predictors.TorchServe.DefaultGpuImageVersion)
 		} else {
-			*t.RuntimeVersion = config.Predictors.PyTorch.DefaultImageVersion
+			t.RuntimeVersion = proto.String(config.Predictors.TorchServe.DefaultImageVersion)
 		}
 	}
-	if t.ModelClassName == "" {
-		t.ModelClassName = DefaultPyTorchModelClassName
+	if t.ProtocolVersion == nil {
+		protocol := constants.ProtocolV1
+		t.ProtocolVersion = &protocol
 	}
 	setResourceRequirementDefaults(&t.Resources)
 }
@@ -75,24 +108,88 @@ func (t *TorchServeSpec) Default(config *InferenceServicesConfig) {
 // GetContainers transforms the resource into a container spec
 func (t *TorchServeSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
 	arguments := []string{
-		fmt.Sprintf("%s=%s", constants.ArgumentModelName, metadata.Name),
-		fmt.Sprintf("%s=%s", constants.ArgumentModelClassName, t.ModelClassName),
-		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
-		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
+		"torchserve",
+		"--start",
+		fmt.Sprintf("%s=%s", "--model-store", TorchServeModelStorePath),
+		fmt.Sprintf("%s=%s", "--ts-config", TorchServeConfigPath),
+	}
+	protocol := constants.ProtocolV1
+	if t.ProtocolVersion != nil {
+		protocol = *t.ProtocolVersion
+	}
+	envs := []v1.EnvVar{
+		{Name: ProtocolVersionEnvKey, Value: string(protocol)},
+		{Name: TorchServeServiceEnvelopeEnvKey, Value: torchServeServiceEnvelopes[protocol]},
+	}
+	if workers := t.getWorkers(); workers != nil {
+		envs = append(envs, v1.EnvVar{Name: TorchServeWorkersEnvKey, Value: strconv.Itoa(int(*workers))})
+	} else if workers, ok := metadata.Annotations[constants.TorchServeWorkersAnnotationKey]; ok {
+		envs = append(envs, v1.EnvVar{Name: TorchServeWorkersEnvKey, Value: workers})
+	} else if extensions.ContainerConcurrency != nil && *extensions.ContainerConcurrency != 0 {
+		envs = append(envs, v1.EnvVar{Name: TorchServeWorkersEnvKey, Value: strconv.FormatInt(*extensions.ContainerConcurrency, 10)})
+	}
+	if queueSize, ok := metadata.Annotations[constants.TorchServeJobQueueSizeAnnotationKey]; ok {
+		envs = append(envs, v1.EnvVar{Name: TorchServeJobQueueSizeEnvKey, Value: queueSize})
 	}
 	if utils.IsGPUEnabled(t.Resources) {
-		arguments = append(arguments, fmt.Sprintf("%s=%s", constants.ArgumentWorkers, "1"))
-	} else if extensions.ContainerConcurrency != nil {
-		arguments = append(arguments, fmt.Sprintf("%s=%s", constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10)))
+		// the GPUs are whole GPUs, MIG instances or time-sliced GPUs
+		gpus := resource.Quantity{}
+		for name, quantity := range t.Resources.Limits {
+			if utils.IsGPUResource(name) {
+				gpus.Add(quantity)
+			}
+		}
+		envs = append(envs, v1.EnvVar{Name: TorchServeNumberOfGPUEnvKey, Value: gpus.String()})
 	}
-	if t.Container.Image == "" {
-		t.Container.Image = config.Predictors.PyTorch.ContainerImage + ":" + *t.RuntimeVersion
+	// The container is built on a copy so the spec is left unchanged
+	container := t.Container.DeepCopy()
+	// environment variables set by the user take precedence
+	for _, env := range envs {
+		if !hasEnvVar(container.Env, env.Name) {
+			container.Env = append(container.Env, env)
+		}
 	}
-	t.Name = constants.InferenceServiceContainerName
-	t.Args = arguments
-	return &t.Container
+	if container.Image == "" {
+		container.Image = config.Predictors.TorchServe.ContainerImage + ":" + *t.RuntimeVersion
+	}
+	container.Name = constants.InferenceServiceContainerName
+	container.Args = arguments
+	return container
 }
 
 func (t *TorchServeSpec) GetStorageUri() *string {
 	return t.StorageURI
 }
+
+func validateTorchServeProtocol(protocol *constants.InferenceServiceProtocol) error {
+	if protocol == nil {
+		return nil
+	}
+	if _, ok := torchServeServiceEnvelopes[*protocol]; !ok {
+		return fmt.Errorf(InvalidTorchServeProtocolError)
+	}
+	return nil
+}
+
+// validateTorchServeAnnotations checks the per model worker configuration set through annotations
+func validateTorchServeAnnotations(annotations map[string]string) error {
+	for _, key := range []string{constants.TorchServeWorkersAnnotationKey, constants.TorchServeJobQueueSizeAnnotationKey} {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return fmt.Errorf(InvalidTorchServeAnnotationFormat, key, value)
+		}
+	}
+	return nil
+}
+
+func hasEnvVar(envs []v1.EnvVar, name string) bool {
+	for _, env := range envs {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
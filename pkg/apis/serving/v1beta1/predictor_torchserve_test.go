@@ -0,0 +1,398 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTorchServeValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	protocolV3 := constants.InferenceServiceProtocol("v3")
+	scenarios := map[string]struct {
+		spec    PredictorSpec
+		matcher types.GomegaMatcher
+	}{
+		"AcceptGoodRuntimeVersion": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion: proto.String("0.3.0"),
+					},
+				},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"RejectGPURuntimeVersionWithoutGPU": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion: proto.String("0.3.0-gpu"),
+					},
+				},
+			},
+			matcher: gomega.MatchError(InvalidPyTorchRuntimeExcludesGPU),
+		},
+		"RejectGPUWithoutGPURuntimeVersion": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion: proto.String("0.3.0"),
+						Container: v1.Container{
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+							},
+						},
+					},
+				},
+			},
+			matcher: gomega.MatchError(InvalidPyTorchRuntimeIncludesGPU),
+		},
+		"RejectUnknownProtocol": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion:  proto.String("0.3.0"),
+						ProtocolVersion: &protocolV3,
+					},
+				},
+			},
+			matcher: gomega.MatchError(InvalidTorchServeProtocolError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := scenario.spec.PyTorch.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestTorchServeAnnotationValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		annotations map[string]string
+		matcher     types.GomegaMatcher
+	}{
+		"ValidWorkers": {
+			annotations: map[string]string{constants.TorchServeWorkersAnnotationKey: "4"},
+			matcher:     gomega.BeNil(),
+		},
+		"InvalidWorkers": {
+			annotations: map[string]string{constants.TorchServeWorkersAnnotationKey: "four"},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidTorchServeAnnotationFormat,
+				constants.TorchServeWorkersAnnotationKey, "four")),
+		},
+		"InvalidJobQueueSize": {
+			annotations: map[string]string{constants.TorchServeJobQueueSizeAnnotationKey: "0"},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidTorchServeAnnotationFormat,
+				constants.TorchServeJobQueueSizeAnnotationKey, "0")),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateTorchServeAnnotations(scenario.annotations)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestTorchServeDefaulter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			TorchServe: PredictorConfig{
+				ContainerImage:         "pytorch/torchserve-kfs",
+				DefaultImageVersion:    "0.3.0",
+				DefaultGpuImageVersion: "0.3.0-gpu",
+			},
+		},
+	}
+	defaultResource = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("1"),
+		v1.ResourceMemory: resource.MustParse("2Gi"),
+	}
+	protocolV1 := constants.ProtocolV1
+	gpuResource := v1.ResourceList{
+		v1.ResourceCPU:                  resource.MustParse("1"),
+		v1.ResourceMemory:               resource.MustParse("2Gi"),
+		constants.NvidiaGPUResourceType: resource.MustParse("1"),
+	}
+	scenarios := map[string]struct {
+		spec     PredictorSpec
+		expected PredictorSpec
+	}{
+		"DefaultRuntimeVersion": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{},
+				},
+			},
+			expected: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion:  proto.String("0.3.0"),
+						ProtocolVersion: &protocolV1,
+						Container: v1.Container{
+							Name: constants.InferenceServiceContainerName,
+							Resources: v1.ResourceRequirements{
+								Requests: defaultResource,
+								Limits:   defaultResource,
+							},
+						},
+					},
+				},
+			},
+		},
+		"DefaultGPURuntimeVersion": {
+			spec: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						Container: v1.Container{
+							Resources: v1.ResourceRequirements{
+								Requests: gpuResource,
+								Limits:   gpuResource,
+							},
+						},
+					},
+				},
+			},
+			expected: PredictorSpec{
+				PyTorch: &TorchServeSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						RuntimeVersion:  proto.String("0.3.0-gpu"),
+						ProtocolVersion: &protocolV1,
+						Container: v1.Container{
+							Name: constants.InferenceServiceContainerName,
+							Resources: v1.ResourceRequirements{
+								Requests: gpuResource,
+								Limits:   gpuResource,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			scenario.spec.PyTorch.Default(&config)
+			if !g.Expect(scenario.spec).To(gomega.Equal(scenario.expected)) {
+				t.Errorf("got %v, want %v", scenario.spec, scenario.expected)
+			}
+		})
+	}
+}
+
+func TestCreateTorchServeModelServingContainer(t *testing.T) {
+	var requestedResource = v1.ResourceRequirements{
+		Limits: v1.ResourceList{
+			"cpu": resource.Quantity{
+				Format: "100",
+			},
+		},
+		Requests: v1.ResourceList{
+			"cpu": resource.Quantity{
+				Format: "90",
+			},
+		},
+	}
+	var config = InferenceServicesConfig{
+		Predictors: PredictorsConfig{
+			TorchServe: PredictorConfig{
+				ContainerImage:      "pytorch/torchserve-kfs",
+				DefaultImageVersion: "0.3.0",
+			},
+		},
+	}
+	protocolV2 := constants.ProtocolV2
+	arguments := []string{
+		"torchserve",
+		"--start",
+		"--model-store=/mnt/models/model-store",
+		"--ts-config=/mnt/models/config/config.properties",
+	}
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		isvc                  InferenceService
+		annotations           map[string]string
+		expectedContainerSpec *v1.Container
+	}{
+		"ContainerSpecWithDefaultProtocol": {
+			isvc: InferenceService{
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						PyTorch: &TorchServeSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:     proto.String("gs://someUri"),
+								RuntimeVersion: proto.String("0.3.0"),
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "pytorch/torchserve-kfs:0.3.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args:      arguments,
+				Env: []v1.EnvVar{
+					{Name: ProtocolVersionEnvKey, Value: "v1"},
+					{Name: TorchServeServiceEnvelopeEnvKey, Value: "kfserving"},
+				},
+			},
+		},
+		"ContainerSpecWithV2ProtocolAndWorkers": {
+			isvc: InferenceService{
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						ComponentExtensionSpec: ComponentExtensionSpec{
+							ContainerConcurrency: proto.Int64(2),
+						},
+						PyTorch: &TorchServeSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:      proto.String("gs://someUri"),
+								RuntimeVersion:  proto.String("0.3.0"),
+								ProtocolVersion: &protocolV2,
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			annotations: map[string]string{
+				constants.TorchServeWorkersAnnotationKey:      "4",
+				constants.TorchServeJobQueueSizeAnnotationKey: "100",
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "pytorch/torchserve-kfs:0.3.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args:      arguments,
+				Env: []v1.EnvVar{
+					{Name: ProtocolVersionEnvKey, Value: "v2"},
+					{Name: TorchServeServiceEnvelopeEnvKey, Value: "kfservingv2"},
+					{Name: TorchServeWorkersEnvKey, Value: "4"},
+					{Name: TorchServeJobQueueSizeEnvKey, Value: "100"},
+				},
+			},
+		},
+		"ContainerSpecWithParallelismWorkers": {
+			isvc: InferenceService{
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						ComponentExtensionSpec: ComponentExtensionSpec{
+							ContainerConcurrency: proto.Int64(2),
+						},
+						PyTorch: &TorchServeSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:     proto.String("gs://someUri"),
+								RuntimeVersion: proto.String("0.3.0"),
+								Parallelism:    &ParallelismSpec{Workers: proto.Int32(3)},
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			annotations: map[string]string{
+				constants.TorchServeWorkersAnnotationKey: "4",
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "pytorch/torchserve-kfs:0.3.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args:      arguments,
+				Env: []v1.EnvVar{
+					{Name: ProtocolVersionEnvKey, Value: "v1"},
+					{Name: TorchServeServiceEnvelopeEnvKey, Value: "kfserving"},
+					{Name: TorchServeWorkersEnvKey, Value: "3"},
+				},
+			},
+		},
+		"ContainerSpecWithUserProvidedEnv": {
+			isvc: InferenceService{
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						ComponentExtensionSpec: ComponentExtensionSpec{
+							ContainerConcurrency: proto.Int64(2),
+						},
+						PyTorch: &TorchServeSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:     proto.String("gs://someUri"),
+								RuntimeVersion: proto.String("0.3.0"),
+								Container: v1.Container{
+									Resources: requestedResource,
+									Env: []v1.EnvVar{
+										{Name: TorchServeServiceEnvelopeEnvKey, Value: "json"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "pytorch/torchserve-kfs:0.3.0",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args:      arguments,
+				Env: []v1.EnvVar{
+					{Name: TorchServeServiceEnvelopeEnvKey, Value: "json"},
+					{Name: ProtocolVersionEnvKey, Value: "v1"},
+					{Name: TorchServeWorkersEnvKey, Value: "2"},
+				},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			spec := scenario.isvc.Spec.Predictor.DeepCopy()
+			predictor := scenario.isvc.Spec.Predictor.GetImplementation()
+			res := predictor.GetContainer(metav1.ObjectMeta{Name: "someName", Annotations: scenario.annotations},
+				&scenario.isvc.Spec.Predictor.ComponentExtensionSpec, &config)
+			if !g.Expect(res).To(gomega.Equal(scenario.expectedContainerSpec)) {
+				t.Errorf("got %q, want %q", res, scenario.expectedContainerSpec)
+			}
+			// The spec is left unchanged
+			g.Expect(scenario.isvc.Spec.Predictor).To(gomega.Equal(*spec))
+		})
+	}
+}
@@ -31,8 +31,30 @@ var (
 	TritonISRestPort = int32(8080)
 )
 
+const (
+	InvalidTritonModelControlModeError = "Triton ModelControlMode must be one of none, poll or explicit."
+)
+
+// TritonModelControlMode controls how Triton loads the models in the model repository
+type TritonModelControlMode string
+
+// TritonModelControlMode enum
+const (
+	// Load all the models at startup
+	TritonModelControlNone TritonModelControlMode = "none"
+	// Poll the model repository for changes
+	TritonModelControlPoll TritonModelControlMode = "poll"
+	// Load and unload the models through the model repository API, used with TrainedModels
+	TritonModelControlExplicit TritonModelControlMode = "explicit"
+)
+
 // TritonSpec defines arguments for configuring Triton model serving.
 type TritonSpec struct {
+	// Model control mode of the model repository, defaults to none.
+	// With explicit the agent started with --model-server-url loads and unloads the TrainedModels through the model
+	// repository API.
+	// +optional
+	ModelControlMode *TritonModelControlMode `json:"modelControlMode,omitempty"`
 	// Contains fields shared across all predictors
 	PredictorExtensionSpec `json:",inline"`
 }
@@ -43,6 +65,7 @@ var _ ComponentImplementation = &TritonSpec{}
 func (t *TritonSpec) Validate() error {
 	return utils.FirstNonNilError([]error{
 		validateStorageURI(t.GetStorageUri()),
+		validateTritonModelControlMode(t.ModelControlMode),
 	})
 }
 
@@ -65,9 +88,14 @@ func (t *TritonSpec) GetContainer(metadata metav1.ObjectMeta, extensions *Compon
 		fmt.Sprintf("%s=%s", "--allow-grpc", "true"),
 		fmt.Sprintf("%s=%s", "--allow-http", "true"),
 	}
-	if extensions.ContainerConcurrency != nil && *extensions.ContainerConcurrency != 0 {
+	if threads := t.getThreads(); threads != nil {
+		arguments = append(arguments, fmt.Sprintf("%s=%d", "--http-thread-count", *threads))
+	} else if extensions.ContainerConcurrency != nil && *extensions.ContainerConcurrency != 0 {
 		arguments = append(arguments, fmt.Sprintf("%s=%d", "--http-thread-count", *extensions.ContainerConcurrency))
 	}
+	if t.ModelControlMode != nil && *t.ModelControlMode != TritonModelControlNone {
+		arguments = append(arguments, fmt.Sprintf("%s=%s", "--model-control-mode", *t.ModelControlMode))
+	}
 	if t.Container.Image == "" {
 		t.Container.Image = config.Predictors.Triton.ContainerImage + ":" + *t.RuntimeVersion
 	}
@@ -79,3 +107,19 @@ func (t *TritonSpec) GetContainer(metadata metav1.ObjectMeta, extensions *Compon
 func (t *TritonSpec) GetStorageUri() *string {
 	return t.StorageURI
 }
+
+// GetProtocol returns v2, Triton only serves the v2 inference protocol
+func (t *TritonSpec) GetProtocol() constants.InferenceServiceProtocol {
+	return constants.ProtocolV2
+}
+
+func validateTritonModelControlMode(mode *TritonModelControlMode) error {
+	if mode == nil {
+		return nil
+	}
+	switch *mode {
+	case TritonModelControlNone, TritonModelControlPoll, TritonModelControlExplicit:
+		return nil
+	}
+	return fmt.Errorf(InvalidTritonModelControlModeError)
+}
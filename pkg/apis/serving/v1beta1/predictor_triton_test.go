@@ -31,6 +31,7 @@ import (
 
 func TestTritonValidation(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
+	invalidModelControlMode := TritonModelControlMode("manual")
 
 	scenarios := map[string]struct {
 		spec    PredictorSpec
@@ -94,6 +95,17 @@ func TestTritonValidation(t *testing.T) {
 			},
 			matcher: gomega.Not(gomega.BeNil()),
 		},
+		"InvalidModelControlMode": {
+			spec: PredictorSpec{
+				Triton: &TritonSpec{
+					ModelControlMode: &invalidModelControlMode,
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						StorageURI: proto.String("gs://modelzoo"),
+					},
+				},
+			},
+			matcher: gomega.MatchError(InvalidTritonModelControlModeError),
+		},
 	}
 
 	for name, scenario := range scenarios {
@@ -181,6 +193,7 @@ func TestTritonDefaulter(t *testing.T) {
 }
 
 func TestCreateTritonContainer(t *testing.T) {
+	explicitModelControlMode := TritonModelControlExplicit
 
 	var requestedResource = v1.ResourceRequirements{
 		Limits: v1.ResourceList{
@@ -310,6 +323,79 @@ func TestCreateTritonContainer(t *testing.T) {
 				},
 			},
 		},
+		"ContainerSpecWithParallelismThreads": {
+			isvc: InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "triton",
+				},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						ComponentExtensionSpec: ComponentExtensionSpec{
+							ContainerConcurrency: proto.Int64(4),
+						},
+						Triton: &TritonSpec{
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:     proto.String("gs://someUri"),
+								RuntimeVersion: proto.String("20.03-py3"),
+								Parallelism:    &ParallelismSpec{Threads: proto.Int32(8)},
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "tritonserver:20.03-py3",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args: []string{
+					"tritonserver",
+					"--model-store=/mnt/models",
+					"--grpc-port=9000",
+					"--http-port=8080",
+					"--allow-grpc=true",
+					"--allow-http=true",
+					"--http-thread-count=8",
+				},
+			},
+		},
+		"ContainerSpecWithExplicitModelControl": {
+			isvc: InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "triton",
+				},
+				Spec: InferenceServiceSpec{
+					Predictor: PredictorSpec{
+						Triton: &TritonSpec{
+							ModelControlMode: &explicitModelControlMode,
+							PredictorExtensionSpec: PredictorExtensionSpec{
+								StorageURI:     proto.String("gs://someUri"),
+								RuntimeVersion: proto.String("20.03-py3"),
+								Container: v1.Container{
+									Resources: requestedResource,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedContainerSpec: &v1.Container{
+				Image:     "tritonserver:20.03-py3",
+				Name:      constants.InferenceServiceContainerName,
+				Resources: requestedResource,
+				Args: []string{
+					"tritonserver",
+					"--model-store=/mnt/models",
+					"--grpc-port=9000",
+					"--http-port=8080",
+					"--allow-grpc=true",
+					"--allow-http=true",
+					"--model-control-mode=explicit",
+				},
+			},
+		},
 	}
 	for name, scenario := range scenarios {
 		t.Run(name, func(t *testing.T) {
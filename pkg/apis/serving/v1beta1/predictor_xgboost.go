@@ -54,17 +54,21 @@ func (x *XGBoostSpec) Default(config *InferenceServicesConfig) {
 
 // GetContainer transforms the resource into a container spec
 func (x *XGBoostSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	// The threads default to the CPU limit
 	cpuLimit := x.Resources.Limits.Cpu()
 	cpuLimit.RoundUp(0)
+	threads := strconv.Itoa(int(cpuLimit.Value()))
+	if x.getThreads() != nil {
+		threads = strconv.Itoa(int(*x.getThreads()))
+	}
 	arguments := []string{
 		fmt.Sprintf("%s=%s", constants.ArgumentModelName, metadata.Name),
 		fmt.Sprintf("%s=%s", constants.ArgumentModelDir, constants.DefaultModelLocalMountPath),
 		fmt.Sprintf("%s=%s", constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort),
-		fmt.Sprintf("%s=%s", "--nthread", strconv.Itoa(int(cpuLimit.Value()))),
-	}
-	if extensions.ContainerConcurrency != nil {
-		arguments = append(arguments, fmt.Sprintf("%s=%s", constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10)))
+		fmt.Sprintf("%s=%s", "--nthread", threads),
 	}
+	arguments = append(arguments, x.workersArguments(extensions)...)
+	arguments = append(arguments, x.loadPolicyArguments()...)
 	if x.Container.Image == "" {
 		x.Container.Image = config.Predictors.XGBoost.ContainerImage + ":" + *x.RuntimeVersion
 	}
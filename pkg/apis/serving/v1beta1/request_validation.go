@@ -0,0 +1,91 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Known error messages
+const (
+	MissingRequestValidationError       = "RequestValidation requires a schema or modelMetadata."
+	InvalidRequestValidationSchemaError = "RequestValidation schema is invalid: %v."
+	RequestValidationProtocolError      = "RequestValidation modelMetadata requires the v2 protocol of the predictor."
+	RequestValidationAsyncConflictError = "RequestValidation cannot be set with async, the async frontend takes the port of the predictor."
+)
+
+// RequestValidationSpec defines the validation of the requests of the predictor by a validator injected in the
+// predictor pods. The validator receives the requests on the port of the predictor and rejects the malformed requests
+// with a 400 before they reach the model server.
+type RequestValidationSpec struct {
+	// Schema of the body of the predict and infer requests, in the OpenAPI v3 subset of JSON Schema used by the
+	// validation of the CRDs
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Schema *runtime.RawExtension `json:"schema,omitempty"`
+	// ModelMetadata validates the inputs of the v2 infer requests against the model metadata of the model server:
+	// the names, the datatypes and the shapes of the inputs and the number of elements of their data
+	// +optional
+	ModelMetadata bool `json:"modelMetadata,omitempty"`
+}
+
+// ParseSchema returns the schema of the requests, nil when unset
+func (r *RequestValidationSpec) ParseSchema() (*openapi3.Schema, error) {
+	if r.Schema == nil || len(r.Schema.Raw) == 0 {
+		return nil, nil
+	}
+	schema := &openapi3.Schema{}
+	if err := json.Unmarshal(r.Schema.Raw, schema); err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// Validate returns an error if invalid
+func (r *RequestValidationSpec) Validate() error {
+	schema, err := r.ParseSchema()
+	if err != nil {
+		return fmt.Errorf(InvalidRequestValidationSchemaError, err)
+	}
+	if schema == nil && !r.ModelMetadata {
+		return fmt.Errorf(MissingRequestValidationError)
+	}
+	return nil
+}
+
+// validateRequestValidation validates the request validation of the predictor
+func validateRequestValidation(predictor *PredictorSpec) error {
+	if predictor.RequestValidation == nil {
+		return nil
+	}
+	if predictor.Async != nil {
+		return fmt.Errorf(RequestValidationAsyncConflictError)
+	}
+	if predictor.RequestValidation.ModelMetadata && predictor.GetProtocol() != constants.ProtocolV2 {
+		return fmt.Errorf(RequestValidationProtocolError)
+	}
+	return predictor.RequestValidation.Validate()
+}
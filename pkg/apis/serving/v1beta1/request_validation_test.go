@@ -0,0 +1,86 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRequestValidationValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	v2 := constants.ProtocolV2
+	scenarios := map[string]struct {
+		predictor *PredictorSpec
+		matcher   types.GomegaMatcher
+	}{
+		"NoRequestValidation": {
+			predictor: &PredictorSpec{},
+			matcher:   gomega.BeNil(),
+		},
+		"Schema": {
+			predictor: &PredictorSpec{RequestValidation: &RequestValidationSpec{
+				Schema: &runtime.RawExtension{Raw: []byte(`{"type":"object","required":["instances"]}`)},
+			}},
+			matcher: gomega.BeNil(),
+		},
+		"ModelMetadata": {
+			predictor: &PredictorSpec{
+				SKLearn:           &SKLearnSpec{PredictorExtensionSpec: PredictorExtensionSpec{ProtocolVersion: &v2}},
+				RequestValidation: &RequestValidationSpec{ModelMetadata: true},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"ModelMetadataV1": {
+			predictor: &PredictorSpec{
+				SKLearn:           &SKLearnSpec{},
+				RequestValidation: &RequestValidationSpec{ModelMetadata: true},
+			},
+			matcher: gomega.MatchError(RequestValidationProtocolError),
+		},
+		"Empty": {
+			predictor: &PredictorSpec{RequestValidation: &RequestValidationSpec{}},
+			matcher:   gomega.MatchError(MissingRequestValidationError),
+		},
+		"InvalidSchema": {
+			predictor: &PredictorSpec{RequestValidation: &RequestValidationSpec{
+				Schema: &runtime.RawExtension{Raw: []byte(`{"type":"tensor"}`)},
+			}},
+			matcher: gomega.MatchError(gomega.HavePrefix("RequestValidation schema is invalid")),
+		},
+		"WithAsync": {
+			predictor: &PredictorSpec{
+				Async:             &AsyncSpec{},
+				RequestValidation: &RequestValidationSpec{ModelMetadata: true},
+			},
+			matcher: gomega.MatchError(RequestValidationAsyncConflictError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateRequestValidation(scenario.predictor)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
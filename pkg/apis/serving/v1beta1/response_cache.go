@@ -0,0 +1,139 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+)
+
+// Known error messages
+const (
+	InvalidResponseCacheBackendError    = "Invalid responseCache backend %q, must be memory or redis."
+	ResponseCacheRedisAddressError      = "ResponseCache redisAddress is required by the redis backend."
+	ResponseCacheTTLLowerBoundError     = "ResponseCache ttlSeconds cannot be less than 1."
+	ResponseCacheMaxEntriesError        = "ResponseCache maxEntries cannot be less than 1."
+	ResponseCacheMaxEntriesBackendError = "ResponseCache maxEntries only applies to the memory backend, the redis backend is bounded by the maxmemory policy of the Redis server."
+	ResponseCacheAsyncConflictError     = "ResponseCache cannot be set with async, the async frontend takes the port of the predictor."
+	ResponseCacheProtocolError          = "ResponseCache cannot be set with the %s protocol of the predictor."
+)
+
+// Default response cache values
+const (
+	DefaultResponseCacheTTLSeconds = 300
+	DefaultResponseCacheMaxEntries = 1000
+)
+
+// ResponseCacheBackend is the store of the cached responses
+// +kubebuilder:validation:Enum=memory;redis
+type ResponseCacheBackend string
+
+// ResponseCacheBackend Enum
+const (
+	// ResponseCacheMemoryBackend keeps the responses in the memory of each replica
+	ResponseCacheMemoryBackend ResponseCacheBackend = "memory"
+	// ResponseCacheRedisBackend keeps the responses in Redis keys shared by the replicas
+	ResponseCacheRedisBackend ResponseCacheBackend = "redis"
+)
+
+// ResponseCacheSpec defines the cache of the responses of a component, injected in the component pods. The cache
+// receives the requests on the port of the component and answers the POST requests identical to a previous request,
+// the same path and the same body, with the response of the previous request until it expires. Only the 200
+// responses are cached. The requests with a Cache-Control: no-cache header are sent to the component.
+type ResponseCacheSpec struct {
+	// Backend of the cache, defaults to memory
+	// +optional
+	Backend ResponseCacheBackend `json:"backend,omitempty"`
+	// RedisAddress is the host:port of the Redis server of the redis backend
+	// +optional
+	RedisAddress string `json:"redisAddress,omitempty"`
+	// TTLSeconds is the time the responses are cached for, defaults to 300
+	// +optional
+	TTLSeconds *int `json:"ttlSeconds,omitempty"`
+	// MaxEntries is the number of responses the memory backend caches per replica, the least recently used responses
+	// are evicted first, defaults to 1000
+	// +optional
+	MaxEntries *int `json:"maxEntries,omitempty"`
+}
+
+// GetBackend returns the backend of the cache
+func (r *ResponseCacheSpec) GetBackend() ResponseCacheBackend {
+	if r.Backend == "" {
+		return ResponseCacheMemoryBackend
+	}
+	return r.Backend
+}
+
+// GetTTLSeconds returns the time the responses are cached for
+func (r *ResponseCacheSpec) GetTTLSeconds() int {
+	if r.TTLSeconds == nil {
+		return DefaultResponseCacheTTLSeconds
+	}
+	return *r.TTLSeconds
+}
+
+// GetMaxEntries returns the number of responses the memory backend caches
+func (r *ResponseCacheSpec) GetMaxEntries() int {
+	if r.MaxEntries == nil {
+		return DefaultResponseCacheMaxEntries
+	}
+	return *r.MaxEntries
+}
+
+// Validate returns an error if invalid
+func (r *ResponseCacheSpec) Validate() error {
+	switch r.GetBackend() {
+	case ResponseCacheMemoryBackend:
+	case ResponseCacheRedisBackend:
+		if r.RedisAddress == "" {
+			return fmt.Errorf(ResponseCacheRedisAddressError)
+		}
+		if r.MaxEntries != nil {
+			return fmt.Errorf(ResponseCacheMaxEntriesBackendError)
+		}
+	default:
+		return fmt.Errorf(InvalidResponseCacheBackendError, r.Backend)
+	}
+	if r.GetTTLSeconds() < 1 {
+		return fmt.Errorf(ResponseCacheTTLLowerBoundError)
+	}
+	if r.GetMaxEntries() < 1 {
+		return fmt.Errorf(ResponseCacheMaxEntriesError)
+	}
+	return nil
+}
+
+func validateResponseCache(responseCache *ResponseCacheSpec) error {
+	if responseCache == nil {
+		return nil
+	}
+	return responseCache.Validate()
+}
+
+// validatePredictorResponseCache validates the cache of the responses of the predictor, the streamed responses and the
+// websocket connections are not cached
+func validatePredictorResponseCache(predictor *PredictorSpec) error {
+	if predictor.ResponseCache == nil {
+		return nil
+	}
+	if predictor.Async != nil {
+		return fmt.Errorf(ResponseCacheAsyncConflictError)
+	}
+	if predictor.IsStreaming() || predictor.IsWebsocket() {
+		return fmt.Errorf(ResponseCacheProtocolError, predictor.Protocol)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestResponseCacheValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	zero := 0
+	entries := 10
+	scenarios := map[string]struct {
+		responseCache *ResponseCacheSpec
+		matcher       types.GomegaMatcher
+	}{
+		"NoResponseCache": {
+			responseCache: nil,
+			matcher:       gomega.BeNil(),
+		},
+		"Memory": {
+			responseCache: &ResponseCacheSpec{MaxEntries: &entries},
+			matcher:       gomega.BeNil(),
+		},
+		"Redis": {
+			responseCache: &ResponseCacheSpec{Backend: ResponseCacheRedisBackend, RedisAddress: "redis:6379"},
+			matcher:       gomega.BeNil(),
+		},
+		"InvalidBackend": {
+			responseCache: &ResponseCacheSpec{Backend: "memcached"},
+			matcher:       gomega.MatchError(fmt.Sprintf(InvalidResponseCacheBackendError, "memcached")),
+		},
+		"RedisWithoutAddress": {
+			responseCache: &ResponseCacheSpec{Backend: ResponseCacheRedisBackend},
+			matcher:       gomega.MatchError(ResponseCacheRedisAddressError),
+		},
+		"RedisWithMaxEntries": {
+			responseCache: &ResponseCacheSpec{Backend: ResponseCacheRedisBackend, RedisAddress: "redis:6379",
+				MaxEntries: &entries},
+			matcher: gomega.MatchError(ResponseCacheMaxEntriesBackendError),
+		},
+		"ZeroTTL": {
+			responseCache: &ResponseCacheSpec{TTLSeconds: &zero},
+			matcher:       gomega.MatchError(ResponseCacheTTLLowerBoundError),
+		},
+		"ZeroMaxEntries": {
+			responseCache: &ResponseCacheSpec{MaxEntries: &zero},
+			matcher:       gomega.MatchError(ResponseCacheMaxEntriesError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateResponseCache(scenario.responseCache)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestPredictorResponseCacheValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		predictor *PredictorSpec
+		matcher   types.GomegaMatcher
+	}{
+		"ResponseCache": {
+			predictor: &PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{ResponseCache: &ResponseCacheSpec{}},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"WithAsync": {
+			predictor: &PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{ResponseCache: &ResponseCacheSpec{}},
+				Async:                  &AsyncSpec{},
+			},
+			matcher: gomega.MatchError(ResponseCacheAsyncConflictError),
+		},
+		"WithStreaming": {
+			predictor: &PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{ResponseCache: &ResponseCacheSpec{}},
+				Protocol:               StreamingProtocol,
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(ResponseCacheProtocolError, StreamingProtocol)),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validatePredictorResponseCache(scenario.predictor)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"time"
+)
+
+// Known error messages
+const (
+	RolloutProgressDeadlineLowerBoundError = "Rollout progressDeadlineSeconds cannot be less than 1."
+)
+
+// DefaultRolloutProgressDeadlineSeconds is the default time a new revision of the predictor has to become ready
+const DefaultRolloutProgressDeadlineSeconds = 600
+
+// RolloutSpec configures the rollouts of the new revisions of the predictor. On each update of the predictor the
+// traffic stays on the revision serving it until the new revision has loaded its model and passed its warmup, the
+// traffic then moves at once to the new revision. Only applies to the rollouts without canaryTrafficPercent and
+// rollbackTo.
+type RolloutSpec struct {
+	// Seconds the new revision has to become ready, the rollout is aborted past the deadline and the traffic stays on
+	// the previous revision until the next update of the predictor. Defaults to 600.
+	// +optional
+	ProgressDeadlineSeconds *int `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// GetProgressDeadline returns the time a new revision has to become ready
+func (r *RolloutSpec) GetProgressDeadline() time.Duration {
+	if r == nil || r.ProgressDeadlineSeconds == nil {
+		return DefaultRolloutProgressDeadlineSeconds * time.Second
+	}
+	return time.Duration(*r.ProgressDeadlineSeconds) * time.Second
+}
+
+// Validate returns an error if invalid
+func (r *RolloutSpec) Validate() error {
+	if r.ProgressDeadlineSeconds != nil && *r.ProgressDeadlineSeconds < 1 {
+		return fmt.Errorf(RolloutProgressDeadlineLowerBoundError)
+	}
+	return nil
+}
+
+// validateRollout validates the rollout of the predictor
+func validateRollout(predictor *PredictorSpec) error {
+	if predictor.Rollout == nil {
+		return nil
+	}
+	return predictor.Rollout.Validate()
+}
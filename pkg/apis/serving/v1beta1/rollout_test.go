@@ -0,0 +1,43 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestRolloutValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := makeTestInferenceService()
+	g.Expect(validateRollout(&isvc.Spec.Predictor)).To(gomega.Succeed())
+	isvc.Spec.Predictor.Rollout = &RolloutSpec{ProgressDeadlineSeconds: GetIntReference(300)}
+	g.Expect(validateRollout(&isvc.Spec.Predictor)).To(gomega.Succeed())
+	isvc.Spec.Predictor.Rollout = &RolloutSpec{ProgressDeadlineSeconds: GetIntReference(0)}
+	g.Expect(validateRollout(&isvc.Spec.Predictor)).To(gomega.MatchError(RolloutProgressDeadlineLowerBoundError))
+}
+
+func TestRolloutProgressDeadline(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var rollout *RolloutSpec
+	g.Expect(rollout.GetProgressDeadline()).To(gomega.Equal(10 * time.Minute))
+	g.Expect((&RolloutSpec{}).GetProgressDeadline()).To(gomega.Equal(10 * time.Minute))
+	g.Expect((&RolloutSpec{ProgressDeadlineSeconds: GetIntReference(90)}).GetProgressDeadline()).
+		To(gomega.Equal(90 * time.Second))
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	ScalingSchedulesRequiredError = "Scaling requires at least one schedule."
+	InvalidScalingScheduleError   = "Scaling schedule %q is not a cron expression: %v."
+	DuplicateScalingScheduleError = "Scaling schedule %q is set more than once."
+	ScalingScheduleReplicasError  = "Scaling schedule %q: %s"
+	ScalingScheduleDurationError  = "Scaling schedule %q duration must be a positive duration."
+)
+
+// scheduleLookbacks are the increasing periods searched for the last start of a scaling window, the windows of the
+// schedules starting less often than yearly are not found
+var scheduleLookbacks = []time.Duration{time.Hour, 24 * time.Hour, 8 * 24 * time.Hour, 32 * 24 * time.Hour,
+	366 * 24 * time.Hour}
+
+// ScalingSpec configures the scheduled scaling windows of the component, e.g. to scale up ahead of the daily peak of
+// the traffic when the warmup of the replicas scaled from zero is too slow
+type ScalingSpec struct {
+	// Schedules are the scaling windows of the component. The window which started last is active, minReplicas and
+	// maxReplicas of the component apply when no window is active.
+	Schedules []ScalingSchedule `json:"schedules"`
+}
+
+// ScalingSchedule is a scaling window starting at the times of a cron schedule, the replica bounds left unset keep
+// the ones of the component
+type ScalingSchedule struct {
+	// Schedule is the cron expression of the starts of the window, e.g. "0 8 * * 1-5", in UTC unless prefixed with
+	// the time zone, e.g. "CRON_TZ=Europe/Paris 0 8 * * 1-5"
+	Schedule string `json:"schedule"`
+	// Duration of the window, e.g. 12h. The window lasts until the start of the next window when unset.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Minimum number of replicas during the window
+	// +optional
+	MinReplicas *int `json:"minReplicas,omitempty"`
+	// Maximum number of replicas during the window
+	// +optional
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+}
+
+// ActiveSchedule returns the scaling window active at the time, nil when no window is active
+func (s *ScalingSpec) ActiveSchedule(now time.Time) *ScalingSchedule {
+	var active *ScalingSchedule
+	var activeStart time.Time
+	for i := range s.Schedules {
+		schedule, err := cron.ParseStandard(s.Schedules[i].Schedule)
+		if err != nil {
+			continue
+		}
+		start := lastStart(schedule, now)
+		if !start.IsZero() && start.After(activeStart) {
+			active, activeStart = &s.Schedules[i], start
+		}
+	}
+	if active != nil && active.Duration != nil && !activeStart.Add(active.Duration.Duration).After(now) {
+		return nil
+	}
+	return active
+}
+
+// lastStart returns the last time of the schedule before the time, zero when the schedule did not start in a year
+func lastStart(schedule cron.Schedule, now time.Time) time.Time {
+	for _, lookback := range scheduleLookbacks {
+		last := time.Time{}
+		for next := schedule.Next(now.Add(-lookback)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			last = next
+		}
+		if !last.IsZero() {
+			return last
+		}
+	}
+	return time.Time{}
+}
+
+// WithScalingSchedule returns the extensions of the component with the replica bounds of the scaling window, the
+// extensions are returned unchanged when the window is not one of the component
+func (s *ComponentExtensionSpec) WithScalingSchedule(schedule string) *ComponentExtensionSpec {
+	if s.Scaling == nil || schedule == "" {
+		return s
+	}
+	for _, window := range s.Scaling.Schedules {
+		if window.Schedule != schedule {
+			continue
+		}
+		extensions := s.DeepCopy()
+		if window.MinReplicas != nil {
+			minReplicas := *window.MinReplicas
+			extensions.MinReplicas = &minReplicas
+		}
+		if window.MaxReplicas != 0 {
+			extensions.MaxReplicas = window.MaxReplicas
+		}
+		return extensions
+	}
+	return s
+}
+
+// validateScalingSchedules checks the cron expressions and the replica bounds of the scaling windows
+func validateScalingSchedules(scaling *ScalingSpec, minReplicas *int, maxReplicas int, scaleMetric *ScaleMetric) error {
+	if scaling == nil {
+		return nil
+	}
+	if len(scaling.Schedules) == 0 {
+		return fmt.Errorf(ScalingSchedulesRequiredError)
+	}
+	schedules := map[string]bool{}
+	for _, window := range scaling.Schedules {
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			return fmt.Errorf(InvalidScalingScheduleError, window.Schedule, err)
+		}
+		if schedules[window.Schedule] {
+			return fmt.Errorf(DuplicateScalingScheduleError, window.Schedule)
+		}
+		schedules[window.Schedule] = true
+		if window.Duration != nil && window.Duration.Duration <= 0 {
+			return fmt.Errorf(ScalingScheduleDurationError, window.Schedule)
+		}
+		windowMinReplicas, windowMaxReplicas := minReplicas, maxReplicas
+		if window.MinReplicas != nil {
+			windowMinReplicas = window.MinReplicas
+		}
+		if window.MaxReplicas != 0 {
+			windowMaxReplicas = window.MaxReplicas
+		}
+		if err := utils.FirstNonNilError([]error{
+			validateReplicas(windowMinReplicas, windowMaxReplicas),
+			validateScaling(scaleMetric, nil, windowMinReplicas),
+		}); err != nil {
+			return fmt.Errorf(ScalingScheduleReplicasError, window.Schedule, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestActiveSchedule(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scaling := &ScalingSpec{
+		Schedules: []ScalingSchedule{
+			// Business hours on weekdays
+			{Schedule: "0 8 * * 1-5", MinReplicas: GetIntReference(5)},
+			{Schedule: "0 20 * * 1-5", MinReplicas: GetIntReference(0)},
+			// Batch scoring on the first day of the month
+			{Schedule: "CRON_TZ=Europe/Paris 0 2 1 * *", Duration: &metav1.Duration{Duration: 3 * time.Hour},
+				MaxReplicas: 20},
+		},
+	}
+	scenarios := map[string]struct {
+		now      time.Time
+		expected *ScalingSchedule
+	}{
+		"BusinessHours": {
+			// Wednesday
+			now:      time.Date(2020, 10, 14, 9, 30, 0, 0, time.UTC),
+			expected: &scaling.Schedules[0],
+		},
+		"WindowStart": {
+			now:      time.Date(2020, 10, 14, 8, 0, 0, 0, time.UTC),
+			expected: &scaling.Schedules[0],
+		},
+		"Evening": {
+			now:      time.Date(2020, 10, 14, 21, 0, 0, 0, time.UTC),
+			expected: &scaling.Schedules[1],
+		},
+		"Weekend": {
+			// Sunday, the window started on Friday evening
+			now:      time.Date(2020, 10, 18, 12, 0, 0, 0, time.UTC),
+			expected: &scaling.Schedules[1],
+		},
+		"BatchScoring": {
+			// Thursday 1 October at 3:00 in Paris
+			now:      time.Date(2020, 10, 1, 1, 0, 0, 0, time.UTC),
+			expected: &scaling.Schedules[2],
+		},
+		"BatchScoringEnded": {
+			// The batch scoring window started after the evening window and ended at 5:00 in Paris
+			now:      time.Date(2020, 10, 1, 4, 0, 0, 0, time.UTC),
+			expected: nil,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(scaling.ActiveSchedule(scenario.now)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestWithScalingSchedule(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	extensions := &ComponentExtensionSpec{
+		MinReplicas: GetIntReference(1),
+		MaxReplicas: 3,
+		Scaling: &ScalingSpec{
+			Schedules: []ScalingSchedule{
+				{Schedule: "0 8 * * *", MinReplicas: GetIntReference(5), MaxReplicas: 10},
+				{Schedule: "0 20 * * *", MinReplicas: GetIntReference(0)},
+			},
+		},
+	}
+	withSchedule := extensions.WithScalingSchedule("0 8 * * *")
+	g.Expect(*withSchedule.MinReplicas).To(gomega.Equal(5))
+	g.Expect(withSchedule.MaxReplicas).To(gomega.Equal(10))
+	withSchedule = extensions.WithScalingSchedule("0 20 * * *")
+	g.Expect(*withSchedule.MinReplicas).To(gomega.Equal(0))
+	g.Expect(withSchedule.MaxReplicas).To(gomega.Equal(3))
+	// The spec of the component is unchanged
+	g.Expect(*extensions.MinReplicas).To(gomega.Equal(1))
+	g.Expect(extensions.WithScalingSchedule("")).To(gomega.BeIdenticalTo(extensions))
+	g.Expect(extensions.WithScalingSchedule("0 9 * * *")).To(gomega.BeIdenticalTo(extensions))
+}
+
+func TestScalingScheduleValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	cpu := MetricCPU
+	scenarios := map[string]struct {
+		spec    ComponentExtensionSpec
+		matcher types.GomegaMatcher
+	}{
+		"Valid": {
+			spec: ComponentExtensionSpec{
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{
+					{Schedule: "0 8 * * 1-5", MinReplicas: GetIntReference(3), MaxReplicas: 10},
+					{Schedule: "CRON_TZ=America/New_York @daily", Duration: &metav1.Duration{Duration: time.Hour}},
+				}},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"NoSchedules": {
+			spec:    ComponentExtensionSpec{Scaling: &ScalingSpec{}},
+			matcher: gomega.MatchError(ScalingSchedulesRequiredError),
+		},
+		"InvalidCron": {
+			spec: ComponentExtensionSpec{
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{{Schedule: "0 25 * * *"}}},
+			},
+			matcher: gomega.MatchError(gomega.HavePrefix(`Scaling schedule "0 25 * * *" is not a cron expression`)),
+		},
+		"DuplicateSchedule": {
+			spec: ComponentExtensionSpec{
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{{Schedule: "0 8 * * *"}, {Schedule: "0 8 * * *"}}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(DuplicateScalingScheduleError, "0 8 * * *")),
+		},
+		"NonPositiveDuration": {
+			spec: ComponentExtensionSpec{
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{
+					{Schedule: "0 8 * * *", Duration: &metav1.Duration{}},
+				}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(ScalingScheduleDurationError, "0 8 * * *")),
+		},
+		"MinReplicasAboveComponentMaxReplicas": {
+			spec: ComponentExtensionSpec{
+				MaxReplicas: 3,
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{
+					{Schedule: "0 8 * * *", MinReplicas: GetIntReference(5)},
+				}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(ScalingScheduleReplicasError, "0 8 * * *",
+				MinReplicasShouldBeLessThanMaxError)),
+		},
+		"ScaleToZeroWithCPU": {
+			spec: ComponentExtensionSpec{
+				ScaleMetric: &cpu,
+				Scaling: &ScalingSpec{Schedules: []ScalingSchedule{
+					{Schedule: "0 20 * * *", MinReplicas: GetIntReference(0)},
+				}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(ScalingScheduleReplicasError, "0 20 * * *",
+				fmt.Sprintf(ScaleToZeroNotSupportedError, cpu))),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(scenario.spec.Validate()).To(scenario.matcher)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// Known error messages
+const (
+	QuotaExceededError      = "ServingQuota %s exceeded: requested %d %s, used %d of %d."
+	QuotaMaxReplicasError   = "ServingQuota %s limits the %s, maxReplicas is required by the %s."
+	QuotaInvalidPolicyError = "Invalid ServingQuota policy %q, must be Reject or Queue."
+)
+
+// QuotaPolicy is the action taken on the inference services exceeding a quota
+// +kubebuilder:validation:Enum=Reject;Queue
+type QuotaPolicy string
+
+const (
+	// RejectQuotaPolicy rejects the inference services exceeding the quota
+	RejectQuotaPolicy QuotaPolicy = "Reject"
+	// QueueQuotaPolicy queues the new inference services exceeding the quota until the quota is available
+	QueueQuotaPolicy QuotaPolicy = "Queue"
+)
+
+// ServingQuotaSpec defines the limits of the inference services of the namespace, the unset limits are not enforced
+type ServingQuotaSpec struct {
+	// Maximum number of GPUs of the replicas of the inference services, the maxReplicas of the components times the
+	// GPU limits of their containers
+	// +optional
+	GPUs *int64 `json:"gpus,omitempty"`
+	// Maximum number of replicas of the components of the inference services, the sum of their maxReplicas
+	// +optional
+	Replicas *int64 `json:"replicas,omitempty"`
+	// Maximum number of inference services
+	// +optional
+	InferenceServices *int64 `json:"inferenceServices,omitempty"`
+	// Policy of the inference services exceeding the quota: Reject rejects them, Queue creates the new inference
+	// services exceeding the quota without deploying them until the quota is available. Defaults to Reject.
+	// +optional
+	Policy QuotaPolicy `json:"policy,omitempty"`
+}
+
+// ServingQuotaUsage is the usage of the quota by the inference services
+type ServingQuotaUsage struct {
+	// Number of GPUs
+	GPUs int64 `json:"gpus"`
+	// Number of replicas
+	Replicas int64 `json:"replicas"`
+	// Number of inference services
+	InferenceServices int64 `json:"inferenceServices"`
+}
+
+// ServingQuotaStatus defines the observed state of ServingQuota
+type ServingQuotaStatus struct {
+	// Conditions for the serving quota
+	duckv1.Status `json:",inline"`
+	// Usage of the quota by the inference services of the namespace which are not queued
+	// +optional
+	Used ServingQuotaUsage `json:"used,omitempty"`
+	// Names of the inference services of the namespace queued until the quota is available, oldest first
+	// +optional
+	Queued []string `json:"queued,omitempty"`
+}
+
+// ServingQuota limits the GPUs, the replicas and the number of the inference services of a namespace. Every quota of
+// the namespace is enforced, the inference services exceeding a quota are rejected or queued by its policy.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Policy",type="string",JSONPath=".spec.policy"
+// +kubebuilder:printcolumn:name="GPUs",type="integer",JSONPath=".status.used.gpus"
+// +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.used.replicas"
+// +kubebuilder:printcolumn:name="InferenceServices",type="integer",JSONPath=".status.used.inferenceServices"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=servingquotas,singular=servingquota
+type ServingQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ServingQuotaSpec   `json:"spec,omitempty"`
+	Status            ServingQuotaStatus `json:"status,omitempty"`
+}
+
+// ServingQuotaList contains a list of ServingQuota
+// +kubebuilder:object:root=true
+type ServingQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServingQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServingQuota{}, &ServingQuotaList{})
+}
+
+var servingQuotaCondSet = apis.NewLivingConditionSet()
+
+// InitializeConditions sets the initial values to the conditions
+func (s *ServingQuotaStatus) InitializeConditions() {
+	servingQuotaCondSet.Manage(s).InitializeConditions()
+}
+
+// MarkReady marks the quota ready, the quota is ready while its usage is within its limits
+func (s *ServingQuotaStatus) MarkReady() {
+	servingQuotaCondSet.Manage(s).MarkTrue(apis.ConditionReady)
+}
+
+// MarkNotReady marks the quota not ready with the reason and message
+func (s *ServingQuotaStatus) MarkNotReady(reason, message string) {
+	servingQuotaCondSet.Manage(s).MarkFalse(apis.ConditionReady, reason, message)
+}
+
+// GetPolicy returns the policy of the quota
+func (s *ServingQuotaSpec) GetPolicy() QuotaPolicy {
+	if s.Policy == "" {
+		return RejectQuotaPolicy
+	}
+	return s.Policy
+}
+
+// Validate returns an error if invalid
+func (s *ServingQuotaSpec) Validate() error {
+	if s.Policy != "" && s.Policy != RejectQuotaPolicy && s.Policy != QueueQuotaPolicy {
+		return fmt.Errorf(QuotaInvalidPolicyError, s.Policy)
+	}
+	return nil
+}
+
+// Check returns an error when the usage requested by an inference service exceeds the quota on top of the usage of
+// the other inference services, or when the components using a limited resource have no maxReplicas
+func (q *ServingQuota) Check(used ServingQuotaUsage, isvc *InferenceService) error {
+	requested := isvc.QuotaUsage()
+	if q.Spec.Replicas != nil {
+		if components := isvc.unboundedComponents(false); len(components) != 0 {
+			return fmt.Errorf(QuotaMaxReplicasError, q.Name, "replicas", strings.Join(components, ", "))
+		}
+	}
+	if q.Spec.GPUs != nil {
+		if components := isvc.unboundedComponents(true); len(components) != 0 {
+			return fmt.Errorf(QuotaMaxReplicasError, q.Name, "GPUs", strings.Join(components, ", "))
+		}
+	}
+	for _, limit := range []struct {
+		resource  string
+		limit     *int64
+		used      int64
+		requested int64
+	}{
+		{"GPUs", q.Spec.GPUs, used.GPUs, requested.GPUs},
+		{"replicas", q.Spec.Replicas, used.Replicas, requested.Replicas},
+		{"inference services", q.Spec.InferenceServices, used.InferenceServices, requested.InferenceServices},
+	} {
+		if limit.limit != nil && limit.used+limit.requested > *limit.limit {
+			return fmt.Errorf(QuotaExceededError, q.Name, limit.requested, limit.resource, limit.used, *limit.limit)
+		}
+	}
+	return nil
+}
+
+// Exceeded returns whether the usage exceeds the limits of the quota
+func (q *ServingQuota) Exceeded(used ServingQuotaUsage) bool {
+	return (q.Spec.GPUs != nil && used.GPUs > *q.Spec.GPUs) ||
+		(q.Spec.Replicas != nil && used.Replicas > *q.Spec.Replicas) ||
+		(q.Spec.InferenceServices != nil && used.InferenceServices > *q.Spec.InferenceServices)
+}
+
+// Add returns the sum of the usages
+func (u ServingQuotaUsage) Add(other ServingQuotaUsage) ServingQuotaUsage {
+	return ServingQuotaUsage{
+		GPUs:              u.GPUs + other.GPUs,
+		Replicas:          u.Replicas + other.Replicas,
+		InferenceServices: u.InferenceServices + other.InferenceServices,
+	}
+}
+
+// Exceeds returns whether the usage is greater than the other usage for any resource
+func (u ServingQuotaUsage) Exceeds(other ServingQuotaUsage) bool {
+	return u.GPUs > other.GPUs || u.Replicas > other.Replicas || u.InferenceServices > other.InferenceServices
+}
+
+// QuotaUsage returns the usage of the quotas by the inference service: the maxReplicas of its components and their
+// GPUs. A suspended inference service only counts as an inference service.
+func (isvc *InferenceService) QuotaUsage() ServingQuotaUsage {
+	usage := ServingQuotaUsage{InferenceServices: 1}
+	if isvc.Spec.Suspend {
+		return usage
+	}
+	for _, component := range isvc.quotaComponents() {
+		replicas := int64(component.GetExtensions().MaxReplicas)
+		usage.Replicas += replicas
+		usage.GPUs += replicas * componentGPUs(component)
+	}
+	return usage
+}
+
+// quotaComponents returns the components of the inference service counted by the quotas
+func (isvc *InferenceService) quotaComponents() map[ComponentType]Component {
+	components := map[ComponentType]Component{PredictorComponent: &isvc.Spec.Predictor}
+	if isvc.Spec.Transformer != nil {
+		components[TransformerComponent] = isvc.Spec.Transformer
+	}
+	if isvc.Spec.Explainer != nil {
+		components[ExplainerComponent] = isvc.Spec.Explainer
+	}
+	return components
+}
+
+// unboundedComponents returns the sorted components without maxReplicas, only the components with GPUs when gpus
+func (isvc *InferenceService) unboundedComponents(gpus bool) []string {
+	unbounded := []string{}
+	if isvc.Spec.Suspend {
+		return unbounded
+	}
+	components := isvc.quotaComponents()
+	for _, name := range []ComponentType{PredictorComponent, TransformerComponent, ExplainerComponent} {
+		component, ok := components[name]
+		if !ok || component.GetExtensions().MaxReplicas != 0 || (gpus && componentGPUs(component) == 0) {
+			continue
+		}
+		unbounded = append(unbounded, string(name))
+	}
+	return unbounded
+}
+
+// componentGPUs returns the GPUs of a replica of the component, the sum of the GPU limits of its containers
+func componentGPUs(component Component) int64 {
+	gpus := int64(0)
+	for _, implementation := range component.GetImplementations() {
+		resources := []v1.ResourceRequirements{}
+		switch spec := implementation.(type) {
+		case *CustomPredictor:
+			resources = containerResources(spec.Containers)
+		case *CustomTransformer:
+			resources = containerResources(spec.Containers)
+		case *CustomExplainer:
+			resources = containerResources(spec.Containers)
+		case *ModelPredictorSpec:
+			resources = append(resources, spec.Resources)
+		case *FeastTransformerSpec:
+			resources = append(resources, spec.Resources)
+		default:
+			if _, implementationResources, _ := resourceProfiles(spec, &InferenceServicesConfig{}); implementationResources != nil {
+				resources = append(resources, *implementationResources)
+			}
+		}
+		for _, requirements := range resources {
+			for name, quantity := range requirements.Limits {
+				if utils.IsGPUResource(name) {
+					gpus += quantity.Value()
+				}
+			}
+		}
+	}
+	return gpus
+}
+
+func containerResources(containers []v1.Container) []v1.ResourceRequirements {
+	resources := make([]v1.ResourceRequirements, 0, len(containers))
+	for _, container := range containers {
+		resources = append(resources, container.Resources)
+	}
+	return resources
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeQuotaInferenceService(maxReplicas int, gpus int64) *InferenceService {
+	resources := v1.ResourceRequirements{}
+	if gpus != 0 {
+		resources.Limits = v1.ResourceList{"nvidia.com/gpu": *resource.NewQuantity(gpus, resource.DecimalSI)}
+	}
+	return &InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "flowers", Namespace: "default"},
+		Spec: InferenceServiceSpec{
+			Predictor: PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{MaxReplicas: maxReplicas},
+				Tensorflow: &TFServingSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{
+						Container: v1.Container{Resources: resources},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestInferenceServiceQuotaUsage(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		isvc     *InferenceService
+		expected ServingQuotaUsage
+	}{
+		"Predictor": {
+			isvc:     makeQuotaInferenceService(3, 0),
+			expected: ServingQuotaUsage{Replicas: 3, InferenceServices: 1},
+		},
+		"GPUPredictor": {
+			isvc:     makeQuotaInferenceService(3, 2),
+			expected: ServingQuotaUsage{GPUs: 6, Replicas: 3, InferenceServices: 1},
+		},
+		"CustomTransformer": {
+			isvc: func() *InferenceService {
+				isvc := makeQuotaInferenceService(2, 1)
+				isvc.Spec.Transformer = &TransformerSpec{
+					ComponentExtensionSpec: ComponentExtensionSpec{MaxReplicas: 4},
+					PodSpec: PodSpec{Containers: []v1.Container{{
+						Image: "transformer:latest",
+						Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+							"nvidia.com/mig-1g.5gb": resource.MustParse("1"),
+						}},
+					}}},
+				}
+				return isvc
+			}(),
+			expected: ServingQuotaUsage{GPUs: 6, Replicas: 6, InferenceServices: 1},
+		},
+		"Suspended": {
+			isvc: func() *InferenceService {
+				isvc := makeQuotaInferenceService(3, 2)
+				isvc.Spec.Suspend = true
+				return isvc
+			}(),
+			expected: ServingQuotaUsage{InferenceServices: 1},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(scenario.isvc.QuotaUsage()).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestServingQuotaCheck(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	limit := func(value int64) *int64 {
+		return &value
+	}
+	scenarios := map[string]struct {
+		spec    ServingQuotaSpec
+		used    ServingQuotaUsage
+		isvc    *InferenceService
+		matcher types.GomegaMatcher
+	}{
+		"WithinQuota": {
+			spec:    ServingQuotaSpec{GPUs: limit(8), Replicas: limit(10), InferenceServices: limit(2)},
+			used:    ServingQuotaUsage{GPUs: 2, Replicas: 2, InferenceServices: 1},
+			isvc:    makeQuotaInferenceService(3, 2),
+			matcher: gomega.BeNil(),
+		},
+		"GPUsExceeded": {
+			spec:    ServingQuotaSpec{GPUs: limit(8)},
+			used:    ServingQuotaUsage{GPUs: 4, Replicas: 2, InferenceServices: 1},
+			isvc:    makeQuotaInferenceService(3, 2),
+			matcher: gomega.MatchError("ServingQuota gpus exceeded: requested 6 GPUs, used 4 of 8."),
+		},
+		"ReplicasExceeded": {
+			spec:    ServingQuotaSpec{Replicas: limit(4)},
+			used:    ServingQuotaUsage{Replicas: 2, InferenceServices: 1},
+			isvc:    makeQuotaInferenceService(3, 0),
+			matcher: gomega.MatchError("ServingQuota gpus exceeded: requested 3 replicas, used 2 of 4."),
+		},
+		"InferenceServicesExceeded": {
+			spec:    ServingQuotaSpec{InferenceServices: limit(1)},
+			used:    ServingQuotaUsage{Replicas: 2, InferenceServices: 1},
+			isvc:    makeQuotaInferenceService(0, 0),
+			matcher: gomega.MatchError("ServingQuota gpus exceeded: requested 1 inference services, used 1 of 1."),
+		},
+		"UnboundedReplicas": {
+			spec:    ServingQuotaSpec{Replicas: limit(4)},
+			isvc:    makeQuotaInferenceService(0, 0),
+			matcher: gomega.MatchError("ServingQuota gpus limits the replicas, maxReplicas is required by the predictor."),
+		},
+		"UnboundedGPUs": {
+			spec:    ServingQuotaSpec{GPUs: limit(4)},
+			isvc:    makeQuotaInferenceService(0, 1),
+			matcher: gomega.MatchError("ServingQuota gpus limits the GPUs, maxReplicas is required by the predictor."),
+		},
+		"UnboundedWithoutGPUs": {
+			spec:    ServingQuotaSpec{GPUs: limit(4)},
+			isvc:    makeQuotaInferenceService(0, 0),
+			matcher: gomega.BeNil(),
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			quota := &ServingQuota{ObjectMeta: metav1.ObjectMeta{Name: "gpus"}, Spec: scenario.spec}
+			g.Expect(quota.Check(scenario.used, scenario.isvc)).Should(scenario.matcher)
+		})
+	}
+}
+
+func TestServingQuotaSpecValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect((&ServingQuotaSpec{}).Validate()).To(gomega.Succeed())
+	g.Expect((&ServingQuotaSpec{}).GetPolicy()).To(gomega.Equal(RejectQuotaPolicy))
+	g.Expect((&ServingQuotaSpec{Policy: QueueQuotaPolicy}).Validate()).To(gomega.Succeed())
+	g.Expect((&ServingQuotaSpec{Policy: "Drop"}).Validate()).To(gomega.MatchError(`Invalid ServingQuota policy "Drop", must be Reject or Queue.`))
+}
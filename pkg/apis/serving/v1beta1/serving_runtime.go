@@ -0,0 +1,276 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SupportedModelFormat describes a model format served by a runtime
+type SupportedModelFormat struct {
+	// Name of the model format, e.g. "sklearn", "tensorflow" or "onnx"
+	Name string `json:"name"`
+	// Versions of the model format served by the runtime, the runtime serves all the versions of the format when not
+	// set. Either a version prefix, e.g. "1" serves "1", "1.0" and "1.2.3", or a comma separated list of comparisons
+	// with the >, >=, <, <= and = operators, e.g. ">=0.23,<2".
+	// +optional
+	Version *string `json:"version,omitempty"`
+	// Set to true to let the controller select the runtime for the models of this format that do not name a runtime
+	// +optional
+	AutoSelect *bool `json:"autoSelect,omitempty"`
+	// Priority of the runtime among the runtimes auto selected for the model format, the runtime with the highest
+	// priority is selected. Defaults to 0.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+}
+
+// ServingRuntimeSpec describes the model formats served by a runtime and the containers serving them
+type ServingRuntimeSpec struct {
+	// Model formats served by the runtime
+	SupportedModelFormats []SupportedModelFormat `json:"supportedModelFormats"`
+	// Inference protocols served by the runtime, defaults to v1
+	// +optional
+	ProtocolVersions []constants.InferenceServiceProtocol `json:"protocolVersions,omitempty"`
+	// Set to true to prevent the runtime from serving new predictors
+	// +optional
+	Disabled *bool `json:"disabled,omitempty"`
+	// Set to true when the runtime serves the models on GPUs and to false when it serves them on CPUs, the runtime is
+	// only selected for the predictors requesting GPUs in the first case and not requesting GPUs in the second case.
+	// The runtime serves models on both when not set.
+	// +optional
+	GPU *bool `json:"gpu,omitempty"`
+	// Containers of the runtime, the first container serves the model and receives the inference requests.
+	// The args, command and env values may use the {{.Name}}, {{.ModelDir}} and {{.HTTPPort}} placeholders.
+	Containers []v1.Container `json:"containers"`
+}
+
+// ServingRuntimeStatus defines the observed state of ServingRuntime
+type ServingRuntimeStatus struct {
+}
+
+// ServingRuntime is the Schema for the servingruntimes API
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Disabled",type="boolean",JSONPath=".spec.disabled"
+// +kubebuilder:printcolumn:name="ModelFormat",type="string",JSONPath=".spec.supportedModelFormats[*].name"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=servingruntimes,singular=servingruntime
+type ServingRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ServingRuntimeSpec   `json:"spec,omitempty"`
+	Status            ServingRuntimeStatus `json:"status,omitempty"`
+}
+
+// ServingRuntimeList contains a list of ServingRuntime
+// +kubebuilder:object:root=true
+type ServingRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServingRuntime `json:"items"`
+}
+
+// ClusterServingRuntime is the Schema for the clusterservingruntimes API, it is available in all the namespaces
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Disabled",type="boolean",JSONPath=".spec.disabled"
+// +kubebuilder:printcolumn:name="ModelFormat",type="string",JSONPath=".spec.supportedModelFormats[*].name"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=clusterservingruntimes,scope=Cluster,singular=clusterservingruntime
+type ClusterServingRuntime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ServingRuntimeSpec   `json:"spec,omitempty"`
+	Status            ServingRuntimeStatus `json:"status,omitempty"`
+}
+
+// ClusterServingRuntimeList contains a list of ClusterServingRuntime
+// +kubebuilder:object:root=true
+type ClusterServingRuntimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterServingRuntime `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServingRuntime{}, &ServingRuntimeList{})
+	SchemeBuilder.Register(&ClusterServingRuntime{}, &ClusterServingRuntimeList{})
+}
+
+// IsDisabled returns true if the runtime must not serve new predictors
+func (s *ServingRuntimeSpec) IsDisabled() bool {
+	return s.Disabled != nil && *s.Disabled
+}
+
+// SupportsProtocol returns true if the runtime serves the protocol
+func (s *ServingRuntimeSpec) SupportsProtocol(protocol constants.InferenceServiceProtocol) bool {
+	if len(s.ProtocolVersions) == 0 {
+		return protocol == constants.ProtocolV1
+	}
+	for _, version := range s.ProtocolVersions {
+		if version == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsModelFormat returns true if the runtime serves the model format
+func (s *ServingRuntimeSpec) SupportsModelFormat(format ModelFormat) bool {
+	return s.findModelFormat(format) != nil
+}
+
+// SupportsGPU returns true if the runtime serves models on GPUs when requested and on CPUs otherwise
+func (s *ServingRuntimeSpec) SupportsGPU(requested bool) bool {
+	return s.GPU == nil || *s.GPU == requested
+}
+
+// IsAutoSelectable returns true if the runtime can be selected for models of the format served with the protocol
+func (s *ServingRuntimeSpec) IsAutoSelectable(format ModelFormat, protocol constants.InferenceServiceProtocol, gpu bool) bool {
+	return s.CheckAutoSelectable(format, protocol, gpu) == nil
+}
+
+// CheckAutoSelectable returns an error telling why the runtime cannot be selected for models of the format served
+// with the protocol
+func (s *ServingRuntimeSpec) CheckAutoSelectable(format ModelFormat, protocol constants.InferenceServiceProtocol, gpu bool) error {
+	if s.IsDisabled() {
+		return fmt.Errorf("runtime is disabled")
+	}
+	if len(s.Containers) == 0 {
+		return fmt.Errorf(RuntimeWithoutContainersError)
+	}
+	if err := s.CheckModelFormat(format); err != nil {
+		return err
+	}
+	if !s.SupportsProtocol(protocol) {
+		return fmt.Errorf("protocol %s is not supported", protocol)
+	}
+	if !s.SupportsGPU(gpu) {
+		if gpu {
+			return fmt.Errorf("runtime does not serve models on GPUs")
+		}
+		return fmt.Errorf("runtime only serves models on GPUs")
+	}
+	if supported := s.findModelFormat(format); supported.AutoSelect == nil || !*supported.AutoSelect {
+		return fmt.Errorf("auto selection is not enabled for model format %s", supported.Name)
+	}
+	return nil
+}
+
+// CheckModelFormat returns an error telling why the runtime does not serve the model format
+func (s *ServingRuntimeSpec) CheckModelFormat(format ModelFormat) error {
+	if s.findModelFormat(format) != nil {
+		return nil
+	}
+	versions := []string{}
+	for _, supported := range s.SupportedModelFormats {
+		if strings.EqualFold(supported.Name, format.Name) && supported.Version != nil {
+			versions = append(versions, *supported.Version)
+		}
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("model format %s is not supported", format.Name)
+	}
+	return fmt.Errorf("model format %s version %s is not supported, supported versions: %s", format.Name,
+		*format.Version, strings.Join(versions, " "))
+}
+
+// GetPriority returns the priority of the runtime for the model format
+func (s *ServingRuntimeSpec) GetPriority(format ModelFormat) int32 {
+	if supported := s.findModelFormat(format); supported != nil && supported.Priority != nil {
+		return *supported.Priority
+	}
+	return 0
+}
+
+// findModelFormat returns the supported model format matching the format, model format names are case insensitive
+func (s *ServingRuntimeSpec) findModelFormat(format ModelFormat) *SupportedModelFormat {
+	for i, supported := range s.SupportedModelFormats {
+		if !strings.EqualFold(supported.Name, format.Name) {
+			continue
+		}
+		if supported.Version == nil || format.Version == nil || matchesVersion(*supported.Version, *format.Version) {
+			return &s.SupportedModelFormats[i]
+		}
+	}
+	return nil
+}
+
+// matchesVersion returns true if the version satisfies the constraint, either a version prefix or a comma separated
+// list of comparisons
+func matchesVersion(constraint string, version string) bool {
+	constraint = strings.TrimSpace(constraint)
+	if !strings.ContainsAny(constraint, "<>=") {
+		return version == constraint || strings.HasPrefix(version, constraint+".")
+	}
+	for _, comparison := range strings.Split(constraint, ",") {
+		comparison = strings.TrimSpace(comparison)
+		operand := strings.TrimLeft(comparison, "<>=")
+		operator := comparison[:len(comparison)-len(operand)]
+		result := compareVersions(version, strings.TrimSpace(operand))
+		var ok bool
+		switch operator {
+		case ">":
+			ok = result > 0
+		case ">=":
+			ok = result >= 0
+		case "<":
+			ok = result < 0
+		case "<=":
+			ok = result <= 0
+		case "=", "==", "":
+			ok = result == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares the dot separated numbers of the versions, missing numbers are zeros and numbers that
+// cannot be parsed are compared as strings
+func compareVersions(a string, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+		aNumber, aErr := strconv.Atoi(aPart)
+		bNumber, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr != nil || bErr != nil:
+			if c := strings.Compare(aPart, bPart); c != 0 {
+				return c
+			}
+		case aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
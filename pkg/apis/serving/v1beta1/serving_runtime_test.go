@@ -0,0 +1,186 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestServingRuntimeIsAutoSelectable(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	containers := []v1.Container{{Name: "kfserving-container", Image: "sklearnserver:v0.4.0"}}
+	scenarios := map[string]struct {
+		spec     ServingRuntimeSpec
+		format   ModelFormat
+		protocol constants.InferenceServiceProtocol
+		gpu      bool
+		expected bool
+	}{
+		"AutoSelect": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "SKLearn"},
+			protocol: constants.ProtocolV1,
+			expected: true,
+		},
+		"AutoSelectNotSet": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn"}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn"},
+			protocol: constants.ProtocolV1,
+			expected: false,
+		},
+		"Disabled": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+				Disabled:              proto.Bool(true),
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn"},
+			protocol: constants.ProtocolV1,
+			expected: false,
+		},
+		"UnsupportedFormat": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "xgboost"},
+			protocol: constants.ProtocolV1,
+			expected: false,
+		},
+		"UnsupportedVersion": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", Version: proto.String("0"), AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn", Version: proto.String("1")},
+			protocol: constants.ProtocolV1,
+			expected: false,
+		},
+		"DefaultProtocolIsV1": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn"},
+			protocol: constants.ProtocolV2,
+			expected: false,
+		},
+		"SupportedProtocol": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", AutoSelect: proto.Bool(true)}},
+				ProtocolVersions:      []constants.InferenceServiceProtocol{constants.ProtocolV1, constants.ProtocolV2},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn"},
+			protocol: constants.ProtocolV2,
+			expected: true,
+		},
+		"SupportedVersionPrefix": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", Version: proto.String("0"), AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn", Version: proto.String("0.23.2")},
+			protocol: constants.ProtocolV1,
+			expected: true,
+		},
+		"SupportedVersionRange": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "sklearn", Version: proto.String(">=0.23,<2"), AutoSelect: proto.Bool(true)}},
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "sklearn", Version: proto.String("1.0")},
+			protocol: constants.ProtocolV1,
+			expected: true,
+		},
+		"GPURuntimeForGPUPredictor": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "onnx", AutoSelect: proto.Bool(true)}},
+				GPU:                   proto.Bool(true),
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "onnx"},
+			protocol: constants.ProtocolV1,
+			gpu:      true,
+			expected: true,
+		},
+		"GPURuntimeForCPUPredictor": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "onnx", AutoSelect: proto.Bool(true)}},
+				GPU:                   proto.Bool(true),
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "onnx"},
+			protocol: constants.ProtocolV1,
+			expected: false,
+		},
+		"CPURuntimeForGPUPredictor": {
+			spec: ServingRuntimeSpec{
+				SupportedModelFormats: []SupportedModelFormat{{Name: "onnx", AutoSelect: proto.Bool(true)}},
+				GPU:                   proto.Bool(false),
+				Containers:            containers,
+			},
+			format:   ModelFormat{Name: "onnx"},
+			protocol: constants.ProtocolV1,
+			gpu:      true,
+			expected: false,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := scenario.spec.IsAutoSelectable(scenario.format, scenario.protocol, scenario.gpu)
+			if !g.Expect(res).To(gomega.Equal(scenario.expected)) {
+				t.Errorf("got %t, want %t", res, scenario.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		"Equal":             {constraint: "1.0", version: "1.0", expected: true},
+		"Prefix":            {constraint: "0", version: "0.23.2", expected: true},
+		"NotPrefix":         {constraint: "0.2", version: "0.23", expected: false},
+		"GreaterOrEqual":    {constraint: ">=0.23", version: "0.23.0", expected: true},
+		"NumericComparison": {constraint: ">0.9", version: "0.23", expected: true},
+		"Range":             {constraint: ">=0.23, <1", version: "0.24", expected: true},
+		"OutOfRange":        {constraint: ">=0.23,<1", version: "1.0", expected: false},
+		"ExactOperator":     {constraint: "=2", version: "2.0", expected: true},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(matchesVersion(scenario.constraint, scenario.version)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/networking"
+)
+
+// Known error messages
+const (
+	SidecarNameConflictError = "Container name %q of the predictor is already used or reserved."
+	SidecarPortConflictError = "Port %d of the container %q of the predictor is already used or reserved."
+)
+
+// reservedContainerNames are the names of the containers added to the pods of the predictor by KFServing, Knative
+// and Istio
+var reservedContainerNames = []string{
+	constants.InferenceServiceContainerName,
+	constants.KnativeQueueProxyContainerName,
+	constants.WarmPoolAgentContainerName,
+	"istio-proxy",
+	"inferenceservice-logger",
+	"batcher",
+	"async",
+	"request-validator",
+	"response-cache",
+	"inferenceservice-warmup",
+	"storage-initializer",
+	"model-converter",
+	"warmup-initializer",
+}
+
+// reservedPorts are the ports served in the pods of the predictor by the model server, the KFServing sidecars and the
+// Knative queue proxy
+func reservedPorts() []int32 {
+	ports := []int32{
+		networking.BackendHTTPPort,
+		networking.BackendHTTP2Port,
+		networking.QueueAdminPort,
+		networking.AutoscalingQueueMetricsPort,
+		networking.UserQueueMetricsPort,
+	}
+	for _, port := range []string{
+		constants.InferenceServiceDefaultHttpPort,
+		constants.InferenceServiceDefaultLoggerPort,
+		constants.InferenceServiceDefaultBatcherPort,
+		constants.InferenceServiceDefaultWarmupPort,
+		constants.InferenceServiceDefaultAsyncPort,
+		constants.InferenceServiceDefaultValidatorPort,
+		constants.InferenceServiceDefaultCachePort,
+	} {
+		p, _ := strconv.Atoi(port)
+		ports = append(ports, int32(p))
+	}
+	return ports
+}
+
+// GetSidecars returns the containers run alongside the model server: the sidecars and the containers of a custom
+// predictor following its model server
+func (s *PredictorSpec) GetSidecars() []v1.Container {
+	var sidecars []v1.Container
+	if len(s.PodSpec.Containers) > 1 {
+		sidecars = append(sidecars, s.PodSpec.Containers[1:]...)
+	}
+	return append(sidecars, s.Sidecars...)
+}
+
+// validateSidecars validates that the names of the sidecars and the init containers and the ports of the sidecars
+// conflict neither with each other nor with the containers and the ports of the model server, KFServing and Knative
+func validateSidecars(predictor *PredictorSpec) error {
+	sidecars := predictor.GetSidecars()
+	if len(sidecars) == 0 && len(predictor.InitContainers) == 0 {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, name := range reservedContainerNames {
+		names[name] = true
+	}
+	ports := map[int32]bool{}
+	for _, port := range reservedPorts() {
+		ports[port] = true
+	}
+	if len(predictor.PodSpec.Containers) != 0 {
+		for _, port := range predictor.PodSpec.Containers[0].Ports {
+			ports[port.ContainerPort] = true
+		}
+	}
+	for _, container := range append(sidecars, predictor.InitContainers...) {
+		if names[container.Name] {
+			return fmt.Errorf(SidecarNameConflictError, container.Name)
+		}
+		names[container.Name] = true
+	}
+	for _, container := range sidecars {
+		for _, port := range container.Ports {
+			if ports[port.ContainerPort] {
+				return fmt.Errorf(SidecarPortConflictError, port.ContainerPort, container.Name)
+			}
+			ports[port.ContainerPort] = true
+		}
+	}
+	return nil
+}
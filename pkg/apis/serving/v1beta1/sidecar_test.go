@@ -0,0 +1,97 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSidecarValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	tokenizer := v1.Container{Name: "tokenizer", Ports: []v1.ContainerPort{{ContainerPort: 8500}}}
+	scenarios := map[string]struct {
+		predictor *PredictorSpec
+		matcher   types.GomegaMatcher
+	}{
+		"NoSidecars": {
+			predictor: &PredictorSpec{},
+			matcher:   gomega.BeNil(),
+		},
+		"SidecarsAndInitContainers": {
+			predictor: &PredictorSpec{
+				Sidecars: []v1.Container{tokenizer, {Name: "features"}},
+				PodSpec:  PodSpec{InitContainers: []v1.Container{{Name: "vocabulary"}}},
+			},
+			matcher: gomega.BeNil(),
+		},
+		"CustomPredictorContainers": {
+			predictor: &PredictorSpec{
+				PodSpec: PodSpec{Containers: []v1.Container{
+					{Name: constants.InferenceServiceContainerName, Ports: []v1.ContainerPort{{ContainerPort: 5000}}},
+					tokenizer,
+				}},
+				Sidecars: []v1.Container{{Name: "features", Ports: []v1.ContainerPort{{ContainerPort: 5000}}}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(SidecarPortConflictError, 5000, "features")),
+		},
+		"DuplicateName": {
+			predictor: &PredictorSpec{
+				Sidecars: []v1.Container{tokenizer},
+				PodSpec:  PodSpec{InitContainers: []v1.Container{{Name: "tokenizer"}}},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(SidecarNameConflictError, "tokenizer")),
+		},
+		"ReservedName": {
+			predictor: &PredictorSpec{Sidecars: []v1.Container{{Name: constants.InferenceServiceContainerName}}},
+			matcher:   gomega.MatchError(fmt.Sprintf(SidecarNameConflictError, constants.InferenceServiceContainerName)),
+		},
+		"ReservedInitContainerName": {
+			predictor: &PredictorSpec{PodSpec: PodSpec{InitContainers: []v1.Container{{Name: "storage-initializer"}}}},
+			matcher:   gomega.MatchError(fmt.Sprintf(SidecarNameConflictError, "storage-initializer")),
+		},
+		"ModelServerPort": {
+			predictor: &PredictorSpec{Sidecars: []v1.Container{{Name: "features",
+				Ports: []v1.ContainerPort{{ContainerPort: 8080}}}}},
+			matcher: gomega.MatchError(fmt.Sprintf(SidecarPortConflictError, 8080, "features")),
+		},
+		"QueueProxyPort": {
+			predictor: &PredictorSpec{Sidecars: []v1.Container{{Name: "features",
+				Ports: []v1.ContainerPort{{ContainerPort: 8012}}}}},
+			matcher: gomega.MatchError(fmt.Sprintf(SidecarPortConflictError, 8012, "features")),
+		},
+		"SidecarsPortConflict": {
+			predictor: &PredictorSpec{Sidecars: []v1.Container{tokenizer, {Name: "features",
+				Ports: []v1.ContainerPort{{ContainerPort: 8500}}}}},
+			matcher: gomega.MatchError(fmt.Sprintf(SidecarPortConflictError, 8500, "features")),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateSidecars(scenario.predictor)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+)
+
+// Known error messages
+const (
+	InvalidResponseProtocolError   = "Protocol %q is not supported, must be one of: [unary, streaming, websocket]."
+	StreamingAsyncConflictError    = "The %s protocol cannot be set with async, the async results are not streamed."
+	StreamingMaxResponseBytesError = "The %s protocol cannot be set with the maxResponseBytes of the %s, the responses are buffered to check their size."
+)
+
+// ResponseProtocol is the protocol of the responses of the predictor
+// +kubebuilder:validation:Enum=unary;streaming;websocket
+type ResponseProtocol string
+
+// ResponseProtocol Enum
+const (
+	// UnaryProtocol responses are read whole by the components of the data plane, e.g. to batch or log them
+	UnaryProtocol ResponseProtocol = "unary"
+	// StreamingProtocol responses are passed through the data plane as they are written, e.g. the tokens generated by
+	// a language model sent as server-sent events
+	StreamingProtocol ResponseProtocol = "streaming"
+	// WebsocketProtocol requests are upgraded to websocket connections passed through the data plane, e.g. the audio
+	// streamed to a speech recognition model and its transcripts
+	WebsocketProtocol ResponseProtocol = "websocket"
+)
+
+// IsStreaming returns true when the responses of the predictor are streamed
+func (s *PredictorSpec) IsStreaming() bool {
+	return s.Protocol == StreamingProtocol
+}
+
+// IsWebsocket returns true when the requests of the predictor are upgraded to websocket connections
+func (s *PredictorSpec) IsWebsocket() bool {
+	return s.Protocol == WebsocketProtocol
+}
+
+// validateProtocol validates the protocol of the responses of the predictor, the responses of the transformer are the
+// streamed responses of the predictor and the websocket connections are relayed by the transformer
+func validateProtocol(predictor *PredictorSpec, transformer *TransformerSpec) error {
+	switch predictor.Protocol {
+	case "", UnaryProtocol:
+		return nil
+	case StreamingProtocol, WebsocketProtocol:
+		if predictor.Async != nil {
+			return fmt.Errorf(StreamingAsyncConflictError, predictor.Protocol)
+		}
+		if predictor.MaxResponseBytes != nil {
+			return fmt.Errorf(StreamingMaxResponseBytesError, predictor.Protocol, PredictorComponent)
+		}
+		if transformer != nil && transformer.MaxResponseBytes != nil {
+			return fmt.Errorf(StreamingMaxResponseBytesError, predictor.Protocol, TransformerComponent)
+		}
+		return nil
+	}
+	return fmt.Errorf(InvalidResponseProtocolError, predictor.Protocol)
+}
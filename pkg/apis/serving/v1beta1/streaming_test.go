@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestProtocolValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		predictor   *PredictorSpec
+		transformer *TransformerSpec
+		matcher     types.GomegaMatcher
+	}{
+		"NoProtocol": {
+			predictor: &PredictorSpec{},
+			matcher:   gomega.BeNil(),
+		},
+		"Unary": {
+			predictor: &PredictorSpec{Protocol: UnaryProtocol, Async: &AsyncSpec{}},
+			matcher:   gomega.BeNil(),
+		},
+		"Streaming": {
+			predictor:   &PredictorSpec{Protocol: StreamingProtocol},
+			transformer: &TransformerSpec{},
+			matcher:     gomega.BeNil(),
+		},
+		"InvalidProtocol": {
+			predictor: &PredictorSpec{Protocol: "grpc"},
+			matcher:   gomega.MatchError(fmt.Sprintf(InvalidResponseProtocolError, "grpc")),
+		},
+		"StreamingWithAsync": {
+			predictor: &PredictorSpec{Protocol: StreamingProtocol, Async: &AsyncSpec{}},
+			matcher:   gomega.MatchError(fmt.Sprintf(StreamingAsyncConflictError, StreamingProtocol)),
+		},
+		"Websocket": {
+			predictor: &PredictorSpec{Protocol: WebsocketProtocol},
+			matcher:   gomega.BeNil(),
+		},
+		"WebsocketWithAsync": {
+			predictor: &PredictorSpec{Protocol: WebsocketProtocol, Async: &AsyncSpec{}},
+			matcher:   gomega.MatchError(fmt.Sprintf(StreamingAsyncConflictError, WebsocketProtocol)),
+		},
+		"StreamingWithPredictorMaxResponseBytes": {
+			predictor: &PredictorSpec{
+				Protocol:               StreamingProtocol,
+				ComponentExtensionSpec: ComponentExtensionSpec{MaxResponseBytes: proto.Int64(1024)},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(StreamingMaxResponseBytesError, StreamingProtocol, PredictorComponent)),
+		},
+		"WebsocketWithTransformerMaxResponseBytes": {
+			predictor: &PredictorSpec{Protocol: WebsocketProtocol},
+			transformer: &TransformerSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{MaxResponseBytes: proto.Int64(1024)},
+			},
+			matcher: gomega.MatchError(fmt.Sprintf(StreamingMaxResponseBytesError, WebsocketProtocol, TransformerComponent)),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateProtocol(scenario.predictor, scenario.transformer)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
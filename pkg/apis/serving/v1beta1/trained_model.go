@@ -22,6 +22,7 @@ import (
 )
 
 // TrainedModel is the Schema for the TrainedModel API
+// +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
@@ -64,4 +65,8 @@ type ModelSpec struct {
 	// Maximum memory this model will consume, this field is used to decide if a model server has enough memory to load this model.
 	// +optional
 	Memory resource.Quantity `json:"memory,omitempty"`
+	// When the model server loads the model, Eager by default. With Lazy the model agent downloads the model without
+	// loading it, the model server loads it on the first request.
+	// +optional
+	LoadPolicy *LoadPolicy `json:"loadPolicy,omitempty"`
 }
@@ -27,4 +27,26 @@ type TrainedModelStatus struct {
 	// Addressable endpoint for the deployed trained model
 	// http://<inferenceservice.metadata.name>/v1/models/<trainedmodel>.metadata.name
 	Address *duckv1.Addressable `json:"address,omitempty"`
+	// Input and output tensors of the model read by the agent from the signature of the model artifacts
+	// +optional
+	Metadata *ModelMetadata `json:"metadata,omitempty"`
+}
+
+// ModelMetadata describes the request and response tensors of a model, in the format of the v2 model metadata API
+type ModelMetadata struct {
+	// Format of the signature the metadata is read from: tensorflow_savedmodel, mlflow or triton
+	Platform string `json:"platform"`
+	// +optional
+	Inputs []TensorMetadata `json:"inputs,omitempty"`
+	// +optional
+	Outputs []TensorMetadata `json:"outputs,omitempty"`
+}
+
+// TensorMetadata describes a tensor of the model signature
+type TensorMetadata struct {
+	Name string `json:"name"`
+	// Data type of the tensor elements as a v2 datatype, e.g. FP32, INT64 or BYTES
+	Datatype string `json:"datatype"`
+	// Shape of the tensor, -1 for the dimensions of variable size and empty when the rank is unknown
+	Shape []int64 `json:"shape"`
 }
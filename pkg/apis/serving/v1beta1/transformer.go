@@ -16,8 +16,14 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // TransformerSpec defines transformer service for pre/post processing
 type TransformerSpec struct {
+	// Spec for a transformer enriching the requests with the online features of a Feast feature server
+	Feast *FeastTransformerSpec `json:"feast,omitempty"`
 	// This spec is dual purpose.
 	// 1) Users may choose to provide a full PodSpec for their transformer.
 	// The field PodSpec.Containers is mutually exclusive with other Transformer (i.e. Feast).
@@ -26,11 +32,48 @@ type TransformerSpec struct {
 	PodSpec `json:",inline"`
 	// Extensions available in all components
 	ComponentExtensionSpec `json:",inline"`
+	// Configures the calls of the transformer to the predictor
+	// +optional
+	PredictorCall *PredictorCallSpec `json:"predictorCall,omitempty"`
+}
+
+// PredictorCallProtocol is the HTTP protocol of the calls of the transformer to the predictor
+type PredictorCallProtocol string
+
+const (
+	PredictorCallHTTP1 PredictorCallProtocol = "HTTP/1.1"
+	PredictorCallHTTP2 PredictorCallProtocol = "HTTP/2"
+)
+
+// PredictorCallSpec configures how the transformer calls the predictor. The settings are passed to the transformer as
+// environment variables and applied by the Istio sidecars through the DestinationRules of the predictor revisions.
+type PredictorCallSpec struct {
+	// Host of the predictor called by the transformer, e.g. "my-model-predictor-default.other-namespace". Defaults to
+	// the predictor of the inference service.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Protocol of the calls to the predictor, "HTTP/1.1" or "HTTP/2". Defaults to "HTTP/1.1". The calls are upgraded to
+	// HTTP/2 by the sidecar of the transformer, the predictor must serve HTTP/2.
+	// +optional
+	Protocol PredictorCallProtocol `json:"protocol,omitempty"`
+	// Maximum number of connections to each replica of the predictor, and of concurrent calls of each transformer
+	// replica.
+	// +optional
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+	// Duration after which the idle keep-alive connections to the predictor are closed. Defaults to 1 hour.
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+	// Requires Istio mutual TLS on the calls to the predictor instead of falling back to plain text when the predictor
+	// has no sidecar.
+	// +optional
+	StrictMTLS bool `json:"strictMTLS,omitempty"`
 }
 
 // GetImplementations returns the implementations for the component
 func (s *TransformerSpec) GetImplementations() []ComponentImplementation {
-	implementations := []ComponentImplementation{}
+	implementations := NonNilComponents([]ComponentImplementation{
+		s.Feast,
+	})
 	// This struct is not a pointer, so it will never be nil; include if containers are specified
 	if len(s.PodSpec.Containers) != 0 {
 		implementations = append(implementations, NewCustomTransformer(&s.PodSpec))
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Known error messages
+const (
+	InvalidFeastServingURLError  = "Feast feastServingUrl %q must be an http or https URL."
+	MissingFeastEntityIDsError   = "Feast entityIds requires at least one entity."
+	MissingFeastFeatureRefsError = "Feast featureRefs requires at least one feature."
+	InvalidFeastFeatureRefError  = "Feast feature reference %q must be of the form <feature view>:<feature>."
+)
+
+// Arguments of the Feast transformer
+const (
+	FeastArgumentServingURL  = "--feast_serving_url"
+	FeastArgumentEntityIDs   = "--entity_ids"
+	FeastArgumentFeatureRefs = "--feature_refs"
+)
+
+// FeastTransformerSpec defines a transformer enriching the requests with the online features of their entities, read
+// from a Feast feature server, before they are sent to the predictor. Each instance of a request holds the values of
+// the entities, the instances sent to the predictor hold the values of the features in the order of the feature
+// references.
+type FeastTransformerSpec struct {
+	// URL of the Feast feature server, e.g. http://feast-feature-server.feast:6566
+	FeastServingURL string `json:"feastServingUrl"`
+	// Names of the entities keying the features, read from the fields of the same names of the instances
+	EntityIDs []string `json:"entityIds"`
+	// Features added to the instances, as <feature view>:<feature>
+	FeatureRefs []string `json:"featureRefs"`
+	// Feast transformer docker image version, defaults to the version of the transformers config
+	// +optional
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Container enables overrides for the transformer.
+	// +optional
+	v1.Container `json:",inline"`
+}
+
+var _ ComponentImplementation = &FeastTransformerSpec{}
+
+var feastFeatureRefRegex = regexp.MustCompile(`^[^:\s]+:[^:\s]+$`)
+
+// Validate returns an error if invalid
+func (s *FeastTransformerSpec) Validate() error {
+	if u, err := url.Parse(s.FeastServingURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf(InvalidFeastServingURLError, s.FeastServingURL)
+	}
+	if len(s.EntityIDs) == 0 {
+		return fmt.Errorf(MissingFeastEntityIDsError)
+	}
+	if len(s.FeatureRefs) == 0 {
+		return fmt.Errorf(MissingFeastFeatureRefsError)
+	}
+	for _, ref := range s.FeatureRefs {
+		if !feastFeatureRefRegex.MatchString(ref) {
+			return fmt.Errorf(InvalidFeastFeatureRefError, ref)
+		}
+	}
+	return nil
+}
+
+// Default sets defaults on the resource
+func (s *FeastTransformerSpec) Default(config *InferenceServicesConfig) {
+	s.Name = constants.InferenceServiceContainerName
+	if s.RuntimeVersion == nil {
+		s.RuntimeVersion = proto.String(config.Transformers.Feast.DefaultImageVersion)
+	}
+	setResourceRequirementDefaults(&s.Resources)
+}
+
+// GetStorageUri returns nil, the features are read from the feature server
+func (s *FeastTransformerSpec) GetStorageUri() *string {
+	return nil
+}
+
+// GetContainer transforms the resource into a container spec
+func (s *FeastTransformerSpec) GetContainer(metadata metav1.ObjectMeta, extensions *ComponentExtensionSpec, config *InferenceServicesConfig) *v1.Container {
+	args := []string{
+		constants.ArgumentModelName, metadata.Name,
+		constants.ArgumentPredictorHost, fmt.Sprintf("%s.%s", constants.DefaultPredictorServiceName(metadata.Name), metadata.Namespace),
+		constants.ArgumentHttpPort, constants.InferenceServiceDefaultHttpPort,
+	}
+	if extensions.ContainerConcurrency != nil {
+		args = append(args, constants.ArgumentWorkers, strconv.FormatInt(*extensions.ContainerConcurrency, 10))
+	}
+	args = append(args, FeastArgumentServingURL, s.FeastServingURL, FeastArgumentEntityIDs)
+	args = append(args, s.EntityIDs...)
+	args = append(args, FeastArgumentFeatureRefs)
+	args = append(args, s.FeatureRefs...)
+	if s.Container.Image == "" {
+		s.Image = config.Transformers.Feast.ContainerImage + ":" + *s.RuntimeVersion
+	}
+	s.Name = constants.InferenceServiceContainerName
+	s.Args = args
+	return &s.Container
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newFeastTransformerSpec() *FeastTransformerSpec {
+	return &FeastTransformerSpec{
+		FeastServingURL: "http://feast-feature-server.feast:6566",
+		EntityIDs:       []string{"driver_id"},
+		FeatureRefs:     []string{"driver_hourly_stats:conv_rate", "driver_hourly_stats:acc_rate"},
+	}
+}
+
+func TestFeastValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		update  func(spec *FeastTransformerSpec)
+		matcher types.GomegaMatcher
+	}{
+		"Valid": {
+			update:  func(spec *FeastTransformerSpec) {},
+			matcher: gomega.BeNil(),
+		},
+		"InvalidServingURL": {
+			update:  func(spec *FeastTransformerSpec) { spec.FeastServingURL = "feast-feature-server:6566" },
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidFeastServingURLError, "feast-feature-server:6566")),
+		},
+		"MissingEntityIDs": {
+			update:  func(spec *FeastTransformerSpec) { spec.EntityIDs = nil },
+			matcher: gomega.MatchError(MissingFeastEntityIDsError),
+		},
+		"MissingFeatureRefs": {
+			update:  func(spec *FeastTransformerSpec) { spec.FeatureRefs = nil },
+			matcher: gomega.MatchError(MissingFeastFeatureRefsError),
+		},
+		"InvalidFeatureRef": {
+			update:  func(spec *FeastTransformerSpec) { spec.FeatureRefs = []string{"conv_rate"} },
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidFeastFeatureRefError, "conv_rate")),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			spec := newFeastTransformerSpec()
+			scenario.update(spec)
+			res := spec.Validate()
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
+
+func TestFeastTransformer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := &InferenceServicesConfig{
+		Transformers: TransformersConfig{
+			Feast: TransformerConfig{
+				ContainerImage:      "gcr.io/kfserving/feast-transformer",
+				DefaultImageVersion: "v0.4.0",
+			},
+		},
+	}
+	transformer := &TransformerSpec{
+		Feast:                  newFeastTransformerSpec(),
+		ComponentExtensionSpec: ComponentExtensionSpec{ContainerConcurrency: proto.Int64(4)},
+	}
+	g.Expect(transformer.GetImplementations()).To(gomega.HaveLen(1))
+	g.Expect(transformer.GetImplementation()).To(gomega.BeIdenticalTo(transformer.Feast))
+	g.Expect(transformer.Feast.GetStorageUri()).To(gomega.BeNil())
+
+	transformer.Feast.Default(config)
+	g.Expect(transformer.Feast.RuntimeVersion).To(gomega.Equal(proto.String("v0.4.0")))
+
+	container := transformer.Feast.GetContainer(metav1.ObjectMeta{Name: "driver", Namespace: "default"},
+		transformer.GetExtensions(), config)
+	g.Expect(container.Name).To(gomega.Equal(constants.InferenceServiceContainerName))
+	g.Expect(container.Image).To(gomega.Equal("gcr.io/kfserving/feast-transformer:v0.4.0"))
+	g.Expect(container.Args).To(gomega.Equal([]string{
+		"--model_name", "driver",
+		"--predictor_host", "driver-predictor-default.default",
+		"--http_port", "8080",
+		"--workers", "4",
+		"--feast_serving_url", "http://feast-feature-server.feast:6566",
+		"--entity_ids", "driver_id",
+		"--feature_refs", "driver_hourly_stats:conv_rate", "driver_hourly_stats:acc_rate",
+	}))
+
+	// The image of the container overrides the image of the transformers config
+	transformer.Feast.Container = v1.Container{Image: "feast-transformer:dev"}
+	container = transformer.Feast.GetContainer(metav1.ObjectMeta{Name: "driver", Namespace: "default"},
+		transformer.GetExtensions(), config)
+	g.Expect(container.Image).To(gomega.Equal("feast-transformer:dev"))
+
+	// The custom containers and the Feast transformer are mutually exclusive
+	transformer.PodSpec = PodSpec{Containers: []v1.Container{{Image: "transformer:v1"}}}
+	g.Expect(validateExactlyOneImplementation(transformer)).NotTo(gomega.Succeed())
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+// Known error messages
+const (
+	VolumeNameConflictError        = "Volume name %q of the predictor is already used or reserved."
+	VolumeMountNotFoundError       = "Volume mount %q of the predictor container has no matching volume."
+	VolumeMountPathConflictError   = "Mount path %q of the predictor container is reserved for the model."
+	modelVolumeName                = "kfserving-provision-location"
+	modelConversionCacheVolumeName = "kfserving-conversion-cache"
+)
+
+// validateVolumes validates that the volumes of the predictor are not named after the volumes added by KFServing, and
+// that the volume mounts of the predictor container match a volume and leave the model mount path to the
+// StorageInitializer
+func validateVolumes(predictor *PredictorSpec) error {
+	volumes := map[string]bool{}
+	for _, volume := range predictor.Volumes {
+		if volumes[volume.Name] || volume.Name == modelVolumeName || volume.Name == modelConversionCacheVolumeName {
+			return fmt.Errorf(VolumeNameConflictError, volume.Name)
+		}
+		volumes[volume.Name] = true
+	}
+	container := predictorContainer(predictor)
+	if container == nil {
+		return nil
+	}
+	hasStorageURI := false
+	if implementations := predictor.GetImplementations(); len(implementations) != 0 {
+		hasStorageURI = implementations[0].GetStorageUri() != nil
+	}
+	for _, mount := range container.VolumeMounts {
+		if !volumes[mount.Name] && mount.Name != modelVolumeName {
+			return fmt.Errorf(VolumeMountNotFoundError, mount.Name)
+		}
+		if hasStorageURI && filepath.Clean(mount.MountPath) == constants.DefaultModelLocalMountPath {
+			return fmt.Errorf(VolumeMountPathConflictError, mount.MountPath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestVolumeValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	shm := v1.Volume{Name: "dshm", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory}}}
+	triton := func(mounts ...v1.VolumeMount) PredictorSpec {
+		return PredictorSpec{Triton: &TritonSpec{PredictorExtensionSpec: PredictorExtensionSpec{
+			StorageURI: proto.String("gs://kfserving-samples/models/triton/bert"),
+			Container:  v1.Container{VolumeMounts: mounts},
+		}}}
+	}
+	scenarios := map[string]struct {
+		predictor PredictorSpec
+		volumes   []v1.Volume
+		matcher   types.GomegaMatcher
+	}{
+		"NoVolumes": {
+			predictor: triton(),
+			matcher:   gomega.BeNil(),
+		},
+		"SharedMemory": {
+			predictor: triton(v1.VolumeMount{Name: "dshm", MountPath: "/dev/shm"}),
+			volumes:   []v1.Volume{shm},
+			matcher:   gomega.BeNil(),
+		},
+		"ModelVolumeMount": {
+			predictor: triton(v1.VolumeMount{Name: "kfserving-provision-location", MountPath: "/models", ReadOnly: true}),
+			matcher:   gomega.BeNil(),
+		},
+		"DuplicateVolumeName": {
+			predictor: triton(),
+			volumes:   []v1.Volume{shm, shm},
+			matcher:   gomega.MatchError(fmt.Sprintf(VolumeNameConflictError, "dshm")),
+		},
+		"ReservedVolumeName": {
+			predictor: triton(),
+			volumes:   []v1.Volume{{Name: "kfserving-provision-location"}},
+			matcher:   gomega.MatchError(fmt.Sprintf(VolumeNameConflictError, "kfserving-provision-location")),
+		},
+		"MountWithoutVolume": {
+			predictor: triton(v1.VolumeMount{Name: "dshm", MountPath: "/dev/shm"}),
+			matcher:   gomega.MatchError(fmt.Sprintf(VolumeMountNotFoundError, "dshm")),
+		},
+		"ModelMountPath": {
+			predictor: triton(v1.VolumeMount{Name: "dshm", MountPath: "/mnt/models/"}),
+			volumes:   []v1.Volume{shm},
+			matcher:   gomega.MatchError(fmt.Sprintf(VolumeMountPathConflictError, "/mnt/models/")),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			predictor := scenario.predictor
+			predictor.Volumes = scenario.volumes
+			res := validateVolumes(&predictor)
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
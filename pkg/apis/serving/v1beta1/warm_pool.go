@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// WarmPoolSpec defines the pre-provisioned runtime pods of the pool
+type WarmPoolSpec struct {
+	// Name of the ServingRuntime or ClusterServingRuntime run by the pods of the pool, the runtime must load the
+	// models through the v2 model repository API (e.g. Triton started with --model-control-mode=explicit).
+	Runtime string `json:"runtime"`
+	// Number of unclaimed pods kept running
+	Replicas int32 `json:"replicas"`
+	// Type of the GPU attached to the pods, the value of the GKE accelerator node label (e.g. nvidia-tesla-t4)
+	// +optional
+	GPUType *string `json:"gpuType,omitempty"`
+	// Compute resources of the runtime container, override the resources of the runtime
+	// +optional
+	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+	// Secrets of the registries the images of the runtime are pulled from, merged with the image pull secrets of the
+	// registry config of the cluster
+	// +optional
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// WarmPoolClaim is a pod of the pool serving the model of an InferenceService
+type WarmPoolClaim struct {
+	// Name of the InferenceService claiming the pod
+	InferenceService string `json:"inferenceService"`
+	// Name of the claimed pod
+	Pod string `json:"pod"`
+}
+
+// WarmPoolStatus defines the observed state of WarmPool
+type WarmPoolStatus struct {
+	// Conditions for the warm pool
+	duckv1.Status `json:",inline"`
+	// Number of ready pods that can be claimed
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+	// Pods claimed by InferenceServices
+	// +optional
+	Claims []WarmPoolClaim `json:"claims,omitempty"`
+	// Digests the images of the containers of the pool were resolved to, keyed by container name, when the registry
+	// config resolves the image digests. The pods of the pool are deployed by digest.
+	// +optional
+	ResolvedImages map[string]ResolvedImage `json:"resolvedImages,omitempty"`
+}
+
+// WarmPool keeps runtime pods running so that new InferenceServices are served by an already running model server
+// while their predictor is created. InferenceServices claim a pod of the pool with the serving.kubeflow.org/warm-pool
+// annotation, the claimed pod loads the model and serves it until the predictor is ready.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Runtime",type="string",JSONPath=".spec.runtime"
+// +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableReplicas"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=warmpools,singular=warmpool
+type WarmPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              WarmPoolSpec   `json:"spec,omitempty"`
+	Status            WarmPoolStatus `json:"status,omitempty"`
+}
+
+// WarmPoolList contains a list of WarmPool
+// +kubebuilder:object:root=true
+type WarmPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WarmPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WarmPool{}, &WarmPoolList{})
+}
+
+var warmPoolCondSet = apis.NewLivingConditionSet()
+
+// InitializeConditions sets the initial values to the conditions
+func (s *WarmPoolStatus) InitializeConditions() {
+	warmPoolCondSet.Manage(s).InitializeConditions()
+}
+
+// MarkReady marks the pool ready, the pool is ready when at least one pod can be claimed
+func (s *WarmPoolStatus) MarkReady() {
+	warmPoolCondSet.Manage(s).MarkTrue(apis.ConditionReady)
+}
+
+// MarkNotReady marks the pool not ready with the reason and message
+func (s *WarmPoolStatus) MarkNotReady(reason, message string) {
+	warmPoolCondSet.Manage(s).MarkFalse(apis.ConditionReady, reason, message)
+}
+
+// GetClaim returns the pod claimed by the inference service
+func (s *WarmPoolStatus) GetClaim(inferenceService string) (string, bool) {
+	for _, claim := range s.Claims {
+		if claim.InferenceService == inferenceService {
+			return claim.Pod, true
+		}
+	}
+	return "", false
+}
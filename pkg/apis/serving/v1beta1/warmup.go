@@ -0,0 +1,124 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Known error messages
+const (
+	MissingWarmupRequestsError       = "Warmup requires requests or the storageUri of the request files."
+	MissingWarmupRequestBodyError    = "Warmup request %d requires a body."
+	InvalidWarmupRequestPathError    = "Warmup request path %q must start with /."
+	WarmupIterationsLowerBoundError  = "Warmup iterations cannot be less than 1."
+	WarmupTimeoutLowerBoundError     = "Warmup timeoutSeconds cannot be less than 1."
+	UnsupportedWarmupStorageURIError = "Warmup storageUri %q is not supported, the request files can not be read from a PVC."
+)
+
+// Defaults of the warmup
+const (
+	DefaultWarmupIterations     = 1
+	DefaultWarmupTimeoutSeconds = 300
+)
+
+// WarmupSpec defines the requests replayed against each new predictor pod once its model is loaded and before the pod
+// receives traffic, e.g. to compile the kernels of the model ahead of the first requests of a rollout
+type WarmupSpec struct {
+	// Sample requests replayed in order
+	// +optional
+	Requests []WarmupRequest `json:"requests,omitempty"`
+	// URI of a directory of request files, each file is the JSON body of a request to the predict endpoint of the
+	// predictor. The files are replayed in the order of their names after the sample requests.
+	// +optional
+	StorageURI *string `json:"storageUri,omitempty"`
+	// Number of times the requests are replayed, defaults to 1
+	// +optional
+	Iterations *int `json:"iterations,omitempty"`
+	// Seconds the requests are replayed for once the model is loaded, the pod then receives traffic even when the
+	// requests are not all replayed. Defaults to 300.
+	// +optional
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty"`
+}
+
+// WarmupRequest is a sample request of the warmup
+type WarmupRequest struct {
+	// Path of the request, defaults to the predict endpoint of the protocol of the predictor: /v1/models/<name>:predict
+	// or /v2/models/<name>/infer
+	// +optional
+	Path string `json:"path,omitempty"`
+	// JSON body of the request
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Body runtime.RawExtension `json:"body"`
+}
+
+// GetIterations returns the number of times the requests are replayed
+func (w *WarmupSpec) GetIterations() int {
+	if w.Iterations == nil {
+		return DefaultWarmupIterations
+	}
+	return *w.Iterations
+}
+
+// GetTimeoutSeconds returns the time the requests are replayed for
+func (w *WarmupSpec) GetTimeoutSeconds() int {
+	if w.TimeoutSeconds == nil {
+		return DefaultWarmupTimeoutSeconds
+	}
+	return *w.TimeoutSeconds
+}
+
+// Validate returns an error if invalid
+func (w *WarmupSpec) Validate() error {
+	if len(w.Requests) == 0 && w.StorageURI == nil {
+		return fmt.Errorf(MissingWarmupRequestsError)
+	}
+	for i, request := range w.Requests {
+		if len(request.Body.Raw) == 0 {
+			return fmt.Errorf(MissingWarmupRequestBodyError, i)
+		}
+		if request.Path != "" && !strings.HasPrefix(request.Path, "/") {
+			return fmt.Errorf(InvalidWarmupRequestPathError, request.Path)
+		}
+	}
+	if w.StorageURI != nil {
+		if err := validateStorageURI(w.StorageURI); err != nil {
+			return err
+		}
+		if strings.HasPrefix(*w.StorageURI, "pvc://") {
+			return fmt.Errorf(UnsupportedWarmupStorageURIError, *w.StorageURI)
+		}
+	}
+	if w.GetIterations() < 1 {
+		return fmt.Errorf(WarmupIterationsLowerBoundError)
+	}
+	if w.GetTimeoutSeconds() < 1 {
+		return fmt.Errorf(WarmupTimeoutLowerBoundError)
+	}
+	return nil
+}
+
+// validateWarmup validates the warmup of the predictor
+func validateWarmup(predictor *PredictorSpec) error {
+	if predictor.Warmup == nil {
+		return nil
+	}
+	return predictor.Warmup.Validate()
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWarmupValidation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	body := runtime.RawExtension{Raw: []byte(`{"instances":[[1,2]]}`)}
+	scenarios := map[string]struct {
+		warmup  *WarmupSpec
+		matcher types.GomegaMatcher
+	}{
+		"NoWarmup": {
+			matcher: gomega.BeNil(),
+		},
+		"Requests": {
+			warmup: &WarmupSpec{
+				Requests:   []WarmupRequest{{Body: body}, {Path: "/v1/models/foo:explain", Body: body}},
+				Iterations: GetIntReference(5),
+			},
+			matcher: gomega.BeNil(),
+		},
+		"RequestFiles": {
+			warmup:  &WarmupSpec{StorageURI: proto.String("s3://warmup/requests")},
+			matcher: gomega.BeNil(),
+		},
+		"NoRequests": {
+			warmup:  &WarmupSpec{},
+			matcher: gomega.MatchError(MissingWarmupRequestsError),
+		},
+		"MissingBody": {
+			warmup:  &WarmupSpec{Requests: []WarmupRequest{{Body: body}, {Path: "/v1/models/foo:predict"}}},
+			matcher: gomega.MatchError(fmt.Sprintf(MissingWarmupRequestBodyError, 1)),
+		},
+		"RelativePath": {
+			warmup:  &WarmupSpec{Requests: []WarmupRequest{{Path: "predict", Body: body}}},
+			matcher: gomega.MatchError(fmt.Sprintf(InvalidWarmupRequestPathError, "predict")),
+		},
+		"PVCStorageURI": {
+			warmup:  &WarmupSpec{StorageURI: proto.String("pvc://warmup/requests")},
+			matcher: gomega.MatchError(fmt.Sprintf(UnsupportedWarmupStorageURIError, "pvc://warmup/requests")),
+		},
+		"UnsupportedStorageURI": {
+			warmup:  &WarmupSpec{StorageURI: proto.String("ftp://warmup/requests")},
+			matcher: gomega.HaveOccurred(),
+		},
+		"ZeroIterations": {
+			warmup:  &WarmupSpec{Requests: []WarmupRequest{{Body: body}}, Iterations: GetIntReference(0)},
+			matcher: gomega.MatchError(WarmupIterationsLowerBoundError),
+		},
+		"ZeroTimeout": {
+			warmup:  &WarmupSpec{Requests: []WarmupRequest{{Body: body}}, TimeoutSeconds: GetIntReference(0)},
+			matcher: gomega.MatchError(WarmupTimeoutLowerBoundError),
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			res := validateWarmup(&PredictorSpec{Warmup: scenario.warmup})
+			if !g.Expect(res).To(scenario.matcher) {
+				t.Errorf("got %q, want %q", res, scenario.matcher)
+			}
+		})
+	}
+}
@@ -21,10 +21,13 @@ limitations under the License.
 package v1beta1
 
 import (
+	"github.com/kubeflow/kfserving/pkg/constants"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/apis"
-	"knative.dev/pkg/apis/duck/v1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -83,6 +86,159 @@ func (in *AlibiExplainerSpec) DeepCopy() *AlibiExplainerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AsyncSpec) DeepCopyInto(out *AsyncSpec) {
+	*out = *in
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxQueueDepth != nil {
+		in, out := &in.MaxQueueDepth, &out.MaxQueueDepth
+		*out = new(int)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.ResultTTLSeconds != nil {
+		in, out := &in.ResultTTLSeconds, &out.ResultTTLSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.QueueDepthTarget != nil {
+		in, out := &in.QueueDepthTarget, &out.QueueDepthTarget
+		*out = new(int)
+		**out = **in
+	}
+	if in.CallbackHosts != nil {
+		in, out := &in.CallbackHosts, &out.CallbackHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AsyncSpec.
+func (in *AsyncSpec) DeepCopy() *AsyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AsyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchInferenceJob) DeepCopyInto(out *BatchInferenceJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchInferenceJob.
+func (in *BatchInferenceJob) DeepCopy() *BatchInferenceJob {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchInferenceJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchInferenceJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchInferenceJobList) DeepCopyInto(out *BatchInferenceJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BatchInferenceJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchInferenceJobList.
+func (in *BatchInferenceJobList) DeepCopy() *BatchInferenceJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchInferenceJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchInferenceJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchInferenceJobSpec) DeepCopyInto(out *BatchInferenceJobSpec) {
+	*out = *in
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchInferenceJobSpec.
+func (in *BatchInferenceJobSpec) DeepCopy() *BatchInferenceJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchInferenceJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchInferenceJobStatus) DeepCopyInto(out *BatchInferenceJobStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchInferenceJobStatus.
+func (in *BatchInferenceJobStatus) DeepCopy() *BatchInferenceJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchInferenceJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Batcher) DeepCopyInto(out *Batcher) {
 	*out = *in
@@ -101,6 +257,11 @@ func (in *Batcher) DeepCopyInto(out *Batcher) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.MaxQueueDepth != nil {
+		in, out := &in.MaxQueueDepth, &out.MaxQueueDepth
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Batcher.
@@ -113,6 +274,171 @@ func (in *Batcher) DeepCopy() *Batcher {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryAnalysisSpec) DeepCopyInto(out *CanaryAnalysisSpec) {
+	*out = *in
+	if in.MaxErrorPercent != nil {
+		in, out := &in.MaxErrorPercent, &out.MaxErrorPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxP99LatencyMilliseconds != nil {
+		in, out := &in.MaxP99LatencyMilliseconds, &out.MaxP99LatencyMilliseconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryAnalysisSpec.
+func (in *CanaryAnalysisSpec) DeepCopy() *CanaryAnalysisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryAnalysisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMatch) DeepCopyInto(out *CanaryMatch) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Cookies != nil {
+		in, out := &in.Cookies, &out.Cookies
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMatch.
+func (in *CanaryMatch) DeepCopy() *CanaryMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BaseEjectionTime != nil {
+		in, out := &in.BaseEjectionTime, &out.BaseEjectionTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreaker.
+func (in *CircuitBreaker) DeepCopy() *CircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterServingRuntime) DeepCopyInto(out *ClusterServingRuntime) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterServingRuntime.
+func (in *ClusterServingRuntime) DeepCopy() *ClusterServingRuntime {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterServingRuntime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterServingRuntime) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterServingRuntimeList) DeepCopyInto(out *ClusterServingRuntimeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterServingRuntime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterServingRuntimeList.
+func (in *ClusterServingRuntimeList) DeepCopy() *ClusterServingRuntimeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterServingRuntimeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterServingRuntimeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentCostStatus) DeepCopyInto(out *ComponentCostStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentCostStatus.
+func (in *ComponentCostStatus) DeepCopy() *ComponentCostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentCostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 	*out = *in
@@ -131,11 +457,58 @@ func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.MaxRequestBytes != nil {
+		in, out := &in.MaxRequestBytes, &out.MaxRequestBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxResponseBytes != nil {
+		in, out := &in.MaxResponseBytes, &out.MaxResponseBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hedging != nil {
+		in, out := &in.Hedging, &out.Hedging
+		*out = new(HedgingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CanaryTrafficPercent != nil {
 		in, out := &in.CanaryTrafficPercent, &out.CanaryTrafficPercent
 		*out = new(int64)
 		**out = **in
 	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(string)
+		**out = **in
+	}
+	if in.CanaryMatch != nil {
+		in, out := &in.CanaryMatch, &out.CanaryMatch
+		*out = make([]CanaryMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CanaryAnalysis != nil {
+		in, out := &in.CanaryAnalysis, &out.CanaryAnalysis
+		*out = new(CanaryAnalysisSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModelDigest != nil {
+		in, out := &in.ModelDigest, &out.ModelDigest
+		*out = new(string)
+		**out = **in
+	}
 	if in.Logger != nil {
 		in, out := &in.Logger, &out.Logger
 		*out = new(LoggerSpec)
@@ -146,125 +519,1458 @@ func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
 		*out = new(Batcher)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ResponseCache != nil {
+		in, out := &in.ResponseCache, &out.ResponseCache
+		*out = new(ResponseCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RevisionAnnotations != nil {
+		in, out := &in.RevisionAnnotations, &out.RevisionAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SidecarInjection != nil {
+		in, out := &in.SidecarInjection, &out.SidecarInjection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ScaleMetric != nil {
+		in, out := &in.ScaleMetric, &out.ScaleMetric
+		*out = new(ScaleMetric)
+		**out = **in
+	}
+	if in.ScaleTarget != nil {
+		in, out := &in.ScaleTarget, &out.ScaleTarget
+		*out = new(int)
+		**out = **in
+	}
+	if in.TargetUtilizationPercentage != nil {
+		in, out := &in.TargetUtilizationPercentage, &out.TargetUtilizationPercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.Keda != nil {
+		in, out := &in.Keda, &out.Keda
+		*out = new(KedaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestsPerSecond != nil {
+		in, out := &in.RequestsPerSecond, &out.RequestsPerSecond
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Burst != nil {
+		in, out := &in.Burst, &out.Burst
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scaling != nil {
+		in, out := &in.Scaling, &out.Scaling
+		*out = new(ScalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentExtensionSpec.
+func (in *ComponentExtensionSpec) DeepCopy() *ComponentExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatusSpec) DeepCopyInto(out *ComponentStatusSpec) {
+	*out = *in
+	if in.TrafficPercent != nil {
+		in, out := &in.TrafficPercent, &out.TrafficPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(duckv1.Addressable)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastActivationTime != nil {
+		in, out := &in.LastActivationTime, &out.LastActivationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RevisionHistory != nil {
+		in, out := &in.RevisionHistory, &out.RevisionHistory
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HedgingDelay != nil {
+		in, out := &in.HedgingDelay, &out.HedgingDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ResolvedImages != nil {
+		in, out := &in.ResolvedImages, &out.ResolvedImages
+		*out = make(map[string]ResolvedImage, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatusSpec.
+func (in *ComponentStatusSpec) DeepCopy() *ComponentStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CostStatus) DeepCopyInto(out *CostStatus) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[ComponentType]ComponentCostStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CostStatus.
+func (in *CostStatus) DeepCopy() *CostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomExplainer) DeepCopyInto(out *CustomExplainer) {
+	*out = *in
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomExplainer.
+func (in *CustomExplainer) DeepCopy() *CustomExplainer {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomExplainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomPredictor) DeepCopyInto(out *CustomPredictor) {
+	*out = *in
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPredictor.
+func (in *CustomPredictor) DeepCopy() *CustomPredictor {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomPredictor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomTransformer) DeepCopyInto(out *CustomTransformer) {
+	*out = *in
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomTransformer.
+func (in *CustomTransformer) DeepCopy() *CustomTransformer {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomTransformer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudgetSpec) DeepCopyInto(out *DisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetSpec.
+func (in *DisruptionBudgetSpec) DeepCopy() *DisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExplainerSpec) DeepCopyInto(out *ExplainerSpec) {
+	*out = *in
+	if in.Alibi != nil {
+		in, out := &in.Alibi, &out.Alibi
+		*out = new(AlibiExplainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AIX != nil {
+		in, out := &in.AIX, &out.AIX
+		*out = new(AIXExplainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+	if in.SamplingPercent != nil {
+		in, out := &in.SamplingPercent, &out.SamplingPercent
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExplainerSpec.
+func (in *ExplainerSpec) DeepCopy() *ExplainerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExplainerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeastTransformerSpec) DeepCopyInto(out *FeastTransformerSpec) {
+	*out = *in
+	if in.EntityIDs != nil {
+		in, out := &in.EntityIDs, &out.EntityIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FeatureRefs != nil {
+		in, out := &in.FeatureRefs, &out.FeatureRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeastTransformerSpec.
+func (in *FeastTransformerSpec) DeepCopy() *FeastTransformerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FeastTransformerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedClusterStatus) DeepCopyInto(out *FederatedClusterStatus) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedClusterStatus.
+func (in *FederatedClusterStatus) DeepCopy() *FederatedClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationSpec) DeepCopyInto(out *FederationSpec) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationSpec.
+func (in *FederationSpec) DeepCopy() *FederationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationStatus) DeepCopyInto(out *FederationStatus) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]FederatedClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationStatus.
+func (in *FederationStatus) DeepCopy() *FederationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUSpec) DeepCopyInto(out *GPUSpec) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUSpec.
+func (in *GPUSpec) DeepCopy() *GPUSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HedgingSpec) DeepCopyInto(out *HedgingSpec) {
+	*out = *in
+	if in.Percentile != nil {
+		in, out := &in.Percentile, &out.Percentile
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxHedges != nil {
+		in, out := &in.MaxHedges, &out.MaxHedges
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HedgingSpec.
+func (in *HedgingSpec) DeepCopy() *HedgingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HedgingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceService) DeepCopyInto(out *InferenceService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceService.
+func (in *InferenceService) DeepCopy() *InferenceService {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceList) DeepCopyInto(out *InferenceServiceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InferenceService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceList.
+func (in *InferenceServiceList) DeepCopy() *InferenceServiceList {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceServiceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceSpec) DeepCopyInto(out *InferenceServiceSpec) {
+	*out = *in
+	in.Predictor.DeepCopyInto(&out.Predictor)
+	if in.Explainer != nil {
+		in, out := &in.Explainer, &out.Explainer
+		*out = new(ExplainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transformer != nil {
+		in, out := &in.Transformer, &out.Transformer
+		*out = new(TransformerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(FederationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceSpec.
+func (in *InferenceServiceSpec) DeepCopy() *InferenceServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceStatus) DeepCopyInto(out *InferenceServiceStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(duckv1.Addressable)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(apis.URL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[ComponentType]ComponentStatusSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ServingMetrics != nil {
+		in, out := &in.ServingMetrics, &out.ServingMetrics
+		*out = new(ServingMetricsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModelVersions != nil {
+		in, out := &in.ModelVersions, &out.ModelVersions
+		*out = make([]ModelVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ModelMetadata != nil {
+		in, out := &in.ModelMetadata, &out.ModelMetadata
+		*out = new(ModelMetadataStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(FederationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cost != nil {
+		in, out := &in.Cost, &out.Cost
+		*out = new(CostStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRequestTime != nil {
+		in, out := &in.LastRequestTime, &out.LastRequestTime
+		*out = (*in).DeepCopy()
+	}
+	if in.IdleActionPendingTime != nil {
+		in, out := &in.IdleActionPendingTime, &out.IdleActionPendingTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceStatus.
+func (in *InferenceServiceStatus) DeepCopy() *InferenceServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaSpec) DeepCopyInto(out *KedaSpec) {
+	*out = *in
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = make([]KedaTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaSpec.
+func (in *KedaSpec) DeepCopy() *KedaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KedaTrigger) DeepCopyInto(out *KedaTrigger) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AuthenticationRef != nil {
+		in, out := &in.AuthenticationRef, &out.AuthenticationRef
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KedaTrigger.
+func (in *KedaTrigger) DeepCopy() *KedaTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(KedaTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LightGBMSpec) DeepCopyInto(out *LightGBMSpec) {
+	*out = *in
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LightGBMSpec.
+func (in *LightGBMSpec) DeepCopy() *LightGBMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LightGBMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggerSpec) DeepCopyInto(out *LoggerSpec) {
+	*out = *in
+	if in.URL != nil {
+		in, out := &in.URL, &out.URL
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggerSpec.
+func (in *LoggerSpec) DeepCopy() *LoggerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MLflowSpec) DeepCopyInto(out *MLflowSpec) {
+	*out = *in
+	if in.Flavor != nil {
+		in, out := &in.Flavor, &out.Flavor
+		*out = new(MLflowFlavor)
+		**out = **in
+	}
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MLflowSpec.
+func (in *MLflowSpec) DeepCopy() *MLflowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MLflowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelConversionSpec) DeepCopyInto(out *ModelConversionSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.CacheVolumeClaim != nil {
+		in, out := &in.CacheVolumeClaim, &out.CacheVolumeClaim
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelConversionSpec.
+func (in *ModelConversionSpec) DeepCopy() *ModelConversionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelConversionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelFormat) DeepCopyInto(out *ModelFormat) {
+	*out = *in
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelFormat.
+func (in *ModelFormat) DeepCopy() *ModelFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelMetadata) DeepCopyInto(out *ModelMetadata) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]TensorMetadata, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]TensorMetadata, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelMetadata.
+func (in *ModelMetadata) DeepCopy() *ModelMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelMetadataStatus) DeepCopyInto(out *ModelMetadataStatus) {
+	*out = *in
+	in.ModelMetadata.DeepCopyInto(&out.ModelMetadata)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelMetadataStatus.
+func (in *ModelMetadataStatus) DeepCopy() *ModelMetadataStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelMetadataStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelPredictorSpec) DeepCopyInto(out *ModelPredictorSpec) {
+	*out = *in
+	in.ModelFormat.DeepCopyInto(&out.ModelFormat)
+	if in.Runtime != nil {
+		in, out := &in.Runtime, &out.Runtime
+		*out = new(string)
+		**out = **in
+	}
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelPredictorSpec.
+func (in *ModelPredictorSpec) DeepCopy() *ModelPredictorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelPredictorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelReadinessSpec) DeepCopyInto(out *ModelReadinessSpec) {
+	*out = *in
+	if in.LoadTimeoutSeconds != nil {
+		in, out := &in.LoadTimeoutSeconds, &out.LoadTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelReadinessSpec.
+func (in *ModelReadinessSpec) DeepCopy() *ModelReadinessSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelReadinessSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
+	*out = *in
+	out.Memory = in.Memory.DeepCopy()
+	if in.LoadPolicy != nil {
+		in, out := &in.LoadPolicy, &out.LoadPolicy
+		*out = new(LoadPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
+func (in *ModelSpec) DeepCopy() *ModelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelVersion) DeepCopyInto(out *ModelVersion) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.DeployedAt != nil {
+		in, out := &in.DeployedAt, &out.DeployedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersion.
+func (in *ModelVersion) DeepCopy() *ModelVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ONNXRuntimeSpec) DeepCopyInto(out *ONNXRuntimeSpec) {
+	*out = *in
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ONNXRuntimeSpec.
+func (in *ONNXRuntimeSpec) DeepCopy() *ONNXRuntimeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ONNXRuntimeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PMMLSpec) DeepCopyInto(out *PMMLSpec) {
+	*out = *in
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PMMLSpec.
+func (in *PMMLSpec) DeepCopy() *PMMLSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PMMLSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PaddleSpec) DeepCopyInto(out *PaddleSpec) {
+	*out = *in
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PaddleSpec.
+func (in *PaddleSpec) DeepCopy() *PaddleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PaddleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParallelismSpec) DeepCopyInto(out *ParallelismSpec) {
+	*out = *in
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParallelismSpec.
+func (in *ParallelismSpec) DeepCopy() *ParallelismSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ParallelismSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSpec) DeepCopyInto(out *PodSpec) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EphemeralContainers != nil {
+		in, out := &in.EphemeralContainers, &out.EphemeralContainers
+		*out = make([]corev1.EphemeralContainer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ShareProcessNamespace != nil {
+		in, out := &in.ShareProcessNamespace, &out.ShareProcessNamespace
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]corev1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]corev1.PodReadinessGate, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.EnableServiceLinks != nil {
+		in, out := &in.EnableServiceLinks, &out.EnableServiceLinks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(corev1.PreemptionPolicy)
+		**out = **in
+	}
+	if in.Overhead != nil {
+		in, out := &in.Overhead, &out.Overhead
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSpec.
+func (in *PodSpec) DeepCopy() *PodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorCallSpec) DeepCopyInto(out *PredictorCallSpec) {
+	*out = *in
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorCallSpec.
+func (in *PredictorCallSpec) DeepCopy() *PredictorCallSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictorCallSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorExtensionSpec) DeepCopyInto(out *PredictorExtensionSpec) {
+	*out = *in
+	if in.StorageURI != nil {
+		in, out := &in.StorageURI, &out.StorageURI
+		*out = new(string)
+		**out = **in
+	}
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.ProtocolVersion != nil {
+		in, out := &in.ProtocolVersion, &out.ProtocolVersion
+		*out = new(constants.InferenceServiceProtocol)
+		**out = **in
+	}
+	if in.LoadPolicy != nil {
+		in, out := &in.LoadPolicy, &out.LoadPolicy
+		*out = new(LoadPolicy)
+		**out = **in
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(ParallelismSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorExtensionSpec.
+func (in *PredictorExtensionSpec) DeepCopy() *PredictorExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictorExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorSpec) DeepCopyInto(out *PredictorSpec) {
+	*out = *in
+	if in.SKLearn != nil {
+		in, out := &in.SKLearn, &out.SKLearn
+		*out = new(SKLearnSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.XGBoost != nil {
+		in, out := &in.XGBoost, &out.XGBoost
+		*out = new(XGBoostSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LightGBM != nil {
+		in, out := &in.LightGBM, &out.LightGBM
+		*out = new(LightGBMSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tensorflow != nil {
+		in, out := &in.Tensorflow, &out.Tensorflow
+		*out = new(TFServingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PyTorch != nil {
+		in, out := &in.PyTorch, &out.PyTorch
+		*out = new(TorchServeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Triton != nil {
+		in, out := &in.Triton, &out.Triton
+		*out = new(TritonSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ONNX != nil {
+		in, out := &in.ONNX, &out.ONNX
+		*out = new(ONNXRuntimeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PMML != nil {
+		in, out := &in.PMML, &out.PMML
+		*out = new(PMMLSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paddle != nil {
+		in, out := &in.Paddle, &out.Paddle
+		*out = new(PaddleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MLflow != nil {
+		in, out := &in.MLflow, &out.MLflow
+		*out = new(MLflowSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(ModelPredictorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	if in.ModelConversion != nil {
+		in, out := &in.ModelConversion, &out.ModelConversion
+		*out = new(ModelConversionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(GPUSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModelReadiness != nil {
+		in, out := &in.ModelReadiness, &out.ModelReadiness
+		*out = new(ModelReadinessSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Warmup != nil {
+		in, out := &in.Warmup, &out.Warmup
+		*out = new(WarmupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployVersion != nil {
+		in, out := &in.DeployVersion, &out.DeployVersion
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Async != nil {
+		in, out := &in.Async, &out.Async
+		*out = new(AsyncSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestValidation != nil {
+		in, out := &in.RequestValidation, &out.RequestValidation
+		*out = new(RequestValidationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorSpec.
+func (in *PredictorSpec) DeepCopy() *PredictorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PredictorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestValidationSpec) DeepCopyInto(out *RequestValidationSpec) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestValidationSpec.
+func (in *RequestValidationSpec) DeepCopy() *RequestValidationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestValidationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedImage) DeepCopyInto(out *ResolvedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedImage.
+func (in *ResolvedImage) DeepCopy() *ResolvedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseCacheSpec) DeepCopyInto(out *ResponseCacheSpec) {
+	*out = *in
+	if in.TTLSeconds != nil {
+		in, out := &in.TTLSeconds, &out.TTLSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxEntries != nil {
+		in, out := &in.MaxEntries, &out.MaxEntries
+		*out = new(int)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentExtensionSpec.
-func (in *ComponentExtensionSpec) DeepCopy() *ComponentExtensionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseCacheSpec.
+func (in *ResponseCacheSpec) DeepCopy() *ResponseCacheSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentExtensionSpec)
+	out := new(ResponseCacheSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentStatusSpec) DeepCopyInto(out *ComponentStatusSpec) {
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
 	*out = *in
-	if in.TrafficPercent != nil {
-		in, out := &in.TrafficPercent, &out.TrafficPercent
-		*out = new(int64)
+	if in.PerTryTimeout != nil {
+		in, out := &in.PerTryTimeout, &out.PerTryTimeout
+		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.URL != nil {
-		in, out := &in.URL, &out.URL
-		*out = new(apis.URL)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Address != nil {
-		in, out := &in.Address, &out.Address
-		*out = new(v1.Addressable)
-		(*in).DeepCopyInto(*out)
+	if in.RetryOn != nil {
+		in, out := &in.RetryOn, &out.RetryOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatusSpec.
-func (in *ComponentStatusSpec) DeepCopy() *ComponentStatusSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentStatusSpec)
+	out := new(RetryPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CustomExplainer) DeepCopyInto(out *CustomExplainer) {
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
 	*out = *in
-	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomExplainer.
-func (in *CustomExplainer) DeepCopy() *CustomExplainer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CustomExplainer)
+	out := new(RolloutSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CustomPredictor) DeepCopyInto(out *CustomPredictor) {
+func (in *SKLearnSpec) DeepCopyInto(out *SKLearnSpec) {
 	*out = *in
-	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomPredictor.
-func (in *CustomPredictor) DeepCopy() *CustomPredictor {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SKLearnSpec.
+func (in *SKLearnSpec) DeepCopy() *SKLearnSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(CustomPredictor)
+	out := new(SKLearnSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CustomTransformer) DeepCopyInto(out *CustomTransformer) {
+func (in *ScalingSchedule) DeepCopyInto(out *ScalingSchedule) {
 	*out = *in
-	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomTransformer.
-func (in *CustomTransformer) DeepCopy() *CustomTransformer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingSchedule.
+func (in *ScalingSchedule) DeepCopy() *ScalingSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(CustomTransformer)
+	out := new(ScalingSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExplainerSpec) DeepCopyInto(out *ExplainerSpec) {
+func (in *ScalingSpec) DeepCopyInto(out *ScalingSpec) {
 	*out = *in
-	if in.Alibi != nil {
-		in, out := &in.Alibi, &out.Alibi
-		*out = new(AlibiExplainerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScalingSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.AIX != nil {
-		in, out := &in.AIX, &out.AIX
-		*out = new(AIXExplainerSpec)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingSpec.
+func (in *ScalingSpec) DeepCopy() *ScalingSpec {
+	if in == nil {
+		return nil
 	}
-	in.PodSpec.DeepCopyInto(&out.PodSpec)
-	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+	out := new(ScalingSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExplainerSpec.
-func (in *ExplainerSpec) DeepCopy() *ExplainerSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingMetricsStatus) DeepCopyInto(out *ServingMetricsStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingMetricsStatus.
+func (in *ServingMetricsStatus) DeepCopy() *ServingMetricsStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ExplainerSpec)
+	out := new(ServingMetricsStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InferenceService) DeepCopyInto(out *InferenceService) {
+func (in *ServingQuota) DeepCopyInto(out *ServingQuota) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -272,18 +1978,18 @@ func (in *InferenceService) DeepCopyInto(out *InferenceService) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceService.
-func (in *InferenceService) DeepCopy() *InferenceService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingQuota.
+func (in *ServingQuota) DeepCopy() *ServingQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(InferenceService)
+	out := new(ServingQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InferenceService) DeepCopyObject() runtime.Object {
+func (in *ServingQuota) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -291,31 +1997,31 @@ func (in *InferenceService) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InferenceServiceList) DeepCopyInto(out *InferenceServiceList) {
+func (in *ServingQuotaList) DeepCopyInto(out *ServingQuotaList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]InferenceService, len(*in))
+		*out = make([]ServingQuota, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceList.
-func (in *InferenceServiceList) DeepCopy() *InferenceServiceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingQuotaList.
+func (in *ServingQuotaList) DeepCopy() *ServingQuotaList {
 	if in == nil {
 		return nil
 	}
-	out := new(InferenceServiceList)
+	out := new(ServingQuotaList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InferenceServiceList) DeepCopyObject() runtime.Object {
+func (in *ServingQuotaList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -323,360 +2029,252 @@ func (in *InferenceServiceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InferenceServiceSpec) DeepCopyInto(out *InferenceServiceSpec) {
+func (in *ServingQuotaSpec) DeepCopyInto(out *ServingQuotaSpec) {
 	*out = *in
-	in.Predictor.DeepCopyInto(&out.Predictor)
-	if in.Explainer != nil {
-		in, out := &in.Explainer, &out.Explainer
-		*out = new(ExplainerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.GPUs != nil {
+		in, out := &in.GPUs, &out.GPUs
+		*out = new(int64)
+		**out = **in
 	}
-	if in.Transformer != nil {
-		in, out := &in.Transformer, &out.Transformer
-		*out = new(TransformerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int64)
+		**out = **in
+	}
+	if in.InferenceServices != nil {
+		in, out := &in.InferenceServices, &out.InferenceServices
+		*out = new(int64)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceSpec.
-func (in *InferenceServiceSpec) DeepCopy() *InferenceServiceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingQuotaSpec.
+func (in *ServingQuotaSpec) DeepCopy() *ServingQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InferenceServiceSpec)
+	out := new(ServingQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InferenceServiceStatus) DeepCopyInto(out *InferenceServiceStatus) {
+func (in *ServingQuotaStatus) DeepCopyInto(out *ServingQuotaStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
-	if in.Address != nil {
-		in, out := &in.Address, &out.Address
-		*out = new(v1.Addressable)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.URL != nil {
-		in, out := &in.URL, &out.URL
-		*out = new(apis.URL)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Components != nil {
-		in, out := &in.Components, &out.Components
-		*out = make(map[ComponentType]ComponentStatusSpec, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	out.Used = in.Used
+	if in.Queued != nil {
+		in, out := &in.Queued, &out.Queued
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceServiceStatus.
-func (in *InferenceServiceStatus) DeepCopy() *InferenceServiceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingQuotaStatus.
+func (in *ServingQuotaStatus) DeepCopy() *ServingQuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(InferenceServiceStatus)
+	out := new(ServingQuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggerSpec) DeepCopyInto(out *LoggerSpec) {
+func (in *ServingQuotaUsage) DeepCopyInto(out *ServingQuotaUsage) {
 	*out = *in
-	if in.URL != nil {
-		in, out := &in.URL, &out.URL
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggerSpec.
-func (in *LoggerSpec) DeepCopy() *LoggerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingQuotaUsage.
+func (in *ServingQuotaUsage) DeepCopy() *ServingQuotaUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggerSpec)
+	out := new(ServingQuotaUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
+func (in *ServingRuntime) DeepCopyInto(out *ServingRuntime) {
 	*out = *in
-	out.Memory = in.Memory.DeepCopy()
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
-func (in *ModelSpec) DeepCopy() *ModelSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingRuntime.
+func (in *ServingRuntime) DeepCopy() *ServingRuntime {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelSpec)
+	out := new(ServingRuntime)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServingRuntime) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ONNXRuntimeSpec) DeepCopyInto(out *ONNXRuntimeSpec) {
+func (in *ServingRuntimeList) DeepCopyInto(out *ServingRuntimeList) {
 	*out = *in
-	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ServingRuntime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ONNXRuntimeSpec.
-func (in *ONNXRuntimeSpec) DeepCopy() *ONNXRuntimeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingRuntimeList.
+func (in *ServingRuntimeList) DeepCopy() *ServingRuntimeList {
 	if in == nil {
 		return nil
 	}
-	out := new(ONNXRuntimeSpec)
+	out := new(ServingRuntimeList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodSpec) DeepCopyInto(out *PodSpec) {
-	*out = *in
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]corev1.Volume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.InitContainers != nil {
-		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]corev1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Containers != nil {
-		in, out := &in.Containers, &out.Containers
-		*out = make([]corev1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.EphemeralContainers != nil {
-		in, out := &in.EphemeralContainers, &out.EphemeralContainers
-		*out = make([]corev1.EphemeralContainer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.TerminationGracePeriodSeconds != nil {
-		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.ActiveDeadlineSeconds != nil {
-		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.AutomountServiceAccountToken != nil {
-		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
-		*out = new(bool)
-		**out = **in
-	}
-	if in.ShareProcessNamespace != nil {
-		in, out := &in.ShareProcessNamespace, &out.ShareProcessNamespace
-		*out = new(bool)
-		**out = **in
-	}
-	if in.SecurityContext != nil {
-		in, out := &in.SecurityContext, &out.SecurityContext
-		*out = new(corev1.PodSecurityContext)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServingRuntimeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	if in.HostAliases != nil {
-		in, out := &in.HostAliases, &out.HostAliases
-		*out = make([]corev1.HostAlias, len(*in))
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingRuntimeSpec) DeepCopyInto(out *ServingRuntimeSpec) {
+	*out = *in
+	if in.SupportedModelFormats != nil {
+		in, out := &in.SupportedModelFormats, &out.SupportedModelFormats
+		*out = make([]SupportedModelFormat, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Priority != nil {
-		in, out := &in.Priority, &out.Priority
-		*out = new(int32)
-		**out = **in
-	}
-	if in.DNSConfig != nil {
-		in, out := &in.DNSConfig, &out.DNSConfig
-		*out = new(corev1.PodDNSConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ReadinessGates != nil {
-		in, out := &in.ReadinessGates, &out.ReadinessGates
-		*out = make([]corev1.PodReadinessGate, len(*in))
+	if in.ProtocolVersions != nil {
+		in, out := &in.ProtocolVersions, &out.ProtocolVersions
+		*out = make([]constants.InferenceServiceProtocol, len(*in))
 		copy(*out, *in)
 	}
-	if in.RuntimeClassName != nil {
-		in, out := &in.RuntimeClassName, &out.RuntimeClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.EnableServiceLinks != nil {
-		in, out := &in.EnableServiceLinks, &out.EnableServiceLinks
+	if in.Disabled != nil {
+		in, out := &in.Disabled, &out.Disabled
 		*out = new(bool)
 		**out = **in
 	}
-	if in.PreemptionPolicy != nil {
-		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
-		*out = new(corev1.PreemptionPolicy)
+	if in.GPU != nil {
+		in, out := &in.GPU, &out.GPU
+		*out = new(bool)
 		**out = **in
 	}
-	if in.Overhead != nil {
-		in, out := &in.Overhead, &out.Overhead
-		*out = make(corev1.ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
-		}
-	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSpec.
-func (in *PodSpec) DeepCopy() *PodSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingRuntimeSpec.
+func (in *ServingRuntimeSpec) DeepCopy() *ServingRuntimeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PodSpec)
+	out := new(ServingRuntimeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PredictorExtensionSpec) DeepCopyInto(out *PredictorExtensionSpec) {
+func (in *ServingRuntimeStatus) DeepCopyInto(out *ServingRuntimeStatus) {
 	*out = *in
-	if in.StorageURI != nil {
-		in, out := &in.StorageURI, &out.StorageURI
-		*out = new(string)
-		**out = **in
-	}
-	if in.RuntimeVersion != nil {
-		in, out := &in.RuntimeVersion, &out.RuntimeVersion
-		*out = new(string)
-		**out = **in
-	}
-	in.Container.DeepCopyInto(&out.Container)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorExtensionSpec.
-func (in *PredictorExtensionSpec) DeepCopy() *PredictorExtensionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingRuntimeStatus.
+func (in *ServingRuntimeStatus) DeepCopy() *ServingRuntimeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PredictorExtensionSpec)
+	out := new(ServingRuntimeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PredictorSpec) DeepCopyInto(out *PredictorSpec) {
+func (in *SupportedModelFormat) DeepCopyInto(out *SupportedModelFormat) {
 	*out = *in
-	if in.SKLearn != nil {
-		in, out := &in.SKLearn, &out.SKLearn
-		*out = new(SKLearnSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.XGBoost != nil {
-		in, out := &in.XGBoost, &out.XGBoost
-		*out = new(XGBoostSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tensorflow != nil {
-		in, out := &in.Tensorflow, &out.Tensorflow
-		*out = new(TFServingSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PyTorch != nil {
-		in, out := &in.PyTorch, &out.PyTorch
-		*out = new(TorchServeSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
 	}
-	if in.Triton != nil {
-		in, out := &in.Triton, &out.Triton
-		*out = new(TritonSpec)
-		(*in).DeepCopyInto(*out)
+	if in.AutoSelect != nil {
+		in, out := &in.AutoSelect, &out.AutoSelect
+		*out = new(bool)
+		**out = **in
 	}
-	if in.ONNX != nil {
-		in, out := &in.ONNX, &out.ONNX
-		*out = new(ONNXRuntimeSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
 	}
-	in.PodSpec.DeepCopyInto(&out.PodSpec)
-	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PredictorSpec.
-func (in *PredictorSpec) DeepCopy() *PredictorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupportedModelFormat.
+func (in *SupportedModelFormat) DeepCopy() *SupportedModelFormat {
 	if in == nil {
 		return nil
 	}
-	out := new(PredictorSpec)
+	out := new(SupportedModelFormat)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SKLearnSpec) DeepCopyInto(out *SKLearnSpec) {
+func (in *TFServingSpec) DeepCopyInto(out *TFServingSpec) {
 	*out = *in
 	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SKLearnSpec.
-func (in *SKLearnSpec) DeepCopy() *SKLearnSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFServingSpec.
+func (in *TFServingSpec) DeepCopy() *TFServingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SKLearnSpec)
+	out := new(TFServingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TFServingSpec) DeepCopyInto(out *TFServingSpec) {
+func (in *TensorMetadata) DeepCopyInto(out *TensorMetadata) {
 	*out = *in
-	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
+	if in.Shape != nil {
+		in, out := &in.Shape, &out.Shape
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TFServingSpec.
-func (in *TFServingSpec) DeepCopy() *TFServingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TensorMetadata.
+func (in *TensorMetadata) DeepCopy() *TensorMetadata {
 	if in == nil {
 		return nil
 	}
-	out := new(TFServingSpec)
+	out := new(TensorMetadata)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -778,7 +2376,12 @@ func (in *TrainedModelStatus) DeepCopyInto(out *TrainedModelStatus) {
 	in.Status.DeepCopyInto(&out.Status)
 	if in.Address != nil {
 		in, out := &in.Address, &out.Address
-		*out = new(v1.Addressable)
+		*out = new(duckv1.Addressable)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ModelMetadata)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -796,8 +2399,18 @@ func (in *TrainedModelStatus) DeepCopy() *TrainedModelStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransformerSpec) DeepCopyInto(out *TransformerSpec) {
 	*out = *in
+	if in.Feast != nil {
+		in, out := &in.Feast, &out.Feast
+		*out = new(FeastTransformerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.PodSpec.DeepCopyInto(&out.PodSpec)
 	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+	if in.PredictorCall != nil {
+		in, out := &in.PredictorCall, &out.PredictorCall
+		*out = new(PredictorCallSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformerSpec.
@@ -813,6 +2426,11 @@ func (in *TransformerSpec) DeepCopy() *TransformerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TritonSpec) DeepCopyInto(out *TritonSpec) {
 	*out = *in
+	if in.ModelControlMode != nil {
+		in, out := &in.ModelControlMode, &out.ModelControlMode
+		*out = new(TritonModelControlMode)
+		**out = **in
+	}
 	in.PredictorExtensionSpec.DeepCopyInto(&out.PredictorExtensionSpec)
 }
 
@@ -826,6 +2444,187 @@ func (in *TritonSpec) DeepCopy() *TritonSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPool) DeepCopyInto(out *WarmPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPool.
+func (in *WarmPool) DeepCopy() *WarmPool {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WarmPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPoolClaim) DeepCopyInto(out *WarmPoolClaim) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPoolClaim.
+func (in *WarmPoolClaim) DeepCopy() *WarmPoolClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPoolClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPoolList) DeepCopyInto(out *WarmPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WarmPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPoolList.
+func (in *WarmPoolList) DeepCopy() *WarmPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WarmPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPoolSpec) DeepCopyInto(out *WarmPoolSpec) {
+	*out = *in
+	if in.GPUType != nil {
+		in, out := &in.GPUType, &out.GPUType
+		*out = new(string)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPoolSpec.
+func (in *WarmPoolSpec) DeepCopy() *WarmPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmPoolStatus) DeepCopyInto(out *WarmPoolStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]WarmPoolClaim, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResolvedImages != nil {
+		in, out := &in.ResolvedImages, &out.ResolvedImages
+		*out = make(map[string]ResolvedImage, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmPoolStatus.
+func (in *WarmPoolStatus) DeepCopy() *WarmPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmupRequest) DeepCopyInto(out *WarmupRequest) {
+	*out = *in
+	in.Body.DeepCopyInto(&out.Body)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmupRequest.
+func (in *WarmupRequest) DeepCopy() *WarmupRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmupRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmupSpec) DeepCopyInto(out *WarmupSpec) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]WarmupRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageURI != nil {
+		in, out := &in.StorageURI, &out.StorageURI
+		*out = new(string)
+		**out = **in
+	}
+	if in.Iterations != nil {
+		in, out := &in.Iterations, &out.Iterations
+		*out = new(int)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmupSpec.
+func (in *WarmupSpec) DeepCopy() *WarmupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *XGBoostSpec) DeepCopyInto(out *XGBoostSpec) {
 	*out = *in
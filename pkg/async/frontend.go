@@ -0,0 +1,448 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// CallbackURLHeader is the header of a request setting the URL its result is posted to
+	CallbackURLHeader = "X-Callback-Url"
+	// ResultsPath is the path of the results endpoint, the result of a request is served on ResultsPath + ID
+	ResultsPath = "/async/requests/"
+	// MetricsPath is the path of the Prometheus metrics of the frontend
+	MetricsPath = "/async/metrics"
+	// DefaultMaxRequestBytes limits the size of the queued requests when the config does not set it
+	DefaultMaxRequestBytes = 10 << 20
+	// DefaultMaxResponseBytes limits the size of the results when the config does not set it
+	DefaultMaxResponseBytes = 10 << 20
+)
+
+// ErrResponseTooLarge is returned when a response is larger than the max response bytes, it is not retried
+var ErrResponseTooLarge = errors.New("response too large")
+
+// Config of the async inference of a predictor, set by the controller in the async annotation of the predictor pods
+type Config struct {
+	// Backend of the queue, memory or redis
+	Backend string `json:"backend"`
+	// RedisAddress of the Redis server of the redis queue
+	RedisAddress string `json:"redisAddress,omitempty"`
+	// QueueName is the key of the Redis list of the redis queue
+	QueueName string `json:"queueName"`
+	// Workers is the number of requests sent to the predictor concurrently
+	Workers int `json:"workers"`
+	// MaxQueueDepth is the number of queued requests beyond which the requests are rejected, 0 for no limit
+	MaxQueueDepth int `json:"maxQueueDepth,omitempty"`
+	// TimeoutSeconds of a request sent to the predictor
+	TimeoutSeconds int `json:"timeoutSeconds"`
+	// ResultTTLSeconds is the time the results are kept for
+	ResultTTLSeconds int `json:"resultTTLSeconds"`
+	// CallbackHosts are the hosts the results can be posted to, a *. prefix matches the subdomains of a host
+	CallbackHosts []string `json:"callbackHosts,omitempty"`
+	// MaxRequestBytes is the size of the request bodies beyond which the requests are rejected with a 413,
+	// DefaultMaxRequestBytes when 0
+	MaxRequestBytes int64 `json:"maxRequestBytes,omitempty"`
+	// MaxResponseBytes is the size of the response bodies of the predictor beyond which the requests fail,
+	// DefaultMaxResponseBytes when 0. The responses of the callback URLs are bounded by the same size.
+	MaxResponseBytes int64 `json:"maxResponseBytes,omitempty"`
+}
+
+func (c *Config) maxRequestBytes() int64 {
+	if c.MaxRequestBytes > 0 {
+		return c.MaxRequestBytes
+	}
+	return DefaultMaxRequestBytes
+}
+
+func (c *Config) maxResponseBytes() int64 {
+	if c.MaxResponseBytes > 0 {
+		return c.MaxResponseBytes
+	}
+	return DefaultMaxResponseBytes
+}
+
+var (
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kfserving_async_queue_depth",
+		Help: "Number of async requests queued for the predictor",
+	})
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kfserving_async_requests_total",
+		Help: "Number of async requests by status: queued, rejected, succeeded or failed",
+	}, []string{"status"})
+	callbacksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kfserving_async_callbacks_total",
+		Help: "Number of results posted to the callback URLs by status: delivered or failed",
+	}, []string{"status"})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepth, requestsTotal, callbacksTotal)
+}
+
+// Frontend accepts the POST requests of the predictor asynchronously: the request is queued and answered with a 202
+// holding its ID. The workers of the frontend send the queued requests to the predictor, post their results to the
+// callback URLs of the requests and keep the results for the results endpoint. The other requests, e.g. the model
+// metadata requests, are proxied to the predictor.
+type Frontend struct {
+	Config
+	Queue Queue
+	// ComponentURL of the predictor, e.g. http://127.0.0.1:8080
+	ComponentURL string
+	// Retries of a request failing with a connection error or a 502, 503 or 504 response, for the requests to the
+	// predictor and to the callback URLs. The retries back off exponentially from RetryInterval.
+	Retries       int
+	RetryInterval time.Duration
+	Client        *http.Client
+	// CallbackClient posts the results to the callback URLs, see NewCallbackClient. Client is used when nil.
+	CallbackClient *http.Client
+	Log            logr.Logger
+	proxy          http.Handler
+	proxyOnce      sync.Once
+}
+
+func (f *Frontend) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch {
+	case strings.HasPrefix(req.URL.Path, ResultsPath) && req.Method == http.MethodGet:
+		f.serveResult(rw, req, strings.TrimPrefix(req.URL.Path, ResultsPath))
+	case req.Method == http.MethodPost:
+		f.enqueue(rw, req)
+	default:
+		f.proxyOnce.Do(func() {
+			target, _ := url.Parse(f.ComponentURL)
+			f.proxy = httputil.NewSingleHostReverseProxy(target)
+		})
+		f.proxy.ServeHTTP(rw, req)
+	}
+}
+
+func (f *Frontend) enqueue(rw http.ResponseWriter, req *http.Request) {
+	callbackURL := req.Header.Get(CallbackURLHeader)
+	if callbackURL != "" {
+		if err := f.validateCallbackURL(callbackURL); err != nil {
+			http.Error(rw, fmt.Sprintf("invalid %s %q, %v", CallbackURLHeader, callbackURL, err), http.StatusBadRequest)
+			return
+		}
+	}
+	// The requests are kept in the queue, the larger ones are rejected
+	maxRequestBytes := f.maxRequestBytes()
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxRequestBytes))
+	if err != nil && int64(len(body)) >= maxRequestBytes {
+		requestsTotal.WithLabelValues("rejected").Inc()
+		http.Error(rw, fmt.Sprintf("request body exceeds %d bytes", maxRequestBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := &Request{
+		ID:          uuid.New().String(),
+		Path:        req.URL.RequestURI(),
+		ContentType: req.Header.Get("Content-Type"),
+		Body:        body,
+		CallbackURL: callbackURL,
+	}
+	// The result is stored first so the request is known to the results endpoint as soon as it is dequeued, and
+	// removed when the request is not queued as its ID is never returned
+	result := &Result{ID: request.ID, Status: StatusQueued}
+	if err := f.Queue.SetResult(req.Context(), result); err != nil {
+		f.Log.Error(err, "Failed to store the result", "id", request.ID)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := f.Queue.Enqueue(req.Context(), request); err != nil {
+		requestsTotal.WithLabelValues("rejected").Inc()
+		if err := f.Queue.DeleteResult(req.Context(), request.ID); err != nil {
+			f.Log.Error(err, "Failed to delete the result", "id", request.ID)
+		}
+		if err == ErrQueueFull {
+			rw.Header().Set("Retry-After", "1")
+			http.Error(rw, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		f.Log.Error(err, "Failed to enqueue the request", "id", request.ID)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	requestsTotal.WithLabelValues(string(StatusQueued)).Inc()
+	rw.Header().Set("Location", ResultsPath+request.ID)
+	writeJSON(rw, http.StatusAccepted, result)
+}
+
+func (f *Frontend) serveResult(rw http.ResponseWriter, req *http.Request, id string) {
+	result, err := f.Queue.GetResult(req.Context(), id)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		http.Error(rw, fmt.Sprintf("request %s not found", id), http.StatusNotFound)
+		return
+	}
+	writeJSON(rw, http.StatusOK, result)
+}
+
+func writeJSON(rw http.ResponseWriter, status int, value interface{}) {
+	body, _ := json.Marshal(value)
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	rw.Write(body)
+}
+
+// Run starts the workers and reports the depth of the queue until the context is done, it then waits for the
+// requests sent to the predictor by the workers
+func (f *Frontend) Run(ctx context.Context) {
+	wg := &sync.WaitGroup{}
+	for i := 0; i < f.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.work(ctx)
+		}()
+	}
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		if depth, err := f.Queue.Depth(ctx); err != nil {
+			f.Log.Error(err, "Failed to read the depth of the queue")
+		} else {
+			queueDepth.Set(float64(depth))
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *Frontend) work(ctx context.Context) {
+	for {
+		request, err := f.Queue.Dequeue(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			f.Log.Error(err, "Failed to dequeue a request")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(f.RetryInterval):
+			}
+			continue
+		}
+		// The request is not interrupted on shutdown, the drain timeout bounds the wait for its result
+		f.process(context.Background(), request)
+	}
+}
+
+// process sends a request to the predictor, stores its result and posts it to the callback URL of the request
+func (f *Frontend) process(ctx context.Context, request *Request) {
+	if err := f.Queue.SetResult(ctx, &Result{ID: request.ID, Status: StatusProcessing}); err != nil {
+		f.Log.Error(err, "Failed to store the result", "id", request.ID)
+	}
+	result := &Result{ID: request.ID, Status: StatusFailed}
+	statusCode, body, err := f.post(ctx, f.Client, f.ComponentURL+request.Path, request.ContentType, request.Body)
+	if err == ErrResponseTooLarge {
+		err = fmt.Errorf("the response of the predictor exceeds %d bytes", f.maxResponseBytes())
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.StatusCode = statusCode
+		if statusCode >= 200 && statusCode < 300 {
+			result.Status = StatusSucceeded
+		}
+		if json.Valid(body) {
+			result.Response = body
+		} else {
+			result.Response, _ = json.Marshal(string(body))
+		}
+	}
+	requestsTotal.WithLabelValues(string(result.Status)).Inc()
+	if err := f.Queue.SetResult(ctx, result); err != nil {
+		f.Log.Error(err, "Failed to store the result", "id", request.ID)
+	}
+	if request.CallbackURL == "" {
+		return
+	}
+	value, _ := json.Marshal(result)
+	callbackClient := f.CallbackClient
+	if callbackClient == nil {
+		callbackClient = f.Client
+	}
+	statusCode, _, err = f.post(ctx, callbackClient, request.CallbackURL, "application/json", value)
+	// The response of the callback URL is not used
+	if err == ErrResponseTooLarge {
+		err = nil
+	}
+	if err == nil && statusCode >= 300 {
+		err = fmt.Errorf("callback failed with status %d", statusCode)
+	}
+	if err != nil {
+		callbacksTotal.WithLabelValues("failed").Inc()
+		f.Log.Error(err, "Failed to post the result", "id", request.ID, "callbackUrl", request.CallbackURL)
+		return
+	}
+	callbacksTotal.WithLabelValues("delivered").Inc()
+}
+
+// post sends a request, retrying the connection errors and the 502, 503 and 504 responses. The responses larger than
+// the max response bytes fail with ErrResponseTooLarge.
+func (f *Frontend) post(ctx context.Context, client *http.Client, url string, contentType string,
+	body []byte) (int, []byte, error) {
+	var statusCode int
+	var response []byte
+	var err error
+	for attempt := 0; attempt <= f.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, nil, ctx.Err()
+			case <-time.After(f.RetryInterval << uint(attempt-1)):
+			}
+		}
+		statusCode, response, err = send(ctx, client, url, contentType, body, f.maxResponseBytes())
+		if err == ErrResponseTooLarge || err == nil && statusCode != http.StatusBadGateway && statusCode != http.StatusServiceUnavailable &&
+			statusCode != http.StatusGatewayTimeout {
+			break
+		}
+	}
+	return statusCode, response, err
+}
+
+func send(ctx context.Context, client *http.Client, url string, contentType string, body []byte,
+	maxResponseBytes int64) (int, []byte, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(io.LimitReader(response.Body, maxResponseBytes+1))
+	if err == nil && int64(len(responseBody)) > maxResponseBytes {
+		return response.StatusCode, nil, ErrResponseTooLarge
+	}
+	return response.StatusCode, responseBody, err
+}
+
+// validateCallbackURL checks that the callback URL is an http or https URL on one of the callback hosts, the cluster
+// service hosts and the loopback, link-local and unspecified addresses are rejected
+func (f *Frontend) validateCallbackURL(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("it must be an http or https URL")
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return fmt.Errorf("the loopback, link-local, private and unspecified addresses are not allowed")
+	}
+	if !strings.Contains(host, ".") || strings.HasSuffix(host, ".svc") || strings.Contains(host, ".svc.") ||
+		strings.HasSuffix(host, ".cluster.local") {
+		return fmt.Errorf("the cluster service hosts are not allowed")
+	}
+	for _, allowed := range f.CallbackHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("its host is not one of the callback hosts")
+}
+
+// privateNetworks are the RFC 1918 and RFC 4193 networks the pod, service and node CIDRs of the clusters are
+// allocated from
+var privateNetworks = func() []*net.IPNet {
+	networks := []*net.IPNet{}
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// isInternalIP is true for the addresses of the cluster, the node and the pod, e.g. the private addresses of the
+// pods and the services, the loopback and the link-local addresses of the metadata services of the cloud providers
+func isInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCallbackClient returns the client posting the results to the callback URLs. The callback hosts may resolve to
+// any address so the connections to the internal addresses are refused when dialing, and the redirects are not
+// followed as their hosts are not checked.
+func NewCallbackClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+				return fmt.Errorf("connection to %s refused, the callback URLs cannot target internal addresses",
+					address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestFrontend(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// The predictor fails the first request with a 503, returns the instances as predictions and rejects the
+	// requests without instances
+	var mu sync.Mutex
+	failures := 1
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if req.Method == http.MethodGet {
+			rw.Write([]byte(`{"name":"iris","ready":true}`))
+			return
+		}
+		if failures > 0 {
+			failures--
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if !strings.Contains(string(body), "instances") {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte("invalid request"))
+			return
+		}
+		rw.Write([]byte(strings.Replace(string(body), "instances", "predictions", 1)))
+	}))
+	defer predictor.Close()
+	callbacks := make(chan *Result, 2)
+	callback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		result := &Result{}
+		json.NewDecoder(req.Body).Decode(result)
+		callbacks <- result
+	}))
+	defer callback.Close()
+
+	// The callback host is allowed and resolved to the callback server
+	callbackClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, callback.Listener.Addr().String())
+		},
+	}}
+
+	frontend := &Frontend{
+		Config:         Config{Workers: 2, CallbackHosts: []string{"*.example.com"}},
+		Queue:          NewMemoryQueue(0, time.Minute),
+		ComponentURL:   predictor.URL,
+		Retries:        1,
+		RetryInterval:  time.Millisecond,
+		Client:         &http.Client{},
+		CallbackClient: callbackClient,
+		Log:            logf.Log,
+	}
+	server := httptest.NewServer(frontend)
+	defer server.Close()
+
+	post := func(body string, callbackURL string) (*http.Response, *Result) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/models/iris:predict", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if callbackURL != "" {
+			req.Header.Set(CallbackURLHeader, callbackURL)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		defer resp.Body.Close()
+		result := &Result{}
+		json.NewDecoder(resp.Body).Decode(result)
+		return resp, result
+	}
+	getResult := func(id string) *Result {
+		resp, err := http.Get(server.URL + ResultsPath + id)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		result := &Result{}
+		g.Expect(json.NewDecoder(resp.Body).Decode(result)).To(gomega.Succeed())
+		return result
+	}
+
+	// The requests are queued until the workers run
+	resp, accepted := post(`{"instances":[1]}`, "http://hooks.example.com/results")
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusAccepted))
+	g.Expect(resp.Header.Get("Location")).To(gomega.Equal(ResultsPath + accepted.ID))
+	g.Expect(accepted.Status).To(gomega.Equal(StatusQueued))
+	g.Expect(getResult(accepted.ID)).To(gomega.Equal(&Result{ID: accepted.ID, Status: StatusQueued}))
+	resp, rejected := post(`{"invalid":[2]}`, "")
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusAccepted))
+	for _, callbackURL := range []string{"ftp://hooks.example.com", callback.URL, "http://hooks.example.org",
+		"http://169.254.169.254/latest/meta-data", "http://10.96.0.10", "http://iris.default.svc.cluster.local",
+		"http://iris"} {
+		resp, _ = post(`{"instances":[3]}`, callbackURL)
+		g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusBadRequest), callbackURL)
+	}
+	g.Expect(getResult("unknown")).To(gomega.BeNil())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		frontend.Run(ctx)
+		close(done)
+	}()
+
+	// The request failing with a 503 is retried and its result posted to the callback URL
+	select {
+	case result := <-callbacks:
+		g.Expect(result.ID).To(gomega.Equal(accepted.ID))
+		g.Expect(result.Status).To(gomega.Equal(StatusSucceeded))
+		g.Expect(result.StatusCode).To(gomega.Equal(http.StatusOK))
+		g.Expect(string(result.Response)).To(gomega.Equal(`{"predictions":[1]}`))
+	case <-time.After(5 * time.Second):
+		t.Fatal("result not posted to the callback URL")
+	}
+	g.Eventually(func() *Result { return getResult(rejected.ID) }, 5*time.Second).Should(gomega.Equal(&Result{
+		ID:         rejected.ID,
+		Status:     StatusFailed,
+		StatusCode: http.StatusBadRequest,
+		Response:   json.RawMessage(`"invalid request"`),
+	}))
+	g.Expect(getResult(accepted.ID).Status).To(gomega.Equal(StatusSucceeded))
+
+	// The other requests are proxied to the predictor
+	metadata, err := http.Get(server.URL + "/v1/models/iris")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	body, _ := ioutil.ReadAll(metadata.Body)
+	metadata.Body.Close()
+	g.Expect(string(body)).To(gomega.Equal(`{"name":"iris","ready":true}`))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("workers not stopped")
+	}
+}
+
+func TestFrontendQueueFull(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	queue := NewMemoryQueue(1, time.Minute)
+	frontend := &Frontend{
+		Queue: queue,
+		Log:   logf.Log,
+	}
+	for _, expected := range []int{http.StatusAccepted, http.StatusServiceUnavailable} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/iris:predict", strings.NewReader(`{"instances":[1]}`))
+		rw := httptest.NewRecorder()
+		frontend.ServeHTTP(rw, req)
+		g.Expect(rw.Code).To(gomega.Equal(expected))
+	}
+	// Only the result of the queued request is stored
+	g.Expect(queue.results).To(gomega.HaveLen(1))
+
+	// The results of the rejected requests are not kept
+	queue = NewMemoryQueue(1, time.Minute)
+	g.Expect(queue.Enqueue(context.Background(), &Request{ID: "queued"})).To(gomega.Succeed())
+	frontend.Queue = queue
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/models/iris:predict", strings.NewReader(`{"instances":[1]}`))
+		rw := httptest.NewRecorder()
+		frontend.ServeHTTP(rw, req)
+		g.Expect(rw.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+	}
+	g.Expect(queue.results).To(gomega.BeEmpty())
+}
+
+func TestCallbackClient(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+
+	// The connections to the internal addresses are refused even when a callback host resolves to them
+	_, err := NewCallbackClient(time.Second).Post(server.URL, "application/json", strings.NewReader("{}"))
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("the callback URLs cannot target internal addresses")))
+	for _, address := range []string{"10.96.0.10", "172.16.0.1", "192.168.1.1", "[fd00::1]"} {
+		_, err = NewCallbackClient(time.Second).Post("http://"+address+"/results", "application/json",
+			strings.NewReader("{}"))
+		g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("the callback URLs cannot target internal addresses")),
+			address)
+	}
+}
+
+func TestFrontendBodyLimits(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	calls := 0
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(req.Body)
+		rw.Write(body)
+	}))
+	defer predictor.Close()
+	frontend := &Frontend{
+		Config:       Config{MaxRequestBytes: 16, MaxResponseBytes: 8},
+		Queue:        NewMemoryQueue(0, time.Minute),
+		ComponentURL: predictor.URL,
+		Client:       &http.Client{},
+		Log:          logf.Log,
+	}
+
+	// The requests over the max request bytes are rejected, with or without a Content-Length
+	req := httptest.NewRequest(http.MethodPost, "/v1/models/iris:predict", strings.NewReader(`{"instances":[1,2,3]}`))
+	rw := httptest.NewRecorder()
+	frontend.ServeHTTP(rw, req)
+	g.Expect(rw.Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+	req = httptest.NewRequest(http.MethodPost, "/v1/models/iris:predict", strings.NewReader(`{"instances":[1,2,3]}`))
+	req.ContentLength = -1
+	rw = httptest.NewRecorder()
+	frontend.ServeHTTP(rw, req)
+	g.Expect(rw.Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+	depth, _ := frontend.Queue.Depth(context.TODO())
+	g.Expect(depth).To(gomega.BeZero())
+
+	// The responses over the max response bytes fail the request without retries
+	frontend.Retries = 3
+	frontend.process(context.TODO(), &Request{ID: "large", Path: "/v1/models/iris:predict", Body: []byte(`{"a":[1]}`)})
+	result, err := frontend.Queue.GetResult(context.TODO(), "large")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result).To(gomega.Equal(&Result{
+		ID:     "large",
+		Status: StatusFailed,
+		Error:  "the response of the predictor exceeds 8 bytes",
+	}))
+	g.Expect(calls).To(gomega.Equal(1))
+	frontend.process(context.TODO(), &Request{ID: "small", Path: "/v1/models/iris:predict", Body: []byte(`[1]`)})
+	result, _ = frontend.Queue.GetResult(context.TODO(), "small")
+	g.Expect(result.Status).To(gomega.Equal(StatusSucceeded))
+	g.Expect(string(result.Response)).To(gomega.Equal(`[1]`))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned when a request is enqueued on a queue holding its max depth of requests
+var ErrQueueFull = errors.New("queue full")
+
+// RequestStatus is the processing status of an async request
+type RequestStatus string
+
+// RequestStatus Enum
+const (
+	StatusQueued     RequestStatus = "queued"
+	StatusProcessing RequestStatus = "processing"
+	StatusSucceeded  RequestStatus = "succeeded"
+	StatusFailed     RequestStatus = "failed"
+)
+
+// Request is an async request queued for the predictor
+type Request struct {
+	ID          string `json:"id"`
+	Path        string `json:"path"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body"`
+	// CallbackURL the result is posted to, empty when the result is fetched by the ID of the request
+	CallbackURL string `json:"callbackUrl,omitempty"`
+}
+
+// Result of an async request, posted to the callback URL and served on the results endpoint
+type Result struct {
+	ID     string        `json:"id"`
+	Status RequestStatus `json:"status"`
+	// StatusCode of the response of the predictor
+	StatusCode int `json:"statusCode,omitempty"`
+	// Response of the predictor, a JSON string when the response is not JSON
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error of the request when the predictor could not be reached
+	Error string `json:"error,omitempty"`
+}
+
+// Queue holds the async requests until they are sent to the predictor and their results until they expire
+type Queue interface {
+	// Enqueue adds a request to the queue, it returns ErrQueueFull when the queue holds its max depth of requests
+	Enqueue(ctx context.Context, request *Request) error
+	// Dequeue removes the oldest request from the queue, it blocks until a request is queued or the context is done
+	Dequeue(ctx context.Context) (*Request, error)
+	// Depth returns the number of queued requests
+	Depth(ctx context.Context) (int64, error)
+	// SetResult stores the result of a request until it expires
+	SetResult(ctx context.Context, result *Result) error
+	// GetResult returns the result of a request, nil when the request is unknown or its result expired
+	GetResult(ctx context.Context, id string) (*Result, error)
+	// DeleteResult removes the result of a request, e.g. of a request which could not be queued
+	DeleteResult(ctx context.Context, id string) error
+}
+
+type memoryResult struct {
+	result  *Result
+	expires time.Time
+}
+
+// MemoryQueue keeps the requests and the results in memory
+type MemoryQueue struct {
+	maxDepth  int
+	resultTTL time.Duration
+	mu        sync.Mutex
+	requests  []*Request
+	results   map[string]*memoryResult
+	// nextPrune is the time the expired results are next removed at
+	nextPrune time.Time
+	// notify wakes up a waiting Dequeue when a request is queued
+	notify chan struct{}
+}
+
+// NewMemoryQueue creates a queue holding up to maxDepth requests, 0 for no limit, and keeping the results for
+// resultTTL
+func NewMemoryQueue(maxDepth int, resultTTL time.Duration) *MemoryQueue {
+	return &MemoryQueue{
+		maxDepth:  maxDepth,
+		resultTTL: resultTTL,
+		results:   map[string]*memoryResult{},
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+func (q *MemoryQueue) Enqueue(_ context.Context, request *Request) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxDepth > 0 && len(q.requests) >= q.maxDepth {
+		return ErrQueueFull
+	}
+	q.requests = append(q.requests, request)
+	q.wakeUp()
+	return nil
+}
+
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Request, error) {
+	for {
+		q.mu.Lock()
+		if len(q.requests) != 0 {
+			request := q.requests[0]
+			q.requests = q.requests[1:]
+			// Another Dequeue may be waiting for the remaining requests
+			if len(q.requests) != 0 {
+				q.wakeUp()
+			}
+			q.mu.Unlock()
+			return request, nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.notify:
+		}
+	}
+}
+
+func (q *MemoryQueue) wakeUp() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *MemoryQueue) Depth(_ context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int64(len(q.requests)), nil
+}
+
+func (q *MemoryQueue) SetResult(_ context.Context, result *Result) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	if now.After(q.nextPrune) {
+		for id, stored := range q.results {
+			if now.After(stored.expires) {
+				delete(q.results, id)
+			}
+		}
+		q.nextPrune = now.Add(q.resultTTL)
+	}
+	q.results[result.ID] = &memoryResult{result: result, expires: now.Add(q.resultTTL)}
+	return nil
+}
+
+func (q *MemoryQueue) GetResult(_ context.Context, id string) (*Result, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stored, ok := q.results[id]
+	if !ok || time.Now().After(stored.expires) {
+		return nil, nil
+	}
+	return stored.result, nil
+}
+
+func (q *MemoryQueue) DeleteResult(_ context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.results, id)
+	return nil
+}
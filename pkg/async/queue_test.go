@@ -0,0 +1,100 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/redis/redistest"
+	"github.com/onsi/gomega"
+)
+
+func TestQueues(t *testing.T) {
+	server := redistest.NewServer(t, "LPUSH", "BRPOP", "LLEN", "SET", "GET", "DEL")
+	defer server.Close()
+
+	for name, queue := range map[string]Queue{
+		"memory": NewMemoryQueue(2, time.Minute),
+		"redis":  NewRedisQueue(server.Addr(), "kfserving:async:default:iris", 2, time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			ctx := context.Background()
+			first := &Request{ID: "1", Path: "/v1/models/iris:predict", Body: []byte(`{"instances":[1]}`)}
+			second := &Request{ID: "2", Path: "/v1/models/iris:predict", Body: []byte(`{"instances":[2]}`),
+				CallbackURL: "http://callback"}
+			g.Expect(queue.Enqueue(ctx, first)).To(gomega.Succeed())
+			g.Expect(queue.Enqueue(ctx, second)).To(gomega.Succeed())
+			g.Expect(queue.Enqueue(ctx, &Request{ID: "3"})).To(gomega.Equal(ErrQueueFull))
+			g.Expect(queue.Depth(ctx)).To(gomega.Equal(int64(2)))
+
+			// The requests are dequeued in order
+			g.Expect(queue.Dequeue(ctx)).To(gomega.Equal(first))
+			g.Expect(queue.Dequeue(ctx)).To(gomega.Equal(second))
+			g.Expect(queue.Depth(ctx)).To(gomega.Equal(int64(0)))
+
+			// Dequeue waits for a request until the context is done
+			timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+			_, err := queue.Dequeue(timeout)
+			g.Expect(err).To(gomega.Equal(context.DeadlineExceeded))
+
+			result := &Result{ID: "1", Status: StatusSucceeded, StatusCode: 200, Response: []byte(`{"predictions":[1]}`)}
+			g.Expect(queue.SetResult(ctx, result)).To(gomega.Succeed())
+			g.Expect(queue.GetResult(ctx, "1")).To(gomega.Equal(result))
+			g.Expect(queue.GetResult(ctx, "2")).To(gomega.BeNil())
+			g.Expect(queue.DeleteResult(ctx, "1")).To(gomega.Succeed())
+			g.Expect(queue.GetResult(ctx, "1")).To(gomega.BeNil())
+		})
+	}
+}
+
+func TestMemoryQueueWakesUpWaitingDequeue(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	queue := NewMemoryQueue(0, time.Minute)
+	dequeued := make(chan *Request, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			request, _ := queue.Dequeue(context.Background())
+			dequeued <- request
+		}()
+	}
+	g.Expect(queue.Enqueue(context.Background(), &Request{ID: "1"})).To(gomega.Succeed())
+	g.Expect(queue.Enqueue(context.Background(), &Request{ID: "2"})).To(gomega.Succeed())
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case request := <-dequeued:
+			ids[request.ID] = true
+		case <-time.After(time.Second):
+			t.Fatal("request not dequeued")
+		}
+	}
+	g.Expect(ids).To(gomega.Equal(map[string]bool{"1": true, "2": true}))
+}
+
+func TestMemoryQueueResultExpires(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	queue := NewMemoryQueue(0, time.Millisecond)
+	g.Expect(queue.SetResult(context.Background(), &Result{ID: "1", Status: StatusQueued})).To(gomega.Succeed())
+	time.Sleep(5 * time.Millisecond)
+	g.Expect(queue.GetResult(context.Background(), "1")).To(gomega.BeNil())
+	g.Expect(queue.SetResult(context.Background(), &Result{ID: "2", Status: StatusQueued})).To(gomega.Succeed())
+	g.Expect(queue.results).To(gomega.HaveLen(1))
+}
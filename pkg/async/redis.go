@@ -0,0 +1,128 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/redis"
+)
+
+// redisPollSeconds is the time a BRPOP waits for a request, Dequeue checks its context between the polls
+const redisPollSeconds = 1
+
+// RedisQueue keeps the requests in a Redis list shared by the replicas and the results in keys expiring with their
+// TTL, so that a request is processed by any replica and its result fetched from any replica
+type RedisQueue struct {
+	client    *redis.Client
+	name      string
+	maxDepth  int
+	resultTTL time.Duration
+}
+
+// NewRedisQueue creates a queue on the list name of the Redis server at address, holding up to maxDepth requests, 0
+// for no limit, and keeping the results for resultTTL
+func NewRedisQueue(address string, name string, maxDepth int, resultTTL time.Duration) *RedisQueue {
+	return &RedisQueue{
+		client:    redis.NewClient(address),
+		name:      name,
+		maxDepth:  maxDepth,
+		resultTTL: resultTTL,
+	}
+}
+
+func (q *RedisQueue) Enqueue(_ context.Context, request *Request) error {
+	// The depth is checked before the push, concurrent pushes may exceed the max depth by the number of replicas
+	if q.maxDepth > 0 {
+		depth, err := q.client.Do(0, "LLEN", q.name)
+		if err != nil {
+			return err
+		}
+		if depth.(int64) >= int64(q.maxDepth) {
+			return ErrQueueFull
+		}
+	}
+	value, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	_, err = q.client.Do(0, "LPUSH", q.name, string(value))
+	return err
+}
+
+func (q *RedisQueue) Dequeue(ctx context.Context) (*Request, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reply, err := q.client.Do(redisPollSeconds*time.Second, "BRPOP", q.name, strconv.Itoa(redisPollSeconds))
+		if err != nil {
+			return nil, err
+		}
+		// The reply is nil when no request was queued during the poll, the list name and the request otherwise
+		if reply == nil {
+			continue
+		}
+		request := &Request{}
+		if err := json.Unmarshal(reply.([]interface{})[1].([]byte), request); err != nil {
+			return nil, err
+		}
+		return request, nil
+	}
+}
+
+func (q *RedisQueue) Depth(_ context.Context) (int64, error) {
+	depth, err := q.client.Do(0, "LLEN", q.name)
+	if err != nil {
+		return 0, err
+	}
+	return depth.(int64), nil
+}
+
+func (q *RedisQueue) SetResult(_ context.Context, result *Result) error {
+	value, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = q.client.Do(0, "SET", q.resultKey(result.ID), string(value), "EX",
+		strconv.Itoa(int(q.resultTTL.Seconds())))
+	return err
+}
+
+func (q *RedisQueue) GetResult(_ context.Context, id string) (*Result, error) {
+	value, err := q.client.Do(0, "GET", q.resultKey(id))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	result := &Result{}
+	if err := json.Unmarshal(value.([]byte), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (q *RedisQueue) DeleteResult(_ context.Context, id string) error {
+	_, err := q.client.Do(0, "DEL", q.resultKey(id))
+	return err
+}
+
+func (q *RedisQueue) resultKey(id string) string {
+	return q.name + ":result:" + id
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+// EventType is the lifecycle transition of a component of an InferenceService, it is the reason of the Kubernetes
+// event
+type EventType string
+
+// EventType Enum
+const (
+	// ModelDeployed is emitted when a new revision of the component becomes ready
+	ModelDeployed EventType = "ModelDeployed"
+	// TrafficShifted is emitted when the traffic percent of the latest ready revision changes
+	TrafficShifted EventType = "TrafficShifted"
+	// RollbackPerformed is emitted when the traffic is pinned to a prior revision
+	RollbackPerformed EventType = "RollbackPerformed"
+	// ScaledToZero is emitted when the latest ready revision is scaled to zero for lack of traffic
+	ScaledToZero EventType = "ScaledToZero"
+	// RolloutAborted is emitted when a new revision of the component is not ready within the progress deadline
+	RolloutAborted EventType = "RolloutAborted"
+)
+
+// Event is an audit record of a lifecycle transition of a component of an InferenceService
+type Event struct {
+	Type             EventType             `json:"type"`
+	Namespace        string                `json:"namespace"`
+	InferenceService string                `json:"inferenceService"`
+	Component        v1beta1.ComponentType `json:"component"`
+	// Revision deployed, receiving the traffic or scaled to zero
+	Revision string `json:"revision,omitempty"`
+	// PreviousRevision is the revision which was serving before the transition
+	PreviousRevision string `json:"previousRevision,omitempty"`
+	// StorageUri of the model of the component
+	StorageUri             string `json:"storageUri,omitempty"`
+	TrafficPercent         *int64 `json:"trafficPercent,omitempty"`
+	PreviousTrafficPercent *int64 `json:"previousTrafficPercent,omitempty"`
+}
+
+// Message is the human readable message of the Kubernetes event
+func (e *Event) Message() string {
+	switch e.Type {
+	case ModelDeployed:
+		message := fmt.Sprintf("Revision %s of the %s is deployed", e.Revision, e.Component)
+		if e.StorageUri != "" {
+			message += fmt.Sprintf(" with model %s", e.StorageUri)
+		}
+		if e.PreviousRevision != "" {
+			message += fmt.Sprintf(", previous revision %s", e.PreviousRevision)
+		}
+		return message
+	case TrafficShifted:
+		return fmt.Sprintf("Traffic of revision %s of the %s shifted from %d%% to %d%%", e.Revision, e.Component,
+			*e.PreviousTrafficPercent, *e.TrafficPercent)
+	case RollbackPerformed:
+		return fmt.Sprintf("Traffic of the %s rolled back from revision %s to revision %s", e.Component,
+			e.PreviousRevision, e.Revision)
+	case ScaledToZero:
+		return fmt.Sprintf("Revision %s of the %s is scaled to zero", e.Revision, e.Component)
+	case RolloutAborted:
+		return fmt.Sprintf("Rollout of revision %s of the %s aborted past the progress deadline, the traffic stays on "+
+			"revision %s", e.Revision, e.Component, e.PreviousRevision)
+	}
+	return string(e.Type)
+}
+
+// Warning returns true for the transitions recorded as warning Kubernetes events
+func (e *Event) Warning() bool {
+	return e.Type == RolloutAborted
+}
+
+// Transitions returns the audit events of the changes from the old status to the status of the InferenceService
+func Transitions(isvc *v1beta1.InferenceService, old *v1beta1.InferenceServiceStatus) []Event {
+	events := []Event{}
+	for _, c := range []struct {
+		componentType v1beta1.ComponentType
+		component     v1beta1.Component
+	}{
+		{v1beta1.PredictorComponent, &isvc.Spec.Predictor},
+		{v1beta1.TransformerComponent, isvc.Spec.Transformer},
+		{v1beta1.ExplainerComponent, isvc.Spec.Explainer},
+	} {
+		status, ok := isvc.Status.Components[c.componentType]
+		if !ok {
+			continue
+		}
+		oldStatus := old.Components[c.componentType]
+		event := Event{
+			Namespace:        isvc.Namespace,
+			InferenceService: isvc.Name,
+			Component:        c.componentType,
+			Revision:         status.LatestReadyRevision,
+			StorageUri:       storageUri(c.component),
+		}
+		if status.LatestReadyRevision != "" && status.LatestReadyRevision != oldStatus.LatestReadyRevision {
+			deployed := event
+			deployed.Type = ModelDeployed
+			deployed.PreviousRevision = oldStatus.LatestReadyRevision
+			deployed.TrafficPercent = status.TrafficPercent
+			events = append(events, deployed)
+		}
+		// The traffic is first set when the component is deployed
+		if status.TrafficPercent != nil && oldStatus.TrafficPercent != nil &&
+			*status.TrafficPercent != *oldStatus.TrafficPercent {
+			shifted := event
+			shifted.Type = TrafficShifted
+			shifted.TrafficPercent = status.TrafficPercent
+			shifted.PreviousTrafficPercent = oldStatus.TrafficPercent
+			events = append(events, shifted)
+		}
+		if status.PinnedRevision != "" && status.PinnedRevision != oldStatus.PinnedRevision {
+			rolledBack := event
+			rolledBack.Type = RollbackPerformed
+			rolledBack.Revision = status.PinnedRevision
+			rolledBack.PreviousRevision = oldStatus.PinnedRevision
+			if rolledBack.PreviousRevision == "" {
+				rolledBack.PreviousRevision = oldStatus.LatestReadyRevision
+			}
+			events = append(events, rolledBack)
+		}
+		if status.RolledBackRevision != "" && status.RolledBackRevision != oldStatus.RolledBackRevision {
+			rolledBack := event
+			rolledBack.Type = RollbackPerformed
+			rolledBack.Revision = status.PreviousReadyRevision
+			rolledBack.PreviousRevision = status.RolledBackRevision
+			events = append(events, rolledBack)
+		}
+		if status.AbortedRevision != "" && status.AbortedRevision != oldStatus.AbortedRevision {
+			aborted := event
+			aborted.Type = RolloutAborted
+			aborted.Revision = status.AbortedRevision
+			aborted.PreviousRevision = status.ServingRevision
+			events = append(events, aborted)
+		}
+		if status.ScaledToZero && !oldStatus.ScaledToZero {
+			scaled := event
+			scaled.Type = ScaledToZero
+			events = append(events, scaled)
+		}
+	}
+	return events
+}
+
+func storageUri(component v1beta1.Component) string {
+	if reflect.ValueOf(component).IsNil() {
+		return ""
+	}
+	for _, implementation := range component.GetImplementations() {
+		if uri := implementation.GetStorageUri(); uri != nil {
+			return *uri
+		}
+	}
+	return ""
+}
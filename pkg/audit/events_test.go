@@ -0,0 +1,257 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransitions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storageUri := "gs://kfserving-samples/models/sklearn/iris-v2"
+	isvc := func(status v1beta1.ComponentStatusSpec) *v1beta1.InferenceService {
+		return &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+			Spec: v1beta1.InferenceServiceSpec{
+				Predictor: v1beta1.PredictorSpec{
+					SKLearn: &v1beta1.SKLearnSpec{
+						PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: &storageUri},
+					},
+				},
+			},
+			Status: v1beta1.InferenceServiceStatus{
+				Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+					v1beta1.PredictorComponent: status,
+				},
+			},
+		}
+	}
+	percent := func(percent int64) *int64 {
+		return &percent
+	}
+	scenarios := map[string]struct {
+		old      v1beta1.ComponentStatusSpec
+		status   v1beta1.ComponentStatusSpec
+		expected []Event
+	}{
+		"FirstDeployment": {
+			old: v1beta1.ComponentStatusSpec{},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+			},
+			expected: []Event{
+				{
+					Type:             ModelDeployed,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00001",
+					StorageUri:       storageUri,
+					TrafficPercent:   percent(100),
+				},
+			},
+		},
+		"CanaryDeployment": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "sklearn-predictor-default-00002",
+				PreviousReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:        percent(10),
+			},
+			expected: []Event{
+				{
+					Type:             ModelDeployed,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00002",
+					PreviousRevision: "sklearn-predictor-default-00001",
+					StorageUri:       storageUri,
+					TrafficPercent:   percent(10),
+				},
+				{
+					Type:                   TrafficShifted,
+					Namespace:              "default",
+					InferenceService:       "sklearn",
+					Component:              v1beta1.PredictorComponent,
+					Revision:               "sklearn-predictor-default-00002",
+					StorageUri:             storageUri,
+					TrafficPercent:         percent(10),
+					PreviousTrafficPercent: percent(100),
+				},
+			},
+		},
+		"Rollback": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00002",
+				TrafficPercent:      percent(100),
+				RevisionHistory:     []string{"sklearn-predictor-default-00002", "sklearn-predictor-default-00001"},
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00002",
+				TrafficPercent:      percent(100),
+				RevisionHistory:     []string{"sklearn-predictor-default-00002", "sklearn-predictor-default-00001"},
+				PinnedRevision:      "sklearn-predictor-default-00001",
+			},
+			expected: []Event{
+				{
+					Type:             RollbackPerformed,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00001",
+					PreviousRevision: "sklearn-predictor-default-00002",
+					StorageUri:       storageUri,
+				},
+			},
+		},
+		"ScaledToZero": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+				ScaledToZero:        true,
+			},
+			expected: []Event{
+				{
+					Type:             ScaledToZero,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00001",
+					StorageUri:       storageUri,
+				},
+			},
+		},
+		"CanaryRolledBack": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "sklearn-predictor-default-00002",
+				PreviousReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:        percent(10),
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "sklearn-predictor-default-00002",
+				PreviousReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:        percent(10),
+				RolledBackRevision:    "sklearn-predictor-default-00002",
+			},
+			expected: []Event{
+				{
+					Type:             RollbackPerformed,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00001",
+					PreviousRevision: "sklearn-predictor-default-00002",
+					StorageUri:       storageUri,
+				},
+			},
+		},
+		"RolloutAborted": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(0),
+				RolloutRevision:     "sklearn-predictor-default-00002",
+				ServingRevision:     "sklearn-predictor-default-00001",
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(0),
+				AbortedRevision:     "sklearn-predictor-default-00002",
+				ServingRevision:     "sklearn-predictor-default-00001",
+			},
+			expected: []Event{
+				{
+					Type:             RolloutAborted,
+					Namespace:        "default",
+					InferenceService: "sklearn",
+					Component:        v1beta1.PredictorComponent,
+					Revision:         "sklearn-predictor-default-00002",
+					PreviousRevision: "sklearn-predictor-default-00001",
+					StorageUri:       storageUri,
+				},
+			},
+		},
+		"NoTransition": {
+			old: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+				ScaledToZero:        true,
+			},
+			status: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "sklearn-predictor-default-00001",
+				TrafficPercent:      percent(100),
+				ScaledToZero:        true,
+			},
+			expected: []Event{},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			old := isvc(scenario.old).Status
+			g.Expect(Transitions(isvc(scenario.status), &old)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	previous, current := int64(100), int64(10)
+	scenarios := map[string]struct {
+		event    Event
+		expected string
+	}{
+		"ModelDeployed": {
+			event: Event{Type: ModelDeployed, Component: v1beta1.PredictorComponent, Revision: "r2",
+				PreviousRevision: "r1", StorageUri: "gs://models/v2"},
+			expected: "Revision r2 of the predictor is deployed with model gs://models/v2, previous revision r1",
+		},
+		"TrafficShifted": {
+			event: Event{Type: TrafficShifted, Component: v1beta1.PredictorComponent, Revision: "r2",
+				TrafficPercent: &current, PreviousTrafficPercent: &previous},
+			expected: "Traffic of revision r2 of the predictor shifted from 100% to 10%",
+		},
+		"RollbackPerformed": {
+			event:    Event{Type: RollbackPerformed, Component: v1beta1.TransformerComponent, Revision: "r1", PreviousRevision: "r2"},
+			expected: "Traffic of the transformer rolled back from revision r2 to revision r1",
+		},
+		"ScaledToZero": {
+			event:    Event{Type: ScaledToZero, Component: v1beta1.ExplainerComponent, Revision: "r1"},
+			expected: "Revision r1 of the explainer is scaled to zero",
+		},
+		"RolloutAborted": {
+			event:    Event{Type: RolloutAborted, Component: v1beta1.PredictorComponent, Revision: "r2", PreviousRevision: "r1"},
+			expected: "Rollout of revision r2 of the predictor aborted past the progress deadline, the traffic stays on revision r1",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(scenario.event.Message()).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
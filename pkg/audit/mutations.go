@@ -0,0 +1,186 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// MutatedReason is the reason of the events of the mutations
+	MutatedReason = "Mutated"
+	// maxMutationMessage truncates the messages of the events of the mutations
+	maxMutationMessage = 1024
+)
+
+// Change is a field changed by a mutation, the values are JSON and empty when the field is not set
+type Change struct {
+	Path     string `json:"path"`
+	Previous string `json:"previous,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, unsetIfEmpty(c.Previous), unsetIfEmpty(c.Value))
+}
+
+func unsetIfEmpty(value string) string {
+	if value == "" {
+		return "<unset>"
+	}
+	return value
+}
+
+// Changes returns the fields changed from previous to value, compared on their JSON representation so the fields
+// are named as in the manifests
+func Changes(previous interface{}, value interface{}) ([]Change, error) {
+	var p, v interface{}
+	if err := roundTrip(previous, &p); err != nil {
+		return nil, err
+	}
+	if err := roundTrip(value, &v); err != nil {
+		return nil, err
+	}
+	changes := []Change{}
+	diff("", p, v, &changes)
+	return changes, nil
+}
+
+func roundTrip(in interface{}, out *interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func diff(path string, previous interface{}, value interface{}, changes *[]Change) {
+	if reflect.DeepEqual(previous, value) {
+		return
+	}
+	previousMap, isPreviousMap := previous.(map[string]interface{})
+	valueMap, isValueMap := value.(map[string]interface{})
+	if isPreviousMap && isValueMap {
+		keys := []string{}
+		for key := range previousMap {
+			keys = append(keys, key)
+		}
+		for key := range valueMap {
+			if _, ok := previousMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			diff(fieldPath, previousMap[key], valueMap[key], changes)
+		}
+		return
+	}
+	previousSlice, isPreviousSlice := previous.([]interface{})
+	valueSlice, isValueSlice := value.([]interface{})
+	if isPreviousSlice && isValueSlice && len(previousSlice) == len(valueSlice) {
+		for i := range previousSlice {
+			diff(fmt.Sprintf("%s[%d]", path, i), previousSlice[i], valueSlice[i], changes)
+		}
+		return
+	}
+	*changes = append(*changes, Change{Path: path, Previous: jsonValue(previous), Value: jsonValue(value)})
+}
+
+func jsonValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// Mutations logs the changes the controller makes to the resources it reconciles, to debug the reconcile loops
+// fighting with other controllers. The changes are also recorded as events of the mutated resources when the
+// recorder is set, and passed to the observer when it is set. A nil Mutations logs nothing.
+type Mutations struct {
+	// Log logs the changes, they are not logged when nil
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// Observe receives the changes of the mutated objects, e.g. to detect the reconcile loops
+	Observe func(obj runtime.Object, kind string, changes []Change)
+}
+
+// Record logs the changes of a mutation of the object from previous to value
+func (m *Mutations) Record(obj runtime.Object, kind string, previous interface{}, value interface{}) {
+	if m == nil {
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		m.logError(err, "Failed to access the mutated object", "kind", kind)
+		return
+	}
+	changes, err := Changes(previous, value)
+	if err != nil {
+		m.logError(err, "Failed to diff the mutation", "kind", kind, "namespace", accessor.GetNamespace(),
+			"name", accessor.GetName())
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+	if m.Observe != nil {
+		m.Observe(obj, kind, changes)
+	}
+	if m.Log != nil {
+		m.Log.Info("Mutated", "kind", kind, "namespace", accessor.GetNamespace(), "name", accessor.GetName(),
+			"changes", changes)
+	}
+	if m.Recorder == nil {
+		return
+	}
+	message := fmt.Sprintf("Updated %s: %s", kind, Summary(changes))
+	if len(message) > maxMutationMessage {
+		message = message[:maxMutationMessage-3] + "..."
+	}
+	m.Recorder.Event(obj, v1.EventTypeNormal, MutatedReason, message)
+}
+
+func (m *Mutations) logError(err error, msg string, keysAndValues ...interface{}) {
+	if m.Log != nil {
+		m.Log.Error(err, msg, keysAndValues...)
+	}
+}
+
+// Summary returns the changes on a line
+func Summary(changes []Change) string {
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.String()
+	}
+	return strings.Join(fields, "; ")
+}
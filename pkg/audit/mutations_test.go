@@ -0,0 +1,107 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestChanges(t *testing.T) {
+	traffic := func(latest int64, previous int64) knservingv1.RouteSpec {
+		return knservingv1.RouteSpec{
+			Traffic: []knservingv1.TrafficTarget{
+				{Tag: "latest", LatestRevision: proto.Bool(true), Percent: proto.Int64(latest)},
+				{Tag: "prev", RevisionName: "sklearn-predictor-default-00001", Percent: proto.Int64(previous)},
+			},
+		}
+	}
+	scenarios := map[string]struct {
+		previous interface{}
+		value    interface{}
+		expected []string
+	}{
+		"Unchanged": {
+			previous: traffic(10, 90),
+			value:    traffic(10, 90),
+			expected: []string{},
+		},
+		"ChangedFields": {
+			previous: traffic(10, 90),
+			value:    traffic(50, 50),
+			expected: []string{"traffic[0].percent: 10 -> 50", "traffic[1].percent: 90 -> 50"},
+		},
+		"ResizedList": {
+			previous: knservingv1.RouteSpec{
+				Traffic: []knservingv1.TrafficTarget{{Tag: "latest", Percent: proto.Int64(100)}},
+			},
+			value: traffic(0, 100),
+			expected: []string{`traffic: [{"percent":100,"tag":"latest"}] -> [{"latestRevision":true,"percent":0,` +
+				`"tag":"latest"},{"percent":100,"revisionName":"sklearn-predictor-default-00001","tag":"prev"}]`},
+		},
+		"SetAndUnsetFields": {
+			previous: metav1.ObjectMeta{Labels: map[string]string{"team": "a"}},
+			value:    metav1.ObjectMeta{Annotations: map[string]string{"owner": "b"}},
+			expected: []string{`annotations: <unset> -> {"owner":"b"}`, `labels: {"team":"a"} -> <unset>`},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			changes, err := Changes(scenario.previous, scenario.value)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			messages := []string{}
+			for _, change := range changes {
+				messages = append(messages, change.String())
+			}
+			g.Expect(messages).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestMutationsRecord(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	service := &knservingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "sklearn-predictor-default", Namespace: "default"}}
+	previous := knservingv1.RouteSpec{Traffic: []knservingv1.TrafficTarget{{Tag: "latest", Percent: proto.Int64(10)}}}
+	value := knservingv1.RouteSpec{Traffic: []knservingv1.TrafficTarget{{Tag: "latest", Percent: proto.Int64(20)}}}
+
+	var disabled *Mutations
+	disabled.Record(service, "Service", previous, value)
+
+	recorder := record.NewFakeRecorder(10)
+	logged := &Mutations{Log: logf.Log}
+	logged.Record(service, "Service", previous, value)
+	recorded := &Mutations{Log: logf.Log, Recorder: recorder}
+	recorded.Record(service, "Service", previous, previous)
+	g.Expect(recorder.Events).To(gomega.BeEmpty())
+	recorded.Record(service, "Service", previous, value)
+	g.Expect(recorder.Events).To(gomega.Receive(gomega.Equal("Normal Mutated Updated Service: traffic[0].percent: 10 -> 20")))
+
+	observed := []Change{}
+	observer := &Mutations{Observe: func(_ runtime.Object, kind string, changes []Change) {
+		observed = append(observed, changes...)
+	}}
+	observer.Record(service, "Service", previous, value)
+	g.Expect(observed).To(gomega.Equal([]Change{{Path: "traffic[0].percent", Previous: "10", Value: "20"}}))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudevents/sdk-go"
+	"github.com/go-logr/logr"
+	guuid "github.com/google/uuid"
+)
+
+const (
+	// CETypePrefix prefixes the lowercased event type, e.g. org.kubeflow.serving.inferenceservice.modeldeployed
+	CETypePrefix = "org.kubeflow.serving.inferenceservice."
+	// ComponentAttr is the cloud events extension attribute of the component
+	ComponentAttr = "component"
+
+	sendTimeout = 30 * time.Second
+)
+
+// Sink sends the audit events as cloud events to a sink, e.g. a Knative broker
+type Sink struct {
+	Log    logr.Logger
+	client cloudevents.Client
+}
+
+// NewSink creates a sink sending the audit events to the URL
+func NewSink(url string, log logr.Logger) (*Sink, error) {
+	t, err := cloudevents.NewHTTPTransport(
+		cloudevents.WithTarget(url),
+		cloudevents.WithEncoding(cloudevents.HTTPBinaryV1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while creating http transport: %s", err)
+	}
+	c, err := cloudevents.NewClient(t,
+		cloudevents.WithTimeNow(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("while creating new cloudevents client: %s", err)
+	}
+	return &Sink{Log: log, client: c}, nil
+}
+
+// Send sends the audit event, the reconciliation does not wait for the sink
+func (s *Sink) Send(event Event) {
+	go func() {
+		if err := s.send(event); err != nil {
+			s.Log.Error(err, "Failed to send audit event", "type", event.Type, "namespace", event.Namespace,
+				"name", event.InferenceService)
+		}
+	}()
+}
+
+func (s *Sink) send(event Event) error {
+	ce := cloudevents.NewEvent()
+	ce.SetID(guuid.New().String())
+	ce.SetType(CETypePrefix + strings.ToLower(string(event.Type)))
+	ce.SetSource(fmt.Sprintf("/apis/serving.kubeflow.org/v1beta1/namespaces/%s/inferenceservices/%s",
+		event.Namespace, event.InferenceService))
+	ce.SetSubject(event.Revision)
+	ce.SetExtension(ComponentAttr, string(event.Component))
+	ce.SetDataContentType(cloudevents.ApplicationJSON)
+	if err := ce.SetData(event); err != nil {
+		return fmt.Errorf("while setting cloudevents data: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if _, _, err := s.client.Send(ctx, ce); err != nil {
+		return fmt.Errorf("while sending event: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestSink(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	type received struct {
+		header http.Header
+		event  Event
+	}
+	events := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		g.Expect(err).To(gomega.BeNil())
+		event := Event{}
+		g.Expect(json.Unmarshal(b, &event)).To(gomega.Succeed())
+		events <- received{header: req.Header, event: event}
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, logf.Log.WithName("audit"))
+	g.Expect(err).To(gomega.BeNil())
+	event := Event{
+		Type:             ModelDeployed,
+		Namespace:        "default",
+		InferenceService: "sklearn",
+		Component:        v1beta1.PredictorComponent,
+		Revision:         "sklearn-predictor-default-00002",
+		PreviousRevision: "sklearn-predictor-default-00001",
+		StorageUri:       "gs://kfserving-samples/models/sklearn/iris-v2",
+	}
+	sink.Send(event)
+
+	var r received
+	g.Eventually(events).Should(gomega.Receive(&r))
+	g.Expect(r.event).To(gomega.Equal(event))
+	g.Expect(r.header.Get("Ce-Type")).To(gomega.Equal("org.kubeflow.serving.inferenceservice.modeldeployed"))
+	g.Expect(r.header.Get("Ce-Source")).To(
+		gomega.Equal("/apis/serving.kubeflow.org/v1beta1/namespaces/default/inferenceservices/sklearn"))
+	g.Expect(r.header.Get("Ce-Subject")).To(gomega.Equal("sklearn-predictor-default-00002"))
+	g.Expect(r.header.Get("Ce-Component")).To(gomega.Equal("predictor"))
+}
@@ -24,11 +24,18 @@ import (
 	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/tracing"
 	"github.com/satori/go.uuid"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -36,6 +43,17 @@ const (
 	SleepTime    = time.Microsecond * 100
 	MaxBatchSize = 32
 	MaxLatency   = 5000
+	// RetryAfter is the delay in seconds advised to the clients when the predictor does not advise one
+	RetryAfter = 1
+	// DrainPollInterval is the interval at which the pending requests are checked while draining
+	DrainPollInterval = 100 * time.Millisecond
+)
+
+// Headers signaling the back pressure to the clients, the queue depth is the number of pending requests of the batcher
+// including the request itself, the Retry-After is set on the 503 responses.
+const (
+	RetryAfterHeader = "Retry-After"
+	QueueDepthHeader = "X-Queue-Depth"
 )
 
 var (
@@ -43,6 +61,7 @@ var (
 	channelIn   = make(chan Input)
 	batcherInfo BatcherInfo
 	mutex       sync.Mutex
+	queueDepth  int64
 )
 
 type MainController struct {
@@ -57,6 +76,8 @@ type Input struct {
 	ContextInput *context.Context
 	Instances    *[]interface{}
 	ChannelOut   *chan Response
+	// TraceContext is the W3C trace context of the request, nil when it is not traced
+	TraceContext http.Header
 }
 
 type InputInfo struct {
@@ -68,6 +89,8 @@ type Response struct {
 	Message     string        `json:"message"`
 	BatchID     string        `json:"batchId"`
 	Predictions []interface{} `json:"predictions"`
+	StatusCode  int           `json:"-"`
+	RetryAfter  string        `json:"-"`
 }
 
 type ResponseError struct {
@@ -81,6 +104,8 @@ type Predictions struct {
 type BatcherInfo struct {
 	MaxBatchSize    int
 	MaxLatency      int
+	MaxQueueDepth   int
+	RetryAfter      int
 	Port            string
 	SvcHost         string
 	SvcPort         string
@@ -94,16 +119,34 @@ type BatcherInfo struct {
 	Start           time.Time
 	Now             time.Time
 	CurrentInputLen int
+	// StatusCode and UpstreamRetryAfter are set when the predictor is saturated or unavailable
+	StatusCode         int
+	UpstreamRetryAfter string
+	// TraceContext is the trace context of the first request of the batch, the predictor call of the batch is part of
+	// the trace of that request
+	TraceContext http.Header
+	// Streaming passes the requests which cannot be batched through to the predictor, see streamRequest
+	Streaming bool
+	// proxy passes the streamed requests and the websocket connections through to the predictor
+	proxy *httputil.ReverseProxy
 }
 
 func Config(port string, svcHost string, svcPort string,
-	maxBatchSize int, maxLatency int, timeout int) {
+	maxBatchSize int, maxLatency int, timeout int, maxQueueDepth int, retryAfter int, streaming bool) {
 	batcherInfo.Port = port
 	batcherInfo.SvcHost = svcHost
 	batcherInfo.SvcPort = svcPort
 	batcherInfo.MaxBatchSize = maxBatchSize
 	batcherInfo.MaxLatency = maxLatency
 	batcherInfo.Timeout = time.Duration(timeout) * time.Second
+	batcherInfo.MaxQueueDepth = maxQueueDepth
+	batcherInfo.RetryAfter = retryAfter
+	batcherInfo.Streaming = streaming
+	// The streamed responses are flushed to the client as they are written by the predictor
+	batcherInfo.proxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: net.JoinHostPort(svcHost, svcPort)})
+	batcherInfo.proxy.FlushInterval = -1
+	batcherInfo.proxy.ModifyResponse = modifyProxyResponse
+	batcherInfo.proxy.ErrorHandler = proxyErrorHandler
 }
 
 func GetNowTime() time.Time {
@@ -127,14 +170,23 @@ func (batcherInfo *BatcherInfo) CallService() *string {
 		return &errStr
 	}
 	req.Header.Add("Content-Type", batcherInfo.ContentType)
+	tracing.Propagate(req.Header, batcherInfo.TraceContext)
 	defer req.Body.Close()
 	client := &http.Client{Timeout: batcherInfo.Timeout}
 	resp, err := client.Do(req)
 	if err != nil {
+		// The predictor is not listening yet while it is scaling up or does not answer in time while saturated
+		batcherInfo.StatusCode = http.StatusServiceUnavailable
 		errStr = fmt.Sprintf("NewRequest send fail: %v", err)
 		return &errStr
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests {
+		batcherInfo.StatusCode = http.StatusServiceUnavailable
+		batcherInfo.UpstreamRetryAfter = resp.Header.Get(RetryAfterHeader)
+		errStr = fmt.Sprintf("Predictor is saturated: %s", resp.Status)
+		return &errStr
+	}
 	result, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		errStr = fmt.Sprintf("Response read fail: %v", err)
@@ -158,6 +210,9 @@ func (batcherInfo *BatcherInfo) InitializeInfo() {
 	batcherInfo.Info = make(map[*context.Context]InputInfo)
 	batcherInfo.Start = GetNowTime()
 	batcherInfo.Now = batcherInfo.Start
+	batcherInfo.StatusCode = 0
+	batcherInfo.UpstreamRetryAfter = ""
+	batcherInfo.TraceContext = nil
 }
 
 func (batcherInfo *BatcherInfo) BatchPredict() {
@@ -169,6 +224,8 @@ func (batcherInfo *BatcherInfo) BatchPredict() {
 				Message:     *err,
 				BatchID:     "",
 				Predictions: nil,
+				StatusCode:  batcherInfo.StatusCode,
+				RetryAfter:  batcherInfo.UpstreamRetryAfter,
 			}
 			*v.ChannelOut <- res
 		}
@@ -201,6 +258,7 @@ func (batcherInfo *BatcherInfo) Batcher() {
 		case req := <-channelIn:
 			if len(batcherInfo.Instances) == 0 {
 				batcherInfo.Start = GetNowTime()
+				batcherInfo.TraceContext = req.TraceContext
 			}
 			batcherInfo.CurrentInputLen = len(batcherInfo.Instances)
 			batcherInfo.Instances = append(batcherInfo.Instances, *req.Instances...)
@@ -236,10 +294,67 @@ func (batcherInfo *BatcherInfo) Consume() {
 	batcherInfo.Batcher()
 }
 
+// Drain waits for the delay while the requests are still served, then for the pending requests to complete up to the
+// timeout. It returns false when requests are still pending at the timeout.
+func Drain(delay time.Duration, timeout time.Duration) bool {
+	time.Sleep(delay)
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&queueDepth) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(DrainPollInterval)
+	}
+	return true
+}
+
+// streamRequest returns true for the requests passed through to the predictor in streaming mode: the requests asking
+// for server-sent events and the requests without instances, e.g. the prompts of the generative models
+func streamRequest(header http.Header, body []byte) bool {
+	if strings.Contains(header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	var req Request
+	return json.Unmarshal(body, &req) != nil || len(req.Instances) == 0
+}
+
+// stream passes the request through to the predictor, the request counts as pending until its response is complete
+func (c *MainController) stream() {
+	defer atomic.AddInt64(&queueDepth, -1)
+	if !c.enqueue() {
+		return
+	}
+	log.Info("Post", "Streaming request", c.Ctx.Input.URL())
+	// The body was read by beego
+	c.Ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(c.Ctx.Input.RequestBody))
+	c.Ctx.Request.ContentLength = int64(len(c.Ctx.Input.RequestBody))
+	batcherInfo.proxy.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
+}
+
+// Get relays the websocket connections to the predictor, the other requests are not served by the batcher
+func (c *MainController) Get() {
+	if !strings.EqualFold(c.Ctx.Request.Header.Get("Upgrade"), "websocket") {
+		c.Controller.Get()
+		return
+	}
+	defer atomic.AddInt64(&queueDepth, -1)
+	if !c.enqueue() {
+		return
+	}
+	log.Info("Get", "Websocket connection", c.Ctx.Input.URL())
+	// The connection is hijacked by the proxy, beego must not render a response
+	c.EnableRender = false
+	batcherInfo.proxy.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
+}
+
 func (c *MainController) Post() {
 	var req Request
 	var err error
 	log.Info("Post", "Request Body Len", len(string(c.Ctx.Input.RequestBody)))
+	if batcherInfo.Streaming && streamRequest(c.Ctx.Request.Header, c.Ctx.Input.RequestBody) {
+		c.stream()
+		return
+	}
 	if err = json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
 		log.Error(errors.New("unmarshal fail"), "")
 		c.Abort("400")
@@ -259,21 +374,86 @@ func (c *MainController) Post() {
 		mutex.Unlock()
 	}
 
+	defer atomic.AddInt64(&queueDepth, -1)
+	if !c.enqueue() {
+		return
+	}
+
 	var ctx = context.Background()
 	var chl = make(chan Response)
 	channelIn <- Input{
 		&ctx,
 		&req.Instances,
 		&chl,
+		tracing.Extract(c.Ctx.Request.Header),
 	}
 
 	response := <-chl
 	close(chl)
 
+	if response.StatusCode == http.StatusServiceUnavailable {
+		c.serveUnavailable(&response, response.RetryAfter)
+		return
+	}
 	c.Data["json"] = &response
 	c.ServeJSON()
 }
 
+// enqueue counts the request as pending and sets its queue depth header, the requests beyond the max queue depth are
+// answered with a 503 and false is returned. The request is counted until the caller decrements the queue depth.
+func (c *MainController) enqueue() bool {
+	depth := atomic.AddInt64(&queueDepth, 1)
+	c.Ctx.Output.Header(QueueDepthHeader, strconv.FormatInt(depth, 10))
+	if batcherInfo.MaxQueueDepth > 0 && depth > int64(batcherInfo.MaxQueueDepth) {
+		log.Info("Rejected with queue depth", "depth", depth, "url", c.Ctx.Input.URL())
+		c.serveUnavailable(&ResponseError{Message: "queue is full"}, "")
+		return false
+	}
+	return true
+}
+
+// defaultRetryAfter returns the delay advised to the clients when the predictor does not advise one
+func defaultRetryAfter() string {
+	if batcherInfo.RetryAfter > 0 {
+		return strconv.Itoa(batcherInfo.RetryAfter)
+	}
+	return strconv.Itoa(RetryAfter)
+}
+
+// modifyProxyResponse answers the saturated predictor responses of the passed through requests with a 503 and a
+// Retry-After, like the responses of the batched requests
+func modifyProxyResponse(resp *http.Response) error {
+	if resp.StatusCode != http.StatusServiceUnavailable && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	resp.StatusCode = http.StatusServiceUnavailable
+	resp.Status = fmt.Sprintf("%d %s", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable))
+	if resp.Header.Get(RetryAfterHeader) == "" {
+		resp.Header.Set(RetryAfterHeader, defaultRetryAfter())
+	}
+	return nil
+}
+
+// proxyErrorHandler answers the passed through requests with a 503 and a Retry-After when the predictor is not
+// reachable, e.g. while it is scaling up
+func proxyErrorHandler(rw http.ResponseWriter, req *http.Request, err error) {
+	log.Error(err, "Failed to proxy the request to the predictor", "url", req.URL.String())
+	rw.Header().Set(RetryAfterHeader, defaultRetryAfter())
+	rw.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// serveUnavailable responds with a 503 advising the clients to retry after the delay of the predictor, or the
+// configured delay when the predictor does not advise one.
+func (c *MainController) serveUnavailable(body interface{}, retryAfter string) {
+	if retryAfter == "" {
+		retryAfter = defaultRetryAfter()
+	}
+	c.Ctx.Output.Header(RetryAfterHeader, retryAfter)
+	c.Ctx.Output.SetStatus(http.StatusServiceUnavailable)
+	c.Data["json"] = body
+	c.ServeJSON()
+}
+
 func init() {
 	logf.SetLogger(logf.ZapLogger(false))
 	log = logf.Log.WithName("entrypoint")
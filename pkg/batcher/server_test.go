@@ -17,14 +17,17 @@ limitations under the License.
 package batcher
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/kubeflow/kfserving/pkg/batcher/controllers"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/tracing"
 	"github.com/onsi/gomega"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -32,7 +35,9 @@ import (
 	"runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -70,7 +75,7 @@ func TestBatcher(t *testing.T) {
 
 	g.Expect(err).To(gomega.BeNil())
 	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
-		predictorSvcUrl.Port(), 32, 1.0, 60)
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, false)
 	println(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
 		predictorSvcUrl.Port())
 
@@ -90,3 +95,241 @@ func TestBatcher(t *testing.T) {
 	fmt.Println(string(josnStr))
 	g.Expect(josnStr).To(gomega.Equal(predictorResponse))
 }
+
+func TestBatcherBackPressure(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	saturated := int32(1)
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&saturated) == 1 {
+			rw.Header().Set(controllers.RetryAfterHeader, "5")
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received <- struct{}{}
+		<-release
+		_, err := rw.Write([]byte(`{"predictions":[[4,5,6]]}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 1, 2, false)
+
+	post := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"instances":[[0,0,0]]}`)))
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, r)
+		return w
+	}
+
+	// The Retry-After of the saturated predictor is forwarded to the client
+	w := post()
+	g.Expect(w.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+	g.Expect(w.Header().Get(controllers.RetryAfterHeader)).To(gomega.Equal("5"))
+	g.Expect(w.Header().Get(controllers.QueueDepthHeader)).To(gomega.Equal("1"))
+
+	// The requests beyond the max queue depth are rejected with the configured Retry-After
+	atomic.StoreInt32(&saturated, 0)
+	pending := make(chan *httptest.ResponseRecorder)
+	go func() {
+		pending <- post()
+	}()
+	select {
+	case <-received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the predictor did not receive the pending request")
+	}
+	w = post()
+	g.Expect(w.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+	g.Expect(w.Header().Get(controllers.RetryAfterHeader)).To(gomega.Equal("2"))
+	g.Expect(w.Header().Get(controllers.QueueDepthHeader)).To(gomega.Equal("2"))
+
+	// The drain times out while the request is pending
+	g.Expect(controllers.Drain(0, 10*time.Millisecond)).To(gomega.BeFalse())
+
+	close(release)
+	w = <-pending
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	g.Expect(w.Header().Get(controllers.QueueDepthHeader)).To(gomega.Equal("1"))
+	g.Expect(w.Header().Get(controllers.RetryAfterHeader)).To(gomega.BeEmpty())
+	g.Expect(controllers.Drain(0, time.Second)).To(gomega.BeTrue())
+}
+
+func TestBatcherTracePropagation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	predicted := make(chan http.Header, 1)
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		predicted <- req.Header
+		_, err := rw.Write([]byte(`{"predictions":[[4,5,6]]}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, false)
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"instances":[[0,0,0]]}`)))
+	r.Header.Set(tracing.TraceParentHeader, traceParent)
+	r.Header.Set(tracing.BaggageHeader, "userId=alice")
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	var header http.Header
+	g.Expect(predicted).To(gomega.Receive(&header))
+	g.Expect(header.Get(tracing.TraceParentHeader)).To(gomega.Equal(traceParent))
+	g.Expect(header.Get(tracing.BaggageHeader)).To(gomega.Equal("userId=alice"))
+}
+
+func TestBatcherStreaming(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// The predictor streams the tokens of the prompts as server-sent events, the second event once the first one
+	// reached the client
+	release := make(chan struct{})
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		g.Expect(err).To(gomega.BeNil())
+		if string(b) == `{"instances":[[0,0,0]]}` {
+			_, err = rw.Write([]byte(`{"predictions":[[4,5,6]]}`))
+			g.Expect(err).To(gomega.BeNil())
+			return
+		}
+		g.Expect(string(b)).To(gomega.Equal(`{"prompt":"Hi"}`))
+		rw.Header().Set("Content-Type", "text/event-stream")
+		_, err = rw.Write([]byte("data: Hello\n\n"))
+		g.Expect(err).To(gomega.BeNil())
+		rw.(http.Flusher).Flush()
+		<-release
+		_, err = rw.Write([]byte("data: world\n\n"))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, true)
+	defer controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, false)
+	batcher := httptest.NewServer(beego.BeeApp.Handlers)
+	defer batcher.Close()
+
+	// The requests with instances are still batched
+	resp, err := http.Post(batcher.URL+"/v1/models/mymodel:predict", "application/json",
+		bytes.NewReader([]byte(`{"instances":[[0,0,0]]}`)))
+	g.Expect(err).To(gomega.BeNil())
+	var res controllers.Response
+	g.Expect(json.NewDecoder(resp.Body).Decode(&res)).To(gomega.Succeed())
+	resp.Body.Close()
+	g.Expect(res.BatchID).NotTo(gomega.BeEmpty())
+
+	// The prompts are passed through and their events are not buffered
+	resp, err = http.Post(batcher.URL+"/v1/models/mymodel:generate", "application/json",
+		bytes.NewReader([]byte(`{"prompt":"Hi"}`)))
+	g.Expect(err).To(gomega.BeNil())
+	defer resp.Body.Close()
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusOK))
+	g.Expect(resp.Header.Get("Content-Type")).To(gomega.Equal("text/event-stream"))
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(line).To(gomega.Equal("data: Hello\n"))
+	close(release)
+	rest, err := ioutil.ReadAll(reader)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(rest)).To(gomega.Equal("\ndata: world\n\n"))
+	g.Expect(controllers.Drain(0, time.Second)).To(gomega.BeTrue())
+}
+
+func TestBatcherStreamingBackPressure(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 2, true)
+	defer controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, false)
+	batcher := httptest.NewServer(beego.BeeApp.Handlers)
+	defer batcher.Close()
+
+	generate := func() *http.Response {
+		resp, err := http.Post(batcher.URL+"/v1/models/mymodel:generate", "application/json",
+			bytes.NewReader([]byte(`{"prompt":"Hi"}`)))
+		g.Expect(err).To(gomega.BeNil())
+		resp.Body.Close()
+		return resp
+	}
+
+	// The saturated predictor is answered with a 503 and the configured Retry-After
+	resp := generate()
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusServiceUnavailable))
+	g.Expect(resp.Header.Get(controllers.RetryAfterHeader)).To(gomega.Equal("2"))
+	g.Expect(resp.Header.Get(controllers.QueueDepthHeader)).To(gomega.Equal("1"))
+
+	// The predictor not reachable while scaling up is answered the same
+	predictor.Close()
+	resp = generate()
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusServiceUnavailable))
+	g.Expect(resp.Header.Get(controllers.RetryAfterHeader)).To(gomega.Equal("2"))
+	g.Expect(resp.Header.Get(controllers.QueueDepthHeader)).To(gomega.Equal("1"))
+	g.Expect(controllers.Drain(0, time.Second)).To(gomega.BeTrue())
+}
+
+func TestBatcherWebsocket(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// The predictor upgrades the connection and echoes the messages
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		g.Expect(req.Header.Get("Upgrade")).To(gomega.Equal("websocket"))
+		conn, buf, err := rw.(http.Hijacker).Hijack()
+		g.Expect(err).To(gomega.BeNil())
+		defer conn.Close()
+		_, err = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(buf.Flush()).To(gomega.Succeed())
+		line, err := buf.ReadString('\n')
+		g.Expect(err).To(gomega.BeNil())
+		_, err = conn.Write([]byte("echo " + line))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	controllers.Config(constants.InferenceServiceDefaultBatcherPort, predictorSvcUrl.Hostname(),
+		predictorSvcUrl.Port(), 32, 1.0, 60, 0, 0, false)
+	batcher := httptest.NewServer(beego.BeeApp.Handlers)
+	defer batcher.Close()
+
+	// The other GET requests are not served
+	resp, err := http.Get(batcher.URL + "/v1/models/mymodel")
+	g.Expect(err).To(gomega.BeNil())
+	resp.Body.Close()
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusMethodNotAllowed))
+
+	conn, err := net.Dial("tcp", batcher.Listener.Addr().String())
+	g.Expect(err).To(gomega.BeNil())
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /v1/models/mymodel:predict HTTP/1.1\r\nHost: mymodel\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	g.Expect(err).To(gomega.BeNil())
+	reader := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(reader, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusSwitchingProtocols))
+	_, err = conn.Write([]byte("hello\n"))
+	g.Expect(err).To(gomega.BeNil())
+	line, err := reader.ReadString('\n')
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(line).To(gomega.Equal("echo hello\n"))
+}
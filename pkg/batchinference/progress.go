@@ -0,0 +1,55 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinference
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ProgressKey returns the key of the progress of a shard in the progress config map of the job
+func ProgressKey(shard int) string {
+	return fmt.Sprintf("shard-%d", shard)
+}
+
+// ConfigMapReporter reports the progress of a worker to the key of its shard in the progress config map of the job, the
+// config map is created by the controller with a role only granting the workers its update
+type ConfigMapReporter struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+	Shard     int
+}
+
+func (r *ConfigMapReporter) Report(progress *Progress) error {
+	value, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	// A merge patch only sets the key of the shard, the workers of the other shards update the config map concurrently
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{ProgressKey(r.Shard): string(value)},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.Client.CoreV1().ConfigMaps(r.Namespace).Patch(r.Name, types.MergePatchType, patch)
+	return err
+}
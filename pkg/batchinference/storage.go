@@ -0,0 +1,201 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinference
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	s3credential "github.com/kubeflow/kfserving/pkg/credentials/s3"
+	gcs "google.golang.org/api/storage/v1"
+)
+
+const (
+	S3Scheme  = "s3://"
+	GCSScheme = "gs://"
+	// DefaultRegion is the region of the S3 API when AWS_REGION is not set
+	DefaultRegion = "us-east-1"
+)
+
+// Location is a storage prefix of a bucket
+type Location struct {
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+// ParseLocation parses a storage prefix, e.g. s3://bucket/inputs/
+func ParseLocation(uri string) (*Location, error) {
+	for _, scheme := range []string{S3Scheme, GCSScheme} {
+		if !strings.HasPrefix(uri, scheme) {
+			continue
+		}
+		path := strings.SplitN(strings.TrimPrefix(uri, scheme), "/", 2)
+		if path[0] == "" {
+			break
+		}
+		location := &Location{Scheme: scheme, Bucket: path[0]}
+		if len(path) == 2 {
+			location.Prefix = path[1]
+		}
+		return location, nil
+	}
+	return nil, fmt.Errorf("unsupported location %q, it must start with %s or %s followed by a bucket", uri, S3Scheme,
+		GCSScheme)
+}
+
+// Key returns the key of an object under the prefix of the location
+func (l *Location) Key(name string) string {
+	if l.Prefix == "" {
+		return strings.TrimPrefix(name, "/")
+	}
+	return strings.TrimSuffix(l.Prefix, "/") + "/" + strings.TrimPrefix(name, "/")
+}
+
+// Storage reads and writes the objects of the buckets of a storage service
+type Storage interface {
+	// List returns the keys of the objects under the prefix of a bucket
+	List(ctx context.Context, bucket string, prefix string) ([]string, error)
+	// Read opens an object for reading
+	Read(ctx context.Context, bucket string, key string) (io.ReadCloser, error)
+	// Write uploads an object while its body is read, the body is not held in memory
+	Write(ctx context.Context, bucket string, key string, body io.Reader) error
+}
+
+// NewStorage creates the storage of the location. The S3 locations use the credentials of the AWS environment
+// variables, the GCS locations use the service account key of GOOGLE_APPLICATION_CREDENTIALS.
+func NewStorage(ctx context.Context, location *Location) (Storage, error) {
+	if location.Scheme == GCSScheme {
+		service, err := gcs.NewService(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &GCSStorage{Service: service}, nil
+	}
+	client, err := NewS3Client()
+	if err != nil {
+		return nil, err
+	}
+	return &S3Storage{Client: client}, nil
+}
+
+// NewS3Client creates the client of the S3 API with the credentials of the AWS environment variables, it uses the
+// endpoint of AWS_ENDPOINT_URL when set
+func NewS3Client() (s3iface.S3API, error) {
+	config := &aws.Config{Region: aws.String(DefaultRegion)}
+	if region, ok := os.LookupEnv(s3credential.AWSRegion); ok {
+		config.Region = aws.String(region)
+	}
+	if endpoint, ok := os.LookupEnv(s3credential.AWSEndpointUrl); ok {
+		config.Endpoint = aws.String(endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	return s3.New(sess), nil
+}
+
+// S3Storage is the storage of the S3 API
+type S3Storage struct {
+	Client s3iface.S3API
+}
+
+func (s *S3Storage) List(ctx context.Context, bucket string, prefix string) ([]string, error) {
+	keys := []string{}
+	input := &s3.ListObjectsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	for {
+		output, err := s.Client.ListObjectsWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range output.Contents {
+			keys = append(keys, *object.Key)
+		}
+		if output.IsTruncated == nil || !*output.IsTruncated || len(output.Contents) == 0 {
+			break
+		}
+		input.Marker = output.Contents[len(output.Contents)-1].Key
+	}
+	return keys, nil
+}
+
+func (s *S3Storage) Read(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	object, err := s.Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return object.Body, nil
+}
+
+// Write uploads the object in parts with a multipart upload, a single part is buffered at a time
+func (s *S3Storage) Write(ctx context.Context, bucket string, key string, body io.Reader) error {
+	uploader := s3manager.NewUploaderWithClient(s.Client, func(u *s3manager.Uploader) {
+		u.Concurrency = 1
+	})
+	_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	return err
+}
+
+// GCSStorage is the storage of the JSON API of GCS
+type GCSStorage struct {
+	Service *gcs.Service
+}
+
+func (s *GCSStorage) List(ctx context.Context, bucket string, prefix string) ([]string, error) {
+	keys := []string{}
+	err := s.Service.Objects.List(bucket).Prefix(prefix).Pages(ctx, func(objects *gcs.Objects) error {
+		for _, object := range objects.Items {
+			keys = append(keys, object.Name)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (s *GCSStorage) Read(ctx context.Context, bucket string, key string) (io.ReadCloser, error) {
+	response, err := s.Service.Objects.Get(bucket, key).Context(ctx).Download()
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// Write uploads the object with a resumable upload, a single chunk is buffered at a time
+func (s *GCSStorage) Write(ctx context.Context, bucket string, key string, body io.Reader) error {
+	_, err := s.Service.Objects.Insert(bucket, &gcs.Object{Name: key}).Media(body).Context(ctx).Do()
+	return err
+}
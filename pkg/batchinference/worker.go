@@ -0,0 +1,235 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinference
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Progress of a worker, reported while the shard is processed and written to the termination message of the worker
+// once its shard is processed
+type Progress struct {
+	// Number of input files processed
+	Files int `json:"files"`
+	// Number of records sent to the predict endpoint
+	ProcessedRecords int64 `json:"processedRecords"`
+	// Number of records whose prediction failed, their output line is an error
+	FailedRecords int64 `json:"failedRecords"`
+}
+
+// ProgressReporter publishes the progress of a running worker
+type ProgressReporter interface {
+	Report(progress *Progress) error
+}
+
+// Worker runs the records of a shard of the input files through the predict endpoint of an inference service. The
+// input files are sorted by key and the worker processes the files whose index modulo the number of shards is its
+// shard index. Each line of an input file is the body of a predict request, the response is written on the same line
+// of the output file.
+type Worker struct {
+	InputStorage  Storage
+	Input         *Location
+	OutputStorage Storage
+	Output        *Location
+	PredictURL    string
+	ShardIndex    int
+	ShardCount    int
+	// Retries of a request failing with a connection error or a 5xx response, the retries back off exponentially
+	// from RetryInterval
+	Retries       int
+	RetryInterval time.Duration
+	Client        *http.Client
+	// Reporter publishes the progress after each file and every ReportInterval while a file is processed, the
+	// progress is not reported when nil
+	Reporter       ProgressReporter
+	ReportInterval time.Duration
+	Log            logr.Logger
+
+	reported time.Time
+}
+
+// Run processes the shard of the worker, it fails when an input file cannot be read or an output file written
+func (w *Worker) Run(ctx context.Context) (*Progress, error) {
+	keys, err := w.listShard(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fails to list the input files")
+	}
+	w.Log.Info("Processing shard", "shard", w.ShardIndex, "shards", w.ShardCount, "files", len(keys))
+	progress := &Progress{}
+	w.report(progress)
+	for _, key := range keys {
+		if err := w.processFile(ctx, key, progress); err != nil {
+			return progress, errors.Wrapf(err, "fails to process input file %s", key)
+		}
+		progress.Files++
+		w.report(progress)
+	}
+	return progress, nil
+}
+
+// report publishes the progress with the reporter of the worker, a progress not reported is only logged
+func (w *Worker) report(progress *Progress) {
+	if w.Reporter == nil {
+		return
+	}
+	w.reported = time.Now()
+	if err := w.Reporter.Report(progress); err != nil {
+		w.Log.Error(err, "Failed to report the progress", "records", progress.ProcessedRecords)
+	}
+}
+
+// listShard lists the keys of the input files of the shard of the worker
+func (w *Worker) listShard(ctx context.Context) ([]string, error) {
+	objects, err := w.InputStorage.List(ctx, w.Input.Bucket, w.Input.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, key := range objects {
+		if !strings.HasSuffix(key, "/") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	shard := []string{}
+	for i, key := range keys {
+		if i%w.ShardCount == w.ShardIndex {
+			shard = append(shard, key)
+		}
+	}
+	return shard, nil
+}
+
+// processFile streams the records of an input file through the predict endpoint and the responses to the output
+// file, the output file is uploaded while the records are processed
+func (w *Worker) processFile(ctx context.Context, key string, progress *Progress) error {
+	input, err := w.InputStorage.Read(ctx, w.Input.Bucket, key)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	outputKey := w.Output.Key(strings.TrimPrefix(key, w.Input.Prefix))
+	w.Log.Info("Processing input file", "input", key, "output", outputKey)
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer.CloseWithError(w.predictFile(ctx, input, writer, progress))
+	}()
+	err = w.OutputStorage.Write(ctx, w.Output.Bucket, outputKey, reader)
+	// The records are no longer processed once the upload failed
+	reader.CloseWithError(io.ErrClosedPipe)
+	<-done
+	return err
+}
+
+// predictFile writes the response of each record of the input to the output
+func (w *Worker) predictFile(ctx context.Context, input io.Reader, output io.Writer, progress *Progress) error {
+	reader := bufio.NewReader(input)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if record := bytes.TrimSpace(line); len(record) != 0 {
+			progress.ProcessedRecords++
+			response, predictErr := w.predict(ctx, record)
+			if predictErr != nil {
+				progress.FailedRecords++
+				response, _ = json.Marshal(map[string]string{"error": predictErr.Error()})
+			}
+			if _, err := output.Write(response); err != nil {
+				return err
+			}
+			if time.Since(w.reported) >= w.ReportInterval {
+				w.report(progress)
+			}
+		}
+		// The empty lines are kept so the lines of the output file match the lines of the input file
+		if len(line) != 0 && line[len(line)-1] == '\n' {
+			if _, err := output.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// predict sends a record to the predict endpoint and returns the compacted JSON response
+func (w *Worker) predict(ctx context.Context, record []byte) ([]byte, error) {
+	var err error
+	for attempt := 0; attempt <= w.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(w.RetryInterval << uint(attempt-1)):
+			}
+		}
+		var response []byte
+		var retry bool
+		if response, retry, err = w.send(ctx, record); err == nil {
+			return response, nil
+		} else if !retry {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// send sends a record to the predict endpoint once, it returns whether the request can be retried on error
+func (w *Worker) send(ctx context.Context, record []byte) ([]byte, bool, error) {
+	request, err := http.NewRequest(http.MethodPost, w.PredictURL, bytes.NewReader(record))
+	if err != nil {
+		return nil, false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := w.Client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, response.StatusCode >= http.StatusInternalServerError,
+			fmt.Errorf("predict request failed with status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, body); err != nil {
+		return nil, false, errors.Wrapf(err, "invalid predict response")
+	}
+	return compacted.Bytes(), false, nil
+}
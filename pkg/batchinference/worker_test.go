@@ -0,0 +1,297 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// fakeS3Client stores the objects of a bucket in memory and lists them a page of pageSize objects at a time
+type fakeS3Client struct {
+	s3iface.S3API
+	mu       sync.Mutex
+	objects  map[string]string
+	pageSize int
+}
+
+func (c *fakeS3Client) ListObjectsWithContext(_ aws.Context, input *s3.ListObjectsInput,
+	_ ...request.Option) (*s3.ListObjectsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := []string{}
+	for key := range c.objects {
+		if strings.HasPrefix(key, *input.Prefix) && (input.Marker == nil || key > *input.Marker) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	output := &s3.ListObjectsOutput{IsTruncated: aws.Bool(len(keys) > c.pageSize)}
+	if len(keys) > c.pageSize {
+		keys = keys[:c.pageSize]
+	}
+	for _, key := range keys {
+		output.Contents = append(output.Contents, &s3.Object{Key: aws.String(key)})
+	}
+	return output, nil
+}
+
+// fakeStorage stores the objects of a bucket in memory
+type fakeStorage struct {
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (s *fakeStorage) List(_ context.Context, _ string, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := []string{}
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *fakeStorage) Read(_ context.Context, _ string, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ioutil.NopCloser(strings.NewReader(s.objects[key])), nil
+}
+
+func (s *fakeStorage) Write(_ context.Context, _ string, key string, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = string(data)
+	return err
+}
+
+// fakeReporter records the reported progress
+type fakeReporter struct {
+	reports []Progress
+}
+
+func (r *fakeReporter) Report(progress *Progress) error {
+	r.reports = append(r.reports, *progress)
+	return nil
+}
+
+// fakePredictor returns the instances of the requests as predictions, it fails the first failures requests and rejects
+// the invalid requests
+type fakePredictor struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+}
+
+func (p *fakePredictor) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests++
+	if p.failures > 0 {
+		p.failures--
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if !bytes.HasPrefix(body, []byte(`{"instances"`)) {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte("invalid request"))
+		return
+	}
+	rw.Write([]byte(strings.Replace(string(body), `"instances"`, `"predictions"`, 1) + "\n"))
+}
+
+func TestParseLocation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	location, err := ParseLocation("s3://bucket/inputs/")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(location).To(gomega.Equal(&Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "inputs/"}))
+	g.Expect(location.Key("/a.jsonl")).To(gomega.Equal("inputs/a.jsonl"))
+	location, err = ParseLocation("gs://bucket")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(location).To(gomega.Equal(&Location{Scheme: GCSScheme, Bucket: "bucket"}))
+	g.Expect(location.Key("a.jsonl")).To(gomega.Equal("a.jsonl"))
+	_, err = ParseLocation("s3:///inputs")
+	g.Expect(err).NotTo(gomega.BeNil())
+	_, err = ParseLocation("pvc://claim/inputs")
+	g.Expect(err).NotTo(gomega.BeNil())
+}
+
+func TestS3StorageList(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	storage := &S3Storage{Client: &fakeS3Client{
+		pageSize: 2,
+		objects: map[string]string{
+			"inputs/":        "",
+			"inputs/a.jsonl": "",
+			"inputs/b.jsonl": "",
+			"other/c.jsonl":  "",
+		},
+	}}
+	keys, err := storage.List(context.Background(), "bucket", "inputs/")
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(keys).To(gomega.Equal([]string{"inputs/", "inputs/a.jsonl", "inputs/b.jsonl"}))
+}
+
+func TestWorker(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	input := &fakeStorage{
+		objects: map[string]string{
+			"inputs/":          "",
+			"inputs/a.jsonl":   "{\"instances\":[1]}\n\n{\"instances\": [2]}\n",
+			"inputs/b.jsonl":   "{\"instances\":[3]}",
+			"inputs/c/d.jsonl": "{\"invalid\":[4]}\n{\"instances\":[5]}\n",
+			"other/e.jsonl":    "{\"instances\":[6]}\n",
+		},
+	}
+	predictor := &fakePredictor{failures: 1}
+	server := httptest.NewServer(predictor)
+	defer server.Close()
+
+	for shard, expected := range []map[string]string{
+		{
+			"outputs/a.jsonl":   "{\"predictions\":[1]}\n\n{\"predictions\":[2]}\n",
+			"outputs/c/d.jsonl": "{\"error\":\"predict request failed with status 400: invalid request\"}\n{\"predictions\":[5]}\n",
+		},
+		{
+			"outputs/b.jsonl": "{\"predictions\":[3]}",
+		},
+	} {
+		output := &fakeStorage{objects: map[string]string{}}
+		reporter := &fakeReporter{}
+		worker := &Worker{
+			InputStorage:  input,
+			Input:         &Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "inputs"},
+			OutputStorage: output,
+			Output:        &Location{Scheme: GCSScheme, Bucket: "bucket", Prefix: "outputs/"},
+			PredictURL:    server.URL + "/v1/models/iris:predict",
+			ShardIndex:    shard,
+			ShardCount:    2,
+			Retries:       1,
+			Client:        &http.Client{},
+			Reporter:      reporter,
+			Log:           logf.Log,
+		}
+		progress, err := worker.Run(context.Background())
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(output.objects).To(gomega.Equal(expected))
+		g.Expect(progress.Files).To(gomega.Equal(len(expected)))
+		g.Expect(reporter.reports[len(reporter.reports)-1]).To(gomega.Equal(*progress))
+		if shard == 0 {
+			g.Expect(progress.ProcessedRecords).To(gomega.Equal(int64(4)))
+			g.Expect(progress.FailedRecords).To(gomega.Equal(int64(1)))
+			// The progress is reported before the first file, after each record and after each file
+			g.Expect(reporter.reports).To(gomega.HaveLen(7))
+		}
+	}
+	// The failed request is retried once
+	g.Expect(predictor.requests).To(gomega.Equal(6))
+
+	// The records are failed once the retries are exhausted
+	predictor.failures = 2
+	output := &fakeStorage{objects: map[string]string{}}
+	worker := &Worker{
+		InputStorage:  input,
+		Input:         &Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "inputs/c/"},
+		OutputStorage: output,
+		Output:        &Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "outputs"},
+		PredictURL:    server.URL,
+		ShardCount:    1,
+		Retries:       1,
+		Client:        &http.Client{},
+		Log:           logf.Log,
+	}
+	progress, err := worker.Run(context.Background())
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(progress).To(gomega.Equal(&Progress{Files: 1, ProcessedRecords: 2, FailedRecords: 1}))
+	g.Expect(output.objects).To(gomega.Equal(map[string]string{
+		"outputs/d.jsonl": "{\"error\":\"predict request failed with status 503: \"}\n{\"predictions\":[5]}\n",
+	}))
+}
+
+// failingStorage fails the uploads after reading the first bytes of the body
+type failingStorage struct {
+	fakeStorage
+}
+
+func (s *failingStorage) Write(_ context.Context, _ string, _ string, body io.Reader) error {
+	body.Read(make([]byte, 1))
+	return errors.New("upload failed")
+}
+
+func TestWorkerUploadFailed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	predictor := &fakePredictor{}
+	server := httptest.NewServer(predictor)
+	defer server.Close()
+	worker := &Worker{
+		InputStorage: &fakeStorage{objects: map[string]string{
+			"inputs/a.jsonl": "{\"instances\":[1]}\n{\"instances\":[2]}\n{\"instances\":[3]}\n",
+		}},
+		Input:         &Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "inputs/"},
+		OutputStorage: &failingStorage{},
+		Output:        &Location{Scheme: S3Scheme, Bucket: "bucket", Prefix: "outputs/"},
+		PredictURL:    server.URL,
+		ShardCount:    1,
+		Client:        &http.Client{},
+		Log:           logf.Log,
+	}
+	_, err := worker.Run(context.Background())
+	g.Expect(err).To(gomega.MatchError("fails to process input file inputs/a.jsonl: upload failed"))
+	// The records are no longer sent once the upload failed
+	g.Expect(predictor.requests).To(gomega.Equal(1))
+}
+
+func TestConfigMapReporter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	clientset := kubefake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris-batch-progress", Namespace: "default"},
+		Data:       map[string]string{ProgressKey(0): `{"files":1,"processedRecords":10,"failedRecords":0}`},
+	})
+	reporter := &ConfigMapReporter{Client: clientset, Namespace: "default", Name: "iris-batch-progress", Shard: 1}
+	g.Expect(reporter.Report(&Progress{Files: 2, ProcessedRecords: 5, FailedRecords: 1})).To(gomega.Succeed())
+
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get("iris-batch-progress", metav1.GetOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(configMap.Data).To(gomega.HaveLen(2))
+	progress := &Progress{}
+	g.Expect(json.Unmarshal([]byte(configMap.Data[ProgressKey(1)]), progress)).To(gomega.Succeed())
+	g.Expect(progress).To(gomega.Equal(&Progress{Files: 2, ProcessedRecords: 5, FailedRecords: 1}))
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+// DescriptionAnnotationKey sets the description of the InferenceService listed in the catalog
+var DescriptionAnnotationKey = constants.KFServingAPIGroupName + "/description"
+
+// Entry describes a Ready InferenceService of the catalog
+type Entry struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// URL of the InferenceService, the example requests are relative to it
+	URL         string            `json:"url,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// Framework is the predictor implementation, e.g. sklearn or tensorflow, containers for a custom predictor
+	Framework   string                             `json:"framework"`
+	Protocol    constants.InferenceServiceProtocol `json:"protocol"`
+	Transformer bool                               `json:"transformer"`
+	Explainer   bool                               `json:"explainer"`
+	// Metadata is the signature of the model read from the model metadata endpoint of the predictor
+	Metadata *v1beta1.ModelMetadata `json:"metadata,omitempty"`
+	// Models are the TrainedModels served by the InferenceService
+	Models   []Model   `json:"models,omitempty"`
+	Examples []Example `json:"examples"`
+}
+
+// Model describes a TrainedModel served by an InferenceService of the catalog
+type Model struct {
+	Name      string `json:"name"`
+	Framework string `json:"framework"`
+	URL       string `json:"url,omitempty"`
+	// Metadata is the signature of the model read by the agent
+	Metadata *v1beta1.ModelMetadata `json:"metadata,omitempty"`
+	Examples []Example              `json:"examples"`
+}
+
+// Example is an example request of an endpoint of the model
+type Example struct {
+	Endpoint string          `json:"endpoint"`
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// Build returns the catalog entries of the Ready InferenceServices with their TrainedModels, sorted by namespace and
+// name.
+func Build(isvcs []v1beta1.InferenceService, trainedModels []v1beta1.TrainedModel) []Entry {
+	models := map[string][]Model{}
+	for i := range trainedModels {
+		tm := &trainedModels[i]
+		key := tm.Namespace + "/" + tm.Spec.InferenceService
+		models[key] = append(models[key], newModel(tm))
+	}
+
+	entries := []Entry{}
+	for i := range isvcs {
+		isvc := &isvcs[i]
+		if !isvc.Status.IsReady() {
+			continue
+		}
+		entry := Entry{
+			Name:        isvc.Name,
+			Namespace:   isvc.Namespace,
+			Description: isvc.Annotations[DescriptionAnnotationKey],
+			Labels:      isvc.Labels,
+			Framework:   v1beta1.ImplementationName(&isvc.Spec.Predictor),
+			Protocol:    isvc.Spec.Predictor.GetProtocol(),
+			Transformer: isvc.Spec.Transformer != nil,
+			Explainer:   isvc.Spec.Explainer != nil,
+			Models:      models[isvc.Namespace+"/"+isvc.Name],
+		}
+		if isvc.Status.URL != nil {
+			entry.URL = isvc.Status.URL.String()
+		}
+		if isvc.Status.ModelMetadata != nil {
+			entry.Metadata = &isvc.Status.ModelMetadata.ModelMetadata
+		}
+		// The models of a multi model InferenceService are served under their own name
+		if len(entry.Models) == 0 {
+			entry.Examples = examples(isvc.Name, entry.Protocol, entry.Explainer, entry.Metadata)
+		} else {
+			entry.Examples = []Example{}
+		}
+		for j := range entry.Models {
+			entry.Models[j].Examples = examples(entry.Models[j].Name, entry.Protocol, false, entry.Models[j].Metadata)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+func newModel(tm *v1beta1.TrainedModel) Model {
+	model := Model{
+		Name:      tm.Name,
+		Framework: tm.Spec.Model.Framework,
+		Metadata:  tm.Status.Metadata,
+	}
+	if tm.Status.Address != nil && tm.Status.Address.URL != nil {
+		model.URL = tm.Status.Address.URL.String()
+	}
+	return model
+}
+
+// examples returns the example requests of the endpoints of the model, the v2 inputs are filled with zeros when the
+// metadata of the model is known.
+func examples(name string, protocol constants.InferenceServiceProtocol, explainer bool, metadata *v1beta1.ModelMetadata) []Example {
+	var body interface{}
+	var predictPath, explainPath string
+	if protocol == constants.ProtocolV2 {
+		predictPath = fmt.Sprintf("/v2/models/%s/infer", name)
+		explainPath = fmt.Sprintf("/v2/models/%s/explain", name)
+		body = map[string]interface{}{"inputs": exampleInputs(metadata)}
+	} else {
+		predictPath = fmt.Sprintf("/v1/models/%s:predict", name)
+		explainPath = fmt.Sprintf("/v1/models/%s:explain", name)
+		body = map[string]interface{}{"instances": []interface{}{}}
+	}
+	// The bodies only contain maps and slices, the marshalling can not fail
+	data, _ := json.Marshal(body)
+	result := []Example{{Endpoint: "predict", Method: "POST", Path: predictPath, Body: data}}
+	if explainer {
+		result = append(result, Example{Endpoint: "explain", Method: "POST", Path: explainPath, Body: data})
+	}
+	return result
+}
+
+// exampleInputs returns an input of zeros per input tensor of the model, the dimensions of variable size are set to 1
+func exampleInputs(metadata *v1beta1.ModelMetadata) []interface{} {
+	inputs := []interface{}{}
+	if metadata == nil {
+		return inputs
+	}
+	for _, tensor := range metadata.Inputs {
+		shape := make([]int64, len(tensor.Shape))
+		size := int64(1)
+		for i, dim := range tensor.Shape {
+			if dim < 0 {
+				dim = 1
+			}
+			shape[i] = dim
+			size *= dim
+		}
+		var data interface{}
+		switch tensor.Datatype {
+		case "BYTES":
+			data = make([]string, size)
+		case "BOOL":
+			data = make([]bool, size)
+		default:
+			data = make([]int, size)
+		}
+		inputs = append(inputs, map[string]interface{}{
+			"name":     tensor.Name,
+			"shape":    shape,
+			"datatype": tensor.Datatype,
+			"data":     data,
+		})
+	}
+	return inputs
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeInferenceService(namespace string, name string, ready bool) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://testbucket/testmodel"),
+					},
+				},
+			},
+		},
+	}
+	isvc.Status.URL = &apis.URL{Scheme: "http", Host: fmt.Sprintf("%s.%s.example.com", name, namespace)}
+	for _, condition := range []apis.ConditionType{v1beta1.PredictorReady, v1beta1.IngressReady, apis.ConditionReady} {
+		status := v1.ConditionTrue
+		if !ready {
+			status = v1.ConditionFalse
+		}
+		isvc.Status.Conditions = append(isvc.Status.Conditions, apis.Condition{Type: condition, Status: status})
+	}
+	return isvc
+}
+
+func TestBuild(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	v2 := constants.ProtocolV2
+	triton := makeInferenceService("models", "triton", true)
+	triton.Spec.Predictor.SKLearn = nil
+	triton.Spec.Predictor.Triton = &v1beta1.TritonSpec{
+		PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+			StorageURI:      proto.String("gs://testbucket/testmodel"),
+			ProtocolVersion: &v2,
+		},
+	}
+	sklearn := makeInferenceService("default", "sklearn", true)
+	sklearn.Annotations = map[string]string{DescriptionAnnotationKey: "Iris classifier"}
+	sklearn.Spec.Explainer = &v1beta1.ExplainerSpec{
+		Alibi: &v1beta1.AlibiExplainerSpec{StorageURI: "gs://testbucket/explainer"},
+	}
+	notReady := makeInferenceService("default", "not-ready", false)
+	irisV2 := makeInferenceService("models", "iris-v2", true)
+	irisV2.Spec.Predictor.SKLearn.ProtocolVersion = &v2
+	irisV2.Status.ModelMetadata = &v1beta1.ModelMetadataStatus{
+		ModelMetadata: v1beta1.ModelMetadata{
+			Platform: "sklearn",
+			Inputs:   []v1beta1.TensorMetadata{{Name: "input-0", Datatype: "FP32", Shape: []int64{-1, 4}}},
+		},
+		Revision: "iris-v2-predictor-default-00001",
+	}
+	trainedModel := v1beta1.TrainedModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "models"},
+		Spec: v1beta1.TrainedModelSpec{
+			InferenceService: "triton",
+			Model:            v1beta1.ModelSpec{Framework: "tensorflow"},
+		},
+		Status: v1beta1.TrainedModelStatus{
+			Metadata: &v1beta1.ModelMetadata{
+				Platform: "tensorflow_savedmodel",
+				Inputs:   []v1beta1.TensorMetadata{{Name: "image", Datatype: "FP32", Shape: []int64{-1, 2}}},
+			},
+		},
+	}
+
+	entries := Build([]v1beta1.InferenceService{*triton, *notReady, *sklearn, *irisV2}, []v1beta1.TrainedModel{trainedModel})
+	g.Expect(entries).To(gomega.HaveLen(3))
+
+	g.Expect(entries[0].Name).To(gomega.Equal("sklearn"))
+	g.Expect(entries[0].URL).To(gomega.Equal("http://sklearn.default.example.com"))
+	g.Expect(entries[0].Description).To(gomega.Equal("Iris classifier"))
+	g.Expect(entries[0].Framework).To(gomega.Equal("sklearn"))
+	g.Expect(entries[0].Protocol).To(gomega.Equal(constants.ProtocolV1))
+	g.Expect(entries[0].Explainer).To(gomega.BeTrue())
+	g.Expect(entries[0].Examples).To(gomega.Equal([]Example{
+		{Endpoint: "predict", Method: "POST", Path: "/v1/models/sklearn:predict", Body: json.RawMessage(`{"instances":[]}`)},
+		{Endpoint: "explain", Method: "POST", Path: "/v1/models/sklearn:explain", Body: json.RawMessage(`{"instances":[]}`)},
+	}))
+
+	// The example inputs of a single model inference service follow the metadata read from its predictor
+	g.Expect(entries[1].Name).To(gomega.Equal("iris-v2"))
+	g.Expect(entries[1].Metadata.Platform).To(gomega.Equal("sklearn"))
+	g.Expect(entries[1].Examples).To(gomega.Equal([]Example{
+		{Endpoint: "predict", Method: "POST", Path: "/v2/models/iris-v2/infer",
+			Body: json.RawMessage(`{"inputs":[{"data":[0,0,0,0],"datatype":"FP32","name":"input-0","shape":[1,4]}]}`)},
+	}))
+
+	g.Expect(entries[2].Name).To(gomega.Equal("triton"))
+	g.Expect(entries[2].Framework).To(gomega.Equal("triton"))
+	g.Expect(entries[2].Protocol).To(gomega.Equal(constants.ProtocolV2))
+	g.Expect(entries[2].Examples).To(gomega.BeEmpty())
+	g.Expect(entries[2].Models).To(gomega.HaveLen(1))
+	g.Expect(entries[2].Models[0].Name).To(gomega.Equal("resnet"))
+	g.Expect(entries[2].Models[0].Examples).To(gomega.Equal([]Example{
+		{Endpoint: "predict", Method: "POST", Path: "/v2/models/resnet/infer",
+			Body: json.RawMessage(`{"inputs":[{"data":[0,0],"datatype":"FP32","name":"image","shape":[1,2]}]}`)},
+	}))
+}
+
+type fakeAuthorizer struct {
+	namespaces map[string][]string
+}
+
+func (a *fakeAuthorizer) Authenticate(token string) (*authenticationv1.UserInfo, error) {
+	if _, ok := a.namespaces[token]; !ok {
+		return nil, fmt.Errorf("unknown token")
+	}
+	return &authenticationv1.UserInfo{Username: token}, nil
+}
+
+func (a *fakeAuthorizer) CanGetInferenceServices(user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	for _, allowed := range a.namespaces[user.Username] {
+		if allowed == namespace {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestHandler(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	handler := &Handler{
+		Client: fake.NewFakeClientWithScheme(scheme,
+			makeInferenceService("team-a", "model-a", true),
+			makeInferenceService("team-b", "model-b", true)),
+		Authorizer: &fakeAuthorizer{namespaces: map[string][]string{
+			"alice": {"team-a"},
+			"admin": {"team-a", "team-b"},
+		}},
+	}
+
+	scenarios := map[string]struct {
+		token               string
+		query               string
+		plaintext           bool
+		remoteAddr          string
+		forwardedClientCert string
+		code                int
+		expected            []string
+	}{
+		"Plaintext": {
+			token:     "admin",
+			plaintext: true,
+			code:      http.StatusForbidden,
+		},
+		"MeshMutualTLS": {
+			token:               "alice",
+			plaintext:           true,
+			remoteAddr:          "127.0.0.1:40312",
+			forwardedClientCert: "By=spiffe://cluster.local/ns/kfserving-system/sa/default",
+			code:                http.StatusOK,
+			expected:            []string{"team-a/model-a"},
+		},
+		"ForgedMeshMutualTLS": {
+			token:               "admin",
+			plaintext:           true,
+			remoteAddr:          "10.0.12.7:40312",
+			forwardedClientCert: "By=spiffe://cluster.local/ns/kfserving-system/sa/default",
+			code:                http.StatusForbidden,
+		},
+		"LoopbackWithoutMeshMutualTLS": {
+			token:      "admin",
+			plaintext:  true,
+			remoteAddr: "127.0.0.1:40312",
+			code:       http.StatusForbidden,
+		},
+		"NoToken": {
+			code: http.StatusUnauthorized,
+		},
+		"UnknownToken": {
+			token: "mallory",
+			code:  http.StatusUnauthorized,
+		},
+		"FilteredByNamespaceAccess": {
+			token:    "alice",
+			code:     http.StatusOK,
+			expected: []string{"team-a/model-a"},
+		},
+		"AllNamespaces": {
+			token:    "admin",
+			code:     http.StatusOK,
+			expected: []string{"team-a/model-a", "team-b/model-b"},
+		},
+		"NamespaceQuery": {
+			token:    "admin",
+			query:    "?namespace=team-b",
+			code:     http.StatusOK,
+			expected: []string{"team-b/model-b"},
+		},
+	}
+	for name, scenario := range scenarios {
+		urlScheme := "https"
+		if scenario.plaintext {
+			urlScheme = "http"
+		}
+		request := httptest.NewRequest(http.MethodGet, urlScheme+"://kfserving-catalog"+Path+scenario.query, nil)
+		if scenario.remoteAddr != "" {
+			request.RemoteAddr = scenario.remoteAddr
+		}
+		if scenario.forwardedClientCert != "" {
+			request.Header.Set(ForwardedClientCertHeader, scenario.forwardedClientCert)
+		}
+		if scenario.token != "" {
+			request.Header.Set("Authorization", "Bearer "+scenario.token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		g.Expect(recorder.Code).To(gomega.Equal(scenario.code), fmt.Sprintf("Testing %s", name))
+		if scenario.code != http.StatusOK {
+			continue
+		}
+		response := struct {
+			InferenceServices []Entry `json:"inferenceServices"`
+		}{}
+		g.Expect(json.Unmarshal(recorder.Body.Bytes(), &response)).To(gomega.Succeed(), fmt.Sprintf("Testing %s", name))
+		names := []string{}
+		for _, entry := range response.InferenceServices {
+			names = append(names, entry.Namespace+"/"+entry.Name)
+		}
+		g.Expect(names).To(gomega.Equal(scenario.expected), fmt.Sprintf("Testing %s", name))
+	}
+}
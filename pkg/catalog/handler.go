@@ -0,0 +1,209 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// Path of the catalog endpoint
+const Path = "/v1/catalog"
+
+const bearerPrefix = "Bearer "
+
+// ForwardedClientCertHeader is set by the Istio sidecar on the requests it received over the mutual TLS of the mesh
+const ForwardedClientCertHeader = "X-Forwarded-Client-Cert"
+
+// PlaintextHost is the host the catalog binds to when served over plain HTTP, only the Istio sidecar of the pod
+// reaches it
+const PlaintextHost = "127.0.0.1"
+
+var log = logf.Log.WithName("catalog")
+
+// Authorizer authenticates the callers of the catalog and checks their access to the InferenceServices of a namespace
+type Authorizer interface {
+	Authenticate(token string) (*authenticationv1.UserInfo, error)
+	CanGetInferenceServices(user *authenticationv1.UserInfo, namespace string) (bool, error)
+}
+
+// Handler serves the catalog on GET /v1/catalog, the entries are filtered to the namespaces in which the caller
+// identified by its bearer token is allowed to get InferenceServices. The namespace query parameter restricts the
+// catalog to a namespace. The bearer tokens are only accepted over TLS, or over the mutual TLS of the mesh when the
+// catalog is served over plain HTTP behind an Istio sidecar. The client certificate header alone can be sent by any
+// caller, the plain HTTP requests are only accepted from the loopback address the sidecar forwards them from.
+type Handler struct {
+	Client     client.Reader
+	Authorizer Authorizer
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.TLS == nil && !fromSidecar(r) {
+		http.Error(w, "the catalog is only served over TLS", http.StatusForbidden)
+		return
+	}
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	user, err := h.Authorizer.Authenticate(strings.TrimPrefix(authorization, bearerPrefix))
+	if err != nil {
+		log.Error(err, "Failed to authenticate the catalog request")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var listOptions []client.ListOption
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		listOptions = append(listOptions, client.InNamespace(namespace))
+	}
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := h.Client.List(context.TODO(), isvcs, listOptions...); err != nil {
+		log.Error(err, "Failed to list the inference services of the catalog")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	trainedModels := &v1beta1.TrainedModelList{}
+	if err := h.Client.List(context.TODO(), trainedModels, listOptions...); err != nil {
+		log.Error(err, "Failed to list the trained models of the catalog")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	allowed, err := h.allowedNamespaces(user, isvcs.Items)
+	if err != nil {
+		log.Error(err, "Failed to authorize the catalog request", "user", user.Username)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var visibleISVCs []v1beta1.InferenceService
+	for _, isvc := range isvcs.Items {
+		if allowed[isvc.Namespace] {
+			visibleISVCs = append(visibleISVCs, isvc)
+		}
+	}
+	var visibleModels []v1beta1.TrainedModel
+	for _, tm := range trainedModels.Items {
+		if allowed[tm.Namespace] {
+			visibleModels = append(visibleModels, tm)
+		}
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"inferenceServices": Build(visibleISVCs, visibleModels),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// allowedNamespaces checks the access of the user once per namespace of the InferenceServices
+func (h *Handler) allowedNamespaces(user *authenticationv1.UserInfo, isvcs []v1beta1.InferenceService) (map[string]bool, error) {
+	allowed := map[string]bool{}
+	for _, isvc := range isvcs {
+		if _, ok := allowed[isvc.Namespace]; ok {
+			continue
+		}
+		ok, err := h.Authorizer.CanGetInferenceServices(user, isvc.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		allowed[isvc.Namespace] = ok
+	}
+	return allowed, nil
+}
+
+// fromSidecar tells whether a plain HTTP request was forwarded by the Istio sidecar of the pod after the mutual TLS of
+// the mesh, the sidecar connects from the loopback address and sets the client certificate header
+func fromSidecar(r *http.Request) bool {
+	if r.Header.Get(ForwardedClientCertHeader) == "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ReviewAuthorizer authenticates the tokens with TokenReviews and authorizes the users with SubjectAccessReviews, the
+// service account of the manager needs the permission to create both.
+type ReviewAuthorizer struct {
+	Client kubernetes.Interface
+}
+
+func (a *ReviewAuthorizer) Authenticate(token string) (*authenticationv1.UserInfo, error) {
+	review, err := a.Client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("token is not authenticated: %s", review.Status.Error)
+	}
+	return &review.Status.User, nil
+}
+
+func (a *ReviewAuthorizer) CanGetInferenceServices(user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := a.Client.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Group:     constants.KFServingAPIGroupName,
+				Resource:  "inferenceservices",
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
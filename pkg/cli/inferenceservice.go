@@ -0,0 +1,226 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	servingv1beta1 "github.com/kubeflow/kfserving/pkg/client/clientset/versioned/typed/serving/v1beta1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Frameworks of the predictors deployed from a model URI
+var Frameworks = []string{"sklearn", "xgboost", "lightgbm", "tensorflow", "pytorch", "triton", "onnx", "pmml", "paddle"}
+
+// Client runs the operations of the CLI on the InferenceServices of a namespace
+type Client struct {
+	Serving   servingv1beta1.ServingV1beta1Interface
+	Kube      kubernetes.Interface
+	HTTP      *http.Client
+	Namespace string
+}
+
+// DeployOptions of the predictor of an InferenceService deployed from a model URI
+type DeployOptions struct {
+	Name           string
+	Framework      string
+	ModelURI       string
+	RuntimeVersion string
+	MinReplicas    *int
+	// CanaryTrafficPercent of the revision rolled out when the InferenceService exists, all the traffic goes to it
+	// when nil
+	CanaryTrafficPercent *int64
+}
+
+// Deploy creates the InferenceService serving the model, or rolls out the model to the predictor of the existing
+// InferenceService. The predictor keeps its other settings when the framework is unchanged.
+func (c *Client) Deploy(opts DeployOptions) (*v1beta1.InferenceService, error) {
+	isvc, err := c.Serving.InferenceServices(c.Namespace).Get(opts.Name, metav1.GetOptions{})
+	if apierr.IsNotFound(err) {
+		isvc = &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      opts.Name,
+				Namespace: c.Namespace,
+			},
+		}
+		if err := setModel(&isvc.Spec.Predictor, opts); err != nil {
+			return nil, err
+		}
+		return c.Serving.InferenceServices(c.Namespace).Create(isvc)
+	} else if err != nil {
+		return nil, err
+	}
+	return c.update(opts.Name, func(isvc *v1beta1.InferenceService) error {
+		if err := setModel(&isvc.Spec.Predictor, opts); err != nil {
+			return err
+		}
+		isvc.Spec.Predictor.CanaryTrafficPercent = opts.CanaryTrafficPercent
+		isvc.Spec.Predictor.RollbackTo = nil
+		return nil
+	})
+}
+
+// Promote routes all the traffic of the components to their latest revision, ending their canary rollout or their
+// rollback. All the components are promoted when the component is empty.
+func (c *Client) Promote(name string, component v1beta1.ComponentType) (*v1beta1.InferenceService, error) {
+	return c.update(name, func(isvc *v1beta1.InferenceService) error {
+		extensions, err := componentExtensions(isvc, component)
+		if err != nil {
+			return err
+		}
+		for _, extension := range extensions {
+			extension.CanaryTrafficPercent = nil
+			extension.RollbackTo = nil
+		}
+		return nil
+	})
+}
+
+// Rollback pins all the traffic of the components to their previous ready revision, ending their canary rollout. All
+// the components are rolled back when the component is empty.
+func (c *Client) Rollback(name string, component v1beta1.ComponentType) (*v1beta1.InferenceService, error) {
+	return c.update(name, func(isvc *v1beta1.InferenceService) error {
+		extensions, err := componentExtensions(isvc, component)
+		if err != nil {
+			return err
+		}
+		for component, extension := range extensions {
+			previous := isvc.Status.Components[component].PreviousReadyRevision
+			if previous == "" {
+				return fmt.Errorf("the %s of InferenceService %s has no previous ready revision", component, name)
+			}
+			extension.CanaryTrafficPercent = nil
+			extension.RollbackTo = &previous
+		}
+		return nil
+	})
+}
+
+// update applies the change to the InferenceService, getting it again on conflicts
+func (c *Client) update(name string, change func(isvc *v1beta1.InferenceService) error) (*v1beta1.InferenceService, error) {
+	var updated *v1beta1.InferenceService
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		isvc, err := c.Serving.InferenceServices(c.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err := change(isvc); err != nil {
+			return err
+		}
+		updated, err = c.Serving.InferenceServices(c.Namespace).Update(isvc)
+		return err
+	})
+	return updated, err
+}
+
+// componentExtensions returns the extension specs of the component, or of all the components of the InferenceService
+// when the component is empty
+func componentExtensions(isvc *v1beta1.InferenceService, component v1beta1.ComponentType) (map[v1beta1.ComponentType]*v1beta1.ComponentExtensionSpec, error) {
+	extensions := map[v1beta1.ComponentType]*v1beta1.ComponentExtensionSpec{
+		v1beta1.PredictorComponent: isvc.Spec.Predictor.GetExtensions(),
+	}
+	if isvc.Spec.Transformer != nil {
+		extensions[v1beta1.TransformerComponent] = isvc.Spec.Transformer.GetExtensions()
+	}
+	if isvc.Spec.Explainer != nil {
+		extensions[v1beta1.ExplainerComponent] = isvc.Spec.Explainer.GetExtensions()
+	}
+	if component == "" {
+		return extensions, nil
+	}
+	extension, ok := extensions[component]
+	if !ok {
+		return nil, fmt.Errorf("InferenceService %s has no %s", isvc.Name, component)
+	}
+	return map[v1beta1.ComponentType]*v1beta1.ComponentExtensionSpec{component: extension}, nil
+}
+
+// setModel sets the model URI and the runtime version of the framework of the predictor, unsetting the other
+// frameworks and the custom containers
+func setModel(predictor *v1beta1.PredictorSpec, opts DeployOptions) error {
+	existing := predictor.DeepCopy()
+	predictor.SKLearn, predictor.XGBoost, predictor.LightGBM = nil, nil, nil
+	predictor.Tensorflow, predictor.PyTorch, predictor.Triton = nil, nil, nil
+	predictor.ONNX, predictor.PMML, predictor.Paddle = nil, nil, nil
+	predictor.MLflow, predictor.Model = nil, nil
+	predictor.Containers = nil
+
+	var extension *v1beta1.PredictorExtensionSpec
+	switch opts.Framework {
+	case "sklearn":
+		if predictor.SKLearn = existing.SKLearn; predictor.SKLearn == nil {
+			predictor.SKLearn = &v1beta1.SKLearnSpec{}
+		}
+		extension = &predictor.SKLearn.PredictorExtensionSpec
+	case "xgboost":
+		if predictor.XGBoost = existing.XGBoost; predictor.XGBoost == nil {
+			predictor.XGBoost = &v1beta1.XGBoostSpec{}
+		}
+		extension = &predictor.XGBoost.PredictorExtensionSpec
+	case "lightgbm":
+		if predictor.LightGBM = existing.LightGBM; predictor.LightGBM == nil {
+			predictor.LightGBM = &v1beta1.LightGBMSpec{}
+		}
+		extension = &predictor.LightGBM.PredictorExtensionSpec
+	case "tensorflow":
+		if predictor.Tensorflow = existing.Tensorflow; predictor.Tensorflow == nil {
+			predictor.Tensorflow = &v1beta1.TFServingSpec{}
+		}
+		extension = &predictor.Tensorflow.PredictorExtensionSpec
+	case "pytorch":
+		if predictor.PyTorch = existing.PyTorch; predictor.PyTorch == nil {
+			predictor.PyTorch = &v1beta1.TorchServeSpec{}
+		}
+		extension = &predictor.PyTorch.PredictorExtensionSpec
+	case "triton":
+		if predictor.Triton = existing.Triton; predictor.Triton == nil {
+			predictor.Triton = &v1beta1.TritonSpec{}
+		}
+		extension = &predictor.Triton.PredictorExtensionSpec
+	case "onnx":
+		if predictor.ONNX = existing.ONNX; predictor.ONNX == nil {
+			predictor.ONNX = &v1beta1.ONNXRuntimeSpec{}
+		}
+		extension = &predictor.ONNX.PredictorExtensionSpec
+	case "pmml":
+		if predictor.PMML = existing.PMML; predictor.PMML == nil {
+			predictor.PMML = &v1beta1.PMMLSpec{}
+		}
+		extension = &predictor.PMML.PredictorExtensionSpec
+	case "paddle":
+		if predictor.Paddle = existing.Paddle; predictor.Paddle == nil {
+			predictor.Paddle = &v1beta1.PaddleSpec{}
+		}
+		extension = &predictor.Paddle.PredictorExtensionSpec
+	default:
+		return fmt.Errorf("unknown framework %q, must be one of: [%s]", opts.Framework, strings.Join(Frameworks, ", "))
+	}
+	extension.StorageURI = &opts.ModelURI
+	if opts.RuntimeVersion != "" {
+		extension.RuntimeVersion = &opts.RuntimeVersion
+	}
+	if opts.MinReplicas != nil {
+		predictor.MinReplicas = opts.MinReplicas
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned/fake"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newClient(objects ...runtime.Object) *Client {
+	return &Client{
+		Serving:   fake.NewSimpleClientset(objects...).ServingV1beta1(),
+		Namespace: "default",
+	}
+}
+
+func newInferenceService() *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://kfserving-samples/models/sklearn/iris"),
+						Container: v1.Container{
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+							},
+						},
+					},
+				},
+			},
+			Transformer: &v1beta1.TransformerSpec{
+				PodSpec: v1beta1.PodSpec{Containers: []v1.Container{{Image: "transformer:v1"}}},
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					CanaryTrafficPercent: proto.Int64(10),
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision:   "sklearn-iris-predictor-default-00002",
+					PreviousReadyRevision: "sklearn-iris-predictor-default-00001",
+					TrafficPercent:        proto.Int64(20),
+				},
+				v1beta1.TransformerComponent: {
+					LatestReadyRevision: "sklearn-iris-transformer-default-00001",
+				},
+			},
+		},
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// Creates the InferenceService
+	c := newClient()
+	isvc, err := c.Deploy(DeployOptions{
+		Name:                 "sklearn-iris",
+		Framework:            "sklearn",
+		ModelURI:             "gs://kfserving-samples/models/sklearn/iris",
+		MinReplicas:          v1beta1.GetIntReference(0),
+		CanaryTrafficPercent: proto.Int64(10),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(proto.String("gs://kfserving-samples/models/sklearn/iris")))
+	g.Expect(isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(v1beta1.GetIntReference(0)))
+	g.Expect(isvc.Spec.Predictor.CanaryTrafficPercent).To(gomega.BeNil())
+
+	// Rolls out the model as a canary, keeping the settings of the predictor
+	c = newClient(newInferenceService())
+	isvc, err = c.Deploy(DeployOptions{
+		Name:                 "sklearn-iris",
+		Framework:            "sklearn",
+		ModelURI:             "gs://kfserving-samples/models/sklearn/iris-v2",
+		RuntimeVersion:       "0.5.0",
+		CanaryTrafficPercent: proto.Int64(10),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isvc.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(proto.String("gs://kfserving-samples/models/sklearn/iris-v2")))
+	g.Expect(isvc.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.Equal(proto.String("0.5.0")))
+	g.Expect(isvc.Spec.Predictor.SKLearn.Resources.Limits.Cpu().String()).To(gomega.Equal("2"))
+	g.Expect(isvc.Spec.Predictor.CanaryTrafficPercent).To(gomega.Equal(proto.Int64(10)))
+
+	// Switches the framework of the predictor
+	isvc, err = c.Deploy(DeployOptions{
+		Name:      "sklearn-iris",
+		Framework: "xgboost",
+		ModelURI:  "gs://kfserving-samples/models/xgboost/iris",
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isvc.Spec.Predictor.SKLearn).To(gomega.BeNil())
+	g.Expect(isvc.Spec.Predictor.XGBoost.StorageURI).To(gomega.Equal(proto.String("gs://kfserving-samples/models/xgboost/iris")))
+	g.Expect(isvc.Spec.Predictor.CanaryTrafficPercent).To(gomega.BeNil())
+
+	_, err = c.Deploy(DeployOptions{Name: "sklearn-iris", Framework: "caffe", ModelURI: "gs://models/caffe"})
+	g.Expect(err).To(gomega.MatchError(`unknown framework "caffe", must be one of: [sklearn, xgboost, lightgbm, tensorflow, pytorch, triton, onnx, pmml, paddle]`))
+}
+
+func TestPromote(t *testing.T) {
+	scenarios := map[string]struct {
+		component   v1beta1.ComponentType
+		predictor   *int64
+		transformer *int64
+		err         string
+	}{
+		"All": {
+			component: "",
+		},
+		"Transformer": {
+			component: v1beta1.TransformerComponent,
+			predictor: proto.Int64(20),
+		},
+		"MissingExplainer": {
+			component: v1beta1.ExplainerComponent,
+			err:       "InferenceService sklearn-iris has no explainer",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := newInferenceService()
+			isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(20)
+			c := newClient(isvc)
+
+			updated, err := c.Promote("sklearn-iris", scenario.component)
+			if scenario.err != "" {
+				g.Expect(err).To(gomega.MatchError(scenario.err))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(updated.Spec.Predictor.CanaryTrafficPercent).To(gomega.Equal(scenario.predictor))
+			g.Expect(updated.Spec.Transformer.CanaryTrafficPercent).To(gomega.Equal(scenario.transformer))
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(20)
+	c := newClient(isvc)
+
+	updated, err := c.Rollback("sklearn-iris", v1beta1.PredictorComponent)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Spec.Predictor.CanaryTrafficPercent).To(gomega.BeNil())
+	g.Expect(updated.Spec.Predictor.RollbackTo).To(gomega.Equal(proto.String("sklearn-iris-predictor-default-00001")))
+
+	// The transformer has a single ready revision
+	_, err = c.Rollback("sklearn-iris", "")
+	g.Expect(err).To(gomega.MatchError("the transformer of InferenceService sklearn-iris has no previous ready revision"))
+
+	// Promoting ends the rollback
+	updated, err = c.Promote("sklearn-iris", v1beta1.PredictorComponent)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(updated.Spec.Predictor.RollbackTo).To(gomega.BeNil())
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// LogsOptions of the logs of the pods of a component
+type LogsOptions struct {
+	Name      string
+	Component v1beta1.ComponentType
+	// Container of the pods, the model server container when empty
+	Container string
+	Follow    bool
+	TailLines *int64
+}
+
+// Logs writes the logs of the pods of the component, each line prefixed with the name of its pod. The logs of the
+// pods are streamed concurrently when followed.
+func (c *Client) Logs(out io.Writer, opts LogsOptions) error {
+	selector := labels.SelectorFromSet(labels.Set{
+		constants.InferenceServicePodLabelKey: opts.Name,
+		constants.KServiceComponentLabel:      string(opts.Component),
+	})
+	pods, err := c.Kube.CoreV1().Pods(c.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("no %s pods found for InferenceService %s", opts.Component, opts.Name)
+	}
+	container := opts.Container
+	if container == "" {
+		container = constants.InferenceServiceContainerName
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(pods.Items))
+	for i := range pods.Items {
+		wg.Add(1)
+		go func(i int, pod string) {
+			defer wg.Done()
+			stream, err := c.Kube.CoreV1().Pods(c.Namespace).GetLogs(pod, &v1.PodLogOptions{
+				Container: container,
+				Follow:    opts.Follow,
+				TailLines: opts.TailLines,
+			}).Stream()
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to get the logs of pod %s: %v", pod, err)
+				return
+			}
+			defer stream.Close()
+			scanner := bufio.NewScanner(stream)
+			for scanner.Scan() {
+				mu.Lock()
+				fmt.Fprintf(out, "[%s] %s\n", pod, scanner.Text())
+				mu.Unlock()
+			}
+			errs[i] = scanner.Err()
+		}(i, pods.Items[i].Name)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
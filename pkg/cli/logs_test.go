@@ -0,0 +1,105 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func newPod(name string, isvc string, component v1beta1.ComponentType) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				constants.InferenceServicePodLabelKey: isvc,
+				constants.KServiceComponentLabel:      string(component),
+			},
+		},
+	}
+}
+
+// newAPIServer serves the pods and their logs, the logs of a pod being its name and container
+func newAPIServer(pods ...v1.Pod) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/log") {
+			pod := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/default/pods/"), "/log")
+			fmt.Fprintf(w, "%s %s started\n%s %s ready\n", pod, r.URL.Query().Get("container"), pod, r.URL.Query().Get("container"))
+			return
+		}
+		selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		list := &v1.PodList{}
+		for _, pod := range pods {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				list.Items = append(list.Items, pod)
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+	}))
+}
+
+func TestLogs(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := newAPIServer(
+		newPod("sklearn-iris-predictor-1", "sklearn-iris", v1beta1.PredictorComponent),
+		newPod("sklearn-iris-predictor-2", "sklearn-iris", v1beta1.PredictorComponent),
+		newPod("sklearn-iris-transformer-1", "sklearn-iris", v1beta1.TransformerComponent),
+		newPod("flowers-predictor-1", "flowers", v1beta1.PredictorComponent),
+	)
+	defer server.Close()
+	kube, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	c := &Client{Kube: kube, Namespace: "default"}
+
+	out := &bytes.Buffer{}
+	g.Expect(c.Logs(out, LogsOptions{Name: "sklearn-iris", Component: v1beta1.PredictorComponent})).To(gomega.Succeed())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	g.Expect(lines).To(gomega.ConsistOf(
+		"[sklearn-iris-predictor-1] sklearn-iris-predictor-1 kfserving-container started",
+		"[sklearn-iris-predictor-1] sklearn-iris-predictor-1 kfserving-container ready",
+		"[sklearn-iris-predictor-2] sklearn-iris-predictor-2 kfserving-container started",
+		"[sklearn-iris-predictor-2] sklearn-iris-predictor-2 kfserving-container ready",
+	))
+
+	out.Reset()
+	g.Expect(c.Logs(out, LogsOptions{Name: "sklearn-iris", Component: v1beta1.TransformerComponent, Container: "queue-proxy"})).To(gomega.Succeed())
+	g.Expect(out.String()).To(gomega.Equal("[sklearn-iris-transformer-1] sklearn-iris-transformer-1 queue-proxy started\n" +
+		"[sklearn-iris-transformer-1] sklearn-iris-transformer-1 queue-proxy ready\n"))
+
+	err = c.Logs(out, LogsOptions{Name: "sklearn-iris", Component: v1beta1.ExplainerComponent})
+	g.Expect(err).To(gomega.MatchError("no explainer pods found for InferenceService sklearn-iris"))
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PredictOptions of a test prediction or explanation
+type PredictOptions struct {
+	Name string
+	// Request body, in the format of the protocol of the predictor
+	Request []byte
+	// Ingress address, e.g. http://localhost:8080 when the ingress gateway is port forwarded, the request is sent to the
+	// URL of the InferenceService when empty
+	Ingress string
+	// Token sent as a bearer token, e.g. the JWT of an InferenceService with auth
+	Token string
+	// APIKey of an InferenceService with API keys
+	APIKey string
+	// Component the request is sent to, e.g. the predictor rather than the transformer in front of it, the
+	// InferenceService when empty
+	Component v1beta1.ComponentType
+	// Explain sends the request to the explain endpoint rather than to the predict endpoint
+	Explain bool
+}
+
+// Predict sends the request to the predict endpoint of the protocol of the predictor of the InferenceService, or to
+// its explain endpoint, and returns the response. The request sent to the ingress address has the host of the
+// InferenceService, which routes it.
+func (c *Client) Predict(opts PredictOptions) ([]byte, error) {
+	isvc, err := c.Serving.InferenceServices(c.Namespace).Get(opts.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	client := &predict.Client{
+		HTTP:      c.HTTP,
+		Ingress:   opts.Ingress,
+		Component: opts.Component,
+		Token:     opts.Token,
+		APIKey:    opts.APIKey,
+	}
+	if opts.Explain {
+		return client.Explain(context.Background(), isvc, opts.Request)
+	}
+	return client.Predict(context.Background(), isvc, opts.Request)
+}
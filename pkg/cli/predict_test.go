@@ -0,0 +1,114 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"knative.dev/pkg/apis"
+)
+
+func TestPredict(t *testing.T) {
+	scenarios := map[string]struct {
+		protocol  constants.InferenceServiceProtocol
+		explain   bool
+		component v1beta1.ComponentType
+		host      string
+		status    int
+		path      string
+		err       string
+	}{
+		"V1": {
+			status: http.StatusOK,
+			path:   "/v1/models/sklearn-iris:predict",
+		},
+		"V2": {
+			protocol: constants.ProtocolV2,
+			status:   http.StatusOK,
+			path:     "/v2/models/sklearn-iris/infer",
+		},
+		"Explain": {
+			explain: true,
+			status:  http.StatusOK,
+			path:    "/v1/models/sklearn-iris:explain",
+		},
+		"Component": {
+			component: v1beta1.PredictorComponent,
+			status:    http.StatusOK,
+			path:      "/v1/models/sklearn-iris:predict",
+			host:      "sklearn-iris-predictor-default.default.example.com",
+		},
+		"Error": {
+			status: http.StatusServiceUnavailable,
+			path:   "/v1/models/sklearn-iris:predict",
+			err:    `prediction failed with status 503: {"predictions": [1, 1]}`,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			var path, host, authorization, request string
+			ingress := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, host, authorization = r.URL.Path, r.Host, r.Header.Get("Authorization")
+				body, _ := ioutil.ReadAll(r.Body)
+				request = string(body)
+				w.WriteHeader(scenario.status)
+				w.Write([]byte(`{"predictions": [1, 1]}`))
+			}))
+			defer ingress.Close()
+
+			isvc := newInferenceService()
+			isvc.Status.URL = &apis.URL{Scheme: "http", Host: "sklearn-iris.default.example.com"}
+			predictor := isvc.Status.Components[v1beta1.PredictorComponent]
+			predictor.URL = &apis.URL{Scheme: "http", Host: "sklearn-iris-predictor-default.default.example.com"}
+			isvc.Status.Components[v1beta1.PredictorComponent] = predictor
+			if scenario.protocol != "" {
+				isvc.Spec.Predictor.SKLearn.ProtocolVersion = &scenario.protocol
+			}
+			c := newClient(isvc)
+			c.HTTP = ingress.Client()
+
+			response, err := c.Predict(PredictOptions{
+				Name:      "sklearn-iris",
+				Request:   []byte(`{"instances": [[6.8, 2.8, 4.8, 1.4], [6.0, 3.4, 4.5, 1.6]]}`),
+				Ingress:   ingress.URL,
+				Token:     "jwt",
+				Explain:   scenario.explain,
+				Component: scenario.component,
+			})
+			if scenario.err != "" {
+				g.Expect(err).To(gomega.MatchError(scenario.err))
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(string(response)).To(gomega.Equal(`{"predictions": [1, 1]}`))
+			}
+			g.Expect(path).To(gomega.Equal(scenario.path))
+			if scenario.host == "" {
+				scenario.host = "sklearn-iris.default.example.com"
+			}
+			g.Expect(host).To(gomega.Equal(scenario.host))
+			g.Expect(authorization).To(gomega.Equal("Bearer jwt"))
+			g.Expect(request).To(gomega.Equal(`{"instances": [[6.8, 2.8, 4.8, 1.4], [6.0, 3.4, 4.5, 1.6]]}`))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"knative.dev/pkg/apis"
+)
+
+var readyConditions = map[v1beta1.ComponentType]apis.ConditionType{
+	v1beta1.PredictorComponent:   v1beta1.PredictorReady,
+	v1beta1.TransformerComponent: v1beta1.TransformerReady,
+	v1beta1.ExplainerComponent:   v1beta1.ExplainerReady,
+}
+
+// PrintStatus writes the status of the InferenceService with the revisions and the traffic of each component
+func PrintStatus(out io.Writer, isvc *v1beta1.InferenceService) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", isvc.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", isvc.Namespace)
+	if isvc.Status.URL != nil {
+		fmt.Fprintf(w, "URL:\t%s\n", isvc.Status.URL)
+	}
+	fmt.Fprintf(w, "Ready:\t%s\n", conditionStatus(isvc.Status.GetCondition(apis.ConditionReady)))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "COMPONENT\tREADY\tLATEST READY\tPREVIOUS READY\tTRAFFIC\tURL")
+	for _, component := range []v1beta1.ComponentType{v1beta1.PredictorComponent, v1beta1.TransformerComponent,
+		v1beta1.ExplainerComponent} {
+		status, ok := isvc.Status.Components[component]
+		if !ok {
+			continue
+		}
+		url := ""
+		if status.URL != nil {
+			url = status.URL.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", component,
+			conditionStatus(isvc.Status.GetCondition(readyConditions[component])),
+			orNone(status.LatestReadyRevision), orNone(status.PreviousReadyRevision), traffic(status), orNone(url))
+	}
+	return w.Flush()
+}
+
+// traffic describes the split of the traffic of a component between its latest and its previous ready revisions
+func traffic(status v1beta1.ComponentStatusSpec) string {
+	if status.PinnedRevision != "" {
+		return fmt.Sprintf("pinned to %s", status.PinnedRevision)
+	}
+	if status.TrafficPercent == nil || *status.TrafficPercent == 100 || status.PreviousReadyRevision == "" {
+		return "100% latest"
+	}
+	return fmt.Sprintf("%d%% latest, %d%% previous", *status.TrafficPercent, 100-*status.TrafficPercent)
+}
+
+func conditionStatus(condition *apis.Condition) string {
+	if condition == nil {
+		return "Unknown"
+	}
+	if condition.Status != "True" && condition.Message != "" {
+		return fmt.Sprintf("%s (%s)", condition.Status, condition.Message)
+	}
+	return string(condition.Status)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func TestPrintStatus(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService()
+	isvc.Status.URL = &apis.URL{Scheme: "http", Host: "sklearn-iris.default.example.com"}
+	isvc.Status.Conditions = duckv1.Conditions{
+		{Type: apis.ConditionReady, Status: "False", Message: "Transformer is not ready"},
+		{Type: v1beta1.PredictorReady, Status: "True"},
+		{Type: v1beta1.TransformerReady, Status: "False", Message: "Revision failed"},
+	}
+	predictor := isvc.Status.Components[v1beta1.PredictorComponent]
+	predictor.URL = &apis.URL{Scheme: "http", Host: "sklearn-iris-predictor-default.default.example.com"}
+	isvc.Status.Components[v1beta1.PredictorComponent] = predictor
+	transformer := isvc.Status.Components[v1beta1.TransformerComponent]
+	transformer.PinnedRevision = "sklearn-iris-transformer-default-00001"
+	isvc.Status.Components[v1beta1.TransformerComponent] = transformer
+
+	out := &bytes.Buffer{}
+	g.Expect(PrintStatus(out, isvc)).To(gomega.Succeed())
+	g.Expect(out.String()).To(gomega.Equal(`Name:       sklearn-iris
+Namespace:  default
+URL:        http://sklearn-iris.default.example.com
+Ready:      False (Transformer is not ready)
+
+COMPONENT    READY                    LATEST READY                            PREVIOUS READY                        TRAFFIC                                           URL
+predictor    True                     sklearn-iris-predictor-default-00002    sklearn-iris-predictor-default-00001  20% latest, 80% previous                          http://sklearn-iris-predictor-default.default.example.com
+transformer  False (Revision failed)  sklearn-iris-transformer-default-00001  <none>                                pinned to sklearn-iris-transformer-default-00001  <none>
+`))
+}
@@ -34,9 +34,9 @@ type FakeInferenceServices struct {
 	ns   string
 }
 
-var inferenceservicesResource = schema.GroupVersionResource{Group: "serving", Version: "v1beta1", Resource: "inferenceservices"}
+var inferenceservicesResource = schema.GroupVersionResource{Group: "serving.kubeflow.org", Version: "v1beta1", Resource: "inferenceservices"}
 
-var inferenceservicesKind = schema.GroupVersionKind{Group: "serving", Version: "v1beta1", Kind: "InferenceService"}
+var inferenceservicesKind = schema.GroupVersionKind{Group: "serving.kubeflow.org", Version: "v1beta1", Kind: "InferenceService"}
 
 // Get takes name of the inferenceService, and returns the corresponding inferenceService object, and an error if there is any.
 func (c *FakeInferenceServices) Get(name string, options v1.GetOptions) (result *v1beta1.InferenceService, err error) {
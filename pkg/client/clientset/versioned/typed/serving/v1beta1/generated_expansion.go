@@ -21,3 +21,5 @@ package v1beta1
 type InferenceRouterExpansion interface{}
 
 type InferenceServiceExpansion interface{}
+
+type TrainedModelExpansion interface{}
@@ -27,6 +27,7 @@ import (
 type ServingV1beta1Interface interface {
 	RESTClient() rest.Interface
 	InferenceServicesGetter
+	TrainedModelsGetter
 }
 
 // ServingV1beta1Client is used to interact with features provided by the serving group.
@@ -38,6 +39,10 @@ func (c *ServingV1beta1Client) InferenceServices(namespace string) InferenceServ
 	return newInferenceServices(c, namespace)
 }
 
+func (c *ServingV1beta1Client) TrainedModels(namespace string) TrainedModelInterface {
+	return newTrainedModels(c, namespace)
+}
+
 // NewForConfig creates a new ServingV1beta1Client for the given config.
 func NewForConfig(c *rest.Config) (*ServingV1beta1Client, error) {
 	config := *c
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// InferenceServices returns a InferenceServiceInformer.
 	InferenceServices() InferenceServiceInformer
+	// TrainedModels returns a TrainedModelInformer.
+	TrainedModels() TrainedModelInformer
 }
 
 type version struct {
@@ -43,3 +45,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) InferenceServices() InferenceServiceInformer {
 	return &inferenceServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// TrainedModels returns a TrainedModelInformer.
+func (v *version) TrainedModels() TrainedModelInformer {
+	return &trainedModelInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
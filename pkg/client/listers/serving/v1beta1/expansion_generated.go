@@ -33,3 +33,11 @@ type InferenceServiceListerExpansion interface{}
 // InferenceServiceNamespaceListerExpansion allows custom methods to be added to
 // InferenceServiceNamespaceLister.
 type InferenceServiceNamespaceListerExpansion interface{}
+
+// TrainedModelListerExpansion allows custom methods to be added to
+// TrainedModelLister.
+type TrainedModelListerExpansion interface{}
+
+// TrainedModelNamespaceListerExpansion allows custom methods to be added to
+// TrainedModelNamespaceLister.
+type TrainedModelNamespaceListerExpansion interface{}
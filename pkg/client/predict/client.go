@@ -0,0 +1,242 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package predict sends predict and explain requests to InferenceServices in the protocol of their predictor, v1 or v2
+// over REST or v2 over gRPC, on their external URL or on their cluster local address.
+package predict
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+// Client sends the requests to InferenceServices, the zero value sends them to the external URL with the default
+// HTTP client and without credentials
+type Client struct {
+	// HTTP client of the REST requests, http.DefaultClient when nil
+	HTTP *http.Client
+	// ClusterLocal sends the requests to the cluster local address of the InferenceService rather than to its
+	// external URL, from a pod of the cluster
+	ClusterLocal bool
+	// Component the requests are sent to, e.g. the predictor rather than the transformer in front of it, the
+	// InferenceService when empty
+	Component v1beta1.ComponentType
+	// Ingress address, e.g. http://localhost:8080 when the ingress gateway is port forwarded, the requests sent to it
+	// have the host of the InferenceService, which routes them
+	Ingress string
+	// Token sent as a bearer token in the Authorization header, e.g. the JWT of an InferenceService with auth
+	Token string
+	// APIKey sent in the X-API-Key header of an InferenceService with API keys
+	APIKey string
+	// Headers added to the requests
+	Headers map[string]string
+}
+
+// Endpoint of an InferenceService the requests are sent to
+type Endpoint struct {
+	// URL of the InferenceService without path, its external URL, its cluster local address or the ingress address
+	URL *url.URL
+	// Host of the InferenceService, the host of the requests
+	Host string
+	// Name of the model, the name of the InferenceService
+	Name string
+	// Protocol of the predictor
+	Protocol constants.InferenceServiceProtocol
+}
+
+// Resolve returns the endpoint of the InferenceService, or of its component, from the addresses of its status
+func (c *Client) Resolve(isvc *v1beta1.InferenceService) (*Endpoint, error) {
+	base, err := c.resolveURL(isvc)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := &Endpoint{
+		URL:      &url.URL{Scheme: base.Scheme, Host: base.Host},
+		Host:     base.Host,
+		Name:     isvc.Name,
+		Protocol: isvc.Spec.Predictor.GetProtocol(),
+	}
+	if c.Ingress != "" {
+		ingress, err := url.Parse(c.Ingress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ingress address %q: %v", c.Ingress, err)
+		}
+		endpoint.URL = &url.URL{Scheme: ingress.Scheme, Host: ingress.Host}
+	}
+	return endpoint, nil
+}
+
+func (c *Client) resolveURL(isvc *v1beta1.InferenceService) (*url.URL, error) {
+	address, external := isvc.Status.Address, isvc.Status.URL
+	target := "InferenceService " + isvc.Name
+	if c.Component != "" {
+		status, ok := isvc.Status.Components[c.Component]
+		if !ok {
+			return nil, fmt.Errorf("InferenceService %s has no %s", isvc.Name, c.Component)
+		}
+		address, external = status.Address, status.URL
+		target = fmt.Sprintf("the %s of InferenceService %s", c.Component, isvc.Name)
+	}
+	if c.ClusterLocal {
+		if address == nil || address.URL == nil {
+			return nil, fmt.Errorf("%s has no address", target)
+		}
+		return address.URL.URL(), nil
+	}
+	if external == nil {
+		return nil, fmt.Errorf("%s has no URL, it is not ready", target)
+	}
+	return external.URL(), nil
+}
+
+// PredictURL returns the URL of the predict endpoint of the protocol of the endpoint, /v1/models/<name>:predict or
+// /v2/models/<name>/infer
+func (e *Endpoint) PredictURL() *url.URL {
+	u := *e.URL
+	u.Path = constants.PredictPath(e.Name)
+	if e.Protocol == constants.ProtocolV2 {
+		u.Path = constants.InferPathV2(e.Name)
+	}
+	return &u
+}
+
+// ExplainURL returns the URL of the explain endpoint, only served by the v1 protocol
+func (e *Endpoint) ExplainURL() (*url.URL, error) {
+	if e.Protocol != constants.ProtocolV1 {
+		return nil, fmt.Errorf("InferenceService %s serves protocol %s, explanations require protocol v1", e.Name, e.Protocol)
+	}
+	u := *e.URL
+	u.Path = constants.ExplainPath(e.Name)
+	return &u, nil
+}
+
+// MetadataURL returns the URL of the model metadata endpoint, only served by the v2 protocol
+func (e *Endpoint) MetadataURL() (*url.URL, error) {
+	if e.Protocol != constants.ProtocolV2 {
+		return nil, fmt.Errorf("InferenceService %s serves protocol %s, model metadata requires protocol v2", e.Name, e.Protocol)
+	}
+	u := *e.URL
+	u.Path = constants.ModelMetadataPathV2(e.Name)
+	return &u, nil
+}
+
+// Predict sends the payload, in the format of the protocol of the predictor, to the predict endpoint of the
+// InferenceService and returns the response
+func (c *Client) Predict(ctx context.Context, isvc *v1beta1.InferenceService, payload []byte) ([]byte, error) {
+	endpoint, err := c.Resolve(isvc)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, "prediction", http.MethodPost, endpoint.PredictURL(), endpoint.Host, payload)
+}
+
+// Explain sends the payload to the explain endpoint of the InferenceService and returns the explanation
+func (c *Client) Explain(ctx context.Context, isvc *v1beta1.InferenceService, payload []byte) ([]byte, error) {
+	endpoint, err := c.Resolve(isvc)
+	if err != nil {
+		return nil, err
+	}
+	u, err := endpoint.ExplainURL()
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, "explanation", http.MethodPost, u, endpoint.Host, payload)
+}
+
+// Metadata reads the inputs and outputs of the model from the v2 model metadata endpoint of the InferenceService
+func (c *Client) Metadata(ctx context.Context, isvc *v1beta1.InferenceService) (*v1beta1.ModelMetadata, error) {
+	endpoint, err := c.Resolve(isvc)
+	if err != nil {
+		return nil, err
+	}
+	u, err := endpoint.MetadataURL()
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(ctx, "metadata request", http.MethodGet, u, endpoint.Host, nil)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &v1beta1.ModelMetadata{}
+	if err := json.Unmarshal(body, metadata); err != nil {
+		return nil, fmt.Errorf("invalid model metadata of InferenceService %s: %v", isvc.Name, err)
+	}
+	return metadata, nil
+}
+
+// do sends the request with the payload, if any, and the credentials of the client, the request fails on a response
+// other than 200
+func (c *Client) do(ctx context.Context, request string, method string, u *url.URL, host string,
+	payload []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Host = host
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range c.credentials() {
+		req.Header.Set(key, value)
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return body, fmt.Errorf("%s failed with status %d: %s", request, resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// credentials returns the headers of the requests, the HTTP headers of the REST requests and the metadata of the gRPC
+// requests
+func (c *Client) credentials() map[string]string {
+	headers := map[string]string{}
+	for key, value := range c.Headers {
+		headers[key] = value
+	}
+	if c.Token != "" {
+		headers["Authorization"] = "Bearer " + c.Token
+	}
+	if c.APIKey != "" {
+		headers[constants.APIKeyHeader] = c.APIKey
+	}
+	return headers
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predict
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func newInferenceService(protocol constants.InferenceServiceProtocol) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI:      proto.String("gs://kfserving-samples/models/sklearn/iris"),
+						ProtocolVersion: &protocol,
+					},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			URL: apis.HTTP("sklearn-iris.default.example.com"),
+			Address: &duckv1.Addressable{
+				URL: apis.HTTP("sklearn-iris.default.svc.cluster.local"),
+			},
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					URL: apis.HTTP("sklearn-iris-predictor-default.default.example.com"),
+					Address: &duckv1.Addressable{
+						URL: apis.HTTP("sklearn-iris-predictor-default.default.svc.cluster.local"),
+					},
+				},
+				v1beta1.TransformerComponent: {},
+			},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	scenarios := map[string]struct {
+		client   Client
+		protocol constants.InferenceServiceProtocol
+		predict  string
+		explain  string
+		host     string
+		err      string
+	}{
+		"External": {
+			protocol: constants.ProtocolV1,
+			predict:  "http://sklearn-iris.default.example.com/v1/models/sklearn-iris:predict",
+			explain:  "http://sklearn-iris.default.example.com/v1/models/sklearn-iris:explain",
+			host:     "sklearn-iris.default.example.com",
+		},
+		"ClusterLocal": {
+			client:   Client{ClusterLocal: true},
+			protocol: constants.ProtocolV2,
+			predict:  "http://sklearn-iris.default.svc.cluster.local/v2/models/sklearn-iris/infer",
+			explain:  "InferenceService sklearn-iris serves protocol v2, explanations require protocol v1",
+			host:     "sklearn-iris.default.svc.cluster.local",
+		},
+		"Component": {
+			client:   Client{Component: v1beta1.PredictorComponent},
+			protocol: constants.ProtocolV1,
+			predict:  "http://sklearn-iris-predictor-default.default.example.com/v1/models/sklearn-iris:predict",
+			explain:  "http://sklearn-iris-predictor-default.default.example.com/v1/models/sklearn-iris:explain",
+			host:     "sklearn-iris-predictor-default.default.example.com",
+		},
+		"ClusterLocalComponent": {
+			client:   Client{Component: v1beta1.PredictorComponent, ClusterLocal: true},
+			protocol: constants.ProtocolV1,
+			predict:  "http://sklearn-iris-predictor-default.default.svc.cluster.local/v1/models/sklearn-iris:predict",
+			explain:  "http://sklearn-iris-predictor-default.default.svc.cluster.local/v1/models/sklearn-iris:explain",
+			host:     "sklearn-iris-predictor-default.default.svc.cluster.local",
+		},
+		"Ingress": {
+			client:   Client{Ingress: "http://localhost:8080"},
+			protocol: constants.ProtocolV1,
+			predict:  "http://localhost:8080/v1/models/sklearn-iris:predict",
+			explain:  "http://localhost:8080/v1/models/sklearn-iris:explain",
+			host:     "sklearn-iris.default.example.com",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			endpoint, err := scenario.client.Resolve(newInferenceService(scenario.protocol))
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(endpoint.Host).To(gomega.Equal(scenario.host))
+			g.Expect(endpoint.PredictURL().String()).To(gomega.Equal(scenario.predict))
+			explain, err := endpoint.ExplainURL()
+			if err != nil {
+				g.Expect(err.Error()).To(gomega.Equal(scenario.explain))
+			} else {
+				g.Expect(explain.String()).To(gomega.Equal(scenario.explain))
+			}
+		})
+	}
+}
+
+func TestResolveNotReady(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := newInferenceService(constants.ProtocolV1)
+	isvc.Status.URL = nil
+	isvc.Status.Address = nil
+
+	_, err := (&Client{}).Resolve(isvc)
+	g.Expect(err).To(gomega.MatchError("InferenceService sklearn-iris has no URL, it is not ready"))
+	_, err = (&Client{ClusterLocal: true}).Resolve(isvc)
+	g.Expect(err).To(gomega.MatchError("InferenceService sklearn-iris has no address"))
+	_, err = (&Client{Component: v1beta1.TransformerComponent}).Resolve(isvc)
+	g.Expect(err).To(gomega.MatchError("the transformer of InferenceService sklearn-iris has no URL, it is not ready"))
+	_, err = (&Client{Component: v1beta1.ExplainerComponent}).Resolve(isvc)
+	g.Expect(err).To(gomega.MatchError("InferenceService sklearn-iris has no explainer"))
+}
+
+func TestPredict(t *testing.T) {
+	scenarios := map[string]struct {
+		client  Client
+		explain bool
+		status  int
+		path    string
+		headers map[string]string
+		err     string
+	}{
+		"Predict": {
+			status:  http.StatusOK,
+			path:    "/v1/models/sklearn-iris:predict",
+			headers: map[string]string{"Content-Type": "application/json"},
+		},
+		"Explain": {
+			explain: true,
+			status:  http.StatusOK,
+			path:    "/v1/models/sklearn-iris:explain",
+		},
+		"Credentials": {
+			client: Client{
+				Token:   "jwt",
+				APIKey:  "key",
+				Headers: map[string]string{"X-Request-Id": "1"},
+			},
+			status: http.StatusOK,
+			path:   "/v1/models/sklearn-iris:predict",
+			headers: map[string]string{
+				"Authorization": "Bearer jwt",
+				"X-Api-Key":     "key",
+				"X-Request-Id":  "1",
+			},
+		},
+		"Error": {
+			status: http.StatusServiceUnavailable,
+			path:   "/v1/models/sklearn-iris:predict",
+			err:    `prediction failed with status 503: {"predictions": [1, 1]}`,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			var request *http.Request
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+				w.WriteHeader(scenario.status)
+				w.Write([]byte(`{"predictions": [1, 1]}`))
+			}))
+			defer server.Close()
+
+			c := scenario.client
+			c.Ingress = server.URL
+			c.HTTP = server.Client()
+			isvc := newInferenceService(constants.ProtocolV1)
+			send := c.Predict
+			if scenario.explain {
+				send = c.Explain
+			}
+			response, err := send(context.Background(), isvc, []byte(`{"instances": [[6.8, 2.8, 4.8, 1.4]]}`))
+			if scenario.err != "" {
+				g.Expect(err).To(gomega.MatchError(scenario.err))
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(string(response)).To(gomega.Equal(`{"predictions": [1, 1]}`))
+			}
+			g.Expect(request.URL.Path).To(gomega.Equal(scenario.path))
+			g.Expect(request.Host).To(gomega.Equal("sklearn-iris.default.example.com"))
+			for key, value := range scenario.headers {
+				g.Expect(request.Header.Get(key)).To(gomega.Equal(value))
+			}
+			g.Expect(body).To(gomega.Equal(`{"instances": [[6.8, 2.8, 4.8, 1.4]]}`))
+		})
+	}
+}
+
+func TestInvalidIngress(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	_, err := (&Client{Ingress: "://localhost"}).Resolve(newInferenceService(constants.ProtocolV1))
+	g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(`invalid ingress address "://localhost"`)))
+}
+
+func TestMetadata(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var request *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Write([]byte(`{"name": "sklearn-iris", "platform": "mlflow",
+			"inputs": [{"name": "input-0", "datatype": "FP32", "shape": [-1, 4]}]}`))
+	}))
+	defer server.Close()
+	c := &Client{Ingress: server.URL, HTTP: server.Client()}
+
+	metadata, err := c.Metadata(context.Background(), newInferenceService(constants.ProtocolV2))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(metadata).To(gomega.Equal(&v1beta1.ModelMetadata{
+		Platform: "mlflow",
+		Inputs:   []v1beta1.TensorMetadata{{Name: "input-0", Datatype: "FP32", Shape: []int64{-1, 4}}},
+	}))
+	g.Expect(request.Method).To(gomega.Equal(http.MethodGet))
+	g.Expect(request.URL.Path).To(gomega.Equal("/v2/models/sklearn-iris"))
+
+	_, err = c.Metadata(context.Background(), newInferenceService(constants.ProtocolV1))
+	g.Expect(err).To(gomega.MatchError("InferenceService sklearn-iris serves protocol v1, model metadata requires protocol v2"))
+}
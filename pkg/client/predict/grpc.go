@@ -0,0 +1,84 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predict
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// ModelInferMethod is the ModelInfer method of the gRPC service of the v2 protocol
+const ModelInferMethod = "/inference.GRPCInferenceService/ModelInfer"
+
+// ModelInfer sends the ModelInfer request of the v2 protocol over gRPC to the InferenceService and reads the response.
+// The request and the response are the inference.ModelInferRequest and inference.ModelInferResponse messages generated
+// from grpc_predict_v2.proto by the caller.
+func (c *Client) ModelInfer(ctx context.Context, isvc *v1beta1.InferenceService, request proto.Message,
+	response proto.Message) error {
+	endpoint, err := c.Resolve(isvc)
+	if err != nil {
+		return err
+	}
+	conn, err := c.dial(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	md := metadata.MD{}
+	for key, value := range c.credentials() {
+		md.Set(strings.ToLower(key), value)
+	}
+	return conn.Invoke(metadata.NewOutgoingContext(ctx, md), ModelInferMethod, request, response)
+}
+
+// dial connects to the endpoint with the host of the InferenceService as authority, over TLS for an https endpoint
+func (c *Client) dial(ctx context.Context, endpoint *Endpoint) (*grpc.ClientConn, error) {
+	target := endpoint.URL.Host
+	if endpoint.URL.Port() == "" {
+		port := "80"
+		if endpoint.URL.Scheme == "https" {
+			port = "443"
+		}
+		target = net.JoinHostPort(endpoint.URL.Hostname(), port)
+	}
+	options := []grpc.DialOption{grpc.WithAuthority(endpoint.Host)}
+	if endpoint.URL.Scheme == "https" {
+		options = append(options, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, hostname(endpoint.Host))))
+	} else {
+		options = append(options, grpc.WithInsecure())
+	}
+	conn, err := grpc.DialContext(ctx, target, options...)
+	if err != nil {
+		return nil, fmt.Errorf("fails to connect to %s: %v", target, err)
+	}
+	return conn, nil
+}
+
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predict
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// inferenceServer serves the ModelInfer method with wrapper messages in place of the messages of the v2 protocol
+type inferenceServer struct {
+	authority string
+	md        metadata.MD
+}
+
+func (s *inferenceServer) modelInfer(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := &wrappers.StringValue{}
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	s.authority = s.md.Get(":authority")[0]
+	return &wrappers.StringValue{Value: "response to " + request.Value}, nil
+}
+
+func TestModelInfer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	server := grpc.NewServer()
+	inference := &inferenceServer{}
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "inference.GRPCInferenceService",
+		HandlerType: (*interface{})(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "ModelInfer", Handler: inference.modelInfer}},
+	}, inference)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	c := &Client{Ingress: "http://" + listener.Addr().String(), Token: "jwt", APIKey: "key"}
+	response := &wrappers.StringValue{}
+	err = c.ModelInfer(context.Background(), newInferenceService(constants.ProtocolV2), &wrappers.StringValue{Value: "request"},
+		response)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(response.Value).To(gomega.Equal("response to request"))
+	g.Expect(inference.authority).To(gomega.Equal("sklearn-iris.default.example.com"))
+	g.Expect(inference.md.Get("authorization")).To(gomega.Equal([]string{"Bearer jwt"}))
+	g.Expect(inference.md.Get(constants.APIKeyHeader)).To(gomega.Equal([]string{"key"}))
+}
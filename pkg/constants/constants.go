@@ -17,11 +17,13 @@ limitations under the License.
 package constants
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"knative.dev/pkg/network"
 
@@ -42,6 +44,11 @@ var (
 	InferenceServiceAPIName       = "inferenceservices"
 	InferenceServicePodLabelKey   = KFServingAPIGroupName + "/" + InferenceServiceName
 	InferenceServiceConfigMapName = "inferenceservice-config"
+	// StorageInitializerLabelKey sends the pods outside an InferenceService to the pod mutator, the value is enabled
+	StorageInitializerLabelKey = KFServingAPIGroupName + "/storage-initializer"
+	// InferenceServiceConfigLabelKey sends the inferenceservice-config ConfigMaps to the ConfigMap validator, the value
+	// is enabled
+	InferenceServiceConfigLabelKey = KFServingAPIGroupName + "/" + InferenceServiceConfigMapName
 )
 
 // InferenceService MultiModel Constants
@@ -52,19 +59,110 @@ var (
 // InferenceService Annotations
 var (
 	InferenceServiceGKEAcceleratorAnnotationKey = KFServingAPIGroupName + "/gke-accelerator"
+	TorchServeWorkersAnnotationKey              = KFServingAPIGroupName + "/torchserve-workers-per-model"
+	TorchServeJobQueueSizeAnnotationKey         = KFServingAPIGroupName + "/torchserve-job-queue-size"
+	ModelSizeAnnotationKey                      = KFServingAPIGroupName + "/model-size"
+	// ServingRuntimeAnnotationKey records the runtime selected to serve a model predictor
+	ServingRuntimeAnnotationKey = KFServingAPIGroupName + "/serving-runtime"
+	// WarmPoolAnnotationKey names the WarmPool serving the model until the predictor is ready
+	WarmPoolAnnotationKey = KFServingAPIGroupName + "/warm-pool"
+	// StorageURIAnnotationKey provisions the model of the URI into the containers of a pod outside an InferenceService,
+	// the pod also needs the StorageInitializerLabelKey label to be sent to the pod mutator
+	StorageURIAnnotationKey = KFServingAPIGroupName + "/storage-uri"
+	// ResourceProfileAnnotationKey selects the resource profile of the framework set when the resources are omitted
+	ResourceProfileAnnotationKey = KFServingAPIGroupName + "/resource-profile"
+	// IngressHostAnnotationKey overrides the external host of the InferenceService, it takes precedence over the domain
+	// template of the ingress config
+	IngressHostAnnotationKey = KFServingAPIGroupName + "/ingress-host"
+	// AuthRequiredAnnotationKey requires a JWT to call the InferenceService at the ingress gateway, "true" or "false",
+	// it overrides the required field of the auth ingress config
+	AuthRequiredAnnotationKey = KFServingAPIGroupName + "/auth-required"
+	// AuthIssuerAnnotationKey, AuthAudiencesAnnotationKey and AuthSubjectsAnnotationKey override the issuer, the
+	// comma separated audiences and the comma separated allowed subjects of the auth ingress config
+	AuthIssuerAnnotationKey    = KFServingAPIGroupName + "/auth-issuer"
+	AuthAudiencesAnnotationKey = KFServingAPIGroupName + "/auth-audiences"
+	AuthSubjectsAnnotationKey  = KFServingAPIGroupName + "/auth-subjects"
+	// APIKeyAnnotationKey requires a valid API key in the X-API-Key header to call the InferenceService at the ingress
+	// gateway, "true" or "false". The keys are the values of the API key secret of the InferenceService.
+	APIKeyAnnotationKey = KFServingAPIGroupName + "/api-key"
+	// APIKeyRotationAnnotationKey rotates the generated API key when its value changes, the previous key stays valid
+	// until the next rotation
+	APIKeyRotationAnnotationKey = KFServingAPIGroupName + "/api-key-rotation"
+	// APIKeySecretLabelKey labels the API key secrets, the controller and the ext-authz provider checking the keys
+	// only watch the labeled secrets
+	APIKeySecretLabelKey = KFServingAPIGroupName + "/api-key-secret"
+	// IdleTTLAnnotationKey is the duration after the last request the idle InferenceService is acted on, e.g. "24h"
+	IdleTTLAnnotationKey = KFServingAPIGroupName + "/ttl-after-last-request"
+	// IdleActionAnnotationKey is the action taken on the idle InferenceService, ScaleToZero, Suspend or Delete,
+	// Suspend by default
+	IdleActionAnnotationKey = KFServingAPIGroupName + "/idle-action"
+)
+
+// WarmPool Constants
+var (
+	WarmPoolLabelKey           = KFServingAPIGroupName + "/warm-pool"
+	WarmPoolStateLabelKey      = KFServingAPIGroupName + "/warm-pool-state"
+	WarmPoolClaimLabelKey      = KFServingAPIGroupName + "/warm-pool-claim"
+	WarmPoolStateAvailable     = "available"
+	WarmPoolStateClaimed       = "claimed"
+	WarmPoolModelConfigMount   = "/mnt/configs"
+	WarmPoolAgentContainerName = "agent"
+)
+
+// Namespace Labels
+var (
+	// V1Alpha2CompatibilityLabelKey enables the routes keeping the v1alpha2 inference paths working in the namespace
+	V1Alpha2CompatibilityLabelKey   = KFServingAPIGroupName + "/v1alpha2-compatibility"
+	V1Alpha2CompatibilityLabelValue = "enabled"
+)
+
+// Scale from zero queue
+var (
+	// WakeQueueSchedulerName is the scheduler name of the cold started pods held by the scale from zero queue, no
+	// scheduler schedules them until the queue releases them
+	WakeQueueSchedulerName = "kfserving-wake-queue"
+	// WakeAdmittedLabelKey labels the ReplicaSets of the components woken by the scale from zero queue with the unix
+	// time of their admission
+	WakeAdmittedLabelKey = KFServingAPIGroupName + "/wake-admitted"
+)
+
+// Conversion Annotations
+var (
+	// V1Alpha2SpecAnnotationKey keeps the v1alpha2 spec of an InferenceService when its conversion to v1beta1 loses some
+	// of its fields, e.g. the default endpoint of a canary, to restore them when the InferenceService is read in v1alpha2
+	V1Alpha2SpecAnnotationKey = KFServingAPIGroupName + "/v1alpha2-spec"
+	// V1Beta1SpecAnnotationKey keeps the v1beta1 spec of an InferenceService when its conversion to v1alpha2 loses some
+	// of its fields, to restore them when the InferenceService is written back in v1alpha2
+	V1Beta1SpecAnnotationKey = KFServingAPIGroupName + "/v1beta1-spec"
 )
 
 // InferenceService Internal Annotations
 var (
 	InferenceServiceInternalAnnotationsPrefix        = "internal." + KFServingAPIGroupName
 	StorageInitializerSourceUriInternalAnnotationKey = InferenceServiceInternalAnnotationsPrefix + "/storage-initializer-sourceuri"
+	ModelConversionInternalAnnotationKey             = InferenceServiceInternalAnnotationsPrefix + "/model-conversion"
+	TorchServeHandlerInternalAnnotationKey           = InferenceServiceInternalAnnotationsPrefix + "/torchserve-handler"
+	SchedulingInternalAnnotationKey                  = InferenceServiceInternalAnnotationsPrefix + "/scheduling"
+	StartupProbeInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/startup-probe"
+	WarmupInternalAnnotationKey                      = InferenceServiceInternalAnnotationsPrefix + "/warmup"
+	AsyncInternalAnnotationKey                       = InferenceServiceInternalAnnotationsPrefix + "/async"
+	RequestValidationInternalAnnotationKey           = InferenceServiceInternalAnnotationsPrefix + "/request-validation"
+	ResponseCacheInternalAnnotationKey               = InferenceServiceInternalAnnotationsPrefix + "/response-cache"
+	StreamingInternalAnnotationKey                   = InferenceServiceInternalAnnotationsPrefix + "/streaming"
+	SidecarsInternalAnnotationKey                    = InferenceServiceInternalAnnotationsPrefix + "/sidecars"
+	VolumesInternalAnnotationKey                     = InferenceServiceInternalAnnotationsPrefix + "/volumes"
+	ImageDigestsInternalAnnotationKey                = InferenceServiceInternalAnnotationsPrefix + "/image-digests"
+	ModelDigestInternalAnnotationKey                 = InferenceServiceInternalAnnotationsPrefix + "/model-digest"
 	LoggerInternalAnnotationKey                      = InferenceServiceInternalAnnotationsPrefix + "/logger"
 	LoggerSinkUrlInternalAnnotationKey               = InferenceServiceInternalAnnotationsPrefix + "/logger-sink-url"
 	LoggerModeInternalAnnotationKey                  = InferenceServiceInternalAnnotationsPrefix + "/logger-mode"
+	LoggerExplainerUrlInternalAnnotationKey          = InferenceServiceInternalAnnotationsPrefix + "/logger-explainer-url"
+	LoggerExplainSamplingInternalAnnotationKey       = InferenceServiceInternalAnnotationsPrefix + "/logger-explain-sampling-percent"
 	BatcherInternalAnnotationKey                     = InferenceServiceInternalAnnotationsPrefix + "/batcher"
 	BatcherMaxBatchSizeInternalAnnotationKey         = InferenceServiceInternalAnnotationsPrefix + "/batcher-max-batchsize"
 	BatcherMaxLatencyInternalAnnotationKey           = InferenceServiceInternalAnnotationsPrefix + "/batcher-max-latency"
 	BatcherTimeoutInternalAnnotationKey              = InferenceServiceInternalAnnotationsPrefix + "/batcher-timeout"
+	BatcherMaxQueueDepthInternalAnnotationKey        = InferenceServiceInternalAnnotationsPrefix + "/batcher-max-queue-depth"
 )
 
 // Controller Constants
@@ -76,6 +174,8 @@ var (
 	DefaultReadinessTimeout   int32 = 600
 	DefaultScalingTarget            = "1"
 	DefaultMinReplicas        int   = 1
+	// ControllerFieldManager owns the fields of the resources the controller applies with server-side apply
+	ControllerFieldManager = KFServingName + "-controller"
 )
 
 // Webhook Constants
@@ -85,11 +185,19 @@ var (
 	EnableWebhookNamespaceSelectorEnvValue = "enabled"
 	IsEnableWebhookNamespaceSelector       = isEnvVarMatched(EnableWebhookNamespaceSelectorEnvName, EnableWebhookNamespaceSelectorEnvValue)
 	PodMutatorWebhookName                  = KFServingName + "-pod-mutator-webhook"
+	ConfigMapValidatorWebhookName          = KFServingName + "-configmap-validator-webhook"
+	ServingQuotaValidatorWebhookName       = KFServingName + "-servingquota-validator-webhook"
 )
 
 // GPU Constants
 const (
 	NvidiaGPUResourceType = "nvidia.com/gpu"
+	// NvidiaMIGResourcePrefix prefixes the resources of the MIG profiles advertised with the mixed strategy of the
+	// NVIDIA device plugin, e.g. nvidia.com/mig-1g.5gb
+	NvidiaMIGResourcePrefix = "nvidia.com/mig-"
+	// GPUSharedResourceSuffix suffixes the resources of the time-sliced GPUs advertised by the NVIDIA device plugin
+	// with renameByDefault, e.g. nvidia.com/gpu.shared
+	GPUSharedResourceSuffix = ".shared"
 )
 
 // DefaultModelLocalMountPath is where models will be mounted by the storage-initializer
@@ -97,18 +205,199 @@ const DefaultModelLocalMountPath = "/mnt/models"
 
 // InferenceService Environment Variables
 const (
-	CustomSpecStorageUriEnvVarKey = "STORAGE_URI"
+	CustomSpecStorageUriEnvVarKey    = "STORAGE_URI"
+	PredictorHostEnvVarKey           = "PREDICTOR_HOST"
+	PredictorProtocolEnvVarKey       = "PREDICTOR_PROTOCOL"
+	PredictorMaxConnectionsEnvVarKey = "PREDICTOR_MAX_CONNECTIONS"
+	PredictorStreamingEnvVarKey      = "PREDICTOR_STREAMING"
+	PredictorWebsocketEnvVarKey      = "PREDICTOR_WEBSOCKET"
+	TorchServeHandlerEnvVarKey       = "TORCHSERVE_HANDLER"
+	TorchServeModelNameEnvVarKey     = "TORCHSERVE_MODEL_NAME"
+	ModelDigestEnvVarKey             = "MODEL_DIGEST"
 )
 
 type InferenceServiceComponent string
 
 type InferenceServiceVerb string
 
+type InferenceServiceProtocol string
+
 // Knative constants
 const (
-	KnativeLocalGateway   = "knative-serving/cluster-local-gateway"
-	KnativeIngressGateway = "knative-serving/knative-ingress-gateway"
-	VisibilityLabel       = "serving.knative.dev/visibility"
+	KnativeLocalGateway       = "knative-serving/cluster-local-gateway"
+	KnativeIngressGateway     = "knative-serving/knative-ingress-gateway"
+	VisibilityLabel           = "serving.knative.dev/visibility"
+	NetworkingVisibilityLabel = "networking.knative.dev/visibility"
+	// VisibilityClusterLocal is the value of the visibility labels keeping a Knative service off the external gateway
+	VisibilityClusterLocal         = "cluster-local"
+	KnativeQueueProxyContainerName = "queue-proxy"
+	// RollbackTrafficTag is the traffic tag of the revision the traffic is pinned to by rollbackTo
+	RollbackTrafficTag = "rollback"
+	// LatestTrafficTag is the traffic tag of the latest revision, Knative routes the host prefixed by the tag to it
+	LatestTrafficTag = "latest"
+)
+
+// KEDA constants
+const (
+	// KedaAutoscalerClass is the Knative autoscaling class of the revisions scaled by a KEDA ScaledObject, the
+	// revisions are left to the KEDA autoscaler extension of Knative
+	KedaAutoscalerClass = "keda.autoscaling.knative.dev"
+	KedaAPIVersion      = "keda.sh/v1alpha1"
+	KedaScaledObject    = "ScaledObject"
+)
+
+// Rate and size limit constants
+const (
+	IstioNetworkingAPIVersion = "networking.istio.io/v1alpha3"
+	IstioEnvoyFilter          = "EnvoyFilter"
+	// LocalRateLimitFilterName is the Envoy HTTP filter rejecting the requests over the token bucket of the sidecar
+	LocalRateLimitFilterName = "envoy.filters.http.local_ratelimit"
+	LocalRateLimitTypeURL    = "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit"
+	RateLimitedHeader        = "x-local-rate-limit"
+	// BufferFilterName is the Envoy HTTP filter rejecting the requests over maxRequestBytes with 413
+	BufferFilterName = "envoy.filters.http.buffer"
+	BufferTypeURL    = "type.googleapis.com/envoy.extensions.filters.http.buffer.v3.Buffer"
+	// LuaFilterName is the Envoy HTTP filter replacing the responses over maxResponseBytes with a 502
+	LuaFilterName = "envoy.filters.http.lua"
+	LuaTypeURL    = "type.googleapis.com/envoy.extensions.filters.http.lua.v3.Lua"
+	// DefaultRouteRetryAttempts is the number of retries of the Istio routes, the default of Istio
+	DefaultRouteRetryAttempts int32 = 2
+)
+
+// Hedging constants
+const (
+	// HedgingRetryOn are the conditions the requests of the hedged routes are retried on besides the hedging delay
+	HedgingRetryOn = "gateway-error,connect-failure,refused-stream"
+	// HedgingLatencyWindow is the time range of the latency percentile the hedged requests are sent after
+	HedgingLatencyWindow = 5 * time.Minute
+	// HedgingRequeueInterval is the interval the latency percentile of the hedged components is queried at
+	HedgingRequeueInterval = time.Minute
+)
+
+// Gateway API constants
+const (
+	GatewayAPIVersion   = "networking.x-k8s.io/v1alpha1"
+	GatewayAPIHTTPRoute = "HTTPRoute"
+)
+
+// cert-manager constants
+const (
+	CertManagerAPIVersion        = "cert-manager.io/v1alpha2"
+	CertManagerCertificate       = "Certificate"
+	DefaultCertificateIssuerKind = "ClusterIssuer"
+	// DefaultCertificateNamespace is the namespace of the Istio ingress gateway pods, the gateway only reads the TLS
+	// secrets of its namespace
+	DefaultCertificateNamespace = "istio-system"
+	// CertificateRequeueInterval is the interval the inference service is reconciled at until its certificate is ready
+	CertificateRequeueInterval = 10 * time.Second
+)
+
+// RolloutRequeueInterval is the interval the inference service is reconciled at while a new revision is rolled out,
+// so the rollout is aborted once past its progress deadline
+const RolloutRequeueInterval = 30 * time.Second
+
+// CanaryAnalysisRequeueInterval is the interval the metrics of the canaries of an inference service are analyzed at
+const CanaryAnalysisRequeueInterval = 30 * time.Second
+
+// BatchInferenceRequeueInterval is the interval a batch inference job waits at for its inference service to be ready
+const BatchInferenceRequeueInterval = 30 * time.Second
+
+// Istio security constants
+const (
+	IstioSecurityAPIVersion          = "security.istio.io/v1beta1"
+	IstioRequestAuthentication       = "RequestAuthentication"
+	IstioAuthorizationPolicy         = "AuthorizationPolicy"
+	DefaultAuthPolicyNamespace       = "istio-system"
+	RequestAuthAudiencesConditionKey = "request.auth.audiences"
+)
+
+// Istio mesh constants
+const (
+	IstioPeerAuthentication = "PeerAuthentication"
+	// IstioSidecarInjectAnnotationKey injects the Istio sidecar into the pod when "true", keeps it out when "false"
+	IstioSidecarInjectAnnotationKey = "sidecar.istio.io/inject"
+	// IstioRewriteProbersAnnotationKey redirects the HTTP probes of the kubelet through the sidecar, the probes are
+	// plain HTTP rejected by strict mutual TLS otherwise
+	IstioRewriteProbersAnnotationKey = "sidecar.istio.io/rewriteAppHTTPProbers"
+	// IstioProxyConfigAnnotationKey overrides the proxy config of the sidecar of the pod
+	IstioProxyConfigAnnotationKey = "proxy.istio.io/config"
+	// IstioHoldApplicationProxyConfig starts the containers once the sidecar is ready, so the sidecars of the pod can
+	// reach the network on start
+	IstioHoldApplicationProxyConfig = `{"holdApplicationUntilProxyStarts": true}`
+	// IstioProxyUID is the user of the sidecar, its traffic is not redirected to the sidecar. The init containers
+	// run before the sidecar starts, they reach the network as this user.
+	IstioProxyUID int64 = 1337
+)
+
+// KnativeQueueProxyMetricsPorts are the ports of the queue-proxy sidecar scraped by the Knative autoscaler and
+// Prometheus, they are left permissive by strict mutual TLS since the scrapers may not be in the mesh
+var KnativeQueueProxyMetricsPorts = []int64{9090, 9091}
+
+// Streaming constants
+const (
+	// AccelBufferingHeader set to "no" on the streamed responses tells the proxies in front of the ingress gateway,
+	// e.g. nginx, not to buffer them
+	AccelBufferingHeader = "X-Accel-Buffering"
+)
+
+// API key constants
+const (
+	APIKeyHeader = "x-api-key"
+	// APIKeySecretKey is the key of the generated API key in the API key secret, PreviousAPIKeySecretKey is the key of
+	// the API key it replaced on the last rotation
+	APIKeySecretKey         = "api-key"
+	PreviousAPIKeySecretKey = "previous-api-key"
+)
+
+// IngressGatewayFinalizer deletes the certificate, the auth policies and the API key policy of the external host,
+// they are in the namespace of the ingress gateway so they are not garbage collected with the InferenceService
+var IngressGatewayFinalizer = KFServingAPIGroupName + "/ingress-gateway"
+
+// Federation constants
+var (
+	// MemberClusterLabelKey labels the secrets of the kubeconfigs of the member clusters in the namespace of the
+	// controller, the name of the secret is the name of the member cluster
+	MemberClusterLabelKey = KFServingAPIGroupName + "/member-cluster"
+	// FederatedFromAnnotationKey annotates the copies of an InferenceService in the member clusters with the
+	// <namespace>/<name> of the federated InferenceService
+	FederatedFromAnnotationKey = KFServingAPIGroupName + "/federated-from"
+	// FederationFinalizer deletes the copies of an InferenceService in the member clusters
+	FederationFinalizer = KFServingAPIGroupName + "/federation"
+)
+
+const (
+	// MemberClusterKubeconfigKey is the key of the kubeconfig in the secret of a member cluster
+	MemberClusterKubeconfigKey = "kubeconfig"
+	// RegionLabelKey labels the secret of a member cluster with its region
+	RegionLabelKey = "topology.kubernetes.io/region"
+	// FederationRequeueInterval is the interval the statuses of the copies of a federated InferenceService are read at
+	FederationRequeueInterval = 30 * time.Second
+)
+
+// ServingQuota constants
+const (
+	// QuotaRequeueInterval is the interval the InferenceServices queued by a ServingQuota are admitted again at
+	QuotaRequeueInterval = 30 * time.Second
+)
+
+// Cost constants
+const (
+	// CostRequeueInterval is the interval the hourly cost of an InferenceService is estimated again at
+	CostRequeueInterval = time.Minute
+)
+
+// Policy constants
+const (
+	// PolicyRequeueInterval is the interval the components denied by the policy engines are verified again at
+	PolicyRequeueInterval = time.Minute
+)
+
+// Ambassador and Contour constants
+const (
+	AmbassadorAPIVersion = "getambassador.io/v2"
+	AmbassadorMapping    = "Mapping"
+	ContourAPIVersion    = "projectcontour.io/v1"
+	ContourHTTPProxy     = "HTTPProxy"
 )
 
 var (
@@ -128,12 +417,22 @@ const (
 	Explain InferenceServiceVerb = "explain"
 )
 
+// InferenceService protocol enums
+const (
+	ProtocolV1 InferenceServiceProtocol = "v1"
+	ProtocolV2 InferenceServiceProtocol = "v2"
+)
+
 // InferenceService Endpoint Ports
 const (
-	InferenceServiceDefaultHttpPort    = "8080"
-	InferenceServiceDefaultLoggerPort  = "8081"
-	InferenceServiceDefaultBatcherPort = "9082"
-	CommonDefaultHttpPort              = 80
+	InferenceServiceDefaultHttpPort      = "8080"
+	InferenceServiceDefaultLoggerPort    = "8081"
+	InferenceServiceDefaultBatcherPort   = "9082"
+	InferenceServiceDefaultWarmupPort    = "9083"
+	InferenceServiceDefaultAsyncPort     = "9084"
+	InferenceServiceDefaultValidatorPort = "9085"
+	InferenceServiceDefaultCachePort     = "9086"
+	CommonDefaultHttpPort                = 80
 )
 
 // Labels to put on kservice
@@ -157,6 +456,7 @@ const (
 	ArgumentPredictorHost  = "--predictor_host"
 	ArgumentHttpPort       = "--http_port"
 	ArgumentWorkers        = "--workers"
+	ArgumentLoadPolicy     = "--load_policy"
 )
 
 // InferenceService container name
@@ -174,6 +474,8 @@ var (
 		autoscaling.MinScaleAnnotationKey,
 		autoscaling.MaxScaleAnnotationKey,
 		StorageInitializerSourceUriInternalAnnotationKey,
+		V1Alpha2SpecAnnotationKey,
+		V1Beta1SpecAnnotationKey,
 		"kubectl.kubernetes.io/last-applied-configuration",
 	}
 )
@@ -229,6 +531,13 @@ func CanaryTransformerServiceName(name string) string {
 	return name + "-" + string(Transformer) + "-" + InferenceServiceCanary
 }
 
+// BatchInferenceJob Constants
+var (
+	BatchInferenceJobLabelKey         = KFServingAPIGroupName + "/batch-inference-job"
+	BatchInferenceShardLabelKey       = KFServingAPIGroupName + "/batch-inference-shard"
+	BatchInferenceWorkerContainerName = "worker"
+)
+
 func DefaultServiceName(name string, component InferenceServiceComponent) string {
 	return name + "-" + component.String() + "-" + InferenceServiceDefault
 }
@@ -237,6 +546,59 @@ func CanaryServiceName(name string, component InferenceServiceComponent) string
 	return name + "-" + component.String() + "-" + InferenceServiceCanary
 }
 
+// WarmPoolDeploymentName returns the name of the deployment running the pods of the warm pool
+func WarmPoolDeploymentName(pool string) string {
+	return pool + "-warmpool"
+}
+
+// WarmPoolModelConfigName returns the name of the config map holding the models loaded by the claimed pods
+func WarmPoolModelConfigName(pool string) string {
+	return pool + "-warmpool-models"
+}
+
+// WarmPoolModelConfigFileName returns the key of the config map holding the models loaded by the pod
+func WarmPoolModelConfigFileName(pod string) string {
+	return pod + ".json"
+}
+
+// WarmPoolAgentRoleName returns the name of the Role granting the agents of the warm pool the updates of the
+// TrainedModel status
+func WarmPoolAgentRoleName(pool string) string {
+	return pool + "-warmpool-agent"
+}
+
+// WarmPoolClaimServiceName returns the name of the service routing to the pod claimed by the inference service
+func WarmPoolClaimServiceName(name string) string {
+	return name + "-warm"
+}
+
+// PredictorCallDestinationRuleName returns the name of the DestinationRule configuring the calls of the transformer to
+// the predictor
+func PredictorCallDestinationRuleName(name string) string {
+	return name + "-predictor-call"
+}
+
+// BatchInferenceWorkerName returns the name of the job running the worker of a shard of the batch inference job
+func BatchInferenceWorkerName(job string, shard int) string {
+	return fmt.Sprintf("%s-worker-%d", job, shard)
+}
+
+// BatchInferenceProgressName returns the name of the config map and of the role the running workers of the batch
+// inference job report their progress with
+func BatchInferenceProgressName(job string) string {
+	return job + "-progress"
+}
+
+// AsyncQueueName is the name of the queue of the async requests of an inference service, the key of the Redis list
+func AsyncQueueName(namespace string, name string) string {
+	return fmt.Sprintf("kfserving:async:%s:%s", namespace, name)
+}
+
+// ResponseCacheKeyPrefix is the prefix of the Redis keys of the cached responses of a component of an inference service
+func ResponseCacheKeyPrefix(namespace string, name string, component string) string {
+	return fmt.Sprintf("kfserving:cache:%s:%s:%s:", namespace, name, component)
+}
+
 func ModelConfigName(inferenceserviceName string, shardId int) string {
 	return fmt.Sprintf("modelconfig-%s-%d", inferenceserviceName, shardId)
 }
@@ -249,10 +611,81 @@ func PredictPath(name string) string {
 	return fmt.Sprintf("/v1/models/%s:predict", name)
 }
 
+// gatewayNamespaceName is the name of a resource of an InferenceService in the namespace of the ingress gateway, shared
+// by all the namespaces. The hash of the namespace and of the name tells apart the InferenceServices joining to the same
+// name, e.g. the InferenceService c of the namespace a-b and the InferenceService b-c of the namespace a.
+func gatewayNamespaceName(name string, namespace string) string {
+	hash := sha256.Sum256([]byte(namespace + "/" + name))
+	return fmt.Sprintf("%s-%s-%x", namespace, name, hash[:4])
+}
+
+// CertificateName is the name of the certificate and of the TLS secret of the external host of an InferenceService,
+// the certificates of all the namespaces share the namespace of the ingress gateway
+func CertificateName(name string, namespace string) string {
+	return gatewayNamespaceName(name, namespace)
+}
+
+// AuthPolicyName is the name of the RequestAuthentication and of the AuthorizationPolicy of the external host of an
+// InferenceService in the namespace of the ingress gateway
+func AuthPolicyName(name string, namespace string) string {
+	return gatewayNamespaceName(name, namespace)
+}
+
+// LimitsEnvoyFilterName is the name of the EnvoyFilter limiting the request rate and the request and response sizes
+// of a component
+func LimitsEnvoyFilterName(componentServiceName string) string {
+	return componentServiceName + "-limits"
+}
+
+// HedgingEnvoyFilterName is the name of the EnvoyFilter hedging the requests of an InferenceService in the namespace of
+// the ingress gateway
+func HedgingEnvoyFilterName(name string, namespace string) string {
+	return gatewayNamespaceName(name, namespace) + "-hedging"
+}
+
+// HedgingRouteName is the name of the routes of a hedged component of an InferenceService, the EnvoyFilter of the
+// ingress gateway matches the routes by name
+func HedgingRouteName(name string, namespace string, component string) string {
+	return namespace + "." + name + "." + component + ".hedging"
+}
+
+// PeerAuthenticationName is the name of the PeerAuthentication setting the mutual TLS mode of an InferenceService
+func PeerAuthenticationName(name string) string {
+	return name + "-mtls"
+}
+
+// APIKeySecretName is the name of the secret of the API keys of an InferenceService
+func APIKeySecretName(name string) string {
+	return name + "-api-keys"
+}
+
+// APIKeyPolicyName is the name of the AuthorizationPolicy checking the API keys of an InferenceService in the
+// namespace of the ingress gateway
+func APIKeyPolicyName(name string, namespace string) string {
+	return gatewayNamespaceName(name, namespace) + "-api-key"
+}
+
+// TLSGatewayName is the name of the Istio Gateway terminating TLS for the external host of an InferenceService
+func TLSGatewayName(name string) string {
+	return name + "-tls"
+}
+
 func ExplainPath(name string) string {
 	return fmt.Sprintf("/v1/models/%s:explain", name)
 }
 
+func InferPathV2(name string) string {
+	return fmt.Sprintf("/v2/models/%s/infer", name)
+}
+
+func ModelMetadataPathV2(name string) string {
+	return fmt.Sprintf("/v2/models/%s", name)
+}
+
+func ModelReadyPathV2(name string) string {
+	return fmt.Sprintf("/v2/models/%s/ready", name)
+}
+
 func PredictPrefix() string {
 	return fmt.Sprintf("^/v1/models/[\\w-]+(:predict)?")
 }
@@ -274,6 +707,14 @@ func PredictorURL(metadata v1.ObjectMeta, isCanary bool) string {
 	return fmt.Sprintf("%s.%s", serviceName, metadata.Namespace)
 }
 
+func ExplainerURL(metadata v1.ObjectMeta, isCanary bool) string {
+	serviceName := DefaultExplainerServiceName(metadata.Name)
+	if isCanary {
+		serviceName = CanaryExplainerServiceName(metadata.Name)
+	}
+	return fmt.Sprintf("%s.%s", serviceName, metadata.Namespace)
+}
+
 func TransformerURL(metadata v1.ObjectMeta, isCanary bool) string {
 	serviceName := DefaultTransformerServiceName(metadata.Name)
 	if isCanary {
@@ -0,0 +1,293 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=batchinferencejobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=batchinferencejobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
+package batchinferencejob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/batchinference"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// BatchInferenceJobReconciler reconciles a BatchInferenceJob object. The input files are split in as many shards as
+// the parallelism of the job, each shard is processed by the worker run by a job of its own.
+type BatchInferenceJobReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+}
+
+func (r *BatchInferenceJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("batchinferencejob", req.NamespacedName)
+	if owned, err := r.Shard.Owns(req.Namespace); !owned {
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the BatchInferenceJob instance
+	job := &v1beta1api.BatchInferenceJob{}
+	if err := r.Get(context.TODO(), req.NamespacedName, job); err != nil {
+		if apierr.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if job.Status.IsDone() {
+		return reconcile.Result{}, nil
+	}
+	log.Info("Reconciling batch inference job", "inferenceService", job.Spec.InferenceService,
+		"parallelism", job.Spec.GetParallelism())
+	job.Status.InitializeConditions()
+
+	if err := job.Spec.Validate(); err != nil {
+		job.Status.MarkFailed("InvalidSpec", err.Error())
+		return reconcile.Result{}, r.updateStatus(job)
+	}
+
+	workers, err := r.listWorkers(job)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to list workers")
+	}
+	// The workers are created once the inference service is ready, the created workers keep running when the
+	// inference service is updated afterwards
+	if len(workers) == 0 {
+		isvc := &v1beta1api.InferenceService{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: job.Spec.InferenceService, Namespace: job.Namespace}, isvc); err != nil {
+			if !apierr.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			job.Status.MarkRunning("InferenceServiceNotFound",
+				fmt.Sprintf("InferenceService %s not found", job.Spec.InferenceService))
+			return reconcile.Result{RequeueAfter: constants.BatchInferenceRequeueInterval}, r.updateStatus(job)
+		}
+		predictURL, err := getPredictURL(isvc)
+		if !isvc.Status.IsReady() || err != nil {
+			job.Status.MarkRunning("InferenceServiceNotReady",
+				fmt.Sprintf("InferenceService %s is not ready", job.Spec.InferenceService))
+			return reconcile.Result{RequeueAfter: constants.BatchInferenceRequeueInterval}, r.updateStatus(job)
+		}
+		if workers, err = r.createWorkers(job, predictURL); err != nil {
+			r.Recorder.Eventf(job, v1.EventTypeWarning, "InternalError", err.Error())
+			return reconcile.Result{}, errors.Wrapf(err, "fails to create workers")
+		}
+	}
+	if job.Status.StartTime == nil {
+		now := metav1.Now()
+		job.Status.StartTime = &now
+	}
+
+	if err := r.propagateWorkerStatus(job, workers); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to propagate worker status")
+	}
+	// The progress reported by the running workers does not trigger a reconcile, it is read again after an interval
+	if !job.Status.IsDone() {
+		return reconcile.Result{RequeueAfter: constants.BatchInferenceRequeueInterval}, r.updateStatus(job)
+	}
+	return reconcile.Result{}, r.updateStatus(job)
+}
+
+func (r *BatchInferenceJobReconciler) listWorkers(job *v1beta1api.BatchInferenceJob) ([]batchv1.Job, error) {
+	workers := &batchv1.JobList{}
+	if err := r.List(context.TODO(), workers, client.InNamespace(job.Namespace),
+		client.MatchingLabels{constants.BatchInferenceJobLabelKey: job.Name}); err != nil {
+		return nil, err
+	}
+	return workers.Items, nil
+}
+
+// createWorkers creates the job of the worker of each shard with the storage credentials of the service account
+func (r *BatchInferenceJobReconciler) createWorkers(job *v1beta1api.BatchInferenceJob, predictURL string) ([]batchv1.Job, error) {
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName,
+		Namespace: constants.KFServingNamespace}, configMap); err != nil {
+		return nil, errors.Wrapf(err, "fails to get the inference service config map")
+	}
+	config, err := getBatchInferenceConfig(configMap)
+	if err != nil {
+		return nil, err
+	}
+	credentialBuilder := credentials.NewCredentialBulder(r.Client, configMap)
+	progressConfigMap, role, roleBinding := createProgress(job)
+	for _, object := range []runtime.Object{progressConfigMap, role, roleBinding} {
+		if err := controllerutil.SetControllerReference(job, object.(metav1.Object), r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(context.TODO(), object); err != nil && !apierr.IsAlreadyExists(err) {
+			return nil, errors.Wrapf(err, "fails to create the progress config map and its role")
+		}
+	}
+	workers := []batchv1.Job{}
+	for shard := 0; shard < int(job.Spec.GetParallelism()); shard++ {
+		worker := createWorker(job, config, predictURL, shard)
+		podSpec := &worker.Spec.Template.Spec
+		if err := credentialBuilder.CreateSecretVolumeAndEnv(job.Namespace, podSpec.ServiceAccountName,
+			&podSpec.Containers[0], &podSpec.Volumes); err != nil {
+			return nil, err
+		}
+		if err := controllerutil.SetControllerReference(job, worker, r.Scheme); err != nil {
+			return nil, err
+		}
+		r.Log.Info("Creating batch inference worker", "namespace", worker.Namespace, "name", worker.Name)
+		if err := r.Create(context.TODO(), worker); err != nil && !apierr.IsAlreadyExists(err) {
+			return nil, err
+		}
+		workers = append(workers, *worker)
+	}
+	r.Recorder.Eventf(job, v1.EventTypeNormal, "Started", "Started %d workers", len(workers))
+	return workers, nil
+}
+
+// propagateWorkerStatus counts the workers by state and sums their progress. The progress of a succeeded worker is
+// the one written to its termination message, the running workers report theirs to the progress config map.
+func (r *BatchInferenceJobReconciler) propagateWorkerStatus(job *v1beta1api.BatchInferenceJob, workers []batchv1.Job) error {
+	job.Status.ActiveWorkers, job.Status.SucceededWorkers, job.Status.FailedWorkers = 0, 0, 0
+	for _, worker := range workers {
+		switch {
+		case isJobFinished(&worker, batchv1.JobComplete):
+			job.Status.SucceededWorkers++
+		case isJobFinished(&worker, batchv1.JobFailed):
+			job.Status.FailedWorkers++
+		default:
+			job.Status.ActiveWorkers++
+		}
+	}
+
+	progresses := map[string]*batchinference.Progress{}
+	progressConfigMap := &v1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: constants.BatchInferenceProgressName(job.Name),
+		Namespace: job.Namespace}, progressConfigMap); err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	for shard := 0; shard < len(workers); shard++ {
+		value, ok := progressConfigMap.Data[batchinference.ProgressKey(shard)]
+		if !ok {
+			continue
+		}
+		progress := &batchinference.Progress{}
+		if err := json.Unmarshal([]byte(value), progress); err != nil {
+			r.Log.Error(err, "Invalid worker progress", "shard", shard)
+			continue
+		}
+		progresses[fmt.Sprint(shard)] = progress
+	}
+
+	pods := &v1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{constants.BatchInferenceJobLabelKey: job.Name}); err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodSucceeded {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != constants.BatchInferenceWorkerContainerName || status.State.Terminated == nil {
+				continue
+			}
+			progress := &batchinference.Progress{}
+			if err := json.Unmarshal([]byte(status.State.Terminated.Message), progress); err != nil {
+				r.Log.Error(err, "Invalid worker progress", "pod", pod.Name)
+				continue
+			}
+			progresses[pod.Labels[constants.BatchInferenceShardLabelKey]] = progress
+		}
+	}
+	job.Status.ProcessedRecords, job.Status.FailedRecords = 0, 0
+	for _, progress := range progresses {
+		job.Status.ProcessedRecords += progress.ProcessedRecords
+		job.Status.FailedRecords += progress.FailedRecords
+	}
+
+	switch {
+	case job.Status.FailedWorkers > 0:
+		job.Status.MarkFailed("WorkerFailed", fmt.Sprintf("%d workers failed", job.Status.FailedWorkers))
+		r.Recorder.Eventf(job, v1.EventTypeWarning, "Failed", "%d workers failed", job.Status.FailedWorkers)
+	case job.Status.SucceededWorkers == int32(len(workers)):
+		now := metav1.Now()
+		job.Status.CompletionTime = &now
+		job.Status.MarkSucceeded()
+		r.Recorder.Eventf(job, v1.EventTypeNormal, "Succeeded", "Processed %d records", job.Status.ProcessedRecords)
+	default:
+		job.Status.MarkRunning("WorkersRunning", fmt.Sprintf("%d of %d workers running", job.Status.ActiveWorkers,
+			len(workers)))
+	}
+	return nil
+}
+
+func isJobFinished(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *BatchInferenceJobReconciler) updateStatus(desired *v1beta1api.BatchInferenceJob) error {
+	existing := &v1beta1api.BatchInferenceJob{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Status, desired.Status) {
+		return nil
+	}
+	if err := r.Status().Update(context.TODO(), desired); err != nil {
+		r.Log.Error(err, "Failed to update BatchInferenceJob status", "BatchInferenceJob", desired.Name)
+		return errors.Wrapf(err, "fails to update BatchInferenceJob status")
+	}
+	return nil
+}
+
+func (r *BatchInferenceJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1api.BatchInferenceJob{}).
+		Owns(&batchv1.Job{}).
+		WithEventFilter(r.Shard.Predicate()).
+		Complete(r)
+}
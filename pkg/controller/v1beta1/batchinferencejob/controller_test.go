@@ -0,0 +1,342 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinferencejob
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	s3credential "github.com/kubeflow/kfserving/pkg/credentials/s3"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestBatchInferenceJobReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	namespace := "default"
+	parallelism := int32(2)
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		Data: map[string]string{
+			BatchInferenceConfigMapKeyName: `{"image": "gcr.io/kfserving/batchinference:latest", "memoryRequest": "100Mi", "memoryLimit": "1Gi", "cpuRequest": "100m", "cpuLimit": "1"}`,
+		},
+	}
+	serviceAccount := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: namespace},
+		Secrets:    []v1.ObjectReference{{Name: "s3-secret"}},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: namespace},
+		Data: map[string][]byte{
+			s3credential.AWSAccessKeyIdName:     []byte("id"),
+			s3credential.AWSSecretAccessKeyName: []byte("key"),
+		},
+	}
+	job := &v1beta1.BatchInferenceJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris-batch", Namespace: namespace},
+		Spec: v1beta1.BatchInferenceJobSpec{
+			InferenceService:   "iris",
+			Input:              "s3://bucket/inputs/",
+			Output:             "s3://bucket/outputs/",
+			Parallelism:        &parallelism,
+			ServiceAccountName: "storage",
+		},
+	}
+	isvc := func(ready bool) *v1beta1.InferenceService {
+		isvc := &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: namespace},
+		}
+		isvc.Status.Address = &duckv1.Addressable{URL: &apis.URL{Scheme: "http", Host: "iris.default.svc.cluster.local"}}
+		if ready {
+			isvc.Status.SetCondition(apis.ConditionReady, &apis.Condition{
+				Type:   apis.ConditionReady,
+				Status: v1.ConditionTrue,
+			})
+		}
+		return isvc
+	}
+	worker := func(shard int, condition batchv1.JobConditionType) *batchv1.Job {
+		worker := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.BatchInferenceWorkerName(job.Name, shard),
+				Namespace: namespace,
+				Labels:    map[string]string{constants.BatchInferenceJobLabelKey: job.Name},
+			},
+		}
+		if condition != "" {
+			worker.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: v1.ConditionTrue}}
+		}
+		return worker
+	}
+	workerPod := func(shard int, message string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.BatchInferenceWorkerName(job.Name, shard) + "-abcde",
+				Namespace: namespace,
+				Labels: map[string]string{
+					constants.BatchInferenceJobLabelKey:   job.Name,
+					constants.BatchInferenceShardLabelKey: fmt.Sprint(shard),
+				},
+			},
+			Status: v1.PodStatus{
+				Phase: v1.PodSucceeded,
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: constants.BatchInferenceWorkerContainerName,
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+	progress := func(data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.BatchInferenceProgressName(job.Name), Namespace: namespace},
+			Data:       data,
+		}
+	}
+
+	scenarios := map[string]struct {
+		objects          []runtime.Object
+		expectedReason   string
+		expectedStatus   v1.ConditionStatus
+		expectedRequeue  bool
+		expectedWorkers  int
+		expectedActive   int32
+		expectedSuccess  int32
+		expectedFailures int32
+		expectedRecords  int64
+		// The created workers get the storage credentials of the service account
+		expectedCredentials bool
+	}{
+		"InferenceServiceNotFound": {
+			expectedReason:  "InferenceServiceNotFound",
+			expectedStatus:  v1.ConditionUnknown,
+			expectedRequeue: true,
+		},
+		"InferenceServiceNotReady": {
+			objects:         []runtime.Object{isvc(false)},
+			expectedReason:  "InferenceServiceNotReady",
+			expectedStatus:  v1.ConditionUnknown,
+			expectedRequeue: true,
+		},
+		"CreateWorkers": {
+			objects:             []runtime.Object{isvc(true)},
+			expectedReason:      "WorkersRunning",
+			expectedStatus:      v1.ConditionUnknown,
+			expectedRequeue:     true,
+			expectedWorkers:     2,
+			expectedActive:      2,
+			expectedCredentials: true,
+		},
+		"WorkerRunning": {
+			objects: []runtime.Object{
+				worker(0, batchv1.JobComplete),
+				worker(1, ""),
+				workerPod(0, `{"files":1,"processedRecords":10,"failedRecords":1}`),
+			},
+			expectedReason:  "WorkersRunning",
+			expectedStatus:  v1.ConditionUnknown,
+			expectedRequeue: true,
+			expectedWorkers: 2,
+			expectedActive:  1,
+			expectedSuccess: 1,
+			expectedRecords: 10,
+		},
+		"WorkerProgress": {
+			objects: []runtime.Object{
+				worker(0, batchv1.JobComplete),
+				worker(1, ""),
+				workerPod(0, `{"files":1,"processedRecords":10,"failedRecords":1}`),
+				// The termination message of the succeeded worker takes precedence over its last reported progress
+				progress(map[string]string{
+					"shard-0": `{"files":0,"processedRecords":8,"failedRecords":1}`,
+					"shard-1": `{"files":1,"processedRecords":7,"failedRecords":0}`,
+				}),
+			},
+			expectedReason:  "WorkersRunning",
+			expectedStatus:  v1.ConditionUnknown,
+			expectedRequeue: true,
+			expectedWorkers: 2,
+			expectedActive:  1,
+			expectedSuccess: 1,
+			expectedRecords: 17,
+		},
+		"WorkersSucceeded": {
+			objects: []runtime.Object{
+				worker(0, batchv1.JobComplete),
+				worker(1, batchv1.JobComplete),
+				workerPod(0, `{"files":1,"processedRecords":10,"failedRecords":1}`),
+				workerPod(1, `{"files":2,"processedRecords":5,"failedRecords":0}`),
+			},
+			expectedStatus:  v1.ConditionTrue,
+			expectedWorkers: 2,
+			expectedSuccess: 2,
+			expectedRecords: 15,
+		},
+		"WorkerFailed": {
+			objects: []runtime.Object{
+				worker(0, batchv1.JobComplete),
+				worker(1, batchv1.JobFailed),
+				workerPod(0, `{"files":1,"processedRecords":10,"failedRecords":1}`),
+			},
+			expectedReason:   "WorkerFailed",
+			expectedStatus:   v1.ConditionFalse,
+			expectedWorkers:  2,
+			expectedSuccess:  1,
+			expectedFailures: 1,
+			expectedRecords:  10,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			objects := append([]runtime.Object{configMap.DeepCopy(), serviceAccount.DeepCopy(), secret.DeepCopy(),
+				job.DeepCopy()}, scenario.objects...)
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := &BatchInferenceJobReconciler{
+				Client:   cl,
+				Log:      logf.Log.WithName("BatchInferenceJob"),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: job.Name, Namespace: namespace}})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(result.RequeueAfter > 0).To(gomega.Equal(scenario.expectedRequeue))
+
+			actual := &v1beta1.BatchInferenceJob{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: namespace}, actual)).To(gomega.Succeed())
+			condition := actual.Status.GetCondition(apis.ConditionSucceeded)
+			g.Expect(condition.Status).To(gomega.Equal(scenario.expectedStatus))
+			g.Expect(condition.Reason).To(gomega.Equal(scenario.expectedReason))
+			g.Expect(actual.Status.ActiveWorkers).To(gomega.Equal(scenario.expectedActive))
+			g.Expect(actual.Status.SucceededWorkers).To(gomega.Equal(scenario.expectedSuccess))
+			g.Expect(actual.Status.FailedWorkers).To(gomega.Equal(scenario.expectedFailures))
+			g.Expect(actual.Status.ProcessedRecords).To(gomega.Equal(scenario.expectedRecords))
+			g.Expect(actual.Status.StartTime != nil).To(gomega.Equal(scenario.expectedWorkers != 0))
+			g.Expect(actual.Status.CompletionTime != nil).To(gomega.Equal(scenario.expectedStatus == v1.ConditionTrue))
+
+			workers := &batchv1.JobList{}
+			g.Expect(cl.List(context.TODO(), workers, client.InNamespace(namespace))).To(gomega.Succeed())
+			g.Expect(workers.Items).To(gomega.HaveLen(scenario.expectedWorkers))
+			if scenario.expectedCredentials {
+				for _, worker := range workers.Items {
+					g.Expect(metav1.IsControlledBy(&worker, actual)).To(gomega.BeTrue())
+					envs := map[string]bool{}
+					for _, env := range worker.Spec.Template.Spec.Containers[0].Env {
+						envs[env.Name] = true
+					}
+					g.Expect(envs).To(gomega.HaveKey(s3credential.AWSAccessKeyId))
+					g.Expect(envs).To(gomega.HaveKey(s3credential.AWSSecretAccessKey))
+				}
+				progressName := types.NamespacedName{Name: constants.BatchInferenceProgressName(job.Name), Namespace: namespace}
+				g.Expect(cl.Get(context.TODO(), progressName, &v1.ConfigMap{})).To(gomega.Succeed())
+				roleBinding := &rbacv1.RoleBinding{}
+				g.Expect(cl.Get(context.TODO(), progressName, roleBinding)).To(gomega.Succeed())
+				g.Expect(roleBinding.Subjects[0].Name).To(gomega.Equal("storage"))
+			}
+		})
+	}
+}
+
+func TestCreateWorker(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	parallelism := int32(3)
+	job := &v1beta1.BatchInferenceJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris-batch", Namespace: "default"},
+		Spec: v1beta1.BatchInferenceJobSpec{
+			InferenceService: "iris",
+			Input:            "gs://bucket/inputs/",
+			Output:           "s3://bucket/outputs/",
+			Parallelism:      &parallelism,
+		},
+	}
+	config := &BatchInferenceConfig{
+		Image:         "gcr.io/kfserving/batchinference:latest",
+		CpuRequest:    "100m",
+		CpuLimit:      "1",
+		MemoryRequest: "100Mi",
+		MemoryLimit:   "1Gi",
+	}
+	worker := createWorker(job, config, "http://iris.default.svc.cluster.local/v1/models/iris:predict", 1)
+	g.Expect(worker.Name).To(gomega.Equal("iris-batch-worker-1"))
+	g.Expect(*worker.Spec.BackoffLimit).To(gomega.Equal(v1beta1.DefaultBatchBackoffLimit))
+	g.Expect(worker.Spec.Template.Labels).To(gomega.Equal(map[string]string{
+		constants.BatchInferenceJobLabelKey:   job.Name,
+		constants.BatchInferenceShardLabelKey: "1",
+	}))
+	g.Expect(worker.Spec.Template.Annotations[IstioInjectAnnotationKey]).To(gomega.Equal("false"))
+	g.Expect(worker.Spec.Template.Spec.RestartPolicy).To(gomega.Equal(v1.RestartPolicyNever))
+	g.Expect(worker.Spec.Template.Spec.Containers[0].Args).To(gomega.Equal([]string{
+		"--input", "gs://bucket/inputs/",
+		"--output", "s3://bucket/outputs/",
+		"--predict-url", "http://iris.default.svc.cluster.local/v1/models/iris:predict",
+		"--shard-index", "1",
+		"--shard-count", "3",
+		"--progress-config-map", "iris-batch-progress",
+	}))
+	g.Expect(worker.Spec.Template.Spec.Containers[0].Env[0].ValueFrom.FieldRef.FieldPath).To(
+		gomega.Equal("metadata.namespace"))
+}
+
+func TestCreateProgress(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	job := &v1beta1.BatchInferenceJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris-batch", Namespace: "default"},
+	}
+	configMap, role, roleBinding := createProgress(job)
+	g.Expect(configMap.Name).To(gomega.Equal("iris-batch-progress"))
+	// The workers can only update the progress config map of their job
+	g.Expect(role.Rules).To(gomega.Equal([]rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{"iris-batch-progress"},
+			Verbs:         []string{"get", "patch"},
+		},
+	}))
+	g.Expect(roleBinding.RoleRef.Name).To(gomega.Equal(role.Name))
+	g.Expect(roleBinding.Subjects).To(gomega.Equal([]rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Name: DefaultServiceAccountName, Namespace: "default"},
+	}))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package batchinferencejob
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	BatchInferenceConfigMapKeyName = "batchInference"
+	// IstioInjectAnnotationKey keeps the sidecar out of the workers, a running sidecar keeps the job from completing
+	IstioInjectAnnotationKey = "sidecar.istio.io/inject"
+	// PodNamespaceEnvVarKey is the env var of the namespace of the progress config map of the worker
+	PodNamespaceEnvVarKey = "POD_NAMESPACE"
+	// DefaultServiceAccountName is the service account of the workers of a job not setting one
+	DefaultServiceAccountName = "default"
+)
+
+type BatchInferenceConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+}
+
+func getBatchInferenceConfig(configMap *v1.ConfigMap) (*BatchInferenceConfig, error) {
+	batchInferenceConfig := &BatchInferenceConfig{}
+	if value, ok := configMap.Data[BatchInferenceConfigMapKeyName]; ok {
+		if err := json.Unmarshal([]byte(value), batchInferenceConfig); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", BatchInferenceConfigMapKeyName, err)
+		}
+	}
+	if batchInferenceConfig.Image == "" {
+		return nil, fmt.Errorf("Invalid %v config, image is required", BatchInferenceConfigMapKeyName)
+	}
+	for _, key := range []string{batchInferenceConfig.MemoryRequest, batchInferenceConfig.MemoryLimit,
+		batchInferenceConfig.CpuRequest, batchInferenceConfig.CpuLimit} {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return nil, fmt.Errorf("Failed to parse resource configuration for %q: %q", BatchInferenceConfigMapKeyName,
+				err.Error())
+		}
+	}
+	return batchInferenceConfig, nil
+}
+
+// getPredictURL returns the URL of the predict endpoint of the inference service on its cluster local address, the
+// transformer serves it when there is one
+func getPredictURL(isvc *v1beta1.InferenceService) (string, error) {
+	endpoint, err := (&predict.Client{ClusterLocal: true}).Resolve(isvc)
+	if err != nil {
+		return "", err
+	}
+	return endpoint.PredictURL().String(), nil
+}
+
+// createProgress creates the config map the running workers report their progress to, with the role and the role
+// binding granting the service account of the workers the update of that config map only
+func createProgress(job *v1beta1.BatchInferenceJob) (*v1.ConfigMap, *rbacv1.Role, *rbacv1.RoleBinding) {
+	name := constants.BatchInferenceProgressName(job.Name)
+	labels := map[string]string{
+		constants.BatchInferenceJobLabelKey: job.Name,
+	}
+	meta := metav1.ObjectMeta{Name: name, Namespace: job.Namespace, Labels: labels}
+	serviceAccountName := job.Spec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = DefaultServiceAccountName
+	}
+	configMap := &v1.ConfigMap{ObjectMeta: *meta.DeepCopy()}
+	role := &rbacv1.Role{
+		ObjectMeta: *meta.DeepCopy(),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{name},
+				Verbs:         []string{"get", "patch"},
+			},
+		},
+	}
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: *meta.DeepCopy(),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccountName,
+				Namespace: job.Namespace,
+			},
+		},
+	}
+	return configMap, role, roleBinding
+}
+
+// createWorker creates the job running the worker of a shard, the credentials of the service account of the job are
+// set by the reconciler
+func createWorker(job *v1beta1.BatchInferenceJob, config *BatchInferenceConfig, predictURL string, shard int) *batchv1.Job {
+	labels := map[string]string{
+		constants.BatchInferenceJobLabelKey:   job.Name,
+		constants.BatchInferenceShardLabelKey: fmt.Sprint(shard),
+	}
+	backoffLimit := job.Spec.GetBackoffLimit()
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.BatchInferenceWorkerName(job.Name, shard),
+			Namespace: job.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					Annotations: map[string]string{
+						IstioInjectAnnotationKey: "false",
+					},
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy:      v1.RestartPolicyNever,
+					ServiceAccountName: job.Spec.ServiceAccountName,
+					Containers: []v1.Container{
+						{
+							Name:  constants.BatchInferenceWorkerContainerName,
+							Image: config.Image,
+							Args: []string{
+								"--input", job.Spec.Input,
+								"--output", job.Spec.Output,
+								"--predict-url", predictURL,
+								"--shard-index", fmt.Sprint(shard),
+								"--shard-count", fmt.Sprint(job.Spec.GetParallelism()),
+								"--progress-config-map", constants.BatchInferenceProgressName(job.Name),
+							},
+							Env: []v1.EnvVar{
+								{
+									Name: PodNamespaceEnvVarKey,
+									ValueFrom: &v1.EnvVarSource{
+										FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+									},
+								},
+							},
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse(config.CpuLimit),
+									v1.ResourceMemory: resource.MustParse(config.MemoryLimit),
+								},
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse(config.CpuRequest),
+									v1.ResourceMemory: resource.MustParse(config.MemoryRequest),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// NewClient creates the client of the manager, it reads the secrets and the config maps from the API server instead of
+// the cache so the controller does not list and watch all the secrets and config maps of the cluster. The pods are read
+// from the cache, its pod informer is shared with the pod watches of the controllers.
+func NewClient(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+	c, err := client.New(config, options)
+	if err != nil {
+		return nil, err
+	}
+	return &client.DelegatingClient{
+		Reader: &uncachedReader{
+			cached:   &client.DelegatingReader{CacheReader: cache, ClientReader: c},
+			uncached: c,
+		},
+		Writer:       c,
+		StatusClient: c,
+	}, nil
+}
+
+// uncachedReader reads the uncached kinds from the API server and the other kinds from the cache
+type uncachedReader struct {
+	cached   client.Reader
+	uncached client.Reader
+}
+
+func (r *uncachedReader) reader(obj runtime.Object) client.Reader {
+	switch obj.(type) {
+	case *v1.Secret, *v1.SecretList, *v1.ConfigMap, *v1.ConfigMapList:
+		return r.uncached
+	}
+	return r.cached
+}
+
+func (r *uncachedReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return r.reader(obj).Get(ctx, key, obj)
+}
+
+func (r *uncachedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return r.reader(list).List(ctx, list, opts...)
+}
+
+// apiKeySecretSource is the source of the events of the API key secrets, its informer only lists and watches the
+// labeled secrets and it is run by the manager
+func apiKeySecretSource(mgr ctrl.Manager) (source.Source, error) {
+	clientSet, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	informer := coreinformers.NewFilteredSecretInformer(clientSet, metav1.NamespaceAll, 0, toolscache.Indexers{},
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = constants.APIKeySecretLabelKey
+		})
+	return informerSource(mgr, informer)
+}
+
+// configMapSource is the source of the events of the inferenceservice-config config maps, its informer only lists and
+// watches the config maps of that name and it is run by the manager
+func configMapSource(mgr ctrl.Manager) (source.Source, error) {
+	clientSet, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	informer := coreinformers.NewFilteredConfigMapInformer(clientSet, metav1.NamespaceAll, 0, toolscache.Indexers{},
+		func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", constants.InferenceServiceConfigMapName).String()
+		})
+	return informerSource(mgr, informer)
+}
+
+// informerSource adds the informer to the runnables of the manager and returns its source
+func informerSource(mgr ctrl.Manager, informer toolscache.SharedIndexInformer) (source.Source, error) {
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		informer.Run(stop)
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+	return &source.Informer{Informer: informer}, nil
+}
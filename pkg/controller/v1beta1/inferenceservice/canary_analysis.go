@@ -0,0 +1,65 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+)
+
+// analyzeCanaries rolls back the canaries whose metrics exceed the thresholds of their canary analysis, the traffic
+// is shifted back to the previous revisions by the component reconcilers. Returns true while a canary is analyzed.
+func (r *InferenceServiceReconciler) analyzeCanaries(isvc *v1beta1api.InferenceService) bool {
+	analyzing := false
+	for _, c := range []struct {
+		componentType v1beta1api.ComponentType
+		component     v1beta1api.Component
+	}{
+		{v1beta1api.PredictorComponent, &isvc.Spec.Predictor},
+		{v1beta1api.TransformerComponent, isvc.Spec.Transformer},
+		{v1beta1api.ExplainerComponent, isvc.Spec.Explainer},
+	} {
+		if reflect.ValueOf(c.component).IsNil() {
+			continue
+		}
+		componentExt := c.component.GetExtensions()
+		analysis := componentExt.CanaryAnalysis
+		status := isvc.Status.Components[c.componentType]
+		if analysis == nil || componentExt.CanaryTrafficPercent == nil || componentExt.RollbackTo != nil ||
+			status.LatestReadyRevision == "" || status.PreviousReadyRevision == "" || status.IsCanaryRolledBack() {
+			continue
+		}
+		analyzing = true
+		selector := fmt.Sprintf(`namespace_name=%q,revision_name=%q`, isvc.Namespace, status.LatestReadyRevision)
+		metrics, err := servingmetrics.QueryMetrics(context.TODO(), r.Querier, selector, analysis.GetInterval())
+		if err != nil {
+			r.Log.Error(err, "Failed to query the metrics of the canary", "isvc", isvc.Name,
+				"component", c.componentType, "revision", status.LatestReadyRevision)
+			continue
+		}
+		if violation := analysis.Violation(metrics.ErrorPercent, metrics.P99LatencyMilliseconds); violation != "" {
+			r.Log.Info("Rolling back canary", "isvc", isvc.Name, "component", c.componentType,
+				"revision", status.LatestReadyRevision, "violation", violation)
+			isvc.Status.MarkCanaryRolledBack(c.componentType, violation)
+		}
+	}
+	return analyzing
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// revisionQuerier answers the queries of the metrics of a revision
+type revisionQuerier struct {
+	revision     string
+	errorPercent float64
+	latency      float64
+}
+
+func (q *revisionQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	if !strings.Contains(query, q.revision) {
+		return 0, false, nil
+	}
+	switch {
+	case strings.Contains(query, "5xx"):
+		return q.errorPercent, true, nil
+	case strings.HasPrefix(query, "histogram_quantile"):
+		return q.latency, true, nil
+	}
+	return 10, true, nil
+}
+
+func TestAnalyzeCanaries(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	canaryStatus := v1beta1api.ComponentStatusSpec{
+		LatestReadyRevision:   "sklearn-predictor-default-00002",
+		PreviousReadyRevision: "sklearn-predictor-default-00001",
+	}
+	analysis := &v1beta1api.CanaryAnalysisSpec{MaxErrorPercent: proto.Int64(5), MaxP99LatencyMilliseconds: proto.Int64(200)}
+	scenarios := map[string]struct {
+		componentExt    v1beta1api.ComponentExtensionSpec
+		componentStatus v1beta1api.ComponentStatusSpec
+		querier         *revisionQuerier
+		analyzing       bool
+		rolledBack      bool
+	}{
+		"HealthyCanary": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10), CanaryAnalysis: analysis},
+			componentStatus: canaryStatus,
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00002", errorPercent: 1, latency: 80},
+			analyzing:       true,
+		},
+		"FailingCanary": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10), CanaryAnalysis: analysis},
+			componentStatus: canaryStatus,
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00002", errorPercent: 20, latency: 80},
+			analyzing:       true,
+			rolledBack:      true,
+		},
+		"SlowCanary": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10), CanaryAnalysis: analysis},
+			componentStatus: canaryStatus,
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00002", errorPercent: 1, latency: 400},
+			analyzing:       true,
+			rolledBack:      true,
+		},
+		"NoCanary": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryAnalysis: analysis},
+			componentStatus: canaryStatus,
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00002", errorPercent: 20},
+		},
+		"FirstRevision": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10), CanaryAnalysis: analysis},
+			componentStatus: v1beta1api.ComponentStatusSpec{LatestReadyRevision: "sklearn-predictor-default-00001"},
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00001", errorPercent: 20},
+		},
+		"NoAnalysis": {
+			componentExt:    v1beta1api.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10)},
+			componentStatus: canaryStatus,
+			querier:         &revisionQuerier{revision: "sklearn-predictor-default-00002", errorPercent: 20},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1api.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+				Spec: v1beta1api.InferenceServiceSpec{
+					Predictor: v1beta1api.PredictorSpec{
+						SKLearn:                &v1beta1api.SKLearnSpec{},
+						ComponentExtensionSpec: scenario.componentExt,
+					},
+				},
+				Status: v1beta1api.InferenceServiceStatus{
+					Components: map[v1beta1api.ComponentType]v1beta1api.ComponentStatusSpec{
+						v1beta1api.PredictorComponent: scenario.componentStatus,
+					},
+				},
+			}
+			r := &InferenceServiceReconciler{Log: logf.Log, Querier: scenario.querier}
+			g.Expect(r.analyzeCanaries(isvc)).To(gomega.Equal(scenario.analyzing))
+			g.Expect(isvc.Status.Components[v1beta1api.PredictorComponent].IsCanaryRolledBack()).
+				To(gomega.Equal(scenario.rolledBack))
+			// the rolled back canary is no longer analyzed
+			if scenario.rolledBack {
+				g.Expect(r.analyzeCanaries(isvc)).To(gomega.BeFalse())
+			}
+		})
+	}
+}
@@ -13,9 +13,302 @@ limitations under the License.
 
 package components
 
-import "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/imagedigest"
+	"github.com/kubeflow/kfserving/pkg/policy"
+	podwebhook "github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 // Component can be reconciled to create underlying resources for an InferenceService
 type Component interface {
 	Reconcile(isvc *v1beta1.InferenceService) error
 }
+
+// propagatePodStatus lists the pods of the component and surfaces the readiness of their sidecar containers and
+// whether they can be scheduled
+func propagatePodStatus(c client.Client, isvc *v1beta1.InferenceService, component v1beta1.ComponentType) error {
+	pods := &v1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(isvc.Namespace), client.MatchingLabels{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+		constants.KServiceComponentLabel:      string(component),
+	}); err != nil {
+		return err
+	}
+	isvc.Status.PropagateSidecarStatus(component, pods.Items)
+	isvc.Status.PropagateSchedulingStatus(component, pods.Items)
+	return nil
+}
+
+// propagateActivationStatus reads the Active condition of the latest ready revision of the component to report whether
+// the component is scaled to zero and when it was last activated
+func propagateActivationStatus(c client.Client, isvc *v1beta1.InferenceService, component v1beta1.ComponentType) error {
+	revisionName := isvc.Status.Components[component].LatestReadyRevision
+	if revisionName == "" {
+		return nil
+	}
+	revision := &knservingv1.Revision{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: revisionName, Namespace: isvc.Namespace}, revision); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	isvc.Status.PropagateActivationStatus(component, revision.Status.GetCondition(v1beta1.RevisionConditionActive))
+	return nil
+}
+
+// setVisibilityLabel sets the Knative visibility label on the component service of a cluster local inference service
+// so Knative keeps it off the external gateway, the visibility labels copied from the inference service are dropped
+// otherwise since spec.visibility takes precedence over them
+func setVisibilityLabel(isvc *v1beta1.InferenceService, labels map[string]string) {
+	delete(labels, constants.VisibilityLabel)
+	delete(labels, constants.NetworkingVisibilityLabel)
+	if isvc.IsClusterLocal() {
+		labels[constants.VisibilityLabel] = constants.VisibilityClusterLocal
+	}
+}
+
+// resolvePriorityClass sets the priority of the priority class of the component on its pod spec, the Priority admission
+// controller does not resolve it since the priority class is set on the pods by the pod mutator. The preemption policy
+// of the priority class applies unless the component sets one. It returns false when the priority class does not exist.
+func resolvePriorityClass(c client.Client, podSpec *v1.PodSpec) (bool, error) {
+	if podSpec.PriorityClassName == "" {
+		return true, nil
+	}
+	priorityClass := &schedulingv1.PriorityClass{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: podSpec.PriorityClassName}, priorityClass); err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	podSpec.Priority = &priorityClass.Value
+	if podSpec.PreemptionPolicy == nil {
+		podSpec.PreemptionPolicy = priorityClass.PreemptionPolicy
+	}
+	return true, nil
+}
+
+// addSchedulingAnnotation moves the node selector, the tolerations, the affinity, the topology spread constraints and the
+// priority of the component out of the pod spec of the knative service since Knative does not allow them in the
+// revision, the pod mutator sets them back on the pods from the annotation. The topology spread constraints without
+// label selector spread the pods of the component.
+func addSchedulingAnnotation(podSpec *v1.PodSpec, objectMeta metav1.ObjectMeta) error {
+	scheduling := v1.PodSpec{
+		NodeSelector:      podSpec.NodeSelector,
+		Tolerations:       podSpec.Tolerations,
+		Affinity:          podSpec.Affinity,
+		PriorityClassName: podSpec.PriorityClassName,
+		Priority:          podSpec.Priority,
+		PreemptionPolicy:  podSpec.PreemptionPolicy,
+	}
+	for i := range podSpec.TopologySpreadConstraints {
+		constraint := podSpec.TopologySpreadConstraints[i].DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					constants.InferenceServicePodLabelKey: objectMeta.Labels[constants.InferenceServicePodLabelKey],
+					constants.KServiceComponentLabel:      objectMeta.Labels[constants.KServiceComponentLabel],
+				},
+			}
+		}
+		scheduling.TopologySpreadConstraints = append(scheduling.TopologySpreadConstraints, *constraint)
+	}
+	podSpec.NodeSelector = nil
+	podSpec.Tolerations = nil
+	podSpec.Affinity = nil
+	podSpec.TopologySpreadConstraints = nil
+	podSpec.PriorityClassName = ""
+	podSpec.Priority = nil
+	podSpec.PreemptionPolicy = nil
+	if len(scheduling.NodeSelector) == 0 && len(scheduling.Tolerations) == 0 && scheduling.Affinity == nil &&
+		len(scheduling.TopologySpreadConstraints) == 0 && scheduling.PriorityClassName == "" &&
+		scheduling.PreemptionPolicy == nil {
+		return nil
+	}
+	schedulingSpec, err := json.Marshal(scheduling)
+	if err != nil {
+		return err
+	}
+	objectMeta.Annotations[constants.SchedulingInternalAnnotationKey] = string(schedulingSpec)
+	return nil
+}
+
+// addVolumesAnnotation moves the volumes Knative does not allow in the revision out of the pod spec of the knative
+// service, with the volume mounts of the containers on them, the pod mutator sets them back on the pods from the
+// annotation. Knative only allows the secret, configMap and projected volumes mounted read only by the containers, the
+// other volumes, e.g. the emptyDir shared memory of Triton or the claim of a feature store, are moved.
+func addVolumesAnnotation(podSpec *v1.PodSpec, objectMeta metav1.ObjectMeta) error {
+	mounted := map[string]bool{}
+	moved := map[string]bool{}
+	for _, container := range podSpec.Containers {
+		for _, mount := range container.VolumeMounts {
+			mounted[mount.Name] = true
+			if !mount.ReadOnly {
+				moved[mount.Name] = true
+			}
+		}
+	}
+	volumes := v1.PodSpec{}
+	var kept []v1.Volume
+	for _, volume := range podSpec.Volumes {
+		if moved[volume.Name] || !mounted[volume.Name] ||
+			(volume.Secret == nil && volume.ConfigMap == nil && volume.Projected == nil) {
+			moved[volume.Name] = true
+			volumes.Volumes = append(volumes.Volumes, volume)
+		} else {
+			kept = append(kept, volume)
+		}
+	}
+	declared := map[string]bool{}
+	for _, volume := range kept {
+		declared[volume.Name] = true
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		var keptMounts, movedMounts []v1.VolumeMount
+		for _, mount := range container.VolumeMounts {
+			if declared[mount.Name] {
+				keptMounts = append(keptMounts, mount)
+			} else {
+				movedMounts = append(movedMounts, mount)
+			}
+		}
+		if len(movedMounts) != 0 {
+			container.VolumeMounts = keptMounts
+			volumes.Containers = append(volumes.Containers, v1.Container{Name: container.Name, VolumeMounts: movedMounts})
+		}
+	}
+	podSpec.Volumes = kept
+	if len(volumes.Volumes) == 0 && len(volumes.Containers) == 0 {
+		return nil
+	}
+	volumesSpec, err := json.Marshal(volumes)
+	if err != nil {
+		return err
+	}
+	objectMeta.Annotations[constants.VolumesInternalAnnotationKey] = string(volumesSpec)
+	return nil
+}
+
+// addResponseCache sets the response cache annotation of the component and the port of the cache on the component
+// container, the cache receives the requests of the component before the other sidecars
+func addResponseCache(responseCache *v1beta1.ResponseCacheSpec, annotations map[string]string,
+	container *v1.Container) error {
+	if responseCache == nil {
+		return nil
+	}
+	cacheConfig, err := json.Marshal(responseCache)
+	if err != nil {
+		return err
+	}
+	annotations[constants.ResponseCacheInternalAnnotationKey] = string(cacheConfig)
+	if len(container.Ports) == 0 {
+		port, _ := strconv.Atoi(constants.InferenceServiceDefaultCachePort)
+		container.Ports = []v1.ContainerPort{
+			{
+				ContainerPort: int32(port),
+			},
+		}
+	}
+	return nil
+}
+
+// resolveImageDigests deploys the images of the pod spec of the component by digest when the registry config resolves
+// the digests, and records the resolved images in the status of the component. The images of the containers the pod
+// webhook injects into the pods with the annotations are resolved with the image pull secrets of the component too,
+// they are recorded by container name and the webhook deploys them by digest from the image digests annotation.
+func resolveImageDigests(c client.Client, isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	podSpec *v1.PodSpec, annotations map[string]string, registryConfig *v1beta1.RegistryConfig) error {
+	delete(annotations, constants.ImageDigestsInternalAnnotationKey)
+	if !registryConfig.ResolveDigests {
+		isvc.Status.SetResolvedImages(component, nil)
+		return nil
+	}
+	previous := isvc.Status.Components[component].ResolvedImages
+	resolver := imagedigest.NewResolver(c)
+	resolvedImages, err := resolver.Resolve(isvc.Namespace, podSpec, previous)
+	if err != nil {
+		return err
+	}
+	configMap := &v1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName,
+		Namespace: constants.KFServingNamespace}, configMap); err != nil {
+		return err
+	}
+	images, err := podwebhook.GetInjectedImages(configMap, annotations)
+	if err != nil {
+		return err
+	}
+	injectedSpec := &v1.PodSpec{
+		ImagePullSecrets:   podSpec.ImagePullSecrets,
+		ServiceAccountName: podSpec.ServiceAccountName,
+	}
+	for name, image := range images {
+		injectedSpec.Containers = append(injectedSpec.Containers, v1.Container{Name: name, Image: image})
+	}
+	injectedImages, err := resolver.Resolve(isvc.Namespace, injectedSpec, previous)
+	if err != nil {
+		return err
+	}
+	if len(injectedImages) != 0 {
+		digests, err := json.Marshal(injectedImages)
+		if err != nil {
+			return err
+		}
+		annotations[constants.ImageDigestsInternalAnnotationKey] = string(digests)
+	}
+	for name, image := range injectedImages {
+		resolvedImages[name] = image
+	}
+	isvc.Status.SetResolvedImages(component, resolvedImages)
+	return nil
+}
+
+// verifyPolicy verifies the model and the images of the component with the policy engines of the policy config and
+// returns whether the component can be rolled out, the knative service of a denied component is not updated so its
+// latest revision keeps serving
+func verifyPolicy(isvc *v1beta1.InferenceService, component v1beta1.ComponentType, podSpec *v1.PodSpec,
+	annotations map[string]string, policyConfig *v1beta1.PolicyConfig) bool {
+	violation := ""
+	if len(policyConfig.Verifiers) != 0 {
+		request := policy.NewRequest(isvc, component,
+			annotations[constants.StorageInitializerSourceUriInternalAnnotationKey],
+			annotations[constants.ModelDigestInternalAnnotationKey], podSpec)
+		violation = policy.NewHook(policyConfig).Verify(request)
+	}
+	isvc.Status.SetPolicyViolation(component, violation)
+	return violation == ""
+}
+
+// addMeshAnnotations sets the Istio sidecar injection of the pods of the component from the component or the mesh
+// config. The injected pods hold their containers until the sidecar is ready and their HTTP probes are redirected
+// through the sidecar, so the sidecars and the probes keep working under strict mutual TLS.
+func addMeshAnnotations(annotations map[string]string, componentExt *v1beta1.ComponentExtensionSpec,
+	meshConfig *v1beta1.MeshConfig) {
+	injection := meshConfig.SidecarInjection
+	if componentExt.SidecarInjection != nil {
+		injection = componentExt.SidecarInjection
+	}
+	if injection == nil {
+		return
+	}
+	annotations[constants.IstioSidecarInjectAnnotationKey] = strconv.FormatBool(*injection)
+	if *injection {
+		annotations[constants.IstioRewriteProbersAnnotationKey] = "true"
+		annotations[constants.IstioProxyConfigAnnotationKey] = constants.IstioHoldApplicationProxyConfig
+	}
+}
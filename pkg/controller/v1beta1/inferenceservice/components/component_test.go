@@ -0,0 +1,272 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/policy"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAddSchedulingAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	objectMeta := metav1.ObjectMeta{
+		Labels: map[string]string{
+			constants.InferenceServicePodLabelKey: "sklearn",
+			constants.KServiceComponentLabel:      "predictor",
+		},
+		Annotations: map[string]string{},
+	}
+	zoneConstraint := v1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+	}
+	priority := int32(1000)
+	preemptionPolicy := v1.PreemptLowerPriority
+	hostConstraint := v1.TopologySpreadConstraint{
+		MaxSkew:           2,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: v1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sklearn"}},
+	}
+	podSpec := &v1.PodSpec{
+		Containers:                []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		NodeSelector:              map[string]string{"disktype": "ssd"},
+		Tolerations:               []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+		Affinity:                  &v1.Affinity{NodeAffinity: &v1.NodeAffinity{}},
+		TopologySpreadConstraints: []v1.TopologySpreadConstraint{zoneConstraint, hostConstraint},
+		PriorityClassName:         "production",
+		Priority:                  &priority,
+		PreemptionPolicy:          &preemptionPolicy,
+	}
+	g.Expect(addSchedulingAnnotation(podSpec, objectMeta)).To(gomega.Succeed())
+	g.Expect(podSpec).To(gomega.Equal(&v1.PodSpec{
+		Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+	}))
+
+	scheduling := v1.PodSpec{}
+	g.Expect(json.Unmarshal([]byte(objectMeta.Annotations[constants.SchedulingInternalAnnotationKey]), &scheduling)).
+		To(gomega.Succeed())
+	g.Expect(scheduling.NodeSelector).To(gomega.Equal(map[string]string{"disktype": "ssd"}))
+	g.Expect(scheduling.Tolerations).To(gomega.Equal([]v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}}))
+	g.Expect(scheduling.Affinity).To(gomega.Equal(&v1.Affinity{NodeAffinity: &v1.NodeAffinity{}}))
+	zoneConstraint.LabelSelector = &metav1.LabelSelector{MatchLabels: map[string]string{
+		constants.InferenceServicePodLabelKey: "sklearn",
+		constants.KServiceComponentLabel:      "predictor",
+	}}
+	g.Expect(scheduling.TopologySpreadConstraints).To(gomega.Equal([]v1.TopologySpreadConstraint{zoneConstraint, hostConstraint}))
+	g.Expect(scheduling.PriorityClassName).To(gomega.Equal("production"))
+	g.Expect(scheduling.Priority).To(gomega.Equal(&priority))
+	g.Expect(scheduling.PreemptionPolicy).To(gomega.Equal(&preemptionPolicy))
+
+	unscheduled := &v1.PodSpec{Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}}}
+	annotations := map[string]string{}
+	g.Expect(addSchedulingAnnotation(unscheduled, metav1.ObjectMeta{Annotations: annotations})).To(gomega.Succeed())
+	g.Expect(annotations).To(gomega.BeEmpty())
+}
+
+func TestAddVolumesAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := v1.Volume{Name: "config", VolumeSource: v1.VolumeSource{
+		ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "triton-config"}}}}
+	shm := v1.Volume{Name: "dshm", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory}}}
+	credentials := v1.Volume{Name: "credentials", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "features"}}}
+	unmounted := v1.Volume{Name: "unmounted", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "unmounted"}}}
+	configMount := v1.VolumeMount{Name: "config", MountPath: "/etc/triton", ReadOnly: true}
+	shmMount := v1.VolumeMount{Name: "dshm", MountPath: "/dev/shm"}
+	credentialsMount := v1.VolumeMount{Name: "credentials", MountPath: "/var/run/credentials"}
+	modelMount := v1.VolumeMount{Name: "kfserving-provision-location", MountPath: "/mnt/triton", ReadOnly: true}
+	annotations := map[string]string{}
+	podSpec := &v1.PodSpec{
+		Containers: []v1.Container{{
+			Name:         constants.InferenceServiceContainerName,
+			VolumeMounts: []v1.VolumeMount{configMount, shmMount, credentialsMount, modelMount},
+		}},
+		Volumes: []v1.Volume{config, shm, credentials, unmounted},
+	}
+	g.Expect(addVolumesAnnotation(podSpec, metav1.ObjectMeta{Annotations: annotations})).To(gomega.Succeed())
+	g.Expect(podSpec).To(gomega.Equal(&v1.PodSpec{
+		Containers: []v1.Container{{
+			Name:         constants.InferenceServiceContainerName,
+			VolumeMounts: []v1.VolumeMount{configMount},
+		}},
+		Volumes: []v1.Volume{config},
+	}))
+
+	volumes := v1.PodSpec{}
+	g.Expect(json.Unmarshal([]byte(annotations[constants.VolumesInternalAnnotationKey]), &volumes)).To(gomega.Succeed())
+	g.Expect(volumes.Volumes).To(gomega.Equal([]v1.Volume{shm, credentials, unmounted}))
+	g.Expect(volumes.Containers).To(gomega.Equal([]v1.Container{{
+		Name:         constants.InferenceServiceContainerName,
+		VolumeMounts: []v1.VolumeMount{shmMount, credentialsMount, modelMount},
+	}}))
+
+	allowed := &v1.PodSpec{
+		Containers: []v1.Container{{Name: constants.InferenceServiceContainerName, VolumeMounts: []v1.VolumeMount{configMount}}},
+		Volumes:    []v1.Volume{config},
+	}
+	annotations = map[string]string{}
+	g.Expect(addVolumesAnnotation(allowed, metav1.ObjectMeta{Annotations: annotations})).To(gomega.Succeed())
+	g.Expect(allowed.Volumes).To(gomega.Equal([]v1.Volume{config}))
+	g.Expect(annotations).To(gomega.BeEmpty())
+}
+
+func TestAddResponseCache(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	annotations := map[string]string{}
+	container := &v1.Container{Name: constants.InferenceServiceContainerName}
+	g.Expect(addResponseCache(nil, annotations, container)).To(gomega.Succeed())
+	g.Expect(annotations).To(gomega.BeEmpty())
+	g.Expect(container.Ports).To(gomega.BeEmpty())
+
+	ttl := 60
+	responseCache := &v1beta1.ResponseCacheSpec{Backend: v1beta1.ResponseCacheRedisBackend, RedisAddress: "redis:6379",
+		TTLSeconds: &ttl}
+	g.Expect(addResponseCache(responseCache, annotations, container)).To(gomega.Succeed())
+	g.Expect(annotations).To(gomega.Equal(map[string]string{
+		constants.ResponseCacheInternalAnnotationKey: `{"backend":"redis","redisAddress":"redis:6379","ttlSeconds":60}`,
+	}))
+	g.Expect(container.Ports).To(gomega.Equal([]v1.ContainerPort{{ContainerPort: 9086}}))
+}
+
+func TestAddMeshAnnotations(t *testing.T) {
+	enabled, disabled := true, false
+	injected := map[string]string{
+		constants.IstioSidecarInjectAnnotationKey:  "true",
+		constants.IstioRewriteProbersAnnotationKey: "true",
+		constants.IstioProxyConfigAnnotationKey:    constants.IstioHoldApplicationProxyConfig,
+	}
+	scenarios := map[string]struct {
+		componentInjection *bool
+		configInjection    *bool
+		expected           map[string]string
+	}{
+		"Unset": {
+			expected: map[string]string{},
+		},
+		"ConfigInjection": {
+			configInjection: &enabled,
+			expected:        injected,
+		},
+		"ComponentInjection": {
+			componentInjection: &enabled,
+			configInjection:    &disabled,
+			expected:           injected,
+		},
+		"ComponentOptOut": {
+			componentInjection: &disabled,
+			configInjection:    &enabled,
+			expected:           map[string]string{constants.IstioSidecarInjectAnnotationKey: "false"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			annotations := map[string]string{}
+			addMeshAnnotations(annotations, &v1beta1.ComponentExtensionSpec{SidecarInjection: scenario.componentInjection},
+				&v1beta1.MeshConfig{SidecarInjection: scenario.configInjection})
+			g.Expect(annotations).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestResolvePriorityClass(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	never := v1.PreemptNever
+	preemptLowerPriority := v1.PreemptLowerPriority
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&schedulingv1.PriorityClass{
+			ObjectMeta:       metav1.ObjectMeta{Name: "batch"},
+			Value:            100,
+			PreemptionPolicy: &never,
+		},
+	)
+
+	podSpec := &v1.PodSpec{PriorityClassName: "batch"}
+	found, err := resolvePriorityClass(cl, podSpec)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(*podSpec.Priority).To(gomega.Equal(int32(100)))
+	g.Expect(podSpec.PreemptionPolicy).To(gomega.Equal(&never))
+
+	// The preemption policy of the component takes precedence over the one of the priority class
+	podSpec = &v1.PodSpec{PriorityClassName: "batch", PreemptionPolicy: &preemptLowerPriority}
+	found, err = resolvePriorityClass(cl, podSpec)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(podSpec.PreemptionPolicy).To(gomega.Equal(&preemptLowerPriority))
+
+	podSpec = &v1.PodSpec{PriorityClassName: "production"}
+	found, err = resolvePriorityClass(cl, podSpec)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(found).To(gomega.BeFalse())
+
+	podSpec = &v1.PodSpec{}
+	found, err = resolvePriorityClass(cl, podSpec)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(podSpec.Priority).To(gomega.BeNil())
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	modelDigest := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			Input policy.Request `json:"input"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		modelDigest = body.Input.ModelDigest
+		w.Write([]byte(`{"allowed": false, "reason": "model is not pinned"}`))
+	}))
+	defer server.Close()
+	isvc := &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"}}
+	podSpec := &v1.PodSpec{Containers: []v1.Container{{Name: "kfserving-container", Image: "kfserving/sklearnserver"}}}
+	annotations := map[string]string{constants.StorageInitializerSourceUriInternalAnnotationKey: "gs://models/iris"}
+
+	g.Expect(verifyPolicy(isvc, v1beta1.PredictorComponent, podSpec, annotations, &v1beta1.PolicyConfig{})).To(gomega.BeTrue())
+	g.Expect(isvc.Status.GetCondition(v1beta1.PolicyViolation)).To(gomega.BeNil())
+
+	policyConfig := &v1beta1.PolicyConfig{
+		Verifiers: []v1beta1.PolicyVerifierConfig{{Name: "opa", URL: server.URL}},
+	}
+	g.Expect(verifyPolicy(isvc, v1beta1.PredictorComponent, podSpec, annotations, policyConfig)).To(gomega.BeFalse())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].PolicyViolation).To(gomega.Equal(
+		"model gs://models/iris has no digest, set the modelDigest of the component, opa: model is not pinned"))
+	g.Expect(isvc.Status.GetCondition(v1beta1.PolicyViolation).Reason).To(gomega.Equal(v1beta1.PolicyVerificationFailed))
+
+	// The declared model digest is sent to the verifiers
+	annotations[constants.ModelDigestInternalAnnotationKey] = "sha256:9c2a"
+	g.Expect(verifyPolicy(isvc, v1beta1.PredictorComponent, podSpec, annotations, policyConfig)).To(gomega.BeFalse())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].PolicyViolation).To(
+		gomega.Equal("opa: model is not pinned"))
+	g.Expect(modelDigest).To(gomega.Equal("sha256:9c2a"))
+
+	// The violation is cleared once the verifiers are removed
+	g.Expect(verifyPolicy(isvc, v1beta1.PredictorComponent, podSpec, annotations, &v1beta1.PolicyConfig{})).To(gomega.BeTrue())
+	g.Expect(isvc.Status.GetCondition(v1beta1.PolicyViolation)).To(gomega.BeNil())
+}
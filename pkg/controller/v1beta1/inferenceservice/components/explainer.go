@@ -15,8 +15,12 @@ package components
 
 import (
 	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/audit"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/envoyfilter"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/keda"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/pdb"
 	"github.com/kubeflow/kfserving/pkg/credentials"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
@@ -38,14 +42,17 @@ type Explainer struct {
 	scheme                 *runtime.Scheme
 	inferenceServiceConfig *v1beta1.InferenceServicesConfig
 	credentialBuilder      *credentials.CredentialBuilder
+	mutations              *audit.Mutations
 	Log                    logr.Logger
 }
 
-func NewExplainer(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+func NewExplainer(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig,
+	mutations *audit.Mutations) Component {
 	return &Explainer{
 		client:                 client,
 		scheme:                 scheme,
 		inferenceServiceConfig: inferenceServiceConfig,
+		mutations:              mutations,
 		Log:                    ctrl.Log.WithName("ExplainerReconciler"),
 	}
 }
@@ -57,10 +64,14 @@ func (p *Explainer) Reconcile(isvc *v1beta1.InferenceService) error {
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
 		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
 	})
+	addMeshAnnotations(annotations, &isvc.Spec.Explainer.ComponentExtensionSpec, &p.inferenceServiceConfig.Mesh)
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := explainer.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
+		if modelDigest := isvc.Spec.Explainer.ModelDigest; modelDigest != nil {
+			annotations[constants.ModelDigestInternalAnnotationKey] = *modelDigest
+		}
 	}
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultExplainerServiceName(isvc.Name),
@@ -71,21 +82,63 @@ func (p *Explainer) Reconcile(isvc *v1beta1.InferenceService) error {
 		}),
 		Annotations: annotations,
 	}
+	setVisibilityLabel(isvc, objectMeta.Labels)
 	if len(isvc.Spec.Explainer.PodSpec.Containers) == 0 {
 		container := explainer.GetContainer(isvc.ObjectMeta, isvc.Spec.Explainer.GetExtensions(), p.inferenceServiceConfig)
-		isvc.Spec.Explainer.PodSpec = v1beta1.PodSpec{
-			Containers: []v1.Container{
-				*container,
-			},
+		isvc.Spec.Explainer.PodSpec.Containers = []v1.Container{
+			*container,
 		}
 	} else {
 		container := explainer.GetContainer(isvc.ObjectMeta, isvc.Spec.Explainer.GetExtensions(), p.inferenceServiceConfig)
 		isvc.Spec.Explainer.PodSpec.Containers[0] = *container
 	}
+	if err := addResponseCache(isvc.Spec.Explainer.ResponseCache, annotations,
+		&isvc.Spec.Explainer.PodSpec.Containers[0]); err != nil {
+		return errors.Wrapf(err, "fails to marshal response cache for explainer")
+	}
 
+	// The replica bounds of the active scaling window apply to the KEDA ScaledObject and the knative service
+	componentExt := isvc.Spec.Explainer.ComponentExtensionSpec.WithKeda(
+		p.inferenceServiceConfig.Autoscaling.KedaEnabled).WithScalingSchedule(
+		isvc.Status.Components[v1beta1.ExplainerComponent].ScalingSchedule)
+	// The ScaledObject is reconciled first to be deleted while the knative service is still scaled by KEDA
+	if err := keda.NewScaledObjectReconciler(p.client, p.scheme, isvc, objectMeta,
+		componentExt).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile KEDA ScaledObject for explainer")
+	}
+	if err := envoyfilter.NewEnvoyFilterReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Explainer.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile limits EnvoyFilter for explainer")
+	}
+	if err := pdb.NewPodDisruptionBudgetReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Explainer.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile PodDisruptionBudget for explainer")
+	}
 	podSpec := v1.PodSpec(isvc.Spec.Explainer.PodSpec)
-	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Explainer.ComponentExtensionSpec,
+	podSpec.ImagePullSecrets = utils.MergeImagePullSecrets(podSpec.ImagePullSecrets,
+		p.inferenceServiceConfig.Registry.ImagePullSecrets)
+	if err := resolveImageDigests(p.client, isvc, v1beta1.ExplainerComponent, &podSpec, annotations,
+		&p.inferenceServiceConfig.Registry); err != nil {
+		return errors.Wrapf(err, "fails to resolve image digests for explainer")
+	}
+	if !verifyPolicy(isvc, v1beta1.ExplainerComponent, &podSpec, annotations, &p.inferenceServiceConfig.Policy) {
+		return nil
+	}
+	if found, err := resolvePriorityClass(p.client, &podSpec); err != nil {
+		return errors.Wrapf(err, "fails to get priority class for explainer")
+	} else if !found {
+		isvc.Status.MarkPriorityClassNotFound(v1beta1.ExplainerComponent, podSpec.PriorityClassName)
+		return nil
+	}
+	if err := addSchedulingAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal scheduling for explainer")
+	}
+	if err := addVolumesAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal volumes for explainer")
+	}
+	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, componentExt,
 		&podSpec, isvc.Status.Components[v1beta1.ExplainerComponent])
+	r.Mutations = p.mutations
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
 		return errors.Wrapf(err, "fails to set owner reference for explainer")
@@ -95,5 +148,12 @@ func (p *Explainer) Reconcile(isvc *v1beta1.InferenceService) error {
 		return errors.Wrapf(err, "fails to reconcile explainer")
 	}
 	isvc.Status.PropagateStatus(v1beta1.ExplainerComponent, status)
+	isvc.Status.PropagateRolloutNotes(v1beta1.ExplainerComponent, r.RolloutNotes)
+	if err := propagatePodStatus(p.client, isvc, v1beta1.ExplainerComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate pod status for explainer")
+	}
+	if err := propagateActivationStatus(p.client, isvc, v1beta1.ExplainerComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate activation status for explainer")
+	}
 	return nil
 }
@@ -14,15 +14,29 @@ limitations under the License.
 package components
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+
 	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/async"
+	"github.com/kubeflow/kfserving/pkg/audit"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/envoyfilter"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/keda"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/pdb"
 	"github.com/kubeflow/kfserving/pkg/credentials"
 	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/kubeflow/kfserving/pkg/warmup"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -39,14 +53,17 @@ type Predictor struct {
 	scheme                 *runtime.Scheme
 	inferenceServiceConfig *v1beta1.InferenceServicesConfig
 	credentialBuilder      *credentials.CredentialBuilder
+	mutations              *audit.Mutations
 	Log                    logr.Logger
 }
 
-func NewPredictor(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+func NewPredictor(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig,
+	mutations *audit.Mutations) Component {
 	return &Predictor{
 		client:                 client,
 		scheme:                 scheme,
 		inferenceServiceConfig: inferenceServiceConfig,
+		mutations:              mutations,
 		Log:                    ctrl.Log.WithName("PredictorReconciler"),
 	}
 }
@@ -58,14 +75,118 @@ func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
 		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
 	})
+	addMeshAnnotations(annotations, &isvc.Spec.Predictor.ComponentExtensionSpec, &p.inferenceServiceConfig.Mesh)
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := predictor.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
+		if modelDigest := isvc.Spec.Predictor.ModelDigest; modelDigest != nil {
+			annotations[constants.ModelDigestInternalAnnotationKey] = *modelDigest
+		}
+	}
+	// The StorageInitializer arranges the TorchServe model store and archives the model with the handler
+	if torchserve := isvc.Spec.Predictor.PyTorch; torchserve != nil {
+		annotations[constants.TorchServeHandlerInternalAnnotationKey] = torchserve.ModelClassName
+	}
+	// The model conversion runs in an init container injected after the StorageInitializer
+	if conversion := isvc.Spec.Predictor.ModelConversion; conversion != nil {
+		conversionSpec, err := json.Marshal(conversion)
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal model conversion for predictor")
+		}
+		annotations[constants.ModelConversionInternalAnnotationKey] = string(conversionSpec)
 	}
 	hasInferenceLogging := addLoggerAnnotations(isvc.Spec.Predictor.Logger, annotations)
+	if hasInferenceLogging {
+		addExplainerSamplingAnnotations(isvc, annotations)
+	}
 	hasInferenceBatcher := addBatcherAnnotations(isvc.Spec.Predictor.Batcher, annotations)
 
+	var container *v1.Container
+	var runtimeSidecars []v1.Container
+	if model := isvc.Spec.Predictor.Model; model != nil {
+		runtimeName, servingRuntime, err := getServingRuntime(p.client, isvc.Namespace, model)
+		if noRuntime, ok := err.(*NoServingRuntimeError); ok {
+			isvc.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{
+				Type:    v1beta1.PredictorReady,
+				Status:  v1.ConditionFalse,
+				Reason:  v1beta1.NoSupportingRuntime,
+				Message: noRuntime.Error(),
+			})
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "fails to select serving runtime for predictor")
+		}
+		if container, err = model.GetRuntimeContainer(isvc.ObjectMeta, servingRuntime); err != nil {
+			return errors.Wrapf(err, "fails to create container from serving runtime %s", runtimeName)
+		}
+		runtimeSidecars = servingRuntime.Containers[1:]
+		annotations[constants.ServingRuntimeAnnotationKey] = runtimeName
+	} else {
+		container = predictor.GetContainer(isvc.ObjectMeta, isvc.Spec.Predictor.GetExtensions(), p.inferenceServiceConfig)
+	}
+	// The redeployed version overrides the model, the image and the resources of the predictor
+	if deployVersion := isvc.Spec.Predictor.DeployVersion; deployVersion != nil {
+		modelVersion := isvc.Status.GetModelVersion(*deployVersion)
+		if modelVersion == nil {
+			isvc.Status.MarkModelVersionNotFound(*deployVersion)
+			return nil
+		}
+		applyModelVersion(modelVersion, container, annotations)
+	}
+	// The revision only receives traffic once the model ready endpoint passes. Knative does not allow startup probes,
+	// the startup probe holding the liveness probe while the model loads is set by the pod mutator.
+	if modelReadiness := isvc.Spec.Predictor.ModelReadiness; modelReadiness != nil {
+		path := modelReadiness.GetPath(isvc.Name, isvc.Spec.Predictor.GetProtocol())
+		container.ReadinessProbe = modelReadiness.ReadinessProbe(path)
+		startupProbe, err := json.Marshal(modelReadiness.StartupProbe(path))
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal startup probe for predictor")
+		}
+		annotations[constants.StartupProbeInternalAnnotationKey] = string(startupProbe)
+	}
+	// The warmup sidecar replays the requests once the model is loaded and gates the readiness of the pods
+	if isvc.Spec.Predictor.Warmup != nil {
+		warmupConfig, err := json.Marshal(newWarmupConfig(isvc))
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal warmup for predictor")
+		}
+		annotations[constants.WarmupInternalAnnotationKey] = string(warmupConfig)
+	}
+	// The logger and the batcher pass the streamed responses through instead of reading them whole
+	if isvc.Spec.Predictor.IsStreaming() {
+		annotations[constants.StreamingInternalAnnotationKey] = "true"
+	}
+	// The async frontend queues the requests of the predictor and sends them to the model server
+	if isvc.Spec.Predictor.Async != nil {
+		asyncConfig, err := json.Marshal(newAsyncConfig(isvc))
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal async for predictor")
+		}
+		annotations[constants.AsyncInternalAnnotationKey] = string(asyncConfig)
+	}
+	// The request validator rejects the malformed requests before the logger, the batcher and the model server
+	if isvc.Spec.Predictor.RequestValidation != nil {
+		validationConfig, err := json.Marshal(isvc.Spec.Predictor.RequestValidation)
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal request validation for predictor")
+		}
+		annotations[constants.RequestValidationInternalAnnotationKey] = string(validationConfig)
+	}
+	// KNative only runs the model server, the sidecars and the init containers are added to the pods by the pod
+	// mutator, the init containers after the StorageInitializer
+	if len(isvc.Spec.Predictor.Sidecars) != 0 || len(isvc.Spec.Predictor.InitContainers) != 0 {
+		sidecars, err := json.Marshal(&v1.PodSpec{
+			Containers:     isvc.Spec.Predictor.Sidecars,
+			InitContainers: isvc.Spec.Predictor.InitContainers,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "fails to marshal sidecars for predictor")
+		}
+		annotations[constants.SidecarsInternalAnnotationKey] = string(sidecars)
+	}
+
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultPredictorServiceName(isvc.Name),
 		Namespace: isvc.Namespace,
@@ -75,18 +196,28 @@ func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 		}),
 		Annotations: annotations,
 	}
-	container := predictor.GetContainer(isvc.ObjectMeta, isvc.Spec.Predictor.GetExtensions(), p.inferenceServiceConfig)
+	setVisibilityLabel(isvc, objectMeta.Labels)
 	if len(isvc.Spec.Predictor.PodSpec.Containers) == 0 {
-		isvc.Spec.Predictor.PodSpec = v1beta1.PodSpec{
-			Containers: []v1.Container{
-				*container,
-			},
+		isvc.Spec.Predictor.PodSpec.Containers = []v1.Container{
+			*container,
 		}
 	} else {
 		isvc.Spec.Predictor.PodSpec.Containers[0] = *container
 	}
+	isvc.Spec.Predictor.PodSpec.Containers = append(isvc.Spec.Predictor.PodSpec.Containers, runtimeSidecars...)
 	//TODO now knative supports multi containers, consolidate logger/batcher/puller to the sidecar container
 	//https://github.com/kubeflow/kfserving/issues/973
+	// The cache receives the requests first and sends the requests it does not answer to the validator, the logger or
+	// the batcher
+	if err := addResponseCache(isvc.Spec.Predictor.ResponseCache, annotations,
+		&isvc.Spec.Predictor.PodSpec.Containers[0]); err != nil {
+		return errors.Wrapf(err, "fails to marshal response cache for predictor")
+	}
+	// The validator receives the requests before the logger and the batcher
+	if isvc.Spec.Predictor.RequestValidation != nil {
+		addValidatorContainerPort(&isvc.Spec.Predictor.PodSpec.Containers[0])
+	}
+
 	if hasInferenceLogging {
 		addLoggerContainerPort(&isvc.Spec.Predictor.PodSpec.Containers[0])
 	}
@@ -95,9 +226,58 @@ func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 		addBatcherContainerPort(&isvc.Spec.Predictor.PodSpec.Containers[0])
 	}
 
+	if isvc.Spec.Predictor.Async != nil {
+		addAsyncContainerPort(&isvc.Spec.Predictor.PodSpec.Containers[0])
+	}
+
+	// The replica bounds of the active scaling window apply to the KEDA ScaledObject and the knative service
+	componentExt := isvc.Spec.Predictor.ComponentExtensionSpec.WithAsyncScaling(isvc.Spec.Predictor.Async,
+		constants.AsyncQueueName(isvc.Namespace, isvc.Name)).WithKeda(
+		p.inferenceServiceConfig.Autoscaling.KedaEnabled).WithScalingSchedule(
+		isvc.Status.Components[v1beta1.PredictorComponent].ScalingSchedule)
+	// The ScaledObject is reconciled first to be deleted while the knative service is still scaled by KEDA
+	if err := keda.NewScaledObjectReconciler(p.client, p.scheme, isvc, objectMeta,
+		componentExt).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile KEDA ScaledObject for predictor")
+	}
+	if err := envoyfilter.NewEnvoyFilterReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Predictor.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile limits EnvoyFilter for predictor")
+	}
+	if err := pdb.NewPodDisruptionBudgetReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Predictor.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile PodDisruptionBudget for predictor")
+	}
 	podSpec := v1.PodSpec(isvc.Spec.Predictor.PodSpec)
-	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Predictor.ComponentExtensionSpec,
+	podSpec.ImagePullSecrets = utils.MergeImagePullSecrets(podSpec.ImagePullSecrets,
+		p.inferenceServiceConfig.Registry.ImagePullSecrets)
+	if err := resolveImageDigests(p.client, isvc, v1beta1.PredictorComponent, &podSpec, annotations,
+		&p.inferenceServiceConfig.Registry); err != nil {
+		return errors.Wrapf(err, "fails to resolve image digests for predictor")
+	}
+	if !verifyPolicy(isvc, v1beta1.PredictorComponent, &podSpec, annotations, &p.inferenceServiceConfig.Policy) {
+		return nil
+	}
+	podSpec.InitContainers = nil
+	if found, err := resolvePriorityClass(p.client, &podSpec); err != nil {
+		return errors.Wrapf(err, "fails to get priority class for predictor")
+	} else if !found {
+		isvc.Status.MarkPriorityClassNotFound(v1beta1.PredictorComponent, podSpec.PriorityClassName)
+		return nil
+	}
+	if err := addSchedulingAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal scheduling for predictor")
+	}
+	if err := addVolumesAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal volumes for predictor")
+	}
+	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, componentExt,
 		&podSpec, isvc.Status.Components[v1beta1.PredictorComponent])
+	r.Mutations = p.mutations
+	// The rollouts are only gated when the predictor sets a rollout
+	if isvc.Spec.Predictor.Rollout != nil {
+		r.ProgressDeadline = isvc.Spec.Predictor.Rollout.GetProgressDeadline()
+	}
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
 		return errors.Wrapf(err, "fails to set owner reference for predictor")
@@ -107,6 +287,125 @@ func (p *Predictor) Reconcile(isvc *v1beta1.InferenceService) error {
 		return errors.Wrapf(err, "fails to reconcile predictor")
 	}
 	isvc.Status.PropagateStatus(v1beta1.PredictorComponent, status)
+	isvc.Status.PropagateRolloutNotes(v1beta1.PredictorComponent, r.RolloutNotes)
+	isvc.Status.PropagateRolloutStatus(v1beta1.PredictorComponent, r.ServingRevision, r.RolloutRevision,
+		r.RolloutAborted)
+	if err := propagatePodStatus(p.client, isvc, v1beta1.PredictorComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate pod status for predictor")
+	}
+	if err := propagateActivationStatus(p.client, isvc, v1beta1.PredictorComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate activation status for predictor")
+	}
+	if err := propagateModelVersion(p.client, isvc); err != nil {
+		return errors.Wrapf(err, "fails to propagate model version for predictor")
+	}
+	return nil
+}
+
+// newWarmupConfig returns the configuration of the warmup sidecar, the requests without path are sent to the predict
+// endpoint of the protocol of the predictor
+func newWarmupConfig(isvc *v1beta1.InferenceService) *warmup.Config {
+	spec := isvc.Spec.Predictor.Warmup
+	protocol := isvc.Spec.Predictor.GetProtocol()
+	modelReadiness := isvc.Spec.Predictor.ModelReadiness
+	if modelReadiness == nil {
+		modelReadiness = &v1beta1.ModelReadinessSpec{}
+	}
+	config := &warmup.Config{
+		ReadyPath:      modelReadiness.GetPath(isvc.Name, protocol),
+		DefaultPath:    constants.PredictPath(isvc.Name),
+		Iterations:     spec.GetIterations(),
+		TimeoutSeconds: spec.GetTimeoutSeconds(),
+	}
+	if protocol == constants.ProtocolV2 {
+		config.DefaultPath = constants.InferPathV2(isvc.Name)
+	}
+	if spec.StorageURI != nil {
+		config.StorageURI = *spec.StorageURI
+	}
+	for _, request := range spec.Requests {
+		path := request.Path
+		if path == "" {
+			path = config.DefaultPath
+		}
+		config.Requests = append(config.Requests, warmup.Request{Path: path, Body: request.Body.Raw})
+	}
+	return config
+}
+
+// newAsyncConfig returns the configuration of the async frontend, the redis queue of an inference service is shared
+// by its replicas. The queued requests and the results are bounded by the request and response limits of the
+// predictor.
+func newAsyncConfig(isvc *v1beta1.InferenceService) *async.Config {
+	spec := isvc.Spec.Predictor.Async
+	config := &async.Config{
+		Backend:          string(spec.GetQueue()),
+		RedisAddress:     spec.RedisAddress,
+		QueueName:        constants.AsyncQueueName(isvc.Namespace, isvc.Name),
+		Workers:          spec.GetWorkers(),
+		TimeoutSeconds:   spec.GetTimeoutSeconds(),
+		ResultTTLSeconds: spec.GetResultTTLSeconds(),
+		CallbackHosts:    spec.CallbackHosts,
+	}
+	if spec.MaxQueueDepth != nil {
+		config.MaxQueueDepth = *spec.MaxQueueDepth
+	}
+	if isvc.Spec.Predictor.MaxRequestBytes != nil {
+		config.MaxRequestBytes = *isvc.Spec.Predictor.MaxRequestBytes
+	}
+	if isvc.Spec.Predictor.MaxResponseBytes != nil {
+		config.MaxResponseBytes = *isvc.Spec.Predictor.MaxResponseBytes
+	}
+	return config
+}
+
+// applyModelVersion sets the model, the image and the resources of a model version on the predictor
+func applyModelVersion(modelVersion *v1beta1.ModelVersion, container *v1.Container, annotations map[string]string) {
+	if modelVersion.StorageUri != "" {
+		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = modelVersion.StorageUri
+	} else {
+		delete(annotations, constants.StorageInitializerSourceUriInternalAnnotationKey)
+	}
+	if modelVersion.ModelDigest != "" {
+		annotations[constants.ModelDigestInternalAnnotationKey] = modelVersion.ModelDigest
+	} else {
+		delete(annotations, constants.ModelDigestInternalAnnotationKey)
+	}
+	if modelVersion.Image != "" {
+		container.Image = modelVersion.Image
+	}
+	container.Resources = *modelVersion.Resources.DeepCopy()
+}
+
+// propagateModelVersion records the model deployed by the latest ready revision of the predictor in the model versions
+func propagateModelVersion(c client.Client, isvc *v1beta1.InferenceService) error {
+	revisionName := isvc.Status.Components[v1beta1.PredictorComponent].LatestReadyRevision
+	if revisionName == "" || len(isvc.Status.ModelVersions) != 0 && isvc.Status.ModelVersions[0].Revision == revisionName {
+		return nil
+	}
+	revision := &knservingv1.Revision{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: revisionName, Namespace: isvc.Namespace}, revision); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	modelVersion := v1beta1.ModelVersion{
+		Revision:    revisionName,
+		StorageUri:  revision.Annotations[constants.StorageInitializerSourceUriInternalAnnotationKey],
+		ModelDigest: revision.Annotations[constants.ModelDigestInternalAnnotationKey],
+	}
+	for _, container := range revision.Spec.Containers {
+		if container.Name == constants.InferenceServiceContainerName {
+			modelVersion.Image = container.Image
+			modelVersion.Resources = container.Resources
+		}
+	}
+	if condition := revision.Status.GetCondition(apis.ConditionReady); condition != nil {
+		deployedAt := condition.LastTransitionTime.Inner
+		modelVersion.DeployedAt = &deployedAt
+	}
+	isvc.Status.AddModelVersion(modelVersion)
 	return nil
 }
 
@@ -122,6 +421,16 @@ func addLoggerAnnotations(logger *v1beta1.LoggerSpec, annotations map[string]str
 	return false
 }
 
+// addExplainerSamplingAnnotations configures the logger to send a sample of the predict requests to the explainer
+func addExplainerSamplingAnnotations(isvc *v1beta1.InferenceService, annotations map[string]string) {
+	if isvc.Spec.Explainer == nil || isvc.Spec.Explainer.SamplingPercent == nil || *isvc.Spec.Explainer.SamplingPercent == 0 {
+		return
+	}
+	annotations[constants.LoggerExplainerUrlInternalAnnotationKey] = fmt.Sprintf("http://%s%s",
+		constants.ExplainerURL(isvc.ObjectMeta, false), constants.ExplainPath(isvc.Name))
+	annotations[constants.LoggerExplainSamplingInternalAnnotationKey] = strconv.FormatInt(*isvc.Spec.Explainer.SamplingPercent, 10)
+}
+
 func addLoggerContainerPort(container *v1.Container) {
 	if container != nil {
 		if container.Ports == nil || len(container.Ports) == 0 {
@@ -151,6 +460,10 @@ func addBatcherAnnotations(batcher *v1beta1.Batcher, annotations map[string]stri
 			s := strconv.Itoa(*batcher.Timeout)
 			annotations[constants.BatcherTimeoutInternalAnnotationKey] = s
 		}
+		if batcher.MaxQueueDepth != nil {
+			s := strconv.Itoa(*batcher.MaxQueueDepth)
+			annotations[constants.BatcherMaxQueueDepthInternalAnnotationKey] = s
+		}
 		return true
 	}
 	return false
@@ -168,3 +481,29 @@ func addBatcherContainerPort(container *v1.Container) {
 		}
 	}
 }
+
+func addAsyncContainerPort(container *v1.Container) {
+	if container != nil {
+		if container.Ports == nil || len(container.Ports) == 0 {
+			port, _ := strconv.Atoi(constants.InferenceServiceDefaultAsyncPort)
+			container.Ports = []v1.ContainerPort{
+				{
+					ContainerPort: int32(port),
+				},
+			}
+		}
+	}
+}
+
+func addValidatorContainerPort(container *v1.Container) {
+	if container != nil {
+		if container.Ports == nil || len(container.Ports) == 0 {
+			port, _ := strconv.Atoi(constants.InferenceServiceDefaultValidatorPort)
+			container.Ports = []v1.ContainerPort{
+				{
+					ContainerPort: int32(port),
+				},
+			}
+		}
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/async"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/warmup"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewWarmupConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	v2 := constants.ProtocolV2
+	body := runtime.RawExtension{Raw: []byte(`{"instances":[[1,2]]}`)}
+	scenarios := map[string]struct {
+		predictor v1beta1.PredictorSpec
+		expected  *warmup.Config
+	}{
+		"V1Defaults": {
+			predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{},
+				Warmup: &v1beta1.WarmupSpec{
+					Requests: []v1beta1.WarmupRequest{
+						{Body: body},
+						{Path: "/v1/models/iris:explain", Body: body},
+					},
+				},
+			},
+			expected: &warmup.Config{
+				ReadyPath:   "/v1/models/iris",
+				DefaultPath: "/v1/models/iris:predict",
+				Requests: []warmup.Request{
+					{Path: "/v1/models/iris:predict", Body: body.Raw},
+					{Path: "/v1/models/iris:explain", Body: body.Raw},
+				},
+				Iterations:     1,
+				TimeoutSeconds: 300,
+			},
+		},
+		"V2RequestFiles": {
+			predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{ProtocolVersion: &v2},
+				},
+				Warmup: &v1beta1.WarmupSpec{
+					StorageURI:     proto.String("gs://warmup/iris"),
+					Iterations:     v1beta1.GetIntReference(3),
+					TimeoutSeconds: v1beta1.GetIntReference(60),
+				},
+			},
+			expected: &warmup.Config{
+				ReadyPath:      "/v2/models/iris/ready",
+				DefaultPath:    "/v2/models/iris/infer",
+				StorageURI:     "gs://warmup/iris",
+				Iterations:     3,
+				TimeoutSeconds: 60,
+			},
+		},
+		"ModelReadinessPath": {
+			predictor: v1beta1.PredictorSpec{
+				SKLearn:        &v1beta1.SKLearnSpec{},
+				ModelReadiness: &v1beta1.ModelReadinessSpec{Path: "/health/model"},
+				Warmup:         &v1beta1.WarmupSpec{StorageURI: proto.String("gs://warmup/iris")},
+			},
+			expected: &warmup.Config{
+				ReadyPath:      "/health/model",
+				DefaultPath:    "/v1/models/iris:predict",
+				StorageURI:     "gs://warmup/iris",
+				Iterations:     1,
+				TimeoutSeconds: 300,
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: "default"},
+				Spec:       v1beta1.InferenceServiceSpec{Predictor: scenario.predictor},
+			}
+			g.Expect(newWarmupConfig(isvc)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestNewAsyncConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	maxRequestBytes, maxResponseBytes := int64(1<<20), int64(4<<20)
+	scenarios := map[string]struct {
+		async            *v1beta1.AsyncSpec
+		maxRequestBytes  *int64
+		maxResponseBytes *int64
+		expected         *async.Config
+	}{
+		"Defaults": {
+			async: &v1beta1.AsyncSpec{},
+			expected: &async.Config{
+				Backend:          "memory",
+				QueueName:        "kfserving:async:default:iris",
+				Workers:          1,
+				TimeoutSeconds:   300,
+				ResultTTLSeconds: 3600,
+			},
+		},
+		"RedisQueue": {
+			async: &v1beta1.AsyncSpec{
+				Queue:            v1beta1.AsyncRedisQueue,
+				RedisAddress:     "redis:6379",
+				Workers:          v1beta1.GetIntReference(4),
+				MaxQueueDepth:    v1beta1.GetIntReference(1000),
+				TimeoutSeconds:   v1beta1.GetIntReference(60),
+				ResultTTLSeconds: v1beta1.GetIntReference(600),
+				CallbackHosts:    []string{"hooks.example.com"},
+			},
+			expected: &async.Config{
+				Backend:          "redis",
+				RedisAddress:     "redis:6379",
+				QueueName:        "kfserving:async:default:iris",
+				Workers:          4,
+				MaxQueueDepth:    1000,
+				TimeoutSeconds:   60,
+				ResultTTLSeconds: 600,
+				CallbackHosts:    []string{"hooks.example.com"},
+			},
+		},
+		"RequestLimits": {
+			async:            &v1beta1.AsyncSpec{},
+			maxRequestBytes:  &maxRequestBytes,
+			maxResponseBytes: &maxResponseBytes,
+			expected: &async.Config{
+				Backend:          "memory",
+				QueueName:        "kfserving:async:default:iris",
+				Workers:          1,
+				TimeoutSeconds:   300,
+				ResultTTLSeconds: 3600,
+				MaxRequestBytes:  1 << 20,
+				MaxResponseBytes: 4 << 20,
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: "default"},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor: v1beta1.PredictorSpec{
+						SKLearn: &v1beta1.SKLearnSpec{},
+						Async:   scenario.async,
+						ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+							MaxRequestBytes:  scenario.maxRequestBytes,
+							MaxResponseBytes: scenario.maxResponseBytes,
+						},
+					},
+				},
+			}
+			g.Expect(newAsyncConfig(isvc)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestApplyModelVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	resources := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")}}
+	container := &v1.Container{Image: "kfserving/sklearnserver:v0.6.0"}
+	annotations := map[string]string{
+		constants.StorageInitializerSourceUriInternalAnnotationKey: "gs://models/iris/v2",
+		constants.ModelDigestInternalAnnotationKey:                 "sha256:1f3e",
+	}
+	applyModelVersion(&v1beta1.ModelVersion{
+		StorageUri:  "gs://models/iris/v1",
+		ModelDigest: "sha256:9c2a",
+		Image:       "kfserving/sklearnserver:v0.5.1",
+		Resources:   resources,
+	}, container, annotations)
+	g.Expect(container.Image).To(gomega.Equal("kfserving/sklearnserver:v0.5.1"))
+	g.Expect(container.Resources).To(gomega.Equal(resources))
+	g.Expect(annotations[constants.StorageInitializerSourceUriInternalAnnotationKey]).To(gomega.Equal("gs://models/iris/v1"))
+	g.Expect(annotations[constants.ModelDigestInternalAnnotationKey]).To(gomega.Equal("sha256:9c2a"))
+
+	applyModelVersion(&v1beta1.ModelVersion{}, container, annotations)
+	g.Expect(container.Image).To(gomega.Equal("kfserving/sklearnserver:v0.5.1"))
+	g.Expect(annotations).NotTo(gomega.HaveKey(constants.StorageInitializerSourceUriInternalAnnotationKey))
+	g.Expect(annotations).NotTo(gomega.HaveKey(constants.ModelDigestInternalAnnotationKey))
+}
+
+func TestPropagateModelVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(knservingv1.AddToScheme(scheme)).To(gomega.Succeed())
+	readyTime := apis.VolatileTime{Inner: metav1.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)}
+	revision := func(name string, storageUri string) *knservingv1.Revision {
+		return &knservingv1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.StorageInitializerSourceUriInternalAnnotationKey: storageUri,
+					constants.ModelDigestInternalAnnotationKey:                 "sha256:9c2a",
+				},
+			},
+			Spec: knservingv1.RevisionSpec{
+				PodSpec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Image: "kfserving/sklearnserver:v0.6.0"},
+						{Name: "queue-proxy", Image: "queue"},
+					},
+				},
+			},
+			Status: knservingv1.RevisionStatus{
+				Status: duckv1.Status{
+					Conditions: duckv1.Conditions{{Type: apis.ConditionReady, LastTransitionTime: readyTime}},
+				},
+			},
+		}
+	}
+	cl := fake.NewFakeClientWithScheme(scheme,
+		revision("iris-predictor-default-00001", "gs://models/iris/v1"),
+		revision("iris-predictor-default-00002", "gs://models/iris/v2"))
+	isvc := &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: "default"}}
+	g.Expect(propagateModelVersion(cl, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ModelVersions).To(gomega.BeEmpty())
+
+	for _, name := range []string{"iris-predictor-default-00001", "iris-predictor-default-00002",
+		"iris-predictor-default-00002", "iris-predictor-default-00003"} {
+		isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+			v1beta1.PredictorComponent: {LatestReadyRevision: name},
+		}
+		g.Expect(propagateModelVersion(cl, isvc)).To(gomega.Succeed())
+	}
+	g.Expect(isvc.Status.ModelVersions).To(gomega.HaveLen(2))
+	latest := isvc.Status.ModelVersions[0]
+	g.Expect(latest.Version).To(gomega.Equal(int64(2)))
+	g.Expect(latest.Revision).To(gomega.Equal("iris-predictor-default-00002"))
+	g.Expect(latest.StorageUri).To(gomega.Equal("gs://models/iris/v2"))
+	g.Expect(latest.ModelDigest).To(gomega.Equal("sha256:9c2a"))
+	g.Expect(latest.Image).To(gomega.Equal("kfserving/sklearnserver:v0.6.0"))
+	g.Expect(latest.DeployedAt.Equal(&readyTime.Inner)).To(gomega.BeTrue())
+}
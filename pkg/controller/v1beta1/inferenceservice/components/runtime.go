@@ -0,0 +1,169 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NoServingRuntimeError is returned when no serving runtime can serve the model of the predictor
+type NoServingRuntimeError struct {
+	message string
+}
+
+func (e *NoServingRuntimeError) Error() string {
+	return e.message
+}
+
+// runtimeCandidate is a runtime considered for the model of the predictor
+type runtimeCandidate struct {
+	name string
+	spec *v1beta1.ServingRuntimeSpec
+}
+
+// getServingRuntime returns the name and spec of the runtime serving the model predictor. The runtime named by the
+// predictor is looked up in the namespace first and then in the cluster, otherwise the auto selectable runtime with
+// the highest priority supporting the model format version, protocol and GPU requirement of the predictor is
+// selected, namespace runtimes taking precedence over cluster runtimes.
+func getServingRuntime(cl client.Client, namespace string, model *v1beta1.ModelPredictorSpec) (string, *v1beta1.ServingRuntimeSpec, error) {
+	protocol := model.GetProtocol()
+	gpu := utils.IsGPUEnabled(model.Resources)
+	if model.Runtime != nil {
+		spec, err := GetNamedServingRuntime(cl, namespace, *model.Runtime)
+		if err != nil {
+			if apierr.IsNotFound(err) {
+				return "", nil, &NoServingRuntimeError{message: fmt.Sprintf("serving runtime %s not found", *model.Runtime)}
+			}
+			return "", nil, err
+		}
+		if err := checkNamedServingRuntime(spec, model.ModelFormat, protocol, gpu); err != nil {
+			return "", nil, &NoServingRuntimeError{message: fmt.Sprintf("serving runtime %s cannot serve the model: %v", *model.Runtime, err)}
+		}
+		return *model.Runtime, spec, nil
+	}
+
+	runtimes := &v1beta1.ServingRuntimeList{}
+	if err := cl.List(context.TODO(), runtimes, client.InNamespace(namespace)); err != nil {
+		return "", nil, err
+	}
+	candidates := []runtimeCandidate{}
+	for i := range runtimes.Items {
+		candidates = append(candidates, runtimeCandidate{name: runtimes.Items[i].Name, spec: &runtimes.Items[i].Spec})
+	}
+	rejected := []string{}
+	if selected := selectServingRuntime(candidates, model.ModelFormat, protocol, gpu, &rejected); selected != nil {
+		return selected.name, selected.spec, nil
+	}
+
+	clusterRuntimes := &v1beta1.ClusterServingRuntimeList{}
+	if err := cl.List(context.TODO(), clusterRuntimes); err != nil {
+		return "", nil, err
+	}
+	candidates = []runtimeCandidate{}
+	for i := range clusterRuntimes.Items {
+		candidates = append(candidates, runtimeCandidate{name: clusterRuntimes.Items[i].Name, spec: &clusterRuntimes.Items[i].Spec})
+	}
+	if selected := selectServingRuntime(candidates, model.ModelFormat, protocol, gpu, &rejected); selected != nil {
+		return selected.name, selected.spec, nil
+	}
+
+	message := fmt.Sprintf("no serving runtime supports model format %s", model.ModelFormat.Name)
+	if model.ModelFormat.Version != nil {
+		message += " version " + *model.ModelFormat.Version
+	}
+	message += fmt.Sprintf(" with protocol %s", protocol)
+	if gpu {
+		message += " on GPUs"
+	}
+	if len(rejected) != 0 {
+		message += ", " + strings.Join(rejected, ", ")
+	}
+	return "", nil, &NoServingRuntimeError{message: message}
+}
+
+// checkNamedServingRuntime returns an error if the runtime named by the predictor cannot serve the model, the
+// runtime does not need to be auto selectable
+func checkNamedServingRuntime(spec *v1beta1.ServingRuntimeSpec, format v1beta1.ModelFormat, protocol constants.InferenceServiceProtocol, gpu bool) error {
+	if spec.IsDisabled() {
+		return fmt.Errorf("runtime is disabled")
+	}
+	if err := spec.CheckModelFormat(format); err != nil {
+		return err
+	}
+	if !spec.SupportsProtocol(protocol) {
+		return fmt.Errorf("protocol %s is not supported", protocol)
+	}
+	if !spec.SupportsGPU(gpu) {
+		if gpu {
+			return fmt.Errorf("runtime does not serve models on GPUs")
+		}
+		return fmt.Errorf("runtime only serves models on GPUs")
+	}
+	return nil
+}
+
+// selectServingRuntime returns the auto selectable candidate with the highest priority for the model format,
+// candidates of the same priority are considered in name order. The reasons why the candidates listing the model
+// format were not selected are appended to rejected.
+func selectServingRuntime(candidates []runtimeCandidate, format v1beta1.ModelFormat, protocol constants.InferenceServiceProtocol,
+	gpu bool, rejected *[]string) *runtimeCandidate {
+	sort.Slice(candidates, func(i, j int) bool {
+		iPriority, jPriority := candidates[i].spec.GetPriority(format), candidates[j].spec.GetPriority(format)
+		if iPriority != jPriority {
+			return iPriority > jPriority
+		}
+		return candidates[i].name < candidates[j].name
+	})
+	for i := range candidates {
+		err := candidates[i].spec.CheckAutoSelectable(format, protocol, gpu)
+		if err == nil {
+			return &candidates[i]
+		}
+		for _, supported := range candidates[i].spec.SupportedModelFormats {
+			if strings.EqualFold(supported.Name, format.Name) {
+				*rejected = append(*rejected, fmt.Sprintf("%s: %v", candidates[i].name, err))
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetNamedServingRuntime returns the spec of the ServingRuntime of the namespace or else of the ClusterServingRuntime
+// with the name, a NotFound error is returned when neither exists
+func GetNamedServingRuntime(cl client.Client, namespace string, name string) (*v1beta1.ServingRuntimeSpec, error) {
+	runtime := &v1beta1.ServingRuntime{}
+	err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, runtime)
+	if err == nil {
+		return &runtime.Spec, nil
+	}
+	if !apierr.IsNotFound(err) {
+		return nil, err
+	}
+	clusterRuntime := &v1beta1.ClusterServingRuntime{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: name}, clusterRuntime); err != nil {
+		return nil, err
+	}
+	return &clusterRuntime.Spec, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetServingRuntime(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	protocolV2 := constants.ProtocolV2
+
+	runtimeSpec := func(format string, autoSelect bool) v1beta1.ServingRuntimeSpec {
+		return v1beta1.ServingRuntimeSpec{
+			SupportedModelFormats: []v1beta1.SupportedModelFormat{{Name: format, AutoSelect: proto.Bool(autoSelect)}},
+			Containers:            []v1.Container{{Name: "kfserving-container", Image: format + "server:latest"}},
+		}
+	}
+	withVersion := func(spec v1beta1.ServingRuntimeSpec, version string) v1beta1.ServingRuntimeSpec {
+		spec.SupportedModelFormats[0].Version = proto.String(version)
+		return spec
+	}
+	withPriority := func(spec v1beta1.ServingRuntimeSpec, priority int32) v1beta1.ServingRuntimeSpec {
+		spec.SupportedModelFormats[0].Priority = proto.Int32(priority)
+		return spec
+	}
+	withGPU := func(spec v1beta1.ServingRuntimeSpec, gpu bool) v1beta1.ServingRuntimeSpec {
+		spec.GPU = proto.Bool(gpu)
+		return spec
+	}
+	gpuResources := v1.ResourceRequirements{
+		Limits: v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme,
+		&v1beta1.ServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom-sklearn", Namespace: "default"},
+			Spec:       runtimeSpec("sklearn", true),
+		},
+		&v1beta1.ServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "manual-xgboost", Namespace: "default"},
+			Spec:       runtimeSpec("xgboost", false),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kfserving-sklearnserver"},
+			Spec:       withVersion(runtimeSpec("sklearn", true), "0"),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "sklearnserver-1"},
+			Spec:       withVersion(runtimeSpec("sklearn", true), ">=1"),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-onnx-legacy"},
+			Spec:       runtimeSpec("onnx", true),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "onnx-cpu"},
+			Spec:       withGPU(withPriority(runtimeSpec("onnx", true), 1), false),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "onnx-gpu"},
+			Spec:       withGPU(withPriority(runtimeSpec("onnx", true), 1), true),
+		},
+		&v1beta1.ClusterServingRuntime{
+			ObjectMeta: metav1.ObjectMeta{Name: "kfserving-xgbserver"},
+			Spec:       runtimeSpec("xgboost", true),
+		},
+	)
+
+	scenarios := map[string]struct {
+		namespace       string
+		model           v1beta1.ModelPredictorSpec
+		expectedRuntime string
+		expectedError   string
+	}{
+		"NamespaceRuntimeTakesPrecedence": {
+			namespace:       "default",
+			model:           v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "sklearn"}},
+			expectedRuntime: "custom-sklearn",
+		},
+		"ClusterRuntime": {
+			namespace:       "other",
+			model:           v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "sklearn"}},
+			expectedRuntime: "kfserving-sklearnserver",
+		},
+		"SkipNotAutoSelectableRuntime": {
+			namespace:       "default",
+			model:           v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "xgboost"}},
+			expectedRuntime: "kfserving-xgbserver",
+		},
+		"NamedRuntime": {
+			namespace: "default",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "xgboost"},
+				Runtime:     proto.String("manual-xgboost"),
+			},
+			expectedRuntime: "manual-xgboost",
+		},
+		"NamedClusterRuntime": {
+			namespace: "default",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "sklearn"},
+				Runtime:     proto.String("kfserving-sklearnserver"),
+			},
+			expectedRuntime: "kfserving-sklearnserver",
+		},
+		"NamedRuntimeNotSupportingFormat": {
+			namespace: "default",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "sklearn"},
+				Runtime:     proto.String("manual-xgboost"),
+			},
+			expectedError: "serving runtime manual-xgboost cannot serve the model: model format sklearn is not supported",
+		},
+		"NamedRuntimeNotFound": {
+			namespace: "default",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "sklearn"},
+				Runtime:     proto.String("missing"),
+			},
+			expectedError: "serving runtime missing not found",
+		},
+		"NoRuntimeSupportingFormat": {
+			namespace:     "default",
+			model:         v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "caffe"}},
+			expectedError: "no serving runtime supports model format caffe with protocol v1",
+		},
+		"SelectByVersion": {
+			namespace:       "other",
+			model:           v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "sklearn", Version: proto.String("1.0")}},
+			expectedRuntime: "sklearnserver-1",
+		},
+		"SelectByPriority": {
+			namespace:       "other",
+			model:           v1beta1.ModelPredictorSpec{ModelFormat: v1beta1.ModelFormat{Name: "onnx"}},
+			expectedRuntime: "onnx-cpu",
+		},
+		"SelectByGPU": {
+			namespace: "other",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "onnx"},
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					Container: v1.Container{Resources: gpuResources},
+				},
+			},
+			expectedRuntime: "onnx-gpu",
+		},
+		"NamedRuntimeNotServingGPU": {
+			namespace: "other",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "onnx"},
+				Runtime:     proto.String("onnx-cpu"),
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					Container: v1.Container{Resources: gpuResources},
+				},
+			},
+			expectedError: "serving runtime onnx-cpu cannot serve the model: runtime does not serve models on GPUs",
+		},
+		"NoRuntimeSupportingProtocol": {
+			namespace: "other",
+			model: v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "xgboost"},
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+					ProtocolVersion: &protocolV2,
+				},
+			},
+			expectedError: "no serving runtime supports model format xgboost with protocol v2, kfserving-xgbserver: protocol v2 is not supported",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			runtimeName, _, err := getServingRuntime(cl, scenario.namespace, &scenario.model)
+			if scenario.expectedError != "" {
+				g.Expect(err).To(gomega.BeAssignableToTypeOf(&NoServingRuntimeError{}))
+				g.Expect(err.Error()).To(gomega.Equal(scenario.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(runtimeName).To(gomega.Equal(scenario.expectedRuntime))
+		})
+	}
+}
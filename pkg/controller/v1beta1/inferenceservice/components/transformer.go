@@ -14,9 +14,15 @@ limitations under the License.
 package components
 
 import (
+	"strconv"
+
 	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/audit"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/envoyfilter"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/keda"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/knative"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/pdb"
 	"github.com/kubeflow/kfserving/pkg/credentials"
 	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
@@ -38,14 +44,17 @@ type Transformer struct {
 	scheme                 *runtime.Scheme
 	inferenceServiceConfig *v1beta1.InferenceServicesConfig
 	credentialBuilder      *credentials.CredentialBuilder
+	mutations              *audit.Mutations
 	Log                    logr.Logger
 }
 
-func NewTransformer(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig) Component {
+func NewTransformer(client client.Client, scheme *runtime.Scheme, inferenceServiceConfig *v1beta1.InferenceServicesConfig,
+	mutations *audit.Mutations) Component {
 	return &Transformer{
 		client:                 client,
 		scheme:                 scheme,
 		inferenceServiceConfig: inferenceServiceConfig,
+		mutations:              mutations,
 		Log:                    ctrl.Log.WithName("TransformerReconciler"),
 	}
 }
@@ -57,10 +66,14 @@ func (p *Transformer) Reconcile(isvc *v1beta1.InferenceService) error {
 	annotations := utils.Filter(isvc.Annotations, func(key string) bool {
 		return !utils.Includes(constants.ServiceAnnotationDisallowedList, key)
 	})
+	addMeshAnnotations(annotations, &isvc.Spec.Transformer.ComponentExtensionSpec, &p.inferenceServiceConfig.Mesh)
 	// KNative does not support INIT containers or mounting, so we add annotations that trigger the
 	// StorageInitializer injector to mutate the underlying deployment to provision model data
 	if sourceURI := transformer.GetStorageUri(); sourceURI != nil {
 		annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] = *sourceURI
+		if modelDigest := isvc.Spec.Transformer.ModelDigest; modelDigest != nil {
+			annotations[constants.ModelDigestInternalAnnotationKey] = *modelDigest
+		}
 	}
 	objectMeta := metav1.ObjectMeta{
 		Name:      constants.DefaultTransformerServiceName(isvc.Name),
@@ -71,21 +84,74 @@ func (p *Transformer) Reconcile(isvc *v1beta1.InferenceService) error {
 		}),
 		Annotations: annotations,
 	}
+	setVisibilityLabel(isvc, objectMeta.Labels)
 	if len(isvc.Spec.Transformer.PodSpec.Containers) == 0 {
 		container := transformer.GetContainer(isvc.ObjectMeta, isvc.Spec.Transformer.GetExtensions(), p.inferenceServiceConfig)
-		isvc.Spec.Transformer.PodSpec = v1beta1.PodSpec{
-			Containers: []corev1.Container{
-				*container,
-			},
+		isvc.Spec.Transformer.PodSpec.Containers = []corev1.Container{
+			*container,
 		}
 	} else {
 		container := transformer.GetContainer(isvc.ObjectMeta, isvc.Spec.Transformer.GetExtensions(), p.inferenceServiceConfig)
 		isvc.Spec.Transformer.PodSpec.Containers[0] = *container
 	}
+	if err := addResponseCache(isvc.Spec.Transformer.ResponseCache, annotations,
+		&isvc.Spec.Transformer.PodSpec.Containers[0]); err != nil {
+		return errors.Wrapf(err, "fails to marshal response cache for transformer")
+	}
+	if isvc.Spec.Transformer.PredictorCall != nil {
+		addPredictorCallEnv(&isvc.Spec.Transformer.PodSpec.Containers[0], isvc.Spec.Transformer.PredictorCall)
+	}
+	// The transformer passes the streamed responses of the predictor through instead of reading them whole
+	if isvc.Spec.Predictor.IsStreaming() {
+		addPredictorProtocolEnv(&isvc.Spec.Transformer.PodSpec.Containers[0], constants.PredictorStreamingEnvVarKey)
+	}
+	// The transformer relays the websocket connections of the clients to the predictor
+	if isvc.Spec.Predictor.IsWebsocket() {
+		addPredictorProtocolEnv(&isvc.Spec.Transformer.PodSpec.Containers[0], constants.PredictorWebsocketEnvVarKey)
+	}
 
+	// The replica bounds of the active scaling window apply to the KEDA ScaledObject and the knative service
+	componentExt := isvc.Spec.Transformer.ComponentExtensionSpec.WithKeda(
+		p.inferenceServiceConfig.Autoscaling.KedaEnabled).WithScalingSchedule(
+		isvc.Status.Components[v1beta1.TransformerComponent].ScalingSchedule)
+	// The ScaledObject is reconciled first to be deleted while the knative service is still scaled by KEDA
+	if err := keda.NewScaledObjectReconciler(p.client, p.scheme, isvc, objectMeta,
+		componentExt).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile KEDA ScaledObject for transformer")
+	}
+	if err := envoyfilter.NewEnvoyFilterReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Transformer.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile limits EnvoyFilter for transformer")
+	}
+	if err := pdb.NewPodDisruptionBudgetReconciler(p.client, p.scheme, isvc, objectMeta,
+		&isvc.Spec.Transformer.ComponentExtensionSpec).Reconcile(); err != nil {
+		return errors.Wrapf(err, "fails to reconcile PodDisruptionBudget for transformer")
+	}
 	podSpec := corev1.PodSpec(isvc.Spec.Transformer.PodSpec)
-	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, &isvc.Spec.Transformer.ComponentExtensionSpec,
+	podSpec.ImagePullSecrets = utils.MergeImagePullSecrets(podSpec.ImagePullSecrets,
+		p.inferenceServiceConfig.Registry.ImagePullSecrets)
+	if err := resolveImageDigests(p.client, isvc, v1beta1.TransformerComponent, &podSpec, annotations,
+		&p.inferenceServiceConfig.Registry); err != nil {
+		return errors.Wrapf(err, "fails to resolve image digests for transformer")
+	}
+	if !verifyPolicy(isvc, v1beta1.TransformerComponent, &podSpec, annotations, &p.inferenceServiceConfig.Policy) {
+		return nil
+	}
+	if found, err := resolvePriorityClass(p.client, &podSpec); err != nil {
+		return errors.Wrapf(err, "fails to get priority class for transformer")
+	} else if !found {
+		isvc.Status.MarkPriorityClassNotFound(v1beta1.TransformerComponent, podSpec.PriorityClassName)
+		return nil
+	}
+	if err := addSchedulingAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal scheduling for transformer")
+	}
+	if err := addVolumesAnnotation(&podSpec, objectMeta); err != nil {
+		return errors.Wrapf(err, "fails to marshal volumes for transformer")
+	}
+	r := knative.NewKsvcReconciler(p.client, p.scheme, objectMeta, componentExt,
 		&podSpec, isvc.Status.Components[v1beta1.TransformerComponent])
+	r.Mutations = p.mutations
 
 	if err := controllerutil.SetControllerReference(isvc, r.Service, p.scheme); err != nil {
 		return errors.Wrapf(err, "fails to set owner reference for transformer")
@@ -95,5 +161,55 @@ func (p *Transformer) Reconcile(isvc *v1beta1.InferenceService) error {
 		return errors.Wrapf(err, "fails to reconcile transformer")
 	}
 	isvc.Status.PropagateStatus(v1beta1.TransformerComponent, status)
+	isvc.Status.PropagateRolloutNotes(v1beta1.TransformerComponent, r.RolloutNotes)
+	if err := propagatePodStatus(p.client, isvc, v1beta1.TransformerComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate pod status for transformer")
+	}
+	if err := propagateActivationStatus(p.client, isvc, v1beta1.TransformerComponent); err != nil {
+		return errors.Wrapf(err, "fails to propagate activation status for transformer")
+	}
 	return nil
 }
+
+// addPredictorCallEnv passes the configuration of the calls to the predictor to the transformer container, the
+// predictor host argument is overridden by the configured host
+func addPredictorCallEnv(container *corev1.Container, predictorCall *v1beta1.PredictorCallSpec) {
+	if predictorCall.Host != "" {
+		for i := range container.Args {
+			if container.Args[i] == constants.ArgumentPredictorHost && i+1 < len(container.Args) {
+				container.Args[i+1] = predictorCall.Host
+			}
+		}
+	}
+	env := []corev1.EnvVar{}
+	for _, envVar := range container.Env {
+		switch envVar.Name {
+		case constants.PredictorHostEnvVarKey, constants.PredictorProtocolEnvVarKey, constants.PredictorMaxConnectionsEnvVarKey:
+		default:
+			env = append(env, envVar)
+		}
+	}
+	if predictorCall.Host != "" {
+		env = append(env, corev1.EnvVar{Name: constants.PredictorHostEnvVarKey, Value: predictorCall.Host})
+	}
+	if predictorCall.Protocol != "" {
+		env = append(env, corev1.EnvVar{Name: constants.PredictorProtocolEnvVarKey, Value: string(predictorCall.Protocol)})
+	}
+	if predictorCall.MaxConnections > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  constants.PredictorMaxConnectionsEnvVarKey,
+			Value: strconv.Itoa(int(predictorCall.MaxConnections)),
+		})
+	}
+	container.Env = env
+}
+
+func addPredictorProtocolEnv(container *corev1.Container, name string) {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			container.Env[i].Value = "true"
+			return
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: "true"})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"testing"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestConfiguredInferenceServices(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := runtime.NewScheme()
+	g.Expect(v1beta1api.AddToScheme(s)).To(gomega.Succeed())
+	r := &InferenceServiceReconciler{
+		Client: fake.NewFakeClientWithScheme(s,
+			&v1beta1api.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "team-a"}},
+			&v1beta1api.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "xgboost", Namespace: "team-b"}},
+		),
+		Log: logf.Log,
+	}
+	configMap := func(name string, namespace string) handler.MapObject {
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		return handler.MapObject{Meta: cm, Object: cm}
+	}
+
+	g.Expect(r.configuredInferenceServices(configMap(constants.InferenceServiceConfigMapName, constants.KFServingNamespace))).
+		To(gomega.ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "sklearn", Namespace: "team-a"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "xgboost", Namespace: "team-b"}},
+		))
+	g.Expect(r.configuredInferenceServices(configMap(constants.InferenceServiceConfigMapName, "team-a"))).
+		To(gomega.ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "sklearn", Namespace: "team-a"}}))
+	g.Expect(r.configuredInferenceServices(configMap("feast-config", constants.KFServingNamespace))).To(gomega.BeEmpty())
+}
+
+func TestUncachedConfigMaps(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key := types.NamespacedName{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace}
+	reader := &uncachedReader{
+		cached: fake.NewFakeClient(),
+		uncached: fake.NewFakeClient(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		}),
+	}
+	g.Expect(reader.Get(context.TODO(), key, &v1.ConfigMap{})).To(gomega.Succeed())
+	configMaps := &v1.ConfigMapList{}
+	g.Expect(reader.List(context.TODO(), configMaps)).To(gomega.Succeed())
+	g.Expect(configMaps.Items).To(gomega.HaveLen(1))
+}
+
+func TestCachedPods(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	key := types.NamespacedName{Name: "sklearn-predictor-default-abc", Namespace: "team-a"}
+	reader := &uncachedReader{
+		cached: fake.NewFakeClient(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		}),
+		uncached: fake.NewFakeClient(),
+	}
+	g.Expect(reader.Get(context.TODO(), key, &v1.Pod{})).To(gomega.Succeed())
+	pods := &v1.PodList{}
+	g.Expect(reader.List(context.TODO(), pods)).To(gomega.Succeed())
+	g.Expect(pods.Items).To(gomega.HaveLen(1))
+}
+
+func TestLabeledInferenceService(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "sklearn-predictor-default-abc", Namespace: "team-a",
+		Labels: map[string]string{constants.InferenceServicePodLabelKey: "sklearn"}}}
+	g.Expect(labeledInferenceService(handler.MapObject{Meta: pod, Object: pod})).To(gomega.ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "sklearn", Namespace: "team-a"}}))
+	unlabeled := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-a"}}
+	g.Expect(labeledInferenceService(handler.MapObject{Meta: unlabeled, Object: unlabeled})).To(gomega.BeEmpty())
+}
@@ -17,16 +17,31 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/audit"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/auth"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/certificate"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/envoyfilter"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/federation"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/ingress"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/mesh"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/pdb"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/servingquota"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/go-logr/logr"
@@ -36,20 +51,43 @@ import (
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=servingruntimes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=clusterservingruntimes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=servingquotas,verbs=get;list;watch
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=revisions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.x-k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=getambassador.io,resources=mappings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=projectcontour.io,resources=httpproxies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=security.istio.io,resources=requestauthentications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=security.istio.io,resources=authorizationpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=security.istio.io,resources=peerauthentications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 
 // InferenceServiceReconciler reconciles a InferenceService object
@@ -58,59 +96,382 @@ type InferenceServiceReconciler struct {
 	Log      logr.Logger
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// AuditSink receives the audit events as cloud events, the audit events are only recorded as Kubernetes events
+	// when nil
+	AuditSink *audit.Sink
+	// Querier queries the metrics of the canaries from Prometheus, the canaries are not analyzed and the idle TTLs are
+	// not enforced when nil
+	Querier servingmetrics.Querier
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	// Mutations logs the changes made to the Knative services, the virtual services and the status, nothing when nil
+	Mutations *audit.Mutations
+	// LoopDetector damps the reconciles of the inference services in a reconcile loop, it observes the changes of the
+	// mutations. The reconcile loops are not detected when nil.
+	LoopDetector *LoopDetector
+	// MemberClusters are the clients of the member clusters the inference services are propagated to, the inference
+	// services are not federated when nil, i.e. unless the manager runs with --enable-federation
+	MemberClusters *federation.MemberClusters
 }
 
 func (r *InferenceServiceReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
+	// The reconciles of an inference service in a reconcile loop are damped, the watch events are ignored meanwhile
+	if damping := r.LoopDetector.Damped(req.NamespacedName); damping > 0 {
+		return ctrl.Result{RequeueAfter: damping}, nil
+	}
+	start := time.Now()
+	result, err := r.reconcile(req)
+	observeReconcile(time.Since(start).Seconds(), err)
+	if changes := r.LoopDetector.Reconciled(req.NamespacedName, err); changes != nil {
+		reconcileLoops.Inc()
+		r.Log.Info("Damping the reconciles of an inference service making the same changes in a loop, "+
+			"another controller may be reverting them", "namespace", req.Namespace, "name", req.Name,
+			"threshold", r.LoopDetector.Threshold, "window", r.LoopDetector.Window.String(),
+			"damping", r.LoopDetector.Damping.String(), "changes", changes)
+		// The error is not returned, controller-runtime requeues the failed reconciles right away ignoring RequeueAfter
+		if err != nil {
+			r.Log.Error(err, "Failed to reconcile the damped inference service", "namespace", req.Namespace,
+				"name", req.Name)
+		}
+		return ctrl.Result{RequeueAfter: r.LoopDetector.Damping}, nil
+	}
+	return result, err
+}
 
+func (r *InferenceServiceReconciler) reconcile(req ctrl.Request) (ctrl.Result, error) {
+	if owned, err := r.Shard.Owns(req.Namespace); !owned {
+		return reconcile.Result{}, err
+	}
 	// Fetch the InferenceService instance
 	isvc := &v1beta1api.InferenceService{}
 	if err := r.Get(context.TODO(), req.NamespacedName, isvc); err != nil {
 		if apierr.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteReadyMetric(req.Namespace, req.Name)
+			deleteCostMetric(req.Namespace, req.Name)
+			r.LoopDetector.Forget(req.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
 	}
 	r.Log.Info("Reconciling inference service", "apiVersion", isvc.APIVersion, "isvc", isvc.Name)
-	isvcConfig, err := v1beta1api.NewInferenceServicesConfig(r.Client)
+	ingressConfig, err := v1beta1api.NewIngressConfig(r.Client, isvc.Namespace)
+	if err != nil {
+		if isvc.DeletionTimestamp == nil {
+			return reconcile.Result{}, errors.Wrapf(err, "fails to create IngressConfig")
+		}
+		// The deletion does not hang on an invalid ingress config, the resources in the namespace of the ingress
+		// gateway are left behind
+		r.Log.Error(err, "Failed to read the ingress config, the resources of the deleted inference service in the "+
+			"namespace of the ingress gateway are not deleted", "namespace", isvc.Namespace, "name", isvc.Name)
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", "Invalid ingress config, the resources in the "+
+			"namespace of the ingress gateway are not deleted: %v", err)
+		ingressConfig = nil
+	}
+	if isvc.DeletionTimestamp != nil {
+		deleteReadyMetric(isvc.Namespace, isvc.Name)
+		deleteCostMetric(isvc.Namespace, isvc.Name)
+		return reconcile.Result{}, r.finalize(isvc, ingressConfig)
+	}
+	// The certificates, the auth policies and the hedging EnvoyFilters are deleted with a finalizer since they are not
+	// in the namespace of the inference service, an invalid API key annotation is reported by the API key reconciler
+	finalizers := append([]string{}, isvc.Finalizers...)
+	apiKeyRequired, _ := auth.IsAPIKeyRequired(isvc)
+	if (ingressConfig.CertificateIssuer != "" || ingressConfig.Auth != nil || apiKeyRequired || isvc.HasHedging()) &&
+		!utils.Includes(finalizers, constants.IngressGatewayFinalizer) {
+		finalizers = append(finalizers, constants.IngressGatewayFinalizer)
+	}
+	// The copies of the inference service in the member clusters are deleted with a finalizer too
+	if r.MemberClusters != nil && isvc.Spec.Federation != nil &&
+		!utils.Includes(finalizers, constants.FederationFinalizer) {
+		finalizers = append(finalizers, constants.FederationFinalizer)
+	}
+	if len(finalizers) != len(isvc.Finalizers) {
+		if err := r.updateFinalizers(isvc, finalizers); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	// The copies of the inference service in the member clusters follow its spec whether it is suspended or not
+	if r.MemberClusters != nil && (isvc.Spec.Federation != nil || isvc.Status.Federation != nil) {
+		if err := federation.NewFederationReconciler(r.Client, r.MemberClusters).Reconcile(isvc); err != nil {
+			reconcileErrors.WithLabelValues(federationStep).Inc()
+			return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile federation")
+		}
+	}
+	// The new inference services exceeding a quota of their namespace are queued, they are not deployed until the
+	// quota is available
+	if servingquota.PendingAdmission(isvc) {
+		queued, err := servingquota.Admit(r.Client, isvc)
+		if err != nil {
+			reconcileErrors.WithLabelValues(quotaStep).Inc()
+			return reconcile.Result{}, errors.Wrapf(err, "fails to admit inference service")
+		}
+		if queued != "" {
+			isvc.Status.MarkQueued(queued)
+			if err := r.updateStatus(isvc); err != nil {
+				reconcileErrors.WithLabelValues(statusStep).Inc()
+				r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
+				return reconcile.Result{}, err
+			}
+			setReadyMetric(isvc)
+			// The quotas and the usage of the other inference services are not watched
+			return ctrl.Result{RequeueAfter: constants.QuotaRequeueInterval}, nil
+		}
+		isvc.Status.ClearCondition(v1beta1api.Queued)
+	}
+	// The components of a suspended inference service are scaled to zero and its routes are deleted, the routes are
+	// created again on resume
+	if isvc.Spec.Suspend {
+		if err := r.suspend(isvc, ingressConfig); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := r.updateStatus(isvc); err != nil {
+			reconcileErrors.WithLabelValues(statusStep).Inc()
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
+			return reconcile.Result{}, err
+		}
+		setReadyMetric(isvc)
+		setCostMetric(isvc)
+		return reconcile.Result{}, nil
+	}
+	if isvc.Status.GetCondition(v1beta1api.Suspended) != nil {
+		if err := r.resume(isvc); err != nil {
+			return reconcile.Result{}, err
+		}
+		isvc.Status.ClearCondition(v1beta1api.Suspended)
+	}
+	isvcConfig, err := v1beta1api.NewInferenceServicesConfig(r.Client, isvc.Namespace)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrapf(err, "fails to create InferenceServicesConfig")
 	}
-	reconcilers := []components.Component{
-		components.NewPredictor(r.Client, r.Scheme, isvcConfig),
+	isvc.Status.ConfigVersion = isvcConfig.Version
+	// The idle reaper only runs with the Prometheus querier, the TTL after the last request is reported as not enforced
+	// without it
+	_, hasIdleTTL := isvc.Annotations[constants.IdleTTLAnnotationKey]
+	notEnforced := isvc.Status.GetCondition(v1beta1api.IdleTTLNotEnforced) != nil
+	isvc.Status.SetIdleTTLEnforced(hasIdleTTL, r.Querier != nil)
+	if condition := isvc.Status.GetCondition(v1beta1api.IdleTTLNotEnforced); condition != nil && !notEnforced {
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, v1beta1api.IdleReaperDisabled, condition.Message)
+	}
+	// The canaries are analyzed first so the component reconcilers shift the traffic of the rolled back canaries
+	analyzingCanaries := r.Querier != nil && r.analyzeCanaries(isvc)
+	reconcilers := map[v1beta1api.ComponentType]components.Component{
+		v1beta1api.PredictorComponent: components.NewPredictor(r.Client, r.Scheme, isvcConfig, r.Mutations),
 	}
 	if isvc.Spec.Transformer != nil {
-		reconcilers = append(reconcilers, components.NewTransformer(r.Client, r.Scheme, isvcConfig))
+		reconcilers[v1beta1api.TransformerComponent] = components.NewTransformer(r.Client, r.Scheme, isvcConfig, r.Mutations)
 	}
 	if isvc.Spec.Explainer != nil {
-		reconcilers = append(reconcilers, components.NewExplainer(r.Client, r.Scheme, isvcConfig))
+		reconcilers[v1beta1api.ExplainerComponent] = components.NewExplainer(r.Client, r.Scheme, isvcConfig, r.Mutations)
 	}
-	for _, reconciler := range reconcilers {
+	for _, component := range []v1beta1api.ComponentType{v1beta1api.PredictorComponent,
+		v1beta1api.TransformerComponent, v1beta1api.ExplainerComponent} {
+		reconciler, ok := reconcilers[component]
+		if !ok {
+			continue
+		}
 		if err := reconciler.Reconcile(isvc); err != nil {
+			reconcileErrors.WithLabelValues(string(component)).Inc()
 			r.Log.Error(err, "Failed to reconcile", "reconciler", reflect.ValueOf(reconciler), "Name", isvc.Name)
 			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
 			return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile component")
 		}
 	}
-	//Reconcile ingress
-	ingressConfig, err := v1beta1api.NewIngressConfig(r.Client)
-	if err != nil {
-		return reconcile.Result{}, errors.Wrapf(err, "fails to create IngressConfig")
+	// The rollout of the components denied by the policy engines is blocked until they are allowed
+	if condition := isvc.Status.GetCondition(v1beta1api.PolicyViolation); condition != nil {
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, v1beta1api.PolicyVerificationFailed, condition.Message)
 	}
-	reconciler := ingress.NewIngressReconciler(r.Client, r.Scheme, ingressConfig)
+	if err := mesh.NewPeerAuthenticationReconciler(r.Client, r.Scheme, &isvcConfig.Mesh).Reconcile(isvc); err != nil {
+		reconcileErrors.WithLabelValues(meshStep).Inc()
+		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile mesh")
+	}
+	// The hedging delays are resolved before the routes are reconciled
+	resolvingHedgingDelays := r.resolveHedgingDelays(isvc, ingressConfig)
+	//Reconcile ingress
+	reconciler := ingress.NewReconciler(r.Client, r.Scheme, ingressConfig, &isvcConfig.Mesh, r.Mutations)
 	r.Log.Info("Reconciling ingress for inference service", "isvc", isvc.Name)
 	if err := reconciler.Reconcile(isvc); err != nil {
+		reconcileErrors.WithLabelValues(ingressStep).Inc()
 		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile ingress")
 	}
+	if err := r.estimateCost(isvc); err != nil {
+		reconcileErrors.WithLabelValues(costStep).Inc()
+		return reconcile.Result{}, errors.Wrapf(err, "fails to estimate cost")
+	}
 
 	if err = r.updateStatus(isvc); err != nil {
+		reconcileErrors.WithLabelValues(statusStep).Inc()
 		r.Recorder.Eventf(isvc, v1.EventTypeWarning, "InternalError", err.Error())
 		return reconcile.Result{}, err
 	}
+	setReadyMetric(isvc)
+	setCostMetric(isvc)
+	return ctrl.Result{RequeueAfter: requeueAfter(isvc, analyzingCanaries, resolvingHedgingDelays)}, nil
+}
+
+// finalize deletes the certificate, the auth policies and the API key policy of the external host of a deleted inference
+// service and its hedging EnvoyFilter, and its copies in the member clusters. The resources of the external host are
+// not deleted when the ingress config is nil, it could not be read.
+func (r *InferenceServiceReconciler) finalize(isvc *v1beta1api.InferenceService, ingressConfig *v1beta1api.IngressConfig) error {
+	finalizers := []string{}
+	for _, finalizer := range isvc.Finalizers {
+		switch finalizer {
+		case constants.IngressGatewayFinalizer:
+			if ingressConfig == nil {
+				continue
+			}
+			if err := r.finalizeIngressGateway(isvc, ingressConfig, false); err != nil {
+				return err
+			}
+		case constants.FederationFinalizer:
+			if r.MemberClusters == nil {
+				finalizers = append(finalizers, finalizer)
+				continue
+			}
+			if err := federation.NewFederationReconciler(r.Client, r.MemberClusters).Delete(isvc); err != nil {
+				return errors.Wrapf(err, "fails to delete federated inference services")
+			}
+		default:
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	if len(finalizers) == len(isvc.Finalizers) {
+		return nil
+	}
+	return r.updateFinalizers(isvc, finalizers)
+}
+
+// finalizeIngressGateway deletes the resources of a deleted or suspended inference service in the namespace of the
+// ingress gateway, the API key secret of a suspended inference service is kept so its clients keep their keys on resume
+func (r *InferenceServiceReconciler) finalizeIngressGateway(isvc *v1beta1api.InferenceService,
+	ingressConfig *v1beta1api.IngressConfig, suspended bool) error {
+	if ingressConfig.CertificateIssuer != "" {
+		if err := certificate.NewCertificateReconciler(r.Client, r.Scheme, ingressConfig).Delete(isvc); err != nil {
+			return errors.Wrapf(err, "fails to delete certificate")
+		}
+	}
+	if ingressConfig.Auth != nil {
+		if err := auth.NewAuthPolicyReconciler(r.Client, ingressConfig).Delete(isvc); err != nil {
+			return errors.Wrapf(err, "fails to delete auth policies")
+		}
+	}
+	apiKeyReconciler := auth.NewAPIKeyReconciler(r.Client, r.Scheme, ingressConfig)
+	if suspended {
+		if err := apiKeyReconciler.DeletePolicy(isvc); err != nil {
+			return errors.Wrapf(err, "fails to delete API key policy")
+		}
+	} else if err := apiKeyReconciler.Delete(isvc); err != nil {
+		return errors.Wrapf(err, "fails to delete API keys")
+	}
+	if err := envoyfilter.NewHedgingReconciler(r.Client, ingressConfig).Delete(isvc); err != nil {
+		return errors.Wrapf(err, "fails to delete hedging EnvoyFilter")
+	}
+	return nil
+}
+
+// suspend scales the knative services of the components of a suspended inference service to zero, their revisions are
+// kept, and deletes the limits EnvoyFilters and the PodDisruptionBudgets of the components, the routing resources and
+// the resources of the external host but the API key secret
+func (r *InferenceServiceReconciler) suspend(isvc *v1beta1api.InferenceService, ingressConfig *v1beta1api.IngressConfig) error {
+	for _, name := range []string{constants.DefaultPredictorServiceName(isvc.Name),
+		constants.DefaultTransformerServiceName(isvc.Name), constants.DefaultExplainerServiceName(isvc.Name)} {
+		if err := r.scaleToZero(name, isvc.Namespace); err != nil {
+			return err
+		}
+		// The components without extension have neither limits nor budget
+		componentMeta := metav1.ObjectMeta{Name: name, Namespace: isvc.Namespace}
+		if err := envoyfilter.NewEnvoyFilterReconciler(r.Client, r.Scheme, isvc, componentMeta,
+			&v1beta1api.ComponentExtensionSpec{}).Reconcile(); err != nil {
+			return errors.Wrapf(err, "fails to delete limits EnvoyFilter %s", name)
+		}
+		if err := pdb.NewPodDisruptionBudgetReconciler(r.Client, r.Scheme, isvc, componentMeta,
+			&v1beta1api.ComponentExtensionSpec{}).Reconcile(); err != nil {
+			return errors.Wrapf(err, "fails to delete PodDisruptionBudget %s", name)
+		}
+	}
+	if err := ingress.NewReconciler(r.Client, r.Scheme, ingressConfig, nil, r.Mutations).Delete(isvc); err != nil {
+		reconcileErrors.WithLabelValues(ingressStep).Inc()
+		return errors.Wrapf(err, "fails to delete ingress")
+	}
+	if err := r.finalizeIngressGateway(isvc, ingressConfig, true); err != nil {
+		reconcileErrors.WithLabelValues(ingressStep).Inc()
+		return err
+	}
+	isvc.Status.MarkSuspended()
+	return nil
+}
+
+// scaleToZero lets the knative service of a suspended component scale to zero and keeps it off the external gateway.
+// Knative creates a revision without minimum of replicas, the revisions of the component are kept and the component
+// reconcilers restore its replicas and visibility on resume.
+func (r *InferenceServiceReconciler) scaleToZero(name string, namespace string) error {
+	existing := &knservingv1.Service{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, existing); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if existing.Spec.Template.Annotations[autoscaling.MinScaleAnnotationKey] == "0" &&
+		existing.Labels[constants.VisibilityLabel] == constants.VisibilityClusterLocal {
+		return nil
+	}
+	patched := existing.DeepCopy()
+	if patched.Spec.Template.Annotations == nil {
+		patched.Spec.Template.Annotations = map[string]string{}
+	}
+	patched.Spec.Template.Annotations[autoscaling.MinScaleAnnotationKey] = "0"
+	if patched.Labels == nil {
+		patched.Labels = map[string]string{}
+	}
+	patched.Labels[constants.VisibilityLabel] = constants.VisibilityClusterLocal
+	r.Log.Info("Scaling knative service to zero", "namespace", namespace, "name", name)
+	if err := r.Patch(context.TODO(), patched, client.MergeFrom(existing)); err != nil {
+		return errors.Wrapf(err, "fails to scale knative service %s to zero", name)
+	}
+	r.Mutations.Record(existing, "Service", existing.Spec, patched.Spec)
+	return nil
+}
+
+// resume exposes the knative services of the components of a resumed inference service again, the visibility label
+// set on suspend is not managed by the component reconcilers. Their replicas are restored by the component reconcilers.
+func (r *InferenceServiceReconciler) resume(isvc *v1beta1api.InferenceService) error {
+	if isvc.IsClusterLocal() {
+		return nil
+	}
+	for _, name := range []string{constants.DefaultPredictorServiceName(isvc.Name),
+		constants.DefaultTransformerServiceName(isvc.Name), constants.DefaultExplainerServiceName(isvc.Name)} {
+		existing := &knservingv1.Service{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: isvc.Namespace}, existing); err != nil {
+			if apierr.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if _, ok := existing.Labels[constants.VisibilityLabel]; !ok {
+			continue
+		}
+		patched := existing.DeepCopy()
+		delete(patched.Labels, constants.VisibilityLabel)
+		if err := r.Patch(context.TODO(), patched, client.MergeFrom(existing)); err != nil {
+			return errors.Wrapf(err, "fails to expose knative service %s", name)
+		}
+	}
+	return nil
+}
 
-	return ctrl.Result{}, nil
+// updateFinalizers patches the finalizers only, the spec of the inference service is defaulted in memory while it
+// is reconciled
+func (r *InferenceServiceReconciler) updateFinalizers(isvc *v1beta1api.InferenceService, finalizers []string) error {
+	patched := isvc.DeepCopy()
+	patched.Finalizers = finalizers
+	if err := r.Patch(context.TODO(), patched, client.MergeFrom(isvc)); err != nil {
+		return errors.Wrapf(err, "fails to update finalizers")
+	}
+	isvc.Finalizers = patched.Finalizers
+	isvc.ResourceVersion = patched.ResourceVersion
+	return nil
 }
 
 func (r *InferenceServiceReconciler) updateStatus(desiredService *v1beta1api.InferenceService) error {
@@ -132,6 +493,7 @@ func (r *InferenceServiceReconciler) updateStatus(desiredService *v1beta1api.Inf
 		return errors.Wrapf(err, "fails to update InferenceService status")
 	} else {
 		// If there was a difference and there was no error.
+		r.Mutations.Record(desiredService, "InferenceService status", existingService.Status, desiredService.Status)
 		isReady := inferenceServiceReadiness(desiredService.Status)
 		if wasReady && !isReady { // Moved to NotReady State
 			r.Recorder.Eventf(desiredService, v1.EventTypeWarning, string(v1alpha2.InferenceServiceNotReadyState),
@@ -140,10 +502,26 @@ func (r *InferenceServiceReconciler) updateStatus(desiredService *v1beta1api.Inf
 			r.Recorder.Eventf(desiredService, v1.EventTypeNormal, string(v1alpha2.InferenceServiceReadyState),
 				fmt.Sprintf("InferenceService [%v] is Ready", desiredService.GetName()))
 		}
+		r.recordAuditEvents(desiredService, &existingService.Status)
 	}
 	return nil
 }
 
+// recordAuditEvents records the lifecycle transitions of the components of the inference service
+func (r *InferenceServiceReconciler) recordAuditEvents(isvc *v1beta1api.InferenceService,
+	old *v1beta1api.InferenceServiceStatus) {
+	for _, event := range audit.Transitions(isvc, old) {
+		eventType := v1.EventTypeNormal
+		if event.Warning() {
+			eventType = v1.EventTypeWarning
+		}
+		r.Recorder.Event(isvc, eventType, string(event.Type), event.Message())
+		if r.AuditSink != nil {
+			r.AuditSink.Send(event)
+		}
+	}
+}
+
 func inferenceServiceReadiness(status v1beta1api.InferenceServiceStatus) bool {
 	return status.Conditions != nil &&
 		status.GetCondition(apis.ConditionReady) != nil &&
@@ -151,8 +529,65 @@ func inferenceServiceReadiness(status v1beta1api.InferenceServiceStatus) bool {
 }
 
 func (r *InferenceServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	secrets, err := apiKeySecretSource(mgr)
+	if err != nil {
+		return err
+	}
+	configMaps, err := configMapSource(mgr)
+	if err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1api.InferenceService{}).
 		Owns(&knservingv1.Service{}).
+		// The deleted API key secrets are created again, only the labeled secrets are watched
+		Watches(secrets, &handler.EnqueueRequestForOwner{OwnerType: &v1beta1api.InferenceService{}, IsController: true}).
+		// Revisions carry the labels of the revision template, their activation changes the status of the component
+		// without changing the status of the knative service
+		Watches(&source.Kind{Type: &knservingv1.Revision{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(labeledInferenceService),
+		}).
+		// The readiness of the sidecars and the scheduling of the pods are surfaced in the status, the pods are read from
+		// the cache of the manager
+		Watches(&source.Kind{Type: &v1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(labeledInferenceService),
+		}).
+		// The inference services are reconciled again with the new configuration when the config map changes, only the
+		// inferenceservice-config config maps are watched
+		Watches(configMaps, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.configuredInferenceServices),
+		}).
+		WithEventFilter(r.Shard.Predicate()).
 		Complete(r)
 }
+
+// labeledInferenceService maps an object labeled with its inference service, e.g. a revision or a pod, to the inference
+// service
+func labeledInferenceService(o handler.MapObject) []reconcile.Request {
+	if name, ok := o.Meta.GetLabels()[constants.InferenceServicePodLabelKey]; ok {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: o.Meta.GetNamespace()}}}
+	}
+	return nil
+}
+
+// configuredInferenceServices maps an inferenceservice-config ConfigMap to the inference services it configures, all
+// the inference services for the one of the cluster and the inference services of the namespace otherwise
+func (r *InferenceServiceReconciler) configuredInferenceServices(o handler.MapObject) []reconcile.Request {
+	if o.Meta.GetName() != constants.InferenceServiceConfigMapName {
+		return nil
+	}
+	var opts []client.ListOption
+	if o.Meta.GetNamespace() != constants.KFServingNamespace {
+		opts = append(opts, client.InNamespace(o.Meta.GetNamespace()))
+	}
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, opts...); err != nil {
+		r.Log.Error(err, "Failed to list the inference services of the config map", "namespace", o.Meta.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(isvcs.Items))
+	for _, isvc := range isvcs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}})
+	}
+	return requests
+}
@@ -455,13 +455,18 @@ var _ = Describe("v1beta1 inference service controller", func() {
 						LatestReadyRevision:   "revision-v1",
 						LatestCreatedRevision: "revision-v1",
 						URL:                   predictorUrl,
+						RolloutNotes:          "image: tensorflow/serving:1.13.0; model: s3://test/mnist/export (uri-sha256:3c4ec02f7159); traffic: latest 100%",
+						RevisionHistory:       []string{"revision-v1"},
 					},
 					v1beta1.TransformerComponent: {
 						LatestReadyRevision:   "t-revision-v1",
 						LatestCreatedRevision: "t-revision-v1",
 						URL:                   transformerUrl,
+						RolloutNotes:          "image: transformer:v1; traffic: latest 100%",
+						RevisionHistory:       []string{"t-revision-v1"},
 					},
 				},
+				ConfigVersion: configMap.ResourceVersion,
 			}
 			Eventually(func() string {
 				isvc := &v1beta1.InferenceService{}
@@ -672,13 +677,18 @@ var _ = Describe("v1beta1 inference service controller", func() {
 						LatestReadyRevision:   "revision-v1",
 						LatestCreatedRevision: "revision-v1",
 						URL:                   predictorUrl,
+						RolloutNotes:          "image: tensorflow/serving:1.13.0; model: s3://test/mnist/export (uri-sha256:3c4ec02f7159); traffic: latest 100%",
+						RevisionHistory:       []string{"revision-v1"},
 					},
 					v1beta1.ExplainerComponent: {
 						LatestReadyRevision:   "exp-revision-v1",
 						LatestCreatedRevision: "exp-revision-v1",
 						URL:                   explainerUrl,
+						RolloutNotes:          "image: kfserving/alibi-explainer:0.4.0; model: s3://test/mnist/explainer (uri-sha256:fc658f7ef8fb); traffic: latest 100%",
+						RevisionHistory:       []string{"exp-revision-v1"},
 					},
 				},
+				ConfigVersion: configMap.ResourceVersion,
 			}
 			Eventually(func() string {
 				isvc := &v1beta1.InferenceService{}
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// estimateCost sets the estimated hourly cost of the running replicas of the components in the status, from the
+// resources requested by their pods and the price table of the cost config. The pods of all the revisions of the
+// components are counted, the canaries and the rollouts included.
+func (r *InferenceServiceReconciler) estimateCost(isvc *v1beta1api.InferenceService) error {
+	costConfig, err := v1beta1api.NewCostConfig(r.Client)
+	if err != nil {
+		return errors.Wrapf(err, "fails to create CostConfig")
+	}
+	if len(costConfig.Prices) == 0 {
+		isvc.Status.Cost = nil
+		return nil
+	}
+	pods := &v1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(isvc.Namespace), client.MatchingLabels{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+	}); err != nil {
+		return errors.Wrapf(err, "fails to list pods")
+	}
+	components := []v1beta1api.ComponentType{v1beta1api.PredictorComponent}
+	if isvc.Spec.Transformer != nil {
+		components = append(components, v1beta1api.TransformerComponent)
+	}
+	if isvc.Spec.Explainer != nil {
+		components = append(components, v1beta1api.ExplainerComponent)
+	}
+	isvc.Status.PropagateCost(components, pods.Items, costConfig)
+	return nil
+}
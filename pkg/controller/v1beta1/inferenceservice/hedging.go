@@ -0,0 +1,82 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resolveHedgingDelays sets the delay the hedged requests of the components are sent after in their status, the
+// percentile of the request latency of the component when it is known, the delay of the hedging spec otherwise. The
+// ingress reconciler retries the requests of the routes after the delay. Returns true while a percentile is queried.
+// Only the istio backend hedges the requests, no delay is resolved with the other backends.
+func (r *InferenceServiceReconciler) resolveHedgingDelays(isvc *v1beta1api.InferenceService,
+	ingressConfig *v1beta1api.IngressConfig) bool {
+	hedged := ingressConfig.IngressBackend == "" || ingressConfig.IngressBackend == v1beta1api.IstioIngressBackend
+	querying := false
+	for _, c := range []struct {
+		componentType v1beta1api.ComponentType
+		component     v1beta1api.Component
+	}{
+		{v1beta1api.PredictorComponent, &isvc.Spec.Predictor},
+		{v1beta1api.TransformerComponent, isvc.Spec.Transformer},
+		{v1beta1api.ExplainerComponent, isvc.Spec.Explainer},
+	} {
+		if reflect.ValueOf(c.component).IsNil() {
+			continue
+		}
+		hedging := c.component.GetExtensions().Hedging
+		if hedging == nil || !hedged {
+			if isvc.Status.Components[c.componentType].HedgingDelay != nil {
+				isvc.Status.SetHedgingDelay(c.componentType, nil)
+			}
+			continue
+		}
+		delay := hedging.GetDelay()
+		if hedging.Percentile != nil && r.Querier != nil {
+			querying = true
+			service := constants.DefaultServiceName(isvc.Name, constants.InferenceServiceComponent(c.componentType))
+			selector := fmt.Sprintf(`namespace_name=%q,service_name=%q`, isvc.Namespace, service)
+			latency, err := servingmetrics.QueryLatencyPercentile(context.TODO(), r.Querier, selector,
+				*hedging.Percentile, constants.HedgingLatencyWindow)
+			if err != nil {
+				// The delay resolved last is kept while Prometheus is unavailable
+				r.Log.Error(err, "Failed to query the latency percentile of the hedged component", "isvc", isvc.Name,
+					"component", c.componentType)
+				if resolved := isvc.Status.Components[c.componentType].HedgingDelay; resolved != nil {
+					delay = resolved.Duration
+				}
+			} else if latency != nil && *latency > 0 {
+				// The latency is in milliseconds, the delay is rounded to the millisecond
+				delay = time.Duration(*latency * float64(time.Millisecond)).Round(time.Millisecond)
+				if delay < time.Millisecond {
+					delay = time.Millisecond
+				}
+			}
+		}
+		isvc.Status.SetHedgingDelay(c.componentType, &metav1.Duration{Duration: delay})
+	}
+	return querying
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// latencyQuerier answers the queries of the latency percentile of a component
+type latencyQuerier struct {
+	service string
+	latency *float64
+	err     error
+}
+
+func (q *latencyQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	if q.err != nil {
+		return 0, false, q.err
+	}
+	if !strings.Contains(query, q.service) || q.latency == nil {
+		return 0, false, nil
+	}
+	return *q.latency, true, nil
+}
+
+func TestResolveHedgingDelays(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	latency := 123.4
+	percentile := &v1beta1api.HedgingSpec{Percentile: proto.Int64(95)}
+	scenarios := map[string]struct {
+		hedging  *v1beta1api.HedgingSpec
+		backend  string
+		status   *metav1.Duration
+		querier  servingmetrics.Querier
+		querying bool
+		expected *metav1.Duration
+	}{
+		"NoHedging": {
+			status: &metav1.Duration{Duration: time.Second},
+		},
+		"DefaultDelay": {
+			hedging:  &v1beta1api.HedgingSpec{},
+			querier:  &latencyQuerier{service: "sklearn-predictor-default", latency: &latency},
+			expected: &metav1.Duration{Duration: time.Second},
+		},
+		"Percentile": {
+			hedging:  percentile,
+			querier:  &latencyQuerier{service: "sklearn-predictor-default", latency: &latency},
+			querying: true,
+			expected: &metav1.Duration{Duration: 123 * time.Millisecond},
+		},
+		"PercentileWithoutRequests": {
+			hedging: &v1beta1api.HedgingSpec{Percentile: proto.Int64(95),
+				Delay: &metav1.Duration{Duration: 200 * time.Millisecond}},
+			querier:  &latencyQuerier{service: "sklearn-predictor-default"},
+			querying: true,
+			expected: &metav1.Duration{Duration: 200 * time.Millisecond},
+		},
+		"PercentileWithoutPrometheus": {
+			hedging:  percentile,
+			expected: &metav1.Duration{Duration: time.Second},
+		},
+		"PrometheusUnavailable": {
+			hedging:  percentile,
+			status:   &metav1.Duration{Duration: 150 * time.Millisecond},
+			querier:  &latencyQuerier{err: errors.New("connection refused")},
+			querying: true,
+			expected: &metav1.Duration{Duration: 150 * time.Millisecond},
+		},
+		"OtherIngressBackend": {
+			hedging: percentile,
+			backend: v1beta1api.KubernetesIngressBackend,
+			status:  &metav1.Duration{Duration: 150 * time.Millisecond},
+			querier: &latencyQuerier{service: "sklearn-predictor-default", latency: &latency},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1api.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+				Spec: v1beta1api.InferenceServiceSpec{
+					Predictor: v1beta1api.PredictorSpec{
+						SKLearn: &v1beta1api.SKLearnSpec{},
+						ComponentExtensionSpec: v1beta1api.ComponentExtensionSpec{
+							Hedging: scenario.hedging,
+						},
+					},
+				},
+				Status: v1beta1api.InferenceServiceStatus{
+					Components: map[v1beta1api.ComponentType]v1beta1api.ComponentStatusSpec{
+						v1beta1api.PredictorComponent: {HedgingDelay: scenario.status},
+					},
+				},
+			}
+			r := &InferenceServiceReconciler{Log: logf.Log, Querier: scenario.querier}
+			ingressConfig := &v1beta1api.IngressConfig{IngressBackend: scenario.backend}
+			g.Expect(r.resolveHedgingDelays(isvc, ingressConfig)).To(gomega.Equal(scenario.querying))
+			g.Expect(isvc.Status.Components[v1beta1api.PredictorComponent].HedgingDelay).To(gomega.Equal(scenario.expected))
+			g.Expect(isvc.Status.Components).NotTo(gomega.HaveKey(v1beta1api.ExplainerComponent))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"strconv"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The metrics of the reconcile loop are served on the metrics endpoint of the manager, next to the metrics of
+// controller-runtime. The depth of the work queue is exported by controller-runtime as
+// workqueue_depth{name="inferenceservice"}.
+const (
+	metricsNamespace = "kfserving"
+	metricsSubsystem = "inferenceservice"
+
+	// The reconcile steps which are not components
+	ingressStep    = "ingress"
+	statusStep     = "status"
+	federationStep = "federation"
+	quotaStep      = "quota"
+	costStep       = "cost"
+	meshStep       = "mesh"
+
+	reconcileSuccess = "success"
+	reconcileError   = "error"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliations of the inference services by result.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"result"})
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of reconciliation errors by component (predictor, transformer, explainer) or step (ingress, status).",
+	}, []string{"component"})
+	inferenceServiceReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "ready",
+		Help:      "Whether the inference service is ready (1) or not (0).",
+	}, []string{"namespace", "name"})
+	// The inference services in a loop are logged, the counter has no label by inference service to bound its cardinality
+	reconcileLoops = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "reconcile_loops_total",
+		Help:      "Number of reconcile loops detected and damped.",
+	})
+	inferenceServiceHourlyCost = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "hourly_cost",
+		Help:      "Estimated hourly cost of the running replicas of the component, in the currency of the cost config.",
+	}, []string{"namespace", "name", "component"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, reconcileErrors, inferenceServiceReady, reconcileLoops,
+		inferenceServiceHourlyCost)
+}
+
+// observeReconcile records the duration and the result of a reconciliation
+func observeReconcile(seconds float64, err error) {
+	result := reconcileSuccess
+	if err != nil {
+		result = reconcileError
+	}
+	reconcileDuration.WithLabelValues(result).Observe(seconds)
+}
+
+// setReadyMetric records the readiness of an inference service
+func setReadyMetric(isvc *v1beta1api.InferenceService) {
+	ready := 0.0
+	if inferenceServiceReadiness(isvc.Status) {
+		ready = 1
+	}
+	inferenceServiceReady.WithLabelValues(isvc.Namespace, isvc.Name).Set(ready)
+}
+
+// deleteReadyMetric stops exporting the readiness of a deleted inference service
+func deleteReadyMetric(namespace string, name string) {
+	inferenceServiceReady.DeleteLabelValues(namespace, name)
+}
+
+// setCostMetric records the estimated hourly cost of the components of an inference service, the components which
+// are not estimated are not exported
+func setCostMetric(isvc *v1beta1api.InferenceService) {
+	deleteCostMetric(isvc.Namespace, isvc.Name)
+	if isvc.Status.Cost == nil {
+		return
+	}
+	for component, cost := range isvc.Status.Cost.Components {
+		if value, err := strconv.ParseFloat(cost.HourlyCost, 64); err == nil {
+			inferenceServiceHourlyCost.WithLabelValues(isvc.Namespace, isvc.Name, string(component)).Set(value)
+		}
+	}
+}
+
+// deleteCostMetric stops exporting the estimated hourly cost of the components of an inference service
+func deleteCostMetric(namespace string, name string) {
+	for _, component := range []v1beta1api.ComponentType{v1beta1api.PredictorComponent,
+		v1beta1api.TransformerComponent, v1beta1api.ExplainerComponent} {
+		inferenceServiceHourlyCost.DeleteLabelValues(namespace, name, string(component))
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"errors"
+	"testing"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestReadyMetric(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1api.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-model", Namespace: "default"},
+	}
+	setReadyMetric(isvc)
+	g.Expect(testutil.ToFloat64(inferenceServiceReady.WithLabelValues("default", "metrics-model"))).To(gomega.Equal(0.0))
+
+	isvc.Status.SetCondition(apis.ConditionReady, &apis.Condition{
+		Type:   apis.ConditionReady,
+		Status: v1.ConditionTrue,
+	})
+	setReadyMetric(isvc)
+	g.Expect(testutil.ToFloat64(inferenceServiceReady.WithLabelValues("default", "metrics-model"))).To(gomega.Equal(1.0))
+
+	deleteReadyMetric("default", "metrics-model")
+	g.Expect(inferenceServiceReady.DeleteLabelValues("default", "metrics-model")).To(gomega.BeFalse())
+}
+
+func TestCostMetric(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1api.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "cost-model", Namespace: "default"},
+	}
+	isvc.Status.Cost = &v1beta1api.CostStatus{
+		Currency:   "USD",
+		HourlyCost: "2.6000",
+		Components: map[v1beta1api.ComponentType]v1beta1api.ComponentCostStatus{
+			v1beta1api.PredictorComponent:   {Replicas: 1, HourlyCost: "2.5600"},
+			v1beta1api.TransformerComponent: {Replicas: 2, HourlyCost: "0.0400"},
+		},
+	}
+	setCostMetric(isvc)
+	g.Expect(testutil.ToFloat64(inferenceServiceHourlyCost.WithLabelValues("default", "cost-model", "predictor"))).To(gomega.Equal(2.56))
+	g.Expect(testutil.ToFloat64(inferenceServiceHourlyCost.WithLabelValues("default", "cost-model", "transformer"))).To(gomega.Equal(0.04))
+
+	isvc.Status.Cost = nil
+	setCostMetric(isvc)
+	g.Expect(inferenceServiceHourlyCost.DeleteLabelValues("default", "cost-model", "predictor")).To(gomega.BeFalse())
+	g.Expect(inferenceServiceHourlyCost.DeleteLabelValues("default", "cost-model", "transformer")).To(gomega.BeFalse())
+}
+
+func TestObserveReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	sampleCount := func(result string) uint64 {
+		metric := &dto.Metric{}
+		g.Expect(reconcileDuration.WithLabelValues(result).(prometheus.Histogram).Write(metric)).To(gomega.Succeed())
+		return metric.GetHistogram().GetSampleCount()
+	}
+	successes, errs := sampleCount(reconcileSuccess), sampleCount(reconcileError)
+	observeReconcile(0.2, nil)
+	observeReconcile(0.5, errors.New("fails to reconcile ingress"))
+	observeReconcile(0.1, nil)
+	g.Expect(sampleCount(reconcileSuccess)).To(gomega.Equal(successes + 2))
+	g.Expect(sampleCount(reconcileError)).To(gomega.Equal(errs + 1))
+}
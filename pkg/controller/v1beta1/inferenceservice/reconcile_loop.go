@@ -0,0 +1,159 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// LoopDetector detects the inference services reconciled Threshold times within the Window making the same changes,
+// e.g. when the controller fights with the Knative defaulting over a field of the Knative service, and damps their
+// reconciles for the Damping duration. The changes are observed from the mutations recorded by the reconcilers, the
+// reconciles making no change are not counted. A nil LoopDetector detects nothing.
+type LoopDetector struct {
+	Threshold int
+	Window    time.Duration
+	Damping   time.Duration
+
+	now   func() time.Time
+	mu    sync.Mutex
+	loops map[types.NamespacedName]*reconcileLoop
+}
+
+// reconcileLoop tracks the reconciles of an inference service making the same changes
+type reconcileLoop struct {
+	// changes made by the ongoing reconcile
+	changes []string
+	// outcome is the changes of the last reconcile, reconciles are the times of the reconciles which made them
+	outcome    string
+	reconciles []time.Time
+	dampedTill time.Time
+}
+
+// NewLoopDetector creates a detector of the reconcile loops
+func NewLoopDetector(threshold int, window time.Duration, damping time.Duration) *LoopDetector {
+	return &LoopDetector{
+		Threshold: threshold,
+		Window:    window,
+		Damping:   damping,
+		now:       time.Now,
+		loops:     map[types.NamespacedName]*reconcileLoop{},
+	}
+}
+
+// Observe collects the changes made to the inference service or to the resources it controls during its reconcile
+func (d *LoopDetector) Observe(obj runtime.Object, kind string, changes []audit.Change) {
+	if d == nil {
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	name := accessor.GetName()
+	if _, ok := obj.(*v1beta1api.InferenceService); !ok {
+		owner := metav1.GetControllerOf(accessor)
+		if owner == nil || owner.Kind != "InferenceService" {
+			return
+		}
+		name = owner.Name
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	loop := d.loop(types.NamespacedName{Namespace: accessor.GetNamespace(), Name: name})
+	loop.changes = append(loop.changes, fmt.Sprintf("%s: %s", kind, audit.Summary(changes)))
+}
+
+// Damped returns the remaining damping of the reconciles of the inference service, 0 when they are not damped
+func (d *LoopDetector) Damped(key types.NamespacedName) time.Duration {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	loop, ok := d.loops[key]
+	if !ok {
+		return 0
+	}
+	if remaining := loop.dampedTill.Sub(d.now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Reconciled ends the reconcile of the inference service, it returns the changes made by the reconcile when the
+// inference service is detected in a reconcile loop and its reconciles are damped
+func (d *LoopDetector) Reconciled(key types.NamespacedName, err error) []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	loop, ok := d.loops[key]
+	if !ok || len(loop.changes) == 0 {
+		return nil
+	}
+	changes := loop.changes
+	loop.changes = nil
+	outcome := fmt.Sprintf("%q %v", changes, err)
+	now := d.now()
+	if outcome != loop.outcome {
+		loop.outcome = outcome
+		loop.reconciles = nil
+	}
+	reconciles := []time.Time{}
+	for _, reconciled := range loop.reconciles {
+		if now.Sub(reconciled) < d.Window {
+			reconciles = append(reconciles, reconciled)
+		}
+	}
+	loop.reconciles = append(reconciles, now)
+	if len(loop.reconciles) < d.Threshold {
+		return nil
+	}
+	loop.reconciles = nil
+	loop.dampedTill = now.Add(d.Damping)
+	return changes
+}
+
+// Forget stops tracking a deleted inference service
+func (d *LoopDetector) Forget(key types.NamespacedName) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.loops, key)
+}
+
+func (d *LoopDetector) loop(key types.NamespacedName) *reconcileLoop {
+	loop, ok := d.loops[key]
+	if !ok {
+		loop = &reconcileLoop{}
+		d.loops[key] = loop
+	}
+	return loop
+}
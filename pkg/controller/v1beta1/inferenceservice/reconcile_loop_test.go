@@ -0,0 +1,101 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"testing"
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestLoopDetector(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	detector := NewLoopDetector(3, time.Minute, 5*time.Minute)
+	detector.now = func() time.Time {
+		return now
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "sklearn"}
+	isController := true
+	service := &knservingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sklearn-predictor-default",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "InferenceService", Name: "sklearn", Controller: &isController},
+			},
+		},
+	}
+	fight := []audit.Change{{Path: "template.spec.containers[0].readinessProbe", Previous: `{"tcpSocket":{"port":0}}`}}
+	reconcile := func(changes []audit.Change) []string {
+		if changes != nil {
+			detector.Observe(service, "Service", changes)
+		}
+		return detector.Reconciled(key, nil)
+	}
+
+	// The reconciles making no change are not counted
+	g.Expect(reconcile(nil)).To(gomega.BeNil())
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	now = now.Add(10 * time.Second)
+	g.Expect(reconcile(nil)).To(gomega.BeNil())
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	g.Expect(detector.Damped(key)).To(gomega.BeZero())
+	now = now.Add(10 * time.Second)
+	g.Expect(reconcile(fight)).To(gomega.Equal([]string{
+		`Service: template.spec.containers[0].readinessProbe: {"tcpSocket":{"port":0}} -> <unset>`,
+	}))
+	g.Expect(detector.Damped(key)).To(gomega.Equal(5 * time.Minute))
+	now = now.Add(time.Minute)
+	g.Expect(detector.Damped(key)).To(gomega.Equal(4 * time.Minute))
+	now = now.Add(4 * time.Minute)
+	g.Expect(detector.Damped(key)).To(gomega.BeZero())
+
+	// Different changes or reconciles out of the window are not a loop
+	traffic := []audit.Change{{Path: "traffic[0].percent", Previous: "10", Value: "20"}}
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	g.Expect(reconcile(traffic)).To(gomega.BeNil())
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	now = now.Add(2 * time.Minute)
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	now = now.Add(2 * time.Minute)
+	g.Expect(reconcile(fight)).To(gomega.BeNil())
+	g.Expect(detector.Damped(key)).To(gomega.BeZero())
+
+	// The changes of the status of the inference service and of the resources of other owners
+	isvc := &v1beta1api.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"}}
+	detector.Observe(isvc, "InferenceService status", traffic)
+	detector.Observe(&knservingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+		"Service", fight)
+	g.Expect(detector.loops[key].changes).To(gomega.Equal([]string{
+		"InferenceService status: traffic[0].percent: 10 -> 20",
+	}))
+
+	detector.Forget(key)
+	g.Expect(detector.loops).To(gomega.BeEmpty())
+
+	var disabled *LoopDetector
+	disabled.Observe(service, "Service", fight)
+	g.Expect(disabled.Reconciled(key, nil)).To(gomega.BeNil())
+	g.Expect(disabled.Damped(key)).To(gomega.BeZero())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// serverFields are the fields of the metadata set by the API server, they are not part of the applied configuration
+var serverFields = []string{"creationTimestamp", "deletionTimestamp", "generation", "managedFields", "resourceVersion",
+	"selfLink", "uid"}
+
+// Object creates or updates the object with server-side apply. Only the fields the controller sets are applied: the
+// typed object is converted to an unstructured configuration without its status, the metadata set by the server and
+// the null or empty fields the typed struct serializes, so the controller does not own the fields it leaves unset.
+// The fields it no longer sets are removed and the fields set by the other managers, e.g. the defaults of the Knative
+// webhook or the annotations of Knative, are kept. The configuration is applied without resource version so the
+// concurrent updates of the other managers do not conflict, and the fields the controller sets are forced as it is
+// their source of truth. The object is updated with the applied object returned by the server.
+func Object(ctx context.Context, cl client.Client, scheme *runtime.Scheme, obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.DeepCopy().Object
+	} else if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+		return err
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, field := range serverFields {
+			delete(metadata, field)
+		}
+	}
+	prune(content)
+	applied := &unstructured.Unstructured{Object: content}
+	applied.SetGroupVersionKind(gvk)
+	if err := cl.Patch(ctx, applied, client.Apply, client.FieldOwner(constants.ControllerFieldManager),
+		client.ForceOwnership); err != nil {
+		return err
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = applied.Object
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, obj)
+}
+
+// prune removes the null fields and the empty objects, the empty lists are kept as they are set by the controller
+func prune(fields map[string]interface{}) {
+	for key, value := range fields {
+		switch value := value.(type) {
+		case nil:
+			delete(fields, key)
+		case map[string]interface{}:
+			prune(value)
+			if len(value) == 0 {
+				delete(fields, key)
+			}
+		case []interface{}:
+			for _, item := range value {
+				if item, ok := item.(map[string]interface{}); ok {
+					prune(item)
+				}
+			}
+		}
+	}
+}
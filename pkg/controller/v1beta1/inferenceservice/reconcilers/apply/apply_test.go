@@ -0,0 +1,157 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// patchClient records the patches, the fake client does not support server-side apply
+type patchClient struct {
+	client.Client
+	patchType types.PatchType
+	data      map[string]interface{}
+	options   *client.PatchOptions
+}
+
+func (c *patchClient) Patch(_ context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patchType = patch.Type()
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	c.options = &client.PatchOptions{}
+	c.options.ApplyOptions(opts)
+	return json.Unmarshal(data, &c.data)
+}
+
+func TestObject(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(s)).To(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(s)).To(gomega.Succeed())
+
+	service := &knservingv1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            "sklearn-predictor-default",
+			Namespace:       "default",
+			ResourceVersion: "42",
+			ManagedFields:   []v1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+	}
+	cl := &patchClient{}
+	g.Expect(Object(context.TODO(), cl, s, service)).To(gomega.Succeed())
+
+	g.Expect(cl.patchType).To(gomega.Equal(types.ApplyPatchType))
+	g.Expect(cl.options.FieldManager).To(gomega.Equal("kfserving-controller"))
+	g.Expect(*cl.options.Force).To(gomega.BeTrue())
+	g.Expect(cl.data["apiVersion"]).To(gomega.Equal("serving.knative.dev/v1"))
+	g.Expect(cl.data["kind"]).To(gomega.Equal("Service"))
+	metadata := cl.data["metadata"].(map[string]interface{})
+	g.Expect(metadata["name"]).To(gomega.Equal("sklearn-predictor-default"))
+	g.Expect(metadata).NotTo(gomega.HaveKey("resourceVersion"))
+	g.Expect(metadata).NotTo(gomega.HaveKey("managedFields"))
+	// The zero-valued fields of the typed struct are not applied
+	g.Expect(metadata).NotTo(gomega.HaveKey("creationTimestamp"))
+	g.Expect(cl.data).NotTo(gomega.HaveKey("status"))
+	g.Expect(cl.data).NotTo(gomega.HaveKey("spec"))
+}
+
+func TestObjectApply(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(s)).To(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(s)).To(gomega.Succeed())
+	cl, err := client.New(cfg, client.Options{Scheme: s})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	service := func(image string, annotations map[string]string) *knservingv1.Service {
+		return &knservingv1.Service{
+			ObjectMeta: v1.ObjectMeta{Name: "sklearn-predictor-default", Namespace: "default", Annotations: annotations},
+			Spec: knservingv1.ServiceSpec{
+				ConfigurationSpec: knservingv1.ConfigurationSpec{
+					Template: knservingv1.RevisionTemplateSpec{
+						Spec: knservingv1.RevisionSpec{
+							PodSpec: corev1.PodSpec{Containers: []corev1.Container{{Image: image}}},
+						},
+					},
+				},
+			},
+		}
+	}
+	// otherManager applies the annotations with the field manager of another controller
+	otherManager := func(annotations map[string]interface{}, opts ...client.PatchOption) error {
+		other := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "serving.knative.dev/v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":        "sklearn-predictor-default",
+				"namespace":   "default",
+				"annotations": annotations,
+			},
+		}}
+		return cl.Patch(context.TODO(), other, client.Apply, append(opts, client.FieldOwner("other-controller"))...)
+	}
+	get := func() *knservingv1.Service {
+		existing := &knservingv1.Service{}
+		g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "sklearn-predictor-default", Namespace: "default"},
+			existing)).To(gomega.Succeed())
+		return existing
+	}
+
+	applied := service("sklearn:v1", map[string]string{"owned": "kfserving", "removed": "true"})
+	g.Expect(Object(context.TODO(), cl, s, applied)).To(gomega.Succeed())
+	g.Expect(applied.ResourceVersion).NotTo(gomega.BeEmpty())
+	for _, entry := range get().ManagedFields {
+		if entry.Manager == "kfserving-controller" {
+			// The controller only owns the fields it set
+			g.Expect(string(entry.FieldsV1.Raw)).NotTo(gomega.ContainSubstring("f:resources"))
+			g.Expect(string(entry.FieldsV1.Raw)).NotTo(gomega.ContainSubstring("f:creationTimestamp"))
+		}
+	}
+
+	// The fields of the controller conflict for the other managers
+	err = otherManager(map[string]interface{}{"owned": "other"})
+	g.Expect(apierr.IsConflict(err)).To(gomega.BeTrue(), "expected a conflict got: %v", err)
+	g.Expect(otherManager(map[string]interface{}{"other": "value"})).To(gomega.Succeed())
+
+	// The fields the controller no longer sets are removed, the fields of the other managers are kept
+	applied = service("sklearn:v2", map[string]string{"owned": "kfserving"})
+	g.Expect(Object(context.TODO(), cl, s, applied)).To(gomega.Succeed())
+	existing := get()
+	g.Expect(existing.Annotations).To(gomega.Equal(map[string]string{"owned": "kfserving", "other": "value"}))
+	g.Expect(existing.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal("sklearn:v2"))
+	g.Expect(applied.Generation).To(gomega.Equal(existing.Generation))
+
+	// The fields forced by another manager are forced back by the controller
+	g.Expect(otherManager(map[string]interface{}{"owned": "other", "other": "value"}, client.ForceOwnership)).
+		To(gomega.Succeed())
+	g.Expect(get().Annotations).To(gomega.HaveKeyWithValue("owned", "other"))
+	g.Expect(Object(context.TODO(), cl, s, service("sklearn:v2", map[string]string{"owned": "kfserving"}))).
+		To(gomega.Succeed())
+	g.Expect(get().Annotations).To(gomega.Equal(map[string]string{"owned": "kfserving", "other": "value"}))
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// APIKeyReconciler generates the API key secret of an inference service and reconciles the AuthorizationPolicy
+// delegating the requests to its external host to the ext-authz provider of the ingress config, the provider denies
+// the requests without one of the keys of the secret in the X-API-Key header. The secret is owned by the inference
+// service, the keys added to the secret by hand are also accepted. The keys are never copied out of the secret.
+type APIKeyReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewAPIKeyReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *APIKeyReconciler {
+	return &APIKeyReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// generateAPIKey generates a random URL safe key of 256 bits
+func generateAPIKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", errors.Wrapf(err, "fails to generate API key")
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// IsAPIKeyRequired is true when the inference service is annotated to require an API key
+func IsAPIKeyRequired(isvc *v1beta1.InferenceService) (bool, error) {
+	value, ok := isvc.Annotations[constants.APIKeyAnnotationKey]
+	if !ok {
+		return false, nil
+	}
+	required, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q", constants.APIKeyAnnotationKey, value)
+	}
+	return required, nil
+}
+
+func (r *APIKeyReconciler) getSecret(isvc *v1beta1.InferenceService) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: constants.APIKeySecretName(isvc.Name),
+		Namespace: isvc.Namespace}, secret)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+// reconcileSecret creates the API key secret with a generated key, and rotates the generated key when the rotation
+// annotation of the inference service changes. The ext-authz provider only accepts the secret named after the
+// inference service in its namespace with the inference service as controller.
+func (r *APIKeyReconciler) reconcileSecret(isvc *v1beta1.InferenceService) error {
+	rotation := isvc.Annotations[constants.APIKeyRotationAnnotationKey]
+	existing, err := r.getSecret(isvc)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		key, err := generateAPIKey()
+		if err != nil {
+			return err
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.APIKeySecretName(isvc.Name),
+				Namespace: isvc.Namespace,
+				Labels: map[string]string{
+					constants.InferenceServicePodLabelKey: isvc.Name,
+					constants.APIKeySecretLabelKey:        "true",
+				},
+				Annotations: map[string]string{
+					constants.APIKeyRotationAnnotationKey: rotation,
+				},
+			},
+			Data: map[string][]byte{
+				constants.APIKeySecretKey: []byte(key),
+			},
+		}
+		if err := controllerutil.SetControllerReference(isvc, secret, r.scheme); err != nil {
+			return errors.Wrapf(err, "fails to set owner reference for API key secret")
+		}
+		log.Info("Creating API key secret", "namespace", secret.Namespace, "name", secret.Name)
+		if err := r.client.Create(context.TODO(), secret); err != nil {
+			return errors.Wrapf(err, "fails to create API key secret")
+		}
+		return nil
+	}
+	desired := existing.DeepCopy()
+	if desired.Labels == nil {
+		desired.Labels = map[string]string{}
+	}
+	desired.Labels[constants.APIKeySecretLabelKey] = "true"
+	if desired.Annotations == nil {
+		desired.Annotations = map[string]string{}
+	}
+	if _, hasKey := existing.Data[constants.APIKeySecretKey]; !hasKey ||
+		existing.Annotations[constants.APIKeyRotationAnnotationKey] != rotation {
+		key, err := generateAPIKey()
+		if err != nil {
+			return err
+		}
+		if desired.Data == nil {
+			desired.Data = map[string][]byte{}
+		}
+		if hasKey {
+			desired.Data[constants.PreviousAPIKeySecretKey] = existing.Data[constants.APIKeySecretKey]
+		}
+		desired.Data[constants.APIKeySecretKey] = []byte(key)
+		desired.Annotations[constants.APIKeyRotationAnnotationKey] = rotation
+		log.Info("Rotating API key", "namespace", existing.Namespace, "name", existing.Name)
+	}
+	if equality.Semantic.DeepEqual(desired, existing) {
+		return nil
+	}
+	if err := r.client.Update(context.TODO(), desired); err != nil {
+		return errors.Wrapf(err, "fails to update API key secret")
+	}
+	return nil
+}
+
+// createAuthorizationPolicy delegates the requests to the external host, and to the path prefix on the ingress
+// domain when there is one, to the ext-authz provider checking the API keys
+func (r *APIKeyReconciler) createAuthorizationPolicy(isvc *v1beta1.InferenceService,
+	selector map[string]interface{}, host string, path string) *unstructured.Unstructured {
+	return newPolicy(isvc, constants.IstioAuthorizationPolicy, constants.APIKeyPolicyName(isvc.Name, isvc.Namespace),
+		policyNamespace(r.ingressConfig), map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": selector},
+			"action":   "CUSTOM",
+			"provider": map[string]interface{}{"name": r.ingressConfig.APIKeyAuthProvider},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"to": getOperations(r.ingressConfig, host, path),
+				},
+			},
+		})
+}
+
+// Reconcile creates the API key secret and the policy of the inference service requiring an API key on its external
+// host and on its path prefix on the ingress domain when the path is set. The policy of a cluster local inference
+// service is deleted but its keys are kept, the keys are deleted once it no longer requires an API key.
+func (r *APIKeyReconciler) Reconcile(isvc *v1beta1.InferenceService, host string, path string, isInternal bool) error {
+	required, err := IsAPIKeyRequired(isvc)
+	if err != nil {
+		return err
+	}
+	if !required {
+		return r.Delete(isvc)
+	}
+	if r.ingressConfig.APIKeyAuthProvider == "" {
+		return fmt.Errorf("the %s annotation requires the apiKeyAuthProvider of the ingress config",
+			constants.APIKeyAnnotationKey)
+	}
+	if err := r.reconcileSecret(isvc); err != nil {
+		return err
+	}
+	if isInternal {
+		return deletePolicy(r.client, constants.IstioAuthorizationPolicy, policyNamespace(r.ingressConfig),
+			constants.APIKeyPolicyName(isvc.Name, isvc.Namespace))
+	}
+	selector, err := getGatewaySelector(r.client, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	return reconcilePolicy(r.client, r.createAuthorizationPolicy(isvc, selector, host, path))
+}
+
+// DeletePolicy deletes the policy of the inference service and keeps its API key secret, the policy is only looked up
+// when the secret exists so the Istio security CRDs are only required by the inference services using API keys
+func (r *APIKeyReconciler) DeletePolicy(isvc *v1beta1.InferenceService) error {
+	secret, err := r.getSecret(isvc)
+	if err != nil || secret == nil {
+		return err
+	}
+	return deletePolicy(r.client, constants.IstioAuthorizationPolicy, policyNamespace(r.ingressConfig),
+		constants.APIKeyPolicyName(isvc.Name, isvc.Namespace))
+}
+
+// Delete deletes the policy and the API key secret of the inference service
+func (r *APIKeyReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	secret, err := r.getSecret(isvc)
+	if err != nil || secret == nil {
+		return err
+	}
+	if err := deletePolicy(r.client, constants.IstioAuthorizationPolicy, policyNamespace(r.ingressConfig),
+		constants.APIKeyPolicyName(isvc.Name, isvc.Namespace)); err != nil {
+		return err
+	}
+	log.Info("Deleting API key secret", "namespace", secret.Namespace, "name", secret.Name)
+	if err := r.client.Delete(context.TODO(), secret); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete API key secret")
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIKeyReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	ingressGateway := &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "kfserving-ingress-gateway", Namespace: "knative-serving"},
+		Spec: istiov1alpha3.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-model",
+			Namespace:   "default",
+			UID:         "my-model-uid",
+			Annotations: map[string]string{constants.APIKeyAnnotationKey: "true"},
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy(), ingressGateway)
+	ingressConfig := &v1beta1.IngressConfig{
+		IngressGateway: "knative-serving/kfserving-ingress-gateway",
+		IngressDomain:  "models.example.com",
+	}
+	r := NewAPIKeyReconciler(cl, scheme, ingressConfig)
+	host := "my-model.default.example.com"
+	secretName := types.NamespacedName{Name: "my-model-api-keys", Namespace: "default"}
+
+	// The API keys require the ext-authz provider
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.MatchError(
+		"the serving.kubeflow.org/api-key annotation requires the apiKeyAuthProvider of the ingress config"))
+	ingressConfig.APIKeyAuthProvider = "kfserving-api-keys"
+
+	// The secret is created with a generated key and the requests are delegated to the provider, the policy does
+	// not hold the keys
+	g.Expect(r.Reconcile(isvc, host, "/serving/default/my-model", false)).To(gomega.Succeed())
+	secret := &corev1.Secret{}
+	g.Expect(cl.Get(context.TODO(), secretName, secret)).To(gomega.Succeed())
+	key := string(secret.Data[constants.APIKeySecretKey])
+	g.Expect(key).To(gomega.HaveLen(43))
+	g.Expect(secret.OwnerReferences).To(gomega.HaveLen(1))
+	g.Expect(secret.Labels).To(gomega.HaveKeyWithValue(constants.APIKeySecretLabelKey, "true"))
+	g.Expect(metav1.IsControlledBy(secret, isvc)).To(gomega.BeTrue())
+	policy, err := getPolicy(cl, constants.IstioAuthorizationPolicy, "istio-system",
+		constants.APIKeyPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(policy).ToNot(gomega.BeNil())
+	g.Expect(policy.Object["spec"]).To(gomega.Equal(map[string]interface{}{
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"istio": "ingressgateway"}},
+		"action":   "CUSTOM",
+		"provider": map[string]interface{}{"name": "kfserving-api-keys"},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"to": []interface{}{
+					map[string]interface{}{
+						"operation": map[string]interface{}{"hosts": []interface{}{host, host + ":*"}},
+					},
+					map[string]interface{}{
+						"operation": map[string]interface{}{
+							"hosts": []interface{}{"models.example.com", "models.example.com:*"},
+							"paths": []interface{}{"/serving/default/my-model", "/serving/default/my-model/*"},
+						},
+					},
+				},
+			},
+		},
+	}))
+
+	// The key is kept when the path of the inference service changes
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), secretName, secret)).To(gomega.Succeed())
+	g.Expect(string(secret.Data[constants.APIKeySecretKey])).To(gomega.Equal(key))
+
+	// The previous key stays valid after a rotation
+	isvc.Annotations[constants.APIKeyRotationAnnotationKey] = "2020-10-01"
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), secretName, secret)).To(gomega.Succeed())
+	g.Expect(string(secret.Data[constants.PreviousAPIKeySecretKey])).To(gomega.Equal(key))
+	rotated := string(secret.Data[constants.APIKeySecretKey])
+	g.Expect(rotated).ToNot(gomega.Equal(key))
+
+	// Reconciling again does not rotate the key
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), secretName, secret)).To(gomega.Succeed())
+	g.Expect(string(secret.Data[constants.APIKeySecretKey])).To(gomega.Equal(rotated))
+
+	// The policy of a suspended inference service is deleted but its keys are kept
+	g.Expect(r.DeletePolicy(isvc)).To(gomega.Succeed())
+	policy, err = getPolicy(cl, constants.IstioAuthorizationPolicy, "istio-system",
+		constants.APIKeyPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(policy).To(gomega.BeNil())
+	g.Expect(cl.Get(context.TODO(), secretName, secret)).To(gomega.Succeed())
+	g.Expect(string(secret.Data[constants.APIKeySecretKey])).To(gomega.Equal(rotated))
+
+	// The policy is created again on resume
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.Succeed())
+	policy, err = getPolicy(cl, constants.IstioAuthorizationPolicy, "istio-system",
+		constants.APIKeyPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(policy).ToNot(gomega.BeNil())
+
+	// The policy and the secret are deleted once the inference service no longer requires an API key
+	isvc.Annotations[constants.APIKeyAnnotationKey] = "false"
+	g.Expect(r.Reconcile(isvc, host, "", false)).To(gomega.Succeed())
+	policy, err = getPolicy(cl, constants.IstioAuthorizationPolicy, "istio-system",
+		constants.APIKeyPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(policy).To(gomega.BeNil())
+	g.Expect(apierr.IsNotFound(cl.Get(context.TODO(), secretName, &corev1.Secret{}))).To(gomega.BeTrue())
+}
+
+func TestIsAPIKeyRequired(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		annotations map[string]string
+		required    bool
+		err         bool
+	}{
+		"NoAnnotation": {},
+		"Required": {
+			annotations: map[string]string{constants.APIKeyAnnotationKey: "true"},
+			required:    true,
+		},
+		"NotRequired": {
+			annotations: map[string]string{constants.APIKeyAnnotationKey: "false"},
+		},
+		"Invalid": {
+			annotations: map[string]string{constants.APIKeyAnnotationKey: "yes please"},
+			err:         true,
+		},
+	}
+	for name, scenario := range scenarios {
+		isvc := &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Annotations: scenario.annotations}}
+		required, err := IsAPIKeyRequired(isvc)
+		g.Expect(required).To(gomega.Equal(scenario.required), name)
+		g.Expect(err != nil).To(gomega.Equal(scenario.err), name)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AuthPolicyReconciler reconciles the Istio RequestAuthentication validating the JWTs of the requests of an inference
+// service at the ingress gateway and the AuthorizationPolicy denying the requests to its external host without a JWT
+// of an allowed subject. The policies are unstructured since the pinned Istio API predates RequestAuthentication and
+// the DENY action.
+type AuthPolicyReconciler struct {
+	client        client.Client
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewAuthPolicyReconciler(client client.Client, ingressConfig *v1beta1.IngressConfig) *AuthPolicyReconciler {
+	return &AuthPolicyReconciler{
+		client:        client,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// authPolicy is the auth ingress config with the overrides of the annotations of an inference service
+type authPolicy struct {
+	issuer    string
+	jwksURI   string
+	audiences []string
+	subjects  []string
+}
+
+func splitAnnotation(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getAuthPolicy returns the auth policy of the inference service, nil when it does not require a JWT
+func getAuthPolicy(isvc *v1beta1.InferenceService, authConfig *v1beta1.AuthConfig) (*authPolicy, error) {
+	required := authConfig.Required
+	if value, ok := isvc.Annotations[constants.AuthRequiredAnnotationKey]; ok {
+		var err error
+		if required, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q", constants.AuthRequiredAnnotationKey, value)
+		}
+	}
+	if !required {
+		return nil, nil
+	}
+	policy := &authPolicy{
+		issuer:    authConfig.Issuer,
+		jwksURI:   authConfig.JwksURI,
+		audiences: authConfig.Audiences,
+		subjects:  authConfig.Subjects,
+	}
+	if issuer, ok := isvc.Annotations[constants.AuthIssuerAnnotationKey]; ok && issuer != authConfig.Issuer {
+		// The key set of the issuer of the config does not apply to another issuer
+		policy.issuer = issuer
+		policy.jwksURI = ""
+	}
+	if audiences, ok := isvc.Annotations[constants.AuthAudiencesAnnotationKey]; ok {
+		policy.audiences = splitAnnotation(audiences)
+	}
+	if subjects, ok := isvc.Annotations[constants.AuthSubjectsAnnotationKey]; ok {
+		policy.subjects = splitAnnotation(subjects)
+	}
+	if policy.issuer == "" {
+		return nil, fmt.Errorf("the inference service requires a JWT but no issuer is configured, set the issuer of " +
+			"the auth ingress config or the " + constants.AuthIssuerAnnotationKey + " annotation")
+	}
+	return policy, nil
+}
+
+func (r *AuthPolicyReconciler) policyName(isvc *v1beta1.InferenceService) string {
+	return constants.AuthPolicyName(isvc.Name, isvc.Namespace)
+}
+
+// createRequestAuthentication validates the JWTs of the issuer on the ingress gateway pods, the original token is
+// forwarded to the model servers
+func (r *AuthPolicyReconciler) createRequestAuthentication(isvc *v1beta1.InferenceService, policy *authPolicy,
+	selector map[string]interface{}) *unstructured.Unstructured {
+	jwtRule := map[string]interface{}{
+		"issuer":               policy.issuer,
+		"forwardOriginalToken": true,
+	}
+	if policy.jwksURI != "" {
+		jwtRule["jwksUri"] = policy.jwksURI
+	}
+	if len(policy.audiences) > 0 {
+		jwtRule["audiences"] = toInterfaces(policy.audiences)
+	}
+	return newPolicy(isvc, constants.IstioRequestAuthentication, r.policyName(isvc), policyNamespace(r.ingressConfig),
+		map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": selector},
+			"jwtRules": []interface{}{jwtRule},
+		})
+}
+
+// createAuthorizationPolicy denies the requests to the external host, and to the path prefix on the ingress domain
+// when there is one, without a JWT of an allowed subject of the issuer or with a JWT of another audience
+func (r *AuthPolicyReconciler) createAuthorizationPolicy(isvc *v1beta1.InferenceService, policy *authPolicy,
+	selector map[string]interface{}, host string, path string) *unstructured.Unstructured {
+	to := getOperations(r.ingressConfig, host, path)
+	principals := []interface{}{policy.issuer + "/*"}
+	if len(policy.subjects) > 0 {
+		principals = []interface{}{}
+		for _, subject := range policy.subjects {
+			principals = append(principals, policy.issuer+"/"+subject)
+		}
+	}
+	rules := []interface{}{
+		map[string]interface{}{
+			"from": []interface{}{
+				map[string]interface{}{"source": map[string]interface{}{"notRequestPrincipals": principals}},
+			},
+			"to": to,
+		},
+	}
+	// The JWT rules of the issuer are shared by all the inference services on the gateway, the audience is checked
+	// per inference service
+	if len(policy.audiences) > 0 {
+		rules = append(rules, map[string]interface{}{
+			"to": to,
+			"when": []interface{}{
+				map[string]interface{}{
+					"key":       constants.RequestAuthAudiencesConditionKey,
+					"notValues": toInterfaces(policy.audiences),
+				},
+			},
+		})
+	}
+	return newPolicy(isvc, constants.IstioAuthorizationPolicy, r.policyName(isvc), policyNamespace(r.ingressConfig),
+		map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": selector},
+			"action":   "DENY",
+			"rules":    rules,
+		})
+}
+
+// Reconcile creates or updates the policies of the inference service requiring a JWT on its external host and on its
+// path prefix on the ingress domain when the path is set, and deletes them when it no longer requires one or is
+// cluster local
+func (r *AuthPolicyReconciler) Reconcile(isvc *v1beta1.InferenceService, host string, path string, isInternal bool) error {
+	policy, err := getAuthPolicy(isvc, r.ingressConfig.Auth)
+	if err != nil {
+		return err
+	}
+	if policy == nil || isInternal {
+		return r.Delete(isvc)
+	}
+	selector, err := getGatewaySelector(r.client, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if err := reconcilePolicy(r.client, r.createRequestAuthentication(isvc, policy, selector)); err != nil {
+		return err
+	}
+	return reconcilePolicy(r.client, r.createAuthorizationPolicy(isvc, policy, selector, host, path))
+}
+
+// Delete deletes the policies of the inference service
+func (r *AuthPolicyReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	for _, kind := range []string{constants.IstioAuthorizationPolicy, constants.IstioRequestAuthentication} {
+		if err := deletePolicy(r.client, kind, policyNamespace(r.ingressConfig), r.policyName(isvc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
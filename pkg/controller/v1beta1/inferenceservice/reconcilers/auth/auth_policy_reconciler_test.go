@@ -0,0 +1,177 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const issuer = "https://accounts.example.com"
+
+func TestGetAuthPolicy(t *testing.T) {
+	authConfig := &v1beta1.AuthConfig{
+		Issuer:    issuer,
+		JwksURI:   issuer + "/jwks",
+		Audiences: []string{"models"},
+	}
+	scenarios := map[string]struct {
+		annotations    map[string]string
+		required       bool
+		expectedPolicy *authPolicy
+		expectedError  bool
+	}{
+		"NotRequired": {},
+		"RequiredByConfig": {
+			required: true,
+			expectedPolicy: &authPolicy{
+				issuer:    issuer,
+				jwksURI:   issuer + "/jwks",
+				audiences: []string{"models"},
+			},
+		},
+		"DisabledByAnnotation": {
+			annotations: map[string]string{constants.AuthRequiredAnnotationKey: "false"},
+			required:    true,
+		},
+		"Overrides": {
+			annotations: map[string]string{
+				constants.AuthRequiredAnnotationKey:  "true",
+				constants.AuthIssuerAnnotationKey:    "https://login.example.com",
+				constants.AuthAudiencesAnnotationKey: "flowers, models",
+				constants.AuthSubjectsAnnotationKey:  "alice,bob",
+			},
+			expectedPolicy: &authPolicy{
+				issuer:    "https://login.example.com",
+				audiences: []string{"flowers", "models"},
+				subjects:  []string{"alice", "bob"},
+			},
+		},
+		"InvalidRequired": {
+			annotations:   map[string]string{constants.AuthRequiredAnnotationKey: "maybe"},
+			expectedError: true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default", Annotations: scenario.annotations},
+			}
+			config := *authConfig
+			config.Required = scenario.required
+			policy, err := getAuthPolicy(isvc, &config)
+			if scenario.expectedError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(gomega.HaveOccurred())
+			g.Expect(policy).To(gomega.Equal(scenario.expectedPolicy))
+		})
+	}
+
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default"}}
+	_, err := getAuthPolicy(isvc, &v1beta1.AuthConfig{Required: true})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestAuthPolicyReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	ingressGateway := &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "kfserving-ingress-gateway", Namespace: "knative-serving"},
+		Spec: istiov1alpha3.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-model",
+			Namespace:   "default",
+			Annotations: map[string]string{constants.AuthSubjectsAnnotationKey: "alice"},
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy(), ingressGateway)
+	r := NewAuthPolicyReconciler(cl, &v1beta1.IngressConfig{
+		IngressGateway: "knative-serving/kfserving-ingress-gateway",
+		IngressDomain:  "models.example.com",
+		Auth: &v1beta1.AuthConfig{
+			Issuer:    issuer,
+			Audiences: []string{"models"},
+			Required:  true,
+		},
+	})
+	g.Expect(r.Reconcile(isvc, "my-model.default.example.com", "/serving/default/my-model", false)).To(gomega.Succeed())
+
+	requestAuthentication, err := getPolicy(cl, constants.IstioRequestAuthentication, "istio-system",
+		constants.AuthPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(requestAuthentication.GetNamespace()).To(gomega.Equal("istio-system"))
+	g.Expect(requestAuthentication.Object["spec"]).To(gomega.Equal(map[string]interface{}{
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"istio": "ingressgateway"}},
+		"jwtRules": []interface{}{
+			map[string]interface{}{
+				"issuer":               issuer,
+				"audiences":            []interface{}{"models"},
+				"forwardOriginalToken": true,
+			},
+		},
+	}))
+
+	authorizationPolicy, err := getPolicy(cl, constants.IstioAuthorizationPolicy, "istio-system",
+		constants.AuthPolicyName("my-model", "default"))
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	action, _, _ := unstructured.NestedString(authorizationPolicy.Object, "spec", "action")
+	g.Expect(action).To(gomega.Equal("DENY"))
+	rules, _, _ := unstructured.NestedSlice(authorizationPolicy.Object, "spec", "rules")
+	g.Expect(rules).To(gomega.HaveLen(2))
+	principals, _, _ := unstructured.NestedStringSlice(rules[0].(map[string]interface{})["from"].([]interface{})[0].(map[string]interface{}),
+		"source", "notRequestPrincipals")
+	g.Expect(principals).To(gomega.Equal([]string{issuer + "/alice"}))
+	to := rules[0].(map[string]interface{})["to"].([]interface{})
+	g.Expect(to).To(gomega.HaveLen(2))
+	hosts, _, _ := unstructured.NestedStringSlice(to[0].(map[string]interface{}), "operation", "hosts")
+	g.Expect(hosts).To(gomega.Equal([]string{"my-model.default.example.com", "my-model.default.example.com:*"}))
+	paths, _, _ := unstructured.NestedStringSlice(to[1].(map[string]interface{}), "operation", "paths")
+	g.Expect(paths).To(gomega.Equal([]string{"/serving/default/my-model", "/serving/default/my-model/*"}))
+	when := rules[1].(map[string]interface{})["when"].([]interface{})[0].(map[string]interface{})
+	g.Expect(when["key"]).To(gomega.Equal(constants.RequestAuthAudiencesConditionKey))
+
+	// The policies are deleted once the inference service is cluster local
+	g.Expect(r.Reconcile(isvc, "my-model.default.example.com", "", true)).To(gomega.Succeed())
+	for _, kind := range []string{constants.IstioRequestAuthentication, constants.IstioAuthorizationPolicy} {
+		policy, err := getPolicy(cl, kind, "istio-system", constants.AuthPolicyName("my-model", "default"))
+		g.Expect(err).ToNot(gomega.HaveOccurred())
+		g.Expect(policy).To(gomega.BeNil())
+	}
+}
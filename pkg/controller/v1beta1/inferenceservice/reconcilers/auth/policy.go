@@ -0,0 +1,155 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("AuthPolicyReconciler")
+
+// policyNamespace is the namespace of the ingress gateway pods the policies are created in, Istio only applies the
+// policies of the namespace of the workload or of the root namespace
+func policyNamespace(ingressConfig *v1beta1.IngressConfig) string {
+	if ingressConfig.Auth != nil && ingressConfig.Auth.Namespace != "" {
+		return ingressConfig.Auth.Namespace
+	}
+	return constants.DefaultAuthPolicyNamespace
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// newPolicy creates a policy of the inference service selecting the ingress gateway pods
+func newPolicy(isvc *v1beta1.InferenceService, kind string, name string, namespace string,
+	spec map[string]interface{}) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	policy.SetAPIVersion(constants.IstioSecurityAPIVersion)
+	policy.SetKind(kind)
+	policy.SetName(name)
+	policy.SetNamespace(namespace)
+	policy.SetLabels(map[string]string{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+	})
+	return policy
+}
+
+// getOperations are the targets of the policies of the inference service, its external host and its path prefix on
+// the ingress domain when the path is set
+func getOperations(ingressConfig *v1beta1.IngressConfig, host string, path string) []interface{} {
+	operations := []interface{}{
+		map[string]interface{}{
+			"operation": map[string]interface{}{"hosts": []interface{}{host, host + ":*"}},
+		},
+	}
+	if path != "" {
+		domain := ingressConfig.IngressDomain
+		operations = append(operations, map[string]interface{}{
+			"operation": map[string]interface{}{
+				"hosts": []interface{}{domain, domain + ":*"},
+				"paths": []interface{}{path, path + "/*"},
+			},
+		})
+	}
+	return operations
+}
+
+// getGatewaySelector returns the selector of the ingress gateway pods of the ingress config
+func getGatewaySelector(cl client.Client, ingressConfig *v1beta1.IngressConfig) (map[string]interface{}, error) {
+	ingressGateway := strings.Split(ingressConfig.IngressGateway, "/")
+	if len(ingressGateway) != 2 {
+		return nil, fmt.Errorf("invalid ingress gateway %s, <namespace>/<name> is required", ingressConfig.IngressGateway)
+	}
+	gateway := &v1alpha3.Gateway{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: ingressGateway[1], Namespace: ingressGateway[0]},
+		gateway); err != nil {
+		return nil, errors.Wrapf(err, "fails to get ingress gateway %s", ingressConfig.IngressGateway)
+	}
+	selector := map[string]interface{}{}
+	for k, v := range gateway.Spec.Selector {
+		selector[k] = v
+	}
+	return selector, nil
+}
+
+// getPolicy gets a policy, it returns nil when the policy does not exist
+func getPolicy(cl client.Client, kind string, namespace string, name string) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.IstioSecurityAPIVersion)
+	existing.SetKind(kind)
+	err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, existing)
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("the %s CRD of Istio is not installed", kind)
+	}
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+func reconcilePolicy(cl client.Client, desired *unstructured.Unstructured) error {
+	existing, err := getPolicy(cl, desired.GetKind(), desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		log.Info("Creating "+desired.GetKind(), "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = cl.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) {
+		existing.Object["spec"] = desired.Object["spec"]
+		log.Info("Updating "+desired.GetKind(), "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = cl.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update %s", desired.GetKind())
+	}
+	return nil
+}
+
+func deletePolicy(cl client.Client, kind string, namespace string, name string) error {
+	existing, err := getPolicy(cl, kind, namespace, name)
+	if err != nil || existing == nil {
+		return err
+	}
+	log.Info("Deleting "+kind, "namespace", namespace, "name", name)
+	if err := cl.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete %s", kind)
+	}
+	return nil
+}
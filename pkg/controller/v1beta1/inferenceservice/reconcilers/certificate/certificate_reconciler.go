@@ -0,0 +1,260 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("CertificateReconciler")
+
+// CertificateReconciler reconciles the cert-manager Certificate of the external host of an inference service and the
+// Istio Gateway terminating TLS with its secret. The Certificate is unstructured so cert-manager is only required in
+// the clusters using it.
+type CertificateReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewCertificateReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *CertificateReconciler {
+	return &CertificateReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+func (r *CertificateReconciler) namespace() string {
+	if r.ingressConfig.CertificateNamespace != "" {
+		return r.ingressConfig.CertificateNamespace
+	}
+	return constants.DefaultCertificateNamespace
+}
+
+func (r *CertificateReconciler) createCertificate(isvc *v1beta1.InferenceService, host string) *unstructured.Unstructured {
+	issuerKind := r.ingressConfig.CertificateIssuerKind
+	if issuerKind == "" {
+		issuerKind = constants.DefaultCertificateIssuerKind
+	}
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName": constants.CertificateName(isvc.Name, isvc.Namespace),
+				"dnsNames":   []interface{}{host},
+				"issuerRef": map[string]interface{}{
+					"name": r.ingressConfig.CertificateIssuer,
+					"kind": issuerKind,
+				},
+			},
+		},
+	}
+	certificate.SetAPIVersion(constants.CertManagerAPIVersion)
+	certificate.SetKind(constants.CertManagerCertificate)
+	certificate.SetName(constants.CertificateName(isvc.Name, isvc.Namespace))
+	certificate.SetNamespace(r.namespace())
+	certificate.SetLabels(map[string]string{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+	})
+	return certificate
+}
+
+// createGateway creates the Gateway serving the external host over HTTPS on the pods of the ingress gateway of the
+// ingress config
+func (r *CertificateReconciler) createGateway(isvc *v1beta1.InferenceService, host string, selector map[string]string) *v1alpha3.Gateway {
+	return &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.TLSGatewayName(isvc.Name),
+			Namespace: isvc.Namespace,
+		},
+		Spec: istiov1alpha3.Gateway{
+			Selector: selector,
+			Servers: []*istiov1alpha3.Server{
+				{
+					Port: &istiov1alpha3.Port{
+						Number:   443,
+						Name:     "https",
+						Protocol: "HTTPS",
+					},
+					Hosts: []string{host},
+					Tls: &istiov1alpha3.Server_TLSOptions{
+						Mode:           istiov1alpha3.Server_TLSOptions_SIMPLE,
+						CredentialName: constants.CertificateName(isvc.Name, isvc.Namespace),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *CertificateReconciler) getCertificate(isvc *v1beta1.InferenceService) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.CertManagerAPIVersion)
+	existing.SetKind(constants.CertManagerCertificate)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: constants.CertificateName(isvc.Name, isvc.Namespace),
+		Namespace: r.namespace()}, existing)
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("the %s CRD of cert-manager is not installed", constants.CertManagerCertificate)
+	}
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (r *CertificateReconciler) reconcileCertificate(isvc *v1beta1.InferenceService, host string) (*unstructured.Unstructured, error) {
+	desired := r.createCertificate(isvc, host)
+	existing, err := r.getCertificate(isvc)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		log.Info("Creating Certificate", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return nil, errors.Wrapf(err, "fails to create Certificate")
+		}
+		return desired, nil
+	}
+	if equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) {
+		return existing, nil
+	}
+	existing.Object["spec"] = desired.Object["spec"]
+	log.Info("Updating Certificate", "namespace", desired.GetNamespace(), "name", desired.GetName())
+	if err := r.client.Update(context.TODO(), existing); err != nil {
+		return nil, errors.Wrapf(err, "fails to update Certificate")
+	}
+	return existing, nil
+}
+
+func (r *CertificateReconciler) reconcileGateway(isvc *v1beta1.InferenceService, host string) error {
+	ingressGateway := strings.Split(r.ingressConfig.IngressGateway, "/")
+	if len(ingressGateway) != 2 {
+		return fmt.Errorf("invalid ingress gateway %s, <namespace>/<name> is required", r.ingressConfig.IngressGateway)
+	}
+	gateway := &v1alpha3.Gateway{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: ingressGateway[1], Namespace: ingressGateway[0]},
+		gateway); err != nil {
+		return errors.Wrapf(err, "fails to get ingress gateway %s", r.ingressConfig.IngressGateway)
+	}
+	desired := r.createGateway(isvc, host, gateway.Spec.Selector)
+	if err := controllerutil.SetControllerReference(isvc, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for Gateway")
+	}
+	existing := &v1alpha3.Gateway{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			log.Info("Creating TLS Gateway", "namespace", desired.Namespace, "name", desired.Name)
+			err = r.client.Create(context.TODO(), desired)
+		}
+	} else if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) {
+		existing.Spec = desired.Spec
+		log.Info("Updating TLS Gateway", "namespace", desired.Namespace, "name", desired.Name)
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update Gateway")
+	}
+	return nil
+}
+
+// Reconcile creates or updates the Certificate of the external host and the TLS Gateway, and propagates the readiness
+// of the Certificate to the CertificateReady condition of the inference service
+func (r *CertificateReconciler) Reconcile(isvc *v1beta1.InferenceService, host string) error {
+	certificate, err := r.reconcileCertificate(isvc, host)
+	if err != nil {
+		return err
+	}
+	if err := r.reconcileGateway(isvc, host); err != nil {
+		return err
+	}
+	isvc.Status.SetCondition(v1beta1.CertificateReady, getCertificateCondition(certificate))
+	return nil
+}
+
+// getCertificateCondition converts the Ready condition of the Certificate, the condition is unknown until
+// cert-manager reports it
+func getCertificateCondition(certificate *unstructured.Unstructured) *apis.Condition {
+	condition := &apis.Condition{
+		Type:   v1beta1.CertificateReady,
+		Status: corev1.ConditionUnknown,
+		Reason: "CertificateNotIssued",
+	}
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		c, ok := c.(map[string]interface{})
+		if !ok || c["type"] != "Ready" {
+			continue
+		}
+		if status, ok := c["status"].(string); ok {
+			condition.Status = corev1.ConditionStatus(status)
+		}
+		condition.Reason, _ = c["reason"].(string)
+		condition.Message, _ = c["message"].(string)
+	}
+	return condition
+}
+
+// Delete deletes the Certificate, its secret and the TLS Gateway once the inference service is no longer served over
+// HTTPS or is deleted
+func (r *CertificateReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	certificate, err := r.getCertificate(isvc)
+	if err != nil {
+		return err
+	}
+	if certificate != nil {
+		log.Info("Deleting Certificate", "namespace", certificate.GetNamespace(), "name", certificate.GetName())
+		if err := r.client.Delete(context.TODO(), certificate); err != nil && !apierr.IsNotFound(err) {
+			return errors.Wrapf(err, "fails to delete Certificate")
+		}
+	}
+	// cert-manager does not delete the secret of a deleted Certificate
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: constants.CertificateName(isvc.Name, isvc.Namespace),
+		Namespace: r.namespace()}}
+	if err := r.client.Delete(context.TODO(), secret); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete Certificate secret")
+	}
+	gateway := &v1alpha3.Gateway{ObjectMeta: metav1.ObjectMeta{Name: constants.TLSGatewayName(isvc.Name),
+		Namespace: isvc.Namespace}}
+	if err := r.client.Delete(context.TODO(), gateway); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete Gateway")
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const host = "my-model.default.example.com"
+
+func makeCertificate(conditions ...interface{}) *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": conditions,
+		},
+	}}
+	certificate.SetAPIVersion(constants.CertManagerAPIVersion)
+	certificate.SetKind(constants.CertManagerCertificate)
+	certificate.SetName(constants.CertificateName("my-model", "default"))
+	certificate.SetNamespace("istio-system")
+	return certificate
+}
+
+func TestCertificateReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	ingressGateway := &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "kfserving-ingress-gateway", Namespace: "knative-serving"},
+		Spec: istiov1alpha3.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+	scenarios := map[string]struct {
+		certificate     *unstructured.Unstructured
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		"NewCertificate": {
+			expectedStatus: corev1.ConditionUnknown,
+			expectedReason: "CertificateNotIssued",
+		},
+		"ReadyCertificate": {
+			certificate: makeCertificate(map[string]interface{}{
+				"type":   "Ready",
+				"status": "True",
+				"reason": "Ready",
+			}),
+			expectedStatus: corev1.ConditionTrue,
+		},
+		"FailedCertificate": {
+			certificate: makeCertificate(map[string]interface{}{
+				"type":    "Ready",
+				"status":  "False",
+				"reason":  "Failed",
+				"message": "rate limited",
+			}),
+			expectedStatus:  corev1.ConditionFalse,
+			expectedReason:  "Failed",
+			expectedMessage: "rate limited",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default", UID: "my-model-uid"},
+			}
+			objects := []runtime.Object{isvc.DeepCopy(), ingressGateway.DeepCopy()}
+			if scenario.certificate != nil {
+				objects = append(objects, scenario.certificate)
+			}
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := NewCertificateReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:    "knative-serving/kfserving-ingress-gateway",
+				CertificateIssuer: "letsencrypt",
+			})
+			g.Expect(r.Reconcile(isvc, host)).To(gomega.Succeed())
+
+			certificate, err := r.getCertificate(isvc)
+			g.Expect(err).ToNot(gomega.HaveOccurred())
+			g.Expect(certificate).ToNot(gomega.BeNil())
+			dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+			g.Expect(dnsNames).To(gomega.Equal([]string{host}))
+			issuer, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+			g.Expect(issuer).To(gomega.Equal(map[string]string{"name": "letsencrypt", "kind": "ClusterIssuer"}))
+
+			gateway := &v1alpha3.Gateway{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-tls", Namespace: "default"}, gateway)).To(gomega.Succeed())
+			g.Expect(gateway.Spec.Selector).To(gomega.Equal(map[string]string{"istio": "ingressgateway"}))
+			g.Expect(gateway.Spec.Servers[0].Hosts).To(gomega.Equal([]string{host}))
+			g.Expect(gateway.Spec.Servers[0].Tls.CredentialName).To(
+				gomega.Equal(constants.CertificateName("my-model", "default")))
+
+			condition := isvc.Status.GetCondition(v1beta1.CertificateReady)
+			g.Expect(condition).ToNot(gomega.BeNil())
+			g.Expect(condition.Status).To(gomega.Equal(scenario.expectedStatus))
+			g.Expect(condition.Reason).To(gomega.Equal(scenario.expectedReason))
+			g.Expect(condition.Message).To(gomega.Equal(scenario.expectedMessage))
+		})
+	}
+}
+
+func TestCertificateDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default"},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme,
+		makeCertificate(),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: constants.CertificateName("my-model", "default"),
+			Namespace: "istio-system"}},
+		&v1alpha3.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "my-model-tls", Namespace: "default"}},
+	)
+	r := NewCertificateReconciler(cl, scheme, &v1beta1.IngressConfig{CertificateIssuer: "letsencrypt"})
+	g.Expect(r.Delete(isvc)).To(gomega.Succeed())
+
+	certificate, err := r.getCertificate(isvc)
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(certificate).To(gomega.BeNil())
+	err = cl.Get(context.TODO(), types.NamespacedName{Name: constants.CertificateName("my-model", "default"),
+		Namespace: "istio-system"}, &corev1.Secret{})
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+	err = cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-tls", Namespace: "default"}, &v1alpha3.Gateway{})
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+
+	// Deleting again is a no-op
+	g.Expect(r.Delete(isvc)).To(gomega.Succeed())
+}
+
+func TestCertificateNameCollision(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// The inference services joining to the same namespace and name get distinct certificates
+	g.Expect(constants.CertificateName("c", "a-b")).ToNot(gomega.Equal(constants.CertificateName("b-c", "a")))
+	g.Expect(constants.CertificateName("c", "a-b")).To(gomega.Equal(constants.CertificateName("c", "a-b")))
+}
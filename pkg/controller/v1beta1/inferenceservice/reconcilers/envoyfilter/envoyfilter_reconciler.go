@@ -0,0 +1,243 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoyfilter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("EnvoyFilterReconciler")
+
+// EnvoyFilterReconciler reconciles the Istio EnvoyFilter inserting the Envoy filters limiting the request rate and the
+// request and response sizes in the inbound listener of the sidecars of the replicas of a component. The limits are
+// per replica. The EnvoyFilter is unstructured since the pinned Istio API predates the typed configuration of the
+// local rate limit filter.
+type EnvoyFilterReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	owner         metav1.Object
+	componentMeta metav1.ObjectMeta
+	componentExt  *v1beta1.ComponentExtensionSpec
+}
+
+func NewEnvoyFilterReconciler(client client.Client, scheme *runtime.Scheme, owner metav1.Object,
+	componentMeta metav1.ObjectMeta, componentExt *v1beta1.ComponentExtensionSpec) *EnvoyFilterReconciler {
+	return &EnvoyFilterReconciler{
+		client:        client,
+		scheme:        scheme,
+		owner:         owner,
+		componentMeta: componentMeta,
+		componentExt:  componentExt,
+	}
+}
+
+// createRateLimitPatch inserts the local rate limit filter, the token bucket is refilled with requestsPerSecond tokens
+// every second up to burst tokens
+func createRateLimitPatch(requestsPerSecond int64, burst *int64) map[string]interface{} {
+	maxTokens := requestsPerSecond
+	if burst != nil {
+		maxTokens = *burst
+	}
+	return createHTTPFilterPatch(constants.LocalRateLimitFilterName, map[string]interface{}{
+		"@type":    "type.googleapis.com/udpa.type.v1.TypedStruct",
+		"type_url": constants.LocalRateLimitTypeURL,
+		"value": map[string]interface{}{
+			"stat_prefix": "http_local_rate_limiter",
+			"token_bucket": map[string]interface{}{
+				"max_tokens":      maxTokens,
+				"tokens_per_fill": requestsPerSecond,
+				"fill_interval":   "1s",
+			},
+			"filter_enabled": map[string]interface{}{
+				"runtime_key":   "local_rate_limit_enabled",
+				"default_value": map[string]interface{}{"numerator": int64(100), "denominator": "HUNDRED"},
+			},
+			"filter_enforced": map[string]interface{}{
+				"runtime_key":   "local_rate_limit_enforced",
+				"default_value": map[string]interface{}{"numerator": int64(100), "denominator": "HUNDRED"},
+			},
+			"response_headers_to_add": []interface{}{
+				map[string]interface{}{
+					"append": false,
+					"header": map[string]interface{}{
+						"key":   constants.RateLimitedHeader,
+						"value": "true",
+					},
+				},
+			},
+		},
+	})
+}
+
+// createMaxRequestBytesPatch inserts the buffer filter, the requests are buffered up to maxRequestBytes and rejected
+// with 413 above
+func createMaxRequestBytesPatch(maxRequestBytes int64) map[string]interface{} {
+	return createHTTPFilterPatch(constants.BufferFilterName, map[string]interface{}{
+		"@type":             constants.BufferTypeURL,
+		"max_request_bytes": maxRequestBytes,
+	})
+}
+
+// maxResponseBytesScript replaces the responses with a Content-Length over the limit with a 502, the responses
+// without a Content-Length are streamed unchecked
+const maxResponseBytesScript = `function envoy_on_response(response_handle)
+  local length = tonumber(response_handle:headers():get("content-length"))
+  if length == nil or length <= %d then
+    return
+  end
+  response_handle:headers():replace(":status", "502")
+  response_handle:headers():replace("content-type", "text/plain")
+  response_handle:body():setBytes("response body exceeds maxResponseBytes")
+end
+`
+
+// createMaxResponseBytesPatch inserts the Lua filter checking the size of the responses
+func createMaxResponseBytesPatch(maxResponseBytes int64) map[string]interface{} {
+	return createHTTPFilterPatch(constants.LuaFilterName, map[string]interface{}{
+		"@type":      constants.LuaTypeURL,
+		"inlineCode": fmt.Sprintf(maxResponseBytesScript, maxResponseBytes),
+	})
+}
+
+// createHTTPFilterPatch inserts the HTTP filter before the router of the inbound listener of the sidecar, the filters
+// are run in the order of the patches
+func createHTTPFilterPatch(name string, typedConfig map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"applyTo": "HTTP_FILTER",
+		"match": map[string]interface{}{
+			"context": "SIDECAR_INBOUND",
+			"listener": map[string]interface{}{
+				"filterChain": map[string]interface{}{
+					"filter": map[string]interface{}{
+						"name": "envoy.filters.network.http_connection_manager",
+						"subFilter": map[string]interface{}{
+							"name": "envoy.filters.http.router",
+						},
+					},
+				},
+			},
+		},
+		"patch": map[string]interface{}{
+			"operation": "INSERT_BEFORE",
+			"value": map[string]interface{}{
+				"name":         name,
+				"typed_config": typedConfig,
+			},
+		},
+	}
+}
+
+// createEnvoyFilter returns the EnvoyFilter of the limits of the component, nil when the component is not limited.
+// The requests over the rate limit are rejected before being buffered.
+func createEnvoyFilter(componentMeta metav1.ObjectMeta, componentExt *v1beta1.ComponentExtensionSpec) *unstructured.Unstructured {
+	configPatches := []interface{}{}
+	if componentExt.RequestsPerSecond != nil {
+		configPatches = append(configPatches, createRateLimitPatch(*componentExt.RequestsPerSecond, componentExt.Burst))
+	}
+	if componentExt.MaxRequestBytes != nil {
+		configPatches = append(configPatches, createMaxRequestBytesPatch(*componentExt.MaxRequestBytes))
+	}
+	if componentExt.MaxResponseBytes != nil {
+		configPatches = append(configPatches, createMaxResponseBytesPatch(*componentExt.MaxResponseBytes))
+	}
+	if len(configPatches) == 0 {
+		return nil
+	}
+	envoyFilter := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"workloadSelector": map[string]interface{}{
+					"labels": map[string]interface{}{
+						constants.InferenceServicePodLabelKey: componentMeta.Labels[constants.InferenceServicePodLabelKey],
+						constants.KServiceComponentLabel:      componentMeta.Labels[constants.KServiceComponentLabel],
+					},
+				},
+				"configPatches": configPatches,
+			},
+		},
+	}
+	envoyFilter.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+	envoyFilter.SetKind(constants.IstioEnvoyFilter)
+	envoyFilter.SetName(constants.LimitsEnvoyFilterName(componentMeta.Name))
+	envoyFilter.SetNamespace(componentMeta.Namespace)
+	envoyFilter.SetLabels(componentMeta.Labels)
+	return envoyFilter
+}
+
+// Reconcile creates or updates the EnvoyFilter of the limited component, and deletes it once the component is no
+// longer limited. The missing EnvoyFilter CRD is only an error for the limited components so the clusters without
+// Istio are not affected.
+func (r *EnvoyFilterReconciler) Reconcile() error {
+	desired := createEnvoyFilter(r.componentMeta, r.componentExt)
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+	existing.SetKind(constants.IstioEnvoyFilter)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: constants.LimitsEnvoyFilterName(r.componentMeta.Name),
+		Namespace: r.componentMeta.Namespace}, existing)
+	if meta.IsNoMatchError(err) {
+		if desired == nil {
+			return nil
+		}
+		return fmt.Errorf("the %s CRD of Istio is not installed, it is required by requestsPerSecond, maxRequestBytes "+
+			"and maxResponseBytes", constants.IstioEnvoyFilter)
+	}
+	if err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if desired == nil {
+		if exists {
+			log.Info("Deleting limits EnvoyFilter", "namespace", existing.GetNamespace(), "name", existing.GetName())
+			if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+				return errors.Wrapf(err, "fails to delete EnvoyFilter")
+			}
+		}
+		return nil
+	}
+	if err := controllerutil.SetControllerReference(r.owner, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for EnvoyFilter")
+	}
+	if !exists {
+		log.Info("Creating limits EnvoyFilter", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(desired.GetLabels(), existing.GetLabels()) {
+		existing.Object["spec"] = desired.Object["spec"]
+		existing.SetLabels(desired.GetLabels())
+		log.Info("Updating limits EnvoyFilter", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update EnvoyFilter")
+	}
+	return nil
+}
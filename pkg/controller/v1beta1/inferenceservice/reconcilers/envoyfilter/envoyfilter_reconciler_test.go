@@ -0,0 +1,195 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoyfilter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateEnvoyFilter(t *testing.T) {
+	componentMeta := metav1.ObjectMeta{
+		Name:      "sklearn-predictor-default",
+		Namespace: "default",
+		Labels: map[string]string{
+			constants.InferenceServicePodLabelKey: "sklearn",
+			constants.KServiceComponentLabel:      "predictor",
+		},
+	}
+	scenarios := map[string]struct {
+		componentExt    *v1beta1.ComponentExtensionSpec
+		expectedFilters []string
+		expectedBucket  map[string]interface{}
+	}{
+		"NotLimited": {
+			componentExt: &v1beta1.ComponentExtensionSpec{},
+		},
+		"DefaultBurst": {
+			componentExt:    &v1beta1.ComponentExtensionSpec{RequestsPerSecond: proto.Int64(20)},
+			expectedFilters: []string{constants.LocalRateLimitFilterName},
+			expectedBucket: map[string]interface{}{
+				"max_tokens":      int64(20),
+				"tokens_per_fill": int64(20),
+				"fill_interval":   "1s",
+			},
+		},
+		"Burst": {
+			componentExt:    &v1beta1.ComponentExtensionSpec{RequestsPerSecond: proto.Int64(20), Burst: proto.Int64(50)},
+			expectedFilters: []string{constants.LocalRateLimitFilterName},
+			expectedBucket: map[string]interface{}{
+				"max_tokens":      int64(50),
+				"tokens_per_fill": int64(20),
+				"fill_interval":   "1s",
+			},
+		},
+		"SizeLimits": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MaxRequestBytes:  proto.Int64(1048576),
+				MaxResponseBytes: proto.Int64(4194304),
+			},
+			expectedFilters: []string{constants.BufferFilterName, constants.LuaFilterName},
+		},
+		"AllLimits": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				RequestsPerSecond: proto.Int64(20),
+				MaxRequestBytes:   proto.Int64(1048576),
+				MaxResponseBytes:  proto.Int64(4194304),
+			},
+			expectedFilters: []string{constants.LocalRateLimitFilterName, constants.BufferFilterName, constants.LuaFilterName},
+			expectedBucket: map[string]interface{}{
+				"max_tokens":      int64(20),
+				"tokens_per_fill": int64(20),
+				"fill_interval":   "1s",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			envoyFilter := createEnvoyFilter(componentMeta, scenario.componentExt)
+			if len(scenario.expectedFilters) == 0 {
+				g.Expect(envoyFilter).To(gomega.BeNil())
+				return
+			}
+			g.Expect(envoyFilter.GetAPIVersion()).To(gomega.Equal(constants.IstioNetworkingAPIVersion))
+			g.Expect(envoyFilter.GetKind()).To(gomega.Equal(constants.IstioEnvoyFilter))
+			g.Expect(envoyFilter.GetName()).To(gomega.Equal("sklearn-predictor-default-limits"))
+			g.Expect(envoyFilter.GetLabels()).To(gomega.Equal(componentMeta.Labels))
+			selector, _, _ := unstructured.NestedStringMap(envoyFilter.Object, "spec", "workloadSelector", "labels")
+			g.Expect(selector).To(gomega.Equal(componentMeta.Labels))
+			patches, _, _ := unstructured.NestedSlice(envoyFilter.Object, "spec", "configPatches")
+			filters := []string{}
+			for _, patch := range patches {
+				filter, _, _ := unstructured.NestedString(patch.(map[string]interface{}), "patch", "value", "name")
+				filters = append(filters, filter)
+				typedConfig, _, _ := unstructured.NestedMap(patch.(map[string]interface{}), "patch", "value", "typed_config")
+				switch filter {
+				case constants.LocalRateLimitFilterName:
+					bucket, _, _ := unstructured.NestedMap(typedConfig, "value", "token_bucket")
+					g.Expect(bucket).To(gomega.Equal(scenario.expectedBucket))
+				case constants.BufferFilterName:
+					g.Expect(typedConfig["max_request_bytes"]).To(gomega.Equal(*scenario.componentExt.MaxRequestBytes))
+				case constants.LuaFilterName:
+					g.Expect(typedConfig["inlineCode"]).To(gomega.ContainSubstring("length <= 4194304"))
+				}
+			}
+			g.Expect(filters).To(gomega.Equal(scenario.expectedFilters))
+			// The unstructured content must be deep copyable to be sent by the client
+			g.Expect(envoyFilter.DeepCopy()).To(gomega.Equal(envoyFilter))
+		})
+	}
+}
+
+func TestEnvoyFilterReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default", UID: "sklearn-uid"},
+	}
+	componentMeta := metav1.ObjectMeta{
+		Name:      "sklearn-predictor-default",
+		Namespace: "default",
+		Labels: map[string]string{
+			constants.InferenceServicePodLabelKey: "sklearn",
+			constants.KServiceComponentLabel:      "predictor",
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	getEnvoyFilter := func() (*unstructured.Unstructured, error) {
+		envoyFilter := &unstructured.Unstructured{}
+		envoyFilter.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+		envoyFilter.SetKind(constants.IstioEnvoyFilter)
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: "sklearn-predictor-default-limits",
+			Namespace: "default"}, envoyFilter)
+		return envoyFilter, err
+	}
+
+	// Nothing is created for the components without a rate limit
+	componentExt := &v1beta1.ComponentExtensionSpec{}
+	g.Expect(NewEnvoyFilterReconciler(cl, scheme, isvc, componentMeta, componentExt).Reconcile()).To(gomega.Succeed())
+	_, err := getEnvoyFilter()
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+
+	// The EnvoyFilter is created and owned by the inference service
+	componentExt.RequestsPerSecond = proto.Int64(20)
+	g.Expect(NewEnvoyFilterReconciler(cl, scheme, isvc, componentMeta, componentExt).Reconcile()).To(gomega.Succeed())
+	envoyFilter, err := getEnvoyFilter()
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(envoyFilter.GetOwnerReferences()).To(gomega.HaveLen(1))
+
+	// The EnvoyFilter is updated with the new burst
+	componentExt.Burst = proto.Int64(50)
+	g.Expect(NewEnvoyFilterReconciler(cl, scheme, isvc, componentMeta, componentExt).Reconcile()).To(gomega.Succeed())
+	envoyFilter, err = getEnvoyFilter()
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	patches, _, _ := unstructured.NestedSlice(envoyFilter.Object, "spec", "configPatches")
+	maxTokens, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}),
+		"patch", "value", "typed_config", "value", "token_bucket", "max_tokens")
+	g.Expect(maxTokens).To(gomega.Equal(int64(50)))
+
+	// The EnvoyFilter is kept while the component has a size limit
+	componentExt.RequestsPerSecond = nil
+	componentExt.Burst = nil
+	componentExt.MaxRequestBytes = proto.Int64(1048576)
+	g.Expect(NewEnvoyFilterReconciler(cl, scheme, isvc, componentMeta, componentExt).Reconcile()).To(gomega.Succeed())
+	envoyFilter, err = getEnvoyFilter()
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	patches, _, _ = unstructured.NestedSlice(envoyFilter.Object, "spec", "configPatches")
+	g.Expect(patches).To(gomega.HaveLen(1))
+
+	// The EnvoyFilter is deleted once the limits are removed
+	componentExt.MaxRequestBytes = nil
+	g.Expect(NewEnvoyFilterReconciler(cl, scheme, isvc, componentMeta, componentExt).Reconcile()).To(gomega.Succeed())
+	_, err = getEnvoyFilter()
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoyfilter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HedgingReconciler reconciles the Istio EnvoyFilter enabling the hedging of the requests on the routes of the hedged
+// components of an inference service at the ingress gateways. The routes retry the requests after the hedging delay,
+// the EnvoyFilter keeps the original requests running so the first response wins. The EnvoyFilter is in the namespace
+// of the ingress gateway pods, it is deleted with a finalizer.
+type HedgingReconciler struct {
+	client        client.Client
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewHedgingReconciler(client client.Client, ingressConfig *v1beta1.IngressConfig) *HedgingReconciler {
+	return &HedgingReconciler{
+		client:        client,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// gatewayNamespace returns the namespace of the ingress gateway pods, the namespace of the host of the ingress service
+func gatewayNamespace(ingressConfig *v1beta1.IngressConfig) string {
+	if parts := strings.Split(ingressConfig.IngressServiceName, "."); len(parts) > 1 && parts[1] != "" {
+		return parts[1]
+	}
+	return constants.DefaultCertificateNamespace
+}
+
+// createHedgePatch enables the hedging on the per try timeout of the routes of a component, the patch matches the
+// routes of both the external and the local gateways
+func createHedgePatch(routeName string) map[string]interface{} {
+	return map[string]interface{}{
+		"applyTo": "HTTP_ROUTE",
+		"match": map[string]interface{}{
+			"context": "GATEWAY",
+			"routeConfiguration": map[string]interface{}{
+				"vhost": map[string]interface{}{
+					"route": map[string]interface{}{"name": routeName},
+				},
+			},
+		},
+		"patch": map[string]interface{}{
+			"operation": "MERGE",
+			"value": map[string]interface{}{
+				"route": map[string]interface{}{
+					"hedge_policy": map[string]interface{}{"hedge_on_per_try_timeout": true},
+				},
+			},
+		},
+	}
+}
+
+// createHedgingEnvoyFilter returns the EnvoyFilter of the hedged components of the inference service, nil when no
+// component is hedged
+func (r *HedgingReconciler) createHedgingEnvoyFilter(isvc *v1beta1.InferenceService) *unstructured.Unstructured {
+	configPatches := []interface{}{}
+	for _, c := range []struct {
+		componentType v1beta1.ComponentType
+		hedged        bool
+	}{
+		{v1beta1.PredictorComponent, isvc.Spec.Predictor.Hedging != nil},
+		{v1beta1.TransformerComponent, isvc.Spec.Transformer != nil && isvc.Spec.Transformer.Hedging != nil},
+		{v1beta1.ExplainerComponent, isvc.Spec.Explainer != nil && isvc.Spec.Explainer.Hedging != nil},
+	} {
+		if c.hedged {
+			configPatches = append(configPatches, createHedgePatch(
+				constants.HedgingRouteName(isvc.Name, isvc.Namespace, string(c.componentType))))
+		}
+	}
+	if len(configPatches) == 0 {
+		return nil
+	}
+	envoyFilter := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"configPatches": configPatches,
+			},
+		},
+	}
+	envoyFilter.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+	envoyFilter.SetKind(constants.IstioEnvoyFilter)
+	envoyFilter.SetName(constants.HedgingEnvoyFilterName(isvc.Name, isvc.Namespace))
+	envoyFilter.SetNamespace(gatewayNamespace(r.ingressConfig))
+	envoyFilter.SetLabels(map[string]string{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+	})
+	return envoyFilter
+}
+
+// getHedgingEnvoyFilter gets the EnvoyFilter of the inference service, it returns nil when it does not exist and the
+// no match error when the EnvoyFilter CRD is not installed
+func (r *HedgingReconciler) getHedgingEnvoyFilter(isvc *v1beta1.InferenceService) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+	existing.SetKind(constants.IstioEnvoyFilter)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: constants.HedgingEnvoyFilterName(isvc.Name, isvc.Namespace),
+		Namespace: gatewayNamespace(r.ingressConfig)}, existing)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Reconcile creates or updates the EnvoyFilter of the hedged components of the inference service, and deletes it once
+// no component is hedged
+func (r *HedgingReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	desired := r.createHedgingEnvoyFilter(isvc)
+	if desired == nil {
+		return r.Delete(isvc)
+	}
+	existing, err := r.getHedgingEnvoyFilter(isvc)
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("the %s CRD of Istio is not installed, it is required by hedging", constants.IstioEnvoyFilter)
+	}
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		log.Info("Creating hedging EnvoyFilter", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) {
+		existing.Object["spec"] = desired.Object["spec"]
+		log.Info("Updating hedging EnvoyFilter", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update hedging EnvoyFilter")
+	}
+	return nil
+}
+
+// Delete deletes the EnvoyFilter of the inference service. The missing EnvoyFilter CRD is not an error so the clusters
+// without Istio are not affected.
+func (r *HedgingReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	existing, err := r.getHedgingEnvoyFilter(isvc)
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil || existing == nil {
+		return err
+	}
+	log.Info("Deleting hedging EnvoyFilter", "namespace", existing.GetNamespace(), "name", existing.GetName())
+	if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete hedging EnvoyFilter")
+	}
+	return nil
+}
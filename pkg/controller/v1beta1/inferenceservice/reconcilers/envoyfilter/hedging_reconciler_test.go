@@ -0,0 +1,114 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoyfilter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGatewayNamespace(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(gatewayNamespace(&v1beta1.IngressConfig{
+		IngressServiceName: "istio-ingressgateway.gateways.svc.cluster.local",
+	})).To(gomega.Equal("gateways"))
+	g.Expect(gatewayNamespace(&v1beta1.IngressConfig{IngressServiceName: "istio-ingressgateway"})).
+		To(gomega.Equal(constants.DefaultCertificateNamespace))
+}
+
+func TestHedgingReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	ingressConfig := &v1beta1.IngressConfig{
+		IngressServiceName: "istio-ingressgateway.istio-system.svc.cluster.local",
+	}
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Explainer: &v1beta1.ExplainerSpec{},
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme)
+	getEnvoyFilter := func() (*unstructured.Unstructured, error) {
+		envoyFilter := &unstructured.Unstructured{}
+		envoyFilter.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+		envoyFilter.SetKind(constants.IstioEnvoyFilter)
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: constants.HedgingEnvoyFilterName("sklearn", "default"),
+			Namespace: "istio-system"}, envoyFilter)
+		return envoyFilter, err
+	}
+	routeNames := func(envoyFilter *unstructured.Unstructured) []string {
+		names := []string{}
+		patches, _, _ := unstructured.NestedSlice(envoyFilter.Object, "spec", "configPatches")
+		for _, patch := range patches {
+			name, _, _ := unstructured.NestedString(patch.(map[string]interface{}),
+				"match", "routeConfiguration", "vhost", "route", "name")
+			names = append(names, name)
+			hedge, _, _ := unstructured.NestedBool(patch.(map[string]interface{}),
+				"patch", "value", "route", "hedge_policy", "hedge_on_per_try_timeout")
+			g.Expect(hedge).To(gomega.BeTrue())
+		}
+		return names
+	}
+
+	// Nothing is created for the inference services without hedging
+	g.Expect(NewHedgingReconciler(cl, ingressConfig).Reconcile(isvc)).To(gomega.Succeed())
+	_, err := getEnvoyFilter()
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+
+	// The EnvoyFilter is created in the namespace of the ingress gateway for the routes of the hedged predictor
+	isvc.Spec.Predictor.Hedging = &v1beta1.HedgingSpec{}
+	g.Expect(NewHedgingReconciler(cl, ingressConfig).Reconcile(isvc)).To(gomega.Succeed())
+	envoyFilter, err := getEnvoyFilter()
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(envoyFilter.GetLabels()).To(gomega.HaveKeyWithValue(constants.InferenceServicePodLabelKey, "sklearn"))
+	g.Expect(routeNames(envoyFilter)).To(gomega.Equal([]string{"default.sklearn.predictor.hedging"}))
+	// The unstructured content must be deep copyable to be sent by the client
+	g.Expect(envoyFilter.DeepCopy()).To(gomega.Equal(envoyFilter))
+
+	// The EnvoyFilter is updated with the routes of the hedged explainer
+	isvc.Spec.Explainer.Hedging = &v1beta1.HedgingSpec{}
+	g.Expect(NewHedgingReconciler(cl, ingressConfig).Reconcile(isvc)).To(gomega.Succeed())
+	envoyFilter, err = getEnvoyFilter()
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(routeNames(envoyFilter)).To(gomega.Equal([]string{"default.sklearn.predictor.hedging",
+		"default.sklearn.explainer.hedging"}))
+
+	// The EnvoyFilter is deleted once no component is hedged
+	isvc.Spec.Predictor.Hedging = nil
+	isvc.Spec.Explainer.Hedging = nil
+	g.Expect(NewHedgingReconciler(cl, ingressConfig).Reconcile(isvc)).To(gomega.Succeed())
+	_, err = getEnvoyFilter()
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+
+	// Deleting the missing EnvoyFilter is not an error
+	g.Expect(NewHedgingReconciler(cl, ingressConfig).Delete(isvc)).To(gomega.Succeed())
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("FederationReconciler")
+
+// FederationReconciler propagates an inference service to the member clusters of its federation placement and rolls
+// up the statuses of its copies. The copies are deleted from the member clusters no longer placed, the copies are
+// not owned by the inference service since they are in other clusters.
+type FederationReconciler struct {
+	client   client.Client
+	clusters *MemberClusters
+}
+
+func NewFederationReconciler(client client.Client, clusters *MemberClusters) *FederationReconciler {
+	return &FederationReconciler{
+		client:   client,
+		clusters: clusters,
+	}
+}
+
+// listMemberClusters returns the secrets of the member clusters by cluster name
+func (r *FederationReconciler) listMemberClusters() (map[string]*v1.Secret, error) {
+	secrets := &v1.SecretList{}
+	if err := r.client.List(context.TODO(), secrets, client.InNamespace(constants.KFServingNamespace),
+		client.MatchingLabels{constants.MemberClusterLabelKey: "true"}); err != nil {
+		return nil, errors.Wrapf(err, "fails to list member clusters")
+	}
+	clusters := map[string]*v1.Secret{}
+	for i := range secrets.Items {
+		clusters[secrets.Items[i].Name] = &secrets.Items[i]
+	}
+	return clusters, nil
+}
+
+// placeClusters returns the names of the member clusters of the federation placement, the named clusters which are
+// not registered included
+func placeClusters(federation *v1beta1.FederationSpec, clusters map[string]*v1.Secret) ([]string, error) {
+	selector := labels.Nothing()
+	if federation.ClusterSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(federation.ClusterSelector); err != nil {
+			return nil, err
+		}
+	}
+	placed := append([]string{}, federation.Clusters...)
+	for name, secret := range clusters {
+		if !utils.Includes(placed, name) && selector.Matches(labels.Set(secret.Labels)) {
+			placed = append(placed, name)
+		}
+	}
+	sort.Strings(placed)
+	return placed, nil
+}
+
+// getGlobalHost returns the global host of the inference service generated with the domain template of the federation
+// config, empty without domain template
+func getGlobalHost(isvc *v1beta1.InferenceService, federationConfig *v1beta1.FederationConfig) (string, error) {
+	if federationConfig.DomainTemplate == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("domain").Parse(federationConfig.DomainTemplate)
+	if err != nil {
+		return "", errors.Wrapf(err, "fails to parse federation domain template")
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, map[string]interface{}{
+		"Name":        isvc.Name,
+		"Namespace":   isvc.Namespace,
+		"Annotations": isvc.Annotations,
+		"Labels":      isvc.Labels,
+	}); err != nil {
+		return "", errors.Wrapf(err, "fails to execute federation domain template")
+	}
+	host := buf.String()
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return "", fmt.Errorf("invalid global host %q: %s", host, strings.Join(errs, ", "))
+	}
+	return host, nil
+}
+
+// createMember returns the copy of the inference service in the member clusters, served on the global host when set
+func createMember(isvc *v1beta1.InferenceService, globalHost string) *v1beta1.InferenceService {
+	member := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        isvc.Name,
+			Namespace:   isvc.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *isvc.Spec.DeepCopy(),
+	}
+	member.Spec.Federation = nil
+	for k, v := range isvc.Labels {
+		member.Labels[k] = v
+	}
+	for k, v := range isvc.Annotations {
+		if k != v1.LastAppliedConfigAnnotation {
+			member.Annotations[k] = v
+		}
+	}
+	member.Annotations[constants.FederatedFromAnnotationKey] = isvc.Namespace + "/" + isvc.Name
+	if globalHost != "" {
+		member.Annotations[constants.IngressHostAnnotationKey] = globalHost
+	}
+	return member
+}
+
+// getMember gets the copy of the inference service in a member cluster, it returns nil when it does not exist
+func getMember(cl client.Client, isvc *v1beta1.InferenceService) (*v1beta1.InferenceService, error) {
+	existing := &v1beta1.InferenceService{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, existing); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+// isMember returns true when the inference service of a member cluster is a copy of the inference service
+func isMember(existing *v1beta1.InferenceService, isvc *v1beta1.InferenceService) bool {
+	return existing.Annotations[constants.FederatedFromAnnotationKey] == isvc.Namespace+"/"+isvc.Name
+}
+
+// defaultMember defaults the copy of the inference service with the config of the member cluster, as the defaulting
+// webhook of the member cluster does, so the copy compares equal to the one stored in the member cluster. It returns
+// false when the global host is not under the host override domains of the ingress config of the member cluster,
+// the validating webhook of the member cluster would reject the copy.
+func defaultMember(cl client.Client, member *v1beta1.InferenceService, globalHost string) (bool, error) {
+	ingressConfig, err := v1beta1.NewIngressConfig(cl, member.Namespace)
+	if err != nil {
+		return false, errors.Wrapf(err, "fails to get the ingress config of the member cluster")
+	}
+	if globalHost != "" && !v1beta1.IsHostOverrideAllowed(ingressConfig, globalHost) {
+		return false, nil
+	}
+	config, err := v1beta1.NewInferenceServicesConfig(cl, member.Namespace)
+	if err != nil {
+		return false, errors.Wrapf(err, "fails to get the inference services config of the member cluster")
+	}
+	member.DefaultInferenceService(config)
+	return true, nil
+}
+
+// reconcileMember creates or updates the copy of the inference service in a member cluster, an inference service of
+// the same name which is not a copy is left untouched. The desired copy must be defaulted with defaultMember.
+func reconcileMember(cl client.Client, desired *v1beta1.InferenceService) (*v1beta1.InferenceService, error) {
+	existing, err := getMember(cl, desired)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !isMember(existing, desired) {
+		return nil, fmt.Errorf("an inference service %s/%s not federated from this cluster already exists",
+			desired.Namespace, desired.Name)
+	}
+	if existing == nil {
+		log.Info("Creating federated inference service", "namespace", desired.Namespace, "name", desired.Name)
+		if err := cl.Create(context.TODO(), desired); err != nil {
+			return nil, errors.Wrapf(err, "fails to create federated inference service")
+		}
+		return desired, nil
+	}
+	if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) ||
+		!equality.Semantic.DeepEqual(desired.Labels, existing.Labels) ||
+		!equality.Semantic.DeepEqual(desired.Annotations, existing.Annotations) {
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		log.Info("Updating federated inference service", "namespace", desired.Namespace, "name", desired.Name)
+		if err := cl.Update(context.TODO(), existing); err != nil {
+			return nil, errors.Wrapf(err, "fails to update federated inference service")
+		}
+	}
+	return existing, nil
+}
+
+// deleteMember deletes the copy of the inference service in the member cluster of the secret
+func (r *FederationReconciler) deleteMember(isvc *v1beta1.InferenceService, secret *v1.Secret) error {
+	cl, err := r.clusters.Client(secret)
+	if err != nil {
+		return err
+	}
+	existing, err := getMember(cl, isvc)
+	if err != nil || existing == nil || !isMember(existing, isvc) {
+		return err
+	}
+	log.Info("Deleting federated inference service", "cluster", secret.Name, "namespace", isvc.Namespace,
+		"name", isvc.Name)
+	if err := cl.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete federated inference service")
+	}
+	return nil
+}
+
+// memberStatus returns the status of the copy of the inference service in the member cluster of the secret, the
+// failures to reach the member cluster are reported in the message. It returns false when the global host is not
+// allowed by the member cluster, the inference service is then not propagated to it.
+func (r *FederationReconciler) memberStatus(desired *v1beta1.InferenceService, name string, secret *v1.Secret,
+	globalHost string) (v1beta1.FederatedClusterStatus, bool) {
+	status := v1beta1.FederatedClusterStatus{Name: name}
+	if secret == nil {
+		status.Message = "the member cluster is not registered"
+		return status, true
+	}
+	status.Region = secret.Labels[constants.RegionLabelKey]
+	cl, err := r.clusters.Client(secret)
+	if err == nil {
+		member := desired.DeepCopy()
+		var hostAllowed bool
+		if hostAllowed, err = defaultMember(cl, member, globalHost); err == nil && !hostAllowed {
+			status.Message = fmt.Sprintf("the global host %s is not under the hostOverrideDomains of the ingress "+
+				"config of the member cluster", globalHost)
+			return status, false
+		}
+		if err == nil {
+			if member, err = reconcileMember(cl, member); err == nil {
+				status.URL = member.Status.URL
+				status.Ready = member.Status.IsReady()
+				if ready := member.Status.GetCondition(apis.ConditionReady); !status.Ready && ready != nil {
+					status.Message = ready.Message
+				}
+			}
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to propagate inference service", "cluster", name, "namespace", desired.Namespace,
+			"name", desired.Name)
+		status.Message = err.Error()
+	}
+	return status, true
+}
+
+// Reconcile propagates the inference service to the member clusters of its federation placement, deletes its copies
+// from the member clusters no longer placed and sets its federation status and the FederationReady condition. All
+// the copies are deleted once the inference service is no longer federated or its namespace is no longer allowed by
+// the federation config, the copies are created with the credentials of the member clusters rather than the ones of
+// the users of the namespace.
+func (r *FederationReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if isvc.Spec.Federation == nil {
+		if err := r.Delete(isvc); err != nil {
+			return err
+		}
+		isvc.Status.Federation = nil
+		isvc.Status.ClearCondition(v1beta1.FederationReady)
+		return nil
+	}
+	federationConfig, err := v1beta1.NewFederationConfig(r.client)
+	if err != nil {
+		return errors.Wrapf(err, "fails to create FederationConfig")
+	}
+	if !v1beta1.IsFederationAllowed(federationConfig, isvc.Namespace) {
+		if err := r.Delete(isvc); err != nil {
+			return err
+		}
+		isvc.Status.Federation = nil
+		isvc.Status.MarkFederationNotAllowed(isvc.Namespace)
+		return nil
+	}
+	globalHost, err := getGlobalHost(isvc, federationConfig)
+	if err != nil {
+		return err
+	}
+	clusters, err := r.listMemberClusters()
+	if err != nil {
+		return err
+	}
+	placed, err := placeClusters(isvc.Spec.Federation, clusters)
+	if err != nil {
+		return err
+	}
+	// The copies of the clusters no longer placed are deleted first, the clusters which are no longer registered
+	// can not be reached
+	if isvc.Status.Federation != nil {
+		for _, cluster := range isvc.Status.Federation.Clusters {
+			if secret, ok := clusters[cluster.Name]; ok && !utils.Includes(placed, cluster.Name) {
+				if err := r.deleteMember(isvc, secret); err != nil {
+					return errors.Wrapf(err, "fails to delete inference service from member cluster %s", cluster.Name)
+				}
+			}
+		}
+	}
+	desired := createMember(isvc, globalHost)
+	federationStatus := &v1beta1.FederationStatus{Clusters: []v1beta1.FederatedClusterStatus{}}
+	if globalHost != "" {
+		federationStatus.URL = &apis.URL{Scheme: "http", Host: globalHost}
+	}
+	hostNotAllowed := []string{}
+	for _, name := range placed {
+		status, hostAllowed := r.memberStatus(desired, name, clusters[name], globalHost)
+		if !hostAllowed {
+			hostNotAllowed = append(hostNotAllowed, name)
+		}
+		federationStatus.Clusters = append(federationStatus.Clusters, status)
+	}
+	isvc.Status.Federation = federationStatus
+	isvc.Status.MarkFederationReady()
+	if len(hostNotAllowed) > 0 {
+		isvc.Status.MarkFederationHostNotAllowed(globalHost, hostNotAllowed)
+	}
+	return nil
+}
+
+// Delete deletes the copies of the inference service from the member clusters of its federation status, the clusters
+// which are no longer registered are skipped
+func (r *FederationReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	if isvc.Status.Federation == nil {
+		return nil
+	}
+	clusters, err := r.listMemberClusters()
+	if err != nil {
+		return err
+	}
+	for _, cluster := range isvc.Status.Federation.Clusters {
+		if secret, ok := clusters[cluster.Name]; ok {
+			if err := r.deleteMember(isvc, secret); err != nil {
+				return errors.Wrapf(err, "fails to delete inference service from member cluster %s", cluster.Name)
+			}
+		}
+	}
+	return nil
+}
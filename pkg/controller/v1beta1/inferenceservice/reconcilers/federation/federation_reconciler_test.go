@@ -0,0 +1,270 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeMemberCluster(name string, region string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: constants.KFServingNamespace,
+			Labels: map[string]string{
+				constants.MemberClusterLabelKey: "true",
+				constants.RegionLabelKey:        region,
+			},
+		},
+		Data: map[string][]byte{constants.MemberClusterKubeconfigKey: []byte(name)},
+	}
+}
+
+func TestPlaceClusters(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	clusters := map[string]*v1.Secret{
+		"us-east": makeMemberCluster("us-east", "us-east1"),
+		"us-west": makeMemberCluster("us-west", "us-west1"),
+		"europe":  makeMemberCluster("europe", "europe-west1"),
+	}
+	scenarios := map[string]struct {
+		federation *v1beta1.FederationSpec
+		expected   []string
+	}{
+		"Clusters": {
+			federation: &v1beta1.FederationSpec{Clusters: []string{"us-west", "europe"}},
+			expected:   []string{"europe", "us-west"},
+		},
+		"UnregisteredCluster": {
+			federation: &v1beta1.FederationSpec{Clusters: []string{"asia"}},
+			expected:   []string{"asia"},
+		},
+		"ClusterSelector": {
+			federation: &v1beta1.FederationSpec{ClusterSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      constants.RegionLabelKey,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{"us-east1", "us-west1"},
+				}},
+			}},
+			expected: []string{"us-east", "us-west"},
+		},
+		"ClustersAndClusterSelector": {
+			federation: &v1beta1.FederationSpec{
+				Clusters: []string{"europe", "us-east"},
+				ClusterSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{constants.RegionLabelKey: "us-east1"},
+				},
+			},
+			expected: []string{"europe", "us-east"},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			placed, err := placeClusters(scenario.federation, clusters)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(placed).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestFederationReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		Data: map[string]string{
+			v1beta1.FederationConfigKeyName: `{"domainTemplate": "{{.Name}}-{{.Namespace}}.global.example.com", ` +
+				`"allowedNamespaces": ["default"]}`,
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, configMap, makeMemberCluster("us-east", "us-east1"),
+		makeMemberCluster("europe", "europe-west1"))
+	memberIngress := func(domain string) string {
+		return `{"ingressGateway": "knative-serving/knative-ingress-gateway", ` +
+			`"ingressService": "istio-ingressgateway.istio-system.svc.cluster.local", ` +
+			`"hostOverrideDomains": ["` + domain + `"]}`
+	}
+	makeMemberConfigMap := func() *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+			Data: map[string]string{
+				v1beta1.PredictorConfigKeyName: `{"sklearn": {"defaultImageVersion": "0.2.0"}}`,
+				v1beta1.IngressConfigKeyName:   memberIngress("global.example.com"),
+			},
+		}
+	}
+	usEastConfigMap := makeMemberConfigMap()
+	members := map[string]client.Client{
+		"us-east": fake.NewFakeClientWithScheme(scheme, usEastConfigMap),
+		"europe": fake.NewFakeClientWithScheme(scheme, makeMemberConfigMap(), &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"},
+		}),
+	}
+	clusters := NewMemberClusters(func(kubeconfig []byte) (client.Client, error) {
+		if member, ok := members[string(kubeconfig)]; ok {
+			return member, nil
+		}
+		return nil, fmt.Errorf("unknown cluster %s", kubeconfig)
+	})
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sklearn",
+			Namespace: "default",
+			Labels:    map[string]string{"team": "fraud"},
+			Annotations: map[string]string{
+				v1.LastAppliedConfigAnnotation: "{}",
+			},
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: proto.String("gs://models/sklearn")},
+				},
+			},
+			Federation: &v1beta1.FederationSpec{Clusters: []string{"us-east", "europe", "asia"}},
+		},
+	}
+	getMemberCopy := func(cluster string) (*v1beta1.InferenceService, error) {
+		member := &v1beta1.InferenceService{}
+		err := members[cluster].Get(context.TODO(), types.NamespacedName{Name: "sklearn", Namespace: "default"}, member)
+		return member, err
+	}
+
+	// The inference service is propagated to the registered clusters, the inference service of the same name in
+	// europe is not a copy and the asia cluster is not registered
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	member, err := getMemberCopy("us-east")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(member.Spec.Federation).To(gomega.BeNil())
+	g.Expect(member.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(proto.String("gs://models/sklearn")))
+	g.Expect(member.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.Equal(proto.String("0.2.0")))
+	g.Expect(member.Labels).To(gomega.Equal(map[string]string{"team": "fraud"}))
+	g.Expect(member.Annotations).To(gomega.Equal(map[string]string{
+		constants.FederatedFromAnnotationKey: "default/sklearn",
+		constants.IngressHostAnnotationKey:   "sklearn-default.global.example.com",
+	}))
+	member, err = getMemberCopy("europe")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(member.Spec.Predictor.SKLearn).To(gomega.BeNil())
+
+	g.Expect(isvc.Status.Federation.URL.String()).To(gomega.Equal("http://sklearn-default.global.example.com"))
+	g.Expect(isvc.Status.Federation.Clusters).To(gomega.HaveLen(3))
+	asia, europe, usEast := isvc.Status.Federation.Clusters[0], isvc.Status.Federation.Clusters[1],
+		isvc.Status.Federation.Clusters[2]
+	g.Expect(asia.Name).To(gomega.Equal("asia"))
+	g.Expect(asia.Message).To(gomega.ContainSubstring("not registered"))
+	g.Expect(europe.Region).To(gomega.Equal("europe-west1"))
+	g.Expect(europe.Message).To(gomega.ContainSubstring("not federated from this cluster"))
+	g.Expect(usEast.Region).To(gomega.Equal("us-east1"))
+	g.Expect(usEast.Ready).To(gomega.BeFalse())
+	condition := isvc.Status.GetCondition(v1beta1.FederationReady)
+	g.Expect(condition.Status).To(gomega.Equal(v1.ConditionFalse))
+	g.Expect(condition.Reason).To(gomega.Equal(v1beta1.MemberClusterNotReady))
+
+	// The readiness and the URL of the copies are rolled up
+	member, _ = getMemberCopy("us-east")
+	member.Status.URL = &apis.URL{Scheme: "http", Host: "sklearn-default.global.example.com"}
+	member.Status.InitializeConditions()
+	member.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	member.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{Status: v1.ConditionTrue})
+	g.Expect(members["us-east"].Status().Update(context.TODO(), member)).To(gomega.Succeed())
+	isvc.Spec.Federation.Clusters = []string{"us-east"}
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.Federation.Clusters).To(gomega.Equal([]v1beta1.FederatedClusterStatus{{
+		Name:   "us-east",
+		Region: "us-east1",
+		URL:    &apis.URL{Scheme: "http", Host: "sklearn-default.global.example.com"},
+		Ready:  true,
+	}}))
+	g.Expect(isvc.Status.IsConditionReady(v1beta1.FederationReady)).To(gomega.BeTrue())
+	// The inference service of europe which is not a copy is kept
+	_, err = getMemberCopy("europe")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The copies defaulted by the member clusters are not updated while the inference service is unchanged
+	member, _ = getMemberCopy("us-east")
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	unchanged, _ := getMemberCopy("us-east")
+	g.Expect(unchanged.ResourceVersion).To(gomega.Equal(member.ResourceVersion))
+
+	// The copies are updated with the spec of the inference service
+	isvc.Spec.Predictor.SKLearn.StorageURI = proto.String("gs://models/sklearn-v2")
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	member, _ = getMemberCopy("us-east")
+	g.Expect(member.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(proto.String("gs://models/sklearn-v2")))
+	g.Expect(member.Spec.Predictor.SKLearn.RuntimeVersion).To(gomega.Equal(proto.String("0.2.0")))
+
+	// The inference service is not propagated to the member clusters which do not allow the global host
+	usEastConfigMap.Data[v1beta1.IngressConfigKeyName] = memberIngress("models.example.com")
+	g.Expect(members["us-east"].Update(context.TODO(), usEastConfigMap)).To(gomega.Succeed())
+	isvc.Spec.Predictor.SKLearn.StorageURI = proto.String("gs://models/sklearn-v3")
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	member, _ = getMemberCopy("us-east")
+	g.Expect(member.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal(proto.String("gs://models/sklearn-v2")))
+	g.Expect(isvc.Status.Federation.Clusters[0].Message).To(gomega.ContainSubstring("hostOverrideDomains"))
+	condition = isvc.Status.GetCondition(v1beta1.FederationReady)
+	g.Expect(condition.Status).To(gomega.Equal(v1.ConditionFalse))
+	g.Expect(condition.Reason).To(gomega.Equal(v1beta1.GlobalHostNotAllowed))
+	g.Expect(condition.Message).To(gomega.ContainSubstring("us-east"))
+	usEastConfigMap.Data[v1beta1.IngressConfigKeyName] = memberIngress("global.example.com")
+	g.Expect(members["us-east"].Update(context.TODO(), usEastConfigMap)).To(gomega.Succeed())
+
+	// The copies are deleted once the namespace is no longer allowed by the federation config
+	allowedNamespaces := configMap.Data[v1beta1.FederationConfigKeyName]
+	configMap.Data[v1beta1.FederationConfigKeyName] = `{"allowedNamespaces": ["fraud"]}`
+	g.Expect(cl.Update(context.TODO(), configMap)).To(gomega.Succeed())
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	_, err = getMemberCopy("us-east")
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+	g.Expect(isvc.Status.Federation).To(gomega.BeNil())
+	condition = isvc.Status.GetCondition(v1beta1.FederationReady)
+	g.Expect(condition.Status).To(gomega.Equal(v1.ConditionFalse))
+	g.Expect(condition.Reason).To(gomega.Equal(v1beta1.NamespaceNotFederated))
+	configMap.Data[v1beta1.FederationConfigKeyName] = allowedNamespaces
+	g.Expect(cl.Update(context.TODO(), configMap)).To(gomega.Succeed())
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	_, err = getMemberCopy("us-east")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// The copies are deleted once the inference service is no longer federated
+	isvc.Spec.Federation = nil
+	g.Expect(NewFederationReconciler(cl, clusters).Reconcile(isvc)).To(gomega.Succeed())
+	_, err = getMemberCopy("us-east")
+	g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+	g.Expect(isvc.Status.Federation).To(gomega.BeNil())
+	g.Expect(isvc.Status.GetCondition(v1beta1.FederationReady)).To(gomega.BeNil())
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"sync"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClientFactory creates the client of a member cluster from its kubeconfig
+type ClientFactory func(kubeconfig []byte) (client.Client, error)
+
+// NewMemberClient creates the client of the inference services and of the config maps of a member cluster from its
+// kubeconfig, the copies are defaulted and validated with the inferenceservice-config of the member cluster
+func NewMemberClient(kubeconfig []byte) (client.Client, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fails to parse kubeconfig")
+	}
+	scheme := runtime.NewScheme()
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+type memberClient struct {
+	resourceVersion string
+	client          client.Client
+}
+
+// MemberClusters caches the clients of the member clusters, the client of a member cluster is created again once the
+// secret of its kubeconfig changes
+type MemberClusters struct {
+	newClient ClientFactory
+	mu        sync.Mutex
+	clients   map[string]memberClient
+}
+
+func NewMemberClusters(newClient ClientFactory) *MemberClusters {
+	return &MemberClusters{
+		newClient: newClient,
+		clients:   map[string]memberClient{},
+	}
+}
+
+// Client returns the client of the member cluster of the secret
+func (m *MemberClusters) Client(secret *v1.Secret) (client.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cached, ok := m.clients[secret.Name]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.client, nil
+	}
+	kubeconfig, ok := secret.Data[constants.MemberClusterKubeconfigKey]
+	if !ok {
+		return nil, errors.Errorf("the secret of the member cluster has no %s key", constants.MemberClusterKubeconfigKey)
+	}
+	cl, err := m.newClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	m.clients[secret.Name] = memberClient{resourceVersion: secret.ResourceVersion, client: cl}
+	return cl, nil
+}
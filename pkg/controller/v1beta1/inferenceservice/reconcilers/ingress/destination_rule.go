@@ -0,0 +1,247 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// servingRevisions returns the revisions of a component receiving traffic: the latest ready revision, the previous
+// one during a canary rollout and the revision pinned by a rollback
+func servingRevisions(isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	componentExt *v1beta1.ComponentExtensionSpec) []string {
+	status, ok := isvc.Status.Components[component]
+	if !ok {
+		return nil
+	}
+	candidates := []string{status.LatestReadyRevision, status.PinnedRevision}
+	if componentExt.CanaryTrafficPercent != nil {
+		candidates = append(candidates, status.PreviousReadyRevision)
+	}
+	revisions := []string{}
+	seen := map[string]bool{}
+	for _, revision := range candidates {
+		if revision != "" && !seen[revision] {
+			seen[revision] = true
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+// createTrafficPolicy returns the traffic policy of the revisions of a component from its circuit breaker, or of the
+// host of the predictor from the configuration of the calls of the transformer. The connection limit of the circuit
+// breaker takes precedence over the one of the calls. The host is called over Istio mutual TLS when mtls is set.
+func createTrafficPolicy(circuitBreaker *v1beta1.CircuitBreaker, predictorCall *v1beta1.PredictorCallSpec,
+	mtls bool) *istiov1alpha3.TrafficPolicy {
+	trafficPolicy := &istiov1alpha3.TrafficPolicy{}
+	if mtls {
+		trafficPolicy.Tls = &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL}
+	}
+	tcp := &istiov1alpha3.ConnectionPoolSettings_TCPSettings{}
+	http := &istiov1alpha3.ConnectionPoolSettings_HTTPSettings{}
+	if predictorCall != nil {
+		tcp.MaxConnections = predictorCall.MaxConnections
+		if predictorCall.IdleTimeout != nil {
+			http.IdleTimeout = gogotypes.DurationProto(predictorCall.IdleTimeout.Duration)
+		}
+		if predictorCall.Protocol == v1beta1.PredictorCallHTTP2 {
+			http.H2UpgradePolicy = istiov1alpha3.ConnectionPoolSettings_HTTPSettings_UPGRADE
+		}
+		if predictorCall.StrictMTLS {
+			trafficPolicy.Tls = &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL}
+		}
+	}
+	if circuitBreaker != nil {
+		trafficPolicy.OutlierDetection = &istiov1alpha3.OutlierDetection{
+			ConsecutiveErrors:  circuitBreaker.ConsecutiveErrors,
+			MaxEjectionPercent: circuitBreaker.MaxEjectionPercent,
+		}
+		if circuitBreaker.Interval != nil {
+			trafficPolicy.OutlierDetection.Interval = gogotypes.DurationProto(circuitBreaker.Interval.Duration)
+		}
+		if circuitBreaker.BaseEjectionTime != nil {
+			trafficPolicy.OutlierDetection.BaseEjectionTime = gogotypes.DurationProto(circuitBreaker.BaseEjectionTime.Duration)
+		}
+		if circuitBreaker.MaxConnections > 0 {
+			tcp.MaxConnections = circuitBreaker.MaxConnections
+		}
+		http.Http1MaxPendingRequests = circuitBreaker.MaxPendingRequests
+	}
+	connectionPool := &istiov1alpha3.ConnectionPoolSettings{}
+	if tcp.MaxConnections > 0 {
+		connectionPool.Tcp = tcp
+	}
+	if !equality.Semantic.DeepEqual(http, &istiov1alpha3.ConnectionPoolSettings_HTTPSettings{}) {
+		connectionPool.Http = http
+	}
+	if connectionPool.Tcp != nil || connectionPool.Http != nil {
+		trafficPolicy.ConnectionPool = connectionPool
+	}
+	return trafficPolicy
+}
+
+// createDestinationRules returns the DestinationRules of the components with a circuit breaker, or of all the
+// components when the mesh config sets a mutual TLS mode, one per revision receiving traffic since the Knative routes
+// address the revisions by their own service. The calls of a configured transformer get a DestinationRule of the host
+// of the predictor it calls.
+func createDestinationRules(isvc *v1beta1.InferenceService, meshConfig *v1beta1.MeshConfig) []*v1alpha3.DestinationRule {
+	mtls := meshConfig != nil && meshConfig.MTLSMode != ""
+	components := map[v1beta1.ComponentType]*v1beta1.ComponentExtensionSpec{
+		v1beta1.PredictorComponent: &isvc.Spec.Predictor.ComponentExtensionSpec,
+	}
+	if isvc.Spec.Transformer != nil {
+		components[v1beta1.TransformerComponent] = &isvc.Spec.Transformer.ComponentExtensionSpec
+	}
+	if isvc.Spec.Explainer != nil {
+		components[v1beta1.ExplainerComponent] = &isvc.Spec.Explainer.ComponentExtensionSpec
+	}
+	destinationRules := []*v1alpha3.DestinationRule{}
+	for _, component := range []v1beta1.ComponentType{v1beta1.PredictorComponent, v1beta1.TransformerComponent,
+		v1beta1.ExplainerComponent} {
+		componentExt, ok := components[component]
+		if !ok {
+			continue
+		}
+		if componentExt.CircuitBreaker == nil && !mtls {
+			continue
+		}
+		for _, revision := range servingRevisions(isvc, component, componentExt) {
+			destinationRules = append(destinationRules, &v1alpha3.DestinationRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      revision,
+					Namespace: isvc.Namespace,
+					Labels: map[string]string{
+						constants.InferenceServicePodLabelKey: isvc.Name,
+						constants.KServiceComponentLabel:      string(component),
+					},
+				},
+				Spec: istiov1alpha3.DestinationRule{
+					Host:          network.GetServiceHostname(revision, isvc.Namespace),
+					TrafficPolicy: createTrafficPolicy(componentExt.CircuitBreaker, nil, mtls),
+				},
+			})
+		}
+	}
+	if isvc.Spec.Transformer != nil && isvc.Spec.Transformer.PredictorCall != nil {
+		destinationRules = append(destinationRules, &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.PredictorCallDestinationRuleName(isvc.Name),
+				Namespace: isvc.Namespace,
+				Labels: map[string]string{
+					constants.InferenceServicePodLabelKey: isvc.Name,
+					constants.KServiceComponentLabel:      string(v1beta1.TransformerComponent),
+				},
+			},
+			Spec: istiov1alpha3.DestinationRule{
+				Host:          predictorCallHost(isvc),
+				TrafficPolicy: createTrafficPolicy(nil, isvc.Spec.Transformer.PredictorCall, mtls),
+				// Only the calls of the clients of the namespace of the transformer are configured
+				ExportTo: []string{"."},
+			},
+		})
+	}
+	return destinationRules
+}
+
+// predictorCallHost returns the cluster local host of the predictor called by the transformer: the host of the
+// predictor call without its port, e.g. other-model-predictor-default.other-namespace, or the default predictor of the
+// inference service
+func predictorCallHost(isvc *v1beta1.InferenceService) string {
+	host := isvc.Spec.Transformer.PredictorCall.Host
+	if host == "" {
+		return network.GetServiceHostname(constants.DefaultPredictorServiceName(isvc.Name), isvc.Namespace)
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	switch parts := strings.Split(host, "."); len(parts) {
+	case 1:
+		return network.GetServiceHostname(parts[0], isvc.Namespace)
+	case 2:
+		return network.GetServiceHostname(parts[0], parts[1])
+	default:
+		return host
+	}
+}
+
+// reconcileDestinationRules creates or updates the DestinationRules of the components, and deletes the DestinationRules
+// of the revisions which no longer receive traffic or of the components without traffic policy
+func (ir *IngressReconciler) reconcileDestinationRules(isvc *v1beta1.InferenceService) error {
+	desiredRules := createDestinationRules(isvc, ir.Mesh)
+	desiredNames := map[string]bool{}
+	for _, desired := range desiredRules {
+		desiredNames[desired.Name] = true
+		if err := controllerutil.SetControllerReference(isvc, desired, ir.scheme); err != nil {
+			return errors.Wrapf(err, "fails to set owner reference for destination rule")
+		}
+		existing := &v1alpha3.DestinationRule{}
+		err := ir.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+		if err != nil {
+			if apierr.IsNotFound(err) {
+				log.Info("Creating destination rule", "namespace", desired.Namespace, "name", desired.Name)
+				err = ir.client.Create(context.TODO(), desired)
+			}
+		} else if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) ||
+			!equality.Semantic.DeepEqual(desired.Labels, existing.Labels) {
+			existing.Spec = desired.Spec
+			existing.Labels = desired.Labels
+			log.Info("Updating destination rule", "namespace", desired.Namespace, "name", desired.Name)
+			err = ir.client.Update(context.TODO(), existing)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "fails to create or update destination rule")
+		}
+	}
+	return ir.deleteDestinationRules(isvc, desiredNames)
+}
+
+// deleteDestinationRules deletes the DestinationRules of the inference service except the desired ones
+func (ir *IngressReconciler) deleteDestinationRules(isvc *v1beta1.InferenceService, desiredNames map[string]bool) error {
+	existingRules := &v1alpha3.DestinationRuleList{}
+	if err := ir.client.List(context.TODO(), existingRules, client.InNamespace(isvc.Namespace),
+		client.MatchingLabels{constants.InferenceServicePodLabelKey: isvc.Name}); err != nil {
+		return errors.Wrapf(err, "fails to list destination rules")
+	}
+	for i := range existingRules.Items {
+		existing := existingRules.Items[i]
+		if desiredNames[existing.Name] {
+			continue
+		}
+		log.Info("Deleting destination rule", "namespace", existing.Namespace, "name", existing.Name)
+		if err := ir.client.Delete(context.TODO(), &existing); err != nil && !apierr.IsNotFound(err) {
+			return errors.Wrapf(err, "fails to delete destination rule")
+		}
+	}
+	return nil
+}
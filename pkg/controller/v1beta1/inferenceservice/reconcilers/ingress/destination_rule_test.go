@@ -0,0 +1,298 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateTrafficPolicy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		circuitBreaker *v1beta1.CircuitBreaker
+		predictorCall  *v1beta1.PredictorCallSpec
+		mtls           bool
+		expected       *istiov1alpha3.TrafficPolicy
+	}{
+		"OutlierDetection": {
+			circuitBreaker: &v1beta1.CircuitBreaker{
+				ConsecutiveErrors:  5,
+				Interval:           &metav1.Duration{Duration: 10 * time.Second},
+				BaseEjectionTime:   &metav1.Duration{Duration: time.Minute},
+				MaxEjectionPercent: 50,
+			},
+			expected: &istiov1alpha3.TrafficPolicy{
+				OutlierDetection: &istiov1alpha3.OutlierDetection{
+					ConsecutiveErrors:  5,
+					Interval:           &gogotypes.Duration{Seconds: 10},
+					BaseEjectionTime:   &gogotypes.Duration{Seconds: 60},
+					MaxEjectionPercent: 50,
+				},
+			},
+		},
+		"ConnectionPool": {
+			circuitBreaker: &v1beta1.CircuitBreaker{
+				MaxConnections:     100,
+				MaxPendingRequests: 10,
+			},
+			expected: &istiov1alpha3.TrafficPolicy{
+				OutlierDetection: &istiov1alpha3.OutlierDetection{},
+				ConnectionPool: &istiov1alpha3.ConnectionPoolSettings{
+					Tcp:  &istiov1alpha3.ConnectionPoolSettings_TCPSettings{MaxConnections: 100},
+					Http: &istiov1alpha3.ConnectionPoolSettings_HTTPSettings{Http1MaxPendingRequests: 10},
+				},
+			},
+		},
+		"PredictorCall": {
+			predictorCall: &v1beta1.PredictorCallSpec{
+				Protocol:       v1beta1.PredictorCallHTTP2,
+				MaxConnections: 50,
+				IdleTimeout:    &metav1.Duration{Duration: 5 * time.Minute},
+				StrictMTLS:     true,
+			},
+			expected: &istiov1alpha3.TrafficPolicy{
+				ConnectionPool: &istiov1alpha3.ConnectionPoolSettings{
+					Tcp: &istiov1alpha3.ConnectionPoolSettings_TCPSettings{MaxConnections: 50},
+					Http: &istiov1alpha3.ConnectionPoolSettings_HTTPSettings{
+						IdleTimeout:     &gogotypes.Duration{Seconds: 300},
+						H2UpgradePolicy: istiov1alpha3.ConnectionPoolSettings_HTTPSettings_UPGRADE,
+					},
+				},
+				Tls: &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL},
+			},
+		},
+		"MeshMutualTLS": {
+			circuitBreaker: &v1beta1.CircuitBreaker{ConsecutiveErrors: 5},
+			mtls:           true,
+			expected: &istiov1alpha3.TrafficPolicy{
+				OutlierDetection: &istiov1alpha3.OutlierDetection{ConsecutiveErrors: 5},
+				Tls:              &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL},
+			},
+		},
+		"CircuitBreakerConnectionsTakePrecedence": {
+			circuitBreaker: &v1beta1.CircuitBreaker{MaxConnections: 100},
+			predictorCall:  &v1beta1.PredictorCallSpec{MaxConnections: 50},
+			expected: &istiov1alpha3.TrafficPolicy{
+				OutlierDetection: &istiov1alpha3.OutlierDetection{},
+				ConnectionPool: &istiov1alpha3.ConnectionPoolSettings{
+					Tcp: &istiov1alpha3.ConnectionPoolSettings_TCPSettings{MaxConnections: 100},
+				},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(createTrafficPolicy(scenario.circuitBreaker, scenario.predictorCall, scenario.mtls)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestReconcileDestinationRules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, nil)
+	isvc.Spec.Predictor.CircuitBreaker = &v1beta1.CircuitBreaker{ConsecutiveErrors: 5}
+	status := isvc.Status.Components[v1beta1.PredictorComponent]
+	status.LatestReadyRevision = "my-model-predictor-default-00002"
+	status.PreviousReadyRevision = "my-model-predictor-default-00001"
+	isvc.Status.Components[v1beta1.PredictorComponent] = status
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	listRules := func() []v1alpha3.DestinationRule {
+		rules := &v1alpha3.DestinationRuleList{}
+		g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+		return rules.Items
+	}
+
+	// Only the latest revision serves outside of a canary rollout
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	rules := listRules()
+	g.Expect(rules).To(gomega.HaveLen(1))
+	g.Expect(rules[0].Name).To(gomega.Equal("my-model-predictor-default-00002"))
+	g.Expect(rules[0].Spec.Host).To(gomega.Equal("my-model-predictor-default-00002.default.svc.cluster.local"))
+	g.Expect(rules[0].Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors).To(gomega.Equal(int32(5)))
+	g.Expect(rules[0].Labels[constants.KServiceComponentLabel]).To(gomega.Equal(string(v1beta1.PredictorComponent)))
+	g.Expect(metav1.IsControlledBy(&rules[0], isvc)).To(gomega.BeTrue())
+
+	// The previous revision is protected as well during a canary rollout
+	isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(10)
+	isvc.Spec.Predictor.CircuitBreaker.ConsecutiveErrors = 3
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	rules = listRules()
+	g.Expect(rules).To(gomega.HaveLen(2))
+	for _, rule := range rules {
+		g.Expect(rule.Spec.TrafficPolicy.OutlierDetection.ConsecutiveErrors).To(gomega.Equal(int32(3)))
+	}
+
+	// The rules are deleted once the component has no circuit breaker
+	isvc.Spec.Predictor.CircuitBreaker = nil
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(listRules()).To(gomega.BeEmpty())
+}
+
+func TestIngressDelete(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, nil)
+	isvc.Spec.Predictor.CircuitBreaker = &v1beta1.CircuitBreaker{ConsecutiveErrors: 5}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	virtualServices := &v1alpha3.VirtualServiceList{}
+	g.Expect(cl.List(context.TODO(), virtualServices, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+	g.Expect(virtualServices.Items).To(gomega.HaveLen(1))
+
+	// The routing resources are deleted, deleting them again is a no-op
+	for i := 0; i < 2; i++ {
+		g.Expect(ir.Delete(isvc)).To(gomega.Succeed())
+		g.Expect(cl.List(context.TODO(), virtualServices, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+		g.Expect(virtualServices.Items).To(gomega.BeEmpty())
+		rules := &v1alpha3.DestinationRuleList{}
+		g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+		g.Expect(rules.Items).To(gomega.BeEmpty())
+	}
+}
+
+func TestReconcilePredictorCallDestinationRules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, &v1beta1.TransformerSpec{
+		PredictorCall: &v1beta1.PredictorCallSpec{StrictMTLS: true},
+	}, nil)
+	for component, revision := range map[v1beta1.ComponentType]string{
+		v1beta1.PredictorComponent:   "my-model-predictor-default-00001",
+		v1beta1.TransformerComponent: "my-model-transformer-default-00001",
+	} {
+		status := isvc.Status.Components[component]
+		status.LatestReadyRevision = revision
+		isvc.Status.Components[component] = status
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+
+	// The rule targets the cluster local host of the predictor the transformer calls, not its revisions
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	rules := &v1alpha3.DestinationRuleList{}
+	g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+	g.Expect(rules.Items).To(gomega.HaveLen(1))
+	g.Expect(rules.Items[0].Name).To(gomega.Equal("my-model-predictor-call"))
+	g.Expect(rules.Items[0].Spec).To(gomega.Equal(istiov1alpha3.DestinationRule{
+		Host: "my-model-predictor-default.default.svc.cluster.local",
+		TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+			Tls: &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL},
+		},
+		ExportTo: []string{"."},
+	}))
+
+	// The calls to another predictor target its host
+	for host, expected := range map[string]string{
+		"other-model-predictor-default.other-namespace:80": "other-model-predictor-default.other-namespace.svc.cluster.local",
+		"other-model-predictor-default":                    "other-model-predictor-default.default.svc.cluster.local",
+		"models.example.com":                               "models.example.com",
+	} {
+		isvc.Spec.Transformer.PredictorCall.Host = host
+		g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+		g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+		g.Expect(rules.Items).To(gomega.HaveLen(1))
+		g.Expect(rules.Items[0].Spec.Host).To(gomega.Equal(expected))
+	}
+
+	// The rule is deleted with the predictor call
+	isvc.Spec.Transformer.PredictorCall = nil
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+	g.Expect(rules.Items).To(gomega.BeEmpty())
+}
+
+func TestReconcileMeshDestinationRules(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, nil)
+	for component, revision := range map[v1beta1.ComponentType]string{
+		v1beta1.PredictorComponent:   "my-model-predictor-default-00001",
+		v1beta1.TransformerComponent: "my-model-transformer-default-00001",
+	} {
+		status := isvc.Status.Components[component]
+		status.LatestReadyRevision = revision
+		isvc.Status.Components[component] = status
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	reconciler := NewReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	}, &v1beta1.MeshConfig{MTLSMode: v1beta1.MTLSModeStrict}, nil)
+
+	// All the components are called over mutual TLS
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	rules := &v1alpha3.DestinationRuleList{}
+	g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+	g.Expect(rules.Items).To(gomega.HaveLen(2))
+	for _, rule := range rules.Items {
+		g.Expect(rule.Spec.TrafficPolicy).To(gomega.Equal(&istiov1alpha3.TrafficPolicy{
+			Tls: &istiov1alpha3.TLSSettings{Mode: istiov1alpha3.TLSSettings_ISTIO_MUTUAL},
+		}))
+	}
+
+	// The rules are deleted once the mesh config sets no mode
+	reconciler = NewReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	}, &v1beta1.MeshConfig{}, nil)
+	g.Expect(reconciler.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(cl.List(context.TODO(), rules, client.InNamespace(isvc.Namespace))).To(gomega.Succeed())
+	g.Expect(rules.Items).To(gomega.BeEmpty())
+}
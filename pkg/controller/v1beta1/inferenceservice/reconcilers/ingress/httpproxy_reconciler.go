@@ -0,0 +1,128 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HTTPProxyReconciler programs a Contour HTTPProxy for the clusters exposing their services with Contour
+type HTTPProxyReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewHTTPProxyReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *HTTPProxyReconciler {
+	return &HTTPProxyReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// createHTTPProxy creates the HTTPProxy of the inference service host with a route per component receiving traffic,
+// Contour matches the longest prefix first. The host header is rewritten to the host the Knative ingress routes the
+// component on.
+func (r *HTTPProxyReconciler) createHTTPProxy(isvc *v1beta1.InferenceService, serviceHost string) *unstructured.Unstructured {
+	paths := getComponentPaths(isvc, serviceHost)
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	// Sort the routes to keep the spec stable across reconciles
+	sort.Strings(names)
+	routes := []interface{}{}
+	for _, name := range names {
+		routes = append(routes, map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"prefix": paths[name]},
+			},
+			"services": []interface{}{
+				map[string]interface{}{
+					"name": name,
+					"port": int64(constants.CommonDefaultHttpPort),
+				},
+			},
+			"requestHeadersPolicy": map[string]interface{}{
+				"set": []interface{}{
+					map[string]interface{}{
+						"name":  "Host",
+						"value": network.GetServiceHostname(name, isvc.Namespace),
+					},
+				},
+			},
+		})
+	}
+	httpProxy := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"virtualhost": map[string]interface{}{
+					"fqdn": serviceHost,
+				},
+				"routes": routes,
+			},
+		},
+	}
+	httpProxy.SetAPIVersion(constants.ContourAPIVersion)
+	httpProxy.SetKind(constants.ContourHTTPProxy)
+	httpProxy.SetName(isvc.Name)
+	httpProxy.SetNamespace(isvc.Namespace)
+	if r.ingressConfig.IngressClassName != "" {
+		httpProxy.SetAnnotations(map[string]string{IngressClassAnnotationKey: r.ingressConfig.IngressClassName})
+	}
+	return httpProxy
+}
+
+// Reconcile creates or updates the HTTPProxy of the inference service, and deletes it when the inference service is
+// cluster local. The address of the inference service is the cluster local host of the component serving the
+// predict route.
+func (r *HTTPProxyReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if !checkComponentsReady(isvc) {
+		return nil
+	}
+	serviceHost, serviceUrl, err := getServiceHostAndUrl(r.client, isvc, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+	if isInternalService(isvc, serviceHost) {
+		if err := deleteUnstructured(r.client, constants.ContourAPIVersion, constants.ContourHTTPProxy, isvc.Namespace,
+			isvc.Name); err != nil {
+			return err
+		}
+	} else if err := reconcileUnstructured(r.client, r.scheme, isvc, r.createHTTPProxy(isvc, serviceHost)); err != nil {
+		return err
+	}
+	return setIngressReady(isvc, serviceUrl, serviceHost,
+		network.GetServiceHostname(getBackendServiceName(isvc), isvc.Namespace))
+}
+
+// Delete deletes the HTTPProxy of the inference service
+func (r *HTTPProxyReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	return deleteUnstructured(r.client, constants.ContourAPIVersion, constants.ContourHTTPProxy, isvc.Namespace,
+		isvc.Name)
+}
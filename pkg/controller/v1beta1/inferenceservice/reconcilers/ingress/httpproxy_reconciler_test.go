@@ -0,0 +1,78 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateHTTPProxy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	r := NewHTTPProxyReconciler(nil, nil, &v1beta1.IngressConfig{
+		IngressBackend:   v1beta1.ContourIngressBackend,
+		IngressClassName: "contour-external",
+	})
+	scenarios := map[string]struct {
+		isvc           *v1beta1.InferenceService
+		expectedRoutes map[string]string
+	}{
+		"Predictor": {
+			isvc: makeReadyInferenceService(nil, nil, nil),
+			expectedRoutes: map[string]string{
+				"/": "my-model-predictor-default",
+			},
+		},
+		"TransformerAndExplainer": {
+			isvc: makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, &v1beta1.ExplainerSpec{}),
+			expectedRoutes: map[string]string{
+				"/":                           "my-model-transformer-default",
+				"/v1/models/my-model:explain": "my-model-explainer-default",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			httpProxy := r.createHTTPProxy(scenario.isvc, "my-model.default.example.com")
+			g.Expect(httpProxy.GetAPIVersion()).To(gomega.Equal(constants.ContourAPIVersion))
+			g.Expect(httpProxy.GetKind()).To(gomega.Equal(constants.ContourHTTPProxy))
+			g.Expect(httpProxy.GetAnnotations()).To(gomega.Equal(map[string]string{IngressClassAnnotationKey: "contour-external"}))
+			fqdn, _, _ := unstructured.NestedString(httpProxy.Object, "spec", "virtualhost", "fqdn")
+			g.Expect(fqdn).To(gomega.Equal("my-model.default.example.com"))
+
+			routes, _, _ := unstructured.NestedSlice(httpProxy.Object, "spec", "routes")
+			actualRoutes := map[string]string{}
+			for _, route := range routes {
+				route := route.(map[string]interface{})
+				prefix := route["conditions"].([]interface{})[0].(map[string]interface{})["prefix"].(string)
+				service := route["services"].([]interface{})[0].(map[string]interface{})["name"].(string)
+				actualRoutes[prefix] = service
+				host := route["requestHeadersPolicy"].(map[string]interface{})["set"].([]interface{})[0].(map[string]interface{})
+				g.Expect(host).To(gomega.Equal(map[string]interface{}{
+					"name":  "Host",
+					"value": service + ".default.svc.cluster.local",
+				}))
+			}
+			g.Expect(actualRoutes).To(gomega.Equal(scenario.expectedRoutes))
+		})
+	}
+}
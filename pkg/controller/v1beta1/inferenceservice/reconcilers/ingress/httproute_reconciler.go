@@ -0,0 +1,140 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HTTPRouteReconciler programs a Gateway API HTTPRoute attached to the Gateway of the ingress config for the clusters
+// not running Istio
+type HTTPRouteReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewHTTPRouteReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *HTTPRouteReconciler {
+	return &HTTPRouteReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// createHTTPRouteRule forwards the requests matching the path to the Knative service of a component with the host
+// header the Knative ingress routes the component on
+func createHTTPRouteRule(pathType string, path string, componentServiceName string, namespace string) interface{} {
+	return map[string]interface{}{
+		"matches": []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  pathType,
+					"value": path,
+				},
+			},
+		},
+		"filters": []interface{}{
+			map[string]interface{}{
+				"type": "RequestHeaderModifier",
+				"requestHeaderModifier": map[string]interface{}{
+					"set": map[string]interface{}{
+						"Host": network.GetServiceHostname(componentServiceName, namespace),
+					},
+				},
+			},
+		},
+		"forwardTo": []interface{}{
+			map[string]interface{}{
+				"serviceName": componentServiceName,
+				"port":        int64(constants.CommonDefaultHttpPort),
+			},
+		},
+	}
+}
+
+func (r *HTTPRouteReconciler) createHTTPRoute(isvc *v1beta1.InferenceService, serviceHost string) *unstructured.Unstructured {
+	rules := []interface{}{}
+	if isvc.Spec.Explainer != nil {
+		rules = append(rules, createHTTPRouteRule("Exact", constants.ExplainPath(isvc.Name),
+			constants.DefaultExplainerServiceName(isvc.Name), isvc.Namespace))
+	}
+	rules = append(rules, createHTTPRouteRule("Prefix", "/", getBackendServiceName(isvc), isvc.Namespace))
+	gateway := strings.Split(r.ingressConfig.KubernetesGateway, "/")
+	httpRoute := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"gateways": map[string]interface{}{
+					"allow": "FromList",
+					"gatewayRefs": []interface{}{
+						map[string]interface{}{
+							"namespace": gateway[0],
+							"name":      gateway[1],
+						},
+					},
+				},
+				"hostnames": []interface{}{serviceHost},
+				"rules":     rules,
+			},
+		},
+	}
+	httpRoute.SetAPIVersion(constants.GatewayAPIVersion)
+	httpRoute.SetKind(constants.GatewayAPIHTTPRoute)
+	httpRoute.SetName(isvc.Name)
+	httpRoute.SetNamespace(isvc.Namespace)
+	return httpRoute
+}
+
+// Reconcile creates or updates the HTTPRoute of the inference service, and deletes it when the inference service is
+// cluster local. The address of the inference service is the cluster local host of the component serving the
+// predict route.
+func (r *HTTPRouteReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if !checkComponentsReady(isvc) {
+		return nil
+	}
+	serviceHost, serviceUrl, err := getServiceHostAndUrl(r.client, isvc, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+
+	if isInternalService(isvc, serviceHost) {
+		if err := deleteUnstructured(r.client, constants.GatewayAPIVersion, constants.GatewayAPIHTTPRoute, isvc.Namespace,
+			isvc.Name); err != nil {
+			return err
+		}
+	} else if err := reconcileUnstructured(r.client, r.scheme, isvc, r.createHTTPRoute(isvc, serviceHost)); err != nil {
+		return err
+	}
+	return setIngressReady(isvc, serviceUrl, serviceHost,
+		network.GetServiceHostname(getBackendServiceName(isvc), isvc.Namespace))
+}
+
+// Delete deletes the HTTPRoute of the inference service
+func (r *HTTPRouteReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	return deleteUnstructured(r.client, constants.GatewayAPIVersion, constants.GatewayAPIHTTPRoute, isvc.Namespace,
+		isvc.Name)
+}
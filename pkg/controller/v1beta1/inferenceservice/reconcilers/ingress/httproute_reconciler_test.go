@@ -0,0 +1,77 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateHTTPRoute(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	r := NewHTTPRouteReconciler(nil, nil, &v1beta1.IngressConfig{
+		IngressBackend:    v1beta1.GatewayAPIIngressBackend,
+		KubernetesGateway: "gateway-system/public",
+	})
+	scenarios := map[string]struct {
+		isvc             *v1beta1.InferenceService
+		expectedServices []string
+		expectedPaths    []string
+	}{
+		"Predictor": {
+			isvc:             makeReadyInferenceService(nil, nil, nil),
+			expectedServices: []string{"my-model-predictor-default"},
+			expectedPaths:    []string{"/"},
+		},
+		"TransformerAndExplainer": {
+			isvc:             makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, &v1beta1.ExplainerSpec{}),
+			expectedServices: []string{"my-model-explainer-default", "my-model-transformer-default"},
+			expectedPaths:    []string{"/v1/models/my-model:explain", "/"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			httpRoute := r.createHTTPRoute(scenario.isvc, "my-model.default.example.com")
+			g.Expect(httpRoute.GetAPIVersion()).To(gomega.Equal(constants.GatewayAPIVersion))
+			g.Expect(httpRoute.GetKind()).To(gomega.Equal(constants.GatewayAPIHTTPRoute))
+			gatewayRefs, _, _ := unstructured.NestedSlice(httpRoute.Object, "spec", "gateways", "gatewayRefs")
+			g.Expect(gatewayRefs).To(gomega.Equal([]interface{}{
+				map[string]interface{}{"namespace": "gateway-system", "name": "public"},
+			}))
+			hostnames, _, _ := unstructured.NestedSlice(httpRoute.Object, "spec", "hostnames")
+			g.Expect(hostnames).To(gomega.Equal([]interface{}{"my-model.default.example.com"}))
+
+			rules, _, _ := unstructured.NestedSlice(httpRoute.Object, "spec", "rules")
+			g.Expect(rules).To(gomega.HaveLen(len(scenario.expectedServices)))
+			for i, rule := range rules {
+				rule := rule.(map[string]interface{})
+				match := rule["matches"].([]interface{})[0].(map[string]interface{})
+				g.Expect(match["path"].(map[string]interface{})["value"]).To(gomega.Equal(scenario.expectedPaths[i]))
+				forwardTo := rule["forwardTo"].([]interface{})[0].(map[string]interface{})
+				g.Expect(forwardTo["serviceName"]).To(gomega.Equal(scenario.expectedServices[i]))
+				host, _, _ := unstructured.NestedString(rule["filters"].([]interface{})[0].(map[string]interface{}),
+					"requestHeaderModifier", "set", "Host")
+				g.Expect(host).To(gomega.Equal(scenario.expectedServices[i] + ".default.svc.cluster.local"))
+			}
+		})
+	}
+}
@@ -19,8 +19,13 @@ package ingress
 import (
 	"context"
 	"fmt"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/auth"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/certificate"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/envoyfilter"
 	"github.com/pkg/errors"
 	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
@@ -34,11 +39,13 @@ import (
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/network"
+	"regexp"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"strings"
+	"time"
 )
 
 var (
@@ -49,6 +56,10 @@ type IngressReconciler struct {
 	client        client.Client
 	scheme        *runtime.Scheme
 	ingressConfig *v1beta1.IngressConfig
+	// Mutations logs the changes made to the virtual services, nothing when nil
+	Mutations *audit.Mutations
+	// Mesh sets the mutual TLS of the DestinationRules of the components, the mesh defaults apply when nil
+	Mesh *v1beta1.MeshConfig
 }
 
 func NewIngressReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *IngressReconciler {
@@ -178,6 +189,161 @@ func (ir *IngressReconciler) createHTTPRouteDestination(targetHost, namespace st
 	return httpRouteDestination
 }
 
+// setShadowMirror mirrors the canary percentage of the traffic of a component in shadow mode to the private service of
+// its latest ready revision while the previous revision serves the traffic. The responses of the mirrored requests
+// are discarded by the gateway.
+func setShadowMirror(route *istiov1alpha3.HTTPRoute, isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	componentExt *v1beta1.ComponentExtensionSpec) {
+	if !componentExt.Shadow || componentExt.CanaryTrafficPercent == nil {
+		return
+	}
+	status, ok := isvc.Status.Components[component]
+	if !ok || status.LatestReadyRevision == "" || status.PreviousReadyRevision == "" || status.IsCanaryRolledBack() {
+		return
+	}
+	route.Mirror = &istiov1alpha3.Destination{
+		Host: network.GetServiceHostname(status.LatestReadyRevision+"-private", isvc.Namespace),
+		Port: &istiov1alpha3.PortSelector{
+			Number: constants.CommonDefaultHttpPort,
+		},
+	}
+	route.MirrorPercent = &gogotypes.UInt32Value{Value: uint32(*componentExt.CanaryTrafficPercent)}
+}
+
+// setRoutePolicy bounds the requests of the routes of a component to its timeout and sets its retry policy. A retry is
+// given the whole timeout when the retry policy has no per try timeout, like the routes of the Knative services, so
+// the retries do not cut the slow requests short.
+func setRoutePolicy(componentExt *v1beta1.ComponentExtensionSpec, routes ...*istiov1alpha3.HTTPRoute) {
+	if componentExt.TimeoutSeconds == nil && componentExt.Retry == nil {
+		return
+	}
+	for _, route := range routes {
+		retries := &istiov1alpha3.HTTPRetry{Attempts: constants.DefaultRouteRetryAttempts}
+		if componentExt.TimeoutSeconds != nil {
+			timeout := time.Duration(*componentExt.TimeoutSeconds) * time.Second
+			route.Timeout = gogotypes.DurationProto(timeout)
+			retries.PerTryTimeout = gogotypes.DurationProto(timeout)
+		}
+		if retry := componentExt.Retry; retry != nil {
+			retries.Attempts = retry.Attempts
+			if retry.PerTryTimeout != nil {
+				retries.PerTryTimeout = gogotypes.DurationProto(retry.PerTryTimeout.Duration)
+			}
+			retries.RetryOn = strings.Join(retry.RetryOn, ",")
+		}
+		if retries.Attempts == 0 {
+			// The per try timeout and the retry conditions have no effect without retries
+			retries = &istiov1alpha3.HTTPRetry{}
+		}
+		route.Retries = retries
+	}
+}
+
+// setHedgingPolicy names the routes of a hedged component so the hedging EnvoyFilter of the ingress gateway matches
+// them, and retries the requests of the routes after the hedging delay of the component status, or of the spec until
+// the controller resolves it. The original requests are kept running by the EnvoyFilter so the first response wins.
+func setHedgingPolicy(isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	componentExt *v1beta1.ComponentExtensionSpec, routes ...*istiov1alpha3.HTTPRoute) {
+	if componentExt.Hedging == nil {
+		return
+	}
+	delay := componentExt.Hedging.GetDelay()
+	if status := isvc.Status.Components[component]; status.HedgingDelay != nil {
+		delay = status.HedgingDelay.Duration
+	}
+	for _, route := range routes {
+		route.Name = constants.HedgingRouteName(isvc.Name, isvc.Namespace, string(component))
+		route.Retries = &istiov1alpha3.HTTPRetry{
+			Attempts:      componentExt.Hedging.GetMaxHedges(),
+			PerTryTimeout: gogotypes.DurationProto(delay),
+			RetryOn:       constants.HedgingRetryOn,
+		}
+	}
+}
+
+// setStreamingPolicy keeps the streamed responses and the websocket connections of the predict routes from being cut
+// short or buffered: the routes are not bounded by a timeout unless the component sets one, and the proxies in front
+// of the gateway are told not to buffer the streamed responses. Istio upgrades the websocket connections by itself.
+func setStreamingPolicy(isvc *v1beta1.InferenceService, componentExt *v1beta1.ComponentExtensionSpec,
+	routes ...*istiov1alpha3.HTTPRoute) {
+	if !isvc.Spec.Predictor.IsStreaming() && !isvc.Spec.Predictor.IsWebsocket() {
+		return
+	}
+	for _, route := range routes {
+		if componentExt.TimeoutSeconds == nil {
+			route.Timeout = gogotypes.DurationProto(0)
+		}
+		if !isvc.Spec.Predictor.IsStreaming() {
+			continue
+		}
+		if route.Headers == nil {
+			route.Headers = &istiov1alpha3.Headers{}
+		}
+		if route.Headers.Response == nil {
+			route.Headers.Response = &istiov1alpha3.Headers_HeaderOperations{}
+		}
+		if route.Headers.Response.Set == nil {
+			route.Headers.Response.Set = map[string]string{}
+		}
+		route.Headers.Response.Set[constants.AccelBufferingHeader] = "no"
+	}
+}
+
+// canaryMatch returns the canary rules of a component, none once its canary is rolled back by the canary analysis
+func canaryMatch(isvc *v1beta1.InferenceService, component v1beta1.ComponentType,
+	componentExt *v1beta1.ComponentExtensionSpec) []v1beta1.CanaryMatch {
+	if isvc.Status.Components[component].IsCanaryRolledBack() {
+		return nil
+	}
+	return componentExt.CanaryMatch
+}
+
+// createCanaryRoutes routes the requests matching the canary rules of a component to its latest revision whatever the
+// traffic split, through the host Knative routes for the latest traffic tag. The routes must be matched before the
+// route of the component.
+func (ir *IngressReconciler) createCanaryRoutes(prefix, serviceHost, internalHost string, isInternal bool,
+	backend string, namespace string, canaryMatch []v1beta1.CanaryMatch) []*istiov1alpha3.HTTPRoute {
+	routes := []*istiov1alpha3.HTTPRoute{}
+	for _, canary := range canaryMatch {
+		headers := map[string]*istiov1alpha3.StringMatch{}
+		for name, value := range canary.Headers {
+			headers[name] = &istiov1alpha3.StringMatch{
+				MatchType: &istiov1alpha3.StringMatch_Exact{Exact: value},
+			}
+		}
+		// Istio matches the cookies with a regular expression on the cookie header
+		for name, value := range canary.Cookies {
+			headers["cookie"] = &istiov1alpha3.StringMatch{
+				MatchType: &istiov1alpha3.StringMatch_Regex{
+					Regex: fmt.Sprintf("^(.*?;\\s*)?%s=%s(;.*)?$", regexp.QuoteMeta(name), regexp.QuoteMeta(value)),
+				},
+			}
+		}
+		queryParams := map[string]*istiov1alpha3.StringMatch{}
+		for name, value := range canary.QueryParams {
+			queryParams[name] = &istiov1alpha3.StringMatch{
+				MatchType: &istiov1alpha3.StringMatch_Exact{Exact: value},
+			}
+		}
+		matches := ir.createHTTPMatchRequest(prefix, serviceHost, internalHost, isInternal)
+		for _, match := range matches {
+			if len(headers) != 0 {
+				match.Headers = headers
+			}
+			if len(queryParams) != 0 {
+				match.QueryParams = queryParams
+			}
+		}
+		routes = append(routes, &istiov1alpha3.HTTPRoute{
+			Match: matches,
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				ir.createHTTPRouteDestination(constants.LatestTrafficTag+"-"+backend, namespace, constants.LocalGatewayHost),
+			},
+		})
+	}
+	return routes
+}
+
 func (ir *IngressReconciler) createHTTPMatchRequest(prefix, targetHost, internalHost string, isInternal bool) []*istiov1alpha3.HTTPMatchRequest {
 	var uri *istiov1alpha3.StringMatch
 	if prefix != "" {
@@ -213,91 +379,187 @@ func (ir *IngressReconciler) createHTTPMatchRequest(prefix, targetHost, internal
 	return matchRequests
 }
 
-func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
-	if !isvc.Status.IsConditionReady(v1beta1.PredictorReady) {
-		isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-			Type:   v1beta1.IngressReady,
-			Status: corev1.ConditionFalse,
-			Reason: "Predictor ingress not created",
-		})
-		return nil
+// isV1Alpha2CompatibilityEnabled returns true if the namespace of the inference service is labelled to keep the
+// v1alpha2 inference paths working
+func (ir *IngressReconciler) isV1Alpha2CompatibilityEnabled(namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := ir.client.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
 	}
-	serviceHost := getServiceHost(isvc)
-	serviceUrl := getServiceUrl(isvc)
-	if serviceHost == "" || serviceUrl == "" {
+	return ns.Labels[constants.V1Alpha2CompatibilityLabelKey] == constants.V1Alpha2CompatibilityLabelValue, nil
+}
+
+// createV1Alpha2CompatibilityRoutes keeps routing the v1 protocol paths used by v1alpha2 clients when the predictor
+// only serves the v2 protocol, the responses carry a Warning header pointing the clients to the v2 path. The paths are
+// not rewritten: the v1 payloads are not translated to the v2 payloads, a v2 server would reject them. Predictors
+// serving v1 keep answering the v1alpha2 paths as is.
+func (ir *IngressReconciler) createV1Alpha2CompatibilityRoutes(isvc *v1beta1.InferenceService, serviceHost string,
+	isInternal bool, backend string) []*istiov1alpha3.HTTPRoute {
+	if isvc.Spec.Transformer != nil || isvc.Spec.Predictor.GetProtocol() != constants.ProtocolV2 {
 		return nil
 	}
-	backend := constants.DefaultPredictorServiceName(isvc.Name)
+	deprecations := []struct {
+		path   string
+		v2Path string
+	}{
+		{path: constants.PredictPath(isvc.Name), v2Path: constants.InferPathV2(isvc.Name)},
+		{path: constants.InferenceServicePrefix(isvc.Name), v2Path: constants.ModelReadyPathV2(isvc.Name)},
+	}
+	routes := []*istiov1alpha3.HTTPRoute{}
+	for _, deprecation := range deprecations {
+		routes = append(routes, &istiov1alpha3.HTTPRoute{
+			Match: ir.createHTTPMatchRequest("^"+regexp.QuoteMeta(deprecation.path)+"$", serviceHost,
+				network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
+			Headers: &istiov1alpha3.Headers{
+				Response: &istiov1alpha3.Headers_HeaderOperations{
+					Set: map[string]string{
+						"Warning": fmt.Sprintf(
+							"299 - \"Deprecated v1alpha2 inference path, the predictor serves the v2 protocol at %s\"",
+							deprecation.v2Path),
+					},
+				},
+			},
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				ir.createHTTPRouteDestination(backend, isvc.Namespace, constants.LocalGatewayHost),
+			},
+		})
+	}
+	setRoutePolicy(&isvc.Spec.Predictor.ComponentExtensionSpec, routes...)
+	setHedgingPolicy(isvc, v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec, routes...)
+	return routes
+}
 
-	if isvc.Spec.Transformer != nil {
-		backend = constants.DefaultTransformerServiceName(isvc.Name)
-		if !isvc.Status.IsConditionReady(v1beta1.TransformerReady) {
-			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-				Type:   v1beta1.IngressReady,
-				Status: corev1.ConditionFalse,
-				Reason: "Transformer ingress not created",
-			})
-			return nil
+// createPathRoutes routes the requests of the ingress domain under the path prefix of the inference service to its
+// components, the prefix is stripped before the requests are sent to the components. The explain route is matched
+// before the predict route.
+func (ir *IngressReconciler) createPathRoutes(isvc *v1beta1.InferenceService, path string,
+	backend string) []*istiov1alpha3.HTTPRoute {
+	createPathRoute := func(prefix string, rewrite string, serviceName string) *istiov1alpha3.HTTPRoute {
+		return &istiov1alpha3.HTTPRoute{
+			Match: []*istiov1alpha3.HTTPMatchRequest{
+				{
+					Uri: &istiov1alpha3.StringMatch{
+						MatchType: &istiov1alpha3.StringMatch_Prefix{
+							Prefix: prefix,
+						},
+					},
+					Authority: &istiov1alpha3.StringMatch{
+						MatchType: &istiov1alpha3.StringMatch_Regex{
+							Regex: constants.HostRegExp(ir.ingressConfig.IngressDomain),
+						},
+					},
+					Gateways: []string{ir.ingressConfig.IngressGateway},
+				},
+			},
+			Rewrite: &istiov1alpha3.HTTPRewrite{
+				Uri: rewrite,
+			},
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				ir.createHTTPRouteDestination(serviceName, isvc.Namespace, constants.LocalGatewayHost),
+			},
 		}
 	}
-	isInternal := false
-	//if service is labelled with cluster local or knative domain is configured as internal
-	if val, ok := isvc.Labels[constants.VisibilityLabel]; ok && val == "ClusterLocal" {
-		isInternal = true
+	routes := []*istiov1alpha3.HTTPRoute{}
+	if isvc.Spec.Explainer != nil {
+		explainRoute := createPathRoute(path+constants.ExplainPath(isvc.Name), constants.ExplainPath(isvc.Name),
+			constants.DefaultExplainerServiceName(isvc.Name))
+		setShadowMirror(explainRoute, isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec)
+		setRoutePolicy(&isvc.Spec.Explainer.ComponentExtensionSpec, explainRoute)
+		setHedgingPolicy(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec, explainRoute)
+		routes = append(routes, explainRoute)
 	}
-	serviceInternalHostName := network.GetServiceHostname(isvc.Name, isvc.Namespace)
-	if serviceHost == serviceInternalHostName {
-		isInternal = true
+	predictRoute := createPathRoute(path+"/", "/", backend)
+	backendComponent, backendExt := v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec
+	if isvc.Spec.Transformer != nil {
+		backendComponent, backendExt = v1beta1.TransformerComponent, &isvc.Spec.Transformer.ComponentExtensionSpec
 	}
-	httpRoutes := []*istiov1alpha3.HTTPRoute{}
-	// Build explain route
-	if isvc.Spec.Explainer != nil {
-		if !isvc.Status.IsConditionReady(v1beta1.ExplainerReady) {
-			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-				Type:   v1beta1.IngressReady,
-				Status: corev1.ConditionFalse,
-				Reason: "Explainer ingress not created",
-			})
-			return nil
+	setShadowMirror(predictRoute, isvc, backendComponent, backendExt)
+	setRoutePolicy(backendExt, predictRoute)
+	setHedgingPolicy(isvc, backendComponent, backendExt, predictRoute)
+	setStreamingPolicy(isvc, backendExt, predictRoute)
+	return append(routes, predictRoute)
+}
+
+// addExternalGateway binds the routes matching the requests of the ingress gateway to another external gateway, e.g.
+// the gateway terminating TLS for the external host
+func addExternalGateway(httpRoutes []*istiov1alpha3.HTTPRoute, ingressGateway string, gateway string) {
+	for _, route := range httpRoutes {
+		for _, match := range route.Match {
+			for _, g := range match.Gateways {
+				if g == ingressGateway {
+					match.Gateways = append(match.Gateways, gateway)
+					break
+				}
+			}
 		}
-		explainerRouter := istiov1alpha3.HTTPRoute{
-			Match: ir.createHTTPMatchRequest(constants.ExplainPrefix(), serviceHost,
-				network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
+	}
+}
+
+// reconcileWarmPoolIngress routes the cluster local traffic of the inference service to the warm pool pod it claimed
+// while the predictor is not ready. Returns false when the inference service is not served by a warm pool pod.
+func (ir *IngressReconciler) reconcileWarmPoolIngress(isvc *v1beta1.InferenceService) (bool, error) {
+	if _, ok := isvc.Annotations[constants.WarmPoolAnnotationKey]; !ok || isvc.Spec.Transformer != nil || isvc.Spec.Explainer != nil {
+		return false, nil
+	}
+	warmService := &corev1.Service{}
+	if err := ir.client.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolClaimServiceName(isvc.Name),
+		Namespace: isvc.Namespace}, warmService); err != nil {
+		if apierr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "fails to get warm pool service")
+	}
+	serviceInternalHostName := network.GetServiceHostname(isvc.Name, isvc.Namespace)
+	httpRoutes := []*istiov1alpha3.HTTPRoute{
+		{
+			Match: ir.createHTTPMatchRequest("", serviceInternalHostName, serviceInternalHostName, true),
 			Route: []*istiov1alpha3.HTTPRouteDestination{
-				ir.createHTTPRouteDestination(constants.DefaultExplainerServiceName(isvc.Name), isvc.Namespace, constants.LocalGatewayHost),
+				{
+					Destination: &istiov1alpha3.Destination{
+						Host: network.GetServiceHostname(warmService.Name, warmService.Namespace),
+						Port: &istiov1alpha3.PortSelector{
+							Number: constants.CommonDefaultHttpPort,
+						},
+					},
+				},
 			},
-		}
-		httpRoutes = append(httpRoutes, &explainerRouter)
+		},
 	}
-	// Add predict route
-	httpRoutes = append(httpRoutes, &istiov1alpha3.HTTPRoute{
-		Match: ir.createHTTPMatchRequest("", serviceHost,
-			network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
-		Route: []*istiov1alpha3.HTTPRouteDestination{
-			ir.createHTTPRouteDestination(backend, isvc.Namespace, constants.LocalGatewayHost),
+	if err := ir.reconcileExternalService(isvc); err != nil {
+		return false, errors.Wrapf(err, "fails to reconcile external name service")
+	}
+	if err := ir.reconcileVirtualService(isvc, []string{serviceInternalHostName}, []string{constants.KnativeLocalGateway},
+		httpRoutes); err != nil {
+		return false, err
+	}
+	isvc.Status.Address = &duckv1.Addressable{
+		URL: &apis.URL{
+			Host:   serviceInternalHostName,
+			Scheme: "http",
 		},
+	}
+	isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
+		Type:   v1beta1.IngressReady,
+		Status: corev1.ConditionFalse,
+		Reason: "Served by warm pool",
 	})
+	return true, nil
+}
 
-	//Create external service which points to local gateway
-	if err := ir.reconcileExternalService(isvc); err != nil {
-		return errors.Wrapf(err, "fails to reconcile external name service")
-	}
-	//Create ingress
+func (ir *IngressReconciler) reconcileVirtualService(isvc *v1beta1.InferenceService, hosts []string, gateways []string,
+	httpRoutes []*istiov1alpha3.HTTPRoute) error {
 	desiredIngress := &v1alpha3.VirtualService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      isvc.Name,
 			Namespace: isvc.Namespace,
 		},
 		Spec: istiov1alpha3.VirtualService{
-			Hosts: []string{
-				serviceHost,
-				network.GetServiceHostname(isvc.Name, isvc.Namespace),
-			},
-			Gateways: []string{
-				ir.ingressConfig.IngressGateway,
-				constants.KnativeLocalGateway,
-			},
-			Http: httpRoutes,
+			Hosts:    hosts,
+			Gateways: gateways,
+			Http:     httpRoutes,
 		},
 	}
 	if err := controllerutil.SetControllerReference(isvc, desiredIngress, ir.scheme); err != nil {
@@ -313,29 +575,176 @@ func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
 		}
 	} else {
 		if !equality.Semantic.DeepEqual(desiredIngress.Spec, existing.Spec) {
+			// The spec is replaced, not mutated, so the previous spec is kept as is
+			previous := existing.Spec
 			existing.Spec = desiredIngress.Spec
 			log.Info("Update Ingress for isvc", "namespace", desiredIngress.Namespace, "name", desiredIngress.Name)
-			err = ir.client.Update(context.TODO(), existing)
+			if err = ir.client.Update(context.TODO(), existing); err == nil {
+				ir.Mutations.Record(existing, "VirtualService", &previous, &existing.Spec)
+			}
 		}
 	}
 	if err != nil {
 		return errors.Wrapf(err, "fails to create or update ingress")
 	}
+	return nil
+}
 
-	if url, err := apis.ParseURL(serviceUrl); err == nil {
-		isvc.Status.URL = url
-		isvc.Status.Address = &duckv1.Addressable{
-			URL: &apis.URL{
-				Host:   network.GetServiceHostname(isvc.Name, isvc.Namespace),
-				Scheme: "http",
-			},
+func (ir *IngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	// The circuit breakers protect the revisions as soon as they serve, whether the components are ready or not
+	if err := ir.reconcileDestinationRules(isvc); err != nil {
+		return errors.Wrapf(err, "fails to reconcile destination rules")
+	}
+	if !isvc.Status.IsConditionReady(v1beta1.PredictorReady) {
+		if served, err := ir.reconcileWarmPoolIngress(isvc); err != nil || served {
+			return err
 		}
-		isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
-			Type:   v1beta1.IngressReady,
-			Status: corev1.ConditionTrue,
-		})
+	}
+	if !checkComponentsReady(isvc) {
 		return nil
-	} else {
-		return errors.Wrapf(err, "fails to parse service url")
 	}
+	serviceHost, serviceUrl, err := getServiceHostAndUrl(ir.client, isvc, ir.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+	backend := getBackendServiceName(isvc)
+	isInternal := isInternalService(isvc, serviceHost)
+	// Serve the external host over HTTPS with the certificate issued by cert-manager, the certificate of an inference
+	// service which is no longer served over HTTPS is deleted
+	tls := ir.ingressConfig.CertificateIssuer != "" && !isInternal
+	certificates := certificate.NewCertificateReconciler(ir.client, ir.scheme, ir.ingressConfig)
+	if tls {
+		if err := certificates.Reconcile(isvc, serviceHost); err != nil {
+			return errors.Wrapf(err, "fails to reconcile certificate")
+		}
+		if isvc.Status.IsConditionReady(v1beta1.CertificateReady) {
+			serviceUrl = strings.Replace(serviceUrl, "http://", "https://", 1)
+		}
+	} else if isvc.Status.GetCondition(v1beta1.CertificateReady) != nil {
+		if err := certificates.Delete(isvc); err != nil {
+			return errors.Wrapf(err, "fails to delete certificate")
+		}
+		isvc.Status.ClearCondition(v1beta1.CertificateReady)
+	}
+	httpRoutes := []*istiov1alpha3.HTTPRoute{}
+	hosts := []string{serviceHost, network.GetServiceHostname(isvc.Name, isvc.Namespace)}
+	// Build the routes of the path prefix on the ingress domain, the status URL is the URL of the path prefix
+	path := ""
+	if ir.ingressConfig.PathTemplate != "" && !isInternal {
+		if path, err = getServicePath(isvc, ir.ingressConfig); err != nil {
+			return err
+		}
+		httpRoutes = append(httpRoutes, ir.createPathRoutes(isvc, path, backend)...)
+		hosts = append(hosts, ir.ingressConfig.IngressDomain)
+		url, err := apis.ParseURL(serviceUrl)
+		if err != nil {
+			return errors.Wrapf(err, "fails to parse service url")
+		}
+		url.Host = ir.ingressConfig.IngressDomain
+		url.Path = path
+		serviceUrl = url.String()
+	}
+	// Require a JWT at the ingress gateway, the policies of an inference service no longer requiring one are deleted
+	if ir.ingressConfig.Auth != nil {
+		if err := auth.NewAuthPolicyReconciler(ir.client, ir.ingressConfig).Reconcile(isvc, serviceHost, path,
+			isInternal); err != nil {
+			return errors.Wrapf(err, "fails to reconcile auth policies")
+		}
+	}
+	if err := auth.NewAPIKeyReconciler(ir.client, ir.scheme, ir.ingressConfig).Reconcile(isvc, serviceHost, path,
+		isInternal); err != nil {
+		return errors.Wrapf(err, "fails to reconcile API keys")
+	}
+	// Build v1alpha2 compatibility routes, they must be matched before the predict route
+	if compatibility, err := ir.isV1Alpha2CompatibilityEnabled(isvc.Namespace); err != nil {
+		return errors.Wrapf(err, "fails to get namespace %s", isvc.Namespace)
+	} else if compatibility {
+		httpRoutes = append(httpRoutes, ir.createV1Alpha2CompatibilityRoutes(isvc, serviceHost, isInternal, backend)...)
+	}
+	// Build explain route
+	if isvc.Spec.Explainer != nil {
+		explainerRouter := istiov1alpha3.HTTPRoute{
+			Match: ir.createHTTPMatchRequest(constants.ExplainPrefix(), serviceHost,
+				network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				ir.createHTTPRouteDestination(constants.DefaultExplainerServiceName(isvc.Name), isvc.Namespace, constants.LocalGatewayHost),
+			},
+		}
+		setShadowMirror(&explainerRouter, isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec)
+		explainerRoutes := append(ir.createCanaryRoutes(constants.ExplainPrefix(), serviceHost,
+			network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal, constants.DefaultExplainerServiceName(isvc.Name),
+			isvc.Namespace, canaryMatch(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec)),
+			&explainerRouter)
+		setRoutePolicy(&isvc.Spec.Explainer.ComponentExtensionSpec, explainerRoutes...)
+		setHedgingPolicy(isvc, v1beta1.ExplainerComponent, &isvc.Spec.Explainer.ComponentExtensionSpec, explainerRoutes...)
+		httpRoutes = append(httpRoutes, explainerRoutes...)
+	}
+	// Add predict route
+	predictRoute := &istiov1alpha3.HTTPRoute{
+		Match: ir.createHTTPMatchRequest("", serviceHost,
+			network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal),
+		Route: []*istiov1alpha3.HTTPRouteDestination{
+			ir.createHTTPRouteDestination(backend, isvc.Namespace, constants.LocalGatewayHost),
+		},
+	}
+	backendComponent, backendExt := v1beta1.PredictorComponent, &isvc.Spec.Predictor.ComponentExtensionSpec
+	if isvc.Spec.Transformer != nil {
+		backendComponent, backendExt = v1beta1.TransformerComponent, &isvc.Spec.Transformer.ComponentExtensionSpec
+	}
+	setShadowMirror(predictRoute, isvc, backendComponent, backendExt)
+	predictRoutes := append(ir.createCanaryRoutes("", serviceHost,
+		network.GetServiceHostname(isvc.Name, isvc.Namespace), isInternal, backend, isvc.Namespace,
+		canaryMatch(isvc, backendComponent, backendExt)),
+		predictRoute)
+	setRoutePolicy(backendExt, predictRoutes...)
+	setHedgingPolicy(isvc, backendComponent, backendExt, predictRoutes...)
+	setStreamingPolicy(isvc, backendExt, predictRoutes...)
+	httpRoutes = append(httpRoutes, predictRoutes...)
+
+	//Create external service which points to local gateway
+	if err := ir.reconcileExternalService(isvc); err != nil {
+		return errors.Wrapf(err, "fails to reconcile external name service")
+	}
+	//Create ingress, the internal inference services are kept off the external gateway
+	gateways := []string{ir.ingressConfig.IngressGateway, constants.KnativeLocalGateway}
+	if isInternal {
+		hosts = []string{network.GetServiceHostname(isvc.Name, isvc.Namespace)}
+		gateways = []string{constants.KnativeLocalGateway}
+	}
+	if tls {
+		tlsGateway := isvc.Namespace + "/" + constants.TLSGatewayName(isvc.Name)
+		addExternalGateway(httpRoutes, ir.ingressConfig.IngressGateway, tlsGateway)
+		gateways = append(gateways, tlsGateway)
+	}
+	if err := ir.reconcileVirtualService(isvc, hosts, gateways, httpRoutes); err != nil {
+		return err
+	}
+	// Hedge the requests of the named routes at the ingress gateways
+	if err := envoyfilter.NewHedgingReconciler(ir.client, ir.ingressConfig).Reconcile(isvc); err != nil {
+		return errors.Wrapf(err, "fails to reconcile hedging")
+	}
+
+	return setIngressReady(isvc, serviceUrl, serviceHost, network.GetServiceHostname(isvc.Name, isvc.Namespace))
+}
+
+// Delete deletes the VirtualService and the DestinationRules of the inference service
+func (ir *IngressReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	if err := ir.deleteDestinationRules(isvc, nil); err != nil {
+		return err
+	}
+	existing := &v1alpha3.VirtualService{}
+	if err := ir.client.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, existing); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	log.Info("Deleting Ingress for isvc", "namespace", isvc.Namespace, "name", isvc.Name)
+	if err := ir.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete ingress")
+	}
+	return nil
 }
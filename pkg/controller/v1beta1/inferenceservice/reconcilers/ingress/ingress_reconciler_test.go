@@ -0,0 +1,678 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateV1Alpha2CompatibilityRoutes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	protocolV2 := constants.ProtocolV2
+	ir := NewIngressReconciler(nil, nil, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	scenarios := map[string]struct {
+		predictor     v1beta1.PredictorSpec
+		transformer   *v1beta1.TransformerSpec
+		expectedPaths map[string]string
+	}{
+		"ProtocolV1": {
+			predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/sklearn"),
+					},
+				},
+			},
+			expectedPaths: map[string]string{},
+		},
+		"ProtocolV2": {
+			predictor: v1beta1.PredictorSpec{
+				PyTorch: &v1beta1.TorchServeSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI:      proto.String("gs://models/pytorch"),
+						ProtocolVersion: &protocolV2,
+					},
+				},
+			},
+			expectedPaths: map[string]string{
+				"^/v1/models/my-model:predict$": "/v2/models/my-model/infer",
+				"^/v1/models/my-model$":         "/v2/models/my-model/ready",
+			},
+		},
+		"Triton": {
+			predictor: v1beta1.PredictorSpec{
+				Triton: &v1beta1.TritonSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/triton"),
+					},
+				},
+			},
+			expectedPaths: map[string]string{
+				"^/v1/models/my-model:predict$": "/v2/models/my-model/infer",
+				"^/v1/models/my-model$":         "/v2/models/my-model/ready",
+			},
+		},
+		"TransformerServesV1": {
+			predictor: v1beta1.PredictorSpec{
+				Triton: &v1beta1.TritonSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						StorageURI: proto.String("gs://models/triton"),
+					},
+				},
+			},
+			transformer:   &v1beta1.TransformerSpec{},
+			expectedPaths: map[string]string{},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-model",
+					Namespace: "default",
+				},
+				Spec: v1beta1.InferenceServiceSpec{
+					Predictor:   scenario.predictor,
+					Transformer: scenario.transformer,
+				},
+			}
+			routes := ir.createV1Alpha2CompatibilityRoutes(isvc, "my-model.default.example.com", false,
+				constants.DefaultPredictorServiceName(isvc.Name))
+			paths := map[string]string{}
+			for _, route := range routes {
+				g.Expect(route.Match).To(gomega.HaveLen(2))
+				// The v1 payloads are not translated, the paths are kept
+				g.Expect(route.Rewrite).To(gomega.BeNil())
+				paths[route.Match[0].Uri.GetRegex()] = route.Headers.Response.Set["Warning"]
+			}
+			g.Expect(paths).To(gomega.HaveLen(len(scenario.expectedPaths)))
+			for path, v2Path := range scenario.expectedPaths {
+				g.Expect(paths[path]).To(gomega.ContainSubstring(v2Path))
+			}
+		})
+	}
+}
+
+func TestReconcileWarmPoolIngress(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	warmService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.WarmPoolClaimServiceName("my-model"), Namespace: "default"},
+	}
+	scenarios := map[string]struct {
+		annotations    map[string]string
+		objects        []runtime.Object
+		expectedServed bool
+	}{
+		"ServedByClaimedPod": {
+			annotations:    map[string]string{constants.WarmPoolAnnotationKey: "triton-t4"},
+			objects:        []runtime.Object{warmService},
+			expectedServed: true,
+		},
+		"PodNotClaimed": {
+			annotations:    map[string]string{constants.WarmPoolAnnotationKey: "triton-t4"},
+			expectedServed: false,
+		},
+		"NoWarmPool": {
+			objects:        []runtime.Object{warmService},
+			expectedServed: false,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-model",
+					Namespace:   "default",
+					UID:         "my-model-uid",
+					Annotations: scenario.annotations,
+				},
+			}
+			cl := fake.NewFakeClientWithScheme(scheme, append([]runtime.Object{isvc.DeepCopy()}, scenario.objects...)...)
+			ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:     constants.KnativeIngressGateway,
+				IngressServiceName: "someIngressServiceName",
+			})
+			served, err := ir.reconcileWarmPoolIngress(isvc)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(served).To(gomega.Equal(scenario.expectedServed))
+
+			virtualService := &v1alpha3.VirtualService{}
+			err = cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)
+			if !scenario.expectedServed {
+				g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(virtualService.Spec.Hosts).To(gomega.Equal([]string{"my-model.default.svc.cluster.local"}))
+			g.Expect(virtualService.Spec.Gateways).To(gomega.Equal([]string{constants.KnativeLocalGateway}))
+			g.Expect(virtualService.Spec.Http[0].Route[0].Destination.Host).To(gomega.Equal("my-model-warm.default.svc.cluster.local"))
+			g.Expect(isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeFalse())
+			g.Expect(isvc.Status.Address.URL.Host).To(gomega.Equal("my-model.default.svc.cluster.local"))
+		})
+	}
+}
+
+func TestSetShadowMirror(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		componentExt    v1beta1.ComponentExtensionSpec
+		componentStatus v1beta1.ComponentStatusSpec
+		expectedMirror  *istiov1alpha3.Destination
+		expectedPercent *gogotypes.UInt32Value
+	}{
+		"Shadow": {
+			componentExt: v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+				Shadow:               true,
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "my-model-predictor-default-fghij",
+				PreviousReadyRevision: "my-model-predictor-default-abcde",
+			},
+			expectedMirror: &istiov1alpha3.Destination{
+				Host: "my-model-predictor-default-fghij-private.default.svc.cluster.local",
+				Port: &istiov1alpha3.PortSelector{
+					Number: constants.CommonDefaultHttpPort,
+				},
+			},
+			expectedPercent: &gogotypes.UInt32Value{Value: 20},
+		},
+		"Canary": {
+			componentExt: v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "my-model-predictor-default-fghij",
+				PreviousReadyRevision: "my-model-predictor-default-abcde",
+			},
+		},
+		"RolledBackCanary": {
+			componentExt: v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+				Shadow:               true,
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision:   "my-model-predictor-default-fghij",
+				PreviousReadyRevision: "my-model-predictor-default-abcde",
+				RolledBackRevision:    "my-model-predictor-default-fghij",
+			},
+		},
+		"FirstRevision": {
+			componentExt: v1beta1.ComponentExtensionSpec{
+				CanaryTrafficPercent: proto.Int64(20),
+				Shadow:               true,
+			},
+			componentStatus: v1beta1.ComponentStatusSpec{
+				LatestReadyRevision: "my-model-predictor-default-abcde",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := &v1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-model",
+					Namespace: "default",
+				},
+				Status: v1beta1.InferenceServiceStatus{
+					Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+						v1beta1.PredictorComponent: scenario.componentStatus,
+					},
+				},
+			}
+			route := &istiov1alpha3.HTTPRoute{}
+			setShadowMirror(route, isvc, v1beta1.PredictorComponent, &scenario.componentExt)
+			g.Expect(route.Mirror).To(gomega.Equal(scenario.expectedMirror))
+			g.Expect(route.MirrorPercent).To(gomega.Equal(scenario.expectedPercent))
+		})
+	}
+}
+
+func TestCreateCanaryRoutes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	ir := NewIngressReconciler(nil, nil, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	routes := ir.createCanaryRoutes("", "my-model.default.example.com", "my-model.default.svc.cluster.local", false,
+		"my-model-predictor-default", "default", []v1beta1.CanaryMatch{
+			{
+				Headers:     map[string]string{"x-model-version": "canary"},
+				QueryParams: map[string]string{"variant": "b"},
+			},
+			{
+				Cookies: map[string]string{"model-version": "canary"},
+			},
+		})
+	g.Expect(routes).To(gomega.HaveLen(2))
+	for _, route := range routes {
+		// the local and the ingress gateways
+		g.Expect(route.Match).To(gomega.HaveLen(2))
+		g.Expect(route.Route[0].Headers.Request.Set).To(gomega.HaveKeyWithValue("Host",
+			"latest-my-model-predictor-default.default.svc.cluster.local"))
+	}
+	headerMatch := routes[0].Match[1]
+	g.Expect(headerMatch.Headers["x-model-version"].GetExact()).To(gomega.Equal("canary"))
+	g.Expect(headerMatch.QueryParams["variant"].GetExact()).To(gomega.Equal("b"))
+
+	cookieRegex := regexp.MustCompile(routes[1].Match[0].Headers["cookie"].GetRegex())
+	g.Expect(cookieRegex.MatchString("model-version=canary")).To(gomega.BeTrue())
+	g.Expect(cookieRegex.MatchString("session=abc; model-version=canary; theme=dark")).To(gomega.BeTrue())
+	g.Expect(cookieRegex.MatchString("model-version=canary-2")).To(gomega.BeFalse())
+	g.Expect(cookieRegex.MatchString("old-model-version=canary")).To(gomega.BeFalse())
+	g.Expect(routes[1].Match[0].QueryParams).To(gomega.BeNil())
+}
+
+func TestReconcileVisibility(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		visibility       v1beta1.Visibility
+		expectedHosts    []string
+		expectedGateways []string
+		expectedURL      string
+	}{
+		"External": {
+			visibility:       v1beta1.ExternalVisibility,
+			expectedHosts:    []string{"my-model.default.example.com", "my-model.default.svc.cluster.local"},
+			expectedGateways: []string{constants.KnativeIngressGateway, constants.KnativeLocalGateway},
+			expectedURL:      "my-model.default.example.com",
+		},
+		"ClusterLocal": {
+			visibility:       v1beta1.ClusterLocalVisibility,
+			expectedHosts:    []string{"my-model.default.svc.cluster.local"},
+			expectedGateways: []string{constants.KnativeLocalGateway},
+			expectedURL:      "my-model.default.svc.cluster.local",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := makeReadyInferenceService(nil, nil, nil)
+			isvc.Spec.Visibility = scenario.visibility
+			cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+			ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:     constants.KnativeIngressGateway,
+				IngressServiceName: "someIngressServiceName",
+			})
+			g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+			virtualService := &v1alpha3.VirtualService{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+			g.Expect(virtualService.Spec.Hosts).To(gomega.Equal(scenario.expectedHosts))
+			g.Expect(virtualService.Spec.Gateways).To(gomega.Equal(scenario.expectedGateways))
+			for _, route := range virtualService.Spec.Http {
+				for _, match := range route.Match {
+					g.Expect(match.Gateways).To(gomega.ConsistOf(gomega.BeElementOf(scenario.expectedGateways)))
+				}
+			}
+			g.Expect(isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeTrue())
+			g.Expect(isvc.Status.URL.Host).To(gomega.Equal(scenario.expectedURL))
+		})
+	}
+}
+
+func TestReconcilePathRoutes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		explainer        *v1beta1.ExplainerSpec
+		expectedPrefixes map[string]string
+	}{
+		"Predictor": {
+			expectedPrefixes: map[string]string{
+				"/serving/default/my-model/": "/",
+			},
+		},
+		"Explainer": {
+			explainer: &v1beta1.ExplainerSpec{},
+			expectedPrefixes: map[string]string{
+				"/serving/default/my-model/v1/models/my-model:explain": "/v1/models/my-model:explain",
+				"/serving/default/my-model/":                           "/",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := makeReadyInferenceService(nil, nil, scenario.explainer)
+			cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+			ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:     constants.KnativeIngressGateway,
+				IngressServiceName: "someIngressServiceName",
+				PathTemplate:       "/serving/{{.Namespace}}/{{.Name}}/",
+				IngressDomain:      "models.example.com",
+			})
+			g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+			virtualService := &v1alpha3.VirtualService{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+			g.Expect(virtualService.Spec.Hosts).To(gomega.ContainElement("models.example.com"))
+			prefixes := map[string]string{}
+			for i, route := range virtualService.Spec.Http[:len(scenario.expectedPrefixes)] {
+				g.Expect(route.Match[0].Authority.GetRegex()).To(gomega.Equal(constants.HostRegExp("models.example.com")))
+				prefixes[route.Match[0].Uri.GetPrefix()] = route.Rewrite.Uri
+				if i == len(scenario.expectedPrefixes)-1 {
+					g.Expect(route.Match[0].Uri.GetPrefix()).To(gomega.Equal("/serving/default/my-model/"))
+				}
+			}
+			g.Expect(prefixes).To(gomega.Equal(scenario.expectedPrefixes))
+			g.Expect(isvc.Status.URL.String()).To(gomega.Equal("http://models.example.com/serving/default/my-model"))
+			g.Expect(isvc.Status.ExternalHost).To(gomega.Equal("my-model.default.example.com"))
+		})
+	}
+}
+
+func TestReconcileCertificate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	ingressGateway := &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "knative-ingress-gateway", Namespace: "knative-serving"},
+	}
+	issuedCertificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}}
+	issuedCertificate.SetAPIVersion(constants.CertManagerAPIVersion)
+	issuedCertificate.SetKind(constants.CertManagerCertificate)
+	issuedCertificate.SetName(constants.CertificateName("my-model", "default"))
+	issuedCertificate.SetNamespace(constants.DefaultCertificateNamespace)
+
+	scenarios := map[string]struct {
+		objects          []runtime.Object
+		expectedGateways []string
+		expectedURL      string
+	}{
+		"Pending": {
+			expectedGateways: []string{constants.KnativeIngressGateway, constants.KnativeLocalGateway, "default/my-model-tls"},
+			expectedURL:      "http://my-model.default.example.com",
+		},
+		"Issued": {
+			objects:          []runtime.Object{issuedCertificate},
+			expectedGateways: []string{constants.KnativeIngressGateway, constants.KnativeLocalGateway, "default/my-model-tls"},
+			expectedURL:      "https://my-model.default.example.com",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := makeReadyInferenceService(nil, nil, nil)
+			cl := fake.NewFakeClientWithScheme(scheme, append([]runtime.Object{isvc.DeepCopy(), ingressGateway.DeepCopy()},
+				scenario.objects...)...)
+			ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:     constants.KnativeIngressGateway,
+				IngressServiceName: "someIngressServiceName",
+				CertificateIssuer:  "letsencrypt",
+			})
+			g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+			virtualService := &v1alpha3.VirtualService{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+			g.Expect(virtualService.Spec.Gateways).To(gomega.Equal(scenario.expectedGateways))
+			for _, route := range virtualService.Spec.Http {
+				for _, match := range route.Match {
+					if match.Gateways[0] == constants.KnativeIngressGateway {
+						g.Expect(match.Gateways).To(gomega.ContainElement("default/my-model-tls"))
+					}
+				}
+			}
+			g.Expect(isvc.Status.URL.String()).To(gomega.Equal(scenario.expectedURL))
+
+			// The certificate is deleted once the inference service is cluster local
+			isvc.Spec.Visibility = v1beta1.ClusterLocalVisibility
+			g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+			g.Expect(isvc.Status.GetCondition(v1beta1.CertificateReady)).To(gomega.BeNil())
+			err := cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-tls", Namespace: "default"}, &v1alpha3.Gateway{})
+			g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+		})
+	}
+}
+
+func TestReconcileRouteTimeout(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, &v1beta1.ExplainerSpec{
+		ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{TimeoutSeconds: proto.Int64(300)},
+	})
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(60)
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+	virtualService := &v1alpha3.VirtualService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http).To(gomega.HaveLen(2))
+	explainRoute, predictRoute := virtualService.Spec.Http[0], virtualService.Spec.Http[1]
+	g.Expect(explainRoute.Match[0].Uri.GetRegex()).To(gomega.Equal(constants.ExplainPrefix()))
+	g.Expect(explainRoute.Timeout).To(gomega.Equal(&gogotypes.Duration{Seconds: 300}))
+	g.Expect(explainRoute.Retries.PerTryTimeout).To(gomega.Equal(&gogotypes.Duration{Seconds: 300}))
+	g.Expect(predictRoute.Timeout).To(gomega.Equal(&gogotypes.Duration{Seconds: 60}))
+	g.Expect(predictRoute.Retries).To(gomega.Equal(&istiov1alpha3.HTTPRetry{
+		Attempts:      constants.DefaultRouteRetryAttempts,
+		PerTryTimeout: &gogotypes.Duration{Seconds: 60},
+	}))
+
+	// The routes of a component without a timeout keep the defaults of Istio
+	isvc.Spec.Predictor.TimeoutSeconds = nil
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http[1].Timeout).To(gomega.BeNil())
+	g.Expect(virtualService.Spec.Http[1].Retries).To(gomega.BeNil())
+}
+
+func TestReconcileRouteRetries(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		timeoutSeconds *int64
+		retry          *v1beta1.RetryPolicy
+		expected       *istiov1alpha3.HTTPRetry
+	}{
+		"RetryPolicyWithoutTimeout": {
+			retry: &v1beta1.RetryPolicy{
+				Attempts:      3,
+				PerTryTimeout: &metav1.Duration{Duration: 10 * time.Second},
+				RetryOn:       []string{"gateway-error", "503"},
+			},
+			expected: &istiov1alpha3.HTTPRetry{
+				Attempts:      3,
+				PerTryTimeout: &gogotypes.Duration{Seconds: 10},
+				RetryOn:       "gateway-error,503",
+			},
+		},
+		"RetryPolicyKeepsTimeoutPerTry": {
+			timeoutSeconds: proto.Int64(60),
+			retry:          &v1beta1.RetryPolicy{Attempts: 5},
+			expected: &istiov1alpha3.HTTPRetry{
+				Attempts:      5,
+				PerTryTimeout: &gogotypes.Duration{Seconds: 60},
+			},
+		},
+		"RetriesDisabled": {
+			timeoutSeconds: proto.Int64(60),
+			retry:          &v1beta1.RetryPolicy{},
+			expected:       &istiov1alpha3.HTTPRetry{},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			isvc := makeReadyInferenceService(nil, nil, nil)
+			isvc.Spec.Predictor.TimeoutSeconds = scenario.timeoutSeconds
+			isvc.Spec.Predictor.Retry = scenario.retry
+			cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+			ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressGateway:     constants.KnativeIngressGateway,
+				IngressServiceName: "someIngressServiceName",
+			})
+			g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+			virtualService := &v1alpha3.VirtualService{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace},
+				virtualService)).To(gomega.Succeed())
+			g.Expect(virtualService.Spec.Http).To(gomega.HaveLen(1))
+			g.Expect(virtualService.Spec.Http[0].Retries).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestReconcileStreamingRoutes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, &v1beta1.ExplainerSpec{})
+	isvc.Spec.Predictor.Protocol = v1beta1.StreamingProtocol
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "someIngressServiceName",
+	})
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+	virtualService := &v1alpha3.VirtualService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http).To(gomega.HaveLen(2))
+	explainRoute, predictRoute := virtualService.Spec.Http[0], virtualService.Spec.Http[1]
+	g.Expect(explainRoute.Timeout).To(gomega.BeNil())
+	g.Expect(explainRoute.Headers.GetResponse().GetSet()).NotTo(gomega.HaveKey(constants.AccelBufferingHeader))
+	g.Expect(predictRoute.Timeout).To(gomega.Equal(&gogotypes.Duration{}))
+	g.Expect(predictRoute.Headers.GetResponse().GetSet()).To(gomega.HaveKeyWithValue(constants.AccelBufferingHeader, "no"))
+
+	// The timeout of the predictor bounds the streamed responses
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(600)
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http[1].Timeout).To(gomega.Equal(&gogotypes.Duration{Seconds: 600}))
+
+	// The websocket connections are not bounded by a timeout either, their responses are not streamed
+	isvc.Spec.Predictor.TimeoutSeconds = nil
+	isvc.Spec.Predictor.Protocol = v1beta1.WebsocketProtocol
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	virtualService = &v1alpha3.VirtualService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http[1].Timeout).To(gomega.Equal(&gogotypes.Duration{}))
+	g.Expect(virtualService.Spec.Http[1].Headers.GetResponse().GetSet()).NotTo(gomega.HaveKey(constants.AccelBufferingHeader))
+}
+
+func TestReconcileHedgedRoutes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1alpha3.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, &v1beta1.ExplainerSpec{})
+	isvc.Spec.Predictor.TimeoutSeconds = proto.Int64(60)
+	isvc.Spec.Predictor.Hedging = &v1beta1.HedgingSpec{
+		Delay:     &metav1.Duration{Duration: 200 * time.Millisecond},
+		MaxHedges: proto.Int32(2),
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	ir := NewIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+		IngressGateway:     constants.KnativeIngressGateway,
+		IngressServiceName: "istio-ingressgateway.istio-system.svc.cluster.local",
+	})
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+
+	virtualService := &v1alpha3.VirtualService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http).To(gomega.HaveLen(2))
+	explainRoute, predictRoute := virtualService.Spec.Http[0], virtualService.Spec.Http[1]
+	g.Expect(explainRoute.Name).To(gomega.BeEmpty())
+	g.Expect(predictRoute.Name).To(gomega.Equal(constants.HedgingRouteName(isvc.Name, isvc.Namespace, "predictor")))
+	g.Expect(predictRoute.Timeout).To(gomega.Equal(&gogotypes.Duration{Seconds: 60}))
+	g.Expect(predictRoute.Retries).To(gomega.Equal(&istiov1alpha3.HTTPRetry{
+		Attempts:      2,
+		PerTryTimeout: &gogotypes.Duration{Nanos: 200000000},
+		RetryOn:       constants.HedgingRetryOn,
+	}))
+	envoyFilter := &unstructured.Unstructured{}
+	envoyFilter.SetAPIVersion(constants.IstioNetworkingAPIVersion)
+	envoyFilter.SetKind(constants.IstioEnvoyFilter)
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.HedgingEnvoyFilterName(isvc.Name, isvc.Namespace),
+		Namespace: "istio-system"}, envoyFilter)).To(gomega.Succeed())
+
+	// The requests are hedged after the latency percentile resolved by the controller
+	predictorStatus := isvc.Status.Components[v1beta1.PredictorComponent]
+	predictorStatus.HedgingDelay = &metav1.Duration{Duration: 350 * time.Millisecond}
+	isvc.Status.Components[v1beta1.PredictorComponent] = predictorStatus
+	g.Expect(ir.Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: isvc.Name, Namespace: isvc.Namespace}, virtualService)).To(gomega.Succeed())
+	g.Expect(virtualService.Spec.Http[1].Retries.PerTryTimeout).To(gomega.Equal(&gogotypes.Duration{Nanos: 350000000}))
+}
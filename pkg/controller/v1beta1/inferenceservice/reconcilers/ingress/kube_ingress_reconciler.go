@@ -0,0 +1,187 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	IngressClassAnnotationKey = "kubernetes.io/ingress.class"
+	// The Knative ingress routes the requests on the host of the component, the ingress controller rewrites the host
+	// header of the upstream requests
+	UpstreamVhostAnnotationKey = "nginx.ingress.kubernetes.io/upstream-vhost"
+	// The streamed responses of the predict route are passed to the client as they are received
+	ProxyBufferingAnnotationKey = "nginx.ingress.kubernetes.io/proxy-buffering"
+	// The websocket connections of the predict route are closed by the ingress controller once idle for these timeouts
+	ProxyReadTimeoutAnnotationKey = "nginx.ingress.kubernetes.io/proxy-read-timeout"
+	ProxySendTimeoutAnnotationKey = "nginx.ingress.kubernetes.io/proxy-send-timeout"
+	// DefaultWebsocketIdleTimeout is the idle timeout of the websocket connections, the default of nginx is 60 seconds
+	DefaultWebsocketIdleTimeout = "3600"
+)
+
+// KubeIngressReconciler programs standard networking.k8s.io Ingress resources for the clusters not running Istio. An
+// Ingress is created per component receiving traffic since the upstream host header is set per Ingress.
+type KubeIngressReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewKubeIngressReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *KubeIngressReconciler {
+	return &KubeIngressReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// createIngress routes the requests of the inference service host on the path to the Knative service of a component
+func (r *KubeIngressReconciler) createIngress(isvc *v1beta1.InferenceService, serviceHost string, path string,
+	componentServiceName string) *networkingv1beta1.Ingress {
+	annotations := map[string]string{
+		UpstreamVhostAnnotationKey: network.GetServiceHostname(componentServiceName, isvc.Namespace),
+	}
+	if r.ingressConfig.IngressClassName != "" {
+		annotations[IngressClassAnnotationKey] = r.ingressConfig.IngressClassName
+	}
+	if isvc.Spec.Predictor.IsStreaming() && componentServiceName == getBackendServiceName(isvc) {
+		annotations[ProxyBufferingAnnotationKey] = "off"
+	}
+	if isvc.Spec.Predictor.IsWebsocket() && componentServiceName == getBackendServiceName(isvc) {
+		annotations[ProxyReadTimeoutAnnotationKey] = DefaultWebsocketIdleTimeout
+		annotations[ProxySendTimeoutAnnotationKey] = DefaultWebsocketIdleTimeout
+	}
+	return &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        componentServiceName,
+			Namespace:   isvc.Namespace,
+			Annotations: annotations,
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{
+				{
+					Host: serviceHost,
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{
+								{
+									Path: path,
+									Backend: networkingv1beta1.IngressBackend{
+										ServiceName: componentServiceName,
+										ServicePort: intstr.FromInt(constants.CommonDefaultHttpPort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *KubeIngressReconciler) reconcileIngress(isvc *v1beta1.InferenceService, desired *networkingv1beta1.Ingress) error {
+	if err := controllerutil.SetControllerReference(isvc, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for ingress")
+	}
+	existing := &networkingv1beta1.Ingress{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			log.Info("Creating Kubernetes Ingress for isvc", "namespace", desired.Namespace, "name", desired.Name)
+			err = r.client.Create(context.TODO(), desired)
+		}
+	} else if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) ||
+		!equality.Semantic.DeepEqual(desired.Annotations, existing.Annotations) {
+		existing.Spec = desired.Spec
+		existing.Annotations = desired.Annotations
+		log.Info("Update Kubernetes Ingress for isvc", "namespace", desired.Namespace, "name", desired.Name)
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update ingress")
+	}
+	return nil
+}
+
+// deleteIngress deletes the Ingress of a component which does not receive traffic from outside the cluster
+func (r *KubeIngressReconciler) deleteIngress(namespace string, name string) error {
+	existing := &networkingv1beta1.Ingress{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, existing); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	log.Info("Deleting Kubernetes Ingress for isvc", "namespace", namespace, "name", name)
+	if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete ingress")
+	}
+	return nil
+}
+
+// Delete deletes the Ingress resources of the components of the inference service
+func (r *KubeIngressReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	for _, name := range getComponentServiceNames(isvc) {
+		if err := r.deleteIngress(isvc.Namespace, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile creates the Ingress resources of the predict and explain routes. The cluster local inference services
+// are not exposed, their address is the cluster local host of the component serving the predict route.
+func (r *KubeIngressReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if !checkComponentsReady(isvc) {
+		return nil
+	}
+	serviceHost, serviceUrl, err := getServiceHostAndUrl(r.client, isvc, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+	paths := getComponentPaths(isvc, serviceHost)
+	for _, name := range getComponentServiceNames(isvc) {
+		if path, ok := paths[name]; ok {
+			if err := r.reconcileIngress(isvc, r.createIngress(isvc, serviceHost, path, name)); err != nil {
+				return err
+			}
+		} else if err := r.deleteIngress(isvc.Namespace, name); err != nil {
+			return err
+		}
+	}
+	return setIngressReady(isvc, serviceUrl, serviceHost,
+		network.GetServiceHostname(getBackendServiceName(isvc), isvc.Namespace))
+}
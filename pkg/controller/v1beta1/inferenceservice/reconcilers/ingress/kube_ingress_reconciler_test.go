@@ -0,0 +1,193 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// makeReadyInferenceService creates an inference service whose components are ready and routed on the host of the
+// predictor, or of the transformer when there is one
+func makeReadyInferenceService(labels map[string]string, transformer *v1beta1.TransformerSpec,
+	explainer *v1beta1.ExplainerSpec) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-model",
+			Namespace: "default",
+			UID:       "my-model-uid",
+			Labels:    labels,
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Transformer: transformer,
+			Explainer:   explainer,
+		},
+	}
+	isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{}
+	for component, condition := range map[v1beta1.ComponentType]apis.ConditionType{
+		v1beta1.PredictorComponent:   v1beta1.PredictorReady,
+		v1beta1.TransformerComponent: v1beta1.TransformerReady,
+		v1beta1.ExplainerComponent:   v1beta1.ExplainerReady,
+	} {
+		isvc.Status.Components[component] = v1beta1.ComponentStatusSpec{
+			URL: &apis.URL{
+				Scheme: "http",
+				Host:   "my-model-" + string(component) + "-default.default.example.com",
+			},
+		}
+		isvc.Status.SetCondition(condition, &apis.Condition{
+			Type:   condition,
+			Status: corev1.ConditionTrue,
+		})
+	}
+	return isvc
+}
+
+func TestKubeIngressReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	staleIngress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultPredictorServiceName("my-model"), Namespace: "default"},
+	}
+	scenarios := map[string]struct {
+		isvc             *v1beta1.InferenceService
+		objects          []runtime.Object
+		expectedPaths    map[string]string
+		expectedURL      string
+		expectedAddress  string
+		expectedNotFound []string
+	}{
+		"Predictor": {
+			isvc: makeReadyInferenceService(nil, nil, nil),
+			expectedPaths: map[string]string{
+				"my-model-predictor-default": "/",
+			},
+			expectedURL:     "my-model.default.example.com",
+			expectedAddress: "my-model-predictor-default.default.svc.cluster.local",
+		},
+		"TransformerAndExplainer": {
+			isvc:    makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, &v1beta1.ExplainerSpec{}),
+			objects: []runtime.Object{staleIngress},
+			expectedPaths: map[string]string{
+				"my-model-transformer-default": "/",
+				"my-model-explainer-default":   "/v1/models/my-model:explain",
+			},
+			expectedURL:      "my-model.default.example.com",
+			expectedAddress:  "my-model-transformer-default.default.svc.cluster.local",
+			expectedNotFound: []string{"my-model-predictor-default"},
+		},
+		"ClusterLocal": {
+			isvc:             makeReadyInferenceService(map[string]string{constants.VisibilityLabel: "ClusterLocal"}, nil, nil),
+			objects:          []runtime.Object{staleIngress},
+			expectedPaths:    map[string]string{},
+			expectedURL:      "my-model-predictor-default.default.svc.cluster.local",
+			expectedAddress:  "my-model-predictor-default.default.svc.cluster.local",
+			expectedNotFound: []string{"my-model-predictor-default"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			cl := fake.NewFakeClientWithScheme(scheme, append([]runtime.Object{scenario.isvc.DeepCopy()}, scenario.objects...)...)
+			r := NewKubeIngressReconciler(cl, scheme, &v1beta1.IngressConfig{
+				IngressBackend:   v1beta1.KubernetesIngressBackend,
+				IngressClassName: "nginx",
+			})
+			g.Expect(r.Reconcile(scenario.isvc)).To(gomega.Succeed())
+
+			for serviceName, path := range scenario.expectedPaths {
+				ingress := &networkingv1beta1.Ingress{}
+				g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: "default"}, ingress)).To(gomega.Succeed())
+				g.Expect(ingress.Annotations).To(gomega.Equal(map[string]string{
+					IngressClassAnnotationKey:  "nginx",
+					UpstreamVhostAnnotationKey: serviceName + ".default.svc.cluster.local",
+				}))
+				g.Expect(ingress.Spec.Rules).To(gomega.HaveLen(1))
+				g.Expect(ingress.Spec.Rules[0].Host).To(gomega.Equal("my-model.default.example.com"))
+				g.Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Path).To(gomega.Equal(path))
+				g.Expect(ingress.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName).To(gomega.Equal(serviceName))
+			}
+			for _, serviceName := range scenario.expectedNotFound {
+				err := cl.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: "default"}, &networkingv1beta1.Ingress{})
+				g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+			}
+			g.Expect(scenario.isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeTrue())
+			g.Expect(scenario.isvc.Status.URL.Host).To(gomega.Equal(scenario.expectedURL))
+			g.Expect(scenario.isvc.Status.Address.URL.Host).To(gomega.Equal(scenario.expectedAddress))
+		})
+	}
+}
+
+func TestKubeIngressReconcileStreaming(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, &v1beta1.ExplainerSpec{})
+	isvc.Spec.Predictor.Protocol = v1beta1.StreamingProtocol
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	r := NewKubeIngressReconciler(cl, scheme, &v1beta1.IngressConfig{IngressBackend: v1beta1.KubernetesIngressBackend})
+	g.Expect(r.Reconcile(isvc)).To(gomega.Succeed())
+
+	// Only the ingress of the predict route passes the responses through unbuffered
+	ingress := &networkingv1beta1.Ingress{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-transformer-default", Namespace: "default"},
+		ingress)).To(gomega.Succeed())
+	g.Expect(ingress.Annotations).To(gomega.HaveKeyWithValue(ProxyBufferingAnnotationKey, "off"))
+	explainIngress := &networkingv1beta1.Ingress{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-explainer-default", Namespace: "default"},
+		explainIngress)).To(gomega.Succeed())
+	g.Expect(explainIngress.Annotations).NotTo(gomega.HaveKey(ProxyBufferingAnnotationKey))
+}
+
+func TestKubeIngressReconcileWebsocket(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := makeReadyInferenceService(nil, nil, nil)
+	isvc.Spec.Predictor.Protocol = v1beta1.WebsocketProtocol
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy())
+	r := NewKubeIngressReconciler(cl, scheme, &v1beta1.IngressConfig{IngressBackend: v1beta1.KubernetesIngressBackend})
+	g.Expect(r.Reconcile(isvc)).To(gomega.Succeed())
+
+	ingress := &networkingv1beta1.Ingress{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-predictor-default", Namespace: "default"},
+		ingress)).To(gomega.Succeed())
+	g.Expect(ingress.Annotations).To(gomega.HaveKeyWithValue(ProxyReadTimeoutAnnotationKey, DefaultWebsocketIdleTimeout))
+	g.Expect(ingress.Annotations).To(gomega.HaveKeyWithValue(ProxySendTimeoutAnnotationKey, DefaultWebsocketIdleTimeout))
+	g.Expect(ingress.Annotations).NotTo(gomega.HaveKey(ProxyBufferingAnnotationKey))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MappingReconciler programs Ambassador/Emissary Mappings for the clusters exposing their services with Ambassador. A
+// Mapping is created per component receiving traffic.
+type MappingReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	ingressConfig *v1beta1.IngressConfig
+}
+
+func NewMappingReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig) *MappingReconciler {
+	return &MappingReconciler{
+		client:        client,
+		scheme:        scheme,
+		ingressConfig: ingressConfig,
+	}
+}
+
+// createMapping routes the requests of the inference service host on the path prefix to the Knative service of a
+// component. The path is not rewritten and the host header is rewritten to the host the Knative ingress routes the
+// component on.
+func createMapping(isvc *v1beta1.InferenceService, serviceHost string, path string,
+	componentServiceName string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"host":         serviceHost,
+		"prefix":       path,
+		"rewrite":      "",
+		"service":      fmt.Sprintf("%s.%s:%d", componentServiceName, isvc.Namespace, constants.CommonDefaultHttpPort),
+		"host_rewrite": network.GetServiceHostname(componentServiceName, isvc.Namespace),
+	}
+	if path != "/" {
+		spec["prefix_exact"] = true
+	}
+	mapping := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	mapping.SetAPIVersion(constants.AmbassadorAPIVersion)
+	mapping.SetKind(constants.AmbassadorMapping)
+	mapping.SetName(componentServiceName)
+	mapping.SetNamespace(isvc.Namespace)
+	return mapping
+}
+
+// Reconcile creates or updates the Mappings of the predict and explain routes, and deletes the Mappings of the
+// components which no longer receive traffic from outside the cluster. The address of the inference service is the
+// cluster local host of the component serving the predict route.
+func (r *MappingReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	if !checkComponentsReady(isvc) {
+		return nil
+	}
+	serviceHost, serviceUrl, err := getServiceHostAndUrl(r.client, isvc, r.ingressConfig)
+	if err != nil {
+		return err
+	}
+	if serviceHost == "" || serviceUrl == "" {
+		return nil
+	}
+	paths := getComponentPaths(isvc, serviceHost)
+	for _, name := range getComponentServiceNames(isvc) {
+		if path, ok := paths[name]; ok {
+			if err := reconcileUnstructured(r.client, r.scheme, isvc, createMapping(isvc, serviceHost, path, name)); err != nil {
+				return err
+			}
+		} else if err := deleteUnstructured(r.client, constants.AmbassadorAPIVersion, constants.AmbassadorMapping,
+			isvc.Namespace, name); err != nil {
+			return err
+		}
+	}
+	return setIngressReady(isvc, serviceUrl, serviceHost,
+		network.GetServiceHostname(getBackendServiceName(isvc), isvc.Namespace))
+}
+
+// Delete deletes the Mappings of the components of the inference service
+func (r *MappingReconciler) Delete(isvc *v1beta1.InferenceService) error {
+	for _, name := range getComponentServiceNames(isvc) {
+		if err := deleteUnstructured(r.client, constants.AmbassadorAPIVersion, constants.AmbassadorMapping,
+			isvc.Namespace, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
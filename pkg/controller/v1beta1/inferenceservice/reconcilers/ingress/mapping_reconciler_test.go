@@ -0,0 +1,109 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMappingReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		isvc             *v1beta1.InferenceService
+		stale            []string
+		expectedSpecs    map[string]map[string]interface{}
+		expectedNotFound []string
+	}{
+		"Predictor": {
+			isvc: makeReadyInferenceService(nil, nil, nil),
+			expectedSpecs: map[string]map[string]interface{}{
+				"my-model-predictor-default": {
+					"host":         "my-model.default.example.com",
+					"prefix":       "/",
+					"rewrite":      "",
+					"service":      "my-model-predictor-default.default:80",
+					"host_rewrite": "my-model-predictor-default.default.svc.cluster.local",
+				},
+			},
+		},
+		"TransformerAndExplainer": {
+			isvc:  makeReadyInferenceService(nil, &v1beta1.TransformerSpec{}, &v1beta1.ExplainerSpec{}),
+			stale: []string{"my-model-predictor-default"},
+			expectedSpecs: map[string]map[string]interface{}{
+				"my-model-transformer-default": {
+					"host":         "my-model.default.example.com",
+					"prefix":       "/",
+					"rewrite":      "",
+					"service":      "my-model-transformer-default.default:80",
+					"host_rewrite": "my-model-transformer-default.default.svc.cluster.local",
+				},
+				"my-model-explainer-default": {
+					"host":         "my-model.default.example.com",
+					"prefix":       "/v1/models/my-model:explain",
+					"prefix_exact": true,
+					"rewrite":      "",
+					"service":      "my-model-explainer-default.default:80",
+					"host_rewrite": "my-model-explainer-default.default.svc.cluster.local",
+				},
+			},
+			expectedNotFound: []string{"my-model-predictor-default"},
+		},
+		"ClusterLocal": {
+			isvc:             makeReadyInferenceService(map[string]string{constants.VisibilityLabel: "ClusterLocal"}, nil, nil),
+			stale:            []string{"my-model-predictor-default"},
+			expectedSpecs:    map[string]map[string]interface{}{},
+			expectedNotFound: []string{"my-model-predictor-default"},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			objects := []runtime.Object{scenario.isvc.DeepCopy()}
+			for _, name := range scenario.stale {
+				objects = append(objects, createMapping(scenario.isvc, "my-model.default.example.com", "/", name))
+			}
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := NewMappingReconciler(cl, scheme, &v1beta1.IngressConfig{IngressBackend: v1beta1.AmbassadorIngressBackend})
+			g.Expect(r.Reconcile(scenario.isvc)).To(gomega.Succeed())
+
+			for serviceName, spec := range scenario.expectedSpecs {
+				mapping, err := getUnstructured(cl, constants.AmbassadorAPIVersion, constants.AmbassadorMapping, "default", serviceName)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(mapping).NotTo(gomega.BeNil())
+				g.Expect(mapping.Object["spec"]).To(gomega.Equal(spec))
+			}
+			for _, serviceName := range scenario.expectedNotFound {
+				mapping, err := getUnstructured(cl, constants.AmbassadorAPIVersion, constants.AmbassadorMapping, "default", serviceName)
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(mapping).To(gomega.BeNil())
+			}
+			g.Expect(scenario.isvc.Status.IsConditionReady(v1beta1.IngressReady)).To(gomega.BeTrue())
+		})
+	}
+}
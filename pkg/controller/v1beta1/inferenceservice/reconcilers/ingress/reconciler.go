@@ -0,0 +1,341 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Reconciler programs the routing of the traffic of an inference service to its components and sets the IngressReady
+// condition, the URL and the address of the inference service
+type Reconciler interface {
+	Reconcile(isvc *v1beta1.InferenceService) error
+	// Delete deletes the routing resources of a suspended inference service
+	Delete(isvc *v1beta1.InferenceService) error
+}
+
+// NewReconciler creates the reconciler of the ingress backend selected in the ingress config, the changes made to the
+// virtual services of the Istio backend are logged to the mutations and its DestinationRules follow the mesh config
+func NewReconciler(client client.Client, scheme *runtime.Scheme, ingressConfig *v1beta1.IngressConfig,
+	meshConfig *v1beta1.MeshConfig, mutations *audit.Mutations) Reconciler {
+	switch ingressConfig.IngressBackend {
+	case v1beta1.KubernetesIngressBackend:
+		return NewKubeIngressReconciler(client, scheme, ingressConfig)
+	case v1beta1.GatewayAPIIngressBackend:
+		return NewHTTPRouteReconciler(client, scheme, ingressConfig)
+	case v1beta1.AmbassadorIngressBackend:
+		return NewMappingReconciler(client, scheme, ingressConfig)
+	case v1beta1.ContourIngressBackend:
+		return NewHTTPProxyReconciler(client, scheme, ingressConfig)
+	default:
+		reconciler := NewIngressReconciler(client, scheme, ingressConfig)
+		reconciler.Mutations = mutations
+		reconciler.Mesh = meshConfig
+		return reconciler
+	}
+}
+
+// templateData are the fields of the inference service available to the domain and path templates of the ingress
+// config
+type templateData struct {
+	Name        string
+	Namespace   string
+	Annotations map[string]string
+	Labels      map[string]string
+}
+
+// executeTemplate generates a host or a path of the inference service with a template of the ingress config
+func executeTemplate(name string, text string, isvc *v1beta1.InferenceService) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "fails to parse %s template", name)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, templateData{
+		Name:        isvc.Name,
+		Namespace:   isvc.Namespace,
+		Annotations: isvc.Annotations,
+		Labels:      isvc.Labels,
+	}); err != nil {
+		return "", errors.Wrapf(err, "fails to execute %s template", name)
+	}
+	return buf.String(), nil
+}
+
+// getExternalHost returns the host of the ingress host annotation, or the host generated with the domain template of
+// the ingress config. It returns an empty host when neither is set. The host of the annotation must be under the host
+// override domains of the ingress config and not already used by another inference service.
+func getExternalHost(cl client.Reader, isvc *v1beta1.InferenceService,
+	ingressConfig *v1beta1.IngressConfig) (string, error) {
+	host, ok := isvc.Annotations[constants.IngressHostAnnotationKey]
+	if !ok {
+		if ingressConfig.DomainTemplate == "" {
+			return "", nil
+		}
+		var err error
+		if host, err = executeTemplate("domain", ingressConfig.DomainTemplate, isvc); err != nil {
+			return "", err
+		}
+	}
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return "", fmt.Errorf("invalid external host %q: %s", host, strings.Join(errs, ", "))
+	}
+	if ok {
+		if !v1beta1.IsHostOverrideAllowed(ingressConfig, host) || host == ingressConfig.IngressDomain {
+			return "", fmt.Errorf("external host %q of the %s annotation is not under the hostOverrideDomains of "+
+				"the ingress config", host, constants.IngressHostAnnotationKey)
+		}
+		if err := checkHostAvailable(cl, isvc, host); err != nil {
+			return "", err
+		}
+	}
+	return host, nil
+}
+
+// checkHostAvailable returns an error when the host is the external host of another inference service, or is claimed
+// by the ingress host annotation of an older inference service
+func checkHostAvailable(cl client.Reader, isvc *v1beta1.InferenceService, host string) error {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := cl.List(context.TODO(), isvcs); err != nil {
+		return errors.Wrapf(err, "fails to list inference services")
+	}
+	for i := range isvcs.Items {
+		other := &isvcs.Items[i]
+		if other.Namespace == isvc.Namespace && other.Name == isvc.Name {
+			continue
+		}
+		used := other.Status.ExternalHost == host ||
+			other.Status.URL != nil && other.Status.URL.Host == host && other.Status.URL.Path == ""
+		claimed := other.Annotations[constants.IngressHostAnnotationKey] == host &&
+			other.CreationTimestamp.Before(&isvc.CreationTimestamp)
+		if used || claimed {
+			return fmt.Errorf("external host %q of the %s annotation is already used by inference service %s/%s",
+				host, constants.IngressHostAnnotationKey, other.Namespace, other.Name)
+		}
+	}
+	return nil
+}
+
+// getServicePath returns the path prefix of the inference service on the ingress domain generated with the path
+// template of the ingress config, without trailing slash
+func getServicePath(isvc *v1beta1.InferenceService, ingressConfig *v1beta1.IngressConfig) (string, error) {
+	path, err := executeTemplate("path", ingressConfig.PathTemplate, isvc)
+	if err != nil {
+		return "", err
+	}
+	path = strings.TrimRight(path, "/")
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, " ?#") {
+		return "", fmt.Errorf("invalid path prefix %q, it must be an absolute path", path)
+	}
+	return path, nil
+}
+
+// getServiceHostAndUrl returns the external host and URL of the inference service, derived from the URL of the
+// component receiving the traffic. The host is replaced by the external host of the annotation or of the domain
+// template for the inference services exposed outside the cluster.
+func getServiceHostAndUrl(cl client.Reader, isvc *v1beta1.InferenceService,
+	ingressConfig *v1beta1.IngressConfig) (string, string, error) {
+	serviceHost := getServiceHost(isvc)
+	serviceUrl := getServiceUrl(isvc)
+	if serviceHost == "" || serviceUrl == "" || isInternalService(isvc, serviceHost) {
+		return serviceHost, serviceUrl, nil
+	}
+	host, err := getExternalHost(cl, isvc, ingressConfig)
+	if err != nil || host == "" {
+		return serviceHost, serviceUrl, err
+	}
+	url, err := apis.ParseURL(serviceUrl)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "fails to parse service url")
+	}
+	url.Host = host
+	return host, url.String(), nil
+}
+
+// checkComponentsReady sets the IngressReady condition to false and returns false while a component receiving traffic
+// from the ingress is not ready
+func checkComponentsReady(isvc *v1beta1.InferenceService) bool {
+	checks := []struct {
+		enabled   bool
+		condition apis.ConditionType
+		reason    string
+	}{
+		{true, v1beta1.PredictorReady, "Predictor ingress not created"},
+		{isvc.Spec.Transformer != nil, v1beta1.TransformerReady, "Transformer ingress not created"},
+		{isvc.Spec.Explainer != nil, v1beta1.ExplainerReady, "Explainer ingress not created"},
+	}
+	for _, check := range checks {
+		if check.enabled && !isvc.Status.IsConditionReady(check.condition) {
+			isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
+				Type:   v1beta1.IngressReady,
+				Status: corev1.ConditionFalse,
+				Reason: check.reason,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// getBackendServiceName is the name of the component serving the predict route, the transformer when there is one
+func getBackendServiceName(isvc *v1beta1.InferenceService) string {
+	if isvc.Spec.Transformer != nil {
+		return constants.DefaultTransformerServiceName(isvc.Name)
+	}
+	return constants.DefaultPredictorServiceName(isvc.Name)
+}
+
+// getComponentPaths are the path prefixes of the components receiving traffic from outside the cluster by component
+// service name, the predict route of the backend and the explain route of the explainer
+func getComponentPaths(isvc *v1beta1.InferenceService, serviceHost string) map[string]string {
+	paths := map[string]string{}
+	if isInternalService(isvc, serviceHost) {
+		return paths
+	}
+	paths[getBackendServiceName(isvc)] = "/"
+	if isvc.Spec.Explainer != nil {
+		paths[constants.DefaultExplainerServiceName(isvc.Name)] = constants.ExplainPath(isvc.Name)
+	}
+	return paths
+}
+
+// getComponentServiceNames are the service names of all the components which may receive traffic from outside the
+// cluster, the routing resources of the components without a path are deleted
+func getComponentServiceNames(isvc *v1beta1.InferenceService) []string {
+	return []string{constants.DefaultPredictorServiceName(isvc.Name), constants.DefaultTransformerServiceName(isvc.Name),
+		constants.DefaultExplainerServiceName(isvc.Name)}
+}
+
+// isInternalService is true when the inference service is cluster local or the knative domain is configured as
+// internal
+func isInternalService(isvc *v1beta1.InferenceService, serviceHost string) bool {
+	return isvc.IsClusterLocal() || serviceHost == network.GetServiceHostname(isvc.Name, isvc.Namespace)
+}
+
+// setIngressReady sets the URL, the external host and the cluster local address of the inference service once its
+// routes are programmed, the URL of an internal inference service is its cluster local address
+func setIngressReady(isvc *v1beta1.InferenceService, serviceUrl string, serviceHost string, internalHost string) error {
+	url, err := apis.ParseURL(serviceUrl)
+	if err != nil {
+		return errors.Wrapf(err, "fails to parse service url")
+	}
+	if isInternalService(isvc, url.Host) {
+		url = &apis.URL{
+			Host:   internalHost,
+			Scheme: "http",
+		}
+	}
+	isvc.Status.URL = url
+	isvc.Status.ExternalHost = serviceHost
+	if isInternalService(isvc, serviceHost) {
+		isvc.Status.ExternalHost = ""
+	}
+	isvc.Status.Address = &duckv1.Addressable{
+		URL: &apis.URL{
+			Host:   internalHost,
+			Scheme: "http",
+		},
+	}
+	isvc.Status.SetCondition(v1beta1.IngressReady, &apis.Condition{
+		Type:   v1beta1.IngressReady,
+		Status: corev1.ConditionTrue,
+	})
+	return nil
+}
+
+// getUnstructured gets a routing resource of a CRD of the ingress backend, it returns nil when the resource does not
+// exist
+func getUnstructured(cl client.Client, apiVersion string, kind string, namespace string,
+	name string) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(apiVersion)
+	existing.SetKind(kind)
+	err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, existing)
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("the %s CRD of the ingress backend is not installed", kind)
+	}
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return existing, nil
+}
+
+// reconcileUnstructured creates the routing resource owned by the inference service, or updates its spec and
+// annotations. The routing resources of the backends other than Istio and Kubernetes are unstructured so their CRDs
+// are only required in the clusters using them.
+func reconcileUnstructured(cl client.Client, scheme *runtime.Scheme, isvc *v1beta1.InferenceService,
+	desired *unstructured.Unstructured) error {
+	if err := controllerutil.SetControllerReference(isvc, desired, scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for %s", desired.GetKind())
+	}
+	existing, err := getUnstructured(cl, desired.GetAPIVersion(), desired.GetKind(), desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		log.Info("Creating "+desired.GetKind()+" for isvc", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = cl.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) ||
+		!equality.Semantic.DeepEqual(desired.GetAnnotations(), existing.GetAnnotations()) {
+		existing.Object["spec"] = desired.Object["spec"]
+		existing.SetAnnotations(desired.GetAnnotations())
+		log.Info("Update "+desired.GetKind()+" for isvc", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = cl.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update %s", desired.GetKind())
+	}
+	return nil
+}
+
+// deleteUnstructured deletes a routing resource which no longer routes traffic from outside the cluster
+func deleteUnstructured(cl client.Client, apiVersion string, kind string, namespace string, name string) error {
+	existing, err := getUnstructured(cl, apiVersion, kind, namespace, name)
+	if err != nil || existing == nil {
+		return err
+	}
+	log.Info("Deleting "+kind+" for isvc", "namespace", namespace, "name", name)
+	if err := cl.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete %s", kind)
+	}
+	return nil
+}
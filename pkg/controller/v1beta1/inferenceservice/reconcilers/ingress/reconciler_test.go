@@ -0,0 +1,131 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetServiceHostAndUrl(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	created := metav1.NewTime(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))
+	// The host of the status of served is used, the annotation of the older claimer claims its host
+	served := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "served", Namespace: "other", CreationTimestamp: created},
+		Status: v1beta1.InferenceServiceStatus{
+			ExternalHost: "served.models.example.com",
+			URL:          &apis.URL{Scheme: "http", Host: "served.models.example.com"},
+		},
+	}
+	claimer := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "claimer",
+			Namespace:         "other",
+			CreationTimestamp: metav1.NewTime(created.Add(-time.Hour)),
+			Annotations:       map[string]string{constants.IngressHostAnnotationKey: "claimed.models.example.com"},
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, served, claimer)
+
+	scenarios := map[string]struct {
+		annotations    map[string]string
+		labels         map[string]string
+		domainTemplate string
+		expectedHost   string
+		expectedUrl    string
+		expectedError  bool
+	}{
+		"KnativeHost": {
+			expectedHost: "my-model.default.example.com",
+			expectedUrl:  "http://my-model.default.example.com",
+		},
+		"DomainTemplate": {
+			domainTemplate: "{{.Name}}-{{.Namespace}}.models.example.com",
+			expectedHost:   "my-model-default.models.example.com",
+			expectedUrl:    "http://my-model-default.models.example.com",
+		},
+		"DomainTemplateLabels": {
+			labels:         map[string]string{"team": "vision"},
+			domainTemplate: "{{.Name}}.{{.Labels.team}}.models.example.com",
+			expectedHost:   "my-model.vision.models.example.com",
+			expectedUrl:    "http://my-model.vision.models.example.com",
+		},
+		"AnnotationOverridesTemplate": {
+			annotations:    map[string]string{constants.IngressHostAnnotationKey: "flowers.models.example.com"},
+			domainTemplate: "{{.Name}}-{{.Namespace}}.models.example.com",
+			expectedHost:   "flowers.models.example.com",
+			expectedUrl:    "http://flowers.models.example.com",
+		},
+		"AnnotationOutsideOverrideDomains": {
+			annotations:   map[string]string{constants.IngressHostAnnotationKey: "flowers.example.com"},
+			expectedError: true,
+		},
+		"AnnotationOverrideDomain": {
+			annotations:   map[string]string{constants.IngressHostAnnotationKey: "models.example.com"},
+			expectedError: true,
+		},
+		"AnnotationHostOfAnotherService": {
+			annotations:   map[string]string{constants.IngressHostAnnotationKey: "served.models.example.com"},
+			expectedError: true,
+		},
+		"AnnotationHostClaimedByOlderService": {
+			annotations:   map[string]string{constants.IngressHostAnnotationKey: "claimed.models.example.com"},
+			expectedError: true,
+		},
+		"ClusterLocal": {
+			labels:         map[string]string{constants.VisibilityLabel: constants.VisibilityClusterLocal},
+			domainTemplate: "{{.Name}}-{{.Namespace}}.models.example.com",
+			expectedHost:   "my-model.default.example.com",
+			expectedUrl:    "http://my-model.default.example.com",
+		},
+		"InvalidHost": {
+			domainTemplate: "{{.Name}}_{{.Namespace}}.models.example.com",
+			expectedError:  true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			isvc := makeReadyInferenceService(scenario.labels, nil, nil)
+			isvc.Annotations = scenario.annotations
+			isvc.CreationTimestamp = created
+			host, url, err := getServiceHostAndUrl(cl, isvc, &v1beta1.IngressConfig{
+				DomainTemplate:      scenario.domainTemplate,
+				HostOverrideDomains: []string{"models.example.com"},
+			})
+			if scenario.expectedError {
+				g.Expect(err).To(gomega.HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(host).To(gomega.Equal(scenario.expectedHost))
+			g.Expect(url).To(gomega.Equal(scenario.expectedUrl))
+		})
+	}
+}
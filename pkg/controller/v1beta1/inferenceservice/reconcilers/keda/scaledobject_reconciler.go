@@ -0,0 +1,174 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/apply"
+	"github.com/pkg/errors"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("ScaledObjectReconciler")
+
+// ScaledObjectReconciler reconciles the KEDA ScaledObject scaling the deployment of the latest revision of the Knative
+// service of a component. The ScaledObject is unstructured so KEDA is only required in the clusters using it.
+type ScaledObjectReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	owner         metav1.Object
+	componentMeta metav1.ObjectMeta
+	componentExt  *v1beta1.ComponentExtensionSpec
+}
+
+func NewScaledObjectReconciler(client client.Client, scheme *runtime.Scheme, owner metav1.Object,
+	componentMeta metav1.ObjectMeta, componentExt *v1beta1.ComponentExtensionSpec) *ScaledObjectReconciler {
+	return &ScaledObjectReconciler{
+		client:        client,
+		scheme:        scheme,
+		owner:         owner,
+		componentMeta: componentMeta,
+		componentExt:  componentExt,
+	}
+}
+
+// revisionDeploymentName is the name of the deployment Knative creates for the revision
+func revisionDeploymentName(revision string) string {
+	return revision + "-deployment"
+}
+
+func createScaledObject(componentMeta metav1.ObjectMeta, componentExt *v1beta1.ComponentExtensionSpec,
+	latestCreatedRevision string) *unstructured.Unstructured {
+	triggers := []interface{}{}
+	for _, trigger := range componentExt.Keda.Triggers {
+		metadata := map[string]interface{}{}
+		for key, value := range trigger.Metadata {
+			metadata[key] = value
+		}
+		t := map[string]interface{}{
+			"type":     trigger.Type,
+			"metadata": metadata,
+		}
+		if trigger.AuthenticationRef != nil {
+			t["authenticationRef"] = map[string]interface{}{"name": *trigger.AuthenticationRef}
+		}
+		triggers = append(triggers, t)
+	}
+	minReplicas := constants.DefaultMinReplicas
+	if componentExt.MinReplicas != nil {
+		minReplicas = *componentExt.MinReplicas
+	}
+	spec := map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{
+			"name": revisionDeploymentName(latestCreatedRevision),
+		},
+		"minReplicaCount": int64(minReplicas),
+		"triggers":        triggers,
+	}
+	if componentExt.MaxReplicas != 0 {
+		spec["maxReplicaCount"] = int64(componentExt.MaxReplicas)
+	}
+	if componentExt.Keda.PollingInterval != nil {
+		spec["pollingInterval"] = int64(*componentExt.Keda.PollingInterval)
+	}
+	if componentExt.Keda.CooldownPeriod != nil {
+		spec["cooldownPeriod"] = int64(*componentExt.Keda.CooldownPeriod)
+	}
+
+	scaledObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	scaledObject.SetAPIVersion(constants.KedaAPIVersion)
+	scaledObject.SetKind(constants.KedaScaledObject)
+	scaledObject.SetName(componentMeta.Name)
+	scaledObject.SetNamespace(componentMeta.Namespace)
+	scaledObject.SetLabels(componentMeta.Labels)
+	return scaledObject
+}
+
+// Reconcile creates or updates the ScaledObject of the component, and deletes it once the component is no longer
+// scaled by KEDA. It runs before the Knative service is reconciled: the ScaledObject targets the latest created
+// revision of the existing Knative service, and is only created once the revisions of the Knative service have the
+// KEDA autoscaling class so the Knative autoscaler and KEDA never scale the same revision. The ScaledObject is only
+// looked up when the component is or was scaled by KEDA so the clusters without KEDA are not queried for it.
+func (r *ScaledObjectReconciler) Reconcile() error {
+	service := &knservingv1.Service{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.componentMeta.Name, Namespace: r.componentMeta.Namespace}, service); err != nil {
+		if apierr.IsNotFound(err) {
+			// The ScaledObject is created once Knative created the first revision of the component
+			return nil
+		}
+		return err
+	}
+	hasKedaClass := service.Spec.Template.Annotations[autoscaling.ClassAnnotationKey] == constants.KedaAutoscalerClass
+	if r.componentExt.Keda != nil && !hasKedaClass {
+		// The Knative service is updated with the KEDA autoscaling class after this reconcile, the update of the
+		// Knative service reconciles the component again
+		return nil
+	}
+	scaledByKeda := r.componentExt.Keda != nil
+	if !scaledByKeda && !hasKedaClass {
+		return nil
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.KedaAPIVersion)
+	existing.SetKind(constants.KedaScaledObject)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.componentMeta.Name, Namespace: r.componentMeta.Namespace}, existing)
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("the %s CRD of KEDA is not installed", constants.KedaScaledObject)
+	}
+	if err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !scaledByKeda {
+		if exists {
+			log.Info("Deleting KEDA ScaledObject", "namespace", existing.GetNamespace(), "name", existing.GetName())
+			if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+				return errors.Wrapf(err, "fails to delete ScaledObject")
+			}
+		}
+		return nil
+	}
+	if service.Status.LatestCreatedRevisionName == "" {
+		return nil
+	}
+	desired := createScaledObject(r.componentMeta, r.componentExt, service.Status.LatestCreatedRevisionName)
+	if err := controllerutil.SetControllerReference(r.owner, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for ScaledObject")
+	}
+	log.Info("Applying KEDA ScaledObject", "namespace", desired.GetNamespace(), "name", desired.GetName())
+	if err := apply.Object(context.TODO(), r.client, r.scheme, desired); err != nil {
+		return errors.Wrapf(err, "fails to apply ScaledObject")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateScaledObject(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	zeroReplicas := 0
+	pollingInterval := int32(10)
+	authenticationRef := "kafka-credentials"
+	scenarios := map[string]struct {
+		componentExt *v1beta1.ComponentExtensionSpec
+		expectedSpec map[string]interface{}
+	}{
+		"KafkaTrigger": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas: &zeroReplicas,
+				MaxReplicas: 5,
+				Keda: &v1beta1.KedaSpec{
+					PollingInterval: &pollingInterval,
+					Triggers: []v1beta1.KedaTrigger{
+						{
+							Type:              "kafka",
+							Metadata:          map[string]string{"topic": "requests", "lagThreshold": "50"},
+							AuthenticationRef: &authenticationRef,
+						},
+					},
+				},
+			},
+			expectedSpec: map[string]interface{}{
+				"scaleTargetRef":  map[string]interface{}{"name": "sklearn-predictor-default-abcde-deployment"},
+				"minReplicaCount": int64(0),
+				"maxReplicaCount": int64(5),
+				"pollingInterval": int64(10),
+				"triggers": []interface{}{
+					map[string]interface{}{
+						"type":              "kafka",
+						"metadata":          map[string]interface{}{"topic": "requests", "lagThreshold": "50"},
+						"authenticationRef": map[string]interface{}{"name": "kafka-credentials"},
+					},
+				},
+			},
+		},
+		"DefaultReplicas": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				Keda: &v1beta1.KedaSpec{
+					Triggers: []v1beta1.KedaTrigger{
+						{
+							Type:     "prometheus",
+							Metadata: map[string]string{"query": "sum(queue_depth)", "threshold": "100"},
+						},
+					},
+				},
+			},
+			expectedSpec: map[string]interface{}{
+				"scaleTargetRef":  map[string]interface{}{"name": "sklearn-predictor-default-abcde-deployment"},
+				"minReplicaCount": int64(constants.DefaultMinReplicas),
+				"triggers": []interface{}{
+					map[string]interface{}{
+						"type":     "prometheus",
+						"metadata": map[string]interface{}{"query": "sum(queue_depth)", "threshold": "100"},
+					},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			componentMeta := metav1.ObjectMeta{
+				Name:      "sklearn-predictor-default",
+				Namespace: "default",
+				Labels:    map[string]string{constants.KServiceComponentLabel: "predictor"},
+			}
+			scaledObject := createScaledObject(componentMeta, scenario.componentExt, "sklearn-predictor-default-abcde")
+			g.Expect(scaledObject.GetAPIVersion()).To(gomega.Equal(constants.KedaAPIVersion))
+			g.Expect(scaledObject.GetKind()).To(gomega.Equal(constants.KedaScaledObject))
+			g.Expect(scaledObject.GetName()).To(gomega.Equal(componentMeta.Name))
+			g.Expect(scaledObject.GetLabels()).To(gomega.Equal(componentMeta.Labels))
+			g.Expect(scaledObject.Object["spec"]).To(gomega.Equal(scenario.expectedSpec))
+			// The unstructured content must be deep copyable to be sent by the client
+			g.Expect(scaledObject.DeepCopy()).To(gomega.Equal(scaledObject))
+		})
+	}
+}
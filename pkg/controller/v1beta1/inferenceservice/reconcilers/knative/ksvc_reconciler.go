@@ -18,16 +18,18 @@ import (
 	"fmt"
 	"github.com/golang/protobuf/proto"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/audit"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/reconcilers/apply"
+	"github.com/kubeflow/kfserving/pkg/utils"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/retry"
-	"knative.dev/pkg/kmp"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,6 +45,18 @@ type KsvcReconciler struct {
 	Service         *knservingv1.Service
 	componentExt    *v1beta1.ComponentExtensionSpec
 	componentStatus v1beta1.ComponentStatusSpec
+	// RolloutNotes summarizes the change applied by the last call to Reconcile, empty if nothing changed
+	RolloutNotes string
+	// ProgressDeadline gates the blue green rollouts when set: the serving revision holds the traffic until the
+	// latest created revision is ready, the rollout is aborted when the revision is not ready within the deadline
+	ProgressDeadline time.Duration
+	// ServingRevision holds the traffic during the rollout of RolloutRevision, RolloutAborted is set once the rollout
+	// is aborted
+	ServingRevision string
+	RolloutRevision string
+	RolloutAborted  bool
+	// Mutations logs the changes made to the Knative service, nothing when nil
+	Mutations *audit.Mutations
 }
 
 func NewKsvcReconciler(client client.Client, scheme *runtime.Scheme, componentMeta metav1.ObjectMeta,
@@ -60,32 +74,69 @@ func NewKsvcReconciler(client client.Client, scheme *runtime.Scheme, componentMe
 func createKnativeService(componentMeta metav1.ObjectMeta,
 	componentExtension *v1beta1.ComponentExtensionSpec, podSpec *corev1.PodSpec,
 	componentStatus v1beta1.ComponentStatusSpec) *knservingv1.Service {
-	annotations := componentMeta.GetAnnotations()
+	// The revision annotations are merged in order: the component annotations, the revisionAnnotations, then the
+	// autoscaling fields which take precedence, the defaults only fill the autoscaling annotations left unset
+	annotations := utils.Union(componentMeta.GetAnnotations(), componentExtension.RevisionAnnotations)
 
-	if componentExtension.MinReplicas == nil {
-		annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(constants.DefaultMinReplicas)
-	} else {
+	if componentExtension.MinReplicas != nil {
 		annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(*componentExtension.MinReplicas)
+	} else if _, ok := annotations[autoscaling.MinScaleAnnotationKey]; !ok {
+		annotations[autoscaling.MinScaleAnnotationKey] = fmt.Sprint(constants.DefaultMinReplicas)
 	}
 
 	if componentExtension.MaxReplicas != 0 {
 		annotations[autoscaling.MaxScaleAnnotationKey] = fmt.Sprint(componentExtension.MaxReplicas)
 	}
 
+	// The scale metric selects the autoscaling class scaling on it, the HPA for cpu
+	if componentExtension.ScaleMetric != nil {
+		annotations[autoscaling.MetricAnnotationKey] = string(*componentExtension.ScaleMetric)
+		annotations[autoscaling.ClassAnnotationKey] = componentExtension.ScaleMetric.AutoscalerClass()
+	}
+	if componentExtension.ScaleTarget != nil {
+		annotations[autoscaling.TargetAnnotationKey] = fmt.Sprint(*componentExtension.ScaleTarget)
+	}
+	if componentExtension.TargetUtilizationPercentage != nil {
+		annotations[autoscaling.TargetUtilizationPercentageKey] = fmt.Sprint(*componentExtension.TargetUtilizationPercentage)
+	}
+	// The component scaled by KEDA is not scaled by the Knative autoscaler
+	if componentExtension.Keda != nil {
+		annotations[autoscaling.ClassAnnotationKey] = constants.KedaAutoscalerClass
+	}
+
 	// User can pass down scaling class annotation to overwrite the default scaling KPA
 	if _, ok := annotations[autoscaling.ClassAnnotationKey]; !ok {
 		annotations[autoscaling.ClassAnnotationKey] = autoscaling.KPA
 	}
 	trafficTargets := []knservingv1.TrafficTarget{}
-	if componentExtension.CanaryTrafficPercent != nil && componentStatus.PreviousReadyRevision != "" {
-		//canary rollout
+	if componentExtension.RollbackTo != nil {
+		//rollback, the latest revision is still reachable on its tag
+		trafficTargets = append(trafficTargets,
+			knservingv1.TrafficTarget{
+				Tag:            "latest",
+				LatestRevision: proto.Bool(true),
+				Percent:        proto.Int64(0),
+			})
+		trafficTargets = append(trafficTargets,
+			knservingv1.TrafficTarget{
+				Tag:            constants.RollbackTrafficTag,
+				RevisionName:   *componentExtension.RollbackTo,
+				LatestRevision: proto.Bool(false),
+				Percent:        proto.Int64(100),
+			})
+	} else if componentExtension.CanaryTrafficPercent != nil && componentStatus.PreviousReadyRevision != "" {
+		//canary rollout, the previous revision serves all the traffic once the canary is rolled back
+		canaryTraffic := canaryTrafficPercent(componentExtension)
+		if componentStatus.IsCanaryRolledBack() {
+			canaryTraffic = 0
+		}
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "latest",
 				LatestRevision: proto.Bool(true),
-				Percent:        proto.Int64(*componentExtension.CanaryTrafficPercent),
+				Percent:        proto.Int64(canaryTraffic),
 			})
-		remainingTraffic := 100 - *componentExtension.CanaryTrafficPercent
+		remainingTraffic := 100 - canaryTraffic
 		trafficTargets = append(trafficTargets,
 			knservingv1.TrafficTarget{
 				Tag:            "prev",
@@ -105,9 +156,10 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 
 	service := &knservingv1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      componentMeta.Name,
-			Namespace: componentMeta.Namespace,
-			Labels:    componentMeta.Labels,
+			Name:        componentMeta.Name,
+			Namespace:   componentMeta.Namespace,
+			Labels:      componentMeta.Labels,
+			Annotations: componentExtension.ServiceAnnotations,
 		},
 		Spec: knservingv1.ServiceSpec{
 			ConfigurationSpec: knservingv1.ConfigurationSpec{
@@ -134,74 +186,155 @@ func createKnativeService(componentMeta metav1.ObjectMeta,
 	return service
 }
 
+// canaryTrafficPercent is the traffic routed to the latest revision during a canary rollout, none in shadow mode where
+// the traffic is mirrored to the latest revision by the ingress
+func canaryTrafficPercent(componentExt *v1beta1.ComponentExtensionSpec) int64 {
+	if componentExt.Shadow {
+		return 0
+	}
+	return *componentExt.CanaryTrafficPercent
+}
+
+// Reconcile applies the desired Knative service, the existing service is only read to gate the rollout and to report
+// the changes applied
 func (r *KsvcReconciler) Reconcile() (*knservingv1.ServiceStatus, error) {
-	// Create service if does not exist
 	desired := r.Service
 	existing := &knservingv1.Service{}
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
 	if err != nil {
-		if apierr.IsNotFound(err) {
-			log.Info("Creating knative service", "namespace", desired.Namespace, "name", desired.Name)
-			return &desired.Status, r.client.Create(context.TODO(), desired)
+		if !apierr.IsNotFound(err) {
+			return nil, err
+		}
+		log.Info("Creating knative service", "namespace", desired.Namespace, "name", desired.Name)
+		applied := desired.DeepCopy()
+		if err := apply.Object(context.TODO(), r.client, r.scheme, applied); err != nil {
+			return &desired.Status, errors.Wrapf(err, "fails to create knative service")
 		}
-		return nil, err
+		r.RolloutNotes = rolloutNotes(applied, nil)
+		return &applied.Status, nil
 	}
-	// Return if no differences to reconcile.
-	if semanticEquals(desired, existing) {
-		return &existing.Status, nil
+	if err := r.gateRollout(desired, existing); err != nil {
+		return &existing.Status, err
 	}
+	r.canaryTraffic(desired, existing)
 
-	// Reconcile differences and update
-	diff, err := kmp.SafeDiff(desired.Spec.ConfigurationSpec, existing.Spec.ConfigurationSpec)
-	if err != nil {
-		return &existing.Status, errors.Wrapf(err, "failed to diff knative service configuration spec")
+	applied := desired.DeepCopy()
+	if err := apply.Object(context.TODO(), r.client, r.scheme, applied); err != nil {
+		return &existing.Status, errors.Wrapf(err, "fails to update knative service")
 	}
-	log.Info("knative service configuration diff (-desired, +observed):", "diff", diff)
-	existing.Spec.ConfigurationSpec = desired.Spec.ConfigurationSpec
-	existing.ObjectMeta.Labels = desired.ObjectMeta.Labels
+	// The generation of the service only changes when the applied configuration changed its spec
+	if applied.Generation != existing.Generation {
+		log.Info("Updated knative service", "namespace", desired.Namespace, "name", desired.Name)
+		r.RolloutNotes = rolloutNotes(applied, existing)
+		r.Mutations.Record(applied, "Service", existing.Spec, applied.Spec)
+	}
+	return &applied.Status, nil
+}
 
-	if r.componentExt.CanaryTrafficPercent != nil && r.componentStatus.LatestReadyRevision != "" &&
-		r.componentStatus.LatestReadyRevision != existing.Status.LatestReadyRevisionName {
-		log.Info("Updating knative service traffic target", "namespace", desired.Namespace, "name", desired.Name, "canaryPercent",
-			r.componentExt.CanaryTrafficPercent)
-		trafficTargets := []knservingv1.TrafficTarget{}
-		trafficTargets = append(trafficTargets,
-			knservingv1.TrafficTarget{
-				Tag:            "latest",
-				LatestRevision: proto.Bool(true),
-				Percent:        r.componentExt.CanaryTrafficPercent,
-			})
-		remainingTraffic := 100 - *r.componentExt.CanaryTrafficPercent
-		trafficTargets = append(trafficTargets,
-			knservingv1.TrafficTarget{
-				Tag:            "prev",
-				RevisionName:   r.componentStatus.LatestReadyRevision,
-				LatestRevision: proto.Bool(false),
-				Percent:        proto.Int64(remainingTraffic),
-			})
-		existing.Spec.Traffic = trafficTargets
-	} else {
-		diff, err := kmp.SafeDiff(desired.Spec.RouteSpec, existing.Spec.RouteSpec)
-		if err != nil {
-			return &existing.Status, errors.Wrapf(err, "fails to diff knative service route spec")
+// canaryTraffic splits the traffic of a canary rollout between the latest revision and the stable revision once the
+// latest ready revision of the component is no longer the one of the service
+func (r *KsvcReconciler) canaryTraffic(desired *knservingv1.Service, existing *knservingv1.Service) {
+	if r.componentExt.RollbackTo != nil || r.componentExt.CanaryTrafficPercent == nil ||
+		r.componentStatus.LatestReadyRevision == "" ||
+		r.componentStatus.LatestReadyRevision == existing.Status.LatestReadyRevisionName {
+		return
+	}
+	log.Info("Updating knative service traffic target", "namespace", desired.Namespace, "name", desired.Name,
+		"canaryPercent", r.componentExt.CanaryTrafficPercent)
+	canaryTraffic := canaryTrafficPercent(r.componentExt)
+	desired.Spec.Traffic = []knservingv1.TrafficTarget{
+		{
+			Tag:            "latest",
+			LatestRevision: proto.Bool(true),
+			Percent:        proto.Int64(canaryTraffic),
+		},
+		{
+			Tag:            "prev",
+			RevisionName:   r.componentStatus.StableRevision(),
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(100 - canaryTraffic),
+		},
+	}
+}
+
+// gateRollout holds the traffic of the desired service on the serving revision until the latest created revision is
+// ready, the canary rollouts and the rollbacks set their own traffic
+func (r *KsvcReconciler) gateRollout(desired *knservingv1.Service, existing *knservingv1.Service) error {
+	if r.ProgressDeadline == 0 || r.componentExt.RollbackTo != nil || r.componentExt.CanaryTrafficPercent != nil {
+		return nil
+	}
+	serving := heldRevision(existing.Spec.Traffic)
+	if !equality.Semantic.DeepEqual(desired.Spec.ConfigurationSpec, existing.Spec.ConfigurationSpec) {
+		// The revision serving the traffic holds it while the revision of the update is created
+		if serving == "" {
+			serving = existing.Status.LatestReadyRevisionName
 		}
-		if diff != "" {
-			log.Info("knative service routing spec diff (-desired, +observed):", "diff", diff)
+		if serving != "" {
+			desired.Spec.Traffic = holdTraffic(serving)
+			r.ServingRevision = serving
 		}
-		existing.Spec.Traffic = desired.Spec.Traffic
+		return nil
 	}
-	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		log.Info("Updating knative service", "namespace", desired.Namespace, "name", desired.Name)
-		return r.client.Update(context.TODO(), existing)
-	})
-	if err != nil {
-		return &existing.Status, errors.Wrapf(err, "fails to update knative service")
+	latest := existing.Status.LatestCreatedRevisionName
+	if serving == "" || (existing.Status.ObservedGeneration == existing.Generation && latest == serving) {
+		return nil
+	}
+	if existing.Status.ObservedGeneration == existing.Generation && latest != "" &&
+		latest == existing.Status.LatestReadyRevisionName && r.componentStatus.AbortedRevision != latest {
+		// The revision of the update is ready, the traffic moves to it
+		log.Info("Rolled out revision", "namespace", desired.Namespace, "name", desired.Name, "revision", latest)
+		return nil
+	}
+	desired.Spec.Traffic = holdTraffic(serving)
+	r.ServingRevision = serving
+	if existing.Status.ObservedGeneration != existing.Generation || latest == "" {
+		// The revision of the update is not created yet
+		return nil
+	}
+	r.RolloutRevision = latest
+	if r.componentStatus.AbortedRevision == latest {
+		r.RolloutAborted = true
+		return nil
+	}
+	revision := &knservingv1.Revision{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: latest, Namespace: existing.Namespace},
+		revision); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "fails to get revision %s", latest)
+	}
+	if time.Since(revision.CreationTimestamp.Time) > r.ProgressDeadline {
+		log.Info("Aborting rollout past the progress deadline", "namespace", desired.Namespace, "name", desired.Name,
+			"revision", latest, "servingRevision", serving)
+		r.RolloutAborted = true
 	}
-	return &existing.Status, nil
+	return nil
 }
 
-func semanticEquals(desiredService, service *knservingv1.Service) bool {
-	return equality.Semantic.DeepEqual(desiredService.Spec.ConfigurationSpec, service.Spec.ConfigurationSpec) &&
-		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels) &&
-		equality.Semantic.DeepEqual(desiredService.Spec.RouteSpec, service.Spec.RouteSpec)
+// heldRevision returns the revision holding all the traffic of the service, empty if the traffic is not held
+func heldRevision(traffic []knservingv1.TrafficTarget) string {
+	for _, target := range traffic {
+		if target.Tag == "prev" && target.Percent != nil && *target.Percent == 100 {
+			return target.RevisionName
+		}
+	}
+	return ""
+}
+
+// holdTraffic routes all the traffic to the serving revision, the latest ready revision stays reachable on its tag
+func holdTraffic(serving string) []knservingv1.TrafficTarget {
+	return []knservingv1.TrafficTarget{
+		{
+			Tag:            "latest",
+			LatestRevision: proto.Bool(true),
+			Percent:        proto.Int64(0),
+		},
+		{
+			Tag:            "prev",
+			RevisionName:   serving,
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(100),
+		},
+	}
 }
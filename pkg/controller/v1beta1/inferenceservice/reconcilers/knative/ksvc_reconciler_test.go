@@ -0,0 +1,377 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateKnativeServiceAnnotations(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	minReplicas := 2
+	zeroReplicas := 0
+	cpu := v1beta1.MetricCPU
+	rps := v1beta1.MetricRPS
+	scaleTarget := 80
+	targetUtilization := 60
+	scenarios := map[string]struct {
+		componentExt        *v1beta1.ComponentExtensionSpec
+		expectedService     map[string]string
+		expectedRevisionKey string
+		expectedRevisionVal string
+		expectedRevision    map[string]string
+	}{
+		"ServiceAnnotations": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				ServiceAnnotations: map[string]string{"networking.knative.dev/disableAutoTLS": "true"},
+			},
+			expectedService: map[string]string{
+				"networking.knative.dev/disableAutoTLS": "true",
+			},
+			expectedRevisionKey: autoscaling.ClassAnnotationKey,
+			expectedRevisionVal: autoscaling.KPA,
+		},
+		"RevisionAnnotations": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				RevisionAnnotations: map[string]string{autoscaling.TargetAnnotationKey: "10"},
+			},
+			expectedRevisionKey: autoscaling.TargetAnnotationKey,
+			expectedRevisionVal: "10",
+		},
+		"RevisionMinScale": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				RevisionAnnotations: map[string]string{autoscaling.MinScaleAnnotationKey: "3"},
+			},
+			expectedRevisionKey: autoscaling.MinScaleAnnotationKey,
+			expectedRevisionVal: "3",
+		},
+		"MinReplicasTakePrecedence": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas:         &minReplicas,
+				RevisionAnnotations: map[string]string{autoscaling.MinScaleAnnotationKey: "5"},
+			},
+			expectedRevisionKey: autoscaling.MinScaleAnnotationKey,
+			expectedRevisionVal: "2",
+		},
+		"ScaleToZero": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas: &zeroReplicas,
+			},
+			expectedRevisionKey: autoscaling.MinScaleAnnotationKey,
+			expectedRevisionVal: "0",
+		},
+		"CPUScaleMetric": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				ScaleMetric:         &cpu,
+				ScaleTarget:         &scaleTarget,
+				RevisionAnnotations: map[string]string{autoscaling.ClassAnnotationKey: autoscaling.KPA},
+			},
+			expectedRevisionKey: autoscaling.ClassAnnotationKey,
+			expectedRevisionVal: autoscaling.HPA,
+			expectedRevision: map[string]string{
+				autoscaling.MetricAnnotationKey: "cpu",
+				autoscaling.TargetAnnotationKey: "80",
+			},
+		},
+		"TargetUtilization": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				ScaleTarget:                 &scaleTarget,
+				TargetUtilizationPercentage: &targetUtilization,
+			},
+			expectedRevisionKey: autoscaling.TargetUtilizationPercentageKey,
+			expectedRevisionVal: "60",
+			expectedRevision: map[string]string{
+				autoscaling.TargetAnnotationKey: "80",
+			},
+		},
+		"RPSScaleMetric": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				ScaleMetric: &rps,
+			},
+			expectedRevisionKey: autoscaling.ClassAnnotationKey,
+			expectedRevisionVal: autoscaling.KPA,
+			expectedRevision: map[string]string{
+				autoscaling.MetricAnnotationKey: "rps",
+			},
+		},
+		"Keda": {
+			componentExt: &v1beta1.ComponentExtensionSpec{
+				MinReplicas: &zeroReplicas,
+				Keda: &v1beta1.KedaSpec{
+					Triggers: []v1beta1.KedaTrigger{{Type: "kafka"}},
+				},
+			},
+			expectedRevisionKey: autoscaling.ClassAnnotationKey,
+			expectedRevisionVal: constants.KedaAutoscalerClass,
+			expectedRevision: map[string]string{
+				autoscaling.MinScaleAnnotationKey: "0",
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			componentMeta := metav1.ObjectMeta{
+				Name:        "sklearn-predictor-default",
+				Namespace:   "default",
+				Annotations: map[string]string{"custom": "value"},
+			}
+			service := createKnativeService(componentMeta, scenario.componentExt,
+				&corev1.PodSpec{Containers: []corev1.Container{{Image: "sklearn"}}}, v1beta1.ComponentStatusSpec{})
+			for key, value := range scenario.expectedService {
+				g.Expect(service.Annotations).To(gomega.HaveKeyWithValue(key, value))
+			}
+			g.Expect(service.Annotations).NotTo(gomega.HaveKey("custom"))
+			revisionAnnotations := service.Spec.Template.Annotations
+			g.Expect(revisionAnnotations).To(gomega.HaveKeyWithValue("custom", "value"))
+			g.Expect(revisionAnnotations).To(gomega.HaveKeyWithValue(scenario.expectedRevisionKey, scenario.expectedRevisionVal))
+			for key, value := range scenario.expectedRevision {
+				g.Expect(revisionAnnotations).To(gomega.HaveKeyWithValue(key, value))
+			}
+		})
+	}
+}
+
+func TestCreateKnativeServiceRollbackTraffic(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	componentMeta := metav1.ObjectMeta{
+		Name:      "sklearn-predictor-default",
+		Namespace: "default",
+	}
+	componentExt := &v1beta1.ComponentExtensionSpec{
+		RollbackTo: proto.String("sklearn-predictor-default-abcde"),
+	}
+	service := createKnativeService(componentMeta, componentExt,
+		&corev1.PodSpec{Containers: []corev1.Container{{Image: "sklearn"}}}, v1beta1.ComponentStatusSpec{
+			LatestReadyRevision:   "sklearn-predictor-default-fghij",
+			PreviousReadyRevision: "sklearn-predictor-default-abcde",
+		})
+	g.Expect(service.Spec.Traffic).To(gomega.Equal([]knservingv1.TrafficTarget{
+		{
+			Tag:            "latest",
+			LatestRevision: proto.Bool(true),
+			Percent:        proto.Int64(0),
+		},
+		{
+			Tag:            constants.RollbackTrafficTag,
+			RevisionName:   "sklearn-predictor-default-abcde",
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(100),
+		},
+	}))
+}
+
+func TestCreateKnativeServiceShadowTraffic(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	componentMeta := metav1.ObjectMeta{
+		Name:      "sklearn-predictor-default",
+		Namespace: "default",
+	}
+	componentExt := &v1beta1.ComponentExtensionSpec{
+		CanaryTrafficPercent: proto.Int64(20),
+		Shadow:               true,
+	}
+	service := createKnativeService(componentMeta, componentExt,
+		&corev1.PodSpec{Containers: []corev1.Container{{Image: "sklearn"}}}, v1beta1.ComponentStatusSpec{
+			LatestReadyRevision:   "sklearn-predictor-default-abcde",
+			PreviousReadyRevision: "sklearn-predictor-default-abcde",
+		})
+	// the canary traffic is mirrored by the ingress, the previous revision serves all the traffic
+	g.Expect(service.Spec.Traffic).To(gomega.Equal([]knservingv1.TrafficTarget{
+		{
+			Tag:            "latest",
+			LatestRevision: proto.Bool(true),
+			Percent:        proto.Int64(0),
+		},
+		{
+			Tag:            "prev",
+			RevisionName:   "sklearn-predictor-default-abcde",
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(100),
+		},
+	}))
+}
+
+func TestCreateKnativeServiceRolledBackCanary(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	componentMeta := metav1.ObjectMeta{
+		Name:      "sklearn-predictor-default",
+		Namespace: "default",
+	}
+	service := createKnativeService(componentMeta,
+		&v1beta1.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(20)},
+		&corev1.PodSpec{Containers: []corev1.Container{{Image: "sklearn"}}},
+		v1beta1.ComponentStatusSpec{
+			LatestReadyRevision:   "sklearn-predictor-default-fghij",
+			PreviousReadyRevision: "sklearn-predictor-default-abcde",
+			RolledBackRevision:    "sklearn-predictor-default-fghij",
+		})
+	g.Expect(service.Spec.Traffic).To(gomega.Equal([]knservingv1.TrafficTarget{
+		{
+			Tag:            "latest",
+			LatestRevision: proto.Bool(true),
+			Percent:        proto.Int64(0),
+		},
+		{
+			Tag:            "prev",
+			RevisionName:   "sklearn-predictor-default-abcde",
+			LatestRevision: proto.Bool(false),
+			Percent:        proto.Int64(100),
+		},
+	}))
+}
+
+func TestGateRollout(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := runtime.NewScheme()
+	g.Expect(knservingv1.AddToScheme(s)).To(gomega.Succeed())
+	componentMeta := metav1.ObjectMeta{Name: "sklearn-predictor-default", Namespace: "default"}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Image: "sklearn:v2"}}}
+	latest := []knservingv1.TrafficTarget{
+		{Tag: "latest", LatestRevision: proto.Bool(true), Percent: proto.Int64(100)},
+	}
+	held := []knservingv1.TrafficTarget{
+		{Tag: "latest", LatestRevision: proto.Bool(true), Percent: proto.Int64(0)},
+		{Tag: "prev", RevisionName: "sklearn-predictor-default-00001", LatestRevision: proto.Bool(false),
+			Percent: proto.Int64(100)},
+	}
+	revision := func(name string, created time.Time) *knservingv1.Revision {
+		return &knservingv1.Revision{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+			CreationTimestamp: metav1.NewTime(created)}}
+	}
+	scenarios := map[string]struct {
+		componentExt    *v1beta1.ComponentExtensionSpec
+		componentStatus v1beta1.ComponentStatusSpec
+		image           string
+		traffic         []knservingv1.TrafficTarget
+		generation      int64
+		latestCreated   string
+		latestReady     string
+		expectedTraffic []knservingv1.TrafficTarget
+		serving         string
+		rollout         string
+		aborted         bool
+	}{
+		"ConfigurationUpdated": {
+			image:           "sklearn:v1",
+			traffic:         latest,
+			latestCreated:   "sklearn-predictor-default-00001",
+			latestReady:     "sklearn-predictor-default-00001",
+			expectedTraffic: held,
+			serving:         "sklearn-predictor-default-00001",
+		},
+		"FirstRevision": {
+			image:           "sklearn:v1",
+			traffic:         latest,
+			latestCreated:   "sklearn-predictor-default-00001",
+			expectedTraffic: latest,
+		},
+		"RevisionNotCreated": {
+			traffic:         held,
+			generation:      2,
+			latestCreated:   "sklearn-predictor-default-00001",
+			latestReady:     "sklearn-predictor-default-00001",
+			expectedTraffic: held,
+			serving:         "sklearn-predictor-default-00001",
+		},
+		"RevisionNotReady": {
+			traffic:         held,
+			latestCreated:   "sklearn-predictor-default-00002",
+			latestReady:     "sklearn-predictor-default-00001",
+			expectedTraffic: held,
+			serving:         "sklearn-predictor-default-00001",
+			rollout:         "sklearn-predictor-default-00002",
+		},
+		"ProgressDeadlineExceeded": {
+			traffic:         held,
+			latestCreated:   "sklearn-predictor-default-00003",
+			latestReady:     "sklearn-predictor-default-00001",
+			expectedTraffic: held,
+			serving:         "sklearn-predictor-default-00001",
+			rollout:         "sklearn-predictor-default-00003",
+			aborted:         true,
+		},
+		"RevisionReady": {
+			traffic:         held,
+			latestCreated:   "sklearn-predictor-default-00002",
+			latestReady:     "sklearn-predictor-default-00002",
+			expectedTraffic: latest,
+		},
+		"AbortedRevisionReady": {
+			componentStatus: v1beta1.ComponentStatusSpec{AbortedRevision: "sklearn-predictor-default-00003"},
+			traffic:         held,
+			latestCreated:   "sklearn-predictor-default-00003",
+			latestReady:     "sklearn-predictor-default-00003",
+			expectedTraffic: held,
+			serving:         "sklearn-predictor-default-00001",
+			rollout:         "sklearn-predictor-default-00003",
+			aborted:         true,
+		},
+		"CanaryRollout": {
+			componentExt:    &v1beta1.ComponentExtensionSpec{CanaryTrafficPercent: proto.Int64(10)},
+			image:           "sklearn:v1",
+			traffic:         latest,
+			latestCreated:   "sklearn-predictor-default-00001",
+			latestReady:     "sklearn-predictor-default-00001",
+			expectedTraffic: latest,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			componentExt := scenario.componentExt
+			if componentExt == nil {
+				componentExt = &v1beta1.ComponentExtensionSpec{}
+			}
+			cl := fake.NewFakeClientWithScheme(s,
+				revision("sklearn-predictor-default-00002", time.Now().Add(-time.Minute)),
+				revision("sklearn-predictor-default-00003", time.Now().Add(-20*time.Minute)),
+			)
+			r := NewKsvcReconciler(cl, s, componentMeta, componentExt, podSpec, scenario.componentStatus)
+			r.ProgressDeadline = 10 * time.Minute
+			existing := r.Service.DeepCopy()
+			if scenario.image != "" {
+				existing.Spec.Template.Spec.Containers[0].Image = scenario.image
+			}
+			existing.Spec.Traffic = scenario.traffic
+			existing.Generation = 1
+			if scenario.generation != 0 {
+				existing.Generation = scenario.generation
+			}
+			existing.Status.ObservedGeneration = 1
+			existing.Status.LatestCreatedRevisionName = scenario.latestCreated
+			existing.Status.LatestReadyRevisionName = scenario.latestReady
+
+			g.Expect(r.gateRollout(r.Service, existing)).To(gomega.Succeed())
+			g.Expect(r.Service.Spec.Traffic).To(gomega.Equal(scenario.expectedTraffic))
+			g.Expect(r.ServingRevision).To(gomega.Equal(scenario.serving))
+			g.Expect(r.RolloutRevision).To(gomega.Equal(scenario.rollout))
+			g.Expect(r.RolloutAborted).To(gomega.Equal(scenario.aborted))
+		})
+	}
+}
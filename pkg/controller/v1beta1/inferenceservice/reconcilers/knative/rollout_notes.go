@@ -0,0 +1,112 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+const (
+	rolloutNotesSeparator = "; "
+	// the hashes of the storage uris are shortened to keep the notes readable
+	storageUriHashLength = 12
+)
+
+// rolloutNotes summarizes what changes when the desired knative service is rolled out, e.g.
+// "image: sklearnserver:v0.4.0 -> sklearnserver:v0.5.0; model: gs://models/v1 (uri-sha256:0a1b2c3d4e5f) -> ...; traffic: latest 10%, prev(sklearn-predictor-default-abcde) 90%".
+// The existing service is nil when the service is created.
+func rolloutNotes(desired *knservingv1.Service, existing *knservingv1.Service) string {
+	var notes []string
+	newImage := serviceImage(desired)
+	if existing == nil {
+		notes = append(notes, fmt.Sprintf("image: %s", newImage))
+	} else if oldImage := serviceImage(existing); oldImage != newImage {
+		notes = append(notes, fmt.Sprintf("image: %s -> %s", oldImage, newImage))
+	} else {
+		notes = append(notes, fmt.Sprintf("image: %s (unchanged)", newImage))
+	}
+
+	newModel := serviceModel(desired)
+	if existing == nil {
+		if newModel != "" {
+			notes = append(notes, fmt.Sprintf("model: %s", newModel))
+		}
+	} else if oldModel := serviceModel(existing); oldModel != newModel {
+		notes = append(notes, fmt.Sprintf("model: %s -> %s", noneIfEmpty(oldModel), noneIfEmpty(newModel)))
+	} else if newModel != "" {
+		notes = append(notes, fmt.Sprintf("model: %s (unchanged)", newModel))
+	}
+
+	notes = append(notes, fmt.Sprintf("traffic: %s", trafficPlan(desired.Spec.Traffic)))
+	return strings.Join(notes, rolloutNotesSeparator)
+}
+
+// serviceImage returns the image of the model server container
+func serviceImage(service *knservingv1.Service) string {
+	containers := service.Spec.Template.Spec.Containers
+	for _, container := range containers {
+		if container.Name == constants.InferenceServiceContainerName {
+			return container.Image
+		}
+	}
+	if len(containers) > 0 {
+		return containers[0].Image
+	}
+	return ""
+}
+
+// serviceModel returns the model storage uri passed to the storage initializer along with the hash of the uri. The
+// hash is not a digest of the model files, which are only read by the storage initializer.
+func serviceModel(service *knservingv1.Service) string {
+	storageUri, ok := service.Spec.Template.Annotations[constants.StorageInitializerSourceUriInternalAnnotationKey]
+	if !ok || storageUri == "" {
+		return ""
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(storageUri)))
+	return fmt.Sprintf("%s (uri-sha256:%s)", storageUri, hash[:storageUriHashLength])
+}
+
+func trafficPlan(traffic []knservingv1.TrafficTarget) string {
+	var targets []string
+	for _, target := range traffic {
+		name := target.Tag
+		if target.RevisionName != "" {
+			name = fmt.Sprintf("%s(%s)", target.Tag, target.RevisionName)
+		}
+		percent := int64(0)
+		if target.Percent != nil {
+			percent = *target.Percent
+		}
+		targets = append(targets, fmt.Sprintf("%s %d%%", name, percent))
+	}
+	if len(targets) == 0 {
+		return "none"
+	}
+	return strings.Join(targets, ", ")
+}
+
+func noneIfEmpty(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
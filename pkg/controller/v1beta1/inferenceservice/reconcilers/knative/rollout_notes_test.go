@@ -0,0 +1,87 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knative
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestRolloutNotes(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	service := func(image string, storageUri string, traffic ...knservingv1.TrafficTarget) *knservingv1.Service {
+		return &knservingv1.Service{
+			Spec: knservingv1.ServiceSpec{
+				ConfigurationSpec: knservingv1.ConfigurationSpec{
+					Template: knservingv1.RevisionTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								constants.StorageInitializerSourceUriInternalAnnotationKey: storageUri,
+							},
+						},
+						Spec: knservingv1.RevisionSpec{
+							PodSpec: corev1.PodSpec{
+								Containers: []corev1.Container{
+									{Name: constants.InferenceServiceContainerName, Image: image},
+								},
+							},
+						},
+					},
+				},
+				RouteSpec: knservingv1.RouteSpec{Traffic: traffic},
+			},
+		}
+	}
+	latest := knservingv1.TrafficTarget{Tag: "latest", LatestRevision: proto.Bool(true), Percent: proto.Int64(100)}
+	canary := knservingv1.TrafficTarget{Tag: "latest", LatestRevision: proto.Bool(true), Percent: proto.Int64(10)}
+	prev := knservingv1.TrafficTarget{Tag: "prev", RevisionName: "sklearn-predictor-default-abcde",
+		LatestRevision: proto.Bool(false), Percent: proto.Int64(90)}
+
+	scenarios := map[string]struct {
+		desired  *knservingv1.Service
+		existing *knservingv1.Service
+		expected string
+	}{
+		"Create": {
+			desired:  service("sklearnserver:v0.4.0", "gs://models/v1", latest),
+			expected: "image: sklearnserver:v0.4.0; model: gs://models/v1 (uri-sha256:ce6273e0aa1f); traffic: latest 100%",
+		},
+		"ModelUpdateWithCanary": {
+			desired:  service("sklearnserver:v0.4.0", "gs://models/v2", canary, prev),
+			existing: service("sklearnserver:v0.4.0", "gs://models/v1", latest),
+			expected: "image: sklearnserver:v0.4.0 (unchanged); model: gs://models/v1 (uri-sha256:ce6273e0aa1f) -> gs://models/v2 (uri-sha256:47483b24bd02); " +
+				"traffic: latest 10%, prev(sklearn-predictor-default-abcde) 90%",
+		},
+		"ImageUpdate": {
+			desired:  service("sklearnserver:v0.5.0", "gs://models/v1", latest),
+			existing: service("sklearnserver:v0.4.0", "gs://models/v1", latest),
+			expected: "image: sklearnserver:v0.4.0 -> sklearnserver:v0.5.0; model: gs://models/v1 (uri-sha256:ce6273e0aa1f) (unchanged); traffic: latest 100%",
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(rolloutNotes(scenario.desired, scenario.existing)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
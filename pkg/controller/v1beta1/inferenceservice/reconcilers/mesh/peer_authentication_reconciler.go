@@ -0,0 +1,141 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("PeerAuthenticationReconciler")
+
+// PeerAuthenticationReconciler reconciles the Istio PeerAuthentication setting the mutual TLS mode of the traffic to
+// the pods of the components of an inference service. The PeerAuthentication is unstructured so the Istio security
+// CRDs are only required in the clusters setting a mode.
+type PeerAuthenticationReconciler struct {
+	client     client.Client
+	scheme     *runtime.Scheme
+	meshConfig *v1beta1.MeshConfig
+}
+
+func NewPeerAuthenticationReconciler(client client.Client, scheme *runtime.Scheme,
+	meshConfig *v1beta1.MeshConfig) *PeerAuthenticationReconciler {
+	return &PeerAuthenticationReconciler{
+		client:     client,
+		scheme:     scheme,
+		meshConfig: meshConfig,
+	}
+}
+
+// createPeerAuthentication returns the PeerAuthentication of the pods of the inference service, the metrics ports of
+// the queue-proxy are left permissive under strict mutual TLS so the Knative autoscaler and Prometheus can scrape them
+func createPeerAuthentication(isvc *v1beta1.InferenceService, mode string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{constants.InferenceServicePodLabelKey: isvc.Name},
+		},
+		"mtls": map[string]interface{}{"mode": mode},
+	}
+	if mode == v1beta1.MTLSModeStrict {
+		ports := map[string]interface{}{}
+		for _, port := range constants.KnativeQueueProxyMetricsPorts {
+			ports[strconv.FormatInt(port, 10)] = map[string]interface{}{"mode": v1beta1.MTLSModePermissive}
+		}
+		spec["portLevelMtls"] = ports
+	}
+	peerAuthentication := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	peerAuthentication.SetAPIVersion(constants.IstioSecurityAPIVersion)
+	peerAuthentication.SetKind(constants.IstioPeerAuthentication)
+	peerAuthentication.SetName(constants.PeerAuthenticationName(isvc.Name))
+	peerAuthentication.SetNamespace(isvc.Namespace)
+	peerAuthentication.SetLabels(map[string]string{
+		constants.InferenceServicePodLabelKey: isvc.Name,
+	})
+	return peerAuthentication
+}
+
+// Reconcile creates or updates the PeerAuthentication of the inference service when the mesh config sets a mutual TLS
+// mode, and deletes it otherwise. The clusters without the Istio security CRDs have nothing to delete. The
+// PeerAuthentications which are not controlled by the inference service are left to the user.
+func (r *PeerAuthenticationReconciler) Reconcile(isvc *v1beta1.InferenceService) error {
+	mode := r.meshConfig.MTLSMode
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion(constants.IstioSecurityAPIVersion)
+	existing.SetKind(constants.IstioPeerAuthentication)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: constants.PeerAuthenticationName(isvc.Name),
+		Namespace: isvc.Namespace}, existing)
+	if meta.IsNoMatchError(err) {
+		if mode == "" {
+			return nil
+		}
+		return fmt.Errorf("the %s CRD of Istio is not installed", constants.IstioPeerAuthentication)
+	}
+	if err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(existing, isvc) {
+		if mode == "" {
+			return nil
+		}
+		return fmt.Errorf("the PeerAuthentication %s is not controlled by the inference service", existing.GetName())
+	}
+
+	if mode == "" {
+		if exists {
+			log.Info("Deleting PeerAuthentication", "namespace", existing.GetNamespace(), "name", existing.GetName())
+			if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+				return errors.Wrapf(err, "fails to delete PeerAuthentication")
+			}
+		}
+		return nil
+	}
+	desired := createPeerAuthentication(isvc, mode)
+	if err := controllerutil.SetControllerReference(isvc, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for PeerAuthentication")
+	}
+	if !exists {
+		log.Info("Creating PeerAuthentication", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Object["spec"], existing.Object["spec"]) {
+		existing.Object["spec"] = desired.Object["spec"]
+		if err := controllerutil.SetControllerReference(isvc, existing, r.scheme); err != nil {
+			return errors.Wrapf(err, "fails to set owner reference for PeerAuthentication")
+		}
+		log.Info("Updating PeerAuthentication", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update PeerAuthentication")
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mesh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestPeerAuthenticationReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default", UID: "my-model-uid"},
+	}
+	scenarios := map[string]struct {
+		existing      string
+		mode          string
+		expectedPorts map[string]interface{}
+	}{
+		"Strict": {
+			mode: v1beta1.MTLSModeStrict,
+			expectedPorts: map[string]interface{}{
+				"9090": map[string]interface{}{"mode": v1beta1.MTLSModePermissive},
+				"9091": map[string]interface{}{"mode": v1beta1.MTLSModePermissive},
+			},
+		},
+		"Permissive": {
+			mode: v1beta1.MTLSModePermissive,
+		},
+		"UpdateToPermissive": {
+			existing: v1beta1.MTLSModeStrict,
+			mode:     v1beta1.MTLSModePermissive,
+		},
+		"Unset": {},
+		"DeleteWhenUnset": {
+			existing: v1beta1.MTLSModeStrict,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			objects := []runtime.Object{isvc.DeepCopy()}
+			if scenario.existing != "" {
+				existing := createPeerAuthentication(isvc, scenario.existing)
+				g.Expect(controllerutil.SetControllerReference(isvc, existing, scheme)).To(gomega.Succeed())
+				objects = append(objects, existing)
+			}
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := NewPeerAuthenticationReconciler(cl, scheme, &v1beta1.MeshConfig{MTLSMode: scenario.mode})
+			g.Expect(r.Reconcile(isvc)).To(gomega.Succeed())
+
+			peerAuthentication := &unstructured.Unstructured{}
+			peerAuthentication.SetAPIVersion(constants.IstioSecurityAPIVersion)
+			peerAuthentication.SetKind(constants.IstioPeerAuthentication)
+			err := cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-mtls", Namespace: "default"},
+				peerAuthentication)
+			if scenario.mode == "" {
+				g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			mode, _, _ := unstructured.NestedString(peerAuthentication.Object, "spec", "mtls", "mode")
+			g.Expect(mode).To(gomega.Equal(scenario.mode))
+			selector, _, _ := unstructured.NestedStringMap(peerAuthentication.Object, "spec", "selector", "matchLabels")
+			g.Expect(selector).To(gomega.Equal(map[string]string{constants.InferenceServicePodLabelKey: "my-model"}))
+			ports, _, _ := unstructured.NestedMap(peerAuthentication.Object, "spec", "portLevelMtls")
+			if scenario.expectedPorts == nil {
+				g.Expect(ports).To(gomega.BeEmpty())
+			} else {
+				g.Expect(ports).To(gomega.Equal(scenario.expectedPorts))
+			}
+			g.Expect(peerAuthentication.GetOwnerReferences()).To(gomega.HaveLen(1))
+		})
+	}
+}
+
+func TestPeerAuthenticationReconcileKeepsForeignPeerAuthentication(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "default", UID: "my-model-uid"},
+	}
+	// A PeerAuthentication of the user with the name of the inference service
+	foreign := createPeerAuthentication(isvc, v1beta1.MTLSModeStrict)
+	foreign.SetName(constants.PeerAuthenticationName(isvc.Name))
+	foreign.SetOwnerReferences([]metav1.OwnerReference{})
+	cl := fake.NewFakeClientWithScheme(scheme, isvc.DeepCopy(), foreign)
+	get := func() *unstructured.Unstructured {
+		peerAuthentication := &unstructured.Unstructured{}
+		peerAuthentication.SetAPIVersion(constants.IstioSecurityAPIVersion)
+		peerAuthentication.SetKind(constants.IstioPeerAuthentication)
+		g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "my-model-mtls", Namespace: "default"},
+			peerAuthentication)).To(gomega.Succeed())
+		return peerAuthentication
+	}
+
+	// It is not deleted without a mode
+	g.Expect(NewPeerAuthenticationReconciler(cl, scheme, &v1beta1.MeshConfig{}).Reconcile(isvc)).To(gomega.Succeed())
+	g.Expect(get().GetOwnerReferences()).To(gomega.BeEmpty())
+
+	// Nor updated with a mode
+	err := NewPeerAuthenticationReconciler(cl, scheme,
+		&v1beta1.MeshConfig{MTLSMode: v1beta1.MTLSModePermissive}).Reconcile(isvc)
+	g.Expect(err).To(gomega.HaveOccurred())
+	peerAuthentication := get()
+	mode, _, _ := unstructured.NestedString(peerAuthentication.Object, "spec", "mtls", "mode")
+	g.Expect(mode).To(gomega.Equal(v1beta1.MTLSModeStrict))
+	g.Expect(peerAuthentication.GetOwnerReferences()).To(gomega.BeEmpty())
+}
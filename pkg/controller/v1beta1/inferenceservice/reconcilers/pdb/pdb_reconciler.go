@@ -0,0 +1,125 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"context"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/pkg/errors"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("PodDisruptionBudgetReconciler")
+
+// PodDisruptionBudgetReconciler reconciles the PodDisruptionBudget keeping a minimum of replicas of a component
+// available during the voluntary disruptions. The budget selects the pods of all the revisions of the component.
+type PodDisruptionBudgetReconciler struct {
+	client        client.Client
+	scheme        *runtime.Scheme
+	owner         metav1.Object
+	componentMeta metav1.ObjectMeta
+	componentExt  *v1beta1.ComponentExtensionSpec
+}
+
+func NewPodDisruptionBudgetReconciler(client client.Client, scheme *runtime.Scheme, owner metav1.Object,
+	componentMeta metav1.ObjectMeta, componentExt *v1beta1.ComponentExtensionSpec) *PodDisruptionBudgetReconciler {
+	return &PodDisruptionBudgetReconciler{
+		client:        client,
+		scheme:        scheme,
+		owner:         owner,
+		componentMeta: componentMeta,
+		componentExt:  componentExt,
+	}
+}
+
+// createPodDisruptionBudget returns the PodDisruptionBudget of the component, nil when the component has no budget.
+// Without minAvailable the budget allows one unavailable replica, which does not block the drains at a single replica.
+func createPodDisruptionBudget(componentMeta metav1.ObjectMeta,
+	componentExt *v1beta1.ComponentExtensionSpec) *policyv1beta1.PodDisruptionBudget {
+	if componentExt.DisruptionBudget == nil {
+		return nil
+	}
+	spec := policyv1beta1.PodDisruptionBudgetSpec{
+		MinAvailable: componentExt.DisruptionBudget.MinAvailable,
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				constants.InferenceServicePodLabelKey: componentMeta.Labels[constants.InferenceServicePodLabelKey],
+				constants.KServiceComponentLabel:      componentMeta.Labels[constants.KServiceComponentLabel],
+			},
+		},
+	}
+	if spec.MinAvailable == nil {
+		maxUnavailable := intstr.FromInt(1)
+		spec.MaxUnavailable = &maxUnavailable
+	}
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      componentMeta.Name,
+			Namespace: componentMeta.Namespace,
+			Labels:    componentMeta.Labels,
+		},
+		Spec: spec,
+	}
+}
+
+// Reconcile creates or updates the PodDisruptionBudget of the component, and deletes it once the component has no
+// budget
+func (r *PodDisruptionBudgetReconciler) Reconcile() error {
+	desired := createPodDisruptionBudget(r.componentMeta, r.componentExt)
+	existing := &policyv1beta1.PodDisruptionBudget{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.componentMeta.Name,
+		Namespace: r.componentMeta.Namespace}, existing)
+	if err != nil && !apierr.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if desired == nil {
+		// The PodDisruptionBudgets which are not owned by the InferenceService are left to the user
+		if exists && metav1.IsControlledBy(existing, r.owner) {
+			log.Info("Deleting PodDisruptionBudget", "namespace", existing.Namespace, "name", existing.Name)
+			if err := r.client.Delete(context.TODO(), existing); err != nil && !apierr.IsNotFound(err) {
+				return errors.Wrapf(err, "fails to delete PodDisruptionBudget")
+			}
+		}
+		return nil
+	}
+	if err := controllerutil.SetControllerReference(r.owner, desired, r.scheme); err != nil {
+		return errors.Wrapf(err, "fails to set owner reference for PodDisruptionBudget")
+	}
+	if !exists {
+		log.Info("Creating PodDisruptionBudget", "namespace", desired.Namespace, "name", desired.Name)
+		err = r.client.Create(context.TODO(), desired)
+	} else if !equality.Semantic.DeepEqual(desired.Spec, existing.Spec) ||
+		!equality.Semantic.DeepEqual(desired.Labels, existing.Labels) {
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		log.Info("Updating PodDisruptionBudget", "namespace", desired.Namespace, "name", desired.Name)
+		err = r.client.Update(context.TODO(), existing)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "fails to create or update PodDisruptionBudget")
+	}
+	return nil
+}
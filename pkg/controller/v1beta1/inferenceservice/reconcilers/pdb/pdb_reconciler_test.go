@@ -0,0 +1,96 @@
+/*
+Copyright 2020 kubeflow.org.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodDisruptionBudgetReconciler(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(v1beta1.AddToScheme(scheme.Scheme)).To(gomega.Succeed())
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default", UID: "1234"},
+	}
+	componentMeta := metav1.ObjectMeta{
+		Name:      constants.DefaultPredictorServiceName("sklearn"),
+		Namespace: "default",
+		Labels: map[string]string{
+			constants.InferenceServicePodLabelKey: "sklearn",
+			constants.KServiceComponentLabel:      "predictor",
+		},
+	}
+	key := types.NamespacedName{Name: componentMeta.Name, Namespace: componentMeta.Namespace}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	// The component without budget has no PodDisruptionBudget
+	componentExt := &v1beta1.ComponentExtensionSpec{}
+	g.Expect(NewPodDisruptionBudgetReconciler(cl, scheme.Scheme, isvc, componentMeta, componentExt).Reconcile()).
+		To(gomega.Succeed())
+	pdb := &policyv1beta1.PodDisruptionBudget{}
+	g.Expect(apierr.IsNotFound(cl.Get(context.TODO(), key, pdb))).To(gomega.BeTrue())
+
+	// The budget evicts one replica at a time by default
+	componentExt.DisruptionBudget = &v1beta1.DisruptionBudgetSpec{}
+	g.Expect(NewPodDisruptionBudgetReconciler(cl, scheme.Scheme, isvc, componentMeta, componentExt).Reconcile()).
+		To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), key, pdb)).To(gomega.Succeed())
+	g.Expect(pdb.Spec.MinAvailable).To(gomega.BeNil())
+	g.Expect(pdb.Spec.MaxUnavailable).To(gomega.Equal(&intstr.IntOrString{Type: intstr.Int, IntVal: 1}))
+	g.Expect(pdb.Spec.Selector.MatchLabels).To(gomega.Equal(componentMeta.Labels))
+	g.Expect(metav1.IsControlledBy(pdb, isvc)).To(gomega.BeTrue())
+
+	minAvailable := intstr.FromString("50%")
+	componentExt.DisruptionBudget.MinAvailable = &minAvailable
+	g.Expect(NewPodDisruptionBudgetReconciler(cl, scheme.Scheme, isvc, componentMeta, componentExt).Reconcile()).
+		To(gomega.Succeed())
+	pdb = &policyv1beta1.PodDisruptionBudget{}
+	g.Expect(cl.Get(context.TODO(), key, pdb)).To(gomega.Succeed())
+	g.Expect(pdb.Spec.MinAvailable).To(gomega.Equal(&minAvailable))
+	g.Expect(pdb.Spec.MaxUnavailable).To(gomega.BeNil())
+
+	// The budget is deleted once removed from the component
+	componentExt.DisruptionBudget = nil
+	g.Expect(NewPodDisruptionBudgetReconciler(cl, scheme.Scheme, isvc, componentMeta, componentExt).Reconcile()).
+		To(gomega.Succeed())
+	g.Expect(apierr.IsNotFound(cl.Get(context.TODO(), key, pdb))).To(gomega.BeTrue())
+}
+
+func TestPodDisruptionBudgetReconcilerKeepsUserBudget(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default", UID: "1234"},
+	}
+	componentMeta := metav1.ObjectMeta{Name: constants.DefaultPredictorServiceName("sklearn"), Namespace: "default"}
+	userBudget := &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: componentMeta.Name, Namespace: componentMeta.Namespace},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme, userBudget)
+	g.Expect(NewPodDisruptionBudgetReconciler(cl, scheme.Scheme, isvc, componentMeta,
+		&v1beta1.ComponentExtensionSpec{}).Reconcile()).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: componentMeta.Name, Namespace: componentMeta.Namespace},
+		&policyv1beta1.PodDisruptionBudget{})).To(gomega.Succeed())
+}
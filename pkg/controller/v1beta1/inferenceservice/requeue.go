@@ -0,0 +1,61 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+// requeueAfter returns the interval the inference service is reconciled again after to poll the states it depends on
+// which are not watched, the shortest interval of the polls which are due so a single reconcile serves all of them. It
+// returns 0 when no poll is due.
+func requeueAfter(isvc *v1beta1api.InferenceService, analyzingCanaries bool, resolvingHedgingDelays bool) time.Duration {
+	rollingOut := false
+	for _, status := range isvc.Status.Components {
+		rollingOut = rollingOut || status.RolloutRevision != ""
+	}
+	certificate := isvc.Status.GetCondition(v1beta1api.CertificateReady)
+	polls := []struct {
+		due      bool
+		interval time.Duration
+	}{
+		// The readiness of the certificates
+		{certificate != nil && !certificate.IsTrue(), constants.CertificateRequeueInterval},
+		// The metrics of the canaries
+		{analyzingCanaries, constants.CanaryAnalysisRequeueInterval},
+		// The statuses of the copies in the member clusters
+		{isvc.Status.Federation != nil, constants.FederationRequeueInterval},
+		// The decisions of the policy engines
+		{isvc.Status.GetCondition(v1beta1api.PolicyViolation) != nil, constants.PolicyRequeueInterval},
+		// The latency percentiles of the hedged components
+		{resolvingHedgingDelays, constants.HedgingRequeueInterval},
+		// The progress deadline of a rollout
+		{rollingOut, constants.RolloutRequeueInterval},
+		// The replicas of the components the cost is estimated from
+		{isvc.Status.Cost != nil, constants.CostRequeueInterval},
+	}
+	next := time.Duration(0)
+	for _, poll := range polls {
+		if poll.due && (next == 0 || poll.interval < next) {
+			next = poll.interval
+		}
+	}
+	return next
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"testing"
+	"time"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestRequeueAfter(t *testing.T) {
+	costly := func() *v1beta1api.InferenceService {
+		isvc := &v1beta1api.InferenceService{}
+		isvc.Status.Cost = &v1beta1api.CostStatus{HourlyCost: "1.00"}
+		return isvc
+	}
+	scenarios := map[string]struct {
+		isvc                   *v1beta1api.InferenceService
+		analyzingCanaries      bool
+		resolvingHedgingDelays bool
+		expected               time.Duration
+	}{
+		"NothingDue": {
+			isvc:     &v1beta1api.InferenceService{},
+			expected: 0,
+		},
+		"Cost": {
+			isvc:     costly(),
+			expected: constants.CostRequeueInterval,
+		},
+		"RolloutAndCost": {
+			isvc: func() *v1beta1api.InferenceService {
+				isvc := costly()
+				isvc.Status.Components = map[v1beta1api.ComponentType]v1beta1api.ComponentStatusSpec{
+					v1beta1api.PredictorComponent: {RolloutRevision: "revision-2"},
+				}
+				return isvc
+			}(),
+			expected: constants.RolloutRequeueInterval,
+		},
+		"HedgingAndCanaries": {
+			isvc:                   costly(),
+			analyzingCanaries:      true,
+			resolvingHedgingDelays: true,
+			expected:               constants.CanaryAnalysisRequeueInterval,
+		},
+		"PendingCertificate": {
+			isvc: func() *v1beta1api.InferenceService {
+				isvc := costly()
+				isvc.Status.SetCondition(v1beta1api.CertificateReady, &apis.Condition{
+					Type:   v1beta1api.CertificateReady,
+					Status: v1.ConditionFalse,
+				})
+				return isvc
+			}(),
+			analyzingCanaries: true,
+			expected:          constants.CertificateRequeueInterval,
+		},
+		"ReadyCertificate": {
+			isvc: func() *v1beta1api.InferenceService {
+				isvc := &v1beta1api.InferenceService{}
+				isvc.Status.SetCondition(v1beta1api.CertificateReady, &apis.Condition{
+					Type:   v1beta1api.CertificateReady,
+					Status: v1.ConditionTrue,
+				})
+				return isvc
+			}(),
+			expected: 0,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(requeueAfter(scenario.isvc, scenario.analyzingCanaries, scenario.resolvingHedgingDelays)).
+				To(gomega.Equal(scenario.expected))
+		})
+	}
+}
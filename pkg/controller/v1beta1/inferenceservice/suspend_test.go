@@ -0,0 +1,71 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inferenceservice
+
+import (
+	"context"
+	"testing"
+
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	knservingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestScaleToZeroAndResume(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(knservingv1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	isvc := &v1beta1api.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"}}
+	name := constants.DefaultPredictorServiceName(isvc.Name)
+	service := &knservingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: isvc.Namespace,
+		Labels: map[string]string{constants.InferenceServicePodLabelKey: isvc.Name}}}
+	service.Spec.Template.Annotations = map[string]string{autoscaling.MinScaleAnnotationKey: "1"}
+	r := &InferenceServiceReconciler{Client: fake.NewFakeClientWithScheme(scheme, service), Log: logf.Log}
+	get := func() *knservingv1.Service {
+		existing := &knservingv1.Service{}
+		g.Expect(r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: isvc.Namespace}, existing)).
+			To(gomega.Succeed())
+		return existing
+	}
+
+	// The knative service is kept with its revisions, the missing components are skipped
+	g.Expect(r.scaleToZero(name, isvc.Namespace)).To(gomega.Succeed())
+	g.Expect(r.scaleToZero(constants.DefaultExplainerServiceName(isvc.Name), isvc.Namespace)).To(gomega.Succeed())
+	suspended := get()
+	g.Expect(suspended.Spec.Template.Annotations).To(gomega.HaveKeyWithValue(autoscaling.MinScaleAnnotationKey, "0"))
+	g.Expect(suspended.Labels).To(gomega.HaveKeyWithValue(constants.VisibilityLabel, constants.VisibilityClusterLocal))
+	g.Expect(suspended.Labels).To(gomega.HaveKeyWithValue(constants.InferenceServicePodLabelKey, isvc.Name))
+
+	g.Expect(r.resume(isvc)).To(gomega.Succeed())
+	g.Expect(get().Labels).NotTo(gomega.HaveKey(constants.VisibilityLabel))
+
+	// The cluster local inference services stay off the external gateway
+	g.Expect(r.scaleToZero(name, isvc.Namespace)).To(gomega.Succeed())
+	isvc.Labels = map[string]string{constants.VisibilityLabel: constants.VisibilityClusterLocal}
+	g.Expect(r.resume(isvc)).To(gomega.Succeed())
+	g.Expect(get().Labels).To(gomega.HaveKeyWithValue(constants.VisibilityLabel, constants.VisibilityClusterLocal))
+}
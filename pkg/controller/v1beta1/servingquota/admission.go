@@ -0,0 +1,157 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingquota
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listQuotas returns the quotas of the namespace, none when the ServingQuota CRD is not installed
+func listQuotas(cli client.Reader, namespace string) ([]v1beta1.ServingQuota, error) {
+	quotas := &v1beta1.ServingQuotaList{}
+	if err := cli.List(context.TODO(), quotas, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return quotas.Items, nil
+}
+
+// listInferenceServices returns the inference services of the namespace, oldest first
+func listInferenceServices(cli client.Reader, namespace string) ([]v1beta1.InferenceService, error) {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := cli.List(context.TODO(), isvcs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	sort.Slice(isvcs.Items, func(i, j int) bool {
+		if !isvcs.Items[i].CreationTimestamp.Equal(&isvcs.Items[j].CreationTimestamp) {
+			return isvcs.Items[i].CreationTimestamp.Before(&isvcs.Items[j].CreationTimestamp)
+		}
+		return isvcs.Items[i].Name < isvcs.Items[j].Name
+	})
+	return isvcs.Items, nil
+}
+
+// Usage returns the usage of the quotas by the inference services which are not queued, but the excluded one
+func Usage(isvcs []v1beta1.InferenceService, exclude string) v1beta1.ServingQuotaUsage {
+	used := v1beta1.ServingQuotaUsage{}
+	for i := range isvcs {
+		if isvcs[i].Name != exclude && isvcs[i].Status.IsQuotaAdmitted() {
+			used = used.Add(isvcs[i].QuotaUsage())
+		}
+	}
+	return used
+}
+
+// PendingAdmission returns whether the inference service is new or queued, the inference services are admitted by
+// the quotas once. The admitted inference services are not queued again when a quota is created or lowered.
+func PendingAdmission(isvc *v1beta1.InferenceService) bool {
+	return len(isvc.Status.Conditions) == 0 || !isvc.Status.IsQuotaAdmitted()
+}
+
+// Admit admits a new or queued inference service when it fits in the quotas of its namespace, it returns the reason
+// the inference service is queued, empty once admitted. The queued inference services are admitted oldest first: the
+// inference service is admitted when it fits once the older queued inference services fitting in the quotas are.
+func Admit(cli client.Client, isvc *v1beta1.InferenceService) (string, error) {
+	quotas, err := listQuotas(cli, isvc.Namespace)
+	if err != nil || len(quotas) == 0 {
+		return "", err
+	}
+	isvcs, err := listInferenceServices(cli, isvc.Namespace)
+	if err != nil {
+		return "", err
+	}
+	used := Usage(isvcs, isvc.Name)
+	for i := range isvcs {
+		candidate := &isvcs[i]
+		if candidate.Name == isvc.Name {
+			candidate = isvc
+		} else if candidate.Status.IsQuotaAdmitted() {
+			continue
+		}
+		if err := check(quotas, used, candidate); err != nil {
+			if candidate == isvc {
+				return err.Error(), nil
+			}
+			continue
+		}
+		if candidate == isvc {
+			return "", nil
+		}
+		used = used.Add(candidate.QuotaUsage())
+	}
+	// The inference service is not listed yet by the cache
+	if err := check(quotas, used, isvc); err != nil {
+		return err.Error(), nil
+	}
+	return "", nil
+}
+
+// Validate returns the reason an inference service is rejected, empty when it is not. The new or queued inference
+// services exceeding a quota of the Reject policy are rejected, they are queued by the quotas of the Queue policy.
+// The updates of the admitted inference services are rejected by all the quotas when they increase the usage beyond
+// the quota, the admitted inference services are not queued again. The old inference service is nil on create.
+// The quotas are best-effort: the reader should read from the API server, still the inference services admitted at the
+// same time are not persisted yet and may both be accepted when they fit alone but not together.
+func Validate(cli client.Reader, isvc *v1beta1.InferenceService, old *v1beta1.InferenceService) (string, error) {
+	if isvc.DeletionTimestamp != nil || (old != nil && equality.Semantic.DeepEqual(isvc.Spec, old.Spec)) {
+		return "", nil
+	}
+	quotas, err := listQuotas(cli, isvc.Namespace)
+	if err != nil || len(quotas) == 0 {
+		return "", err
+	}
+	isvcs, err := listInferenceServices(cli, isvc.Namespace)
+	if err != nil {
+		return "", err
+	}
+	used := Usage(isvcs, isvc.Name)
+	admitted := old != nil && !PendingAdmission(old)
+	for i := range quotas {
+		err := quotas[i].Check(used, isvc)
+		if err == nil {
+			continue
+		}
+		if admitted {
+			if isvc.QuotaUsage().Exceeds(old.QuotaUsage()) || quotas[i].Check(used, old) == nil {
+				return err.Error(), nil
+			}
+			continue
+		}
+		if quotas[i].Spec.GetPolicy() == v1beta1.RejectQuotaPolicy {
+			return err.Error(), nil
+		}
+	}
+	return "", nil
+}
+
+// check returns the error of the first quota the inference service exceeds
+func check(quotas []v1beta1.ServingQuota, used v1beta1.ServingQuotaUsage, isvc *v1beta1.InferenceService) error {
+	for i := range quotas {
+		if err := quotas[i].Check(used, isvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingquota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var created = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newFakeClient(t *testing.T, objs ...runtime.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewFakeClientWithScheme(scheme, objs...)
+}
+
+func makeQuota(name string, gpus int64, policy v1beta1.QuotaPolicy) *v1beta1.ServingQuota {
+	return &v1beta1.ServingQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1beta1.ServingQuotaSpec{GPUs: &gpus, Policy: policy},
+	}
+}
+
+// makeInferenceService returns an inference service created after the minutes, with a predictor of 2 replicas of the
+// GPUs. The inference service is admitted, queued or new.
+func makeInferenceService(name string, minutes int, gpus int64, state string) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created.Add(time.Duration(minutes) * time.Minute)),
+		},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MaxReplicas: 2},
+				Tensorflow: &v1beta1.TFServingSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+						Container: v1.Container{Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+							"nvidia.com/gpu": *resource.NewQuantity(gpus, resource.DecimalSI),
+						}}},
+					},
+				},
+			},
+		},
+	}
+	switch state {
+	case "admitted":
+		isvc.Status.InitializeConditions()
+		isvc.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{Status: v1.ConditionTrue})
+	case "queued":
+		isvc.Status.MarkQueued("ServingQuota gpus exceeded")
+	}
+	return isvc
+}
+
+func TestAdmit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		objs     []runtime.Object
+		isvc     *v1beta1.InferenceService
+		expected string
+	}{
+		"NoQuota": {
+			objs: []runtime.Object{makeInferenceService("first", 0, 4, "admitted")},
+			isvc: makeInferenceService("second", 1, 4, ""),
+		},
+		"WithinQuota": {
+			objs: []runtime.Object{makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+				makeInferenceService("first", 0, 2, "admitted")},
+			isvc: makeInferenceService("second", 1, 2, ""),
+		},
+		"QuotaExceeded": {
+			objs: []runtime.Object{makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+				makeInferenceService("first", 0, 2, "admitted")},
+			isvc:     makeInferenceService("second", 1, 4, ""),
+			expected: "ServingQuota gpus exceeded: requested 8 GPUs, used 4 of 8.",
+		},
+		"OlderQueuedInferenceServiceFirst": {
+			objs: []runtime.Object{makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+				makeInferenceService("first", 0, 2, "admitted"),
+				makeInferenceService("second", 1, 2, "queued"),
+				makeInferenceService("third", 2, 1, "queued")},
+			isvc:     makeInferenceService("third", 2, 1, "queued"),
+			expected: "ServingQuota gpus exceeded: requested 2 GPUs, used 8 of 8.",
+		},
+		"OlderQueuedInferenceServiceNotFitting": {
+			objs: []runtime.Object{makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+				makeInferenceService("first", 0, 2, "admitted"),
+				makeInferenceService("second", 1, 4, "queued"),
+				makeInferenceService("third", 2, 1, "queued")},
+			isvc: makeInferenceService("third", 2, 1, "queued"),
+		},
+		"NotListedYet": {
+			objs: []runtime.Object{makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+				makeInferenceService("first", 0, 3, "admitted")},
+			isvc:     makeInferenceService("second", 1, 2, ""),
+			expected: "ServingQuota gpus exceeded: requested 4 GPUs, used 6 of 8.",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			queued, err := Admit(newFakeClient(t, scenario.objs...), scenario.isvc)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(queued).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	first := makeInferenceService("first", 0, 2, "admitted")
+	scenarios := map[string]struct {
+		quota    *v1beta1.ServingQuota
+		isvc     *v1beta1.InferenceService
+		old      *v1beta1.InferenceService
+		expected string
+	}{
+		"WithinQuota": {
+			quota: makeQuota("gpus", 8, v1beta1.RejectQuotaPolicy),
+			isvc:  makeInferenceService("second", 1, 2, ""),
+		},
+		"Rejected": {
+			quota:    makeQuota("gpus", 8, v1beta1.RejectQuotaPolicy),
+			isvc:     makeInferenceService("second", 1, 4, ""),
+			expected: "ServingQuota gpus exceeded: requested 8 GPUs, used 4 of 8.",
+		},
+		"Queued": {
+			quota: makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+			isvc:  makeInferenceService("second", 1, 4, ""),
+		},
+		"QueuedUpdated": {
+			quota: makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+			isvc:  makeInferenceService("second", 1, 8, "queued"),
+			old:   makeInferenceService("second", 1, 4, "queued"),
+		},
+		"AdmittedIncreased": {
+			quota:    makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+			isvc:     makeInferenceService("first", 0, 6, "admitted"),
+			old:      first,
+			expected: "ServingQuota gpus exceeded: requested 12 GPUs, used 0 of 8.",
+		},
+		"AdmittedBeyondLoweredQuota": {
+			quota: makeQuota("gpus", 1, v1beta1.RejectQuotaPolicy),
+			isvc:  makeInferenceService("first", 0, 1, "admitted"),
+			old:   makeInferenceService("first", 0, 2, "admitted"),
+		},
+		"SpecNotChanged": {
+			quota: makeQuota("gpus", 2, v1beta1.RejectQuotaPolicy),
+			isvc: func() *v1beta1.InferenceService {
+				isvc := first.DeepCopy()
+				isvc.Finalizers = []string{"serving.kubeflow.org/federation"}
+				return isvc
+			}(),
+			old: first,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			reason, err := Validate(newFakeClient(t, scenario.quota, first), scenario.isvc, scenario.old)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(reason).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
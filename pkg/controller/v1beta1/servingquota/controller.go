@@ -0,0 +1,128 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=servingquotas,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=servingquotas/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices,verbs=get;list;watch
+package servingquota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ServingQuotaReconciler reconciles a ServingQuota object. The quotas are enforced by the admission webhook and the
+// InferenceService controller, the reconciler reports the usage of the quota and the queued inference services.
+type ServingQuotaReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+}
+
+func (r *ServingQuotaReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("servingquota", req.NamespacedName)
+	if owned, err := r.Shard.Owns(req.Namespace); !owned {
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the ServingQuota instance
+	quota := &v1beta1api.ServingQuota{}
+	if err := r.Get(context.TODO(), req.NamespacedName, quota); err != nil {
+		if apierr.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	log.Info("Reconciling serving quota", "policy", quota.Spec.GetPolicy())
+	quota.Status.InitializeConditions()
+
+	isvcs, err := listInferenceServices(r.Client, quota.Namespace)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to list inference services")
+	}
+	quota.Status.Used = Usage(isvcs, "")
+	quota.Status.Queued = nil
+	for i := range isvcs {
+		if !isvcs[i].Status.IsQuotaAdmitted() {
+			quota.Status.Queued = append(quota.Status.Queued, isvcs[i].Name)
+		}
+	}
+	if err := quota.Spec.Validate(); err != nil {
+		quota.Status.MarkNotReady("InvalidPolicy", err.Error())
+	} else if quota.Exceeded(quota.Status.Used) {
+		// The inference services admitted before the quota was created or lowered are not queued
+		quota.Status.MarkNotReady(v1beta1api.QuotaExceeded, fmt.Sprintf("The usage of the inference services "+
+			"exceeds the quota: %d GPUs, %d replicas and %d inference services are used",
+			quota.Status.Used.GPUs, quota.Status.Used.Replicas, quota.Status.Used.InferenceServices))
+	} else {
+		quota.Status.MarkReady()
+	}
+	return reconcile.Result{}, r.updateStatus(quota)
+}
+
+func (r *ServingQuotaReconciler) updateStatus(desired *v1beta1api.ServingQuota) error {
+	existing := &v1beta1api.ServingQuota{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Status, desired.Status) {
+		return nil
+	}
+	if err := r.Status().Update(context.TODO(), desired); err != nil {
+		r.Log.Error(err, "Failed to update ServingQuota status", "ServingQuota", desired.Name)
+		return errors.Wrapf(err, "fails to update ServingQuota status")
+	}
+	return nil
+}
+
+func (r *ServingQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Inference services are mapped to all the quotas of their namespace
+	toQuotas := func(o handler.MapObject) []reconcile.Request {
+		quotas, err := listQuotas(r.Client, o.Meta.GetNamespace())
+		if err != nil {
+			r.Log.Error(err, "Failed to list the serving quotas", "namespace", o.Meta.GetNamespace())
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(quotas))
+		for _, quota := range quotas {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: quota.Name,
+				Namespace: quota.Namespace}})
+		}
+		return requests
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1api.ServingQuota{}).
+		Watches(&source.Kind{Type: &v1beta1api.InferenceService{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(toQuotas)}).
+		WithEventFilter(r.Shard.Predicate()).
+		Complete(r)
+}
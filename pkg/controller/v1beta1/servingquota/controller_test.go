@@ -0,0 +1,82 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingquota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestServingQuotaReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		quota  *v1beta1.ServingQuota
+		used   v1beta1.ServingQuotaUsage
+		queued []string
+		ready  v1.ConditionStatus
+		reason string
+	}{
+		"WithinQuota": {
+			quota:  makeQuota("gpus", 8, v1beta1.QueueQuotaPolicy),
+			used:   v1beta1.ServingQuotaUsage{GPUs: 6, Replicas: 4, InferenceServices: 2},
+			queued: []string{"third"},
+			ready:  v1.ConditionTrue,
+		},
+		"QuotaExceeded": {
+			quota:  makeQuota("gpus", 4, v1beta1.QueueQuotaPolicy),
+			used:   v1beta1.ServingQuotaUsage{GPUs: 6, Replicas: 4, InferenceServices: 2},
+			queued: []string{"third"},
+			ready:  v1.ConditionFalse,
+			reason: v1beta1.QuotaExceeded,
+		},
+		"InvalidPolicy": {
+			quota:  makeQuota("gpus", 8, "Drop"),
+			used:   v1beta1.ServingQuotaUsage{GPUs: 6, Replicas: 4, InferenceServices: 2},
+			queued: []string{"third"},
+			ready:  v1.ConditionFalse,
+			reason: "InvalidPolicy",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			cl := newFakeClient(t, scenario.quota,
+				makeInferenceService("first", 0, 2, "admitted"),
+				makeInferenceService("second", 1, 1, ""),
+				makeInferenceService("third", 2, 4, "queued"))
+			r := &ServingQuotaReconciler{Client: cl, Log: logf.Log.WithName("test")}
+			key := types.NamespacedName{Name: scenario.quota.Name, Namespace: scenario.quota.Namespace}
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			quota := &v1beta1.ServingQuota{}
+			g.Expect(cl.Get(context.TODO(), key, quota)).To(gomega.Succeed())
+			g.Expect(quota.Status.Used).To(gomega.Equal(scenario.used))
+			g.Expect(quota.Status.Queued).To(gomega.Equal(scenario.queued))
+			condition := quota.Status.GetCondition(apis.ConditionReady)
+			g.Expect(condition.Status).To(gomega.Equal(scenario.ready))
+			g.Expect(condition.Reason).To(gomega.Equal(scenario.reason))
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/trainedmodel/reconcilers/modelconfig"
 	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/trainedmodel/sharding/memory"
+	"github.com/kubeflow/kfserving/pkg/shard"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -49,10 +50,15 @@ type TrainedModelReconciler struct {
 	Scheme                *runtime.Scheme
 	Recorder              record.EventRecorder
 	ModelConfigReconciler *modelconfig.ModelConfigReconciler
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
 }
 
 func (r *TrainedModelReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("trainedmodel", req.NamespacedName)
+	if owned, err := r.Shard.Owns(req.Namespace); !owned {
+		return reconcile.Result{}, err
+	}
 
 	// Fetch the TrainedModel instance
 	tm := &v1beta1api.TrainedModel{}
@@ -91,5 +97,6 @@ func (r *TrainedModelReconciler) updateStatus(desiredService *v1beta1api.Trained
 func (r *TrainedModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1api.TrainedModel{}).
+		WithEventFilter(r.Shard.Predicate()).
 		Complete(r)
 }
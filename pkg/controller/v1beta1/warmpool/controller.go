@@ -0,0 +1,410 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=warmpools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=warmpools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=inferenceservices,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=servingruntimes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=clusterservingruntimes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=trainedmodels,verbs=get
+// +kubebuilder:rbac:groups=serving.kubeflow.org,resources=trainedmodels/status,verbs=get;update;patch
+package warmpool
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/go-logr/logr"
+	v1beta1api "github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/inferenceservice/components"
+	"github.com/kubeflow/kfserving/pkg/frameworks"
+	"github.com/kubeflow/kfserving/pkg/imagedigest"
+	"github.com/kubeflow/kfserving/pkg/modelconfig"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	podwebhook "github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// WarmPoolReconciler reconciles a WarmPool object. The unclaimed pods of the pool are run by a deployment, a pod is
+// claimed by relabelling it out of the deployment so that the deployment replaces it with a new unclaimed pod.
+type WarmPoolReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+}
+
+func (r *WarmPoolReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("warmpool", req.NamespacedName)
+	if owned, err := r.Shard.Owns(req.Namespace); !owned {
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the WarmPool instance
+	pool := &v1beta1api.WarmPool{}
+	if err := r.Get(context.TODO(), req.NamespacedName, pool); err != nil {
+		if apierr.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	log.Info("Reconciling warm pool", "runtime", pool.Spec.Runtime, "replicas", pool.Spec.Replicas)
+	pool.Status.InitializeConditions()
+
+	servingRuntime, err := components.GetNamedServingRuntime(r.Client, pool.Namespace, pool.Spec.Runtime)
+	if err != nil {
+		pool.Status.MarkNotReady("RuntimeNotFound", err.Error())
+		return reconcile.Result{}, utils.FirstNonNilError([]error{err, r.updateStatus(pool)})
+	}
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: constants.InferenceServiceConfigMapName,
+		Namespace: constants.KFServingNamespace}, configMap); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to get the inference service config map")
+	}
+	agentConfig, err := getAgentConfig(configMap)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	securityContextConfig, err := podwebhook.GetSecurityContextConfigs(configMap)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	registryConfig, err := v1beta1api.GetRegistryConfig(configMap)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	deployment, err := createDeployment(pool, servingRuntime, agentConfig, securityContextConfig, registryConfig)
+	if err != nil {
+		pool.Status.MarkNotReady("InvalidRuntime", err.Error())
+		return reconcile.Result{}, utils.FirstNonNilError([]error{err, r.updateStatus(pool)})
+	}
+	if err := r.resolveImageDigests(pool, &deployment.Spec.Template.Spec, registryConfig); err != nil {
+		pool.Status.MarkNotReady("ImageResolutionFailed", err.Error())
+		return reconcile.Result{}, utils.FirstNonNilError([]error{err, r.updateStatus(pool)})
+	}
+
+	modelConfig, err := r.reconcileModelConfig(pool)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile model config")
+	}
+	if err := r.reconcileAgentRole(pool); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile agent role")
+	}
+	if err := r.reconcileDeployment(pool, deployment); err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile deployment")
+	}
+	if err := r.reconcileClaims(pool, modelConfig, &deployment.Spec.Template.Spec.Containers[0]); err != nil {
+		r.Recorder.Eventf(pool, v1.EventTypeWarning, "InternalError", err.Error())
+		return reconcile.Result{}, errors.Wrapf(err, "fails to reconcile claims")
+	}
+	return reconcile.Result{}, r.updateStatus(pool)
+}
+
+// resolveImageDigests deploys the images of the pods of the pool, the runtime and the agent, by digest when the
+// registry config resolves the digests, and records the resolved images in the status of the pool
+func (r *WarmPoolReconciler) resolveImageDigests(pool *v1beta1api.WarmPool, podSpec *v1.PodSpec,
+	registryConfig *v1beta1api.RegistryConfig) error {
+	if !registryConfig.ResolveDigests {
+		pool.Status.ResolvedImages = nil
+		return nil
+	}
+	resolvedImages, err := imagedigest.NewResolver(r.Client).Resolve(pool.Namespace, podSpec,
+		pool.Status.ResolvedImages)
+	if err != nil {
+		return err
+	}
+	pool.Status.ResolvedImages = resolvedImages
+	return nil
+}
+
+// reconcileModelConfig creates the config map holding the model config file of each claimed pod
+func (r *WarmPoolReconciler) reconcileModelConfig(pool *v1beta1api.WarmPool) (*v1.ConfigMap, error) {
+	existing := &v1.ConfigMap{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolModelConfigName(pool.Name), Namespace: pool.Namespace}, existing)
+	if err == nil || !apierr.IsNotFound(err) {
+		return existing, err
+	}
+	desired := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.WarmPoolModelConfigName(pool.Name),
+			Namespace: pool.Namespace,
+			Labels: map[string]string{
+				constants.WarmPoolLabelKey: pool.Name,
+			},
+		},
+		Data: map[string]string{},
+	}
+	if err := controllerutil.SetControllerReference(pool, desired, r.Scheme); err != nil {
+		return nil, err
+	}
+	r.Log.Info("Creating warm pool model config", "namespace", desired.Namespace, "name", desired.Name)
+	return desired, r.Create(context.TODO(), desired)
+}
+
+// reconcileAgentRole creates the Role and the RoleBinding of the agents of the pool, they are not updated
+func (r *WarmPoolReconciler) reconcileAgentRole(pool *v1beta1api.WarmPool) error {
+	role, roleBinding := createAgentRole(pool)
+	for _, object := range []runtime.Object{role, roleBinding} {
+		if err := controllerutil.SetControllerReference(pool, object.(metav1.Object), r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(context.TODO(), object); err != nil && !apierr.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *WarmPoolReconciler) reconcileDeployment(pool *v1beta1api.WarmPool, desired *appsv1.Deployment) error {
+	if err := controllerutil.SetControllerReference(pool, desired, r.Scheme); err != nil {
+		return err
+	}
+	existing := &appsv1.Deployment{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing)
+	if err != nil {
+		if apierr.IsNotFound(err) {
+			r.Log.Info("Creating warm pool deployment", "namespace", desired.Namespace, "name", desired.Name)
+			return r.Create(context.TODO(), desired)
+		}
+		return err
+	}
+	if equality.Semantic.DeepDerivative(desired.Spec, existing.Spec) {
+		return nil
+	}
+	r.Log.Info("Updating warm pool deployment", "namespace", desired.Namespace, "name", desired.Name)
+	existing.Spec = desired.Spec
+	return r.Update(context.TODO(), existing)
+}
+
+// reconcileClaims releases the pods claimed by inference services whose predictor is ready and assigns an available
+// pod to the inference services annotated with the pool that are not ready yet.
+func (r *WarmPoolReconciler) reconcileClaims(pool *v1beta1api.WarmPool, modelConfig *v1.ConfigMap, modelServer *v1.Container) error {
+	pods := &v1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(pool.Namespace),
+		client.MatchingLabels{constants.WarmPoolLabelKey: pool.Name}); err != nil {
+		return err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	isvcs := &v1beta1api.InferenceServiceList{}
+	if err := r.List(context.TODO(), isvcs, client.InNamespace(pool.Namespace)); err != nil {
+		return err
+	}
+	waiting := map[string]*v1beta1api.InferenceService{}
+	for i := range isvcs.Items {
+		isvc := &isvcs.Items[i]
+		if isvc.Annotations[constants.WarmPoolAnnotationKey] == pool.Name && isvc.DeletionTimestamp == nil &&
+			!isvc.Status.IsConditionReady(v1beta1api.PredictorReady) {
+			waiting[isvc.Name] = isvc
+		}
+	}
+
+	var claims []v1beta1api.WarmPoolClaim
+	available := []*v1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		switch pod.Labels[constants.WarmPoolStateLabelKey] {
+		case constants.WarmPoolStateClaimed:
+			name := pod.Labels[constants.WarmPoolClaimLabelKey]
+			if _, ok := waiting[name]; !ok {
+				if err := r.releaseClaim(pool, modelConfig, pod, name); err != nil {
+					return err
+				}
+				continue
+			}
+			delete(waiting, name)
+			claims = append(claims, v1beta1api.WarmPoolClaim{InferenceService: name, Pod: pod.Name})
+		case constants.WarmPoolStateAvailable:
+			if isPodReady(pod) {
+				available = append(available, pod)
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range waiting {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(available) == 0 {
+			break
+		}
+		isvc := waiting[name]
+		implementations := isvc.Spec.Predictor.GetImplementations()
+		if len(implementations) == 0 || implementations[0].GetStorageUri() == nil {
+			r.Recorder.Eventf(isvc, v1.EventTypeWarning, "WarmPoolClaimFailed",
+				"InferenceService %s requires the storageUri of the predictor to claim a pod of warm pool %s", name, pool.Name)
+			continue
+		}
+		framework, _ := frameworks.ForPredictor(&isvc.Spec.Predictor)
+		if err := r.claim(pool, modelConfig, modelServer, available[0], name, modelconfig.ModelConfig{
+			Name: name,
+			Spec: v1beta1api.ModelSpec{
+				StorageURI: *implementations[0].GetStorageUri(),
+				Framework:  string(framework),
+			},
+		}); err != nil {
+			return err
+		}
+		claims = append(claims, v1beta1api.WarmPoolClaim{InferenceService: name, Pod: available[0].Name})
+		available = available[1:]
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].InferenceService < claims[j].InferenceService
+	})
+	pool.Status.Claims = claims
+	pool.Status.AvailableReplicas = int32(len(available))
+	if len(available) == 0 {
+		pool.Status.MarkNotReady("NoAvailableReplicas", "No ready pod can be claimed")
+	} else {
+		pool.Status.MarkReady()
+	}
+	return nil
+}
+
+// claim writes the model config file of the pod, relabels the pod out of the deployment and creates the service
+// routing to the pod
+func (r *WarmPoolReconciler) claim(pool *v1beta1api.WarmPool, modelConfig *v1.ConfigMap, modelServer *v1.Container,
+	pod *v1.Pod, isvc string, model modelconfig.ModelConfig) error {
+	r.Log.Info("Claiming warm pool pod", "namespace", pod.Namespace, "pod", pod.Name, "inferenceservice", isvc)
+	config, err := json.Marshal(modelconfig.ModelConfigs{model})
+	if err != nil {
+		return err
+	}
+	if modelConfig.Data == nil {
+		modelConfig.Data = map[string]string{}
+	}
+	modelConfig.Data[constants.WarmPoolModelConfigFileName(pod.Name)] = string(config)
+	if err := r.Update(context.TODO(), modelConfig); err != nil {
+		return errors.Wrapf(err, "fails to update model config")
+	}
+
+	// The deployment releases the pod once relabelled, the pool owns the claimed pod instead
+	pod.Labels[constants.WarmPoolStateLabelKey] = constants.WarmPoolStateClaimed
+	pod.Labels[constants.WarmPoolClaimLabelKey] = isvc
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(pool,
+		v1beta1api.SchemeGroupVersion.WithKind("WarmPool"))}
+	if err := r.Update(context.TODO(), pod); err != nil {
+		return errors.Wrapf(err, "fails to claim pod %s", pod.Name)
+	}
+
+	service := createClaimService(pool, isvc, modelServer)
+	if err := controllerutil.SetControllerReference(pool, service, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(context.TODO(), service); err != nil && !apierr.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "fails to create service %s", service.Name)
+	}
+	r.Recorder.Eventf(pool, v1.EventTypeNormal, "Claimed", "Pod %s claimed by InferenceService %s", pod.Name, isvc)
+	return nil
+}
+
+// releaseClaim deletes the claimed pod, its model config file and service once the inference service no longer
+// needs it
+func (r *WarmPoolReconciler) releaseClaim(pool *v1beta1api.WarmPool, modelConfig *v1.ConfigMap, pod *v1.Pod, isvc string) error {
+	r.Log.Info("Releasing warm pool pod", "namespace", pod.Namespace, "pod", pod.Name, "inferenceservice", isvc)
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.WarmPoolClaimServiceName(isvc),
+			Namespace: pool.Namespace,
+		},
+	}
+	if err := r.Delete(context.TODO(), service); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete service %s", service.Name)
+	}
+	if _, ok := modelConfig.Data[constants.WarmPoolModelConfigFileName(pod.Name)]; ok {
+		delete(modelConfig.Data, constants.WarmPoolModelConfigFileName(pod.Name))
+		if err := r.Update(context.TODO(), modelConfig); err != nil {
+			return errors.Wrapf(err, "fails to update model config")
+		}
+	}
+	if err := r.Delete(context.TODO(), pod); err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "fails to delete pod %s", pod.Name)
+	}
+	r.Recorder.Eventf(pool, v1.EventTypeNormal, "Released", "Pod %s released by InferenceService %s", pod.Name, isvc)
+	return nil
+}
+
+func (r *WarmPoolReconciler) updateStatus(desired *v1beta1api.WarmPool) error {
+	existing := &v1beta1api.WarmPool{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, existing); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Status, desired.Status) {
+		return nil
+	}
+	if err := r.Status().Update(context.TODO(), desired); err != nil {
+		r.Log.Error(err, "Failed to update WarmPool status", "WarmPool", desired.Name)
+		return errors.Wrapf(err, "fails to update WarmPool status")
+	}
+	return nil
+}
+
+func (r *WarmPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Inference services and claimed pods are mapped to the pool named in their annotation and label
+	toPool := func(key string, annotations bool) handler.ToRequestsFunc {
+		return func(o handler.MapObject) []reconcile.Request {
+			values := o.Meta.GetLabels()
+			if annotations {
+				values = o.Meta.GetAnnotations()
+			}
+			if pool, ok := values[key]; ok {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pool, Namespace: o.Meta.GetNamespace()}}}
+			}
+			return nil
+		}
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1api.WarmPool{}).
+		Owns(&appsv1.Deployment{}).
+		Watches(&source.Kind{Type: &v1beta1api.InferenceService{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: toPool(constants.WarmPoolAnnotationKey, true)}).
+		Watches(&source.Kind{Type: &v1.Pod{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: toPool(constants.WarmPoolLabelKey, false)}).
+		WithEventFilter(r.Shard.Predicate()).
+		Complete(r)
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmpool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	podwebhook "github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestWarmPoolReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	namespace := "default"
+	storageUri := "s3://models/sklearn/iris"
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		Data: map[string]string{
+			AgentConfigMapKeyName:                      `{"image": "gcr.io/kfserving/agent:latest", "memoryRequest": "100Mi", "memoryLimit": "1Gi", "cpuRequest": "100m", "cpuLimit": "1"}`,
+			podwebhook.SecurityContextConfigMapKeyName: `{"runAsNonRoot": true, "seccompProfile": "RuntimeDefault"}`,
+			v1beta1.RegistryConfigKeyName:              `{"imagePullSecrets": [{"name": "kfserving-registry"}]}`,
+		},
+	}
+	servingRuntime := &v1beta1.ClusterServingRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "triton"},
+		Spec: v1beta1.ServingRuntimeSpec{
+			SupportedModelFormats: []v1beta1.SupportedModelFormat{{Name: "sklearn"}},
+			ProtocolVersions:      []constants.InferenceServiceProtocol{constants.ProtocolV2},
+			Containers: []v1.Container{
+				{
+					Name:  constants.InferenceServiceContainerName,
+					Image: "tritonserver:latest",
+					Args:  []string{"--model-repository={{.ModelDir}}", "--model-control-mode=explicit"},
+				},
+			},
+		},
+	}
+	pool := &v1beta1.WarmPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "triton-t4", Namespace: namespace},
+		Spec: v1beta1.WarmPoolSpec{
+			Runtime:          "triton",
+			Replicas:         2,
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "triton-registry"}},
+		},
+	}
+	poolPod := func(name string, labels map[string]string, ready bool) *v1.Pod {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			},
+		}
+	}
+	isvc := func(name string, predictorReady bool) *v1beta1.InferenceService {
+		isvc := &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{constants.WarmPoolAnnotationKey: pool.Name},
+			},
+			Spec: v1beta1.InferenceServiceSpec{
+				Predictor: v1beta1.PredictorSpec{
+					SKLearn: &v1beta1.SKLearnSpec{
+						PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: &storageUri},
+					},
+				},
+			},
+		}
+		if predictorReady {
+			isvc.Status.SetCondition(v1beta1.PredictorReady, &apis.Condition{
+				Type:   v1beta1.PredictorReady,
+				Status: v1.ConditionTrue,
+			})
+		}
+		return isvc
+	}
+	available := map[string]string{
+		constants.WarmPoolLabelKey:      pool.Name,
+		constants.WarmPoolStateLabelKey: constants.WarmPoolStateAvailable,
+	}
+	claimed := func(isvc string) map[string]string {
+		return map[string]string{
+			constants.WarmPoolLabelKey:      pool.Name,
+			constants.WarmPoolStateLabelKey: constants.WarmPoolStateClaimed,
+			constants.WarmPoolClaimLabelKey: isvc,
+		}
+	}
+
+	scenarios := map[string]struct {
+		objects           []runtime.Object
+		expectedClaims    []v1beta1.WarmPoolClaim
+		expectedAvailable int32
+		expectedDeleted   []string
+		expectedModels    map[string]string
+	}{
+		"ClaimReadyPod": {
+			objects: []runtime.Object{
+				poolPod("triton-t4-a", available, false),
+				poolPod("triton-t4-b", available, true),
+				isvc("iris", false),
+			},
+			expectedClaims: []v1beta1.WarmPoolClaim{{InferenceService: "iris", Pod: "triton-t4-b"}},
+			expectedModels: map[string]string{
+				"triton-t4-b.json": `[{"modelName":"iris","modelSpec":{"storageUri":"s3://models/sklearn/iris","framework":"sklearn","memory":"0"}}]`,
+			},
+		},
+		"NoReadyPod": {
+			objects: []runtime.Object{
+				poolPod("triton-t4-a", available, false),
+				isvc("iris", false),
+			},
+			expectedModels: map[string]string{},
+		},
+		"KeepClaimUntilPredictorReady": {
+			objects: []runtime.Object{
+				poolPod("triton-t4-a", claimed("iris"), true),
+				poolPod("triton-t4-b", available, true),
+				isvc("iris", false),
+			},
+			expectedClaims:    []v1beta1.WarmPoolClaim{{InferenceService: "iris", Pod: "triton-t4-a"}},
+			expectedAvailable: 1,
+			expectedModels:    map[string]string{},
+		},
+		"ReleaseClaimOncePredictorReady": {
+			objects: []runtime.Object{
+				poolPod("triton-t4-a", claimed("iris"), true),
+				isvc("iris", true),
+			},
+			expectedDeleted: []string{"triton-t4-a"},
+			expectedModels:  map[string]string{},
+		},
+		"ReleaseClaimOfDeletedInferenceService": {
+			objects: []runtime.Object{
+				poolPod("triton-t4-a", claimed("iris"), true),
+			},
+			expectedDeleted: []string{"triton-t4-a"},
+			expectedModels:  map[string]string{},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			objects := append([]runtime.Object{configMap.DeepCopy(), servingRuntime.DeepCopy(), pool.DeepCopy()}, scenario.objects...)
+			cl := fake.NewFakeClientWithScheme(scheme, objects...)
+			r := &WarmPoolReconciler{
+				Client:   cl,
+				Log:      logf.Log.WithName("WarmPool"),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: pool.Name, Namespace: namespace}})
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+
+			actual := &v1beta1.WarmPool{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: pool.Name, Namespace: namespace}, actual)).To(gomega.Succeed())
+			g.Expect(actual.Status.Claims).To(gomega.Equal(scenario.expectedClaims))
+			g.Expect(actual.Status.AvailableReplicas).To(gomega.Equal(scenario.expectedAvailable))
+
+			deployment := &appsv1.Deployment{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolDeploymentName(pool.Name), Namespace: namespace}, deployment)).To(gomega.Succeed())
+			g.Expect(*deployment.Spec.Replicas).To(gomega.Equal(pool.Spec.Replicas))
+			g.Expect(deployment.Spec.Template.Spec.ImagePullSecrets).To(gomega.Equal([]v1.LocalObjectReference{
+				{Name: "triton-registry"}, {Name: "kfserving-registry"},
+			}))
+			containers := deployment.Spec.Template.Spec.Containers
+			g.Expect(containers[0].SecurityContext).To(gomega.BeNil())
+			agent := containers[len(containers)-1]
+			g.Expect(agent.Name).To(gomega.Equal(constants.WarmPoolAgentContainerName))
+			g.Expect(*agent.SecurityContext.RunAsNonRoot).To(gomega.BeTrue())
+			g.Expect(agent.Ports).To(gomega.Equal([]v1.ContainerPort{
+				{Name: "metadata", ContainerPort: AgentMetadataPort, Protocol: v1.ProtocolTCP},
+			}))
+			g.Expect(agent.Env).To(gomega.ContainElement(v1.EnvVar{Name: PodNamespaceEnvVarKey, ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}}))
+			role := &rbacv1.Role{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolAgentRoleName(pool.Name), Namespace: namespace}, role)).To(gomega.Succeed())
+			g.Expect(role.Rules).To(gomega.ContainElement(rbacv1.PolicyRule{APIGroups: []string{constants.KFServingAPIGroupName},
+				Resources: []string{"trainedmodels/status"}, Verbs: []string{"patch"}}))
+			roleBinding := &rbacv1.RoleBinding{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolAgentRoleName(pool.Name), Namespace: namespace}, roleBinding)).To(gomega.Succeed())
+			g.Expect(roleBinding.Subjects).To(gomega.Equal([]rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: DefaultServiceAccountName, Namespace: namespace},
+			}))
+			g.Expect(deployment.Spec.Template.Annotations).To(gomega.Equal(map[string]string{
+				v1.SeccompContainerAnnotationKeyPrefix + constants.WarmPoolAgentContainerName: v1.SeccompProfileRuntimeDefault,
+			}))
+
+			modelConfig := &v1.ConfigMap{}
+			g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolModelConfigName(pool.Name), Namespace: namespace}, modelConfig)).To(gomega.Succeed())
+			for key, expected := range scenario.expectedModels {
+				g.Expect(modelConfig.Data[key]).To(gomega.MatchJSON(expected))
+			}
+			if len(scenario.expectedModels) == 0 {
+				g.Expect(modelConfig.Data).To(gomega.BeEmpty())
+			}
+
+			for _, claim := range scenario.expectedClaims {
+				pod := &v1.Pod{}
+				g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: claim.Pod, Namespace: namespace}, pod)).To(gomega.Succeed())
+				g.Expect(pod.Labels).To(gomega.Equal(claimed(claim.InferenceService)))
+				service := &v1.Service{}
+				if _, ok := scenario.expectedModels[constants.WarmPoolModelConfigFileName(claim.Pod)]; ok {
+					g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: constants.WarmPoolClaimServiceName(claim.InferenceService), Namespace: namespace}, service)).To(gomega.Succeed())
+					g.Expect(service.Spec.Selector).To(gomega.Equal(map[string]string{
+						constants.WarmPoolLabelKey:      pool.Name,
+						constants.WarmPoolClaimLabelKey: claim.InferenceService,
+					}))
+				}
+			}
+			for _, name := range scenario.expectedDeleted {
+				err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, &v1.Pod{})
+				g.Expect(apierr.IsNotFound(err)).To(gomega.BeTrue())
+			}
+		})
+	}
+}
+
+func TestCreateDeploymentGPUType(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gpuType := "nvidia-tesla-t4"
+	pool := &v1beta1.WarmPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "triton-t4", Namespace: "default"},
+		Spec:       v1beta1.WarmPoolSpec{Runtime: "triton", Replicas: 1, GPUType: &gpuType},
+	}
+	runtime := &v1beta1.ServingRuntimeSpec{
+		Containers: []v1.Container{{Name: constants.InferenceServiceContainerName, Image: "tritonserver:latest"}},
+	}
+	agentConfig := &AgentConfig{Image: "gcr.io/kfserving/agent:latest", MemoryRequest: "100Mi", MemoryLimit: "1Gi",
+		CpuRequest: "100m", CpuLimit: "1"}
+
+	deployment, err := createDeployment(pool, runtime, agentConfig, &podwebhook.SecurityContextConfig{},
+		&v1beta1.RegistryConfig{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	podSpec := deployment.Spec.Template.Spec
+	g.Expect(podSpec.NodeSelector).To(gomega.Equal(map[string]string{GKEAcceleratorNodeLabelKey: gpuType}))
+	g.Expect(podSpec.Containers[0].Resources.Limits).To(gomega.HaveKeyWithValue(
+		v1.ResourceName(constants.NvidiaGPUResourceType), resource.MustParse("1")))
+}
+
+func TestWarmPoolRuntimeNotFound(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	pool := &v1beta1.WarmPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "triton-t4", Namespace: "default"},
+		Spec:       v1beta1.WarmPoolSpec{Runtime: "triton", Replicas: 1},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, pool)
+	r := &WarmPoolReconciler{
+		Client:   cl,
+		Log:      logf.Log.WithName("WarmPool"),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}})
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	actual := &v1beta1.WarmPool{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}, actual)).To(gomega.Succeed())
+	g.Expect(actual.Status.GetCondition(apis.ConditionReady).Reason).To(gomega.Equal("RuntimeNotFound"))
+}
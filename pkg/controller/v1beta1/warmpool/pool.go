@@ -0,0 +1,351 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmpool
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	podwebhook "github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	AgentConfigMapKeyName      = "agent"
+	ModelVolumeName            = "kfserving-warmpool-models"
+	ModelConfigVolumeName      = "kfserving-warmpool-model-config"
+	GKEAcceleratorNodeLabelKey = "cloud.google.com/gke-accelerator"
+	PodNameEnvVarKey           = "POD_NAME"
+	PodNamespaceEnvVarKey      = "POD_NAMESPACE"
+	// AgentMetadataPort is the port of the metadata endpoint of the agent serving the signatures of the models
+	AgentMetadataPort = 9081
+	// DefaultServiceAccountName is the service account of the pods of the pool
+	DefaultServiceAccountName = "default"
+	// DefaultDrainTimeoutSeconds is the time left to the agent to unload the models on scale down
+	DefaultDrainTimeoutSeconds = 10
+	// DefaultTerminationGracePeriodSeconds is left to the model server to stop once the models are unloaded
+	DefaultTerminationGracePeriodSeconds = 30
+)
+
+type AgentConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+	// DrainTimeoutSeconds is the time left to the agent to unload the models on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
+}
+
+func getAgentConfig(configMap *v1.ConfigMap) (*AgentConfig, error) {
+	agentConfig := &AgentConfig{}
+	if agentConfigValue, ok := configMap.Data[AgentConfigMapKeyName]; ok {
+		if err := json.Unmarshal([]byte(agentConfigValue), agentConfig); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", AgentConfigMapKeyName, err)
+		}
+	}
+	if agentConfig.Image == "" {
+		return nil, fmt.Errorf("Invalid %v config, image is required", AgentConfigMapKeyName)
+	}
+	//Ensure that we set proper values for CPU/Memory Limit/Request
+	for _, key := range []string{agentConfig.MemoryRequest, agentConfig.MemoryLimit, agentConfig.CpuRequest, agentConfig.CpuLimit} {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return nil, fmt.Errorf("Failed to parse resource configuration for %q: %q", AgentConfigMapKeyName, err.Error())
+		}
+	}
+	return agentConfig, nil
+}
+
+// poolLabels are the labels selecting the unclaimed pods of the pool
+func poolLabels(pool *v1beta1.WarmPool) map[string]string {
+	return map[string]string{
+		constants.WarmPoolLabelKey:      pool.Name,
+		constants.WarmPoolStateLabelKey: constants.WarmPoolStateAvailable,
+	}
+}
+
+// createDeployment builds the deployment running the unclaimed pods of the pool. The pods run the model server of
+// the runtime and the agent loading the models of the pod config file once the pod is claimed.
+func createDeployment(pool *v1beta1.WarmPool, runtime *v1beta1.ServingRuntimeSpec, agentConfig *AgentConfig,
+	securityContextConfig *podwebhook.SecurityContextConfig, registryConfig *v1beta1.RegistryConfig) (*appsv1.Deployment, error) {
+	model := &v1beta1.ModelPredictorSpec{
+		PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{
+			Container: v1.Container{
+				Resources: *pool.Spec.Resources.DeepCopy(),
+			},
+		},
+	}
+	container, err := model.GetRuntimeContainer(pool.ObjectMeta, runtime)
+	if err != nil {
+		return nil, err
+	}
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+		Name:      ModelVolumeName,
+		MountPath: constants.DefaultModelLocalMountPath,
+		ReadOnly:  true,
+	})
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{*container},
+		Volumes: []v1.Volume{
+			{
+				Name: ModelVolumeName,
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{},
+				},
+			},
+			{
+				Name: ModelConfigVolumeName,
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: constants.WarmPoolModelConfigName(pool.Name),
+						},
+					},
+				},
+			},
+		},
+	}
+	podSpec.Containers = append(podSpec.Containers, runtime.Containers[1:]...)
+	podSpec.Containers = append(podSpec.Containers, createAgentContainer(container, agentConfig))
+	setShutdownOrdering(&podSpec, agentConfig)
+	podSpec.ImagePullSecrets = utils.MergeImagePullSecrets(pool.Spec.ImagePullSecrets, registryConfig.ImagePullSecrets)
+	// The pods of the pool are not mutated by the pod webhook, the agent is secured like the injected containers
+	var annotations map[string]string
+	if securityContextConfig.SeccompProfile != "" {
+		annotations = map[string]string{}
+	}
+	securityContextConfig.Apply(&podSpec, annotations, constants.WarmPoolAgentContainerName)
+	if pool.Spec.GPUType != nil {
+		podSpec.NodeSelector = map[string]string{
+			GKEAcceleratorNodeLabelKey: *pool.Spec.GPUType,
+		}
+		if podSpec.Containers[0].Resources.Limits == nil {
+			podSpec.Containers[0].Resources.Limits = v1.ResourceList{}
+		}
+		if _, ok := podSpec.Containers[0].Resources.Limits[constants.NvidiaGPUResourceType]; !ok {
+			podSpec.Containers[0].Resources.Limits[constants.NvidiaGPUResourceType] = resource.MustParse("1")
+		}
+	}
+
+	replicas := pool.Spec.Replicas
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.WarmPoolDeploymentName(pool.Name),
+			Namespace: pool.Namespace,
+			Labels: map[string]string{
+				constants.WarmPoolLabelKey: pool.Name,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: poolLabels(pool),
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      poolLabels(pool),
+					Annotations: annotations,
+				},
+				Spec: podSpec,
+			},
+		},
+	}, nil
+}
+
+func createAgentContainer(modelServer *v1.Container, agentConfig *AgentConfig) v1.Container {
+	return v1.Container{
+		Name:  constants.WarmPoolAgentContainerName,
+		Image: agentConfig.Image,
+		Args: []string{
+			"--config-dir", constants.WarmPoolModelConfigMount,
+			"--config-file", constants.WarmPoolModelConfigFileName("$(" + PodNameEnvVarKey + ")"),
+			"--model-dir", constants.DefaultModelLocalMountPath,
+			"--model-server-url", fmt.Sprintf("http://localhost:%d", modelServerPort(modelServer)),
+			"--drain-timeout", strconv.Itoa(drainTimeout(agentConfig)),
+			"--metadata-port", strconv.Itoa(AgentMetadataPort),
+		},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "metadata",
+				ContainerPort: AgentMetadataPort,
+				Protocol:      v1.ProtocolTCP,
+			},
+		},
+		Env: []v1.EnvVar{
+			{
+				Name: PodNameEnvVarKey,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+			// The namespace enables the updates of the metadata of the TrainedModel status
+			{
+				Name: PodNamespaceEnvVarKey,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{
+						FieldPath: "metadata.namespace",
+					},
+				},
+			},
+		},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(agentConfig.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(agentConfig.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(agentConfig.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(agentConfig.MemoryRequest),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      ModelVolumeName,
+				MountPath: constants.DefaultModelLocalMountPath,
+			},
+			{
+				Name:      ModelConfigVolumeName,
+				MountPath: constants.WarmPoolModelConfigMount,
+				ReadOnly:  true,
+			},
+		},
+	}
+}
+
+// setShutdownOrdering keeps the model server running on scale down until the agent has unloaded the models. A
+// lifecycle set by the runtime is left as is.
+func setShutdownOrdering(podSpec *v1.PodSpec, agentConfig *AgentConfig) {
+	timeout := drainTimeout(agentConfig)
+	if server := &podSpec.Containers[0]; server.Lifecycle == nil {
+		server.Lifecycle = &v1.Lifecycle{
+			PreStop: &v1.Handler{
+				Exec: &v1.ExecAction{Command: []string{"sleep", strconv.Itoa(timeout)}},
+			},
+		}
+	}
+	gracePeriod := int64(timeout + DefaultTerminationGracePeriodSeconds)
+	podSpec.TerminationGracePeriodSeconds = &gracePeriod
+}
+
+func drainTimeout(agentConfig *AgentConfig) int {
+	if agentConfig.DrainTimeoutSeconds > 0 {
+		return agentConfig.DrainTimeoutSeconds
+	}
+	return DefaultDrainTimeoutSeconds
+}
+
+// createClaimService builds the service routing to the pod claimed by the inference service
+func createClaimService(pool *v1beta1.WarmPool, isvc string, modelServer *v1.Container) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.WarmPoolClaimServiceName(isvc),
+			Namespace: pool.Namespace,
+			Labels: map[string]string{
+				constants.WarmPoolLabelKey: pool.Name,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{
+				constants.WarmPoolLabelKey:      pool.Name,
+				constants.WarmPoolClaimLabelKey: isvc,
+			},
+			Ports: []v1.ServicePort{
+				{
+					Name:       "http",
+					Port:       constants.CommonDefaultHttpPort,
+					TargetPort: intstr.FromInt(int(modelServerPort(modelServer))),
+				},
+			},
+		},
+	}
+}
+
+// modelServerPort returns the first port of the model server container, the default http port when not set
+func modelServerPort(modelServer *v1.Container) int32 {
+	if len(modelServer.Ports) != 0 {
+		return modelServer.Ports[0].ContainerPort
+	}
+	port, _ := strconv.Atoi(constants.InferenceServiceDefaultHttpPort)
+	return int32(port)
+}
+
+// isPodReady returns true if the pod is running and ready
+func isPodReady(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// createAgentRole returns the Role and the RoleBinding granting the service account of the pods of the pool the
+// updates of the TrainedModel status by the agent
+func createAgentRole(pool *v1beta1.WarmPool) (*rbacv1.Role, *rbacv1.RoleBinding) {
+	meta := metav1.ObjectMeta{
+		Name:      constants.WarmPoolAgentRoleName(pool.Name),
+		Namespace: pool.Namespace,
+		Labels: map[string]string{
+			constants.WarmPoolLabelKey: pool.Name,
+		},
+	}
+	role := &rbacv1.Role{
+		ObjectMeta: *meta.DeepCopy(),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{v1beta1.SchemeGroupVersion.Group},
+				Resources: []string{"trainedmodels"},
+				Verbs:     []string{"get"},
+			},
+			{
+				APIGroups: []string{v1beta1.SchemeGroupVersion.Group},
+				Resources: []string{"trainedmodels/status"},
+				Verbs:     []string{"patch"},
+			},
+		},
+	}
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: *meta.DeepCopy(),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     meta.Name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      DefaultServiceAccountName,
+				Namespace: pool.Namespace,
+			},
+		},
+	}
+	return role, roleBinding
+}
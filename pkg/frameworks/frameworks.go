@@ -0,0 +1,220 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package frameworks exports the ports, probe paths, protocol and metrics endpoints the controller assumes for the
+// model servers of each predictor framework, so transformers, CLIs and gateways can stay consistent with it.
+package frameworks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+// Framework identifies a predictor framework, the values match the keys of the predictors config
+type Framework string
+
+// Framework Enum
+const (
+	SKLearn    Framework = "sklearn"
+	XGBoost    Framework = "xgboost"
+	LightGBM   Framework = "lightgbm"
+	Tensorflow Framework = "tensorflow"
+	PyTorch    Framework = "pytorch"
+	Triton     Framework = "triton"
+	ONNX       Framework = "onnx"
+	PMML       Framework = "pmml"
+	Paddle     Framework = "paddle"
+	MLflow     Framework = "mlflow"
+)
+
+// Defaults describes how the model server of a framework is reached
+type Defaults struct {
+	// Port serving the REST API, this is the port receiving the traffic from the queue proxy
+	HTTPPort int32
+	// Port serving the gRPC API, 0 if the model server does not serve gRPC
+	GRPCPort int32
+	// Protocol served by default, some frameworks can be configured to serve another protocol
+	Protocol constants.InferenceServiceProtocol
+	// Path returning 200 when the server is alive, the model name is formatted in when the path contains %s.
+	// Empty if the model server does not expose a liveness endpoint.
+	LivenessPathFormat string
+	// Format of the path returning 200 when a model is ready, the model name is the only argument.
+	// Empty if the model server does not expose a readiness endpoint.
+	ReadinessPathFormat string
+	// Port serving the model server metrics, 0 if the model server does not expose metrics
+	MetricsPort int32
+	// Path serving the model server metrics
+	MetricsPath string
+}
+
+// LivenessPath returns the path returning 200 when the server of the model is alive, empty if not supported
+func (d Defaults) LivenessPath(modelName string) string {
+	if !strings.Contains(d.LivenessPathFormat, "%s") {
+		return d.LivenessPathFormat
+	}
+	return fmt.Sprintf(d.LivenessPathFormat, modelName)
+}
+
+// ReadinessPath returns the path returning 200 when the model is ready, empty if not supported
+func (d Defaults) ReadinessPath(modelName string) string {
+	if d.ReadinessPathFormat == "" {
+		return ""
+	}
+	return fmt.Sprintf(d.ReadinessPathFormat, modelName)
+}
+
+const (
+	v1LivenessPath       = "/"
+	v1ReadinessFormat    = "/v1/models/%s"
+	v2LivenessPath       = "/v2/health/live"
+	v2ReadinessFormat    = "/v2/models/%s/ready"
+	prometheusPath       = "/metrics"
+	tritonMetricsPort    = 8002
+	torchServeMetricPort = 8082
+)
+
+// kfserverDefaults are the defaults of the model servers built on the kfserving python server
+var kfserverDefaults = Defaults{
+	HTTPPort:            port(constants.InferenceServiceDefaultHttpPort),
+	Protocol:            constants.ProtocolV1,
+	LivenessPathFormat:  v1LivenessPath,
+	ReadinessPathFormat: v1ReadinessFormat,
+}
+
+var registry = map[Framework]Defaults{
+	SKLearn:  kfserverDefaults,
+	XGBoost:  kfserverDefaults,
+	LightGBM: kfserverDefaults,
+	PMML:     kfserverDefaults,
+	Paddle:   kfserverDefaults,
+	// TF Serving does not expose a server health endpoint, the model status endpoint is probed for the liveness
+	Tensorflow: {
+		HTTPPort:            port(v1beta1.TensorflowServingRestPort),
+		GRPCPort:            port(v1beta1.TensorflowServingGRPCPort),
+		Protocol:            constants.ProtocolV1,
+		LivenessPathFormat:  v1ReadinessFormat,
+		ReadinessPathFormat: v1ReadinessFormat,
+	},
+	// TorchServe is fronted by the kfserving wrapper translating the KFServing protocols
+	PyTorch: {
+		HTTPPort:            port(constants.InferenceServiceDefaultHttpPort),
+		Protocol:            constants.ProtocolV1,
+		LivenessPathFormat:  v1LivenessPath,
+		ReadinessPathFormat: v1ReadinessFormat,
+		MetricsPort:         torchServeMetricPort,
+		MetricsPath:         prometheusPath,
+	},
+	Triton: {
+		HTTPPort:            v1beta1.TritonISRestPort,
+		GRPCPort:            v1beta1.TritonISGRPCPort,
+		Protocol:            constants.ProtocolV2,
+		LivenessPathFormat:  v2LivenessPath,
+		ReadinessPathFormat: v2ReadinessFormat,
+		MetricsPort:         tritonMetricsPort,
+		MetricsPath:         prometheusPath,
+	},
+	// ONNX Runtime server does not expose health endpoints
+	ONNX: {
+		HTTPPort: port(v1beta1.ONNXServingRestPort),
+		GRPCPort: port(v1beta1.ONNXServingGRPCPort),
+		Protocol: constants.ProtocolV1,
+	},
+	// MLflow models are served by MLServer, the native flavors are served with the defaults of their framework
+	MLflow: {
+		HTTPPort:            port(constants.InferenceServiceDefaultHttpPort),
+		GRPCPort:            port(v1beta1.MLServerGRPCPort),
+		Protocol:            constants.ProtocolV2,
+		LivenessPathFormat:  v2LivenessPath,
+		ReadinessPathFormat: v2ReadinessFormat,
+	},
+}
+
+// Get returns the defaults of the framework
+func Get(framework Framework) (Defaults, bool) {
+	defaults, ok := registry[framework]
+	return defaults, ok
+}
+
+// List returns the frameworks of the registry sorted by name
+func List() []Framework {
+	frameworks := make([]Framework, 0, len(registry))
+	for framework := range registry {
+		frameworks = append(frameworks, framework)
+	}
+	sort.Slice(frameworks, func(i, j int) bool {
+		return frameworks[i] < frameworks[j]
+	})
+	return frameworks
+}
+
+// ForPredictor returns the framework of the predictor, false for custom predictors and the model formats
+// served by runtimes outside of the registry
+func ForPredictor(predictor *v1beta1.PredictorSpec) (Framework, bool) {
+	switch {
+	case predictor.SKLearn != nil:
+		return SKLearn, true
+	case predictor.XGBoost != nil:
+		return XGBoost, true
+	case predictor.LightGBM != nil:
+		return LightGBM, true
+	case predictor.Tensorflow != nil:
+		return Tensorflow, true
+	case predictor.PyTorch != nil:
+		return PyTorch, true
+	case predictor.Triton != nil:
+		return Triton, true
+	case predictor.ONNX != nil:
+		return ONNX, true
+	case predictor.PMML != nil:
+		return PMML, true
+	case predictor.Paddle != nil:
+		return Paddle, true
+	case predictor.MLflow != nil:
+		return mlflowFramework(predictor.MLflow), true
+	case predictor.Model != nil:
+		framework := Framework(strings.ToLower(predictor.Model.ModelFormat.Name))
+		_, ok := registry[framework]
+		return framework, ok
+	}
+	return "", false
+}
+
+// mlflowFramework returns the framework serving the flavor of the MLflow model
+func mlflowFramework(mlflow *v1beta1.MLflowSpec) Framework {
+	if mlflow.Flavor == nil {
+		return MLflow
+	}
+	switch *mlflow.Flavor {
+	case v1beta1.MLflowSKLearnFlavor:
+		return SKLearn
+	case v1beta1.MLflowONNXFlavor:
+		return ONNX
+	}
+	return MLflow
+}
+
+func port(value string) int32 {
+	p, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		panic(fmt.Sprintf("invalid port %q: %v", value, err))
+	}
+	return int32(p)
+}
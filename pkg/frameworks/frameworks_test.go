@@ -0,0 +1,147 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworks
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+)
+
+func TestGet(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		framework         Framework
+		expectedFound     bool
+		expectedHTTPPort  int32
+		expectedGRPCPort  int32
+		expectedProtocol  constants.InferenceServiceProtocol
+		expectedLiveness  string
+		expectedReadiness string
+	}{
+		"SKLearn": {
+			framework:         SKLearn,
+			expectedFound:     true,
+			expectedLiveness:  "/",
+			expectedHTTPPort:  8080,
+			expectedProtocol:  constants.ProtocolV1,
+			expectedReadiness: "/v1/models/my-model",
+		},
+		"Tensorflow": {
+			framework:         Tensorflow,
+			expectedFound:     true,
+			expectedLiveness:  "/v1/models/my-model",
+			expectedHTTPPort:  8080,
+			expectedGRPCPort:  9000,
+			expectedProtocol:  constants.ProtocolV1,
+			expectedReadiness: "/v1/models/my-model",
+		},
+		"Triton": {
+			framework:         Triton,
+			expectedFound:     true,
+			expectedLiveness:  "/v2/health/live",
+			expectedHTTPPort:  8080,
+			expectedGRPCPort:  9000,
+			expectedProtocol:  constants.ProtocolV2,
+			expectedReadiness: "/v2/models/my-model/ready",
+		},
+		"ONNX": {
+			framework:        ONNX,
+			expectedFound:    true,
+			expectedHTTPPort: 8080,
+			expectedGRPCPort: 9000,
+			expectedProtocol: constants.ProtocolV1,
+		},
+		"Unknown": {
+			framework:     Framework("caffe"),
+			expectedFound: false,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			defaults, found := Get(scenario.framework)
+			g.Expect(found).To(gomega.Equal(scenario.expectedFound))
+			g.Expect(defaults.HTTPPort).To(gomega.Equal(scenario.expectedHTTPPort))
+			g.Expect(defaults.GRPCPort).To(gomega.Equal(scenario.expectedGRPCPort))
+			g.Expect(defaults.Protocol).To(gomega.Equal(scenario.expectedProtocol))
+			g.Expect(defaults.LivenessPath("my-model")).To(gomega.Equal(scenario.expectedLiveness))
+			g.Expect(defaults.ReadinessPath("my-model")).To(gomega.Equal(scenario.expectedReadiness))
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(List()).To(gomega.Equal([]Framework{
+		LightGBM, MLflow, ONNX, Paddle, PMML, PyTorch, SKLearn, Tensorflow, Triton, XGBoost,
+	}))
+}
+
+func TestForPredictor(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	sklearnFlavor := v1beta1.MLflowSKLearnFlavor
+	scenarios := map[string]struct {
+		predictor         v1beta1.PredictorSpec
+		expectedFramework Framework
+		expectedFound     bool
+	}{
+		"XGBoost": {
+			predictor:         v1beta1.PredictorSpec{XGBoost: &v1beta1.XGBoostSpec{}},
+			expectedFramework: XGBoost,
+			expectedFound:     true,
+		},
+		"MLflowPyFunc": {
+			predictor:         v1beta1.PredictorSpec{MLflow: &v1beta1.MLflowSpec{}},
+			expectedFramework: MLflow,
+			expectedFound:     true,
+		},
+		"MLflowSKLearnFlavor": {
+			predictor:         v1beta1.PredictorSpec{MLflow: &v1beta1.MLflowSpec{Flavor: &sklearnFlavor}},
+			expectedFramework: SKLearn,
+			expectedFound:     true,
+		},
+		"ModelFormat": {
+			predictor: v1beta1.PredictorSpec{Model: &v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "TensorFlow"},
+			}},
+			expectedFramework: Tensorflow,
+			expectedFound:     true,
+		},
+		"UnknownModelFormat": {
+			predictor: v1beta1.PredictorSpec{Model: &v1beta1.ModelPredictorSpec{
+				ModelFormat: v1beta1.ModelFormat{Name: "caffe"},
+			}},
+			expectedFramework: Framework("caffe"),
+			expectedFound:     false,
+		},
+		"CustomPredictor": {
+			predictor:     v1beta1.PredictorSpec{},
+			expectedFound: false,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			framework, found := ForPredictor(&scenario.predictor)
+			g.Expect(found).To(gomega.Equal(scenario.expectedFound))
+			g.Expect(framework).To(gomega.Equal(scenario.expectedFramework))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// InformerGetter gets the informers of the objects, implemented by the cache of the manager
+type InformerGetter interface {
+	GetInformer(obj runtime.Object) (cache.Informer, error)
+}
+
+// Informers returns a checker failing until the informers of the objects watched by a reconciler have synced. The
+// informers are got when the checker is built, before the manager starts, as getting an informer of a started cache
+// blocks until it has synced.
+func Informers(getter InformerGetter, objs ...runtime.Object) (healthz.Checker, error) {
+	informers := make([]cache.Informer, len(objs))
+	for i, obj := range objs {
+		informer, err := getter.GetInformer(obj)
+		if err != nil {
+			return nil, err
+		}
+		informers[i] = informer
+	}
+	return func(_ *http.Request) error {
+		for i, informer := range informers {
+			if !informer.HasSynced() {
+				return fmt.Errorf("the informer of %T has not synced", objs[i])
+			}
+		}
+		return nil
+	}, nil
+}
+
+// Webhook returns a checker failing when the webhook server at the address does not complete a TLS handshake within
+// the timeout
+func Webhook(addr string, timeout time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		// The certificate is issued for the webhook service, only the handshake is checked
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, &tls.Config{InsecureSkipVerify: true}) // #nosec G402
+		if err != nil {
+			return fmt.Errorf("the webhook server is not serving: %v", err)
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type fakeInformer struct {
+	cache.Informer
+	synced bool
+}
+
+func (i *fakeInformer) HasSynced() bool {
+	return i.synced
+}
+
+type fakeInformers map[string]*fakeInformer
+
+func (f fakeInformers) GetInformer(obj runtime.Object) (cache.Informer, error) {
+	informer, ok := f[fmt.Sprintf("%T", obj)]
+	if !ok {
+		return nil, fmt.Errorf("no informer of %T", obj)
+	}
+	return informer, nil
+}
+
+func TestInformers(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvcs := &fakeInformer{synced: true}
+	secrets := &fakeInformer{}
+	informers := fakeInformers{
+		"*v1beta1.InferenceService": isvcs,
+		"*v1.Secret":                secrets,
+	}
+
+	check, err := Informers(informers, &v1beta1.InferenceService{}, &v1.Secret{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(check(nil)).To(gomega.MatchError("the informer of *v1.Secret has not synced"))
+	secrets.synced = true
+	g.Expect(check(nil)).To(gomega.Succeed())
+
+	_, err = Informers(informers, &v1.ConfigMap{})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestWebhook(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	addr := server.Listener.Addr().String()
+
+	check := Webhook(addr, time.Second)
+	g.Expect(check(nil)).To(gomega.Succeed())
+	server.Close()
+	g.Expect(check(nil)).To(gomega.HaveOccurred())
+}
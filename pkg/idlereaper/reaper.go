@@ -0,0 +1,218 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idlereaper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/servingmetrics"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the events recorded on the idle InferenceServices
+const (
+	IdleActionPendingReason = "IdleActionPending"
+	IdleScaleToZeroReason   = "IdleScaleToZero"
+	IdleSuspendReason       = "IdleSuspend"
+	IdleDeleteReason        = "IdleDelete"
+	IdleFailedReason        = "IdleActionFailed"
+)
+
+// Reaper periodically acts on the InferenceServices which received no request for the TTL of their
+// serving.kubeflow.org/ttl-after-last-request annotation: it scales them to zero, suspends or deletes them as set by
+// their serving.kubeflow.org/idle-action annotation. The time of the last request is tracked in the status from the
+// request count of the Knative queue-proxy sidecars, it starts when the annotation is set or the InferenceService is
+// resumed. A warning event announces the action an interval before the TTL, the action is only taken on a later
+// check.
+type Reaper struct {
+	Client   client.Client
+	Querier  servingmetrics.Querier
+	Recorder record.EventRecorder
+	// Interval between the checks, the InferenceServices are acted on up to an interval after their TTL
+	Interval time.Duration
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	Log   logr.Logger
+}
+
+// Start checks the InferenceServices on start then every interval until the manager stops
+func (r *Reaper) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Reap(context.TODO(), time.Now()); err != nil {
+			r.Log.Error(err, "Failed to reap the idle inference services")
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap acts on the InferenceServices idle for longer than their TTL at the time, the InferenceServices whose requests
+// can not be queried are retried on the next check
+func (r *Reaper) Reap(ctx context.Context, now time.Time) error {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := r.Client.List(ctx, isvcs); err != nil {
+		return errors.Wrapf(err, "fails to list inference services")
+	}
+	for i := range isvcs.Items {
+		isvc := &isvcs.Items[i]
+		if isvc.DeletionTimestamp != nil {
+			continue
+		}
+		if owned, err := r.Shard.Owns(isvc.Namespace); !owned {
+			if err != nil {
+				r.Log.Error(err, "Failed to check the shard", "namespace", isvc.Namespace, "name", isvc.Name)
+			}
+			continue
+		}
+		if err := r.reap(ctx, isvc, now); err != nil {
+			r.Log.Error(err, "Failed to reap the idle inference service", "namespace", isvc.Namespace,
+				"name", isvc.Name)
+		}
+	}
+	return nil
+}
+
+// reap updates the time of the last request of the InferenceService, announces the idle action an interval before the
+// TTL, then takes it on a later check once the InferenceService is idle for longer than the TTL
+func (r *Reaper) reap(ctx context.Context, isvc *v1beta1.InferenceService, now time.Time) error {
+	ttl, err := isvc.GetIdleTTL()
+	if err != nil {
+		return err
+	}
+	lastRequestTime := isvc.Status.LastRequestTime
+	if ttl == nil || isvc.IsIdleActionTaken() {
+		// The tracking restarts once the annotation is set again or the InferenceService is resumed
+		if lastRequestTime == nil && isvc.Status.IdleActionPendingTime == nil {
+			return nil
+		}
+		return r.setIdleStatus(ctx, isvc, nil, nil)
+	}
+	if lastRequestTime == nil {
+		return r.setIdleStatus(ctx, isvc, &metav1.Time{Time: now}, nil)
+	}
+	idle := now.Sub(lastRequestTime.Time)
+	if idle < time.Second {
+		return nil
+	}
+	requests, ok, err := servingmetrics.QueryRequestCount(ctx, r.Querier, requestSelector(isvc), idle)
+	if err != nil || !ok {
+		// Without a sample the requests are unknown, no action is taken
+		return err
+	}
+	if requests > 0 {
+		return r.setIdleStatus(ctx, isvc, &metav1.Time{Time: now}, nil)
+	}
+	if idle < *ttl-r.Interval {
+		return nil
+	}
+	pendingTime := isvc.Status.IdleActionPendingTime
+	if pendingTime == nil {
+		return r.announce(ctx, isvc, idle.Truncate(time.Second), *ttl-idle, now)
+	}
+	// The action is taken on a later check than the announcement, even when the TTL was lowered since
+	if idle < *ttl || !pendingTime.Time.Before(now) {
+		return nil
+	}
+	return r.act(ctx, isvc, idle.Truncate(time.Second))
+}
+
+// announce records a warning event with the idle action about to be taken and the time it is recorded at in the
+// status
+func (r *Reaper) announce(ctx context.Context, isvc *v1beta1.InferenceService, idle time.Duration,
+	remaining time.Duration, now time.Time) error {
+	if remaining < r.Interval {
+		remaining = r.Interval
+	}
+	r.Log.Info("Announcing the idle action", "namespace", isvc.Namespace, "name", isvc.Name,
+		"action", isvc.GetIdleAction(), "idle", idle)
+	r.Recorder.Eventf(isvc, v1.EventTypeWarning, IdleActionPendingReason,
+		"No request for %s, the %s idle action is taken in %s unless the inference service is requested", idle,
+		isvc.GetIdleAction(), remaining.Round(time.Second))
+	return r.setIdleStatus(ctx, isvc, isvc.Status.LastRequestTime, &metav1.Time{Time: now})
+}
+
+// act takes the idle action of the InferenceService, the event is recorded before the action
+func (r *Reaper) act(ctx context.Context, isvc *v1beta1.InferenceService, idle time.Duration) error {
+	action := isvc.GetIdleAction()
+	r.Log.Info("Acting on the idle inference service", "namespace", isvc.Namespace, "name", isvc.Name,
+		"action", action, "idle", idle)
+	var err error
+	switch action {
+	case v1beta1.IdleActionScaleToZero:
+		r.Recorder.Eventf(isvc, v1.EventTypeNormal, IdleScaleToZeroReason,
+			"No request for %s, scaling the inference service to zero", idle)
+		patched := isvc.DeepCopy()
+		patched.ScaleToZero()
+		err = r.Client.Patch(ctx, patched, client.MergeFrom(isvc))
+	case v1beta1.IdleActionSuspend:
+		r.Recorder.Eventf(isvc, v1.EventTypeNormal, IdleSuspendReason,
+			"No request for %s, suspending the inference service", idle)
+		patched := isvc.DeepCopy()
+		patched.Spec.Suspend = true
+		err = r.Client.Patch(ctx, patched, client.MergeFrom(isvc))
+	case v1beta1.IdleActionDelete:
+		r.Recorder.Eventf(isvc, v1.EventTypeNormal, IdleDeleteReason,
+			"No request for %s, deleting the inference service", idle)
+		err = r.Client.Delete(ctx, isvc)
+	default:
+		err = fmt.Errorf(v1beta1.InvalidIdleActionAnnotationError, constants.IdleActionAnnotationKey, action)
+	}
+	if err != nil {
+		r.Recorder.Eventf(isvc, v1.EventTypeWarning, IdleFailedReason, "Failed to %s the idle inference service: %s",
+			action, err)
+	}
+	return err
+}
+
+// setIdleStatus patches the time of the last request and the time the idle action was announced at in the status of
+// the InferenceService
+func (r *Reaper) setIdleStatus(ctx context.Context, isvc *v1beta1.InferenceService, lastRequestTime *metav1.Time,
+	pendingTime *metav1.Time) error {
+	patched := isvc.DeepCopy()
+	patched.Status.LastRequestTime = lastRequestTime
+	patched.Status.IdleActionPendingTime = pendingTime
+	return r.Client.Status().Patch(ctx, patched, client.MergeFrom(isvc))
+}
+
+// requestSelector selects the queue-proxy metrics of all the components of the InferenceService, the requests of the
+// explainer are counted along the predictions
+func requestSelector(isvc *v1beta1.InferenceService) string {
+	services := []string{constants.DefaultPredictorServiceName(isvc.Name)}
+	if isvc.Spec.Transformer != nil {
+		services = append(services, constants.DefaultTransformerServiceName(isvc.Name))
+	}
+	if isvc.Spec.Explainer != nil {
+		services = append(services, constants.DefaultExplainerServiceName(isvc.Name))
+	}
+	return fmt.Sprintf(`namespace_name=%q,service_name=~%q`, isvc.Namespace, strings.Join(services, "|"))
+}
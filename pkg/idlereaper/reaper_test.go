@@ -0,0 +1,218 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idlereaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// fakeQuerier answers the queries with their values
+type fakeQuerier map[string]float64
+
+func (q fakeQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	value, ok := q[query]
+	return value, ok, nil
+}
+
+func TestReap(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	now := time.Date(2020, 10, 15, 12, 0, 0, 0, time.UTC)
+	twoHoursAgo := &metav1.Time{Time: now.Add(-2 * time.Hour)}
+	fiftyFiveMinutesAgo := &metav1.Time{Time: now.Add(-55 * time.Minute)}
+	aMinuteAgo := &metav1.Time{Time: now.Add(-time.Minute)}
+	makeInferenceService := func(annotations map[string]string, lastRequestTime *metav1.Time) *v1beta1.InferenceService {
+		return &v1beta1.InferenceService{
+			ObjectMeta: metav1.ObjectMeta{Name: "flowers", Namespace: "default", Annotations: annotations},
+			Spec: v1beta1.InferenceServiceSpec{
+				Transformer: &v1beta1.TransformerSpec{},
+			},
+			Status: v1beta1.InferenceServiceStatus{LastRequestTime: lastRequestTime},
+		}
+	}
+	// announced returns the inference service whose idle action was announced at the pending time
+	announced := func(isvc *v1beta1.InferenceService, pendingTime *metav1.Time) *v1beta1.InferenceService {
+		isvc.Status.IdleActionPendingTime = pendingTime
+		return isvc
+	}
+	ttl := func(action v1beta1.IdleAction) map[string]string {
+		return map[string]string{
+			constants.IdleTTLAnnotationKey:    "1h",
+			constants.IdleActionAnnotationKey: string(action),
+		}
+	}
+	query := func(window string) string {
+		return `sum(increase(revision_request_count{namespace_name="default",` +
+			`service_name=~"flowers-predictor-default|flowers-transformer-default"}[` + window + `]))`
+	}
+	requests := fakeQuerier{query("120m"): 3}
+	noRequest := fakeQuerier{query("120m"): 0, query("55m"): 0}
+	scenarios := map[string]struct {
+		isvc    *v1beta1.InferenceService
+		querier fakeQuerier
+		events  int
+		// event is the beginning of the recorded event
+		event string
+		// expected is nil when the inference service is deleted
+		expected func(isvc *v1beta1.InferenceService)
+	}{
+		"NoTTL": {
+			isvc:    makeInferenceService(nil, nil),
+			querier: fakeQuerier{},
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime).To(gomega.BeNil())
+			},
+		},
+		"TrackingStarts": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionSuspend), nil),
+			querier: fakeQuerier{},
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime.Time.Equal(now)).To(gomega.BeTrue())
+			},
+		},
+		"Requested": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionSuspend), twoHoursAgo),
+			querier: requests,
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime.Time.Equal(now)).To(gomega.BeTrue())
+				g.Expect(isvc.Spec.Suspend).To(gomega.BeFalse())
+			},
+		},
+		"WithinTTL": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionSuspend), &metav1.Time{Time: now.Add(-30 * time.Minute)}),
+			querier: fakeQuerier{},
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime.Time.Equal(now.Add(-30 * time.Minute))).To(gomega.BeTrue())
+				g.Expect(isvc.Spec.Suspend).To(gomega.BeFalse())
+			},
+		},
+		"WithinInterval": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionDelete), fiftyFiveMinutesAgo),
+			querier: noRequest,
+			events:  1,
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.IdleActionPendingTime.Time.Equal(now)).To(gomega.BeTrue())
+			},
+		},
+		"IdleAnnounced": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionSuspend), twoHoursAgo),
+			querier: noRequest,
+			events:  1,
+			event:   "Warning IdleActionPending No request for 2h0m0s, the Suspend idle action is taken in 10m0s",
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.IdleActionPendingTime.Time.Equal(now)).To(gomega.BeTrue())
+				g.Expect(isvc.Spec.Suspend).To(gomega.BeFalse())
+			},
+		},
+		"AnnouncedWithinTTL": {
+			isvc:    announced(makeInferenceService(ttl(v1beta1.IdleActionDelete), fiftyFiveMinutesAgo), aMinuteAgo),
+			querier: noRequest,
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.IdleActionPendingTime.Time.Equal(aMinuteAgo.Time)).To(gomega.BeTrue())
+			},
+		},
+		"RequestedAfterAnnouncement": {
+			isvc:    announced(makeInferenceService(ttl(v1beta1.IdleActionDelete), twoHoursAgo), aMinuteAgo),
+			querier: requests,
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime.Time.Equal(now)).To(gomega.BeTrue())
+				g.Expect(isvc.Status.IdleActionPendingTime).To(gomega.BeNil())
+			},
+		},
+		"IdleSuspended": {
+			isvc: announced(makeInferenceService(map[string]string{constants.IdleTTLAnnotationKey: "1h"}, twoHoursAgo),
+				aMinuteAgo),
+			querier: noRequest,
+			events:  1,
+			event:   "Normal IdleSuspend No request for 2h0m0s, suspending the inference service",
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Spec.Suspend).To(gomega.BeTrue())
+			},
+		},
+		"IdleScaledToZero": {
+			isvc:    announced(makeInferenceService(ttl(v1beta1.IdleActionScaleToZero), twoHoursAgo), aMinuteAgo),
+			querier: noRequest,
+			events:  1,
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Spec.Suspend).To(gomega.BeFalse())
+				g.Expect(*isvc.Spec.Predictor.MinReplicas).To(gomega.Equal(0))
+				g.Expect(*isvc.Spec.Transformer.MinReplicas).To(gomega.Equal(0))
+			},
+		},
+		"IdleDeleted": {
+			isvc:    announced(makeInferenceService(ttl(v1beta1.IdleActionDelete), twoHoursAgo), aMinuteAgo),
+			querier: noRequest,
+			events:  1,
+		},
+		"NoSample": {
+			isvc:    makeInferenceService(ttl(v1beta1.IdleActionDelete), twoHoursAgo),
+			querier: fakeQuerier{},
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime.Time.Equal(twoHoursAgo.Time)).To(gomega.BeTrue())
+			},
+		},
+		"TrackingStopsWhileSuspended": {
+			isvc: func() *v1beta1.InferenceService {
+				isvc := announced(makeInferenceService(ttl(v1beta1.IdleActionSuspend), twoHoursAgo), aMinuteAgo)
+				isvc.Spec.Suspend = true
+				return isvc
+			}(),
+			querier: fakeQuerier{},
+			expected: func(isvc *v1beta1.InferenceService) {
+				g.Expect(isvc.Status.LastRequestTime).To(gomega.BeNil())
+				g.Expect(isvc.Status.IdleActionPendingTime).To(gomega.BeNil())
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			cl := fake.NewFakeClientWithScheme(scheme, scenario.isvc)
+			recorder := record.NewFakeRecorder(10)
+			reaper := &Reaper{Client: cl, Querier: scenario.querier, Recorder: recorder, Interval: 10 * time.Minute,
+				Log: logf.Log}
+			g.Expect(reaper.Reap(context.TODO(), now)).To(gomega.Succeed())
+			g.Expect(recorder.Events).To(gomega.HaveLen(scenario.events))
+			if scenario.event != "" {
+				g.Expect(<-recorder.Events).To(gomega.HavePrefix(scenario.event))
+			}
+
+			isvc := &v1beta1.InferenceService{}
+			err := cl.Get(context.TODO(), types.NamespacedName{Name: "flowers", Namespace: "default"}, isvc)
+			if scenario.expected == nil {
+				g.Expect(errors.IsNotFound(err)).To(gomega.BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			scenario.expected(isvc)
+		})
+	}
+}
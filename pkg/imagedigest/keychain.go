@@ -0,0 +1,117 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedigest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "k8s.io/api/core/v1"
+)
+
+// dockerConfigEntry is the credential of a registry in a docker config
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// dockerConfigJSON is the content of the .dockerconfigjson key of a kubernetes.io/dockerconfigjson secret
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// secretKeychain resolves the credentials of the registries from the image pull secrets, by registry host
+type secretKeychain struct {
+	credentials map[string]authn.Authenticator
+}
+
+// newSecretKeychain reads the credentials of the kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg secrets,
+// the credentials of the first secret referencing a registry are used like the kubelet does. The secrets of other
+// types and the invalid entries are skipped.
+func newSecretKeychain(secrets []v1.Secret) authn.Keychain {
+	keychain := &secretKeychain{credentials: map[string]authn.Authenticator{}}
+	for _, secret := range secrets {
+		var entries map[string]dockerConfigEntry
+		switch secret.Type {
+		case v1.SecretTypeDockerConfigJson:
+			config := dockerConfigJSON{}
+			if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+				continue
+			}
+			entries = config.Auths
+		case v1.SecretTypeDockercfg:
+			if err := json.Unmarshal(secret.Data[v1.DockerConfigKey], &entries); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+		for server, entry := range entries {
+			host := registryHost(server)
+			if _, ok := keychain.credentials[host]; ok {
+				continue
+			}
+			if authenticator := entry.authenticator(); authenticator != nil {
+				keychain.credentials[host] = authenticator
+			}
+		}
+	}
+	return keychain
+}
+
+// Resolve returns the credential of the registry of the resource, anonymous when no secret references it
+func (k *secretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	if authenticator, ok := k.credentials[resource.RegistryStr()]; ok {
+		return authenticator, nil
+	}
+	return authn.Anonymous, nil
+}
+
+func (e dockerConfigEntry) authenticator() authn.Authenticator {
+	if e.Username != "" || e.Password != "" {
+		return &authn.Basic{Username: e.Username, Password: e.Password}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(e.Auth)
+	if err != nil {
+		return nil
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return &authn.Basic{Username: parts[0], Password: parts[1]}
+}
+
+// registryHost returns the host of the registry of a docker config entry, e.g. registry.example.com for
+// https://registry.example.com/v1/. The Docker Hub entries are mapped to the host of its registry API.
+func registryHost(server string) string {
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	} else if i := strings.Index(server, "/"); i >= 0 {
+		host = server[:i]
+	}
+	if host == "docker.io" || host == "registry-1.docker.io" {
+		return name.DefaultRegistry
+	}
+	return host
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedigest
+
+import (
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSecretKeychain(t *testing.T) {
+	keychain := newSecretKeychain([]v1.Secret{
+		{
+			Type: v1.SecretTypeDockercfg,
+			Data: map[string][]byte{
+				v1.DockerConfigKey: []byte(`{"https://index.docker.io/v1/": {"username": "hub", "password": "first"}}`),
+			},
+		},
+		{
+			Type: v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				v1.DockerConfigJsonKey: []byte(`{"auths": {"docker.io": {"username": "hub", "password": "second"},
+					"https://gcr.io": {"username": "_json_key", "password": "key"},
+					"quay.io": {"auth": "not base64"}}}`),
+			},
+		},
+		{
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				v1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"username": "robot", "password": "opaque"}}}`),
+			},
+		},
+	})
+
+	scenarios := map[string]struct {
+		registry string
+		expected authn.Authenticator
+	}{
+		"FirstSecretWins": {
+			registry: name.DefaultRegistry,
+			expected: &authn.Basic{Username: "hub", Password: "first"},
+		},
+		"SchemeStripped": {
+			registry: "gcr.io",
+			expected: &authn.Basic{Username: "_json_key", Password: "key"},
+		},
+		"InvalidAuthSkipped": {
+			registry: "quay.io",
+			expected: authn.Anonymous,
+		},
+		"OpaqueSecretSkipped": {
+			registry: "registry.example.com",
+			expected: authn.Anonymous,
+		},
+	}
+	for scenarioName, scenario := range scenarios {
+		t.Run(scenarioName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			registry, err := name.NewRegistry(scenario.registry)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			authenticator, err := keychain.Resolve(registry)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(authenticator).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
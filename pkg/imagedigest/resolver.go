@@ -0,0 +1,173 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedigest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DigestFunc returns the digest of the manifest an image reference refers to, e.g. sha256:4b7d...
+type DigestFunc func(ref name.Reference, keychain authn.Keychain) (string, error)
+
+// Resolver resolves the tags of the images of the pods to digests with the registry API, authenticated with the
+// image pull secrets of the pods and of their service account
+type Resolver struct {
+	client client.Client
+	digest DigestFunc
+}
+
+func NewResolver(client client.Client) *Resolver {
+	return &Resolver{
+		client: client,
+		digest: remoteDigest,
+	}
+}
+
+// registryTimeout bounds the requests resolving a digest, the retries included, so a stalled registry fails the
+// reconcile of the component instead of blocking the controller
+var registryTimeout = 10 * time.Second
+
+// deadlineTransport sends the requests with the context of the digest resolution
+type deadlineTransport struct {
+	ctx   context.Context
+	inner http.RoundTripper
+}
+
+func (t *deadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.inner.RoundTrip(req.WithContext(t.ctx))
+}
+
+// remoteDigest gets the descriptor of the image from its registry, the digest of the index of a multi-platform image
+// is returned so the nodes keep pulling the image of their platform
+func remoteDigest(ref name.Reference, keychain authn.Keychain) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+	defer cancel()
+	descriptor, err := remote.Get(ref, remote.WithAuthFromKeychain(keychain),
+		remote.WithTransport(&deadlineTransport{ctx: ctx, inner: http.DefaultTransport}))
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("the registry %s did not answer within %v", ref.Context().RegistryStr(),
+				registryTimeout)
+		}
+		return "", err
+	}
+	return descriptor.Digest.String(), nil
+}
+
+// Resolve replaces the tags of the images of the containers of the pod spec with the digests they refer to, and
+// returns the resolved images by container name. The digests of the previous images are reused while the image of a
+// container does not change, so a retagged image is not rolled out until the spec changes. The images already
+// referenced by digest are left as is. The containers are copied, the pod spec may share them with the
+// InferenceService.
+func (r *Resolver) Resolve(namespace string, podSpec *v1.PodSpec,
+	previous map[string]v1beta1.ResolvedImage) (map[string]v1beta1.ResolvedImage, error) {
+	var keychain authn.Keychain
+	resolved := map[string]v1beta1.ResolvedImage{}
+	podSpec.InitContainers = append([]v1.Container(nil), podSpec.InitContainers...)
+	podSpec.Containers = append([]v1.Container(nil), podSpec.Containers...)
+	containers := []*v1.Container{}
+	for i := range podSpec.InitContainers {
+		containers = append(containers, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		containers = append(containers, &podSpec.Containers[i])
+	}
+	for _, container := range containers {
+		ref, err := name.ParseReference(container.Image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image %q of container %s: %v", container.Image, container.Name, err)
+		}
+		tag, ok := ref.(name.Tag)
+		if !ok {
+			continue
+		}
+		image, ok := previous[container.Name]
+		if !ok || image.Image != container.Image {
+			if keychain == nil {
+				if keychain, err = r.keychain(namespace, podSpec); err != nil {
+					return nil, err
+				}
+			}
+			digest, err := r.digest(ref, keychain)
+			if err != nil {
+				return nil, errors.Wrapf(err, "fails to resolve the digest of image %s", container.Image)
+			}
+			image = v1beta1.ResolvedImage{Image: container.Image, Digest: digest}
+		}
+		resolved[container.Name] = image
+		container.Image = strings.TrimSuffix(container.Image, ":"+tag.TagStr()) + "@" + image.Digest
+	}
+	return resolved, nil
+}
+
+// Pin returns the image referenced by the digest it was resolved to, the tag of the image is replaced by the digest.
+// The images already referenced by digest are returned as is.
+func Pin(image string, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %q: %v", image, err)
+	}
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return image, nil
+	}
+	return strings.TrimSuffix(image, ":"+tag.TagStr()) + "@" + digest, nil
+}
+
+// keychain reads the image pull secrets of the pod spec and of its service account, the missing secrets are skipped
+// like the kubelet does
+func (r *Resolver) keychain(namespace string, podSpec *v1.PodSpec) (authn.Keychain, error) {
+	references := append([]v1.LocalObjectReference{}, podSpec.ImagePullSecrets...)
+	serviceAccountName := podSpec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = "default"
+	}
+	serviceAccount := &v1.ServiceAccount{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: serviceAccountName, Namespace: namespace}, serviceAccount)
+	if err != nil && !apierr.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "fails to get service account %s", serviceAccountName)
+	}
+	references = append(references, serviceAccount.ImagePullSecrets...)
+
+	secrets := []v1.Secret{}
+	for _, reference := range references {
+		secret := v1.Secret{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: reference.Name, Namespace: namespace}, &secret)
+		if apierr.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "fails to get image pull secret %s", reference.Name)
+		}
+		secrets = append(secrets, secret)
+	}
+	return newSecretKeychain(secrets), nil
+}
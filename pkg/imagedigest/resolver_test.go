@@ -0,0 +1,264 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagedigest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	sklearnDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	loggerDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	pinnedDigest  = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+)
+
+func TestResolve(t *testing.T) {
+	digests := map[string]string{
+		"index.docker.io/kfserving/sklearnserver:v0.5.0": sklearnDigest,
+		"registry.example.com:5000/team/logger:latest":   loggerDigest,
+	}
+	scenarios := map[string]struct {
+		containers         []v1.Container
+		previous           map[string]v1beta1.ResolvedImage
+		expectedImages     []string
+		expectedResolved   map[string]v1beta1.ResolvedImage
+		expectedRegistries []string
+		matcher            string
+	}{
+		"ResolveTags": {
+			containers: []v1.Container{
+				{Name: "kfserving-container", Image: "kfserving/sklearnserver:v0.5.0"},
+				{Name: "logger", Image: "registry.example.com:5000/team/logger"},
+			},
+			expectedImages: []string{
+				"kfserving/sklearnserver@" + sklearnDigest,
+				"registry.example.com:5000/team/logger@" + loggerDigest,
+			},
+			expectedResolved: map[string]v1beta1.ResolvedImage{
+				"kfserving-container": {Image: "kfserving/sklearnserver:v0.5.0", Digest: sklearnDigest},
+				"logger":              {Image: "registry.example.com:5000/team/logger", Digest: loggerDigest},
+			},
+			expectedRegistries: []string{"index.docker.io", "registry.example.com:5000"},
+		},
+		"KeepPreviousDigest": {
+			containers: []v1.Container{{Name: "kfserving-container", Image: "kfserving/sklearnserver:v0.5.0"}},
+			previous: map[string]v1beta1.ResolvedImage{
+				"kfserving-container": {Image: "kfserving/sklearnserver:v0.5.0", Digest: pinnedDigest},
+			},
+			expectedImages: []string{"kfserving/sklearnserver@" + pinnedDigest},
+			expectedResolved: map[string]v1beta1.ResolvedImage{
+				"kfserving-container": {Image: "kfserving/sklearnserver:v0.5.0", Digest: pinnedDigest},
+			},
+		},
+		"ResolveChangedImage": {
+			containers: []v1.Container{{Name: "kfserving-container", Image: "kfserving/sklearnserver:v0.5.0"}},
+			previous: map[string]v1beta1.ResolvedImage{
+				"kfserving-container": {Image: "kfserving/sklearnserver:v0.4.0", Digest: pinnedDigest},
+			},
+			expectedImages: []string{"kfserving/sklearnserver@" + sklearnDigest},
+			expectedResolved: map[string]v1beta1.ResolvedImage{
+				"kfserving-container": {Image: "kfserving/sklearnserver:v0.5.0", Digest: sklearnDigest},
+			},
+			expectedRegistries: []string{"index.docker.io"},
+		},
+		"KeepDigestReference": {
+			containers:       []v1.Container{{Name: "kfserving-container", Image: "kfserving/sklearnserver@" + pinnedDigest}},
+			expectedImages:   []string{"kfserving/sklearnserver@" + pinnedDigest},
+			expectedResolved: map[string]v1beta1.ResolvedImage{},
+		},
+		"UnknownImage": {
+			containers: []v1.Container{{Name: "kfserving-container", Image: "kfserving/unknown:v0.5.0"}},
+			matcher:    "fails to resolve the digest of image kfserving/unknown:v0.5.0: MANIFEST_UNKNOWN",
+		},
+		"InvalidImage": {
+			containers: []v1.Container{{Name: "kfserving-container", Image: "kfserving/Sklearnserver"}},
+			matcher:    `invalid image "kfserving/Sklearnserver" of container kfserving-container`,
+		},
+	}
+
+	for scenarioName, scenario := range scenarios {
+		t.Run(scenarioName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			registries := []string{}
+			resolver := &Resolver{
+				client: fake.NewFakeClientWithScheme(scheme.Scheme),
+				digest: func(ref name.Reference, keychain authn.Keychain) (string, error) {
+					registries = append(registries, ref.Context().RegistryStr())
+					if digest, ok := digests[ref.Name()]; ok {
+						return digest, nil
+					}
+					return "", fmt.Errorf("MANIFEST_UNKNOWN")
+				},
+			}
+			containers := append([]v1.Container{}, scenario.containers...)
+			podSpec := &v1.PodSpec{Containers: containers}
+			resolved, err := resolver.Resolve("default", podSpec, scenario.previous)
+			if scenario.matcher != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(scenario.matcher)))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			images := []string{}
+			for _, container := range podSpec.Containers {
+				images = append(images, container.Image)
+			}
+			g.Expect(images).To(gomega.Equal(scenario.expectedImages))
+			g.Expect(resolved).To(gomega.Equal(scenario.expectedResolved))
+			if scenario.expectedRegistries == nil {
+				g.Expect(registries).To(gomega.BeEmpty())
+			} else {
+				g.Expect(registries).To(gomega.Equal(scenario.expectedRegistries))
+			}
+			// The containers shared with the spec are not changed
+			g.Expect(containers).To(gomega.Equal(scenario.containers))
+		})
+	}
+}
+
+func TestResolveWithImagePullSecrets(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	cl := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&v1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "models", Namespace: "default"},
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "mirror-registry"}},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-registry", Namespace: "default"},
+			Type:       v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				v1.DockerConfigJsonKey: []byte(`{"auths": {"registry.example.com": {"username": "robot", "password": "team"}}}`),
+			},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "mirror-registry", Namespace: "default"},
+			Type:       v1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				v1.DockerConfigJsonKey: []byte(`{"auths": {"mirror.example.com": {"auth": "bWlycm9yOnNlY3JldA=="}}}`),
+			},
+		},
+	)
+	authorizations := map[string]string{}
+	resolver := &Resolver{
+		client: cl,
+		digest: func(ref name.Reference, keychain authn.Keychain) (string, error) {
+			authenticator, err := keychain.Resolve(ref.Context().Registry)
+			if err != nil {
+				return "", err
+			}
+			authorization, err := authenticator.Authorization()
+			if err != nil {
+				return "", err
+			}
+			authorizations[ref.Context().RegistryStr()] = authorization
+			return sklearnDigest, nil
+		},
+	}
+	podSpec := &v1.PodSpec{
+		ServiceAccountName: "models",
+		ImagePullSecrets:   []v1.LocalObjectReference{{Name: "team-registry"}, {Name: "missing-registry"}},
+		Containers: []v1.Container{
+			{Name: "kfserving-container", Image: "registry.example.com/team/model:v1"},
+			{Name: "logger", Image: "mirror.example.com/kfserving/logger:v0.5.0"},
+			{Name: "batcher", Image: "kfserving/batcher:v0.5.0"},
+		},
+	}
+	_, err := resolver.Resolve("default", podSpec, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(authorizations).To(gomega.Equal(map[string]string{
+		"registry.example.com": "Basic cm9ib3Q6dGVhbQ==",
+		"mirror.example.com":   "Basic bWlycm9yOnNlY3JldA==",
+		"index.docker.io":      "",
+	}))
+}
+
+func TestPin(t *testing.T) {
+	scenarios := map[string]struct {
+		image    string
+		expected string
+		matcher  string
+	}{
+		"Tag": {
+			image:    "kfserving/agent:v0.5.0",
+			expected: "kfserving/agent@" + sklearnDigest,
+		},
+		"ImplicitLatestTag": {
+			image:    "registry.example.com:5000/team/logger",
+			expected: "registry.example.com:5000/team/logger@" + sklearnDigest,
+		},
+		"Digest": {
+			image:    "kfserving/agent@" + pinnedDigest,
+			expected: "kfserving/agent@" + pinnedDigest,
+		},
+		"InvalidImage": {
+			image:   "kfserving/Agent:v0.5.0",
+			matcher: `invalid image "kfserving/Agent:v0.5.0"`,
+		},
+	}
+	for scenarioName, scenario := range scenarios {
+		t.Run(scenarioName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			image, err := Pin(scenario.image, sklearnDigest)
+			if scenario.matcher != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(scenario.matcher)))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(image).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestRemoteDigestStalledRegistry(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	stop := make(chan struct{})
+	registry := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-stop
+	}))
+	defer registry.Close()
+	defer close(stop)
+	timeout := registryTimeout
+	registryTimeout = 100 * time.Millisecond
+	defer func() { registryTimeout = timeout }()
+
+	ref, err := name.ParseReference(strings.TrimPrefix(registry.URL, "http://")+"/team/model:v1", name.Insecure)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	done := make(chan error, 1)
+	go func() {
+		_, err := remoteDigest(ref, newSecretKeychain(nil))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("did not answer within 100ms")))
+	case <-time.After(10 * time.Second):
+		t.Fatal("the digest resolution is not bounded by the registry timeout")
+	}
+}
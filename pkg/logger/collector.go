@@ -16,10 +16,35 @@ limitations under the License.
 
 package logger
 
+import (
+	"sync/atomic"
+	"time"
+)
+
+// FlushPollInterval is the interval at which the queued log requests are checked while flushing
+const FlushPollInterval = 100 * time.Millisecond
+
 // A buffered channel that we can send work requests on.
 var WorkQueue = make(chan LogRequest, LoggerWorkerQueueSize)
 
+// pendingLogRequests counts the log requests queued and not sent yet
+var pendingLogRequests int64
+
 func QueueLogRequest(req LogRequest) error {
+	atomic.AddInt64(&pendingLogRequests, 1)
 	WorkQueue <- req
 	return nil
 }
+
+// Flush waits for the queued log requests to be sent up to the timeout, it returns false when log requests are still
+// queued at the timeout.
+func Flush(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&pendingLogRequests) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(FlushPollInterval)
+	}
+	return true
+}
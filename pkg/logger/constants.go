@@ -16,7 +16,13 @@ limitations under the License.
 
 package logger
 
+import "time"
+
 const (
 	LoggerWorkerQueueSize = 100
 	CloudEventsIdHeader   = "Ce-Id"
+	// The sampled predictions are explained by a fixed number of workers, the samples are dropped when the queue is full
+	ExplainerWorkers   = 4
+	ExplainerQueueSize = 100
+	ExplainerTimeout   = 60 * time.Second
 )
@@ -0,0 +1,37 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/utils"
+)
+
+// ValidateSinkURL checks that the sink host is one of the allowed domains or a subdomain of one of them.
+// An empty list of allowed domains places no restriction on the sink.
+func ValidateSinkURL(sink *url.URL, allowedDomains []string) error {
+	if len(allowedDomains) == 0 {
+		return nil
+	}
+	if utils.InDomains(sink.Hostname(), allowedDomains) {
+		return nil
+	}
+	return fmt.Errorf("log sink %q is not in the allowed sink domains [%s]", sink.String(), strings.Join(allowedDomains, ", "))
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestValidateSinkURL(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		sink           string
+		allowedDomains []string
+		matcher        gomega.OmegaMatcher
+	}{
+		"NoRestriction": {
+			sink:    "http://collector.example.com",
+			matcher: gomega.BeNil(),
+		},
+		"ExactDomain": {
+			sink:           "http://logs.eu-west-1.example.com:8080/events",
+			allowedDomains: []string{"logs.eu-west-1.example.com"},
+			matcher:        gomega.BeNil(),
+		},
+		"SubDomain": {
+			sink:           "http://broker.default.svc.cluster.local",
+			allowedDomains: []string{"example.com", ".svc.cluster.local"},
+			matcher:        gomega.BeNil(),
+		},
+		"OutsideAllowedDomains": {
+			sink:           "http://logs.us-east-1.example.com",
+			allowedDomains: []string{"eu-west-1.example.com"},
+			matcher:        gomega.HaveOccurred(),
+		},
+		"SuffixIsNotASubDomain": {
+			sink:           "http://evilexample.com",
+			allowedDomains: []string{"example.com"},
+			matcher:        gomega.HaveOccurred(),
+		},
+	}
+	for name, scenario := range scenarios {
+		sink, err := url.Parse(scenario.sink)
+		g.Expect(err).To(gomega.BeNil())
+		if err := ValidateSinkURL(sink, scenario.allowedDomains); !g.Expect(err).To(scenario.matcher) {
+			t.Errorf("Test %q unexpected result: %v", name, err)
+		}
+	}
+}
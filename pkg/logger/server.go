@@ -18,51 +18,107 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/go-logr/logr"
 	guuid "github.com/google/uuid"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/tracing"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
+	"strings"
 )
 
 type LoggerHandler struct {
-	log              logr.Logger
-	svcHost          string
-	svcPort          string
-	logUrl           *url.URL
-	sourceUri        *url.URL
-	logMode          v1alpha2.LoggerMode
-	inferenceService string
-	namespace        string
-	endpoint         string
+	log                    logr.Logger
+	svcHost                string
+	svcPort                string
+	logUrl                 *url.URL
+	sourceUri              *url.URL
+	logMode                v1alpha2.LoggerMode
+	inferenceService       string
+	namespace              string
+	endpoint               string
+	explainerUrl           *url.URL
+	explainSamplingPercent int
+	// explainQueue holds the sampled predictions until an explainer worker calls the explainer with explainClient
+	explainQueue  chan explainRequest
+	explainClient *http.Client
+	// streaming passes the responses of the service through to the client as they are read
+	streaming bool
+	// proxy relays the websocket connections to the service
+	proxy *httputil.ReverseProxy
 }
 
-func New(log logr.Logger, svcHost string, svcPort string, logUrl *url.URL, sourceUri *url.URL, logMode v1alpha2.LoggerMode, inferenceService string, namespace string, endpoint string) http.Handler {
-	return &LoggerHandler{
-		log:              log,
-		svcHost:          svcHost,
-		svcPort:          svcPort,
-		logUrl:           logUrl,
-		sourceUri:        sourceUri,
-		logMode:          logMode,
-		inferenceService: inferenceService,
-		namespace:        namespace,
-		endpoint:         endpoint,
+// explainRequest is a sampled prediction to explain
+type explainRequest struct {
+	bytes        []byte
+	contentType  string
+	id           string
+	traceContext http.Header
+}
+
+func New(log logr.Logger, svcHost string, svcPort string, logUrl *url.URL, sourceUri *url.URL, logMode v1alpha2.LoggerMode, inferenceService string, namespace string, endpoint string, explainerUrl *url.URL, explainSamplingPercent int, streaming bool) http.Handler {
+	eh := &LoggerHandler{
+		log:                    log,
+		svcHost:                svcHost,
+		svcPort:                svcPort,
+		logUrl:                 logUrl,
+		sourceUri:              sourceUri,
+		logMode:                logMode,
+		inferenceService:       inferenceService,
+		namespace:              namespace,
+		endpoint:               endpoint,
+		explainerUrl:           explainerUrl,
+		explainSamplingPercent: explainSamplingPercent,
+		streaming:              streaming,
+		proxy:                  httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: fmt.Sprintf("%s:%s", svcHost, svcPort)}),
+	}
+	if explainerUrl != nil && explainSamplingPercent > 0 {
+		eh.explainQueue = make(chan explainRequest, ExplainerQueueSize)
+		eh.explainClient = &http.Client{Timeout: ExplainerTimeout}
+		for i := 0; i < ExplainerWorkers; i++ {
+			go func() {
+				for req := range eh.explainQueue {
+					eh.explain(req.bytes, req.contentType, req.id, req.traceContext)
+				}
+			}()
+		}
 	}
+	return eh
 }
 
-func (eh *LoggerHandler) callService(b []byte, r *http.Request) ([]byte, *string, *int, error) {
+func (eh *LoggerHandler) post(ctx context.Context, b []byte, r *http.Request) (*http.Response, error) {
 	url := &url.URL{
 		Scheme: "http",
 		Host:   fmt.Sprintf("%s:%s", eh.svcHost, eh.svcPort),
 		Path:   r.URL.Path,
 	}
 	eh.log.Info("Calling server", "url", url.String())
-	response, err := http.Post(url.String(), r.Header.Get("Content-Type"), bytes.NewReader(b))
+	req, err := http.NewRequest(http.MethodPost, url.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("while creating request: %s", err)
+	}
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	if accept := r.Header.Get("Accept"); accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	tracing.Propagate(req.Header, r.Header)
+	response, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("while calling post: %s", err)
+		return nil, fmt.Errorf("while calling post: %s", err)
+	}
+	return response, nil
+}
+
+func (eh *LoggerHandler) callService(b []byte, r *http.Request) ([]byte, *string, *int, error) {
+	response, err := eh.post(context.Background(), b, r)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	rb, err := ioutil.ReadAll(response.Body)
 	if err != nil {
@@ -76,6 +132,103 @@ func (eh *LoggerHandler) callService(b []byte, r *http.Request) ([]byte, *string
 	return rb, &contentType, &statusCode, nil
 }
 
+// streamService calls the service and writes its response to the client as it is read, each chunk is flushed so the
+// chunked responses and the server-sent events are not buffered. The whole response is returned to be logged, the
+// status code is 0 when the response could not be started. The call is canceled when the client goes away, e.g. to stop
+// the generation of the tokens nobody reads.
+func (eh *LoggerHandler) streamService(b []byte, r *http.Request, w http.ResponseWriter) ([]byte, *string, int, error) {
+	response, err := eh.post(r.Context(), b, r)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer response.Body.Close()
+	contentType := response.Header.Get("Content-Type")
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(response.StatusCode)
+	flusher, _ := w.(http.Flusher)
+	rb := &bytes.Buffer{}
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := response.Body.Read(chunk)
+		if n > 0 {
+			rb.Write(chunk[:n])
+			if _, err := w.Write(chunk[:n]); err != nil {
+				return rb.Bytes(), &contentType, response.StatusCode, fmt.Errorf("while writing response: %s", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return rb.Bytes(), &contentType, response.StatusCode, nil
+		}
+		if err != nil {
+			return rb.Bytes(), &contentType, response.StatusCode, fmt.Errorf("while reading response body: %s", err)
+		}
+	}
+}
+
+// shouldExplain decides whether a predict request is part of the sample sent to the explainer
+func (eh *LoggerHandler) shouldExplain(r *http.Request) bool {
+	if eh.explainQueue == nil {
+		return false
+	}
+	if !strings.HasSuffix(r.URL.Path, ":predict") {
+		return false
+	}
+	return rand.Intn(100) < eh.explainSamplingPercent
+}
+
+// explain calls the explainer with the request payload and sends the explanation to logUrl, the explainer call is
+// part of the trace of the predict request
+func (eh *LoggerHandler) explain(b []byte, contentType string, id string, traceContext http.Header) {
+	eh.log.Info("Calling explainer", "url", eh.explainerUrl.String(), "requestId", id)
+	req, err := http.NewRequest(http.MethodPost, eh.explainerUrl.String(), bytes.NewReader(b))
+	if err != nil {
+		eh.log.Error(err, "Failed to create explainer request", "url", eh.explainerUrl.String())
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+	tracing.Propagate(req.Header, traceContext)
+	response, err := eh.explainClient.Do(req)
+	if err != nil {
+		eh.log.Error(err, "Failed to call explainer", "url", eh.explainerUrl.String())
+		return
+	}
+	defer response.Body.Close()
+	rb, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		eh.log.Error(err, "Failed to read explainer response")
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		eh.log.Info("Bad call to explainer.", "status code", response.StatusCode)
+		return
+	}
+	if err := QueueLogRequest(LogRequest{
+		Url:              eh.logUrl,
+		Bytes:            &rb,
+		ContentType:      "application/json", // Always JSON at present
+		ReqType:          InferenceExplanation,
+		Id:               id,
+		SourceUri:        eh.sourceUri,
+		InferenceService: eh.inferenceService,
+		Namespace:        eh.namespace,
+		Endpoint:         eh.endpoint,
+		TraceParent:      traceContext.Get(tracing.TraceParentHeader),
+		TraceState:       traceContext.Get(tracing.TraceStateHeader),
+	}); err != nil {
+		eh.log.Error(err, "Failed to log explanation")
+	}
+}
+
+// isWebsocketUpgrade returns true for the requests opening a websocket connection
+func isWebsocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
 func getOrCreateID(r *http.Request) string {
 	id := r.Header.Get(CloudEventsIdHeader)
 	if id == "" {
@@ -86,6 +239,13 @@ func getOrCreateID(r *http.Request) string {
 
 // call svc and add send request/responses to logUrl
 func (eh *LoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The websocket connections are relayed to the service, their messages are not logged
+	if isWebsocketUpgrade(r) {
+		eh.log.Info("Relaying websocket connection", "path", r.URL.Path)
+		eh.proxy.ServeHTTP(w, r)
+		return
+	}
+
 	// Read Payload
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -107,40 +267,79 @@ func (eh *LoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			InferenceService: eh.inferenceService,
 			Namespace:        eh.namespace,
 			Endpoint:         eh.endpoint,
+			TraceParent:      r.Header.Get(tracing.TraceParentHeader),
+			TraceState:       r.Header.Get(tracing.TraceStateHeader),
 		}); err != nil {
 			eh.log.Error(err, "Failed to log request")
 		}
 	}
 
 	// Call service
-	b, respContentType, statusCode, err := eh.callService(b, r)
-	// Error in internal calling of service. Non 200 returns code from service will not cause an error.
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	reqBytes := b
+	var respContentType *string
+	var statusCode *int
+	if eh.streaming {
+		// The streamed response is already written to the client, it is logged whole once complete
+		var streamStatusCode int
+		b, respContentType, streamStatusCode, err = eh.streamService(b, r, w)
+		if err != nil && streamStatusCode == 0 {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			eh.log.Error(err, "Failed to stream response")
+			return
+		}
+		statusCode = &streamStatusCode
+	} else {
+		b, respContentType, statusCode, err = eh.callService(b, r)
+		// Error in internal calling of service. Non 200 returns code from service will not cause an error.
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
 	// log response if OK
 	if *statusCode == http.StatusOK {
 		if eh.logMode == v1alpha2.LogAll || eh.logMode == v1alpha2.LogResponse {
+			responseContentType := "application/json" // Always JSON at present, except for the streamed responses
+			if eh.streaming && *respContentType != "" {
+				responseContentType = *respContentType
+			}
 			if err := QueueLogRequest(LogRequest{
 				Url:              eh.logUrl,
 				Bytes:            &b,
-				ContentType:      "application/json", // Always JSON at present
+				ContentType:      responseContentType,
 				ReqType:          InferenceResponse,
 				Id:               id,
 				SourceUri:        eh.sourceUri,
 				InferenceService: eh.inferenceService,
 				Namespace:        eh.namespace,
 				Endpoint:         eh.endpoint,
+				TraceParent:      r.Header.Get(tracing.TraceParentHeader),
+				TraceState:       r.Header.Get(tracing.TraceStateHeader),
 			}); err != nil {
 				eh.log.Error(err, "Failed to log response")
 			}
 		}
+		// explain a sample of the successful predictions asynchronously, the sample is dropped rather than blocking the
+		// predict request when the explainer workers are behind
+		if eh.shouldExplain(r) {
+			select {
+			case eh.explainQueue <- explainRequest{reqBytes, r.Header.Get("Content-Type"), id, tracing.Extract(r.Header)}:
+			default:
+				eh.log.Info("Explainer queue is full, dropping the sampled prediction", "requestId", id)
+			}
+		}
 	} else {
 		eh.log.Info("Bad call to service.", "status code", *statusCode)
 	}
 
+	if eh.streaming {
+		return
+	}
+
 	// Write final response
 	if *respContentType != "" {
 		w.Header().Set("Content-Type", *respContentType)
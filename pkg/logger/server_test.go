@@ -17,15 +17,20 @@ limitations under the License.
 package logger
 
 import (
+	"bufio"
 	"bytes"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/tracing"
 	"github.com/onsi/gomega"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestLogger(t *testing.T) {
@@ -70,7 +75,7 @@ func TestLogger(t *testing.T) {
 	g.Expect(err).To(gomega.BeNil())
 	sourceUri, err := url.Parse("http://localhost:8080/")
 	g.Expect(err).To(gomega.BeNil())
-	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default")
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil, 0, false)
 
 	oh.ServeHTTP(w, r)
 
@@ -78,3 +83,304 @@ func TestLogger(t *testing.T) {
 	g.Expect(b2).To(gomega.Equal(predictorResponse))
 
 }
+
+func TestLoggerExplainSampling(t *testing.T) {
+
+	g := gomega.NewGomegaWithT(t)
+
+	predictorRequest := []byte(`{"instances":[[0,0,0]]}`)
+	predictorResponse := []byte(`{"instances":[[4,5,6]]}`)
+
+	explained := make(chan []byte, 1)
+	// Start a local explainer HTTP server
+	explainer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		g.Expect(err).To(gomega.BeNil())
+		explained <- b
+		_, err = rw.Write([]byte(`{"explanations":[]}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer explainer.Close()
+
+	// Start a local HTTP server
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(predictorResponse)
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	explainerUrl, err := url.Parse(explainer.URL + "/v1/models/mymodel:explain")
+	g.Expect(err).To(gomega.BeNil())
+	logSvcUrl, err := url.Parse("http://localhost:8081/")
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogResponse, "mymodel", "default", "default", explainerUrl, 100, false)
+
+	r := httptest.NewRequest("POST", "http://a/v1/models/mymodel:predict", bytes.NewReader(predictorRequest))
+	w := httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+
+	b2, _ := ioutil.ReadAll(w.Result().Body)
+	g.Expect(b2).To(gomega.Equal(predictorResponse))
+	g.Eventually(explained).Should(gomega.Receive(gomega.Equal(predictorRequest)))
+}
+
+func TestLoggerFlush(t *testing.T) {
+
+	g := gomega.NewGomegaWithT(t)
+
+	logged := make(chan []byte, 2)
+	logSvc := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		g.Expect(err).To(gomega.BeNil())
+		logged <- b
+	}))
+	defer logSvc.Close()
+
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write([]byte(`{"predictions":[[4,5,6]]}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	logSvcUrl, err := url.Parse(logSvc.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil, 0, false)
+
+	r := httptest.NewRequest("POST", "http://a", bytes.NewReader([]byte(`{"instances":[[0,0,0]]}`)))
+	w := httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+
+	// The log events queued by the request, and by the previous tests, are sent once the dispatcher runs
+	g.Expect(Flush(0)).To(gomega.BeFalse())
+	StartDispatcher(5, log)
+	g.Expect(Flush(30 * time.Second)).To(gomega.BeTrue())
+	g.Expect(logged).To(gomega.HaveLen(2))
+}
+
+func TestLoggerTracePropagation(t *testing.T) {
+
+	g := gomega.NewGomegaWithT(t)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	predictorRequest := []byte(`{"instances":[[0,0,0]]}`)
+	predictorResponse := []byte(`{"predictions":[[4,5,6]]}`)
+
+	explained := make(chan http.Header, 1)
+	explainer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		explained <- req.Header
+		_, err := rw.Write([]byte(`{"explanations":[]}`))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer explainer.Close()
+
+	predicted := make(chan http.Header, 1)
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		predicted <- req.Header
+		_, err := rw.Write(predictorResponse)
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	explainerUrl, err := url.Parse(explainer.URL + "/v1/models/mymodel:explain")
+	g.Expect(err).To(gomega.BeNil())
+	logSvcUrl, err := url.Parse("http://localhost:8081/")
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogResponse, "mymodel", "default", "default", explainerUrl, 100, false)
+
+	r := httptest.NewRequest("POST", "http://a/v1/models/mymodel:predict", bytes.NewReader(predictorRequest))
+	r.Header.Set(tracing.TraceParentHeader, traceParent)
+	r.Header.Set(tracing.TraceStateHeader, "congo=t61rcWkgMzE")
+	w := httptest.NewRecorder()
+	oh.ServeHTTP(w, r)
+
+	g.Expect(w.Code).To(gomega.Equal(http.StatusOK))
+	var header http.Header
+	g.Eventually(predicted).Should(gomega.Receive(&header))
+	g.Expect(header.Get(tracing.TraceParentHeader)).To(gomega.Equal(traceParent))
+	g.Expect(header.Get(tracing.TraceStateHeader)).To(gomega.Equal("congo=t61rcWkgMzE"))
+	g.Eventually(explained).Should(gomega.Receive(&header))
+	g.Expect(header.Get(tracing.TraceParentHeader)).To(gomega.Equal(traceParent))
+}
+
+func TestLoggerStreaming(t *testing.T) {
+
+	g := gomega.NewGomegaWithT(t)
+
+	// The predictor streams two server-sent events, the second one once the first one reached the client
+	release := make(chan struct{})
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		g.Expect(req.Header.Get("Accept")).To(gomega.Equal("text/event-stream"))
+		rw.Header().Set("Content-Type", "text/event-stream")
+		_, err := rw.Write([]byte("data: {\"token\":\"Hello\"}\n\n"))
+		g.Expect(err).To(gomega.BeNil())
+		rw.(http.Flusher).Flush()
+		<-release
+		_, err = rw.Write([]byte("data: {\"token\":\"world\"}\n\n"))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	logSvcUrl, err := url.Parse("http://localhost:8081/")
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogRequest, "mymodel", "default", "default", nil, 0, true)
+	server := httptest.NewServer(oh)
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/v1/models/mymodel:generate", bytes.NewReader([]byte(`{"prompt":"Hi"}`)))
+	g.Expect(err).To(gomega.BeNil())
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	g.Expect(err).To(gomega.BeNil())
+	defer resp.Body.Close()
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusOK))
+	g.Expect(resp.Header.Get("Content-Type")).To(gomega.Equal("text/event-stream"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(line).To(gomega.Equal("data: {\"token\":\"Hello\"}\n"))
+	close(release)
+	rest, err := ioutil.ReadAll(reader)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(rest)).To(gomega.Equal("\ndata: {\"token\":\"world\"}\n\n"))
+}
+
+func TestWorkerTraceContext(t *testing.T) {
+
+	g := gomega.NewGomegaWithT(t)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	logged := make(chan http.Header, 1)
+	logSvc := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		logged <- req.Header
+	}))
+	defer logSvc.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+
+	logSvcUrl, err := url.Parse(logSvc.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	b := []byte(`{"instances":[[0,0,0]]}`)
+	worker := NewWorker(1, make(chan chan LogRequest), log)
+	g.Expect(worker.sendCloudEvent(LogRequest{
+		Url:              logSvcUrl,
+		Bytes:            &b,
+		ContentType:      "application/json",
+		ReqType:          InferenceRequest,
+		Id:               "1",
+		SourceUri:        sourceUri,
+		InferenceService: "mymodel",
+		Namespace:        "default",
+		Endpoint:         "default",
+		TraceParent:      traceParent,
+	})).To(gomega.Succeed())
+
+	var header http.Header
+	g.Expect(logged).To(gomega.Receive(&header))
+	// The event is sent with a child span of the same trace
+	g.Expect(header.Get("Ce-Traceparent")).To(gomega.HavePrefix("00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+	g.Expect(header.Get("Ce-Traceparent")).NotTo(gomega.Equal(traceParent))
+}
+
+func TestLoggerWebsocket(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var logged int32
+	logSvc := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&logged, 1)
+	}))
+	defer logSvc.Close()
+
+	// The predictor upgrades the connection and echoes the messages
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		g.Expect(req.Header.Get("Upgrade")).To(gomega.Equal("websocket"))
+		conn, buf, err := rw.(http.Hijacker).Hijack()
+		g.Expect(err).To(gomega.BeNil())
+		defer conn.Close()
+		_, err = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(buf.Flush()).To(gomega.Succeed())
+		line, err := buf.ReadString('\n')
+		g.Expect(err).To(gomega.BeNil())
+		_, err = conn.Write([]byte("echo " + line))
+		g.Expect(err).To(gomega.BeNil())
+	}))
+	defer predictor.Close()
+
+	logf.SetLogger(logf.ZapLogger(false))
+	log := logf.Log.WithName("entrypoint")
+	predictorSvcUrl, err := url.Parse(predictor.URL)
+	g.Expect(err).To(gomega.BeNil())
+	logSvcUrl, err := url.Parse(logSvc.URL)
+	g.Expect(err).To(gomega.BeNil())
+	sourceUri, err := url.Parse("http://localhost:8080/")
+	g.Expect(err).To(gomega.BeNil())
+	oh := New(log, "0.0.0.0", predictorSvcUrl.Port(), logSvcUrl, sourceUri, v1alpha2.LogAll, "mymodel", "default", "default", nil, 0, false)
+	logger := httptest.NewServer(oh)
+	defer logger.Close()
+
+	conn, err := net.Dial("tcp", logger.Listener.Addr().String())
+	g.Expect(err).To(gomega.BeNil())
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /v1/models/mymodel:predict HTTP/1.1\r\nHost: mymodel\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+	g.Expect(err).To(gomega.BeNil())
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(resp.StatusCode).To(gomega.Equal(http.StatusSwitchingProtocols))
+	_, err = conn.Write([]byte("hello\n"))
+	g.Expect(err).To(gomega.BeNil())
+	line, err := reader.ReadString('\n')
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(line).To(gomega.Equal("echo hello\n"))
+	// The messages of the websocket connections are not logged
+	g.Consistently(func() int32 { return atomic.LoadInt32(&logged) }, 100*time.Millisecond).Should(gomega.BeZero())
+}
+
+func TestExplainerWorkers(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	log := logf.Log.WithName("entrypoint")
+	explainerUrl, err := url.Parse("http://explainer.default/v1/models/mymodel:explain")
+	g.Expect(err).To(gomega.BeNil())
+
+	eh := New(log, "0.0.0.0", "8080", nil, nil, v1alpha2.LogAll, "mymodel", "default", "default", explainerUrl, 100, false).(*LoggerHandler)
+	g.Expect(cap(eh.explainQueue)).To(gomega.Equal(ExplainerQueueSize))
+	g.Expect(eh.explainClient.Timeout).To(gomega.Equal(ExplainerTimeout))
+
+	// No explainer workers are started without sampling
+	eh = New(log, "0.0.0.0", "8080", nil, nil, v1alpha2.LogAll, "mymodel", "default", "default", explainerUrl, 0, false).(*LoggerHandler)
+	g.Expect(eh.explainQueue).To(gomega.BeNil())
+	g.Expect(eh.shouldExplain(httptest.NewRequest(http.MethodPost, "/v1/models/mymodel:predict", nil))).To(gomega.BeFalse())
+}
@@ -25,6 +25,8 @@ type LogRequestType string
 const (
 	InferenceRequest  LogRequestType = "Request"
 	InferenceResponse LogRequestType = "Response"
+	// InferenceExplanation is the explanation of a sampled inference request
+	InferenceExplanation LogRequestType = "Explanation"
 )
 
 type LogRequest struct {
@@ -37,4 +39,7 @@ type LogRequest struct {
 	InferenceService string
 	Namespace        string
 	Endpoint         string
+	// TraceParent and TraceState are the W3C trace context of the inference request, empty when it is not traced
+	TraceParent string
+	TraceState  string
 }
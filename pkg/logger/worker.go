@@ -20,15 +20,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/cloudevents/sdk-go"
+	"github.com/cloudevents/sdk-go/pkg/cloudevents/extensions"
 	"github.com/cloudevents/sdk-go/pkg/cloudevents/transport"
 	"github.com/go-logr/logr"
+	"go.opencensus.io/trace"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	CEInferenceRequest  = "org.kubeflow.serving.inference.request"
-	CEInferenceResponse = "org.kubeflow.serving.inference.response"
+	CEInferenceRequest     = "org.kubeflow.serving.inference.request"
+	CEInferenceResponse    = "org.kubeflow.serving.inference.response"
+	CEInferenceExplanation = "org.kubeflow.serving.inference.explanation"
 
 	// cloud events extension attributes have to be lowercase alphanumeric
 	//TODO: ideally request id would have its own header but make do with ce-id for now
@@ -36,6 +40,8 @@ const (
 	NamespaceAttr        = "namespace"
 	//endpoint would be either default or canary
 	EndpointAttr = "endpoint"
+
+	logSpanName = "kfserving.logger"
 )
 
 // NewWorker creates, and returns a new Worker object. Its only argument
@@ -85,9 +91,12 @@ func (W *Worker) sendCloudEvent(logReq LogRequest) error {
 	}
 	event := cloudevents.NewEvent()
 	event.SetID(logReq.Id)
-	if logReq.ReqType == InferenceRequest {
+	switch logReq.ReqType {
+	case InferenceRequest:
 		event.SetType(CEInferenceRequest)
-	} else {
+	case InferenceExplanation:
+		event.SetType(CEInferenceExplanation)
+	default:
 		event.SetType(CEInferenceResponse)
 	}
 
@@ -101,7 +110,20 @@ func (W *Worker) sendCloudEvent(logReq LogRequest) error {
 		return fmt.Errorf("while setting cloudevents data: %s", err)
 	}
 
-	if _, _, err := c.Send(W.CeCtx, event); err != nil {
+	// The span of the send is a child of the inference request span, the client sets it as the distributed tracing
+	// extension of the event
+	ctx := W.CeCtx
+	if logReq.TraceParent != "" {
+		var span *trace.Span
+		ctx, span = extensions.DistributedTracingExtension{
+			TraceParent: logReq.TraceParent,
+			TraceState:  logReq.TraceState,
+		}.StartChildSpan(ctx, logSpanName)
+		if span != nil {
+			defer span.End()
+		}
+	}
+	if _, _, err := c.Send(ctx, event); err != nil {
 		return fmt.Errorf("while sending event: %s", err)
 	}
 	return nil
@@ -124,6 +146,7 @@ func (w *Worker) Start() {
 				if err := w.sendCloudEvent(work); err != nil {
 					w.Log.Error(err, "Failed to send log", "URL", work.Url.String())
 				}
+				atomic.AddInt64(&pendingLogRequests, -1)
 
 			case <-w.QuitChan:
 				// We have been asked to stop.
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CRDName of the InferenceService CustomResourceDefinition
+const CRDName = "inferenceservices.serving.kubeflow.org"
+
+// StorageVersion of the InferenceServices
+var StorageVersion = v1beta1.SchemeGroupVersion.Version
+
+var crdGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1beta1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// Migrator rewrites the InferenceServices stored in v1alpha2 in the storage version, v1beta1. The API server converts
+// the InferenceServices read in v1beta1 with the conversion webhook and encodes them in the storage version on update,
+// so an update without changes rewrites an InferenceService. Once they are all rewritten v1alpha2 can be pruned from
+// the stored versions of the CRD, and then removed from the CRD.
+type Migrator struct {
+	Client client.Client
+	Log    logr.Logger
+	// Namespace of the InferenceServices rewritten, all the namespaces when empty
+	Namespace string
+	// DryRun lists the InferenceServices without rewriting them
+	DryRun bool
+}
+
+// Migrate rewrites the InferenceServices and returns the number of InferenceServices rewritten
+func (m *Migrator) Migrate(ctx context.Context) (int, error) {
+	list := &v1beta1.InferenceServiceList{}
+	if err := m.Client.List(ctx, list, client.InNamespace(m.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list the InferenceServices: %v", err)
+	}
+	migrated := 0
+	for i := range list.Items {
+		isvc := &list.Items[i]
+		if m.DryRun {
+			m.Log.Info("Would rewrite InferenceService", "namespace", isvc.Namespace, "name", isvc.Name)
+			migrated++
+			continue
+		}
+		if err := m.rewrite(ctx, isvc); err != nil {
+			return migrated, fmt.Errorf("failed to rewrite InferenceService %s/%s: %v", isvc.Namespace, isvc.Name, err)
+		}
+		m.Log.Info("Rewrote InferenceService", "namespace", isvc.Namespace, "name", isvc.Name)
+		migrated++
+	}
+	return migrated, nil
+}
+
+// rewrite updates the InferenceService without changes, getting it again when it changed since it was listed
+func (m *Migrator) rewrite(ctx context.Context, isvc *v1beta1.InferenceService) error {
+	key := types.NamespacedName{Namespace: isvc.Namespace, Name: isvc.Name}
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			if err := m.Client.Get(ctx, key, isvc); err != nil {
+				return err
+			}
+		}
+		first = false
+		return m.Client.Update(ctx, isvc)
+	})
+}
+
+// PruneStoredVersions sets the stored versions of the InferenceService CRD to the storage version, the
+// InferenceServices must all be rewritten first
+func (m *Migrator) PruneStoredVersions(ctx context.Context) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: CRDName}, crd); err != nil {
+		return fmt.Errorf("failed to get the CRD %s: %v", CRDName, err)
+	}
+	storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if err != nil {
+		return fmt.Errorf("invalid stored versions of the CRD %s: %v", CRDName, err)
+	}
+	if len(storedVersions) == 1 && storedVersions[0] == StorageVersion {
+		return nil
+	}
+	if m.DryRun {
+		m.Log.Info("Would prune the stored versions of the CRD", "storedVersions", storedVersions)
+		return nil
+	}
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{StorageVersion}, "status", "storedVersions"); err != nil {
+		return err
+	}
+	if err := m.Client.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("failed to update the stored versions of the CRD %s: %v", CRDName, err)
+	}
+	m.Log.Info("Pruned the stored versions of the CRD", "storedVersions", storedVersions)
+	return nil
+}
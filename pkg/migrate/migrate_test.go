@@ -0,0 +1,148 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func newInferenceService(namespace, name string) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	}
+}
+
+func newCRD(storedVersions ...interface{}) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": CRDName},
+		"status":   map[string]interface{}{"storedVersions": storedVersions},
+	}}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+var inferenceServices = []types.NamespacedName{
+	{Namespace: "a", Name: "sklearn"},
+	{Namespace: "a", Name: "tensorflow"},
+	{Namespace: "b", Name: "sklearn"},
+}
+
+func TestMigrate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		namespace string
+		dryRun    bool
+		expected  int
+		rewritten []string
+	}{
+		"AllNamespaces": {
+			expected:  3,
+			rewritten: []string{"a/sklearn", "a/tensorflow", "b/sklearn"},
+		},
+		"Namespace": {
+			namespace: "a",
+			expected:  2,
+			rewritten: []string{"a/sklearn", "a/tensorflow"},
+		},
+		"DryRun": {
+			dryRun:   true,
+			expected: 3,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			var objs []runtime.Object
+			for _, key := range inferenceServices {
+				objs = append(objs, newInferenceService(key.Namespace, key.Name))
+			}
+			c := fake.NewFakeClientWithScheme(scheme, objs...)
+			resourceVersions := map[string]string{}
+			for _, key := range inferenceServices {
+				isvc := &v1beta1.InferenceService{}
+				g.Expect(c.Get(context.TODO(), key, isvc)).To(gomega.Succeed())
+				resourceVersions[key.String()] = isvc.ResourceVersion
+			}
+
+			m := &Migrator{Client: c, Log: logf.Log, Namespace: scenario.namespace, DryRun: scenario.dryRun}
+			migrated, err := m.Migrate(context.TODO())
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(migrated).To(gomega.Equal(scenario.expected))
+
+			var rewritten []string
+			for _, key := range inferenceServices {
+				isvc := &v1beta1.InferenceService{}
+				g.Expect(c.Get(context.TODO(), key, isvc)).To(gomega.Succeed())
+				if isvc.ResourceVersion != resourceVersions[key.String()] {
+					rewritten = append(rewritten, key.String())
+				}
+			}
+			g.Expect(rewritten).To(gomega.Equal(scenario.rewritten))
+		})
+	}
+}
+
+func TestPruneStoredVersions(t *testing.T) {
+	scenarios := map[string]struct {
+		storedVersions []interface{}
+		dryRun         bool
+		expected       []string
+	}{
+		"Prune": {
+			storedVersions: []interface{}{"v1alpha2", "v1beta1"},
+			expected:       []string{"v1beta1"},
+		},
+		"AlreadyPruned": {
+			storedVersions: []interface{}{"v1beta1"},
+			expected:       []string{"v1beta1"},
+		},
+		"DryRun": {
+			storedVersions: []interface{}{"v1alpha2", "v1beta1"},
+			dryRun:         true,
+			expected:       []string{"v1alpha2", "v1beta1"},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			c := fake.NewFakeClientWithScheme(runtime.NewScheme(), newCRD(scenario.storedVersions...))
+
+			m := &Migrator{Client: c, Log: logf.Log, DryRun: scenario.dryRun}
+			g.Expect(m.PruneStoredVersions(context.TODO())).To(gomega.Succeed())
+
+			crd := &unstructured.Unstructured{}
+			crd.SetGroupVersionKind(crdGVK)
+			g.Expect(c.Get(context.TODO(), types.NamespacedName{Name: CRDName}, crd)).To(gomega.Succeed())
+			storedVersions, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(storedVersions).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmetadata
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Collector periodically reads the metadata of the model of each ready v2 InferenceService from the model metadata
+// endpoint of its predictor into its status, once per ready revision of the predictor
+type Collector struct {
+	Client client.Client
+	// HTTP client of the metadata requests
+	HTTP *http.Client
+	// Interval between the collections
+	Interval time.Duration
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	Log   logr.Logger
+}
+
+// Start collects the metadata every interval until the manager stops
+func (c *Collector) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			if err := c.Collect(context.TODO()); err != nil {
+				c.Log.Error(err, "Failed to collect the model metadata")
+			}
+		}
+	}
+}
+
+// Collect updates the model metadata of the ready v2 InferenceServices whose latest ready predictor revision was not
+// read yet, the InferenceServices whose metadata can not be read are skipped until the next collection
+func (c *Collector) Collect(ctx context.Context) error {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := c.Client.List(ctx, isvcs); err != nil {
+		return errors.Wrapf(err, "fails to list inference services")
+	}
+	// The predictor is called directly as a transformer in front of it may not serve the metadata endpoint
+	metadataClient := &predict.Client{HTTP: c.HTTP, ClusterLocal: true, Component: v1beta1.PredictorComponent}
+	for i := range isvcs.Items {
+		isvc := &isvcs.Items[i]
+		if !isvc.Status.IsReady() || isvc.Spec.Predictor.GetProtocol() != constants.ProtocolV2 {
+			continue
+		}
+		revision := isvc.Status.Components[v1beta1.PredictorComponent].LatestReadyRevision
+		if isvc.Status.ModelMetadata != nil && isvc.Status.ModelMetadata.Revision == revision {
+			continue
+		}
+		if owned, err := c.Shard.Owns(isvc.Namespace); !owned {
+			if err != nil {
+				c.Log.Error(err, "Failed to check the shard", "namespace", isvc.Namespace, "name", isvc.Name)
+			}
+			continue
+		}
+		metadata, err := metadataClient.Metadata(ctx, isvc)
+		if err != nil {
+			c.Log.Error(err, "Failed to read the model metadata", "namespace", isvc.Namespace, "name", isvc.Name)
+			continue
+		}
+		patched := isvc.DeepCopy()
+		patched.Status.ModelMetadata = &v1beta1.ModelMetadataStatus{ModelMetadata: *metadata, Revision: revision}
+		if err := c.Client.Status().Patch(ctx, patched, client.MergeFrom(isvc)); err != nil {
+			c.Log.Error(err, "Failed to update the model metadata", "namespace", isvc.Namespace, "name", isvc.Name)
+			continue
+		}
+		c.Log.Info("Updated the model metadata", "namespace", isvc.Namespace, "name", isvc.Name, "revision", revision)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelmetadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func makeInferenceService(name string, ready bool, protocol constants.InferenceServiceProtocol,
+	predictorHost string) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				SKLearn: &v1beta1.SKLearnSpec{
+					PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{ProtocolVersion: &protocol},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {
+					LatestReadyRevision: name + "-predictor-default-00002",
+					Address:             &duckv1.Addressable{URL: apis.HTTP(predictorHost)},
+				},
+			},
+		},
+	}
+	status := v1.ConditionTrue
+	if !ready {
+		status = v1.ConditionFalse
+	}
+	isvc.Status.Conditions = append(isvc.Status.Conditions, apis.Condition{Type: apis.ConditionReady, Status: status})
+	return isvc
+}
+
+func TestCollect(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path != "/v2/models/resnet" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name": "resnet", "versions": ["1"], "platform": "tensorflow_savedmodel",
+			"inputs": [{"name": "image", "datatype": "FP32", "shape": [-1, 224, 224, 3]}],
+			"outputs": [{"name": "scores", "datatype": "FP32", "shape": [-1, 1000]}]}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	collected := makeInferenceService("collected", true, constants.ProtocolV2, u.Host)
+	collected.Status.ModelMetadata = &v1beta1.ModelMetadataStatus{
+		ModelMetadata: v1beta1.ModelMetadata{Platform: "mlflow"},
+		Revision:      "collected-predictor-default-00002",
+	}
+	stale := makeInferenceService("resnet", true, constants.ProtocolV2, u.Host)
+	stale.Status.ModelMetadata = &v1beta1.ModelMetadataStatus{Revision: "resnet-predictor-default-00001"}
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+	cl := fake.NewFakeClientWithScheme(scheme, stale, collected,
+		makeInferenceService("v1", true, constants.ProtocolV1, u.Host),
+		makeInferenceService("not-ready", false, constants.ProtocolV2, u.Host),
+		makeInferenceService("no-metadata", true, constants.ProtocolV2, u.Host))
+	collector := &Collector{Client: cl, HTTP: server.Client(), Log: logf.Log}
+	g.Expect(collector.Collect(context.TODO())).To(gomega.Succeed())
+
+	// Only the ready v2 inference services whose latest revision was not collected are requested
+	g.Expect(paths).To(gomega.ConsistOf("/v2/models/resnet", "/v2/models/no-metadata"))
+
+	isvc := &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "resnet", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ModelMetadata).To(gomega.Equal(&v1beta1.ModelMetadataStatus{
+		ModelMetadata: v1beta1.ModelMetadata{
+			Platform: "tensorflow_savedmodel",
+			Inputs:   []v1beta1.TensorMetadata{{Name: "image", Datatype: "FP32", Shape: []int64{-1, 224, 224, 3}}},
+			Outputs:  []v1beta1.TensorMetadata{{Name: "scores", Datatype: "FP32", Shape: []int64{-1, 1000}}},
+		},
+		Revision: "resnet-predictor-default-00002",
+	}))
+
+	for _, name := range []string{"v1", "not-ready", "no-metadata"} {
+		isvc = &v1beta1.InferenceService{}
+		g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, isvc)).To(gomega.Succeed())
+		g.Expect(isvc.Status.ModelMetadata).To(gomega.BeNil())
+	}
+	isvc = &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "collected", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ModelMetadata.Platform).To(gomega.Equal("mlflow"))
+}
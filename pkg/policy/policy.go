@@ -0,0 +1,142 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ModelDigestMissingViolation is the violation of the components with a storage uri and no model digest
+const ModelDigestMissingViolation = "model %s has no digest, set the modelDigest of the component"
+
+// digestPattern matches the digest a storage uri or an image reference is pinned to
+var digestPattern = regexp.MustCompile(`@(sha256|sha512):[0-9a-f]+$`)
+
+// Request is sent to the policy engines with the model and the images of a component before it is rolled out
+type Request struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Component string `json:"component"`
+	// Storage uri of the model downloaded by the storage initializer, empty when the model is in the image
+	StorageURI string `json:"storageUri,omitempty"`
+	// Digest of the model declared by the modelDigest of the component, or the digest the storage uri is pinned to,
+	// e.g. oci://models/iris@sha256:4b7d...
+	ModelDigest string `json:"modelDigest,omitempty"`
+	// Images of the containers and the init containers of the component
+	Images []Image `json:"images"`
+}
+
+// Image is the image of a container of a component
+type Image struct {
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	// Digest the image is pinned to, empty when the image is referenced by tag
+	Digest string `json:"digest,omitempty"`
+}
+
+// Decision is the verdict of a policy engine on a component
+type Decision struct {
+	Allowed bool `json:"allowed"`
+	// Reason the component is denied for
+	Reason string `json:"reason,omitempty"`
+}
+
+// Verifier verifies the model and the images of a component against a policy, e.g. their cosign signatures or an
+// OPA policy
+type Verifier interface {
+	Name() string
+	Verify(request *Request) (*Decision, error)
+}
+
+// Hook verifies the components with the policy engines before they are rolled out
+type Hook struct {
+	Verifiers []Verifier
+}
+
+// NewHook returns the hook verifying the components with the verifiers of the policy config
+func NewHook(policyConfig *v1beta1.PolicyConfig) *Hook {
+	hook := &Hook{}
+	for _, verifierConfig := range policyConfig.Verifiers {
+		hook.Verifiers = append(hook.Verifiers, NewWebhookVerifier(verifierConfig))
+	}
+	return hook
+}
+
+// NewRequest returns the verification request of the component deploying the model of the storage uri with the pod
+// spec, the declared model digest takes precedence over the digest the storage uri is pinned to
+func NewRequest(isvc *v1beta1.InferenceService, component v1beta1.ComponentType, storageURI string,
+	modelDigest string, podSpec *v1.PodSpec) *Request {
+	if modelDigest == "" {
+		modelDigest = referenceDigest(storageURI)
+	}
+	request := &Request{
+		Name:        isvc.Name,
+		Namespace:   isvc.Namespace,
+		Component:   string(component),
+		StorageURI:  storageURI,
+		ModelDigest: modelDigest,
+		Images:      []Image{},
+	}
+	for _, containers := range [][]v1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, container := range containers {
+			request.Images = append(request.Images, Image{
+				Container: container.Name,
+				Image:     container.Image,
+				Digest:    referenceDigest(container.Image),
+			})
+		}
+	}
+	return request
+}
+
+// Verify sends the request to the verifiers in order and returns the reasons the component is denied for, empty when
+// all the verifiers allow it. A verifier failing to verify the component denies it. A model without a digest is
+// denied, the verifiers could only check the storage uri and not the model the storage initializer downloads.
+func (h *Hook) Verify(request *Request) string {
+	violations := []string{}
+	if request.StorageURI != "" && request.ModelDigest == "" {
+		violations = append(violations, fmt.Sprintf(ModelDigestMissingViolation, request.StorageURI))
+	}
+	for _, verifier := range h.Verifiers {
+		decision, err := verifier.Verify(request)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s failed to verify: %v", verifier.Name(), err))
+			continue
+		}
+		if !decision.Allowed {
+			reason := decision.Reason
+			if reason == "" {
+				reason = "denied"
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s", verifier.Name(), reason))
+		}
+	}
+	return strings.Join(violations, ", ")
+}
+
+// referenceDigest returns the digest the reference is pinned to, empty when it is not pinned
+func referenceDigest(ref string) string {
+	if match := digestPattern.FindString(ref); match != "" {
+		return strings.TrimPrefix(match, "@")
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeVerifier struct {
+	name     string
+	decision *Decision
+	err      error
+}
+
+func (f *fakeVerifier) Name() string {
+	return f.name
+}
+
+func (f *fakeVerifier) Verify(request *Request) (*Decision, error) {
+	return f.decision, f.err
+}
+
+func TestNewRequest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: "sklearn", Namespace: "default"}}
+	podSpec := &v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "fetch", Image: "busybox:1.32"}},
+		Containers: []v1.Container{
+			{Name: "kfserving-container", Image: "kfserving/sklearnserver@sha256:4b7d0e1f"},
+		},
+	}
+
+	request := NewRequest(isvc, v1beta1.PredictorComponent, "oci://models/iris@sha256:9c2a", "", podSpec)
+	g.Expect(request).To(gomega.Equal(&Request{
+		Name:        "sklearn",
+		Namespace:   "default",
+		Component:   "predictor",
+		StorageURI:  "oci://models/iris@sha256:9c2a",
+		ModelDigest: "sha256:9c2a",
+		Images: []Image{
+			{Container: "fetch", Image: "busybox:1.32"},
+			{Container: "kfserving-container", Image: "kfserving/sklearnserver@sha256:4b7d0e1f", Digest: "sha256:4b7d0e1f"},
+		},
+	}))
+
+	request = NewRequest(isvc, v1beta1.PredictorComponent, "gs://models/iris", "", podSpec)
+	g.Expect(request.ModelDigest).To(gomega.BeEmpty())
+
+	// The declared model digest takes precedence over the digest of the storage uri
+	request = NewRequest(isvc, v1beta1.PredictorComponent, "gs://models/iris", "sha256:1f3e", podSpec)
+	g.Expect(request.ModelDigest).To(gomega.Equal("sha256:1f3e"))
+	request = NewRequest(isvc, v1beta1.PredictorComponent, "oci://models/iris@sha256:9c2a", "sha256:1f3e", podSpec)
+	g.Expect(request.ModelDigest).To(gomega.Equal("sha256:1f3e"))
+}
+
+func TestHookVerify(t *testing.T) {
+	scenarios := map[string]struct {
+		verifiers []Verifier
+		request   Request
+		expected  string
+	}{
+		"NoVerifiers": {
+			expected: "",
+		},
+		"Allowed": {
+			verifiers: []Verifier{
+				&fakeVerifier{name: "cosign", decision: &Decision{Allowed: true}},
+				&fakeVerifier{name: "opa", decision: &Decision{Allowed: true}},
+			},
+			expected: "",
+		},
+		"Denied": {
+			verifiers: []Verifier{
+				&fakeVerifier{name: "cosign", decision: &Decision{Reason: "no matching signatures"}},
+				&fakeVerifier{name: "opa", decision: &Decision{Allowed: true}},
+			},
+			expected: "cosign: no matching signatures",
+		},
+		"DeniedWithoutReason": {
+			verifiers: []Verifier{&fakeVerifier{name: "opa", decision: &Decision{}}},
+			expected:  "opa: denied",
+		},
+		"VerifierFailed": {
+			verifiers: []Verifier{
+				&fakeVerifier{name: "cosign", err: fmt.Errorf("connection refused")},
+				&fakeVerifier{name: "opa", decision: &Decision{Reason: "model is not pinned"}},
+			},
+			expected: "cosign failed to verify: connection refused, opa: model is not pinned",
+		},
+		"ModelDigest": {
+			verifiers: []Verifier{&fakeVerifier{name: "cosign", decision: &Decision{Allowed: true}}},
+			request:   Request{StorageURI: "gs://models/iris", ModelDigest: "sha256:9c2a"},
+			expected:  "",
+		},
+		"ModelDigestMissing": {
+			verifiers: []Verifier{&fakeVerifier{name: "cosign", decision: &Decision{Allowed: true}}},
+			request:   Request{StorageURI: "gs://models/iris"},
+			expected:  "model gs://models/iris has no digest, set the modelDigest of the component",
+		},
+		"ModelDigestMissingAndDenied": {
+			verifiers: []Verifier{&fakeVerifier{name: "opa", decision: &Decision{Reason: "image is not pinned"}}},
+			request:   Request{StorageURI: "gs://models/iris"},
+			expected:  "model gs://models/iris has no digest, set the modelDigest of the component, opa: image is not pinned",
+		},
+	}
+
+	for scenarioName, scenario := range scenarios {
+		t.Run(scenarioName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			hook := &Hook{Verifiers: scenario.verifiers}
+			g.Expect(hook.Verify(&scenario.request)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+// defaultTimeout is the timeout of a verification request when the verifier does not set one
+const defaultTimeout = 10 * time.Second
+
+// WebhookVerifier posts the verification requests under input to a policy engine, which answers with a Decision,
+// directly or under result as the OPA data API does.
+type WebhookVerifier struct {
+	config v1beta1.PolicyVerifierConfig
+	client *http.Client
+}
+
+// webhookRequest is the verification request posted to the policy engine, the input of the OPA data API
+type webhookRequest struct {
+	Input *Request `json:"input"`
+}
+
+// webhookResponse is the decision of the policy engine, or of the OPA data API under result
+type webhookResponse struct {
+	Decision
+	Result *Decision `json:"result,omitempty"`
+}
+
+func NewWebhookVerifier(config v1beta1.PolicyVerifierConfig) *WebhookVerifier {
+	timeout := defaultTimeout
+	if config.TimeoutSeconds != 0 {
+		timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+	return &WebhookVerifier{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *WebhookVerifier) Name() string {
+	return w.config.Name
+}
+
+// Verify posts the request to the policy engine. The component is allowed when the policy engine can not be reached
+// and the failure policy of the verifier is Ignore.
+func (w *WebhookVerifier) Verify(request *Request) (*Decision, error) {
+	decision, err := w.post(request)
+	if err != nil && w.config.FailurePolicy == v1beta1.PolicyFailurePolicyIgnore {
+		return &Decision{Allowed: true}, nil
+	}
+	return decision, err
+}
+
+func (w *WebhookVerifier) post(request *Request) (*Decision, error) {
+	body, err := json.Marshal(&webhookRequest{Input: request})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Post(w.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	response := &webhookResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if response.Result != nil {
+		return response.Result, nil
+	}
+	return &response.Decision, nil
+}
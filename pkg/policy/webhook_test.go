@@ -0,0 +1,96 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+)
+
+func TestWebhookVerifier(t *testing.T) {
+	scenarios := map[string]struct {
+		status        int
+		response      string
+		failurePolicy string
+		expected      *Decision
+		expectedErr   string
+	}{
+		"Allowed": {
+			status:   http.StatusOK,
+			response: `{"allowed": true}`,
+			expected: &Decision{Allowed: true},
+		},
+		"Denied": {
+			status:   http.StatusOK,
+			response: `{"allowed": false, "reason": "no matching signatures"}`,
+			expected: &Decision{Reason: "no matching signatures"},
+		},
+		"OPAResult": {
+			status:   http.StatusOK,
+			response: `{"result": {"allowed": false, "reason": "model is not pinned"}}`,
+			expected: &Decision{Reason: "model is not pinned"},
+		},
+		"ServerError": {
+			status:      http.StatusInternalServerError,
+			expectedErr: "unexpected status code 500",
+		},
+		"InvalidResponse": {
+			status:      http.StatusOK,
+			response:    `allowed`,
+			expectedErr: "invalid response: invalid character 'a' looking for beginning of value",
+		},
+		"ServerErrorIgnored": {
+			status:        http.StatusInternalServerError,
+			failurePolicy: v1beta1.PolicyFailurePolicyIgnore,
+			expected:      &Decision{Allowed: true},
+		},
+	}
+
+	for scenarioName, scenario := range scenarios {
+		t.Run(scenarioName, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := map[string]*Request{}
+				g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(gomega.Succeed())
+				g.Expect(body["input"]).To(gomega.Equal(&Request{Name: "sklearn", Namespace: "default",
+					Component: "predictor", Images: []Image{}}))
+				w.WriteHeader(scenario.status)
+				w.Write([]byte(scenario.response))
+			}))
+			defer server.Close()
+
+			verifier := NewWebhookVerifier(v1beta1.PolicyVerifierConfig{
+				Name:          "cosign",
+				URL:           server.URL,
+				FailurePolicy: scenario.failurePolicy,
+			})
+			decision, err := verifier.Verify(&Request{Name: "sklearn", Namespace: "default", Component: "predictor",
+				Images: []Image{}})
+			if scenario.expectedErr != "" {
+				g.Expect(err).To(gomega.MatchError(scenario.expectedErr))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(decision).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// timeout is the time a command waits for its reply on top of the time it blocks for
+	timeout = 10 * time.Second
+	// maxIdleConns is the number of connections kept open between the commands
+	maxIdleConns = 8
+)
+
+// Error is an error reply of the Redis server, the connection is still usable
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Client sends the commands of the Redis serialization protocol over a pool of connections
+type Client struct {
+	address string
+	idle    chan *conn
+}
+
+// NewClient creates a client of the Redis server at address
+func NewClient(address string) *Client {
+	return &Client{address: address, idle: make(chan *conn, maxIdleConns)}
+}
+
+// Do sends a command blocking for up to block and returns its reply: a string, an int64, a []byte, a []interface{}
+// or nil
+func (c *Client) Do(block time.Duration, args ...string) (interface{}, error) {
+	var cn *conn
+	select {
+	case cn = <-c.idle:
+	default:
+		netConn, err := net.DialTimeout("tcp", c.address, timeout)
+		if err != nil {
+			return nil, err
+		}
+		cn = &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+	}
+	reply, err := cn.do(block, args...)
+	if _, ok := err.(Error); err != nil && !ok {
+		cn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+	return reply, err
+}
+
+func (c *conn) do(block time.Duration, args ...string) (interface{}, error) {
+	if err := c.SetDeadline(time.Now().Add(block + timeout)); err != nil {
+		return nil, err
+	}
+	command := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		command += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c, command); err != nil {
+		return nil, err
+	}
+	return ReadReply(c.reader)
+}
+
+// ReadReply reads a reply of the Redis serialization protocol, also used to read the commands sent to a server
+func ReadReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, Error(value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, size)
+		for i := range values {
+			if values[i], err = ReadReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply %q", line)
+}
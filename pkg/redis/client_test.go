@@ -0,0 +1,120 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/redis"
+	"github.com/kubeflow/kfserving/pkg/redis/redistest"
+	"github.com/onsi/gomega"
+)
+
+func TestReadReply(t *testing.T) {
+	scenarios := map[string]struct {
+		reply    string
+		expected interface{}
+		err      error
+	}{
+		"SimpleString": {
+			reply:    "+OK\r\n",
+			expected: "OK",
+		},
+		"Error": {
+			reply: "-ERR unknown command\r\n",
+			err:   redis.Error("ERR unknown command"),
+		},
+		"Integer": {
+			reply:    ":42\r\n",
+			expected: int64(42),
+		},
+		"Bulk": {
+			reply:    "$5\r\nhello\r\n",
+			expected: []byte("hello"),
+		},
+		"NullBulk": {
+			reply: "$-1\r\n",
+		},
+		"Array": {
+			reply:    "*2\r\n$4\r\nkey1\r\n:1\r\n",
+			expected: []interface{}{[]byte("key1"), int64(1)},
+		},
+		"NullArray": {
+			reply: "*-1\r\n",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			reply, err := redis.ReadReply(bufio.NewReader(strings.NewReader(scenario.reply)))
+			if scenario.err != nil {
+				g.Expect(err).To(gomega.Equal(scenario.err))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			if scenario.expected == nil {
+				g.Expect(reply).To(gomega.BeNil())
+			} else {
+				g.Expect(reply).To(gomega.Equal(scenario.expected))
+			}
+		})
+	}
+
+	g := gomega.NewGomegaWithT(t)
+	for _, invalid := range []string{"OK\r\n", "+OK\n", "$5\r\nhel"} {
+		_, err := redis.ReadReply(bufio.NewReader(strings.NewReader(invalid)))
+		g.Expect(err).To(gomega.HaveOccurred(), invalid)
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := redistest.NewServer(t, "SET", "GET", "BRPOP")
+	defer server.Close()
+	client := redis.NewClient(server.Addr())
+
+	g.Expect(client.Do(0, "SET", "key", "value")).To(gomega.Equal("OK"))
+	g.Expect(client.Do(0, "GET", "key")).To(gomega.Equal([]byte("value")))
+	g.Expect(client.Do(0, "GET", "missing")).To(gomega.BeNil())
+	g.Expect(client.Do(0, "BRPOP", "list", "1")).To(gomega.BeNil())
+
+	// An error reply keeps the connection
+	_, err := client.Do(0, "LPUSH", "list", "value")
+	g.Expect(err).To(gomega.Equal(redis.Error("ERR unknown command")))
+	g.Expect(client.Do(0, "GET", "key")).To(gomega.Equal([]byte("value")))
+
+	// The idle connection is reused by the commands
+	g.Expect(server.Connections()).To(gomega.Equal(1))
+}
+
+func TestClientDropsBrokenConnection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	server := redistest.NewServer(t, "SET", "GET")
+	defer server.Close()
+	client := redis.NewClient(server.Addr())
+	g.Expect(client.Do(0, "SET", "key", "value")).To(gomega.Equal("OK"))
+
+	// The command sent on the broken idle connection fails and the connection is not reused
+	server.CloseConnections()
+	_, err := client.Do(0, "GET", "key")
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err).NotTo(gomega.BeAssignableToTypeOf(redis.Error("")))
+	g.Expect(client.Do(0, "GET", "key")).To(gomega.Equal([]byte("value")))
+	g.Expect(server.Connections()).To(gomega.Equal(2))
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redistest provides a fake Redis server for the tests of the Redis clients
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/redis"
+)
+
+// Server serves a set of the LPUSH, BRPOP, LLEN, SET, GET and DEL commands on in memory lists and keys, the other
+// commands are answered with an error. BRPOP does not block and the keys do not expire.
+type Server struct {
+	listener    net.Listener
+	commands    map[string]bool
+	mu          sync.Mutex
+	lists       map[string][]string
+	keys        map[string]string
+	conns       map[net.Conn]bool
+	connections int
+}
+
+// NewServer starts a server of the commands on a local port
+func NewServer(t *testing.T, commands ...string) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{
+		listener: listener,
+		commands: map[string]bool{},
+		lists:    map[string][]string{},
+		keys:     map[string]string{},
+		conns:    map[net.Conn]bool{},
+	}
+	for _, command := range commands {
+		server.commands[command] = true
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns[conn] = true
+			server.connections++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and closes its connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.CloseConnections()
+}
+
+// CloseConnections closes the open connections, the server keeps accepting new ones
+func (s *Server) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Connections returns the number of connections accepted by the server
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Keys returns a copy of the keys set on the server
+func (s *Server) Keys() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := map[string]string{}
+	for key, value := range s.keys {
+		keys[key] = value
+	}
+	return keys
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	reader := bufio.NewReader(conn)
+	for {
+		command, err := redis.ReadReply(reader)
+		if err != nil {
+			return
+		}
+		args := []string{}
+		for _, arg := range command.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+		if _, err := conn.Write([]byte(s.reply(args))); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) reply(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bulk := func(value string) string {
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	}
+	if !s.commands[args[0]] {
+		return "-ERR unknown command\r\n"
+	}
+	switch args[0] {
+	case "LPUSH":
+		s.lists[args[1]] = append([]string{args[2]}, s.lists[args[1]]...)
+		return fmt.Sprintf(":%d\r\n", len(s.lists[args[1]]))
+	case "BRPOP":
+		list := s.lists[args[1]]
+		if len(list) == 0 {
+			return "*-1\r\n"
+		}
+		s.lists[args[1]] = list[:len(list)-1]
+		return "*2\r\n" + bulk(args[1]) + bulk(list[len(list)-1])
+	case "LLEN":
+		return fmt.Sprintf(":%d\r\n", len(s.lists[args[1]]))
+	case "SET":
+		s.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.keys[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "DEL":
+		_, ok := s.keys[args[1]]
+		delete(s.keys, args[1])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
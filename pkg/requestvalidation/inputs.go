@@ -0,0 +1,122 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requestvalidation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+)
+
+// inferRequest is the part of a v2 infer request validated against the model metadata
+type inferRequest struct {
+	Inputs []inferInput `json:"inputs"`
+}
+
+type inferInput struct {
+	Name     string      `json:"name"`
+	Shape    []int64     `json:"shape"`
+	Datatype string      `json:"datatype"`
+	Data     interface{} `json:"data"`
+}
+
+// validateInputs validates the inputs of a v2 infer request against the inputs of the model metadata: each input of
+// the model is sent once with its datatype and a shape matching the shape of the model, -1 matching any size, and
+// the data of each input holds the number of elements of its shape
+func validateInputs(body []byte, metadata *v1beta1.ModelMetadata) error {
+	request := &inferRequest{}
+	if err := json.Unmarshal(body, request); err != nil {
+		return fmt.Errorf("invalid infer request: %v", err)
+	}
+	expected := map[string]v1beta1.TensorMetadata{}
+	var names []string
+	for _, input := range metadata.Inputs {
+		expected[input.Name] = input
+		names = append(names, input.Name)
+	}
+	sent := map[string]bool{}
+	for _, input := range request.Inputs {
+		tensor, ok := expected[input.Name]
+		if !ok {
+			return fmt.Errorf("unknown input %q, the inputs of the model are: [%s]", input.Name,
+				strings.Join(names, ", "))
+		}
+		if sent[input.Name] {
+			return fmt.Errorf("input %q is sent more than once", input.Name)
+		}
+		sent[input.Name] = true
+		if tensor.Datatype != "" && input.Datatype != tensor.Datatype {
+			return fmt.Errorf("input %q has datatype %s, the model expects %s", input.Name, input.Datatype,
+				tensor.Datatype)
+		}
+		if !matchShape(input.Shape, tensor.Shape) {
+			return fmt.Errorf("input %q has shape %v, the model expects %v", input.Name, input.Shape, tensor.Shape)
+		}
+		if input.Data != nil {
+			if elements, size := countElements(input.Data), shapeSize(input.Shape); elements != size {
+				return fmt.Errorf("input %q has %d elements, its shape %v holds %d", input.Name, elements,
+					input.Shape, size)
+			}
+		}
+	}
+	for _, name := range names {
+		if !sent[name] {
+			return fmt.Errorf("missing input %q", name)
+		}
+	}
+	return nil
+}
+
+// matchShape returns whether the shape of an input matches the shape of the model, an empty shape of the model
+// matching any shape
+func matchShape(shape []int64, expected []int64) bool {
+	if len(expected) == 0 {
+		return true
+	}
+	if len(shape) != len(expected) {
+		return false
+	}
+	for i := range shape {
+		if expected[i] != -1 && shape[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func shapeSize(shape []int64) int64 {
+	size := int64(1)
+	for _, dim := range shape {
+		size *= dim
+	}
+	return size
+}
+
+// countElements returns the number of elements of the data of an input, flat or nested in arrays
+func countElements(data interface{}) int64 {
+	values, ok := data.([]interface{})
+	if !ok {
+		return 1
+	}
+	var count int64
+	for _, value := range values {
+		count += countElements(value)
+	}
+	return count
+}
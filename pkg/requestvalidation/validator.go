@@ -0,0 +1,154 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requestvalidation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+)
+
+var (
+	predictPath = regexp.MustCompile(`^/v1/models/[^/]+:predict$`)
+	inferPath   = regexp.MustCompile(`^/v2/models/([^/]+)(/versions/[^/]+)?/infer$`)
+)
+
+// Validator validates the predict and infer requests of the predictor against the schema of the requests and the
+// model metadata of the model server, the malformed requests are rejected with a 400 and the other requests are
+// proxied to the predictor
+type Validator struct {
+	// Schema of the body of the predict and infer requests, nil to skip the schema validation
+	Schema *openapi3.Schema
+	// ModelMetadata validates the inputs of the v2 infer requests against the metadata of their model
+	ModelMetadata bool
+	// ComponentURL of the predictor, e.g. http://127.0.0.1:8080
+	ComponentURL string
+	Client       *http.Client
+	Log          logr.Logger
+	proxy        http.Handler
+	proxyOnce    sync.Once
+	// metadata of the models by name, read from the predictor on their first request
+	metadata   map[string]*v1beta1.ModelMetadata
+	metadataMu sync.Mutex
+}
+
+// NewValidator returns the validator of the request validation of a predictor
+func NewValidator(spec *v1beta1.RequestValidationSpec, componentURL string, client *http.Client,
+	log logr.Logger) (*Validator, error) {
+	schema, err := spec.ParseSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{
+		Schema:        schema,
+		ModelMetadata: spec.ModelMetadata,
+		ComponentURL:  componentURL,
+		Client:        client,
+		Log:           log,
+	}, nil
+}
+
+func (v *Validator) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost && (predictPath.MatchString(req.URL.Path) || inferPath.MatchString(req.URL.Path)) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := v.validate(req.URL.Path, body); err != nil {
+			writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	v.proxyOnce.Do(func() {
+		target, _ := url.Parse(v.ComponentURL)
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		// The streamed responses are passed through as they are written
+		proxy.FlushInterval = -1
+		v.proxy = proxy
+	})
+	v.proxy.ServeHTTP(rw, req)
+}
+
+// validate returns the error of a malformed request, nil when valid
+func (v *Validator) validate(path string, body []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("invalid JSON body: %v", err)
+	}
+	if v.Schema != nil {
+		if err := v.Schema.VisitJSON(value); err != nil {
+			return fmt.Errorf("the request does not match the schema: %v", err)
+		}
+	}
+	if match := inferPath.FindStringSubmatch(path); match != nil && v.ModelMetadata {
+		if metadata := v.getMetadata(match[1]); metadata != nil {
+			return validateInputs(body, metadata)
+		}
+	}
+	return nil
+}
+
+// getMetadata returns the metadata of the model, read from the predictor the first time. The requests are not
+// validated against the metadata while it can not be read, e.g. while the model loads.
+func (v *Validator) getMetadata(name string) *v1beta1.ModelMetadata {
+	v.metadataMu.Lock()
+	defer v.metadataMu.Unlock()
+	if metadata, ok := v.metadata[name]; ok {
+		return metadata
+	}
+	resp, err := v.Client.Get(strings.TrimSuffix(v.ComponentURL, "/") + constants.ModelMetadataPathV2(name))
+	if err != nil {
+		v.Log.Error(err, "Failed to read the model metadata", "model", name)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		v.Log.Info("Failed to read the model metadata", "model", name, "status", resp.StatusCode)
+		return nil
+	}
+	metadata := &v1beta1.ModelMetadata{}
+	if err := json.NewDecoder(resp.Body).Decode(metadata); err != nil {
+		v.Log.Error(err, "Invalid model metadata", "model", name)
+		return nil
+	}
+	if v.metadata == nil {
+		v.metadata = map[string]*v1beta1.ModelMetadata{}
+	}
+	v.metadata[name] = metadata
+	return metadata
+}
+
+func writeError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	rw.Write(body)
+}
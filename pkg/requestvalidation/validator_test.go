@@ -0,0 +1,171 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requestvalidation
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const cifarMetadata = `{"name":"cifar10","versions":["1"],"platform":"pytorch_libtorch",` +
+	`"inputs":[{"name":"INPUT__0","datatype":"FP32","shape":[-1,3,2,2]}],` +
+	`"outputs":[{"name":"OUTPUT__0","datatype":"FP32","shape":[-1,10]}]}`
+
+func TestValidator(t *testing.T) {
+	openapi3.SchemaErrorDetailsDisabled = true
+	defer func() { openapi3.SchemaErrorDetailsDisabled = false }()
+
+	// The predictor serves the metadata of cifar10, fails the metadata of the other models and answers the other
+	// requests with their path
+	var mu sync.Mutex
+	metadataRequests := 0
+	predictor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			mu.Lock()
+			metadataRequests++
+			mu.Unlock()
+			if req.URL.Path == "/v2/models/cifar10" {
+				rw.Write([]byte(cifarMetadata))
+				return
+			}
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.Write([]byte(`{"path":"` + req.URL.Path + `"}`))
+	}))
+	defer predictor.Close()
+
+	validator, err := NewValidator(&v1beta1.RequestValidationSpec{
+		Schema: &runtime.RawExtension{Raw: []byte(`{"type":"object","properties":{` +
+			`"instances":{"type":"array","minItems":1,"items":{"type":"array","items":{"type":"number"}}}}}`)},
+		ModelMetadata: true,
+	}, predictor.URL, &http.Client{}, logf.Log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(validator)
+	defer server.Close()
+
+	cifarData := "[" + strings.TrimSuffix(strings.Repeat("0.5,", 12), ",") + "]"
+	scenarios := map[string]struct {
+		path   string
+		body   string
+		status int
+		error  string
+	}{
+		"ValidV1": {
+			path:   "/v1/models/iris:predict",
+			body:   `{"instances":[[6.8,2.8,4.8,1.4]]}`,
+			status: http.StatusOK,
+		},
+		"SchemaMismatch": {
+			path:   "/v1/models/iris:predict",
+			body:   `{"instances":[["6.8"]]}`,
+			status: http.StatusBadRequest,
+			error:  "the request does not match the schema",
+		},
+		"InvalidJSON": {
+			path:   "/v1/models/iris:predict",
+			body:   `{"instances":`,
+			status: http.StatusBadRequest,
+			error:  "invalid JSON body",
+		},
+		"ValidV2": {
+			path: "/v2/models/cifar10/infer",
+			body: `{"inputs":[{"name":"INPUT__0","datatype":"FP32","shape":[1,3,2,2],"data":` + cifarData +
+				`}]}`,
+			status: http.StatusOK,
+		},
+		"NestedData": {
+			path: "/v2/models/cifar10/versions/1/infer",
+			body: `{"inputs":[{"name":"INPUT__0","datatype":"FP32","shape":[1,3,2,2],` +
+				`"data":[[[[1,2],[3,4]],[[1,2],[3,4]],[[1,2],[3,4]]]]}]}`,
+			status: http.StatusOK,
+		},
+		"UnknownInput": {
+			path:   "/v2/models/cifar10/infer",
+			body:   `{"inputs":[{"name":"input","datatype":"FP32","shape":[1,3,2,2],"data":` + cifarData + `}]}`,
+			status: http.StatusBadRequest,
+			error:  `unknown input \"input\", the inputs of the model are: [INPUT__0]`,
+		},
+		"WrongDatatype": {
+			path:   "/v2/models/cifar10/infer",
+			body:   `{"inputs":[{"name":"INPUT__0","datatype":"INT64","shape":[1,3,2,2],"data":` + cifarData + `}]}`,
+			status: http.StatusBadRequest,
+			error:  `input \"INPUT__0\" has datatype INT64, the model expects FP32`,
+		},
+		"WrongShape": {
+			path:   "/v2/models/cifar10/infer",
+			body:   `{"inputs":[{"name":"INPUT__0","datatype":"FP32","shape":[1,3,4],"data":` + cifarData + `}]}`,
+			status: http.StatusBadRequest,
+			error:  `input \"INPUT__0\" has shape [1 3 4], the model expects [-1 3 2 2]`,
+		},
+		"WrongNumberOfElements": {
+			path:   "/v2/models/cifar10/infer",
+			body:   `{"inputs":[{"name":"INPUT__0","datatype":"FP32","shape":[2,3,2,2],"data":` + cifarData + `}]}`,
+			status: http.StatusBadRequest,
+			error:  `input \"INPUT__0\" has 12 elements, its shape [2 3 2 2] holds 24`,
+		},
+		"MissingInput": {
+			path:   "/v2/models/cifar10/infer",
+			body:   `{"inputs":[]}`,
+			status: http.StatusBadRequest,
+			error:  `missing input \"INPUT__0\"`,
+		},
+		"UnavailableMetadata": {
+			path:   "/v2/models/mnist/infer",
+			body:   `{"inputs":[{"name":"input","datatype":"FP32","shape":[1],"data":[1]}]}`,
+			status: http.StatusOK,
+		},
+		"OtherRequest": {
+			path:   "/v1/models/iris:explain",
+			body:   `not json`,
+			status: http.StatusOK,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			resp, err := http.Post(server.URL+scenario.path, "application/json", strings.NewReader(scenario.body))
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			g.Expect(resp.StatusCode).To(gomega.Equal(scenario.status), string(body))
+			if scenario.error != "" {
+				g.Expect(string(body)).To(gomega.ContainSubstring(scenario.error))
+			} else {
+				g.Expect(string(body)).To(gomega.Equal(`{"path":"` + scenario.path + `"}`))
+			}
+		})
+	}
+
+	// The metadata of cifar10 is read once, the metadata of mnist on each request until it is served
+	mu.Lock()
+	defer mu.Unlock()
+	gomega.NewGomegaWithT(t).Expect(metadataRequests).To(gomega.Equal(2))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// CacheHeader tells the clients whether the response was cached, HIT, or sent by the component, MISS
+	CacheHeader = "X-Response-Cache"
+	CacheHit    = "HIT"
+	CacheMiss   = "MISS"
+)
+
+type contextKey struct{}
+
+// Cache answers the POST requests identical to a previous request with the cached response of the previous request,
+// the other requests are proxied to the component. The requests are identical when they have the same path, the same
+// query and the same body. Only the 200 responses are cached, the requests with a Cache-Control: no-cache header are
+// proxied to the component and their response is cached.
+type Cache struct {
+	// ComponentURL of the component, e.g. http://127.0.0.1:8080
+	ComponentURL string
+	Store        Store
+	Log          logr.Logger
+	proxy        http.Handler
+	proxyOnce    sync.Once
+}
+
+func (c *Cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	c.proxyOnce.Do(func() {
+		target, _ := url.Parse(c.ComponentURL)
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		proxy.ModifyResponse = c.cacheResponse
+		c.proxy = proxy
+	})
+	if req.Method != http.MethodPost {
+		c.proxy.ServeHTTP(rw, req)
+		return
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	key := requestKey(req, body)
+	if !strings.Contains(req.Header.Get("Cache-Control"), "no-cache") {
+		// The requests are sent to the component while the store fails
+		entry, err := c.Store.Get(key)
+		if err != nil {
+			c.Log.Error(err, "Failed to read the cached response")
+		}
+		if entry != nil {
+			if entry.ContentType != "" {
+				rw.Header().Set("Content-Type", entry.ContentType)
+			}
+			rw.Header().Set(CacheHeader, CacheHit)
+			rw.WriteHeader(http.StatusOK)
+			rw.Write(entry.Body)
+			return
+		}
+	}
+	c.proxy.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), contextKey{}, key)))
+}
+
+// cacheResponse caches the 200 responses of the POST requests
+func (c *Cache) cacheResponse(resp *http.Response) error {
+	key, ok := resp.Request.Context().Value(contextKey{}).(string)
+	if !ok {
+		return nil
+	}
+	resp.Header.Set(CacheHeader, CacheMiss)
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := c.Store.Set(key, &Entry{ContentType: resp.Header.Get("Content-Type"), Body: body}); err != nil {
+		c.Log.Error(err, "Failed to cache the response")
+	}
+	return nil
+}
+
+// requestKey returns the hash of the path, the query and the body of a request
+func requestKey(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.URL.Path + "?" + req.URL.RawQuery + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestCache(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// The component answers with the number of requests it received, the requests with a fail body fail
+	var mu sync.Mutex
+	requests := 0
+	component := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests++
+		count := requests
+		mu.Unlock()
+		body, _ := ioutil.ReadAll(req.Body)
+		if string(body) == "fail" {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(rw, `{"count":%d}`, count)
+	}))
+	defer component.Close()
+
+	store := NewMemoryStore(10, time.Minute)
+	server := httptest.NewServer(&Cache{ComponentURL: component.URL, Store: store, Log: logf.Log})
+	defer server.Close()
+
+	send := func(method string, path string, body string, header http.Header) (string, string, int) {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		defer resp.Body.Close()
+		response, _ := ioutil.ReadAll(resp.Body)
+		return string(response), resp.Header.Get(CacheHeader), resp.StatusCode
+	}
+
+	scenarios := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		header   http.Header
+		response string
+		cache    string
+		status   int
+	}{
+		{"FirstRequest", http.MethodPost, "/v1/models/iris:predict", `{"instances":[1]}`, nil,
+			`{"count":1}`, CacheMiss, http.StatusOK},
+		{"IdenticalRequest", http.MethodPost, "/v1/models/iris:predict", `{"instances":[1]}`, nil,
+			`{"count":1}`, CacheHit, http.StatusOK},
+		{"OtherBody", http.MethodPost, "/v1/models/iris:predict", `{"instances":[2]}`, nil,
+			`{"count":2}`, CacheMiss, http.StatusOK},
+		{"OtherPath", http.MethodPost, "/v1/models/iris:explain", `{"instances":[1]}`, nil,
+			`{"count":3}`, CacheMiss, http.StatusOK},
+		{"NoCache", http.MethodPost, "/v1/models/iris:predict", `{"instances":[1]}`,
+			http.Header{"Cache-Control": []string{"no-cache"}}, `{"count":4}`, CacheMiss, http.StatusOK},
+		{"NoCacheResponseCached", http.MethodPost, "/v1/models/iris:predict", `{"instances":[1]}`, nil,
+			`{"count":4}`, CacheHit, http.StatusOK},
+		{"Error", http.MethodPost, "/v1/models/iris:predict", "fail", nil,
+			`{"count":5}`, CacheMiss, http.StatusInternalServerError},
+		{"ErrorNotCached", http.MethodPost, "/v1/models/iris:predict", "fail", nil,
+			`{"count":6}`, CacheMiss, http.StatusInternalServerError},
+		{"Get", http.MethodGet, "/v1/models/iris", "", nil,
+			`{"count":7}`, "", http.StatusOK},
+	}
+	for _, scenario := range scenarios {
+		response, cache, status := send(scenario.method, scenario.path, scenario.body, scenario.header)
+		g.Expect(response).To(gomega.Equal(scenario.response), scenario.name)
+		g.Expect(cache).To(gomega.Equal(scenario.cache), scenario.name)
+		g.Expect(status).To(gomega.Equal(scenario.status), scenario.name)
+	}
+	g.Expect(store.Len()).To(gomega.Equal(3))
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"container/list"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/redis"
+)
+
+// Entry is a cached response
+type Entry struct {
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// Store keeps the cached responses until they expire
+type Store interface {
+	// Get returns the response cached for the key, nil when the key is unknown or its response expired
+	Get(key string) (*Entry, error)
+	// Set caches the response for the key
+	Set(key string, entry *Entry) error
+}
+
+type memoryEntry struct {
+	key     string
+	entry   *Entry
+	expires time.Time
+}
+
+// MemoryStore keeps up to maxEntries responses in memory, the least recently used responses are evicted first
+type MemoryStore struct {
+	maxEntries int
+	ttl        time.Duration
+	mu         sync.Mutex
+	// entries holds the *memoryEntry from the most to the least recently used
+	entries *list.List
+	keys    map[string]*list.Element
+}
+
+// NewMemoryStore creates a store caching up to maxEntries responses for ttl
+func NewMemoryStore(maxEntries int, ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    list.New(),
+		keys:       map[string]*list.Element{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.keys[key]
+	if !ok {
+		return nil, nil
+	}
+	cached := element.Value.(*memoryEntry)
+	if time.Now().After(cached.expires) {
+		s.entries.Remove(element)
+		delete(s.keys, key)
+		return nil, nil
+	}
+	s.entries.MoveToFront(element)
+	return cached.entry, nil
+}
+
+func (s *MemoryStore) Set(key string, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached := &memoryEntry{key: key, entry: entry, expires: time.Now().Add(s.ttl)}
+	if element, ok := s.keys[key]; ok {
+		element.Value = cached
+		s.entries.MoveToFront(element)
+		return nil
+	}
+	s.keys[key] = s.entries.PushFront(cached)
+	for s.entries.Len() > s.maxEntries {
+		oldest := s.entries.Back()
+		s.entries.Remove(oldest)
+		delete(s.keys, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses, including the expired responses not evicted yet
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries.Len()
+}
+
+// RedisStore keeps the responses in Redis keys expiring with their TTL, shared by the replicas of the component
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore creates a store caching the responses for ttl in the keys starting with prefix of the Redis server
+// at address
+func NewRedisStore(address string, prefix string, ttl time.Duration) *RedisStore {
+	return &RedisStore{
+		client: redis.NewClient(address),
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (s *RedisStore) Get(key string) (*Entry, error) {
+	value, err := s.client.Do(0, "GET", s.prefix+key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal(value.([]byte), entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *RedisStore) Set(key string, entry *Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Do(0, "SET", s.prefix+key, string(value), "EX", strconv.Itoa(int(s.ttl.Seconds())))
+	return err
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package responsecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/redis/redistest"
+	"github.com/onsi/gomega"
+)
+
+func TestStores(t *testing.T) {
+	server := redistest.NewServer(t, "SET", "GET")
+	defer server.Close()
+
+	for name, store := range map[string]Store{
+		"memory": NewMemoryStore(10, time.Minute),
+		"redis":  NewRedisStore(server.Addr(), "kfserving:cache:default:iris:", time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			entry := &Entry{ContentType: "application/json", Body: []byte(`{"predictions":[1]}`)}
+			g.Expect(store.Get("1")).To(gomega.BeNil())
+			g.Expect(store.Set("1", entry)).To(gomega.Succeed())
+			g.Expect(store.Get("1")).To(gomega.Equal(entry))
+		})
+	}
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(server.Keys()).To(gomega.HaveKey("kfserving:cache:default:iris:1"))
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	store := NewMemoryStore(2, time.Minute)
+	g.Expect(store.Set("1", &Entry{Body: []byte("1")})).To(gomega.Succeed())
+	g.Expect(store.Set("2", &Entry{Body: []byte("2")})).To(gomega.Succeed())
+	// Reading 1 makes 2 the least recently used
+	g.Expect(store.Get("1")).NotTo(gomega.BeNil())
+	g.Expect(store.Set("3", &Entry{Body: []byte("3")})).To(gomega.Succeed())
+	g.Expect(store.Len()).To(gomega.Equal(2))
+	g.Expect(store.Get("1")).NotTo(gomega.BeNil())
+	g.Expect(store.Get("2")).To(gomega.BeNil())
+	g.Expect(store.Get("3")).NotTo(gomega.BeNil())
+}
+
+func TestMemoryStoreEntryExpires(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	store := NewMemoryStore(2, time.Millisecond)
+	g.Expect(store.Set("1", &Entry{Body: []byte("1")})).To(gomega.Succeed())
+	time.Sleep(5 * time.Millisecond)
+	g.Expect(store.Get("1")).To(gomega.BeNil())
+	g.Expect(store.Len()).To(gomega.Equal(0))
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalingschedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Scheduler periodically records the active scaling window of the components of the InferenceServices in their
+// status. The status update triggers the reconciliation of the InferenceService, which applies the replica bounds of
+// the window to the Knative service of the component.
+type Scheduler struct {
+	Client client.Client
+	// Interval between the checks of the schedules, the windows start up to an interval late
+	Interval time.Duration
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	Log   logr.Logger
+}
+
+// Start checks the schedules on start then every interval until the manager stops
+func (s *Scheduler) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Schedule(context.TODO(), time.Now()); err != nil {
+			s.Log.Error(err, "Failed to schedule the scaling windows")
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Schedule updates the active scaling windows of the InferenceServices at the time, the InferenceServices whose
+// status can not be updated are retried on the next check
+func (s *Scheduler) Schedule(ctx context.Context, now time.Time) error {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := s.Client.List(ctx, isvcs); err != nil {
+		return errors.Wrapf(err, "fails to list inference services")
+	}
+	for i := range isvcs.Items {
+		isvc := &isvcs.Items[i]
+		if owned, err := s.Shard.Owns(isvc.Namespace); !owned {
+			if err != nil {
+				s.Log.Error(err, "Failed to check the shard", "namespace", isvc.Namespace, "name", isvc.Name)
+			}
+			continue
+		}
+		patched := isvc.DeepCopy()
+		if !setScalingSchedules(patched, now) {
+			continue
+		}
+		s.Log.Info("Updating the scaling windows", "namespace", isvc.Namespace, "name", isvc.Name)
+		if err := s.Client.Status().Patch(ctx, patched, client.MergeFrom(isvc)); err != nil {
+			s.Log.Error(err, "Failed to update the scaling windows", "namespace", isvc.Namespace, "name", isvc.Name)
+		}
+	}
+	return nil
+}
+
+// setScalingSchedules sets the active scaling window of each component in the status, it returns whether a window
+// started or ended
+func setScalingSchedules(isvc *v1beta1.InferenceService, now time.Time) bool {
+	extensions := map[v1beta1.ComponentType]*v1beta1.ComponentExtensionSpec{
+		v1beta1.PredictorComponent: &isvc.Spec.Predictor.ComponentExtensionSpec,
+	}
+	if isvc.Spec.Transformer != nil {
+		extensions[v1beta1.TransformerComponent] = &isvc.Spec.Transformer.ComponentExtensionSpec
+	}
+	if isvc.Spec.Explainer != nil {
+		extensions[v1beta1.ExplainerComponent] = &isvc.Spec.Explainer.ComponentExtensionSpec
+	}
+	changed := false
+	for component, extension := range extensions {
+		schedule := ""
+		if extension.Scaling != nil {
+			if active := extension.Scaling.ActiveSchedule(now); active != nil {
+				schedule = active.Schedule
+			}
+		}
+		statusSpec := isvc.Status.Components[component]
+		if statusSpec.ScalingSchedule == schedule {
+			continue
+		}
+		if isvc.Status.Components == nil {
+			isvc.Status.Components = map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{}
+		}
+		statusSpec.ScalingSchedule = schedule
+		isvc.Status.Components[component] = statusSpec
+		changed = true
+	}
+	return changed
+}
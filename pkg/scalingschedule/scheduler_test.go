@@ -0,0 +1,99 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalingschedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestSchedule(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	zeroReplicas := 0
+	scaling := &v1beta1.ScalingSpec{
+		Schedules: []v1beta1.ScalingSchedule{
+			{Schedule: "0 8 * * *", Duration: &metav1.Duration{Duration: 12 * time.Hour}},
+		},
+	}
+	scheduled := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "scheduled", Namespace: "default"},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MinReplicas: &zeroReplicas, Scaling: scaling},
+			},
+			Transformer: &v1beta1.TransformerSpec{},
+		},
+	}
+	unscheduled := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "unscheduled", Namespace: "default"},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, scheduled, unscheduled)
+	scheduler := &Scheduler{Client: cl, Log: logf.Log}
+	get := func(name string) *v1beta1.InferenceService {
+		isvc := &v1beta1.InferenceService{}
+		g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, isvc)).
+			To(gomega.Succeed())
+		return isvc
+	}
+
+	// The window starts at 8:00
+	g.Expect(scheduler.Schedule(context.TODO(), time.Date(2020, 10, 15, 9, 0, 0, 0, time.UTC))).To(gomega.Succeed())
+	isvc := get("scheduled")
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].ScalingSchedule).To(gomega.Equal("0 8 * * *"))
+	g.Expect(isvc.Status.Components[v1beta1.TransformerComponent].ScalingSchedule).To(gomega.BeEmpty())
+	g.Expect(get("unscheduled").Status.Components).To(gomega.BeEmpty())
+
+	// The window ends at 20:00
+	g.Expect(scheduler.Schedule(context.TODO(), time.Date(2020, 10, 15, 21, 0, 0, 0, time.UTC))).To(gomega.Succeed())
+	isvc = get("scheduled")
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].ScalingSchedule).To(gomega.BeEmpty())
+}
+
+func TestSetScalingSchedulesUnchanged(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	isvc := &v1beta1.InferenceService{
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{
+					Scaling: &v1beta1.ScalingSpec{
+						Schedules: []v1beta1.ScalingSchedule{{Schedule: "0 8 * * *"}},
+					},
+				},
+			},
+		},
+		Status: v1beta1.InferenceServiceStatus{
+			Components: map[v1beta1.ComponentType]v1beta1.ComponentStatusSpec{
+				v1beta1.PredictorComponent: {LatestReadyRevision: "scheduled-predictor-default-00001", ScalingSchedule: "0 8 * * *"},
+			},
+		},
+	}
+	g.Expect(setScalingSchedules(isvc, time.Date(2020, 10, 15, 9, 0, 0, 0, time.UTC))).To(gomega.BeFalse())
+	g.Expect(isvc.Status.Components[v1beta1.PredictorComponent].LatestReadyRevision).
+		To(gomega.Equal("scheduled-predictor-default-00001"))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sdk helps Go programs, e.g. CI pipelines and operators, work with InferenceServices and TrainedModels on
+// top of the generated typed client: waiting for them to be ready and sending them predictions.
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned"
+	servingv1beta1 "github.com/kubeflow/kfserving/pkg/client/clientset/versioned/typed/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultPollInterval is the interval between the checks of the readiness of a resource
+const DefaultPollInterval = 2 * time.Second
+
+// Client of the InferenceServices and the TrainedModels of a namespace
+type Client struct {
+	Serving servingv1beta1.ServingV1beta1Interface
+	// Predictions sends the predictions, with its credentials
+	Predictions predict.Client
+	Namespace   string
+	// PollInterval between the checks of the readiness, DefaultPollInterval when zero
+	PollInterval time.Duration
+}
+
+// NewForConfig creates the client of the namespace with the config of the API server, e.g. the config of
+// rest.InClusterConfig in a pod or of clientcmd out of the cluster
+func NewForConfig(config *rest.Config, namespace string) (*Client, error) {
+	serving, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Serving:     serving.ServingV1beta1(),
+		Predictions: predict.Client{HTTP: &http.Client{Timeout: time.Minute}},
+		Namespace:   namespace,
+	}, nil
+}
+
+func (c *Client) pollInterval() time.Duration {
+	if c.PollInterval == 0 {
+		return DefaultPollInterval
+	}
+	return c.PollInterval
+}
+
+// Predict sends the payload, in the format of the protocol of the predictor, to the predict endpoint of the
+// InferenceService and returns the response
+func (c *Client) Predict(ctx context.Context, isvc *v1beta1.InferenceService, payload []byte) ([]byte, error) {
+	return c.Predictions.Predict(ctx, isvc, payload)
+}
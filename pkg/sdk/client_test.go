@@ -0,0 +1,92 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	"knative.dev/pkg/apis"
+)
+
+func TestPredict(t *testing.T) {
+	scenarios := map[string]struct {
+		protocol constants.InferenceServiceProtocol
+		status   int
+		path     string
+		err      string
+	}{
+		"V1": {
+			status: http.StatusOK,
+			path:   "/v1/models/sklearn-iris:predict",
+		},
+		"V2": {
+			protocol: constants.ProtocolV2,
+			status:   http.StatusOK,
+			path:     "/v2/models/sklearn-iris/infer",
+		},
+		"Error": {
+			status: http.StatusServiceUnavailable,
+			path:   "/v1/models/sklearn-iris:predict",
+			err:    `prediction failed with status 503: {"predictions": [1, 1]}`,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			var path, request string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				body, _ := ioutil.ReadAll(r.Body)
+				request = string(body)
+				w.WriteHeader(scenario.status)
+				w.Write([]byte(`{"predictions": [1, 1]}`))
+			}))
+			defer server.Close()
+			u, _ := url.Parse(server.URL)
+
+			isvc := newInferenceService(apis.Condition{Status: "True"})
+			isvc.Spec.Predictor.SKLearn = &v1beta1.SKLearnSpec{
+				PredictorExtensionSpec: v1beta1.PredictorExtensionSpec{StorageURI: proto.String("gs://kfserving-samples/models/sklearn/iris")},
+			}
+			if scenario.protocol != "" {
+				isvc.Spec.Predictor.SKLearn.ProtocolVersion = &scenario.protocol
+			}
+			isvc.Status.URL = apis.HTTP(u.Host)
+			c := newClient()
+			c.Predictions.HTTP = server.Client()
+
+			response, err := c.Predict(context.Background(), isvc, []byte(`{"instances": [[6.8, 2.8, 4.8, 1.4]]}`))
+			if scenario.err != "" {
+				g.Expect(err).To(gomega.MatchError(scenario.err))
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+				g.Expect(string(response)).To(gomega.Equal(`{"predictions": [1, 1]}`))
+			}
+			g.Expect(path).To(gomega.Equal(scenario.path))
+			g.Expect(request).To(gomega.Equal(`{"instances": [[6.8, 2.8, 4.8, 1.4]]}`))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/apis"
+)
+
+// WaitForReady waits for the InferenceService to be ready and returns it, or returns an error with the reason it is not
+// ready once the timeout expires
+func (c *Client) WaitForReady(ctx context.Context, name string, timeout time.Duration) (*v1beta1.InferenceService, error) {
+	var isvc *v1beta1.InferenceService
+	err := c.poll(ctx, timeout, func() (bool, error) {
+		var err error
+		isvc, err = c.Serving.InferenceServices(c.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isvc.Status.IsReady(), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return isvc, fmt.Errorf("InferenceService %s is not ready after %s: %s", name, timeout,
+			reason(isvc.Status.GetCondition(apis.ConditionReady)))
+	}
+	return isvc, err
+}
+
+// WaitForTrainedModelReady waits for the TrainedModel to be ready, loaded by the model server of its InferenceService,
+// and returns it, or returns an error with the reason it is not ready once the timeout expires
+func (c *Client) WaitForTrainedModelReady(ctx context.Context, name string, timeout time.Duration) (*v1beta1.TrainedModel, error) {
+	var tm *v1beta1.TrainedModel
+	err := c.poll(ctx, timeout, func() (bool, error) {
+		var err error
+		tm, err = c.Serving.TrainedModels(c.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return tm.Status.GetCondition(apis.ConditionReady).IsTrue(), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return tm, fmt.Errorf("TrainedModel %s is not ready after %s: %s", name, timeout,
+			reason(tm.Status.GetCondition(apis.ConditionReady)))
+	}
+	return tm, err
+}
+
+// poll checks the condition at the poll interval until it is done, it fails, the timeout expires or the context is
+// cancelled, the cancellation of the context being returned as its error rather than as a timeout
+func (c *Client) poll(ctx context.Context, timeout time.Duration, condition wait.ConditionFunc) error {
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := wait.PollImmediateUntil(c.pollInterval(), condition, pollCtx.Done())
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func reason(condition *apis.Condition) string {
+	switch {
+	case condition == nil:
+		return "no Ready condition"
+	case condition.Message != "":
+		return condition.Message
+	case condition.Reason != "":
+		return condition.Reason
+	default:
+		return fmt.Sprintf("Ready is %s", condition.Status)
+	}
+}
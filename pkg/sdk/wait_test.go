@@ -0,0 +1,96 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned/fake"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func newClient(objects ...runtime.Object) *Client {
+	return &Client{
+		Serving:      fake.NewSimpleClientset(objects...).ServingV1beta1(),
+		Namespace:    "default",
+		PollInterval: 10 * time.Millisecond,
+	}
+}
+
+func newInferenceService(ready apis.Condition) *v1beta1.InferenceService {
+	ready.Type = apis.ConditionReady
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "sklearn-iris", Namespace: "default"},
+		Status: v1beta1.InferenceServiceStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{ready}},
+		},
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	// Ready once the controller updates the status
+	c := newClient(newInferenceService(apis.Condition{Status: "Unknown"}))
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		c.Serving.InferenceServices("default").UpdateStatus(newInferenceService(apis.Condition{Status: "True"}))
+	}()
+	isvc, err := c.WaitForReady(context.Background(), "sklearn-iris", 5*time.Second)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isvc.Status.IsReady()).To(gomega.BeTrue())
+
+	// Times out with the reason
+	c = newClient(newInferenceService(apis.Condition{Status: "False", Message: "Revision failed"}))
+	_, err = c.WaitForReady(context.Background(), "sklearn-iris", 50*time.Millisecond)
+	g.Expect(err).To(gomega.MatchError("InferenceService sklearn-iris is not ready after 50ms: Revision failed"))
+
+	// Fails when the InferenceService is not found
+	_, err = c.WaitForReady(context.Background(), "missing", 5*time.Second)
+	g.Expect(err).To(gomega.MatchError(`inferenceservices.serving.kubeflow.org "missing" not found`))
+
+	// Returns the cancellation of the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.WaitForReady(ctx, "sklearn-iris", 5*time.Second)
+	g.Expect(err).To(gomega.Equal(context.Canceled))
+}
+
+func TestWaitForTrainedModelReady(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	tm := &v1beta1.TrainedModel{
+		ObjectMeta: metav1.ObjectMeta{Name: "iris", Namespace: "default"},
+		Status: v1beta1.TrainedModelStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionReady, Status: "True"}}},
+		},
+	}
+	c := newClient(tm)
+	_, err := c.WaitForTrainedModelReady(context.Background(), "iris", 5*time.Second)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	tm.Status.Conditions = nil
+	c = newClient(tm)
+	_, err = c.WaitForTrainedModelReady(context.Background(), "iris", 50*time.Millisecond)
+	g.Expect(err).To(gomega.MatchError("TrainedModel iris is not ready after 50ms: no Ready condition"))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingmetrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The metrics of the Knative queue-proxy sidecars, the latencies are in milliseconds
+const (
+	requestCountMetric     = "revision_request_count"
+	requestLatenciesMetric = "revision_request_latencies_bucket"
+)
+
+// Aggregator periodically rolls up the metrics of the Knative queue-proxy of the component receiving the requests of
+// each ready InferenceService into its status
+type Aggregator struct {
+	Client  client.Client
+	Querier Querier
+	// Interval between the aggregations
+	Interval time.Duration
+	// Window is the time range of the rates
+	Window time.Duration
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	Log   logr.Logger
+}
+
+// Start aggregates the metrics every interval until the manager stops
+func (a *Aggregator) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			if err := a.Aggregate(context.TODO()); err != nil {
+				a.Log.Error(err, "Failed to aggregate the serving metrics")
+			}
+		}
+	}
+}
+
+// Aggregate updates the serving metrics of the ready InferenceServices, the InferenceServices whose metrics can not be
+// queried are skipped
+func (a *Aggregator) Aggregate(ctx context.Context) error {
+	isvcs := &v1beta1.InferenceServiceList{}
+	if err := a.Client.List(ctx, isvcs); err != nil {
+		return errors.Wrapf(err, "fails to list inference services")
+	}
+	for i := range isvcs.Items {
+		isvc := &isvcs.Items[i]
+		if !isvc.Status.IsReady() {
+			continue
+		}
+		if owned, err := a.Shard.Owns(isvc.Namespace); !owned {
+			if err != nil {
+				a.Log.Error(err, "Failed to check the shard", "namespace", isvc.Namespace, "name", isvc.Name)
+			}
+			continue
+		}
+		servingMetrics, err := a.query(ctx, isvc)
+		if err != nil {
+			a.Log.Error(err, "Failed to query the serving metrics", "namespace", isvc.Namespace, "name", isvc.Name)
+			continue
+		}
+		patched := isvc.DeepCopy()
+		patched.Status.ServingMetrics = servingMetrics
+		if err := a.Client.Status().Patch(ctx, patched, client.MergeFrom(isvc)); err != nil {
+			a.Log.Error(err, "Failed to update the serving metrics", "namespace", isvc.Namespace, "name", isvc.Name)
+		}
+	}
+	return nil
+}
+
+// query returns the request rate, the p99 latency and the error rate of the component receiving the requests
+func (a *Aggregator) query(ctx context.Context, isvc *v1beta1.InferenceService) (*v1beta1.ServingMetricsStatus, error) {
+	service := constants.DefaultPredictorServiceName(isvc.Name)
+	if isvc.Spec.Transformer != nil {
+		service = constants.DefaultTransformerServiceName(isvc.Name)
+	}
+	selector := fmt.Sprintf(`namespace_name=%q,service_name=%q`, isvc.Namespace, service)
+	metrics, err := QueryMetrics(ctx, a.Querier, selector, a.Window)
+	if err != nil {
+		return nil, err
+	}
+	servingMetrics := &v1beta1.ServingMetricsStatus{
+		RequestsPerSecond: formatValue(metrics.RequestsPerSecond),
+		ErrorPercent:      formatValue(metrics.ErrorPercent),
+		Window:            formatWindow(a.Window),
+		LastUpdateTime:    &metav1.Time{Time: time.Now()},
+	}
+	if metrics.P99LatencyMilliseconds != nil {
+		servingMetrics.P99LatencyMilliseconds = formatValue(*metrics.P99LatencyMilliseconds)
+	}
+	return servingMetrics, nil
+}
+
+// Metrics are the request rate, the p99 latency and the error rate of the requests over a window
+type Metrics struct {
+	RequestsPerSecond float64
+	// P99LatencyMilliseconds is nil without requests
+	P99LatencyMilliseconds *float64
+	ErrorPercent           float64
+}
+
+// QueryMetrics returns the metrics of the Knative queue-proxy sidecars matching the label selector, e.g.
+// revision_name="sklearn-predictor-default-00002"
+func QueryMetrics(ctx context.Context, querier Querier, selector string, window time.Duration) (*Metrics, error) {
+	promWindow := formatWindow(window)
+	requests := fmt.Sprintf(`sum(rate(%s{%s}[%s]))`, requestCountMetric, selector, promWindow)
+	latencies := fmt.Sprintf(`histogram_quantile(0.99, sum by (le) (rate(%s{%s}[%s])))`, requestLatenciesMetric,
+		selector, promWindow)
+	errorRate := fmt.Sprintf(`100 * sum(rate(%s{%s,response_code_class="5xx"}[%s])) / %s`, requestCountMetric,
+		selector, promWindow, requests)
+	// The queries have no sample, i.e. a zero value, when no request or no error was counted in the window, the
+	// latency is left unset without requests
+	metrics := &Metrics{}
+	var err error
+	if metrics.RequestsPerSecond, _, err = querier.Query(ctx, requests); err != nil {
+		return nil, err
+	}
+	latency, ok, err := querier.Query(ctx, latencies)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		metrics.P99LatencyMilliseconds = &latency
+	}
+	if metrics.ErrorPercent, _, err = querier.Query(ctx, errorRate); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// QueryLatencyPercentile returns the percentile of the request latency in milliseconds of the Knative queue-proxy
+// sidecars matching the label selector, nil without requests
+func QueryLatencyPercentile(ctx context.Context, querier Querier, selector string, percentile int64,
+	window time.Duration) (*float64, error) {
+	query := fmt.Sprintf(`histogram_quantile(%s, sum by (le) (rate(%s{%s}[%s])))`,
+		strconv.FormatFloat(float64(percentile)/100, 'f', -1, 64), requestLatenciesMetric, selector, formatWindow(window))
+	latency, ok, err := querier.Query(ctx, query)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &latency, nil
+}
+
+// QueryRequestCount returns the number of requests counted by the Knative queue-proxy sidecars matching the label
+// selector over the window, ok is false when Prometheus has no sample, e.g. before the first scrape
+func QueryRequestCount(ctx context.Context, querier Querier, selector string,
+	window time.Duration) (float64, bool, error) {
+	query := fmt.Sprintf(`sum(increase(%s{%s}[%s]))`, requestCountMetric, selector, formatWindow(window))
+	return querier.Query(ctx, query)
+}
+
+// formatValue formats a metric with two decimals
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// formatWindow formats a duration as a PromQL range, e.g. 5m or 90s
+func formatWindow(window time.Duration) string {
+	if window%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int64(window/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int64(window/time.Second))
+}
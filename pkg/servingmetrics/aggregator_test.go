@@ -0,0 +1,193 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingmetrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// fakeQuerier answers the queries with their values
+type fakeQuerier map[string]float64
+
+func (q fakeQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	value, ok := q[query]
+	return value, ok, nil
+}
+
+func makeInferenceService(name string, ready bool) *v1beta1.InferenceService {
+	isvc := &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+	}
+	status := v1.ConditionTrue
+	if !ready {
+		status = v1.ConditionFalse
+	}
+	isvc.Status.Conditions = append(isvc.Status.Conditions, apis.Condition{Type: apis.ConditionReady, Status: status})
+	return isvc
+}
+
+func TestPrometheusQuerier(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		response    string
+		expected    float64
+		expectedOk  bool
+		expectedErr string
+	}{
+		"Sample": {
+			response:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000.5,"12.5"]}]}}`,
+			expected:   12.5,
+			expectedOk: true,
+		},
+		"NoSample": {
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		},
+		"NaN": {
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000.5,"NaN"]}]}}`,
+		},
+		"Error": {
+			response:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			expectedErr: "parse error",
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				g.Expect(r.URL.Path).To(gomega.Equal("/api/v1/query"))
+				g.Expect(r.URL.Query().Get("query")).To(gomega.Equal("sum(up)"))
+				fmt.Fprint(w, scenario.response)
+			}))
+			defer server.Close()
+			querier := &PrometheusQuerier{URL: server.URL + "/"}
+			value, ok, err := querier.Query(context.TODO(), "sum(up)")
+			if scenario.expectedErr != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(scenario.expectedErr)))
+				return
+			}
+			g.Expect(err).ToNot(gomega.HaveOccurred())
+			g.Expect(ok).To(gomega.Equal(scenario.expectedOk))
+			g.Expect(value).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	withTransformer := makeInferenceService("with-transformer", true)
+	withTransformer.Spec.Transformer = &v1beta1.TransformerSpec{}
+	cl := fake.NewFakeClientWithScheme(scheme, makeInferenceService("ready", true),
+		makeInferenceService("not-ready", false), withTransformer)
+	aggregator := &Aggregator{
+		Client: cl,
+		Querier: fakeQuerier{
+			`sum(rate(revision_request_count{namespace_name="default",service_name="ready-predictor-default"}[5m]))`: 20,
+			`histogram_quantile(0.99, sum by (le) (rate(revision_request_latencies_bucket{namespace_name="default",` +
+				`service_name="ready-predictor-default"}[5m])))`: 84.127,
+			`100 * sum(rate(revision_request_count{namespace_name="default",service_name="ready-predictor-default",` +
+				`response_code_class="5xx"}[5m])) / sum(rate(revision_request_count{namespace_name="default",` +
+				`service_name="ready-predictor-default"}[5m]))`: 1.5,
+			`sum(rate(revision_request_count{namespace_name="default",` +
+				`service_name="with-transformer-transformer-default"}[5m]))`: 3,
+		},
+		Window: 5 * time.Minute,
+		Log:    logf.Log,
+	}
+	g.Expect(aggregator.Aggregate(context.TODO())).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "ready", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ServingMetrics).ToNot(gomega.BeNil())
+	g.Expect(isvc.Status.ServingMetrics.RequestsPerSecond).To(gomega.Equal("20.00"))
+	g.Expect(isvc.Status.ServingMetrics.P99LatencyMilliseconds).To(gomega.Equal("84.13"))
+	g.Expect(isvc.Status.ServingMetrics.ErrorPercent).To(gomega.Equal("1.50"))
+	g.Expect(isvc.Status.ServingMetrics.Window).To(gomega.Equal("5m"))
+
+	// The requests of an inference service with a transformer are received by the transformer
+	isvc = &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "with-transformer", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ServingMetrics.RequestsPerSecond).To(gomega.Equal("3.00"))
+
+	isvc = &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "not-ready", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ServingMetrics).To(gomega.BeNil())
+}
+
+func TestAggregateWithoutTraffic(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1beta1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	cl := fake.NewFakeClientWithScheme(scheme, makeInferenceService("idle", true))
+	aggregator := &Aggregator{Client: cl, Querier: fakeQuerier{}, Window: 90 * time.Second, Log: logf.Log}
+	g.Expect(aggregator.Aggregate(context.TODO())).To(gomega.Succeed())
+
+	isvc := &v1beta1.InferenceService{}
+	g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: "idle", Namespace: "default"}, isvc)).To(gomega.Succeed())
+	g.Expect(isvc.Status.ServingMetrics.RequestsPerSecond).To(gomega.Equal("0.00"))
+	g.Expect(isvc.Status.ServingMetrics.ErrorPercent).To(gomega.Equal("0.00"))
+	g.Expect(isvc.Status.ServingMetrics.P99LatencyMilliseconds).To(gomega.BeEmpty())
+	g.Expect(isvc.Status.ServingMetrics.Window).To(gomega.Equal("90s"))
+}
+
+func TestQueryLatencyPercentile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	selector := `namespace_name="default",service_name="sklearn-predictor-default"`
+	querier := fakeQuerier{
+		`histogram_quantile(0.95, sum by (le) (rate(revision_request_latencies_bucket{` + selector + `}[5m])))`: 120,
+	}
+	latency, err := QueryLatencyPercentile(context.TODO(), querier, selector, 95, 5*time.Minute)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(latency).NotTo(gomega.BeNil())
+	g.Expect(*latency).To(gomega.Equal(120.0))
+
+	latency, err = QueryLatencyPercentile(context.TODO(), querier, selector, 99, 5*time.Minute)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(latency).To(gomega.BeNil())
+}
+
+func TestQueryRequestCount(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	selector := `namespace_name="default",service_name="sklearn-predictor-default"`
+	querier := fakeQuerier{`sum(increase(revision_request_count{` + selector + `}[60m]))`: 0}
+	count, ok, err := QueryRequestCount(context.TODO(), querier, selector, time.Hour)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ok).To(gomega.BeTrue())
+	g.Expect(count).To(gomega.Equal(0.0))
+
+	// No sample is not read as no request
+	_, ok, err = QueryRequestCount(context.TODO(), querier, selector, 2*time.Hour)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(ok).To(gomega.BeFalse())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Querier evaluates a PromQL query returning a single sample
+type Querier interface {
+	// Query returns the value of the sample, ok is false when the query has no sample or the value is not a number
+	Query(ctx context.Context, query string) (value float64, ok bool, err error)
+}
+
+// PrometheusQuerier queries the HTTP API of a Prometheus server
+type PrometheusQuerier struct {
+	// URL of the Prometheus server, e.g. http://prometheus.istio-system:9090
+	URL    string
+	Client *http.Client
+}
+
+var _ Querier = &PrometheusQuerier{}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Query evaluates an instant query with the /api/v1/query endpoint
+func (q *PrometheusQuerier) Query(ctx context.Context, query string) (float64, bool, error) {
+	endpoint := strings.TrimSuffix(q.URL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	client := q.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	response := &queryResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return 0, false, fmt.Errorf("fails to decode the response of Prometheus with status %d: %v", resp.StatusCode, err)
+	}
+	if response.Status != "success" {
+		return 0, false, fmt.Errorf("Prometheus query %q failed: %s", query, response.Error)
+	}
+	if response.Data.ResultType != "vector" {
+		return 0, false, fmt.Errorf("Prometheus query %q returned a %s, expected a vector", query, response.Data.ResultType)
+	}
+	if len(response.Data.Result) == 0 {
+		return 0, false, nil
+	}
+	// The value of a sample is a [<timestamp>, "<value>"] pair
+	sample := response.Data.Result[0].Value
+	if len(sample) != 2 {
+		return 0, false, fmt.Errorf("Prometheus query %q returned a malformed sample", query)
+	}
+	text, ok := sample[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("Prometheus query %q returned a malformed sample", query)
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("Prometheus query %q returned a malformed sample: %v", query, err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// Shard is the part of the namespaces reconciled by a replica of the controller: the namespaces matching the
+// namespace selector and hashed into the index of the shard among the shards. The replicas hold the index of their
+// shard with a lease so that a shard is reconciled by a single replica. A nil Shard owns all the namespaces.
+// The controllers filter out the events of the namespaces of the other shards with Predicate and check Owns on
+// reconcile, the periodic runnables, e.g. the collectors and the schedulers, skip the namespaces Owns rejects.
+type Shard struct {
+	// Client reads the labels of the namespaces
+	Client client.Client
+	// Selector of the namespaces of the shards, all the namespaces when empty
+	Selector labels.Selector
+	// Count of the shards the namespaces are hashed into, the namespaces are not hashed when 0
+	Count int
+	// Index of the shard held by the replica, set by Acquire
+	Index int
+	// Leases, LeaseNamespace, LeaseName and Identity of the leases of the shards acquired when the shard is started by
+	// the manager. Owns waits for the lease when Leases is set. Lost is called when the lease is lost.
+	Leases         coordinationv1client.LeasesGetter
+	LeaseNamespace string
+	LeaseName      string
+	Identity       string
+	Lost           func()
+
+	acquiredOnce sync.Once
+	acquired     chan struct{}
+}
+
+// acquiredChannel returns the channel closed once the lease of the shard is acquired
+func (s *Shard) acquiredChannel() chan struct{} {
+	s.acquiredOnce.Do(func() {
+		s.acquired = make(chan struct{})
+	})
+	return s.acquired
+}
+
+// isAcquired returns whether the index of the shard is known, it always is when the shard does not wait for a lease
+func (s *Shard) isAcquired() bool {
+	if s.Leases == nil {
+		return true
+	}
+	select {
+	case <-s.acquiredChannel():
+		return true
+	default:
+		return false
+	}
+}
+
+// Start acquires the lease of a shard and blocks until the manager stops, the lease is then released. The shard is
+// started with the webhooks and the other runnables of the manager while the replicas without a shard wait.
+func (s *Shard) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	if err := s.Acquire(ctx, s.Leases, s.LeaseNamespace, s.LeaseName, s.Identity, s.Lost); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection is false, the leases of the shards replace the leader election
+func (s *Shard) NeedLeaderElection() bool {
+	return false
+}
+
+// Owns returns whether the namespace is in the shard, it waits for the lease of the shard when it is acquired by
+// Start
+func (s *Shard) Owns(namespace string) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+	if s.Leases != nil {
+		<-s.acquiredChannel()
+	}
+	if s.Count > 0 && Index(namespace, s.Count) != s.Index {
+		return false, nil
+	}
+	if s.Selector == nil || s.Selector.Empty() {
+		return true, nil
+	}
+	ns := &v1.Namespace{}
+	if err := s.Client.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return s.Selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// Index returns the shard the namespace is hashed into among count shards
+func Index(namespace string, count int) int {
+	h := fnv.New32a()
+	h.Write([]byte(namespace))
+	return int(h.Sum32() % uint32(count))
+}
+
+// LeaseName returns the name of the lease of a shard, the name of the leases when the namespaces are not hashed
+func LeaseName(name string, index int, count int) string {
+	if count == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, index)
+}
+
+// Acquire blocks until the replica holds the lease of a shard and sets the index of the shard. The replica races for
+// the leases of all the shards and keeps the first one it acquires, the replicas without a shard wait for a lease to
+// be released or to expire. lost is called when the lease of the shard is lost after it was acquired.
+func (s *Shard) Acquire(ctx context.Context, leases coordinationv1client.LeasesGetter, namespace string, name string,
+	identity string, lost func()) error {
+	count := s.Count
+	if count == 0 {
+		count = 1
+	}
+	acquired := make(chan int, count)
+	cancels := make([]context.CancelFunc, count)
+	var mu sync.Mutex
+	held := -1
+	for i := 0; i < count; i++ {
+		index := i
+		elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock: &resourcelock.LeaseLock{
+				LeaseMeta:  metav1.ObjectMeta{Name: LeaseName(name, index, s.Count), Namespace: namespace},
+				Client:     leases,
+				LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+			},
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) {
+					acquired <- index
+				},
+				OnStoppedLeading: func() {
+					mu.Lock()
+					defer mu.Unlock()
+					if held == index {
+						lost()
+					}
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		var electorCtx context.Context
+		electorCtx, cancels[index] = context.WithCancel(ctx)
+		go elector.Run(electorCtx)
+	}
+	select {
+	case index := <-acquired:
+		mu.Lock()
+		held = index
+		mu.Unlock()
+		// The leases of the other shards are released, including the ones acquired in the meantime
+		for i, cancel := range cancels {
+			if i != index {
+				cancel()
+			}
+		}
+		s.Index = index
+		close(s.acquiredChannel())
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Predicate filters out the events of the objects of the namespaces hashed into the other shards once the lease is
+// acquired. The objects of the KFServing namespace, e.g. the cluster configuration, and the cluster scoped objects
+// are kept as they map to the objects of all the namespaces, and the selector is only checked on reconcile.
+func (s *Shard) Predicate() predicate.Funcs {
+	keep := func(meta metav1.Object) bool {
+		if s == nil || s.Count == 0 || meta.GetNamespace() == "" || meta.GetNamespace() == constants.KFServingNamespace ||
+			!s.isAcquired() {
+			return true
+		}
+		return Index(meta.GetNamespace(), s.Count) == s.Index
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return keep(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return keep(e.MetaNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return keep(e.Meta) },
+		GenericFunc: func(e event.GenericEvent) bool { return keep(e.Meta) },
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestIndex(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	counts := make([]int, 4)
+	for i := 0; i < 400; i++ {
+		namespace := fmt.Sprintf("namespace-%d", i)
+		index := Index(namespace, 4)
+		g.Expect(index).To(gomega.Equal(Index(namespace, 4)))
+		counts[index]++
+	}
+	for _, count := range counts {
+		g.Expect(count).To(gomega.BeNumerically(">", 50))
+	}
+}
+
+func TestOwns(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cl := fake.NewFakeClientWithScheme(scheme,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tier": "gold"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tier": "silver"}}},
+	)
+	gold := labels.SelectorFromSet(labels.Set{"tier": "gold"})
+	scenarios := map[string]struct {
+		shard     *Shard
+		namespace string
+		matcher   gomega.OmegaMatcher
+		errored   bool
+	}{
+		"NilShard": {
+			shard:     nil,
+			namespace: "team-a",
+			matcher:   gomega.BeTrue(),
+		},
+		"HashedIntoShard": {
+			shard:     &Shard{Count: 3, Index: Index("team-a", 3)},
+			namespace: "team-a",
+			matcher:   gomega.BeTrue(),
+		},
+		"HashedIntoOtherShard": {
+			shard:     &Shard{Count: 3, Index: (Index("team-a", 3) + 1) % 3},
+			namespace: "team-a",
+			matcher:   gomega.BeFalse(),
+		},
+		"SelectedNamespace": {
+			shard:     &Shard{Client: cl, Selector: gold},
+			namespace: "team-a",
+			matcher:   gomega.BeTrue(),
+		},
+		"UnselectedNamespace": {
+			shard:     &Shard{Client: cl, Selector: gold},
+			namespace: "team-b",
+			matcher:   gomega.BeFalse(),
+		},
+		"EmptySelector": {
+			shard:     &Shard{Client: cl, Selector: labels.Everything()},
+			namespace: "team-b",
+			matcher:   gomega.BeTrue(),
+		},
+		"MissingNamespace": {
+			shard:     &Shard{Client: cl, Selector: gold},
+			namespace: "team-c",
+			matcher:   gomega.BeFalse(),
+			errored:   true,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			owned, err := scenario.shard.Owns(scenario.namespace)
+			if scenario.errored {
+				g.Expect(err).To(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+			g.Expect(owned).To(scenario.matcher)
+		})
+	}
+}
+
+func TestLeaseName(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(LeaseName("kfserving-controller-shard", 0, 0)).To(gomega.Equal("kfserving-controller-shard"))
+	g.Expect(LeaseName("kfserving-controller-shard", 2, 4)).To(gomega.Equal("kfserving-controller-shard-2"))
+}
+
+func TestStart(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	s := &Shard{
+		Count:          2,
+		Leases:         kubefake.NewSimpleClientset().CoordinationV1(),
+		LeaseNamespace: "kfserving-system",
+		LeaseName:      "kfserving-controller-shard",
+		Identity:       "replica-a",
+		Lost:           func() {},
+	}
+	namespaces := []string{}
+	for i := 0; len(namespaces) < 2; i++ {
+		namespace := fmt.Sprintf("namespace-%d", i)
+		if len(namespaces) == Index(namespace, 2) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	keep := func(namespace string) bool {
+		return s.Predicate().Create(event.CreateEvent{Meta: &metav1.ObjectMeta{Namespace: namespace}})
+	}
+
+	// The events are kept until the lease is acquired, the reconcilers wait for it
+	g.Expect(s.NeedLeaderElection()).To(gomega.BeFalse())
+	g.Expect(keep(namespaces[0])).To(gomega.BeTrue())
+	g.Expect(keep(namespaces[1])).To(gomega.BeTrue())
+	owned := make(chan bool)
+	go func() {
+		result, _ := s.Owns(namespaces[0])
+		owned <- result
+	}()
+	g.Consistently(owned, 100*time.Millisecond).ShouldNot(gomega.Receive())
+
+	stop := make(chan struct{})
+	stopped := make(chan error)
+	go func() {
+		stopped <- s.Start(stop)
+	}()
+	var result bool
+	g.Eventually(owned, 5*time.Second).Should(gomega.Receive(&result))
+	g.Expect(result).To(gomega.Equal(s.Index == 0))
+	g.Expect(keep(namespaces[s.Index])).To(gomega.BeTrue())
+	g.Expect(keep(namespaces[1-s.Index])).To(gomega.BeFalse())
+	g.Expect(keep("kfserving-system")).To(gomega.BeTrue())
+
+	close(stop)
+	g.Eventually(stopped, 5*time.Second).Should(gomega.Receive(gomega.BeNil()))
+}
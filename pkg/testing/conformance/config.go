@@ -0,0 +1,134 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance runs the end to end scenarios of KFServing, e.g. the deployment of an InferenceService per
+// framework or a canary rollout, against an install. Downstream distributions run them from a test of their own with
+// the config of their cluster:
+//
+//	func TestConformance(t *testing.T) {
+//		config, err := conformance.ConfigFromEnv()
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		h, err := conformance.NewHarness(config)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		h.Run(t, conformance.Scenarios()...)
+//	}
+package conformance
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// DefaultTimeout of the readiness of an InferenceService
+	DefaultTimeout = 5 * time.Minute
+	// DefaultScaleToZeroTimeout of the scale down of the pods of an idle InferenceService
+	DefaultScaleToZeroTimeout = 5 * time.Minute
+
+	// Environment variables of ConfigFromEnv
+	KubeconfigEnvVar = "KUBECONFIG"
+	NamespaceEnvVar  = "KFSERVING_CONFORMANCE_NAMESPACE"
+	IngressEnvVar    = "KFSERVING_CONFORMANCE_INGRESS"
+	TokenEnvVar      = "KFSERVING_CONFORMANCE_TOKEN"
+	APIKeyEnvVar     = "KFSERVING_CONFORMANCE_API_KEY"
+)
+
+// Config of the install under test
+type Config struct {
+	// RestConfig of the API server
+	RestConfig *rest.Config
+	// Namespace of the InferenceServices of the scenarios, it must exist
+	Namespace string
+	// Predictions sends the predictions of the scenarios, e.g. to the ingress gateway with the credentials of the
+	// install
+	Predictions predict.Client
+	// Timeout of the readiness of an InferenceService, DefaultTimeout when zero
+	Timeout time.Duration
+	// ScaleToZeroTimeout of the scale down of an idle InferenceService, DefaultScaleToZeroTimeout when zero
+	ScaleToZeroTimeout time.Duration
+	// StorageURIs replaces the storage URIs of the models of the scenarios, keyed by the default URI, e.g. with the
+	// URIs of the models mirrored in an air gapped install
+	StorageURIs map[string]string
+	// Images replaces the images of the scenarios, keyed by the default image
+	Images map[string]string
+	// KeepResources keeps the InferenceServices of the scenarios to debug them
+	KeepResources bool
+}
+
+// ConfigFromEnv returns the config of the cluster of the kubeconfig of KUBECONFIG, the default kubeconfig when unset,
+// and of the namespace of KFSERVING_CONFORMANCE_NAMESPACE. The predictions are sent to the ingress address of
+// KFSERVING_CONFORMANCE_INGRESS, the URL of the InferenceServices when unset, with the token of
+// KFSERVING_CONFORMANCE_TOKEN and the API key of KFSERVING_CONFORMANCE_API_KEY.
+func ConfigFromEnv() (*Config, error) {
+	namespace := os.Getenv(NamespaceEnvVar)
+	if namespace == "" {
+		return nil, fmt.Errorf("%s is not set", NamespaceEnvVar)
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = os.Getenv(KubeconfigEnvVar)
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		RestConfig: restConfig,
+		Namespace:  namespace,
+		Predictions: predict.Client{
+			Ingress: os.Getenv(IngressEnvVar),
+			Token:   os.Getenv(TokenEnvVar),
+			APIKey:  os.Getenv(APIKeyEnvVar),
+		},
+	}, nil
+}
+
+// StorageURI returns the storage URI of the model in the install
+func (c *Config) StorageURI(uri string) string {
+	if replacement, ok := c.StorageURIs[uri]; ok {
+		return replacement
+	}
+	return uri
+}
+
+// Image returns the image in the install
+func (c *Config) Image(image string) string {
+	if replacement, ok := c.Images[image]; ok {
+		return replacement
+	}
+	return image
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+func (c *Config) scaleToZeroTimeout() time.Duration {
+	if c.ScaleToZeroTimeout == 0 {
+		return DefaultScaleToZeroTimeout
+	}
+	return c.ScaleToZeroTimeout
+}
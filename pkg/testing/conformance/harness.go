@@ -0,0 +1,205 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/sdk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Scenario of the conformance test
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, h *Harness) error
+}
+
+// Harness runs the scenarios against the install of its config
+type Harness struct {
+	Config *Config
+	Client *sdk.Client
+	Kube   kubernetes.Interface
+	// InferenceServices created by the running scenario, deleted once it completes
+	created []string
+}
+
+// NewHarness creates the clients of the install of the config
+func NewHarness(config *Config) (*Harness, error) {
+	client, err := sdk.NewForConfig(config.RestConfig, config.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	client.Predictions = config.Predictions
+	if client.Predictions.HTTP == nil {
+		client.Predictions.HTTP = &http.Client{Timeout: time.Minute}
+	}
+	kube, err := kubernetes.NewForConfig(config.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Harness{Config: config, Client: client, Kube: kube}, nil
+}
+
+// Run runs each scenario as a subtest, deleting its InferenceServices once it completes
+func (h *Harness) Run(t *testing.T, scenarios ...Scenario) {
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			defer h.cleanup(t)
+			if err := scenario.Run(context.Background(), h); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func (h *Harness) cleanup(t *testing.T) {
+	created := h.created
+	h.created = nil
+	if h.Config.KeepResources {
+		return
+	}
+	for _, name := range created {
+		if err := h.Client.Serving.InferenceServices(h.Config.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil {
+			t.Logf("Failed to delete InferenceService %s: %v", name, err)
+		}
+	}
+}
+
+// Deploy creates the InferenceService in the namespace of the config and waits for it to be ready
+func (h *Harness) Deploy(ctx context.Context, isvc *v1beta1.InferenceService) (*v1beta1.InferenceService, error) {
+	isvc.Namespace = h.Config.Namespace
+	if _, err := h.Client.Serving.InferenceServices(isvc.Namespace).Create(isvc); err != nil {
+		return nil, err
+	}
+	h.created = append(h.created, isvc.Name)
+	return h.Client.WaitForReady(ctx, isvc.Name, h.Config.timeout())
+}
+
+// Update updates the spec of the InferenceService, retrying on conflict
+func (h *Harness) Update(name string, update func(isvc *v1beta1.InferenceService)) (*v1beta1.InferenceService, error) {
+	var updated *v1beta1.InferenceService
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		isvc, err := h.Client.Serving.InferenceServices(h.Config.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		update(isvc)
+		updated, err = h.Client.Serving.InferenceServices(h.Config.Namespace).Update(isvc)
+		return err
+	})
+	return updated, err
+}
+
+// WaitFor waits for the condition on the InferenceService, it fails with the description of the condition once the
+// timeout of the config expires
+func (h *Harness) WaitFor(ctx context.Context, name string, description string,
+	condition func(isvc *v1beta1.InferenceService) bool) (*v1beta1.InferenceService, error) {
+	var isvc *v1beta1.InferenceService
+	err := h.poll(ctx, h.Config.timeout(), func() (bool, error) {
+		var err error
+		isvc, err = h.Client.Serving.InferenceServices(h.Config.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return condition(isvc), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return isvc, fmt.Errorf("InferenceService %s is not %s after %s", name, description, h.Config.timeout())
+	}
+	return isvc, err
+}
+
+// WaitForScaleToZero waits for the pods of the component of the InferenceService to be deleted
+func (h *Harness) WaitForScaleToZero(ctx context.Context, name string, component v1beta1.ComponentType) error {
+	selector := fmt.Sprintf("%s=%s,%s=%s", constants.InferenceServicePodLabelKey, name,
+		constants.KServiceComponentLabel, component)
+	running := 0
+	err := h.poll(ctx, h.Config.scaleToZeroTimeout(), func() (bool, error) {
+		pods, err := h.Kube.CoreV1().Pods(h.Config.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return false, err
+		}
+		running = 0
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp == nil {
+				running++
+			}
+		}
+		return running == 0, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("the %s of InferenceService %s has %d pods after %s", component, name, running,
+			h.Config.scaleToZeroTimeout())
+	}
+	return err
+}
+
+func (h *Harness) poll(ctx context.Context, timeout time.Duration, condition wait.ConditionFunc) error {
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	interval := h.Client.PollInterval
+	if interval == 0 {
+		interval = sdk.DefaultPollInterval
+	}
+	return wait.PollImmediateUntil(interval, condition, pollCtx.Done())
+}
+
+// ExpectPredictions sends the request to the InferenceService and compares the predictions of the response with the
+// expected JSON
+func (h *Harness) ExpectPredictions(ctx context.Context, isvc *v1beta1.InferenceService, request string,
+	expected string) error {
+	predictions, err := h.Predictions(ctx, isvc, request)
+	if err != nil {
+		return err
+	}
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(predictions, want) {
+		got, _ := json.Marshal(predictions)
+		return fmt.Errorf("InferenceService %s predicted %s, expected %s", isvc.Name, got, expected)
+	}
+	return nil
+}
+
+// Predictions sends the request to the InferenceService and returns the predictions of the response
+func (h *Harness) Predictions(ctx context.Context, isvc *v1beta1.InferenceService, request string) (interface{}, error) {
+	body, err := h.Client.Predict(ctx, isvc, []byte(request))
+	if err != nil {
+		return nil, err
+	}
+	response := struct {
+		Predictions interface{} `json:"predictions"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid response of InferenceService %s: %v", isvc.Name, err)
+	}
+	return response.Predictions, nil
+}
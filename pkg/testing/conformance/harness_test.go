@@ -0,0 +1,215 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned/fake"
+	"github.com/kubeflow/kfserving/pkg/client/predict"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/sdk"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// TestConformance runs the scenarios against the cluster of the environment, it is skipped when no namespace is set
+func TestConformance(t *testing.T) {
+	if os.Getenv(NamespaceEnvVar) == "" {
+		t.Skipf("%s is not set", NamespaceEnvVar)
+	}
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHarness(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Run(t, Scenarios()...)
+}
+
+// newHarness returns a harness whose InferenceServices are ready once created, with the URL of the server
+func newHarness(server *httptest.Server, objects ...runtime.Object) *Harness {
+	serving := fake.NewSimpleClientset()
+	serving.PrependReactor("create", "inferenceservices", func(action ktesting.Action) (bool, runtime.Object, error) {
+		isvc := action.(ktesting.CreateAction).GetObject().(*v1beta1.InferenceService)
+		isvc.Status.URL = apis.HTTP(isvc.Name + "." + isvc.Namespace + ".example.com")
+		isvc.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionReady, Status: "True"}}
+		return false, nil, nil
+	})
+	config := &Config{
+		Namespace:          "default",
+		Predictions:        predict.Client{Ingress: server.URL, HTTP: server.Client()},
+		Timeout:            100 * time.Millisecond,
+		ScaleToZeroTimeout: 100 * time.Millisecond,
+	}
+	return &Harness{
+		Config: config,
+		Client: &sdk.Client{
+			Serving:      serving.ServingV1beta1(),
+			Predictions:  config.Predictions,
+			Namespace:    config.Namespace,
+			PollInterval: 10 * time.Millisecond,
+		},
+		Kube: kubefake.NewSimpleClientset(objects...),
+	}
+}
+
+func newServer(response string, paths *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.Host+r.URL.Path)
+		w.Write([]byte(response))
+	}))
+}
+
+func TestRun(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var paths []string
+	server := newServer(`{"predictions": [1, 1]}`, &paths)
+	defer server.Close()
+	h := newHarness(server)
+	h.Config.StorageURIs = map[string]string{SKLearnIrisURI: "s3://mirror/sklearn/iris"}
+
+	var deployed *v1beta1.InferenceService
+	h.Run(t, Scenario{Name: "SKLearn", Run: func(ctx context.Context, h *Harness) error {
+		if err := SKLearn(ctx, h); err != nil {
+			return err
+		}
+		var err error
+		deployed, err = h.Client.Serving.InferenceServices("default").Get("conformance-sklearn", metav1.GetOptions{})
+		return err
+	}})
+	g.Expect(*deployed.Spec.Predictor.SKLearn.StorageURI).To(gomega.Equal("s3://mirror/sklearn/iris"))
+	g.Expect(paths).To(gomega.Equal([]string{"conformance-sklearn.default.example.com/v1/models/conformance-sklearn:predict"}))
+
+	// The InferenceServices of the scenario are deleted once it completes
+	isvcs, err := h.Client.Serving.InferenceServices("default").List(metav1.ListOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isvcs.Items).To(gomega.BeEmpty())
+}
+
+func TestExpectPredictions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var paths []string
+	server := newServer(`{"predictions": [0, 1]}`, &paths)
+	defer server.Close()
+	h := newHarness(server)
+
+	err := SKLearn(context.Background(), h)
+	g.Expect(err).To(gomega.MatchError("InferenceService conformance-sklearn predicted [0,1], expected [1, 1]"))
+}
+
+func TestWaitFor(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var paths []string
+	server := newServer(`{"predictions": [1, 1]}`, &paths)
+	defer server.Close()
+	h := newHarness(server)
+
+	// The canary rollout times out as no controller updates the status
+	err := CanaryRollout(context.Background(), h)
+	g.Expect(err).To(gomega.MatchError("InferenceService conformance-canary is not rolled out to 10% of the traffic after 100ms"))
+	isvc, err := h.Client.Serving.InferenceServices("default").Get("conformance-canary", metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(*isvc.Spec.Predictor.CanaryTrafficPercent).To(gomega.Equal(int64(10)))
+	g.Expect(isvc.Spec.Predictor.SKLearn.Env).To(gomega.Equal([]v1.EnvVar{{Name: "CANARY", Value: "true"}}))
+}
+
+func TestWaitForScaleToZero(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var paths []string
+	server := newServer(`{"predictions": [1, 1]}`, &paths)
+	defer server.Close()
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "conformance-scale-to-zero-predictor-default-00001-deployment-1",
+			Namespace: "default",
+			Labels: map[string]string{
+				constants.InferenceServicePodLabelKey: "conformance-scale-to-zero",
+				constants.KServiceComponentLabel:      "predictor",
+			},
+		},
+	}
+
+	h := newHarness(server)
+	g.Expect(ScaleToZero(context.Background(), h)).To(gomega.Succeed())
+	g.Expect(paths).To(gomega.HaveLen(1))
+
+	h = newHarness(server, pod)
+	err := ScaleToZero(context.Background(), h)
+	g.Expect(err).To(gomega.MatchError("the predictor of InferenceService conformance-scale-to-zero has 1 pods after 100ms"))
+}
+
+func TestArgmax(t *testing.T) {
+	scenarios := map[string]struct {
+		predictions interface{}
+		expected    int
+	}{
+		"Batch": {
+			predictions: []interface{}{[]interface{}{0.1, 0.2, 0.05, 0.6, 0.05}},
+			expected:    3,
+		},
+		"Scores": {
+			predictions: []interface{}{0.7, 0.3},
+			expected:    0,
+		},
+		"Labels": {
+			predictions: []interface{}{"cat"},
+			expected:    -1,
+		},
+		"Empty": {
+			predictions: nil,
+			expected:    -1,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(argmax(scenario.predictions)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	config := &Config{
+		StorageURIs: map[string]string{SKLearnIrisURI: "s3://mirror/sklearn/iris"},
+		Images:      map[string]string{ImageTransformer: "registry.local/image-transformer:v1"},
+	}
+	g.Expect(config.StorageURI(SKLearnIrisURI)).To(gomega.Equal("s3://mirror/sklearn/iris"))
+	g.Expect(config.StorageURI(XGBoostIrisURI)).To(gomega.Equal(XGBoostIrisURI))
+	g.Expect(config.Image(ImageTransformer)).To(gomega.Equal("registry.local/image-transformer:v1"))
+	g.Expect(config.timeout()).To(gomega.Equal(DefaultTimeout))
+
+	if os.Getenv(NamespaceEnvVar) == "" {
+		_, err := ConfigFromEnv()
+		g.Expect(err).To(gomega.MatchError("KFSERVING_CONFORMANCE_NAMESPACE is not set"))
+	}
+}
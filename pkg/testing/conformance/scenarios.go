@@ -0,0 +1,213 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	SKLearnIrisURI     = "gs://kfserving-samples/models/sklearn/iris"
+	XGBoostIrisURI     = "gs://kfserving-samples/models/xgboost/iris"
+	PyTorchCifar10URI  = "gs://kfserving-samples/models/pytorch/cifar10"
+	ImageTransformer   = "gcr.io/kubeflow-ci/kfserving/image-transformer:latest"
+	irisRequest        = `{"instances": [[6.8, 2.8, 4.8, 1.4], [6.0, 3.4, 4.5, 1.6]]}`
+	irisPredictions    = `[1, 1]`
+	cifar10Predictions = 3
+)
+
+// Scenarios returns all the scenarios
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "SKLearn", Run: SKLearn},
+		{Name: "XGBoost", Run: XGBoost},
+		{Name: "CanaryRollout", Run: CanaryRollout},
+		{Name: "TransformerChain", Run: TransformerChain},
+		{Name: "ScaleToZero", Run: ScaleToZero},
+	}
+}
+
+// resources of the containers of the scenarios, small enough for the clusters of CI
+var resources = v1.ResourceRequirements{
+	Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+	Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+}
+
+func newInferenceService(name string, minReplicas int) *v1beta1.InferenceService {
+	return &v1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1beta1.InferenceServiceSpec{
+			Predictor: v1beta1.PredictorSpec{
+				ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MinReplicas: &minReplicas},
+			},
+		},
+	}
+}
+
+func (h *Harness) predictorExtension(storageURI string) v1beta1.PredictorExtensionSpec {
+	uri := h.Config.StorageURI(storageURI)
+	return v1beta1.PredictorExtensionSpec{
+		StorageURI: &uri,
+		Container:  v1.Container{Resources: resources},
+	}
+}
+
+// SKLearn deploys the sklearn iris model and checks its predictions
+func SKLearn(ctx context.Context, h *Harness) error {
+	isvc := newInferenceService("conformance-sklearn", 1)
+	isvc.Spec.Predictor.SKLearn = &v1beta1.SKLearnSpec{PredictorExtensionSpec: h.predictorExtension(SKLearnIrisURI)}
+	isvc, err := h.Deploy(ctx, isvc)
+	if err != nil {
+		return err
+	}
+	return h.ExpectPredictions(ctx, isvc, irisRequest, irisPredictions)
+}
+
+// XGBoost deploys the xgboost iris model and checks its predictions
+func XGBoost(ctx context.Context, h *Harness) error {
+	isvc := newInferenceService("conformance-xgboost", 1)
+	isvc.Spec.Predictor.XGBoost = &v1beta1.XGBoostSpec{PredictorExtensionSpec: h.predictorExtension(XGBoostIrisURI)}
+	isvc, err := h.Deploy(ctx, isvc)
+	if err != nil {
+		return err
+	}
+	return h.ExpectPredictions(ctx, isvc, irisRequest, irisPredictions)
+}
+
+// CanaryRollout rolls out a new revision of the predictor to 10% of the traffic, checks the split of the traffic and
+// the predictions during the rollout, then promotes the new revision
+func CanaryRollout(ctx context.Context, h *Harness) error {
+	isvc := newInferenceService("conformance-canary", 1)
+	isvc.Spec.Predictor.SKLearn = &v1beta1.SKLearnSpec{PredictorExtensionSpec: h.predictorExtension(SKLearnIrisURI)}
+	isvc, err := h.Deploy(ctx, isvc)
+	if err != nil {
+		return err
+	}
+	previous := isvc.Status.Components[v1beta1.PredictorComponent].LatestReadyRevision
+
+	// A change of the environment of the model server creates a new revision of the same model
+	if _, err := h.Update(isvc.Name, func(isvc *v1beta1.InferenceService) {
+		isvc.Spec.Predictor.CanaryTrafficPercent = proto.Int64(10)
+		isvc.Spec.Predictor.SKLearn.Env = append(isvc.Spec.Predictor.SKLearn.Env, v1.EnvVar{Name: "CANARY", Value: "true"})
+	}); err != nil {
+		return err
+	}
+	isvc, err = h.WaitFor(ctx, isvc.Name, "rolled out to 10% of the traffic", func(isvc *v1beta1.InferenceService) bool {
+		status := isvc.Status.Components[v1beta1.PredictorComponent]
+		return isvc.Status.IsReady() && status.LatestReadyRevision != previous &&
+			status.PreviousReadyRevision == previous && status.TrafficPercent != nil && *status.TrafficPercent == 10
+	})
+	if err != nil {
+		return err
+	}
+	if err := h.ExpectPredictions(ctx, isvc, irisRequest, irisPredictions); err != nil {
+		return err
+	}
+
+	if _, err := h.Update(isvc.Name, func(isvc *v1beta1.InferenceService) {
+		isvc.Spec.Predictor.CanaryTrafficPercent = nil
+	}); err != nil {
+		return err
+	}
+	isvc, err = h.WaitFor(ctx, isvc.Name, "promoted", func(isvc *v1beta1.InferenceService) bool {
+		status := isvc.Status.Components[v1beta1.PredictorComponent]
+		return isvc.Status.IsReady() && status.LatestReadyRevision != previous &&
+			(status.TrafficPercent == nil || *status.TrafficPercent == 100)
+	})
+	if err != nil {
+		return err
+	}
+	return h.ExpectPredictions(ctx, isvc, irisRequest, irisPredictions)
+}
+
+// TransformerChain deploys the pytorch cifar10 model behind the image transformer, which converts the images of the
+// requests to tensors, and checks the class predicted for the image of a cat
+func TransformerChain(ctx context.Context, h *Harness) error {
+	isvc := newInferenceService("conformance-transformer", 1)
+	isvc.Spec.Predictor.PyTorch = &v1beta1.TorchServeSpec{
+		ModelClassName:         "Net",
+		PredictorExtensionSpec: h.predictorExtension(PyTorchCifar10URI),
+	}
+	minReplicas := 1
+	isvc.Spec.Transformer = &v1beta1.TransformerSpec{
+		ComponentExtensionSpec: v1beta1.ComponentExtensionSpec{MinReplicas: &minReplicas},
+		PodSpec: v1beta1.PodSpec{
+			Containers: []v1.Container{{
+				Name:      "kfserving-container",
+				Image:     h.Config.Image(ImageTransformer),
+				Resources: resources,
+			}},
+		},
+	}
+	isvc, err := h.Deploy(ctx, isvc)
+	if err != nil {
+		return err
+	}
+	predictions, err := h.Predictions(ctx, isvc, transformerRequest)
+	if err != nil {
+		return err
+	}
+	if class := argmax(predictions); class != cifar10Predictions {
+		return fmt.Errorf("InferenceService %s predicted class %d, expected %d", isvc.Name, class, cifar10Predictions)
+	}
+	return nil
+}
+
+// ScaleToZero deploys a predictor scaling to zero, waits for its pods to be deleted once idle and checks that a
+// request scales it up again
+func ScaleToZero(ctx context.Context, h *Harness) error {
+	isvc := newInferenceService("conformance-scale-to-zero", 0)
+	isvc.Spec.Predictor.SKLearn = &v1beta1.SKLearnSpec{PredictorExtensionSpec: h.predictorExtension(SKLearnIrisURI)}
+	isvc, err := h.Deploy(ctx, isvc)
+	if err != nil {
+		return err
+	}
+	if err := h.WaitForScaleToZero(ctx, isvc.Name, v1beta1.PredictorComponent); err != nil {
+		return err
+	}
+	return h.ExpectPredictions(ctx, isvc, irisRequest, irisPredictions)
+}
+
+// argmax returns the index of the highest score of the first prediction, -1 when the predictions are not scores
+func argmax(predictions interface{}) int {
+	list, ok := predictions.([]interface{})
+	if !ok || len(list) == 0 {
+		return -1
+	}
+	scores, ok := list[0].([]interface{})
+	if !ok {
+		scores = list
+	}
+	index, highest := -1, 0.0
+	for i, score := range scores {
+		value, ok := score.(float64)
+		if !ok {
+			return -1
+		}
+		if index == -1 || value > highest {
+			index, highest = i, value
+		}
+	}
+	return index
+}
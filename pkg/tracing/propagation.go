@@ -0,0 +1,57 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"net/http"
+)
+
+// The W3C trace context and baggage headers, see https://www.w3.org/TR/trace-context/
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+	BaggageHeader     = "baggage"
+)
+
+// PropagationHeaders are the headers forwarded by the sidecars so the spans of the components of an inference service
+// are part of the same trace
+var PropagationHeaders = []string{TraceParentHeader, TraceStateHeader, BaggageHeader}
+
+// Propagate copies the trace context of the incoming request headers to the headers of an outgoing request
+func Propagate(dst http.Header, src http.Header) {
+	for _, name := range PropagationHeaders {
+		key := http.CanonicalHeaderKey(name)
+		if values := src[key]; len(values) > 0 {
+			dst[key] = append([]string(nil), values...)
+		}
+	}
+}
+
+// Extract returns the trace context of the request headers, nil when the request is not traced
+func Extract(src http.Header) http.Header {
+	var header http.Header
+	for _, name := range PropagationHeaders {
+		key := http.CanonicalHeaderKey(name)
+		if values := src[key]; len(values) > 0 {
+			if header == nil {
+				header = http.Header{}
+			}
+			header[key] = append([]string(nil), values...)
+		}
+	}
+	return header
+}
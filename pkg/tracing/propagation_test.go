@@ -0,0 +1,74 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestPropagate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		src      http.Header
+		dst      http.Header
+		expected http.Header
+	}{
+		"TracedRequest": {
+			src: http.Header{
+				"Traceparent":  {traceParent},
+				"Tracestate":   {"congo=t61rcWkgMzE"},
+				"Baggage":      {"userId=alice"},
+				"Content-Type": {"application/json"},
+			},
+			dst: http.Header{"Content-Type": {"application/json"}},
+			expected: http.Header{
+				"Traceparent":  {traceParent},
+				"Tracestate":   {"congo=t61rcWkgMzE"},
+				"Baggage":      {"userId=alice"},
+				"Content-Type": {"application/json"},
+			},
+		},
+		"UntracedRequest": {
+			src:      http.Header{"Content-Type": {"application/json"}},
+			dst:      http.Header{"Content-Type": {"application/json"}},
+			expected: http.Header{"Content-Type": {"application/json"}},
+		},
+		"OverridesTraceContext": {
+			src:      http.Header{"Traceparent": {traceParent}},
+			dst:      http.Header{"Traceparent": {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}},
+			expected: http.Header{"Traceparent": {traceParent}},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			Propagate(scenario.dst, scenario.src)
+			g.Expect(scenario.dst).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	g.Expect(Extract(http.Header{"Content-Type": {"application/json"}})).To(gomega.BeNil())
+	g.Expect(Extract(http.Header{"Traceparent": {traceParent}, "Content-Type": {"application/json"}})).To(
+		gomega.Equal(http.Header{"Traceparent": {traceParent}}))
+}
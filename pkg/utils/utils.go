@@ -17,6 +17,8 @@ limitations under the License.
 package utils
 
 import (
+	"strings"
+
 	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
 )
@@ -65,9 +67,56 @@ func AppendVolumeIfNotExists(slice []v1.Volume, volume v1.Volume) []v1.Volume {
 	return append(slice, volume)
 }
 
+// MergeImagePullSecrets returns a new slice of the image pull secrets followed by the defaults they do not reference
+func MergeImagePullSecrets(secrets []v1.LocalObjectReference, defaults []v1.LocalObjectReference) []v1.LocalObjectReference {
+	if len(defaults) == 0 {
+		return secrets
+	}
+	merged := append([]v1.LocalObjectReference{}, secrets...)
+	for _, secret := range defaults {
+		found := false
+		for _, existing := range merged {
+			if existing.Name == secret.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, secret)
+		}
+	}
+	return merged
+}
+
+// IsGPUEnabled returns whether the resources limit whole GPUs, MIG instances or time-sliced GPUs
 func IsGPUEnabled(requirements v1.ResourceRequirements) bool {
-	_, ok := requirements.Limits[constants.NvidiaGPUResourceType]
-	return ok
+	for name := range requirements.Limits {
+		if IsGPUResource(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGPUResource returns whether the resource is a whole GPU, a MIG instance or a time-sliced GPU
+func IsGPUResource(name v1.ResourceName) bool {
+	return strings.HasPrefix(string(name), constants.NvidiaGPUResourceType) ||
+		strings.HasPrefix(string(name), constants.NvidiaMIGResourcePrefix)
+}
+
+// InDomains returns whether the host is one of the domains or a subdomain of one of them
+func InDomains(host string, domains []string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
 }
 
 // FirstNonNilError returns the first non nil interface in the slice
@@ -19,6 +19,7 @@ package utils
 import (
 	"github.com/kubeflow/kfserving/pkg/credentials/gcs"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -238,3 +239,67 @@ func TestAppendVolumeIfNotExists(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeImagePullSecrets(t *testing.T) {
+	scenarios := map[string]struct {
+		secrets         []v1.LocalObjectReference
+		defaults        []v1.LocalObjectReference
+		expectedSecrets []v1.LocalObjectReference
+	}{
+		"NoDefaults": {
+			secrets:         []v1.LocalObjectReference{{Name: "team-registry"}},
+			expectedSecrets: []v1.LocalObjectReference{{Name: "team-registry"}},
+		},
+		"AppendDefaults": {
+			secrets:         []v1.LocalObjectReference{{Name: "team-registry"}},
+			defaults:        []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+			expectedSecrets: []v1.LocalObjectReference{{Name: "team-registry"}, {Name: "kfserving-registry"}},
+		},
+		"SkipReferencedDefaults": {
+			secrets:         []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+			defaults:        []v1.LocalObjectReference{{Name: "kfserving-registry"}, {Name: "mirror-registry"}},
+			expectedSecrets: []v1.LocalObjectReference{{Name: "kfserving-registry"}, {Name: "mirror-registry"}},
+		},
+		"OnlyDefaults": {
+			defaults:        []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+			expectedSecrets: []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		merged := MergeImagePullSecrets(scenario.secrets, scenario.defaults)
+
+		if diff := cmp.Diff(scenario.expectedSecrets, merged); diff != "" {
+			t.Errorf("Test %q unexpected image pull secrets (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestIsGPUEnabled(t *testing.T) {
+	scenarios := map[string]struct {
+		resources v1.ResourceRequirements
+		expected  bool
+	}{
+		"GPU": {
+			resources: v1.ResourceRequirements{Limits: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")}},
+			expected:  true,
+		},
+		"MIGProfile": {
+			resources: v1.ResourceRequirements{Limits: v1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("1")}},
+			expected:  true,
+		},
+		"TimeSlicedGPU": {
+			resources: v1.ResourceRequirements{Limits: v1.ResourceList{"nvidia.com/gpu.shared": resource.MustParse("1")}},
+			expected:  true,
+		},
+		"CPU": {
+			resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+			expected:  false,
+		},
+	}
+	for name, scenario := range scenarios {
+		if enabled := IsGPUEnabled(scenario.resources); enabled != scenario.expected {
+			t.Errorf("Test %q expected %v, got %v", name, scenario.expected, enabled)
+		}
+	}
+}
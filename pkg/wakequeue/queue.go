@@ -0,0 +1,308 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wakequeue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/shard"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ScaleFromZeroConfigMapKeyName = "scaleFromZero"
+	DefaultWakeTimeoutSeconds     = 600
+	// schedulerNameField indexes the cached pods by scheduler name
+	schedulerNameField = "spec.schedulerName"
+)
+
+// Config is the admission queue of the cold starts of the components scaled to zero. The cold started pods are held
+// until the queue wakes their component, the components are woken in the priority order of their pods so the
+// components competing for scarce resources, e.g. GPUs, are not all left Pending at once.
+type Config struct {
+	// maximum number of components woken at the same time, the cold starts are not queued when 0
+	MaxConcurrentWakes int `json:"maxConcurrentWakes,omitempty"`
+	// time in seconds a woken component is waited for to be ready before the next one is woken, 600 when unset
+	WakeTimeoutSeconds int64 `json:"wakeTimeoutSeconds,omitempty"`
+}
+
+// GetConfig reads the scale from zero queue configuration of the inferenceservice-config ConfigMap
+func GetConfig(configMap *v1.ConfigMap) (*Config, error) {
+	config := &Config{}
+	if value, ok := configMap.Data[ScaleFromZeroConfigMapKeyName]; ok {
+		if err := json.Unmarshal([]byte(value), config); err != nil {
+			return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", ScaleFromZeroConfigMapKeyName, err)
+		}
+	}
+	if config.MaxConcurrentWakes < 0 || config.WakeTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("Invalid %v config, maxConcurrentWakes and wakeTimeoutSeconds cannot be negative",
+			ScaleFromZeroConfigMapKeyName)
+	}
+	return config, nil
+}
+
+func (c *Config) wakeTimeout() time.Duration {
+	if c.WakeTimeoutSeconds == 0 {
+		return DefaultWakeTimeoutSeconds * time.Second
+	}
+	return time.Duration(c.WakeTimeoutSeconds) * time.Second
+}
+
+// waking returns whether the ReplicaSet was woken by the queue less than the wake timeout ago and is not ready yet
+func (c *Config) waking(rs *appsv1.ReplicaSet, now time.Time) bool {
+	admitted, err := strconv.ParseInt(rs.Labels[constants.WakeAdmittedLabelKey], 10, 64)
+	if err != nil {
+		return false
+	}
+	return rs.Status.ReadyReplicas == 0 && now.Sub(time.Unix(admitted, 0)) < c.wakeTimeout()
+}
+
+// Hold returns whether the pod being created is a cold start held by the queue: the pod of a component scaled to zero
+// scheduled by the default scheduler, whose ReplicaSet has no ready replica and is not being woken
+func (c *Config) Hold(pod *v1.Pod, rs *appsv1.ReplicaSet, now time.Time) bool {
+	if c.MaxConcurrentWakes == 0 || rs == nil {
+		return false
+	}
+	if pod.Annotations[autoscaling.MinScaleAnnotationKey] != "0" {
+		return false
+	}
+	if pod.Spec.SchedulerName != "" && pod.Spec.SchedulerName != v1.DefaultSchedulerName {
+		return false
+	}
+	return rs.Status.ReadyReplicas == 0 && !c.waking(rs, now)
+}
+
+// OwnerReplicaSet returns the name of the ReplicaSet controlling the pod, empty when the pod is not controlled by a
+// ReplicaSet
+func OwnerReplicaSet(pod *v1.Pod) string {
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "ReplicaSet" {
+		return owner.Name
+	}
+	return ""
+}
+
+// IndexSchedulerName indexes the pods of the cache by scheduler name, the held pods are listed with the index. It must
+// be called before the cache of the manager starts.
+func IndexSchedulerName(indexer client.FieldIndexer) error {
+	return indexer.IndexField(&v1.Pod{}, schedulerNameField, func(obj runtime.Object) []string {
+		return []string{obj.(*v1.Pod).Spec.SchedulerName}
+	})
+}
+
+// Queue periodically wakes the components whose cold started pods are held, in the priority order of the pods then
+// the order of their creation. A component is woken by labeling its ReplicaSet as admitted and deleting its held pods,
+// the ReplicaSet creates them again and the pod mutator no longer holds them. At most maxConcurrentWakes components
+// are waking at the same time, a component is waking until one of its pods is ready or the wake timeout.
+type Queue struct {
+	// Client reads the config map and the held pods from the cache, the pods are listed with the index of
+	// IndexSchedulerName
+	Client client.Client
+	// Reader reads the ReplicaSets from the API server as they are not cached, only while pods are held
+	Reader client.Reader
+	// Interval between the wakes, a component is woken up to an interval after a slot was freed
+	Interval time.Duration
+	// Shard of the replica, see shard.Shard
+	Shard *shard.Shard
+	Log   logr.Logger
+}
+
+// Start wakes the components on start then every interval until the manager stops
+func (q *Queue) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(q.Interval)
+	defer ticker.Stop()
+	for {
+		if err := q.Wake(context.TODO(), time.Now()); err != nil {
+			q.Log.Error(err, "Failed to wake the components scaled from zero")
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// heldReplicaSet is a ReplicaSet with held pods
+type heldReplicaSet struct {
+	key      types.NamespacedName
+	priority int32
+	created  time.Time
+	pods     []*v1.Pod
+}
+
+// Wake wakes the components with held pods in the free slots of the queue, all of them when the queue is disabled. The
+// ReplicaSets are only read while pods are held, the admission labels of the woken components are then removed.
+func (q *Queue) Wake(ctx context.Context, now time.Time) error {
+	configMap := &v1.ConfigMap{}
+	if err := q.Client.Get(ctx, types.NamespacedName{Name: constants.InferenceServiceConfigMapName,
+		Namespace: constants.KFServingNamespace}, configMap); err != nil {
+		return errors.Wrapf(err, "fails to read the %s config map", constants.InferenceServiceConfigMapName)
+	}
+	config, err := GetConfig(configMap)
+	if err != nil {
+		return err
+	}
+
+	pods := &v1.PodList{}
+	if err := q.Client.List(ctx, pods,
+		client.MatchingFields{schedulerNameField: constants.WakeQueueSchedulerName}); err != nil {
+		return errors.Wrapf(err, "fails to list the held pods")
+	}
+	held := map[types.NamespacedName]*heldReplicaSet{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		name := OwnerReplicaSet(pod)
+		if pod.Spec.SchedulerName != constants.WakeQueueSchedulerName || name == "" {
+			continue
+		}
+		if owned, err := q.Shard.Owns(pod.Namespace); !owned {
+			if err != nil {
+				q.Log.Error(err, "Failed to check the shard", "namespace", pod.Namespace, "name", pod.Name)
+			}
+			continue
+		}
+		priority := int32(0)
+		if pod.Spec.Priority != nil {
+			priority = *pod.Spec.Priority
+		}
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: name}
+		rs, ok := held[key]
+		if !ok {
+			rs = &heldReplicaSet{key: key, priority: priority, created: pod.CreationTimestamp.Time}
+			held[key] = rs
+		}
+		if priority > rs.priority {
+			rs.priority = priority
+		}
+		if pod.CreationTimestamp.Time.Before(rs.created) {
+			rs.created = pod.CreationTimestamp.Time
+		}
+		rs.pods = append(rs.pods, pod)
+	}
+
+	if len(held) == 0 {
+		return nil
+	}
+	slots := len(held)
+	if config.MaxConcurrentWakes > 0 {
+		waking, err := q.waking(ctx, config, now)
+		if err != nil {
+			return err
+		}
+		slots = config.MaxConcurrentWakes - waking
+	}
+	for _, rs := range order(held) {
+		if slots <= 0 {
+			break
+		}
+		if err := q.wake(ctx, rs, now); err != nil {
+			q.Log.Error(err, "Failed to wake the component", "namespace", rs.key.Namespace, "replicaset", rs.key.Name)
+			continue
+		}
+		slots--
+	}
+	return nil
+}
+
+// waking counts the components being woken and removes the admission label of the ReplicaSets which are ready or timed
+// out
+func (q *Queue) waking(ctx context.Context, config *Config, now time.Time) (int, error) {
+	admitted, err := labels.NewRequirement(constants.WakeAdmittedLabelKey, selection.Exists, nil)
+	if err != nil {
+		return 0, err
+	}
+	replicaSets := &appsv1.ReplicaSetList{}
+	if err := q.Reader.List(ctx, replicaSets,
+		&client.ListOptions{LabelSelector: labels.NewSelector().Add(*admitted)}); err != nil {
+		return 0, errors.Wrapf(err, "fails to list the woken replica sets")
+	}
+	waking := 0
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if owned, _ := q.Shard.Owns(rs.Namespace); !owned {
+			continue
+		}
+		if config.waking(rs, now) {
+			waking++
+			continue
+		}
+		patched := rs.DeepCopy()
+		delete(patched.Labels, constants.WakeAdmittedLabelKey)
+		if err := q.Client.Patch(ctx, patched, client.MergeFrom(rs)); err != nil && !apierr.IsNotFound(err) {
+			q.Log.Error(err, "Failed to remove the admission label", "namespace", rs.Namespace, "replicaset", rs.Name)
+		}
+	}
+	return waking, nil
+}
+
+// wake labels the ReplicaSet as admitted then deletes its held pods
+func (q *Queue) wake(ctx context.Context, held *heldReplicaSet, now time.Time) error {
+	rs := &appsv1.ReplicaSet{}
+	if err := q.Reader.Get(ctx, held.key, rs); err != nil {
+		return err
+	}
+	patched := rs.DeepCopy()
+	if patched.Labels == nil {
+		patched.Labels = map[string]string{}
+	}
+	patched.Labels[constants.WakeAdmittedLabelKey] = strconv.FormatInt(now.Unix(), 10)
+	if err := q.Client.Patch(ctx, patched, client.MergeFrom(rs)); err != nil {
+		return errors.Wrapf(err, "fails to label the replica set as admitted")
+	}
+	q.Log.Info("Waking the component", "namespace", rs.Namespace, "replicaset", rs.Name, "priority", held.priority)
+	for _, pod := range held.pods {
+		if err := q.Client.Delete(ctx, pod); err != nil && !apierr.IsNotFound(err) {
+			return errors.Wrapf(err, "fails to release the held pod %s", pod.Name)
+		}
+	}
+	return nil
+}
+
+// order sorts the ReplicaSets by the priority of their pods, then by their oldest held pod
+func order(held map[types.NamespacedName]*heldReplicaSet) []*heldReplicaSet {
+	ordered := make([]*heldReplicaSet, 0, len(held))
+	for _, rs := range held {
+		ordered = append(ordered, rs)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].priority != ordered[j].priority {
+			return ordered[i].priority > ordered[j].priority
+		}
+		if !ordered[i].created.Equal(ordered[j].created) {
+			return ordered[i].created.Before(ordered[j].created)
+		}
+		return ordered[i].key.String() < ordered[j].key.String()
+	})
+	return ordered
+}
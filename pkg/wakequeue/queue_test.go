@@ -0,0 +1,222 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wakequeue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var now = time.Date(2020, 10, 15, 9, 0, 0, 0, time.UTC)
+
+func replicaSet(name string, readyReplicas int32, labels map[string]string) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Status:     appsv1.ReplicaSetStatus{ReadyReplicas: readyReplicas},
+	}
+}
+
+func heldPod(name string, rs string, priority int32, created time.Time) *v1.Pod {
+	controller := true
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs, Controller: &controller},
+			},
+		},
+		Spec: v1.PodSpec{SchedulerName: constants.WakeQueueSchedulerName, Priority: &priority},
+	}
+}
+
+func configMap(config string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: constants.KFServingNamespace},
+		Data:       map[string]string{ScaleFromZeroConfigMapKeyName: config},
+	}
+}
+
+func TestWake(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(appsv1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	admitted := map[string]string{constants.WakeAdmittedLabelKey: strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)}
+	timedOut := map[string]string{constants.WakeAdmittedLabelKey: strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)}
+	cl := fake.NewFakeClientWithScheme(scheme,
+		configMap(`{"maxConcurrentWakes": 2, "wakeTimeoutSeconds": 600}`),
+		replicaSet("waking", 0, admitted),
+		replicaSet("timed-out", 0, timedOut),
+		replicaSet("low", 0, nil),
+		replicaSet("high", 0, nil),
+		heldPod("low-1", "low", 100, now.Add(-2*time.Minute)),
+		heldPod("high-1", "high", 1000, now.Add(-time.Minute)),
+		heldPod("high-2", "high", 1000, now.Add(-time.Minute)),
+	)
+	queue := &Queue{Client: cl, Reader: cl, Log: logf.Log}
+	getReplicaSet := func(name string) *appsv1.ReplicaSet {
+		rs := &appsv1.ReplicaSet{}
+		g.Expect(cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, rs)).To(gomega.Succeed())
+		return rs
+	}
+	isHeld := func(name string) bool {
+		err := cl.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, &v1.Pod{})
+		g.Expect(err == nil || apierr.IsNotFound(err)).To(gomega.BeTrue())
+		return err == nil
+	}
+
+	// A single slot is free, the component of the higher priority is woken before the older one
+	g.Expect(queue.Wake(context.TODO(), now)).To(gomega.Succeed())
+	g.Expect(getReplicaSet("high").Labels).To(gomega.HaveKeyWithValue(constants.WakeAdmittedLabelKey,
+		strconv.FormatInt(now.Unix(), 10)))
+	g.Expect(isHeld("high-1")).To(gomega.BeFalse())
+	g.Expect(isHeld("high-2")).To(gomega.BeFalse())
+	g.Expect(isHeld("low-1")).To(gomega.BeTrue())
+	g.Expect(getReplicaSet("low").Labels).NotTo(gomega.HaveKey(constants.WakeAdmittedLabelKey))
+	// The timed out component no longer takes a slot
+	g.Expect(getReplicaSet("timed-out").Labels).NotTo(gomega.HaveKey(constants.WakeAdmittedLabelKey))
+
+	// The queue is full until a woken component is ready
+	g.Expect(queue.Wake(context.TODO(), now)).To(gomega.Succeed())
+	g.Expect(isHeld("low-1")).To(gomega.BeTrue())
+	high := getReplicaSet("high")
+	high.Status.ReadyReplicas = 1
+	g.Expect(cl.Update(context.TODO(), high)).To(gomega.Succeed())
+	g.Expect(queue.Wake(context.TODO(), now)).To(gomega.Succeed())
+	g.Expect(isHeld("low-1")).To(gomega.BeFalse())
+	g.Expect(getReplicaSet("high").Labels).NotTo(gomega.HaveKey(constants.WakeAdmittedLabelKey))
+
+	// The ReplicaSets are not read from the API server while no pod is held
+	idle := &Queue{Client: cl, Reader: failingReader{}, Log: logf.Log}
+	g.Expect(idle.Wake(context.TODO(), now)).To(gomega.Succeed())
+}
+
+// failingReader fails the reads from the API server
+type failingReader struct{}
+
+func (failingReader) Get(context.Context, client.ObjectKey, runtime.Object) error {
+	return fmt.Errorf("unexpected read from the API server")
+}
+
+func (failingReader) List(context.Context, runtime.Object, ...client.ListOption) error {
+	return fmt.Errorf("unexpected read from the API server")
+}
+
+func TestWakeDisabled(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(v1.AddToScheme(scheme)).To(gomega.Succeed())
+	g.Expect(appsv1.AddToScheme(scheme)).To(gomega.Succeed())
+
+	// The pods held before the queue was disabled are all released
+	cl := fake.NewFakeClientWithScheme(scheme,
+		configMap(`{"maxConcurrentWakes": 0}`),
+		replicaSet("first", 0, nil),
+		replicaSet("second", 0, nil),
+		heldPod("first-1", "first", 0, now),
+		heldPod("second-1", "second", 0, now),
+	)
+	queue := &Queue{Client: cl, Reader: cl, Log: logf.Log}
+	g.Expect(queue.Wake(context.TODO(), now)).To(gomega.Succeed())
+	pods := &v1.PodList{}
+	g.Expect(cl.List(context.TODO(), pods)).To(gomega.Succeed())
+	g.Expect(pods.Items).To(gomega.BeEmpty())
+}
+
+func TestHold(t *testing.T) {
+	config := &Config{MaxConcurrentWakes: 1}
+	coldStart := func() *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "0"},
+			},
+		}
+	}
+	admitted := map[string]string{constants.WakeAdmittedLabelKey: strconv.FormatInt(now.Unix(), 10)}
+	scenarios := map[string]struct {
+		config   *Config
+		pod      *v1.Pod
+		rs       *appsv1.ReplicaSet
+		expected bool
+	}{
+		"ColdStart": {
+			config:   config,
+			pod:      coldStart(),
+			rs:       replicaSet("rs", 0, nil),
+			expected: true,
+		},
+		"Disabled": {
+			config:   &Config{},
+			pod:      coldStart(),
+			rs:       replicaSet("rs", 0, nil),
+			expected: false,
+		},
+		"ReadyReplicaSet": {
+			config:   config,
+			pod:      coldStart(),
+			rs:       replicaSet("rs", 1, nil),
+			expected: false,
+		},
+		"WokenReplicaSet": {
+			config:   config,
+			pod:      coldStart(),
+			rs:       replicaSet("rs", 0, admitted),
+			expected: false,
+		},
+		"NotScaledToZero": {
+			config:   config,
+			pod:      &v1.Pod{},
+			rs:       replicaSet("rs", 0, nil),
+			expected: false,
+		},
+		"OtherScheduler": {
+			config: config,
+			pod: func() *v1.Pod {
+				pod := coldStart()
+				pod.Spec.SchedulerName = "volcano"
+				return pod
+			}(),
+			rs:       replicaSet("rs", 0, nil),
+			expected: false,
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(scenario.config.Hold(scenario.pod, scenario.rs, now)).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Config of the warmup of a model server, set by the controller in the warmup annotation of the predictor pods
+type Config struct {
+	// ReadyPath of the model ready endpoint of the model server, the requests are replayed once it passes
+	ReadyPath string `json:"readyPath"`
+	// DefaultPath of the request files, the predict endpoint of the protocol of the predictor
+	DefaultPath string `json:"defaultPath"`
+	// Requests replayed before the request files
+	Requests []Request `json:"requests,omitempty"`
+	// StorageURI of the request files, downloaded by the pod mutator
+	StorageURI string `json:"storageUri,omitempty"`
+	// Iterations of the replay of the requests
+	Iterations int `json:"iterations"`
+	// TimeoutSeconds of the replay, the pod is then ready even when the requests are not all replayed
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+// Request replayed against the model server
+type Request struct {
+	Path string          `json:"path"`
+	Body json.RawMessage `json:"body"`
+}
+
+// Warmer replays the warmup requests against the model server once the model is loaded. It serves the readiness of
+// the warmup, which gates the readiness of the pod, so the pod only receives traffic once the requests are replayed.
+type Warmer struct {
+	Config
+	// ServerURL of the model server, e.g. http://localhost:8080
+	ServerURL string
+	// RequestsDir of the request files downloaded from the storage URI, empty without request files
+	RequestsDir string
+	// PollInterval between the probes of the model ready endpoint
+	PollInterval time.Duration
+	Client       *http.Client
+	Log          logr.Logger
+	done         int32
+}
+
+// Run waits for the model to be loaded and replays the requests until they are all replayed or the timeout expires,
+// the warmup is then done. The requests failing are logged, they do not fail the warmup. The warmup fails open on the
+// timeout of the replay, the pod is ready with a partial warmup, and fails closed on the other errors, the request
+// files which cannot be read or the cancellation before the model is loaded keep the pod not ready.
+func (w *Warmer) Run(ctx context.Context) error {
+	requests, err := w.requests()
+	if err != nil {
+		return err
+	}
+	// The model server is not ready until the model is loaded, the timeout starts once the model is loaded
+	if err := w.waitReady(ctx); err != nil {
+		return err
+	}
+	defer atomic.StoreInt32(&w.done, 1)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(w.TimeoutSeconds)*time.Second)
+	defer cancel()
+	failed := 0
+	for i := 0; i < w.Iterations; i++ {
+		for _, request := range requests {
+			if err := w.send(ctx, request); err != nil {
+				if ctx.Err() != nil {
+					w.Log.Info("Warmup timed out, the pod is ready with a partial warmup", "iterations", i,
+						"expected", w.Iterations)
+					return nil
+				}
+				w.Log.Info("Warmup request failed", "path", request.Path, "error", err.Error())
+				failed++
+			}
+		}
+	}
+	w.Log.Info("Warmup done", "requests", len(requests)*w.Iterations, "failed", failed)
+	return nil
+}
+
+// Ready returns true once the warmup is done
+func (w *Warmer) Ready() bool {
+	return atomic.LoadInt32(&w.done) == 1
+}
+
+// ServeHTTP serves the readiness of the warmup
+func (w *Warmer) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	if !w.Ready() {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// requests returns the requests followed by the request files in the order of their names
+func (w *Warmer) requests() ([]Request, error) {
+	requests := append([]Request{}, w.Requests...)
+	if w.RequestsDir == "" {
+		return requests, nil
+	}
+	files, err := ioutil.ReadDir(w.RequestsDir)
+	if err != nil {
+		return nil, fmt.Errorf("fails to list the request files: %v", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		body, err := ioutil.ReadFile(filepath.Join(w.RequestsDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("fails to read the request file %s: %v", file.Name(), err)
+		}
+		requests = append(requests, Request{Path: w.DefaultPath, Body: body})
+	}
+	return requests, nil
+}
+
+// waitReady probes the model ready endpoint until it passes
+func (w *Warmer) waitReady(ctx context.Context) error {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+	for {
+		if w.get(ctx, w.ReadyPath) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Warmer) get(ctx context.Context, path string) bool {
+	req, err := http.NewRequest(http.MethodGet, w.ServerURL+path, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := w.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+func (w *Warmer) send(ctx context.Context, request Request) error {
+	req, err := http.NewRequest(http.MethodPost, w.ServerURL+request.Path, bytes.NewReader(request.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
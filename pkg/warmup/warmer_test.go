@@ -0,0 +1,187 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package warmup
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// fakeModelServer becomes ready after a number of probes and records the bodies of the requests by path
+type fakeModelServer struct {
+	mu            sync.Mutex
+	probesToReady int
+	predictStatus int
+	requests      map[string][]string
+}
+
+func (s *fakeModelServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Method == http.MethodGet {
+		if s.probesToReady > 0 {
+			s.probesToReady--
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	s.requests[req.URL.Path] = append(s.requests[req.URL.Path], string(body))
+	rw.WriteHeader(s.predictStatus)
+}
+
+func TestWarmer(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	dir, err := ioutil.TempDir("", "warmup")
+	g.Expect(err).To(gomega.BeNil())
+	defer os.RemoveAll(dir)
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"instances":[2]}`), 0644)).To(gomega.Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"instances":[1]}`), 0644)).To(gomega.Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte(`{}`), 0644)).To(gomega.Succeed())
+
+	scenarios := map[string]struct {
+		predictStatus int
+		requestsDir   string
+		expected      map[string][]string
+	}{
+		"RequestsAndFiles": {
+			predictStatus: http.StatusOK,
+			requestsDir:   dir,
+			expected: map[string][]string{
+				"/v1/models/sklearn:explain": {`{"instances":[0]}`, `{"instances":[0]}`},
+				"/v1/models/sklearn:predict": {`{"instances":[1]}`, `{"instances":[2]}`, `{"instances":[1]}`, `{"instances":[2]}`},
+			},
+		},
+		"FailingRequests": {
+			predictStatus: http.StatusInternalServerError,
+			expected: map[string][]string{
+				"/v1/models/sklearn:explain": {`{"instances":[0]}`, `{"instances":[0]}`},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			modelServer := &fakeModelServer{
+				probesToReady: 2,
+				predictStatus: scenario.predictStatus,
+				requests:      map[string][]string{},
+			}
+			server := httptest.NewServer(modelServer)
+			defer server.Close()
+			warmer := &Warmer{
+				Config: Config{
+					ReadyPath:   "/v1/models/sklearn",
+					DefaultPath: "/v1/models/sklearn:predict",
+					Requests: []Request{
+						{Path: "/v1/models/sklearn:explain", Body: []byte(`{"instances":[0]}`)},
+					},
+					Iterations:     2,
+					TimeoutSeconds: 10,
+				},
+				ServerURL:    server.URL,
+				RequestsDir:  scenario.requestsDir,
+				PollInterval: time.Millisecond,
+				Client:       server.Client(),
+				Log:          logf.Log,
+			}
+			g.Expect(warmer.Ready()).To(gomega.BeFalse())
+			g.Expect(warmer.Run(context.TODO())).To(gomega.Succeed())
+			g.Expect(warmer.Ready()).To(gomega.BeTrue())
+			g.Expect(modelServer.probesToReady).To(gomega.Equal(0))
+			g.Expect(modelServer.requests).To(gomega.Equal(scenario.expected))
+		})
+	}
+}
+
+func TestWarmerReadiness(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	modelServer := &fakeModelServer{probesToReady: 1 << 30, requests: map[string][]string{}}
+	server := httptest.NewServer(modelServer)
+	defer server.Close()
+	warmer := &Warmer{
+		Config:       Config{ReadyPath: "/v1/models/sklearn", Iterations: 1, TimeoutSeconds: 1},
+		ServerURL:    server.URL,
+		PollInterval: time.Millisecond,
+		Client:       server.Client(),
+		Log:          logf.Log,
+	}
+
+	recorder := httptest.NewRecorder()
+	warmer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+
+	// The warmup is not done when it is cancelled before the model is loaded
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	g.Expect(warmer.Run(ctx)).NotTo(gomega.Succeed())
+	recorder = httptest.NewRecorder()
+	warmer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+}
+
+func TestWarmerTimeout(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// The model server is ready but does not answer the warmup requests
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			<-release
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+	warmer := &Warmer{
+		Config: Config{
+			ReadyPath:      "/v1/models/sklearn",
+			Requests:       []Request{{Path: "/v1/models/sklearn:predict", Body: []byte(`{"instances":[0]}`)}},
+			Iterations:     1,
+			TimeoutSeconds: 1,
+		},
+		ServerURL:    server.URL,
+		PollInterval: time.Millisecond,
+		Client:       server.Client(),
+		Log:          logf.Log,
+	}
+	// The pod is ready with a partial warmup once the replay times out
+	g.Expect(warmer.Run(context.TODO())).To(gomega.Succeed())
+	g.Expect(warmer.Ready()).To(gomega.BeTrue())
+}
+
+func TestWarmerMissingRequestsDir(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	warmer := &Warmer{
+		Config:      Config{Iterations: 1, TimeoutSeconds: 1},
+		RequestsDir: "/does/not/exist",
+		Log:         logf.Log,
+	}
+	g.Expect(warmer.Run(context.TODO())).NotTo(gomega.Succeed())
+	g.Expect(warmer.Ready()).To(gomega.BeFalse())
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/batchinferencejob"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/warmpool"
+	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	"github.com/kubeflow/kfserving/pkg/wakequeue"
+	"github.com/kubeflow/kfserving/pkg/webhook/admission/pod"
+	v1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-configmaps,mutating=false,failurePolicy=fail,groups="",resources=configmaps,verbs=create;update,versions=v1,name=inferenceservice.kfserving-webhook-server.configmap-validator
+var log = logf.Log.WithName(constants.ConfigMapValidatorWebhookName)
+
+// schemas are the types the keys of the inferenceservice-config ConfigMap are read into
+var schemas = map[string]func() interface{}{
+	v1beta1.PredictorConfigKeyName:                   func() interface{} { return &v1beta1.PredictorsConfig{} },
+	v1beta1.TransformerConfigKeyName:                 func() interface{} { return &v1beta1.TransformersConfig{} },
+	v1beta1.ExplainerConfigKeyName:                   func() interface{} { return &v1beta1.ExplainersConfig{} },
+	v1beta1.IngressConfigKeyName:                     func() interface{} { return &v1beta1.IngressConfig{} },
+	v1beta1.FederationConfigKeyName:                  func() interface{} { return &v1beta1.FederationConfig{} },
+	v1beta1.CostConfigKeyName:                        func() interface{} { return &v1beta1.CostConfig{} },
+	v1beta1.MeshConfigKeyName:                        func() interface{} { return &v1beta1.MeshConfig{} },
+	v1beta1.RegistryConfigKeyName:                    func() interface{} { return &v1beta1.RegistryConfig{} },
+	v1beta1.PolicyConfigKeyName:                      func() interface{} { return &v1beta1.PolicyConfig{} },
+	v1beta1.AutoscalingConfigKeyName:                 func() interface{} { return &v1beta1.AutoscalingConfig{} },
+	credentials.CredentialConfigKeyName:              func() interface{} { return &credentials.CredentialConfig{} },
+	pod.StorageInitializerConfigMapKeyName:           func() interface{} { return &pod.StorageInitializerConfig{} },
+	pod.LoggerConfigMapKeyName:                       func() interface{} { return &pod.LoggerConfig{} },
+	pod.BatcherConfigMapKeyName:                      func() interface{} { return &pod.BatcherConfig{} },
+	pod.WarmupConfigMapKeyName:                       func() interface{} { return &pod.WarmupConfig{} },
+	pod.AsyncConfigMapKeyName:                        func() interface{} { return &pod.AsyncConfig{} },
+	pod.RequestValidationConfigMapKeyName:            func() interface{} { return &pod.RequestValidationConfig{} },
+	pod.ResponseCacheConfigMapKeyName:                func() interface{} { return &pod.ResponseCacheConfig{} },
+	wakequeue.ScaleFromZeroConfigMapKeyName:          func() interface{} { return &wakequeue.Config{} },
+	pod.TracingConfigMapKeyName:                      func() interface{} { return &pod.TracingConfig{} },
+	pod.SecurityContextConfigMapKeyName:              func() interface{} { return &pod.SecurityContextConfig{} },
+	warmpool.AgentConfigMapKeyName:                   func() interface{} { return &warmpool.AgentConfig{} },
+	batchinferencejob.BatchInferenceConfigMapKeyName: func() interface{} { return &batchinferencejob.BatchInferenceConfig{} },
+}
+
+// namespaceKeys are the keys the inferenceservice-config ConfigMap of a namespace can overlay on the cluster one
+var namespaceKeys = []string{
+	v1beta1.PredictorConfigKeyName,
+	v1beta1.TransformerConfigKeyName,
+	v1beta1.ExplainerConfigKeyName,
+	v1beta1.IngressConfigKeyName,
+	v1beta1.MeshConfigKeyName,
+	v1beta1.LoggerConfigKeyName,
+}
+
+// namespaceSchemas are the types the keys of the ConfigMap of a namespace are read into when a namespace can only set
+// part of the cluster config, the logger of a namespace only sets its sinks
+var namespaceSchemas = map[string]func() interface{}{
+	v1beta1.LoggerConfigKeyName: func() interface{} { return &v1beta1.LoggerConfig{} },
+}
+
+// Validator is a webhook that validates the inferenceservice-config ConfigMaps
+type Validator struct {
+	Decoder *admission.Decoder
+}
+
+// Handle decodes the incoming ConfigMap and validates it when it is an inferenceservice-config ConfigMap.
+func (validator *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	configMap := &v1.ConfigMap{}
+	if err := validator.Decoder.Decode(req, configMap); err != nil {
+		log.Error(err, "Failed to decode config map", "name", req.AdmissionRequest.Name)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if configMap.Name != constants.InferenceServiceConfigMapName {
+		return admission.ValidationResponse(true, "")
+	}
+	// The namespace of the config map may be empty in the admission request object
+	configMap.Namespace = req.AdmissionRequest.Namespace
+	if err := ValidateConfigMap(configMap); err != nil {
+		log.Info("Rejected config map", "namespace", configMap.Namespace, "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// ValidateConfigMap validates the keys of an inferenceservice-config ConfigMap against their schemas. The unknown keys
+// and fields are rejected so that a typo does not silently fall back to the defaults. The ConfigMaps of the namespaces
+// can only set the keys overlaid on the cluster ConfigMap.
+func ValidateConfigMap(configMap *v1.ConfigMap) error {
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	clusterConfig := configMap.Namespace == constants.KFServingNamespace
+	for _, key := range keys {
+		schema, ok := schemas[key]
+		if !ok {
+			return fmt.Errorf("Unknown key %q of the %s config map, known keys are %s.", key,
+				constants.InferenceServiceConfigMapName, strings.Join(knownKeys(), ", "))
+		}
+		if !clusterConfig && !utils.Includes(namespaceKeys, key) {
+			return fmt.Errorf("Key %q of the %s config map is only read from the %s namespace, the keys of a "+
+				"namespace are %s.", key, constants.InferenceServiceConfigMapName, constants.KFServingNamespace,
+				strings.Join(namespaceKeys, ", "))
+		}
+		if namespaceSchema, ok := namespaceSchemas[key]; ok && !clusterConfig {
+			schema = namespaceSchema
+		}
+		config := schema()
+		decoder := json.NewDecoder(strings.NewReader(configMap.Data[key]))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return fmt.Errorf("Invalid %s config: %v", key, err)
+		}
+		// The ingress of a namespace only tightens the limits of the cluster ingress
+		if ingressConfig, ok := config.(*v1beta1.IngressConfig); ok {
+			validate := v1beta1.ValidateIngressConfig
+			if !clusterConfig {
+				validate = v1beta1.ValidateNamespaceIngressConfig
+			}
+			if err := validate(ingressConfig); err != nil {
+				return err
+			}
+		}
+		if meshConfig, ok := config.(*v1beta1.MeshConfig); ok {
+			if err := v1beta1.ValidateMeshConfig(meshConfig); err != nil {
+				return err
+			}
+		}
+		if federationConfig, ok := config.(*v1beta1.FederationConfig); ok {
+			if err := v1beta1.ValidateFederationConfig(federationConfig); err != nil {
+				return err
+			}
+		}
+		if policyConfig, ok := config.(*v1beta1.PolicyConfig); ok {
+			if err := v1beta1.ValidatePolicyConfig(policyConfig); err != nil {
+				return err
+			}
+		}
+		if costConfig, ok := config.(*v1beta1.CostConfig); ok {
+			if err := v1beta1.ValidateCostConfig(costConfig); err != nil {
+				return err
+			}
+		}
+		if storageInitializerConfig, ok := config.(*pod.StorageInitializerConfig); ok {
+			if err := pod.ValidateStorageInitializerConfig(storageInitializerConfig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func knownKeys() []string {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// InjectDecoder injects the decoder.
+func (validator *Validator) InjectDecoder(d *admission.Decoder) error {
+	validator.Decoder = d
+	return nil
+}
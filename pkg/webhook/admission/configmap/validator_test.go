@@ -0,0 +1,191 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestValidateDefaultConfigMaps(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	for _, file := range []string{
+		"../../../../config/configmap/inferenceservice.yaml",
+		"../../../../config/overlays/test/configmap/inferenceservice.yaml",
+	} {
+		data, err := ioutil.ReadFile(file)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		// The ingress gateway is substituted by kustomize with the quoted gateway followed by a comma
+		data = []byte(strings.Replace(string(data), "$(ingressGateway)", `"knative-serving/knative-ingress-gateway",`, 1))
+		configMap := &v1.ConfigMap{}
+		g.Expect(yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(configMap)).To(gomega.Succeed())
+		configMap.Namespace = constants.KFServingNamespace
+		g.Expect(ValidateConfigMap(configMap)).To(gomega.Succeed(), file)
+	}
+}
+
+func TestValidateConfigMap(t *testing.T) {
+	ingress := `{"ingressGateway": "knative-serving/knative-ingress-gateway", "ingressService": "istio-ingressgateway.istio-system.svc.cluster.local"}`
+	scenarios := map[string]struct {
+		namespace string
+		data      map[string]string
+		matcher   string
+	}{
+		"Valid": {
+			namespace: constants.KFServingNamespace,
+			data: map[string]string{
+				"predictors": `{"sklearn": {"image": "kfserving/sklearnserver", "defaultImageVersion": "v0.5.0"}}`,
+				"ingress":    ingress,
+				"logger":     `{"image": "kfserving/logger:v0.5.0", "cpuRequest": "100m"}`,
+			},
+		},
+		"UnknownKey": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"predictor": `{}`},
+			matcher:   `Unknown key "predictor"`,
+		},
+		"NegativePrice": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"cost": `{"currency": "USD", "prices": {"cpu": 0.03, "nvidia.com/gpu": -1}}`},
+			matcher:   "Invalid cost config, the price of nvidia.com/gpu cannot be negative.",
+		},
+		"NegativeEphemeralStorageHeadroom": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"storageInitializer": `{"image": "kfserving/storage-initializer:latest", "ephemeralStorageHeadroomPercent": -10}`},
+			matcher:   "Invalid storageInitializer config, ephemeralStorageHeadroomPercent cannot be negative.",
+		},
+		"InvalidMTLSMode": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"mesh": `{"mtlsMode": "DISABLE"}`},
+			matcher:   "Invalid mesh config, unknown mtlsMode DISABLE, must be PERMISSIVE or STRICT.",
+		},
+		"NamespaceMesh": {
+			namespace: "team-a",
+			data:      map[string]string{"mesh": `{"sidecarInjection": true, "mtlsMode": "STRICT"}`},
+		},
+		"NamespaceRegistry": {
+			namespace: "team-a",
+			data:      map[string]string{"registry": `{"imagePullSecrets": [{"name": "team-a-registry"}]}`},
+			matcher:   `Key "registry" of the inferenceservice-config config map is only read from the kfserving-system namespace`,
+		},
+		"InvalidFederationNamespace": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"federation": `{"allowedNamespaces": ["Team_A"]}`},
+			matcher:   `Invalid federation config, allowedNamespaces has an invalid namespace "Team_A"`,
+		},
+		"NamespaceFederation": {
+			namespace: "team-a",
+			data:      map[string]string{"federation": `{"allowedNamespaces": ["team-a"]}`},
+			matcher:   `Key "federation" of the inferenceservice-config config map is only read from the kfserving-system namespace`,
+		},
+		"InvalidPolicyFailurePolicy": {
+			namespace: constants.KFServingNamespace,
+			data: map[string]string{"policy": `{"verifiers": [{"name": "cosign", "url": "http://cosign-verifier",
+				"failurePolicy": "Allow"}]}`},
+			matcher: "Invalid policy config, unknown failurePolicy Allow of verifier cosign, must be Fail or Ignore.",
+		},
+		"NamespacePolicy": {
+			namespace: "team-a",
+			data:      map[string]string{"policy": `{"verifiers": []}`},
+			matcher:   `Key "policy" of the inferenceservice-config config map is only read from the kfserving-system namespace`,
+		},
+		"UnknownField": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"predictors": `{"sklearn": {"imge": "kfserving/sklearnserver"}}`},
+			matcher:   `Invalid predictors config: json: unknown field "imge"`,
+		},
+		"UnknownFramework": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"predictors": `{"scikit": {"image": "kfserving/sklearnserver"}}`},
+			matcher:   `Invalid predictors config: json: unknown field "scikit"`,
+		},
+		"InvalidJSON": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"batcher": `{"image": "kfserving/batcher",}`},
+			matcher:   "Invalid batcher config",
+		},
+		"WrongType": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"predictors": `{"sklearn": {"allowedImageVersions": "v0.5.0"}}`},
+			matcher:   "Invalid predictors config",
+		},
+		"InvalidIngress": {
+			namespace: constants.KFServingNamespace,
+			data:      map[string]string{"ingress": `{"backend": "nginx"}`},
+			matcher:   "Invalid ingress config, unknown backend nginx.",
+		},
+		"InvalidHostOverrideDomain": {
+			namespace: constants.KFServingNamespace,
+			data: map[string]string{"ingress": `{"ingressGateway": "knative-serving/knative-ingress-gateway",
+				"ingressService": "istio-ingressgateway.istio-system.svc.cluster.local",
+				"hostOverrideDomains": ["*.models.example.com"]}`},
+			matcher: `Invalid ingress config, hostOverrideDomains "*.models.example.com" is not a DNS subdomain`,
+		},
+		"NamespaceHostOverrideDomains": {
+			namespace: "team-a",
+			data:      map[string]string{"ingress": `{"hostOverrideDomains": ["team-a.example.com"]}`},
+			matcher:   "Invalid ingress config of a namespace, only maxTimeoutSeconds, maxRequestBytes and maxResponseBytes can be set",
+		},
+		"NamespaceIngressLimits": {
+			namespace: "team-a",
+			data:      map[string]string{"ingress": `{"maxTimeoutSeconds": 60, "maxRequestBytes": 1048576}`},
+		},
+		"NamespaceIngressDomain": {
+			namespace: "team-a",
+			data:      map[string]string{"ingress": `{"ingressDomain": "team-a.example.com"}`},
+			matcher:   "Invalid ingress config of a namespace, only maxTimeoutSeconds, maxRequestBytes and maxResponseBytes can be set",
+		},
+		"NamespaceClusterKey": {
+			namespace: "team-a",
+			data:      map[string]string{"batcher": `{"image": "kfserving/batcher:v0.5.0"}`},
+			matcher:   `Key "batcher" of the inferenceservice-config config map is only read from the kfserving-system namespace`,
+		},
+		"NamespaceLoggerSinks": {
+			namespace: "team-a",
+			data: map[string]string{"logger": `{"defaultUrl": "http://broker.team-a.eu.example.com",
+				"allowedSinkDomains": ["team-a.eu.example.com"]}`},
+		},
+		"NamespaceLoggerImage": {
+			namespace: "team-a",
+			data:      map[string]string{"logger": `{"image": "kfserving/logger:v0.5.0"}`},
+			matcher:   `Invalid logger config: json: unknown field "image"`,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g := gomega.NewGomegaWithT(t)
+			err := ValidateConfigMap(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: constants.InferenceServiceConfigMapName, Namespace: scenario.namespace},
+				Data:       scenario.data,
+			})
+			if scenario.matcher == "" {
+				g.Expect(err).NotTo(gomega.HaveOccurred())
+			} else {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(scenario.matcher)))
+			}
+		})
+	}
+}
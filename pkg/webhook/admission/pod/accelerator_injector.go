@@ -30,7 +30,7 @@ const (
 func InjectGKEAcceleratorSelector(pod *v1.Pod) error {
 	gpuEnabled := false
 	for _, container := range pod.Spec.Containers {
-		if _, ok := container.Resources.Limits[constants.NvidiaGPUResourceType]; ok {
+		if utils.IsGPUEnabled(container.Resources) {
 			gpuEnabled = true
 		}
 	}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/async"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	AsyncContainerName         = "async"
+	AsyncConfigMapKeyName      = "async"
+	AsyncArgumentConfig        = "--config"
+	AsyncArgumentPort          = "--port"
+	AsyncArgumentComponentPort = "--component-port"
+	PrometheusScrapeKey        = "prometheus.io/scrape"
+	PrometheusPortKey          = "prometheus.io/port"
+	PrometheusPathKey          = "prometheus.io/path"
+)
+
+type AsyncConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+	// Seconds the frontend waits for the requests sent to the predictor on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
+}
+
+// AsyncInjector injects the async frontend receiving the requests of the predictor on the port of the predictor
+// container. The controller sets the port of the predictor container to the port of the frontend, the frontend sends
+// the queued requests to the model server on the default port.
+type AsyncInjector struct {
+	config *AsyncConfig
+}
+
+func getAsyncConfigs(configMap *v1.ConfigMap) (*AsyncConfig, error) {
+	asyncConfig := &AsyncConfig{}
+	asyncConfigValue, ok := configMap.Data[AsyncConfigMapKeyName]
+	// The async inference is optional, the pods with an async annotation fail to be mutated without the configuration
+	if !ok {
+		return asyncConfig, nil
+	}
+	if err := json.Unmarshal([]byte(asyncConfigValue), &asyncConfig); err != nil {
+		return asyncConfig, fmt.Errorf("Unable to unmarshall async json string due to %v ", err)
+	}
+	resourceDefaults := []string{asyncConfig.MemoryRequest,
+		asyncConfig.MemoryLimit,
+		asyncConfig.CpuRequest,
+		asyncConfig.CpuLimit}
+	for _, key := range resourceDefaults {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return asyncConfig, fmt.Errorf("Failed to parse resource configuration for %q: %q",
+				AsyncConfigMapKeyName, err.Error())
+		}
+	}
+	return asyncConfig, nil
+}
+
+// InjectAsync adds the async frontend to the pods annotated with an async configuration
+func (ai *AsyncInjector) InjectAsync(pod *v1.Pod) error {
+	asyncSpec, ok := pod.ObjectMeta.Annotations[constants.AsyncInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// Don't inject if the sidecar is already injected
+	if getContainer(pod, AsyncContainerName) != nil {
+		return nil
+	}
+	if ai.config.Image == "" {
+		return fmt.Errorf("Invalid configuration: the %q configuration is required by the async inference",
+			AsyncConfigMapKeyName)
+	}
+
+	asyncContainer := v1.Container{
+		Name:  AsyncContainerName,
+		Image: ai.config.Image,
+		Args: []string{
+			AsyncArgumentConfig,
+			asyncSpec,
+			AsyncArgumentPort,
+			constants.InferenceServiceDefaultAsyncPort,
+			AsyncArgumentComponentPort,
+			constants.InferenceServiceDefaultHttpPort,
+		},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ai.config.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(ai.config.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ai.config.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(ai.config.MemoryRequest),
+			},
+		},
+		SecurityContext: pod.Spec.Containers[0].SecurityContext.DeepCopy(),
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, asyncContainer)
+
+	// The queue depth of the frontend is scraped from the pod, unless the pod is already scraped
+	if _, ok := pod.ObjectMeta.Annotations[PrometheusScrapeKey]; !ok {
+		pod.ObjectMeta.Annotations[PrometheusScrapeKey] = "true"
+		pod.ObjectMeta.Annotations[PrometheusPortKey] = constants.InferenceServiceDefaultAsyncPort
+		pod.ObjectMeta.Annotations[PrometheusPathKey] = async.MetricsPath
+	}
+	return nil
+}
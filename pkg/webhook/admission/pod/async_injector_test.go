@@ -0,0 +1,184 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+var (
+	asyncConfig = &AsyncConfig{
+		Image:         "gcr.io/kfserving/async:latest",
+		CpuRequest:    "100m",
+		CpuLimit:      "1",
+		MemoryRequest: "100Mi",
+		MemoryLimit:   "1Gi",
+	}
+
+	asyncResourceRequirement = v1.ResourceRequirements{
+		Limits: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Requests: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+)
+
+func TestAsyncInjector(t *testing.T) {
+	config := `{"backend":"memory","queueName":"kfserving:async:default:sklearn","workers":1,` +
+		`"timeoutSeconds":300,"resultTTLSeconds":3600}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddAsync": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.AsyncInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.AsyncInternalAnnotationKey: config,
+						PrometheusScrapeKey:                  "true",
+						PrometheusPortKey:                    "9084",
+						PrometheusPathKey:                    "/async/metrics",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:      AsyncContainerName,
+							Image:     "gcr.io/kfserving/async:latest",
+							Args:      []string{"--config", config, "--port", "9084", "--component-port", "8080"},
+							Resources: asyncResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"AlreadyScraped": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.AsyncInternalAnnotationKey: config,
+						PrometheusScrapeKey:                  "true",
+						PrometheusPortKey:                    "9090",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.AsyncInternalAnnotationKey: config,
+						PrometheusScrapeKey:                  "true",
+						PrometheusPortKey:                    "9090",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:      AsyncContainerName,
+							Image:     "gcr.io/kfserving/async:latest",
+							Args:      []string{"--config", config, "--port", "9084", "--component-port", "8080"},
+							Resources: asyncResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.AsyncInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: AsyncContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.AsyncInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: AsyncContainerName},
+					},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &AsyncInjector{config: asyncConfig}
+		if err := injector.InjectAsync(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestAsyncInjectorMissingConfiguration(t *testing.T) {
+	injector := &AsyncInjector{config: &AsyncConfig{}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.AsyncInternalAnnotationKey: "{}"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	if err := injector.InjectAsync(pod); err == nil {
+		t.Errorf("Expected an error for the missing async configuration")
+	}
+}
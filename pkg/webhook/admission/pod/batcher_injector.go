@@ -26,11 +26,13 @@ import (
 )
 
 const (
-	BatcherContainerName        = "batcher"
-	BatcherConfigMapKeyName     = "batcher"
-	BatcherArgumentMaxBatchSize = "--max-batchsize"
-	BatcherArgumentMaxLatency   = "--max-latency"
-	BatcherArgumentTimeout      = "--timeout"
+	BatcherContainerName         = "batcher"
+	BatcherConfigMapKeyName      = "batcher"
+	BatcherArgumentMaxBatchSize  = "--max-batchsize"
+	BatcherArgumentMaxLatency    = "--max-latency"
+	BatcherArgumentTimeout       = "--timeout"
+	BatcherArgumentMaxQueueDepth = "--max-queue-depth"
+	BatcherArgumentStreaming     = "--streaming"
 )
 
 type BatcherConfig struct {
@@ -39,6 +41,8 @@ type BatcherConfig struct {
 	CpuLimit      string `json:"cpuLimit"`
 	MemoryRequest string `json:"memoryRequest"`
 	MemoryLimit   string `json:"memoryLimit"`
+	// Seconds the batcher waits for its pending requests on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
 }
 
 type BatcherInjector struct {
@@ -98,6 +102,16 @@ func (il *BatcherInjector) InjectBatcher(pod *v1.Pod) error {
 		args = append(args, timeout)
 	}
 
+	maxQueueDepth, ok := pod.ObjectMeta.Annotations[constants.BatcherMaxQueueDepthInternalAnnotationKey]
+	if ok {
+		args = append(args, BatcherArgumentMaxQueueDepth)
+		args = append(args, maxQueueDepth)
+	}
+
+	if _, ok := pod.ObjectMeta.Annotations[constants.StreamingInternalAnnotationKey]; ok {
+		args = append(args, BatcherArgumentStreaming)
+	}
+
 	// Don't inject if Contianer already injected
 	for _, container := range pod.Spec.Containers {
 		if strings.Compare(container.Name, BatcherContainerName) == 0 {
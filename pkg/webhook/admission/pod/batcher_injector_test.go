@@ -65,10 +65,11 @@ func TestBatcherInjector(t *testing.T) {
 					Name:      "deployment",
 					Namespace: "default",
 					Annotations: map[string]string{
-						constants.BatcherInternalAnnotationKey:             "true",
-						constants.BatcherMaxBatchSizeInternalAnnotationKey: "32",
-						constants.BatcherMaxLatencyInternalAnnotationKey:   "5000",
-						constants.BatcherTimeoutInternalAnnotationKey:      "60",
+						constants.BatcherInternalAnnotationKey:              "true",
+						constants.BatcherMaxBatchSizeInternalAnnotationKey:  "32",
+						constants.BatcherMaxLatencyInternalAnnotationKey:    "5000",
+						constants.BatcherTimeoutInternalAnnotationKey:       "60",
+						constants.BatcherMaxQueueDepthInternalAnnotationKey: "100",
 					},
 					Labels: map[string]string{
 						"serving.kubeflow.org/inferenceservice": "sklearn",
@@ -87,10 +88,11 @@ func TestBatcherInjector(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "deployment",
 					Annotations: map[string]string{
-						constants.BatcherInternalAnnotationKey:             "true",
-						constants.BatcherMaxBatchSizeInternalAnnotationKey: "32",
-						constants.BatcherMaxLatencyInternalAnnotationKey:   "5000",
-						constants.BatcherTimeoutInternalAnnotationKey:      "60",
+						constants.BatcherInternalAnnotationKey:              "true",
+						constants.BatcherMaxBatchSizeInternalAnnotationKey:  "32",
+						constants.BatcherMaxLatencyInternalAnnotationKey:    "5000",
+						constants.BatcherTimeoutInternalAnnotationKey:       "60",
+						constants.BatcherMaxQueueDepthInternalAnnotationKey: "100",
 					},
 				},
 				Spec: v1.PodSpec{
@@ -108,6 +110,8 @@ func TestBatcherInjector(t *testing.T) {
 								"5000",
 								BatcherArgumentTimeout,
 								"60",
+								BatcherArgumentMaxQueueDepth,
+								"100",
 							},
 							Resources: batcherResourceRequirement,
 						},
@@ -115,6 +119,37 @@ func TestBatcherInjector(t *testing.T) {
 				},
 			},
 		},
+		"StreamingBatcher": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "deployment",
+					Annotations: map[string]string{
+						constants.BatcherInternalAnnotationKey:   "true",
+						constants.StreamingInternalAnnotationKey: "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "sklearn",
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: "sklearn",
+						},
+						{
+							Name:      BatcherContainerName,
+							Image:     batcherConfig.Image,
+							Args:      []string{BatcherArgumentStreaming},
+							Resources: batcherResourceRequirement,
+						},
+					},
+				},
+			},
+		},
 		"DoNotAddBatcher": {
 			original: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/imagedigest"
+	"github.com/kubeflow/kfserving/pkg/warmup"
+	v1 "k8s.io/api/core/v1"
+)
+
+// injectedImage is a container the webhook injects with the image of a key of the inferenceservice-config ConfigMap
+type injectedImage struct {
+	containerName string
+	configKey     string
+	// injected tells whether the container is injected into the pods with the annotations
+	injected func(annotations map[string]string) bool
+}
+
+func hasAnnotation(key string) func(annotations map[string]string) bool {
+	return func(annotations map[string]string) bool {
+		_, ok := annotations[key]
+		return ok
+	}
+}
+
+var injectedImages = []injectedImage{
+	{StorageInitializerContainerName, StorageInitializerConfigMapKeyName, func(annotations map[string]string) bool {
+		return annotations[constants.StorageInitializerSourceUriInternalAnnotationKey] != ""
+	}},
+	{WarmupInitializerContainerName, StorageInitializerConfigMapKeyName, func(annotations map[string]string) bool {
+		config := warmup.Config{}
+		warmupSpec, ok := annotations[constants.WarmupInternalAnnotationKey]
+		return ok && json.Unmarshal([]byte(warmupSpec), &config) == nil && config.StorageURI != ""
+	}},
+	{LoggerContainerName, LoggerConfigMapKeyName, hasAnnotation(constants.LoggerInternalAnnotationKey)},
+	{BatcherContainerName, BatcherConfigMapKeyName, hasAnnotation(constants.BatcherInternalAnnotationKey)},
+	{AsyncContainerName, AsyncConfigMapKeyName, hasAnnotation(constants.AsyncInternalAnnotationKey)},
+	{RequestValidationContainerName, RequestValidationConfigMapKeyName,
+		hasAnnotation(constants.RequestValidationInternalAnnotationKey)},
+	{ResponseCacheContainerName, ResponseCacheConfigMapKeyName,
+		hasAnnotation(constants.ResponseCacheInternalAnnotationKey)},
+	{WarmupContainerName, WarmupConfigMapKeyName, hasAnnotation(constants.WarmupInternalAnnotationKey)},
+}
+
+// GetInjectedImages returns the images of the containers the webhook injects into the pods with the annotations,
+// keyed by container name, as set in the inferenceservice-config ConfigMap. The containers without an image are
+// skipped, their injection fails.
+func GetInjectedImages(configMap *v1.ConfigMap, annotations map[string]string) (map[string]string, error) {
+	images := map[string]string{}
+	for _, injected := range injectedImages {
+		if !injected.injected(annotations) {
+			continue
+		}
+		config := struct {
+			Image string `json:"image"`
+		}{}
+		if value, ok := configMap.Data[injected.configKey]; ok {
+			if err := json.Unmarshal([]byte(value), &config); err != nil {
+				return nil, fmt.Errorf("Unable to unmarshall %v json string due to %v ", injected.configKey, err)
+			}
+		}
+		if config.Image == "" && injected.configKey == StorageInitializerConfigMapKeyName {
+			config.Image = StorageInitializerContainerImage + ":" + StorageInitializerContainerImageVersion
+		}
+		if config.Image != "" {
+			images[injected.containerName] = config.Image
+		}
+	}
+	return images, nil
+}
+
+// InjectImageDigests deploys the injected containers by the digests the controller resolved their images to. The
+// digests are only used while the image of a container is still the resolved one, a container injected with an image
+// changed in the ConfigMap since is deployed by tag until the controller resolves it.
+func InjectImageDigests(pod *v1.Pod) error {
+	digests, ok := pod.ObjectMeta.Annotations[constants.ImageDigestsInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	resolvedImages := map[string]v1beta1.ResolvedImage{}
+	if err := json.Unmarshal([]byte(digests), &resolvedImages); err != nil {
+		return fmt.Errorf("fails to unmarshal the image digests annotation %s: %v", digests, err)
+	}
+	for name, resolved := range resolvedImages {
+		for _, container := range []*v1.Container{getContainer(pod, name), getInitContainer(pod, name)} {
+			if container == nil || container.Image != resolved.Image {
+				continue
+			}
+			image, err := imagedigest.Pin(container.Image, resolved.Digest)
+			if err != nil {
+				return err
+			}
+			container.Image = image
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+const (
+	loggerDigest  = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	storageDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestGetInjectedImages(t *testing.T) {
+	configMap := &v1.ConfigMap{
+		Data: map[string]string{
+			LoggerConfigMapKeyName:  `{"image": "kfserving/logger:v0.5.0"}`,
+			BatcherConfigMapKeyName: `{"image": "kfserving/batcher:v0.5.0"}`,
+			WarmupConfigMapKeyName:  `{"image": "kfserving/warmup:v0.5.0"}`,
+		},
+	}
+	scenarios := map[string]struct {
+		annotations map[string]string
+		configMap   *v1.ConfigMap
+		expected    map[string]string
+		err         bool
+	}{
+		"NoSidecar": {
+			annotations: map[string]string{},
+			configMap:   configMap,
+			expected:    map[string]string{},
+		},
+		"LoggerAndBatcher": {
+			annotations: map[string]string{
+				constants.LoggerInternalAnnotationKey:  "true",
+				constants.BatcherInternalAnnotationKey: "true",
+			},
+			configMap: configMap,
+			expected: map[string]string{
+				LoggerContainerName:  "kfserving/logger:v0.5.0",
+				BatcherContainerName: "kfserving/batcher:v0.5.0",
+			},
+		},
+		"DefaultStorageInitializer": {
+			annotations: map[string]string{
+				constants.StorageInitializerSourceUriInternalAnnotationKey: "gs://models/sklearn",
+			},
+			configMap: configMap,
+			expected: map[string]string{
+				StorageInitializerContainerName: "gcr.io/kfserving/storage-initializer:latest",
+			},
+		},
+		"WarmupRequests": {
+			annotations: map[string]string{
+				constants.WarmupInternalAnnotationKey: `{"storageUri": "gs://models/requests"}`,
+			},
+			configMap: configMap,
+			expected: map[string]string{
+				WarmupContainerName:            "kfserving/warmup:v0.5.0",
+				WarmupInitializerContainerName: "gcr.io/kfserving/storage-initializer:latest",
+			},
+		},
+		"WarmupWithoutRequests": {
+			annotations: map[string]string{
+				constants.WarmupInternalAnnotationKey: `{}`,
+			},
+			configMap: configMap,
+			expected: map[string]string{
+				WarmupContainerName: "kfserving/warmup:v0.5.0",
+			},
+		},
+		"NotConfigured": {
+			annotations: map[string]string{
+				constants.AsyncInternalAnnotationKey: "{}",
+			},
+			configMap: configMap,
+			expected:  map[string]string{},
+		},
+		"InvalidConfig": {
+			annotations: map[string]string{
+				constants.LoggerInternalAnnotationKey: "true",
+			},
+			configMap: &v1.ConfigMap{
+				Data: map[string]string{LoggerConfigMapKeyName: `{"image": `},
+			},
+			err: true,
+		},
+	}
+	for name, scenario := range scenarios {
+		images, err := GetInjectedImages(scenario.configMap, scenario.annotations)
+		if scenario.err {
+			if err == nil {
+				t.Errorf("Test %q expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, images); diff != "" {
+			t.Errorf("Test %q unexpected images (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestInjectImageDigests(t *testing.T) {
+	digests := `{"inferenceservice-logger": {"image": "kfserving/logger:v0.5.0", "digest": "` + loggerDigest + `"},` +
+		`"storage-initializer": {"image": "kfserving/storage-initializer:v0.5.0", "digest": "` + storageDigest + `"}}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+		err      bool
+	}{
+		"PinInjectedContainers": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.ImageDigestsInternalAnnotationKey: digests},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{Name: StorageInitializerContainerName, Image: "kfserving/storage-initializer:v0.5.0"},
+					},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Image: "kfserving/sklearnserver:v0.5.0"},
+						{Name: LoggerContainerName, Image: "kfserving/logger:v0.5.0"},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{Name: StorageInitializerContainerName, Image: "kfserving/storage-initializer@" + storageDigest},
+					},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Image: "kfserving/sklearnserver:v0.5.0"},
+						{Name: LoggerContainerName, Image: "kfserving/logger@" + loggerDigest},
+					},
+				},
+			},
+		},
+		"ImageChangedSinceResolved": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.ImageDigestsInternalAnnotationKey: digests},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Image: "kfserving/sklearnserver:v0.5.0"},
+						{Name: LoggerContainerName, Image: "kfserving/logger:v0.6.0"},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Image: "kfserving/sklearnserver:v0.5.0"},
+						{Name: LoggerContainerName, Image: "kfserving/logger:v0.6.0"},
+					},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: LoggerContainerName, Image: "kfserving/logger:v0.5.0"},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: LoggerContainerName, Image: "kfserving/logger:v0.5.0"},
+					},
+				},
+			},
+		},
+		"InvalidAnnotation": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.ImageDigestsInternalAnnotationKey: "{"},
+				},
+			},
+			err: true,
+		},
+	}
+	for name, scenario := range scenarios {
+		err := InjectImageDigests(scenario.original)
+		if scenario.err {
+			if err == nil {
+				t.Errorf("Test %q expected an error", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected pod spec (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+type ImagePullSecretInjector struct {
+	config *v1beta1.RegistryConfig
+}
+
+// InjectImagePullSecrets merges the image pull secrets of the registry config into the pods a container was injected
+// into, the images of the injected containers are set in the inferenceservice-config ConfigMap and may be pulled from
+// the private registry of the cluster. The pods of the components already have them from the controller.
+func (ii *ImagePullSecretInjector) InjectImagePullSecrets(pod *v1.Pod) error {
+	if len(ii.config.ImagePullSecrets) == 0 {
+		return nil
+	}
+	for _, name := range injectedContainerNames {
+		if getContainer(pod, name) != nil || getInitContainer(pod, name) != nil {
+			pod.Spec.ImagePullSecrets = utils.MergeImagePullSecrets(pod.Spec.ImagePullSecrets,
+				ii.config.ImagePullSecrets)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestImagePullSecretInjector(t *testing.T) {
+	config := &v1beta1.RegistryConfig{
+		ImagePullSecrets: []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+	}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected []v1.LocalObjectReference
+	}{
+		"InjectedInitContainer": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: StorageInitializerContainerName}},
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: []v1.LocalObjectReference{{Name: "kfserving-registry"}},
+		},
+		"MergeWithPodSecrets": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: LoggerContainerName},
+					},
+					ImagePullSecrets: []v1.LocalObjectReference{{Name: "team-registry"}, {Name: "kfserving-registry"}},
+				},
+			},
+			expected: []v1.LocalObjectReference{{Name: "team-registry"}, {Name: "kfserving-registry"}},
+		},
+		"NoInjectedContainer": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &ImagePullSecretInjector{
+			config: config,
+		}
+		if err := injector.InjectImagePullSecrets(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original.Spec.ImagePullSecrets); diff != "" {
+			t.Errorf("Test %q unexpected image pull secrets (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
 	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/logger"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"net/url"
 	"strings"
 )
 
@@ -36,6 +38,10 @@ const (
 	LoggerArgumentInferenceService = "--inference-service"
 	LoggerArgumentNamespace        = "--namespace"
 	LoggerArgumentEndpoint         = "--endpoint"
+	LoggerArgumentAllowedDomains   = "--allowed-sink-domains"
+	LoggerArgumentExplainerUrl     = "--explainer-url"
+	LoggerArgumentExplainSampling  = "--explain-sampling-percent"
+	LoggerArgumentStreaming        = "--streaming"
 )
 
 type LoggerConfig struct {
@@ -45,6 +51,11 @@ type LoggerConfig struct {
 	MemoryRequest string `json:"memoryRequest"`
 	MemoryLimit   string `json:"memoryLimit"`
 	DefaultUrl    string `json:"defaultUrl"`
+	// Domains the log sinks are restricted to, e.g. to comply with data residency requirements.
+	// Subdomains of an allowed domain are accepted, an empty list places no restriction on the sinks.
+	AllowedSinkDomains []string `json:"allowedSinkDomains,omitempty"`
+	// Seconds the logger waits for its in-flight requests and queued log events on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
 }
 
 type LoggerInjector struct {
@@ -88,6 +99,16 @@ func (il *LoggerInjector) InjectLogger(pod *v1.Pod) error {
 		logUrl = il.config.DefaultUrl
 	}
 
+	if len(il.config.AllowedSinkDomains) != 0 {
+		logUrlParsed, err := url.Parse(logUrl)
+		if err != nil {
+			return fmt.Errorf("Malformed logger sink url %q: %v", logUrl, err)
+		}
+		if err := logger.ValidateSinkURL(logUrlParsed, il.config.AllowedSinkDomains); err != nil {
+			return err
+		}
+	}
+
 	logMode, ok := pod.ObjectMeta.Annotations[constants.LoggerModeInternalAnnotationKey]
 	if !ok {
 		logMode = string(v1alpha2.LogAll)
@@ -107,23 +128,37 @@ func (il *LoggerInjector) InjectLogger(pod *v1.Pod) error {
 	// Make sure securityContext is initialized and valid
 	securityContext := pod.Spec.Containers[0].SecurityContext.DeepCopy()
 
+	args := []string{
+		LoggerArgumentLogUrl,
+		logUrl,
+		LoggerArgumentSourceUri,
+		pod.Name,
+		LoggerArgumentMode,
+		logMode,
+		LoggerArgumentInferenceService,
+		inferenceServiceName,
+		LoggerArgumentNamespace,
+		namespace,
+		LoggerArgumentEndpoint,
+		endpoint,
+	}
+	if len(il.config.AllowedSinkDomains) != 0 {
+		args = append(args, LoggerArgumentAllowedDomains, strings.Join(il.config.AllowedSinkDomains, ","))
+	}
+	if explainerUrl, ok := pod.ObjectMeta.Annotations[constants.LoggerExplainerUrlInternalAnnotationKey]; ok {
+		args = append(args, LoggerArgumentExplainerUrl, explainerUrl)
+		if samplingPercent, ok := pod.ObjectMeta.Annotations[constants.LoggerExplainSamplingInternalAnnotationKey]; ok {
+			args = append(args, LoggerArgumentExplainSampling, samplingPercent)
+		}
+	}
+	if _, ok := pod.ObjectMeta.Annotations[constants.StreamingInternalAnnotationKey]; ok {
+		args = append(args, LoggerArgumentStreaming)
+	}
+
 	loggerContainer := &v1.Container{
 		Name:  LoggerContainerName,
 		Image: il.config.Image,
-		Args: []string{
-			LoggerArgumentLogUrl,
-			logUrl,
-			LoggerArgumentSourceUri,
-			pod.Name,
-			LoggerArgumentMode,
-			logMode,
-			LoggerArgumentInferenceService,
-			inferenceServiceName,
-			LoggerArgumentNamespace,
-			namespace,
-			LoggerArgumentEndpoint,
-			endpoint,
-		},
+		Args:  args,
 		Resources: v1.ResourceRequirements{
 			Limits: map[v1.ResourceName]resource.Quantity{
 				v1.ResourceCPU:    resource.MustParse(il.config.CpuLimit),
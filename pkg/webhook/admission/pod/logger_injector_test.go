@@ -153,3 +153,142 @@ func TestLoggerInjector(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerInjectorAllowedSinkDomains(t *testing.T) {
+	config := &LoggerConfig{
+		Image:              "gcr.io/kfserving/logger:latest",
+		CpuRequest:         LoggerDefaultCPURequest,
+		CpuLimit:           LoggerDefaultCPULimit,
+		MemoryRequest:      LoggerDefaultMemoryRequest,
+		MemoryLimit:        LoggerDefaultMemoryLimit,
+		AllowedSinkDomains: []string{"eu.example.com"},
+	}
+	scenarios := map[string]struct {
+		sinkUrl    string
+		expectErr  bool
+		expectArgs []string
+	}{
+		"SinkInAllowedDomain": {
+			sinkUrl:   "http://logs.eu.example.com/",
+			expectErr: false,
+			expectArgs: []string{
+				LoggerArgumentLogUrl,
+				"http://logs.eu.example.com/",
+				LoggerArgumentSourceUri,
+				"deployment",
+				LoggerArgumentMode,
+				"all",
+				LoggerArgumentInferenceService,
+				"sklearn",
+				LoggerArgumentNamespace,
+				"default",
+				LoggerArgumentEndpoint,
+				"default",
+				LoggerArgumentAllowedDomains,
+				"eu.example.com",
+			},
+		},
+		"SinkOutsideAllowedDomains": {
+			sinkUrl:   "http://logs.us.example.com/",
+			expectErr: true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "deployment",
+				Namespace: "default",
+				Annotations: map[string]string{
+					constants.LoggerInternalAnnotationKey:        "true",
+					constants.LoggerSinkUrlInternalAnnotationKey: scenario.sinkUrl,
+					constants.LoggerModeInternalAnnotationKey:    string(v1alpha2.LogAll),
+				},
+				Labels: map[string]string{
+					constants.KServiceModelLabel:    "sklearn",
+					constants.KServiceEndpointLabel: "default",
+				},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name: "sklearn",
+				}},
+			},
+		}
+		injector := &LoggerInjector{config}
+		err := injector.InjectLogger(pod)
+		if scenario.expectErr {
+			if err == nil {
+				t.Errorf("Test %q expected error, got none", name)
+			}
+			if len(pod.Spec.Containers) != 1 {
+				t.Errorf("Test %q expected logger not to be injected", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+			continue
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expectArgs, pod.Spec.Containers[1].Args); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestLoggerInjectorExplainSampling(t *testing.T) {
+	config := &LoggerConfig{
+		Image:         "gcr.io/kfserving/logger:latest",
+		CpuRequest:    LoggerDefaultCPURequest,
+		CpuLimit:      LoggerDefaultCPULimit,
+		MemoryRequest: LoggerDefaultMemoryRequest,
+		MemoryLimit:   LoggerDefaultMemoryLimit,
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deployment",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.LoggerInternalAnnotationKey:                "true",
+				constants.LoggerSinkUrlInternalAnnotationKey:         "http://httpbin.org/",
+				constants.LoggerModeInternalAnnotationKey:            string(v1alpha2.LogAll),
+				constants.LoggerExplainerUrlInternalAnnotationKey:    "http://sklearn-explainer-default.default/v1/models/sklearn:explain",
+				constants.LoggerExplainSamplingInternalAnnotationKey: "10",
+			},
+			Labels: map[string]string{
+				constants.KServiceModelLabel:    "sklearn",
+				constants.KServiceEndpointLabel: "default",
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name: "sklearn",
+			}},
+		},
+	}
+	expectArgs := []string{
+		LoggerArgumentLogUrl,
+		"http://httpbin.org/",
+		LoggerArgumentSourceUri,
+		"deployment",
+		LoggerArgumentMode,
+		"all",
+		LoggerArgumentInferenceService,
+		"sklearn",
+		LoggerArgumentNamespace,
+		"default",
+		LoggerArgumentEndpoint,
+		"default",
+		LoggerArgumentExplainerUrl,
+		"http://sklearn-explainer-default.default/v1/models/sklearn:explain",
+		LoggerArgumentExplainSampling,
+		"10",
+	}
+	injector := &LoggerInjector{config}
+	if err := injector.InjectLogger(pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff, _ := kmp.SafeDiff(expectArgs, pod.Spec.Containers[1].Args); diff != "" {
+		t.Errorf("unexpected result (-want +got): %v", diff)
+	}
+}
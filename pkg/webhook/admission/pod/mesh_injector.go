@@ -0,0 +1,44 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// InjectMeshCompatibility runs the init containers downloading the model and the warmup requests as the user of the
+// Istio sidecar in the pods the controller injects the sidecar into. The init containers run before the sidecar
+// starts, their traffic would be redirected to the sidecar which is not listening yet otherwise. The storage is
+// reached outside of the mesh, over plain HTTP or TLS.
+func InjectMeshCompatibility(pod *v1.Pod) error {
+	if pod.ObjectMeta.Annotations[constants.IstioSidecarInjectAnnotationKey] != "true" {
+		return nil
+	}
+	for _, name := range []string{StorageInitializerContainerName, WarmupInitializerContainerName} {
+		initContainer := getInitContainer(pod, name)
+		if initContainer == nil {
+			continue
+		}
+		if initContainer.SecurityContext == nil {
+			initContainer.SecurityContext = &v1.SecurityContext{}
+		}
+		uid := constants.IstioProxyUID
+		initContainer.SecurityContext.RunAsUser = &uid
+	}
+	return nil
+}
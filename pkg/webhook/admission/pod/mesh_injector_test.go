@@ -0,0 +1,81 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectMeshCompatibility(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	proxyUID := constants.IstioProxyUID
+	userUID := int64(1000)
+	scenarios := map[string]struct {
+		annotations map[string]string
+		expected    []*v1.SecurityContext
+	}{
+		"SidecarInjected": {
+			annotations: map[string]string{constants.IstioSidecarInjectAnnotationKey: "true"},
+			expected: []*v1.SecurityContext{
+				{RunAsUser: &proxyUID},
+				{RunAsUser: &proxyUID, RunAsNonRoot: proto.Bool(true)},
+				{RunAsUser: &userUID},
+			},
+		},
+		"SidecarNotInjected": {
+			annotations: map[string]string{constants.IstioSidecarInjectAnnotationKey: "false"},
+			expected: []*v1.SecurityContext{
+				nil,
+				{RunAsUser: &userUID, RunAsNonRoot: proto.Bool(true)},
+				{RunAsUser: &userUID},
+			},
+		},
+		"NamespaceInjection": {
+			expected: []*v1.SecurityContext{
+				nil,
+				{RunAsUser: &userUID, RunAsNonRoot: proto.Bool(true)},
+				{RunAsUser: &userUID},
+			},
+		},
+	}
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			uid := userUID
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: scenario.annotations},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{Name: StorageInitializerContainerName},
+						{Name: WarmupInitializerContainerName,
+							SecurityContext: &v1.SecurityContext{RunAsUser: &uid, RunAsNonRoot: proto.Bool(true)}},
+						{Name: "user-init", SecurityContext: &v1.SecurityContext{RunAsUser: &uid}},
+					},
+				},
+			}
+			g.Expect(InjectMeshCompatibility(pod)).To(gomega.Succeed())
+			for i, container := range pod.Spec.InitContainers {
+				g.Expect(container.SecurityContext).To(gomega.Equal(scenario.expected[i]), container.Name)
+			}
+		})
+	}
+}
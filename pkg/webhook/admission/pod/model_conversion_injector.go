@@ -0,0 +1,150 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	ModelConverterContainerName      = "model-converter"
+	ModelConversionCacheVolumeName   = "kfserving-conversion-cache"
+	ModelConversionCacheMountPath    = "/mnt/conversion-cache"
+	ModelConverterModelDirEnvVarKey  = "MODEL_DIR"
+	ModelConverterGPUTypeEnvVarKey   = "GPU_TYPE"
+	ModelConverterCacheDirEnvVarKey  = "MODEL_CONVERSION_CACHE_DIR"
+	modelConverterCacheWrapperBinary = "/bin/sh"
+)
+
+// modelConversionCacheScript runs the converter passed as arguments unless the conversion of the model for the GPU
+// type is found in the cache. The cache entries are keyed on the checksum of the downloaded model files and the GPU
+// type, the GPU type is detected with nvidia-smi when not set from the accelerator annotation.
+const modelConversionCacheScript = `set -e
+gpu="${GPU_TYPE}"
+if [ -z "$gpu" ] && command -v nvidia-smi >/dev/null 2>&1; then
+  gpu=$(nvidia-smi --query-gpu=name --format=csv,noheader | head -n 1)
+fi
+gpu=$(echo "${gpu:-cpu}" | tr -c 'a-zA-Z0-9.\n' '-')
+checksum=$(cd "$MODEL_DIR" && find . -type f -print0 | LC_ALL=C sort -z | xargs -0 -r sha256sum | sha256sum | cut -c 1-16)
+cached="$MODEL_CONVERSION_CACHE_DIR/$checksum-$gpu"
+if [ -f "$cached/.complete" ]; then
+  echo "Using the model conversion cached in $cached"
+  cp -a "$cached/." "$MODEL_DIR/"
+  rm -f "$MODEL_DIR/.complete"
+  exit 0
+fi
+"$@"
+tmp="$cached.$HOSTNAME"
+if rm -rf "$tmp" && mkdir -p "$tmp" && cp -a "$MODEL_DIR/." "$tmp/" && touch "$tmp/.complete" && [ ! -e "$cached" ]; then
+  mv "$tmp" "$cached" || rm -rf "$tmp"
+else
+  echo "Skipping the cache of the model conversion in $cached"
+  rm -rf "$tmp"
+fi
+`
+
+// InjectModelConverter injects an init container converting the model downloaded by the StorageInitializer before
+// the model server starts, the converted models are cached in the volume claim of the conversion when set.
+func InjectModelConverter(pod *v1.Pod) error {
+	conversionSpec, ok := pod.ObjectMeta.Annotations[constants.ModelConversionInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+
+	// Dont inject if InitContainer already injected
+	storageInitializerInjected := false
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == ModelConverterContainerName {
+			return nil
+		}
+		if container.Name == StorageInitializerContainerName {
+			storageInitializerInjected = true
+		}
+	}
+	if !storageInitializerInjected {
+		return fmt.Errorf("Invalid configuration: model conversion requires the %s init container", StorageInitializerContainerName)
+	}
+
+	conversion := &v1beta1.ModelConversionSpec{}
+	if err := json.Unmarshal([]byte(conversionSpec), conversion); err != nil {
+		return fmt.Errorf("Invalid %s annotation: %v", constants.ModelConversionInternalAnnotationKey, err)
+	}
+
+	var userContainer *v1.Container
+	for idx, container := range pod.Spec.Containers {
+		if container.Name == constants.InferenceServiceContainerName {
+			userContainer = &pod.Spec.Containers[idx]
+			break
+		}
+	}
+	if userContainer == nil {
+		return fmt.Errorf("Invalid configuration: cannot find container: %s", constants.InferenceServiceContainerName)
+	}
+
+	resources := conversion.Resources
+	if len(resources.Limits) == 0 && len(resources.Requests) == 0 {
+		resources = *userContainer.Resources.DeepCopy()
+	}
+	env := append([]v1.EnvVar{
+		{Name: ModelConverterModelDirEnvVarKey, Value: constants.DefaultModelLocalMountPath},
+		{Name: ModelConverterGPUTypeEnvVarKey, Value: pod.ObjectMeta.Annotations[constants.InferenceServiceGKEAcceleratorAnnotationKey]},
+	}, conversion.Env...)
+	converter := v1.Container{
+		Name:                     ModelConverterContainerName,
+		Image:                    conversion.Image,
+		Command:                  conversion.Command,
+		Args:                     conversion.Args,
+		Env:                      env,
+		Resources:                resources,
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      StorageInitializerVolumeName,
+				MountPath: constants.DefaultModelLocalMountPath,
+				ReadOnly:  false,
+			},
+		},
+		SecurityContext: userContainer.SecurityContext.DeepCopy(),
+	}
+
+	if conversion.CacheVolumeClaim != nil {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name: ModelConversionCacheVolumeName,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: *conversion.CacheVolumeClaim,
+				},
+			},
+		})
+		converter.VolumeMounts = append(converter.VolumeMounts, v1.VolumeMount{
+			Name:      ModelConversionCacheVolumeName,
+			MountPath: ModelConversionCacheMountPath,
+		})
+		converter.Env = append(converter.Env, v1.EnvVar{Name: ModelConverterCacheDirEnvVarKey, Value: ModelConversionCacheMountPath})
+		// The converter command is passed as the arguments of the cache script
+		converter.Command = []string{modelConverterCacheWrapperBinary, "-c", modelConversionCacheScript, ModelConverterContainerName}
+		converter.Args = append(append([]string{}, conversion.Command...), conversion.Args...)
+	}
+
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, converter)
+	return nil
+}
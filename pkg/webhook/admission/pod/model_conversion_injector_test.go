@@ -0,0 +1,220 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestModelConverterInjector(t *testing.T) {
+	gpuResources := v1.ResourceRequirements{
+		Limits: v1.ResourceList{constants.NvidiaGPUResourceType: resource.MustParse("1")},
+	}
+	storageInitializer := v1.Container{Name: StorageInitializerContainerName}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"MissingAnnotations": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+					InitContainers: []v1.Container{storageInitializer},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+					InitContainers: []v1.Container{storageInitializer},
+				},
+			},
+		},
+		"ConverterInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey:        `{"image":"trtexec:21.03","command":["build-engine"],"args":["--fp16"]}`,
+						constants.InferenceServiceGKEAcceleratorAnnotationKey: "nvidia-tesla-t4",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName, Resources: gpuResources}},
+					InitContainers: []v1.Container{storageInitializer},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey:        `{"image":"trtexec:21.03","command":["build-engine"],"args":["--fp16"]}`,
+						constants.InferenceServiceGKEAcceleratorAnnotationKey: "nvidia-tesla-t4",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName, Resources: gpuResources}},
+					InitContainers: []v1.Container{
+						storageInitializer,
+						{
+							Name:    ModelConverterContainerName,
+							Image:   "trtexec:21.03",
+							Command: []string{"build-engine"},
+							Args:    []string{"--fp16"},
+							Env: []v1.EnvVar{
+								{Name: ModelConverterModelDirEnvVarKey, Value: constants.DefaultModelLocalMountPath},
+								{Name: ModelConverterGPUTypeEnvVarKey, Value: "nvidia-tesla-t4"},
+							},
+							Resources:                gpuResources,
+							TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+							VolumeMounts: []v1.VolumeMount{
+								{Name: StorageInitializerVolumeName, MountPath: constants.DefaultModelLocalMountPath},
+							},
+						},
+					},
+				},
+			},
+		},
+		"CachedConverterInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":"trtexec:21.03","command":["build-engine"],"args":["--fp16"],"cacheVolumeClaim":"engines"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName, Resources: gpuResources}},
+					InitContainers: []v1.Container{storageInitializer},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":"trtexec:21.03","command":["build-engine"],"args":["--fp16"],"cacheVolumeClaim":"engines"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName, Resources: gpuResources}},
+					InitContainers: []v1.Container{
+						storageInitializer,
+						{
+							Name:    ModelConverterContainerName,
+							Image:   "trtexec:21.03",
+							Command: []string{"/bin/sh", "-c", modelConversionCacheScript, ModelConverterContainerName},
+							Args:    []string{"build-engine", "--fp16"},
+							Env: []v1.EnvVar{
+								{Name: ModelConverterModelDirEnvVarKey, Value: constants.DefaultModelLocalMountPath},
+								{Name: ModelConverterGPUTypeEnvVarKey, Value: ""},
+								{Name: ModelConverterCacheDirEnvVarKey, Value: ModelConversionCacheMountPath},
+							},
+							Resources:                gpuResources,
+							TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+							VolumeMounts: []v1.VolumeMount{
+								{Name: StorageInitializerVolumeName, MountPath: constants.DefaultModelLocalMountPath},
+								{Name: ModelConversionCacheVolumeName, MountPath: ModelConversionCacheMountPath},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: ModelConversionCacheVolumeName,
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "engines"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":"trtexec:21.03"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+					InitContainers: []v1.Container{storageInitializer, {Name: ModelConverterContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":"trtexec:21.03"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+					InitContainers: []v1.Container{storageInitializer, {Name: ModelConverterContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectModelConverter(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected result: %s", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestModelConverterInjectorFailureCases(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	scenarios := map[string]struct {
+		pod *v1.Pod
+	}{
+		"MissingStorageInitializer": {
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":"trtexec:21.03"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+		"InvalidAnnotation": {
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.ModelConversionInternalAnnotationKey: `{"image":`,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+					InitContainers: []v1.Container{{Name: StorageInitializerContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(InjectModelConverter(scenario.pod)).NotTo(gomega.Succeed())
+		})
+	}
+}
@@ -22,8 +22,10 @@ import (
 	k8types "k8s.io/apimachinery/pkg/types"
 	"net/http"
 
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
 	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/kubeflow/kfserving/pkg/credentials"
+	"github.com/kubeflow/kfserving/pkg/wakequeue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -34,7 +36,9 @@ var log = logf.Log.WithName(constants.PodMutatorWebhookName)
 
 // Mutator is a webhook that injects incoming pods
 type Mutator struct {
-	Client  client.Client
+	Client client.Client
+	// Reader reads the ReplicaSets of the cold started pods from the API server
+	Reader  client.Reader
 	Decoder *admission.Decoder
 }
 
@@ -92,6 +96,13 @@ func (mutator *Mutator) mutate(pod *v1.Pod, configMap *v1.ConfigMap) error {
 	if err != nil {
 		return err
 	}
+	// The sinks are resolved with the overlay of the namespace, as the log sinks of the inference services are validated
+	sinkConfig, err := v1beta1.NewLoggerConfig(mutator.Client, pod.Namespace)
+	if err != nil {
+		return err
+	}
+	loggerConfig.DefaultUrl = sinkConfig.DefaultUrl
+	loggerConfig.AllowedSinkDomains = sinkConfig.AllowedSinkDomains
 
 	loggerInjector := &LoggerInjector{
 		config: loggerConfig,
@@ -106,11 +117,109 @@ func (mutator *Mutator) mutate(pod *v1.Pod, configMap *v1.ConfigMap) error {
 		config: batcherConfig,
 	}
 
+	scaleFromZeroConfig, err := wakequeue.GetConfig(configMap)
+	if err != nil {
+		return err
+	}
+
+	scaleFromZeroInjector := &ScaleFromZeroInjector{
+		config: scaleFromZeroConfig,
+		reader: mutator.Reader,
+	}
+
+	tracingConfig, err := getTracingConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	tracingInjector := &TracingInjector{
+		config: tracingConfig,
+	}
+
+	warmupConfig, err := getWarmupConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	warmupInjector := &WarmupInjector{
+		config:             warmupConfig,
+		storageInitializer: storageInitializer,
+	}
+
+	asyncConfig, err := getAsyncConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	asyncInjector := &AsyncInjector{
+		config: asyncConfig,
+	}
+
+	requestValidationConfig, err := getRequestValidationConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	requestValidationInjector := &RequestValidationInjector{
+		config: requestValidationConfig,
+	}
+
+	responseCacheConfig, err := getResponseCacheConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	responseCacheInjector := &ResponseCacheInjector{
+		config: responseCacheConfig,
+	}
+
+	shutdownInjector := &ShutdownInjector{
+		responseCacheConfig:     responseCacheConfig,
+		requestValidationConfig: requestValidationConfig,
+		asyncConfig:             asyncConfig,
+		batcherConfig:           batcherConfig,
+		loggerConfig:            loggerConfig,
+	}
+
+	securityContextConfig, err := GetSecurityContextConfigs(configMap)
+	if err != nil {
+		return err
+	}
+
+	securityContextInjector := &SecurityContextInjector{
+		config: securityContextConfig,
+	}
+
+	registryConfig, err := v1beta1.GetRegistryConfig(configMap)
+	if err != nil {
+		return err
+	}
+
+	imagePullSecretInjector := &ImagePullSecretInjector{
+		config: registryConfig,
+	}
+
 	mutators := []func(pod *v1.Pod) error{
 		InjectGKEAcceleratorSelector,
+		InjectScheduling,
+		scaleFromZeroInjector.InjectWakeQueue,
 		storageInitializer.InjectStorageInitializer,
+		InjectModelConverter,
+		InjectSidecars,
+		InjectVolumes,
 		loggerInjector.InjectLogger,
 		batcherInjector.InjectBatcher,
+		asyncInjector.InjectAsync,
+		requestValidationInjector.InjectRequestValidation,
+		responseCacheInjector.InjectResponseCache,
+		tracingInjector.InjectTracing,
+		InjectStartupProbe,
+		warmupInjector.InjectWarmup,
+		shutdownInjector.InjectShutdownOrdering,
+		securityContextInjector.InjectSecurityContext,
+		InjectImageDigests,
+		imagePullSecretInjector.InjectImagePullSecrets,
+		InjectMeshCompatibility,
 	}
 
 	for _, mutator := range mutators {
@@ -123,8 +232,11 @@ func (mutator *Mutator) mutate(pod *v1.Pod, configMap *v1.ConfigMap) error {
 }
 
 func needMutate(pod *v1.Pod) bool {
-	// Skip webhook if pod not managed by kfserving
-	_, ok := pod.Labels[constants.InferenceServicePodLabelKey]
+	// Skip webhook if pod not managed by kfserving or not provisioned with a model by the storage initializer
+	if _, ok := pod.Labels[constants.InferenceServicePodLabelKey]; ok {
+		return true
+	}
+	_, ok := pod.Annotations[constants.StorageURIAnnotationKey]
 	return ok
 }
 
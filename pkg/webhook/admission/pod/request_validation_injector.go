@@ -0,0 +1,126 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	RequestValidationContainerName         = "request-validator"
+	RequestValidationConfigMapKeyName      = "requestValidation"
+	RequestValidationArgumentConfig        = "--config"
+	RequestValidationArgumentPort          = "--port"
+	RequestValidationArgumentComponentPort = "--component-port"
+)
+
+type RequestValidationConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+	// Seconds the validator waits for the requests sent to the predictor on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
+}
+
+// RequestValidationInjector injects the validator receiving the requests of the predictor on the port of the
+// predictor container. The controller sets the port of the predictor container to the port of the validator, the
+// validator sends the valid requests to the logger or the batcher of the predictor when injected, to the model server
+// otherwise.
+type RequestValidationInjector struct {
+	config *RequestValidationConfig
+}
+
+func getRequestValidationConfigs(configMap *v1.ConfigMap) (*RequestValidationConfig, error) {
+	validationConfig := &RequestValidationConfig{}
+	validationConfigValue, ok := configMap.Data[RequestValidationConfigMapKeyName]
+	// The request validation is optional, the pods with a request validation annotation fail to be mutated without
+	// the configuration
+	if !ok {
+		return validationConfig, nil
+	}
+	if err := json.Unmarshal([]byte(validationConfigValue), &validationConfig); err != nil {
+		return validationConfig, fmt.Errorf("Unable to unmarshall request validation json string due to %v ", err)
+	}
+	resourceDefaults := []string{validationConfig.MemoryRequest,
+		validationConfig.MemoryLimit,
+		validationConfig.CpuRequest,
+		validationConfig.CpuLimit}
+	for _, key := range resourceDefaults {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return validationConfig, fmt.Errorf("Failed to parse resource configuration for %q: %q",
+				RequestValidationConfigMapKeyName, err.Error())
+		}
+	}
+	return validationConfig, nil
+}
+
+// InjectRequestValidation adds the validator to the pods annotated with a request validation configuration
+func (ri *RequestValidationInjector) InjectRequestValidation(pod *v1.Pod) error {
+	validationSpec, ok := pod.ObjectMeta.Annotations[constants.RequestValidationInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// Don't inject if the sidecar is already injected
+	if getContainer(pod, RequestValidationContainerName) != nil {
+		return nil
+	}
+	if ri.config.Image == "" {
+		return fmt.Errorf("Invalid configuration: the %q configuration is required by the request validation",
+			RequestValidationConfigMapKeyName)
+	}
+
+	// The logger receives the requests before the batcher
+	componentPort := constants.InferenceServiceDefaultHttpPort
+	if _, ok := pod.ObjectMeta.Annotations[constants.LoggerInternalAnnotationKey]; ok {
+		componentPort = constants.InferenceServiceDefaultLoggerPort
+	} else if _, ok := pod.ObjectMeta.Annotations[constants.BatcherInternalAnnotationKey]; ok {
+		componentPort = constants.InferenceServiceDefaultBatcherPort
+	}
+
+	validatorContainer := v1.Container{
+		Name:  RequestValidationContainerName,
+		Image: ri.config.Image,
+		Args: []string{
+			RequestValidationArgumentConfig,
+			validationSpec,
+			RequestValidationArgumentPort,
+			constants.InferenceServiceDefaultValidatorPort,
+			RequestValidationArgumentComponentPort,
+			componentPort,
+		},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ri.config.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(ri.config.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ri.config.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(ri.config.MemoryRequest),
+			},
+		},
+		SecurityContext: pod.Spec.Containers[0].SecurityContext.DeepCopy(),
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, validatorContainer)
+	return nil
+}
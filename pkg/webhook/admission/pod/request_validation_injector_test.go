@@ -0,0 +1,210 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+var (
+	requestValidationConfig = &RequestValidationConfig{
+		Image:         "gcr.io/kfserving/validator:latest",
+		CpuRequest:    "100m",
+		CpuLimit:      "1",
+		MemoryRequest: "100Mi",
+		MemoryLimit:   "1Gi",
+	}
+
+	requestValidationResourceRequirement = v1.ResourceRequirements{
+		Limits: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Requests: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+)
+
+func TestRequestValidationInjector(t *testing.T) {
+	config := `{"modelMetadata":true}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddValidator": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.RequestValidationInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.RequestValidationInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:      RequestValidationContainerName,
+							Image:     "gcr.io/kfserving/validator:latest",
+							Args:      []string{"--config", config, "--port", "9085", "--component-port", "8080"},
+							Resources: requestValidationResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"InFrontOfTheBatcher": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.RequestValidationInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:           "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.RequestValidationInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:           "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:      RequestValidationContainerName,
+							Image:     "gcr.io/kfserving/validator:latest",
+							Args:      []string{"--config", config, "--port", "9085", "--component-port", "9082"},
+							Resources: requestValidationResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"InFrontOfTheLogger": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.RequestValidationInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:           "true",
+						constants.LoggerInternalAnnotationKey:            "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.RequestValidationInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:           "true",
+						constants.LoggerInternalAnnotationKey:            "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:      RequestValidationContainerName,
+							Image:     "gcr.io/kfserving/validator:latest",
+							Args:      []string{"--config", config, "--port", "9085", "--component-port", "8081"},
+							Resources: requestValidationResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.RequestValidationInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: RequestValidationContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.RequestValidationInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: RequestValidationContainerName},
+					},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &RequestValidationInjector{config: requestValidationConfig}
+		if err := injector.InjectRequestValidation(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestRequestValidationInjectorMissingConfiguration(t *testing.T) {
+	injector := &RequestValidationInjector{config: &RequestValidationConfig{}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.RequestValidationInternalAnnotationKey: "{}"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	if err := injector.InjectRequestValidation(pod); err == nil {
+		t.Errorf("Expected an error for the missing request validation configuration")
+	}
+}
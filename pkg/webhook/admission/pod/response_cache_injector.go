@@ -0,0 +1,133 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	ResponseCacheContainerName         = "response-cache"
+	ResponseCacheConfigMapKeyName      = "responseCache"
+	ResponseCacheArgumentConfig        = "--config"
+	ResponseCacheArgumentPort          = "--port"
+	ResponseCacheArgumentComponentPort = "--component-port"
+	ResponseCacheArgumentKeyPrefix     = "--key-prefix"
+)
+
+type ResponseCacheConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+	// Seconds the cache waits for the requests sent to the component on shutdown
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds,omitempty"`
+}
+
+// ResponseCacheInjector injects the cache receiving the requests of the component on the port of the component
+// container. The controller sets the port of the component container to the port of the cache, the cache sends the
+// requests it does not answer to the request validator, the logger or the batcher of the component when injected, to
+// the component otherwise.
+type ResponseCacheInjector struct {
+	config *ResponseCacheConfig
+}
+
+func getResponseCacheConfigs(configMap *v1.ConfigMap) (*ResponseCacheConfig, error) {
+	cacheConfig := &ResponseCacheConfig{}
+	cacheConfigValue, ok := configMap.Data[ResponseCacheConfigMapKeyName]
+	// The response cache is optional, the pods with a response cache annotation fail to be mutated without the
+	// configuration
+	if !ok {
+		return cacheConfig, nil
+	}
+	if err := json.Unmarshal([]byte(cacheConfigValue), &cacheConfig); err != nil {
+		return cacheConfig, fmt.Errorf("Unable to unmarshall response cache json string due to %v ", err)
+	}
+	resourceDefaults := []string{cacheConfig.MemoryRequest,
+		cacheConfig.MemoryLimit,
+		cacheConfig.CpuRequest,
+		cacheConfig.CpuLimit}
+	for _, key := range resourceDefaults {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return cacheConfig, fmt.Errorf("Failed to parse resource configuration for %q: %q",
+				ResponseCacheConfigMapKeyName, err.Error())
+		}
+	}
+	return cacheConfig, nil
+}
+
+// InjectResponseCache adds the cache to the pods annotated with a response cache configuration
+func (ri *ResponseCacheInjector) InjectResponseCache(pod *v1.Pod) error {
+	cacheSpec, ok := pod.ObjectMeta.Annotations[constants.ResponseCacheInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// Don't inject if the sidecar is already injected
+	if getContainer(pod, ResponseCacheContainerName) != nil {
+		return nil
+	}
+	if ri.config.Image == "" {
+		return fmt.Errorf("Invalid configuration: the %q configuration is required by the response cache",
+			ResponseCacheConfigMapKeyName)
+	}
+
+	// The cache receives the requests before the validator, the logger and the batcher, the cached responses are not
+	// logged
+	componentPort := constants.InferenceServiceDefaultHttpPort
+	if _, ok := pod.ObjectMeta.Annotations[constants.RequestValidationInternalAnnotationKey]; ok {
+		componentPort = constants.InferenceServiceDefaultValidatorPort
+	} else if _, ok := pod.ObjectMeta.Annotations[constants.LoggerInternalAnnotationKey]; ok {
+		componentPort = constants.InferenceServiceDefaultLoggerPort
+	} else if _, ok := pod.ObjectMeta.Annotations[constants.BatcherInternalAnnotationKey]; ok {
+		componentPort = constants.InferenceServiceDefaultBatcherPort
+	}
+
+	cacheContainer := v1.Container{
+		Name:  ResponseCacheContainerName,
+		Image: ri.config.Image,
+		Args: []string{
+			ResponseCacheArgumentConfig,
+			cacheSpec,
+			ResponseCacheArgumentPort,
+			constants.InferenceServiceDefaultCachePort,
+			ResponseCacheArgumentComponentPort,
+			componentPort,
+			ResponseCacheArgumentKeyPrefix,
+			constants.ResponseCacheKeyPrefix(pod.Namespace, pod.Labels[constants.InferenceServicePodLabelKey],
+				pod.Labels[constants.KServiceComponentLabel]),
+		},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ri.config.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(ri.config.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(ri.config.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(ri.config.MemoryRequest),
+			},
+		},
+		SecurityContext: pod.Spec.Containers[0].SecurityContext.DeepCopy(),
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, cacheContainer)
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+var (
+	responseCacheConfig = &ResponseCacheConfig{
+		Image:         "gcr.io/kfserving/responsecache:latest",
+		CpuRequest:    "100m",
+		CpuLimit:      "1",
+		MemoryRequest: "100Mi",
+		MemoryLimit:   "1Gi",
+	}
+
+	responseCacheResourceRequirement = v1.ResourceRequirements{
+		Limits: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Requests: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+)
+
+func TestResponseCacheInjector(t *testing.T) {
+	config := `{"ttlSeconds":60}`
+	labels := map[string]string{
+		constants.InferenceServicePodLabelKey: "embeddings",
+		constants.KServiceComponentLabel:      "predictor",
+	}
+	keyPrefix := "kfserving:cache:default:embeddings:predictor:"
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddCache": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Labels:      labels,
+					Annotations: map[string]string{constants.ResponseCacheInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "default",
+					Labels:      labels,
+					Annotations: map[string]string{constants.ResponseCacheInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:  ResponseCacheContainerName,
+							Image: "gcr.io/kfserving/responsecache:latest",
+							Args: []string{"--config", config, "--port", "9086", "--component-port", "8080",
+								"--key-prefix", keyPrefix},
+							Resources: responseCacheResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"InFrontOfTheLogger": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Labels:    labels,
+					Annotations: map[string]string{
+						constants.ResponseCacheInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:       "true",
+						constants.LoggerInternalAnnotationKey:        "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Labels:    labels,
+					Annotations: map[string]string{
+						constants.ResponseCacheInternalAnnotationKey: config,
+						constants.BatcherInternalAnnotationKey:       "true",
+						constants.LoggerInternalAnnotationKey:        "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:  ResponseCacheContainerName,
+							Image: "gcr.io/kfserving/responsecache:latest",
+							Args: []string{"--config", config, "--port", "9086", "--component-port", "8081",
+								"--key-prefix", keyPrefix},
+							Resources: responseCacheResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"InFrontOfTheValidator": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Labels:    labels,
+					Annotations: map[string]string{
+						constants.ResponseCacheInternalAnnotationKey:     config,
+						constants.RequestValidationInternalAnnotationKey: "{}",
+						constants.LoggerInternalAnnotationKey:            "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Labels:    labels,
+					Annotations: map[string]string{
+						constants.ResponseCacheInternalAnnotationKey:     config,
+						constants.RequestValidationInternalAnnotationKey: "{}",
+						constants.LoggerInternalAnnotationKey:            "true",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:  ResponseCacheContainerName,
+							Image: "gcr.io/kfserving/responsecache:latest",
+							Args: []string{"--config", config, "--port", "9086", "--component-port", "9085",
+								"--key-prefix", keyPrefix},
+							Resources: responseCacheResourceRequirement,
+						},
+					},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.ResponseCacheInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: ResponseCacheContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.ResponseCacheInternalAnnotationKey: config},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: ResponseCacheContainerName},
+					},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &ResponseCacheInjector{config: responseCacheConfig}
+		if err := injector.InjectResponseCache(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestResponseCacheInjectorMissingConfiguration(t *testing.T) {
+	injector := &ResponseCacheInjector{config: &ResponseCacheConfig{}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.ResponseCacheInternalAnnotationKey: "{}"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	if err := injector.InjectResponseCache(pod); err == nil {
+		t.Errorf("Expected an error for the missing response cache configuration")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/wakequeue"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ScaleFromZeroInjector struct {
+	config *wakequeue.Config
+	reader client.Reader
+}
+
+// InjectWakeQueue holds the cold started pods of the components scaled to zero with the scheduler name of the scale
+// from zero queue, the queue releases them when it wakes their component. The pods are only held on create since the
+// scheduler name of a pod cannot be updated.
+func (si *ScaleFromZeroInjector) InjectWakeQueue(pod *v1.Pod) error {
+	if si.config.MaxConcurrentWakes == 0 || !pod.CreationTimestamp.IsZero() {
+		return nil
+	}
+	name := wakequeue.OwnerReplicaSet(pod)
+	if name == "" {
+		return nil
+	}
+	rs := &appsv1.ReplicaSet{}
+	if err := si.reader.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: name}, rs); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if si.config.Hold(pod, rs, time.Now()) {
+		log.Info("Holding the cold started pod in the scale from zero queue", "namespace", pod.Namespace,
+			"replicaset", name)
+		pod.Spec.SchedulerName = constants.WakeQueueSchedulerName
+	}
+	return nil
+}
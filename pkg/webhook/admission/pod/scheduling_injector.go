@@ -0,0 +1,88 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// InjectScheduling sets the node selector, the tolerations, the affinity, the topology spread constraints and the
+// priority of the component on the pod, Knative does not allow them in the revision. The node selector is merged with
+// the one of the pod, the affinity set on the pod is kept. The priority class of the component replaces the default
+// priority class set by the Priority admission controller.
+func InjectScheduling(pod *v1.Pod) error {
+	schedulingSpec, ok := pod.ObjectMeta.Annotations[constants.SchedulingInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	scheduling := v1.PodSpec{}
+	if err := json.Unmarshal([]byte(schedulingSpec), &scheduling); err != nil {
+		return fmt.Errorf("fails to unmarshal the scheduling annotation %s: %v", schedulingSpec, err)
+	}
+
+	if len(scheduling.NodeSelector) != 0 && pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+	for key, value := range scheduling.NodeSelector {
+		pod.Spec.NodeSelector[key] = value
+	}
+	// The tolerations and the constraints are already set when the pod was mutated on create
+	for _, toleration := range scheduling.Tolerations {
+		if !hasToleration(pod.Spec.Tolerations, toleration) {
+			pod.Spec.Tolerations = append(pod.Spec.Tolerations, toleration)
+		}
+	}
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = scheduling.Affinity
+	}
+	for _, constraint := range scheduling.TopologySpreadConstraints {
+		if !hasTopologySpreadConstraint(pod.Spec.TopologySpreadConstraints, constraint) {
+			pod.Spec.TopologySpreadConstraints = append(pod.Spec.TopologySpreadConstraints, constraint)
+		}
+	}
+	if scheduling.PriorityClassName != "" {
+		pod.Spec.PriorityClassName = scheduling.PriorityClassName
+		pod.Spec.Priority = scheduling.Priority
+	}
+	if scheduling.PreemptionPolicy != nil {
+		pod.Spec.PreemptionPolicy = scheduling.PreemptionPolicy
+	}
+	return nil
+}
+
+func hasToleration(tolerations []v1.Toleration, toleration v1.Toleration) bool {
+	for i := range tolerations {
+		if equality.Semantic.DeepEqual(tolerations[i], toleration) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTopologySpreadConstraint(constraints []v1.TopologySpreadConstraint, constraint v1.TopologySpreadConstraint) bool {
+	for i := range constraints {
+		if equality.Semantic.DeepEqual(constraints[i], constraint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestSchedulingInjector(t *testing.T) {
+	toleration := v1.Toleration{
+		Key:      "nvidia.com/gpu",
+		Operator: v1.TolerationOpExists,
+		Effect:   v1.TaintEffectNoSchedule,
+	}
+	constraint := v1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{constants.InferenceServicePodLabelKey: "sklearn"},
+		},
+	}
+	affinity := &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{
+					MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key:      "node-pool",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"inference"},
+					}},
+				}},
+			},
+		},
+	}
+	defaultPriority := int32(0)
+	priority := int32(1000)
+	never := v1.PreemptNever
+	scheduling := `{"containers":null,"nodeSelector":{"disktype":"ssd"},` +
+		`"tolerations":[{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}],` +
+		`"affinity":{"nodeAffinity":{"requiredDuringSchedulingIgnoredDuringExecution":{"nodeSelectorTerms":` +
+		`[{"matchExpressions":[{"key":"node-pool","operator":"In","values":["inference"]}]}]}}},` +
+		`"topologySpreadConstraints":[{"maxSkew":1,"topologyKey":"topology.kubernetes.io/zone",` +
+		`"whenUnsatisfiable":"ScheduleAnyway","labelSelector":{"matchLabels":{"serving.kubeflow.org/inferenceservice":"sklearn"}}}]}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddScheduling": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SchedulingInternalAnnotationKey: scheduling},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					NodeSelector:              map[string]string{"disktype": "ssd"},
+					Tolerations:               []v1.Toleration{toleration},
+					Affinity:                  affinity,
+					TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint},
+				},
+			},
+		},
+		"MergeScheduling": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SchedulingInternalAnnotationKey: scheduling},
+				},
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{GkeAcceleratorNodeSelector: "nvidia-tesla-t4"},
+					Tolerations:  []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+					Affinity:     &v1.Affinity{},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{
+						GkeAcceleratorNodeSelector: "nvidia-tesla-t4",
+						"disktype":                 "ssd",
+					},
+					Tolerations:               []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}, toleration},
+					Affinity:                  &v1.Affinity{},
+					TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint},
+				},
+			},
+		},
+		"SchedulingAlreadySet": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SchedulingInternalAnnotationKey: scheduling},
+				},
+				Spec: v1.PodSpec{
+					NodeSelector:              map[string]string{"disktype": "ssd"},
+					Tolerations:               []v1.Toleration{toleration},
+					Affinity:                  affinity,
+					TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					NodeSelector:              map[string]string{"disktype": "ssd"},
+					Tolerations:               []v1.Toleration{toleration},
+					Affinity:                  affinity,
+					TopologySpreadConstraints: []v1.TopologySpreadConstraint{constraint},
+				},
+			},
+		},
+		"PriorityClass": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.SchedulingInternalAnnotationKey: `{"containers":null,"priorityClassName":"production",` +
+							`"priority":1000,"preemptionPolicy":"Never"}`,
+					},
+				},
+				Spec: v1.PodSpec{
+					PriorityClassName: "default",
+					Priority:          &defaultPriority,
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					PriorityClassName: "production",
+					Priority:          &priority,
+					PreemptionPolicy:  &never,
+				},
+			},
+		},
+		"NoScheduling": {
+			original: &v1.Pod{},
+			expected: &v1.Pod{},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectScheduling(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestSchedulingInjectorInvalidAnnotation(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.SchedulingInternalAnnotationKey: "{"},
+		},
+	}
+	if err := InjectScheduling(pod); err == nil {
+		t.Errorf("Expected an error for the invalid scheduling annotation")
+	}
+}
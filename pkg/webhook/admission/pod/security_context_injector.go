@@ -0,0 +1,181 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	SecurityContextConfigMapKeyName = "securityContext"
+	// The writable /tmp of the injected containers whose root filesystem is read-only
+	TmpVolumeName = "kfserving-tmp"
+	TmpMountPath  = "/tmp"
+	// SeccompProfileRuntimeDefault is the only seccomp profile of the config, the profile of the container runtime
+	SeccompProfileRuntimeDefault = "RuntimeDefault"
+)
+
+// injectedContainerNames are the containers injected into the InferenceService pods the security context defaults
+// apply to, the model server containers are secured by the InferenceService spec
+var injectedContainerNames = []string{
+	StorageInitializerContainerName,
+	ModelConverterContainerName,
+	WarmupInitializerContainerName,
+	LoggerContainerName,
+	BatcherContainerName,
+	AsyncContainerName,
+	RequestValidationContainerName,
+	ResponseCacheContainerName,
+	WarmupContainerName,
+}
+
+// SecurityContextConfig is the default security context of the containers injected into the InferenceService pods
+// and of the agent of the warm pools, so that the pods can run in namespaces enforcing the restricted Pod Security
+// Standard. The fields set on the containers are kept.
+type SecurityContextConfig struct {
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+	// RunAsUser is required with runAsNonRoot by the images running as root
+	RunAsUser                *int64 `json:"runAsUser,omitempty"`
+	ReadOnlyRootFilesystem   *bool  `json:"readOnlyRootFilesystem,omitempty"`
+	AllowPrivilegeEscalation *bool  `json:"allowPrivilegeEscalation,omitempty"`
+	// DropCapabilities are dropped from the containers not setting capabilities, e.g. ALL
+	DropCapabilities []v1.Capability `json:"dropCapabilities,omitempty"`
+	// SeccompProfile of the containers, RuntimeDefault, set with the seccomp annotations of the pod
+	SeccompProfile string `json:"seccompProfile,omitempty"`
+}
+
+type SecurityContextInjector struct {
+	config *SecurityContextConfig
+}
+
+// GetSecurityContextConfigs reads the security context defaults of the inferenceservice-config ConfigMap
+func GetSecurityContextConfigs(configMap *v1.ConfigMap) (*SecurityContextConfig, error) {
+	securityContextConfig := &SecurityContextConfig{}
+	if securityContextConfigValue, ok := configMap.Data[SecurityContextConfigMapKeyName]; ok {
+		err := json.Unmarshal([]byte(securityContextConfigValue), &securityContextConfig)
+		if err != nil {
+			return securityContextConfig, fmt.Errorf("Unable to unmarshall %v json string due to %v ",
+				SecurityContextConfigMapKeyName, err)
+		}
+	}
+	switch securityContextConfig.SeccompProfile {
+	case "", SeccompProfileRuntimeDefault:
+	default:
+		return securityContextConfig, fmt.Errorf("Invalid %v config, unsupported seccomp profile %q, must be %s",
+			SecurityContextConfigMapKeyName, securityContextConfig.SeccompProfile, SeccompProfileRuntimeDefault)
+	}
+	if securityContextConfig.RunAsUser != nil && *securityContextConfig.RunAsUser < 0 {
+		return securityContextConfig, fmt.Errorf("Invalid %v config, runAsUser %d cannot be negative",
+			SecurityContextConfigMapKeyName, *securityContextConfig.RunAsUser)
+	}
+	return securityContextConfig, nil
+}
+
+// InjectSecurityContext applies the security context defaults to the containers injected into the pod
+func (si *SecurityContextInjector) InjectSecurityContext(pod *v1.Pod) error {
+	if pod.ObjectMeta.Annotations == nil {
+		pod.ObjectMeta.Annotations = map[string]string{}
+	}
+	si.config.Apply(&pod.Spec, pod.ObjectMeta.Annotations, injectedContainerNames...)
+	return nil
+}
+
+// Apply sets the defaults on the security context of the containers and init containers of the pod spec with the
+// given names. A read-only root filesystem gets an emptyDir mounted on /tmp, and the seccomp profile is set with the
+// container annotations since the seccompProfile field is not supported by the API of the clusters yet.
+func (c *SecurityContextConfig) Apply(podSpec *v1.PodSpec, annotations map[string]string, names ...string) {
+	containers := []*v1.Container{}
+	for i := range podSpec.InitContainers {
+		containers = append(containers, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		containers = append(containers, &podSpec.Containers[i])
+	}
+	for _, container := range containers {
+		if !utils.Includes(names, container.Name) {
+			continue
+		}
+		if c.applyToContainer(container) {
+			podSpec.Volumes = appendTmpVolume(podSpec.Volumes)
+		}
+		key := v1.SeccompContainerAnnotationKeyPrefix + container.Name
+		if _, ok := annotations[key]; !ok && c.SeccompProfile == SeccompProfileRuntimeDefault {
+			annotations[key] = v1.SeccompProfileRuntimeDefault
+		}
+	}
+}
+
+// applyToContainer sets the unset fields of the security context of the container and returns whether the /tmp
+// volume is mounted into it
+func (c *SecurityContextConfig) applyToContainer(container *v1.Container) bool {
+	if c.RunAsNonRoot == nil && c.RunAsUser == nil && c.ReadOnlyRootFilesystem == nil &&
+		c.AllowPrivilegeEscalation == nil && len(c.DropCapabilities) == 0 {
+		return false
+	}
+	if container.SecurityContext == nil {
+		container.SecurityContext = &v1.SecurityContext{}
+	}
+	securityContext := container.SecurityContext
+	if securityContext.RunAsNonRoot == nil && c.RunAsNonRoot != nil {
+		runAsNonRoot := *c.RunAsNonRoot
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	}
+	if securityContext.RunAsUser == nil && c.RunAsUser != nil {
+		runAsUser := *c.RunAsUser
+		securityContext.RunAsUser = &runAsUser
+	}
+	if securityContext.AllowPrivilegeEscalation == nil && c.AllowPrivilegeEscalation != nil {
+		allowPrivilegeEscalation := *c.AllowPrivilegeEscalation
+		securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if securityContext.Capabilities == nil && len(c.DropCapabilities) != 0 {
+		securityContext.Capabilities = &v1.Capabilities{
+			Drop: append([]v1.Capability{}, c.DropCapabilities...),
+		}
+	}
+	if securityContext.ReadOnlyRootFilesystem != nil || c.ReadOnlyRootFilesystem == nil {
+		return false
+	}
+	readOnlyRootFilesystem := *c.ReadOnlyRootFilesystem
+	securityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	if !readOnlyRootFilesystem {
+		return false
+	}
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == TmpMountPath {
+			return false
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: TmpVolumeName, MountPath: TmpMountPath})
+	return true
+}
+
+func appendTmpVolume(volumes []v1.Volume) []v1.Volume {
+	for _, volume := range volumes {
+		if volume.Name == TmpVolumeName {
+			return volumes
+		}
+	}
+	return append(volumes, v1.Volume{
+		Name:         TmpVolumeName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+}
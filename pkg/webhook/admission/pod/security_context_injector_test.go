@@ -0,0 +1,209 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestSecurityContextInjector(t *testing.T) {
+	enabled, disabled := true, false
+	uid, userUID := int64(1000), int64(2000)
+	config := &SecurityContextConfig{
+		RunAsNonRoot:             &enabled,
+		RunAsUser:                &uid,
+		ReadOnlyRootFilesystem:   &enabled,
+		AllowPrivilegeEscalation: &disabled,
+		DropCapabilities:         []v1.Capability{"ALL"},
+		SeccompProfile:           SeccompProfileRuntimeDefault,
+	}
+	restricted := func(uid int64) *v1.SecurityContext {
+		return &v1.SecurityContext{
+			RunAsNonRoot:             &enabled,
+			RunAsUser:                &uid,
+			ReadOnlyRootFilesystem:   &enabled,
+			AllowPrivilegeEscalation: &disabled,
+			Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+		}
+	}
+	tmpMount := v1.VolumeMount{Name: TmpVolumeName, MountPath: TmpMountPath}
+	tmpVolume := v1.Volume{Name: TmpVolumeName, VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}
+	scenarios := map[string]struct {
+		original            *v1.Pod
+		expected            *v1.Pod
+		expectedAnnotations map[string]string
+	}{
+		"InjectedContainers": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: StorageInitializerContainerName}},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: LoggerContainerName},
+						{Name: constants.KnativeQueueProxyContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{
+						Name:            StorageInitializerContainerName,
+						SecurityContext: restricted(1000),
+						VolumeMounts:    []v1.VolumeMount{tmpMount},
+					}},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:            LoggerContainerName,
+							SecurityContext: restricted(1000),
+							VolumeMounts:    []v1.VolumeMount{tmpMount},
+						},
+						{Name: constants.KnativeQueueProxyContainerName},
+					},
+					Volumes: []v1.Volume{tmpVolume},
+				},
+			},
+			expectedAnnotations: map[string]string{
+				v1.SeccompContainerAnnotationKeyPrefix + StorageInitializerContainerName: v1.SeccompProfileRuntimeDefault,
+				v1.SeccompContainerAnnotationKeyPrefix + LoggerContainerName:             v1.SeccompProfileRuntimeDefault,
+			},
+		},
+		"KeepContainerSettings": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						v1.SeccompContainerAnnotationKeyPrefix + BatcherContainerName: "localhost/batcher.json",
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: BatcherContainerName,
+						SecurityContext: &v1.SecurityContext{
+							RunAsUser:              &userUID,
+							ReadOnlyRootFilesystem: &disabled,
+						},
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: BatcherContainerName,
+						SecurityContext: &v1.SecurityContext{
+							RunAsNonRoot:             &enabled,
+							RunAsUser:                &userUID,
+							ReadOnlyRootFilesystem:   &disabled,
+							AllowPrivilegeEscalation: &disabled,
+							Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+						},
+					}},
+				},
+			},
+			expectedAnnotations: map[string]string{
+				v1.SeccompContainerAnnotationKeyPrefix + BatcherContainerName: "localhost/batcher.json",
+			},
+		},
+		"KeepTmpMount": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         AsyncContainerName,
+						VolumeMounts: []v1.VolumeMount{{Name: "scratch", MountPath: TmpMountPath}},
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:            AsyncContainerName,
+						SecurityContext: restricted(1000),
+						VolumeMounts:    []v1.VolumeMount{{Name: "scratch", MountPath: TmpMountPath}},
+					}},
+				},
+			},
+			expectedAnnotations: map[string]string{
+				v1.SeccompContainerAnnotationKeyPrefix + AsyncContainerName: v1.SeccompProfileRuntimeDefault,
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &SecurityContextInjector{
+			config: config,
+		}
+		if err := injector.InjectSecurityContext(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expectedAnnotations, scenario.original.Annotations); diff != "" {
+			t.Errorf("Test %q unexpected annotations (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestSecurityContextInjectorDisabled(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: StorageInitializerContainerName}},
+		},
+	}
+	injector := &SecurityContextInjector{
+		config: &SecurityContextConfig{},
+	}
+	if err := injector.InjectSecurityContext(pod); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if pod.Spec.InitContainers[0].SecurityContext != nil || len(pod.Spec.Volumes) != 0 || len(pod.Annotations) != 0 {
+		t.Errorf("Expected no security context without defaults, got %v", pod)
+	}
+}
+
+func TestGetSecurityContextConfigs(t *testing.T) {
+	scenarios := map[string]struct {
+		value         string
+		expectedError bool
+	}{
+		"Valid": {
+			value: `{"runAsNonRoot": true, "runAsUser": 1000, "dropCapabilities": ["ALL"], "seccompProfile": "RuntimeDefault"}`,
+		},
+		"UnsupportedSeccompProfile": {
+			value:         `{"seccompProfile": "Unconfined"}`,
+			expectedError: true,
+		},
+		"NegativeRunAsUser": {
+			value:         `{"runAsUser": -1}`,
+			expectedError: true,
+		},
+	}
+	for name, scenario := range scenarios {
+		configMap := &v1.ConfigMap{
+			Data: map[string]string{
+				SecurityContextConfigMapKeyName: scenario.value,
+			},
+		}
+		if _, err := GetSecurityContextConfigs(configMap); (err != nil) != scenario.expectedError {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+	}
+}
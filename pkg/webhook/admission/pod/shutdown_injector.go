@@ -0,0 +1,113 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	DrainDelayArgument   = "--drain-delay"
+	DrainTimeoutArgument = "--drain-timeout"
+	// DefaultDrainTimeoutSeconds is the drain timeout of the sidecars which do not configure one
+	DefaultDrainTimeoutSeconds = 10
+	// DefaultTerminationGracePeriodSeconds is left to the model server to stop once the sidecars are drained
+	DefaultTerminationGracePeriodSeconds = 30
+)
+
+// ShutdownInjector orders the shutdown of the containers of the pod on scale down. All the containers receive SIGTERM
+// at once, so the sidecars delay their drain by the drain timeouts of the sidecars before them: the response cache, the
+// request validator and the async frontend wait for the requests they sent to the predictor and the batcher drains its
+// pending requests first, then the logger flushes its queued log events. The model server is stopped last by a preStop
+// hook sleeping until the sidecars are drained.
+type ShutdownInjector struct {
+	responseCacheConfig     *ResponseCacheConfig
+	requestValidationConfig *RequestValidationConfig
+	asyncConfig             *AsyncConfig
+	batcherConfig           *BatcherConfig
+	loggerConfig            *LoggerConfig
+}
+
+// InjectShutdownOrdering sets the drain arguments of the injected sidecars and the preStop hook of the model server
+func (si *ShutdownInjector) InjectShutdownOrdering(pod *v1.Pod) error {
+	stages := []struct {
+		containerName string
+		drainTimeout  int
+	}{
+		{ResponseCacheContainerName, si.responseCacheConfig.DrainTimeoutSeconds},
+		{RequestValidationContainerName, si.requestValidationConfig.DrainTimeoutSeconds},
+		{AsyncContainerName, si.asyncConfig.DrainTimeoutSeconds},
+		{BatcherContainerName, si.batcherConfig.DrainTimeoutSeconds},
+		{LoggerContainerName, si.loggerConfig.DrainTimeoutSeconds},
+	}
+	delay := 0
+	for _, stage := range stages {
+		container := getContainer(pod, stage.containerName)
+		if container == nil {
+			continue
+		}
+		timeout := stage.drainTimeout
+		if timeout <= 0 {
+			timeout = DefaultDrainTimeoutSeconds
+		}
+		// The arguments are already set when the pod was mutated on create
+		if !hasArgument(container, DrainDelayArgument) {
+			container.Args = append(container.Args, DrainDelayArgument, strconv.Itoa(delay),
+				DrainTimeoutArgument, strconv.Itoa(timeout))
+		}
+		delay += timeout
+	}
+	if delay == 0 {
+		return nil
+	}
+
+	// A lifecycle set on the model server is left to the user. The preStop hook fails on images without sleep, the
+	// model server is then stopped along with the sidecars.
+	if server := getContainer(pod, constants.InferenceServiceContainerName); server != nil && server.Lifecycle == nil {
+		server.Lifecycle = &v1.Lifecycle{
+			PreStop: &v1.Handler{
+				Exec: &v1.ExecAction{Command: []string{"sleep", strconv.Itoa(delay)}},
+			},
+		}
+	}
+	gracePeriod := int64(delay + DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds == nil || *pod.Spec.TerminationGracePeriodSeconds < gracePeriod {
+		pod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	}
+	return nil
+}
+
+func getContainer(pod *v1.Pod, name string) *v1.Container {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+func hasArgument(container *v1.Container, argument string) bool {
+	for _, arg := range container.Args {
+		if arg == argument {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestShutdownInjector(t *testing.T) {
+	gracePeriod := int64(55)
+	asyncGracePeriod := int64(65)
+	validatorGracePeriod := int64(45)
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"BatcherAndLogger": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: BatcherContainerName},
+						{Name: LoggerContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Lifecycle: &v1.Lifecycle{
+								PreStop: &v1.Handler{
+									Exec: &v1.ExecAction{Command: []string{"sleep", "25"}},
+								},
+							},
+						},
+						{
+							Name: BatcherContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "10"},
+						},
+						{
+							Name: LoggerContainerName,
+							Args: []string{DrainDelayArgument, "10", DrainTimeoutArgument, "15"},
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod,
+				},
+			},
+		},
+		"AsyncAndLogger": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: AsyncContainerName},
+						{Name: LoggerContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Lifecycle: &v1.Lifecycle{
+								PreStop: &v1.Handler{
+									Exec: &v1.ExecAction{Command: []string{"sleep", "35"}},
+								},
+							},
+						},
+						{
+							Name: AsyncContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "20"},
+						},
+						{
+							Name: LoggerContainerName,
+							Args: []string{DrainDelayArgument, "20", DrainTimeoutArgument, "15"},
+						},
+					},
+					TerminationGracePeriodSeconds: &asyncGracePeriod,
+				},
+			},
+		},
+		"ValidatorAndBatcher": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: BatcherContainerName},
+						{Name: RequestValidationContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Lifecycle: &v1.Lifecycle{
+								PreStop: &v1.Handler{
+									Exec: &v1.ExecAction{Command: []string{"sleep", "15"}},
+								},
+							},
+						},
+						{
+							Name: BatcherContainerName,
+							Args: []string{DrainDelayArgument, "5", DrainTimeoutArgument, "10"},
+						},
+						{
+							Name: RequestValidationContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "5"},
+						},
+					},
+					TerminationGracePeriodSeconds: &validatorGracePeriod,
+				},
+			},
+		},
+		"CacheAndValidator": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: RequestValidationContainerName},
+						{Name: ResponseCacheContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Lifecycle: &v1.Lifecycle{
+								PreStop: &v1.Handler{
+									Exec: &v1.ExecAction{Command: []string{"sleep", "15"}},
+								},
+							},
+						},
+						{
+							Name: RequestValidationContainerName,
+							Args: []string{DrainDelayArgument, "10", DrainTimeoutArgument, "5"},
+						},
+						{
+							Name: ResponseCacheContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "10"},
+						},
+					},
+					TerminationGracePeriodSeconds: &validatorGracePeriod,
+				},
+			},
+		},
+		"ArgumentsAlreadySet": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name: BatcherContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "10"},
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod,
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Lifecycle: &v1.Lifecycle{
+								PreStop: &v1.Handler{
+									Exec: &v1.ExecAction{Command: []string{"sleep", "10"}},
+								},
+							},
+						},
+						{
+							Name: BatcherContainerName,
+							Args: []string{DrainDelayArgument, "0", DrainTimeoutArgument, "10"},
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod,
+				},
+			},
+		},
+		"NoSidecars": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &ShutdownInjector{
+			responseCacheConfig:     &ResponseCacheConfig{},
+			requestValidationConfig: &RequestValidationConfig{DrainTimeoutSeconds: 5},
+			asyncConfig:             &AsyncConfig{DrainTimeoutSeconds: 20},
+			batcherConfig:           &BatcherConfig{},
+			loggerConfig:            &LoggerConfig{DrainTimeoutSeconds: 15},
+		}
+		if err := injector.InjectShutdownOrdering(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// InjectSidecars adds the sidecars and the init containers of the predictor to its pods. The init containers run
+// after the init containers of KFServing, e.g. once the model is downloaded by the StorageInitializer, and can mount
+// the model volume.
+func InjectSidecars(pod *v1.Pod) error {
+	sidecarsSpec, ok := pod.ObjectMeta.Annotations[constants.SidecarsInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	sidecars := &v1.PodSpec{}
+	if err := json.Unmarshal([]byte(sidecarsSpec), sidecars); err != nil {
+		return fmt.Errorf("Invalid %s annotation: %v", constants.SidecarsInternalAnnotationKey, err)
+	}
+
+	// Don't inject the containers already injected
+	for _, sidecar := range sidecars.Containers {
+		if getContainer(pod, sidecar.Name) == nil {
+			pod.Spec.Containers = append(pod.Spec.Containers, sidecar)
+		}
+	}
+	for _, initContainer := range sidecars.InitContainers {
+		if getInitContainer(pod, initContainer.Name) == nil {
+			pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+		}
+	}
+	return nil
+}
+
+func getInitContainer(pod *v1.Pod, name string) *v1.Container {
+	for i := range pod.Spec.InitContainers {
+		if pod.Spec.InitContainers[i].Name == name {
+			return &pod.Spec.InitContainers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestSidecarInjector(t *testing.T) {
+	sidecars := `{"containers":[{"name":"tokenizer","image":"tokenizer:latest","ports":[{"containerPort":8500}]}],` +
+		`"initContainers":[{"name":"vocabulary","image":"vocabulary:latest"}]}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddSidecars": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SidecarsInternalAnnotationKey: sidecars},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: StorageInitializerContainerName}},
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SidecarsInternalAnnotationKey: sidecars},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{Name: StorageInitializerContainerName},
+						{Name: "vocabulary", Image: "vocabulary:latest"},
+					},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:  "tokenizer",
+							Image: "tokenizer:latest",
+							Ports: []v1.ContainerPort{{ContainerPort: 8500}},
+						},
+					},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SidecarsInternalAnnotationKey: sidecars},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: "vocabulary"}},
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}, {Name: "tokenizer"}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.SidecarsInternalAnnotationKey: sidecars},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{Name: "vocabulary"}},
+					Containers:     []v1.Container{{Name: constants.InferenceServiceContainerName}, {Name: "tokenizer"}},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectSidecars(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestSidecarInjectorInvalidAnnotation(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.SidecarsInternalAnnotationKey: "{"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	if err := InjectSidecars(pod); err == nil {
+		t.Errorf("Expected an error for the invalid sidecars annotation")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// InjectStartupProbe sets the startup probe of the model server, Knative does not allow startup probes in the
+// revision. The liveness probe of the model server only starts once the startup probe passes, so that the model
+// server is not restarted while the model loads.
+func InjectStartupProbe(pod *v1.Pod) error {
+	probeSpec, ok := pod.ObjectMeta.Annotations[constants.StartupProbeInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	server := getContainer(pod, constants.InferenceServiceContainerName)
+	if server == nil || server.StartupProbe != nil {
+		return nil
+	}
+	probe := &v1.Probe{}
+	if err := json.Unmarshal([]byte(probeSpec), probe); err != nil {
+		return fmt.Errorf("fails to unmarshal the startup probe annotation %s: %v", probeSpec, err)
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Port.IntValue() == 0 {
+		probe.HTTPGet.Port = intstr.FromInt(modelServerPort(pod, server))
+	}
+	server.StartupProbe = probe
+	return nil
+}
+
+// modelServerPort returns the port of the model server: the port set by Knative, or the default port when the
+// requests go through the logger, the batcher or the async frontend
+func modelServerPort(pod *v1.Pod, server *v1.Container) int {
+	port, _ := strconv.Atoi(constants.InferenceServiceDefaultHttpPort)
+	if getContainer(pod, LoggerContainerName) != nil || getContainer(pod, BatcherContainerName) != nil ||
+		getContainer(pod, AsyncContainerName) != nil {
+		return port
+	}
+	if len(server.Ports) != 0 && server.Ports[0].ContainerPort != 0 {
+		return int(server.Ports[0].ContainerPort)
+	}
+	return port
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/kmp"
+)
+
+func TestStartupProbeInjector(t *testing.T) {
+	startupProbe := `{"httpGet":{"path":"/v1/models/sklearn","port":0},"timeoutSeconds":1,"periodSeconds":10,` +
+		`"successThreshold":1,"failureThreshold":60}`
+	probe := func(port int) *v1.Probe {
+		return &v1.Probe{
+			Handler: v1.Handler{
+				HTTPGet: &v1.HTTPGetAction{Path: "/v1/models/sklearn", Port: intstr.FromInt(port)},
+			},
+			TimeoutSeconds:   1,
+			PeriodSeconds:    10,
+			SuccessThreshold: 1,
+			FailureThreshold: 60,
+		}
+	}
+	annotations := map[string]string{constants.StartupProbeInternalAnnotationKey: startupProbe}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddStartupProbe": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:  constants.InferenceServiceContainerName,
+						Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 9000}},
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						Ports:        []v1.ContainerPort{{Name: "user-port", ContainerPort: 9000}},
+						StartupProbe: probe(9000),
+					}},
+				},
+			},
+		},
+		"DefaultPort": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						StartupProbe: probe(8080),
+					}},
+				},
+			},
+		},
+		"KeepStartupProbe": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						StartupProbe: probe(9000),
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						StartupProbe: probe(9000),
+					}},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectStartupProbe(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestStartupProbeInjectorInvalidAnnotation(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{constants.StartupProbeInternalAnnotationKey: "{"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	if err := InjectStartupProbe(pod); err == nil {
+		t.Errorf("Expected an error for the invalid startup probe annotation")
+	}
+}
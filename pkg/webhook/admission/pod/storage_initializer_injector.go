@@ -34,6 +34,7 @@ const (
 	PvcURIPrefix                            = "pvc://"
 	PvcSourceMountName                      = "kfserving-pvc-source"
 	PvcSourceMountPath                      = "/mnt/pvc"
+	DefaultEphemeralStorageHeadroomPercent  = 20
 )
 
 type StorageInitializerConfig struct {
@@ -42,6 +43,8 @@ type StorageInitializerConfig struct {
 	CpuLimit      string `json:"cpuLimit"`
 	MemoryRequest string `json:"memoryRequest"`
 	MemoryLimit   string `json:"memoryLimit"`
+	// Headroom added on top of the model size when sizing the ephemeral storage
+	EphemeralStorageHeadroomPercent *int64 `json:"ephemeralStorageHeadroomPercent,omitempty"`
 }
 
 type StorageInitializerInjector struct {
@@ -68,19 +71,32 @@ func getStorageInitializerConfigs(configMap *v1.ConfigMap) (*StorageInitializerC
 			return storageInitializerConfig, fmt.Errorf("Failed to parse resource configuration for %q: %q", StorageInitializerConfigMapKeyName, err.Error())
 		}
 	}
+	if err := ValidateStorageInitializerConfig(storageInitializerConfig); err != nil {
+		return storageInitializerConfig, err
+	}
 
 	return storageInitializerConfig, nil
 }
 
+// ValidateStorageInitializerConfig rejects a negative ephemeral storage headroom, which would size the ephemeral
+// storage below the model size
+func ValidateStorageInitializerConfig(config *StorageInitializerConfig) error {
+	if config.EphemeralStorageHeadroomPercent != nil && *config.EphemeralStorageHeadroomPercent < 0 {
+		return fmt.Errorf("Invalid %s config, ephemeralStorageHeadroomPercent cannot be negative.",
+			StorageInitializerConfigMapKeyName)
+	}
+	return nil
+}
+
 // InjectStorageInitializer injects an init container to provision model data
 // for the serving container in a unified way across storage tech by injecting
 // a provisioning INIT container. This is a work around because KNative does not
 // support INIT containers: https://github.com/knative/serving/issues/4307
 func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) error {
 	// Only inject if the required annotations are set
-	srcURI, ok := pod.ObjectMeta.Annotations[constants.StorageInitializerSourceUriInternalAnnotationKey]
-	if !ok {
-		return nil
+	srcURI, userContainers, err := storageInitializerTargets(pod)
+	if err != nil || srcURI == "" {
+		return err
 	}
 
 	// Dont inject if InitContianer already injected
@@ -89,19 +105,7 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 			return nil
 		}
 	}
-
-	// Find the kfserving-container (this is the model inference server)
-	var userContainer *v1.Container
-	for idx, container := range pod.Spec.Containers {
-		if strings.Compare(container.Name, constants.InferenceServiceContainerName) == 0 {
-			userContainer = &pod.Spec.Containers[idx]
-			break
-		}
-	}
-
-	if userContainer == nil {
-		return fmt.Errorf("Invalid configuration: cannot find container: %s", constants.InferenceServiceContainerName)
-	}
+	userContainer := userContainers[0]
 
 	podVolumes := []v1.Volume{}
 	storageInitializerMounts := []v1.VolumeMount{}
@@ -134,7 +138,9 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 		storageInitializerMounts = append(storageInitializerMounts, pvcSourceVolumeMount)
 
 		// Since the model path is linked from source pvc, userContainer also need to mount the pvc.
-		userContainer.VolumeMounts = append(userContainer.VolumeMounts, pvcSourceVolumeMount)
+		for _, container := range userContainers {
+			container.VolumeMounts = append(container.VolumeMounts, pvcSourceVolumeMount)
+		}
 
 		// modify the sourceURI to point to the PVC path
 		srcURI = PvcSourceMountPath + "/" + pvcPath
@@ -147,7 +153,6 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 			EmptyDir: &v1.EmptyDirVolumeSource{},
 		},
 	}
-	podVolumes = append(podVolumes, sharedVolume)
 
 	// Create a write mount into the shared volume
 	sharedVolumeWriteMount := v1.VolumeMount{
@@ -157,16 +162,11 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 	}
 	storageInitializerMounts = append(storageInitializerMounts, sharedVolumeWriteMount)
 
-	storageInitializerImage := StorageInitializerContainerImage + ":" + StorageInitializerContainerImageVersion
-	if mi.config != nil && mi.config.Image != "" {
-		storageInitializerImage = mi.config.Image
-	}
-
 	securityContext := userContainer.SecurityContext.DeepCopy()
 	// Add an init container to run provisioning logic to the PodSpec
 	initContainer := &v1.Container{
 		Name:  StorageInitializerContainerName,
-		Image: storageInitializerImage,
+		Image: mi.image(),
 		Args: []string{
 			srcURI,
 			constants.DefaultModelLocalMountPath,
@@ -192,14 +192,37 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 		MountPath: constants.DefaultModelLocalMountPath,
 		ReadOnly:  true,
 	}
-	userContainer.VolumeMounts = append(userContainer.VolumeMounts, sharedVolumeReadMount)
-	// Change the CustomSpecStorageUri env variable value to the default model path if present
-	for index, envVar := range userContainer.Env {
-		if envVar.Name == constants.CustomSpecStorageUriEnvVarKey && envVar.Value != "" {
-			userContainer.Env[index].Value = constants.DefaultModelLocalMountPath
+	for _, container := range userContainers {
+		container.VolumeMounts = append(container.VolumeMounts, sharedVolumeReadMount)
+		// Change the CustomSpecStorageUri env variable value to the default model path if present
+		for index, envVar := range container.Env {
+			if envVar.Name == constants.CustomSpecStorageUriEnvVarKey && envVar.Value != "" {
+				container.Env[index].Value = constants.DefaultModelLocalMountPath
+			}
 		}
 	}
 
+	// Let the StorageInitializer prepare the TorchServe model store of the pytorch predictor
+	if handler, ok := pod.ObjectMeta.Annotations[constants.TorchServeHandlerInternalAnnotationKey]; ok {
+		initContainer.Env = append(initContainer.Env,
+			v1.EnvVar{Name: constants.TorchServeHandlerEnvVarKey, Value: handler},
+			v1.EnvVar{Name: constants.TorchServeModelNameEnvVarKey, Value: pod.ObjectMeta.Labels[constants.InferenceServicePodLabelKey]},
+		)
+	}
+
+	// Let the StorageInitializer check the downloaded model against the digest verified by the policy engines
+	if modelDigest, ok := pod.ObjectMeta.Annotations[constants.ModelDigestInternalAnnotationKey]; ok && modelDigest != "" {
+		initContainer.Env = append(initContainer.Env, v1.EnvVar{Name: constants.ModelDigestEnvVarKey, Value: modelDigest})
+	}
+
+	// Size the ephemeral storage after the model so the pod is not evicted mid-download
+	if modelSize, ok := pod.ObjectMeta.Annotations[constants.ModelSizeAnnotationKey]; ok {
+		if err := mi.injectEphemeralStorage(modelSize, userContainer, initContainer, &sharedVolume); err != nil {
+			return err
+		}
+	}
+	podVolumes = append(podVolumes, sharedVolume)
+
 	// Add volumes to the PodSpec
 	pod.Spec.Volumes = append(pod.Spec.Volumes, podVolumes...)
 
@@ -219,6 +242,77 @@ func (mi *StorageInitializerInjector) InjectStorageInitializer(pod *v1.Pod) erro
 	return nil
 }
 
+// image returns the image of the storage initializer
+func (mi *StorageInitializerInjector) image() string {
+	if mi.config != nil && mi.config.Image != "" {
+		return mi.config.Image
+	}
+	return StorageInitializerContainerImage + ":" + StorageInitializerContainerImageVersion
+}
+
+// storageInitializerTargets returns the source URI and the containers reading the model, the kfserving-container of
+// the InferenceService pods or every container of the pods annotated with the storage URI, e.g. custom workloads.
+// The source URI is empty when no model is provisioned.
+func storageInitializerTargets(pod *v1.Pod) (string, []*v1.Container, error) {
+	if srcURI, ok := pod.ObjectMeta.Annotations[constants.StorageInitializerSourceUriInternalAnnotationKey]; ok {
+		// Find the kfserving-container (this is the model inference server)
+		for idx, container := range pod.Spec.Containers {
+			if strings.Compare(container.Name, constants.InferenceServiceContainerName) == 0 {
+				return srcURI, []*v1.Container{&pod.Spec.Containers[idx]}, nil
+			}
+		}
+		return "", nil, fmt.Errorf("Invalid configuration: cannot find container: %s", constants.InferenceServiceContainerName)
+	}
+	if srcURI, ok := pod.ObjectMeta.Annotations[constants.StorageURIAnnotationKey]; ok && srcURI != "" {
+		if len(pod.Spec.Containers) == 0 {
+			return "", nil, fmt.Errorf("Invalid configuration: no container to mount the model of annotation %s", constants.StorageURIAnnotationKey)
+		}
+		containers := []*v1.Container{}
+		for idx := range pod.Spec.Containers {
+			containers = append(containers, &pod.Spec.Containers[idx])
+		}
+		return srcURI, containers, nil
+	}
+	return "", nil, nil
+}
+
+// injectEphemeralStorage sets the ephemeral storage of the containers and the size limit of the
+// shared volume to the model size plus the configured headroom, resources set by the user are kept.
+// The injected request never exceeds a limit set by the user.
+func (mi *StorageInitializerInjector) injectEphemeralStorage(modelSize string, userContainer *v1.Container,
+	initContainer *v1.Container, sharedVolume *v1.Volume) error {
+	size, err := resource.ParseQuantity(modelSize)
+	if err != nil {
+		return fmt.Errorf("Invalid %s annotation %q: %v", constants.ModelSizeAnnotationKey, modelSize, err)
+	}
+	headroom := int64(DefaultEphemeralStorageHeadroomPercent)
+	if mi.config != nil && mi.config.EphemeralStorageHeadroomPercent != nil {
+		headroom = *mi.config.EphemeralStorageHeadroomPercent
+	}
+	storage := resource.NewQuantity(size.Value()*(100+headroom)/100, resource.BinarySI)
+
+	sharedVolume.EmptyDir.SizeLimit = storage
+	for _, container := range []*v1.Container{userContainer, initContainer} {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = v1.ResourceList{}
+		}
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = v1.ResourceList{}
+		}
+		if _, ok := container.Resources.Requests[v1.ResourceEphemeralStorage]; !ok {
+			request := *storage
+			if limit, ok := container.Resources.Limits[v1.ResourceEphemeralStorage]; ok && limit.Cmp(request) < 0 {
+				request = limit
+			}
+			container.Resources.Requests[v1.ResourceEphemeralStorage] = request
+		}
+		if _, ok := container.Resources.Limits[v1.ResourceEphemeralStorage]; !ok {
+			container.Resources.Limits[v1.ResourceEphemeralStorage] = *storage
+		}
+	}
+	return nil
+}
+
 func parsePvcURI(srcURI string) (pvcName string, pvcPath string, err error) {
 	parts := strings.Split(strings.TrimPrefix(srcURI, PvcURIPrefix), "/")
 	if len(parts) > 1 {
@@ -308,6 +308,16 @@ func TestStorageInitializerFailureCases(t *testing.T) {
 			},
 			expectedErrorPrefix: "Invalid configuration: cannot find container",
 		},
+		"AnnotatedPodWithoutContainers": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						constants.StorageURIAnnotationKey: "gs://foo",
+					},
+				},
+			},
+			expectedErrorPrefix: "Invalid configuration: no container to mount the model",
+		},
 	}
 
 	for name, scenario := range scenarios {
@@ -432,6 +442,113 @@ func TestCustomSpecStorageUriInjection(t *testing.T) {
 	}
 }
 
+func TestStorageURIAnnotationInjection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "custom",
+			Namespace: "default",
+			Annotations: map[string]string{
+				constants.StorageURIAnnotationKey: "gs://foo",
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "server",
+					Env: []v1.EnvVar{
+						{Name: constants.CustomSpecStorageUriEnvVarKey, Value: "gs://foo"},
+					},
+				},
+				{
+					Name: "sidecar",
+				},
+			},
+		},
+	}
+	injector := &StorageInitializerInjector{
+		credentialBuilder: credentials.NewCredentialBulder(c, &v1.ConfigMap{
+			Data: map[string]string{},
+		}),
+		config: storageInitializerConfig,
+	}
+	g.Expect(needMutate(pod)).To(gomega.BeTrue())
+	g.Expect(injector.InjectStorageInitializer(pod)).To(gomega.Succeed())
+
+	readMount := v1.VolumeMount{
+		Name:      StorageInitializerVolumeName,
+		MountPath: constants.DefaultModelLocalMountPath,
+		ReadOnly:  true,
+	}
+	g.Expect(pod.Spec.InitContainers).To(gomega.HaveLen(1))
+	g.Expect(pod.Spec.InitContainers[0].Name).To(gomega.Equal(StorageInitializerContainerName))
+	g.Expect(pod.Spec.InitContainers[0].Args).To(gomega.Equal([]string{"gs://foo", constants.DefaultModelLocalMountPath}))
+	g.Expect(pod.Spec.Containers[0].VolumeMounts).To(gomega.Equal([]v1.VolumeMount{readMount}))
+	g.Expect(pod.Spec.Containers[0].Env[0].Value).To(gomega.Equal(constants.DefaultModelLocalMountPath))
+	g.Expect(pod.Spec.Containers[1].VolumeMounts).To(gomega.Equal([]v1.VolumeMount{readMount}))
+	g.Expect(pod.Spec.Volumes).To(gomega.ContainElement(v1.Volume{
+		Name:         StorageInitializerVolumeName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	}))
+
+	unannotated := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "server"}}}}
+	g.Expect(needMutate(unannotated)).To(gomega.BeFalse())
+	g.Expect(injector.InjectStorageInitializer(unannotated)).To(gomega.Succeed())
+	g.Expect(unannotated.Spec.InitContainers).To(gomega.BeEmpty())
+}
+
+func TestTorchServeHandlerInjection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	injector := &StorageInitializerInjector{
+		credentialBuilder: credentials.NewCredentialBulder(c, &v1.ConfigMap{
+			Data: map[string]string{},
+		}),
+		config: storageInitializerConfig,
+	}
+
+	pod := makePod()
+	pod.ObjectMeta.Labels = map[string]string{constants.InferenceServicePodLabelKey: "mnist"}
+	pod.ObjectMeta.Annotations[constants.TorchServeHandlerInternalAnnotationKey] = "image_classifier"
+	g.Expect(injector.InjectStorageInitializer(pod)).To(gomega.Succeed())
+	g.Expect(pod.Spec.InitContainers).To(gomega.HaveLen(1))
+	g.Expect(pod.Spec.InitContainers[0].Env).To(gomega.ContainElement(
+		v1.EnvVar{Name: constants.TorchServeHandlerEnvVarKey, Value: "image_classifier"}))
+	g.Expect(pod.Spec.InitContainers[0].Env).To(gomega.ContainElement(
+		v1.EnvVar{Name: constants.TorchServeModelNameEnvVarKey, Value: "mnist"}))
+
+	// Other frameworks do not prepare a TorchServe model store
+	other := makePod()
+	g.Expect(injector.InjectStorageInitializer(other)).To(gomega.Succeed())
+	for _, env := range other.Spec.InitContainers[0].Env {
+		g.Expect(env.Name).NotTo(gomega.Equal(constants.TorchServeHandlerEnvVarKey))
+	}
+}
+
+func TestModelDigestInjection(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	injector := &StorageInitializerInjector{
+		credentialBuilder: credentials.NewCredentialBulder(c, &v1.ConfigMap{
+			Data: map[string]string{},
+		}),
+		config: storageInitializerConfig,
+	}
+
+	digest := "sha256:9c2a51a1e3e7fbb1a8c2b2a2e1f0ad1e0fd7b3b0d6e07a7d6a4e1c4f6b3c2a19"
+	pod := makePod()
+	pod.ObjectMeta.Annotations[constants.ModelDigestInternalAnnotationKey] = digest
+	g.Expect(injector.InjectStorageInitializer(pod)).To(gomega.Succeed())
+	g.Expect(pod.Spec.InitContainers).To(gomega.HaveLen(1))
+	g.Expect(pod.Spec.InitContainers[0].Env).To(gomega.ContainElement(
+		v1.EnvVar{Name: constants.ModelDigestEnvVarKey, Value: digest}))
+
+	// The models without a declared digest are not checked
+	other := makePod()
+	g.Expect(injector.InjectStorageInitializer(other)).To(gomega.Succeed())
+	for _, env := range other.Spec.InitContainers[0].Env {
+		g.Expect(env.Name).NotTo(gomega.Equal(constants.ModelDigestEnvVarKey))
+	}
+}
+
 func makePod() *v1.Pod {
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -775,3 +892,84 @@ func TestStorageInitializerConfigmap(t *testing.T) {
 		}
 	}
 }
+
+func TestInjectEphemeralStorage(t *testing.T) {
+	headroom := int64(50)
+	userStorage := resource.MustParse("1Gi")
+	scenarios := map[string]struct {
+		modelSize        string
+		config           *StorageInitializerConfig
+		userResources    v1.ResourceRequirements
+		expectedStorage  resource.Quantity
+		expectedUserSize resource.Quantity
+		expectErr        bool
+	}{
+		"DefaultHeadroom": {
+			modelSize:        "10Gi",
+			config:           storageInitializerConfig,
+			expectedStorage:  resource.MustParse("12Gi"),
+			expectedUserSize: resource.MustParse("12Gi"),
+		},
+		"ConfiguredHeadroom": {
+			modelSize: "10Gi",
+			config: &StorageInitializerConfig{
+				EphemeralStorageHeadroomPercent: &headroom,
+			},
+			expectedStorage:  resource.MustParse("15Gi"),
+			expectedUserSize: resource.MustParse("15Gi"),
+		},
+		"KeepUserResources": {
+			modelSize: "10Gi",
+			config:    storageInitializerConfig,
+			userResources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceEphemeralStorage: userStorage},
+				Limits:   v1.ResourceList{v1.ResourceEphemeralStorage: userStorage},
+			},
+			expectedStorage:  resource.MustParse("12Gi"),
+			expectedUserSize: userStorage,
+		},
+		"UserLimitBelowStorage": {
+			modelSize: "10Gi",
+			config:    storageInitializerConfig,
+			userResources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceEphemeralStorage: userStorage},
+			},
+			expectedStorage:  resource.MustParse("12Gi"),
+			expectedUserSize: userStorage,
+		},
+		"InvalidModelSize": {
+			modelSize: "large",
+			config:    storageInitializerConfig,
+			expectErr: true,
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &StorageInitializerInjector{config: scenario.config}
+		userContainer := &v1.Container{Resources: scenario.userResources}
+		initContainer := &v1.Container{}
+		volume := &v1.Volume{VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}
+		err := injector.injectEphemeralStorage(scenario.modelSize, userContainer, initContainer, volume)
+		if scenario.expectErr {
+			if err == nil {
+				t.Errorf("Test %q expected error, got none", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+			continue
+		}
+		if volume.EmptyDir.SizeLimit.Cmp(scenario.expectedStorage) != 0 {
+			t.Errorf("Test %q expected size limit %v, got %v", name, scenario.expectedStorage.String(), volume.EmptyDir.SizeLimit.String())
+		}
+		initStorage := initContainer.Resources.Limits[v1.ResourceEphemeralStorage]
+		if initStorage.Cmp(scenario.expectedStorage) != 0 {
+			t.Errorf("Test %q expected init container storage %v, got %v", name, scenario.expectedStorage.String(), initStorage.String())
+		}
+		userRequest := userContainer.Resources.Requests[v1.ResourceEphemeralStorage]
+		if userRequest.Cmp(scenario.expectedUserSize) != 0 {
+			t.Errorf("Test %q expected user container storage %v, got %v", name, scenario.expectedUserSize.String(), userRequest.String())
+		}
+	}
+}
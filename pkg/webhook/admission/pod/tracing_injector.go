@@ -0,0 +1,166 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	TracingConfigMapKeyName = "tracing"
+	// The containers injected by Istio and Knative are traced with the configuration of their own control plane
+	IstioProxyContainerName = "istio-proxy"
+)
+
+// The environment variables of the OpenTelemetry SDKs, see
+// https://github.com/open-telemetry/opentelemetry-specification/blob/master/specification/sdk-environment-variables.md
+const (
+	OtelExporterEndpointEnvVarKey = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OtelExporterProtocolEnvVarKey = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	OtelServiceNameEnvVarKey      = "OTEL_SERVICE_NAME"
+	OtelResourceAttrsEnvVarKey    = "OTEL_RESOURCE_ATTRIBUTES"
+	OtelPropagatorsEnvVarKey      = "OTEL_PROPAGATORS"
+	OtelTracesSamplerEnvVarKey    = "OTEL_TRACES_SAMPLER"
+	OtelTracesSamplerArgEnvVarKey = "OTEL_TRACES_SAMPLER_ARG"
+
+	// The W3C trace context and baggage propagated by the sidecars
+	OtelPropagators = "tracecontext,baggage"
+	// The sampling decision of the caller is kept so a prediction is traced by all the components or by none
+	OtelTracesSampler = "parentbased_traceidratio"
+)
+
+// TracingConfig configures the OTLP exporter of the containers of the InferenceService pods
+type TracingConfig struct {
+	// Endpoint of the OTLP collector, e.g. http://otel-collector.observability:4317, the tracing is not configured
+	// when empty
+	Endpoint string `json:"endpoint,omitempty"`
+	// Protocol of the exporter, grpc, http/protobuf or http/json
+	Protocol string `json:"protocol,omitempty"`
+	// SamplingRatio of the traces started by the InferenceService, between 0 and 1
+	SamplingRatio *float64 `json:"samplingRatio,omitempty"`
+	// ResourceAttributes are added to the resource of the spans, e.g. the cluster name
+	ResourceAttributes map[string]string `json:"resourceAttributes,omitempty"`
+}
+
+type TracingInjector struct {
+	config *TracingConfig
+}
+
+func getTracingConfigs(configMap *v1.ConfigMap) (*TracingConfig, error) {
+	tracingConfig := &TracingConfig{}
+	if tracingConfigValue, ok := configMap.Data[TracingConfigMapKeyName]; ok {
+		err := json.Unmarshal([]byte(tracingConfigValue), &tracingConfig)
+		if err != nil {
+			return tracingConfig, fmt.Errorf("Unable to unmarshall %v json string due to %v ", TracingConfigMapKeyName, err)
+		}
+	}
+	switch tracingConfig.Protocol {
+	case "", "grpc", "http/protobuf", "http/json":
+	default:
+		return tracingConfig, fmt.Errorf("Invalid %v config, unsupported protocol %q", TracingConfigMapKeyName,
+			tracingConfig.Protocol)
+	}
+	if ratio := tracingConfig.SamplingRatio; ratio != nil && (*ratio < 0 || *ratio > 1) {
+		return tracingConfig, fmt.Errorf("Invalid %v config, sampling ratio %v must be between 0 and 1",
+			TracingConfigMapKeyName, *ratio)
+	}
+	return tracingConfig, nil
+}
+
+// InjectTracing configures the OpenTelemetry SDK of the containers of the pod to export their spans to the collector,
+// the variables set on the containers are kept
+func (ti *TracingInjector) InjectTracing(pod *v1.Pod) error {
+	if ti.config.Endpoint == "" {
+		return nil
+	}
+	isvcName, ok := pod.ObjectMeta.Labels[constants.InferenceServicePodLabelKey]
+	if !ok {
+		return nil
+	}
+	component := pod.ObjectMeta.Labels[constants.KServiceComponentLabel]
+	serviceName := isvcName
+	if component != "" {
+		serviceName = isvcName + "-" + component
+	}
+
+	attributes := map[string]string{}
+	for key, value := range ti.config.ResourceAttributes {
+		attributes[key] = value
+	}
+	attributes["k8s.namespace.name"] = pod.Namespace
+	attributes["kfserving.inferenceservice"] = isvcName
+	if component != "" {
+		attributes["kfserving.component"] = component
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+attributes[key])
+	}
+
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if container.Name == constants.KnativeQueueProxyContainerName || container.Name == IstioProxyContainerName {
+			continue
+		}
+		containerServiceName := serviceName
+		if container.Name != constants.InferenceServiceContainerName {
+			containerServiceName = serviceName + "-" + container.Name
+		}
+		env := []v1.EnvVar{
+			{Name: OtelExporterEndpointEnvVarKey, Value: ti.config.Endpoint},
+			{Name: OtelServiceNameEnvVarKey, Value: containerServiceName},
+			{Name: OtelResourceAttrsEnvVarKey, Value: strings.Join(pairs, ",")},
+			{Name: OtelPropagatorsEnvVarKey, Value: OtelPropagators},
+		}
+		if ti.config.Protocol != "" {
+			env = append(env, v1.EnvVar{Name: OtelExporterProtocolEnvVarKey, Value: ti.config.Protocol})
+		}
+		if ti.config.SamplingRatio != nil {
+			env = append(env,
+				v1.EnvVar{Name: OtelTracesSamplerEnvVarKey, Value: OtelTracesSampler},
+				v1.EnvVar{Name: OtelTracesSamplerArgEnvVarKey,
+					Value: strconv.FormatFloat(*ti.config.SamplingRatio, 'f', -1, 64)})
+		}
+		for _, envVar := range env {
+			if !hasEnvVar(container, envVar.Name) {
+				container.Env = append(container.Env, envVar)
+			}
+		}
+	}
+	return nil
+}
+
+func hasEnvVar(container *v1.Container, name string) bool {
+	for _, envVar := range container.Env {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
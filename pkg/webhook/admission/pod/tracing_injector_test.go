@@ -0,0 +1,179 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestTracingInjector(t *testing.T) {
+	samplingRatio := 0.1
+	config := &TracingConfig{
+		Endpoint:           "http://otel-collector.observability:4317",
+		Protocol:           "grpc",
+		SamplingRatio:      &samplingRatio,
+		ResourceAttributes: map[string]string{"k8s.cluster.name": "prod"},
+	}
+	attributes := "k8s.cluster.name=prod,k8s.namespace.name=default,kfserving.component=transformer," +
+		"kfserving.inferenceservice=sklearn"
+	tracingEnv := func(serviceName string) []v1.EnvVar {
+		return []v1.EnvVar{
+			{Name: OtelExporterEndpointEnvVarKey, Value: "http://otel-collector.observability:4317"},
+			{Name: OtelServiceNameEnvVarKey, Value: serviceName},
+			{Name: OtelResourceAttrsEnvVarKey, Value: attributes},
+			{Name: OtelPropagatorsEnvVarKey, Value: OtelPropagators},
+			{Name: OtelExporterProtocolEnvVarKey, Value: "grpc"},
+			{Name: OtelTracesSamplerEnvVarKey, Value: OtelTracesSampler},
+			{Name: OtelTracesSamplerArgEnvVarKey, Value: "0.1"},
+		}
+	}
+	podMeta := metav1.ObjectMeta{
+		Namespace: "default",
+		Labels: map[string]string{
+			constants.InferenceServicePodLabelKey: "sklearn",
+			constants.KServiceComponentLabel:      "transformer",
+		},
+	}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"InjectContainersAndSidecars": {
+			original: &v1.Pod{
+				ObjectMeta: podMeta,
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: LoggerContainerName},
+						{Name: constants.KnativeQueueProxyContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName, Env: tracingEnv("sklearn-transformer")},
+						{Name: LoggerContainerName, Env: tracingEnv("sklearn-transformer-" + LoggerContainerName)},
+						{Name: constants.KnativeQueueProxyContainerName},
+					},
+				},
+			},
+		},
+		"KeepUserEnv": {
+			original: &v1.Pod{
+				ObjectMeta: podMeta,
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Env:  []v1.EnvVar{{Name: OtelServiceNameEnvVarKey, Value: "my-transformer"}},
+						},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: constants.InferenceServiceContainerName,
+							Env: append([]v1.EnvVar{{Name: OtelServiceNameEnvVarKey, Value: "my-transformer"}},
+								append(tracingEnv("")[:1], tracingEnv("")[2:]...)...),
+						},
+					},
+				},
+			},
+		},
+		"DoNotInjectPodsOfOtherWorkloads": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "my-container"}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "my-container"}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &TracingInjector{
+			config: config,
+		}
+		if err := injector.InjectTracing(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestTracingInjectorDisabled(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{constants.InferenceServicePodLabelKey: "sklearn"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+		},
+	}
+	injector := &TracingInjector{
+		config: &TracingConfig{},
+	}
+	if err := injector.InjectTracing(pod); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(pod.Spec.Containers[0].Env) != 0 {
+		t.Errorf("Expected no tracing env without endpoint, got %v", pod.Spec.Containers[0].Env)
+	}
+}
+
+func TestGetTracingConfigs(t *testing.T) {
+	scenarios := map[string]struct {
+		value         string
+		expectedError bool
+	}{
+		"Valid": {
+			value: `{"endpoint": "http://otel-collector:4318", "protocol": "http/protobuf", "samplingRatio": 1}`,
+		},
+		"UnsupportedProtocol": {
+			value:         `{"endpoint": "http://otel-collector:4318", "protocol": "thrift"}`,
+			expectedError: true,
+		},
+		"SamplingRatioOutOfRange": {
+			value:         `{"endpoint": "http://otel-collector:4317", "samplingRatio": 2}`,
+			expectedError: true,
+		},
+	}
+	for name, scenario := range scenarios {
+		configMap := &v1.ConfigMap{
+			Data: map[string]string{
+				TracingConfigMapKeyName: scenario.value,
+			},
+		}
+		if _, err := getTracingConfigs(configMap); (err != nil) != scenario.expectedError {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+// InjectVolumes adds the volumes Knative does not allow in the revision, e.g. the emptyDir and the persistent volume
+// claims, back to the pods of the component along with the volume mounts of its containers.
+func InjectVolumes(pod *v1.Pod) error {
+	volumesSpec, ok := pod.ObjectMeta.Annotations[constants.VolumesInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	volumes := &v1.PodSpec{}
+	if err := json.Unmarshal([]byte(volumesSpec), volumes); err != nil {
+		return fmt.Errorf("Invalid %s annotation: %v", constants.VolumesInternalAnnotationKey, err)
+	}
+
+	// Don't inject the volumes and the volume mounts already injected
+	for _, volume := range volumes.Volumes {
+		if !hasVolume(pod, volume.Name) {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
+		}
+	}
+	for _, mounts := range volumes.Containers {
+		container := getContainer(pod, mounts.Name)
+		if container == nil {
+			return fmt.Errorf("Invalid configuration: cannot find container: %s", mounts.Name)
+		}
+		for _, mount := range mounts.VolumeMounts {
+			if !hasVolumeMount(container, mount) {
+				container.VolumeMounts = append(container.VolumeMounts, mount)
+			}
+		}
+	}
+	return nil
+}
+
+func hasVolume(pod *v1.Pod, name string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasVolumeMount(container *v1.Container, mount v1.VolumeMount) bool {
+	for _, m := range container.VolumeMounts {
+		if m.Name == mount.Name && m.MountPath == mount.MountPath {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
+)
+
+func TestVolumeInjector(t *testing.T) {
+	volumes := `{"volumes":[{"name":"dshm","emptyDir":{"medium":"Memory"}}],` +
+		`"containers":[{"name":"kfserving-container","volumeMounts":[{"name":"dshm","mountPath":"/dev/shm"}]}]}`
+	modelMount := v1.VolumeMount{Name: StorageInitializerVolumeName, MountPath: constants.DefaultModelLocalMountPath, ReadOnly: true}
+	shmVolume := v1.Volume{Name: "dshm", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumMemory}}}
+	shmMount := v1.VolumeMount{Name: "dshm", MountPath: "/dev/shm"}
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddVolumes": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.VolumesInternalAnnotationKey: volumes},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						VolumeMounts: []v1.VolumeMount{modelMount},
+					}},
+					Volumes: []v1.Volume{{Name: StorageInitializerVolumeName}},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.VolumesInternalAnnotationKey: volumes},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						VolumeMounts: []v1.VolumeMount{modelMount, shmMount},
+					}},
+					Volumes: []v1.Volume{{Name: StorageInitializerVolumeName}, shmVolume},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.VolumesInternalAnnotationKey: volumes},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						VolumeMounts: []v1.VolumeMount{shmMount},
+					}},
+					Volumes: []v1.Volume{shmVolume},
+				},
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.VolumesInternalAnnotationKey: volumes},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:         constants.InferenceServiceContainerName,
+						VolumeMounts: []v1.VolumeMount{shmMount},
+					}},
+					Volumes: []v1.Volume{shmVolume},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		if err := InjectVolumes(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected, scenario.original); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestVolumeInjectorErrors(t *testing.T) {
+	scenarios := map[string]string{
+		"InvalidAnnotation": "{",
+		"MissingContainer":  `{"containers":[{"name":"tokenizer","volumeMounts":[{"name":"dshm","mountPath":"/dev/shm"}]}]}`,
+	}
+	for name, annotation := range scenarios {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{constants.VolumesInternalAnnotationKey: annotation},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+			},
+		}
+		if err := InjectVolumes(pod); err == nil {
+			t.Errorf("Test %q expected an error for the volumes annotation", name)
+		}
+	}
+}
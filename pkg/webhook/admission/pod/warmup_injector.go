@@ -0,0 +1,196 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/warmup"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	WarmupContainerName            = "inferenceservice-warmup"
+	WarmupConfigMapKeyName         = "warmup"
+	WarmupInitializerContainerName = "warmup-initializer"
+	WarmupVolumeName               = "kfserving-warmup-requests"
+	WarmupRequestsMountPath        = "/mnt/warmup"
+	WarmupArgumentConfig           = "--config"
+	WarmupArgumentServerURL        = "--server-url"
+	WarmupArgumentRequestsDir      = "--requests-dir"
+	WarmupReadinessPath            = "/ready"
+)
+
+type WarmupConfig struct {
+	Image         string `json:"image"`
+	CpuRequest    string `json:"cpuRequest"`
+	CpuLimit      string `json:"cpuLimit"`
+	MemoryRequest string `json:"memoryRequest"`
+	MemoryLimit   string `json:"memoryLimit"`
+}
+
+// WarmupInjector injects the warmup sidecar replaying the warmup requests against the model server. The readiness
+// probe of the sidecar only passes once the requests are replayed, so the pod only receives traffic then. The request
+// files are downloaded by an init container running the storage initializer.
+type WarmupInjector struct {
+	config             *WarmupConfig
+	storageInitializer *StorageInitializerInjector
+}
+
+func getWarmupConfigs(configMap *v1.ConfigMap) (*WarmupConfig, error) {
+	warmupConfig := &WarmupConfig{}
+	warmupConfigValue, ok := configMap.Data[WarmupConfigMapKeyName]
+	// The warmup is optional, the pods with a warmup fail to be mutated without the configuration
+	if !ok {
+		return warmupConfig, nil
+	}
+	if err := json.Unmarshal([]byte(warmupConfigValue), &warmupConfig); err != nil {
+		return warmupConfig, fmt.Errorf("Unable to unmarshall warmup json string due to %v ", err)
+	}
+	resourceDefaults := []string{warmupConfig.MemoryRequest,
+		warmupConfig.MemoryLimit,
+		warmupConfig.CpuRequest,
+		warmupConfig.CpuLimit}
+	for _, key := range resourceDefaults {
+		if _, err := resource.ParseQuantity(key); err != nil {
+			return warmupConfig, fmt.Errorf("Failed to parse resource configuration for %q: %q",
+				WarmupConfigMapKeyName, err.Error())
+		}
+	}
+	return warmupConfig, nil
+}
+
+// InjectWarmup adds the warmup sidecar to the pods annotated with a warmup configuration
+func (wi *WarmupInjector) InjectWarmup(pod *v1.Pod) error {
+	warmupSpec, ok := pod.ObjectMeta.Annotations[constants.WarmupInternalAnnotationKey]
+	if !ok {
+		return nil
+	}
+	// Don't inject if the sidecar is already injected
+	if getContainer(pod, WarmupContainerName) != nil {
+		return nil
+	}
+	server := getContainer(pod, constants.InferenceServiceContainerName)
+	if server == nil {
+		return fmt.Errorf("Invalid configuration: cannot find container: %s", constants.InferenceServiceContainerName)
+	}
+	if wi.config.Image == "" {
+		return fmt.Errorf("Invalid configuration: the %q configuration is required by the warmup", WarmupConfigMapKeyName)
+	}
+	config := warmup.Config{}
+	if err := json.Unmarshal([]byte(warmupSpec), &config); err != nil {
+		return fmt.Errorf("fails to unmarshal the warmup annotation %s: %v", warmupSpec, err)
+	}
+
+	port, _ := strconv.Atoi(constants.InferenceServiceDefaultWarmupPort)
+	warmupContainer := v1.Container{
+		Name:  WarmupContainerName,
+		Image: wi.config.Image,
+		Args: []string{
+			WarmupArgumentConfig,
+			warmupSpec,
+			WarmupArgumentServerURL,
+			fmt.Sprintf("http://localhost:%d", modelServerPort(pod, server)),
+		},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(wi.config.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(wi.config.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(wi.config.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(wi.config.MemoryRequest),
+			},
+		},
+		ReadinessProbe: &v1.Probe{
+			Handler: v1.Handler{
+				HTTPGet: &v1.HTTPGetAction{Path: WarmupReadinessPath, Port: intstr.FromInt(port)},
+			},
+			PeriodSeconds: 1,
+		},
+		SecurityContext: pod.Spec.Containers[0].SecurityContext.DeepCopy(),
+	}
+	if config.StorageURI != "" {
+		if err := wi.injectRequestsInitializer(pod, config.StorageURI); err != nil {
+			return err
+		}
+		warmupContainer.Args = append(warmupContainer.Args, WarmupArgumentRequestsDir, WarmupRequestsMountPath)
+		warmupContainer.VolumeMounts = append(warmupContainer.VolumeMounts, v1.VolumeMount{
+			Name:      WarmupVolumeName,
+			MountPath: WarmupRequestsMountPath,
+			ReadOnly:  true,
+		})
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, warmupContainer)
+	return nil
+}
+
+// injectRequestsInitializer adds the init container downloading the request files into a volume shared with the
+// warmup sidecar
+func (wi *WarmupInjector) injectRequestsInitializer(pod *v1.Pod, storageURI string) error {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == WarmupInitializerContainerName {
+			return nil
+		}
+	}
+	storageInitializerConfig := wi.storageInitializer.config
+	initContainer := &v1.Container{
+		Name:  WarmupInitializerContainerName,
+		Image: wi.storageInitializer.image(),
+		Args: []string{
+			storageURI,
+			WarmupRequestsMountPath,
+		},
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      WarmupVolumeName,
+			MountPath: WarmupRequestsMountPath,
+		}},
+		Resources: v1.ResourceRequirements{
+			Limits: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(storageInitializerConfig.CpuLimit),
+				v1.ResourceMemory: resource.MustParse(storageInitializerConfig.MemoryLimit),
+			},
+			Requests: map[v1.ResourceName]resource.Quantity{
+				v1.ResourceCPU:    resource.MustParse(storageInitializerConfig.CpuRequest),
+				v1.ResourceMemory: resource.MustParse(storageInitializerConfig.MemoryRequest),
+			},
+		},
+		SecurityContext: pod.Spec.Containers[0].SecurityContext.DeepCopy(),
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name: WarmupVolumeName,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+	if err := wi.storageInitializer.credentialBuilder.CreateSecretVolumeAndEnv(
+		pod.Namespace,
+		pod.Spec.ServiceAccountName,
+		initContainer,
+		&pod.Spec.Volumes,
+	); err != nil {
+		return err
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, *initContainer)
+	return nil
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/credentials"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"knative.dev/pkg/kmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var (
+	warmupConfig = &WarmupConfig{
+		Image:         "gcr.io/kfserving/warmup:latest",
+		CpuRequest:    "100m",
+		CpuLimit:      "1",
+		MemoryRequest: "100Mi",
+		MemoryLimit:   "1Gi",
+	}
+
+	warmupResourceRequirement = v1.ResourceRequirements{
+		Limits: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Requests: map[v1.ResourceName]resource.Quantity{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+
+	warmupReadinessProbe = &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt(9083)},
+		},
+		PeriodSeconds: 1,
+	}
+)
+
+func TestWarmupInjector(t *testing.T) {
+	requests := `{"readyPath":"/v1/models/sklearn","defaultPath":"/v1/models/sklearn:predict",` +
+		`"requests":[{"path":"/v1/models/sklearn:predict","body":{"instances":[[1,2]]}}],"iterations":1,"timeoutSeconds":300}`
+	files := `{"readyPath":"/v1/models/sklearn","defaultPath":"/v1/models/sklearn:predict",` +
+		`"storageUri":"gs://warmup/sklearn","iterations":1,"timeoutSeconds":300}`
+	scenarios := map[string]struct {
+		original *v1.Pod
+		expected *v1.Pod
+	}{
+		"AddWarmup": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: requests},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name:  constants.InferenceServiceContainerName,
+						Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 9000}},
+					}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  constants.InferenceServiceContainerName,
+							Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 9000}},
+						},
+						{
+							Name:           WarmupContainerName,
+							Image:          "gcr.io/kfserving/warmup:latest",
+							Args:           []string{"--config", requests, "--server-url", "http://localhost:9000"},
+							Resources:      warmupResourceRequirement,
+							ReadinessProbe: warmupReadinessProbe,
+						},
+					},
+				},
+			},
+		},
+		"BypassLogger": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: requests},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  constants.InferenceServiceContainerName,
+							Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 8081}},
+						},
+						{Name: LoggerContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  constants.InferenceServiceContainerName,
+							Ports: []v1.ContainerPort{{Name: "user-port", ContainerPort: 8081}},
+						},
+						{Name: LoggerContainerName},
+						{
+							Name:           WarmupContainerName,
+							Image:          "gcr.io/kfserving/warmup:latest",
+							Args:           []string{"--config", requests, "--server-url", "http://localhost:8080"},
+							Resources:      warmupResourceRequirement,
+							ReadinessProbe: warmupReadinessProbe,
+						},
+					},
+				},
+			},
+		},
+		"RequestFiles": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: files},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{{
+						Name:                     WarmupInitializerContainerName,
+						Image:                    StorageInitializerContainerImage + ":" + StorageInitializerContainerImageVersion,
+						Args:                     []string{"gs://warmup/sklearn", WarmupRequestsMountPath},
+						TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+						VolumeMounts:             []v1.VolumeMount{{Name: WarmupVolumeName, MountPath: WarmupRequestsMountPath}},
+						Resources:                resourceRequirement,
+					}},
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{
+							Name:  WarmupContainerName,
+							Image: "gcr.io/kfserving/warmup:latest",
+							Args: []string{"--config", files, "--server-url", "http://localhost:8080",
+								"--requests-dir", WarmupRequestsMountPath},
+							Resources:      warmupResourceRequirement,
+							ReadinessProbe: warmupReadinessProbe,
+							VolumeMounts: []v1.VolumeMount{
+								{Name: WarmupVolumeName, MountPath: WarmupRequestsMountPath, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []v1.Volume{{
+						Name:         WarmupVolumeName,
+						VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+					}},
+				},
+			},
+		},
+		"AlreadyInjected": {
+			original: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: requests},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: WarmupContainerName},
+					},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Name: constants.InferenceServiceContainerName},
+						{Name: WarmupContainerName},
+					},
+				},
+			},
+		},
+		"NoAnnotation": {
+			original: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+			expected: &v1.Pod{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &WarmupInjector{
+			config: warmupConfig,
+			storageInitializer: &StorageInitializerInjector{
+				credentialBuilder: credentials.NewCredentialBulder(fake.NewFakeClientWithScheme(scheme.Scheme),
+					&v1.ConfigMap{Data: map[string]string{}}),
+				config: storageInitializerConfig,
+			},
+		}
+		if err := injector.InjectWarmup(scenario.original); err != nil {
+			t.Errorf("Test %q unexpected error: %v", name, err)
+		}
+		if diff, _ := kmp.SafeDiff(scenario.expected.Spec, scenario.original.Spec); diff != "" {
+			t.Errorf("Test %q unexpected result (-want +got): %v", name, diff)
+		}
+	}
+}
+
+func TestWarmupInjectorFailureCases(t *testing.T) {
+	scenarios := map[string]struct {
+		config *WarmupConfig
+		pod    *v1.Pod
+	}{
+		"InvalidAnnotation": {
+			config: warmupConfig,
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: "{"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+		"MissingConfiguration": {
+			config: &WarmupConfig{},
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: "{}"},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: constants.InferenceServiceContainerName}},
+				},
+			},
+		},
+		"MissingModelServer": {
+			config: warmupConfig,
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{constants.WarmupInternalAnnotationKey: "{}"},
+				},
+			},
+		},
+	}
+
+	for name, scenario := range scenarios {
+		injector := &WarmupInjector{config: scenario.config, storageInitializer: &StorageInitializerInjector{}}
+		if err := injector.InjectWarmup(scenario.pod); err == nil {
+			t.Errorf("Test %q expected an error", name)
+		}
+	}
+}
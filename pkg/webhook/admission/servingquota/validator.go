@@ -0,0 +1,75 @@
+/*
+Copyright 2020 kubeflow.org.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servingquota
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/kubeflow/kfserving/pkg/apis/serving/v1beta1"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	"github.com/kubeflow/kfserving/pkg/controller/v1beta1/servingquota"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-servingquotas,mutating=false,failurePolicy=fail,groups=serving.kubeflow.org,resources=inferenceservices,verbs=create;update,versions=v1beta1,name=inferenceservice.kfserving-webhook-server.servingquota-validator
+var log = logf.Log.WithName(constants.ServingQuotaValidatorWebhookName)
+
+// Validator is a webhook that validates the InferenceServices against the ServingQuotas of their namespace, the Reader
+// reads the quotas and the inference services from the API server rather than from the cache
+type Validator struct {
+	Reader  client.Reader
+	Decoder *admission.Decoder
+}
+
+// Handle decodes the incoming InferenceService and rejects it when it exceeds a ServingQuota.
+func (validator *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	isvc := &v1beta1.InferenceService{}
+	if err := validator.Decoder.Decode(req, isvc); err != nil {
+		log.Error(err, "Failed to decode inference service", "name", req.AdmissionRequest.Name)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// The namespace of the inference service may be empty in the admission request object
+	isvc.Namespace = req.AdmissionRequest.Namespace
+	var old *v1beta1.InferenceService
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old = &v1beta1.InferenceService{}
+		if err := validator.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, old); err != nil {
+			log.Error(err, "Failed to decode old inference service", "name", req.AdmissionRequest.Name)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	reason, err := servingquota.Validate(validator.Reader, isvc, old)
+	if err != nil {
+		log.Error(err, "Failed to validate inference service", "name", isvc.Name)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if reason != "" {
+		log.Info("Rejected inference service", "namespace", isvc.Namespace, "name", isvc.Name, "reason", reason)
+		return admission.Denied(reason)
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// InjectDecoder injects the decoder.
+func (validator *Validator) InjectDecoder(d *admission.Decoder) error {
+	validator.Decoder = d
+	return nil
+}
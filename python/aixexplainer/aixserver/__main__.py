@@ -24,6 +24,7 @@ DEFAULT_SEGMENTATION_ALGORITHM = "quickshift"
 DEFAULT_TOP_LABELS = "10"
 DEFAULT_MIN_WEIGHT = "0.01"
 DEFAULT_POSITIVE_ONLY = "true"
+DEFAULT_NUM_PROTOTYPES = "5"
 # The required parameter is predictor_host
 
 parser = argparse.ArgumentParser(parents=[kfserving.kfserver.parser])
@@ -40,7 +41,12 @@ parser.add_argument('--min_weight', default=DEFAULT_MIN_WEIGHT,
 parser.add_argument('--positive_only', default=DEFAULT_POSITIVE_ONLY,
                     help='Whether or not to show only the explanations that positively indicate a classification.')
 parser.add_argument('--explainer_type', default=DEFAULT_EXPLAINER_TYPE,
-                    help='What type of model explainer to use.')
+                    help='What type of model explainer to use: LimeImages, LimeTabular, LimeText or Protodash.')
+parser.add_argument('--num_prototypes', default=DEFAULT_NUM_PROTOTYPES,
+                    help='The number of prototypes selected by the Protodash explainer, '
+                         'overridden by the num_prototypes of the request.')
+parser.add_argument('--storage_uri', default=None,
+                    help='The location of the training data loaded by the LimeTabular explainer.')
 
 parser.add_argument('--predictor_host', help='The host for the predictor.', required=True)
 args, _ = parser.parse_known_args()
@@ -49,6 +55,7 @@ if __name__ == "__main__":
     model = AIXModel(name=args.model_name, predictor_host=args.predictor_host,
                      segm_alg=args.segmentation_algorithm, num_samples=args.num_samples,
                      top_labels=args.top_labels, min_weight=args.min_weight,
-                     positive_only=args.positive_only, explainer_type=args.explainer_type)
+                     positive_only=args.positive_only, explainer_type=args.explainer_type,
+                     num_prototypes=args.num_prototypes, storage_uri=args.storage_uri)
     model.load()
     kfserving.KFServer().start([model], nest_asyncio=True)
@@ -15,15 +15,21 @@ from typing import Dict
 
 import asyncio
 import logging
+import os
 import kfserving
 import numpy as np
-from aix360.algorithms.lime import LimeImageExplainer
+from aix360.algorithms.lime import LimeImageExplainer, LimeTabularExplainer, LimeTextExplainer
+from aix360.algorithms.protodash import ProtodashExplainer
 from lime.wrappers.scikit_image import SegmentationAlgorithm
 
+SUPPORTED_EXPLAINER_TYPES = ["limeimages", "limetabular", "limetext", "protodash"]
+TRAINING_DATA_FILENAME = "training_data.npy"
+
 
 class AIXModel(kfserving.KFModel):  # pylint:disable=c-extension-no-member
     def __init__(self, name: str, predictor_host: str, segm_alg: str, num_samples: str,
-                 top_labels: str, min_weight: str, positive_only: str, explainer_type: str):
+                 top_labels: str, min_weight: str, positive_only: str, explainer_type: str,
+                 num_prototypes: str = "5", storage_uri: str = None):
         super().__init__(name)
         self.name = name
         self.top_labels = int(top_labels)
@@ -32,12 +38,25 @@ class AIXModel(kfserving.KFModel):  # pylint:disable=c-extension-no-member
         self.predictor_host = predictor_host
         self.min_weight = float(min_weight)
         self.positive_only = (positive_only.lower() == "true") | (positive_only.lower() == "t")
-        if str.lower(explainer_type) != "limeimages":
+        self.num_prototypes = int(num_prototypes)
+        if str.lower(explainer_type) not in SUPPORTED_EXPLAINER_TYPES:
             raise Exception("Invalid explainer type: %s" % explainer_type)
         self.explainer_type = explainer_type
+        self.storage_uri = storage_uri
+        self.training_data = None
         self.ready = False
 
     def load(self) -> bool:
+        # LIME tabular samples the features from the statistics of the training data of the model, the training data
+        # is loaded from the storage uri of the explainer
+        if str.lower(self.explainer_type) == "limetabular":
+            if self.storage_uri is None:
+                raise Exception("The LimeTabular explainer requires the storage uri of the training data")
+            training_data = os.path.join(kfserving.Storage.download(self.storage_uri), TRAINING_DATA_FILENAME)
+            if not os.path.exists(training_data):
+                raise Exception("The training data %s of the LimeTabular explainer is not found" % training_data)
+            logging.info("Loading the training data %s", training_data)
+            self.training_data = np.load(training_data)
         self.ready = True
         return self.ready
 
@@ -48,11 +67,18 @@ class AIXModel(kfserving.KFModel):  # pylint:disable=c-extension-no-member
         resp = loop.run_until_complete(self.predict(scoring_data))
         return np.array(resp["predictions"])
 
+    def _predict_text(self, input_texts):
+        scoring_data = {'instances': list(input_texts)}
+
+        loop = asyncio.get_running_loop()
+        resp = loop.run_until_complete(self.predict(scoring_data))
+        return np.array(resp["predictions"])
+
     def explain(self, request: Dict) -> Dict:
         instances = request["instances"]
         try:
             inputs = np.array(instances[0])
-            logging.info("Calling explain on image of shape %s", (inputs.shape,))
+            logging.info("Calling %s explain on inputs of shape %s", self.explainer_type, (inputs.shape,))
         except Exception as err:
             raise Exception(
                 "Failed to initialize NumPy array from inputs: %s, %s" % (err, instances))
@@ -83,6 +109,36 @@ class AIXModel(kfserving.KFModel):  # pylint:disable=c-extension-no-member
                     "masks": masks,
                     "top_labels": np.array(explanation.top_labels).astype(np.int32).tolist()
                 }}
+            elif str.lower(self.explainer_type) == "limetabular":
+                explainer = LimeTabularExplainer(self.training_data, discretize_continuous=False)
+                explanation = explainer.explain_instance(inputs,
+                                                         self._predict,
+                                                         top_labels=self.top_labels,
+                                                         num_samples=self.num_samples)
+                return {"explanations": {
+                    str(label): explanation.as_list(label=label) for label in explanation.top_labels
+                }}
+            elif str.lower(self.explainer_type) == "limetext":
+                explainer = LimeTextExplainer(verbose=False)
+                explanation = explainer.explain_instance(str(instances[0]),
+                                                         self._predict_text,
+                                                         top_labels=self.top_labels,
+                                                         num_samples=self.num_samples)
+                return {"explanations": {
+                    str(label): explanation.as_list(label=label) for label in explanation.top_labels
+                }}
+            elif str.lower(self.explainer_type) == "protodash":
+                # Summarize the request instances with the prototypes that best represent them
+                dataset = np.array(instances, dtype=np.float64)
+                dataset = dataset.reshape(dataset.shape[0], -1)
+                num_prototypes = int(request.get("num_prototypes", self.num_prototypes))
+                explainer = ProtodashExplainer()
+                weights, indices, _ = explainer.explain(dataset, dataset,
+                                                        m=min(num_prototypes, dataset.shape[0]))
+                return {"explanations": {
+                    "prototypes": np.array(indices).astype(np.int32).tolist(),
+                    "weights": np.around(weights / np.sum(weights), 4).tolist()
+                }}
 
         except Exception as err:
             raise Exception("Failed to explain %s" % err)
@@ -0,0 +1,71 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+import numpy as np
+import pytest
+from aixserver.model import AIXModel, TRAINING_DATA_FILENAME
+
+
+def make_model(explainer_type, num_prototypes="5", storage_uri=None):
+    return AIXModel("aix", "localhost:8080", "quickshift", "100", "1", "0", "true", explainer_type,
+                    num_prototypes, storage_uri)
+
+
+def test_limetabular(tmp_path, monkeypatch):
+    np.random.seed(0)
+    np.save(os.path.join(str(tmp_path), TRAINING_DATA_FILENAME), np.random.normal(size=(50, 3)))
+    model = make_model("LimeTabular", storage_uri=str(tmp_path))
+    model.load()
+    # the first feature decides the class
+    monkeypatch.setattr(model, "_predict",
+                        lambda inputs: np.stack([inputs[:, 0] < 0, inputs[:, 0] >= 0], axis=1).astype(np.float64))
+    explanations = model.explain({"instances": [[1.0, 0.0, 0.0]]})["explanations"]
+    assert list(explanations.keys()) == ["1"]
+    assert len(explanations["1"]) == 3
+
+
+def test_limetabular_without_training_data(tmp_path):
+    with pytest.raises(Exception, match="training data"):
+        make_model("LimeTabular", storage_uri=str(tmp_path)).load()
+    with pytest.raises(Exception, match="storage uri"):
+        make_model("LimeTabular").load()
+
+
+def test_limetext(monkeypatch):
+    model = make_model("LimeText")
+    model.load()
+    monkeypatch.setattr(model, "_predict_text", lambda texts: np.array(
+        [[0.0, 1.0] if "good" in text.split() else [1.0, 0.0] for text in texts]))
+    explanations = model.explain({"instances": ["a good movie"]})["explanations"]
+    assert list(explanations.keys()) == ["1"]
+    assert explanations["1"][0][0] == "good"
+
+
+def test_protodash():
+    model = make_model("Protodash", num_prototypes="2")
+    model.load()
+    instances = [[0.0, 0.0], [0.1, 0.0], [5.0, 5.0], [5.1, 5.0]]
+    explanations = model.explain({"instances": instances})["explanations"]
+    assert len(explanations["prototypes"]) == 2
+    assert sum(explanations["weights"]) == pytest.approx(1.0, abs=1e-3)
+
+
+def test_protodash_more_prototypes_than_instances():
+    model = make_model("Protodash")
+    model.load()
+    instances = [[0.0, 0.0], [5.0, 5.0], [9.0, 1.0]]
+    explanations = model.explain({"instances": instances, "num_prototypes": 10})["explanations"]
+    assert sorted(explanations["prototypes"]) == [0, 1, 2]
+    assert sum(explanations["weights"]) == pytest.approx(1.0, abs=1e-3)
@@ -25,7 +25,7 @@ setup(
     author_email='Andrew.Butler@ibm.com',
     license='https://github.com/kubeflow/kfserving/LICENSE',
     url='https://github.com/kubeflow/kfserving/python/aixserver',
-    description='Model Server implementation for AI eXplainability with LIME. \
+    description='Model Server implementation for AI eXplainability with LIME and Protodash. \
                  Not intended for use outside KFServing Frameworks Images',
     long_description=open('README.md').read(),
     python_requires='>3.4',
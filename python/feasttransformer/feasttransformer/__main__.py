@@ -0,0 +1,38 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import argparse
+import kfserving
+
+from feasttransformer import FeastTransformer
+
+DEFAULT_MODEL_NAME = "model"
+
+parser = argparse.ArgumentParser(parents=[kfserving.kfserver.parser])  # pylint:disable=c-extension-no-member
+parser.add_argument('--model_name', default=DEFAULT_MODEL_NAME,
+                    help='The name that the model is served under.')
+parser.add_argument('--predictor_host', required=True,
+                    help='The host of the predictor the enriched requests are sent to.')
+parser.add_argument('--feast_serving_url', required=True,
+                    help='The URL of the Feast feature server.')
+parser.add_argument('--entity_ids', nargs='+', required=True,
+                    help='The names of the entities keying the features, read from the instances.')
+parser.add_argument('--feature_refs', nargs='+', required=True,
+                    help='The features added to the instances, as <feature view>:<feature>.')
+args, _ = parser.parse_known_args()
+
+if __name__ == "__main__":
+    transformer = FeastTransformer(args.model_name, args.predictor_host, args.feast_serving_url,
+                                   args.entity_ids, args.feature_refs)
+    kfserving.KFServer().start([transformer])  # pylint:disable=c-extension-no-member
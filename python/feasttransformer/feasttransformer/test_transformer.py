@@ -0,0 +1,83 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from unittest import mock
+
+import pytest
+import requests
+import tornado.web
+
+from feasttransformer import FeastTransformer
+
+ONLINE_FEATURES = {
+    "metadata": {"feature_names": ["driver_id", "conv_rate", "acc_rate"]},
+    "results": [
+        {"values": [1001, 1002], "statuses": ["PRESENT", "PRESENT"]},
+        {"values": [0.5, 0.25], "statuses": ["PRESENT", "PRESENT"]},
+        {"values": [0.9, 0.75], "statuses": ["PRESENT", "PRESENT"]},
+    ],
+}
+
+
+def new_transformer():
+    return FeastTransformer("driver", "driver-predictor-default", "http://feast-feature-server:6566/",
+                            ["driver_id"], ["driver_hourly_stats:acc_rate", "driver_hourly_stats:conv_rate"])
+
+
+def response(status_code, body):
+    resp = mock.Mock()
+    resp.status_code = status_code
+    resp.json.return_value = body
+    resp.text = str(body)
+    return resp
+
+
+def test_preprocess():
+    transformer = new_transformer()
+    with mock.patch.object(transformer.session, "post", return_value=response(200, ONLINE_FEATURES)) as post:
+        request = transformer.preprocess({"instances": [{"driver_id": 1001}, {"driver_id": 1002}]})
+    post.assert_called_once_with(
+        "http://feast-feature-server:6566/get-online-features",
+        json={"features": ["driver_hourly_stats:acc_rate", "driver_hourly_stats:conv_rate"],
+              "entities": {"driver_id": [1001, 1002]}},
+        timeout=10)
+    assert request == {"instances": [[0.9, 0.5], [0.75, 0.25]]}
+
+
+def test_preprocess_missing_entity():
+    transformer = new_transformer()
+    with pytest.raises(tornado.web.HTTPError) as e:
+        transformer.preprocess({"instances": [{"customer_id": 1}]})
+    assert e.value.status_code == 400
+
+
+def test_preprocess_feature_server_error():
+    transformer = new_transformer()
+    with mock.patch.object(transformer.session, "post", return_value=response(500, {"detail": "error"})):
+        with pytest.raises(tornado.web.HTTPError) as e:
+            transformer.preprocess({"instances": [{"driver_id": 1001}]})
+    assert e.value.status_code == 502
+    with mock.patch.object(transformer.session, "post", side_effect=requests.ConnectionError("refused")):
+        with pytest.raises(tornado.web.HTTPError) as e:
+            transformer.preprocess({"instances": [{"driver_id": 1001}]})
+    assert e.value.status_code == 502
+
+
+def test_preprocess_missing_feature():
+    transformer = FeastTransformer("driver", "driver-predictor-default", "http://feast-feature-server:6566",
+                                   ["driver_id"], ["driver_hourly_stats:avg_daily_trips"])
+    with mock.patch.object(transformer.session, "post", return_value=response(200, ONLINE_FEATURES)):
+        with pytest.raises(tornado.web.HTTPError) as e:
+            transformer.preprocess({"instances": [{"driver_id": 1001}]})
+    assert e.value.status_code == 502
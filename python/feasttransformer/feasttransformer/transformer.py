@@ -0,0 +1,83 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from http import HTTPStatus
+from typing import Dict, List
+
+import kfserving
+import requests
+import tornado.web
+
+ONLINE_FEATURES_PATH = "/get-online-features"
+
+
+class FeastTransformer(kfserving.KFModel):
+    """Enriches the instances of the requests with the online features of their entities before they are sent to the
+    predictor. Each instance holds the values of the entities, the instances sent to the predictor hold the values of
+    the features in the order of the feature references."""
+
+    def __init__(self, name: str, predictor_host: str, feast_serving_url: str, entity_ids: List[str],
+                 feature_refs: List[str], timeout: float = 10):
+        super().__init__(name)
+        self.predictor_host = predictor_host
+        self.feast_serving_url = feast_serving_url.rstrip("/")
+        self.entity_ids = entity_ids
+        self.feature_refs = feature_refs
+        self.feature_timeout = timeout
+        self.session = requests.Session()
+
+    def preprocess(self, inputs: Dict) -> Dict:
+        instances = inputs.get("instances")
+        if not isinstance(instances, list):
+            raise tornado.web.HTTPError(
+                status_code=HTTPStatus.BAD_REQUEST,
+                reason="Expected \"instances\" to be a list")
+        entities = {entity_id: [] for entity_id in self.entity_ids}
+        for instance in instances:
+            for entity_id in self.entity_ids:
+                if not isinstance(instance, dict) or entity_id not in instance:
+                    raise tornado.web.HTTPError(
+                        status_code=HTTPStatus.BAD_REQUEST,
+                        reason="Missing entity \"%s\" in instance %s" % (entity_id, instance))
+                entities[entity_id].append(instance[entity_id])
+        features = self.get_online_features(entities)
+        return {"instances": [[feature[i] for feature in features] for i in range(len(instances))]}
+
+    def get_online_features(self, entities: Dict) -> List[List]:
+        """Returns the values of each feature reference for the entities, read from the feature server"""
+        try:
+            response = self.session.post(
+                self.feast_serving_url + ONLINE_FEATURES_PATH,
+                json={"features": self.feature_refs, "entities": entities},
+                timeout=self.feature_timeout)
+        except requests.RequestException as e:
+            raise tornado.web.HTTPError(
+                status_code=HTTPStatus.BAD_GATEWAY,
+                reason="Failed to read the online features: %s" % e)
+        if response.status_code != HTTPStatus.OK:
+            raise tornado.web.HTTPError(
+                status_code=HTTPStatus.BAD_GATEWAY,
+                reason="Failed to read the online features: %s %s" % (response.status_code, response.text))
+        body = response.json()
+        # The features are named without their feature view unless the feature server uses full feature names
+        names = body["metadata"]["feature_names"]
+        values = []
+        for ref in self.feature_refs:
+            name = ref if ref in names else ref.split(":")[-1]
+            if name not in names:
+                raise tornado.web.HTTPError(
+                    status_code=HTTPStatus.BAD_GATEWAY,
+                    reason="Feature \"%s\" is missing from the online features" % ref)
+            values.append(body["results"][names.index(name)]["values"])
+        return values
@@ -13,25 +13,37 @@
 # limitations under the License.
 
 import inspect
+import os
+import tornado.httpclient
+import tornado.ioloop
 import tornado.web
+import tornado.websocket
 import json
 from http import HTTPStatus
 from kfserving.kfmodel_repository import KFModelRepository
+from kfserving.metrics import MODEL_LOAD_SECONDS, REQUEST_SECONDS, LOAD_POLICY_LAZY
 
 
 class HTTPHandler(tornado.web.RequestHandler):
     def initialize(self, models: KFModelRepository):
         self.models = models  # pylint:disable=attribute-defined-outside-init
 
-    def get_model(self, name: str):
+    async def get_model(self, name: str):
         model = self.models.get_model(name)
+        if model is None and os.path.isdir(os.path.join(self.models.models_dir, name)):
+            # The models downloaded by the agent without being loaded are lazily loaded by their first request
+            with MODEL_LOAD_SECONDS.labels(model=name, load_policy=LOAD_POLICY_LAZY).time():
+                (await self.models.load(name)) if inspect.iscoroutinefunction(self.models.load) \
+                    else self.models.load(name)
+            model = self.models.get_model(name)
         if model is None:
             raise tornado.web.HTTPError(
                 status_code=HTTPStatus.NOT_FOUND,
                 reason="Model with name %s does not exist." % name
             )
         if not model.ready:
-            model.load()
+            with MODEL_LOAD_SECONDS.labels(model=name, load_policy=LOAD_POLICY_LAZY).time():
+                model.load()
         return model
 
     def validate(self, request):
@@ -43,36 +55,84 @@ class HTTPHandler(tornado.web.RequestHandler):
             )
         return request
 
+    async def call(self, method, request):
+        # The request headers are only passed to the models accepting them, e.g. to forward the trace context
+        kwargs = {"headers": self.request.headers} if "headers" in inspect.signature(method).parameters else {}
+        return (await method(request, **kwargs)) if inspect.iscoroutinefunction(method) else method(request, **kwargs)
 
-class PredictHandler(HTTPHandler):
-    async def post(self, name: str):
-        model = self.get_model(name)
+
+class PredictHandler(tornado.websocket.WebSocketHandler, HTTPHandler):
+    predictor_connection = None
+
+    # The websocket connections of the clients are relayed to the predictor, the messages are not transformed
+    async def get(self, name: str):  # pylint:disable=arguments-differ
+        model = await self.get_model(name)
+        if not getattr(model, "websocket", False):
+            raise tornado.web.HTTPError(status_code=HTTPStatus.METHOD_NOT_ALLOWED)
         try:
-            body = json.loads(self.request.body)
-        except json.decoder.JSONDecodeError as e:
+            self.predictor_connection = await model.predict_websocket()
+        except (OSError, tornado.httpclient.HTTPClientError) as e:
             raise tornado.web.HTTPError(
-                status_code=HTTPStatus.BAD_REQUEST,
-                reason="Unrecognized request format: %s" % e
+                status_code=HTTPStatus.BAD_GATEWAY,
+                reason="Failed to connect to the predictor: %s" % e
             )
-        request = model.preprocess(body)
-        request = self.validate(request)
-        response = (await model.predict(request)) if inspect.iscoroutinefunction(model.predict) else model.predict(request)
-        response = model.postprocess(response)
+        await super().get(name)
+
+    def open(self, *args, **kwargs):
+        tornado.ioloop.IOLoop.current().spawn_callback(self.relay_predictor_messages)
+
+    async def relay_predictor_messages(self):
+        while True:
+            message = await self.predictor_connection.read_message()
+            if message is None:
+                self.close()
+                return
+            try:
+                await self.write_message(message, binary=isinstance(message, bytes))
+            except tornado.websocket.WebSocketClosedError:
+                return
+
+    async def on_message(self, message):
+        await self.predictor_connection.write_message(message, binary=isinstance(message, bytes))
+
+    def on_close(self):
+        if self.predictor_connection is not None:
+            self.predictor_connection.close()
+
+    async def post(self, name: str):
+        model = await self.get_model(name)
+        with REQUEST_SECONDS.labels(model=name, endpoint="predict").time():
+            try:
+                body = json.loads(self.request.body)
+            except json.decoder.JSONDecodeError as e:
+                raise tornado.web.HTTPError(
+                    status_code=HTTPStatus.BAD_REQUEST,
+                    reason="Unrecognized request format: %s" % e
+                )
+            request = model.preprocess(body)
+            request = self.validate(request)
+            # The streamed responses of the predictor are written to the client without being postprocessed
+            if getattr(model, "streaming", False):
+                await model.predict_stream(request, self)
+                return
+            response = await self.call(model.predict, request)
+            response = model.postprocess(response)
         self.write(response)
 
 
 class ExplainHandler(HTTPHandler):
     async def post(self, name: str):
-        model = self.get_model(name)
-        try:
-            body = json.loads(self.request.body)
-        except json.decoder.JSONDecodeError as e:
-            raise tornado.web.HTTPError(
-                status_code=HTTPStatus.BAD_REQUEST,
-                reason="Unrecognized request format: %s" % e
-            )
-        request = model.preprocess(body)
-        request = self.validate(request)
-        response = (await model.explain(request)) if inspect.iscoroutinefunction(model.explain) else model.explain(request)
-        response = model.postprocess(response)
+        model = await self.get_model(name)
+        with REQUEST_SECONDS.labels(model=name, endpoint="explain").time():
+            try:
+                body = json.loads(self.request.body)
+            except json.decoder.JSONDecodeError as e:
+                raise tornado.web.HTTPError(
+                    status_code=HTTPStatus.BAD_REQUEST,
+                    reason="Unrecognized request format: %s" % e
+                )
+            request = model.preprocess(body)
+            request = self.validate(request)
+            response = await self.call(model.explain, request)
+            response = model.postprocess(response)
         self.write(response)
@@ -13,14 +13,29 @@
 # limitations under the License.
 
 from typing import Dict
+import os
 import sys
 
 import json
+import tornado.httputil
 import tornado.web
+import tornado.websocket
 from tornado.httpclient import AsyncHTTPClient
 
 PREDICTOR_URL_FORMAT = "http://{0}/v1/models/{1}:predict"
 EXPLAINER_URL_FORMAT = "http://{0}/v1/models/{1}:explain"
+PREDICTOR_WEBSOCKET_URL_FORMAT = "ws://{0}/v1/models/{1}:predict"
+PREDICTOR_HTTP2 = "HTTP/2"
+# The W3C trace context and the B3 headers of Istio, forwarded so the predictor and explainer spans are part of the
+# trace of the request
+TRACE_HEADERS = ["traceparent", "tracestate", "baggage", "b3", "x-b3-traceid", "x-b3-spanid", "x-b3-parentspanid",
+                 "x-b3-sampled", "x-b3-flags", "x-request-id"]
+
+
+def trace_headers(headers: Dict) -> Dict:
+    if not headers:
+        return {}
+    return {name: value for name, value in headers.items() if name.lower() in TRACE_HEADERS}
 
 
 # KFModel is intended to be subclassed by various components within KFServing.
@@ -29,20 +44,41 @@ class KFModel:
     def __init__(self, name: str):
         self.name = name
         self.ready = False
-        self.predictor_host = None
+        # The predictor call of the transformer sets the host and the HTTP protocol of the predictor
+        self.predictor_host = os.environ.get("PREDICTOR_HOST")
+        self.predictor_protocol = os.environ.get("PREDICTOR_PROTOCOL", "HTTP/1.1")
         self.explainer_host = None
         # The timeout matches what is set in generated Istio resorurces.
         # We generally don't want things to time out at the request level here,
         # timeouts should be handled elsewhere in the system.
         self.timeout = 600
+        # The responses of a streaming predictor are written to the client as they are received
+        self.streaming = os.environ.get("PREDICTOR_STREAMING", "false").lower() == "true"
+        # The websocket connections of the clients are relayed to the predictor
+        self.websocket = os.environ.get("PREDICTOR_WEBSOCKET", "false").lower() == "true"
         self._http_client_instance = None
 
     @property
     def _http_client(self):
         if self._http_client_instance is None:
-            self._http_client_instance = AsyncHTTPClient(max_clients=sys.maxsize)
+            # The number of concurrent calls to the predictor can be bounded by the predictorCall of the transformer
+            max_clients = int(os.environ.get("PREDICTOR_MAX_CONNECTIONS", sys.maxsize))
+            if self.predictor_protocol == PREDICTOR_HTTP2:
+                # Only the curl client speaks HTTP/2, it requires pycurl built with HTTP/2 support
+                from tornado.curl_httpclient import CurlAsyncHTTPClient
+                self._http_client_instance = CurlAsyncHTTPClient(max_clients=max_clients)
+            else:
+                self._http_client_instance = AsyncHTTPClient(max_clients=max_clients)
         return self._http_client_instance
 
+    def _predictor_options(self) -> Dict:
+        # The predictor serves HTTP/2 cleartext, the calls use it without upgrading from HTTP/1.1
+        if self.predictor_protocol == PREDICTOR_HTTP2:
+            import pycurl
+            return {"prepare_curl_callback": lambda curl: curl.setopt(
+                pycurl.HTTP_VERSION, pycurl.CURL_HTTP_VERSION_2_PRIOR_KNOWLEDGE)}
+        return {}
+
     def load(self) -> bool:
         self.ready = True
         return self.ready
@@ -53,7 +89,7 @@ class KFModel:
     def postprocess(self, request: Dict) -> Dict:
         return request
 
-    async def predict(self, request: Dict) -> Dict:
+    async def predict(self, request: Dict, headers: Dict = None) -> Dict:
         if not self.predictor_host:
             raise NotImplementedError
 
@@ -61,7 +97,9 @@ class KFModel:
             PREDICTOR_URL_FORMAT.format(self.predictor_host, self.name),
             method='POST',
             request_timeout=self.timeout,
-            body=json.dumps(request)
+            headers=trace_headers(headers),
+            body=json.dumps(request),
+            **self._predictor_options()
         )
         if response.code != 200:
             raise tornado.web.HTTPError(
@@ -69,7 +107,48 @@ class KFModel:
                 reason=response.body)
         return json.loads(response.body)
 
-    async def explain(self, request: Dict) -> Dict:
+    async def predict_stream(self, request: Dict, handler: tornado.web.RequestHandler):
+        if not self.predictor_host:
+            raise NotImplementedError
+
+        def on_header(line: str):
+            if line.startswith("HTTP/"):
+                handler.set_status(tornado.httputil.parse_response_start_line(line).code)
+            elif line.lower().startswith("content-type:"):
+                handler.set_header("Content-Type", line.split(":", 1)[1].strip())
+
+        def on_chunk(chunk: bytes):
+            handler.write(chunk)
+            handler.flush()
+
+        response = await self._http_client.fetch(
+            PREDICTOR_URL_FORMAT.format(self.predictor_host, self.name),
+            method='POST',
+            request_timeout=self.timeout,
+            headers={**trace_headers(handler.request.headers),
+                     "Accept": handler.request.headers.get("Accept", "*/*")},
+            body=json.dumps(request),
+            header_callback=on_header,
+            streaming_callback=on_chunk,
+            raise_error=False,
+            **self._predictor_options()
+        )
+        # The predictor could not be reached, nothing was streamed to the client
+        if response.code == 599:
+            raise tornado.web.HTTPError(
+                status_code=502,
+                reason=str(response.error))
+
+    async def predict_websocket(self) -> tornado.websocket.WebSocketClientConnection:
+        if not self.predictor_host:
+            raise NotImplementedError
+
+        return await tornado.websocket.websocket_connect(
+            PREDICTOR_WEBSOCKET_URL_FORMAT.format(self.predictor_host, self.name),
+            connect_timeout=self.timeout
+        )
+
+    async def explain(self, request: Dict, headers: Dict = None) -> Dict:
         if self.explainer_host is None:
             raise NotImplementedError
 
@@ -77,6 +156,7 @@ class KFModel:
             url=EXPLAINER_URL_FORMAT.format(self.explainer_host, self.name),
             method='POST',
             request_timeout=self.timeout,
+            headers=trace_headers(headers),
             body=json.dumps(request)
         )
         if response.code != 200:
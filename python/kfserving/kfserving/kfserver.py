@@ -22,10 +22,12 @@ import tornado.ioloop
 import tornado.web
 import tornado.httpserver
 import tornado.log
+from prometheus_client import generate_latest, CONTENT_TYPE_LATEST
 
 from kfserving.handlers.http import PredictHandler, ExplainHandler
 from kfserving import KFModel
 from kfserving.kfmodel_repository import KFModelRepository
+from kfserving.metrics import MODEL_LOAD_SECONDS, LOAD_POLICY_EAGER, LOAD_POLICY_LAZY
 
 DEFAULT_HTTP_PORT = 8080
 DEFAULT_GRPC_PORT = 8081
@@ -40,6 +42,8 @@ parser.add_argument('--max_buffer_size', default=DEFAULT_MAX_BUFFER_SIZE, type=i
                     help='The max buffer size for tornado.')
 parser.add_argument('--workers', default=1, type=int,
                     help='The number of works to fork')
+parser.add_argument('--load_policy', default=LOAD_POLICY_EAGER, choices=[LOAD_POLICY_EAGER, LOAD_POLICY_LAZY],
+                    help='Load the models at startup (Eager) or on their first request (Lazy).')
 args, _ = parser.parse_known_args()
 
 tornado.log.enable_pretty_logging()
@@ -50,12 +54,14 @@ class KFServer:
                  grpc_port: int = args.grpc_port,
                  max_buffer_size: int = args.max_buffer_size,
                  workers: int = args.workers,
-                 registered_models: KFModelRepository = KFModelRepository()):
+                 registered_models: KFModelRepository = KFModelRepository(),
+                 load_policy: str = args.load_policy):
         self.registered_models = registered_models
         self.http_port = http_port
         self.grpc_port = grpc_port
         self.max_buffer_size = max_buffer_size
         self.workers = workers
+        self.load_policy = load_policy
         self._http_server: Optional[tornado.httpserver.HTTPServer] = None
 
     def create_application(self):
@@ -63,15 +69,16 @@ class KFServer:
             # Server Liveness API returns 200 if server is alive.
             (r"/", LivenessHandler),
             (r"/v2/health/live", LivenessHandler),
+            (r"/metrics", MetricsHandler),
             (r"/v1/models",
              ListHandler, dict(models=self.registered_models)),
             (r"/v2/models",
              ListHandler, dict(models=self.registered_models)),
             # Model Health API returns 200 if model is ready to serve.
             (r"/v1/models/([a-zA-Z0-9_-]+)",
-             HealthHandler, dict(models=self.registered_models)),
+             HealthHandler, dict(models=self.registered_models, load_policy=self.load_policy)),
             (r"/v2/models/([a-zA-Z0-9_-]+)/status",
-             HealthHandler, dict(models=self.registered_models)),
+             HealthHandler, dict(models=self.registered_models, load_policy=self.load_policy)),
             (r"/v1/models/([a-zA-Z0-9_-]+):predict",
              PredictHandler, dict(models=self.registered_models)),
             (r"/v2/models/([a-zA-Z0-9_-]+)/infer",
@@ -88,6 +95,8 @@ class KFServer:
 
     def start(self, models: List[KFModel], nest_asyncio: bool = False):
         for model in models:
+            if self.load_policy == LOAD_POLICY_EAGER and not model.ready and not self.load_model(model):
+                continue
             self.register_model(model)
 
         self._http_server = tornado.httpserver.HTTPServer(
@@ -114,15 +123,34 @@ class KFServer:
         self.registered_models.update(model)
         logging.info("Registering model: %s", model.name)
 
+    def load_model(self, model: KFModel) -> bool:
+        """Loads the model at startup, the models failing to load are not registered"""
+        try:
+            with MODEL_LOAD_SECONDS.labels(model=model.name, load_policy=LOAD_POLICY_EAGER).time():
+                model.load()
+        except Exception:  # pylint:disable=broad-except
+            ex_type, ex_value, _ = sys.exc_info()
+            logging.error(f"fail to load model {model.name}. "
+                          f"exception type {ex_type}, exception msg: {ex_value}")
+            model.ready = False
+        return model.ready
+
 
 class LivenessHandler(tornado.web.RequestHandler):  # pylint:disable=too-few-public-methods
     def get(self):
         self.write("Alive")
 
 
+class MetricsHandler(tornado.web.RequestHandler):  # pylint:disable=too-few-public-methods
+    def get(self):
+        self.set_header("Content-Type", CONTENT_TYPE_LATEST)
+        self.write(generate_latest())
+
+
 class HealthHandler(tornado.web.RequestHandler):
-    def initialize(self, models: KFModelRepository):
+    def initialize(self, models: KFModelRepository, load_policy: str = LOAD_POLICY_EAGER):
         self.models = models  # pylint:disable=attribute-defined-outside-init
+        self.load_policy = load_policy  # pylint:disable=attribute-defined-outside-init
 
     def get(self, name: str):
         model = self.models.get_model(name)
@@ -132,7 +160,8 @@ class HealthHandler(tornado.web.RequestHandler):
                 reason="Model with name %s does not exist." % name
             )
 
-        if not model.ready:
+        # The lazily loaded models are able to serve before their load, the first request loads them
+        if not model.ready and self.load_policy != LOAD_POLICY_LAZY:
             raise tornado.web.HTTPError(
                 status_code=503,
                 reason="Model with name %s is not ready." % name
@@ -158,7 +187,9 @@ class LoadHandler(tornado.web.RequestHandler):
 
     async def post(self, name: str):
         try:
-            (await self.models.load(name)) if inspect.iscoroutinefunction(self.models.load) else self.models.load(name)
+            with MODEL_LOAD_SECONDS.labels(model=name, load_policy=LOAD_POLICY_EAGER).time():
+                (await self.models.load(name)) if inspect.iscoroutinefunction(self.models.load) \
+                    else self.models.load(name)
         except Exception as e:
             ex_type, ex_value, ex_traceback = sys.exc_info()
             raise tornado.web.HTTPError(
@@ -0,0 +1,27 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+from prometheus_client import Histogram
+
+LOAD_POLICY_EAGER = "Eager"
+LOAD_POLICY_LAZY = "Lazy"
+
+# The load of a model is recorded apart from the requests, a request loading a lazily loaded model records the load in
+# MODEL_LOAD_SECONDS and only the inference in REQUEST_SECONDS.
+MODEL_LOAD_SECONDS = Histogram("kfserving_model_load_seconds",
+                               "Latency of loading a model in the model server",
+                               ["model", "load_policy"])
+REQUEST_SECONDS = Histogram("kfserving_request_seconds",
+                            "Latency of the inference requests, excluding the load of the model",
+                            ["model", "endpoint"])
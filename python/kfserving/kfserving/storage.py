@@ -13,12 +13,14 @@
 # limitations under the License.
 
 import glob
+import hashlib
 import logging
 import tempfile
 import mimetypes
 import os
 import re
 import shutil
+import subprocess
 import tarfile
 import zipfile
 import gzip
@@ -35,6 +37,20 @@ _BLOB_RE = "https://(.+?).blob.core.windows.net/(.+)"
 _LOCAL_PREFIX = "file://"
 _URI_RE = "https?://(.+)/(.+)"
 _HTTP_PREFIX = "http(s)://"
+_TORCHSERVE_MODEL_STORE = "model-store"
+_TORCHSERVE_CONFIG = os.path.join("config", "config.properties")
+# the kfserving wrapper in the TorchServe image serves the KFServing protocol on 8080
+_TORCHSERVE_DEFAULT_CONFIG = """inference_address=http://0.0.0.0:8085
+management_address=http://0.0.0.0:8086
+metrics_address=http://0.0.0.0:8082
+model_store={model_store}
+load_models=all
+enable_envvars_config=true
+"""
+_TORCHSERVE_ARCHIVER = "torch-model-archiver"
+_TORCHSERVE_ARCHIVE_HINT = ("No TorchServe model archive (.mar) found in %s, generate one with: "
+                            "torch-model-archiver --model-name %s --version 1.0 --serialized-file %s "
+                            "--handler %s --export-path " + _TORCHSERVE_MODEL_STORE)
 
 class Storage(object): # pylint: disable=too-few-public-methods
     @staticmethod
@@ -254,6 +270,72 @@ The path or model %s does not exist." % (uri))
 
         return out_dir
 
+    @staticmethod
+    def verify_model_digest(model_dir: str, digest: str):
+        """Check the downloaded model against the digest declared on the component,
+        e.g. sha256:9c2a... A single file model is hashed as is, a model directory
+        as the sha256sum listing of its files sorted by relative path, the output of
+        find -L . -type f | sed 's|^./||' | LC_ALL=C sort | xargs -d '\\n' sha256sum | sha256sum"""
+        algorithm, _, expected = digest.partition(":")
+        if algorithm not in ("sha256", "sha512"):
+            raise RuntimeError("Unsupported model digest %s, expected sha256 or sha512" % digest)
+        files = []
+        for root, _, names in os.walk(model_dir, followlinks=True):
+            for name in names:
+                path = os.path.join(root, name)
+                files.append((os.path.relpath(path, model_dir), path))
+        if len(files) == 1 and os.path.dirname(files[0][0]) == "":
+            actual = Storage._file_digest(algorithm, files[0][1])
+        else:
+            listing = hashlib.new(algorithm)
+            for relpath, path in sorted(files, key=lambda f: f[0].encode()):
+                listing.update(("%s  %s\n" % (Storage._file_digest(algorithm, path), relpath)).encode())
+            actual = listing.hexdigest()
+        if actual != expected:
+            raise RuntimeError("Model digest mismatch for %s, expected %s got %s:%s" %
+                               (model_dir, digest, algorithm, actual))
+        logging.info("Verified model digest %s", digest)
+
+    @staticmethod
+    def _file_digest(algorithm: str, path: str) -> str:
+        file_hash = hashlib.new(algorithm)
+        with open(path, "rb") as f:
+            for chunk in iter(lambda: f.read(1 << 20), b""):
+                file_hash.update(chunk)
+        return file_hash.hexdigest()
+
+    @staticmethod
+    def prepare_torchserve_model_store(model_dir: str, model_name: str = None, handler: str = None):
+        """Arrange the model archives into the layout expected by TorchServe,
+        model_dir/model-store/*.mar and model_dir/config/config.properties.
+        A serialized model without archive is archived with the handler when
+        torch-model-archiver is installed."""
+        model_store = os.path.join(model_dir, _TORCHSERVE_MODEL_STORE)
+        archives = glob.glob(os.path.join(model_dir, "*.mar"))
+        if not archives and not os.path.isdir(model_store):
+            serialized = glob.glob(os.path.join(model_dir, "*.pt")) + glob.glob(os.path.join(model_dir, "*.pth"))
+            if not serialized:
+                return
+            if not (model_name and handler and shutil.which(_TORCHSERVE_ARCHIVER)):
+                logging.warning(_TORCHSERVE_ARCHIVE_HINT, model_dir, model_name or "<name>",
+                                os.path.basename(serialized[0]), handler or "<handler>")
+                return
+            os.makedirs(model_store, exist_ok=True)
+            subprocess.run([_TORCHSERVE_ARCHIVER, "--model-name", model_name, "--version", "1.0",
+                            "--serialized-file", serialized[0], "--handler", handler,
+                            "--export-path", model_store], check=True)
+        if archives:
+            os.makedirs(model_store, exist_ok=True)
+            for archive in archives:
+                shutil.move(archive, model_store)
+        config = os.path.join(model_dir, _TORCHSERVE_CONFIG)
+        if not os.path.exists(config):
+            os.makedirs(os.path.dirname(config), exist_ok=True)
+            with open(config, "w") as f:
+                # the model store is mounted at the same path in the predictor container
+                f.write(_TORCHSERVE_DEFAULT_CONFIG.format(model_store=model_store))
+        logging.info("Prepared TorchServe model store in %s", model_store)
+
     @staticmethod
     def _create_minio_client():
         # Adding prefixing "http" in urlparse is necessary for it to be the netloc
@@ -12,12 +12,15 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import json
 import pytest
 from kfserving import kfmodel
 from kfserving import kfserver
 from tornado.httpclient import HTTPClientError
 from kfserving.kfmodel_repository import KFModelRepository
 
+TRACEPARENT = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
 
 class DummyModel(kfmodel.KFModel):
     def __init__(self, name):
@@ -35,6 +38,11 @@ class DummyModel(kfmodel.KFModel):
         return {"predictions": request["instances"]}
 
 
+class HeadersModel(DummyModel):
+    async def predict(self, request, headers=None):
+        return {"predictions": request["instances"], "trace": kfmodel.trace_headers(headers)}
+
+
 class DummyKFModelRepository(KFModelRepository):
     def __init__(self, test_load_success: bool):
         super().__init__()
@@ -90,6 +98,28 @@ class TestTFHttpServer():
         assert resp.body == b'["TestModel"]'
 
 
+class TestTFHttpServerHeaders():
+
+    @pytest.fixture(scope="class")
+    def app(self):  # pylint: disable=no-self-use
+        model = HeadersModel("TestModel")
+        model.load()
+        server = kfserver.KFServer()
+        server.register_model(model)
+        return server.create_application()
+
+    async def test_predict_trace_headers(self, http_server_client):
+        resp = await http_server_client.fetch('/v1/models/TestModel:predict',
+                                              method="POST",
+                                              headers={"traceparent": TRACEPARENT,
+                                                       "X-B3-Sampled": "1",
+                                                       "Authorization": "Bearer token"},
+                                              body=b'{"instances":[[1,2]]}')
+        assert resp.code == 200
+        assert json.loads(resp.body) == {"predictions": [[1, 2]],
+                                         "trace": {"Traceparent": TRACEPARENT, "X-B3-Sampled": "1"}}
+
+
 class TestTFHttpServerLoadAndUnLoad():
     @pytest.fixture(scope="class")
     def app(self):  # pylint: disable=no-self-use
@@ -141,3 +171,33 @@ class TestTFHttpServerModelNotLoaded():
         with pytest.raises(HTTPClientError) as excinfo:
             _ = await http_server_client.fetch('/v1/models/TestModel')
         assert excinfo.value.code == 503
+
+
+class TestTFHttpServerLazyLoad():
+
+    @pytest.fixture(scope="class")
+    def app(self):  # pylint: disable=no-self-use
+        model = DummyModel("LazyModel")
+        server = kfserver.KFServer(load_policy="Lazy")
+        server.register_model(model)
+        return server.create_application()
+
+    async def test_model_not_loaded(self, http_server_client):
+        resp = await http_server_client.fetch('/v1/models/LazyModel')
+        assert resp.code == 200
+        assert resp.body == b'{"name": "LazyModel", "ready": false}'
+
+    async def test_predict_loads_model(self, http_server_client):
+        resp = await http_server_client.fetch('/v1/models/LazyModel:predict',
+                                              method="POST",
+                                              body=b'{"instances":[[1,2]]}')
+        assert resp.code == 200
+        assert resp.body == b'{"predictions": [[1, 2]]}'
+        resp = await http_server_client.fetch('/v1/models/LazyModel')
+        assert resp.body == b'{"name": "LazyModel", "ready": true}'
+
+    async def test_metrics(self, http_server_client):
+        resp = await http_server_client.fetch('/metrics')
+        assert resp.code == 200
+        assert b'kfserving_model_load_seconds_count{model="LazyModel",load_policy="Lazy"} 1.0' in resp.body
+        assert b'kfserving_request_seconds_count{model="LazyModel",endpoint="predict"} 1.0' in resp.body
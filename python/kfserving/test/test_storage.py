@@ -12,6 +12,7 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
+import hashlib
 import io
 import os
 import pytest
@@ -132,3 +133,53 @@ def test_no_permission_buckets(mock_connection, mock_minio):
     mock_connection.side_effect = exceptions.Forbidden(None)
     with pytest.raises(exceptions.Forbidden):
         kfserving.Storage.download(bad_gcs_path)
+
+
+def test_prepare_torchserve_model_store(tmpdir):
+    tmpdir.join("mnist.mar").write("archive")
+    kfserving.Storage.prepare_torchserve_model_store(str(tmpdir))
+    assert os.path.exists(os.path.join(str(tmpdir), "model-store", "mnist.mar"))
+    assert not os.path.exists(os.path.join(str(tmpdir), "mnist.mar"))
+    config = tmpdir.join("config", "config.properties").read()
+    assert "enable_envvars_config=true" in config
+
+
+def test_prepare_torchserve_model_store_without_archive(tmpdir):
+    tmpdir.join("model.pt").write("weights")
+    kfserving.Storage.prepare_torchserve_model_store(str(tmpdir))
+    assert not os.path.exists(os.path.join(str(tmpdir), "model-store"))
+    assert not os.path.exists(os.path.join(str(tmpdir), "config"))
+
+
+@mock.patch("shutil.which", return_value="/usr/bin/torch-model-archiver")
+@mock.patch("subprocess.run")
+def test_prepare_torchserve_model_store_archives_with_handler(mock_run, mock_which, tmpdir):
+    tmpdir.join("model.pt").write("weights")
+    kfserving.Storage.prepare_torchserve_model_store(str(tmpdir), "mnist", "image_classifier")
+    model_store = os.path.join(str(tmpdir), "model-store")
+    mock_run.assert_called_once_with(["torch-model-archiver", "--model-name", "mnist", "--version", "1.0",
+                                      "--serialized-file", os.path.join(str(tmpdir), "model.pt"),
+                                      "--handler", "image_classifier", "--export-path", model_store], check=True)
+    assert os.path.exists(os.path.join(str(tmpdir), "config", "config.properties"))
+
+
+def test_verify_model_digest(tmpdir):
+    tmpdir.join("model.joblib").write("weights")
+    digest = "sha256:" + hashlib.sha256(b"weights").hexdigest()
+    kfserving.Storage.verify_model_digest(str(tmpdir), digest)
+
+
+def test_verify_model_digest_directory(tmpdir):
+    tmpdir.join("model.joblib").write("weights")
+    tmpdir.mkdir("assets").join("vocab.txt").write("vocab")
+    listing = "%s  assets/vocab.txt\n%s  model.joblib\n" % (hashlib.sha256(b"vocab").hexdigest(),
+                                                            hashlib.sha256(b"weights").hexdigest())
+    digest = "sha256:" + hashlib.sha256(listing.encode()).hexdigest()
+    kfserving.Storage.verify_model_digest(str(tmpdir), digest)
+
+
+def test_verify_model_digest_mismatch(tmpdir):
+    tmpdir.join("model.joblib").write("tampered")
+    digest = "sha256:" + hashlib.sha256(b"weights").hexdigest()
+    with pytest.raises(RuntimeError, match="Model digest mismatch"):
+        kfserving.Storage.verify_model_digest(str(tmpdir), digest)
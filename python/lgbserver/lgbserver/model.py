@@ -0,0 +1,57 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import kfserving
+import lightgbm as lgb
+import os
+from typing import Dict
+
+MODEL_EXTENSIONS = (".txt", ".bst")
+
+
+class LightGBMModel(kfserving.KFModel):
+    def __init__(self, name: str, model_dir: str, nthread: int,
+                 booster: lgb.Booster = None):
+        super().__init__(name)
+        self.name = name
+        self.model_dir = model_dir
+        self.nthread = nthread
+        if booster is not None:
+            self._booster = booster
+            self.ready = True
+
+    def load(self) -> bool:
+        model_path = kfserving.Storage.download(self.model_dir)
+        model_files = []
+        for file in os.listdir(model_path):
+            if file.endswith(MODEL_EXTENSIONS):
+                model_files.append(os.path.join(model_path, file))
+        if len(model_files) == 0:
+            raise RuntimeError('Missing LightGBM model file with extension %s in %s'
+                               % (MODEL_EXTENSIONS, model_path))
+        if len(model_files) > 1:
+            raise RuntimeError('More than one LightGBM model file found in %s: %s'
+                               % (model_path, model_files))
+        self._booster = lgb.Booster(params={"num_threads": self.nthread},
+                                    model_file=model_files[0])
+        self.ready = True
+        return self.ready
+
+    def predict(self, request: Dict) -> Dict:
+        try:
+            result = self._booster.predict(request["instances"],
+                                           num_threads=self.nthread)
+            return {"predictions": result.tolist()}
+        except Exception as e:
+            raise Exception("Failed to predict %s" % e)
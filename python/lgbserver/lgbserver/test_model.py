@@ -0,0 +1,41 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import lightgbm as lgb
+import os
+from sklearn.datasets import load_iris
+from lgbserver import LightGBMModel
+
+MODEL_FILE = "model.txt"
+NTHREAD = 1
+
+
+def test_model(tmp_path):
+    iris = load_iris()
+    y = iris['target']
+    X = iris['data']
+    dtrain = lgb.Dataset(X, label=y)
+    params = {'objective': 'multiclass',
+              'num_class': 3,
+              'num_threads': 1,
+              'verbose': -1
+              }
+    lgb_model = lgb.train(params=params, train_set=dtrain)
+    model_file = os.path.join(tmp_path, MODEL_FILE)
+    lgb_model.save_model(model_file)
+    model = LightGBMModel("model", str(tmp_path), NTHREAD)
+    model.load()
+    request = [X[0].tolist()]
+    response = model.predict({"instances": request})
+    assert response["predictions"][0].index(max(response["predictions"][0])) == 0
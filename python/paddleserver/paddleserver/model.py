@@ -0,0 +1,77 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+from typing import Dict
+
+import kfserving
+import numpy as np
+import paddle
+from paddle import inference
+
+MODEL_FILE_EXTENSION = ".pdmodel"
+PARAMS_FILE_EXTENSION = ".pdiparams"
+
+
+class PaddleModel(kfserving.KFModel):
+    def __init__(self, name: str, model_dir: str):
+        super().__init__(name)
+        self.name = name
+        self.model_dir = model_dir
+        self.ready = False
+        self.predictor = None
+        self.input_tensor = None
+        self.output_tensor = None
+
+    def load(self) -> bool:
+        def get_model_files(ext: str) -> str:
+            file_list = []
+            for filename in os.listdir(model_path):
+                if filename.endswith(ext):
+                    file_list.append(filename)
+            if len(file_list) == 0:
+                raise RuntimeError("Missing PaddlePaddle file with extension %s in %s" % (ext, model_path))
+            if len(file_list) > 1:
+                raise RuntimeError("More than one PaddlePaddle file with extension %s found in %s: %s"
+                                   % (ext, model_path, file_list))
+            return os.path.join(model_path, file_list[0])
+
+        model_path = kfserving.Storage.download(self.model_dir)
+        config = inference.Config(get_model_files(MODEL_FILE_EXTENSION), get_model_files(PARAMS_FILE_EXTENSION))
+        # the controller selects the GPU enabled image when GPUs are requested
+        if paddle.is_compiled_with_cuda():
+            config.enable_use_gpu(100, 0)
+        else:
+            config.disable_gpu()
+        config.switch_use_feed_fetch_ops(False)
+        self.predictor = inference.create_predictor(config)
+
+        self.input_tensor = self.predictor.get_input_handle(self.predictor.get_input_names()[0])
+        self.output_tensor = self.predictor.get_output_handle(self.predictor.get_output_names()[0])
+
+        self.ready = True
+        return self.ready
+
+    def predict(self, request: Dict) -> Dict:
+        instances = request["instances"]
+        try:
+            inputs = np.array(instances, dtype="float32")
+        except Exception as e:
+            raise Exception("Failed to initialize NumPy array from inputs: %s, %s" % (e, instances))
+        try:
+            self.input_tensor.copy_from_cpu(inputs)
+            self.predictor.run()
+            return {"predictions": self.output_tensor.copy_to_cpu().tolist()}
+        except Exception as e:
+            raise Exception("Failed to predict %s" % e)
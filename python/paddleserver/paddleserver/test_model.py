@@ -0,0 +1,33 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import numpy as np
+import paddle
+from paddleserver import PaddleModel
+
+
+def test_model(tmp_path):
+    paddle.enable_static()
+    x = paddle.static.data(name="x", shape=[None, 4], dtype="float32")
+    y = paddle.static.nn.fc(x, size=3, activation="softmax")
+    exe = paddle.static.Executor(paddle.CPUPlace())
+    exe.run(paddle.static.default_startup_program())
+    paddle.static.save_inference_model(str(tmp_path / "inference"), [x], [y], exe)
+
+    server = PaddleModel("model", str(tmp_path))
+    server.load()
+
+    request = {"instances": np.random.rand(2, 4).tolist()}
+    response = server.predict(request)
+    assert np.array(response["predictions"]).shape == (2, 3)
@@ -0,0 +1,54 @@
+# Copyright 2020 kubeflow.org.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#    http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+import os
+from typing import Dict
+
+import kfserving
+from pypmml import Model
+
+MODEL_EXTENSION = ".pmml"
+
+
+class PmmlModel(kfserving.KFModel):
+    def __init__(self, name: str, model_dir: str):
+        super().__init__(name)
+        self.name = name
+        self.model_dir = model_dir
+        self.ready = False
+        self._model = None
+
+    def load(self) -> bool:
+        model_path = kfserving.Storage.download(self.model_dir)
+        model_files = []
+        for file in os.listdir(model_path):
+            if file.endswith(MODEL_EXTENSION):
+                model_files.append(os.path.join(model_path, file))
+        if len(model_files) == 0:
+            raise RuntimeError('Missing PMML model file with extension %s in %s'
+                               % (MODEL_EXTENSION, model_path))
+        if len(model_files) > 1:
+            raise RuntimeError('More than one PMML model file found in %s: %s'
+                               % (model_path, model_files))
+        self._model = Model.load(model_files[0])
+        self.ready = True
+        return self.ready
+
+    def predict(self, request: Dict) -> Dict:
+        instances = request["instances"]
+        try:
+            result = [self._model.predict(instance) for instance in instances]
+            return {"predictions": result}
+        except Exception as e:
+            raise Exception("Failed to predict %s" % e)